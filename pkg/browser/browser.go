@@ -0,0 +1,113 @@
+// Package browser finds the storage of browser profiles, like Local Storage,
+// cookies and extension storage, and decodes their LevelDB and SQLite files
+// so that the tokens they hold can be scanned as text.
+package browser
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// chromiumRoots are the user data directories of Chromium based browsers,
+// relative to the home directory, on Linux, macOS and Windows.
+var chromiumRoots = []string{
+	".config/google-chrome",
+	".config/google-chrome-beta",
+	".config/chromium",
+	".config/microsoft-edge",
+	".config/BraveSoftware/Brave-Browser",
+	".config/vivaldi",
+	"Library/Application Support/Google/Chrome",
+	"Library/Application Support/Chromium",
+	"Library/Application Support/Microsoft Edge",
+	"Library/Application Support/BraveSoftware/Brave-Browser",
+	"Library/Application Support/Vivaldi",
+	"AppData/Local/Google/Chrome/User Data",
+	"AppData/Local/Chromium/User Data",
+	"AppData/Local/Microsoft/Edge/User Data",
+	"AppData/Local/BraveSoftware/Brave-Browser/User Data",
+	"AppData/Local/Vivaldi/User Data",
+}
+
+// firefoxRoots are the directories holding Firefox profiles, relative to the
+// home directory.
+var firefoxRoots = []string{
+	".mozilla/firefox",
+	"snap/firefox/common/.mozilla/firefox",
+	"Library/Application Support/Firefox/Profiles",
+	"AppData/Roaming/Mozilla/Firefox/Profiles",
+}
+
+// chromiumStorage and firefoxStorage are the files and directories of a
+// profile that hold site and extension data.
+var (
+	chromiumStorage = []string{
+		"Local Storage",
+		"Session Storage",
+		"Local Extension Settings",
+		"Sync Extension Settings",
+		"IndexedDB",
+		"Cookies",
+		"Network/Cookies",
+	}
+	firefoxStorage = []string{
+		"cookies.sqlite",
+		"webappsstore.sqlite",
+		"storage/default",
+		"browser-extension-data",
+	}
+)
+
+// Profiles returns the profile directories of the Chromium based browsers
+// and of Firefox found below home.
+func Profiles(home string) []string {
+	var profiles []string
+	for _, root := range chromiumRoots {
+		profiles = append(profiles, profileDirs(filepath.Join(home, root), "Preferences")...)
+	}
+	for _, root := range firefoxRoots {
+		profiles = append(profiles, profileDirs(filepath.Join(home, root), "prefs.js")...)
+	}
+	return profiles
+}
+
+// profileDirs returns the subdirectories of root holding the marker file of
+// a profile.
+func profileDirs(root, marker string) []string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, marker)); entry.IsDir() && err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// StoragePaths returns the files and directories holding the site and
+// extension storage of the profiles, leaving out caches and history.
+func StoragePaths(profiles []string) []string {
+	var paths []string
+	for _, profile := range profiles {
+		storage := chromiumStorage
+		if _, err := os.Stat(filepath.Join(profile, "prefs.js")); err == nil {
+			storage = firefoxStorage
+		}
+		for _, name := range storage {
+			path := filepath.Join(profile, filepath.FromSlash(name))
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			paths = append(paths, path)
+			// Recent changes to SQLite databases are in their write-ahead log.
+			if _, err := os.Stat(path + "-wal"); err == nil {
+				paths = append(paths, path+"-wal")
+			}
+		}
+	}
+	return paths
+}
@@ -0,0 +1,148 @@
+package browser
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mkdir(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for _, file := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), nil, 0644))
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	home := t.TempDir()
+	chrome := filepath.Join(home, ".config/google-chrome")
+	mkdir(t, filepath.Join(chrome, "Default"), "Preferences", "Local Storage/leveldb/CURRENT", "Network/Cookies", "Network/Cookies-wal")
+	mkdir(t, filepath.Join(chrome, "Crashpad"))
+	firefox := filepath.Join(home, ".mozilla/firefox/abcd.default-release")
+	mkdir(t, firefox, "prefs.js", "cookies.sqlite", "storage/default/https+++example.com/ls/data.sqlite")
+
+	profiles := Profiles(home)
+	assert.Equal(t, []string{filepath.Join(chrome, "Default"), firefox}, profiles)
+	assert.Equal(t, []string{
+		filepath.Join(chrome, "Default", "Local Storage"),
+		filepath.Join(chrome, "Default", "Network", "Cookies"),
+		filepath.Join(chrome, "Default", "Network", "Cookies-wal"),
+		filepath.Join(firefox, "cookies.sqlite"),
+		filepath.Join(firefox, "storage", "default"),
+	}, StoragePaths(profiles))
+}
+
+func utf16String(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+func appendSlice(b, s []byte) []byte {
+	return append(binary.AppendUvarint(b, uint64(len(s))), s...)
+}
+
+// makeBlock returns a block with the entries, without prefix compression,
+// followed by its trailer.
+func makeBlock(compress bool, entries ...[2][]byte) []byte {
+	var block []byte
+	for _, entry := range entries {
+		block = binary.AppendUvarint(block, 0)
+		block = binary.AppendUvarint(block, uint64(len(entry[0])))
+		block = binary.AppendUvarint(block, uint64(len(entry[1])))
+		block = append(block, entry[0]...)
+		block = append(block, entry[1]...)
+	}
+	block = binary.LittleEndian.AppendUint32(block, 0)
+	block = binary.LittleEndian.AppendUint32(block, 1)
+	kind := byte(0)
+	if compress {
+		block, kind = snappy.Encode(nil, block), snappyBlock
+	}
+	return append(block, kind, 0, 0, 0, 0)
+}
+
+func makeTable(entries ...[2][]byte) []byte {
+	internalKey := func(key string) []byte { return append([]byte(key), make([]byte, 8)...) }
+	var table []byte
+	var index [][2][]byte
+	for i, entry := range entries {
+		block := makeBlock(i%2 == 0, [2][]byte{internalKey(string(entry[0])), entry[1]})
+		var handle []byte
+		handle = binary.AppendUvarint(handle, uint64(len(table)))
+		handle = binary.AppendUvarint(handle, uint64(len(block)-blockTrailer))
+		index = append(index, [2][]byte{entry[0], handle})
+		table = append(table, block...)
+	}
+	indexBlock := makeBlock(false, index...)
+	footer := binary.AppendUvarint(nil, 0)
+	footer = binary.AppendUvarint(footer, 0)
+	footer = binary.AppendUvarint(footer, uint64(len(table)))
+	footer = binary.AppendUvarint(footer, uint64(len(indexBlock)-blockTrailer))
+	footer = append(footer, make([]byte, 40-len(footer))...)
+	footer = binary.LittleEndian.AppendUint64(footer, tableMagic)
+	return append(append(table, indexBlock...), footer...)
+}
+
+func TestDecode_LevelDB(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "leveldb")
+	mkdir(t, dir, "CURRENT")
+
+	table := makeTable(
+		[2][]byte{[]byte("_https://app.example.com\x00\x01token"), append([]byte{0}, utf16String("ghp_utf16")...)},
+		[2][]byte{[]byte("_https://app.example.com\x00\x01apiKey"), []byte("\x01sk_latin1")},
+	)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "000005.ldb"), table, 0644))
+
+	var batch []byte
+	batch = append(batch, make([]byte, 12)...)
+	batch = append(batch, 1)
+	batch = appendSlice(batch, []byte("map-1-session"))
+	batch = appendSlice(batch, utf16String("xoxb-session"))
+	batch = append(batch, 0)
+	batch = appendSlice(batch, []byte("deleted"))
+	record := binary.LittleEndian.AppendUint32(nil, 0)
+	record = binary.LittleEndian.AppendUint16(record, uint16(len(batch)))
+	record = append(append(record, recordFull), batch...)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "000003.log"), record, 0644))
+
+	data, ok, err := Decode(filepath.Join(dir, "000005.ldb"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "_https://app.example.com token = ghp_utf16\n_https://app.example.com apiKey = sk_latin1\n", string(data))
+
+	data, ok, err = Decode(filepath.Join(dir, "000003.log"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "map-1-session = xoxb-session\n", string(data))
+
+	_, ok, err = Decode(filepath.Join(dir, "CURRENT"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestDecode_SQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.sqlite")
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE moz_cookies (id INTEGER, host TEXT, name TEXT, value TEXT, data BLOB);
+		INSERT INTO moz_cookies VALUES (1, '.example.com', 'session', 'abc123', ?)`, snappy.Encode(nil, []byte("compressed")))
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	data, ok, err := Decode(path)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "moz_cookies: host=.example.com name=session value=abc123 data=compressed\n", string(data))
+}
@@ -0,0 +1,158 @@
+package browser
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/snappy"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// Decode returns the contents of a LevelDB table or log, or of a SQLite
+// database, as lines of text, and whether the file is one of those. Other
+// files are left to be scanned as they are.
+func Decode(path string) ([]byte, bool, error) {
+	var out bytes.Buffer
+	switch {
+	case isSQLite(path):
+		if err := decodeSQLite(path, &out); err != nil {
+			return nil, true, err
+		}
+	case isLevelDB(path):
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, true, err
+		}
+		if strings.EqualFold(filepath.Ext(path), ".log") {
+			err = decodeLog(data, &out)
+		} else {
+			err = decodeTable(data, &out)
+		}
+		if err != nil {
+			return nil, true, err
+		}
+	default:
+		return nil, false, nil
+	}
+	return out.Bytes(), true, nil
+}
+
+func isSQLite(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	header := make([]byte, len(sqliteHeader))
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false
+	}
+	return bytes.Equal(header, sqliteHeader)
+}
+
+// isLevelDB reports whether path is a table or log of a LevelDB database,
+// which is a directory with a CURRENT file.
+func isLevelDB(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ldb", ".sst", ".log":
+	default:
+		return false
+	}
+	_, err := os.Stat(filepath.Join(filepath.Dir(path), "CURRENT"))
+	return err == nil
+}
+
+// decodeSQLite writes the text and blob columns of the rows of every table
+// of a SQLite database, like cookies.
+func decodeSQLite(path string, out *bytes.Buffer) error {
+	// Open the database read-only, and as immutable so the locks of a
+	// running browser are ignored.
+	dsn := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path), RawQuery: "mode=ro&immutable=1"}).String()
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table'")
+	if err != nil {
+		return fmt.Errorf("could not list tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if err := dumpTable(db, table, out); err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// dumpTable writes the rows of a table, one per line, with the names and
+// values of their text and blob columns.
+func dumpTable(db *sql.DB, table string, out *bytes.Buffer) error {
+	rows, err := db.Query(`SELECT * FROM "` + strings.ReplaceAll(table, `"`, `""`) + `"`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(columns))
+	for i := range values {
+		values[i] = new(any)
+	}
+	for rows.Next() {
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+		out.WriteString(table)
+		out.WriteByte(':')
+		for i, column := range columns {
+			var value []byte
+			switch v := (*values[i].(*any)).(type) {
+			case string:
+				value = []byte(v)
+			case []byte:
+				value = decodeBlob(v)
+			}
+			if len(value) == 0 {
+				continue
+			}
+			fmt.Fprintf(out, " %s=%s", column, value)
+		}
+		out.WriteByte('\n')
+	}
+	return rows.Err()
+}
+
+// decodeBlob returns the text of a blob. Firefox compresses the values of
+// Local Storage with snappy.
+func decodeBlob(b []byte) []byte {
+	if decoded, err := snappy.Decode(nil, b); err == nil && len(decoded) > 0 {
+		b = decoded
+	}
+	return decodeString(b)
+}
@@ -0,0 +1,267 @@
+package browser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+
+	"github.com/golang/snappy"
+)
+
+// tableMagic ends every LevelDB table file.
+const tableMagic = 0xdb4775248b80fb57
+
+const (
+	footerSize   = 48
+	blockTrailer = 5
+	snappyBlock  = 1
+)
+
+var errCorrupt = errors.New("corrupt LevelDB file")
+
+// decodeTable writes the keys and values of a LevelDB table, a .ldb or .sst
+// file, whose blocks are usually compressed.
+func decodeTable(data []byte, out *bytes.Buffer) error {
+	if len(data) < footerSize || binary.LittleEndian.Uint64(data[len(data)-8:]) != tableMagic {
+		return errCorrupt
+	}
+	footer := data[len(data)-footerSize:]
+	// Skip the handle of the meta index block.
+	_, _, n := readHandle(footer)
+	if n <= 0 {
+		return errCorrupt
+	}
+	indexOffset, indexSize, m := readHandle(footer[n:])
+	if m <= 0 {
+		return errCorrupt
+	}
+	index, err := readBlock(data, indexOffset, indexSize)
+	if err != nil {
+		return err
+	}
+	return eachEntry(index, func(_, handle []byte) error {
+		offset, size, n := readHandle(handle)
+		if n <= 0 {
+			return errCorrupt
+		}
+		block, err := readBlock(data, offset, size)
+		if err != nil {
+			return err
+		}
+		return eachEntry(block, func(key, value []byte) error {
+			// Keys end with their sequence number and type.
+			if len(key) >= 8 {
+				key = key[:len(key)-8]
+			}
+			writeEntry(out, key, value)
+			return nil
+		})
+	})
+}
+
+// readHandle reads the offset and size of a block, returning the number of
+// bytes read, which is not positive on error.
+func readHandle(b []byte) (offset, size uint64, n int) {
+	offset, n1 := binary.Uvarint(b)
+	if n1 <= 0 {
+		return 0, 0, n1
+	}
+	size, n2 := binary.Uvarint(b[n1:])
+	if n2 <= 0 {
+		return 0, 0, n2
+	}
+	return offset, size, n1 + n2
+}
+
+func readBlock(data []byte, offset, size uint64) ([]byte, error) {
+	if offset+size+blockTrailer > uint64(len(data)) || offset+size < offset {
+		return nil, errCorrupt
+	}
+	block := data[offset : offset+size]
+	if data[offset+size] == snappyBlock {
+		decoded, err := snappy.Decode(nil, block)
+		if err != nil {
+			return nil, fmt.Errorf("could not decompress block: %w", err)
+		}
+		return decoded, nil
+	}
+	return block, nil
+}
+
+// eachEntry calls fn with the prefix compressed entries of a block.
+func eachEntry(block []byte, fn func(key, value []byte) error) error {
+	if len(block) < 4 {
+		return errCorrupt
+	}
+	restarts := uint64(binary.LittleEndian.Uint32(block[len(block)-4:]))
+	if restarts*4+4 > uint64(len(block)) {
+		return errCorrupt
+	}
+	entries := block[:uint64(len(block))-restarts*4-4]
+
+	var key []byte
+	for len(entries) > 0 {
+		var header [3]uint64
+		for i := range header {
+			v, n := binary.Uvarint(entries)
+			if n <= 0 {
+				return errCorrupt
+			}
+			header[i] = v
+			entries = entries[n:]
+		}
+		shared, unshared, valueLen := header[0], header[1], header[2]
+		if shared > uint64(len(key)) || unshared+valueLen > uint64(len(entries)) {
+			return errCorrupt
+		}
+		key = append(key[:shared], entries[:unshared]...)
+		value := entries[unshared : unshared+valueLen]
+		entries = entries[unshared+valueLen:]
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	logBlockSize  = 32 * 1024
+	logHeaderSize = 7
+
+	recordFull   = 1
+	recordFirst  = 2
+	recordMiddle = 3
+	recordLast   = 4
+)
+
+// decodeLog writes the keys and values of the write batches in a LevelDB
+// log, which holds the most recent changes of a database.
+func decodeLog(data []byte, out *bytes.Buffer) error {
+	var record []byte
+	for block := 0; block < len(data); block += logBlockSize {
+		end := block + logBlockSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[block:end]
+		for len(chunk) >= logHeaderSize {
+			length := int(binary.LittleEndian.Uint16(chunk[4:6]))
+			kind := chunk[6]
+			if logHeaderSize+length > len(chunk) {
+				return errCorrupt
+			}
+			payload := chunk[logHeaderSize : logHeaderSize+length]
+			chunk = chunk[logHeaderSize+length:]
+			switch kind {
+			case recordFull:
+				decodeBatch(payload, out)
+			case recordFirst:
+				record = append(record[:0], payload...)
+			case recordMiddle:
+				record = append(record, payload...)
+			case recordLast:
+				decodeBatch(append(record, payload...), out)
+				record = record[:0]
+			}
+		}
+	}
+	return nil
+}
+
+// decodeBatch writes the values put by a write batch. Batches cut short are
+// decoded as far as they go.
+func decodeBatch(batch []byte, out *bytes.Buffer) {
+	// Skip the sequence number and the count.
+	if len(batch) < 12 {
+		return
+	}
+	batch = batch[12:]
+	for len(batch) > 0 {
+		put := batch[0] == 1
+		batch = batch[1:]
+		key, ok := readSlice(&batch)
+		if !ok {
+			return
+		}
+		if !put {
+			// Deletions have no value.
+			continue
+		}
+		value, ok := readSlice(&batch)
+		if !ok {
+			return
+		}
+		writeEntry(out, key, value)
+	}
+}
+
+func readSlice(b *[]byte) ([]byte, bool) {
+	length, n := binary.Uvarint(*b)
+	if n <= 0 || length > uint64(len(*b)-n) {
+		return nil, false
+	}
+	s := (*b)[n : n+int(length)]
+	*b = (*b)[n+int(length):]
+	return s, true
+}
+
+// writeEntry writes a key and its value on a line, decoding the strings
+// Chromium stores in Local Storage.
+func writeEntry(out *bytes.Buffer, key, value []byte) {
+	// Local Storage keys are the origin and the key, split by a zero byte.
+	if origin, name, ok := bytes.Cut(key, []byte{0}); ok {
+		out.Write(origin)
+		out.WriteByte(' ')
+		key = name
+	}
+	out.Write(decodeString(key))
+	out.WriteString(" = ")
+	out.Write(decodeString(value))
+	out.WriteByte('\n')
+}
+
+// decodeString returns a string stored by Chromium as UTF-8. Strings are
+// prefixed with 1 for Latin-1 or 0 for UTF-16, and Session Storage values
+// are UTF-16 without a prefix.
+func decodeString(b []byte) []byte {
+	switch {
+	case len(b) > 0 && b[0] == 1:
+		return latin1(b[1:])
+	case len(b)%2 == 1 && b[0] == 0:
+		return utf16le(b[1:])
+	case looksUTF16(b):
+		return utf16le(b)
+	}
+	return b
+}
+
+func latin1(b []byte) []byte {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return []byte(string(runes))
+}
+
+func utf16le(b []byte) []byte {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return []byte(string(utf16.Decode(units)))
+}
+
+// looksUTF16 reports whether b is ASCII text encoded as UTF-16.
+func looksUTF16(b []byte) bool {
+	if len(b) < 4 || len(b)%2 == 1 {
+		return false
+	}
+	for i := 1; i < len(b); i += 2 {
+		if b[i] != 0 || b[i-1] == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,286 @@
+// Package codecontext finds the named constructs of source code around a
+// position, like the function, class, variable or configuration key a secret
+// is in, for Go, JavaScript and TypeScript, Python, Java and YAML. It works on
+// single chunks of files with heuristics instead of parsers, so it tolerates
+// partial and invalid code.
+package codecontext
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Language is a language scopes can be found in.
+type Language int
+
+const (
+	Unknown Language = iota
+	Go
+	JavaScript
+	Python
+	Java
+	YAML
+)
+
+var extensions = map[string]Language{
+	".go":   Go,
+	".js":   JavaScript,
+	".jsx":  JavaScript,
+	".mjs":  JavaScript,
+	".cjs":  JavaScript,
+	".ts":   JavaScript,
+	".tsx":  JavaScript,
+	".mts":  JavaScript,
+	".cts":  JavaScript,
+	".py":   Python,
+	".java": Java,
+	".yaml": YAML,
+	".yml":  YAML,
+}
+
+// LanguageOf returns the language of a file by its extension.
+func LanguageOf(file string) Language {
+	return extensions[strings.ToLower(path.Ext(strings.ReplaceAll(file, "\\", "/")))]
+}
+
+// Scope is a named construct of source code, like a function or a key.
+type Scope struct {
+	// Kind is what the scope is: function, method, class, type, test,
+	// variable or key.
+	Kind string
+	Name string
+}
+
+func (s Scope) String() string {
+	return s.Kind + " " + s.Name
+}
+
+// Format returns the scopes as a path, outermost first, e.g.
+// "function TestLogin > variable token".
+func Format(scopes []Scope) string {
+	parts := make([]string, len(scopes))
+	for i, scope := range scopes {
+		parts[i] = scope.String()
+	}
+	return strings.Join(parts, " > ")
+}
+
+// Enclosing returns the scopes enclosing pos in data, code of the language,
+// outermost first. The innermost scope is the variable or key assigned on the
+// line of pos, if any.
+func Enclosing(lang Language, data []byte, pos int) []Scope {
+	if pos < 0 || pos > len(data) {
+		return nil
+	}
+	var scopes []Scope
+	switch lang {
+	case Go, JavaScript, Java:
+		scopes = braceScopes(lang, data, pos)
+	case Python:
+		scopes = indentScopes(data, pos)
+	case YAML:
+		if key := yamlKeyPath(data, pos); key != "" {
+			return []Scope{{Kind: "key", Name: key}}
+		}
+		return nil
+	default:
+		return nil
+	}
+	if assigned, ok := assignment(data, pos); ok {
+		if len(scopes) == 0 || scopes[len(scopes)-1] != assigned {
+			scopes = append(scopes, assigned)
+		}
+	}
+	return scopes
+}
+
+// assignmentPattern matches the end of the text before a value assigned to a
+// name, like `token = "`, `"token": "Bearer `, `token := `, `api_key='` or
+// `apiKey: string = '`.
+var assignmentPattern = regexp.MustCompile(`([A-Za-z_$][\w$.\-]*)["']?\s*(?::\s*[\w$<>\[\]|. ]+?\s*)?(:=|=|:)\s*[\[({]?\s*(?:[A-Za-z_]\w*\()?\s*[rbfu]?(?:["'\x60](?:[A-Za-z]+ )?)?$`)
+
+// assignment returns the variable or key the value at pos is assigned to on
+// its line.
+func assignment(data []byte, pos int) (Scope, bool) {
+	start := bytes.LastIndexByte(data[:pos], '\n') + 1
+	m := assignmentPattern.FindSubmatch(data[start:pos])
+	if m == nil {
+		return Scope{}, false
+	}
+	kind := "variable"
+	if string(m[2]) == ":" {
+		kind = "key"
+	}
+	return Scope{Kind: kind, Name: string(m[1])}, true
+}
+
+// headerPattern matches the line opening a block that is a scope.
+type headerPattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+var headers = map[Language][]headerPattern{
+	Go: {
+		{"function", regexp.MustCompile(`^\s*func\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)`)},
+		{"type", regexp.MustCompile(`^\s*type\s+([A-Za-z_]\w*)\s+(?:struct|interface)`)},
+		{"test", regexp.MustCompile(`\.Run\(\s*"([^"]+)"`)},
+		{"function", regexp.MustCompile(`([A-Za-z_]\w*)\s*:?=\s*func\s*\(`)},
+		{"variable", regexp.MustCompile(`^\s*(?:var|const)?\s*([A-Za-z_]\w*)\s*(?:[\w\[\]*.]+\s*)?:?=`)},
+		{"key", regexp.MustCompile(`^\s*"?([A-Za-z_][\w.\-]*)"?\s*:`)},
+	},
+	JavaScript: {
+		{"test", regexp.MustCompile(`\b(?:describe|it|test)\(\s*["'\x60]([^"'\x60]+)`)},
+		{"function", regexp.MustCompile(`\bfunction\s*\*?\s*([A-Za-z_$][\w$]*)\s*\(`)},
+		{"class", regexp.MustCompile(`\bclass\s+([A-Za-z_$][\w$]*)`)},
+		{"function", regexp.MustCompile(`\b(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s*)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|[A-Za-z_$][\w$]*\s*=>)`)},
+		{"variable", regexp.MustCompile(`\b(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*(?::[^=]+)?=`)},
+		{"method", regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|async|get|set|override|readonly)\s+)*\*?([A-Za-z_$][\w$]*)\s*\([^)]*\)\s*(?::[^{]+)?\{`)},
+		{"key", regexp.MustCompile(`^\s*["']?([A-Za-z_$][\w$\-]*)["']?\s*:`)},
+		{"variable", regexp.MustCompile(`^\s*(?:this\.|exports\.|module\.)?([A-Za-z_$][\w$.]*)\s*=[^=>]`)},
+	},
+	Java: {
+		{"class", regexp.MustCompile(`\b(?:class|interface|enum|record)\s+([A-Za-z_]\w*)`)},
+		{"method", regexp.MustCompile(`^\s*(?:@\w+\s+)*(?:(?:public|private|protected|static|final|synchronized|abstract|default|native)\s+)*(?:<[^>]+>\s+)?[\w<>\[\],.? ]+\s+([A-Za-z_]\w*)\s*\([^)]*\)?\s*(?:throws\s+[\w., ]+)?\{?\s*$`)},
+		{"variable", regexp.MustCompile(`([A-Za-z_]\w*)\s*=\s*(?:new\b|\{)`)},
+	},
+}
+
+// keywords open blocks that are not scopes, and are never names.
+var keywords = map[string]bool{
+	"if": true, "else": true, "for": true, "while": true, "do": true,
+	"switch": true, "case": true, "catch": true, "try": true, "finally": true,
+	"return": true, "new": true, "function": true, "synchronized": true,
+	"go": true, "defer": true, "select": true, "range": true, "with": true,
+	"default": true,
+}
+
+// braceScopes returns the scopes of the blocks enclosing pos in languages
+// with braces. Braces in strings and comments are not told apart, which is
+// rarely a problem around secrets.
+func braceScopes(lang Language, data []byte, pos int) []Scope {
+	var scopes []Scope
+	depth := 0
+	for i := pos - 1; i >= 0; i-- {
+		switch data[i] {
+		case '}':
+			depth++
+		case '{':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if scope, ok := blockHeader(lang, data, i); ok {
+				scopes = append([]Scope{scope}, scopes...)
+			}
+		}
+	}
+	return scopes
+}
+
+// blockHeader returns the scope of the block opened by the brace at open,
+// from the line the brace is on, or the line before it if the brace is on a
+// line of its own.
+func blockHeader(lang Language, data []byte, open int) (Scope, bool) {
+	start := bytes.LastIndexByte(data[:open], '\n') + 1
+	line := data[start : open+1]
+	if len(bytes.TrimSpace(line)) == 1 && start > 0 {
+		prev := bytes.LastIndexByte(data[:start-1], '\n') + 1
+		line = append(append([]byte{}, data[prev:start-1]...), '{')
+	}
+	for _, header := range headers[lang] {
+		m := header.re.FindSubmatch(line)
+		if m == nil || keywords[string(m[1])] {
+			continue
+		}
+		return Scope{Kind: header.kind, Name: string(m[1])}, true
+	}
+	return Scope{}, false
+}
+
+var (
+	pythonFunction = regexp.MustCompile(`^\s*(?:async\s+)?def\s+([A-Za-z_]\w*)`)
+	pythonClass    = regexp.MustCompile(`^\s*class\s+([A-Za-z_]\w*)`)
+)
+
+// indentScopes returns the functions and classes enclosing pos in Python, by
+// indentation.
+func indentScopes(data []byte, pos int) []Scope {
+	lines, current := linesBefore(data, pos)
+	indent := indentation(current)
+	var scopes []Scope
+	for i := len(lines) - 1; i >= 0 && indent > 0; i-- {
+		line := lines[i]
+		if len(bytes.TrimSpace(line)) == 0 || bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			continue
+		}
+		lineIndent := indentation(line)
+		if lineIndent >= indent {
+			continue
+		}
+		indent = lineIndent
+		if m := pythonFunction.FindSubmatch(line); m != nil {
+			scopes = append([]Scope{{Kind: "function", Name: string(m[1])}}, scopes...)
+		} else if m := pythonClass.FindSubmatch(line); m != nil {
+			scopes = append([]Scope{{Kind: "class", Name: string(m[1])}}, scopes...)
+		}
+	}
+	return scopes
+}
+
+var yamlKey = regexp.MustCompile(`^(\s*)((?:-\s+)?)["']?([^"'#:\s][^"'#:]*?)["']?\s*:(?:\s|$)`)
+
+// yamlKeyPath returns the dotted path of the key whose value pos is in, e.g.
+// spring.datasource.password. Keys of list items are included without the
+// index of the item.
+func yamlKeyPath(data []byte, pos int) string {
+	lines, current := linesBefore(data, pos)
+	var path []string
+	indent := -1
+	if m := yamlKey.FindSubmatch(current); m != nil && len(m[0]) <= pos-(bytes.LastIndexByte(data[:pos], '\n')+1) {
+		path = append(path, string(m[3]))
+		indent = len(m[1]) + len(m[2])
+	} else {
+		indent = indentation(current)
+	}
+	for i := len(lines) - 1; i >= 0 && indent > 0; i-- {
+		m := yamlKey.FindSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		keyIndent := len(m[1]) + len(m[2])
+		if keyIndent >= indent {
+			continue
+		}
+		path = append([]string{string(m[3])}, path...)
+		indent = keyIndent
+		if len(m[2]) > 0 {
+			// The key starts a list item, whose parent is less indented
+			// than the dash.
+			indent = len(m[1])
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+// linesBefore returns the lines before the line of pos, and the line of pos.
+func linesBefore(data []byte, pos int) ([][]byte, []byte) {
+	start := bytes.LastIndexByte(data[:pos], '\n') + 1
+	end := len(data)
+	if i := bytes.IndexByte(data[pos:], '\n'); i >= 0 {
+		end = pos + i
+	}
+	var lines [][]byte
+	if start > 0 {
+		lines = bytes.Split(data[:start-1], []byte("\n"))
+	}
+	return lines, data[start:end]
+}
+
+// indentation returns the width of the leading whitespace of a line, with
+// tabs counted as one.
+func indentation(line []byte) int {
+	return len(line) - len(bytes.TrimLeft(line, " \t"))
+}
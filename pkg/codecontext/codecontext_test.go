@@ -0,0 +1,129 @@
+package codecontext
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageOf(t *testing.T) {
+	assert.Equal(t, Go, LanguageOf("pkg/main.go"))
+	assert.Equal(t, JavaScript, LanguageOf("src/App.TSX"))
+	assert.Equal(t, YAML, LanguageOf(`C:\deploy\values.yml`))
+	assert.Equal(t, Unknown, LanguageOf("README.md"))
+}
+
+func TestEnclosing(t *testing.T) {
+	tests := []struct {
+		name string
+		lang Language
+		code string
+		want string
+	}{
+		{
+			name: "go test",
+			lang: Go,
+			code: `package auth
+
+func TestLogin(t *testing.T) {
+	t.Run("expired token", func(t *testing.T) {
+		if true {
+			token := "SECRET"
+		}
+	})
+}`,
+			want: "function TestLogin > test expired token > variable token",
+		},
+		{
+			name: "go struct literal",
+			lang: Go,
+			code: `package config
+
+var defaults = Config{
+	APIKey: "SECRET",
+}
+
+func (c *Client) Do() {}`,
+			want: "variable defaults > key APIKey",
+		},
+		{
+			name: "javascript",
+			lang: JavaScript,
+			code: `class Client {
+  constructor() {
+    this.options = {
+      headers: { Authorization: "Bearer SECRET" },
+    };
+  }
+}`,
+			want: "class Client > method constructor > variable options > key headers > key Authorization",
+		},
+		{
+			name: "typescript arrow function",
+			lang: JavaScript,
+			code: `export const connect = async (url: string): Promise<void> => {
+  const apiKey: string = 'SECRET';
+};`,
+			want: "function connect > variable apiKey",
+		},
+		{
+			name: "python",
+			lang: Python,
+			code: `import os
+
+class Settings:
+    debug = False
+
+    def client(self):
+        # Example credentials.
+        return Client(api_key="SECRET")
+`,
+			want: "class Settings > function client > variable api_key",
+		},
+		{
+			name: "java",
+			lang: Java,
+			code: `public class PaymentService {
+    private static final String KEY = "other";
+
+    public Response charge(Order order) throws IOException {
+        String stripeKey = "SECRET";
+    }
+}`,
+			want: "class PaymentService > method charge > variable stripeKey",
+		},
+		{
+			name: "yaml",
+			lang: YAML,
+			code: `spring:
+  profiles: prod
+  datasource:
+    url: jdbc:postgresql://db/app
+    password: SECRET
+`,
+			want: "key spring.datasource.password",
+		},
+		{
+			name: "yaml list",
+			lang: YAML,
+			code: `env:
+- name: TOKEN
+  value: SECRET
+`,
+			want: "key env.value",
+		},
+		{
+			name: "top level",
+			lang: Go,
+			code: `// SECRET`,
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := strings.Index(tt.code, "SECRET")
+			assert.Equal(t, tt.want, Format(Enclosing(tt.lang, []byte(tt.code), pos)))
+		})
+	}
+}
@@ -73,6 +73,9 @@ func PinnedCertPool() *x509.CertPool {
 	for _, cert := range caCerts {
 		trustedCerts.AppendCertsFromPEM([]byte(strings.TrimSpace(cert)))
 	}
+	for _, pem := range extraCAs {
+		trustedCerts.AppendCertsFromPEM(pem)
+	}
 	return trustedCerts
 }
 
@@ -118,22 +121,38 @@ func ConstantResponseHttpClient(statusCode int, body string) *http.Client {
 func PinnedRetryableHttpClient() *http.Client {
 	httpClient := retryablehttp.NewClient()
 	httpClient.Logger = nil
-	httpClient.HTTPClient.Transport = NewCustomTransport(&http.Transport{
-		TLSClientConfig: &tls.Config{
-			RootCAs: PinnedCertPool(),
-		},
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
+	tlsConfig := TLSClientConfig()
+	tlsConfig.RootCAs = PinnedCertPool()
+	httpClient.HTTPClient.Transport = NewCustomTransport(newTransport(tlsConfig))
+	return httpClient.StandardClient()
+}
+
+// RetryableHttpClientTLS returns a client like RetryableHttpClientTimeout
+// whose transport uses the TLS config, for sources that trust their own CA,
+// present a client certificate or skip verification. The config should be
+// derived from TLSClientConfig.
+func RetryableHttpClientTLS(timeOutSeconds int64, tlsConfig *tls.Config) *http.Client {
+	httpClient := retryablehttp.NewClient()
+	httpClient.RetryMax = 3
+	httpClient.Logger = nil
+	httpClient.HTTPClient.Timeout = time.Duration(timeOutSeconds) * time.Second
+	httpClient.HTTPClient.Transport = NewCustomTransport(newTransport(tlsConfig))
+	return httpClient.StandardClient()
+}
+
+// newTransport returns a transport with the TLS config that goes through the
+// configured proxies.
+func newTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		TLSClientConfig:       tlsConfig,
+		Proxy:                 Proxy,
+		DialContext:           dialer.DialContext,
 		ForceAttemptHTTP2:     true,
 		MaxIdleConns:          100,
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
-	})
-	return httpClient.StandardClient()
+	}
 }
 
 func RetryableHttpClient() *http.Client {
@@ -157,7 +176,7 @@ func RetryableHttpClientTimeout(timeOutSeconds int64) *http.Client {
 const DefaultResponseTimeout = 5 * time.Second
 
 var saneTransport = &http.Transport{
-	Proxy: http.ProxyFromEnvironment,
+	Proxy: Proxy,
 	DialContext: (&net.Dialer{
 		Timeout:   2 * time.Second,
 		KeepAlive: 5 * time.Second,
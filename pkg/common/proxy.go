@@ -0,0 +1,186 @@
+package common
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ProxyDirect is the proxy of rules for hosts that are connected to directly.
+const ProxyDirect = "direct"
+
+// ProxyConfig configures the proxies outbound HTTP connections go through.
+// Proxy URLs have an http, https or socks5 scheme.
+type ProxyConfig struct {
+	// Proxy is the proxy of hosts that match no rule. If empty, the
+	// HTTP_PROXY and HTTPS_PROXY environment variables are used.
+	Proxy string
+	// Rules are the proxies of specific hosts. The first matching rule
+	// applies, even to hosts listed in NoProxy.
+	Rules []ProxyRule
+	// NoProxy is the hosts connected to directly, in the syntax of the
+	// NO_PROXY environment variable, which is used if NoProxy is empty.
+	NoProxy string
+	// ClientCert and ClientKey are the PEM files of the certificate presented
+	// to proxies and servers that ask for one, like egress proxies using mTLS.
+	ClientCert string
+	ClientKey  string
+}
+
+// ProxyRule routes the requests to a host through a proxy.
+type ProxyRule struct {
+	// Host is a host name, or a domain starting with "*." or "." to match
+	// all its subdomains.
+	Host string
+	// Proxy is the URL of the proxy, or ProxyDirect.
+	Proxy string
+}
+
+// ParseProxyRule parses a rule of the form host=proxy.
+func ParseProxyRule(s string) (ProxyRule, error) {
+	host, proxy, ok := strings.Cut(s, "=")
+	if !ok || host == "" || proxy == "" {
+		return ProxyRule{}, fmt.Errorf("proxy rule %q is not of the form host=proxy", s)
+	}
+	return ProxyRule{Host: strings.ToLower(host), Proxy: proxy}, nil
+}
+
+func (r ProxyRule) matches(host string) bool {
+	if domain, ok := strings.CutPrefix(r.Host, "*"); ok {
+		return strings.HasSuffix(host, domain)
+	}
+	if strings.HasPrefix(r.Host, ".") {
+		return strings.HasSuffix(host, r.Host)
+	}
+	return host == r.Host
+}
+
+func parseProxyURL(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy %q: scheme must be http, https or socks5", proxy)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", proxy)
+	}
+	return u, nil
+}
+
+var (
+	proxyFunc         atomic.Pointer[func(*http.Request) (*url.URL, error)]
+	clientCertificate atomic.Pointer[tls.Certificate]
+)
+
+// Proxy returns the proxy of a request as configured by ConfigureProxy, or
+// the environment's proxy until it is called. It is the Proxy of the
+// transports of this package, and should be that of any other transport, so
+// that proxy rules apply to all connections.
+func Proxy(req *http.Request) (*url.URL, error) {
+	if fn := proxyFunc.Load(); fn != nil {
+		return (*fn)(req)
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+// getClientCertificate presents the configured client certificate, if any.
+func getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	if cert := clientCertificate.Load(); cert != nil {
+		return cert, nil
+	}
+	return &tls.Certificate{}, nil
+}
+
+// ProxyFunc returns the function choosing the proxy of a request.
+func (c ProxyConfig) ProxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	env := httpproxy.FromEnvironment()
+	if c.Proxy != "" {
+		if _, err := parseProxyURL(c.Proxy); err != nil {
+			return nil, err
+		}
+		env.HTTPProxy, env.HTTPSProxy = c.Proxy, c.Proxy
+	}
+	if c.NoProxy != "" {
+		env.NoProxy = c.NoProxy
+	}
+	fallback := env.ProxyFunc()
+
+	type rule struct {
+		ProxyRule
+		url *url.URL
+	}
+	rules := make([]rule, 0, len(c.Rules))
+	for _, r := range c.Rules {
+		parsed := rule{ProxyRule: r}
+		if r.Proxy != ProxyDirect {
+			u, err := parseProxyURL(r.Proxy)
+			if err != nil {
+				return nil, err
+			}
+			parsed.url = u
+		}
+		rules = append(rules, parsed)
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		host := strings.ToLower(req.URL.Hostname())
+		for _, r := range rules {
+			if r.matches(host) {
+				return r.url, nil
+			}
+		}
+		return fallback(req.URL)
+	}, nil
+}
+
+// ConfigureProxy applies the configuration to all outbound HTTP connections:
+// those of the clients of this package, used by detectors to verify secrets,
+// and those of http.DefaultTransport, used by the SDKs of sources. The default
+// proxy and NoProxy are also exported to the environment, so git and other
+// commands run by sources use them.
+func ConfigureProxy(c ProxyConfig) error {
+	fn, err := c.ProxyFunc()
+	if err != nil {
+		return err
+	}
+	if (c.ClientCert == "") != (c.ClientKey == "") {
+		return fmt.Errorf("a proxy client certificate needs both a certificate and a key")
+	}
+	if c.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return fmt.Errorf("could not load proxy client certificate: %w", err)
+		}
+		clientCertificate.Store(&cert)
+		updateTLSConfigs(func(config *tls.Config) {
+			config.GetClientCertificate = getClientCertificate
+		})
+	}
+
+	proxyFunc.Store(&fn)
+	if t := defaultTransport(); t != nil {
+		t.Proxy = Proxy
+	}
+	for key, value := range map[string]string{"HTTP_PROXY": c.Proxy, "HTTPS_PROXY": c.Proxy, "NO_PROXY": c.NoProxy} {
+		if value == "" {
+			continue
+		}
+		// Some commands prefer the lowercase variables.
+		for _, key := range []string{key, strings.ToLower(key)} {
+			if err := os.Setenv(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
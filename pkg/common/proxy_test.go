@@ -0,0 +1,223 @@
+package common
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProxyRule(t *testing.T) {
+	rule, err := ParseProxyRule("*.Internal.example.com=socks5://proxy:1080")
+	require.NoError(t, err)
+	assert.Equal(t, ProxyRule{Host: "*.internal.example.com", Proxy: "socks5://proxy:1080"}, rule)
+
+	for _, s := range []string{"example.com", "=http://proxy", "example.com="} {
+		_, err := ParseProxyRule(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestProxyConfig_ProxyFunc(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("NO_PROXY", "")
+	config := ProxyConfig{
+		Proxy: "http://egress:3128",
+		Rules: []ProxyRule{
+			{Host: "*.corp.example.com", Proxy: ProxyDirect},
+			{Host: ".socks.example.com", Proxy: "socks5://socks:1080"},
+			{Host: "api.github.com", Proxy: "https://github-proxy:443"},
+		},
+		NoProxy: "localhost,.local",
+	}
+	fn, err := config.ProxyFunc()
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"https://git.corp.example.com/repo":  "",
+		"https://a.b.socks.example.com/":     "socks5://socks:1080",
+		"https://API.github.com/user":        "https://github-proxy:443",
+		"https://github.com/org/repo":        "http://egress:3128",
+		"http://localhost:8080/":             "",
+		"http://nas.local/":                  "",
+		"https://corp.example.com.evil.com/": "http://egress:3128",
+	}
+	for target, want := range tests {
+		req, err := http.NewRequest(http.MethodGet, target, nil)
+		require.NoError(t, err)
+		got, err := fn(req)
+		require.NoError(t, err)
+		if want == "" {
+			assert.Nil(t, got, target)
+			continue
+		}
+		require.NotNil(t, got, target)
+		assert.Equal(t, want, got.String(), target)
+	}
+}
+
+func TestProxyConfig_Invalid(t *testing.T) {
+	for _, config := range []ProxyConfig{
+		{Proxy: "ftp://proxy:21"},
+		{Proxy: "http://"},
+		{Rules: []ProxyRule{{Host: "example.com", Proxy: "proxy:3128"}}},
+	} {
+		_, err := config.ProxyFunc()
+		assert.Error(t, err, config)
+	}
+	assert.Error(t, ConfigureProxy(ProxyConfig{ClientCert: "cert.pem"}))
+}
+
+// useProxy makes Proxy use the proxy function until the test ends.
+func useProxy(t *testing.T, config ProxyConfig) {
+	t.Helper()
+	fn, err := config.ProxyFunc()
+	require.NoError(t, err)
+	proxyFunc.Store(&fn)
+	t.Cleanup(func() { proxyFunc.Store(nil) })
+}
+
+// greeter is a server that speaks first, like IMAP and FTP servers, and
+// then echoes a line.
+func greeter(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				fmt.Fprint(conn, "* OK ready\r\n")
+				line, _ := bufio.NewReader(conn).ReadString('\n')
+				fmt.Fprint(conn, line)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+// fakeProxy is a proxy whose tunnels are opened by the handshake. The
+// targets of the tunnels are sent to the returned channel.
+func fakeProxy(t *testing.T, handshake func(*bufio.Reader, net.Conn) (string, error)) (string, chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	tunnels := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				target, err := handshake(reader, conn)
+				if err != nil {
+					return
+				}
+				tunnels <- target
+				upstream, err := net.Dial("tcp", target)
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+				go func() { _, _ = io.Copy(upstream, reader) }()
+				_, _ = io.Copy(conn, upstream)
+			}()
+		}
+	}()
+	return l.Addr().String(), tunnels
+}
+
+func connectHandshake(reader *bufio.Reader, conn net.Conn) (string, error) {
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return "", err
+	}
+	if req.Method != http.MethodConnect || req.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNz" {
+		fmt.Fprint(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+		return "", errors.New("unauthorized")
+	}
+	fmt.Fprint(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	return req.Host, nil
+}
+
+func socks5Handshake(reader *bufio.Reader, conn net.Conn) (string, error) {
+	// Greeting: version, methods. Only "no authentication" is offered.
+	greeting := make([]byte, 3)
+	if _, err := io.ReadFull(reader, greeting); err != nil {
+		return "", err
+	}
+	_, _ = conn.Write([]byte{5, 0})
+	// Request: version, connect, reserved, IPv4 address and port.
+	req := make([]byte, 10)
+	if _, err := io.ReadFull(reader, req); err != nil || req[3] != 1 {
+		return "", errors.New("unsupported request")
+	}
+	_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	port := int(req[8])<<8 | int(req[9])
+	return net.JoinHostPort(net.IP(req[4:8]).String(), strconv.Itoa(port)), nil
+}
+
+func TestDialContext(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "")
+	server := greeter(t)
+	connect, connectTunnels := fakeProxy(t, connectHandshake)
+	socks, socksTunnels := fakeProxy(t, socks5Handshake)
+
+	tests := []struct {
+		name    string
+		proxy   string
+		tunnels chan string
+	}{
+		{name: "direct", proxy: ProxyDirect},
+		{name: "connect", proxy: "http://user:pass@" + connect, tunnels: connectTunnels},
+		{name: "socks5", proxy: "socks5://" + socks, tunnels: socksTunnels},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useProxy(t, ProxyConfig{Rules: []ProxyRule{{Host: "127.0.0.1", Proxy: tt.proxy}}})
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			conn, err := DialContext(ctx, "tcp", server)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			reader := bufio.NewReader(conn)
+			greeting, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			assert.Equal(t, "* OK ready\r\n", greeting)
+			fmt.Fprint(conn, "a1 LOGOUT\r\n")
+			echo, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			assert.Equal(t, "a1 LOGOUT\r\n", echo)
+			if tt.tunnels != nil {
+				assert.Equal(t, server, <-tt.tunnels)
+			}
+		})
+	}
+}
+
+func TestDialContext_ProxyRefused(t *testing.T) {
+	connect, _ := fakeProxy(t, connectHandshake)
+	useProxy(t, ProxyConfig{Rules: []ProxyRule{{Host: "127.0.0.1", Proxy: "http://" + connect}}})
+	_, err := DialContext(context.Background(), "tcp", "127.0.0.1:993")
+	assert.ErrorContains(t, err, "407 Proxy Authentication Required")
+}
@@ -0,0 +1,148 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// TLSConfig configures the TLS of outbound connections.
+type TLSConfig struct {
+	// CAFiles are PEM bundles of CAs trusted in addition to the system's,
+	// like those of TLS-intercepting proxies and self-signed on-prem services.
+	CAFiles []string
+	// MinVersion is the minimum TLS version, one of TLSVersions. If empty,
+	// Go's default is used.
+	MinVersion string
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSVersions returns the TLS versions MinVersion can be set to.
+func TLSVersions() []string {
+	return []string{"1.0", "1.1", "1.2", "1.3"}
+}
+
+var (
+	// extraCAs are the PEM bundles of the CAFiles, which are also added to
+	// the PinnedCertPool.
+	extraCAs [][]byte
+	// rootCAs are the system's CAs and the CAFiles, or nil to use the
+	// system's.
+	rootCAs       *x509.CertPool
+	minTLSVersion uint16
+)
+
+// TLSClientConfig returns a new TLS config of outbound connections, as set by
+// ConfigureTLS and ConfigureProxy. Sources that build their own transports or
+// TLS clients must start from it rather than from an empty config, so that
+// the trusted CAs, minimum version and client certificate of the flags apply
+// to them. The config can be modified by the caller.
+func TLSClientConfig() *tls.Config {
+	config := &tls.Config{
+		RootCAs:    rootCAs,
+		MinVersion: minTLSVersion,
+	}
+	if clientCertificate.Load() != nil {
+		config.GetClientCertificate = getClientCertificate
+	}
+	return config
+}
+
+// AddRootCAs adds the PEM certificates to the CAs trusted by the config, like
+// the CA certificate of a source. The pool of the config is not modified.
+func AddRootCAs(config *tls.Config, pemCerts []byte) error {
+	pool := config.RootCAs
+	if pool == nil {
+		systemPool, err := x509.SystemCertPool()
+		if err != nil {
+			return fmt.Errorf("could not load system CAs: %w", err)
+		}
+		pool = systemPool
+	} else {
+		pool = pool.Clone()
+	}
+	if !pool.AppendCertsFromPEM(pemCerts) {
+		return errors.New("no PEM certificates found")
+	}
+	config.RootCAs = pool
+	return nil
+}
+
+// ConfigureTLS applies the configuration to all outbound connections, like
+// ConfigureProxy. It must be called before connections are made.
+func ConfigureTLS(c TLSConfig) error {
+	var version uint16
+	if c.MinVersion != "" {
+		v, ok := tlsVersions[c.MinVersion]
+		if !ok {
+			return fmt.Errorf("unknown TLS version %q", c.MinVersion)
+		}
+		version = v
+	}
+
+	var pool *x509.CertPool
+	if len(c.CAFiles) > 0 {
+		systemPool, err := x509.SystemCertPool()
+		if err != nil {
+			return fmt.Errorf("could not load system CAs: %w", err)
+		}
+		pool = systemPool
+		for _, file := range c.CAFiles {
+			pem, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("%s: no PEM certificates found", file)
+			}
+			extraCAs = append(extraCAs, pem)
+		}
+	}
+	if pool == nil && version == 0 {
+		return nil
+	}
+
+	minTLSVersion = version
+	if pool != nil {
+		rootCAs = pool
+	}
+	updateTLSConfigs(func(config *tls.Config) {
+		if pool != nil {
+			config.RootCAs = pool
+		}
+		if version != 0 {
+			config.MinVersion = version
+		}
+	})
+	return nil
+}
+
+// updateTLSConfigs updates the TLS configs of the transports configured by
+// ConfigureProxy and ConfigureTLS.
+func updateTLSConfigs(update func(*tls.Config)) {
+	for _, t := range []*http.Transport{saneTransport, defaultTransport()} {
+		if t == nil {
+			continue
+		}
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+			// Setting a TLS config would otherwise disable HTTP/2.
+			t.ForceAttemptHTTP2 = true
+		}
+		update(t.TLSClientConfig)
+	}
+}
+
+func defaultTransport() *http.Transport {
+	t, _ := http.DefaultTransport.(*http.Transport)
+	return t
+}
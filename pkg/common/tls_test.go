@@ -0,0 +1,61 @@
+package common
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := SaneHttpClient().Get(server.URL)
+	require.Error(t, err, "self-signed certificate should not be trusted by default")
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0644))
+	require.NoError(t, ConfigureTLS(TLSConfig{CAFiles: []string{caFile}, MinVersion: "1.2"}))
+
+	for name, client := range map[string]*http.Client{
+		"sane":      SaneHttpClient(),
+		"retryable": RetryableHttpClient(),
+		"pinned":    PinnedRetryableHttpClient(),
+		"tls":       RetryableHttpClientTLS(10, TLSClientConfig()),
+	} {
+		resp, err := client.Get(server.URL)
+		if assert.NoError(t, err, name) {
+			resp.Body.Close()
+		}
+	}
+}
+
+func TestAddRootCAs(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	config := TLSClientConfig()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, AddRootCAs(config, caPEM))
+	resp, err := RetryableHttpClientTLS(10, config).Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+
+	assert.Error(t, AddRootCAs(TLSClientConfig(), []byte("not a certificate")))
+}
+
+func TestConfigureTLS_Invalid(t *testing.T) {
+	assert.Error(t, ConfigureTLS(TLSConfig{MinVersion: "1.4"}))
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0644))
+	assert.Error(t, ConfigureTLS(TLSConfig{CAFiles: []string{notPEM}}))
+}
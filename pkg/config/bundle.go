@@ -0,0 +1,141 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/policy"
+)
+
+// SignatureExtension is appended to the name of a bundle to get the name of
+// its signature.
+const SignatureExtension = ".sig"
+
+// Bundle is a pack of custom detectors and policy rules, like rules that
+// ignore known false positives, distributed as a signed file so air-gapped
+// machines can be kept current through their own artifact channels.
+//
+//	version: 2023-08-01
+//	detectors:
+//	  - name: internal-token
+//	    keywords: [itk_]
+//	    regex:
+//	      token: 'itk_[a-z0-9]{32}'
+//	rules:
+//	  - name: known-test-keys
+//	    when: 'file.matches("(^|/)fixtures/")'
+//	    action: ignore
+//
+// Detectors have the format of the detectors of the configuration file, and
+// rules that of the rules of a policy.
+type Bundle struct {
+	Version string
+	Config  *Config
+	// Policy is nil if the bundle has no rules.
+	Policy *policy.Policy
+}
+
+type bundleFile struct {
+	Version   string            `json:"version"`
+	Detectors []json.RawMessage `json:"detectors"`
+	Rules     []json.RawMessage `json:"rules"`
+}
+
+// ReadBundle verifies the signature of the bundle file, which is read from
+// the file of the same name with SignatureExtension appended, with the
+// Ed25519 public key in a PEM file, and parses the bundle. The signature is
+// the raw or base64 encoded Ed25519 signature of the bundle file, e.g. made
+// with openssl pkeyutl -sign -rawin.
+func ReadBundle(filename, publicKeyFile string) (*Bundle, error) {
+	key, err := ReadPublicKey(publicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	input, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := os.ReadFile(filename + SignatureExtension)
+	if err != nil {
+		return nil, fmt.Errorf("could not read bundle signature: %w", err)
+	}
+	if err := VerifyBundle(input, signature, key); err != nil {
+		return nil, err
+	}
+	return NewBundle(input)
+}
+
+// ReadPublicKey reads an Ed25519 public key from a PEM file.
+func ReadPublicKey(filename string) (ed25519.PublicKey, error) {
+	input, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(input)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM public key found", filename)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 public key", filename)
+	}
+	return edKey, nil
+}
+
+// VerifyBundle checks the raw or base64 encoded signature of a bundle.
+func VerifyBundle(input, signature []byte, key ed25519.PublicKey) error {
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil {
+			return errors.New("bundle signature is neither a raw nor a base64 encoded Ed25519 signature")
+		}
+		signature = decoded
+	}
+	if !ed25519.Verify(key, input, signature) {
+		return errors.New("bundle signature does not match")
+	}
+	return nil
+}
+
+// NewBundle parses the given YAML data into a Bundle.
+func NewBundle(input []byte) (*Bundle, error) {
+	var file bundleFile
+	if err := yaml.UnmarshalStrict(input, &file); err != nil {
+		return nil, err
+	}
+	detectors, err := json.Marshal(map[string]any{"detectors": file.Detectors})
+	if err != nil {
+		return nil, err
+	}
+	// JSON is valid YAML, so the sections are parsed as the files they come
+	// from would be.
+	config, err := NewYAML(detectors)
+	if err != nil {
+		return nil, fmt.Errorf("bundle detectors: %w", err)
+	}
+	bundle := &Bundle{Version: file.Version, Config: config}
+	if len(file.Rules) == 0 {
+		return bundle, nil
+	}
+	rules, err := json.Marshal(map[string]any{"rules": file.Rules})
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Policy, err = policy.New(rules); err != nil {
+		return nil, fmt.Errorf("bundle rules: %w", err)
+	}
+	return bundle, nil
+}
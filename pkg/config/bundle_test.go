@@ -0,0 +1,92 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/policy"
+)
+
+const testBundle = `version: 2023-08-01
+detectors:
+  - name: internal-token
+    keywords: [itk_]
+    regex:
+      token: 'itk_[a-z0-9]{32}'
+rules:
+  - name: known-test-keys
+    when: 'file.matches("(^|/)fixtures/")'
+    action: ignore
+`
+
+func writeBundle(t *testing.T, bundle string, sign func([]byte) []byte) (string, string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "bundle.pub")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644))
+	bundleFile := filepath.Join(dir, "bundle.yaml")
+	require.NoError(t, os.WriteFile(bundleFile, []byte(bundle), 0644))
+	signature := ed25519.Sign(private, []byte(bundle))
+	require.NoError(t, os.WriteFile(bundleFile+SignatureExtension, sign(signature), 0644))
+	return bundleFile, keyFile
+}
+
+func TestReadBundle(t *testing.T) {
+	raw := func(signature []byte) []byte { return signature }
+	encoded := func(signature []byte) []byte {
+		return []byte(base64.StdEncoding.EncodeToString(signature) + "\n")
+	}
+	for name, sign := range map[string]func([]byte) []byte{"raw": raw, "base64": encoded} {
+		t.Run(name, func(t *testing.T) {
+			bundleFile, keyFile := writeBundle(t, testBundle, sign)
+			bundle, err := ReadBundle(bundleFile, keyFile)
+			require.NoError(t, err)
+			assert.Equal(t, "2023-08-01", bundle.Version)
+			assert.Len(t, bundle.Config.Detectors, 1)
+			require.NotNil(t, bundle.Policy)
+			require.Len(t, bundle.Policy.Rules, 1)
+			assert.Equal(t, policy.ActionIgnore, bundle.Policy.Rules[0].Action)
+		})
+	}
+}
+
+func TestReadBundle_Tampered(t *testing.T) {
+	bundleFile, keyFile := writeBundle(t, testBundle, func(signature []byte) []byte { return signature })
+	require.NoError(t, os.WriteFile(bundleFile, []byte(testBundle+"# changed\n"), 0644))
+	_, err := ReadBundle(bundleFile, keyFile)
+	assert.ErrorContains(t, err, "signature does not match")
+
+	require.NoError(t, os.Remove(bundleFile+SignatureExtension))
+	_, err = ReadBundle(bundleFile, keyFile)
+	assert.Error(t, err)
+}
+
+func TestNewBundle(t *testing.T) {
+	bundle, err := NewBundle([]byte("version: 1\n"))
+	require.NoError(t, err)
+	assert.Empty(t, bundle.Config.Detectors)
+	assert.Nil(t, bundle.Policy)
+
+	for name, input := range map[string]string{
+		"unknown field":    "version: 1\nallowlist: []\n",
+		"invalid detector": "detectors: [{name: x, keywords: [a], regex: {a: '('}}]\n",
+		"invalid rule":     "rules: [{when: 'verified &&'}]\n",
+	} {
+		_, err := NewBundle([]byte(input))
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"sigs.k8s.io/yaml"
+)
+
+// Profile is a named set of command line arguments for a scan. Profiles are
+// read from a YAML or TOML file:
+//
+//	profiles:
+//	  base:
+//	    flags:
+//	      no-update: true
+//	      only-verified: true
+//	  nightly-org:
+//	    extends: base
+//	    command: github
+//	    flags:
+//	      org: [my-org]
+//	      archive-max-size: 10MB
+type Profile struct {
+	// Extends is the name of a profile whose settings this profile inherits.
+	Extends string `json:"extends" toml:"extends"`
+	// Command is the scan command, e.g. github or filesystem.
+	Command string `json:"command" toml:"command"`
+	// Args are the positional arguments of the command.
+	Args []string `json:"args" toml:"args"`
+	// Flags are the command line flags by name, without leading dashes.
+	// Booleans set or negate a flag, lists repeat it, and maps set it once per
+	// key as key=value.
+	Flags map[string]any `json:"flags" toml:"flags"`
+}
+
+type profileFile struct {
+	Profiles map[string]Profile `json:"profiles" toml:"profiles"`
+}
+
+// ReadProfiles parses the profiles in a YAML or TOML file. TOML is used for
+// files with a .toml extension.
+func ReadProfiles(filename string) (map[string]Profile, error) {
+	input, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var file profileFile
+	if strings.EqualFold(filepath.Ext(filename), ".toml") {
+		md, err := toml.NewDecoder(bytes.NewReader(input)).Decode(&file)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range md.Undecoded() {
+			// Tables nested in flag values are decoded into maps, but the
+			// decoder still reports their keys as undecoded.
+			if len(key) > 3 && key[0] == "profiles" && key[2] == "flags" {
+				continue
+			}
+			return nil, fmt.Errorf("unknown profile setting %q", key.String())
+		}
+	} else if err := yaml.UnmarshalStrict(input, &file); err != nil {
+		return nil, err
+	}
+	return file.Profiles, nil
+}
+
+// ResolveProfile returns the named profile with the settings of the profiles it
+// extends applied.
+func ResolveProfile(profiles map[string]Profile, name string) (Profile, error) {
+	var chain []Profile
+	seen := make(map[string]struct{})
+	for next := name; next != ""; {
+		if _, ok := seen[next]; ok {
+			return Profile{}, fmt.Errorf("profile %q extends itself", next)
+		}
+		seen[next] = struct{}{}
+		p, ok := profiles[next]
+		if !ok {
+			return Profile{}, fmt.Errorf("profile %q not found", next)
+		}
+		chain = append(chain, p)
+		next = p.Extends
+	}
+
+	// Apply the most generic profile first, so more specific ones override it.
+	resolved := Profile{Flags: make(map[string]any)}
+	for i := len(chain) - 1; i >= 0; i-- {
+		p := chain[i]
+		if p.Command != "" {
+			resolved.Command = p.Command
+		}
+		if len(p.Args) > 0 {
+			resolved.Args = p.Args
+		}
+		for k, v := range p.Flags {
+			resolved.Flags[k] = v
+		}
+	}
+	if resolved.Command == "" {
+		return Profile{}, fmt.Errorf("profile %q does not set a command", name)
+	}
+	return resolved, nil
+}
+
+// CommandLine returns the profile as command line arguments. Flags are sorted
+// by name so the result is stable.
+func (p Profile) CommandLine() ([]string, error) {
+	args := []string{p.Command}
+
+	names := make([]string, 0, len(p.Flags))
+	for name := range p.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flagArgs, err := flagArgs(name, p.Flags[name])
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, flagArgs...)
+	}
+	return append(args, p.Args...), nil
+}
+
+// flagArgs converts a flag value from a profile to command line arguments.
+func flagArgs(name string, value any) ([]string, error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return []string{"--" + name}, nil
+		}
+		return []string{"--no-" + name}, nil
+	case []any:
+		var args []string
+		for _, item := range v {
+			s, err := scalarString(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for flag %q: %w", name, err)
+			}
+			args = append(args, "--"+name+"="+s)
+		}
+		return args, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var args []string
+		for _, k := range keys {
+			s, err := scalarString(v[k])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for flag %q: %w", name, err)
+			}
+			args = append(args, "--"+name+"="+k+"="+s)
+		}
+		return args, nil
+	default:
+		s, err := scalarString(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for flag %q: %w", name, err)
+		}
+		return []string{"--" + name + "=" + s}, nil
+	}
+}
+
+func scalarString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", value)
+	}
+}
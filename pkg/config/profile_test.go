@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const yamlProfiles = `profiles:
+  base:
+    flags:
+      no-update: true
+      only-verified: true
+      concurrency: 4
+  nightly-org:
+    extends: base
+    command: github
+    flags:
+      only-verified: false
+      org: [my-org, other-org]
+      include-paths: ./paths.txt
+`
+
+const tomlProfiles = `
+[profiles.base.flags]
+no-update = true
+concurrency = 4
+
+[profiles.local]
+extends = "base"
+command = "filesystem"
+args = ["./src"]
+
+[profiles.local.flags]
+jira-field = { customfield_1 = "a", components = "b" }
+`
+
+func writeProfiles(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestProfile_YAML(t *testing.T) {
+	profiles, err := ReadProfiles(writeProfiles(t, ".trufflehog.yaml", yamlProfiles))
+	require.NoError(t, err)
+
+	profile, err := ResolveProfile(profiles, "nightly-org")
+	require.NoError(t, err)
+	args, err := profile.CommandLine()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"github",
+		"--concurrency=4",
+		"--include-paths=./paths.txt",
+		"--no-update",
+		"--no-only-verified",
+		"--org=my-org",
+		"--org=other-org",
+	}, args)
+}
+
+func TestProfile_TOML(t *testing.T) {
+	profiles, err := ReadProfiles(writeProfiles(t, "profiles.toml", tomlProfiles))
+	require.NoError(t, err)
+
+	profile, err := ResolveProfile(profiles, "local")
+	require.NoError(t, err)
+	args, err := profile.CommandLine()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"filesystem",
+		"--concurrency=4",
+		"--jira-field=components=b",
+		"--jira-field=customfield_1=a",
+		"--no-update",
+		"./src",
+	}, args)
+}
+
+func TestReadProfiles_UnknownSetting(t *testing.T) {
+	_, err := ReadProfiles(writeProfiles(t, "profiles.yaml", "profiles:\n  a:\n    comand: git\n"))
+	assert.Error(t, err)
+
+	_, err = ReadProfiles(writeProfiles(t, "profiles.toml", "[profiles.a]\ncomand = \"git\"\n"))
+	assert.Error(t, err)
+}
+
+func TestResolveProfile_Errors(t *testing.T) {
+	profiles := map[string]Profile{
+		"no-command": {Flags: map[string]any{"json": true}},
+		"loop-a":     {Extends: "loop-b", Command: "git"},
+		"loop-b":     {Extends: "loop-a"},
+		"missing":    {Extends: "nope", Command: "git"},
+	}
+	tests := map[string]string{
+		"unknown profile": "does-not-exist",
+		"no command":      "no-command",
+		"extends cycle":   "loop-a",
+		"missing base":    "missing",
+	}
+	for name, profile := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ResolveProfile(profiles, profile)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// fileState is what is compared to tell whether a watched file changed.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) (fileState, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}, false
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}, true
+}
+
+// Watch calls onChange with the path of a file whenever its modification time
+// or size changes from when Watch was called, checking every interval in the
+// background until ctx is done. onChange is called from a single goroutine.
+// Files are polled rather than watched for events, so files replaced on save,
+// as many editors and config management tools do, keep being watched. Files
+// that are missing are skipped until they reappear.
+func Watch(ctx context.Context, interval time.Duration, onChange func(path string), paths ...string) {
+	states := make(map[string]fileState, len(paths))
+	for _, path := range paths {
+		states[path], _ = statFile(path)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			for _, path := range paths {
+				state, ok := statFile(path)
+				if !ok || state == states[path] {
+					continue
+				}
+				states[path] = state
+				onChange(path)
+			}
+		}
+	}()
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("detectors: []\n"), 0644))
+	missing := filepath.Join(dir, "policy.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan string, 10)
+	defer cancel()
+	Watch(ctx, 10*time.Millisecond, func(path string) { changes <- path }, path, missing)
+
+	// Replace the file, like editors do on save.
+	tmp := filepath.Join(dir, "config.yaml.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("detectors: [] # changed\n"), 0644))
+	require.NoError(t, os.Rename(tmp, path))
+	select {
+	case changed := <-changes:
+		assert.Equal(t, path, changed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not noticed")
+	}
+
+	require.NoError(t, os.WriteFile(missing, []byte("rules: []\n"), 0644))
+	select {
+	case changed := <-changes:
+		assert.Equal(t, missing, changed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("new file not noticed")
+	}
+	assert.Empty(t, changes)
+}
@@ -12,6 +12,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
@@ -47,6 +52,7 @@ func WithSkipIDs(skipIDs []string) func(*scanner) {
 
 // Ensure the scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*scanner)(nil)
+var _ detectors.Revoker = (*scanner)(nil)
 
 var (
 	defaultVerificationClient = common.SaneHttpClient()
@@ -291,6 +297,40 @@ type identityRes struct {
 	} `json:"GetCallerIdentityResponse"`
 }
 
+// Revoke deactivates the access key with IAM's UpdateAccessKey, signed with the
+// key itself. This only works if the key is allowed to update its own user's
+// access keys. Temporary credentials can't be deactivated.
+func (s scanner) Revoke(ctx context.Context, result detectors.Result) error {
+	id, idAndSecret := string(result.Raw), string(result.RawV2)
+	secret, ok := strings.CutPrefix(idAndSecret, id)
+	if !ok || secret == "" {
+		return fmt.Errorf("no secret key found for %s", id)
+	}
+	if strings.HasPrefix(id, "ASIA") {
+		return fmt.Errorf("%s is a temporary credential and can't be deactivated", id)
+	}
+
+	client := s.verificationClient
+	if client == nil {
+		client = defaultVerificationClient
+	}
+	cfg := aws.NewConfig().
+		WithRegion("us-east-1").
+		WithCredentials(credentials.NewStaticCredentials(id, secret, "")).
+		WithHTTPClient(client)
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return err
+	}
+	// Without a user name, IAM deactivates the key of the user signing the
+	// request.
+	_, err = iam.New(sess).UpdateAccessKeyWithContext(ctx, &iam.UpdateAccessKeyInput{
+		AccessKeyId: aws.String(id),
+		Status:      aws.String(iam.StatusTypeInactive),
+	})
+	return err
+}
+
 func (s scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_AWS
 }
@@ -39,6 +39,12 @@ type EndpointCustomizer interface {
 	DefaultEndpoint() string
 }
 
+// Revoker is an optional interface that a detector can implement to revoke or
+// deactivate the secrets it verified.
+type Revoker interface {
+	Revoke(ctx context.Context, result Result) error
+}
+
 type Result struct {
 	// DetectorType is the type of Detector.
 	DetectorType detectorspb.DetectorType
@@ -75,6 +81,10 @@ type ResultWithMetadata struct {
 	Result
 	// Data from the sources.Chunk which this result was emitted for
 	Data []byte
+	// Column is the 1-based column of the secret in its line, if known.
+	Column int64
+	// Snippet is the part of the line around the secret, if requested.
+	Snippet string
 }
 
 // CopyMetadata returns a detector result with included metadata from the source chunk.
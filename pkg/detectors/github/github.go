@@ -1,8 +1,10 @@
 package github
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -19,6 +21,7 @@ type Scanner struct{ detectors.EndpointSetter }
 var _ detectors.Detector = (*Scanner)(nil)
 var _ detectors.Versioner = (*Scanner)(nil)
 var _ detectors.EndpointCustomizer = (*Scanner)(nil)
+var _ detectors.Revoker = (*Scanner)(nil)
 
 func (Scanner) Version() int            { return 2 }
 func (Scanner) DefaultEndpoint() string { return "https://api.github.com" }
@@ -115,3 +118,35 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_Github
 }
+
+// Revoke reports the token to GitHub's credential revocation API, which revokes
+// it and notifies its owner.
+// https://docs.github.com/en/rest/credentials/revoke#revoke-a-list-of-credentials
+func (s Scanner) Revoke(ctx context.Context, result detectors.Result) error {
+	body, err := json.Marshal(map[string][]string{"credentials": {string(result.Raw)}})
+	if err != nil {
+		return err
+	}
+	client := common.SaneHttpClient()
+	var errs []error
+	for _, url := range s.Endpoints(s.DefaultEndpoint()) {
+		req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/credentials/revoke", url), bytes.NewReader(body))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		req.Header.Add("Accept", "application/vnd.github+json")
+		req.Header.Add("Content-Type", "application/json; charset=utf-8")
+		res, err := client.Do(req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		res.Body.Close()
+		if res.StatusCode >= 200 && res.StatusCode < 300 {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s returned status %d", req.URL, res.StatusCode))
+	}
+	return errors.Join(errs...)
+}
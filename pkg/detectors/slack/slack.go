@@ -17,6 +17,7 @@ type Scanner struct{}
 
 // Check that the Slack scanner implements the SecretScanner interface at compile time.
 var _ detectors.Detector = Scanner{}
+var _ detectors.Revoker = Scanner{}
 
 var (
 	tokenPats = map[string]*regexp.Regexp{
@@ -26,6 +27,7 @@ var (
 		"Slack Workspace Refresh Token": regexp.MustCompile(`xoxr\-[0-9]{10,13}\-[0-9]{10,13}[a-zA-Z0-9\-]*`),
 	}
 	verifyURL = "https://slack.com/api/auth.test"
+	revokeURL = "https://slack.com/api/auth.revoke"
 )
 
 type authRes struct {
@@ -86,3 +88,30 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_Slack
 }
+
+// Revoke revokes the token with the auth.revoke API.
+// https://api.slack.com/methods/auth.revoke
+func (s Scanner) Revoke(ctx context.Context, result detectors.Result) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", revokeURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", result.Raw))
+	res, err := common.SaneHttpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	var revokeResponse struct {
+		Ok      bool   `json:"ok"`
+		Revoked bool   `json:"revoked"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&revokeResponse); err != nil {
+		return fmt.Errorf("could not parse the auth.revoke response: %w", err)
+	}
+	if !revokeResponse.Ok || !revokeResponse.Revoked {
+		return fmt.Errorf("auth.revoke failed: %s", revokeResponse.Error)
+	}
+	return nil
+}
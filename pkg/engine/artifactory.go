@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/artifactory"
+)
+
+// ScanArtifactory scans the artifacts of the local repositories of JFrog
+// Artifactory.
+func (e *Engine) ScanArtifactory(ctx context.Context, c sources.ArtifactoryConfig) error {
+	connection := &sourcespb.Artifactory{
+		Endpoint:      c.Endpoint,
+		Repositories:  c.Repositories,
+		IncludePaths:  c.IncludePaths,
+		ExcludePaths:  c.ExcludePaths,
+		MaxObjectSize: c.MaxObjectSize,
+		StatePath:     c.StatePath,
+		Insecure:      c.Insecure,
+	}
+	switch {
+	case c.AccessToken != "":
+		connection.Credential = &sourcespb.Artifactory_AccessToken{AccessToken: c.AccessToken}
+	case c.Username != "":
+		connection.Credential = &sourcespb.Artifactory_BasicAuth{
+			BasicAuth: &credentialspb.BasicAuth{Username: c.Username, Password: c.Password},
+		}
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - artifactory", new(artifactory.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			artifactorySource := artifactory.Source{}
+			if err := artifactorySource.Init(ctx, "trufflehog - artifactory", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			artifactorySource.WithArchiveOptions(c.ArchiveOptions)
+			return &artifactorySource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
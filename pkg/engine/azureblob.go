@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/azureblob"
+)
+
+// ScanAzureBlob scans the containers of an Azure Storage account.
+func (e *Engine) ScanAzureBlob(ctx context.Context, c sources.AzureBlobConfig) error {
+	connection := &sourcespb.AzureStorage{
+		Account:                 c.Account,
+		Endpoint:                c.Endpoint,
+		StorageContainers:       c.Containers,
+		Prefixes:                c.Prefixes,
+		IgnorePrefixes:          c.ExcludePrefixes,
+		IncludeSnapshots:        c.IncludeSnapshots,
+		IncludeVersions:         c.IncludeVersions,
+		ManagedIdentityClientId: c.ManagedIdentityClientID,
+		MaxObjectSize:           c.MaxObjectSize,
+	}
+	var credentials int
+	for _, set := range []bool{c.ConnectionString != "", c.AccountKey != "", c.SASToken != "", c.ClientSecret != "", c.ManagedIdentity} {
+		if set {
+			credentials++
+		}
+	}
+	if credentials > 1 {
+		return fmt.Errorf("only one of a connection string, account key, SAS token, service principal or managed identity can be used")
+	}
+	switch {
+	case c.ConnectionString != "":
+		connection.Credential = &sourcespb.AzureStorage_ConnectionString{ConnectionString: c.ConnectionString}
+	case c.AccountKey != "":
+		connection.Credential = &sourcespb.AzureStorage_BasicAuth{
+			BasicAuth: &credentialspb.BasicAuth{Username: c.Account, Password: c.AccountKey},
+		}
+	case c.SASToken != "":
+		connection.Credential = &sourcespb.AzureStorage_SasToken{SasToken: c.SASToken}
+	case c.ClientSecret != "":
+		connection.Credential = &sourcespb.AzureStorage_ServicePrincipal{
+			ServicePrincipal: &credentialspb.ClientCredentials{
+				TenantId:     c.TenantID,
+				ClientId:     c.ClientID,
+				ClientSecret: c.ClientSecret,
+			},
+		}
+	case c.ManagedIdentity:
+		connection.Credential = &sourcespb.AzureStorage_ManagedIdentity{ManagedIdentity: &credentialspb.CloudEnvironment{}}
+	default:
+		connection.Credential = &sourcespb.AzureStorage_Unauthenticated{Unauthenticated: &credentialspb.Unauthenticated{}}
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - azure blob", new(azureblob.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			azureSource := azureblob.Source{}
+			if err := azureSource.Init(ctx, "trufflehog - azure blob", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			azureSource.WithArchiveOptions(c.ArchiveOptions)
+			return &azureSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	gogit "github.com/go-git/go-git/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/azurerepos"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+// ScanAzureDevOps scans the repositories of Azure DevOps Services or Server,
+// and the pipelines, variable groups, wikis and work items of their projects.
+func (e *Engine) ScanAzureDevOps(ctx context.Context, c sources.AzureDevOpsConfig) error {
+	opts := []git.ScanOption{
+		git.ScanOptionFilter(c.Filter),
+		git.ScanOptionLogOptions(&gogit.LogOptions{}),
+		git.ScanOptionSkipIgnoreFile(e.skipIgnoreFiles),
+		git.ScanOptionArchiveOptions(c.ArchiveOptions),
+	}
+	scanOptions := git.NewScanOptions(opts...)
+
+	connection := &sourcespb.AzureRepos{
+		Endpoint:           c.Endpoint,
+		Repositories:       c.Repos,
+		Organizations:      c.Organizations,
+		Projects:           c.Projects,
+		IncludeForks:       c.IncludeForks,
+		IncludeRepos:       c.IncludeRepos,
+		IgnoreRepos:        c.ExcludeRepos,
+		IncludeProjects:    c.IncludeProjects,
+		IgnoreProjects:     c.ExcludeProjects,
+		SkipPipelines:      c.SkipPipelines,
+		SkipVariableGroups: c.SkipVariableGroups,
+		SkipWikis:          c.SkipWikis,
+		SkipWorkItems:      c.SkipWorkItems,
+	}
+	switch {
+	case c.OAuthRefreshToken != "":
+		connection.Credential = &sourcespb.AzureRepos_Oauth{
+			Oauth: &credentialspb.Oauth2{
+				ClientId:     c.OAuthClientID,
+				ClientSecret: c.OAuthClientSecret,
+				RefreshToken: c.OAuthRefreshToken,
+				AccessToken:  c.Token,
+			},
+		}
+	case c.Token != "":
+		connection.Credential = &sourcespb.AzureRepos_Token{Token: c.Token}
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - azure devops", new(azurerepos.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			azureSource := azurerepos.Source{}
+			if err := azureSource.Init(ctx, "trufflehog - azure devops", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			azureSource.WithScanOptions(scanOptions)
+			return &azureSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	gogit "github.com/go-git/go-git/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/bitbucket"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+// ScanBitbucket scans the repositories of Bitbucket Cloud or Server, their
+// pull requests and the variables of their pipelines.
+func (e *Engine) ScanBitbucket(ctx context.Context, c sources.BitbucketConfig) error {
+	opts := []git.ScanOption{
+		git.ScanOptionFilter(c.Filter),
+		git.ScanOptionLogOptions(&gogit.LogOptions{}),
+		git.ScanOptionSkipIgnoreFile(e.skipIgnoreFiles),
+		git.ScanOptionArchiveOptions(c.ArchiveOptions),
+	}
+	scanOptions := git.NewScanOptions(opts...)
+
+	connection := &sourcespb.Bitbucket{
+		Endpoint:              c.Endpoint,
+		Repositories:          c.Repos,
+		IgnoreRepos:           c.ExcludeRepos,
+		Workspaces:            c.Workspaces,
+		Projects:              c.Projects,
+		SkipPullRequests:      c.SkipPullRequests,
+		SkipPipelineVariables: c.SkipPipelineVariables,
+	}
+	switch {
+	case c.Username != "":
+		connection.Credential = &sourcespb.Bitbucket_BasicAuth{
+			BasicAuth: &credentialspb.BasicAuth{Username: c.Username, Password: c.Token},
+		}
+	case c.OAuthRefreshToken != "":
+		connection.Credential = &sourcespb.Bitbucket_Oauth{
+			Oauth: &credentialspb.Oauth2{
+				ClientId:     c.OAuthClientID,
+				ClientSecret: c.OAuthClientSecret,
+				RefreshToken: c.OAuthRefreshToken,
+				AccessToken:  c.Token,
+			},
+		}
+	case c.Token != "":
+		connection.Credential = &sourcespb.Bitbucket_Token{Token: c.Token}
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - bitbucket", new(bitbucket.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			bitbucketSource := bitbucket.Source{}
+			if err := bitbucketSource.Init(ctx, "trufflehog - bitbucket", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			bitbucketSource.WithScanOptions(scanOptions)
+			return &bitbucketSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/cloudlogs"
+)
+
+// ScanCloudLogs scans the events of the log groups of CloudWatch Logs, Cloud
+// Logging or Azure Monitor.
+func (e *Engine) ScanCloudLogs(ctx context.Context, c sources.CloudLogsConfig) error {
+	connection := &sourcespb.CloudLogs{
+		LogGroups: c.LogGroups,
+		Filter:    c.Filter,
+		BatchSize: int64(c.BatchSize),
+		Endpoint:  c.Endpoint,
+	}
+	if !c.Since.IsZero() {
+		connection.Since = timestamppb.New(c.Since)
+	}
+	if !c.Until.IsZero() {
+		connection.Until = timestamppb.New(c.Until)
+	}
+
+	switch c.Platform {
+	case "cloudwatch":
+		cloudWatch := &sourcespb.CloudWatchLogs{Region: c.Region}
+		switch {
+		case c.SessionToken != "":
+			cloudWatch.Credential = &sourcespb.CloudWatchLogs_SessionToken{
+				SessionToken: &credentialspb.AWSSessionTokenSecret{Key: c.Key, Secret: c.Secret, SessionToken: c.SessionToken},
+			}
+		case c.Key != "":
+			cloudWatch.Credential = &sourcespb.CloudWatchLogs_AccessKey{
+				AccessKey: &credentialspb.KeySecret{Key: c.Key, Secret: c.Secret},
+			}
+		default:
+			cloudWatch.Credential = &sourcespb.CloudWatchLogs_CloudEnvironment{CloudEnvironment: &credentialspb.CloudEnvironment{}}
+		}
+		connection.Platform = &sourcespb.CloudLogs_Cloudwatch{Cloudwatch: cloudWatch}
+	case "cloud-logging":
+		cloudLogging := &sourcespb.CloudLogging{ProjectId: c.ProjectID}
+		if c.ServiceAccountFile != "" {
+			cloudLogging.Credential = &sourcespb.CloudLogging_ServiceAccountFile{ServiceAccountFile: c.ServiceAccountFile}
+		} else {
+			cloudLogging.Credential = &sourcespb.CloudLogging_Adc{Adc: &credentialspb.CloudEnvironment{}}
+		}
+		connection.Platform = &sourcespb.CloudLogs_CloudLogging{CloudLogging: cloudLogging}
+	case "azure-monitor":
+		azureMonitor := &sourcespb.AzureMonitorLogs{WorkspaceId: c.WorkspaceID}
+		if c.ManagedIdentity {
+			azureMonitor.Credential = &sourcespb.AzureMonitorLogs_ManagedIdentity{ManagedIdentity: &credentialspb.CloudEnvironment{}}
+			azureMonitor.ManagedIdentityClientId = c.ClientID
+		} else {
+			azureMonitor.Credential = &sourcespb.AzureMonitorLogs_ServicePrincipal{
+				ServicePrincipal: &credentialspb.ClientCredentials{TenantId: c.TenantID, ClientId: c.ClientID, ClientSecret: c.ClientSecret},
+			}
+		}
+		connection.Platform = &sourcespb.CloudLogs_AzureMonitor{AzureMonitor: azureMonitor}
+	default:
+		return fmt.Errorf("unknown log platform %q", c.Platform)
+	}
+
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - logs", new(cloudlogs.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			cloudLogsSource := cloudlogs.Source{}
+			if err := cloudLogsSource.Init(ctx, "trufflehog - logs", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			return &cloudLogsSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/confluence"
+)
+
+// ScanConfluence scans the versions and attachments of Confluence pages.
+func (e *Engine) ScanConfluence(ctx context.Context, c sources.ConfluenceConfig) error {
+	connection := &sourcespb.Confluence{
+		Endpoint:              c.Endpoint,
+		Credential:            &sourcespb.Confluence_Unauthenticated{},
+		Spaces:                c.Spaces,
+		IgnoreSpaces:          c.ExcludeSpaces,
+		SkipHistory:           c.SkipHistory,
+		IncludeAttachments:    !c.SkipAttachments,
+		InsecureSkipVerifyTls: c.Insecure,
+	}
+	switch {
+	case c.Username != "":
+		connection.Credential = &sourcespb.Confluence_BasicAuth{
+			BasicAuth: &credentialspb.BasicAuth{Username: c.Username, Password: c.Token},
+		}
+	case c.Token != "":
+		connection.Credential = &sourcespb.Confluence_Token{Token: c.Token}
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - confluence", new(confluence.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			confluenceSource := confluence.Source{}
+			if err := confluenceSource.Init(ctx, "trufflehog - confluence", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			confluenceSource.WithArchiveOptions(c.ArchiveOptions)
+			return &confluenceSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
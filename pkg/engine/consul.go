@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/consul"
+)
+
+// ScanConsul scans the KV store of a Consul datacenter.
+func (e *Engine) ScanConsul(ctx context.Context, c sources.ConsulConfig) error {
+	connection := &sourcespb.Consul{
+		Address:      c.Address,
+		Token:        c.Token,
+		Datacenter:   c.Datacenter,
+		Prefix:       c.Prefix,
+		ExcludeGlobs: c.ExcludeGlobs,
+		Insecure:     c.Insecure,
+	}
+	if c.CACertFile != "" {
+		ca, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return errors.WrapPrefix(err, "error reading CA certificate", 0)
+		}
+		connection.CaCertificate = string(ca)
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - consul", new(consul.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			consulSource := consul.Source{}
+			if err := consulSource.Init(ctx, "trufflehog - consul", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			return &consulSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
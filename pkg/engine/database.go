@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/database"
+)
+
+// ScanDatabase scans the text columns of the tables of a SQL database.
+func (e *Engine) ScanDatabase(ctx context.Context, c sources.DatabaseConfig) error {
+	connection := &sourcespb.Database{
+		Dsn:          c.DSN,
+		IncludeGlobs: c.IncludeGlobs,
+		ExcludeGlobs: c.ExcludeGlobs,
+		BatchSize:    c.BatchSize,
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - database", new(database.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			databaseSource := database.Source{}
+			if err := databaseSource.Init(ctx, "trufflehog - database", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			return &databaseSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
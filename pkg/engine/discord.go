@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/discord"
+)
+
+// ScanDiscord scans the messages and attachments of the channels of the
+// Discord servers of a bot.
+func (e *Engine) ScanDiscord(ctx context.Context, c sources.DiscordConfig) error {
+	connection := &sourcespb.Discord{
+		Token:           c.Token,
+		Guilds:          c.Guilds,
+		Channels:        c.Channels,
+		SkipAttachments: c.SkipAttachments,
+	}
+
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - discord", new(discord.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			discordSource := discord.Source{}
+			if err := discordSource.Init(ctx, "trufflehog - discord", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			discordSource.WithArchiveOptions(c.ArchiveOptions)
+			return &discordSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
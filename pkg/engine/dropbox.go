@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/dropbox"
+)
+
+// ScanDropbox scans the team folders and member folders of a Dropbox team.
+func (e *Engine) ScanDropbox(ctx context.Context, c sources.DropboxConfig) error {
+	connection := &sourcespb.Dropbox{
+		Credential:        &sourcespb.Dropbox_Token{Token: c.Token},
+		Members:           c.Members,
+		SkipTeamFolders:   c.SkipTeamFolders,
+		SkipMemberFolders: c.SkipMemberFolders,
+		MaxObjectSize:     c.MaxObjectSize,
+		StatePath:         c.StatePath,
+	}
+	if c.RefreshToken != "" {
+		connection.Credential = &sourcespb.Dropbox_Oauth{
+			Oauth: &credentialspb.Oauth2{ClientId: c.AppKey, ClientSecret: c.AppSecret, RefreshToken: c.RefreshToken},
+		}
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - dropbox", new(dropbox.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			dropboxSource := dropbox.Source{}
+			if err := dropboxSource.Init(ctx, "trufflehog - dropbox", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			dropboxSource.WithArchiveOptions(c.ArchiveOptions)
+			return &dropboxSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/elasticsearch"
+)
+
+// ScanElasticsearch scans the documents of the indices of an Elasticsearch
+// or OpenSearch cluster.
+func (e *Engine) ScanElasticsearch(ctx context.Context, c sources.ElasticsearchConfig) error {
+	connection := &sourcespb.Elasticsearch{
+		Url:            c.URL,
+		Username:       c.Username,
+		Password:       c.Password,
+		ApiKey:         c.APIKey,
+		Indices:        c.Indices,
+		IncludeFields:  c.IncludeFields,
+		TimestampField: c.TimestampField,
+		Since:          c.Since,
+		Until:          c.Until,
+		Insecure:       c.Insecure,
+	}
+	if c.CACertFile != "" {
+		ca, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return errors.WrapPrefix(err, "error reading CA certificate", 0)
+		}
+		connection.CaCertificate = string(ca)
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - elasticsearch", new(elasticsearch.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			elasticsearchSource := elasticsearch.Source{}
+			if err := elasticsearchSource.Init(ctx, "trufflehog - elasticsearch", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			return &elasticsearchSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
 	"runtime"
@@ -19,10 +20,12 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignore"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/policy"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
@@ -38,6 +41,15 @@ type Metrics struct {
 	ScanDuration  time.Duration
 }
 
+// ExceedsThresholds reports whether more verified or more unverified secrets
+// were found than the thresholds allow. A negative threshold disables its
+// check.
+func (m Metrics) ExceedsThresholds(verified, unverified int) bool {
+	verifiedExceeded := verified >= 0 && m.VerifiedSecretsFound > uint64(verified)
+	unverifiedExceeded := unverified >= 0 && m.UnverifiedSecretsFound > uint64(unverified)
+	return verifiedExceeded || unverifiedExceeded
+}
+
 // runtimeMetrics for the scan engine for internal use by the engine.
 type runtimeMetrics struct {
 	mu sync.RWMutex
@@ -51,6 +63,19 @@ type Printer interface {
 	Print(ctx context.Context, r *detectors.ResultWithMetadata) error
 }
 
+// ResultLabeler adds information to results before they are redacted and
+// printed, usually to their ExtraData.
+type ResultLabeler interface {
+	Label(ctx context.Context, r *detectors.ResultWithMetadata)
+}
+
+// ResultLabelerFunc is a function that implements ResultLabeler.
+type ResultLabelerFunc func(ctx context.Context, r *detectors.ResultWithMetadata)
+
+func (f ResultLabelerFunc) Label(ctx context.Context, r *detectors.ResultWithMetadata) {
+	f(ctx, r)
+}
+
 type Engine struct {
 	// CLI flags.
 	concurrency uint8
@@ -62,10 +87,41 @@ type Engine struct {
 	filterUnverified     bool
 	onlyVerified         bool
 	printAvgDetectorTime bool
-
-	// prefilter is a ahocorasick struct used for doing efficient string
-	// matching given a set of words (keywords from the rules in the config)
-	prefilter ahocorasick.Trie
+	// redactMode controls how secret values are rendered before results
+	// are handed to the printer.
+	redactMode output.RedactMode
+	// logRedactMode controls how secret values are rendered in logged
+	// errors, which may quote the secret they were about.
+	logRedactMode output.RedactMode
+	// skipIgnoreFiles disables honoring .trufflehogignore files in scanned
+	// repositories and directories.
+	skipIgnoreFiles bool
+	// requireIgnoreReason only honors inline ignore comments that give a
+	// reason.
+	requireIgnoreReason bool
+	// contextWindow is how many bytes of the line around a secret are
+	// included in its snippet. Snippets are not included if it is 0.
+	contextWindow int
+	// chunkOptions configure how large chunks are split.
+	chunkOptions []sources.ConfigOption
+	// dryRun receives the targets of sources instead of scanning them, if set.
+	dryRun sources.TargetReporter
+	// progressHooks are notified of the progress of the sources' jobs.
+	progressHooks []sources.JobProgressHook
+	// labelers label results before they are printed.
+	labelers []ResultLabeler
+	// policy decides whether results are reported and whether they fail the
+	// scan, if set. It can be replaced while scanning.
+	policy atomic.Pointer[policy.Policy]
+
+	// current is the detector set chunks are scanned with. It is replaced when
+	// detectors are reloaded.
+	current atomic.Pointer[detectorSet]
+	// generation counts the configurations applied to the engine, starting
+	// at 1, and is incremented whenever detectors or the policy are reloaded.
+	generation uint64
+	// sourceGenerations is the generation last logged for each source.
+	sourceGenerations sync.Map
 
 	// Engine synchronization primitives.
 	sourceManager        *sources.SourceManager
@@ -79,6 +135,11 @@ type Engine struct {
 	metrics runtimeMetrics
 	// numFoundResults is used to keep track of the number of results found.
 	numFoundResults uint32
+	// numPolicyFailures is the number of results matched by a policy rule
+	// that fails the scan.
+	numPolicyFailures uint32
+	// summary aggregates per-source and per-detector statistics.
+	summary *scanSummary
 
 	// printer provides a method for formatting and outputting search results.
 	// The specific implementation (e.g., JSON, plain text)
@@ -98,8 +159,6 @@ func WithConcurrency(concurrency uint8) EngineOption {
 	}
 }
 
-const ignoreTag = "trufflehog:ignore"
-
 func WithDetectors(verify bool, d ...detectors.Detector) EngineOption {
 	return func(e *Engine) {
 		if e.detectors == nil {
@@ -162,6 +221,176 @@ func WithFilterDetectors(filterFunc func(detectors.Detector) bool) EngineOption
 	}
 }
 
+// WithRedactMode sets how secret values are redacted before they are printed.
+// Redaction is applied after deduplication, so it does not affect which
+// results are reported.
+func WithRedactMode(mode output.RedactMode) EngineOption {
+	return func(e *Engine) {
+		e.redactMode = mode
+	}
+}
+
+// WithLogRedactMode sets how secret values are redacted in the errors the
+// engine logs for detectors and printers. Unlike WithRedactMode it is not
+// reset when a printer redacts results itself.
+func WithLogRedactMode(mode output.RedactMode) EngineOption {
+	return func(e *Engine) {
+		e.logRedactMode = mode
+	}
+}
+
+// WithSkipIgnoreFiles sets whether sources ignore the .trufflehogignore files
+// of scanned repositories and directories. By default they are honored.
+func WithSkipIgnoreFiles(skip bool) EngineOption {
+	return func(e *Engine) {
+		e.skipIgnoreFiles = skip
+	}
+}
+
+// WithRequireIgnoreReason sets whether inline ignore comments must give a
+// reason. Findings on lines with an ignore comment without a reason are
+// reported.
+func WithRequireIgnoreReason(require bool) EngineOption {
+	return func(e *Engine) {
+		e.requireIgnoreReason = require
+	}
+}
+
+// WithContextWindow sets how many bytes before and after a secret on its line
+// are included in the snippet of its result. Long lines, like those of
+// minified files, are cut to the window.
+func WithContextWindow(size int) EngineOption {
+	return func(e *Engine) {
+		e.contextWindow = size
+	}
+}
+
+// WithChunkOverlap sets how much of the next chunk is scanned with each chunk
+// the engine splits the large chunks of sources into. Zero keeps
+// sources.PeekSize.
+func WithChunkOverlap(size int) EngineOption {
+	return func(e *Engine) {
+		if size > 0 {
+			e.chunkOptions = []sources.ConfigOption{sources.WithPeekSize(size)}
+		}
+	}
+}
+
+// WithDryRun makes sources report what they would scan to reporter instead
+// of scanning it.
+func WithDryRun(reporter sources.TargetReporter) EngineOption {
+	return func(e *Engine) {
+		e.dryRun = reporter
+	}
+}
+
+// WithProgressHook notifies hook of the progress of every source run by the
+// engine. A nil hook is ignored.
+func WithProgressHook(hook sources.JobProgressHook) EngineOption {
+	return func(e *Engine) {
+		if hook != nil {
+			e.progressHooks = append(e.progressHooks, hook)
+		}
+	}
+}
+
+// WithResultLabeler labels results with labeler before they are printed. A nil
+// labeler is ignored.
+func WithResultLabeler(labeler ResultLabeler) EngineOption {
+	return func(e *Engine) {
+		if labeler != nil {
+			e.labelers = append(e.labelers, labeler)
+		}
+	}
+}
+
+// detectorSet is a configuration of detectors.
+type detectorSet struct {
+	generation uint64
+	detectors  map[bool][]detectors.Detector
+	// prefilter is a ahocorasick struct used for doing efficient string
+	// matching given a set of words (keywords from the rules in the config)
+	prefilter ahocorasick.Trie
+}
+
+func newDetectorSet(generation uint64, dets map[bool][]detectors.Detector) *detectorSet {
+	// build ahocorasick prefilter for efficient string matching
+	// on keywords
+	keywords := []string{}
+	for _, d := range dets[false] {
+		for _, kw := range d.Keywords() {
+			keywords = append(keywords, strings.ToLower(kw))
+		}
+	}
+	for _, d := range dets[true] {
+		for _, kw := range d.Keywords() {
+			keywords = append(keywords, strings.ToLower(kw))
+		}
+	}
+	return &detectorSet{
+		generation: generation,
+		detectors:  dets,
+		prefilter:  *ahocorasick.NewTrieBuilder().AddStrings(keywords).Build(),
+	}
+}
+
+// ReloadDetectors replaces the detectors of the engine with those configured
+// by the WithDetectors and WithFilterDetectors options, in order. Other
+// options are ignored. Chunks are scanned with the new detectors once it
+// returns, without interrupting the scan. It returns the new configuration
+// generation.
+func (e *Engine) ReloadDetectors(ctx context.Context, options ...EngineOption) uint64 {
+	var configured Engine
+	for _, option := range options {
+		option(&configured)
+	}
+	dets := configured.detectors
+	if dets == nil {
+		dets = map[bool][]detectors.Detector{true: {}, false: {}}
+	}
+
+	generation := atomic.AddUint64(&e.generation, 1)
+	e.current.Store(newDetectorSet(generation, dets))
+	ctx.Logger().Info("reloaded detectors",
+		"generation", generation,
+		"verification_enabled", len(dets[true]),
+		"verification_disabled", len(dets[false]),
+	)
+	return generation
+}
+
+// ReloadPolicy replaces the policy results are evaluated against. It returns
+// the new configuration generation.
+func (e *Engine) ReloadPolicy(ctx context.Context, p *policy.Policy) uint64 {
+	e.policy.Store(p)
+	generation := atomic.AddUint64(&e.generation, 1)
+	ctx.Logger().Info("reloaded policy", "generation", generation, "rules", len(p.Rules))
+	return generation
+}
+
+// logGeneration logs the configuration generation applied to the chunks of a
+// source when it changes. Changes during a scan are logged at info level.
+func (e *Engine) logGeneration(ctx context.Context, chunk *sources.Chunk) {
+	generation := atomic.LoadUint64(&e.generation)
+	previous, loaded := e.sourceGenerations.Swap(chunk.SourceName, generation)
+	switch {
+	case !loaded:
+		ctx.Logger().V(2).Info("applying configuration", "source_name", chunk.SourceName, "generation", generation)
+	case previous.(uint64) != generation:
+		ctx.Logger().Info("applying configuration", "source_name", chunk.SourceName, "generation", generation)
+	}
+}
+
+// WithPolicy evaluates p against every result to decide whether it is
+// reported and whether it fails the scan. A nil policy is ignored.
+func WithPolicy(p *policy.Policy) EngineOption {
+	return func(e *Engine) {
+		if p != nil {
+			e.policy.Store(p)
+		}
+	}
+}
+
 // WithPrinter sets the Printer on the engine.
 func WithPrinter(printer Printer) EngineOption {
 	return func(e *Engine) {
@@ -184,6 +413,12 @@ func (e *Engine) HasFoundResults() bool {
 	return atomic.LoadUint32(&e.numFoundResults) > 0
 }
 
+// HasPolicyFailures returns true if any results matched a policy rule that
+// fails the scan.
+func (e *Engine) HasPolicyFailures() bool {
+	return atomic.LoadUint32(&e.numPolicyFailures) > 0
+}
+
 // GetMetrics returns a copy of Metrics.
 // It's safe for concurrent use, and the caller can't modify the original data.
 func (e *Engine) GetMetrics() Metrics {
@@ -276,6 +511,7 @@ func Start(ctx context.Context, options ...EngineOption) (*Engine, error) {
 		dedupeCache:          cache,
 		printer:              new(output.PlainPrinter), // default printer
 		metrics:              runtimeMetrics{Metrics: Metrics{scanStartTime: time.Now()}},
+		summary:              newScanSummary(),
 	}
 
 	for _, option := range options {
@@ -291,10 +527,18 @@ func Start(ctx context.Context, options ...EngineOption) (*Engine, error) {
 	ctx.Logger().V(3).Info("engine started", "workers", e.concurrency)
 
 	// Create SourceManager.
-	e.sourceManager = sources.NewManager(
+	managerOpts := []func(*sources.SourceManager){
 		sources.WithConcurrentSources(int(e.concurrency)),
 		sources.WithConcurrentUnits(int(e.concurrency)),
-	)
+		sources.WithSkipReporter(e.summary),
+	}
+	if e.dryRun != nil {
+		managerOpts = append(managerOpts, sources.WithDryRun(e.dryRun))
+	}
+	for _, hook := range e.progressHooks {
+		managerOpts = append(managerOpts, sources.WithReportHook(hook))
+	}
+	e.sourceManager = sources.NewManager(managerOpts...)
 
 	if len(e.decoders) == 0 {
 		e.decoders = decoders.DefaultDecoders()
@@ -306,20 +550,8 @@ func Start(ctx context.Context, options ...EngineOption) (*Engine, error) {
 		e.detectors[false] = []detectors.Detector{}
 	}
 
-	// build ahocorasick prefilter for efficient string matching
-	// on keywords
-	keywords := []string{}
-	for _, d := range e.detectors[false] {
-		for _, kw := range d.Keywords() {
-			keywords = append(keywords, strings.ToLower(kw))
-		}
-	}
-	for _, d := range e.detectors[true] {
-		for _, kw := range d.Keywords() {
-			keywords = append(keywords, strings.ToLower(kw))
-		}
-	}
-	e.prefilter = *ahocorasick.NewTrieBuilder().AddStrings(keywords).Build()
+	e.generation = 1
+	e.current.Store(newDetectorSet(e.generation, e.detectors))
 
 	ctx.Logger().V(3).Info("loaded decoders", "count", len(e.decoders))
 	ctx.Logger().V(3).Info("loaded detectors",
@@ -427,9 +659,14 @@ func (e *Engine) detectorWorker(ctx context.Context) {
 	var wgDetect sync.WaitGroup
 
 	for originalChunk := range e.ChunksChan() {
-		for chunk := range sources.Chunker(originalChunk) {
+		e.logGeneration(ctx, originalChunk)
+		// Chunks are scanned with the detectors current when they start.
+		set := e.current.Load()
+		var chunkBytes uint64
+		for chunk := range sources.Chunker(originalChunk, e.chunkOptions...) {
 			matchedKeywords := make(map[string]struct{})
 			atomic.AddUint64(&e.metrics.BytesScanned, uint64(len(chunk.Data)))
+			chunkBytes += uint64(len(chunk.Data))
 			for _, decoder := range e.decoders {
 				var decoderType detectorspb.DecoderType
 				switch decoder.(type) {
@@ -451,11 +688,11 @@ func (e *Engine) detectorWorker(ctx context.Context) {
 				}
 
 				// build a map of all keywords that were matched in the chunk
-				for _, m := range e.prefilter.MatchString(strings.ToLower(string(decoded.Data))) {
+				for _, m := range set.prefilter.MatchString(strings.ToLower(string(decoded.Data))) {
 					matchedKeywords[strings.ToLower(m.MatchString())] = struct{}{}
 				}
 
-				for verify, detectorsSet := range e.detectors {
+				for verify, detectorsSet := range set.detectors {
 					for _, detector := range detectorsSet {
 						chunkContainsKeyword := false
 						for _, kw := range detector.Keywords() {
@@ -482,6 +719,7 @@ func (e *Engine) detectorWorker(ctx context.Context) {
 			}
 		}
 		atomic.AddUint64(&e.metrics.ChunksScanned, 1)
+		e.summary.addChunk(originalChunk, chunkBytes)
 	}
 	wgDetect.Wait()
 }
@@ -503,7 +741,11 @@ func (e *Engine) detectChunk(ctx context.Context, data detectableChunk) {
 
 	results, err := data.detector.FromData(ctx, data.chunk.Verify, data.chunk.Data)
 	if err != nil {
-		ctx.Logger().Error(err, "error scanning chunk")
+		var secrets []string
+		for _, res := range results {
+			secrets = append(secrets, string(res.Raw), string(res.RawV2))
+		}
+		ctx.Logger().Error(e.redactError(err, secrets...), "error scanning chunk")
 	}
 	if e.printAvgDetectorTime && len(results) > 0 {
 		elapsed := time.Since(start)
@@ -525,13 +767,14 @@ func (e *Engine) detectChunk(ctx context.Context, data detectableChunk) {
 	}
 
 	for _, res := range results {
-		e.processResult(data, res)
+		e.processResult(ctx, data, res)
 	}
 	data.wgDoneFn()
 }
 
-func (e *Engine) processResult(data detectableChunk, res detectors.Result) {
+func (e *Engine) processResult(ctx context.Context, data detectableChunk, res detectors.Result) {
 	ignoreLinePresent := false
+	var line int64
 	if SupportsLineNumbers(data.chunk.SourceType) {
 		copyChunk := data.chunk
 		copyMetaDataClone := proto.Clone(data.chunk.SourceMetadata)
@@ -540,14 +783,33 @@ func (e *Engine) processResult(data detectableChunk, res detectors.Result) {
 		}
 		fragStart, mdLine := FragmentFirstLine(&copyChunk)
 		ignoreLinePresent = SetResultLineNumber(&copyChunk, &res, fragStart, mdLine)
+		if mdLine != nil {
+			line = *mdLine
+		}
+		SetResultLink(copyChunk.SourceMetadata)
 		data.chunk = copyChunk
 	}
+
 	if ignoreLinePresent {
+		comment, _ := FragmentIgnoreComment(&data.chunk, &res)
+		switch {
+		case !comment.Applies(res.DetectorType):
+		case e.requireIgnoreReason && comment.Reason == "":
+			ctx.Logger().Info("ignore comment without a reason, reporting finding",
+				"detector", res.DetectorType.String(), "file", sources.MetadataFile(data.chunk.SourceMetadata), "line", line)
+		default:
+			e.summary.addSuppressed(&data.chunk, &res, line, SuppressedByComment, comment.Reason)
+			return
+		}
+	}
+	if ignoredByRules(data.chunk, res.DetectorType) {
+		e.summary.addSuppressed(&data.chunk, &res, line, SuppressedByIgnoreFile, "")
 		return
 	}
 
 	secret := detectors.CopyMetadata(&data.chunk, res)
 	secret.DecoderType = data.decoder
+	secret.Column, secret.Snippet = ResultContext(&data.chunk, &res, data.decoder, e.contextWindow)
 	e.results <- secret
 }
 
@@ -556,6 +818,14 @@ func (e *Engine) notifyResults(ctx context.Context) {
 		if e.onlyVerified && !r.Verified {
 			continue
 		}
+		decision := policy.Decision{Action: policy.ActionReport}
+		if p := e.policy.Load(); p != nil {
+			decision = p.Evaluate(ctx, &r)
+		}
+		if decision.Action == policy.ActionIgnore {
+			e.summary.addPolicySuppressed(&r, decision.Rule)
+			continue
+		}
 		atomic.AddUint32(&e.numFoundResults, 1)
 
 		key := fmt.Sprintf("%s%s%s%+v", r.DetectorType.String(), r.Raw, r.RawV2, r.SourceMetadata)
@@ -569,13 +839,71 @@ func (e *Engine) notifyResults(ctx context.Context) {
 		} else {
 			atomic.AddUint64(&e.metrics.UnverifiedSecretsFound, 1)
 		}
+		if decision.Action == policy.ActionFail {
+			atomic.AddUint32(&e.numPolicyFailures, 1)
+		}
+		e.summary.addResult(&r)
 
+		decision.Label(&r)
+		for _, labeler := range e.labelers {
+			labeler.Label(ctx, &r)
+		}
+		raw, rawV2 := string(r.Raw), string(r.RawV2)
+		e.redactMode.RedactResult(&r)
 		if err := e.printer.Print(ctx, &r); err != nil {
-			ctx.Logger().Error(err, "error printing result")
+			ctx.Logger().Error(e.redactError(err, raw, rawV2), "error printing result")
 		}
 	}
 }
 
+// redactError returns err with the secrets in its message redacted according
+// to the log redaction mode.
+func (e *Engine) redactError(err error, secrets ...string) error {
+	msg := err.Error()
+	if redacted := e.logRedactMode.RedactIn(msg, secrets...); redacted != msg {
+		return errors.New(redacted)
+	}
+	return err
+}
+
+// ignoredByRules reports whether the ignore file of the chunk's repository or
+// directory excludes findings of the detector in the chunk's file.
+func ignoredByRules(chunk sources.Chunk, detectorType detectorspb.DetectorType) bool {
+	if chunk.IgnoreRules == nil {
+		return false
+	}
+	file := sources.MetadataFile(chunk.SourceMetadata)
+	if file == "" {
+		return false
+	}
+	return chunk.IgnoreRules.Ignores(file, detectorType)
+}
+
+// metadataLine returns the line in the metadata of sources that have files, or
+// 0.
+func metadataLine(metadata *source_metadatapb.MetaData) int64 {
+	switch metadata := metadata.GetData().(type) {
+	case *source_metadatapb.MetaData_Git:
+		return metadata.Git.Line
+	case *source_metadatapb.MetaData_Github:
+		return metadata.Github.Line
+	case *source_metadatapb.MetaData_Gitlab:
+		return metadata.Gitlab.Line
+	case *source_metadatapb.MetaData_Bitbucket:
+		return metadata.Bitbucket.Line
+	case *source_metadatapb.MetaData_Gerrit:
+		return metadata.Gerrit.Line
+	case *source_metadatapb.MetaData_Filesystem:
+		return metadata.Filesystem.Line
+	case *source_metadatapb.MetaData_S3:
+		return metadata.S3.Line
+	case *source_metadatapb.MetaData_Gcs:
+		return metadata.Gcs.Line
+	default:
+		return 0
+	}
+}
+
 // SupportsLineNumbers determines if a line number can be found for a source type.
 func SupportsLineNumbers(sourceType sourcespb.SourceType) bool {
 	switch sourceType {
@@ -586,7 +914,9 @@ func SupportsLineNumbers(sourceType sourcespb.SourceType) bool {
 		sourcespb.SourceType_SOURCE_TYPE_GERRIT,
 		sourcespb.SourceType_SOURCE_TYPE_GITHUB_UNAUTHENTICATED_ORG,
 		sourcespb.SourceType_SOURCE_TYPE_PUBLIC_GIT,
-		sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM:
+		sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM,
+		sourcespb.SourceType_SOURCE_TYPE_S3,
+		sourcespb.SourceType_SOURCE_TYPE_GCS:
 		return true
 	default:
 		return false
@@ -595,20 +925,82 @@ func SupportsLineNumbers(sourceType sourcespb.SourceType) bool {
 
 // FragmentLineOffset sets the line number for a provided source chunk with a given detector result.
 func FragmentLineOffset(chunk *sources.Chunk, result *detectors.Result) (int64, bool) {
-	before, after, found := bytes.Cut(chunk.Data, result.Raw)
+	lineNumber, lines, found := fragmentResultLines(chunk, result)
 	if !found {
 		return 0, false
 	}
-	lineNumber := int64(bytes.Count(before, []byte("\n")))
 	// If the line contains the ignore tag, we should ignore the result.
-	endLine := bytes.Index(after, []byte("\n"))
+	return lineNumber, bytes.Contains(lines, []byte(ignore.Tag))
+}
+
+// FragmentIgnoreComment returns the inline ignore comment on the lines of the
+// result, if there is one.
+func FragmentIgnoreComment(chunk *sources.Chunk, result *detectors.Result) (ignore.Comment, bool) {
+	_, lines, found := fragmentResultLines(chunk, result)
+	if !found {
+		return ignore.Comment{}, false
+	}
+	return ignore.FindComment(lines)
+}
+
+// ResultContext returns the 1-based column of the result in its line, and
+// up to window bytes of the line on each side of it. The column is only known
+// for plain text, whose offsets are those of the source. Cut off parts of the
+// line are marked with an ellipsis.
+func ResultContext(chunk *sources.Chunk, result *detectors.Result, decoder detectorspb.DecoderType, window int) (int64, string) {
+	pos := bytes.Index(chunk.Data, result.Raw)
+	if pos < 0 || len(result.Raw) == 0 {
+		return 0, ""
+	}
+	lineStart := bytes.LastIndexByte(chunk.Data[:pos], '\n') + 1
+	lineEnd := len(chunk.Data)
+	if i := bytes.IndexByte(chunk.Data[pos:], '\n'); i >= 0 {
+		lineEnd = pos + i
+	}
+
+	var column int64
+	if decoder == detectorspb.DecoderType_PLAIN {
+		column = int64(pos-lineStart) + 1
+		if lineStart == 0 {
+			// The chunk may begin in the middle of the line.
+			column += chunk.Offset - chunk.LineStart
+		}
+	}
+	if window <= 0 {
+		return column, ""
+	}
+
+	start, end := pos-window, pos+len(result.Raw)+window
+	var prefix, suffix string
+	if start > lineStart || (lineStart == 0 && chunk.Offset > chunk.LineStart) {
+		prefix = "…"
+	}
+	if start < lineStart {
+		start = lineStart
+	}
+	if end < lineEnd {
+		suffix = "…"
+	} else {
+		end = lineEnd
+	}
+	line := strings.TrimRight(string(chunk.Data[start:end]), "\r")
+	return column, prefix + strings.ToValidUTF8(line, "") + suffix
+}
+
+// fragmentResultLines returns the offset of the first line of the result in
+// the chunk, and the complete lines the result spans.
+func fragmentResultLines(chunk *sources.Chunk, result *detectors.Result) (int64, []byte, bool) {
+	before, after, found := bytes.Cut(chunk.Data, result.Raw)
+	if !found {
+		return 0, nil, false
+	}
+	lineNumber := int64(bytes.Count(before, []byte("\n")))
+	startLine := bytes.LastIndexByte(before, '\n') + 1
+	endLine := bytes.IndexByte(after, '\n')
 	if endLine == -1 {
 		endLine = len(after)
 	}
-	if bytes.Contains(after[:endLine], []byte(ignoreTag)) {
-		return lineNumber, true
-	}
-	return lineNumber, false
+	return lineNumber, chunk.Data[startLine : len(before)+len(result.Raw)+endLine], true
 }
 
 // FragmentFirstLine returns the first line number of a fragment along with a pointer to the value to update in the
@@ -628,6 +1020,10 @@ func FragmentFirstLine(chunk *sources.Chunk) (int64, *int64) {
 		fragmentStart = &metadata.Gerrit.Line
 	case *source_metadatapb.MetaData_Filesystem:
 		fragmentStart = &metadata.Filesystem.Line
+	case *source_metadatapb.MetaData_S3:
+		fragmentStart = &metadata.S3.Line
+	case *source_metadatapb.MetaData_Gcs:
+		fragmentStart = &metadata.Gcs.Line
 	default:
 		return 0, nil
 	}
@@ -637,6 +1033,6 @@ func FragmentFirstLine(chunk *sources.Chunk) (int64, *int64) {
 // SetResultLineNumber sets the line number in the provided result.
 func SetResultLineNumber(chunk *sources.Chunk, result *detectors.Result, fragStart int64, mdLine *int64) bool {
 	offset, skip := FragmentLineOffset(chunk, result)
-	*mdLine = fragStart + offset
+	*mdLine = fragStart + chunk.LineOffset + offset
 	return skip
 }
@@ -4,9 +4,17 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/policy"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
@@ -84,6 +92,17 @@ func TestFragmentLineOffset(t *testing.T) {
 			expectedLine: 3,
 			ignore:       true,
 		},
+		{
+			name: "ignore before secret on same line",
+			chunk: &sources.Chunk{
+				Data: []byte("line1\n/* trufflehog:ignore */ secret here\nline3"),
+			},
+			result: &detectors.Result{
+				Raw: []byte("secret here"),
+			},
+			expectedLine: 1,
+			ignore:       true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -136,6 +155,119 @@ func BenchmarkFragmentLineOffsetEnd(b *testing.B) {
 	}
 }
 
+func TestResultContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		chunk   *sources.Chunk
+		decoder detectorspb.DecoderType
+		window  int
+		column  int64
+		snippet string
+	}{
+		{
+			name:    "short line",
+			chunk:   &sources.Chunk{Data: []byte("first\nkey = \"needle\"\r\nlast")},
+			decoder: detectorspb.DecoderType_PLAIN,
+			window:  16,
+			column:  8,
+			snippet: `key = "needle"`,
+		},
+		{
+			name:    "long line",
+			chunk:   &sources.Chunk{Data: []byte(`var a={b:1,c:2};var t="needle";var d={e:3,f:4}`)},
+			decoder: detectorspb.DecoderType_PLAIN,
+			window:  7,
+			column:  24,
+			snippet: `…var t="needle";var d…`,
+		},
+		{
+			name: "chunk starting mid-line",
+			chunk: &sources.Chunk{
+				Data:      []byte(`x";t="needle"`),
+				Offset:    10240,
+				LineStart: 10000,
+			},
+			decoder: detectorspb.DecoderType_PLAIN,
+			window:  16,
+			column:  247,
+			snippet: `…x";t="needle"`,
+		},
+		{
+			name:    "decoded",
+			chunk:   &sources.Chunk{Data: []byte("token needle")},
+			decoder: detectorspb.DecoderType_BASE64,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			column, snippet := ResultContext(tt.chunk, &detectors.Result{Raw: []byte("needle")}, tt.decoder, tt.window)
+			assert.Equal(t, tt.column, column)
+			assert.Equal(t, tt.snippet, snippet)
+		})
+	}
+}
+
+func TestLabelScope(t *testing.T) {
+	r := &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{File: "tests/fixtures.py"},
+			},
+		},
+		Result: detectors.Result{
+			Raw:         []byte("needle"),
+			DecoderType: detectorspb.DecoderType_PLAIN,
+			ExtraData:   map[string]string{"account": "1"},
+		},
+		Data: []byte("def test_login():\n    token = 'needle'\n"),
+	}
+	LabelScope(context.Background(), r)
+	assert.Equal(t, map[string]string{"account": "1", ScopeKey: "function test_login > variable token"}, r.ExtraData)
+
+	r.DecoderType = detectorspb.DecoderType_BASE64
+	r.ExtraData = nil
+	LabelScope(context.Background(), r)
+	assert.Nil(t, r.ExtraData)
+}
+
+func TestSetResultLink(t *testing.T) {
+	github := &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Github{
+			Github: &source_metadatapb.Github{
+				Repository: "https://github.com/org/repo.git",
+				Commit:     "abc",
+				File:       "main.go",
+				Line:       12,
+				Link:       "https://github.com/org/repo/blob/abc/main.go#L10",
+			},
+		},
+	}
+	SetResultLink(github)
+	assert.Equal(t, "https://github.com/org/repo/blob/abc/main.go#L12", github.GetGithub().GetLink())
+
+	git := &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Git{
+			Git: &source_metadatapb.Git{
+				Repository:  "git@gitlab.com:org/repo.git",
+				Commit:      "abc",
+				File:        "release.zip",
+				Line:        3,
+				ArchivePath: "config/app.yml",
+			},
+		},
+	}
+	SetResultLink(git)
+	assert.Equal(t, "https://gitlab.com/org/repo/-/blob/abc/release.zip", git.GetGit().GetLink())
+
+	local := &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Git{
+			Git: &source_metadatapb.Git{Repository: "/tmp/repo", Commit: "abc", File: "main.go", Line: 3},
+		},
+	}
+	SetResultLink(local)
+	assert.Empty(t, local.GetGit().GetLink())
+}
+
 // Test to make sure that DefaultDecoders always returns the UTF8 decoder first.
 // Technically a decoder test but we want this to run and fail in CI
 func TestDefaultDecoders(t *testing.T) {
@@ -171,3 +303,75 @@ func BenchmarkSupportsLineNumbersLoop(b *testing.B) {
 		_ = SupportsLineNumbers(sourceType)
 	}
 }
+
+func TestEngine_Reload(t *testing.T) {
+	ctx := context.Background()
+	var aws, slack detectors.Detector
+	for _, d := range DefaultDetectors() {
+		switch d.Type() {
+		case detectorspb.DetectorType_AWS:
+			aws = d
+		case detectorspb.DetectorType_Slack:
+			slack = d
+		}
+	}
+	e := &Engine{generation: 1}
+	e.current.Store(newDetectorSet(1, map[bool][]detectors.Detector{true: {aws}, false: {}}))
+
+	generation := e.ReloadDetectors(ctx,
+		WithDetectors(true, aws, slack),
+		WithFilterDetectors(func(d detectors.Detector) bool { return d.Type() != detectorspb.DetectorType_AWS }),
+		WithConcurrency(3),
+	)
+	assert.Equal(t, uint64(2), generation)
+	set := e.current.Load()
+	assert.Equal(t, uint64(2), set.generation)
+	assert.Equal(t, []detectors.Detector{slack}, set.detectors[true])
+	assert.Empty(t, set.detectors[false])
+	assert.NotEmpty(t, set.prefilter.MatchString("xoxb-token"))
+	assert.Empty(t, set.prefilter.MatchString("AKIA"))
+	assert.Zero(t, e.concurrency)
+
+	p, err := policy.New([]byte("rules: [{when: verified, action: fail}]"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), e.ReloadPolicy(ctx, p))
+	assert.Same(t, p, e.policy.Load())
+}
+
+func TestEngine_redactError(t *testing.T) {
+	err := fmt.Errorf("GET https://api.example.com/?key=s3cr3t-api-key-value: 500")
+
+	e := &Engine{}
+	assert.Same(t, err, e.redactError(err, "s3cr3t-api-key-value"))
+
+	e = &Engine{logRedactMode: output.RedactPartial}
+	assert.EqualError(t, e.redactError(err, "s3cr3t-api-key-value", ""),
+		"GET https://api.example.com/?key=s3cr********alue: 500")
+	assert.Same(t, err, e.redactError(err, "other-secret"))
+}
+
+func TestMetrics_ExceedsThresholds(t *testing.T) {
+	tests := []struct {
+		name       string
+		verified   uint64
+		unverified uint64
+		thresholds [2]int
+		want       bool
+	}{
+		{name: "disabled", verified: 5, unverified: 5, thresholds: [2]int{-1, -1}},
+		{name: "below verified", verified: 1, thresholds: [2]int{2, -1}},
+		{name: "at verified", verified: 2, thresholds: [2]int{2, -1}},
+		{name: "above verified", verified: 3, thresholds: [2]int{2, -1}, want: true},
+		{name: "zero verified", verified: 1, thresholds: [2]int{0, -1}, want: true},
+		{name: "below unverified", unverified: 9, thresholds: [2]int{-1, 10}},
+		{name: "at unverified", unverified: 10, thresholds: [2]int{-1, 10}},
+		{name: "above unverified", unverified: 11, thresholds: [2]int{-1, 10}, want: true},
+		{name: "only unverified above", verified: 1, unverified: 11, thresholds: [2]int{5, 10}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Metrics{VerifiedSecretsFound: tt.verified, UnverifiedSecretsFound: tt.unverified}
+			assert.Equal(t, tt.want, m.ExceedsThresholds(tt.thresholds[0], tt.thresholds[1]))
+		})
+	}
+}
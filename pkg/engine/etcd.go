@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/etcd"
+)
+
+// ScanEtcd scans the keys of an etcd cluster.
+func (e *Engine) ScanEtcd(ctx context.Context, c sources.EtcdConfig) error {
+	connection := &sourcespb.Etcd{
+		Endpoint:     c.Endpoint,
+		Username:     c.Username,
+		Password:     c.Password,
+		Prefix:       c.Prefix,
+		ExcludeGlobs: c.ExcludeGlobs,
+		Insecure:     c.Insecure,
+	}
+	for _, file := range []struct {
+		path, name string
+		pem        *string
+	}{
+		{c.CertFile, "client certificate", &connection.ClientCertificate},
+		{c.KeyFile, "client key", &connection.ClientKey},
+		{c.CACertFile, "CA certificate", &connection.CaCertificate},
+	} {
+		if file.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(file.path)
+		if err != nil {
+			return errors.WrapPrefix(err, "error reading "+file.name, 0)
+		}
+		*file.pem = string(data)
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - etcd", new(etcd.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			etcdSource := etcd.Source{}
+			if err := etcdSource.Init(ctx, "trufflehog - etcd", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			return &etcdSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -28,6 +28,9 @@ func (e *Engine) ScanFileSystem(ctx context.Context, c sources.FilesystemConfig)
 		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
 			fileSystemSource := filesystem.Source{}
 			fileSystemSource.WithFilter(c.Filter)
+			fileSystemSource.WithSkipIgnoreFile(e.skipIgnoreFiles)
+			fileSystemSource.WithBrowserStorage(c.BrowserStorage)
+			fileSystemSource.WithArchiveOptions(c.ArchiveOptions)
 			if err := fileSystemSource.Init(ctx, "trufflehog - filesystem", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
 				return nil, err
 			}
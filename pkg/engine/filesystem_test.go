@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"archive/zip"
+	"bytes"
+	gocontext "context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// tokenDetector finds the tokens of a made-up format, without verifying them.
+type tokenDetector struct{}
+
+var tokenPattern = regexp.MustCompile(`tok_[a-z0-9]{16}`)
+
+func (tokenDetector) FromData(_ gocontext.Context, _ bool, data []byte) ([]detectors.Result, error) {
+	var results []detectors.Result
+	for _, match := range tokenPattern.FindAll(data, -1) {
+		results = append(results, detectors.Result{DetectorType: detectorspb.DetectorType_Generic, Raw: match})
+	}
+	return results, nil
+}
+
+func (tokenDetector) Keywords() []string { return []string{"tok_"} }
+
+func (tokenDetector) Type() detectorspb.DetectorType { return detectorspb.DetectorType_Generic }
+
+// pathPrinter records the files results are found in.
+type pathPrinter struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (p *pathPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paths = append(p.paths, r.SourceMetadata.GetFilesystem().GetFile())
+	return nil
+}
+
+func TestEngine_ArchiveOptionsPerSource(t *testing.T) {
+	ctx := context.Background()
+
+	// Both zips hold a token past the first KiB of a file.
+	content := append(bytes.Repeat([]byte("x"), 4096), " tok_0123456789abcdef\n"...)
+	var dirs []string
+	for _, name := range []string{"small", "large"} {
+		dir := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.Mkdir(dir, 0o755))
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		f, err := w.Create("app.log")
+		require.NoError(t, err)
+		_, err = f.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "logs.zip"), buf.Bytes(), 0o644))
+		dirs = append(dirs, dir)
+	}
+
+	printer := new(pathPrinter)
+	e, err := Start(ctx,
+		WithConcurrency(1),
+		WithDetectors(false, tokenDetector{}),
+		WithPrinter(printer),
+	)
+	require.NoError(t, err)
+
+	// Only the first KiB of the files of the first source's archives is
+	// scanned, while the second source keeps the default limit.
+	require.NoError(t, e.ScanFileSystem(ctx, sources.FilesystemConfig{
+		Paths:          dirs[:1],
+		Filter:         common.FilterEmpty(),
+		ArchiveOptions: sources.ArchiveOptions{MaxSize: 1024},
+	}))
+	require.NoError(t, e.ScanFileSystem(ctx, sources.FilesystemConfig{
+		Paths:  dirs[1:],
+		Filter: common.FilterEmpty(),
+	}))
+	require.NoError(t, e.Finish(ctx))
+
+	assert.Equal(t, []string{filepath.Join(dirs[1], "logs.zip")}, printer.paths)
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/ftp"
+)
+
+// ScanFTP scans a directory of an SFTP, FTP or FTPS server.
+func (e *Engine) ScanFTP(ctx context.Context, c sources.FTPConfig) error {
+	connection := &sourcespb.FTP{
+		Url:                  c.URL,
+		Username:             c.Username,
+		Password:             c.Password,
+		PrivateKeyPassphrase: c.PrivateKeyPassphrase,
+		KnownHosts:           c.KnownHosts,
+		Insecure:             c.Insecure,
+		IncludeGlobs:         c.IncludeGlobs,
+		ExcludeGlobs:         c.ExcludeGlobs,
+		MaxConnections:       int64(c.MaxConnections),
+		MaxObjectSize:        c.MaxObjectSize,
+	}
+	if c.PrivateKeyFile != "" {
+		key, err := os.ReadFile(c.PrivateKeyFile)
+		if err != nil {
+			return errors.WrapPrefix(err, "error reading private key", 0)
+		}
+		connection.PrivateKey = string(key)
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - ftp", new(ftp.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			ftpSource := ftp.Source{}
+			if err := ftpSource.Init(ctx, "trufflehog - ftp", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			ftpSource.WithArchiveOptions(c.ArchiveOptions)
+			return &ftpSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
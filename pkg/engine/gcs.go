@@ -14,10 +14,15 @@ import (
 
 // ScanGCS with the provided options.
 func (e *Engine) ScanGCS(ctx context.Context, c sources.GCSConfig) error {
-	// Project ID is required if using any authenticated access.
-	if c.ProjectID == "" && !c.WithoutAuth {
+	// Project ID is required if using any authenticated access, unless the
+	// projects or the organization to scan are given.
+	multiProject := len(c.ProjectIDs) > 0 || c.AllProjects || c.OrganizationID != ""
+	if c.ProjectID == "" && !c.WithoutAuth && !multiProject {
 		return fmt.Errorf("project ID is required")
 	}
+	if c.WithoutAuth && (c.AllProjects || c.OrganizationID != "") {
+		return fmt.Errorf("listing the projects or the organization requires authentication")
+	}
 
 	// If using unauthenticated access, the project ID is not used.
 	if c.ProjectID != "" && c.WithoutAuth {
@@ -26,11 +31,17 @@ func (e *Engine) ScanGCS(ctx context.Context, c sources.GCSConfig) error {
 	}
 
 	connection := &sourcespb.GCS{
-		ProjectId:      c.ProjectID,
-		IncludeBuckets: c.IncludeBuckets,
-		ExcludeBuckets: c.ExcludeBuckets,
-		IncludeObjects: c.IncludeObjects,
-		ExcludeObjects: c.ExcludeObjects,
+		ProjectId:          c.ProjectID,
+		IncludeBuckets:     c.IncludeBuckets,
+		ExcludeBuckets:     c.ExcludeBuckets,
+		IncludeObjects:     c.IncludeObjects,
+		ExcludeObjects:     c.ExcludeObjects,
+		MaxObjectSize:      c.MaxObjectSize,
+		ProjectIds:         c.ProjectIDs,
+		AllProjects:        c.AllProjects,
+		OrganizationId:     c.OrganizationID,
+		IncludeGenerations: c.IncludeGenerations,
+		StatePath:          c.StatePath,
 	}
 
 	// Make sure only one auth method is selected.
@@ -50,6 +61,7 @@ func (e *Engine) ScanGCS(ctx context.Context, c sources.GCSConfig) error {
 			if err := gcsSource.Init(ctx, "trufflehog - gcs", jobID, sourceID, true, &conn, int(c.Concurrency)); err != nil {
 				return nil, err
 			}
+			gcsSource.WithArchiveOptions(c.ArchiveOptions)
 			return &gcsSource, nil
 		})
 	if err != nil {
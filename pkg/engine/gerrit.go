@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/gerrit"
+)
+
+// ScanGerrit scans the patch sets of the changes of a Gerrit server and the
+// comments of their reviews.
+func (e *Engine) ScanGerrit(ctx context.Context, c sources.GerritConfig) error {
+	connection := &sourcespb.Gerrit{
+		Endpoint: c.Endpoint,
+		Projects: c.Projects,
+		Query:    c.Query,
+	}
+	if c.Username != "" {
+		connection.Credential = &sourcespb.Gerrit_BasicAuth{
+			BasicAuth: &credentialspb.BasicAuth{Username: c.Username, Password: c.Password},
+		}
+	} else {
+		connection.Credential = &sourcespb.Gerrit_Unauthenticated{Unauthenticated: &credentialspb.Unauthenticated{}}
+	}
+
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - gerrit", new(gerrit.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			gerritSource := gerrit.Source{}
+			if err := gerritSource.Init(ctx, "trufflehog - gerrit", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			return &gerritSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -19,6 +19,8 @@ func (e *Engine) ScanGit(ctx context.Context, c sources.GitConfig) error {
 	opts := []git.ScanOption{
 		git.ScanOptionFilter(c.Filter),
 		git.ScanOptionLogOptions(logOptions),
+		git.ScanOptionSkipIgnoreFile(e.skipIgnoreFiles),
+		git.ScanOptionArchiveOptions(c.ArchiveOptions),
 	}
 
 	if c.MaxDepth != 0 {
@@ -36,6 +38,15 @@ func (e *Engine) ScanGit(ctx context.Context, c sources.GitConfig) error {
 	if c.Bare {
 		opts = append(opts, git.ScanOptionBare(c.Bare))
 	}
+	if c.StagedOnly {
+		opts = append(opts, git.ScanOptionStagedOnly(c.StagedOnly))
+	}
+	if len(c.Revisions) > 0 {
+		opts = append(opts, git.ScanOptionRevisions(c.Revisions))
+	}
+	if c.DiffRange != "" {
+		opts = append(opts, git.ScanOptionDiffRange(c.DiffRange))
+	}
 	scanOptions := git.NewScanOptions(opts...)
 
 	connection := &sourcespb.Git{
@@ -41,6 +41,8 @@ func (e *Engine) ScanGitHub(ctx context.Context, c sources.GithubConfig) error {
 	opts := []git.ScanOption{
 		git.ScanOptionFilter(c.Filter),
 		git.ScanOptionLogOptions(logOptions),
+		git.ScanOptionSkipIgnoreFile(e.skipIgnoreFiles),
+		git.ScanOptionArchiveOptions(c.ArchiveOptions),
 	}
 	scanOptions := git.NewScanOptions(opts...)
 
@@ -21,6 +21,8 @@ func (e *Engine) ScanGitLab(ctx context.Context, c sources.GitlabConfig) error {
 	opts := []git.ScanOption{
 		git.ScanOptionFilter(c.Filter),
 		git.ScanOptionLogOptions(logOptions),
+		git.ScanOptionSkipIgnoreFile(e.skipIgnoreFiles),
+		git.ScanOptionArchiveOptions(c.ArchiveOptions),
 	}
 	scanOptions := git.NewScanOptions(opts...)
 
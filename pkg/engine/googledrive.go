@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/googledrive"
+)
+
+// ScanGoogleDrive scans the files of Google Drive and of shared drives.
+func (e *Engine) ScanGoogleDrive(ctx context.Context, c sources.GoogleDriveConfig) error {
+	connection := &sourcespb.GoogleDrive{
+		ImpersonateUser: c.ImpersonateUser,
+		DriveIds:        c.DriveIDs,
+		MaxObjectSize:   c.MaxObjectSize,
+		StatePath:       c.StatePath,
+	}
+	switch {
+	case c.ServiceAccountFile != "":
+		connection.Credential = &sourcespb.GoogleDrive_ServiceAccountFile{ServiceAccountFile: c.ServiceAccountFile}
+	case c.RefreshToken != "":
+		connection.Credential = &sourcespb.GoogleDrive_Oauth{Oauth: &credentialspb.Oauth2{
+			ClientId:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			RefreshToken: c.RefreshToken,
+		}}
+	case c.UseADC:
+		connection.Credential = &sourcespb.GoogleDrive_Adc{Adc: &credentialspb.CloudEnvironment{}}
+	default:
+		return fmt.Errorf("a service account file, an OAuth refresh token or application default credentials are required")
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - google drive", new(googledrive.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			driveSource := googledrive.Source{}
+			if err := driveSource.Init(ctx, "trufflehog - google drive", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			driveSource.WithArchiveOptions(c.ArchiveOptions)
+			return &driveSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/helpdesk"
+)
+
+// ScanHelpdesk scans the descriptions, comments and attachments of the
+// tickets of Zendesk or Freshdesk.
+func (e *Engine) ScanHelpdesk(ctx context.Context, c sources.HelpdeskConfig) error {
+	connection := &sourcespb.Helpdesk{
+		StatePath:       c.StatePath,
+		SkipAttachments: c.SkipAttachments,
+	}
+	if !c.UpdatedSince.IsZero() {
+		connection.UpdatedSince = timestamppb.New(c.UpdatedSince)
+	}
+
+	switch c.Platform {
+	case "zendesk":
+		zendesk := &sourcespb.Zendesk{Endpoint: c.Endpoint, Email: c.Email}
+		if c.OAuthToken != "" {
+			zendesk.Credential = &sourcespb.Zendesk_OauthToken{OauthToken: c.OAuthToken}
+		} else {
+			zendesk.Credential = &sourcespb.Zendesk_ApiToken{ApiToken: c.APIToken}
+		}
+		connection.Platform = &sourcespb.Helpdesk_Zendesk{Zendesk: zendesk}
+	case "freshdesk":
+		connection.Platform = &sourcespb.Helpdesk_Freshdesk{Freshdesk: &sourcespb.Freshdesk{Endpoint: c.Endpoint, ApiKey: c.APIKey}}
+	default:
+		return fmt.Errorf("unknown helpdesk %q", c.Platform)
+	}
+
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - helpdesk", new(helpdesk.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			helpdeskSource := helpdesk.Source{}
+			if err := helpdeskSource.Init(ctx, "trufflehog - helpdesk", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			helpdeskSource.WithArchiveOptions(c.ArchiveOptions)
+			return &helpdeskSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/imap"
+)
+
+// ScanIMAP scans the messages of the folders of an IMAP mailbox.
+func (e *Engine) ScanIMAP(ctx context.Context, c sources.IMAPConfig) error {
+	connection := &sourcespb.IMAP{
+		Address:        c.Address,
+		Username:       c.Username,
+		Folders:        c.Folders,
+		ExcludeFolders: c.ExcludeFolders,
+		Starttls:       c.StartTLS,
+		Plaintext:      c.Plaintext,
+		Insecure:       c.Insecure,
+		MaxMessageSize: c.MaxMessageSize,
+	}
+	if c.OAuth2Token != "" {
+		connection.Credential = &sourcespb.IMAP_Oauth2Token{Oauth2Token: c.OAuth2Token}
+	} else {
+		connection.Credential = &sourcespb.IMAP_Password{Password: c.Password}
+	}
+	if !c.Since.IsZero() {
+		connection.Since = timestamppb.New(c.Since)
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - imap", new(imap.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			imapSource := imap.Source{}
+			if err := imapSource.Init(ctx, "trufflehog - imap", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			imapSource.WithArchiveOptions(c.ArchiveOptions)
+			return &imapSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/jira"
+)
+
+// ScanJira scans the descriptions, comments and attachments of Jira issues.
+func (e *Engine) ScanJira(ctx context.Context, c sources.JiraConfig) error {
+	connection := &sourcespb.JIRA{
+		Endpoint:        c.Endpoint,
+		Credential:      &sourcespb.JIRA_Unauthenticated{},
+		Projects:        c.Projects,
+		IgnoreProjects:  c.ExcludeProjects,
+		Jql:             c.JQL,
+		SkipAttachments: c.SkipAttachments,
+	}
+	switch {
+	case c.Username != "":
+		connection.Credential = &sourcespb.JIRA_BasicAuth{
+			BasicAuth: &credentialspb.BasicAuth{Username: c.Username, Password: c.Token},
+		}
+	case c.Token != "":
+		connection.Credential = &sourcespb.JIRA_Token{Token: c.Token}
+	}
+	if !c.UpdatedSince.IsZero() {
+		connection.UpdatedSince = timestamppb.New(c.UpdatedSince)
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - jira", new(jira.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			jiraSource := jira.Source{}
+			if err := jiraSource.Init(ctx, "trufflehog - jira", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			jiraSource.WithArchiveOptions(c.ArchiveOptions)
+			return &jiraSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/kafka"
+)
+
+// ScanKafka scans the messages of the topics of a Kafka cluster.
+func (e *Engine) ScanKafka(ctx context.Context, c sources.KafkaConfig) error {
+	connection := &sourcespb.Kafka{
+		Brokers:                c.Brokers,
+		Topics:                 c.Topics,
+		StartOffset:            c.StartOffset,
+		SaslMechanism:          c.SASLMechanism,
+		Username:               c.Username,
+		Password:               c.Password,
+		Tls:                    c.TLS,
+		Insecure:               c.Insecure,
+		Encoding:               c.Encoding,
+		SchemaRegistryUrl:      c.SchemaRegistryURL,
+		SchemaRegistryUsername: c.SchemaRegistryUsername,
+		SchemaRegistryPassword: c.SchemaRegistryPassword,
+	}
+	if !c.Since.IsZero() {
+		connection.Since = timestamppb.New(c.Since)
+	}
+	if c.CACertFile != "" {
+		ca, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return errors.WrapPrefix(err, "error reading CA certificate", 0)
+		}
+		connection.CaCertificate = string(ca)
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - kafka", new(kafka.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			kafkaSource := kafka.Source{}
+			if err := kafkaSource.Init(ctx, "trufflehog - kafka", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			return &kafkaSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
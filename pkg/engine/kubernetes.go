@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/kubernetes"
+)
+
+// ScanKubernetes scans the objects of a Kubernetes cluster.
+func (e *Engine) ScanKubernetes(ctx context.Context, c sources.KubernetesConfig) error {
+	connection := &sourcespb.Kubernetes{
+		Kubeconfig:          c.Kubeconfig,
+		Context:             c.Context,
+		InCluster:           c.InCluster,
+		Namespaces:          c.Namespaces,
+		ExcludeNamespaces:   c.ExcludeNamespaces,
+		SkipSecrets:         c.SkipSecrets,
+		SkipConfigMaps:      c.SkipConfigMaps,
+		SkipPods:            c.SkipPods,
+		SkipCustomResources: c.SkipCustomResources,
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - kubernetes", new(kubernetes.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			kubernetesSource := kubernetes.Source{}
+			if err := kubernetesSource.Init(ctx, "trufflehog - kubernetes", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			return &kubernetesSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/mongodb"
+)
+
+// ScanMongoDB scans the documents of the collections of a MongoDB deployment.
+func (e *Engine) ScanMongoDB(ctx context.Context, c sources.MongoDBConfig) error {
+	connection := &sourcespb.MongoDB{
+		Uri:          c.URI,
+		Databases:    c.Databases,
+		IncludeGlobs: c.IncludeGlobs,
+		ExcludeGlobs: c.ExcludeGlobs,
+		BatchSize:    c.BatchSize,
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - mongodb", new(mongodb.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			mongodbSource := mongodb.Source{}
+			if err := mongodbSource.Init(ctx, "trufflehog - mongodb", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			return &mongodbSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/nexus"
+)
+
+// ScanNexus scans the assets of the hosted repositories of Nexus
+// Repository.
+func (e *Engine) ScanNexus(ctx context.Context, c sources.NexusConfig) error {
+	connection := &sourcespb.Nexus{
+		Endpoint:      c.Endpoint,
+		Repositories:  c.Repositories,
+		IncludePaths:  c.IncludePaths,
+		ExcludePaths:  c.ExcludePaths,
+		MaxObjectSize: c.MaxObjectSize,
+		StatePath:     c.StatePath,
+		Insecure:      c.Insecure,
+	}
+	if c.Username != "" {
+		connection.BasicAuth = &credentialspb.BasicAuth{Username: c.Username, Password: c.Password}
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - nexus", new(nexus.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			nexusSource := nexus.Source{}
+			if err := nexusSource.Init(ctx, "trufflehog - nexus", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			nexusSource.WithArchiveOptions(c.ArchiveOptions)
+			return &nexusSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/notion"
+)
+
+// ScanNotion scans the pages and databases of a Notion workspace shared
+// with an integration.
+func (e *Engine) ScanNotion(ctx context.Context, c sources.NotionConfig) error {
+	connection := &sourcespb.Notion{
+		Token:     c.Token,
+		SkipFiles: c.SkipFiles,
+	}
+
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - notion", new(notion.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			notionSource := notion.Source{}
+			if err := notionSource.Init(ctx, "trufflehog - notion", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			notionSource.WithArchiveOptions(c.ArchiveOptions)
+			return &notionSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
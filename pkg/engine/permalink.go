@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"github.com/trufflesecurity/trufflehog/v3/pkg/giturl"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// SetResultLink points the link in the metadata of a result found in a
+// repository on GitHub, GitLab, Bitbucket or Gerrit at the commit, file and
+// line of the result, generating it if the source did not. Sources link to
+// the line a chunk starts at, which is rarely the line of the result. Results
+// in archives link to the archive, as their line is of the file in it.
+func SetResultLink(metadata *source_metadatapb.MetaData) {
+	switch metadata := metadata.GetData().(type) {
+	case *source_metadatapb.MetaData_Git:
+		md := metadata.Git
+		md.Link = permalink(md.Link, md.Repository, md.Commit, md.File, md.ArchivePath, md.Line)
+	case *source_metadatapb.MetaData_Github:
+		md := metadata.Github
+		md.Link = permalink(md.Link, md.Repository, md.Commit, md.File, md.ArchivePath, md.Line)
+	case *source_metadatapb.MetaData_Gitlab:
+		md := metadata.Gitlab
+		md.Link = permalink(md.Link, md.Repository, md.Commit, md.File, md.ArchivePath, md.Line)
+	case *source_metadatapb.MetaData_Bitbucket:
+		md := metadata.Bitbucket
+		md.Link = permalink(md.Link, md.Repository, md.Commit, md.File, md.ArchivePath, md.Line)
+	case *source_metadatapb.MetaData_Gerrit:
+		md := metadata.Gerrit
+		md.Link = permalink(md.Link, md.Project, md.Commit, md.File, md.ArchivePath, md.Line)
+	}
+}
+
+func permalink(link, repository, commit, file, archivePath string, line int64) string {
+	if archivePath != "" {
+		line = 0
+	}
+	if link == "" {
+		return giturl.GenerateLink(repository, commit, file, line)
+	}
+	return giturl.UpdateLinkLineNumber(link, line)
+}
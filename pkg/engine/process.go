@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/process"
+)
+
+// ScanProcesses scans the environment variables and command lines of the
+// running processes.
+func (e *Engine) ScanProcesses(ctx context.Context, c sources.ProcessConfig) error {
+	connection := &sourcespb.Process{
+		Pids:            c.Pids,
+		Names:           c.Names,
+		SkipCommandLine: c.SkipCommandLine,
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - process", new(process.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			processSource := process.Source{}
+			if err := processSource.Init(ctx, "trufflehog - process", jobID, sourceID, true, &conn, 1); err != nil {
+				return nil, err
+			}
+			return &processSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/redis"
+)
+
+// ScanRedis scans the values of the keys of the databases of a Redis server.
+func (e *Engine) ScanRedis(ctx context.Context, c sources.RedisConfig) error {
+	connection := &sourcespb.Redis{
+		Uri:          c.URI,
+		Databases:    c.Databases,
+		KeyPatterns:  c.KeyPatterns,
+		ExcludeGlobs: c.ExcludeGlobs,
+		Insecure:     c.Insecure,
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - redis", new(redis.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			redisSource := redis.Source{}
+			if err := redisSource.Init(ctx, "trufflehog - redis", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			return &redisSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
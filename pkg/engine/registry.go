@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/registry"
+)
+
+// ScanRegistry scans the images of the repositories of a container
+// registry.
+func (e *Engine) ScanRegistry(ctx context.Context, c sources.RegistryConfig) error {
+	connection := &sourcespb.Registry{
+		Registry:            c.Registry,
+		Repositories:        c.Repositories,
+		ExcludeRepositories: c.ExcludeRepositories,
+		Tags:                c.Tags,
+		MaxObjectSize:       c.MaxObjectSize,
+		StatePath:           c.StatePath,
+		Insecure:            c.Insecure,
+	}
+	switch {
+	case c.BearerToken != "":
+		connection.Credential = &sourcespb.Registry_BearerToken{BearerToken: c.BearerToken}
+	case c.Username != "":
+		connection.Credential = &sourcespb.Registry_BasicAuth{
+			BasicAuth: &credentialspb.BasicAuth{Username: c.Username, Password: c.Password},
+		}
+	case c.CloudCredentials:
+		connection.Credential = &sourcespb.Registry_CloudCredentials{CloudCredentials: true}
+	case c.DockerKeychain:
+		connection.Credential = &sourcespb.Registry_DockerKeychain{DockerKeychain: true}
+	default:
+		connection.Credential = &sourcespb.Registry_Unauthenticated{}
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - registry", new(registry.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			registrySource := registry.Source{}
+			if err := registrySource.Init(ctx, "trufflehog - registry", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			registrySource.WithArchiveOptions(c.ArchiveOptions)
+			return &registrySource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
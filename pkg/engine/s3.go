@@ -46,6 +46,15 @@ func (e *Engine) ScanS3(ctx context.Context, c sources.S3Config) error {
 	if len(c.Buckets) > 0 {
 		connection.Buckets = c.Buckets
 	}
+	connection.IncludeVersions = c.IncludeVersions
+	connection.RequesterPays = c.RequesterPays
+	for _, chain := range c.RoleChains {
+		roles := make([]*sourcespb.AWSRole, 0, len(chain))
+		for _, role := range chain {
+			roles = append(roles, &sourcespb.AWSRole{RoleArn: role.ARN, ExternalId: role.ExternalID})
+		}
+		connection.RoleChains = append(connection.RoleChains, &sourcespb.AWSRoleChain{Roles: roles})
+	}
 	var conn anypb.Any
 	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
 	if err != nil {
@@ -59,6 +68,7 @@ func (e *Engine) ScanS3(ctx context.Context, c sources.S3Config) error {
 			if err := s3Source.Init(ctx, "trufflehog - s3", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
 				return nil, err
 			}
+			s3Source.WithArchiveOptions(c.ArchiveOptions)
 			return &s3Source, nil
 		})
 	if err != nil {
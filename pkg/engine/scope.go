@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"bytes"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/codecontext"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// ScopeKey is the ExtraData key of the scope of source code a secret was found
+// in.
+const ScopeKey = "scope"
+
+// LabelScope labels results found in Go, JavaScript, TypeScript, Python, Java
+// and YAML files with the function, class, variable or key they were found
+// in, e.g. "function TestLogin > variable token". It is a ResultLabelerFunc.
+func LabelScope(_ context.Context, r *detectors.ResultWithMetadata) {
+	if r.DecoderType != detectorspb.DecoderType_PLAIN || len(r.Raw) == 0 {
+		return
+	}
+	file := sources.MetadataFile(r.SourceMetadata)
+	if archivePath := sources.ArchivePath(r.SourceMetadata); archivePath != "" {
+		file = archivePath
+	}
+	lang := codecontext.LanguageOf(file)
+	if lang == codecontext.Unknown {
+		return
+	}
+	pos := bytes.Index(r.Data, r.Raw)
+	if pos < 0 {
+		return
+	}
+	scopes := codecontext.Enclosing(lang, r.Data, pos)
+	if len(scopes) == 0 {
+		return
+	}
+
+	extra := make(map[string]string, len(r.ExtraData)+1)
+	for k, v := range r.ExtraData {
+		extra[k] = v
+	}
+	extra[ScopeKey] = codecontext.Format(scopes)
+	r.ExtraData = extra
+}
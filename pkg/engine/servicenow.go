@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/servicenow"
+)
+
+// ScanServiceNow scans the records of the tables of a ServiceNow instance,
+// their journal entries and their attachments.
+func (e *Engine) ScanServiceNow(ctx context.Context, c sources.ServiceNowConfig) error {
+	connection := &sourcespb.ServiceNow{
+		Endpoint:        c.Endpoint,
+		Tables:          c.Tables,
+		Query:           c.Query,
+		SkipAttachments: c.SkipAttachments,
+	}
+	if c.OAuthToken != "" {
+		connection.Credential = &sourcespb.ServiceNow_OauthToken{OauthToken: c.OAuthToken}
+	} else {
+		connection.Credential = &sourcespb.ServiceNow_BasicAuth{
+			BasicAuth: &credentialspb.BasicAuth{Username: c.Username, Password: c.Password},
+		}
+	}
+	if !c.UpdatedSince.IsZero() {
+		connection.UpdatedSince = timestamppb.New(c.UpdatedSince)
+	}
+
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - servicenow", new(servicenow.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			servicenowSource := servicenow.Source{}
+			if err := servicenowSource.Init(ctx, "trufflehog - servicenow", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			servicenowSource.WithArchiveOptions(c.ArchiveOptions)
+			return &servicenowSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
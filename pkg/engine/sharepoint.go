@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/sharepoint"
+)
+
+// ScanSharePoint scans the document libraries and lists of SharePoint sites
+// and OneDrive accounts.
+func (e *Engine) ScanSharePoint(ctx context.Context, c sources.SharePointConfig) error {
+	connection := &sourcespb.Sharepoint{
+		Credential:    &sourcespb.Sharepoint_Token{Token: c.Token},
+		SiteUrl:       c.SiteURL,
+		Sites:         c.Sites,
+		IgnoreSites:   c.ExcludeSites,
+		Users:         c.Users,
+		SkipOnedrive:  c.SkipOneDrive,
+		SkipLists:     c.SkipLists,
+		MaxObjectSize: c.MaxObjectSize,
+		StatePath:     c.StatePath,
+	}
+	if c.ClientID != "" {
+		connection.Credential = &sourcespb.Sharepoint_Authenticated{
+			Authenticated: &credentialspb.ClientCredentials{TenantId: c.TenantID, ClientId: c.ClientID, ClientSecret: c.ClientSecret},
+		}
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - sharepoint", new(sharepoint.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			sharePointSource := sharepoint.Source{}
+			if err := sharePointSource.Init(ctx, "trufflehog - sharepoint", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			sharePointSource.WithArchiveOptions(c.ArchiveOptions)
+			return &sharePointSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/slack"
+)
+
+// ScanSlack scans the messages, threads and files of the channels of a Slack
+// workspace, or of its export.
+func (e *Engine) ScanSlack(ctx context.Context, c sources.SlackConfig) error {
+	connection := &sourcespb.Slack{
+		Endpoint:   c.Endpoint,
+		Credential: &sourcespb.Slack_Token{Token: c.Token},
+		Channels:   c.Channels,
+		IgnoreList: c.ExcludeChannels,
+		ExportPath: c.ExportPath,
+		SkipFiles:  c.SkipFiles,
+	}
+	if !c.Since.IsZero() {
+		connection.Since = timestamppb.New(c.Since)
+	}
+	if !c.Until.IsZero() {
+		connection.Until = timestamppb.New(c.Until)
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - slack", new(slack.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			slackSource := slack.Source{}
+			if err := slackSource.Init(ctx, "trufflehog - slack", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			slackSource.WithArchiveOptions(c.ArchiveOptions)
+			return &slackSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
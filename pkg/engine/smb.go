@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/smb"
+)
+
+// ScanSMB scans the shares of an SMB server.
+func (e *Engine) ScanSMB(ctx context.Context, c sources.SMBConfig) error {
+	connection := &sourcespb.SMB{
+		Host:          c.Host,
+		Username:      c.Username,
+		Password:      c.Password,
+		NtlmHash:      c.NTLMHash,
+		Domain:        c.Domain,
+		Shares:        c.Shares,
+		ExcludeShares: c.ExcludeShares,
+		IncludeGlobs:  c.IncludeGlobs,
+		ExcludeGlobs:  c.ExcludeGlobs,
+		MaxObjectSize: c.MaxObjectSize,
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - smb", new(smb.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			smbSource := smb.Source{}
+			if err := smbSource.Init(ctx, "trufflehog - smb", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			smbSource.WithArchiveOptions(c.ArchiveOptions)
+			return &smbSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
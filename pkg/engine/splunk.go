@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/splunk"
+)
+
+// ScanSplunk scans the events that a saved or ad-hoc SPL search of Splunk
+// finds.
+func (e *Engine) ScanSplunk(ctx context.Context, c sources.SplunkConfig) error {
+	connection := &sourcespb.Splunk{
+		Endpoint:    c.Endpoint,
+		Username:    c.Username,
+		Password:    c.Password,
+		Token:       c.Token,
+		Search:      c.Search,
+		SavedSearch: c.SavedSearch,
+		App:         c.App,
+		Owner:       c.Owner,
+		Earliest:    c.Earliest,
+		Latest:      c.Latest,
+		Insecure:    c.Insecure,
+	}
+	if c.CACertFile != "" {
+		ca, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return errors.WrapPrefix(err, "error reading CA certificate", 0)
+		}
+		connection.CaCertificate = string(ca)
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - splunk", new(splunk.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			splunkSource := splunk.Source{}
+			if err := splunkSource.Init(ctx, "trufflehog - splunk", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			return &splunkSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
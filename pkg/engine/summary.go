@@ -0,0 +1,286 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// maxSkippedExamples is the number of skipped items kept per source and reason
+// to include in the summary. All skipped items are counted regardless.
+const maxSkippedExamples = 10
+
+// SuppressionMethod describes how a finding was suppressed.
+type SuppressionMethod string
+
+const (
+	// SuppressedByComment is used for findings on lines with an inline
+	// trufflehog:ignore comment.
+	SuppressedByComment SuppressionMethod = "comment"
+	// SuppressedByIgnoreFile is used for findings excluded by a
+	// .trufflehogignore file.
+	SuppressedByIgnoreFile SuppressionMethod = "ignore_file"
+	// SuppressedByPolicy is used for findings ignored by a policy rule.
+	SuppressedByPolicy SuppressionMethod = "policy"
+)
+
+// Summary is a structured report of what a scan covered and found. It is
+// intended to be emitted once the engine has finished.
+type Summary struct {
+	ScanDuration           time.Duration
+	BytesScanned           uint64
+	ChunksScanned          uint64
+	VerifiedSecretsFound   uint64
+	UnverifiedSecretsFound uint64
+	// Sources contains per-source coverage statistics, sorted by name.
+	Sources []SourceSummary
+	// Detectors contains findings per detector, sorted by name.
+	Detectors []DetectorSummary
+	// Suppressed lists findings that were not reported because of an ignore
+	// comment, ignore file or policy, so suppressions can be audited.
+	Suppressed []Suppression
+}
+
+// Suppression describes a finding that was not reported.
+type Suppression struct {
+	SourceName string
+	Detector   string
+	// File and Line locate the finding for sources that have files.
+	File   string
+	Line   int64
+	Method SuppressionMethod
+	// Reason is the reason given in the ignore comment, or the name of the
+	// policy rule.
+	Reason string
+}
+
+// SourceSummary contains coverage statistics for a single source.
+type SourceSummary struct {
+	Name                   string
+	Type                   string
+	BytesScanned           uint64
+	ChunksScanned          uint64
+	VerifiedSecretsFound   uint64
+	UnverifiedSecretsFound uint64
+	// Skipped is the number of items not scanned, keyed by reason.
+	Skipped map[sources.SkipReason]uint64
+	// SkippedBytes is the size of the items not scanned whose size is
+	// known, keyed by reason.
+	SkippedBytes map[sources.SkipReason]uint64
+	// SkippedExamples contains up to maxSkippedExamples items per reason.
+	SkippedExamples map[sources.SkipReason][]string
+}
+
+// DetectorSummary contains the number of findings for a single detector.
+type DetectorSummary struct {
+	Name       string
+	Verified   uint64
+	Unverified uint64
+	// Suppressed is the number of findings not reported because of an ignore
+	// comment or ignore file.
+	Suppressed uint64
+}
+
+// scanSummary aggregates the data for a Summary while the engine is running.
+// It implements sources.SkipReporter.
+type scanSummary struct {
+	mu        sync.Mutex
+	sources   map[string]*SourceSummary
+	detectors map[string]*DetectorSummary
+	// suppressed is keyed by the finding, as the same finding can be found by
+	// several decoders.
+	suppressed map[string]Suppression
+}
+
+var _ sources.SkipReporter = (*scanSummary)(nil)
+
+func newScanSummary() *scanSummary {
+	return &scanSummary{
+		sources:    make(map[string]*SourceSummary),
+		detectors:  make(map[string]*DetectorSummary),
+		suppressed: make(map[string]Suppression),
+	}
+}
+
+// source returns the summary for the named source, creating it if needed. The
+// caller must hold the lock.
+func (s *scanSummary) source(name, kind string) *SourceSummary {
+	src, ok := s.sources[name]
+	if !ok {
+		src = &SourceSummary{
+			Name:            name,
+			Skipped:         make(map[sources.SkipReason]uint64),
+			SkippedBytes:    make(map[sources.SkipReason]uint64),
+			SkippedExamples: make(map[sources.SkipReason][]string),
+		}
+		s.sources[name] = src
+	}
+	if src.Type == "" {
+		src.Type = kind
+	}
+	return src
+}
+
+// ReportSkip implements sources.SkipReporter.
+func (s *scanSummary) ReportSkip(item sources.SkippedItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src := s.source(item.SourceName, item.SourceType.String())
+	src.Skipped[item.Reason]++
+	if item.Bytes > 0 {
+		src.SkippedBytes[item.Reason] += uint64(item.Bytes)
+	}
+	if len(src.SkippedExamples[item.Reason]) < maxSkippedExamples {
+		src.SkippedExamples[item.Reason] = append(src.SkippedExamples[item.Reason], item.Item)
+	}
+}
+
+// addChunk records a scanned chunk and the number of bytes scanned for it.
+func (s *scanSummary) addChunk(chunk *sources.Chunk, bytesScanned uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src := s.source(chunk.SourceName, chunk.SourceType.String())
+	src.ChunksScanned++
+	src.BytesScanned += bytesScanned
+}
+
+// detector returns the summary for the named detector, creating it if needed.
+// The caller must hold the lock.
+func (s *scanSummary) detector(name string) *DetectorSummary {
+	det, ok := s.detectors[name]
+	if !ok {
+		det = &DetectorSummary{Name: name}
+		s.detectors[name] = det
+	}
+	return det
+}
+
+// addResult records a reported result.
+func (s *scanSummary) addResult(r *detectors.ResultWithMetadata) {
+	name := detectorName(&r.Result)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src := s.source(r.SourceName, r.SourceType.String())
+	det := s.detector(name)
+	if r.Verified {
+		src.VerifiedSecretsFound++
+		det.Verified++
+	} else {
+		src.UnverifiedSecretsFound++
+		det.Unverified++
+	}
+}
+
+// addSuppressed records a finding that is not reported.
+func (s *scanSummary) addSuppressed(chunk *sources.Chunk, res *detectors.Result, line int64, method SuppressionMethod, reason string) {
+	s.suppress(Suppression{
+		SourceName: chunk.SourceName,
+		Detector:   detectorName(res),
+		File:       sources.ArchiveFile(chunk.SourceMetadata, sources.MetadataFile(chunk.SourceMetadata)),
+		Line:       line,
+		Method:     method,
+		Reason:     reason,
+	}, res, chunk.SourceMetadata.String())
+}
+
+// addPolicySuppressed records a result ignored by the policy rule.
+func (s *scanSummary) addPolicySuppressed(r *detectors.ResultWithMetadata, rule string) {
+	s.suppress(Suppression{
+		SourceName: r.SourceName,
+		Detector:   detectorName(&r.Result),
+		File:       sources.ArchiveFile(r.SourceMetadata, sources.MetadataFile(r.SourceMetadata)),
+		Line:       metadataLine(r.SourceMetadata),
+		Method:     SuppressedByPolicy,
+		Reason:     rule,
+	}, &r.Result, r.SourceMetadata.String())
+}
+
+func (s *scanSummary) suppress(supp Suppression, res *detectors.Result, location string) {
+	key := fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s", supp.Detector, res.Raw, res.RawV2, supp.SourceName, location)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.suppressed[key]; ok {
+		return
+	}
+	s.suppressed[key] = supp
+	s.detector(supp.Detector).Suppressed++
+}
+
+func detectorName(res *detectors.Result) string {
+	if res.DetectorName != "" {
+		return res.DetectorName
+	}
+	return res.DetectorType.String()
+}
+
+// snapshot returns a copy of the per-source and per-detector statistics, and
+// the suppressed findings.
+func (s *scanSummary) snapshot() ([]SourceSummary, []DetectorSummary, []Suppression) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	srcs := make([]SourceSummary, 0, len(s.sources))
+	for _, src := range s.sources {
+		cp := *src
+		cp.Skipped = make(map[sources.SkipReason]uint64, len(src.Skipped))
+		for k, v := range src.Skipped {
+			cp.Skipped[k] = v
+		}
+		cp.SkippedBytes = make(map[sources.SkipReason]uint64, len(src.SkippedBytes))
+		for k, v := range src.SkippedBytes {
+			cp.SkippedBytes[k] = v
+		}
+		cp.SkippedExamples = make(map[sources.SkipReason][]string, len(src.SkippedExamples))
+		for k, v := range src.SkippedExamples {
+			cp.SkippedExamples[k] = append([]string(nil), v...)
+		}
+		srcs = append(srcs, cp)
+	}
+	sort.Slice(srcs, func(i, j int) bool { return srcs[i].Name < srcs[j].Name })
+
+	dets := make([]DetectorSummary, 0, len(s.detectors))
+	for _, det := range s.detectors {
+		dets = append(dets, *det)
+	}
+	sort.Slice(dets, func(i, j int) bool { return dets[i].Name < dets[j].Name })
+
+	supps := make([]Suppression, 0, len(s.suppressed))
+	for _, supp := range s.suppressed {
+		supps = append(supps, supp)
+	}
+	sort.Slice(supps, func(i, j int) bool {
+		a, b := supps[i], supps[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Detector < b.Detector
+	})
+
+	return srcs, dets, supps
+}
+
+// GetSummary returns a structured summary of the scan. It should be called
+// after Finish to get complete results.
+func (e *Engine) GetSummary() Summary {
+	metrics := e.GetMetrics()
+	srcs, dets, supps := e.summary.snapshot()
+	return Summary{
+		ScanDuration:           metrics.ScanDuration,
+		BytesScanned:           metrics.BytesScanned,
+		ChunksScanned:          metrics.ChunksScanned,
+		VerifiedSecretsFound:   metrics.VerifiedSecretsFound,
+		UnverifiedSecretsFound: metrics.UnverifiedSecretsFound,
+		Sources:                srcs,
+		Detectors:              dets,
+		Suppressed:             supps,
+	}
+}
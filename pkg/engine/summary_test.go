@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestScanSummary(t *testing.T) {
+	s := newScanSummary()
+
+	s.addChunk(&sources.Chunk{SourceName: "fs", SourceType: sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM}, 10)
+	s.addChunk(&sources.Chunk{SourceName: "fs", SourceType: sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM}, 5)
+	s.addChunk(&sources.Chunk{SourceName: "bucket", SourceType: sourcespb.SourceType_SOURCE_TYPE_S3}, 7)
+
+	s.addResult(&detectors.ResultWithMetadata{
+		SourceName: "fs",
+		SourceType: sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM,
+		Result:     detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Verified: true},
+	})
+	s.addResult(&detectors.ResultWithMetadata{
+		SourceName: "fs",
+		SourceType: sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM,
+		Result:     detectors.Result{DetectorType: detectorspb.DetectorType_AWS},
+	})
+
+	for i := 0; i < maxSkippedExamples+2; i++ {
+		s.ReportSkip(sources.SkippedItem{
+			SourceName: "bucket",
+			SourceType: sourcespb.SourceType_SOURCE_TYPE_S3,
+			Item:       fmt.Sprintf("key-%d", i),
+			Reason:     sources.SkipReasonSize,
+			Bytes:      int64(i),
+		})
+	}
+
+	srcs, dets, _ := s.snapshot()
+	assert.Len(t, srcs, 2)
+
+	bucket := srcs[0]
+	assert.Equal(t, "bucket", bucket.Name)
+	assert.Equal(t, "SOURCE_TYPE_S3", bucket.Type)
+	assert.Equal(t, uint64(1), bucket.ChunksScanned)
+	assert.Equal(t, uint64(7), bucket.BytesScanned)
+	assert.Equal(t, uint64(maxSkippedExamples+2), bucket.Skipped[sources.SkipReasonSize])
+	assert.Len(t, bucket.SkippedExamples[sources.SkipReasonSize], maxSkippedExamples)
+	assert.Equal(t, uint64((maxSkippedExamples+1)*(maxSkippedExamples+2)/2), bucket.SkippedBytes[sources.SkipReasonSize])
+
+	fs := srcs[1]
+	assert.Equal(t, "fs", fs.Name)
+	assert.Equal(t, uint64(2), fs.ChunksScanned)
+	assert.Equal(t, uint64(15), fs.BytesScanned)
+	assert.Equal(t, uint64(1), fs.VerifiedSecretsFound)
+	assert.Equal(t, uint64(1), fs.UnverifiedSecretsFound)
+
+	assert.Equal(t, []DetectorSummary{{Name: "AWS", Verified: 1, Unverified: 1}}, dets)
+}
+
+func TestScanSummary_Suppressed(t *testing.T) {
+	s := newScanSummary()
+
+	chunk := &sources.Chunk{
+		SourceName: "fs",
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{File: "config.yaml", Line: 3},
+			},
+		},
+	}
+	res := &detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("AKIA")}
+	s.addSuppressed(chunk, res, 3, SuppressedByComment, "fake key")
+	// The same finding from another decoder is only counted once.
+	s.addSuppressed(chunk, res, 3, SuppressedByComment, "fake key")
+	s.addSuppressed(&sources.Chunk{SourceName: "fs"}, &detectors.Result{DetectorType: detectorspb.DetectorType_Stripe}, 0, SuppressedByIgnoreFile, "")
+
+	_, dets, supps := s.snapshot()
+	assert.Equal(t, []DetectorSummary{{Name: "AWS", Suppressed: 1}, {Name: "Stripe", Suppressed: 1}}, dets)
+	assert.Equal(t, []Suppression{
+		{SourceName: "fs", Detector: "Stripe", Method: SuppressedByIgnoreFile},
+		{SourceName: "fs", Detector: "AWS", File: "config.yaml", Line: 3, Method: SuppressedByComment, Reason: "fake key"},
+	}, supps)
+}
+
+func TestScanSummary_PolicySuppressed(t *testing.T) {
+	s := newScanSummary()
+
+	r := &detectors.ResultWithMetadata{
+		SourceName: "git",
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{File: "testdata/creds", Line: 7},
+			},
+		},
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("AKIA")},
+	}
+	s.addPolicySuppressed(r, "test-fixtures")
+	s.addPolicySuppressed(r, "test-fixtures")
+
+	_, dets, supps := s.snapshot()
+	assert.Equal(t, []DetectorSummary{{Name: "AWS", Suppressed: 1}}, dets)
+	assert.Equal(t, []Suppression{
+		{SourceName: "git", Detector: "AWS", File: "testdata/creds", Line: 7, Method: SuppressedByPolicy, Reason: "test-fixtures"},
+	}, supps)
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/teams"
+)
+
+// ScanTeams scans the channel messages, chats and shared files of Microsoft
+// Teams.
+func (e *Engine) ScanTeams(ctx context.Context, c sources.TeamsConfig) error {
+	connection := &sourcespb.Teams{
+		Credential: &sourcespb.Teams_Token{Token: c.Token},
+		TeamIds:    c.Teams,
+		Channels:   c.Channels,
+		IgnoreList: c.ExcludeChannels,
+		SkipChats:  c.SkipChats,
+		SkipFiles:  c.SkipFiles,
+		StatePath:  c.StatePath,
+	}
+	if c.ClientID != "" {
+		connection.Credential = &sourcespb.Teams_Authenticated{
+			Authenticated: &credentialspb.ClientCredentials{TenantId: c.TenantID, ClientId: c.ClientID, ClientSecret: c.ClientSecret},
+		}
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - teams", new(teams.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			teamsSource := teams.Source{}
+			if err := teamsSource.Init(ctx, "trufflehog - teams", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			teamsSource.WithArchiveOptions(c.ArchiveOptions)
+			return &teamsSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/tracker"
+)
+
+// ScanTracker scans the descriptions, comments and attachments of the
+// cards of Trello, the tasks of Asana or the issues of Linear.
+func (e *Engine) ScanTracker(ctx context.Context, c sources.TrackerConfig) error {
+	connection := &sourcespb.Tracker{SkipAttachments: c.SkipAttachments}
+	switch c.Platform {
+	case "trello":
+		connection.Platform = &sourcespb.Tracker_Trello{Trello: &sourcespb.Trello{
+			ApiKey: c.TrelloAPIKey,
+			Token:  c.TrelloToken,
+			Boards: c.Boards,
+		}}
+	case "asana":
+		connection.Platform = &sourcespb.Tracker_Asana{Asana: &sourcespb.Asana{Token: c.AsanaToken, Projects: c.Projects}}
+	case "linear":
+		connection.Platform = &sourcespb.Tracker_Linear{Linear: &sourcespb.Linear{ApiKey: c.LinearAPIKey, Teams: c.Teams}}
+	default:
+		return fmt.Errorf("unknown tracker %q", c.Platform)
+	}
+
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error marshalling connection", 0)
+	}
+
+	handle, err := e.sourceManager.Enroll(ctx, "trufflehog - tracker", new(tracker.Source).Type(),
+		func(ctx context.Context, jobID, sourceID int64) (sources.Source, error) {
+			trackerSource := tracker.Source{}
+			if err := trackerSource.Init(ctx, "trufflehog - tracker", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+				return nil, err
+			}
+			trackerSource.WithArchiveOptions(c.ArchiveOptions)
+			return &trackerSource, nil
+		})
+	if err != nil {
+		return err
+	}
+	_, err = e.sourceManager.ScheduleRun(ctx, handle)
+	return err
+}
@@ -162,15 +162,21 @@ func (c1 *Commit) Equal(c2 *Commit) bool {
 
 // RepoPath parses the output of the `git log` command for the `source` path.
 func (c *Parser) RepoPath(ctx context.Context, source string, head string, abbreviatedLog bool, excludedGlobs []string, isBare bool) (chan Commit, error) {
+	revisions := []string{"--all"}
+	if head != "" {
+		revisions = []string{head}
+	}
+	return c.RepoRevisions(ctx, source, revisions, abbreviatedLog, excludedGlobs, isBare)
+}
+
+// RepoRevisions parses the output of the `git log` command for the `source`
+// path, limited to the given revision arguments, e.g. `new --not --all`.
+func (c *Parser) RepoRevisions(ctx context.Context, source string, revisions []string, abbreviatedLog bool, excludedGlobs []string, isBare bool) (chan Commit, error) {
 	args := []string{"-C", source, "log", "-p", "--full-history", "--date=format:%a %b %d %H:%M:%S %Y %z"}
 	if abbreviatedLog {
 		args = append(args, "--diff-filter=AM")
 	}
-	if head != "" {
-		args = append(args, head)
-	} else {
-		args = append(args, "--all")
-	}
+	args = append(args, revisions...)
 	for _, glob := range excludedGlobs {
 		args = append(args, "--", ".", fmt.Sprintf(":(exclude)%s", glob))
 	}
@@ -213,6 +219,15 @@ func (c *Parser) Staged(ctx context.Context, source string) (chan Commit, error)
 	return c.executeCommand(ctx, cmd, true)
 }
 
+// Diff parses the output of the `git diff` command for the `source` path
+// between the revisions of diffRange, e.g. `main..HEAD` or `main...HEAD`.
+func (c *Parser) Diff(ctx context.Context, source, diffRange string) (chan Commit, error) {
+	// Without context lines, hunks start at the first added line.
+	args := []string{"-C", source, "diff", "-p", "-U0", "--diff-filter=AM", diffRange, "--"}
+	cmd := exec.Command("git", args...)
+	return c.executeCommand(ctx, cmd, true)
+}
+
 // executeCommand runs an exec.Cmd, reads stdout and stderr, and waits for the Cmd to complete.
 func (c *Parser) executeCommand(ctx context.Context, cmd *exec.Cmd, isStaged bool) (chan Commit, error) {
 	commitChan := make(chan Commit, 64)
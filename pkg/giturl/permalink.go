@@ -0,0 +1,225 @@
+package giturl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	providerBitbucketServer provider = "BitbucketServer"
+	providerGerrit          provider = "Gerrit"
+	providerAzureDevOps     provider = "AzureDevOps"
+)
+
+// azureLineParams are the query parameters of the lines that links to files
+// of Azure DevOps select.
+var azureLineParams = []string{"line", "lineEnd", "lineStartColumn", "lineEndColumn", "lineStyle"}
+
+// GenerateLink returns a permalink to a line of a file at a commit of a
+// repository hosted on GitHub, GitLab, Bitbucket, Gerrit or Azure DevOps, or
+// to the commit
+// if file is empty. The line anchor is left out if line is not positive. It
+// returns an empty string for repositories of other hosts, which have no
+// known link format.
+func GenerateLink(repo, commit, file string, line int64) string {
+	if commit == "" {
+		return ""
+	}
+	base, provider, ok := parseRepo(repo)
+	if !ok {
+		return ""
+	}
+	file = escapePath(strings.TrimPrefix(file, "/"))
+
+	var link string
+	switch provider {
+	case providerGithub:
+		if file == "" {
+			return base.String() + "/commit/" + commit
+		}
+		link = base.String() + "/blob/" + commit + "/" + file
+	case providerGitlab:
+		if file == "" {
+			return base.String() + "/-/commit/" + commit
+		}
+		link = base.String() + "/-/blob/" + commit + "/" + file
+	case providerBitbucket:
+		if file == "" {
+			return base.String() + "/commits/" + commit
+		}
+		link = base.String() + "/src/" + commit + "/" + file
+	case providerBitbucketServer:
+		// Bitbucket Server clones from /scm/<project>/<repo> and browses
+		// at /projects/<project>/repos/<repo>.
+		parts := strings.Split(strings.TrimPrefix(base.Path, "/scm/"), "/")
+		if len(parts) != 2 {
+			return ""
+		}
+		base.Path = "/projects/" + parts[0] + "/repos/" + parts[1]
+		if file == "" {
+			return base.String() + "/commits/" + commit
+		}
+		link = base.String() + "/browse/" + file + "?at=" + commit
+	case providerGerrit:
+		// Gerrit serves authenticated clones below /a/, and browses files
+		// with Gitiles, which googlesource.com hosts at the root.
+		project := strings.TrimPrefix(base.Path, "/a/")
+		base.Path = "/plugins/gitiles/" + strings.TrimPrefix(project, "/")
+		if strings.HasSuffix(base.Host, ".googlesource.com") {
+			base.Path = "/" + strings.TrimPrefix(project, "/")
+		}
+		if file == "" {
+			return base.String() + "/+/" + commit
+		}
+		link = base.String() + "/+/" + commit + "/" + file
+	case providerAzureDevOps:
+		if file == "" {
+			return base.String() + "/commit/" + commit
+		}
+		// Azure DevOps selects the file, commit and lines in the query.
+		link = base.String() + "?path=/" + file + "&version=GC" + commit + "&_a=contents"
+	}
+	return UpdateLinkLineNumber(link, line)
+}
+
+// UpdateLinkLineNumber returns a link to a file, made by GenerateLink or by
+// the code host, with its line anchor pointing at line, or removed if line is
+// not positive. Other links, like links to commits or comments, are returned
+// as is.
+func UpdateLinkLineNumber(link string, line int64) string {
+	base, _, _ := strings.Cut(link, "#")
+	u, err := url.Parse(base)
+	if err != nil {
+		return link
+	}
+
+	var anchor string
+	switch provider := providerOf(u.Hostname()); {
+	case (provider == providerGithub || provider == providerGitlab) && strings.Contains(u.Path, "/blob/"):
+		anchor = "L%d"
+	case provider == providerBitbucket && strings.Contains(u.Path, "/src/"):
+		anchor = "lines-%d"
+	case provider == providerBitbucketServer && strings.Contains(u.Path, "/browse/"):
+		anchor = "%d"
+	case provider == providerAzureDevOps && u.Query().Get("path") != "":
+		return azureLink(base, line)
+	case provider == providerGerrit && strings.Contains(u.Path, "/+/"):
+		// Gitiles links to commits end in /+/<commit>, and links to changes
+		// in /+/<change>/<patch set> or /+/<change>/comment/<id>/, with files
+		// after them.
+		project, rest, _ := strings.Cut(u.Path, "/+/")
+		parts := strings.SplitN(rest, "/", 3)
+		if len(parts) < 2 || strings.HasPrefix(project, "/c/") && (len(parts) < 3 || parts[1] == "comment") {
+			return link
+		}
+		anchor = "%d"
+	default:
+		return link
+	}
+	if line <= 0 {
+		return base
+	}
+	return base + "#" + fmt.Sprintf(anchor, line)
+}
+
+// azureLink returns a link to a file of Azure DevOps that selects line, or no
+// line if line is not positive. The other parameters keep their order.
+func azureLink(link string, line int64) string {
+	base, query, _ := strings.Cut(link, "?")
+	var params []string
+	for _, param := range strings.Split(query, "&") {
+		key, _, _ := strings.Cut(param, "=")
+		isLine := false
+		for _, lineParam := range azureLineParams {
+			isLine = isLine || key == lineParam
+		}
+		if !isLine && param != "" {
+			params = append(params, param)
+		}
+	}
+	if line > 0 {
+		params = append(params, fmt.Sprintf("line=%d&lineEnd=%d&lineStartColumn=1&lineEndColumn=1&lineStyle=plain", line, line+1))
+	}
+	return base + "?" + strings.Join(params, "&")
+}
+
+// providerOf returns the code host of a host name, or an empty provider if
+// it is unknown.
+func providerOf(host string) provider {
+	host = strings.ToLower(host)
+	switch {
+	case host == "github.com" || strings.HasPrefix(host, "github."):
+		return providerGithub
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return providerGitlab
+	case host == "bitbucket.org":
+		return providerBitbucket
+	case strings.HasPrefix(host, "bitbucket."):
+		return providerBitbucketServer
+	case strings.HasSuffix(host, ".googlesource.com") || strings.HasPrefix(host, "gerrit."):
+		return providerGerrit
+	case host == "dev.azure.com" || host == "ssh.dev.azure.com" || strings.HasSuffix(host, ".visualstudio.com"):
+		return providerAzureDevOps
+	default:
+		return ""
+	}
+}
+
+// parseRepo returns the web URL of a repository from its clone URL, which may
+// be an HTTP, SSH or scp-like URL such as git@github.com:org/repo.git, and
+// its code host.
+func parseRepo(repo string) (*url.URL, provider, bool) {
+	if !strings.Contains(repo, "://") {
+		// scp-like URLs have no scheme and separate the path with a colon.
+		_, hostPath, ok := strings.Cut(repo, "@")
+		if !ok {
+			return nil, "", false
+		}
+		repo = "ssh://" + strings.Replace(hostPath, ":", "/", 1)
+	}
+	u, err := url.Parse(repo)
+	if err != nil || u.Host == "" {
+		return nil, "", false
+	}
+	provider := providerOf(u.Hostname())
+	if provider == "" {
+		return nil, "", false
+	}
+
+	web := &url.URL{Scheme: "https", Host: u.Host}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		// The port of an SSH URL is not the port of the web server.
+		web.Host = u.Hostname()
+	} else {
+		web.Scheme = u.Scheme
+	}
+	web.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), ".git")
+	if provider == providerAzureDevOps && strings.HasPrefix(web.Path, "/v3/") {
+		// SSH URLs of Azure DevOps are v3/<organization>/<project>/<repo>,
+		// which is browsed at /<organization>/<project>/_git/<repo>.
+		parts := strings.Split(strings.TrimPrefix(web.Path, "/v3/"), "/")
+		if len(parts) != 3 {
+			return nil, "", false
+		}
+		web.Host = "dev.azure.com"
+		if strings.HasSuffix(u.Hostname(), ".visualstudio.com") {
+			web.Host = parts[0] + ".visualstudio.com"
+			parts = parts[1:]
+		}
+		web.Path = "/" + strings.Join(parts[:len(parts)-1], "/") + "/_git/" + parts[len(parts)-1]
+	}
+	if web.Path == "" {
+		return nil, "", false
+	}
+	return web, provider, true
+}
+
+// escapePath escapes each element of a slash separated path.
+func escapePath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
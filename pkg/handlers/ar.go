@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	arMagic      = "!<arch>\n"
+	arHeaderSize = 60
+)
+
+// arReader reads the members of an ar archive, the format of Debian packages,
+// in order, like archive/tar.
+type arReader struct {
+	r *bufio.Reader
+	// remaining is the unread content of the current member, and padding
+	// the byte that aligns odd sized members.
+	remaining, padding int64
+}
+
+// arHeader is the header of a member of an ar archive.
+type arHeader struct {
+	Name string
+	Size int64
+}
+
+func newArReader(r io.Reader) (*arReader, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(arMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("unable to read ar archive: %w", err)
+	}
+	if string(magic) != arMagic {
+		return nil, errors.New("not an ar archive")
+	}
+	return &arReader{r: br}, nil
+}
+
+// Next advances to the next member of the archive, whose content is then read
+// by Read. It returns io.EOF at the end of the archive.
+func (a *arReader) Next() (*arHeader, error) {
+	// Skip what is left of the previous member, and its padding to an even
+	// offset.
+	if _, err := a.r.Discard(int(a.remaining + a.padding)); err != nil {
+		return nil, err
+	}
+	a.remaining, a.padding = 0, 0
+
+	header := make([]byte, arHeaderSize)
+	if _, err := io.ReadFull(a.r, header); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("truncated ar header: %w", err)
+		}
+		return nil, err
+	}
+	if string(header[58:60]) != "`\n" {
+		return nil, errors.New("invalid ar header")
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("invalid ar member size %q", header[48:58])
+	}
+	// GNU ar ends names with a slash.
+	name := strings.TrimSuffix(strings.TrimSpace(string(header[:16])), "/")
+	a.remaining, a.padding = size, size%2
+	return &arHeader{Name: name, Size: size}, nil
+}
+
+// Read reads the content of the current member.
+func (a *arReader) Read(p []byte) (int, error) {
+	if a.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > a.remaining {
+		p = p[:a.remaining]
+	}
+	n, err := a.r.Read(p)
+	a.remaining -= int64(n)
+	if errors.Is(err, io.EOF) && a.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
@@ -1,34 +1,61 @@
 package handlers
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/h2non/filetype"
+	"github.com/klauspost/compress/zip"
 	"github.com/mholt/archiver/v4"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
 type ctxKey int
 
 const (
 	depthKey ctxKey = iota
+	pathKey
+	imageLayerKey
+	packedCommitKey
+	helmChartKey
+	packageKey
+	chunkOptionsKey
 )
 
-var (
+// The defaults of the options archive handlers leave unset.
+const (
 	maxDepth   = 5
-	maxSize    = 250 * 1024 * 1024 // 20MB
+	maxSize    = 250 * 1024 * 1024 // 250MB
 	maxTimeout = time.Duration(30) * time.Second
+	// maxCompressionRatio is the largest ratio of the decompressed to the
+	// compressed size of a file extracted. Repetitive logs compress a few
+	// hundred times, while the zeros zip bombs are made of deflate just
+	// over a thousand times, and far more with bzip2, xz or zstd.
+	maxCompressionRatio = 1000.0
+	// archiveConcurrency is the number of files extracted at the same time.
+	archiveConcurrency = 1
+)
+
+var (
+	// timeoutProgress is how much of an extracted file is read each time
+	// its timeout starts over, so large files read steadily are not
+	// abandoned.
+	timeoutProgress int64 = 1 << 20
+
+	errMaxArchiveDepthReached = errors.New("max archive depth reached")
+	errArchiveStalled         = errors.New("archive extraction stalled")
 )
 
 // Ensure the Archive satisfies the interfaces at compile time.
@@ -37,7 +64,31 @@ var _ SpecializedHandler = (*Archive)(nil)
 // Archive is a handler for extracting and decompressing archives.
 type Archive struct {
 	size         int
+	sizeMu       sync.Mutex
 	currentDepth int
+	opts         sources.ArchiveOptions
+	// name is the name of the file handled, if it is known, for the
+	// formats only recognized by their extension.
+	name string
+	// workers limits the files extracted at the same time, if they are
+	// extracted concurrently.
+	workers chan struct{}
+	// members is the filter of the files extracted, compiled once from the
+	// member globs of the options.
+	members     *sources.MemberFilter
+	membersOnce sync.Once
+	// stalled cancels the extraction when none of the files of the archive
+	// are read for the timeout, and is reset as they are.
+	stalled *time.Timer
+	// timedOut are the paths of the files abandoned for taking longer than
+	// the timeout to extract.
+	timedOut   []string
+	timedOutMu sync.Mutex
+}
+
+// NewArchive returns a handler that extracts archives as configured by opts.
+func NewArchive(opts sources.ArchiveOptions) *Archive {
+	return &Archive{opts: opts}
 }
 
 // New sets a default maximum size and current size counter.
@@ -45,87 +96,311 @@ func (a *Archive) New() {
 	a.size = 0
 }
 
-// SetArchiveMaxSize sets the maximum size of the archive.
-func SetArchiveMaxSize(size int) {
-	maxSize = size
+// fileName returns the name of the file at path in the file handled, or of
+// the file handled itself.
+func (a *Archive) fileName(path string) string {
+	if path == "" {
+		return a.name
+	}
+	return path
 }
 
-// SetArchiveMaxDepth sets the maximum depth of the archive.
-func SetArchiveMaxDepth(depth int) {
-	maxDepth = depth
+// options returns the options of the handler, with the defaults in place of
+// the unset ones.
+func (a *Archive) options() sources.ArchiveOptions {
+	opts := a.opts
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = maxDepth
+	}
+	if opts.MaxSize == 0 {
+		opts.MaxSize = maxSize
+	}
+	if opts.MaxTimeout == 0 {
+		opts.MaxTimeout = maxTimeout
+	}
+	if opts.MaxCompressionRatio == 0 {
+		opts.MaxCompressionRatio = maxCompressionRatio
+	}
+	if opts.Concurrency == 0 {
+		opts.Concurrency = archiveConcurrency
+	}
+	return opts
 }
 
-// SetArchiveMaxTimeout sets the maximum timeout for the archive handler.
-func SetArchiveMaxTimeout(timeout time.Duration) {
-	maxTimeout = timeout
+// memberFilter returns the filter of the files extracted from archives.
+func (a *Archive) memberFilter(ctx context.Context) *sources.MemberFilter {
+	a.membersOnce.Do(func() {
+		opts := a.options()
+		var err error
+		a.members, err = sources.NewMemberFilter(opts.IncludeMembers, opts.ExcludeMembers)
+		if err != nil {
+			logContext.AddLogger(ctx).Logger().V(1).Info("Ignoring invalid archive member globs.", "error", err)
+		}
+	})
+	return a.members
 }
 
 // FromFile extracts the files from an archive.
-func (a *Archive) FromFile(originalCtx context.Context, data io.Reader) chan []byte {
-	archiveChan := make(chan []byte, 512)
+func (a *Archive) FromFile(originalCtx context.Context, data io.Reader) chan Part {
+	return a.extract(originalCtx, func(ctx context.Context, archiveChan chan Part) error {
+		return a.openArchive(ctx, 0, "", data, archiveChan)
+	})
+}
+
+// extract runs extractFiles in a goroutine, and returns the channel it sends
+// the parts of the files it extracts on, which is closed when it returns.
+func (a *Archive) extract(originalCtx context.Context, extractFiles func(ctx context.Context, archiveChan chan Part) error) chan Part {
+	archiveChan := make(chan Part, 512)
+	if concurrency := a.options().Concurrency; concurrency > 1 && a.workers == nil {
+		// The goroutine extracting the archive is one of the workers.
+		a.workers = make(chan struct{}, concurrency-1)
+	}
 	go func() {
-		ctx, cancel := context.WithTimeout(originalCtx, maxTimeout)
+		// Files that take too long are abandoned one by one, so the archive
+		// itself is only abandoned if it stops making progress, e.g. while
+		// it is read before its files are.
+		timeout := a.options().MaxTimeout
+		ctx, cancel := context.WithCancelCause(context.WithValue(originalCtx, chunkOptionsKey, a.options().ChunkOptions()))
+		a.stalled = time.AfterFunc(timeout, func() { cancel(errArchiveStalled) })
 		logger := logContext.AddLogger(ctx).Logger()
-		defer cancel()
+		defer cancel(nil)
+		defer a.stalled.Stop()
 		defer close(archiveChan)
-		err := a.openArchive(ctx, 0, data, archiveChan)
+		defer a.warnTimedOut(ctx)
+		// Archiver v4 is in alpha and using an experimental version of
+		// rardecode. There is a bug somewhere with rar decoder format 29
+		// that can lead to a panic. An issue is open in rardecode repo
+		// https://github.com/nwaples/rardecode/issues/30.
+		defer func() {
+			if r := recover(); r != nil {
+				err, ok := r.(error)
+				if !ok {
+					err = fmt.Errorf("Panic occurred: %v", r)
+				}
+				logger.Error(err, "Panic occurred when reading archive")
+			}
+		}()
+		err := extractFiles(ctx, archiveChan)
 		if err != nil {
 			if errors.Is(err, archiver.ErrNoMatch) {
 				return
 			}
-			logger.V(2).Info("Error unarchiving chunk.")
+			switch {
+			case errors.Is(context.Cause(ctx), errArchiveStalled):
+				a.reportSkip(ctx, "", sources.SkipReasonTimeout, 0)
+			case errors.Is(err, errMaxArchiveDepthReached), errors.Is(err, errCompressionRatioExceeded), errors.Is(err, context.Canceled):
+				// Reported where they occur, or not a failure of the file.
+			default:
+				a.reportSkip(ctx, "", sources.SkipReasonError, 0)
+			}
+			logger.V(2).Info("Error unarchiving chunk.", "error", err)
 		}
 	}()
 	return archiveChan
 }
 
 // openArchive takes a reader and extracts the contents up to the maximum depth.
-func (a *Archive) openArchive(ctx context.Context, depth int, reader io.Reader, archiveChan chan []byte) error {
-	if depth >= maxDepth {
-		return fmt.Errorf("max archive depth reached")
+// path is the path of the file read in the outermost archive.
+func (a *Archive) openArchive(ctx context.Context, depth int, path string, reader io.Reader, archiveChan chan Part) error {
+	opts := a.options()
+	if depth >= opts.MaxDepth {
+		return errMaxArchiveDepthReached
 	}
 	format, reader, err := archiver.Identify("", reader)
+	if err != nil && reader != nil {
+		var isCompressed bool
+		if format, reader, isCompressed = identifyCompression(a.fileName(path), reader); isCompressed {
+			err = nil
+		}
+	}
+	if _, isLz4 := format.(archiver.Lz4); isLz4 {
+		format = lz4Frames{}
+	}
 	if err != nil {
-		if errors.Is(err, archiver.ErrNoMatch) && depth > 0 {
-			chunkSize := 10 * 1024
-			for {
-				chunk := make([]byte, chunkSize)
-				n, _ := reader.Read(chunk)
-				archiveChan <- chunk
-				if n < chunkSize {
-					break
-				}
+		if reader != nil {
+			diskFormat, diskReader, isDiskImage := identifyDiskImage(reader)
+			if isDiskImage && !opts.SkipsFormat(string(diskFormat)) {
+				return a.extractDiskImage(ctx, depth, path, diskFormat, diskReader, archiveChan)
 			}
-			return nil
+			reader = diskReader
+			mailFormat, mailReader, isMail := identifyMail(reader)
+			if isMail && !opts.SkipsFormat(string(mailFormat)) {
+				return a.extractMail(ctx, depth, path, mailFormat, mailReader, archiveChan)
+			}
+			reader = mailReader
+			columnarFormat, columnarReader, isColumnar := identifyColumnar(reader)
+			if isColumnar && !opts.SkipsFormat(string(columnarFormat)) {
+				return a.extractColumnar(ctx, depth, path, columnarFormat, columnarReader, archiveChan)
+			}
+			reader = columnarReader
+			packFormat, packReader, isPack := identifyPack(reader)
+			if isPack && !opts.SkipsFormat(string(packFormat)) {
+				return a.extractPack(ctx, depth, path, packFormat, packReader, archiveChan)
+			}
+			reader = packReader
+			dumpFormat, dumpReader, isDump := identifyDump(reader)
+			if isDump && !opts.SkipsFormat(string(dumpFormat)) {
+				return a.extractDump(ctx, depth, path, dumpFormat, dumpReader, archiveChan)
+			}
+			reader = dumpReader
+			installerFormat, installerReader, isInstaller := identifyInstaller(reader)
+			if isInstaller && !opts.SkipsFormat(string(installerFormat)) {
+				return a.extractInstaller(ctx, depth, path, installerFormat, installerReader, archiveChan)
+			}
+			reader = installerReader
+			cabinetReader, isCabinet := identifyCabinet(reader)
+			if isCabinet && !opts.SkipsFormat(cabFormat) {
+				return a.extractCabinet(ctx, depth, path, cabinetReader, archiveChan)
+			}
+			reader = cabinetReader
+		}
+		// Extracted files that are not archives, or only look like the
+		// start of one, like text starting with a zlib header byte, are
+		// scanned as they are.
+		if depth > 0 && reader != nil {
+			return sendParts(ctx, path, reader, archiveChan)
 		}
 		return err
 	}
+	// Archives of the formats skipped are scanned as they are.
+	if opts.SkipsFormat(format.Name()) {
+		logContext.AddLogger(ctx).Logger().V(3).Info("Not extracting archive of a skipped format.", "format", format.Name())
+		return sendParts(ctx, path, reader, archiveChan)
+	}
 	switch archive := format.(type) {
 	case archiver.Decompressor:
-		compReader, err := archive.OpenReader(reader)
-		if err != nil {
-			return err
-		}
-		fileBytes, err := a.ReadToMax(ctx, compReader)
+		compressed := &countingReader{reader: reader}
+		compReader, err := archive.OpenReader(compressed)
 		if err != nil {
 			return err
 		}
-		newReader := bytes.NewReader(fileBytes)
-		return a.openArchive(ctx, depth+1, newReader, archiveChan)
+		defer compReader.Close()
+		decompressed := a.ratioLimitReader(ctx, path, compReader, func() int64 { return compressed.n })
+		return a.openArchive(ctx, depth+1, path, a.limitReader(ctx, path, decompressed), archiveChan)
 	case archiver.Extractor:
-		err := archive.Extract(context.WithValue(ctx, depthKey, depth+1), reader, nil, a.extractorHandler(archiveChan))
+		tarReader, isTar, err := openTar(format, reader)
 		if err != nil {
 			return err
 		}
-		return nil
+		if isTar {
+			defer tarReader.Close()
+			var isImage bool
+			if reader, isImage = isImageTar(tarReader); isImage {
+				return a.extractImage(ctx, depth, path, reader, archiveChan)
+			}
+			var isChart bool
+			if reader, isChart = isHelmChartTar(reader); isChart {
+				return a.extractHelmChart(ctx, depth, path, reader, archiveChan)
+			}
+			var ecosystem, manifest string
+			var isPackage bool
+			if ecosystem, manifest, reader, isPackage = identifyPackageTar(reader); isPackage {
+				return a.extractPackage(ctx, depth, path, ecosystem, manifest, reader, archiveChan)
+			}
+			archive = archiver.Tar{}
+		}
+		if needsSeeking(format) {
+			seekableReader, cleanup, err := seekable(reader)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			reader = seekableReader
+		}
+		extractCtx := context.WithValue(context.WithValue(ctx, depthKey, depth+1), pathKey, path)
+		// Zip and 7z archives are extracted here rather than by archiver to
+		// decrypt their encrypted files.
+		switch format.(type) {
+		case archiver.Zip:
+			return a.extractZip(extractCtx, reader.(readerAtSeeker), archiveChan)
+		case archiver.SevenZip:
+			return a.extractSevenZip(extractCtx, reader.(readerAtSeeker), a.extractorHandler(archiveChan))
+		}
+		pool, poolCtx := a.newEntryPool(extractCtx)
+		err = archive.Extract(poolCtx, reader, nil, pool.bufferedHandler(a.extractorHandler(archiveChan)))
+		if poolErr := pool.wait(); poolErr != nil {
+			return poolErr
+		}
+		return err
 	}
 	return fmt.Errorf("Unknown archive type: %s", format.Name())
 }
 
-// IsFiletype returns true if the provided reader is an archive.
+// sendParts sends the content of an extracted file in chunks, with their
+// position in the file.
+func sendParts(ctx context.Context, path string, reader io.Reader, archiveChan chan Part) error {
+	layer, _ := ctx.Value(imageLayerKey).(*source_metadatapb.ImageLayer)
+	commit, _ := ctx.Value(packedCommitKey).(*source_metadatapb.PackedCommit)
+	chart, _ := ctx.Value(helmChartKey).(*source_metadatapb.HelmChart)
+	pkg, _ := ctx.Value(packageKey).(*source_metadatapb.Package)
+	chunkOptions, _ := ctx.Value(chunkOptionsKey).([]sources.ConfigOption)
+	chunkReader := sources.NewChunkReader(chunkOptions...)
+	for data := range chunkReader(logContext.AddLogger(ctx), reader) {
+		if err := data.Error(); err != nil {
+			return err
+		}
+		part := Part{
+			Data:         data.Bytes(),
+			Path:         path,
+			Offset:       data.Offset(),
+			LineStart:    data.LineStart(),
+			LineOffset:   data.LineOffset(),
+			ImageLayer:   layer,
+			PackedCommit: commit,
+			HelmChart:    chart,
+			Package:      pkg,
+		}
+		select {
+		case archiveChan <- part:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// IsFiletype returns true if the provided reader is an archive, disk image,
+// email, columnar data file, git pack or memory dump of a format that is not
+// skipped.
 func (a *Archive) IsFiletype(ctx context.Context, reader io.Reader) (io.Reader, bool) {
+	opts := a.options()
 	format, readerB, err := archiver.Identify("", reader)
 	if err != nil {
+		if readerB == nil {
+			return readerB, false
+		}
+		compressionFormat, readerB, isCompressed := identifyCompression(a.name, readerB)
+		if isCompressed && !opts.SkipsFormat(compressionFormat.Name()) {
+			return readerB, true
+		}
+		diskFormat, readerB, isDiskImage := identifyDiskImage(readerB)
+		if isDiskImage && !opts.SkipsFormat(string(diskFormat)) {
+			return readerB, true
+		}
+		mailFormat, readerB, isMail := identifyMail(readerB)
+		if isMail && !opts.SkipsFormat(string(mailFormat)) {
+			return readerB, true
+		}
+		columnarFormat, readerB, isColumnar := identifyColumnar(readerB)
+		if isColumnar && !opts.SkipsFormat(string(columnarFormat)) {
+			return readerB, true
+		}
+		packFormat, readerB, isPack := identifyPack(readerB)
+		if isPack && !opts.SkipsFormat(string(packFormat)) {
+			return readerB, true
+		}
+		dumpFormat, readerB, isDump := identifyDump(readerB)
+		if isDump && !opts.SkipsFormat(string(dumpFormat)) {
+			return readerB, true
+		}
+		installerFormat, readerB, isInstaller := identifyInstaller(readerB)
+		if isInstaller && !opts.SkipsFormat(string(installerFormat)) {
+			return readerB, true
+		}
+		readerB, isCabinet := identifyCabinet(readerB)
+		return readerB, isCabinet && !opts.SkipsFormat(cabFormat)
+	}
+	if opts.SkipsFormat(format.Name()) {
 		return readerB, false
 	}
 	switch format.(type) {
@@ -138,7 +413,7 @@ func (a *Archive) IsFiletype(ctx context.Context, reader io.Reader) (io.Reader,
 }
 
 // extractorHandler is applied to each file in an archiver.Extractor file.
-func (a *Archive) extractorHandler(archiveChan chan []byte) func(context.Context, archiver.File) error {
+func (a *Archive) extractorHandler(archiveChan chan Part) func(context.Context, archiver.File) error {
 	return func(ctx context.Context, f archiver.File) error {
 		logger := logContext.AddLogger(ctx).Logger()
 		logger.V(5).Info("Handling extracted file.", "filename", f.Name())
@@ -147,24 +422,316 @@ func (a *Archive) extractorHandler(archiveChan chan []byte) func(context.Context
 			depth = ctxDepth
 		}
 
+		parent, _ := ctx.Value(pathKey).(string)
+		path := memberPath(parent, f.NameInArchive)
+		if name := memberPath("", f.NameInArchive); !f.IsDir() && !a.memberFilter(ctx).Includes(name) {
+			logger.V(5).Info("Skipping filtered file.", "filename", f.Name())
+			a.reportSkip(ctx, path, sources.SkipReasonFiltered, f.Size())
+			return nil
+		}
+
+		if isEncrypted(f) && len(a.options().Passwords) == 0 {
+			logger.V(3).Info("Skipping encrypted file.", "filename", f.Name())
+			a.reportSkip(ctx, path, sources.SkipReasonEncrypted, f.Size())
+			return nil
+		}
+
 		fReader, err := f.Open()
-		if err != nil {
-			return err
+		if errors.Is(err, errWrongPassword) {
+			logger.V(3).Info("Skipping encrypted file, no archive password decrypts it.", "filename", f.Name())
+			a.reportSkip(ctx, path, sources.SkipReasonEncrypted, f.Size())
+			return nil
 		}
-		fileBytes, err := a.ReadToMax(ctx, fReader)
 		if err != nil {
 			return err
 		}
-		fileContent := bytes.NewReader(fileBytes)
+		defer fReader.Close()
 
-		err = a.openArchive(ctx, depth, fileContent, archiveChan)
+		var reader io.Reader = fReader
+		if hdr, ok := f.Header.(zip.FileHeader); ok {
+			reader = a.ratioLimitZipFile(ctx, path, hdr, reader)
+		}
+		limited := &sizeLimitedReader{ctx: ctx, archive: a, name: path, reader: reader, size: f.Size()}
+		err = a.openArchive(ctx, depth, path, limited, archiveChan)
 		if err != nil {
+			if errors.Is(err, errMaxArchiveDepthReached) {
+				a.reportSkip(ctx, path, sources.SkipReasonDepth, f.Size())
+			}
 			return err
 		}
 		return nil
 	}
 }
 
+// memberPath returns the path of a file in an archive, after the path of the
+// archive in its parent archives if it is nested, e.g.
+// lib/app.jar!/config/app.yml. The ./ and / tar paths may start with are
+// removed, so paths are the same however the archive was made.
+func memberPath(parent, name string) string {
+	for strings.HasPrefix(name, "./") || strings.HasPrefix(name, "/") {
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "./"), "/")
+	}
+	if parent == "" {
+		return name
+	}
+	return parent + sources.ArchivePathSeparator + name
+}
+
+// isEncrypted reports whether an extracted file is encrypted. Only zip entries
+// carry this information.
+func isEncrypted(f archiver.File) bool {
+	hdr, ok := f.Header.(zip.FileHeader)
+	return ok && hdr.Flags&0x1 != 0
+}
+
+// reportSkip reports a file of the file handled that is not scanned, by its
+// path after the name of the file handled, e.g. dist.tar.gz!/logo.png, or the
+// file handled itself if path is empty. size is the size of the file, or zero
+// if it is not known.
+func (a *Archive) reportSkip(ctx context.Context, path string, reason sources.SkipReason, size int64) {
+	item := memberPath(a.name, path)
+	switch {
+	case path == "":
+		item = a.name
+	case a.name == "":
+		item = path
+	}
+	if item == "" {
+		item = "archive"
+	}
+	sources.ReportSkipBytes(ctx, item, reason, size)
+}
+
+// limitReader returns a reader of the content of an extracted file, which
+// ends when the files extracted from the archive reach the maximum size.
+// Extracted files are streamed through it instead of being read into memory.
+// name is the path of the file in the file handled.
+func (a *Archive) limitReader(ctx context.Context, name string, reader io.Reader) io.Reader {
+	return &sizeLimitedReader{ctx: ctx, archive: a, name: name, reader: reader}
+}
+
+type sizeLimitedReader struct {
+	ctx     context.Context
+	archive *Archive
+	name    string
+	reader  io.Reader
+	// size is the size of the file, if it is known, and read how much of
+	// it was read, for the size of what is skipped of it.
+	size, read int64
+	// deadline is when the file is abandoned, which is pushed back each
+	// time timeoutProgress more of it, counted from extended, is read.
+	deadline  time.Time
+	extended  int64
+	abandoned bool
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	if common.IsDone(r.ctx) {
+		return 0, r.ctx.Err()
+	}
+	if r.abandoned {
+		return 0, io.EOF
+	}
+	timeout := r.archive.options().MaxTimeout
+	if r.deadline.IsZero() {
+		r.deadline = time.Now().Add(timeout)
+	}
+	reserved := r.archive.reserve(len(p))
+	if reserved == 0 && len(p) > 0 {
+		logContext.AddLogger(r.ctx).Logger().V(2).Info("Max archive size reached.")
+		var skipped int64
+		if r.size > r.read {
+			skipped = r.size - r.read
+		}
+		r.archive.reportSkip(r.ctx, r.name, sources.SkipReasonSize, skipped)
+		return 0, io.EOF
+	}
+	n, err := r.reader.Read(p[:reserved])
+	r.read += int64(n)
+	r.archive.release(reserved - n)
+	if n > 0 {
+		r.archive.progress(timeout)
+	}
+	if r.read-r.extended >= timeoutProgress {
+		r.deadline, r.extended = time.Now().Add(timeout), r.read
+	}
+	// Files read to their end are not abandoned, even late.
+	if err == nil && time.Now().After(r.deadline) {
+		logContext.AddLogger(r.ctx).Logger().V(2).Info("Abandoning archived file, timeout reached.", "filename", r.name)
+		var skipped int64
+		if r.size > r.read {
+			skipped = r.size - r.read
+		}
+		r.archive.abandon(r.ctx, r.name, skipped)
+		r.abandoned = true
+		return n, io.EOF
+	}
+	return n, err
+}
+
+// progress starts the timeout of the archive over, as one of its files was
+// read.
+func (a *Archive) progress(timeout time.Duration) {
+	if a.stalled != nil {
+		a.stalled.Reset(timeout)
+	}
+}
+
+// abandon reports a file of the archive abandoned for taking longer than the
+// timeout to extract.
+func (a *Archive) abandon(ctx context.Context, path string, size int64) {
+	a.reportSkip(ctx, path, sources.SkipReasonTimeout, size)
+	a.timedOutMu.Lock()
+	defer a.timedOutMu.Unlock()
+	a.timedOut = append(a.timedOut, memberPath(a.name, path))
+}
+
+// warnTimedOut logs the files of the archive that were abandoned for taking
+// longer than the timeout to extract, which are not scanned past what was
+// read of them.
+func (a *Archive) warnTimedOut(ctx context.Context) {
+	a.timedOutMu.Lock()
+	defer a.timedOutMu.Unlock()
+	if len(a.timedOut) == 0 {
+		return
+	}
+	logContext.AddLogger(ctx).Logger().Info("Files of archive abandoned, timeout reached.", "archive", a.name, "files", a.timedOut)
+	a.timedOut = nil
+}
+
+// reserve counts up to n bytes about to be read toward the maximum size of
+// the files extracted, and returns how many it counted. Files extracted at the
+// same time reserve what they read before reading it so that together they do
+// not read more than the maximum size.
+func (a *Archive) reserve(n int) int {
+	a.sizeMu.Lock()
+	defer a.sizeMu.Unlock()
+	remaining := a.options().MaxSize - a.size
+	if remaining <= 0 {
+		return 0
+	}
+	if n > remaining {
+		n = remaining
+	}
+	a.size += n
+	return n
+}
+
+// release uncounts n reserved bytes that were not read.
+func (a *Archive) release(n int) {
+	a.sizeMu.Lock()
+	defer a.sizeMu.Unlock()
+	a.size -= n
+}
+
+// needsSeeking reports whether extracting an archive of the format needs to
+// seek in it. Zip and 7z archives have their index at the end.
+func needsSeeking(format archiver.Format) bool {
+	switch format.(type) {
+	case archiver.Zip, *archiver.Zip, archiver.SevenZip, *archiver.SevenZip:
+		return true
+	}
+	return false
+}
+
+// readerAtSeeker is the reader zip and 7z archives are extracted from.
+type readerAtSeeker interface {
+	io.ReaderAt
+	io.Seeker
+}
+
+// readerSize returns the size of a reader from its start.
+func readerSize(r io.Seeker) (int64, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// extractZip extracts the files of a zip archive, or the text of Office
+// documents, mobile app packages and Java archives, which are zip archives,
+// like archiver.Zip does but decrypting encrypted files with the archive
+// passwords.
+func (a *Archive) extractZip(ctx context.Context, r readerAtSeeker, archiveChan chan Part) error {
+	size, err := readerSize(r)
+	if err != nil {
+		return fmt.Errorf("determining zip size: %w", err)
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	if doc, isOffice := openOfficeDocument(zr); isOffice {
+		return a.extractOffice(ctx, doc, archiveChan)
+	}
+	if kind, isMobilePackage := mobilePackageOf(zr); isMobilePackage {
+		return a.extractMobilePackage(ctx, zr, kind, archiveChan)
+	}
+	if isJavaArchive(zr) {
+		return a.extractJavaArchive(ctx, zr, archiveChan)
+	}
+	if pkg := wheelPackage(zr); pkg != nil {
+		ctx = context.WithValue(ctx, packageKey, pkg)
+	}
+	handleFile := a.extractorHandler(archiveChan)
+	return a.forEachEntry(ctx, len(zr.File), func(ctx context.Context, i int) error {
+		f := zr.File[i]
+		if err := handleFile(ctx, a.zipArchiverFile(f)); err != nil {
+			return fmt.Errorf("handling file %d: %s: %w", i, f.Name, err)
+		}
+		return nil
+	})
+}
+
+// zipArchiverFile returns a file of a zip archive as an extracted file,
+// decrypted with the archive passwords if it is encrypted.
+func (a *Archive) zipArchiverFile(f *zip.File) archiver.File {
+	open := f.Open
+	if f.Flags&0x1 != 0 {
+		opts := a.options()
+		open = func() (io.ReadCloser, error) { return openEncryptedZipFile(f, opts.Passwords, opts.MaxSize) }
+	}
+	return archiver.File{
+		FileInfo:      f.FileInfo(),
+		Header:        f.FileHeader,
+		NameInArchive: f.Name,
+		Open:          open,
+	}
+}
+
+// seekable returns reader if it can be read at any offset, or spools it to a
+// temporary file otherwise, so nested archives are not held in memory. The
+// returned function removes the file.
+func seekable(reader io.Reader) (io.Reader, func(), error) {
+	_, isReaderAt := reader.(io.ReaderAt)
+	_, isSeeker := reader.(io.Seeker)
+	if isReaderAt && isSeeker {
+		return reader, func() {}, nil
+	}
+
+	tempFile, err := os.CreateTemp("", "trufflehog-archive-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create temporary file: %w", err)
+	}
+	cleanup := func() {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+	}
+	if _, err := io.Copy(tempFile, reader); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("unable to write to temporary file: %w", err)
+	}
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return tempFile, cleanup, nil
+}
+
 // ReadToMax reads up to the max size.
 func (a *Archive) ReadToMax(ctx context.Context, reader io.Reader) (data []byte, err error) {
 	// Archiver v4 is in alpha and using an experimental version of
@@ -183,6 +750,7 @@ func (a *Archive) ReadToMax(ctx context.Context, reader io.Reader) (data []byte,
 			logger.Error(err, "Panic occurred when reading archive")
 		}
 	}()
+	maxSize := a.options().MaxSize
 	fileContent := bytes.Buffer{}
 	logger.V(5).Info("Remaining buffer capacity", "bytes", maxSize-a.size)
 	for i := 0; i <= maxSize/512; i++ {
@@ -211,35 +779,20 @@ func (a *Archive) ReadToMax(ctx context.Context, reader io.Reader) (data []byte,
 
 const (
 	arMimeType  = "application/x-unix-archive"
+	debMimeType = "application/vnd.debian.binary-package"
 	rpmMimeType = "application/x-rpm"
 )
 
-// Define a map of mime types to corresponding command-line tools
-var mimeTools = map[string][]string{
-	arMimeType:  {"ar"},
-	rpmMimeType: {"rpm2cpio", "cpio"},
-}
-
-// Check if the command-line tool is installed.
-func isToolInstalled(tool string) bool {
-	_, err := exec.LookPath(tool)
-	return err == nil
-}
-
-// Ensure all tools are available for given mime type.
-func ensureToolsForMimeType(mimeType string) error {
-	tools, exists := mimeTools[mimeType]
-	if !exists {
-		return fmt.Errorf("unsupported mime type")
-	}
-
-	for _, tool := range tools {
-		if !isToolInstalled(tool) {
-			return fmt.Errorf("Required tool " + tool + " is not installed")
-		}
+// specializedFormat returns the name of the format of the packages of a MIME
+// type handled by HandleSpecialized, to skip them as configured.
+func specializedFormat(mimeType string) string {
+	switch mimeType {
+	case arMimeType, debMimeType:
+		return "deb"
+	case rpmMimeType:
+		return "rpm"
 	}
-
-	return nil
+	return ""
 }
 
 // HandleSpecialized takes a file path and an io.Reader representing the input file,
@@ -253,16 +806,13 @@ func (a *Archive) HandleSpecialized(ctx context.Context, reader io.Reader) (io.R
 		return nil, false, err
 	}
 
+	if a.options().SkipsFormat(specializedFormat(mimeType)) {
+		return reader, false, nil
+	}
 	switch mimeType {
-	case arMimeType: // includes .deb files
-		if err := ensureToolsForMimeType(mimeType); err != nil {
-			return nil, false, err
-		}
+	case arMimeType, debMimeType:
 		reader, err = a.extractDebContent(ctx, reader)
 	case rpmMimeType:
-		if err := ensureToolsForMimeType(mimeType); err != nil {
-			return nil, false, err
-		}
 		reader, err = a.extractRpmContent(ctx, reader)
 	default:
 		return reader, false, nil
@@ -274,109 +824,115 @@ func (a *Archive) HandleSpecialized(ctx context.Context, reader io.Reader) (io.R
 	return reader, true, nil
 }
 
-// extractDebContent takes a .deb file as an io.Reader, extracts its contents
-// into a temporary directory, and returns a Reader for the extracted data archive.
-// It handles the extraction process by using the 'ar' command and manages temporary
-// files and directories for the operation.
+// extractDebContent takes a .deb file as an io.Reader and returns a Reader for
+// its data archive, the compressed tarball of the files of the package, read
+// directly from the ar archive the package is.
 // The caller is responsible for closing the returned reader.
-func (a *Archive) extractDebContent(ctx context.Context, file io.Reader) (io.ReadCloser, error) {
-	if a.currentDepth >= maxDepth {
+func (a *Archive) extractDebContent(_ context.Context, file io.Reader) (io.ReadCloser, error) {
+	if a.currentDepth >= a.options().MaxDepth {
 		return nil, fmt.Errorf("max archive depth reached")
 	}
 
-	tmpEnv, err := a.createTempEnv(ctx, file)
+	ar, err := newArReader(file)
 	if err != nil {
 		return nil, err
 	}
-	defer os.Remove(tmpEnv.tempFileName)
-	defer os.RemoveAll(tmpEnv.extractPath)
-
-	cmd := exec.Command("ar", "x", tmpEnv.tempFile.Name())
-	cmd.Dir = tmpEnv.extractPath
-	if err := executeCommand(cmd); err != nil {
-		return nil, err
-	}
-
-	handler := func(ctx context.Context, env tempEnv, file string) (string, error) {
-		if strings.HasPrefix(file, "data.tar.") {
-			return file, nil
+	for {
+		hdr, err := ar.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no data archive found in deb package")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read deb package: %w", err)
+		}
+		if strings.HasPrefix(hdr.Name, "data.tar") {
+			return io.NopCloser(ar), nil
 		}
-		return a.handleNestedFileMIME(ctx, env, file)
-	}
-
-	dataArchiveName, err := a.handleExtractedFiles(ctx, tmpEnv, handler)
-	if err != nil {
-		return nil, err
 	}
-
-	return openDataArchive(tmpEnv.extractPath, dataArchiveName)
 }
 
-// extractRpmContent takes an .rpm file as an io.Reader, extracts its contents
-// into a temporary directory, and returns a Reader for the extracted data archive.
-// It handles the extraction process by using the 'rpm2cpio' and 'cpio' commands and manages temporary
-// files and directories for the operation.
+// extractRpmContent takes an .rpm file as an io.Reader and returns a Reader
+// for the source tarball of a source package, or for a tar archive of the
+// files of other packages, from the cpio archive that is the package's
+// payload. The tar archive is written to a temporary file, which is removed
+// when the reader is closed.
 // The caller is responsible for closing the returned reader.
 func (a *Archive) extractRpmContent(ctx context.Context, file io.Reader) (io.ReadCloser, error) {
-	if a.currentDepth >= maxDepth {
+	if a.currentDepth >= a.options().MaxDepth {
 		return nil, fmt.Errorf("max archive depth reached")
 	}
 
-	tmpEnv, err := a.createTempEnv(ctx, file)
+	payload, err := rpmPayload(file)
 	if err != nil {
 		return nil, err
 	}
-	defer os.Remove(tmpEnv.tempFileName)
-	defer os.RemoveAll(tmpEnv.extractPath)
 
-	// Use rpm2cpio to convert the RPM file to a cpio archive and then extract it using cpio command.
-	cmd := exec.Command("sh", "-c", "rpm2cpio "+tmpEnv.tempFile.Name()+" | cpio -id")
-	cmd.Dir = tmpEnv.extractPath
-	if err := executeCommand(cmd); err != nil {
-		return nil, err
+	tempFile, err := os.CreateTemp("", "trufflehog-rpm-")
+	if err != nil {
+		payload.Close()
+		return nil, fmt.Errorf("unable to create temporary file: %w", err)
 	}
+	removeTemp := func() {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+	}
+	tw := tar.NewWriter(tempFile)
 
-	handler := func(ctx context.Context, env tempEnv, file string) (string, error) {
-		if strings.HasSuffix(file, ".tar.gz") {
-			return file, nil
+	cpio := newCPIOReader(payload)
+	for {
+		if common.IsDone(ctx) {
+			payload.Close()
+			removeTemp()
+			return nil, ctx.Err()
+		}
+		hdr, err := cpio.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			payload.Close()
+			removeTemp()
+			return nil, fmt.Errorf("unable to read rpm payload: %w", err)
+		}
+		if !hdr.Regular {
+			continue
+		}
+		if strings.HasSuffix(hdr.Name, ".tar.gz") {
+			removeTemp()
+			return readCloser{Reader: cpio, close: payload.Close}, nil
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: hdr.Name, Mode: 0o644, Size: hdr.Size}); err != nil {
+			payload.Close()
+			removeTemp()
+			return nil, fmt.Errorf("unable to write to temporary file: %w", err)
+		}
+		if _, err := io.Copy(tw, cpio); err != nil {
+			payload.Close()
+			removeTemp()
+			return nil, fmt.Errorf("unable to write to temporary file: %w", err)
 		}
-		return a.handleNestedFileMIME(ctx, env, file)
 	}
+	payload.Close()
 
-	dataArchiveName, err := a.handleExtractedFiles(ctx, tmpEnv, handler)
-	if err != nil {
+	if err := tw.Close(); err != nil {
+		removeTemp()
+		return nil, fmt.Errorf("unable to write to temporary file: %w", err)
+	}
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		removeTemp()
 		return nil, err
 	}
-
-	return openDataArchive(tmpEnv.extractPath, dataArchiveName)
+	return readCloser{Reader: tempFile, close: func() error { removeTemp(); return nil }}, nil
 }
 
-func (a *Archive) handleNestedFileMIME(ctx context.Context, tempEnv tempEnv, fileName string) (string, error) {
-	nestedFile, err := os.Open(filepath.Join(tempEnv.extractPath, fileName))
-	if err != nil {
-		return "", err
-	}
-	defer nestedFile.Close()
-
-	mimeType, reader, err := determineMimeType(nestedFile)
-	if err != nil {
-		return "", err
-	}
-
-	switch mimeType {
-	case arMimeType:
-		_, _, err = a.HandleSpecialized(ctx, reader)
-	case rpmMimeType:
-		_, _, err = a.HandleSpecialized(ctx, reader)
-	default:
-		return "", nil
-	}
-
-	if err != nil {
-		return "", err
-	}
+// readCloser is a reader closed by a function.
+type readCloser struct {
+	io.Reader
+	close func() error
+}
 
-	return fileName, nil
+func (r readCloser) Close() error {
+	return r.close()
 }
 
 // determineMimeType reads from the provided reader to detect the MIME type.
@@ -388,9 +944,16 @@ func determineMimeType(reader io.Reader) (string, io.Reader, error) {
 		return "", nil, fmt.Errorf("unable to read file for MIME type detection: %w", err)
 	}
 
-	// Create a new reader that starts with the buffer we just read
-	// and continues with the rest of the original reader.
-	reader = io.MultiReader(bytes.NewReader(buffer[:n]), reader)
+	// Rewind seekable readers, which archives like zip need to be extracted,
+	// or create a new reader that starts with the buffer we just read and
+	// continues with the rest of the original reader.
+	if seeker, ok := reader.(io.Seeker); ok {
+		if _, err := seeker.Seek(-int64(n), io.SeekCurrent); err != nil {
+			return "", nil, fmt.Errorf("unable to rewind file after MIME type detection: %w", err)
+		}
+	} else {
+		reader = io.MultiReader(bytes.NewReader(buffer[:n]), reader)
+	}
 
 	kind, err := filetype.Match(buffer)
 	if err != nil {
@@ -399,79 +962,3 @@ func determineMimeType(reader io.Reader) (string, io.Reader, error) {
 
 	return kind.MIME.Value, reader, nil
 }
-
-// handleExtractedFiles processes each file in the extracted directory using a provided handler function.
-// The function iterates through the files, applying the handleFile function to each, and returns the name
-// of the data archive it finds. This centralizes the logic for handling specialized files such as .deb and .rpm
-// by using the appropriate handling function passed as an argument. This design allows for flexibility and reuse
-// of this function across various extraction processes in the package.
-func (a *Archive) handleExtractedFiles(ctx context.Context, env tempEnv, handleFile func(context.Context, tempEnv, string) (string, error)) (string, error) {
-	extractedFiles, err := os.ReadDir(env.extractPath)
-	if err != nil {
-		return "", fmt.Errorf("unable to read extracted directory: %w", err)
-	}
-
-	var dataArchiveName string
-	for _, file := range extractedFiles {
-		name, err := handleFile(ctx, env, file.Name())
-		if err != nil {
-			return "", err
-		}
-		if name != "" {
-			dataArchiveName = name
-			break
-		}
-	}
-
-	return dataArchiveName, nil
-}
-
-type tempEnv struct {
-	tempFile     *os.File
-	tempFileName string
-	extractPath  string
-}
-
-// createTempEnv creates a temporary file and a temporary directory for extracting archives.
-// The caller is responsible for removing these temporary resources
-// (both the file and directory) when they are no longer needed.
-func (a *Archive) createTempEnv(ctx context.Context, file io.Reader) (tempEnv, error) {
-	tempFile, err := os.CreateTemp("", "tmp")
-	if err != nil {
-		return tempEnv{}, fmt.Errorf("unable to create temporary file: %w", err)
-	}
-
-	extractPath, err := os.MkdirTemp("", "tmp_archive")
-	if err != nil {
-		return tempEnv{}, fmt.Errorf("unable to create temporary directory: %w", err)
-	}
-
-	b, err := a.ReadToMax(ctx, file)
-	if err != nil {
-		return tempEnv{}, err
-	}
-
-	if _, err = tempFile.Write(b); err != nil {
-		return tempEnv{}, fmt.Errorf("unable to write to temporary file: %w", err)
-	}
-
-	return tempEnv{tempFile: tempFile, tempFileName: tempFile.Name(), extractPath: extractPath}, nil
-}
-
-func executeCommand(cmd *exec.Cmd) error {
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("unable to execute command: %w; error: %s", err, stderr.String())
-	}
-	return nil
-}
-
-func openDataArchive(extractPath string, dataArchiveName string) (io.ReadCloser, error) {
-	dataArchivePath := filepath.Join(extractPath, dataArchiveName)
-	dataFile, err := os.Open(dataArchivePath)
-	if err != nil {
-		return nil, fmt.Errorf("unable to open file: %w", err)
-	}
-	return dataFile, nil
-}
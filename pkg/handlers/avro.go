@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	avroSyncSize = 16
+	// avroMaxNested bounds the nesting of values, as recursive schemas
+	// like linked lists can nest records without limit.
+	avroMaxNested = 64
+)
+
+// avroCodecs are the names of the compression codecs of Avro blocks.
+var avroCodecs = map[string]string{"null": "none", "deflate": "deflate", "snappy": "snappy", "zstandard": "zstd", "bzip2": "bzip2", "xz": "xz"}
+
+// avroSchema is a node of the schema of an Avro file.
+type avroSchema struct {
+	// typ is a primitive type, or record, enum, array, map, fixed or
+	// union.
+	typ     string
+	fields  []avroField
+	symbols []string
+	// items is the schema of the items of arrays and the values of maps,
+	// and branches those of the types of unions.
+	items    *avroSchema
+	branches []*avroSchema
+	size     int
+}
+
+type avroField struct {
+	name   string
+	schema *avroSchema
+}
+
+// avroColumns returns the schema and metadata of an Avro object container
+// file, and the strings of its records, one per line after their path in
+// the record, e.g. user.token = ghp_...
+func avroColumns(r io.Reader) ([]column, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(avroMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != avroMagic {
+		return nil, errCorruptColumnar
+	}
+	d := &avroDecoder{r: br}
+	meta := make(map[string][]byte)
+	if err := d.blocks(func() error {
+		key, err := d.bytes()
+		if err != nil {
+			return err
+		}
+		value, err := d.bytes()
+		meta[string(key)] = value
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptColumnar, err)
+	}
+	sync := make([]byte, avroSyncSize)
+	if _, err := io.ReadFull(br, sync); err != nil {
+		return nil, err
+	}
+	var rawSchema interface{}
+	if err := json.Unmarshal(meta["avro.schema"], &rawSchema); err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptColumnar, err)
+	}
+	schema, err := parseAvroSchema(rawSchema, "", make(map[string]*avroSchema))
+	if err != nil {
+		return nil, err
+	}
+	codec := "none"
+	if name := meta["avro.codec"]; len(name) > 0 {
+		var ok bool
+		if codec, ok = avroCodecs[string(name)]; !ok {
+			return nil, fmt.Errorf("%w: unsupported codec %s", errCorruptColumnar, name)
+		}
+	}
+
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var metadata bytes.Buffer
+	for _, key := range keys {
+		if key != "avro.schema" && key != "avro.codec" {
+			fmt.Fprintf(&metadata, "%s = %s\n", key, meta[key])
+		}
+	}
+	columns := []column{{name: "schema", values: bytes.NewReader(meta["avro.schema"])}}
+	if metadata.Len() > 0 {
+		columns = append(columns, column{name: "metadata", values: &metadata})
+	}
+	records := &lineReader{next: func() ([][]byte, error) {
+		count, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		size, err := d.long()
+		if err != nil || count < 0 || count > maxMetadataSize || size < 0 || size > maxMetadataSize {
+			return nil, errCorruptColumnar
+		}
+		block := make([]byte, size)
+		if _, err := io.ReadFull(br, block); err != nil {
+			return nil, err
+		}
+		marker := make([]byte, avroSyncSize)
+		if _, err := io.ReadFull(br, marker); err != nil || !bytes.Equal(marker, sync) {
+			return nil, errCorruptColumnar
+		}
+		if codec == "snappy" {
+			// Snappy blocks end with their CRC-32.
+			if len(block) < 4 {
+				return nil, errCorruptColumnar
+			}
+			block = block[:len(block)-4]
+		}
+		if block, err = decompressColumnar(codec, block, 0); err != nil {
+			return nil, err
+		}
+		blockDecoder := &avroDecoder{r: bytes.NewReader(block)}
+		var lines [][]byte
+		for i := int64(0); i < count; i++ {
+			if err := blockDecoder.value(schema, "", &lines); err != nil {
+				return lines, fmt.Errorf("%w: %v", errCorruptColumnar, err)
+			}
+		}
+		return lines, nil
+	}}
+	return append(columns, column{name: "records", values: records}), nil
+}
+
+// AvroDatum decodes a single value of the Avro binary encoding with the JSON
+// of its schema, like the messages of Kafka serialized with a schema
+// registry. It returns its strings like those of the records of files.
+func AvroDatum(schema, datum []byte) ([]byte, error) {
+	var rawSchema interface{}
+	if err := json.Unmarshal(schema, &rawSchema); err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptColumnar, err)
+	}
+	s, err := parseAvroSchema(rawSchema, "", make(map[string]*avroSchema))
+	if err != nil {
+		return nil, err
+	}
+	d := &avroDecoder{r: bytes.NewReader(datum)}
+	var lines [][]byte
+	if err := d.value(s, "", &lines); err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptColumnar, err)
+	}
+	var out bytes.Buffer
+	for _, line := range lines {
+		out.Write(line)
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+// parseAvroSchema parses the JSON of a schema. Named types are registered in
+// named by their full name, and by their name in their namespace, so later
+// references to them are resolved.
+func parseAvroSchema(raw interface{}, namespace string, named map[string]*avroSchema) (*avroSchema, error) {
+	switch raw := raw.(type) {
+	case string:
+		switch raw {
+		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			return &avroSchema{typ: raw}, nil
+		}
+		if s, ok := named[raw]; ok {
+			return s, nil
+		}
+		if s, ok := named[namespace+"."+raw]; ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("%w: unknown Avro type %s", errCorruptColumnar, raw)
+	case []interface{}:
+		union := &avroSchema{typ: "union"}
+		for _, branch := range raw {
+			s, err := parseAvroSchema(branch, namespace, named)
+			if err != nil {
+				return nil, err
+			}
+			union.branches = append(union.branches, s)
+		}
+		return union, nil
+	case map[string]interface{}:
+		typ, _ := raw["type"].(string)
+		s := &avroSchema{typ: typ}
+		if name, ok := raw["name"].(string); ok {
+			if ns, ok := raw["namespace"].(string); ok {
+				namespace = ns
+			}
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				namespace = name[:i]
+			} else if namespace != "" {
+				name = namespace + "." + name
+			}
+			named[name] = s
+		}
+		switch typ {
+		case "record", "error":
+			s.typ = "record"
+			fields, _ := raw["fields"].([]interface{})
+			for _, field := range fields {
+				field, _ := field.(map[string]interface{})
+				name, _ := field["name"].(string)
+				fieldSchema, err := parseAvroSchema(field["type"], namespace, named)
+				if err != nil {
+					return nil, err
+				}
+				s.fields = append(s.fields, avroField{name: name, schema: fieldSchema})
+			}
+		case "enum":
+			symbols, _ := raw["symbols"].([]interface{})
+			for _, symbol := range symbols {
+				symbol, _ := symbol.(string)
+				s.symbols = append(s.symbols, symbol)
+			}
+		case "array", "map":
+			key := "items"
+			if typ == "map" {
+				key = "values"
+			}
+			items, err := parseAvroSchema(raw[key], namespace, named)
+			if err != nil {
+				return nil, err
+			}
+			s.items = items
+		case "fixed":
+			size, _ := raw["size"].(float64)
+			if size < 0 || size > maxMetadataSize {
+				return nil, errCorruptColumnar
+			}
+			s.size = int(size)
+		default:
+			// Primitive types with attributes, like logical types.
+			return parseAvroSchema(raw["type"], namespace, named)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("%w: invalid Avro schema", errCorruptColumnar)
+}
+
+// avroDecoder decodes values of the Avro binary encoding.
+type avroDecoder struct {
+	r interface {
+		io.Reader
+		io.ByteReader
+	}
+	depth int
+}
+
+func (d *avroDecoder) long() (int64, error) {
+	// Integers are zigzag encoded varints.
+	return binary.ReadVarint(d.r)
+}
+
+func (d *avroDecoder) bytes() ([]byte, error) {
+	n, err := d.long()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > maxMetadataSize {
+		return nil, errCorruptColumnar
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(d.r, b)
+	return b, err
+}
+
+func (d *avroDecoder) skip(n int) error {
+	_, err := io.CopyN(io.Discard, d.r, int64(n))
+	return err
+}
+
+// blocks calls item with each item of the blocks of an array or map.
+func (d *avroDecoder) blocks(item func() error) error {
+	for {
+		count, err := d.long()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		if count < 0 {
+			// Blocks with a negative count are followed by their size.
+			count = -count
+			if _, err := d.long(); err != nil {
+				return err
+			}
+		}
+		if count > maxMetadataSize {
+			return errCorruptColumnar
+		}
+		for i := int64(0); i < count; i++ {
+			if err := item(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// value decodes a value of schema, appending its strings to lines after
+// their path in the record.
+func (d *avroDecoder) value(s *avroSchema, path string, lines *[][]byte) error {
+	if d.depth++; d.depth > avroMaxNested {
+		return errCorruptColumnar
+	}
+	defer func() { d.depth-- }()
+	switch s.typ {
+	case "null":
+	case "boolean":
+		return d.skip(1)
+	case "int", "long":
+		_, err := d.long()
+		return err
+	case "float":
+		return d.skip(4)
+	case "double":
+		return d.skip(8)
+	case "fixed":
+		return d.skip(s.size)
+	case "bytes", "string":
+		b, err := d.bytes()
+		if err != nil {
+			return err
+		}
+		*lines = append(*lines, avroLine(path, b))
+	case "enum":
+		i, err := d.long()
+		if err != nil {
+			return err
+		}
+		if i >= 0 && i < int64(len(s.symbols)) {
+			*lines = append(*lines, avroLine(path, []byte(s.symbols[i])))
+		}
+	case "record":
+		for _, field := range s.fields {
+			fieldPath := field.name
+			if path != "" {
+				fieldPath = path + "." + field.name
+			}
+			if err := d.value(field.schema, fieldPath, lines); err != nil {
+				return err
+			}
+		}
+	case "array":
+		return d.blocks(func() error { return d.value(s.items, path, lines) })
+	case "map":
+		return d.blocks(func() error {
+			key, err := d.bytes()
+			if err != nil {
+				return err
+			}
+			keyPath := string(key)
+			if path != "" {
+				keyPath = path + "." + keyPath
+			}
+			return d.value(s.items, keyPath, lines)
+		})
+	case "union":
+		i, err := d.long()
+		if err != nil {
+			return err
+		}
+		if i < 0 || i >= int64(len(s.branches)) {
+			return errCorruptColumnar
+		}
+		return d.value(s.branches[i], path, lines)
+	default:
+		return fmt.Errorf("%w: unknown Avro type %s", errCorruptColumnar, s.typ)
+	}
+	return nil
+}
+
+// avroLine formats a string of a record after its path.
+func avroLine(path string, value []byte) []byte {
+	if path == "" {
+		return value
+	}
+	return append([]byte(path+" = "), value...)
+}
@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// The chunk types of Android binary XML files and resource tables.
+const (
+	resStringPoolType   = 0x0001
+	resTableType        = 0x0002
+	resXMLType          = 0x0003
+	resXMLStartNS       = 0x0100
+	resXMLStartElement  = 0x0102
+	resXMLEndElement    = 0x0103
+	resXMLCData         = 0x0104
+	resTablePackageType = 0x0200
+	resTableTypeType    = 0x0201
+	resStringPoolUTF8   = 1 << 8
+	resNoEntry          = 0xffffffff
+	// The types of values read as text: strings, references, and
+	// numbers and booleans.
+	resValueReference = 0x01
+	resValueString    = 0x03
+	resValueFloat     = 0x04
+	resValueIntDec    = 0x10
+	resValueIntHex    = 0x11
+	resValueBoolean   = 0x12
+	// resEntryComplex marks entries of resource tables that are bags, like
+	// string arrays, and resEntryCompact entries whose value is in the
+	// entry. resTypeSparse and resTypeOffset16 mark types whose entry
+	// offsets are listed with the index of their entry, and in 16 bits.
+	resEntryComplex = 0x1
+	resEntryCompact = 0x8
+	resTypeSparse   = 0x1
+	resTypeOffset16 = 0x2
+	// resMaxStrings bounds the strings of string pools.
+	resMaxStrings = 1 << 22
+)
+
+var errCorruptAndroidResource = errors.New("corrupt Android resource")
+
+// resChunk is a chunk of an Android binary resource: a header, whose size
+// includes the fields of the chunk type, and a body.
+type resChunk struct {
+	kind       uint16
+	headerSize int
+	data       []byte
+}
+
+// readResChunk reads the chunk at the start of data.
+func readResChunk(data []byte) (resChunk, error) {
+	if len(data) < 8 {
+		return resChunk{}, errCorruptAndroidResource
+	}
+	c := resChunk{
+		kind:       binary.LittleEndian.Uint16(data),
+		headerSize: int(binary.LittleEndian.Uint16(data[2:])),
+	}
+	size := int(binary.LittleEndian.Uint32(data[4:]))
+	if c.headerSize < 8 || size < c.headerSize || size > len(data) {
+		return resChunk{}, errCorruptAndroidResource
+	}
+	c.data = data[:size]
+	return c, nil
+}
+
+// resChunks returns the chunks of data, one after the other.
+func resChunks(data []byte) ([]resChunk, error) {
+	var chunks []resChunk
+	for len(data) > 0 {
+		c, err := readResChunk(data)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, c)
+		data = data[len(c.data):]
+	}
+	return chunks, nil
+}
+
+// parseStringPool returns the strings of a string pool chunk, in UTF-8 or
+// UTF-16.
+func parseStringPool(c resChunk) ([]string, error) {
+	if c.kind != resStringPoolType || c.headerSize < 28 {
+		return nil, errCorruptAndroidResource
+	}
+	count := int(binary.LittleEndian.Uint32(c.data[8:]))
+	flags := binary.LittleEndian.Uint32(c.data[16:])
+	start := int(binary.LittleEndian.Uint32(c.data[20:]))
+	if count > resMaxStrings || c.headerSize+4*count > len(c.data) || start > len(c.data) {
+		return nil, errCorruptAndroidResource
+	}
+	pool := make([]string, count)
+	for i := range pool {
+		offset := start + int(binary.LittleEndian.Uint32(c.data[c.headerSize+4*i:]))
+		if offset < 0 || offset >= len(c.data) {
+			return nil, errCorruptAndroidResource
+		}
+		var ok bool
+		if flags&resStringPoolUTF8 != 0 {
+			pool[i], ok = resUTF8String(c.data[offset:])
+		} else {
+			pool[i], ok = resUTF16String(c.data[offset:])
+		}
+		if !ok {
+			return nil, errCorruptAndroidResource
+		}
+	}
+	return pool, nil
+}
+
+// resUTF8String reads a UTF-8 string of a string pool: its length in UTF-16
+// units, then in bytes, each on one or two bytes, then its bytes.
+func resUTF8String(data []byte) (string, bool) {
+	length := func() (int, bool) {
+		if len(data) < 1 {
+			return 0, false
+		}
+		n := int(data[0])
+		if n&0x80 == 0 {
+			data = data[1:]
+			return n, true
+		}
+		if len(data) < 2 {
+			return 0, false
+		}
+		n = (n&0x7f)<<8 | int(data[1])
+		data = data[2:]
+		return n, true
+	}
+	if _, ok := length(); !ok {
+		return "", false
+	}
+	n, ok := length()
+	if !ok || n > len(data) {
+		return "", false
+	}
+	return string(data[:n]), true
+}
+
+// resUTF16String reads a UTF-16 string of a string pool: its length in
+// units, on one or two units, then its units.
+func resUTF16String(data []byte) (string, bool) {
+	if len(data) < 2 {
+		return "", false
+	}
+	n := int(binary.LittleEndian.Uint16(data))
+	data = data[2:]
+	if n&0x8000 != 0 {
+		if len(data) < 2 {
+			return "", false
+		}
+		n = (n&0x7fff)<<16 | int(binary.LittleEndian.Uint16(data))
+		data = data[2:]
+	}
+	if 2*n > len(data) {
+		return "", false
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units)), true
+}
+
+// resString returns a string of a pool by index, or "" for no string.
+func resString(pool []string, index uint32) string {
+	if int64(index) >= int64(len(pool)) {
+		return ""
+	}
+	return pool[index]
+}
+
+// formatResValue returns the text of a typed value of a resource.
+func formatResValue(pool []string, dataType byte, data uint32) (string, bool) {
+	switch dataType {
+	case resValueString:
+		return resString(pool, data), true
+	case resValueReference:
+		return fmt.Sprintf("@0x%08x", data), true
+	case resValueIntDec:
+		return strconv.Itoa(int(int32(data))), true
+	case resValueIntHex:
+		return fmt.Sprintf("0x%x", data), true
+	case resValueBoolean:
+		return strconv.FormatBool(data != 0), true
+	}
+	return "", false
+}
+
+// decodeAXML decodes an Android binary XML file, like AndroidManifest.xml or
+// the layouts of APKs, into XML text, one element per line.
+func decodeAXML(data []byte) ([]byte, error) {
+	root, err := readResChunk(data)
+	if err != nil || root.kind != resXMLType {
+		return nil, errCorruptAndroidResource
+	}
+	chunks, err := resChunks(root.data[root.headerSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	var pool []string
+	prefixes := make(map[string]string)
+	var out strings.Builder
+	depth := 0
+	for _, c := range chunks {
+		body := c.data[c.headerSize:]
+		switch c.kind {
+		case resStringPoolType:
+			if pool, err = parseStringPool(c); err != nil {
+				return nil, err
+			}
+		case resXMLStartNS:
+			if len(body) >= 8 {
+				prefixes[resString(pool, binary.LittleEndian.Uint32(body[4:]))] = resString(pool, binary.LittleEndian.Uint32(body))
+			}
+		case resXMLStartElement:
+			if len(body) < 20 {
+				return nil, errCorruptAndroidResource
+			}
+			out.WriteString(strings.Repeat("  ", depth) + "<" + resString(pool, binary.LittleEndian.Uint32(body[4:])))
+			attrStart := int(binary.LittleEndian.Uint16(body[8:]))
+			attrSize := int(binary.LittleEndian.Uint16(body[10:]))
+			attrCount := int(binary.LittleEndian.Uint16(body[12:]))
+			if attrSize < 20 || attrStart+attrSize*attrCount > len(body) {
+				return nil, errCorruptAndroidResource
+			}
+			for i := 0; i < attrCount; i++ {
+				attr := body[attrStart+attrSize*i:]
+				name := resString(pool, binary.LittleEndian.Uint32(attr[4:]))
+				if prefix := prefixes[resString(pool, binary.LittleEndian.Uint32(attr))]; prefix != "" {
+					name = prefix + ":" + name
+				}
+				value, ok := "", false
+				if raw := binary.LittleEndian.Uint32(attr[8:]); raw != resNoEntry {
+					value, ok = resString(pool, raw), true
+				} else {
+					value, ok = formatResValue(pool, attr[15], binary.LittleEndian.Uint32(attr[16:]))
+				}
+				if ok {
+					out.WriteString(" " + name + "=" + strconv.Quote(value))
+				}
+			}
+			out.WriteString(">\n")
+			depth++
+		case resXMLEndElement:
+			if depth > 0 {
+				depth--
+			}
+			if len(body) >= 8 {
+				out.WriteString(strings.Repeat("  ", depth) + "</" + resString(pool, binary.LittleEndian.Uint32(body[4:])) + ">\n")
+			}
+		case resXMLCData:
+			if len(body) >= 4 {
+				out.WriteString(strings.Repeat("  ", depth) + resString(pool, binary.LittleEndian.Uint32(body)) + "\n")
+			}
+		}
+	}
+	return []byte(out.String()), nil
+}
+
+// decodeARSC decodes the resources of the resources.arsc table of an APK
+// whose values are strings, numbers or booleans, one per line, like
+// string/api_key = value. Resources with several configurations, like
+// translations, have a line for each.
+func decodeARSC(data []byte) ([]byte, error) {
+	table, err := readResChunk(data)
+	if err != nil || table.kind != resTableType {
+		return nil, errCorruptAndroidResource
+	}
+	chunks, err := resChunks(table.data[table.headerSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	var out strings.Builder
+	for _, c := range chunks {
+		switch c.kind {
+		case resStringPoolType:
+			if values, err = parseStringPool(c); err != nil {
+				return nil, err
+			}
+		case resTablePackageType:
+			if err := decodeARSCPackage(c, values, &out); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return []byte(out.String()), nil
+}
+
+// decodeARSCPackage decodes the resources of a package of a resource table,
+// whose type and key names are in string pools of the package.
+func decodeARSCPackage(pkg resChunk, values []string, out *strings.Builder) error {
+	if pkg.headerSize < 8+4+256+16 {
+		return errCorruptAndroidResource
+	}
+	typeStrings := int(binary.LittleEndian.Uint32(pkg.data[8+4+256:]))
+	keyStrings := int(binary.LittleEndian.Uint32(pkg.data[8+4+256+8:]))
+	pool := func(offset int) ([]string, error) {
+		if offset <= 0 || offset >= len(pkg.data) {
+			return nil, errCorruptAndroidResource
+		}
+		c, err := readResChunk(pkg.data[offset:])
+		if err != nil {
+			return nil, err
+		}
+		return parseStringPool(c)
+	}
+	types, err := pool(typeStrings)
+	if err != nil {
+		return err
+	}
+	keys, err := pool(keyStrings)
+	if err != nil {
+		return err
+	}
+	chunks, err := resChunks(pkg.data[pkg.headerSize:])
+	if err != nil {
+		return err
+	}
+
+	for _, c := range chunks {
+		if c.kind != resTableTypeType || c.headerSize < 20 {
+			continue
+		}
+		typeName := resString(types, uint32(c.data[8])-1)
+		flags := c.data[9]
+		count := int(binary.LittleEndian.Uint32(c.data[12:]))
+		entriesStart := int(binary.LittleEndian.Uint32(c.data[16:]))
+		offsets := c.data[c.headerSize:]
+		if entriesStart > len(c.data) {
+			return errCorruptAndroidResource
+		}
+		entries := c.data[entriesStart:]
+
+		for i := 0; i < count; i++ {
+			var offset int
+			switch {
+			case flags&resTypeSparse != 0:
+				// Sparse entries are an index and an offset in 4 byte
+				// units.
+				if 4*i+4 > len(offsets) {
+					return errCorruptAndroidResource
+				}
+				offset = 4 * int(binary.LittleEndian.Uint16(offsets[4*i+2:]))
+			case flags&resTypeOffset16 != 0:
+				if 2*i+2 > len(offsets) {
+					return errCorruptAndroidResource
+				}
+				o := binary.LittleEndian.Uint16(offsets[2*i:])
+				if o == 0xffff {
+					continue
+				}
+				offset = 4 * int(o)
+			default:
+				if 4*i+4 > len(offsets) {
+					return errCorruptAndroidResource
+				}
+				o := binary.LittleEndian.Uint32(offsets[4*i:])
+				if o == resNoEntry {
+					continue
+				}
+				offset = int(o)
+			}
+			if offset+8 > len(entries) {
+				return errCorruptAndroidResource
+			}
+			entry := entries[offset:]
+			entryFlags := binary.LittleEndian.Uint16(entry[2:])
+
+			switch {
+			case entryFlags&resEntryCompact != 0:
+				// Compact entries hold their key, and the type of their
+				// value in their flags.
+				key := resString(keys, uint32(binary.LittleEndian.Uint16(entry)))
+				if value, ok := formatResValue(values, byte(entryFlags>>8), binary.LittleEndian.Uint32(entry[4:])); ok {
+					fmt.Fprintf(out, "%s/%s = %s\n", typeName, key, value)
+				}
+			case entryFlags&resEntryComplex != 0:
+				// Bags, like string arrays, have a parent, then values
+				// named by resource IDs.
+				key := resString(keys, binary.LittleEndian.Uint32(entry[4:]))
+				size := int(binary.LittleEndian.Uint16(entry))
+				if size < 16 || offset+size > len(entries) {
+					return errCorruptAndroidResource
+				}
+				bagCount := int(binary.LittleEndian.Uint32(entry[12:]))
+				for j := 0; j < bagCount && size+12*(j+1) <= len(entry); j++ {
+					item := entry[size+12*j:]
+					if value, ok := formatResValue(values, item[7], binary.LittleEndian.Uint32(item[8:])); ok && item[7] != resValueReference {
+						fmt.Fprintf(out, "%s/%s = %s\n", typeName, key, value)
+					}
+				}
+			default:
+				key := resString(keys, binary.LittleEndian.Uint32(entry[4:]))
+				size := int(binary.LittleEndian.Uint16(entry))
+				if size < 8 || offset+size+8 > len(entries) {
+					return errCorruptAndroidResource
+				}
+				value := entry[size:]
+				if text, ok := formatResValue(values, value[3], binary.LittleEndian.Uint32(value[4:])); ok {
+					fmt.Fprintf(out, "%s/%s = %s\n", typeName, key, text)
+				}
+			}
+		}
+	}
+	return nil
+}
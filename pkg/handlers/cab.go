@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// cabFormat is the format name of Microsoft cabinets, the archives of driver
+// bundles, Windows updates and the payloads of MSI packages.
+const cabFormat = "cab"
+
+const (
+	cabMagic          = "MSCF"
+	cabHeaderSize     = 36
+	cabFolderSize     = 8
+	cabFileSize       = 16
+	cabDataSize       = 8
+	cabFlagPrev       = 0x1
+	cabFlagNext       = 0x2
+	cabFlagReserve    = 0x4
+	cabCompressNone   = 0
+	cabCompressMSZIP  = 1
+	cabCompressLZX    = 3
+	cabCompressMask   = 0xf
+	cabFolderContinue = 0xfffd
+	cabNameUTF8       = 0x80
+	cabMaxName        = 256
+	cabMSZIPSignature = "CK"
+)
+
+var (
+	errCorruptCabinet = errors.New("corrupt cabinet")
+	// errUnsupportedCabinet is returned for folders compressed with
+	// Quantum, or continued in another cabinet.
+	errUnsupportedCabinet = errors.New("unsupported cabinet folder")
+)
+
+// cabFolder is a folder of a cabinet, a compressed stream of files.
+type cabFolder struct {
+	offset      int64
+	blocks      int
+	compression uint16
+}
+
+// cabFile is a file of a folder of a cabinet.
+type cabFile struct {
+	name   string
+	size   int64
+	offset int64
+	folder int
+}
+
+type cabinet struct {
+	r           io.ReaderAt
+	size        int64
+	folders     []cabFolder
+	files       []cabFile
+	dataReserve int64
+}
+
+// identifyCabinet returns whether a file is a cabinet from its header. The
+// returned reader must be read instead of reader.
+func identifyCabinet(reader io.Reader) (io.Reader, bool) {
+	br := bufio.NewReader(reader)
+	header, _ := br.Peek(cabHeaderSize)
+	return br, len(header) == cabHeaderSize && bytes.HasPrefix(header, []byte(cabMagic)) &&
+		binary.LittleEndian.Uint32(header[4:]) == 0 && header[25] == 1
+}
+
+func openCabinet(r io.ReaderAt, size int64) (*cabinet, error) {
+	header := make([]byte, cabHeaderSize)
+	if err := readAt(r, header, 0); err != nil {
+		return nil, errCorruptCabinet
+	}
+	c := &cabinet{r: r, size: size}
+	folders := int(binary.LittleEndian.Uint16(header[26:]))
+	files := int(binary.LittleEndian.Uint16(header[28:]))
+	flags := binary.LittleEndian.Uint16(header[30:])
+	offset := int64(cabHeaderSize)
+	var folderReserve int64
+	if flags&cabFlagReserve != 0 {
+		reserve := make([]byte, 4)
+		if err := readAt(r, reserve, offset); err != nil {
+			return nil, errCorruptCabinet
+		}
+		folderReserve, c.dataReserve = int64(reserve[2]), int64(reserve[3])
+		offset += 4 + int64(binary.LittleEndian.Uint16(reserve))
+	}
+	// The names of the previous and next cabinets of a set, and of their
+	// disks.
+	for _, flag := range []uint16{cabFlagPrev, cabFlagNext} {
+		if flags&flag == 0 {
+			continue
+		}
+		for i := 0; i < 2; i++ {
+			name, err := c.readName(offset)
+			if err != nil {
+				return nil, err
+			}
+			offset += int64(len(name)) + 1
+		}
+	}
+
+	folder := make([]byte, cabFolderSize)
+	for i := 0; i < folders; i++ {
+		if err := readAt(r, folder, offset); err != nil {
+			return nil, errCorruptCabinet
+		}
+		c.folders = append(c.folders, cabFolder{
+			offset:      int64(binary.LittleEndian.Uint32(folder)),
+			blocks:      int(binary.LittleEndian.Uint16(folder[4:])),
+			compression: binary.LittleEndian.Uint16(folder[6:]),
+		})
+		offset += cabFolderSize + folderReserve
+	}
+
+	offset = int64(binary.LittleEndian.Uint32(header[16:]))
+	file := make([]byte, cabFileSize)
+	for i := 0; i < files; i++ {
+		if err := readAt(r, file, offset); err != nil {
+			return nil, errCorruptCabinet
+		}
+		name, err := c.readName(offset + cabFileSize)
+		if err != nil {
+			return nil, err
+		}
+		offset += cabFileSize + int64(len(name)) + 1
+		if binary.LittleEndian.Uint16(file[14:])&cabNameUTF8 == 0 {
+			// Other names are in the code page of the system that made the
+			// cabinet, and are read as Latin-1.
+			runes := make([]rune, len(name))
+			for j := 0; j < len(name); j++ {
+				runes[j] = rune(name[j])
+			}
+			name = string(runes)
+		}
+		c.files = append(c.files, cabFile{
+			name:   strings.ReplaceAll(name, "\\", "/"),
+			size:   int64(binary.LittleEndian.Uint32(file)),
+			offset: int64(binary.LittleEndian.Uint32(file[4:])),
+			folder: int(binary.LittleEndian.Uint16(file[8:])),
+		})
+	}
+	return c, nil
+}
+
+// readName reads a NUL-terminated name at an offset.
+func (c *cabinet) readName(offset int64) (string, error) {
+	buf := make([]byte, cabMaxName)
+	n, _ := c.r.ReadAt(buf, offset)
+	end := bytes.IndexByte(buf[:n], 0)
+	if end < 0 {
+		return "", errCorruptCabinet
+	}
+	return string(buf[:end]), nil
+}
+
+// cabFolderReader reads the decompressed content of a folder, data block by
+// data block.
+type cabFolderReader struct {
+	c       *cabinet
+	folder  cabFolder
+	offset  int64
+	block   int
+	lzx     *lzxDecoder
+	history []byte
+	buf     []byte
+}
+
+func (c *cabinet) openFolder(i int) (*cabFolderReader, error) {
+	if i >= len(c.folders) {
+		return nil, errCorruptCabinet
+	}
+	f := &cabFolderReader{c: c, folder: c.folders[i], offset: c.folders[i].offset}
+	switch f.folder.compression & cabCompressMask {
+	case cabCompressNone, cabCompressMSZIP:
+	case cabCompressLZX:
+		lzx, err := newLZXDecoder(int(f.folder.compression>>8&0x1f), false)
+		if err != nil {
+			return nil, errCorruptCabinet
+		}
+		f.lzx = lzx
+	default:
+		return nil, errUnsupportedCabinet
+	}
+	return f, nil
+}
+
+func (f *cabFolderReader) Read(p []byte) (int, error) {
+	for len(f.buf) == 0 {
+		if f.block >= f.folder.blocks {
+			return 0, io.EOF
+		}
+		if err := f.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+// next decompresses the next data block of the folder.
+func (f *cabFolderReader) next() error {
+	header := make([]byte, cabDataSize)
+	if err := readAt(f.c.r, header, f.offset); err != nil {
+		return errCorruptCabinet
+	}
+	size := int64(binary.LittleEndian.Uint16(header[4:]))
+	uncompressed := int(binary.LittleEndian.Uint16(header[6:]))
+	f.offset += cabDataSize + f.c.dataReserve
+	data := make([]byte, size)
+	if err := readAt(f.c.r, data, f.offset); err != nil {
+		return errCorruptCabinet
+	}
+	f.offset += size
+	f.block++
+	if uncompressed == 0 {
+		// The block is continued in the next cabinet of a set.
+		return errUnsupportedCabinet
+	}
+
+	switch f.folder.compression & cabCompressMask {
+	case cabCompressNone:
+		f.buf = data
+	case cabCompressMSZIP:
+		// Each block is a deflate stream with the previous block as its
+		// dictionary.
+		if !bytes.HasPrefix(data, []byte(cabMSZIPSignature)) {
+			return errCorruptCabinet
+		}
+		out := make([]byte, uncompressed)
+		if _, err := io.ReadFull(flate.NewReaderDict(bytes.NewReader(data[2:]), f.history), out); err != nil {
+			return errCorruptCabinet
+		}
+		f.buf, f.history = out, out
+	case cabCompressLZX:
+		out, err := f.lzx.decodeFrame(data, uncompressed)
+		if err != nil {
+			return err
+		}
+		f.buf = out
+	}
+	return nil
+}
+
+// extractCabinet extracts the files of a cabinet, folder by folder. Files of
+// folders compressed with Quantum, or continued from or in other cabinets of
+// a set, are skipped. Cabinets that cannot be read are scanned as they are.
+func (a *Archive) extractCabinet(ctx context.Context, depth int, path string, reader io.Reader, archiveChan chan Part) error {
+	logger := logContext.AddLogger(ctx).Logger()
+	logger.V(3).Info("Handling cabinet.")
+	seekableReader, cleanup, err := seekable(reader)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	r := seekableReader.(readerAtSeeker)
+	size, err := readerSize(r)
+	if err != nil {
+		return err
+	}
+	c, err := openCabinet(r, size)
+	if err != nil {
+		logger.V(2).Info("Error reading cabinet, scanning it as it is.", "error", err)
+		return sendParts(ctx, path, io.NewSectionReader(r, 0, size), archiveChan)
+	}
+
+	byFolder := make(map[int][]cabFile)
+	for _, f := range c.files {
+		if f.folder >= cabFolderContinue {
+			a.reportSkip(ctx, memberPath(path, f.name), sources.SkipReasonUnsupported, f.size)
+			continue
+		}
+		byFolder[f.folder] = append(byFolder[f.folder], f)
+	}
+	for i := range c.folders {
+		files := byFolder[i]
+		sort.SliceStable(files, func(j, k int) bool { return files[j].offset < files[k].offset })
+		if err := a.extractCabinetFolder(ctx, depth, path, c, i, files, archiveChan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractCabinetFolder extracts the files of a folder, in the order of their
+// offsets in it.
+func (a *Archive) extractCabinetFolder(ctx context.Context, depth int, path string, c *cabinet, folder int, files []cabFile, archiveChan chan Part) error {
+	logger := logContext.AddLogger(ctx).Logger()
+	var (
+		content  *cabFolderReader
+		position int64
+	)
+	for i, f := range files {
+		name := memberPath(path, f.name)
+		// Files that overlap the previous one are read from the start of
+		// the folder again.
+		if content == nil || f.offset < position {
+			var err error
+			if content, err = c.openFolder(folder); err != nil {
+				for _, skipped := range files[i:] {
+					a.reportSkip(ctx, memberPath(path, skipped.name), sources.SkipReasonUnsupported, skipped.size)
+				}
+				return nil
+			}
+			position = 0
+		}
+		skipped, err := io.CopyN(io.Discard, content, f.offset-position)
+		position += skipped
+		if err == nil {
+			file := io.LimitReader(content, f.size)
+			err = a.openArchive(ctx, depth+1, name, a.limitReader(ctx, name, file), archiveChan)
+			if errors.Is(err, errMaxArchiveDepthReached) {
+				a.reportSkip(ctx, name, sources.SkipReasonDepth, f.size)
+			}
+			if err == nil {
+				_, err = io.Copy(io.Discard, file)
+			}
+			position = f.offset + f.size - file.(*io.LimitedReader).N
+		}
+		switch {
+		case errors.Is(err, errCorruptCabinet), errors.Is(err, errCorruptLZX), errors.Is(err, errUnsupportedCabinet), errors.Is(err, io.EOF):
+			logger.V(2).Info("Error reading cabinet folder.", "folder", folder, "error", err)
+			for _, skipped := range files[i:] {
+				a.reportSkip(ctx, memberPath(path, skipped.name), sources.SkipReasonError, skipped.size)
+			}
+			return nil
+		case err != nil:
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bitWriter16 writes bits from the most significant bit of 16-bit little
+// endian words, like LZX and XPRESS.
+type bitWriter16 struct {
+	out []byte
+	cur uint16
+	n   uint
+}
+
+func (w *bitWriter16) write(v uint32, n uint) {
+	for i := n; i > 0; i-- {
+		w.cur = w.cur<<1 | uint16(v>>(i-1)&1)
+		w.n++
+		if w.n == 16 {
+			w.out = binary.LittleEndian.AppendUint16(w.out, w.cur)
+			w.cur, w.n = 0, 0
+		}
+	}
+}
+
+// align pads the bits to a word.
+func (w *bitWriter16) align() {
+	if w.n > 0 {
+		w.write(0, 16-w.n)
+	}
+}
+
+// lzxOp is a literal, or a match if length is not 0.
+type lzxOp struct {
+	literal        string
+	length, offset int
+}
+
+// lzxWriter writes LZX data with codes of 9 bits for the main tree and no
+// length tree, so matches are at most 8 bytes.
+type lzxWriter struct {
+	bitWriter16
+	slots int
+	wim   bool
+}
+
+func newLZXWriter(windowBits int, wim bool) *lzxWriter {
+	w := &lzxWriter{slots: lzxPositionSlots[windowBits-lzxMinWindowBits], wim: wim}
+	if !wim {
+		// No x86 call translation.
+		w.write(0, 1)
+	}
+	return w
+}
+
+func (w *lzxWriter) blockHeader(blockType, size int) {
+	w.write(uint32(blockType), 3)
+	switch {
+	case !w.wim:
+		w.write(uint32(size), 24)
+	case size == 32768:
+		w.write(1, 1)
+	default:
+		w.write(0, 1)
+		w.write(uint32(size), 16)
+	}
+}
+
+// lengths writes the lengths of part of a tree whose previous lengths are 0,
+// all 9 or all 0, with a pretree of symbol 0 (unchanged) and 8 (9).
+func (w *lzxWriter) lengths(n int, nine bool) {
+	for i := 0; i < lzxPretreeSize; i++ {
+		if i == 0 || i == 8 {
+			w.write(1, 4)
+		} else {
+			w.write(0, 4)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if nine {
+			w.write(1, 1)
+		} else {
+			w.write(0, 1)
+		}
+	}
+}
+
+// verbatim writes the header and trees of a verbatim block, then ops.
+func (w *lzxWriter) verbatim(size int, ops ...lzxOp) {
+	w.blockHeader(lzxBlockVerbatim, size)
+	w.lengths(lzxNumChars, true)
+	w.lengths(8*w.slots, true)
+	w.lengths(lzxLengthSize, false)
+	w.ops(ops...)
+}
+
+func (w *lzxWriter) ops(ops ...lzxOp) {
+	for _, op := range ops {
+		if op.length == 0 {
+			for i := 0; i < len(op.literal); i++ {
+				w.write(uint32(op.literal[i]), 9)
+			}
+			continue
+		}
+		formatted := uint32(op.offset + 2)
+		slot := 3
+		for lzxPositionBase[slot+1] <= formatted {
+			slot++
+		}
+		w.write(uint32(lzxNumChars+slot<<3|(op.length-lzxMinMatch)), 9)
+		w.write(formatted-lzxPositionBase[slot], lzxExtraBits[slot])
+	}
+}
+
+// uncompressed writes an uncompressed block.
+func (w *lzxWriter) uncompressed(data string) {
+	w.blockHeader(lzxBlockUncompressed, len(data))
+	if w.n == 0 {
+		w.write(0, 16)
+	}
+	w.align()
+	for i := 0; i < 3; i++ {
+		w.out = binary.LittleEndian.AppendUint32(w.out, 1)
+	}
+	w.out = append(w.out, data...)
+	if len(data)%2 == 1 {
+		w.out = append(w.out, 0)
+	}
+}
+
+// frame ends a frame, and returns its data.
+func (w *lzxWriter) frame() []byte {
+	w.align()
+	out := w.out
+	w.out = nil
+	return out
+}
+
+type cabBlock struct {
+	data []byte
+	size int
+}
+
+// makeCabinet returns a cabinet of one folder, of compressed data blocks, with
+// files of sizes.
+func makeCabinet(t *testing.T, compression uint16, names []string, sizes []int, blocks []cabBlock) []byte {
+	t.Helper()
+	require.Len(t, sizes, len(names))
+	var files []byte
+	offset := 0
+	for i, name := range names {
+		files = binary.LittleEndian.AppendUint32(files, uint32(sizes[i]))
+		files = binary.LittleEndian.AppendUint32(files, uint32(offset))
+		files = append(files, make([]byte, 8)...)
+		files = append(append(files, name...), 0)
+		offset += sizes[i]
+	}
+	dataStart := cabHeaderSize + cabFolderSize + len(files)
+
+	header := make([]byte, cabHeaderSize)
+	copy(header, cabMagic)
+	binary.LittleEndian.PutUint32(header[16:], cabHeaderSize+cabFolderSize)
+	header[24], header[25] = 3, 1
+	binary.LittleEndian.PutUint16(header[26:], 1)
+	binary.LittleEndian.PutUint16(header[28:], uint16(len(names)))
+	folder := binary.LittleEndian.AppendUint32(nil, uint32(dataStart))
+	folder = binary.LittleEndian.AppendUint16(folder, uint16(len(blocks)))
+	folder = binary.LittleEndian.AppendUint16(folder, compression)
+
+	cab := append(append(header, folder...), files...)
+	for _, b := range blocks {
+		cab = binary.LittleEndian.AppendUint32(cab, 0)
+		cab = binary.LittleEndian.AppendUint16(cab, uint16(len(b.data)))
+		cab = binary.LittleEndian.AppendUint16(cab, uint16(b.size))
+		cab = append(cab, b.data...)
+	}
+	binary.LittleEndian.PutUint32(cab[8:], uint32(len(cab)))
+	return cab
+}
+
+func TestHandleFile_Cabinet(t *testing.T) {
+	readme, config := "password = readme\n", "password = hunter2\npassword = hunter2\n"
+	names, sizes := []string{"readme.txt", `config\app.ini`}, []int{len(readme), len(config)}
+	want := map[string]string{"readme.txt": readme, "config/app.ini": config}
+
+	t.Run("stored", func(t *testing.T) {
+		content := []byte(readme + config)
+		cab := makeCabinet(t, cabCompressNone, names, sizes, []cabBlock{{content[:20], 20}, {content[20:], len(content) - 20}})
+		assert.Equal(t, want, handleNamedFile(t, "driver.cab", cab))
+	})
+
+	t.Run("mszip", func(t *testing.T) {
+		// The second block refers to the first.
+		var blocks []cabBlock
+		var history []byte
+		for _, part := range []string{readme + config[:19], config[19:]} {
+			var buf bytes.Buffer
+			w, err := flate.NewWriterDict(&buf, flate.BestCompression, history)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(part))
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			blocks = append(blocks, cabBlock{append([]byte(cabMSZIPSignature), buf.Bytes()...), len(part)})
+			history = []byte(part)
+		}
+		assert.Equal(t, want, handleNamedFile(t, "driver.cab", makeCabinet(t, cabCompressMSZIP, names, sizes, blocks)))
+	})
+
+	t.Run("lzx", func(t *testing.T) {
+		// A block of two frames, whose second frame refers to the first,
+		// then an uncompressed block.
+		padding := strings.Repeat("x", 32768-len(readme))
+		w := newLZXWriter(16, false)
+		ops := []lzxOp{{literal: "x"}}
+		for left := len(padding) - 1; left > 0; left -= 8 {
+			n := 8
+			if left < n {
+				n = left
+			}
+			ops = append(ops, lzxOp{length: n, offset: 1})
+		}
+		ops = append(ops, lzxOp{literal: readme})
+		w.verbatim(32768+19, ops...)
+		first := w.frame()
+		w.ops(lzxOp{length: 8, offset: len(readme)}, lzxOp{literal: " = hunter2\n"})
+		w.uncompressed(config[19:])
+		second := w.frame()
+
+		names, sizes := []string{"padding", "readme.txt", `config\app.ini`}, []int{len(padding), len(readme), len(config)}
+		cab := makeCabinet(t, cabCompressLZX|16<<8, names, sizes, []cabBlock{{first, 32768}, {second, len(config)}})
+		files := handleNamedFile(t, "driver.cab", cab)
+		assert.Equal(t, padding, files["padding"])
+		delete(files, "padding")
+		assert.Equal(t, map[string]string{"readme.txt": readme, "config/app.ini": config}, files)
+	})
+}
+
+func TestHandleFile_CabinetQuantum(t *testing.T) {
+	// Folders compressed with Quantum are not read.
+	cab := makeCabinet(t, 2, []string{"app.ini"}, []int{4}, []cabBlock{{[]byte("data"), 4}})
+	assert.Empty(t, handleNamedFile(t, "driver.cab", cab))
+}
+
+func TestLZXUndoE8(t *testing.T) {
+	data := []byte{0xe8, 0x10, 0, 0, 0, 0x90, 0xe8, 0xfe, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0}
+	lzxUndoE8(data, 100, 1000)
+	assert.Equal(t, []byte{0xe8, 0xac, 0xff, 0xff, 0xff, 0x90, 0xe8, 0xe6, 0x03, 0, 0}, data[:11])
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"unicode/utf16"
+)
+
+// cfbMagic starts compound files, the OLE container of legacy Office files,
+// embedded OLE objects and VBA projects.
+var cfbMagic = []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}
+
+const (
+	cfbHeaderSize    = 512
+	cfbDirEntrySize  = 128
+	cfbMiniCutoff    = 4096
+	cfbMiniSector    = 64
+	cfbHeaderDIFAT   = 109
+	cfbEndOfChain    = 0xfffffffe
+	cfbMaxRegular    = 0xfffffffa
+	cfbNoStream      = 0xffffffff
+	cfbTypeStorage   = 1
+	cfbTypeStream    = 2
+	cfbTypeRoot      = 5
+	cfbMaxDirEntries = 1 << 20
+)
+
+var errCorruptCFB = errors.New("corrupt compound file")
+
+// cfbEntry is a storage or stream of a compound file.
+type cfbEntry struct {
+	name               string
+	kind               byte
+	left, right, child uint32
+	// clsid identifies the application of storages, like the kind of
+	// Windows Installer package of the root.
+	clsid [16]byte
+	start uint32
+	size  int64
+}
+
+// compoundFile is a read-only compound file. Its small streams are read
+// whole, as compound files in Office documents are small, and large streams
+// can be streamed, like the cabinets of Windows Installer packages.
+type compoundFile struct {
+	r          io.ReaderAt
+	size       int64
+	sectorSize int64
+	fat        []uint32
+	miniFAT    []uint32
+	miniStream []byte
+	entries    []cfbEntry
+}
+
+// openCompoundFile parses a compound file.
+func openCompoundFile(data []byte) (*compoundFile, error) {
+	return openCompoundFileAt(bytes.NewReader(data), int64(len(data)))
+}
+
+// openCompoundFileAt parses a compound file of a size read from r.
+func openCompoundFileAt(r io.ReaderAt, size int64) (*compoundFile, error) {
+	data := make([]byte, cfbHeaderSize)
+	if _, err := r.ReadAt(data, 0); err != nil || !bytes.HasPrefix(data, cfbMagic) {
+		return nil, errCorruptCFB
+	}
+	shift := binary.LittleEndian.Uint16(data[30:])
+	if shift != 9 && shift != 12 {
+		return nil, errCorruptCFB
+	}
+	c := &compoundFile{r: r, size: size, sectorSize: 1 << shift}
+
+	// The sectors of the FAT are listed in the header, then in a chain of
+	// DIFAT sectors.
+	var fatSectors []uint32
+	for i := 0; i < cfbHeaderDIFAT; i++ {
+		fatSectors = append(fatSectors, binary.LittleEndian.Uint32(data[76+4*i:]))
+	}
+	difat := binary.LittleEndian.Uint32(data[68:])
+	for i := int64(0); difat <= cfbMaxRegular && i < size/c.sectorSize; i++ {
+		sector, ok := c.sector(difat)
+		if !ok {
+			return nil, errCorruptCFB
+		}
+		for j := 0; j < len(sector)/4-1; j++ {
+			fatSectors = append(fatSectors, binary.LittleEndian.Uint32(sector[4*j:]))
+		}
+		difat = binary.LittleEndian.Uint32(sector[len(sector)-4:])
+	}
+	fatCount := int(binary.LittleEndian.Uint32(data[44:]))
+	for i := 0; i < fatCount && i < len(fatSectors); i++ {
+		sector, ok := c.sector(fatSectors[i])
+		if !ok {
+			return nil, errCorruptCFB
+		}
+		for j := 0; j < len(sector); j += 4 {
+			c.fat = append(c.fat, binary.LittleEndian.Uint32(sector[j:]))
+		}
+	}
+
+	dir, err := c.chain(binary.LittleEndian.Uint32(data[48:]), -1)
+	if err != nil {
+		return nil, err
+	}
+	for offset := 0; offset+cfbDirEntrySize <= len(dir) && len(c.entries) < cfbMaxDirEntries; offset += cfbDirEntrySize {
+		c.entries = append(c.entries, parseCFBEntry(dir[offset:offset+cfbDirEntrySize]))
+	}
+	if len(c.entries) == 0 || c.entries[0].kind != cfbTypeRoot {
+		return nil, errCorruptCFB
+	}
+
+	miniFAT, err := c.chain(binary.LittleEndian.Uint32(data[60:]), -1)
+	if err != nil {
+		return nil, err
+	}
+	for j := 0; j+4 <= len(miniFAT); j += 4 {
+		c.miniFAT = append(c.miniFAT, binary.LittleEndian.Uint32(miniFAT[j:]))
+	}
+	// The mini stream, of the small streams, is the stream of the root.
+	if c.miniStream, err = c.chain(c.entries[0].start, c.entries[0].size); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func parseCFBEntry(raw []byte) cfbEntry {
+	nameLen := int(binary.LittleEndian.Uint16(raw[64:]))
+	if nameLen > 64 {
+		nameLen = 64
+	}
+	units := make([]uint16, 0, nameLen/2)
+	for i := 0; i+1 < nameLen; i += 2 {
+		if u := binary.LittleEndian.Uint16(raw[i:]); u != 0 {
+			units = append(units, u)
+		}
+	}
+	e := cfbEntry{
+		name:  string(utf16.Decode(units)),
+		kind:  raw[66],
+		left:  binary.LittleEndian.Uint32(raw[68:]),
+		right: binary.LittleEndian.Uint32(raw[72:]),
+		child: binary.LittleEndian.Uint32(raw[76:]),
+		start: binary.LittleEndian.Uint32(raw[116:]),
+		size:  int64(binary.LittleEndian.Uint32(raw[120:])),
+	}
+	copy(e.clsid[:], raw[80:96])
+	return e
+}
+
+// sectorOffset returns the offset of a sector by number. Sector 0 follows
+// the header.
+func (c *compoundFile) sectorOffset(n uint32) (int64, bool) {
+	start := (int64(n) + 1) * c.sectorSize
+	if n > cfbMaxRegular || start+c.sectorSize > c.size {
+		return 0, false
+	}
+	return start, true
+}
+
+// sector returns a sector by number.
+func (c *compoundFile) sector(n uint32) ([]byte, bool) {
+	start, ok := c.sectorOffset(n)
+	if !ok {
+		return nil, false
+	}
+	data := make([]byte, c.sectorSize)
+	if _, err := c.r.ReadAt(data, start); err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// chain returns the content of the chain of sectors starting at start, cut at
+// size if it is not negative.
+func (c *compoundFile) chain(start uint32, size int64) ([]byte, error) {
+	var out []byte
+	// Chains are bounded by the number of sectors, for chains that loop.
+	for n, i := start, 0; n != cfbEndOfChain && n != cfbNoStream && i <= len(c.fat); i++ {
+		if size >= 0 && int64(len(out)) >= size {
+			break
+		}
+		sector, ok := c.sector(n)
+		if !ok || int(n) >= len(c.fat) {
+			return nil, errCorruptCFB
+		}
+		out = append(out, sector...)
+		n = c.fat[n]
+	}
+	if size >= 0 && int64(len(out)) > size {
+		out = out[:size]
+	}
+	return out, nil
+}
+
+// miniChain returns the content of a small stream, in mini sectors of the mini
+// stream.
+func (c *compoundFile) miniChain(start uint32, size int64) ([]byte, error) {
+	var out []byte
+	for n, i := start, 0; n != cfbEndOfChain && int64(len(out)) < size && i <= len(c.miniFAT); i++ {
+		offset := int64(n) * cfbMiniSector
+		if int(n) >= len(c.miniFAT) || offset+cfbMiniSector > int64(len(c.miniStream)) {
+			return nil, errCorruptCFB
+		}
+		out = append(out, c.miniStream[offset:offset+cfbMiniSector]...)
+		n = c.miniFAT[n]
+	}
+	if int64(len(out)) > size {
+		out = out[:size]
+	}
+	return out, nil
+}
+
+// stream returns the content of a stream.
+func (c *compoundFile) stream(e cfbEntry) ([]byte, error) {
+	if e.size < cfbMiniCutoff {
+		return c.miniChain(e.start, e.size)
+	}
+	return c.chain(e.start, e.size)
+}
+
+// streamReader returns a reader of a stream, which reads the sectors of large
+// streams as it is read rather than all at once.
+func (c *compoundFile) streamReader(e cfbEntry) (io.Reader, error) {
+	if e.size < cfbMiniCutoff {
+		data, err := c.stream(e)
+		return bytes.NewReader(data), err
+	}
+	var sectors []uint32
+	for n, i := e.start, 0; n != cfbEndOfChain && n != cfbNoStream && int64(len(sectors))*c.sectorSize < e.size; i++ {
+		if i > len(c.fat) || int(n) >= len(c.fat) {
+			return nil, errCorruptCFB
+		}
+		sectors = append(sectors, n)
+		n = c.fat[n]
+	}
+	return &cfbStreamReader{c: c, sectors: sectors, remaining: e.size}, nil
+}
+
+// cfbStreamReader reads the chain of sectors of a stream.
+type cfbStreamReader struct {
+	c         *compoundFile
+	sectors   []uint32
+	offset    int64
+	remaining int64
+}
+
+func (r *cfbStreamReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 || len(r.sectors) == 0 {
+		return 0, io.EOF
+	}
+	start, ok := r.c.sectorOffset(r.sectors[0])
+	if !ok {
+		return 0, errCorruptCFB
+	}
+	n := r.c.sectorSize - r.offset
+	if n > r.remaining {
+		n = r.remaining
+	}
+	if int64(len(p)) > n {
+		p = p[:n]
+	}
+	read, err := r.c.r.ReadAt(p, start+r.offset)
+	r.offset += int64(read)
+	r.remaining -= int64(read)
+	if r.offset == r.c.sectorSize {
+		r.sectors, r.offset = r.sectors[1:], 0
+	}
+	if errors.Is(err, io.EOF) && read == len(p) {
+		err = nil
+	}
+	return read, err
+}
+
+// walk calls fn with the path of each stream, its storages separated by
+// slashes, in the order of the directory.
+func (c *compoundFile) walk(fn func(path string, e cfbEntry) error) error {
+	visited := make(map[uint32]bool)
+	var walkTree func(id uint32, parent string) error
+	walkTree = func(id uint32, parent string) error {
+		if id == cfbNoStream || int(id) >= len(c.entries) || visited[id] {
+			return nil
+		}
+		visited[id] = true
+		e := c.entries[id]
+		if err := walkTree(e.left, parent); err != nil {
+			return err
+		}
+		path := e.name
+		if parent != "" {
+			path = parent + "/" + e.name
+		}
+		switch e.kind {
+		case cfbTypeStream:
+			if err := fn(path, e); err != nil {
+				return err
+			}
+		case cfbTypeStorage:
+			if err := walkTree(e.child, path); err != nil {
+				return err
+			}
+		}
+		return walkTree(e.right, parent)
+	}
+	return walkTree(c.entries[0].child, "")
+}
+
+// find returns the stream at a path of a compound file.
+func (c *compoundFile) find(path string) (cfbEntry, bool) {
+	var found cfbEntry
+	ok := false
+	_ = c.walk(func(p string, e cfbEntry) error {
+		if p == path {
+			found, ok = e, true
+			return io.EOF
+		}
+		return nil
+	})
+	return found, ok
+}
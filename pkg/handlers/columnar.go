@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// columnarFormat is the format of a data file of columns or records, like
+// the exports of data lakes and pipelines.
+type columnarFormat string
+
+const (
+	columnarParquet columnarFormat = "parquet"
+	columnarAvro    columnarFormat = "avro"
+	columnarORC     columnarFormat = "orc"
+)
+
+const (
+	parquetMagic = "PAR1"
+	avroMagic    = "Obj\x01"
+	orcMagic     = "ORC"
+)
+
+// errCorruptColumnar is returned for data files whose structures cannot be
+// decoded.
+var errCorruptColumnar = errors.New("corrupt columnar file")
+
+// identifyColumnar returns the format of a Parquet, Avro or ORC file from
+// its first bytes. The returned reader must be read instead of reader.
+func identifyColumnar(reader io.Reader) (columnarFormat, io.Reader, bool) {
+	br := bufio.NewReader(reader)
+	header, _ := br.Peek(len(parquetMagic))
+	switch {
+	case bytes.Equal(header, []byte(parquetMagic)):
+		return columnarParquet, br, true
+	case bytes.Equal(header, []byte(avroMagic)):
+		return columnarAvro, br, true
+	case bytes.HasPrefix(header, []byte(orcMagic)):
+		return columnarORC, br, true
+	}
+	return "", br, false
+}
+
+// column is the values of a column of a data file, or another part of it
+// like its metadata, read one per line.
+type column struct {
+	name   string
+	values io.Reader
+}
+
+// extractColumnar extracts the string columns of a Parquet or ORC file, or
+// the records of an Avro file, with their metadata. Files are spooled to a
+// temporary file, as Parquet and ORC files are indexed by their footer, and
+// files that cannot be decoded are scanned as they are.
+func (a *Archive) extractColumnar(ctx context.Context, depth int, path string, format columnarFormat, reader io.Reader, archiveChan chan Part) error {
+	seekableReader, cleanup, err := seekable(reader)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	r := seekableReader.(readerAtSeeker)
+	size, err := readerSize(r)
+	if err != nil {
+		return err
+	}
+
+	logger := logContext.AddLogger(ctx).Logger()
+	logger.V(3).Info("Handling columnar file.", "format", format)
+	var columns []column
+	switch format {
+	case columnarParquet:
+		columns, err = parquetColumns(r, size)
+	case columnarAvro:
+		columns, err = avroColumns(io.NewSectionReader(r, 0, size))
+	case columnarORC:
+		columns, err = orcColumns(r, size)
+	}
+	if errors.Is(err, errCorruptColumnar) || errors.Is(err, io.ErrUnexpectedEOF) {
+		logger.V(2).Info("Error decoding columnar file, scanning it as it is.", "format", format, "error", err)
+		return sendParts(ctx, path, io.NewSectionReader(r, 0, size), archiveChan)
+	}
+	if err != nil {
+		return err
+	}
+	for _, c := range columns {
+		name := memberPath(path, c.name)
+		err := sendParts(ctx, name, a.limitReader(ctx, name, c.values), archiveChan)
+		if errors.Is(err, errCorruptColumnar) || errors.Is(err, io.ErrUnexpectedEOF) {
+			logger.V(2).Info("Error decoding column.", "column", c.name, "error", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineReader reads values one per line, decoding them a page or block at a
+// time with next, which returns io.EOF after the last.
+type lineReader struct {
+	next func() ([][]byte, error)
+	buf  []byte
+	err  error
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		var values [][]byte
+		values, r.err = r.next()
+		for _, value := range values {
+			r.buf = append(append(r.buf, value...), '\n')
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// decompressColumnar decompresses a page or block of a data file compressed
+// with codec, which decompresses to size bytes if it is known, or up to
+// maxMetadataSize.
+func decompressColumnar(codec string, data []byte, size int) ([]byte, error) {
+	if size < 0 || size > maxMetadataSize {
+		return nil, errCorruptColumnar
+	}
+	limit := size
+	if limit == 0 {
+		limit = maxMetadataSize
+	}
+	var reader io.Reader
+	switch codec {
+	case "", "none", "null":
+		return data, nil
+	case "snappy":
+		n, err := snappy.DecodedLen(data)
+		if err != nil || n > limit {
+			return nil, errCorruptColumnar
+		}
+		return snappy.Decode(nil, data)
+	case "lz4":
+		if size == 0 {
+			return nil, errCorruptColumnar
+		}
+		out := make([]byte, size)
+		n, err := lz4.UncompressBlock(data, out)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errCorruptColumnar, err)
+		}
+		return out[:n], nil
+	case "deflate":
+		reader = flate.NewReader(bytes.NewReader(data))
+	case "gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errCorruptColumnar, err)
+		}
+		reader = gz
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		reader = zr
+	case "brotli":
+		reader = brotli.NewReader(bytes.NewReader(data))
+	case "bzip2":
+		reader = bzip2.NewReader(bytes.NewReader(data))
+	case "xz":
+		xr, err := xz.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errCorruptColumnar, err)
+		}
+		reader = xr
+	default:
+		return nil, fmt.Errorf("%w: unsupported codec %s", errCorruptColumnar, codec)
+	}
+	out, err := io.ReadAll(io.LimitReader(reader, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptColumnar, err)
+	}
+	if len(out) > limit {
+		return nil, errCorruptColumnar
+	}
+	return out, nil
+}
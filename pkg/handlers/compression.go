@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/mholt/archiver/v4"
+	"github.com/pierrec/lz4/v4"
+)
+
+// The magic numbers of zstd and LZ4 frames, which are little-endian. Both
+// formats share skippable frames, with any of 16 magic numbers, that carry
+// metadata rather than data.
+const (
+	zstdFrameMagic      = 0xfd2fb528
+	lz4FrameMagic       = 0x184d2204
+	lz4LegacyFrameMagic = 0x184c2102
+	skippableFrameMagic = 0x184d2a50
+)
+
+// compressionPeekSize is how far streams are looked into to identify them
+// past their skippable frames, or to check brotli streams decode.
+const compressionPeekSize = 64 * 1024
+
+// isSkippableFrame reports whether magic is that of a skippable frame.
+func isSkippableFrame(magic uint32) bool {
+	return magic&^0xf == skippableFrameMagic
+}
+
+// identifyCompression returns the format of the compressed streams archiver
+// does not identify: zstd and LZ4 streams that start with skippable frames, LZ4
+// streams of legacy frames, and brotli streams, which have no magic number,
+// named with the .br extension. The returned reader must be read instead of
+// reader.
+func identifyCompression(name string, reader io.Reader) (archiver.Format, io.Reader, bool) {
+	br := bufio.NewReaderSize(reader, compressionPeekSize)
+	var offset int
+	for {
+		header, err := br.Peek(offset + 8)
+		if err != nil {
+			break
+		}
+		magic := binary.LittleEndian.Uint32(header[offset:])
+		switch {
+		case isSkippableFrame(magic):
+			offset += 8 + int(binary.LittleEndian.Uint32(header[offset+4:]))
+			continue
+		case magic == zstdFrameMagic && offset > 0:
+			return archiver.Zstd{}, br, true
+		case magic == lz4LegacyFrameMagic || (magic == lz4FrameMagic && offset > 0):
+			return lz4Frames{}, br, true
+		}
+		break
+	}
+	if path.Ext(strings.ToLower(name)) == ".br" && isBrotli(br) {
+		return archiver.Brotli{}, br, true
+	}
+	return nil, br, false
+}
+
+// isBrotli reports whether the start of a stream decodes as brotli. Streams
+// shorter than the peek must decode completely.
+func isBrotli(br *bufio.Reader) bool {
+	data, _ := br.Peek(compressionPeekSize)
+	if len(data) == 0 {
+		return false
+	}
+	_, err := io.Copy(io.Discard, brotli.NewReader(bytes.NewReader(data)))
+	return err == nil || (len(data) == compressionPeekSize && errors.Is(err, io.ErrUnexpectedEOF))
+}
+
+// lz4Frames decompresses LZ4 streams of one or more frames, which archiver
+// stops reading after the first of.
+type lz4Frames struct {
+	archiver.Lz4
+}
+
+func (lz4Frames) OpenReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	return io.NopCloser(&lz4FramesReader{src: br, reader: lz4.NewReader(br)}), nil
+}
+
+// lz4FramesReader reads the frames of an LZ4 stream one after the other.
+type lz4FramesReader struct {
+	src    *bufio.Reader
+	reader *lz4.Reader
+}
+
+func (r *lz4FramesReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if !errors.Is(err, io.EOF) {
+		return n, err
+	}
+	// Another frame may follow the end of this one.
+	header, _ := r.src.Peek(4)
+	if len(header) < 4 {
+		return n, io.EOF
+	}
+	switch magic := binary.LittleEndian.Uint32(header); {
+	case magic == lz4FrameMagic, magic == lz4LegacyFrameMagic, isSkippableFrame(magic):
+		r.reader.Reset(r.src)
+		return n, nil
+	}
+	return n, io.EOF
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+)
+
+const (
+	dexMagic      = "dex\n"
+	dexHeaderSize = 0x70
+)
+
+var errCorruptDEX = errors.New("corrupt DEX file")
+
+// dexStrings returns the strings of a DEX file, the bytecode of Android apps,
+// one per line: its string constants, and the names of its classes, methods
+// and fields.
+func dexStrings(data []byte) ([]byte, error) {
+	if len(data) < dexHeaderSize || !bytes.HasPrefix(data, []byte(dexMagic)) {
+		return nil, errCorruptDEX
+	}
+	count := int64(binary.LittleEndian.Uint32(data[56:]))
+	idsOffset := int64(binary.LittleEndian.Uint32(data[60:]))
+	if idsOffset+4*count > int64(len(data)) {
+		return nil, errCorruptDEX
+	}
+
+	var out bytes.Buffer
+	for i := int64(0); i < count; i++ {
+		offset := int64(binary.LittleEndian.Uint32(data[idsOffset+4*i:]))
+		if offset >= int64(len(data)) {
+			return nil, errCorruptDEX
+		}
+		// Strings start with their length in UTF-16 units, in ULEB128,
+		// then are NUL terminated modified UTF-8.
+		s := data[offset:]
+		for len(s) > 0 && s[0]&0x80 != 0 {
+			s = s[1:]
+		}
+		if len(s) == 0 {
+			return nil, errCorruptDEX
+		}
+		s = s[1:]
+		if end := bytes.IndexByte(s, 0); end >= 0 {
+			s = s[:end]
+		}
+		out.Write(s)
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+// machoStrings returns the C strings and UTF-16 string literals of a Mach-O
+// binary, like the executables and frameworks of iOS apps, one per line.
+// Only the first architecture of universal binaries is read, as the others
+// have the same strings.
+func machoStrings(data []byte) ([]byte, error) {
+	f, err := macho.NewFile(bytes.NewReader(data))
+	if err != nil {
+		fat, fatErr := macho.NewFatFile(bytes.NewReader(data))
+		if fatErr != nil {
+			return nil, err
+		}
+		if len(fat.Arches) == 0 {
+			return nil, macho.ErrNotFat
+		}
+		f = fat.Arches[0].File
+	}
+
+	var out bytes.Buffer
+	for _, section := range f.Sections {
+		switch section.Name {
+		case "__cstring":
+			content, err := section.Data()
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range bytes.Split(content, []byte{0}) {
+				if len(s) > 0 {
+					out.Write(s)
+					out.WriteByte('\n')
+				}
+			}
+		case "__ustring":
+			content, err := section.Data()
+			if err != nil {
+				return nil, err
+			}
+			var units []uint16
+			for i := 0; i+1 < len(content); i += 2 {
+				u := f.ByteOrder.Uint16(content[i:])
+				if u != 0 {
+					units = append(units, u)
+					continue
+				}
+				if len(units) > 0 {
+					out.WriteString(string(utf16.Decode(units)) + "\n")
+					units = units[:0]
+				}
+			}
+		}
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// diskImageFormat is the filesystem of a disk image, or the format of the
+// disk of VM disk images and partitioned disk images.
+type diskImageFormat string
+
+const (
+	diskImageISO9660     diskImageFormat = "iso9660"
+	diskImageFAT         diskImageFormat = "fat"
+	diskImageExt         diskImageFormat = "ext"
+	diskImagePartitioned diskImageFormat = "partitioned"
+	diskImageVMDK        diskImageFormat = "vmdk"
+	diskImageVHD         diskImageFormat = "vhd"
+	diskImageVHDX        diskImageFormat = "vhdx"
+	diskImageQCOW2       diskImageFormat = "qcow2"
+	diskImageWIM         diskImageFormat = "wim"
+)
+
+// diskImagePeekSize is the number of bytes read to identify a disk image: up
+// to the first volume descriptor of ISO 9660 images, after 16 system sectors.
+const diskImagePeekSize = 16*isoSectorSize + 6
+
+// walkFunc is called with the path and content of each regular file of a
+// disk image.
+type walkFunc func(name string, content io.Reader) error
+
+// identifyDiskImage returns the filesystem of a disk image from its first
+// bytes. The returned reader must be read instead of reader.
+func identifyDiskImage(reader io.Reader) (diskImageFormat, io.Reader, bool) {
+	br := bufio.NewReaderSize(reader, diskImagePeekSize)
+	// Files smaller than the peek size can still be FAT or ext images.
+	header, _ := br.Peek(diskImagePeekSize)
+	format, ok := diskImageFormatOf(header)
+	return format, br, ok
+}
+
+// diskImageFormatOf returns the format of a disk image from its first bytes.
+// Hybrid ISO 9660 images, which also have a partition table to boot from USB
+// drives, are read as ISO 9660 images.
+func diskImageFormatOf(header []byte) (diskImageFormat, bool) {
+	switch {
+	case bytes.HasPrefix(header, []byte(vmdkMagic)):
+		return diskImageVMDK, true
+	case bytes.HasPrefix(header, []byte(vhdCookie)):
+		return diskImageVHD, true
+	case bytes.HasPrefix(header, []byte(vhdxSignature)):
+		return diskImageVHDX, true
+	case bytes.HasPrefix(header, []byte(qcow2Magic)):
+		return diskImageQCOW2, true
+	case bytes.HasPrefix(header, []byte(wimMagic)):
+		return diskImageWIM, true
+	case len(header) >= diskImagePeekSize && bytes.Equal(header[16*isoSectorSize+1:diskImagePeekSize], []byte(isoIdentifier)):
+		return diskImageISO9660, true
+	case len(header) >= extSuperblockOffset+extSuperblockSize && binary.LittleEndian.Uint16(header[extSuperblockOffset+56:]) == extMagic:
+		return diskImageExt, true
+	case len(header) >= fatBootSectorSize && isFATBootSector(header[:fatBootSectorSize]):
+		return diskImageFAT, true
+	case isPartitionTable(header):
+		return diskImagePartitioned, true
+	}
+	return "", false
+}
+
+// extractDiskImage extracts the regular files of an ISO 9660, FAT or ext2/3/4
+// disk image, such as installer and firmware images, or of a WIM image, or of
+// the partitions of a partitioned or VM disk image, such as appliance images.
+// Images are spooled to a temporary file to read their file trees.
+func (a *Archive) extractDiskImage(ctx context.Context, depth int, path string, format diskImageFormat, reader io.Reader, archiveChan chan Part) error {
+	seekableReader, cleanup, err := seekable(reader)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	r := seekableReader.(readerAtSeeker)
+	size, err := readerSize(r)
+	if err != nil {
+		return err
+	}
+
+	logger := logContext.AddLogger(ctx).Logger()
+	logger.V(3).Info("Handling disk image.", "format", format)
+	walk := func(name string, content io.Reader) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name = memberPath(path, name)
+		err := a.openArchive(ctx, depth+1, name, a.limitReader(ctx, name, content), archiveChan)
+		if errors.Is(err, errMaxArchiveDepthReached) {
+			a.reportSkip(ctx, name, sources.SkipReasonDepth, 0)
+		}
+		return err
+	}
+
+	var disk io.ReaderAt = r
+	diskSize, sectorSize := size, int64(diskSectorSize)
+	var vm *virtualDisk
+	switch format {
+	case diskImageISO9660, diskImageFAT, diskImageExt, diskImageWIM:
+		err := walkFilesystem(format, r, size, walk)
+		if errors.Is(err, errUnsupportedWIM) {
+			logger.V(3).Info("Skipping WIM image with unsupported compression or split into parts.")
+			a.reportSkip(ctx, path, sources.SkipReasonUnsupported, size)
+			return nil
+		}
+		return err
+	case diskImageVMDK:
+		vm, err = openVMDK(r, size)
+	case diskImageVHD:
+		vm, err = openVHD(r, size)
+	case diskImageVHDX:
+		vm, err = openVHDX(r, size)
+	case diskImageQCOW2:
+		vm, err = openQCOW2(r, size)
+	}
+	if errors.Is(err, errEncryptedDiskImage) {
+		logger.V(3).Info("Skipping encrypted disk image.")
+		a.reportSkip(ctx, path, sources.SkipReasonEncrypted, size)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if vm != nil {
+		disk, diskSize, sectorSize = vm, vm.size, vm.sectorSize
+	}
+
+	// Partitions are named in the paths of their files, e.g.
+	// disk.vmdk!/partition1/etc/shadow.
+	walked := false
+	for _, partition := range diskPartitions(disk, diskSize, sectorSize) {
+		content := io.NewSectionReader(disk, partition.offset, partition.size)
+		header := make([]byte, diskImagePeekSize)
+		n, _ := content.ReadAt(header, 0)
+		partitionFormat, ok := diskImageFormatOf(header[:n])
+		if !ok || (partitionFormat != diskImageISO9660 && partitionFormat != diskImageFAT && partitionFormat != diskImageExt) {
+			// Filesystems like NTFS, and LVM volumes, are not read.
+			logger.V(3).Info("Skipping partition with an unsupported filesystem.", "partition", partition.name)
+			a.reportSkip(ctx, memberPath(path, partition.name), sources.SkipReasonUnsupported, partition.size)
+			continue
+		}
+		partitionWalk := walk
+		if partition.name != "" {
+			name := partition.name
+			partitionWalk = func(file string, content io.Reader) error {
+				return walk(name+"/"+file, content)
+			}
+		}
+		err := walkFilesystem(partitionFormat, content, partition.size, partitionWalk)
+		if errors.Is(err, errCorruptDiskImage) || errors.Is(err, io.ErrUnexpectedEOF) {
+			logger.V(2).Info("Error reading partition.", "partition", partition.name, "error", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		walked = true
+	}
+	// Files that only look like partitioned disks are scanned as they are.
+	if !walked && format == diskImagePartitioned {
+		return sendParts(ctx, path, io.NewSectionReader(r, 0, size), archiveChan)
+	}
+	return nil
+}
+
+// walkFilesystem calls walk with each file of a filesystem image.
+func walkFilesystem(format diskImageFormat, r io.ReaderAt, size int64, walk walkFunc) error {
+	switch format {
+	case diskImageISO9660:
+		return walkISO9660(r, size, walk)
+	case diskImageFAT:
+		return walkFAT(r, size, walk)
+	case diskImageWIM:
+		return walkWIM(r, size, walk)
+	default:
+		return walkExt(r, size, walk)
+	}
+}
+
+// readAt reads len(p) bytes at off, failing on short reads.
+func readAt(r io.ReaderAt, p []byte, off int64) error {
+	n, err := r.ReadAt(p, off)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// errCorruptDiskImage is returned for disk images whose structures point
+// outside of the image or loop.
+var errCorruptDiskImage = errors.New("corrupt disk image")
@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"sort"
+	"strings"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// mailFormat is the format of an email message or mailbox.
+type mailFormat string
+
+const (
+	mailMbox mailFormat = "mbox"
+	mailEML  mailFormat = "eml"
+	// mailPST is the format of Outlook PST and OST files.
+	mailPST mailFormat = "pst"
+)
+
+// mailPeekSize is the number of bytes read to identify an email message, which
+// is enough for the Received and DKIM headers messages start with.
+const mailPeekSize = 16 * 1024
+
+// mboxFromLine starts each message of an mbox mailbox.
+const mboxFromLine = "From "
+
+// identifyMail returns the format of an email message or mailbox from its
+// first bytes. The returned reader must be read instead of reader.
+func identifyMail(reader io.Reader) (mailFormat, io.Reader, bool) {
+	br := bufio.NewReaderSize(reader, mailPeekSize)
+	header, _ := br.Peek(mailPeekSize)
+	format, ok := mailFormatOf(header)
+	return format, br, ok
+}
+
+// mailFormatOf returns the format of an email message or mailbox from its
+// first bytes. Text is only read as a message if it starts with the headers
+// of one, including who sent it.
+func mailFormatOf(header []byte) (mailFormat, bool) {
+	switch {
+	case len(header) >= 10 && bytes.HasPrefix(header, []byte(pstMagic)) &&
+		(string(header[8:10]) == pstClientPST || string(header[8:10]) == pstClientOST):
+		return mailPST, true
+	case bytes.HasPrefix(header, []byte(mboxFromLine)):
+		if _, rest, found := bytes.Cut(header, []byte("\n")); found && isMessageHeader(rest) {
+			return mailMbox, true
+		}
+	case isMessageHeader(header):
+		return mailEML, true
+	}
+	return "", false
+}
+
+// isMessageHeader reports whether data starts with the header of an email
+// message: fields up to an empty line, or up to the end of data, with a From
+// field and one of the fields mail servers and clients add to messages.
+func isMessageHeader(data []byte) bool {
+	fields := make(map[string]bool)
+	for len(data) > 0 {
+		line, rest, found := bytes.Cut(data, []byte("\n"))
+		if !found {
+			// The header goes on past the bytes peeked.
+			break
+		}
+		data = rest
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if len(line) == 0 {
+			break
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(fields) == 0 {
+				return false
+			}
+			continue
+		}
+		name, _, ok := bytes.Cut(line, []byte(":"))
+		if !ok || !isHeaderFieldName(name) {
+			return false
+		}
+		fields[textproto.CanonicalMIMEHeaderKey(string(name))] = true
+	}
+	return fields["From"] && (fields["Date"] || fields["Message-Id"] || fields["Received"] || fields["Mime-Version"])
+}
+
+// isHeaderFieldName reports whether name is the name of a header field: one
+// or more printable ASCII characters other than a colon.
+func isHeaderFieldName(name []byte) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for _, c := range name {
+		if c <= ' ' || c > '~' || c == ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// extractMail extracts the messages of an mbox mailbox, EML message or PST
+// file, like the archives they often hold credentials shared over email in.
+// Messages are named after their Message-ID and sender in the paths of their
+// parts, e.g. mail.mbox!/id@example.com from alice@example.com!/body.txt,
+// and their attachments are extracted like the files of archives.
+func (a *Archive) extractMail(ctx context.Context, depth int, path string, format mailFormat, reader io.Reader, archiveChan chan Part) error {
+	logContext.AddLogger(ctx).Logger().V(3).Info("Handling email.", "format", format)
+	switch format {
+	case mailPST:
+		return a.extractPST(ctx, depth, path, reader, archiveChan)
+	case mailMbox:
+		return a.extractMbox(ctx, depth, path, reader, archiveChan)
+	}
+	return a.extractMessage(ctx, depth, path, 0, reader, archiveChan)
+}
+
+// extractMbox extracts the messages of an mbox mailbox, which start with a
+// From line.
+func (a *Archive) extractMbox(ctx context.Context, depth int, path string, reader io.Reader, archiveChan chan Part) error {
+	br := bufio.NewReader(reader)
+	if _, err := br.ReadBytes('\n'); err != nil {
+		return err
+	}
+	for n := 1; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		message := &mboxMessageReader{br: br}
+		if err := a.extractMessage(ctx, depth, path, n, message, archiveChan); err != nil {
+			return err
+		}
+		// Skip what is left of messages whose MIME parts end early.
+		if _, err := io.Copy(io.Discard, message); err != nil {
+			return err
+		}
+		if !message.next {
+			return nil
+		}
+	}
+}
+
+// mboxMessageReader reads a message of an mbox mailbox, up to the From line
+// of the next one. The From lines of bodies, escaped with a > in mboxo and
+// mboxrd mailboxes, are unescaped.
+type mboxMessageReader struct {
+	br   *bufio.Reader
+	line []byte
+	end  bool
+	// next is set when the message is followed by another.
+	next bool
+}
+
+func (r *mboxMessageReader) Read(p []byte) (int, error) {
+	for len(r.line) == 0 {
+		if r.end {
+			return 0, io.EOF
+		}
+		line, err := r.br.ReadBytes('\n')
+		if bytes.HasPrefix(line, []byte(mboxFromLine)) {
+			r.end, r.next = true, true
+			return 0, io.EOF
+		}
+		if unescaped := bytes.TrimLeft(line, ">"); len(unescaped) < len(line) && bytes.HasPrefix(unescaped, []byte(mboxFromLine)) {
+			line = line[1:]
+		}
+		r.line = line
+		if err != nil {
+			r.end = true
+			if !errors.Is(err, io.EOF) {
+				return 0, err
+			}
+		}
+	}
+	n := copy(p, r.line)
+	r.line = r.line[n:]
+	return n, nil
+}
+
+// extractMessage extracts the header, bodies and attachments of the nth
+// message of a mailbox, or of a message on its own if n is 0.
+func (a *Archive) extractMessage(ctx context.Context, depth int, path string, n int, reader io.Reader, archiveChan chan Part) error {
+	br := bufio.NewReader(reader)
+	header, err := textproto.NewReader(br).ReadMIMEHeader()
+	if err != nil && len(header) == 0 {
+		// Messages without a readable header are scanned as they are.
+		return sendParts(ctx, path, br, archiveChan)
+	}
+	if err != nil {
+		logContext.AddLogger(ctx).Logger().V(3).Info("Error reading email header.", "error", err)
+	}
+	messagePath := memberPath(path, messageName(mail.Header(header).Get("Message-Id"), headerSender(header), n))
+	if err := sendParts(ctx, memberPath(messagePath, "headers"), bytes.NewReader(formatMessageHeader(header)), archiveChan); err != nil {
+		return err
+	}
+	names := make(partNames)
+	return a.extractMIMEPart(ctx, depth, messagePath, header, br, names, archiveChan)
+}
+
+// messageName names a message in the paths of its parts after its Message-ID
+// and sender, or its position in its mailbox if it has no Message-ID.
+func messageName(id, sender string, n int) string {
+	name := strings.Trim(strings.TrimSpace(id), "<>")
+	if name == "" {
+		name = "message"
+		if n > 0 {
+			name = fmt.Sprintf("message %d", n)
+		}
+	}
+	if sender != "" {
+		name += " from " + sender
+	}
+	return name
+}
+
+// headerSender returns the address of the sender of a message.
+func headerSender(header textproto.MIMEHeader) string {
+	from := header.Get("From")
+	if addr, err := mail.ParseAddress(from); err == nil {
+		return addr.Address
+	}
+	return strings.TrimSpace(from)
+}
+
+// formatMessageHeader returns the fields of a message header one per line,
+// sorted by name, with their encoded words decoded.
+func formatMessageHeader(header textproto.MIMEHeader) []byte {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var decoder mime.WordDecoder
+	var buf bytes.Buffer
+	for _, name := range names {
+		for _, value := range header[name] {
+			if decoded, err := decoder.DecodeHeader(value); err == nil {
+				value = decoded
+			}
+			fmt.Fprintf(&buf, "%s: %s\n", name, value)
+		}
+	}
+	return buf.Bytes()
+}
+
+// partNames makes the names of the parts of a message unique.
+type partNames map[string]int
+
+// unique returns name, numbered if another part has it.
+func (p partNames) unique(name string) string {
+	p[name]++
+	if n := p[name]; n > 1 {
+		ext := path.Ext(name)
+		return fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), n, ext)
+	}
+	return name
+}
+
+// extractMIMEPart extracts the bodies and attachments of a part of a
+// message, the parts of multipart parts in turn. Text bodies are scanned as
+// they are after decoding their transfer encoding, and attachments, which
+// can be archives or messages themselves, are extracted.
+func (a *Archive) extractMIMEPart(ctx context.Context, depth int, messagePath string, header textproto.MIMEHeader, body io.Reader, names partNames, archiveChan chan Part) error {
+	logger := logContext.AddLogger(ctx).Logger()
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+	if strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				logger.V(3).Info("Error reading email part.", "error", err)
+				return nil
+			}
+			if err := a.extractMIMEPart(ctx, depth, messagePath, part.Header, part, names, archiveChan); err != nil {
+				return err
+			}
+		}
+	}
+
+	content := decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body)
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := attachmentName(dispositionParams["filename"], params["name"])
+	if filename == "" && disposition != "attachment" && strings.HasPrefix(mediaType, "text/") {
+		name := memberPath(messagePath, names.unique(bodyName(mediaType)))
+		err := sendParts(ctx, name, a.limitReader(ctx, name, content), archiveChan)
+		return ignoreCorruptEncoding(ctx, err)
+	}
+	if filename == "" {
+		filename = "attachment"
+		if mediaType == "message/rfc822" {
+			filename = "message.eml"
+		}
+	}
+	name := memberPath(messagePath, names.unique(filename))
+	err = a.openArchive(ctx, depth+1, name, a.limitReader(ctx, name, content), archiveChan)
+	if errors.Is(err, errMaxArchiveDepthReached) {
+		a.reportSkip(ctx, name, sources.SkipReasonDepth, 0)
+	}
+	return ignoreCorruptEncoding(ctx, err)
+}
+
+// bodyName names a text body of a message after its media type, e.g.
+// body.txt or body.html.
+func bodyName(mediaType string) string {
+	subtype := strings.TrimPrefix(mediaType, "text/")
+	if subtype == "plain" {
+		subtype = "txt"
+	}
+	return "body." + subtype
+}
+
+// attachmentName returns the name of an attached file from the filename of
+// its Content-Disposition or the name of its Content-Type, without the
+// directories some clients keep.
+func attachmentName(names ...string) string {
+	var decoder mime.WordDecoder
+	for _, name := range names {
+		if decoded, err := decoder.DecodeHeader(name); err == nil {
+			name = decoded
+		}
+		name = path.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+		if name != "" && name != "." && name != "/" {
+			return name
+		}
+	}
+	return ""
+}
+
+// decodeTransferEncoding returns a reader of the content of a part of a
+// message, decoding its base64 or quoted-printable transfer encoding.
+func decodeTransferEncoding(encoding string, reader io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, reader)
+	case "quoted-printable":
+		return quotedprintable.NewReader(reader)
+	}
+	return reader
+}
+
+// ignoreCorruptEncoding returns err unless it is the error of a part of a
+// message that is not correctly encoded, whose decoded content before the
+// error is scanned, so that the next parts and messages are extracted.
+func ignoreCorruptEncoding(ctx context.Context, err error) error {
+	var corrupt base64.CorruptInputError
+	if errors.As(err, &corrupt) || (err != nil && strings.HasPrefix(err.Error(), "quotedprintable:")) {
+		logContext.AddLogger(ctx).Logger().V(3).Info("Error decoding email part.", "error", err)
+		return nil
+	}
+	return err
+}
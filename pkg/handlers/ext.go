@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+const (
+	extSuperblockOffset = 1024
+	extSuperblockSize   = 1024
+	extMagic            = 0xef53
+	extRootInode        = 2
+	// extFeature64Bit is the incompatible feature of filesystems with 64 bit
+	// block numbers and group descriptors.
+	extFeature64Bit = 0x80
+	// extFlagExtents and extFlagInlineData are the inode flags of files
+	// whose blocks are mapped by extents, and whose data is in the inode.
+	extFlagExtents    = 0x80000
+	extFlagInlineData = 0x10000000
+	extModeTypeMask   = 0xf000
+	extModeRegular    = 0x8000
+	extModeDirectory  = 0x4000
+	extExtentMagic    = 0xf30a
+	// extMaxExtentDepth bounds the depth of extent trees, which is at most
+	// 5 in valid filesystems.
+	extMaxExtentDepth = 5
+)
+
+// extVolume is an ext2, ext3 or ext4 filesystem.
+type extVolume struct {
+	r              io.ReaderAt
+	size           int64
+	blockSize      int64
+	inodesPerGroup uint32
+	inodeSize      int64
+	// descriptors are the group descriptors, of descSize bytes each.
+	descriptors []byte
+	descSize    int64
+}
+
+// extInode is the part of an inode needed to read a file.
+type extInode struct {
+	mode   uint16
+	size   int64
+	flags  uint32
+	blocks []byte
+}
+
+// extRun is a run of contiguous blocks of a file, from its logical block.
+type extRun struct {
+	logical, physical, length int64
+}
+
+// walkExt calls walk with each file of an ext2, ext3 or ext4 filesystem image.
+func walkExt(r io.ReaderAt, size int64, walk walkFunc) error {
+	sb := make([]byte, extSuperblockSize)
+	if err := readAt(r, sb, extSuperblockOffset); err != nil {
+		return err
+	}
+	logBlockSize := binary.LittleEndian.Uint32(sb[24:])
+	if logBlockSize > 6 {
+		return errCorruptDiskImage
+	}
+	v := &extVolume{
+		r:              r,
+		size:           size,
+		blockSize:      1024 << logBlockSize,
+		inodesPerGroup: binary.LittleEndian.Uint32(sb[40:]),
+		inodeSize:      128,
+		descSize:       32,
+	}
+	// Revision 0 filesystems have 128 byte inodes.
+	if binary.LittleEndian.Uint32(sb[76:]) >= 1 {
+		v.inodeSize = int64(binary.LittleEndian.Uint16(sb[88:]))
+	}
+	if binary.LittleEndian.Uint32(sb[96:])&extFeature64Bit != 0 {
+		v.descSize = int64(binary.LittleEndian.Uint16(sb[254:]))
+	}
+	blocksPerGroup := int64(binary.LittleEndian.Uint32(sb[32:]))
+	blocks := int64(binary.LittleEndian.Uint32(sb[4:]))
+	if v.inodesPerGroup == 0 || blocksPerGroup == 0 || v.inodeSize < 128 || v.descSize < 32 {
+		return errCorruptDiskImage
+	}
+
+	// The group descriptors are in the block after the superblock.
+	firstDataBlock := int64(binary.LittleEndian.Uint32(sb[20:]))
+	groups := (blocks - firstDataBlock + blocksPerGroup - 1) / blocksPerGroup
+	if groups <= 0 || groups*v.descSize > size {
+		return errCorruptDiskImage
+	}
+	v.descriptors = make([]byte, groups*v.descSize)
+	if err := readAt(r, v.descriptors, (firstDataBlock+1)*v.blockSize); err != nil {
+		return err
+	}
+
+	root, err := v.inode(extRootInode)
+	if err != nil {
+		return err
+	}
+	return v.walkDir(root, "", map[uint32]bool{extRootInode: true}, walk)
+}
+
+// inode reads an inode by number.
+func (v *extVolume) inode(n uint32) (*extInode, error) {
+	if n == 0 {
+		return nil, errCorruptDiskImage
+	}
+	group := int64((n - 1) / v.inodesPerGroup)
+	index := int64((n - 1) % v.inodesPerGroup)
+	if (group+1)*v.descSize > int64(len(v.descriptors)) {
+		return nil, errCorruptDiskImage
+	}
+	desc := v.descriptors[group*v.descSize:]
+	table := int64(binary.LittleEndian.Uint32(desc[8:]))
+	if v.descSize >= 64 {
+		table |= int64(binary.LittleEndian.Uint32(desc[40:])) << 32
+	}
+
+	raw := make([]byte, 128)
+	if err := readAt(v.r, raw, table*v.blockSize+index*v.inodeSize); err != nil {
+		return nil, err
+	}
+	return &extInode{
+		mode:   binary.LittleEndian.Uint16(raw[0:]),
+		size:   int64(binary.LittleEndian.Uint32(raw[4:])) | int64(binary.LittleEndian.Uint32(raw[108:]))<<32,
+		flags:  binary.LittleEndian.Uint32(raw[32:]),
+		blocks: raw[40:100],
+	}, nil
+}
+
+// walkDir calls walk with each file of a directory and its subdirectories.
+// Hashed directories are read as linear ones, whose index blocks look like
+// empty entries.
+func (v *extVolume) walkDir(dir *extInode, parent string, visited map[uint32]bool, walk walkFunc) error {
+	content, err := v.open(dir)
+	if err != nil {
+		return err
+	}
+	data, err := readAllLimited(content)
+	if err != nil {
+		return err
+	}
+
+	for offset := 0; offset+8 <= len(data); {
+		n := binary.LittleEndian.Uint32(data[offset:])
+		recordLen := int(binary.LittleEndian.Uint16(data[offset+4:]))
+		nameLen := int(data[offset+6])
+		if recordLen < 8 || offset+8+nameLen > len(data) {
+			break
+		}
+		name := string(data[offset+8 : offset+8+nameLen])
+		offset += recordLen
+		if n == 0 || name == "." || name == ".." || name == "" || strings.Contains(name, "/") {
+			continue
+		}
+		if parent != "" {
+			name = parent + "/" + name
+		}
+
+		inode, err := v.inode(n)
+		if err != nil {
+			return err
+		}
+		switch inode.mode & extModeTypeMask {
+		case extModeDirectory:
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+			if err := v.walkDir(inode, name, visited, walk); err != nil {
+				return err
+			}
+		case extModeRegular:
+			content, err := v.open(inode)
+			if err != nil {
+				return err
+			}
+			if err := walk(name, content); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// open returns a reader of the content of an inode. Holes read as zeros.
+func (v *extVolume) open(inode *extInode) (io.Reader, error) {
+	if inode.flags&extFlagInlineData != 0 {
+		// Inline data longer than the block map continues in extended
+		// attributes, which are not read.
+		size := inode.size
+		if size > int64(len(inode.blocks)) {
+			size = int64(len(inode.blocks))
+		}
+		return bytes.NewReader(inode.blocks[:size]), nil
+	}
+
+	var runs []extRun
+	var err error
+	if inode.flags&extFlagExtents != 0 {
+		runs, err = v.extents(inode.blocks, 0)
+	} else {
+		runs, err = v.blockMap(inode.blocks)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var readers []io.Reader
+	var offset int64
+	for _, run := range runs {
+		start := run.logical * v.blockSize
+		if start >= inode.size {
+			break
+		}
+		if start < offset {
+			return nil, errCorruptDiskImage
+		}
+		if start > offset {
+			readers = append(readers, io.LimitReader(zeroReader{}, start-offset))
+		}
+		length := run.length * v.blockSize
+		if start+length > inode.size {
+			length = inode.size - start
+		}
+		if run.physical*v.blockSize+length > v.size {
+			return nil, errCorruptDiskImage
+		}
+		readers = append(readers, io.NewSectionReader(v.r, run.physical*v.blockSize, length))
+		offset = start + length
+	}
+	if offset < inode.size {
+		readers = append(readers, io.LimitReader(zeroReader{}, inode.size-offset))
+	}
+	return io.MultiReader(readers...), nil
+}
+
+// extents returns the runs of an extent tree node.
+func (v *extVolume) extents(node []byte, depth int) ([]extRun, error) {
+	if len(node) < 12 || binary.LittleEndian.Uint16(node) != extExtentMagic || depth > extMaxExtentDepth {
+		return nil, errCorruptDiskImage
+	}
+	entries := int(binary.LittleEndian.Uint16(node[2:]))
+	if 12+entries*12 > len(node) {
+		return nil, errCorruptDiskImage
+	}
+	var runs []extRun
+	leaf := binary.LittleEndian.Uint16(node[6:]) == 0
+	for i := 0; i < entries; i++ {
+		entry := node[12+i*12 : 24+i*12]
+		if leaf {
+			length := int64(binary.LittleEndian.Uint16(entry[4:]))
+			// Lengths over 32768 mark uninitialized extents, which read
+			// as zeros.
+			if length > 32768 {
+				continue
+			}
+			physical := int64(binary.LittleEndian.Uint16(entry[6:]))<<32 | int64(binary.LittleEndian.Uint32(entry[8:]))
+			runs = append(runs, extRun{logical: int64(binary.LittleEndian.Uint32(entry)), physical: physical, length: length})
+			continue
+		}
+		child := int64(binary.LittleEndian.Uint32(entry[4:])) | int64(binary.LittleEndian.Uint16(entry[8:]))<<32
+		block := make([]byte, v.blockSize)
+		if err := readAt(v.r, block, child*v.blockSize); err != nil {
+			return nil, err
+		}
+		childRuns, err := v.extents(block, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, childRuns...)
+	}
+	return runs, nil
+}
+
+// blockMap returns the runs of the block map of ext2 and ext3 files: 12
+// direct blocks, then an indirect, a double indirect and a triple indirect
+// block.
+func (v *extVolume) blockMap(blocks []byte) ([]extRun, error) {
+	var runs []extRun
+	var logical int64
+	add := func(physical int64) {
+		if physical != 0 {
+			if n := len(runs); n > 0 && runs[n-1].logical+runs[n-1].length == logical && runs[n-1].physical+runs[n-1].length == physical {
+				runs[n-1].length++
+			} else {
+				runs = append(runs, extRun{logical: logical, physical: physical, length: 1})
+			}
+		}
+		logical++
+	}
+	perBlock := v.blockSize / 4
+	var indirect func(block int64, level int) error
+	indirect = func(block int64, level int) error {
+		if block == 0 {
+			// Holes of indirect blocks skip all the blocks they map.
+			skipped := int64(1)
+			for i := 0; i < level; i++ {
+				skipped *= perBlock
+			}
+			logical += skipped
+			return nil
+		}
+		data := make([]byte, v.blockSize)
+		if err := readAt(v.r, data, block*v.blockSize); err != nil {
+			return err
+		}
+		for i := int64(0); i < perBlock; i++ {
+			next := int64(binary.LittleEndian.Uint32(data[i*4:]))
+			if level == 1 {
+				add(next)
+				continue
+			}
+			if err := indirect(next, level-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := 0; i < 12; i++ {
+		add(int64(binary.LittleEndian.Uint32(blocks[i*4:])))
+	}
+	for level := 1; level <= 3; level++ {
+		if err := indirect(int64(binary.LittleEndian.Uint32(blocks[(11+level)*4:])), level); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
+
+// zeroReader reads zeros.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	fatBootSectorSize = 512
+	fatDirEntrySize   = 32
+	fatAttrDirectory  = 0x10
+	fatAttrVolumeID   = 0x08
+	fatAttrLongName   = 0x0f
+	// fatEntryDeleted marks deleted directory entries, and fatEntryEnd the
+	// end of a directory.
+	fatEntryDeleted = 0xe5
+	fatEntryEnd     = 0x00
+)
+
+// fatVolume is a FAT12, FAT16 or FAT32 filesystem.
+type fatVolume struct {
+	r           io.ReaderAt
+	bits        int
+	clusterSize int64
+	// dataStart is the offset of cluster 2, the first one, and clusters the
+	// number of clusters.
+	dataStart int64
+	clusters  uint32
+	fat       []byte
+}
+
+// isFATBootSector reports whether a sector is the boot sector of a FAT
+// filesystem: it has the signature of boot sectors, a valid BIOS parameter
+// block, and a FAT filesystem type.
+func isFATBootSector(sector []byte) bool {
+	if sector[510] != 0x55 || sector[511] != 0xaa || (sector[0] != 0xeb && sector[0] != 0xe9) {
+		return false
+	}
+	bytesPerSector := binary.LittleEndian.Uint16(sector[11:])
+	sectorsPerCluster := sector[13]
+	if bytesPerSector < 512 || bytesPerSector > 4096 || bytesPerSector&(bytesPerSector-1) != 0 ||
+		sectorsPerCluster == 0 || sectorsPerCluster&(sectorsPerCluster-1) != 0 ||
+		sector[16] == 0 || binary.LittleEndian.Uint16(sector[14:]) == 0 {
+		return false
+	}
+	return bytes.HasPrefix(sector[54:], []byte("FAT1")) || bytes.HasPrefix(sector[82:], []byte("FAT32"))
+}
+
+// walkFAT calls walk with each file of a FAT filesystem image, with their long
+// names if they have one.
+func walkFAT(r io.ReaderAt, size int64, walk walkFunc) error {
+	boot := make([]byte, fatBootSectorSize)
+	if err := readAt(r, boot, 0); err != nil {
+		return err
+	}
+	bytesPerSector := int64(binary.LittleEndian.Uint16(boot[11:]))
+	sectorsPerCluster := int64(boot[13])
+	reservedSectors := int64(binary.LittleEndian.Uint16(boot[14:]))
+	fats := int64(boot[16])
+	rootEntries := int64(binary.LittleEndian.Uint16(boot[17:]))
+	totalSectors := int64(binary.LittleEndian.Uint16(boot[19:]))
+	if totalSectors == 0 {
+		totalSectors = int64(binary.LittleEndian.Uint32(boot[32:]))
+	}
+	fatSectors := int64(binary.LittleEndian.Uint16(boot[22:]))
+	if fatSectors == 0 {
+		fatSectors = int64(binary.LittleEndian.Uint32(boot[36:]))
+	}
+
+	rootSectors := (rootEntries*fatDirEntrySize + bytesPerSector - 1) / bytesPerSector
+	dataSector := reservedSectors + fats*fatSectors + rootSectors
+	if dataSector >= totalSectors || fatSectors*bytesPerSector > size {
+		return errCorruptDiskImage
+	}
+	v := &fatVolume{
+		r:           r,
+		clusterSize: sectorsPerCluster * bytesPerSector,
+		dataStart:   dataSector * bytesPerSector,
+		clusters:    uint32((totalSectors - dataSector) / sectorsPerCluster),
+		fat:         make([]byte, fatSectors*bytesPerSector),
+	}
+	// The type of FAT filesystems is set by their number of clusters.
+	switch {
+	case v.clusters < 4085:
+		v.bits = 12
+	case v.clusters < 65525:
+		v.bits = 16
+	default:
+		v.bits = 32
+	}
+	if err := readAt(r, v.fat, reservedSectors*bytesPerSector); err != nil {
+		return err
+	}
+
+	var root []byte
+	if v.bits == 32 {
+		var err error
+		if root, err = readAllLimited(v.open(binary.LittleEndian.Uint32(boot[44:]), -1)); err != nil {
+			return err
+		}
+	} else {
+		// The root directory of FAT12 and FAT16 filesystems is before the
+		// clusters.
+		root = make([]byte, rootEntries*fatDirEntrySize)
+		if err := readAt(r, root, (reservedSectors+fats*fatSectors)*bytesPerSector); err != nil {
+			return err
+		}
+	}
+	return v.walkDir(root, "", make(map[uint32]bool), walk)
+}
+
+// walkDir calls walk with each file of a directory and its subdirectories.
+func (v *fatVolume) walkDir(dir []byte, parent string, visited map[uint32]bool, walk walkFunc) error {
+	var longName []uint16
+	for offset := 0; offset+fatDirEntrySize <= len(dir); offset += fatDirEntrySize {
+		entry := dir[offset : offset+fatDirEntrySize]
+		if entry[0] == fatEntryEnd {
+			break
+		}
+		attr := entry[11]
+		if entry[0] == fatEntryDeleted {
+			longName = nil
+			continue
+		}
+		if attr&0x3f == fatAttrLongName {
+			// Long names are stored backwards in the entries before the
+			// short one, 13 characters each, the last one first.
+			var part []uint16
+			for _, field := range [][2]int{{1, 11}, {14, 26}, {28, 32}} {
+				for i := field[0]; i < field[1]; i += 2 {
+					part = append(part, binary.LittleEndian.Uint16(entry[i:]))
+				}
+			}
+			longName = append(part, longName...)
+			continue
+		}
+		name := fatShortName(entry)
+		if longName != nil {
+			name = fatLongName(longName)
+			longName = nil
+		}
+		if attr&fatAttrVolumeID != 0 || name == "." || name == ".." || name == "" {
+			continue
+		}
+		if parent != "" {
+			name = parent + "/" + name
+		}
+
+		cluster := uint32(binary.LittleEndian.Uint16(entry[26:]))
+		if v.bits == 32 {
+			cluster |= uint32(binary.LittleEndian.Uint16(entry[20:])) << 16
+		}
+		if attr&fatAttrDirectory != 0 {
+			if visited[cluster] || cluster < 2 {
+				continue
+			}
+			visited[cluster] = true
+			subdir, err := readAllLimited(v.open(cluster, -1))
+			if err != nil {
+				return err
+			}
+			if err := v.walkDir(subdir, name, visited, walk); err != nil {
+				return err
+			}
+			continue
+		}
+		size := int64(binary.LittleEndian.Uint32(entry[28:]))
+		if err := walk(name, v.open(cluster, size)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// open returns a reader of size bytes of the clusters of the chain starting
+// at cluster, or of all of them if size is negative. Contiguous clusters are
+// read at once.
+func (v *fatVolume) open(cluster uint32, size int64) io.Reader {
+	var (
+		readers             []io.Reader
+		runStart, runLength int64
+		read                int64
+	)
+	flush := func() {
+		if runLength > 0 {
+			readers = append(readers, io.NewSectionReader(v.r, runStart, runLength))
+		}
+	}
+	// Chains are bounded by the number of clusters, for chains that loop.
+	for i := uint32(0); i < v.clusters && cluster >= 2 && cluster < v.clusters+2; i++ {
+		length := v.clusterSize
+		if size >= 0 {
+			if read >= size {
+				break
+			}
+			if size-read < length {
+				length = size - read
+			}
+		}
+		offset := v.dataStart + int64(cluster-2)*v.clusterSize
+		if offset != runStart+runLength {
+			flush()
+			runStart, runLength = offset, 0
+		}
+		runLength += length
+		read += length
+		cluster = v.next(cluster)
+	}
+	flush()
+	return io.MultiReader(readers...)
+}
+
+// next returns the cluster after cluster in its chain, or 0 at its end.
+func (v *fatVolume) next(cluster uint32) uint32 {
+	var next, end uint32
+	switch v.bits {
+	case 12:
+		offset := int(cluster + cluster/2)
+		if offset+2 > len(v.fat) {
+			return 0
+		}
+		next = uint32(binary.LittleEndian.Uint16(v.fat[offset:]))
+		if cluster%2 == 1 {
+			next >>= 4
+		}
+		next, end = next&0xfff, 0xff7
+	case 16:
+		offset := int(cluster) * 2
+		if offset+2 > len(v.fat) {
+			return 0
+		}
+		next, end = uint32(binary.LittleEndian.Uint16(v.fat[offset:])), 0xfff7
+	default:
+		offset := int(cluster) * 4
+		if offset+4 > len(v.fat) {
+			return 0
+		}
+		next, end = binary.LittleEndian.Uint32(v.fat[offset:])&0x0fffffff, 0x0ffffff7
+	}
+	// Values from end on mark bad clusters and the end of chains.
+	if next >= end {
+		return 0
+	}
+	return next
+}
+
+// fatShortName returns the 8.3 name of a directory entry, lowercased if the
+// entry says so, as Windows does.
+func fatShortName(entry []byte) string {
+	base := strings.TrimRight(string(entry[0:8]), " ")
+	ext := strings.TrimRight(string(entry[8:11]), " ")
+	if entry[0] == 0x05 {
+		// 0x05 stands for 0xe5 as the first character.
+		base = "\xe5" + base[1:]
+	}
+	if entry[12]&0x08 != 0 {
+		base = strings.ToLower(base)
+	}
+	if entry[12]&0x10 != 0 {
+		ext = strings.ToLower(ext)
+	}
+	if ext == "" {
+		return base
+	}
+	return base + "." + ext
+}
+
+// fatLongName decodes a long name, which ends with a NUL if it is shorter
+// than its entries, padded with 0xffff.
+func fatLongName(units []uint16) string {
+	for i, u := range units {
+		if u == 0 {
+			units = units[:i]
+			break
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// maxMetadataSize bounds the directories of disk images and the other
+// metadata of archives read whole into memory.
+const maxMetadataSize = 64 * 1024 * 1024
+
+// readAllLimited reads a directory, up to maxMetadataSize.
+func readAllLimited(r io.Reader) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(r, maxMetadataSize))
+}
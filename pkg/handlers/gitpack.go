@@ -0,0 +1,677 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// packFormat is the format of a file of git objects: a bundle, made by git
+// bundle, or a bare packfile, like those of .git/objects/pack.
+type packFormat string
+
+const (
+	packBundle   packFormat = "bundle"
+	packPackfile packFormat = "pack"
+)
+
+const (
+	packMagic = "PACK"
+	// packMaxDeltaChain bounds the length of chains of deltas, which git
+	// limits to 50 by default.
+	packMaxDeltaChain = 4096
+)
+
+// The types of the objects of a packfile.
+const (
+	packCommit   = 1
+	packTree     = 2
+	packBlob     = 3
+	packTag      = 4
+	packOfsDelta = 6
+	packRefDelta = 7
+)
+
+var bundleSignatures = []string{"# v2 git bundle\n", "# v3 git bundle\n"}
+
+// errCorruptPack is returned for bundles and packfiles whose objects cannot
+// be read.
+var errCorruptPack = errors.New("corrupt git pack")
+
+// identifyPack returns the format of a git bundle or packfile from its first
+// bytes. The returned reader must be read instead of reader.
+func identifyPack(reader io.Reader) (packFormat, io.Reader, bool) {
+	br := bufio.NewReader(reader)
+	header, _ := br.Peek(len(bundleSignatures[0]))
+	for _, signature := range bundleSignatures {
+		if bytes.Equal(header, []byte(signature)) {
+			return packBundle, br, true
+		}
+	}
+	if len(header) >= 8 && bytes.HasPrefix(header, []byte(packMagic)) {
+		if version := binary.BigEndian.Uint32(header[4:]); version == 2 || version == 3 {
+			return packPackfile, br, true
+		}
+	}
+	return "", br, false
+}
+
+// extractPack extracts the blobs of a git bundle or packfile. Blobs are named
+// by their path in the earliest commit of the pack that has them, which their
+// parts carry as their packed commit, and blobs no commit of the pack has are
+// named by their object ID. Files are spooled to a temporary file, as deltas
+// refer to other objects of the pack, and packs that cannot be read are
+// scanned as they are.
+func (a *Archive) extractPack(ctx context.Context, depth int, path string, format packFormat, reader io.Reader, archiveChan chan Part) error {
+	seekableReader, cleanup, err := seekable(reader)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	r := seekableReader.(readerAtSeeker)
+	size, err := readerSize(r)
+	if err != nil {
+		return err
+	}
+
+	logger := logContext.AddLogger(ctx).Logger()
+	logger.V(3).Info("Handling git pack.", "format", format)
+	var (
+		offset  int64
+		newHash = sha1.New
+	)
+	if format == packBundle {
+		offset, newHash, err = readBundleHeader(io.NewSectionReader(r, 0, size))
+	}
+	var pack *packfile
+	if err == nil {
+		pack, err = openPackfile(io.NewSectionReader(r, offset, size-offset), newHash)
+	}
+	if errors.Is(err, errCorruptPack) || errors.Is(err, io.ErrUnexpectedEOF) {
+		logger.V(2).Info("Error reading git pack, scanning it as it is.", "format", format, "error", err)
+		return sendParts(ctx, path, io.NewSectionReader(r, 0, size), archiveChan)
+	}
+	if err != nil {
+		return err
+	}
+	if unresolved := pack.unresolved(); unresolved > 0 {
+		// Thin packs, like those of incremental bundles, have deltas
+		// of objects that are not in the pack.
+		logger.V(2).Info("Skipping deltas of objects not in the git pack.", "count", unresolved)
+	}
+
+	for _, blob := range pack.blobs() {
+		name := memberPath(path, blob.path)
+		blobReader, err := pack.reader(blob.index)
+		if errors.Is(err, errCorruptPack) || errors.Is(err, io.ErrUnexpectedEOF) {
+			logger.V(2).Info("Error reading git blob.", "blob", blob.path, "error", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		blobCtx := ctx
+		if blob.commit != nil {
+			blobCtx = context.WithValue(ctx, packedCommitKey, blob.commit)
+		}
+		err = a.openArchive(blobCtx, depth+1, name, a.limitReader(ctx, name, blobReader), archiveChan)
+		if errors.Is(err, errCorruptPack) || errors.Is(err, zlib.ErrChecksum) {
+			logger.V(2).Info("Error reading git blob.", "blob", blob.path, "error", err)
+			continue
+		}
+		if err != nil {
+			if errors.Is(err, errMaxArchiveDepthReached) {
+				a.reportSkip(ctx, name, sources.SkipReasonDepth, 0)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// readBundleHeader reads the header of a bundle, its prerequisites and refs,
+// and returns the offset of its packfile and the hash of its object IDs.
+func readBundleHeader(r io.Reader) (int64, func() hash.Hash, error) {
+	br := bufio.NewReader(io.LimitReader(r, maxMetadataSize))
+	newHash := sha1.New
+	var offset int64
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return 0, nil, fmt.Errorf("%w: %v", errCorruptPack, err)
+		}
+		offset += int64(len(line))
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return offset, newHash, nil
+		case line == "@object-format=sha256":
+			newHash = sha256.New
+		}
+	}
+}
+
+// packObject is an object of a packfile.
+type packObject struct {
+	// typ is the type of the object, or of the object its delta applies to
+	// once it is resolved.
+	typ  byte
+	size int64
+	// dataOffset is the offset of the compressed data of the object.
+	dataOffset int64
+	// base is the index of the object a delta applies to, or -1 if it is
+	// not in the pack. baseID is its ID for deltas that refer to it by ID.
+	base   int
+	baseID string
+	delta  bool
+	// id is the object ID, which is empty until the delta is resolved.
+	id string
+}
+
+// packfile is an indexed packfile.
+type packfile struct {
+	r       io.ReaderAt
+	newHash func() hash.Hash
+	objects []packObject
+	byID    map[string]int
+	// cache holds the content of objects deltas apply to, up to
+	// maxMetadataSize bytes.
+	cache       map[int][]byte
+	cacheSize   int
+	isDeltaBase map[int]bool
+}
+
+// countingByteReader counts the bytes read, and reads bytes one at a time so
+// zlib readers do not read past the end of their stream.
+type countingByteReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingByteReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingByteReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// openPackfile indexes the objects of a packfile, and resolves the IDs of
+// their deltas.
+func openPackfile(r *io.SectionReader, newHash func() hash.Hash) (*packfile, error) {
+	cr := &countingByteReader{r: bufio.NewReader(r)}
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(cr, header); err != nil || string(header[:4]) != packMagic {
+		return nil, errCorruptPack
+	}
+	count := int64(binary.BigEndian.Uint32(header[8:]))
+	if count > r.Size() {
+		return nil, fmt.Errorf("%w: %d objects", errCorruptPack, count)
+	}
+	hashSize := newHash().Size()
+	p := &packfile{
+		r:           r,
+		newHash:     newHash,
+		objects:     make([]packObject, 0, count),
+		byID:        make(map[string]int, count),
+		cache:       make(map[int][]byte),
+		isDeltaBase: make(map[int]bool),
+	}
+	byOffset := make(map[int64]int, count)
+	var zr io.ReadCloser
+	for i := 0; i < int(count); i++ {
+		offset := cr.n
+		byOffset[offset] = i
+		c, err := cr.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errCorruptPack, err)
+		}
+		obj := packObject{typ: (c >> 4) & 7, size: int64(c & 15), base: -1}
+		for shift := 4; c&0x80 != 0; shift += 7 {
+			if c, err = cr.ReadByte(); err != nil || shift > 56 {
+				return nil, errCorruptPack
+			}
+			obj.size |= int64(c&0x7f) << shift
+		}
+		switch obj.typ {
+		case packCommit, packTree, packBlob, packTag:
+		case packOfsDelta:
+			// The offset of the base back from the object is a
+			// big-endian varint where each continuation adds one.
+			c, err := cr.ReadByte()
+			if err != nil {
+				return nil, errCorruptPack
+			}
+			back := int64(c & 0x7f)
+			for c&0x80 != 0 {
+				if c, err = cr.ReadByte(); err != nil || back >= 1<<55 {
+					return nil, errCorruptPack
+				}
+				back = (back+1)<<7 | int64(c&0x7f)
+			}
+			base, ok := byOffset[offset-back]
+			if !ok {
+				return nil, fmt.Errorf("%w: no object at offset %d", errCorruptPack, offset-back)
+			}
+			obj.delta, obj.base = true, base
+			p.isDeltaBase[base] = true
+		case packRefDelta:
+			id := make([]byte, hashSize)
+			if _, err := io.ReadFull(cr, id); err != nil {
+				return nil, errCorruptPack
+			}
+			obj.delta, obj.baseID = true, hex.EncodeToString(id)
+		default:
+			return nil, fmt.Errorf("%w: object type %d", errCorruptPack, obj.typ)
+		}
+		obj.dataOffset = cr.n
+
+		// Objects are read once to find where the next one starts, and
+		// their IDs computed, but for deltas.
+		if zr == nil {
+			zr, err = zlib.NewReader(cr)
+		} else {
+			err = zr.(zlib.Resetter).Reset(cr, nil)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errCorruptPack, err)
+		}
+		var h hash.Hash
+		var w io.Writer = io.Discard
+		if !obj.delta {
+			h = newHash()
+			fmt.Fprintf(h, "%s %d\x00", objectTypeName(obj.typ), obj.size)
+			w = h
+		}
+		// Reading to the end of the stream reads its checksum too.
+		n, err := io.Copy(w, io.LimitReader(zr, obj.size+1))
+		if err != nil || n != obj.size {
+			return nil, fmt.Errorf("%w: object at offset %d: %v", errCorruptPack, offset, err)
+		}
+		if h != nil {
+			obj.id = hex.EncodeToString(h.Sum(nil))
+			p.byID[obj.id] = i
+		}
+		p.objects = append(p.objects, obj)
+	}
+	p.resolveIDs()
+	return p, nil
+}
+
+// resolveIDs computes the IDs of deltas, whose base may be any object of the
+// pack for deltas that refer to it by ID.
+func (p *packfile) resolveIDs() {
+	for resolved := true; resolved; {
+		resolved = false
+		for i := range p.objects {
+			obj := &p.objects[i]
+			if obj.id != "" {
+				continue
+			}
+			if obj.base < 0 {
+				base, ok := p.byID[obj.baseID]
+				if !ok {
+					continue
+				}
+				obj.base = base
+				p.isDeltaBase[base] = true
+			}
+			if p.objects[obj.base].id == "" {
+				continue
+			}
+			data, typ, err := p.content(i)
+			if err != nil {
+				continue
+			}
+			h := p.newHash()
+			fmt.Fprintf(h, "%s %d\x00", objectTypeName(typ), len(data))
+			h.Write(data)
+			obj.typ, obj.id = typ, hex.EncodeToString(h.Sum(nil))
+			p.byID[obj.id] = i
+			resolved = true
+		}
+	}
+}
+
+// unresolved returns the number of deltas whose base is not in the pack.
+func (p *packfile) unresolved() int {
+	n := 0
+	for _, obj := range p.objects {
+		if obj.id == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// reader returns a reader of the content of an object. Objects that are not
+// deltas are decompressed as they are read.
+func (p *packfile) reader(i int) (io.Reader, error) {
+	obj := p.objects[i]
+	if obj.delta {
+		data, _, err := p.content(i)
+		return bytes.NewReader(data), err
+	}
+	zr, err := zlib.NewReader(bufio.NewReader(io.NewSectionReader(p.r, obj.dataOffset, 1<<62)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptPack, err)
+	}
+	return io.LimitReader(zr, obj.size), nil
+}
+
+// content returns the content and type of an object, applying the chain of
+// deltas of deltas.
+func (p *packfile) content(i int) ([]byte, byte, error) {
+	var chain []int
+	for p.objects[i].delta {
+		if data, ok := p.cache[i]; ok {
+			return p.applyDeltas(data, p.objects[i].typ, chain)
+		}
+		if len(chain) >= packMaxDeltaChain || p.objects[i].base < 0 {
+			return nil, 0, errCorruptPack
+		}
+		chain = append(chain, i)
+		i = p.objects[i].base
+	}
+	data, ok := p.cache[i]
+	if !ok {
+		var err error
+		if data, err = p.inflate(i); err != nil {
+			return nil, 0, err
+		}
+		p.addToCache(i, data)
+	}
+	return p.applyDeltas(data, p.objects[i].typ, chain)
+}
+
+// applyDeltas applies the deltas of chain, from the last, to the content of
+// their base.
+func (p *packfile) applyDeltas(data []byte, typ byte, chain []int) ([]byte, byte, error) {
+	for j := len(chain) - 1; j >= 0; j-- {
+		delta, err := p.inflate(chain[j])
+		if err != nil {
+			return nil, 0, err
+		}
+		if data, err = applyDelta(data, delta); err != nil {
+			return nil, 0, err
+		}
+		p.addToCache(chain[j], data)
+	}
+	return data, typ, nil
+}
+
+func (p *packfile) addToCache(i int, data []byte) {
+	if p.isDeltaBase[i] && p.cacheSize+len(data) <= maxMetadataSize {
+		if _, ok := p.cache[i]; !ok {
+			p.cache[i] = data
+			p.cacheSize += len(data)
+		}
+	}
+}
+
+// inflate decompresses the data of an object, which is the content of the
+// object or its delta.
+func (p *packfile) inflate(i int) ([]byte, error) {
+	obj := p.objects[i]
+	if obj.size > maxMetadataSize {
+		return nil, fmt.Errorf("%w: object of %d bytes", errCorruptPack, obj.size)
+	}
+	zr, err := zlib.NewReader(bufio.NewReader(io.NewSectionReader(p.r, obj.dataOffset, 1<<62)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptPack, err)
+	}
+	defer zr.Close()
+	data := make([]byte, obj.size)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptPack, err)
+	}
+	return data, nil
+}
+
+// applyDelta applies a delta to the content of its base: the sizes of the
+// base and the result, followed by instructions that copy a range of the base
+// or insert data.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	r := bytes.NewReader(delta)
+	baseSize, err := binary.ReadUvarint(r)
+	if err != nil || baseSize != uint64(len(base)) {
+		return nil, errCorruptPack
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil || size > maxMetadataSize {
+		return nil, errCorruptPack
+	}
+	out := make([]byte, 0, size)
+	for {
+		op, err := r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if op&0x80 == 0 {
+			if op == 0 {
+				return nil, errCorruptPack
+			}
+			insert := make([]byte, op)
+			if _, err := io.ReadFull(r, insert); err != nil {
+				return nil, errCorruptPack
+			}
+			out = append(out, insert...)
+			continue
+		}
+		// The bits of the copy instruction tell which bytes of its
+		// offset and size follow.
+		var offset, n uint64
+		for bit := 0; bit < 7; bit++ {
+			if op&(1<<bit) == 0 {
+				continue
+			}
+			b, err := r.ReadByte()
+			if err != nil {
+				return nil, errCorruptPack
+			}
+			if bit < 4 {
+				offset |= uint64(b) << (8 * bit)
+			} else {
+				n |= uint64(b) << (8 * (bit - 4))
+			}
+		}
+		if n == 0 {
+			n = 0x10000
+		}
+		if offset+n > uint64(len(base)) || uint64(len(out))+n > size {
+			return nil, errCorruptPack
+		}
+		out = append(out, base[offset:offset+n]...)
+	}
+	if uint64(len(out)) != size {
+		return nil, errCorruptPack
+	}
+	return out, nil
+}
+
+func objectTypeName(typ byte) string {
+	switch typ {
+	case packCommit:
+		return "commit"
+	case packTree:
+		return "tree"
+	case packBlob:
+		return "blob"
+	case packTag:
+		return "tag"
+	}
+	return ""
+}
+
+// packedBlob is a blob of a pack, with its path in the commit it is
+// attributed to.
+type packedBlob struct {
+	index  int
+	path   string
+	commit *source_metadatapb.PackedCommit
+}
+
+// packedCommitInfo is a commit of a pack.
+type packedCommitInfo struct {
+	index     int
+	tree      string
+	committed int64
+	metadata  *source_metadatapb.PackedCommit
+}
+
+// blobs returns the blobs of the pack. Each blob is attributed to the
+// earliest commit, by commit date, whose tree has it, with its path in the
+// tree. The blobs of no commit follow, named by their ID.
+func (p *packfile) blobs() []packedBlob {
+	var commits []packedCommitInfo
+	for i, obj := range p.objects {
+		if obj.typ != packCommit || obj.id == "" {
+			continue
+		}
+		data, _, err := p.content(i)
+		if err != nil {
+			continue
+		}
+		if commit, ok := parseCommit(data); ok {
+			commit.index = i
+			commit.metadata.Commit = obj.id
+			commits = append(commits, commit)
+		}
+	}
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].committed < commits[j].committed })
+
+	var blobs []packedBlob
+	attributed := make(map[int]bool)
+	seenTrees := make(map[int]bool)
+	var walk func(id, dir string, commit *source_metadatapb.PackedCommit)
+	walk = func(id, dir string, commit *source_metadatapb.PackedCommit) {
+		i, ok := p.byID[id]
+		if !ok || seenTrees[i] || p.objects[i].typ != packTree {
+			return
+		}
+		seenTrees[i] = true
+		data, _, err := p.content(i)
+		if err != nil {
+			return
+		}
+		for _, entry := range parseTree(data, p.newHash().Size()) {
+			name := dir + entry.name
+			switch {
+			case entry.mode == "40000":
+				walk(entry.id, name+"/", commit)
+			case entry.mode == "160000":
+				// Submodules are commits of other repositories.
+			default:
+				if blob, ok := p.byID[entry.id]; ok && !attributed[blob] && p.objects[blob].typ == packBlob {
+					attributed[blob] = true
+					blobs = append(blobs, packedBlob{index: blob, path: name, commit: commit})
+				}
+			}
+		}
+	}
+	for _, commit := range commits {
+		walk(commit.tree, "", commit.metadata)
+	}
+	for i, obj := range p.objects {
+		if obj.typ == packBlob && obj.id != "" && !attributed[i] {
+			blobs = append(blobs, packedBlob{index: i, path: obj.id})
+		}
+	}
+	return blobs
+}
+
+// parseCommit parses the tree, author and committer date of a commit.
+func parseCommit(data []byte) (packedCommitInfo, bool) {
+	commit := packedCommitInfo{metadata: &source_metadatapb.PackedCommit{}}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			// The message follows the headers.
+			break
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			commit.tree = value
+		case "author":
+			email, when := parseSignature(value)
+			commit.metadata.Email = email
+			commit.metadata.Timestamp = when.UTC().Format("2006-01-02 15:04:05 -0700")
+		case "committer":
+			_, when := parseSignature(value)
+			commit.committed = when.Unix()
+		}
+	}
+	return commit, commit.tree != ""
+}
+
+// parseSignature parses the author or committer of a commit, e.g.
+// Alice <alice@example.com> 1700000000 +0100.
+func parseSignature(signature string) (string, time.Time) {
+	end := strings.LastIndexByte(signature, '>')
+	if end < 0 {
+		return "", time.Time{}
+	}
+	email := signature[:end+1]
+	if address, err := mail.ParseAddress(email); err == nil {
+		email = address.Address
+	} else if start := strings.LastIndexByte(email, '<'); start >= 0 {
+		email = email[start+1 : end]
+	}
+	fields := strings.Fields(signature[end+1:])
+	if len(fields) == 0 {
+		return email, time.Time{}
+	}
+	seconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return email, time.Time{}
+	}
+	return email, time.Unix(seconds, 0)
+}
+
+type treeEntry struct {
+	mode, name, id string
+}
+
+// parseTree parses the entries of a tree: their mode and name, separated by a
+// space and ending with a NUL byte, followed by their binary object ID.
+func parseTree(data []byte, hashSize int) []treeEntry {
+	var entries []treeEntry
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if space < 0 || nul < space || len(data) < nul+1+hashSize {
+			break
+		}
+		entries = append(entries, treeEntry{
+			mode: string(data[:space]),
+			name: string(data[space+1 : nul]),
+			id:   hex.EncodeToString(data[nul+1 : nul+1+hashSize]),
+		})
+		data = data[nul+1+hashSize:]
+	}
+	return entries
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"io"
 
+	"google.golang.org/protobuf/proto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
+// DefaultHandlers returns the handlers HandleFile tries in order: the
+// registered handlers, then the archive handler.
 func DefaultHandlers() []Handler {
-	return []Handler{
-		&Archive{},
-	}
+	return append(registeredHandlers(), &Archive{})
 }
 
 // SpecializedHandler defines the interface for handlers that can process specialized archives.
@@ -22,21 +25,70 @@ type SpecializedHandler interface {
 	HandleSpecialized(context.Context, io.Reader) (io.Reader, bool, error)
 }
 
+// Part is a chunk of a file extracted by a handler, with where it is in the
+// file.
+type Part struct {
+	// Data is the content of the chunk.
+	Data []byte
+	// Path is the path of the file in the handled file, like the name of an
+	// archive member. Paths of members of nested archives are separated by
+	// sources.ArchivePathSeparator. It is empty if the handled file was only
+	// decompressed.
+	Path string
+	// Offset, LineStart and LineOffset are the position of Data in the
+	// extracted file, as in sources.Chunk.
+	Offset     int64
+	LineStart  int64
+	LineOffset int64
+	// ImageLayer is the layer of the file if it was extracted from an image
+	// saved with docker save.
+	ImageLayer *source_metadatapb.ImageLayer
+	// PackedCommit is the commit of the file if it was extracted from a git
+	// bundle or packfile.
+	PackedCommit *source_metadatapb.PackedCommit
+	// HelmChart is the chart of the file if it was extracted from a Helm
+	// chart.
+	HelmChart *source_metadatapb.HelmChart
+	// Package is the package of the file if it was extracted from an npm,
+	// PyPI or RubyGems package.
+	Package *source_metadatapb.Package
+}
+
 type Handler interface {
-	FromFile(context.Context, io.Reader) chan ([]byte)
+	FromFile(context.Context, io.Reader) chan Part
 	IsFiletype(context.Context, io.Reader) (io.Reader, bool)
 	New()
 }
 
+// Option configures how HandleFile handles a file.
+type Option func(*handleConfig)
+
+type handleConfig struct {
+	archiveOptions sources.ArchiveOptions
+}
+
+// WithArchiveOptions sets how archives are extracted, e.g. to the options of
+// the source the file is from.
+func WithArchiveOptions(opts sources.ArchiveOptions) Option {
+	return func(c *handleConfig) {
+		c.archiveOptions = opts
+	}
+}
+
 // HandleFile processes a given file by selecting an appropriate handler from DefaultHandlers.
 // It first checks if the handler implements SpecializedHandler for any special processing,
 // then falls back to regular file type handling. If successful, it reads the file in chunks,
 // packages them in the provided chunk skeleton, and sends them to chunksChan.
 // The function returns true if processing was successful and false otherwise.
 // Context is used for cancellation, and the caller is responsible for canceling it if needed.
-func HandleFile(ctx context.Context, file io.Reader, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) bool {
+func HandleFile(ctx context.Context, file io.Reader, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk, opts ...Option) bool {
+	var config handleConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
 	for _, h := range DefaultHandlers() {
 		h.New()
+		configure(h, config.archiveOptions, sources.MetadataFile(chunkSkel.SourceMetadata))
 		var (
 			isSpecial bool
 			err       error
@@ -45,8 +97,15 @@ func HandleFile(ctx context.Context, file io.Reader, chunkSkel *sources.Chunk, c
 		// Check if the handler implements SpecializedHandler and process accordingly.
 		if specialHandler, ok := h.(SpecializedHandler); ok {
 			if file, isSpecial, err = specialHandler.HandleSpecialized(ctx, file); isSpecial && err == nil {
+				if closer, ok := file.(io.Closer); ok {
+					defer closer.Close()
+				}
 				return handleChunks(ctx, h.FromFile(ctx, file), chunkSkel, chunksChan)
 			}
+			if file == nil {
+				// The handler failed after reading the file.
+				return false
+			}
 		}
 
 		var isType bool
@@ -57,15 +116,47 @@ func HandleFile(ctx context.Context, file io.Reader, chunkSkel *sources.Chunk, c
 	return false
 }
 
-func handleChunks(ctx context.Context, handlerChan chan []byte, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) bool {
+func handleChunks(ctx context.Context, handlerChan chan Part, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk) bool {
+	// The metadata of the skeleton is shared by the chunks of parts of the
+	// same file.
+	var (
+		path   string
+		layer  *source_metadatapb.ImageLayer
+		commit *source_metadatapb.PackedCommit
+		chart  *source_metadatapb.HelmChart
+		pkg    *source_metadatapb.Package
+	)
+	metadata := chunkSkel.SourceMetadata
 	for {
 		select {
-		case data, open := <-handlerChan:
+		case part, open := <-handlerChan:
 			if !open {
 				return true
 			}
+			if part.Path != path || part.ImageLayer != layer || part.PackedCommit != commit || part.HelmChart != chart || part.Package != pkg {
+				path, layer, commit, chart, pkg = part.Path, part.ImageLayer, part.PackedCommit, part.HelmChart, part.Package
+				metadata = chunkSkel.SourceMetadata
+				if withPath, ok := proto.Clone(metadata).(*source_metadatapb.MetaData); ok && sources.SetArchivePath(withPath, path) {
+					if layer != nil {
+						sources.SetImageLayer(withPath, layer)
+					}
+					if commit != nil {
+						sources.SetPackedCommit(withPath, commit)
+					}
+					if chart != nil {
+						sources.SetHelmChart(withPath, chart)
+					}
+					if pkg != nil {
+						sources.SetPackage(withPath, pkg)
+					}
+					metadata = withPath
+				}
+			}
 			chunk := *chunkSkel
-			chunk.Data = data
+			chunk.SourceMetadata = metadata
+			chunk.Data = part.Data
+			chunk.Offset, chunk.LineStart = part.Offset, part.LineStart
+			chunk.LineOffset = chunkSkel.LineOffset + part.LineOffset
 			select {
 			case chunksChan <- &chunk:
 			case <-ctx.Done():
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// helmChartFile is the file of the name and version of a Helm chart, which
+// helm package writes first in the chart directory.
+var helmChartFile = regexp.MustCompile(`^[^/]+/Chart\.yaml$`)
+
+var (
+	// secretKind matches the kind of Kubernetes Secret manifests.
+	secretKind = regexp.MustCompile(`^kind:\s*["']?Secret["']?\s*(#.*)?$`)
+	// secretDataEntry matches the entries of the data of Secret manifests
+	// whose value is literal base64, rather than a template.
+	secretDataEntry = regexp.MustCompile(`^(\s+)["']?([-._a-zA-Z0-9]+)["']?:\s*["']?([A-Za-z0-9+/]+={0,2})["']?\s*(#.*)?$`)
+	// yamlDocumentSeparator separates the documents of YAML files.
+	yamlDocumentSeparator = regexp.MustCompile(`(?m)^---.*$`)
+)
+
+// isHelmChartTar returns whether a tar archive is a Helm chart, from the name
+// of its first entry. The returned reader must be read instead of reader.
+func isHelmChartTar(reader io.Reader) (io.Reader, bool) {
+	br := bufio.NewReaderSize(reader, 512)
+	header, err := br.Peek(512)
+	if err != nil {
+		return br, false
+	}
+	name, _, _ := bytes.Cut(header[:100], []byte{0})
+	return br, helmChartFile.Match(bytes.TrimPrefix(name, []byte("./")))
+}
+
+// extractHelmChart extracts the files of a Helm chart, which carry the name
+// and version of the chart. The data of the Secret manifests of its YAML
+// files is decoded from base64, and scanned after the manifest under the
+// name of each key, e.g. chart/templates/secret.yaml!/tls.key. Charts this
+// chart depends on, in its charts directory, carry their own name.
+func (a *Archive) extractHelmChart(ctx context.Context, depth int, path string, reader io.Reader, archiveChan chan Part) error {
+	logger := logContext.AddLogger(ctx).Logger()
+	tarReader := tar.NewReader(reader)
+	var chart *source_metadatapb.HelmChart
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read Helm chart: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+		name := memberPath(path, strings.TrimPrefix(header.Name, "./"))
+		var content []byte
+		if chart == nil || isYAML(header.Name) {
+			// Chart.yaml is read before the first file is sent.
+			if content, err = io.ReadAll(io.LimitReader(a.limitReader(ctx, name, tarReader), maxMetadataSize)); err != nil {
+				return err
+			}
+		}
+		if chart == nil {
+			chart = parseChart(content)
+			logger.V(3).Info("Handling Helm chart.", "chart", chart.Name, "version", chart.Version)
+			ctx = context.WithValue(ctx, helmChartKey, chart)
+		}
+
+		var fileReader io.Reader = bytes.NewReader(content)
+		if content == nil {
+			fileReader = a.limitReader(ctx, name, tarReader)
+		}
+		if err := a.openArchive(ctx, depth+1, name, fileReader, archiveChan); err != nil {
+			if errors.Is(err, errMaxArchiveDepthReached) {
+				a.reportSkip(ctx, name, sources.SkipReasonDepth, header.Size)
+			}
+			return err
+		}
+		for _, data := range secretData(content) {
+			if err := sendParts(ctx, memberPath(name, data.key), bytes.NewReader(data.value), archiveChan); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseChart returns the name and version of a chart from its Chart.yaml.
+func parseChart(content []byte) *source_metadatapb.HelmChart {
+	var chart struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	// Charts with an invalid Chart.yaml are still extracted, without their
+	// name and version.
+	_ = yaml.Unmarshal(content, &chart)
+	return &source_metadatapb.HelmChart{Name: chart.Name, Version: chart.Version}
+}
+
+func isYAML(name string) bool {
+	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+}
+
+// secretValue is an entry of the data of a Secret manifest, decoded.
+type secretValue struct {
+	key   string
+	value []byte
+}
+
+// secretData returns the entries of the data of the Secret manifests of a
+// YAML file that are literal base64. The file is read line by line rather
+// than parsed, as the templates of charts are not valid YAML.
+func secretData(content []byte) []secretValue {
+	var values []secretValue
+	for _, document := range yamlDocumentSeparator.Split(string(content), -1) {
+		lines := strings.Split(document, "\n")
+		isSecret := false
+		for _, line := range lines {
+			if secretKind.MatchString(strings.TrimRight(line, "\r")) {
+				isSecret = true
+				break
+			}
+		}
+		if !isSecret {
+			continue
+		}
+		inData, indent := false, ""
+		for _, line := range lines {
+			line = strings.TrimRight(line, "\r")
+			if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+				inData, indent = strings.TrimSpace(line) == "data:", ""
+				continue
+			}
+			if !inData {
+				continue
+			}
+			match := secretDataEntry.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			// Only the entries of data, not of values nested in them.
+			if indent == "" {
+				indent = match[1]
+			}
+			if match[1] != indent {
+				continue
+			}
+			value, err := base64.StdEncoding.DecodeString(match[3])
+			if err != nil {
+				continue
+			}
+			values = append(values, secretValue{key: match[2], value: value})
+		}
+	}
+	return values
+}
@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/klauspost/compress/zstd"
+	"github.com/mholt/archiver/v4"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	imageManifest = "manifest.json"
+	// whiteoutPrefix starts the names of the files of a layer that delete a
+	// file of the layers below, and whiteoutOpaque is the name of the file
+	// that hides the content of its directory in the layers below.
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// imageFirstEntry matches the name of the first entry of the tar archive of an
+// image saved with docker save, podman save or tarball.Write: a layer
+// directory or config of the legacy format, a blob of the OCI layout, or one
+// of their index files.
+var imageFirstEntry = regexp.MustCompile(`^(blobs/|sha256:|[0-9a-f]{64}([/.]|$)|manifest\.json$|index\.json$|oci-layout$|repositories$)`)
+
+// openTar returns a reader of the tar archive of an archive of the format if
+// it is a tar archive, which may be compressed.
+func openTar(format archiver.Format, reader io.Reader) (io.ReadCloser, bool, error) {
+	switch format := format.(type) {
+	case archiver.Tar:
+		return io.NopCloser(reader), true, nil
+	case archiver.CompressedArchive:
+		if _, ok := format.Archival.(archiver.Tar); !ok || format.Compression == nil {
+			return nil, false, nil
+		}
+		tarReader, err := format.Compression.OpenReader(reader)
+		if err != nil {
+			return nil, false, err
+		}
+		return tarReader, true, nil
+	}
+	return nil, false, nil
+}
+
+// isImageTar reports whether a tar archive looks like an image saved with
+// docker save, from the name of its first entry. The returned reader must be
+// read instead of reader.
+func isImageTar(reader io.Reader) (io.Reader, bool) {
+	br := bufio.NewReaderSize(reader, 512)
+	header, err := br.Peek(512)
+	if err != nil {
+		return br, false
+	}
+	name, _, _ := bytes.Cut(header[:100], []byte{0})
+	return br, imageFirstEntry.Match(bytes.TrimPrefix(name, []byte("./")))
+}
+
+// tarEntry is the position of the content of a file in a tar archive.
+type tarEntry struct {
+	offset, size int64
+}
+
+// extractImage extracts the files of the layers of the images of a tar
+// archive saved with docker save, with the layer they are in, and their
+// configs. Layers are read from the top one down, so files deleted or
+// replaced by a later layer, which are in the image but not in its
+// containers, are known when they are read. Whiteout files are not scanned.
+// Tar archives without a manifest are extracted as other tar archives.
+func (a *Archive) extractImage(ctx context.Context, depth int, path string, reader io.Reader, archiveChan chan Part) error {
+	seekableReader, cleanup, err := seekable(reader)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	r := seekableReader.(readerAtSeeker)
+	size, err := readerSize(r)
+	if err != nil {
+		return err
+	}
+
+	entries, err := indexTar(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return err
+	}
+	open := func(name string) (*io.SectionReader, bool) {
+		entry, ok := entries[strings.TrimPrefix(name, "./")]
+		if !ok {
+			return nil, false
+		}
+		return io.NewSectionReader(r, entry.offset, entry.size), true
+	}
+
+	var manifest tarball.Manifest
+	manifestReader, ok := open(imageManifest)
+	if ok {
+		err = json.NewDecoder(manifestReader).Decode(&manifest)
+	}
+	if !ok || err != nil || len(manifest) == 0 {
+		extractCtx := context.WithValue(context.WithValue(ctx, depthKey, depth+1), pathKey, path)
+		return archiver.Tar{}.Extract(extractCtx, io.NewSectionReader(r, 0, size), nil, a.extractorHandler(archiveChan))
+	}
+
+	logger := logContext.AddLogger(ctx).Logger()
+	// Images saved together share their common layers.
+	scanned := make(map[string]bool)
+	for _, image := range manifest {
+		configReader, ok := open(image.Config)
+		if !ok {
+			return fmt.Errorf("image config %s not found", image.Config)
+		}
+		config, err := v1.ParseConfigFile(configReader)
+		if err != nil {
+			return fmt.Errorf("unable to parse image config %s: %w", image.Config, err)
+		}
+		if !scanned[image.Config] {
+			scanned[image.Config] = true
+			configReader, _ = open(image.Config)
+			if err := sendParts(ctx, memberPath(path, image.Config), configReader, archiveChan); err != nil {
+				return err
+			}
+		}
+
+		createdBy := layerHistory(config)
+		var lower whiteouts
+		for i := len(image.Layers) - 1; i >= 0; i-- {
+			layerPath := image.Layers[i]
+			layerReader, ok := open(layerPath)
+			if !ok {
+				return fmt.Errorf("image layer %s not found", layerPath)
+			}
+			layer := &source_metadatapb.ImageLayer{}
+			if i < len(config.RootFS.DiffIDs) {
+				layer.Digest = config.RootFS.DiffIDs[i].String()
+			}
+			if i < len(createdBy) {
+				layer.CreatedBy = createdBy[i]
+			}
+			logger.V(3).Info("Handling image layer.", "layer", layerPath, "digest", layer.Digest)
+
+			// The layer is read for the whiteouts of the layers below it
+			// even if it was scanned with another image.
+			err := a.extractLayer(ctx, depth, memberPath(path, layerPath), layerReader, layer, &lower, !scanned[layerPath], archiveChan)
+			if err != nil {
+				return err
+			}
+			scanned[layerPath] = true
+		}
+	}
+	return nil
+}
+
+// extractLayer extracts the files of the tar archive of an image layer, which
+// may be compressed, and adds the files it deletes or replaces in the layers
+// below to lower. Files deleted or replaced by the layers above, in lower, are
+// marked as removed. Files are only sent if scan is true.
+func (a *Archive) extractLayer(ctx context.Context, depth int, layerPath string, reader io.Reader, layer *source_metadatapb.ImageLayer, lower *whiteouts, scan bool, archiveChan chan Part) error {
+	layerReader, err := decompressLayer(reader)
+	if err != nil {
+		return fmt.Errorf("unable to read image layer %s: %w", layerPath, err)
+	}
+	defer layerReader.Close()
+
+	// The whiteouts of this layer only apply to the layers below it.
+	var current whiteouts
+	tarReader := tar.NewReader(layerReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read image layer %s: %w", layerPath, err)
+		}
+		name := strings.TrimSuffix(memberPath("", header.Name), "/")
+		dir, base := pathDir(name), path.Base(name)
+		switch {
+		case base == whiteoutOpaque:
+			current.addOpaque(dir)
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			current.addDeleted(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)))
+			continue
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		current.addReplaced(name)
+		if !scan || (header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA) {
+			continue
+		}
+
+		fileLayer := layer
+		if lower.removes(name) {
+			fileLayer = &source_metadatapb.ImageLayer{Digest: layer.Digest, CreatedBy: layer.CreatedBy, Removed: true}
+		}
+		fileCtx := context.WithValue(ctx, imageLayerKey, fileLayer)
+		filePath := memberPath(layerPath, name)
+		err = a.openArchive(fileCtx, depth+2, filePath, a.limitReader(ctx, filePath, tarReader), archiveChan)
+		if err != nil {
+			if errors.Is(err, errMaxArchiveDepthReached) {
+				a.reportSkip(ctx, filePath, sources.SkipReasonDepth, header.Size)
+			}
+			return err
+		}
+	}
+	lower.merge(&current)
+	return nil
+}
+
+// layerHistory returns the instruction that created each layer of an image,
+// from the entries of its history that are not empty layers.
+func layerHistory(config *v1.ConfigFile) []string {
+	var createdBy []string
+	for _, history := range config.History {
+		if history.EmptyLayer {
+			continue
+		}
+		createdBy = append(createdBy, history.CreatedBy)
+	}
+	return createdBy
+}
+
+// decompressLayer returns a reader of the tar archive of a layer, which is
+// uncompressed in images saved with docker save, but may be compressed with
+// gzip or zstd in the OCI layout.
+func decompressLayer(reader io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(reader)
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		decoder, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+// indexTar returns the position of the content of each regular file of a tar
+// archive, by name.
+func indexTar(r io.Reader) (map[string]tarEntry, error) {
+	counter := &countingReader{reader: r}
+	tarReader := tar.NewReader(counter)
+	entries := make(map[string]tarEntry)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg || header.Typeflag == tar.TypeRegA {
+			// The tar reader has read the header, and none of the content.
+			entries[strings.TrimPrefix(header.Name, "./")] = tarEntry{offset: counter.n, size: header.Size}
+		}
+	}
+}
+
+// countingReader counts the bytes read from a reader.
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// whiteouts are the paths the layers above a layer delete or replace.
+type whiteouts struct {
+	// deleted are the files and directories deleted, with their content,
+	// replaced the files replaced, and opaque the directories whose content
+	// is hidden.
+	deleted, replaced, opaque map[string]bool
+}
+
+func (w *whiteouts) addDeleted(name string) { w.add(&w.deleted, name) }
+
+func (w *whiteouts) addReplaced(name string) { w.add(&w.replaced, name) }
+
+func (w *whiteouts) addOpaque(dir string) { w.add(&w.opaque, dir) }
+
+func (w *whiteouts) add(set *map[string]bool, name string) {
+	if *set == nil {
+		*set = make(map[string]bool)
+	}
+	(*set)[name] = true
+}
+
+func (w *whiteouts) merge(other *whiteouts) {
+	for name := range other.deleted {
+		w.addDeleted(name)
+	}
+	for name := range other.replaced {
+		w.addReplaced(name)
+	}
+	for dir := range other.opaque {
+		w.addOpaque(dir)
+	}
+}
+
+// removes reports whether a file of a layer below is deleted or replaced.
+func (w *whiteouts) removes(name string) bool {
+	if w.replaced[name] || w.deleted[name] {
+		return true
+	}
+	for dir := pathDir(name); dir != ""; dir = pathDir(dir) {
+		if w.deleted[dir] || w.opaque[dir] {
+			return true
+		}
+	}
+	// An opaque whiteout at the root hides every file below it.
+	return w.opaque[""]
+}
+
+// pathDir returns the directory of a slash separated relative path, or "" if
+// it is at the root.
+func pathDir(name string) string {
+	dir := path.Dir(name)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
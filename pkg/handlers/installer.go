@@ -0,0 +1,657 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/ulikunitz/xz/lzma"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// installerFormat is the format of a Windows installer. Executables are
+// identified as installerExe, and extracted as the installer of the setup
+// data found in them.
+type installerFormat string
+
+const (
+	installerMSI  installerFormat = "msi"
+	installerExe  installerFormat = "exe"
+	installerNSIS installerFormat = "nsis"
+	installerInno installerFormat = "innosetup"
+)
+
+// errNotInstaller is returned for compound files that are not Windows
+// Installer packages, and executables without setup data.
+var errNotInstaller = errors.New("not an installer")
+
+// The CLSIDs of the root storage of Windows Installer packages, patches and
+// transforms, {000C1084-0000-0000-C000-000000000046} and its siblings.
+var msiCLSIDs = [][16]byte{
+	{0x84, 0x10, 0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46},
+	{0x86, 0x10, 0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46},
+	{0x82, 0x10, 0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46},
+}
+
+// msiNameAlphabet is the alphabet of the names of the streams of Windows
+// Installer packages, which are packed two characters to a UTF-16 unit.
+const msiNameAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz._"
+
+const (
+	// nsisSignature follows the flags of the first header of NSIS
+	// installers, which starts at a multiple of 512 bytes.
+	nsisSignature    = "\xef\xbe\xad\xdeNullsoftInst"
+	nsisHeaderSize   = 28
+	nsisAlignment    = 512
+	nsisCompressed   = 0x80000000
+	innoSetupID      = "Inno Setup Setup Data ("
+	innoSetupIDSize  = 64
+	innoChunkMagic   = "zlb\x1a"
+	innoBlockSize    = 4096
+	installerMaxDict = 64 << 20
+)
+
+// identifyInstaller returns whether a file is a compound file, which may be a
+// Windows Installer package, or an executable, which may have the setup data
+// of an NSIS or Inno Setup installer. The returned reader must be read
+// instead of reader.
+func identifyInstaller(reader io.Reader) (installerFormat, io.Reader, bool) {
+	br := bufio.NewReader(reader)
+	header, _ := br.Peek(len(cfbMagic))
+	switch {
+	case bytes.Equal(header, cfbMagic):
+		return installerMSI, br, true
+	case bytes.HasPrefix(header, []byte("MZ")):
+		return installerExe, br, true
+	}
+	return "", br, false
+}
+
+// extractInstaller extracts the files of a Windows installer: the streams of
+// MSI packages, like their cabinets and custom actions, with the strings and
+// properties of their tables, and the files and script of the setup data of
+// NSIS and Inno Setup installers. Compound files that are not MSI packages,
+// executables that are not installers, and installers that cannot be read
+// are scanned as they are.
+func (a *Archive) extractInstaller(ctx context.Context, depth int, path string, format installerFormat, reader io.Reader, archiveChan chan Part) error {
+	logger := logContext.AddLogger(ctx).Logger()
+	seekableReader, cleanup, err := seekable(reader)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	r := seekableReader.(readerAtSeeker)
+	size, err := readerSize(r)
+	if err != nil {
+		return err
+	}
+
+	if format == installerMSI {
+		err = a.extractMSI(ctx, depth, path, r, size, archiveChan)
+	} else {
+		err = a.extractSetup(ctx, depth, path, r, size, archiveChan)
+	}
+	if errors.Is(err, errNotInstaller) || errors.Is(err, errCorruptCFB) {
+		if !errors.Is(err, errNotInstaller) {
+			logger.V(2).Info("Error reading installer, scanning it as it is.", "format", format, "error", err)
+		}
+		return sendParts(ctx, path, io.NewSectionReader(r, 0, size), archiveChan)
+	}
+	return err
+}
+
+// extractMSI extracts the streams of an MSI package, named by their decoded
+// names, e.g. product.msi!/Binary.CustomAction, and scans the strings of its
+// tables one per line, under strings, and its properties as name = value
+// lines, under Property. The streams of other tables are not scanned.
+func (a *Archive) extractMSI(ctx context.Context, depth int, path string, r io.ReaderAt, size int64, archiveChan chan Part) error {
+	cfb, err := openCompoundFileAt(r, size)
+	if err != nil {
+		return err
+	}
+	if !isMSI(cfb.entries[0].clsid) {
+		return errNotInstaller
+	}
+	logContext.AddLogger(ctx).Logger().V(3).Info("Handling Windows Installer package.")
+
+	strs, refSize := msiStringTable(cfb)
+	if len(strs) > 1 {
+		if err := sendParts(ctx, memberPath(path, "strings"), strings.NewReader(strings.Join(strs[1:], "\n")+"\n"), archiveChan); err != nil {
+			return err
+		}
+	}
+	if entry, ok := cfb.find(msiEncodeName("!Property")); ok {
+		if stream, err := cfb.stream(entry); err == nil {
+			if properties := msiProperties(stream, strs, refSize); properties != "" {
+				if err := sendParts(ctx, memberPath(path, "Property"), strings.NewReader(properties), archiveChan); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return cfb.walk(func(streamPath string, e cfbEntry) error {
+		parts := strings.Split(streamPath, "/")
+		for i, part := range parts {
+			parts[i] = msiStreamName(part)
+		}
+		if strings.HasPrefix(parts[len(parts)-1], "!") {
+			// Tables are scanned as their strings.
+			return nil
+		}
+		label := strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, strings.Join(parts, "/"))
+		stream, err := cfb.streamReader(e)
+		if err != nil {
+			return nil
+		}
+		name := memberPath(path, label)
+		err = a.openArchive(ctx, depth+1, name, a.limitReader(ctx, name, stream), archiveChan)
+		if errors.Is(err, errMaxArchiveDepthReached) {
+			a.reportSkip(ctx, name, sources.SkipReasonDepth, e.size)
+		}
+		return err
+	})
+}
+
+func isMSI(clsid [16]byte) bool {
+	for _, id := range msiCLSIDs {
+		if clsid == id {
+			return true
+		}
+	}
+	return false
+}
+
+// msiStreamName decodes the name of a stream of an MSI package. The names of
+// tables start with !.
+func msiStreamName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r == 0x4840:
+			b.WriteByte('!')
+		case r >= 0x3800 && r < 0x4800:
+			r -= 0x3800
+			b.WriteByte(msiNameAlphabet[r&0x3f])
+			b.WriteByte(msiNameAlphabet[r>>6&0x3f])
+		case r >= 0x4800 && r < 0x4840:
+			b.WriteByte(msiNameAlphabet[r-0x4800])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// msiEncodeName encodes the name of a stream of an MSI package, as it is in
+// the compound file.
+func msiEncodeName(name string) string {
+	var units []rune
+	if strings.HasPrefix(name, "!") {
+		units = append(units, 0x4840)
+		name = name[1:]
+	}
+	for i := 0; i < len(name); i++ {
+		c := strings.IndexByte(msiNameAlphabet, name[i])
+		if c < 0 {
+			units = append(units, rune(name[i]))
+			continue
+		}
+		if i+1 < len(name) {
+			if next := strings.IndexByte(msiNameAlphabet, name[i+1]); next >= 0 {
+				units = append(units, rune(0x3800+c+next<<6))
+				i++
+				continue
+			}
+		}
+		units = append(units, rune(0x4800+c))
+	}
+	return string(units)
+}
+
+// msiStringTable returns the strings of the tables of an MSI package by
+// their ID, starting at 1, and the size of references to them in tables.
+func msiStringTable(cfb *compoundFile) ([]string, int) {
+	strs, refSize := []string{""}, 2
+	poolEntry, ok := cfb.find(msiEncodeName("!_StringPool"))
+	if !ok {
+		return strs, refSize
+	}
+	dataEntry, ok := cfb.find(msiEncodeName("!_StringData"))
+	if !ok {
+		return strs, refSize
+	}
+	pool, err := cfb.stream(poolEntry)
+	if err != nil || len(pool) < 4 {
+		return strs, refSize
+	}
+	data, err := cfb.stream(dataEntry)
+	if err != nil {
+		return strs, refSize
+	}
+	if binary.LittleEndian.Uint16(pool[2:])&0x8000 != 0 {
+		refSize = 3
+	}
+	offset := 0
+	for i := 4; i+4 <= len(pool); i += 4 {
+		length := int(binary.LittleEndian.Uint16(pool[i:]))
+		refs := binary.LittleEndian.Uint16(pool[i+2:])
+		if length == 0 && refs != 0 && i+8 <= len(pool) {
+			// Strings longer than 64 KiB have the high word of their
+			// length in an entry of their own.
+			i += 4
+			length = int(refs)<<16 | int(binary.LittleEndian.Uint16(pool[i:]))
+		}
+		if offset+length > len(data) {
+			break
+		}
+		strs = append(strs, string(data[offset:offset+length]))
+		offset += length
+	}
+	return strs, refSize
+}
+
+// msiProperties returns the rows of the Property table, a column of names
+// then a column of values, as name = value lines.
+func msiProperties(stream []byte, strs []string, refSize int) string {
+	rows := len(stream) / (2 * refSize)
+	ref := func(i int) string {
+		b := stream[i*refSize:]
+		id := int(b[0]) | int(b[1])<<8
+		if refSize == 3 {
+			id |= int(b[2]) << 16
+		}
+		if id < len(strs) {
+			return strs[id]
+		}
+		return ""
+	}
+	var b strings.Builder
+	for row := 0; row < rows; row++ {
+		name, value := ref(row), ref(rows+row)
+		if name == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s = %s\n", name, strings.ReplaceAll(value, "\n", " "))
+	}
+	return b.String()
+}
+
+// extractSetup extracts the setup data of an NSIS or Inno Setup installer
+// appended to its executable.
+func (a *Archive) extractSetup(ctx context.Context, depth int, path string, r io.ReaderAt, size int64, archiveChan chan Part) error {
+	opts := a.options()
+	nsis := indexAt(r, size, 0, []byte(nsisSignature), func(offset int64) bool {
+		return (offset-4)%nsisAlignment == 0
+	})
+	if nsis >= 0 {
+		if opts.SkipsFormat(string(installerNSIS)) {
+			return errNotInstaller
+		}
+		return a.extractNSIS(ctx, depth, path, r, size, nsis-4, archiveChan)
+	}
+	inno := indexAt(r, size, 0, []byte(innoSetupID), func(offset int64) bool {
+		_, _, ok := innoBlockHeader(r, offset+innoSetupIDSize)
+		return ok
+	})
+	if inno >= 0 {
+		if opts.SkipsFormat(string(installerInno)) {
+			return errNotInstaller
+		}
+		return a.extractInno(ctx, depth, path, r, size, inno, archiveChan)
+	}
+	return errNotInstaller
+}
+
+// indexAt returns the offset of the first occurrence of pattern in r from an
+// offset that accept accepts, or -1.
+func indexAt(r io.ReaderAt, size, from int64, pattern []byte, accept func(offset int64) bool) int64 {
+	const window = 1 << 20
+	buf := make([]byte, window+len(pattern)-1)
+	for start := from; start < size; start += window {
+		n, err := r.ReadAt(buf, start)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return -1
+		}
+		for data, base := buf[:n], start; ; {
+			i := bytes.Index(data, pattern)
+			if i < 0 {
+				break
+			}
+			if accept(base + int64(i)) {
+				return base + int64(i)
+			}
+			data, base = data[i+1:], base+int64(i)+1
+		}
+	}
+	return -1
+}
+
+// extractNSIS extracts the setup data of an NSIS installer, which follows its
+// first header: the script of the installer, scanned as its printable text
+// under header, and its files, named file1, file2 and so on, as their names
+// are in the script. The data is compressed with LZMA or deflate, as one
+// stream if it is solid or file by file otherwise. Data compressed with
+// NSIS's bzip2 is not read.
+func (a *Archive) extractNSIS(ctx context.Context, depth int, path string, r io.ReaderAt, size, offset int64, archiveChan chan Part) error {
+	logger := logContext.AddLogger(ctx).Logger()
+	logger.V(3).Info("Handling NSIS installer.")
+	first := make([]byte, nsisHeaderSize)
+	if _, err := r.ReadAt(first, offset); err != nil {
+		return errNotInstaller
+	}
+	end := offset + int64(binary.LittleEndian.Uint32(first[24:]))
+	if end > size || end < offset+nsisHeaderSize {
+		end = size
+	}
+	data := io.NewSectionReader(r, offset+nsisHeaderSize, end-offset-nsisHeaderSize)
+	sig := make([]byte, 10)
+	if _, err := data.ReadAt(sig, 0); err != nil {
+		return errNotInstaller
+	}
+
+	var (
+		solid   bool
+		useLZMA bool
+	)
+	switch {
+	case nsisLZMAOffset(sig) >= 0:
+		solid, useLZMA = true, true
+	case nsisLZMAOffset(sig[4:]) >= 0:
+		useLZMA = true
+	case sig[3] == 0x80:
+		if sig[4] == '1' {
+			a.reportSkip(ctx, path, sources.SkipReasonUnsupported, size)
+			return errNotInstaller
+		}
+	case sig[0] == '1':
+		a.reportSkip(ctx, path, sources.SkipReasonUnsupported, size)
+		return errNotInstaller
+	default:
+		solid = true
+	}
+	decompress := func(block io.Reader) (io.Reader, error) {
+		if !useLZMA {
+			return flate.NewReader(block), nil
+		}
+		return nsisLZMAReader(block)
+	}
+
+	// The first block is the script, then the files follow.
+	files := -1
+	handleFile := func(content io.Reader) error {
+		files++
+		if files == 0 {
+			name := memberPath(path, "header")
+			return sendParts(ctx, name, a.limitReader(ctx, name, newPrintableReader(content)), archiveChan)
+		}
+		name := memberPath(path, fmt.Sprintf("file%d", files))
+		err := a.openArchive(ctx, depth+1, name, a.limitReader(ctx, name, content), archiveChan)
+		if errors.Is(err, errMaxArchiveDepthReached) {
+			a.reportSkip(ctx, name, sources.SkipReasonDepth, 0)
+		}
+		return err
+	}
+
+	if solid {
+		stream, err := decompress(data)
+		if err != nil {
+			return errNotInstaller
+		}
+		stream = &untilErrorReader{r: stream}
+		for {
+			var length uint32
+			if err := binary.Read(stream, binary.LittleEndian, &length); err != nil {
+				return nil
+			}
+			content := io.LimitReader(stream, int64(length&^nsisCompressed))
+			if err := handleFile(content); err != nil {
+				return err
+			}
+			if _, err := io.Copy(io.Discard, content); err != nil {
+				return err
+			}
+		}
+	}
+	for blockOffset := int64(0); blockOffset+4 <= data.Size(); {
+		lengthBytes := make([]byte, 4)
+		if _, err := data.ReadAt(lengthBytes, blockOffset); err != nil {
+			return nil
+		}
+		length := binary.LittleEndian.Uint32(lengthBytes)
+		block := io.NewSectionReader(data, blockOffset+4, int64(length&^nsisCompressed))
+		if length == 0 || blockOffset+4+block.Size() > data.Size() {
+			return nil
+		}
+		blockOffset += 4 + block.Size()
+		var content io.Reader = block
+		if length&nsisCompressed != 0 {
+			stream, err := decompress(block)
+			if err != nil {
+				continue
+			}
+			content = &untilErrorReader{r: stream}
+		}
+		if err := handleFile(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nsisLZMAOffset returns the offset of the properties of an LZMA stream at
+// the start of NSIS data, after the byte of whether the x86 filter is
+// applied if it has one, or -1 if the data is not LZMA.
+func nsisLZMAOffset(data []byte) int {
+	isProps := func(p []byte) bool {
+		return len(p) >= 5 && p[0] == 0x5d && p[1] == 0 && p[2] == 0 && p[4]&0x80 == 0
+	}
+	switch {
+	case isProps(data):
+		return 0
+	case len(data) > 0 && data[0] <= 1 && isProps(data[1:]):
+		return 1
+	}
+	return -1
+}
+
+// nsisLZMAReader returns a reader of the LZMA stream of NSIS data, which has
+// the properties and dictionary size of the stream but not its size.
+func nsisLZMAReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	peek, _ := br.Peek(6)
+	skip := nsisLZMAOffset(peek)
+	if skip < 0 {
+		return nil, errNotInstaller
+	}
+	if _, err := br.Discard(skip); err != nil {
+		return nil, err
+	}
+	props := make([]byte, 5)
+	if _, err := io.ReadFull(br, props); err != nil {
+		return nil, err
+	}
+	return newLZMAReader(props, br)
+}
+
+// newLZMAReader returns a reader of a raw LZMA stream of unknown size, with
+// its properties and dictionary size. Dictionaries are capped, for streams
+// that claim huge ones.
+func newLZMAReader(props []byte, r io.Reader) (io.Reader, error) {
+	header := make([]byte, lzma.HeaderLen)
+	copy(header, props[:5])
+	if binary.LittleEndian.Uint32(header[1:]) > installerMaxDict {
+		binary.LittleEndian.PutUint32(header[1:], installerMaxDict)
+	}
+	for i := 5; i < lzma.HeaderLen; i++ {
+		header[i] = 0xff
+	}
+	return lzma.NewReader(io.MultiReader(bytes.NewReader(header), r))
+}
+
+// untilErrorReader ends at the first error of a decompressor, as installer
+// streams may be cut short or end without an end marker. The data decoded
+// before the error is still read.
+type untilErrorReader struct {
+	r io.Reader
+}
+
+func (u *untilErrorReader) Read(p []byte) (int, error) {
+	n, err := u.r.Read(p)
+	if err != nil {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// innoBlockHeader returns the offset and stored size of the compressed
+// block at an offset of an Inno Setup installer, and whether its checksum
+// is valid: a CRC-32 of the stored size and whether the block is
+// compressed.
+func innoBlockHeader(r io.ReaderAt, offset int64) (int64, uint32, bool) {
+	header := make([]byte, 9)
+	if _, err := r.ReadAt(header, offset); err != nil {
+		return 0, 0, false
+	}
+	if crc32.ChecksumIEEE(header[4:9]) != binary.LittleEndian.Uint32(header) {
+		return 0, 0, false
+	}
+	return offset + 9, binary.LittleEndian.Uint32(header[4:]), header[8] != 0
+}
+
+// extractInno extracts the setup data of an Inno Setup installer: its setup
+// header, with the entries of its script, scanned as its printable text under
+// header, and the chunks of the compressed files of the installer, named
+// chunk1, chunk2 and so on. The files of a chunk are scanned together, as
+// where they start is in the version-specific entries of the header.
+func (a *Archive) extractInno(ctx context.Context, depth int, path string, r io.ReaderAt, size, offset int64, archiveChan chan Part) error {
+	logContext.AddLogger(ctx).Logger().V(3).Info("Handling Inno Setup installer.")
+	blockHeader := make([]byte, 9)
+	if _, err := r.ReadAt(blockHeader, offset+innoSetupIDSize); err != nil {
+		return errNotInstaller
+	}
+	start := offset + innoSetupIDSize + 9
+	stored := int64(binary.LittleEndian.Uint32(blockHeader[4:]))
+	if start+stored > size {
+		stored = size - start
+	}
+	var header io.Reader = &innoBlockReader{r: io.NewSectionReader(r, start, stored)}
+	if blockHeader[8] != 0 {
+		props := make([]byte, 5)
+		if _, err := io.ReadFull(header, props); err != nil {
+			return errNotInstaller
+		}
+		lzmaReader, err := newLZMAReader(props, header)
+		if err != nil {
+			return errNotInstaller
+		}
+		header = &untilErrorReader{r: lzmaReader}
+	}
+	name := memberPath(path, "header")
+	if err := sendParts(ctx, name, a.limitReader(ctx, name, newPrintableReader(header)), archiveChan); err != nil {
+		return err
+	}
+
+	// Chunks run to the next chunk, as their size is in the header.
+	chunk := indexAt(r, size, start+stored, []byte(innoChunkMagic), func(int64) bool { return true })
+	for i := 1; chunk >= 0; i++ {
+		next := indexAt(r, size, chunk+int64(len(innoChunkMagic)), []byte(innoChunkMagic), func(int64) bool { return true })
+		end := next
+		if end < 0 {
+			end = size
+		}
+		content, ok := innoChunkReader(io.NewSectionReader(r, chunk+int64(len(innoChunkMagic)), end-chunk-int64(len(innoChunkMagic))))
+		name := memberPath(path, fmt.Sprintf("chunk%d", i))
+		if !ok {
+			// Encrypted chunks are not read.
+			a.reportSkip(ctx, name, sources.SkipReasonUnsupported, end-chunk)
+		} else {
+			err := a.openArchive(ctx, depth+1, name, a.limitReader(ctx, name, content), archiveChan)
+			if errors.Is(err, errMaxArchiveDepthReached) {
+				a.reportSkip(ctx, name, sources.SkipReasonDepth, end-chunk)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		chunk = next
+	}
+	return nil
+}
+
+// innoChunkReader returns a reader of the content of a chunk of Inno Setup
+// files, compressed with LZMA, LZMA2 or zlib.
+func innoChunkReader(chunk *io.SectionReader) (io.Reader, bool) {
+	head := make([]byte, 5)
+	if _, err := chunk.ReadAt(head, 0); err != nil {
+		return nil, false
+	}
+	switch {
+	case head[0] == 0x5d:
+		r, err := newLZMAReader(head, io.NewSectionReader(chunk, 5, chunk.Size()-5))
+		if err != nil {
+			return nil, false
+		}
+		return &untilErrorReader{r: r}, true
+	case head[0] <= 40:
+		dictCap := int64(2|head[0]&1) << (head[0]/2 + 11)
+		if dictCap > installerMaxDict || head[0] == 40 {
+			dictCap = installerMaxDict
+		}
+		r, err := lzma.Reader2Config{DictCap: int(dictCap)}.NewReader2(io.NewSectionReader(chunk, 1, chunk.Size()-1))
+		if err != nil {
+			return nil, false
+		}
+		return &untilErrorReader{r: r}, true
+	case head[0] == 0x78:
+		r, err := zlib.NewReader(chunk)
+		if err != nil {
+			return nil, false
+		}
+		return &untilErrorReader{r: r}, true
+	}
+	return nil, false
+}
+
+// innoBlockReader reads the data of a block of an Inno Setup installer,
+// which is stored in parts of 4 KiB, each after its CRC-32.
+type innoBlockReader struct {
+	r    io.Reader
+	part []byte
+}
+
+func (b *innoBlockReader) Read(p []byte) (int, error) {
+	if len(b.part) == 0 {
+		buf := make([]byte, 4+innoBlockSize)
+		n, err := io.ReadFull(b.r, buf)
+		if n <= 4 {
+			if err == nil || errors.Is(err, io.ErrUnexpectedEOF) {
+				err = io.EOF
+			}
+			return 0, err
+		}
+		if crc32.ChecksumIEEE(buf[4:n]) != binary.LittleEndian.Uint32(buf) {
+			return 0, errNotInstaller
+		}
+		b.part = buf[4:n]
+	}
+	n := copy(p, b.part)
+	b.part = b.part[n:]
+	return n, nil
+}
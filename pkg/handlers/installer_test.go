@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+func makeMSI(t *testing.T) []byte {
+	t.Helper()
+	strs := []string{"ProductName", "Example", "DB_PASSWORD", "hunter2"}
+	pool := []byte{0xe4, 0x04, 0, 0}
+	for _, s := range strs {
+		pool = binary.LittleEndian.AppendUint16(binary.LittleEndian.AppendUint16(pool, uint16(len(s))), 1)
+	}
+	var property []byte
+	for _, id := range []uint16{1, 3, 2, 4} {
+		property = binary.LittleEndian.AppendUint16(property, id)
+	}
+	payload := strings.Repeat("# settings\n", 400) + "token = payload\n"
+	streams := map[string][]byte{
+		msiEncodeName("!_StringPool"):  pool,
+		msiEncodeName("!_StringData"):  []byte(strings.Join(strs, "")),
+		msiEncodeName("!Property"):     property,
+		msiEncodeName("Binary.config"): []byte("api_key = custom-action\n"),
+		msiEncodeName("Payload.dat"):   []byte(payload),
+		"\x05SummaryInformation":       []byte("Author: Example\n"),
+	}
+	var paths []string
+	for name := range streams {
+		paths = append(paths, name)
+	}
+	image := makeCompoundFile(t, paths, streams)
+	// The CLSID of the root entry, which follows the header and FAT sector.
+	copy(image[2*512+80:], msiCLSIDs[0][:])
+	return image
+}
+
+func TestMSIStreamName(t *testing.T) {
+	for _, name := range []string{"!_StringPool", "Binary.config", "a", "!Property", "SummaryInformation"} {
+		assert.Equal(t, name, msiStreamName(msiEncodeName(name)))
+	}
+	assert.Equal(t, "\x05SummaryInformation", msiStreamName("\x05SummaryInformation"))
+}
+
+func TestHandleFile_MSI(t *testing.T) {
+	files := handleNamedFile(t, "product.msi", makeMSI(t))
+	assert.Equal(t, map[string]string{
+		"strings":            "ProductName\nExample\nDB_PASSWORD\nhunter2\n",
+		"Property":           "ProductName = Example\nDB_PASSWORD = hunter2\n",
+		"Binary.config":      "api_key = custom-action\n",
+		"Payload.dat":        strings.Repeat("# settings\n", 400) + "token = payload\n",
+		"SummaryInformation": "Author: Example\n",
+	}, files)
+}
+
+func TestHandleFile_CompoundFileNotMSI(t *testing.T) {
+	image := makeCompoundFile(t, []string{"WordDocument"}, map[string][]byte{"WordDocument": []byte("password = hunter2\n")})
+	files := handleNamedFile(t, "letter.doc", image)
+	require.Len(t, files, 1)
+	for _, content := range files {
+		assert.Equal(t, string(image), content)
+	}
+}
+
+// lzmaRaw returns the properties and raw LZMA stream of data, as installers
+// store them, without the size of the classic header.
+func lzmaRaw(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := lzma.WriterConfig{EOSMarker: true}.NewWriter(&buf)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return append(buf.Bytes()[:5:5], buf.Bytes()[lzma.HeaderLen:]...)
+}
+
+// makeNSIS returns an executable with the setup data of an NSIS installer,
+// solid LZMA or deflated block by block.
+func makeNSIS(t *testing.T, solid bool, blocks [][]byte) []byte {
+	t.Helper()
+	var data []byte
+	if solid {
+		var stream []byte
+		for _, b := range blocks {
+			stream = append(binary.LittleEndian.AppendUint32(stream, uint32(len(b))), b...)
+		}
+		data = lzmaRaw(t, stream)
+	} else {
+		for _, b := range blocks {
+			var buf bytes.Buffer
+			w, err := flate.NewWriter(&buf, flate.BestCompression)
+			require.NoError(t, err)
+			_, err = w.Write(b)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			data = append(binary.LittleEndian.AppendUint32(data, uint32(buf.Len())|nsisCompressed), buf.Bytes()...)
+		}
+	}
+	exe := make([]byte, nsisAlignment)
+	copy(exe, "MZ")
+	// A signature that is not at the start of a first header.
+	copy(exe[100:], nsisSignature)
+	exe = binary.LittleEndian.AppendUint32(exe, 0)
+	exe = append(exe, nsisSignature...)
+	exe = binary.LittleEndian.AppendUint32(exe, uint32(len(blocks[0])))
+	exe = binary.LittleEndian.AppendUint32(exe, uint32(nsisHeaderSize+len(data)))
+	return append(exe, data...)
+}
+
+func TestHandleFile_NSIS(t *testing.T) {
+	// The script of the installer, then its files.
+	blocks := [][]byte{
+		[]byte("\x00\x01Example Setup\x00$INSTDIR\\config.ini\x00"),
+		[]byte("password = hunter2\n"),
+		[]byte("token = second\n"),
+	}
+	want := map[string]string{
+		"header": "Example Setup\n$INSTDIR\\config.ini\n",
+		"file1":  "password = hunter2\n",
+		"file2":  "token = second\n",
+	}
+	assert.Equal(t, want, handleNamedFile(t, "setup.exe", makeNSIS(t, true, blocks)))
+	assert.Equal(t, want, handleNamedFile(t, "setup.exe", makeNSIS(t, false, blocks)))
+}
+
+// innoBlock returns data in a block of Inno Setup setup data, compressed
+// with LZMA, in parts of 4 KiB after their CRC-32.
+func innoBlock(t *testing.T, data []byte) []byte {
+	t.Helper()
+	compressed := lzmaRaw(t, data)
+	var stored []byte
+	for len(compressed) > 0 {
+		n := innoBlockSize
+		if n > len(compressed) {
+			n = len(compressed)
+		}
+		stored = binary.LittleEndian.AppendUint32(stored, crc32.ChecksumIEEE(compressed[:n]))
+		stored = append(stored, compressed[:n]...)
+		compressed = compressed[n:]
+	}
+	header := binary.LittleEndian.AppendUint32(nil, uint32(len(stored)))
+	header = append(header, 1)
+	block := binary.LittleEndian.AppendUint32(nil, crc32.ChecksumIEEE(header))
+	return append(append(block, header...), stored...)
+}
+
+func makeInno(t *testing.T) []byte {
+	t.Helper()
+	exe := make([]byte, 1024)
+	copy(exe, "MZ")
+	id := make([]byte, innoSetupIDSize)
+	copy(id, innoSetupID+"6.0.0) (u)")
+	exe = append(exe, id...)
+	setup := append([]byte("\x00\x10"), utf16LE("Example App")...)
+	setup = append(setup, "\x00\x00{app}\\settings.json\x00"...)
+	exe = append(exe, innoBlock(t, setup)...)
+	exe = append(exe, innoChunkMagic...)
+	exe = append(exe, lzmaRaw(t, []byte("password = hunter2\n"))...)
+	var zlibbed bytes.Buffer
+	w := zlib.NewWriter(&zlibbed)
+	_, err := w.Write([]byte("token = second\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	exe = append(exe, innoChunkMagic...)
+	return append(exe, zlibbed.Bytes()...)
+}
+
+func TestHandleFile_InnoSetup(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"header": "Example App\n{app}\\settings.json\n",
+		"chunk1": "password = hunter2\n",
+		"chunk2": "token = second\n",
+	}, handleNamedFile(t, "setup.exe", makeInno(t)))
+}
+
+func TestHandleFile_ExecutableNotInstaller(t *testing.T) {
+	exe := append([]byte("MZ\x90\x00"), "password = hunter2\n"...)
+	assert.Equal(t, map[string]string{"": string(exe)}, handleNamedFile(t, "tool.exe", exe))
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	isoSectorSize = 2048
+	isoIdentifier = "CD001"
+	// isoDescriptorPrimary and isoDescriptorSupplementary are the types of
+	// the volume descriptors with a root directory, and isoDescriptorEnd
+	// ends the list of descriptors.
+	isoDescriptorPrimary       = 1
+	isoDescriptorSupplementary = 2
+	isoDescriptorEnd           = 255
+	isoFlagDirectory           = 0x2
+	// isoMaxDirectories bounds the directories read, for images whose
+	// directories loop.
+	isoMaxDirectories = 1 << 16
+)
+
+// isoRecord is a directory record of an ISO 9660 image.
+type isoRecord struct {
+	name   string
+	extent int64
+	size   int64
+	dir    bool
+}
+
+// walkISO9660 calls walk with each file of an ISO 9660 image. Names are read
+// from the Joliet directories if the image has them, or from Rock Ridge
+// entries, or are the ISO 9660 names without their version.
+func walkISO9660(r io.ReaderAt, size int64, walk walkFunc) error {
+	var root []byte
+	joliet := false
+	descriptor := make([]byte, isoSectorSize)
+	for sector := int64(16); (sector+1)*isoSectorSize <= size; sector++ {
+		if err := readAt(r, descriptor, sector*isoSectorSize); err != nil {
+			return err
+		}
+		if string(descriptor[1:6]) != isoIdentifier || descriptor[0] == isoDescriptorEnd {
+			break
+		}
+		switch descriptor[0] {
+		case isoDescriptorPrimary:
+			if root == nil {
+				root = append([]byte(nil), descriptor[156:190]...)
+			}
+		case isoDescriptorSupplementary:
+			// Joliet descriptors are supplementary descriptors with one of
+			// the escape sequences of UCS-2 levels 1 to 3.
+			escape := string(descriptor[88:91])
+			if escape == "%/@" || escape == "%/C" || escape == "%/E" {
+				root = append([]byte(nil), descriptor[156:190]...)
+				joliet = true
+			}
+		}
+	}
+	if root == nil {
+		return errCorruptDiskImage
+	}
+
+	// The root directory record of a volume descriptor is the record of the
+	// directory itself.
+	rootRecord := isoRecord{
+		extent: int64(binary.LittleEndian.Uint32(root[2:6])),
+		size:   int64(binary.LittleEndian.Uint32(root[10:14])),
+		dir:    true,
+	}
+	visited := make(map[int64]bool)
+	var walkDir func(dir isoRecord, parent string) error
+	walkDir = func(dir isoRecord, parent string) error {
+		if visited[dir.extent] || len(visited) >= isoMaxDirectories {
+			return nil
+		}
+		visited[dir.extent] = true
+		if dir.extent*isoSectorSize+dir.size > size {
+			return errCorruptDiskImage
+		}
+		data := make([]byte, dir.size)
+		if err := readAt(r, data, dir.extent*isoSectorSize); err != nil {
+			return err
+		}
+
+		for offset := 0; offset < len(data); {
+			length := int(data[offset])
+			if length == 0 {
+				// Records do not cross sectors, the rest of which is
+				// zeroed.
+				offset = (offset/isoSectorSize + 1) * isoSectorSize
+				continue
+			}
+			if offset+length > len(data) {
+				return errCorruptDiskImage
+			}
+			record, ok := parseISORecord(data[offset:offset+length], joliet)
+			offset += length
+			if !ok {
+				continue
+			}
+			name := record.name
+			if parent != "" {
+				name = parent + "/" + name
+			}
+			if record.dir {
+				if err := walkDir(record, name); err != nil {
+					return err
+				}
+				continue
+			}
+			if record.extent*isoSectorSize+record.size > size {
+				return errCorruptDiskImage
+			}
+			if err := walk(name, io.NewSectionReader(r, record.extent*isoSectorSize, record.size)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walkDir(rootRecord, "")
+}
+
+// parseISORecord parses a directory record. It returns false for the records
+// of the directory itself and its parent, and invalid records.
+func parseISORecord(record []byte, joliet bool) (isoRecord, bool) {
+	if len(record) < 34 {
+		return isoRecord{}, false
+	}
+	nameLen := int(record[32])
+	if 33+nameLen > len(record) {
+		return isoRecord{}, false
+	}
+	rawName := record[33 : 33+nameLen]
+	r := isoRecord{
+		extent: int64(binary.LittleEndian.Uint32(record[2:6])),
+		size:   int64(binary.LittleEndian.Uint32(record[10:14])),
+		dir:    record[25]&isoFlagDirectory != 0,
+	}
+	if nameLen == 1 && (rawName[0] == 0 || rawName[0] == 1) {
+		return isoRecord{}, false
+	}
+
+	// The system use area after the name, padded to an even offset, holds
+	// Rock Ridge entries.
+	var systemUse []byte
+	if start := 33 + nameLen + (1 - nameLen%2); start < len(record) {
+		systemUse = record[start:]
+	}
+	if name, ok := rockRidgeName(systemUse); ok && !joliet {
+		r.name = name
+	} else {
+		if joliet {
+			units := make([]uint16, nameLen/2)
+			for i := range units {
+				units[i] = binary.BigEndian.Uint16(rawName[2*i:])
+			}
+			r.name = string(utf16.Decode(units))
+		} else {
+			r.name = string(rawName)
+		}
+		// File names end in a version, after a dot if they have no
+		// extension.
+		if !r.dir {
+			r.name, _, _ = strings.Cut(r.name, ";")
+			r.name = strings.TrimSuffix(r.name, ".")
+		}
+	}
+	if r.name == "" || strings.Contains(r.name, "/") {
+		return isoRecord{}, false
+	}
+	return r, true
+}
+
+// rockRidgeName returns the name of the NM entries of the system use area of
+// a directory record.
+func rockRidgeName(systemUse []byte) (string, bool) {
+	var name bytes.Buffer
+	found := false
+	for len(systemUse) >= 4 {
+		length := int(systemUse[2])
+		if length < 4 || length > len(systemUse) {
+			break
+		}
+		if string(systemUse[:2]) == "NM" && length >= 5 {
+			// The flags of the entry mark the current and parent
+			// directories, and names continued in the next entry.
+			if systemUse[4]&0x6 != 0 {
+				return "", false
+			}
+			name.Write(systemUse[5:length])
+			found = true
+		}
+		systemUse = systemUse[length:]
+	}
+	return name.String(), found
+}
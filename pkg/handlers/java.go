@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zip"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	classMagic = 0xcafebabe
+	// classMinVersion is the major version of the class files of Java 1.0.
+	// Universal Mach-O binaries share their magic number, with a number of
+	// architectures in its place.
+	classMinVersion = 45
+)
+
+// Tags of the constants of the constant pool of a class file.
+const (
+	classConstantUtf8               = 1
+	classConstantInteger            = 3
+	classConstantFloat              = 4
+	classConstantLong               = 5
+	classConstantDouble             = 6
+	classConstantClass              = 7
+	classConstantString             = 8
+	classConstantFieldref           = 9
+	classConstantMethodref          = 10
+	classConstantInterfaceMethodref = 11
+	classConstantNameAndType        = 12
+	classConstantMethodHandle       = 15
+	classConstantMethodType         = 16
+	classConstantDynamic            = 17
+	classConstantInvokeDynamic      = 18
+	classConstantModule             = 19
+	classConstantPackage            = 20
+)
+
+var errCorruptClass = errors.New("corrupt class file")
+
+// isJavaArchive reports whether a zip archive is a Java archive, like a jar,
+// war or ear: one with a manifest, compiled classes or a web application
+// directory.
+func isJavaArchive(zr *zip.Reader) bool {
+	for _, f := range zr.File {
+		if f.Name == "META-INF/MANIFEST.MF" || path.Ext(f.Name) == ".class" || strings.HasPrefix(f.Name, "WEB-INF/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isClassFile reports whether data starts with the header of a class file.
+func isClassFile(data []byte) bool {
+	return len(data) >= 8 && binary.BigEndian.Uint32(data) == classMagic &&
+		binary.BigEndian.Uint16(data[6:]) >= classMinVersion
+}
+
+// classStrings returns the strings of the constant pool of a class file, one
+// per line: its string literals, the values of its string constants and
+// annotations, and the names and descriptors of its classes, fields and
+// methods. Strings are in the modified UTF-8 of class files, which only
+// differs from UTF-8 for NUL and characters outside the BMP.
+func classStrings(data []byte) ([]byte, error) {
+	if !isClassFile(data) || len(data) < 10 {
+		return nil, errCorruptClass
+	}
+	count := int(binary.BigEndian.Uint16(data[8:]))
+	pool := data[10:]
+
+	var out bytes.Buffer
+	// Constants are numbered from 1, and longs and doubles take two numbers.
+	for i := 1; i < count; i++ {
+		if len(pool) < 1 {
+			return nil, errCorruptClass
+		}
+		var size int
+		switch pool[0] {
+		case classConstantUtf8:
+			if len(pool) < 3 {
+				return nil, errCorruptClass
+			}
+			n := int(binary.BigEndian.Uint16(pool[1:]))
+			if 3+n > len(pool) {
+				return nil, errCorruptClass
+			}
+			if n > 0 {
+				out.Write(pool[3 : 3+n])
+				out.WriteByte('\n')
+			}
+			size = 2 + n
+		case classConstantClass, classConstantString, classConstantMethodType, classConstantModule, classConstantPackage:
+			size = 2
+		case classConstantMethodHandle:
+			size = 3
+		case classConstantInteger, classConstantFloat, classConstantFieldref, classConstantMethodref,
+			classConstantInterfaceMethodref, classConstantNameAndType, classConstantDynamic, classConstantInvokeDynamic:
+			size = 4
+		case classConstantLong, classConstantDouble:
+			size = 8
+			i++
+		default:
+			return nil, errCorruptClass
+		}
+		if 1+size > len(pool) {
+			return nil, errCorruptClass
+		}
+		pool = pool[1+size:]
+	}
+	return out.Bytes(), nil
+}
+
+// extractJavaArchive extracts the files of a jar, war or ear, scanning the
+// strings of the constant pools of its classes rather than their raw bytes,
+// so credentials hardcoded in compiled code are found. Nested archives, like
+// the libraries of web applications, are extracted in turn.
+func (a *Archive) extractJavaArchive(ctx context.Context, zr *zip.Reader, archiveChan chan Part) error {
+	logger := logContext.AddLogger(ctx).Logger()
+	logger.V(3).Info("Handling Java archive.")
+	return a.extractDecodedZip(ctx, zr, func(name string) bool {
+		return path.Ext(name) == ".class"
+	}, func(_ string, data []byte) ([]byte, bool) {
+		text, err := classStrings(data)
+		return text, err == nil
+	}, archiveChan)
+}
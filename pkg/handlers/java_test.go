@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeClass returns a class file whose constant pool has a class, a long and
+// a string literal.
+func makeClass(className, literal string) []byte {
+	utf8 := func(s string) []byte {
+		return append(binary.BigEndian.AppendUint16([]byte{classConstantUtf8}, uint16(len(s))), s...)
+	}
+	class := binary.BigEndian.AppendUint32(nil, classMagic)
+	class = binary.BigEndian.AppendUint16(class, 0)
+	class = binary.BigEndian.AppendUint16(class, 52)
+	// Constants 1 to 6, with the long taking 3 and 4.
+	class = binary.BigEndian.AppendUint16(class, 7)
+	class = append(class, utf8(className)...)
+	class = append(class, classConstantClass, 0, 1)
+	class = append(class, classConstantLong, 0, 0, 0, 0, 0, 0, 0, 42)
+	class = append(class, utf8(literal)...)
+	class = append(class, classConstantString, 0, 5)
+	// The rest of the class is not read.
+	return append(class, make([]byte, 16)...)
+}
+
+func TestClassStrings(t *testing.T) {
+	text, err := classStrings(makeClass("com/example/Config", "sk_live_test"))
+	require.NoError(t, err)
+	assert.Equal(t, "com/example/Config\nsk_live_test\n", string(text))
+
+	_, err = classStrings(makeClass("com/example/Config", "sk_live_test")[:20])
+	assert.ErrorIs(t, err, errCorruptClass)
+
+	// Universal Mach-O binaries have the same magic number.
+	_, err = classStrings([]byte{0xca, 0xfe, 0xba, 0xbe, 0, 0, 0, 2, 0, 0})
+	assert.ErrorIs(t, err, errCorruptClass)
+}
+
+func TestHandleFile_War(t *testing.T) {
+	lib := makeZip(t, map[string][]byte{
+		"META-INF/MANIFEST.MF":        []byte("Manifest-Version: 1.0\n"),
+		"com/example/Client.class":    makeClass("com/example/Client", "ghp_secret"),
+		"com/example/notaclass.class": []byte("plain text"),
+	})
+	war := makeZip(t, map[string][]byte{
+		"WEB-INF/web.xml":                       []byte("<web-app/>"),
+		"WEB-INF/classes/com/example/App.class": makeClass("com/example/App", "AKIAEXAMPLE"),
+		"WEB-INF/lib/client.jar":                lib,
+	})
+	files := handleDiskImage(t, war)
+	assert.Equal(t, "<web-app/>", files["WEB-INF/web.xml"])
+	assert.Equal(t, "com/example/App\nAKIAEXAMPLE\n", files["WEB-INF/classes/com/example/App.class"])
+	assert.Equal(t, "com/example/Client\nghp_secret\n", files["WEB-INF/lib/client.jar!/com/example/Client.class"])
+	assert.Equal(t, "plain text", files["WEB-INF/lib/client.jar!/com/example/notaclass.class"])
+}
@@ -0,0 +1,486 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	lzxNumChars          = 256
+	lzxMinMatch          = 2
+	lzxPretreeSize       = 20
+	lzxAlignedSize       = 8
+	lzxLengthSize        = 249
+	lzxBlockVerbatim     = 1
+	lzxBlockAligned      = 2
+	lzxBlockUncompressed = 3
+	lzxMinWindowBits     = 15
+	lzxMaxWindowBits     = 21
+	// lzxWIMTranslation is the size of the x86 call translation of WIM
+	// chunks, which have no header.
+	lzxWIMTranslation = 12000000
+	lzxMaxFrames      = 32768
+	// huffmanTableBits is the length of the codes decoded by table lookup.
+	// Longer codes are decoded bit by bit.
+	huffmanTableBits = 10
+	huffmanMaxLen    = 16
+)
+
+var errCorruptLZX = errors.New("corrupt LZX data")
+
+// lzxPositionSlots is the number of position slots of each window size, from
+// 2^15 to 2^21 bytes.
+var lzxPositionSlots = [...]int{30, 32, 34, 36, 38, 42, 50}
+
+// lzxExtraBits and lzxPositionBase are the number of footer bits and the
+// base offset of each position slot.
+var lzxExtraBits, lzxPositionBase = func() ([52]uint, [52]uint32) {
+	var extra [52]uint
+	var base [52]uint32
+	for i, j := 0, uint(0); i < 51; i += 2 {
+		extra[i], extra[i+1] = j, j
+		if i != 0 && j < 17 {
+			j++
+		}
+	}
+	for i, j := 0, uint32(0); i < 51; i++ {
+		base[i] = j
+		j += 1 << extra[i]
+	}
+	return extra, base
+}()
+
+// bitReader16 reads bits from the most significant bit of 16-bit little
+// endian words, the bitstream of LZX and XPRESS. Reads past the end of the
+// data read zeros, and fail once they read far past it.
+type bitReader16 struct {
+	data []byte
+	pos  int
+	buf  uint32
+	n    uint
+}
+
+func (b *bitReader16) word() uint32 {
+	var w uint32
+	if b.pos+1 < len(b.data) {
+		w = uint32(binary.LittleEndian.Uint16(b.data[b.pos:]))
+	}
+	b.pos += 2
+	return w
+}
+
+// ensure buffers at least n bits, up to 16.
+func (b *bitReader16) ensure(n uint) {
+	for b.n < n {
+		b.buf |= b.word() << (16 - b.n)
+		b.n += 16
+	}
+}
+
+func (b *bitReader16) overrun() bool {
+	return b.pos > len(b.data)+4
+}
+
+func (b *bitReader16) consume(n uint) {
+	b.buf <<= n
+	b.n -= n
+}
+
+// bits reads n bits, up to 32.
+func (b *bitReader16) bits(n uint) uint32 {
+	if n == 0 {
+		return 0
+	}
+	if n > 16 {
+		hi := b.bits(n - 16)
+		return hi<<16 | b.bits(16)
+	}
+	b.ensure(n)
+	v := b.buf >> (32 - n)
+	b.consume(n)
+	return v
+}
+
+// huffman decodes the canonical Huffman codes of code lengths, as in LZX and
+// XPRESS: shorter codes first, then in the order of their symbols.
+type huffman struct {
+	// table maps the next huffmanTableBits bits to a symbol and the length
+	// of its code, symbol<<8 | length, or 0 for longer codes.
+	table   [1 << huffmanTableBits]uint32
+	counts  [huffmanMaxLen + 1]int
+	symbols []uint16
+	empty   bool
+}
+
+func newHuffman(lengths []uint8) (*huffman, error) {
+	h := &huffman{}
+	for _, l := range lengths {
+		if l > huffmanMaxLen {
+			return nil, errCorruptLZX
+		}
+		h.counts[l]++
+	}
+	h.counts[0] = 0
+	left := 1
+	for l := 1; l <= huffmanMaxLen; l++ {
+		left = left<<1 - h.counts[l]
+		if left < 0 {
+			return nil, errCorruptLZX
+		}
+	}
+	h.empty = left == 1<<huffmanMaxLen
+
+	var offsets [huffmanMaxLen + 2]int
+	for l := 1; l <= huffmanMaxLen; l++ {
+		offsets[l+1] = offsets[l] + h.counts[l]
+	}
+	h.symbols = make([]uint16, offsets[huffmanMaxLen+1])
+	for sym, l := range lengths {
+		if l != 0 {
+			h.symbols[offsets[l]] = uint16(sym)
+			offsets[l]++
+		}
+	}
+	code, index := 0, 0
+	for l := 1; l <= huffmanTableBits; l++ {
+		for i := 0; i < h.counts[l]; i++ {
+			entry := uint32(h.symbols[index])<<8 | uint32(l)
+			start := code << (huffmanTableBits - l)
+			for j := 0; j < 1<<(huffmanTableBits-l); j++ {
+				h.table[start+j] = entry
+			}
+			code++
+			index++
+		}
+		code <<= 1
+	}
+	return h, nil
+}
+
+func (h *huffman) decode(b *bitReader16) (int, error) {
+	if h.empty {
+		return 0, errCorruptLZX
+	}
+	b.ensure(huffmanMaxLen)
+	if entry := h.table[b.buf>>(32-huffmanTableBits)]; entry != 0 {
+		b.consume(uint(entry & 0xff))
+		return int(entry >> 8), nil
+	}
+	code, first, index := 0, 0, 0
+	for l := 1; l <= huffmanMaxLen; l++ {
+		code |= int(b.buf >> (32 - l) & 1)
+		count := h.counts[l]
+		if code-first < count {
+			b.consume(uint(l))
+			return int(h.symbols[index+code-first]), nil
+		}
+		index += count
+		first = (first + count) << 1
+		code <<= 1
+	}
+	return 0, errCorruptLZX
+}
+
+// lzxDecoder decodes LZX data, the compression of cabinets and WIM images,
+// frame by frame. The frames of cabinets are the data blocks of a folder,
+// which share the window and trees of the decoder, while each chunk of a WIM
+// resource is decoded by a decoder of its own.
+type lzxDecoder struct {
+	window []byte
+	pos    int
+	slots  int
+	// wim is whether the data is of a WIM image, whose block sizes are
+	// coded differently and which has no header.
+	wim         bool
+	started     bool
+	translation int32
+	frames      int
+	offset      int64
+
+	r                     [3]uint32
+	mainLens, lengthLens  []uint8
+	main, length, aligned *huffman
+	blockType             int
+	blockLength           int
+	blockRemaining        int
+}
+
+func newLZXDecoder(windowBits int, wim bool) (*lzxDecoder, error) {
+	if windowBits < lzxMinWindowBits || windowBits > lzxMaxWindowBits {
+		return nil, errCorruptLZX
+	}
+	d := &lzxDecoder{
+		window: make([]byte, 1<<windowBits),
+		slots:  lzxPositionSlots[windowBits-lzxMinWindowBits],
+		wim:    wim,
+		r:      [3]uint32{1, 1, 1},
+	}
+	d.mainLens = make([]uint8, lzxNumChars+8*d.slots)
+	d.lengthLens = make([]uint8, lzxLengthSize)
+	if wim {
+		d.started, d.translation = true, lzxWIMTranslation
+	}
+	return d, nil
+}
+
+// decodeFrame decodes the next frame, of size bytes, from its compressed
+// data.
+func (d *lzxDecoder) decodeFrame(data []byte, size int) ([]byte, error) {
+	b := &bitReader16{data: data}
+	if !d.started {
+		d.started = true
+		if b.bits(1) == 1 {
+			d.translation = int32(b.bits(32))
+		}
+	}
+	if d.pos+size > len(d.window) {
+		return nil, errCorruptLZX
+	}
+	start := d.pos
+	for remaining := size; remaining > 0; {
+		if d.blockRemaining == 0 {
+			if err := d.readBlockHeader(b); err != nil {
+				return nil, err
+			}
+		}
+		n := remaining
+		if n > d.blockRemaining {
+			n = d.blockRemaining
+		}
+		var produced int
+		if d.blockType == lzxBlockUncompressed {
+			if b.pos+n > len(data) {
+				return nil, errCorruptLZX
+			}
+			copy(d.window[d.pos:], data[b.pos:b.pos+n])
+			d.pos += n
+			b.pos += n
+			produced = n
+			// Uncompressed blocks of odd sizes are padded to an even size.
+			if n == d.blockRemaining && d.blockLength%2 == 1 {
+				b.pos++
+			}
+		} else {
+			var err error
+			if produced, err = d.decodeSymbols(b, n, remaining); err != nil {
+				return nil, err
+			}
+		}
+		// Matches may run past the end of a block, which then ends.
+		d.blockRemaining -= produced
+		if d.blockRemaining < 0 {
+			d.blockRemaining = 0
+		}
+		remaining -= produced
+		if b.overrun() {
+			return nil, errCorruptLZX
+		}
+	}
+
+	out := append([]byte(nil), d.window[start:start+size]...)
+	if d.translation != 0 && d.frames < lzxMaxFrames {
+		lzxUndoE8(out, d.offset, d.translation)
+	}
+	d.frames++
+	d.offset += int64(size)
+	if d.pos == len(d.window) {
+		d.pos = 0
+	}
+	return out, nil
+}
+
+func (d *lzxDecoder) readBlockHeader(b *bitReader16) error {
+	d.blockType = int(b.bits(3))
+	switch {
+	case !d.wim:
+		d.blockRemaining = int(b.bits(24))
+	case b.bits(1) == 1:
+		d.blockRemaining = 1 << lzxMinWindowBits
+	default:
+		d.blockRemaining = int(b.bits(16))
+		if len(d.window) >= 1<<16 {
+			d.blockRemaining = d.blockRemaining<<8 | int(b.bits(8))
+		}
+	}
+	if d.blockRemaining == 0 {
+		return errCorruptLZX
+	}
+	d.blockLength = d.blockRemaining
+
+	var err error
+	switch d.blockType {
+	case lzxBlockAligned:
+		lens := make([]uint8, lzxAlignedSize)
+		for i := range lens {
+			lens[i] = uint8(b.bits(3))
+		}
+		if d.aligned, err = newHuffman(lens); err != nil {
+			return err
+		}
+		fallthrough
+	case lzxBlockVerbatim:
+		if err := d.readLengths(b, d.mainLens[:lzxNumChars]); err != nil {
+			return err
+		}
+		if err := d.readLengths(b, d.mainLens[lzxNumChars:]); err != nil {
+			return err
+		}
+		if d.main, err = newHuffman(d.mainLens); err != nil {
+			return err
+		}
+		if err := d.readLengths(b, d.lengthLens); err != nil {
+			return err
+		}
+		d.length, err = newHuffman(d.lengthLens)
+		return err
+	case lzxBlockUncompressed:
+		// The block is aligned to a word, skipping a whole word if it
+		// already is, then has the recent offsets.
+		if b.n%16 == 0 {
+			b.ensure(16)
+			b.consume(16)
+		}
+		b.pos -= 2 * int(b.n/16)
+		b.buf, b.n = 0, 0
+		if b.pos+12 > len(b.data) {
+			return errCorruptLZX
+		}
+		for i := range d.r {
+			d.r[i] = binary.LittleEndian.Uint32(b.data[b.pos:])
+			b.pos += 4
+		}
+		return nil
+	}
+	return errCorruptLZX
+}
+
+// readLengths reads the code lengths of part of a tree, coded as changes to
+// their previous lengths with a pretree.
+func (d *lzxDecoder) readLengths(b *bitReader16, lens []uint8) error {
+	pretreeLens := make([]uint8, lzxPretreeSize)
+	for i := range pretreeLens {
+		pretreeLens[i] = uint8(b.bits(4))
+	}
+	pretree, err := newHuffman(pretreeLens)
+	if err != nil {
+		return err
+	}
+	for x := 0; x < len(lens); {
+		z, err := pretree.decode(b)
+		if err != nil {
+			return err
+		}
+		run, value := 1, uint8(0)
+		switch z {
+		case 17:
+			run = int(b.bits(4)) + 4
+		case 18:
+			run = int(b.bits(5)) + 20
+		case 19:
+			run = int(b.bits(1)) + 4
+			if z, err = pretree.decode(b); err != nil {
+				return err
+			}
+			value = uint8((int(lens[x]) - z + 17) % 17)
+		default:
+			value = uint8((int(lens[x]) - z + 17) % 17)
+		}
+		if x+run > len(lens) || b.overrun() {
+			return errCorruptLZX
+		}
+		for ; run > 0; run-- {
+			lens[x] = value
+			x++
+		}
+	}
+	return nil
+}
+
+// decodeSymbols decodes at least n bytes of a verbatim or aligned block, and
+// at most limit bytes, the rest of the frame. It returns the number of bytes
+// decoded.
+func (d *lzxDecoder) decodeSymbols(b *bitReader16, n, limit int) (int, error) {
+	mask := len(d.window) - 1
+	produced := 0
+	for produced < n {
+		sym, err := d.main.decode(b)
+		if err != nil {
+			return 0, err
+		}
+		if sym < lzxNumChars {
+			d.window[d.pos] = byte(sym)
+			d.pos++
+			produced++
+			continue
+		}
+		sym -= lzxNumChars
+		length := sym & 7
+		if length == 7 {
+			extra, err := d.length.decode(b)
+			if err != nil {
+				return 0, err
+			}
+			length += extra
+		}
+		length += lzxMinMatch
+
+		var offset uint32
+		switch slot := sym >> 3; slot {
+		case 0:
+			offset = d.r[0]
+		case 1:
+			offset = d.r[1]
+			d.r[1] = d.r[0]
+		case 2:
+			offset = d.r[2]
+			d.r[2] = d.r[0]
+		default:
+			extra := lzxExtraBits[slot]
+			offset = lzxPositionBase[slot] - 2
+			if d.blockType == lzxBlockAligned && extra >= 3 {
+				offset += b.bits(extra-3) << 3
+				aligned, err := d.aligned.decode(b)
+				if err != nil {
+					return 0, err
+				}
+				offset += uint32(aligned)
+			} else {
+				offset += b.bits(extra)
+			}
+			d.r[2], d.r[1] = d.r[1], d.r[0]
+		}
+		d.r[0] = offset
+
+		if offset == 0 || int(offset) > len(d.window) || produced+length > limit {
+			return 0, errCorruptLZX
+		}
+		src := (d.pos - int(offset)) & mask
+		for i := 0; i < length; i++ {
+			d.window[d.pos] = d.window[src]
+			d.pos++
+			src = (src + 1) & mask
+		}
+		produced += length
+	}
+	return produced, nil
+}
+
+// lzxUndoE8 reverses the translation of the targets of x86 CALL instructions
+// from relative to absolute of a frame at an offset of the output.
+func lzxUndoE8(data []byte, offset int64, size int32) {
+	for i := 0; i+10 < len(data); i++ {
+		if data[i] != 0xe8 {
+			continue
+		}
+		current := int32(offset + int64(i))
+		abs := int32(binary.LittleEndian.Uint32(data[i+1:]))
+		if abs >= -current && abs < size {
+			rel := abs - current
+			if abs < 0 {
+				rel = abs + size
+			}
+			binary.LittleEndian.PutUint32(data[i+1:], uint32(rel))
+		}
+		i += 4
+	}
+}
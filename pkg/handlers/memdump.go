@@ -0,0 +1,555 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// dumpFormat is the format of a snapshot of the memory of a process, like
+// the crash artifacts of services.
+type dumpFormat string
+
+const (
+	dumpCore     dumpFormat = "core"
+	dumpMinidump dumpFormat = "dmp"
+	dumpHeap     dumpFormat = "hprof"
+)
+
+const (
+	minidumpMagic   = "MDMP"
+	minidumpVersion = 0xa793
+	hprofMagic      = "JAVA PROFILE 1.0."
+	// minPrintableRun is the length of the shortest run of printable
+	// characters of memory that is scanned.
+	minPrintableRun = 6
+	// maxPrintableRun is the length of runs of printable characters that
+	// are written out before they end.
+	maxPrintableRun = 64 * 1024
+)
+
+// The streams of minidumps with the memory of the process.
+const (
+	minidumpMemoryList   = 5
+	minidumpMemory64List = 9
+)
+
+// The records of heap dumps with the objects of the heap, and the
+// sub-records of heap dump records.
+const (
+	hprofHeapDump        = 0x0c
+	hprofHeapDumpSegment = 0x1c
+
+	hprofClassDump      = 0x20
+	hprofInstanceDump   = 0x21
+	hprofObjectArray    = 0x22
+	hprofPrimitiveArray = 0x23
+	hprofCharType       = 5
+	hprofByteType       = 8
+)
+
+// errCorruptDump is returned for memory dumps whose structures cannot be
+// decoded.
+var errCorruptDump = errors.New("corrupt memory dump")
+
+// identifyDump returns the format of an ELF core dump, minidump or Java heap
+// dump from its first bytes. The returned reader must be read instead of
+// reader.
+func identifyDump(reader io.Reader) (dumpFormat, io.Reader, bool) {
+	br := bufio.NewReader(reader)
+	header, _ := br.Peek(20)
+	switch {
+	case bytes.HasPrefix(header, []byte(hprofMagic)):
+		return dumpHeap, br, true
+	case len(header) >= 8 && bytes.HasPrefix(header, []byte(minidumpMagic)) && binary.LittleEndian.Uint16(header[4:]) == minidumpVersion:
+		return dumpMinidump, br, true
+	case len(header) >= 18 && bytes.HasPrefix(header, []byte(elf.ELFMAG)):
+		var order binary.ByteOrder = binary.LittleEndian
+		if elf.Data(header[elf.EI_DATA]) == elf.ELFDATA2MSB {
+			order = binary.BigEndian
+		}
+		if elf.Type(order.Uint16(header[16:])) == elf.ET_CORE {
+			return dumpCore, br, true
+		}
+	}
+	return "", br, false
+}
+
+// extractDump extracts the runs of printable ASCII and UTF-16 characters of
+// the memory regions of a core dump or minidump, named by their address, and
+// the strings and byte arrays of the heap of a heap dump. Core dumps and
+// minidumps are spooled to a temporary file, as they are indexed by their
+// headers, and those that cannot be decoded are scanned as they are.
+func (a *Archive) extractDump(ctx context.Context, depth int, path string, format dumpFormat, reader io.Reader, archiveChan chan Part) error {
+	logger := logContext.AddLogger(ctx).Logger()
+	logger.V(3).Info("Handling memory dump.", "format", format)
+	var regions []column
+	if format == dumpHeap {
+		regions = []column{{name: "heap", values: &lineReader{next: (&hprofReader{r: bufio.NewReader(reader)}).next}}}
+	} else {
+		seekableReader, cleanup, err := seekable(reader)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		r := seekableReader.(readerAtSeeker)
+		size, err := readerSize(r)
+		if err != nil {
+			return err
+		}
+		if format == dumpCore {
+			regions, err = coreRegions(r)
+		} else {
+			regions, err = minidumpRegions(r, size)
+		}
+		if errors.Is(err, errCorruptDump) || errors.Is(err, io.ErrUnexpectedEOF) {
+			logger.V(2).Info("Error decoding memory dump, scanning it as it is.", "format", format, "error", err)
+			return sendParts(ctx, path, io.NewSectionReader(r, 0, size), archiveChan)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, region := range regions {
+		name := memberPath(path, region.name)
+		err := sendParts(ctx, name, a.limitReader(ctx, name, newPrintableReader(region.values)), archiveChan)
+		if errors.Is(err, errCorruptDump) || errors.Is(err, io.ErrUnexpectedEOF) {
+			logger.V(2).Info("Error decoding memory dump region.", "region", region.name, "error", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// coreRegions returns the loaded segments of an ELF core dump, which are the
+// memory mappings of the process, and its notes, which have its command line
+// and the files it mapped.
+func coreRegions(r io.ReaderAt) ([]column, error) {
+	f, err := elf.NewFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptDump, err)
+	}
+	var notes []io.Reader
+	var regions []column
+	for _, prog := range f.Progs {
+		switch {
+		case prog.Filesz == 0:
+		case prog.Type == elf.PT_NOTE:
+			notes = append(notes, prog.Open())
+		case prog.Type == elf.PT_LOAD:
+			regions = append(regions, column{name: fmt.Sprintf("memory/0x%x", prog.Vaddr), values: prog.Open()})
+		}
+	}
+	if len(notes) > 0 {
+		regions = append([]column{{name: "notes", values: io.MultiReader(notes...)}}, regions...)
+	}
+	return regions, nil
+}
+
+// minidumpRegions returns the memory ranges of a minidump, and its other
+// streams, like the modules and threads of the process.
+func minidumpRegions(r io.ReaderAt, size int64) ([]column, error) {
+	header := make([]byte, 32)
+	if err := readAt(r, header, 0); err != nil {
+		return nil, err
+	}
+	count, directory := int64(binary.LittleEndian.Uint32(header[8:])), int64(binary.LittleEndian.Uint32(header[12:]))
+	if directory+count*12 > size {
+		return nil, fmt.Errorf("%w: %d streams", errCorruptDump, count)
+	}
+	entries := make([]byte, count*12)
+	if err := readAt(r, entries, directory); err != nil {
+		return nil, err
+	}
+	var streams []io.Reader
+	var regions []column
+	for i := int64(0); i < count; i++ {
+		entry := entries[i*12:]
+		typ := binary.LittleEndian.Uint32(entry)
+		dataSize, rva := int64(binary.LittleEndian.Uint32(entry[4:])), int64(binary.LittleEndian.Uint32(entry[8:]))
+		if rva+dataSize > size {
+			return nil, fmt.Errorf("%w: stream %d outside of the dump", errCorruptDump, typ)
+		}
+		switch typ {
+		case 0:
+			// Unused streams.
+		case minidumpMemoryList:
+			// The number of ranges is followed by their address, size
+			// and location.
+			data := make([]byte, dataSize)
+			if err := readAt(r, data, rva); err != nil {
+				return nil, err
+			}
+			if len(data) < 4 {
+				return nil, errCorruptDump
+			}
+			n := int64(binary.LittleEndian.Uint32(data))
+			if 4+n*16 > dataSize {
+				return nil, errCorruptDump
+			}
+			for j := int64(0); j < n; j++ {
+				descriptor := data[4+j*16:]
+				start := binary.LittleEndian.Uint64(descriptor)
+				rangeSize, rangeRva := int64(binary.LittleEndian.Uint32(descriptor[8:])), int64(binary.LittleEndian.Uint32(descriptor[12:]))
+				if rangeRva+rangeSize > size {
+					return nil, errCorruptDump
+				}
+				regions = append(regions, column{name: fmt.Sprintf("memory/0x%x", start), values: io.NewSectionReader(r, rangeRva, rangeSize)})
+			}
+		case minidumpMemory64List:
+			// The ranges of full memory dumps follow each other from a
+			// base location, after their address and size.
+			data := make([]byte, dataSize)
+			if err := readAt(r, data, rva); err != nil {
+				return nil, err
+			}
+			if len(data) < 16 {
+				return nil, errCorruptDump
+			}
+			n, base := binary.LittleEndian.Uint64(data), binary.LittleEndian.Uint64(data[8:])
+			if n > uint64(dataSize-16)/16 || base > uint64(size) {
+				return nil, errCorruptDump
+			}
+			offset := int64(base)
+			for j := 0; j < int(n); j++ {
+				descriptor := data[16+j*16:]
+				start, rangeSize := binary.LittleEndian.Uint64(descriptor), binary.LittleEndian.Uint64(descriptor[8:])
+				if rangeSize > uint64(size-offset) {
+					return nil, errCorruptDump
+				}
+				regions = append(regions, column{name: fmt.Sprintf("memory/0x%x", start), values: io.NewSectionReader(r, offset, int64(rangeSize))})
+				offset += int64(rangeSize)
+			}
+		default:
+			streams = append(streams, io.NewSectionReader(r, rva, dataSize))
+		}
+	}
+	if len(streams) > 0 {
+		regions = append([]column{{name: "streams", values: io.MultiReader(streams...)}}, regions...)
+	}
+	return regions, nil
+}
+
+// hprofReader reads the char and byte arrays of the heap dump records of a
+// Java heap dump, which hold the strings of the heap, one sub-record at a
+// time.
+type hprofReader struct {
+	r       *bufio.Reader
+	idSize  int64
+	started bool
+	// remaining is the size of the rest of the heap dump record being
+	// read.
+	remaining int64
+}
+
+func (h *hprofReader) next() ([][]byte, error) {
+	if !h.started {
+		// The header is the format version ending with a NUL byte, the
+		// size of identifiers and a timestamp.
+		h.started = true
+		version, err := h.r.ReadSlice(0)
+		if err != nil || !bytes.HasPrefix(version, []byte(hprofMagic)) {
+			return nil, errCorruptDump
+		}
+		header := make([]byte, 12)
+		if _, err := io.ReadFull(h.r, header); err != nil {
+			return nil, err
+		}
+		if h.idSize = int64(binary.BigEndian.Uint32(header)); h.idSize != 4 && h.idSize != 8 {
+			return nil, fmt.Errorf("%w: identifiers of %d bytes", errCorruptDump, h.idSize)
+		}
+	}
+	for {
+		if h.remaining == 0 {
+			header := make([]byte, 9)
+			if _, err := io.ReadFull(h.r, header); err != nil {
+				return nil, err
+			}
+			length := int64(binary.BigEndian.Uint32(header[5:]))
+			if header[0] == hprofHeapDump || header[0] == hprofHeapDumpSegment {
+				h.remaining = length
+			} else if _, err := h.r.Discard(int(length)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		value, err := h.subRecord()
+		if errors.Is(err, errCorruptDump) {
+			// The rest of the record is skipped, as the size of its
+			// sub-records is unknown.
+			if _, err := h.r.Discard(int(h.remaining)); err != nil {
+				return nil, err
+			}
+			h.remaining = 0
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			return [][]byte{value}, nil
+		}
+	}
+}
+
+// skip skips n bytes of the heap dump record.
+func (h *hprofReader) skip(n int64) error {
+	if n > h.remaining {
+		return errCorruptDump
+	}
+	h.remaining -= n
+	_, err := h.r.Discard(int(n))
+	return err
+}
+
+// read reads n bytes of the heap dump record.
+func (h *hprofReader) read(n int64) ([]byte, error) {
+	if n > h.remaining {
+		return nil, errCorruptDump
+	}
+	h.remaining -= n
+	b := make([]byte, n)
+	_, err := io.ReadFull(h.r, b)
+	return b, err
+}
+
+func (h *hprofReader) uint(n int64) (uint64, error) {
+	b, err := h.read(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// typeSize returns the size of the values of a basic type.
+func (h *hprofReader) typeSize(typ byte) (int64, error) {
+	switch typ {
+	case 2:
+		// Objects.
+		return h.idSize, nil
+	case 4, 8:
+		return 1, nil
+	case 5, 9:
+		return 2, nil
+	case 6, 10:
+		return 4, nil
+	case 7, 11:
+		return 8, nil
+	}
+	return 0, fmt.Errorf("%w: basic type %d", errCorruptDump, typ)
+}
+
+// subRecord reads a sub-record of a heap dump record, and returns the content
+// of char and byte arrays, with char arrays decoded from UTF-16.
+func (h *hprofReader) subRecord() ([]byte, error) {
+	tag, err := h.uint(1)
+	if err != nil {
+		return nil, err
+	}
+	id := h.idSize
+	switch tag {
+	case 0xff, 0x05, 0x07, 0x89, 0x8a, 0x8b, 0x8c, 0x8d, 0x90:
+		// Roots, and the roots of Android heap dumps.
+		return nil, h.skip(id)
+	case 0x01:
+		return nil, h.skip(2 * id)
+	case 0x04, 0x06, 0xfe:
+		return nil, h.skip(id + 4)
+	case 0x02, 0x03, 0x08, 0x8e:
+		return nil, h.skip(id + 8)
+	case 0xc3:
+		// Primitive arrays without their data.
+		return nil, h.skip(id + 9)
+	case hprofClassDump:
+		return nil, h.classDump()
+	case hprofInstanceDump:
+		if err := h.skip(2*id + 4); err != nil {
+			return nil, err
+		}
+		n, err := h.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return nil, h.skip(int64(n))
+	case hprofObjectArray:
+		if err := h.skip(id + 4); err != nil {
+			return nil, err
+		}
+		n, err := h.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return nil, h.skip(id + int64(n)*id)
+	case hprofPrimitiveArray:
+		if err := h.skip(id + 4); err != nil {
+			return nil, err
+		}
+		n, err := h.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		typ, err := h.uint(1)
+		if err != nil {
+			return nil, err
+		}
+		size, err := h.typeSize(byte(typ))
+		if err != nil {
+			return nil, err
+		}
+		length := int64(n) * size
+		if (typ != hprofCharType && typ != hprofByteType) || length > maxMetadataSize {
+			return nil, h.skip(length)
+		}
+		data, err := h.read(length)
+		if err != nil || typ == hprofByteType {
+			return data, err
+		}
+		chars := make([]uint16, n)
+		for i := range chars {
+			chars[i] = binary.BigEndian.Uint16(data[2*i:])
+		}
+		return []byte(string(utf16.Decode(chars))), nil
+	}
+	return nil, fmt.Errorf("%w: heap dump sub-record %#x", errCorruptDump, tag)
+}
+
+// classDump skips a class dump: its class, superclass, loaders and size,
+// followed by its constant pool, static fields and instance fields.
+func (h *hprofReader) classDump() error {
+	id := h.idSize
+	if err := h.skip(7*id + 8); err != nil {
+		return err
+	}
+	// Constants have an index, static fields a name, and both a value of
+	// their type.
+	for _, prefix := range []int64{2, id} {
+		n, err := h.uint(2)
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			if err := h.skip(prefix); err != nil {
+				return err
+			}
+			typ, err := h.uint(1)
+			if err != nil {
+				return err
+			}
+			size, err := h.typeSize(byte(typ))
+			if err != nil {
+				return err
+			}
+			if err := h.skip(size); err != nil {
+				return err
+			}
+		}
+	}
+	n, err := h.uint(2)
+	if err != nil {
+		return err
+	}
+	return h.skip(int64(n) * (id + 1))
+}
+
+// printableRun is a run of printable characters being read.
+type printableRun struct {
+	b []byte
+	// long is whether the start of the run was written out already.
+	long bool
+}
+
+// printableReader reads the runs of printable ASCII characters, or of
+// UTF-16LE characters that are printable ASCII, of at least minPrintableRun
+// characters of a reader, one per line, like strings.
+type printableReader struct {
+	r     io.Reader
+	buf   []byte
+	out   []byte
+	err   error
+	ascii printableRun
+	// wide are the runs of UTF-16 characters starting at even and odd
+	// offsets.
+	wide    [2]printableRun
+	offset  int64
+	prev    byte
+	started bool
+}
+
+func newPrintableReader(r io.Reader) io.Reader {
+	return &printableReader{r: r, buf: make([]byte, 32*1024)}
+}
+
+func isPrintable(c byte) bool {
+	return c == '\t' || (c >= 0x20 && c < 0x7f)
+}
+
+func (p *printableReader) Read(b []byte) (int, error) {
+	for len(p.out) == 0 {
+		if p.err != nil {
+			return 0, p.err
+		}
+		var n int
+		n, p.err = p.r.Read(p.buf)
+		for _, c := range p.buf[:n] {
+			p.add(c)
+		}
+		if p.err != nil {
+			p.end(&p.ascii)
+			p.end(&p.wide[0])
+			p.end(&p.wide[1])
+		}
+	}
+	n := copy(b, p.out)
+	p.out = p.out[n:]
+	return n, nil
+}
+
+func (p *printableReader) add(c byte) {
+	if isPrintable(c) {
+		p.extend(&p.ascii, c)
+	} else {
+		p.end(&p.ascii)
+	}
+	// Each byte ends a character of the UTF-16 runs of the parity of the
+	// previous byte.
+	if p.started {
+		run := &p.wide[(p.offset-1)&1]
+		if isPrintable(p.prev) && c == 0 {
+			p.extend(run, p.prev)
+		} else {
+			p.end(run)
+		}
+	}
+	p.prev, p.started = c, true
+	p.offset++
+}
+
+func (p *printableReader) extend(run *printableRun, c byte) {
+	run.b = append(run.b, c)
+	if len(run.b) >= maxPrintableRun {
+		p.out = append(p.out, run.b...)
+		run.b, run.long = run.b[:0], true
+	}
+}
+
+func (p *printableReader) end(run *printableRun) {
+	if run.long || len(run.b) >= minPrintableRun {
+		p.out = append(append(p.out, run.b...), '\n')
+	}
+	run.b, run.long = run.b[:0], false
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zip"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// mobilePackageKind is the kind of package of a mobile app.
+type mobilePackageKind string
+
+const (
+	mobilePackageAPK mobilePackageKind = "apk"
+	mobilePackageIPA mobilePackageKind = "ipa"
+)
+
+// resXMLMagic starts Android binary XML files.
+var resXMLMagic = []byte{0x03, 0x00, 0x08, 0x00}
+
+// mobilePackageOf returns the kind of mobile app package of a zip archive,
+// if it is one: an Android APK, with a manifest and code or resources, or an
+// iOS IPA, with an app in its Payload directory.
+func mobilePackageOf(zr *zip.Reader) (mobilePackageKind, bool) {
+	hasManifest, hasAndroidContent := false, false
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "AndroidManifest.xml":
+			hasManifest = true
+		case f.Name == "resources.arsc" || f.Name == "classes.dex":
+			hasAndroidContent = true
+		case strings.HasPrefix(f.Name, "Payload/") && path.Base(f.Name) == "Info.plist" && strings.HasSuffix(path.Dir(f.Name), ".app"):
+			return mobilePackageIPA, true
+		}
+	}
+	if hasManifest && hasAndroidContent {
+		return mobilePackageAPK, true
+	}
+	return "", false
+}
+
+// mobileCandidate reports whether a file of a package may be in one of the
+// binary formats decoded: the binary XML files, resource table and DEX files
+// of APKs, and the binary property lists and Mach-O binaries of IPAs.
+func mobileCandidate(kind mobilePackageKind, name string) bool {
+	ext := path.Ext(name)
+	if kind == mobilePackageAPK {
+		return ext == ".xml" || ext == ".dex" || name == "resources.arsc"
+	}
+	// Executables of apps and frameworks have no extension.
+	return ext == ".plist" || ext == ".dylib" || (ext == "" && strings.HasPrefix(name, "Payload/"))
+}
+
+// decodeMobileFile returns the text of a file of a mobile app package in one
+// of the binary formats decoded, or false if it is not in one.
+func decodeMobileFile(kind mobilePackageKind, name string, data []byte) ([]byte, bool) {
+	var text []byte
+	var err error
+	switch {
+	case kind == mobilePackageAPK && bytes.HasPrefix(data, resXMLMagic):
+		text, err = decodeAXML(data)
+	case kind == mobilePackageAPK && name == "resources.arsc":
+		text, err = decodeARSC(data)
+	case kind == mobilePackageAPK && bytes.HasPrefix(data, []byte(dexMagic)):
+		text, err = dexStrings(data)
+	case kind == mobilePackageIPA && bytes.HasPrefix(data, []byte(bplistMagic)):
+		text, err = decodeBinaryPlist(data)
+	case kind == mobilePackageIPA && isMachO(data):
+		text, err = machoStrings(data)
+	default:
+		return nil, false
+	}
+	return text, err == nil
+}
+
+// isMachO reports whether data starts with the magic number of a Mach-O
+// binary, or of a universal binary. Universal binaries share their magic
+// number with Java classes, whose version is larger than any number of
+// architectures.
+func isMachO(data []byte) bool {
+	if len(data) < 8 {
+		return false
+	}
+	switch binary.LittleEndian.Uint32(data) {
+	case 0xfeedface, 0xfeedfacf:
+		return true
+	}
+	return binary.BigEndian.Uint32(data) == 0xcafebabe && binary.BigEndian.Uint32(data[4:]) < 32
+}
+
+// extractMobilePackage extracts the files of an APK or IPA package, scanning
+// the text decoded from its binary resources and code rather than their raw
+// bytes: the manifest and other binary XML files and the resource table of
+// APKs, the strings of their DEX files, and the binary property lists and
+// the strings of the executables of IPAs. Files that cannot be decoded are
+// scanned as they are.
+func (a *Archive) extractMobilePackage(ctx context.Context, zr *zip.Reader, kind mobilePackageKind, archiveChan chan Part) error {
+	logger := logContext.AddLogger(ctx).Logger()
+	logger.V(3).Info("Handling mobile app package.", "kind", kind)
+	return a.extractDecodedZip(ctx, zr, func(name string) bool {
+		return mobileCandidate(kind, name)
+	}, func(name string, data []byte) ([]byte, bool) {
+		return decodeMobileFile(kind, name, data)
+	}, archiveChan)
+}
+
+// extractDecodedZip extracts the files of a zip archive, scanning the text
+// decode returns for the files candidate selects instead of their bytes, if
+// it can decode them.
+func (a *Archive) extractDecodedZip(
+	ctx context.Context,
+	zr *zip.Reader,
+	candidate func(name string) bool,
+	decode func(name string, data []byte) ([]byte, bool),
+	archiveChan chan Part,
+) error {
+	depth, _ := ctx.Value(depthKey).(int)
+	parent, _ := ctx.Value(pathKey).(string)
+	handleFile := a.extractorHandler(archiveChan)
+	return a.forEachEntry(ctx, len(zr.File), func(ctx context.Context, i int) error {
+		f := zr.File[i]
+		if f.FileInfo().IsDir() || f.Flags&0x1 != 0 || !candidate(f.Name) {
+			if err := handleFile(ctx, a.zipArchiverFile(f)); err != nil {
+				return fmt.Errorf("handling file %d: %s: %w", i, f.Name, err)
+			}
+			return nil
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		name := memberPath(parent, f.Name)
+		data, err := io.ReadAll(a.limitReader(ctx, name, a.ratioLimitZipFile(ctx, name, f.FileHeader, rc)))
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		if text, ok := decode(f.Name, data); ok {
+			data = text
+		}
+		err = a.openArchive(ctx, depth, name, bytes.NewReader(data), archiveChan)
+		if errors.Is(err, errMaxArchiveDepthReached) {
+			a.reportSkip(ctx, name, sources.SkipReasonDepth, int64(f.UncompressedSize64))
+		}
+		if err != nil {
+			return fmt.Errorf("handling file %d: %s: %w", i, f.Name, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeResChunk returns a chunk of an Android binary resource, with the
+// fields of its header after its type and sizes.
+func makeResChunk(kind uint16, header, body []byte) []byte {
+	c := binary.LittleEndian.AppendUint16(nil, kind)
+	c = binary.LittleEndian.AppendUint16(c, uint16(8+len(header)))
+	c = binary.LittleEndian.AppendUint32(c, uint32(8+len(header)+len(body)))
+	return append(append(c, header...), body...)
+}
+
+// makeStringPool returns a string pool, in UTF-8 or UTF-16.
+func makeStringPool(utf8 bool, strs ...string) []byte {
+	var offsets, data []byte
+	for _, s := range strs {
+		offsets = binary.LittleEndian.AppendUint32(offsets, uint32(len(data)))
+		if utf8 {
+			data = append(data, byte(len(s)), byte(len(s)))
+			data = append(append(data, s...), 0)
+			continue
+		}
+		units := utf16.Encode([]rune(s))
+		data = binary.LittleEndian.AppendUint16(data, uint16(len(units)))
+		for _, u := range units {
+			data = binary.LittleEndian.AppendUint16(data, u)
+		}
+		data = append(data, 0, 0)
+	}
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+	var flags uint32
+	if utf8 {
+		flags = resStringPoolUTF8
+	}
+	header := binary.LittleEndian.AppendUint32(nil, uint32(len(strs)))
+	header = binary.LittleEndian.AppendUint32(header, 0)
+	header = binary.LittleEndian.AppendUint32(header, flags)
+	header = binary.LittleEndian.AppendUint32(header, uint32(28+len(offsets)))
+	header = binary.LittleEndian.AppendUint32(header, 0)
+	return makeResChunk(resStringPoolType, header, append(offsets, data...))
+}
+
+// makeAXML returns a binary AndroidManifest.xml with a meta-data element.
+func makeAXML(apiKey string) []byte {
+	const (
+		android = iota
+		uri
+		manifest
+		pkg
+		pkgName
+		metaData
+		name
+		value
+		keyName
+		key
+	)
+	pool := makeStringPool(false, "android", "http://schemas.android.com/apk/res/android", "manifest", "package",
+		"com.example.app", "meta-data", "name", "value", "com.google.android.geo.API_KEY", apiKey)
+	node := make([]byte, 8) // line number and comment
+	u32 := func(values ...uint32) []byte {
+		var b []byte
+		for _, v := range values {
+			b = binary.LittleEndian.AppendUint32(b, v)
+		}
+		return b
+	}
+	attr := func(ns, name, raw uint32) []byte {
+		a := u32(ns, name, raw)
+		a = append(a, 8, 0, 0, resValueString)
+		return append(a, u32(raw)...)
+	}
+	element := func(name uint32, attrs ...[]byte) []byte {
+		body := u32(resNoEntry, name)
+		body = binary.LittleEndian.AppendUint16(body, 20)
+		body = binary.LittleEndian.AppendUint16(body, 20)
+		body = binary.LittleEndian.AppendUint16(body, uint16(len(attrs)))
+		body = append(body, make([]byte, 6)...)
+		for _, a := range attrs {
+			body = append(body, a...)
+		}
+		return makeResChunk(resXMLStartElement, node, body)
+	}
+	end := func(name uint32) []byte { return makeResChunk(resXMLEndElement, node, u32(resNoEntry, name)) }
+
+	var body []byte
+	body = append(body, pool...)
+	body = append(body, makeResChunk(resXMLStartNS, node, u32(android, uri))...)
+	body = append(body, element(manifest, attr(resNoEntry, pkg, pkgName))...)
+	body = append(body, element(metaData, attr(uri, name, keyName), attr(uri, value, key))...)
+	body = append(body, end(metaData)...)
+	body = append(body, end(manifest)...)
+	return makeResChunk(resXMLType, nil, body)
+}
+
+// makeARSC returns a resource table with string resources.
+func makeARSC(values ...string) []byte {
+	var keys []string
+	var offsets, entries []byte
+	for i := range values {
+		keys = append(keys, "key_"+string(rune('a'+i)))
+		offsets = binary.LittleEndian.AppendUint32(offsets, uint32(len(entries)))
+		entries = binary.LittleEndian.AppendUint16(entries, 8)
+		entries = binary.LittleEndian.AppendUint16(entries, 0)
+		entries = binary.LittleEndian.AppendUint32(entries, uint32(i))
+		entries = append(entries, 8, 0, 0, resValueString)
+		entries = binary.LittleEndian.AppendUint32(entries, uint32(i))
+	}
+	// The type chunk has an id, flags, an entry count, the offset of its
+	// entries and an empty configuration.
+	typeHeader := []byte{1, 0, 0, 0}
+	typeHeader = binary.LittleEndian.AppendUint32(typeHeader, uint32(len(values)))
+	config := binary.LittleEndian.AppendUint32(nil, 28)
+	config = append(config, make([]byte, 24)...)
+	typeHeader = binary.LittleEndian.AppendUint32(typeHeader, uint32(20+len(config)+len(offsets)))
+	typeHeader = append(typeHeader, config...)
+	typeChunk := makeResChunk(resTableTypeType, typeHeader, append(offsets, entries...))
+
+	typePool := makeStringPool(true, "string")
+	keyPool := makeStringPool(true, keys...)
+	pkgHeader := binary.LittleEndian.AppendUint32(nil, 0x7f)
+	pkgHeader = append(pkgHeader, make([]byte, 256)...)
+	pkgHeader = binary.LittleEndian.AppendUint32(pkgHeader, 288)
+	pkgHeader = binary.LittleEndian.AppendUint32(pkgHeader, 0)
+	pkgHeader = binary.LittleEndian.AppendUint32(pkgHeader, uint32(288+len(typePool)))
+	pkgHeader = binary.LittleEndian.AppendUint32(pkgHeader, 0)
+	pkgHeader = binary.LittleEndian.AppendUint32(pkgHeader, 0)
+	pkg := makeResChunk(resTablePackageType, pkgHeader, append(append(typePool, keyPool...), typeChunk...))
+
+	body := append(makeStringPool(false, values...), pkg...)
+	return makeResChunk(resTableType, binary.LittleEndian.AppendUint32(nil, 1), body)
+}
+
+// makeDEX returns a DEX file with strings.
+func makeDEX(strs ...string) []byte {
+	dex := make([]byte, dexHeaderSize)
+	copy(dex, "dex\n035\x00")
+	binary.LittleEndian.PutUint32(dex[56:], uint32(len(strs)))
+	binary.LittleEndian.PutUint32(dex[60:], dexHeaderSize)
+	dex = append(dex, make([]byte, 4*len(strs))...)
+	for i, s := range strs {
+		binary.LittleEndian.PutUint32(dex[dexHeaderSize+4*i:], uint32(len(dex)))
+		dex = append(dex, byte(len(s)))
+		dex = append(append(dex, s...), 0)
+	}
+	return dex
+}
+
+func TestDecodeAndroidResources(t *testing.T) {
+	text, err := decodeAXML(makeAXML("AIzaSyA-test"))
+	require.NoError(t, err)
+	assert.Equal(t, `<manifest package="com.example.app">
+  <meta-data android:name="com.google.android.geo.API_KEY" android:value="AIzaSyA-test">
+  </meta-data>
+</manifest>
+`, string(text))
+
+	text, err = decodeARSC(makeARSC("app", "sk_live_héllo"))
+	require.NoError(t, err)
+	assert.Equal(t, "string/key_a = app\nstring/key_b = sk_live_héllo\n", string(text))
+
+	text, err = dexStrings(makeDEX("Lcom/example/Api;", "ghp_secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "Lcom/example/Api;\nghp_secret\n", string(text))
+
+	_, err = decodeAXML(makeAXML("x")[:40])
+	assert.Error(t, err)
+}
+
+// makeBinaryPlist returns a binary property list of a dictionary of a string
+// and an array of a string and an integer.
+func makeBinaryPlist() []byte {
+	data := []byte(bplistMagic)
+	var offsets []byte
+	object := func(b ...byte) {
+		offsets = append(offsets, byte(len(data)))
+		data = append(data, b...)
+	}
+	ascii := func(s string) []byte { return append([]byte{0x50 | byte(len(s))}, s...) }
+	object(0xd2, 1, 2, 3, 4)    // 0: {1: 3, 2: 4}
+	object(ascii("API_KEY")...) // 1
+	object(ascii("Schemes")...) // 2
+	utf16 := []byte{0x62, 0, 's', 0, 'k'}
+	object(utf16...)          // 3: "sk"
+	object(0xa2, 5, 6)        // 4: [5, 6]
+	object(ascii("fb123")...) // 5
+	object(0x10, 42)          // 6
+	tableOffset := len(data)
+	data = append(data, offsets...)
+	trailer := make([]byte, bplistTrailerSize)
+	trailer[6], trailer[7] = 1, 1
+	binary.BigEndian.PutUint64(trailer[8:], uint64(len(offsets)))
+	binary.BigEndian.PutUint64(trailer[24:], uint64(tableOffset))
+	return append(data, trailer...)
+}
+
+// makeMachO returns a 64 bit Mach-O binary with C strings.
+func makeMachO(strs ...string) []byte {
+	var cstrings []byte
+	for _, s := range strs {
+		cstrings = append(append(cstrings, s...), 0)
+	}
+	const headerSize, segmentSize, sectionSize = 32, 72, 80
+	offset := headerSize + segmentSize + sectionSize
+	u32 := binary.LittleEndian.AppendUint32
+	u64 := binary.LittleEndian.AppendUint64
+	name := func(b []byte, s string) []byte { return append(b, append([]byte(s), make([]byte, 16-len(s))...)...) }
+
+	b := u32(nil, 0xfeedfacf)
+	b = u32(b, 0x0100000c) // arm64
+	b = u32(b, 0)
+	b = u32(b, 2) // executable
+	b = u32(b, 1)
+	b = u32(b, segmentSize+sectionSize)
+	b = u32(b, 0)
+	b = u32(b, 0)
+	b = u32(b, 0x19) // LC_SEGMENT_64
+	b = u32(b, segmentSize+sectionSize)
+	b = name(b, "__TEXT")
+	b = u64(b, 0)
+	b = u64(b, uint64(offset+len(cstrings)))
+	b = u64(b, 0)
+	b = u64(b, uint64(offset+len(cstrings)))
+	b = u32(b, 5)
+	b = u32(b, 5)
+	b = u32(b, 1)
+	b = u32(b, 0)
+	b = name(b, "__cstring")
+	b = name(b, "__TEXT")
+	b = u64(b, uint64(offset))
+	b = u64(b, uint64(len(cstrings)))
+	b = u32(b, uint32(offset))
+	b = append(b, make([]byte, 28)...)
+	return append(b, cstrings...)
+}
+
+func TestHandleFile_APK(t *testing.T) {
+	apk := makeZip(t, map[string][]byte{
+		"AndroidManifest.xml":  makeAXML("AIzaSyA-test"),
+		"resources.arsc":       makeARSC("app", "sk_live_test"),
+		"classes.dex":          makeDEX("ghp_secret"),
+		"assets/config.json":   []byte(`{"key": "value"}`),
+		"res/layout/main.xml":  []byte("<plain>not binary</plain>"),
+		"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\n"),
+	})
+	files := handleDiskImage(t, apk)
+	assert.Contains(t, files["AndroidManifest.xml"], `android:value="AIzaSyA-test"`)
+	assert.Equal(t, "string/key_a = app\nstring/key_b = sk_live_test\n", files["resources.arsc"])
+	assert.Equal(t, "ghp_secret\n", files["classes.dex"])
+	assert.Equal(t, `{"key": "value"}`, files["assets/config.json"])
+	assert.Equal(t, "<plain>not binary</plain>", files["res/layout/main.xml"])
+}
+
+func TestHandleFile_IPA(t *testing.T) {
+	ipa := makeZip(t, map[string][]byte{
+		"Payload/App.app/Info.plist":                makeBinaryPlist(),
+		"Payload/App.app/App":                       makeMachO("https://api.example.com", "sk_live_test"),
+		"Payload/App.app/GoogleService-Info.plist":  []byte("<plist><dict><key>API_KEY</key></dict></plist>"),
+		"Payload/App.app/Frameworks/Lib.framework/": nil,
+	})
+	files := handleDiskImage(t, ipa)
+	assert.Equal(t, "API_KEY = sk\nSchemes[0] = fb123\nSchemes[1] = 42\n", files["Payload/App.app/Info.plist"])
+	assert.Equal(t, "https://api.example.com\nsk_live_test\n", files["Payload/App.app/App"])
+	assert.Equal(t, "<plist><dict><key>API_KEY</key></dict></plist>", files["Payload/App.app/GoogleService-Info.plist"])
+}
@@ -0,0 +1,748 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/klauspost/compress/zip"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	officeContentTypes = "[Content_Types].xml"
+	officeRootRels     = "_rels/.rels"
+	// officeOle10Native is the stream of OLE objects packaging an embedded
+	// file, like a script or a text file dropped in a document.
+	officeOle10Native = "\x01Ole10Native"
+)
+
+// The relationship types of the parts of Office documents read as text, by
+// the end of their URI.
+const (
+	officeRelDocument  = "/officeDocument"
+	officeRelComments  = "/comments"
+	officeRelHeader    = "/header"
+	officeRelFooter    = "/footer"
+	officeRelFootnotes = "/footnotes"
+	officeRelEndnotes  = "/endnotes"
+	officeRelNotes     = "/notesSlide"
+	officeRelSlide     = "/slide"
+	officeRelStrings   = "/sharedStrings"
+)
+
+var errCorruptOffice = errors.New("corrupt Office document")
+
+// officeRel is a relationship of a part of an Office document to another
+// part, or to an external target like a link.
+type officeRel struct {
+	ID         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr"`
+}
+
+// officeDocument is a docx, xlsx or pptx file: a zip archive of XML parts
+// linked by relationships.
+type officeDocument struct {
+	zr    *zip.Reader
+	parts map[string]*zip.File
+	// main is the name of the main part: word/document.xml,
+	// xl/workbook.xml or ppt/presentation.xml in documents made by Office.
+	main string
+}
+
+// openOfficeDocument returns the Office document of a zip archive, if it is
+// one.
+func openOfficeDocument(zr *zip.Reader) (*officeDocument, bool) {
+	doc := &officeDocument{zr: zr, parts: make(map[string]*zip.File, len(zr.File))}
+	for _, f := range zr.File {
+		doc.parts[f.Name] = f
+	}
+	if doc.parts[officeContentTypes] == nil {
+		return nil, false
+	}
+	rels, err := doc.rels("")
+	if err != nil {
+		return nil, false
+	}
+	for _, rel := range rels {
+		if strings.HasSuffix(rel.Type, officeRelDocument) && doc.parts[doc.target("", rel)] != nil {
+			doc.main = doc.target("", rel)
+			return doc, true
+		}
+	}
+	return nil, false
+}
+
+// readOfficePart reads a part, which counts towards the maximum size of
+// extracted files and is limited by the maximum compression ratio.
+func (a *Archive) readOfficePart(ctx context.Context, doc *officeDocument, name string) ([]byte, error) {
+	f := doc.parts[name]
+	if f == nil {
+		return nil, errCorruptOffice
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(a.limitReader(ctx, name, a.ratioLimitZipFile(ctx, name, f.FileHeader, rc)))
+}
+
+// rels returns the relationships of a part, or of the document for "".
+func (doc *officeDocument) rels(part string) ([]officeRel, error) {
+	return parseOfficeRels(doc.parts[relsName(part)])
+}
+
+// parseOfficeRels parses a part of relationships, which may be missing.
+func parseOfficeRels(f *zip.File) ([]officeRel, error) {
+	if f == nil {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var rels struct {
+		Relationships []officeRel `xml:"Relationship"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(rc, maxMetadataSize)).Decode(&rels); err != nil {
+		return nil, err
+	}
+	return rels.Relationships, nil
+}
+
+// target returns the name of the part a relationship of a part points to.
+// Targets are relative to the directory of the part, or absolute.
+func (doc *officeDocument) target(part string, rel officeRel) string {
+	if strings.HasPrefix(rel.Target, "/") {
+		return strings.TrimPrefix(rel.Target, "/")
+	}
+	return path.Join(path.Dir(part), rel.Target)
+}
+
+// relsName returns the name of the part of the relationships of a part.
+func relsName(part string) string {
+	if part == "" {
+		return officeRootRels
+	}
+	return path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+}
+
+// extractOffice scans the text of an Office document, labelled by the page,
+// sheet or slide it is on, rather than its XML parts: the text of the body,
+// headers, footers, notes and comments, the external links, the source of
+// macros, and embedded objects. Parts the document is not read from, like
+// custom XML, are scanned as files of the archive.
+func (a *Archive) extractOffice(ctx context.Context, doc *officeDocument, archiveChan chan Part) error {
+	logger := logContext.AddLogger(ctx).Logger()
+	parent, _ := ctx.Value(pathKey).(string)
+	// read is set with the parts whose content has been scanned.
+	read := map[string]bool{officeContentTypes: true}
+	send := func(label string, text string) error {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		return sendParts(ctx, memberPath(parent, label), strings.NewReader(text), archiveChan)
+	}
+
+	mainData, err := a.readOfficePart(ctx, doc, doc.main)
+	if err != nil {
+		return err
+	}
+	var sections []officeSection
+	switch officeRootElement(mainData) {
+	case "document":
+		sections, err = a.wordSections(ctx, doc, mainData)
+	case "workbook":
+		sections, err = a.excelSections(ctx, doc, mainData)
+	case "presentation":
+		sections, err = a.powerPointSections(ctx, doc, mainData)
+	}
+	if err != nil {
+		// Documents that cannot be read as text are scanned as archives.
+		logger.V(3).Info("Error reading Office document text, scanning its parts.", "error", err)
+		sections = nil
+	}
+	for _, section := range sections {
+		for _, part := range section.parts {
+			read[part] = true
+		}
+		if err := send(section.label, section.text); err != nil {
+			return err
+		}
+	}
+
+	var links strings.Builder
+	for _, f := range doc.zr.File {
+		if !strings.HasSuffix(f.Name, ".rels") {
+			continue
+		}
+		rels, err := parseOfficeRels(f)
+		if err != nil {
+			continue
+		}
+		read[f.Name] = true
+		for _, rel := range rels {
+			if rel.TargetMode == "External" {
+				links.WriteString(rel.Target + "\n")
+			}
+		}
+	}
+	if err := send("links", links.String()); err != nil {
+		return err
+	}
+
+	handleFile := a.extractorHandler(archiveChan)
+	for i, f := range doc.zr.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if read[f.Name] || f.FileInfo().IsDir() {
+			continue
+		}
+		var handled bool
+		var err error
+		switch {
+		case path.Base(f.Name) == "vbaProject.bin":
+			handled, err = a.extractMacros(ctx, doc, f.Name, archiveChan)
+		case path.Base(path.Dir(f.Name)) == "embeddings":
+			handled, err = a.extractOLEObject(ctx, doc, f.Name, archiveChan)
+		}
+		if err != nil {
+			return err
+		}
+		if handled {
+			continue
+		}
+		if err := handleFile(ctx, a.zipArchiverFile(f)); err != nil {
+			return fmt.Errorf("handling file %d: %s: %w", i, f.Name, err)
+		}
+	}
+	return nil
+}
+
+// officeSection is text of an Office document, with the label it is scanned
+// with and the parts it was read from.
+type officeSection struct {
+	label string
+	text  string
+	parts []string
+}
+
+// wordSections returns the pages of a Word document, then the text of its
+// headers, footers, footnotes, endnotes and comments. Pages end at page
+// breaks, and at the breaks last rendered by Word.
+func (a *Archive) wordSections(ctx context.Context, doc *officeDocument, data []byte) ([]officeSection, error) {
+	pages, err := officeText(data)
+	if err != nil {
+		return nil, err
+	}
+	var sections []officeSection
+	for i, page := range pages {
+		sections = append(sections, officeSection{label: "page " + strconv.Itoa(i+1), text: page})
+	}
+	sections[0].parts = []string{doc.main}
+
+	rels, err := doc.rels(doc.main)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range rels {
+		var label string
+		target := doc.target(doc.main, rel)
+		switch {
+		case strings.HasSuffix(rel.Type, officeRelHeader), strings.HasSuffix(rel.Type, officeRelFooter):
+			// Headers and footers are labelled by their part, e.g.
+			// header1, as sections have their own.
+			label = strings.TrimSuffix(path.Base(target), ".xml")
+		case strings.HasSuffix(rel.Type, officeRelFootnotes):
+			label = "footnotes"
+		case strings.HasSuffix(rel.Type, officeRelEndnotes):
+			label = "endnotes"
+		case strings.HasSuffix(rel.Type, officeRelComments):
+			label = "comments"
+		default:
+			continue
+		}
+		section, err := a.textSection(ctx, doc, label, target)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// excelSections returns the cells of the sheets of an Excel workbook, one
+// line per row, so lines are row numbers, and their comments.
+func (a *Archive) excelSections(ctx context.Context, doc *officeDocument, data []byte) ([]officeSection, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(data, &workbook); err != nil {
+		return nil, err
+	}
+	rels, err := doc.rels(doc.main)
+	if err != nil {
+		return nil, err
+	}
+	sections := []officeSection{{parts: []string{doc.main}}}
+
+	var sharedStrings []string
+	targets := make(map[string]string)
+	for _, rel := range rels {
+		target := doc.target(doc.main, rel)
+		targets[rel.ID] = target
+		if strings.HasSuffix(rel.Type, officeRelStrings) {
+			stringsData, err := a.readOfficePart(ctx, doc, target)
+			if err != nil {
+				return nil, err
+			}
+			if sharedStrings, err = excelSharedStrings(stringsData); err != nil {
+				return nil, err
+			}
+			sections[0].parts = append(sections[0].parts, target)
+		}
+	}
+
+	for _, sheet := range workbook.Sheets {
+		target, ok := targets[sheet.ID]
+		if !ok || doc.parts[target] == nil {
+			continue
+		}
+		sheetData, err := a.readOfficePart(ctx, doc, target)
+		if err != nil {
+			return nil, err
+		}
+		text, err := excelSheetText(sheetData, sharedStrings)
+		if err != nil {
+			return nil, err
+		}
+		label := "sheet " + sheet.Name
+		sections = append(sections, officeSection{label: label, text: text, parts: []string{target}})
+
+		sheetRels, err := doc.rels(target)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range sheetRels {
+			if !strings.HasSuffix(rel.Type, officeRelComments) {
+				continue
+			}
+			section, err := a.textSection(ctx, doc, label+" comments", doc.target(target, rel))
+			if err != nil {
+				return nil, err
+			}
+			sections = append(sections, section)
+		}
+	}
+	return sections, nil
+}
+
+// powerPointSections returns the text of the slides of a presentation, in
+// their order, and of their notes and comments.
+func (a *Archive) powerPointSections(ctx context.Context, doc *officeDocument, data []byte) ([]officeSection, error) {
+	var presentation struct {
+		Slides []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sldIdLst>sldId"`
+	}
+	if err := xml.Unmarshal(data, &presentation); err != nil {
+		return nil, err
+	}
+	rels, err := doc.rels(doc.main)
+	if err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string)
+	for _, rel := range rels {
+		if strings.HasSuffix(rel.Type, officeRelSlide) {
+			targets[rel.ID] = doc.target(doc.main, rel)
+		}
+	}
+
+	sections := []officeSection{{parts: []string{doc.main}}}
+	for i, slide := range presentation.Slides {
+		target, ok := targets[slide.ID]
+		if !ok || doc.parts[target] == nil {
+			continue
+		}
+		label := "slide " + strconv.Itoa(i+1)
+		section, err := a.textSection(ctx, doc, label, target)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, section)
+
+		slideRels, err := doc.rels(target)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range slideRels {
+			var suffix string
+			switch {
+			case strings.HasSuffix(rel.Type, officeRelNotes):
+				suffix = " notes"
+			case strings.HasSuffix(rel.Type, officeRelComments):
+				suffix = " comments"
+			default:
+				continue
+			}
+			section, err := a.textSection(ctx, doc, label+suffix, doc.target(target, rel))
+			if err != nil {
+				return nil, err
+			}
+			sections = append(sections, section)
+		}
+	}
+	return sections, nil
+}
+
+// textSection returns the text of a part, with its pages joined.
+func (a *Archive) textSection(ctx context.Context, doc *officeDocument, label, part string) (officeSection, error) {
+	data, err := a.readOfficePart(ctx, doc, part)
+	if err != nil {
+		return officeSection{}, err
+	}
+	pages, err := officeText(data)
+	if err != nil {
+		return officeSection{}, err
+	}
+	return officeSection{label: label, text: strings.Join(pages, ""), parts: []string{part}}, nil
+}
+
+// officeRootElement returns the local name of the root element of a part.
+func officeRootElement(data []byte) string {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+}
+
+// officeText returns the text of a part of a Word document or presentation,
+// or of comments, split into pages. The runs of text of paragraphs are joined
+// and paragraphs end with a newline. The fallback content of alternate
+// content, which duplicates its choice, is skipped.
+func officeText(data []byte) ([]string, error) {
+	var pages []string
+	var page strings.Builder
+	newPage := func() {
+		// Explicit page breaks are often followed by a rendered one.
+		if strings.TrimSpace(page.String()) != "" {
+			pages = append(pages, page.String())
+			page.Reset()
+		}
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	inText, fallback := 0, 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if fallback > 0 || t.Name.Local == "Fallback" {
+				fallback++
+				continue
+			}
+			switch t.Name.Local {
+			case "t", "delText", "instrText", "text":
+				inText++
+			case "tab":
+				page.WriteByte('\t')
+			case "br", "cr":
+				if officeAttr(t, "type") == "page" {
+					newPage()
+				} else {
+					page.WriteByte('\n')
+				}
+			case "lastRenderedPageBreak":
+				newPage()
+			}
+		case xml.EndElement:
+			if fallback > 0 {
+				fallback--
+				continue
+			}
+			switch t.Name.Local {
+			case "t", "delText", "instrText", "text":
+				inText--
+			case "p":
+				page.WriteByte('\n')
+			case "cm", "comment":
+				if !strings.HasSuffix(page.String(), "\n") {
+					page.WriteByte('\n')
+				}
+			}
+		case xml.CharData:
+			if inText > 0 && fallback == 0 {
+				page.Write(t)
+			}
+		}
+	}
+	if page.Len() > 0 || len(pages) == 0 {
+		pages = append(pages, page.String())
+	}
+	return pages, nil
+}
+
+// officeAttr returns the value of an attribute of an element by local name.
+func officeAttr(start xml.StartElement, name string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// excelSharedStrings returns the shared strings of a workbook, which cells
+// of strings refer to by index.
+func excelSharedStrings(data []byte) ([]string, error) {
+	var sst struct {
+		Items []excelInlineString `xml:"si"`
+	}
+	if err := xml.Unmarshal(data, &sst); err != nil {
+		return nil, err
+	}
+	items := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		items[i] = item.String()
+	}
+	return items, nil
+}
+
+// excelInlineString is a string of a workbook, plain or made of runs of rich
+// text.
+type excelInlineString struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s excelInlineString) String() string {
+	text := s.T
+	for _, run := range s.Runs {
+		text += run.T
+	}
+	return text
+}
+
+// excelSheetText returns the cells of a sheet, joined by tabs, one line per
+// row, with empty lines for empty rows so that line numbers are row numbers.
+// Formulas are scanned before the values computed from them.
+func excelSheetText(data []byte, sharedStrings []string) (string, error) {
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				T      string            `xml:"t,attr"`
+				F      string            `xml:"f"`
+				V      string            `xml:"v"`
+				Inline excelInlineString `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(data, &sheet); err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	line := 1
+	for _, row := range sheet.Rows {
+		// Rows without a number follow the previous one.
+		for line < row.R {
+			text.WriteByte('\n')
+			line++
+		}
+		var cells []string
+		for _, cell := range row.Cells {
+			if cell.F != "" {
+				cells = append(cells, "="+cell.F)
+			}
+			value := cell.V
+			switch cell.T {
+			case "s":
+				if i, err := strconv.Atoi(cell.V); err == nil && i >= 0 && i < len(sharedStrings) {
+					value = sharedStrings[i]
+				}
+			case "inlineStr":
+				value = cell.Inline.String()
+			}
+			if value != "" {
+				cells = append(cells, value)
+			}
+		}
+		// Cells with line breaks would shift the rows after them.
+		text.WriteString(strings.Map(func(r rune) rune {
+			if r == '\n' || r == '\r' {
+				return ' '
+			}
+			return r
+		}, strings.Join(cells, "\t")))
+		text.WriteByte('\n')
+		line++
+	}
+	return text.String(), nil
+}
+
+// extractMacros scans the source of the modules of the VBA project of a
+// macro-enabled document, labelled macros/<module>. It returns false if the
+// project cannot be read.
+func (a *Archive) extractMacros(ctx context.Context, doc *officeDocument, name string, archiveChan chan Part) (bool, error) {
+	data, err := a.readOfficePart(ctx, doc, name)
+	if err != nil {
+		return false, nil
+	}
+	cfb, err := openCompoundFile(data)
+	if err != nil {
+		return false, nil
+	}
+	modules, err := vbaModules(cfb)
+	if err != nil {
+		return false, nil
+	}
+	parent, _ := ctx.Value(pathKey).(string)
+	for _, module := range modules {
+		if err := sendParts(ctx, memberPath(parent, "macros/"+module.name), bytes.NewReader(module.source), archiveChan); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// extractOLEObject scans the streams of an embedded OLE object, and the file
+// packaged in it if it has one, as files in the object. It returns false if
+// the object is not a compound file, like embedded Office documents, which are
+// extracted as files of the archive.
+func (a *Archive) extractOLEObject(ctx context.Context, doc *officeDocument, name string, archiveChan chan Part) (bool, error) {
+	f := doc.parts[name]
+	rc, err := f.Open()
+	if err != nil {
+		return false, nil
+	}
+	magic := make([]byte, len(cfbMagic))
+	_, err = io.ReadFull(rc, magic)
+	rc.Close()
+	if err != nil || !bytes.Equal(magic, cfbMagic) {
+		return false, nil
+	}
+	data, err := a.readOfficePart(ctx, doc, name)
+	if err != nil {
+		return false, nil
+	}
+	cfb, err := openCompoundFile(data)
+	if err != nil {
+		return false, nil
+	}
+
+	depth, _ := ctx.Value(depthKey).(int)
+	parent, _ := ctx.Value(pathKey).(string)
+	objectPath := memberPath(parent, name)
+	return true, cfb.walk(func(streamPath string, e cfbEntry) error {
+		stream, err := cfb.stream(e)
+		if err != nil {
+			return nil
+		}
+		label := strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, streamPath)
+		if streamPath == officeOle10Native {
+			if packageName, content, ok := parseOle10Native(stream); ok {
+				label, stream = packageName, content
+			}
+		}
+		name := memberPath(objectPath, label)
+		err = a.openArchive(ctx, depth, name, bytes.NewReader(stream), archiveChan)
+		if errors.Is(err, errMaxArchiveDepthReached) {
+			a.reportSkip(ctx, name, sources.SkipReasonDepth, int64(len(stream)))
+		}
+		return err
+	})
+}
+
+// parseOle10Native returns the name and content of the file packaged in the
+// Ole10Native stream of an OLE object: its size, then flags, the label,
+// source path and temporary path of the file, and the size and content of
+// the file.
+func parseOle10Native(stream []byte) (string, []byte, bool) {
+	r := bytes.NewReader(stream)
+	var header [6]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return "", nil, false
+	}
+	label, err := readCString(r)
+	if err != nil {
+		return "", nil, false
+	}
+	if _, err := readCString(r); err != nil {
+		return "", nil, false
+	}
+	var unknown [8]byte
+	if _, err := io.ReadFull(r, unknown[:]); err != nil {
+		return "", nil, false
+	}
+	if _, err := readCString(r); err != nil {
+		return "", nil, false
+	}
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return "", nil, false
+	}
+	n := int64(binary.LittleEndian.Uint32(size[:]))
+	if n > int64(r.Len()) {
+		return "", nil, false
+	}
+	content := stream[len(stream)-r.Len():][:n]
+	label = path.Base(strings.ReplaceAll(label, `\`, "/"))
+	if label == "" || label == "." || label == "/" {
+		label = officeOle10Native[1:]
+	}
+	return label, content, true
+}
+
+// readCString reads a NUL terminated string.
+func readCString(r *bytes.Reader) (string, error) {
+	var s []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if b == 0 {
+			return string(s), nil
+		}
+		s = append(s, b)
+	}
+}
@@ -0,0 +1,591 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	orcKindString  = 7
+	orcKindBinary  = 8
+	orcKindMap     = 11
+	orcKindStruct  = 12
+	orcKindVarchar = 16
+	orcKindChar    = 17
+
+	orcStreamData           = 1
+	orcStreamLength         = 2
+	orcStreamDictionaryData = 3
+
+	orcEncodingDictionary   = 1
+	orcEncodingDirectV2     = 2
+	orcEncodingDictionaryV2 = 3
+
+	orcDefaultBlockSize = 256 * 1024
+	// orcMaxValues bounds the integers of a stream decoded, as runs of a
+	// few bytes decode to hundreds of integers.
+	orcMaxValues = 1 << 24
+)
+
+// orcCodecs are the names of the compression codecs of ORC files, by their
+// number. ZLIB streams are raw deflate streams.
+var orcCodecs = map[uint64]string{0: "none", 1: "deflate", 2: "snappy", 4: "lz4", 5: "zstd"}
+
+// protoMessage are the fields of a protobuf message by number: uint64
+// values of varint and fixed fields, and []byte values of the others.
+type protoMessage map[protowire.Number][]interface{}
+
+func parseProto(b []byte) (protoMessage, error) {
+	m := make(protoMessage)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, errCorruptColumnar
+		}
+		b = b[n:]
+		var value interface{}
+		switch typ {
+		case protowire.VarintType:
+			value, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			value = uint64(v)
+		case protowire.Fixed64Type:
+			value, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return nil, errCorruptColumnar
+		}
+		b = b[n:]
+		if value != nil {
+			m[num] = append(m[num], value)
+		}
+	}
+	return m, nil
+}
+
+func (m protoMessage) uint(num protowire.Number) uint64 {
+	for _, v := range m[num] {
+		if v, ok := v.(uint64); ok {
+			return v
+		}
+	}
+	return 0
+}
+
+func (m protoMessage) bytes(num protowire.Number) []byte {
+	for _, v := range m[num] {
+		if v, ok := v.([]byte); ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// uints returns the values of a repeated integer field, packed or not.
+func (m protoMessage) uints(num protowire.Number) ([]uint64, error) {
+	var values []uint64
+	for _, v := range m[num] {
+		switch v := v.(type) {
+		case uint64:
+			values = append(values, v)
+		case []byte:
+			for len(v) > 0 {
+				value, n := protowire.ConsumeVarint(v)
+				if n < 0 {
+					return nil, errCorruptColumnar
+				}
+				values, v = append(values, value), v[n:]
+			}
+		}
+	}
+	return values, nil
+}
+
+// messages returns the values of a repeated message field.
+func (m protoMessage) messages(num protowire.Number) ([]protoMessage, error) {
+	var messages []protoMessage
+	for _, v := range m[num] {
+		b, ok := v.([]byte)
+		if !ok {
+			return nil, errCorruptColumnar
+		}
+		message, err := parseProto(b)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// orcFile is an ORC file, read from its postscript and footer at its end.
+type orcFile struct {
+	r         io.ReaderAt
+	size      int64
+	codec     string
+	blockSize int
+	stripes   []protoMessage
+}
+
+// orcColumns reads the footer of an ORC file, and returns its user metadata
+// and its columns of strings, which are read stripe by stripe.
+func orcColumns(r io.ReaderAt, size int64) ([]column, error) {
+	if size < int64(len(orcMagic))+1 {
+		return nil, errCorruptColumnar
+	}
+	last := make([]byte, 1)
+	if err := readAt(r, last, size-1); err != nil {
+		return nil, err
+	}
+	postscriptSize := int64(last[0])
+	if postscriptSize+1 > size {
+		return nil, errCorruptColumnar
+	}
+	b := make([]byte, postscriptSize)
+	if err := readAt(r, b, size-1-postscriptSize); err != nil {
+		return nil, err
+	}
+	postscript, err := parseProto(b)
+	if err != nil || string(postscript.bytes(8000)) != orcMagic {
+		return nil, errCorruptColumnar
+	}
+	f := &orcFile{r: r, size: size, blockSize: int(postscript.uint(3))}
+	codec, ok := orcCodecs[postscript.uint(2)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported codec %d", errCorruptColumnar, postscript.uint(2))
+	}
+	f.codec = codec
+	if f.blockSize == 0 {
+		f.blockSize = orcDefaultBlockSize
+	}
+	footerSize := int64(postscript.uint(1))
+	if footerSize > maxMetadataSize || f.blockSize > maxMetadataSize || footerSize+postscriptSize+1 > size {
+		return nil, errCorruptColumnar
+	}
+	footerData, err := f.read(size-1-postscriptSize-footerSize, footerSize)
+	if err != nil {
+		return nil, err
+	}
+	footer, err := parseProto(footerData)
+	if err != nil {
+		return nil, err
+	}
+	if f.stripes, err = footer.messages(3); err != nil {
+		return nil, err
+	}
+	types, err := footer.messages(4)
+	if err != nil {
+		return nil, err
+	}
+	userMetadata, err := footer.messages(5)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []column
+	var metadata bytes.Buffer
+	for _, item := range userMetadata {
+		fmt.Fprintf(&metadata, "%s = %s\n", item.bytes(1), item.bytes(2))
+	}
+	if metadata.Len() > 0 {
+		columns = append(columns, column{name: "metadata", values: &metadata})
+	}
+	names, err := orcColumnNames(types)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range types {
+		switch t.uint(1) {
+		case orcKindString, orcKindBinary, orcKindVarchar, orcKindChar:
+			columns = append(columns, column{name: names[i], values: f.values(i)})
+		}
+	}
+	return columns, nil
+}
+
+// orcColumnNames names the columns of an ORC file after the fields of the
+// structs they are in, e.g. user.token, and the keys of maps after their map
+// with a .key suffix. Types are listed depth first, from the struct of the
+// rows.
+func orcColumnNames(types []protoMessage) ([]string, error) {
+	names := make([]string, len(types))
+	visited := make([]bool, len(types))
+	var walk func(i int, name string) error
+	walk = func(i int, name string) error {
+		if visited[i] {
+			return errCorruptColumnar
+		}
+		visited[i] = true
+		names[i] = name
+		subtypes, err := types[i].uints(2)
+		if err != nil {
+			return err
+		}
+		var fieldNames [][]byte
+		for _, v := range types[i][3] {
+			fieldName, _ := v.([]byte)
+			fieldNames = append(fieldNames, fieldName)
+		}
+		for j, subtype := range subtypes {
+			if subtype <= uint64(i) || subtype >= uint64(len(types)) {
+				return errCorruptColumnar
+			}
+			child := name
+			switch types[i].uint(1) {
+			case orcKindStruct:
+				if j < len(fieldNames) {
+					child = string(fieldNames[j])
+					if name != "" {
+						child = name + "." + child
+					}
+				}
+			case orcKindMap:
+				if j == 0 {
+					child = name + ".key"
+				}
+			}
+			if err := walk(int(subtype), child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(types) == 0 {
+		return names, nil
+	}
+	return names, walk(0, "")
+}
+
+// read reads and decompresses a stream of the file.
+func (f *orcFile) read(offset, size int64) ([]byte, error) {
+	if offset < 0 || size < 0 || size > maxMetadataSize || offset+size > f.size {
+		return nil, errCorruptColumnar
+	}
+	data := make([]byte, size)
+	if err := readAt(f.r, data, offset); err != nil {
+		return nil, err
+	}
+	if f.codec == "none" {
+		return data, nil
+	}
+	// Compressed streams are chunks after a 3 byte header of their size
+	// and whether they are stored uncompressed.
+	var out []byte
+	for len(data) > 0 {
+		if len(data) < 3 {
+			return nil, errCorruptColumnar
+		}
+		header := int(data[0]) | int(data[1])<<8 | int(data[2])<<16
+		n := header >> 1
+		if n > len(data)-3 {
+			return nil, errCorruptColumnar
+		}
+		chunk := data[3 : 3+n]
+		data = data[3+n:]
+		if header&1 == 0 {
+			var err error
+			if chunk, err = decompressColumnar(f.codec, chunk, f.blockSize); err != nil {
+				return nil, err
+			}
+		}
+		if out = append(out, chunk...); len(out) > maxMetadataSize {
+			return nil, errCorruptColumnar
+		}
+	}
+	return out, nil
+}
+
+// values returns a reader of the strings of a column, read a stripe at a
+// time. Dictionary encoded columns are read as their dictionary, the
+// distinct strings of the stripe.
+func (f *orcFile) values(col int) io.Reader {
+	stripes := f.stripes
+	return &lineReader{next: func() ([][]byte, error) {
+		for len(stripes) > 0 {
+			stripe := stripes[0]
+			stripes = stripes[1:]
+			values, err := f.stripeValues(stripe, col)
+			if err != nil || len(values) > 0 {
+				return values, err
+			}
+		}
+		return nil, io.EOF
+	}}
+}
+
+func (f *orcFile) stripeValues(stripe protoMessage, col int) ([][]byte, error) {
+	offset := int64(stripe.uint(1))
+	footerOffset := offset + int64(stripe.uint(2)) + int64(stripe.uint(3))
+	footerData, err := f.read(footerOffset, int64(stripe.uint(4)))
+	if err != nil {
+		return nil, err
+	}
+	footer, err := parseProto(footerData)
+	if err != nil {
+		return nil, err
+	}
+	streams, err := footer.messages(1)
+	if err != nil {
+		return nil, err
+	}
+	encodings, err := footer.messages(2)
+	if err != nil {
+		return nil, err
+	}
+	if col >= len(encodings) {
+		return nil, errCorruptColumnar
+	}
+	encoding := encodings[col].uint(1)
+
+	// Streams are stored one after the other from the start of the
+	// stripe.
+	var data, lengths []byte
+	position := offset
+	for _, stream := range streams {
+		kind, length := stream.uint(1), int64(stream.uint(3))
+		if stream.uint(2) == uint64(col) {
+			dictionary := encoding == orcEncodingDictionary || encoding == orcEncodingDictionaryV2
+			switch {
+			case kind == orcStreamLength:
+				lengths, err = f.read(position, length)
+			case kind == orcStreamData && !dictionary, kind == orcStreamDictionaryData && dictionary:
+				data, err = f.read(position, length)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		position += length
+	}
+	var sizes []uint64
+	if encoding == orcEncodingDirectV2 || encoding == orcEncodingDictionaryV2 {
+		sizes, err = orcRLEv2(lengths)
+	} else {
+		sizes, err = orcRLEv1(lengths)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var values [][]byte
+	for _, n := range sizes {
+		if n > uint64(len(data)) {
+			return nil, errCorruptColumnar
+		}
+		values, data = append(values, data[:n]), data[n:]
+	}
+	return values, nil
+}
+
+// orcRLEv1 decodes unsigned integers of the run length encoding of ORC 0.11:
+// runs of 3 to 130 integers with a fixed delta, and literals.
+func orcRLEv1(data []byte) ([]uint64, error) {
+	var values []uint64
+	for len(data) > 0 {
+		if len(values) > orcMaxValues {
+			return nil, errCorruptColumnar
+		}
+		control := int8(data[0])
+		data = data[1:]
+		if control >= 0 {
+			if len(data) < 1 {
+				return nil, errCorruptColumnar
+			}
+			delta := int64(int8(data[0]))
+			base, n := binary.Uvarint(data[1:])
+			if n <= 0 {
+				return nil, errCorruptColumnar
+			}
+			data = data[1+n:]
+			for i := int64(0); i < int64(control)+3; i++ {
+				values = append(values, base+uint64(delta*i))
+			}
+			continue
+		}
+		for i := 0; i < -int(control); i++ {
+			v, n := binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errCorruptColumnar
+			}
+			values, data = append(values, v), data[n:]
+		}
+	}
+	return values, nil
+}
+
+// orcBitWidths are the bit widths of the 5 bit codes of RLE v2 headers.
+var orcBitWidths = [32]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 26, 28, 30, 32, 40, 48, 56, 64}
+
+// orcClosestFixedBits rounds the bit width of patches up to one that can be
+// encoded.
+func orcClosestFixedBits(n int) int {
+	for _, width := range orcBitWidths {
+		if width >= n {
+			return width
+		}
+	}
+	return 64
+}
+
+// orcRLEv2 decodes unsigned integers of the run length encoding of ORC 0.12:
+// short repeats, direct, patched base and delta runs.
+func orcRLEv2(data []byte) ([]uint64, error) {
+	var values []uint64
+	for len(data) > 0 {
+		if len(values) > orcMaxValues {
+			return nil, errCorruptColumnar
+		}
+		header := data[0]
+		switch header >> 6 {
+		case 0:
+			width, count := int(header>>3&7)+1, int(header&7)+3
+			if len(data) < 1+width {
+				return nil, errCorruptColumnar
+			}
+			v := bigEndianUint(data[1 : 1+width])
+			for i := 0; i < count; i++ {
+				values = append(values, v)
+			}
+			data = data[1+width:]
+		case 1:
+			if len(data) < 2 {
+				return nil, errCorruptColumnar
+			}
+			width, count := orcBitWidths[header>>1&0x1f], (int(header&1)<<8|int(data[1]))+1
+			data = data[2:]
+			size := (count*width + 7) / 8
+			if size > len(data) {
+				return nil, errCorruptColumnar
+			}
+			values = append(values, unpackBitsMSB(data[:size], count, width)...)
+			data = data[size:]
+		case 2:
+			if len(data) < 4 {
+				return nil, errCorruptColumnar
+			}
+			width, count := orcBitWidths[header>>1&0x1f], (int(header&1)<<8|int(data[1]))+1
+			baseWidth, patchWidth := int(data[2]>>5&7)+1, orcBitWidths[data[2]&0x1f]
+			gapWidth, patchCount := int(data[3]>>5&7)+1, int(data[3]&0x1f)
+			data = data[4:]
+			if len(data) < baseWidth {
+				return nil, errCorruptColumnar
+			}
+			// The most significant bit of the base is its sign.
+			base := int64(bigEndianUint(data[:baseWidth]))
+			if mask := int64(1) << (8*baseWidth - 1); base&mask != 0 {
+				base = -(base &^ mask)
+			}
+			data = data[baseWidth:]
+			size := (count*width + 7) / 8
+			patchBits := orcClosestFixedBits(gapWidth + patchWidth)
+			patchSize := (patchCount*patchBits + 7) / 8
+			if size+patchSize > len(data) || patchWidth+width > 64 {
+				return nil, errCorruptColumnar
+			}
+			runValues := unpackBitsMSB(data[:size], count, width)
+			position := 0
+			for _, patch := range unpackBitsMSB(data[size:size+patchSize], patchCount, patchBits) {
+				position += int(patch >> patchWidth)
+				if position < count {
+					runValues[position] |= (patch & (1<<patchWidth - 1)) << width
+				}
+			}
+			for _, v := range runValues {
+				values = append(values, uint64(base+int64(v)))
+			}
+			data = data[size+patchSize:]
+		case 3:
+			if len(data) < 2 {
+				return nil, errCorruptColumnar
+			}
+			width := 0
+			if code := header >> 1 & 0x1f; code != 0 {
+				width = orcBitWidths[code]
+			}
+			count := (int(header&1)<<8 | int(data[1])) + 1
+			data = data[2:]
+			base, n := binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errCorruptColumnar
+			}
+			data = data[n:]
+			deltaBase, n := binary.Varint(data)
+			if n <= 0 {
+				return nil, errCorruptColumnar
+			}
+			data = data[n:]
+			values = append(values, base)
+			previous := base
+			if count > 1 {
+				previous += uint64(deltaBase)
+				values = append(values, previous)
+			}
+			var deltas []uint64
+			if count > 2 {
+				if width == 0 {
+					deltas = make([]uint64, count-2)
+					for i := range deltas {
+						deltas[i] = uint64(abs64(deltaBase))
+					}
+				} else {
+					size := ((count-2)*width + 7) / 8
+					if size > len(data) {
+						return nil, errCorruptColumnar
+					}
+					deltas = unpackBitsMSB(data[:size], count-2, width)
+					data = data[size:]
+				}
+			}
+			for _, delta := range deltas {
+				if deltaBase < 0 {
+					previous -= delta
+				} else {
+					previous += delta
+				}
+				values = append(values, previous)
+			}
+		}
+	}
+	return values, nil
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// bigEndianUint reads a big endian integer of up to 8 bytes.
+func bigEndianUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// unpackBitsMSB reads count integers of width bits of data, most
+// significant bits first.
+func unpackBitsMSB(data []byte, count, width int) []uint64 {
+	values := make([]uint64, count)
+	bit := 0
+	for i := range values {
+		var v uint64
+		for j := 0; j < width; j++ {
+			v = v<<1 | uint64(data[bit/8]>>(7-bit%8)&1)
+			bit++
+		}
+		values[i] = v
+	}
+	return values
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/klauspost/compress/zip"
+	"github.com/mholt/archiver/v4"
+	"sigs.k8s.io/yaml"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// The ecosystems of the packages whose files carry their package.
+const (
+	ecosystemNPM      = "npm"
+	ecosystemPyPI     = "pypi"
+	ecosystemRubyGems = "rubygems"
+)
+
+var (
+	// sdistFirstEntry matches the first entry of Python source
+	// distributions, in their name-version directory.
+	sdistFirstEntry = regexp.MustCompile(`^([^/]+-[0-9][^/-]*)/`)
+	// wheelMetadataFile is the file of the name and version of a Python
+	// wheel.
+	wheelMetadataFile = regexp.MustCompile(`^[^/]+\.dist-info/METADATA$`)
+)
+
+// identifyPackageTar returns the ecosystem of a tar archive that is a
+// package, from the name of its first entry: npm tarballs have their files
+// in package, Python source distributions in their name-version directory,
+// and gems start with their metadata.gz. It also returns the file of the
+// name and version of the package. The returned reader must be read instead
+// of reader.
+func identifyPackageTar(reader io.Reader) (ecosystem, manifest string, _ io.Reader, _ bool) {
+	br := bufio.NewReaderSize(reader, 512)
+	header, err := br.Peek(512)
+	if err != nil {
+		return "", "", br, false
+	}
+	nameBytes, _, _ := bytes.Cut(header[:100], []byte{0})
+	name := strings.TrimPrefix(string(nameBytes), "./")
+	switch {
+	case name == "metadata.gz":
+		return ecosystemRubyGems, name, br, true
+	case strings.HasPrefix(name, "package/"):
+		return ecosystemNPM, "package/package.json", br, true
+	}
+	if m := sdistFirstEntry.FindStringSubmatch(name); m != nil {
+		return ecosystemPyPI, m[1] + "/PKG-INFO", br, true
+	}
+	return "", "", br, false
+}
+
+// extractPackage extracts the files of a tar archive that is a package of an
+// ecosystem, which carry the name and version of the package read from its
+// manifest, like its package.json. Archives without a manifest are extracted
+// as other tar archives.
+func (a *Archive) extractPackage(ctx context.Context, depth int, path, ecosystem, manifest string, reader io.Reader, archiveChan chan Part) error {
+	seekableReader, cleanup, err := seekable(reader)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	r := seekableReader.(readerAtSeeker)
+	size, err := readerSize(r)
+	if err != nil {
+		return err
+	}
+
+	entries, err := indexTar(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return err
+	}
+	if entry, ok := entries[manifest]; ok {
+		content, err := io.ReadAll(io.LimitReader(io.NewSectionReader(r, entry.offset, entry.size), maxMetadataSize))
+		if err != nil {
+			return err
+		}
+		if pkg := parsePackage(ecosystem, content); pkg != nil {
+			logContext.AddLogger(ctx).Logger().V(3).Info("Handling package.", "ecosystem", pkg.Ecosystem, "package", pkg.Name, "version", pkg.Version)
+			ctx = context.WithValue(ctx, packageKey, pkg)
+		}
+	}
+	extractCtx := context.WithValue(context.WithValue(ctx, depthKey, depth+1), pathKey, path)
+	return archiver.Tar{}.Extract(extractCtx, io.NewSectionReader(r, 0, size), nil, a.extractorHandler(archiveChan))
+}
+
+// wheelPackage returns the name and version of a Python wheel from its
+// METADATA, or nil if the zip archive is not a wheel.
+func wheelPackage(zr *zip.Reader) *source_metadatapb.Package {
+	for _, f := range zr.File {
+		if !wheelMetadataFile.MatchString(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(io.LimitReader(rc, maxMetadataSize))
+		if err != nil {
+			return nil
+		}
+		return parsePackage(ecosystemPyPI, content)
+	}
+	return nil
+}
+
+// parsePackage returns the name and version of a package from its manifest:
+// the package.json of npm packages, the PKG-INFO or METADATA of Python
+// packages, and the gzipped gemspec of gems. It returns nil if the manifest
+// has no name.
+func parsePackage(ecosystem string, content []byte) *source_metadatapb.Package {
+	var name, version string
+	switch ecosystem {
+	case ecosystemNPM:
+		var manifest struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return nil
+		}
+		name, version = manifest.Name, manifest.Version
+	case ecosystemPyPI:
+		// PKG-INFO and METADATA are email headers.
+		msg, err := mail.ReadMessage(io.MultiReader(bytes.NewReader(content), strings.NewReader("\r\n\r\n")))
+		if err != nil {
+			return nil
+		}
+		name, version = msg.Header.Get("Name"), msg.Header.Get("Version")
+	case ecosystemRubyGems:
+		gz, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil
+		}
+		spec, err := io.ReadAll(io.LimitReader(gz, maxMetadataSize))
+		if err != nil {
+			return nil
+		}
+		var gemspec struct {
+			Name    string `json:"name"`
+			Version struct {
+				Version string `json:"version"`
+			} `json:"version"`
+		}
+		if err := yaml.Unmarshal(spec, &gemspec); err != nil {
+			return nil
+		}
+		name, version = gemspec.Name, gemspec.Version.Version
+	}
+	if name == "" {
+		return nil
+	}
+	return &source_metadatapb.Package{Ecosystem: ecosystem, Name: name, Version: version}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// makeTar returns a tar archive of files, in order.
+func makeTar(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: files[i], Mode: 0o644, Size: int64(len(files[i+1])), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(files[i+1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+// handlePackage handles a file, and returns the packages of the files
+// extracted from it by path.
+func handlePackage(t *testing.T, name string, data []byte) map[string]*source_metadatapb.Package {
+	t.Helper()
+	skel := &sources.Chunk{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: name}},
+		},
+	}
+	ch := make(chan *sources.Chunk, 1024)
+	assert.True(t, HandleFile(context.Background(), bytes.NewReader(data), skel, ch))
+	close(ch)
+	packages := make(map[string]*source_metadatapb.Package)
+	for chunk := range ch {
+		metadata := chunk.SourceMetadata.GetFilesystem()
+		packages[metadata.GetArchivePath()] = metadata.GetPackage()
+	}
+	return packages
+}
+
+func TestHandleFile_RegistryPackages(t *testing.T) {
+	gemspec := `--- !ruby/object:Gem::Specification
+name: deploy-tools
+version: !ruby/object:Gem::Version
+  version: 0.4.1
+platform: ruby
+date: 2023-05-02 00:00:00.000000000 Z
+`
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want map[string]*source_metadatapb.Package
+	}{
+		{
+			// package.json is not the first file of npm tarballs.
+			name: "left-pad-1.3.0.tgz",
+			data: gzipped(t, makeTar(t,
+				"package/index.js", "const token = 'npm_secret';\n",
+				"package/package.json", `{"name": "@acme/left-pad", "version": "1.3.0"}`,
+			)),
+			want: map[string]*source_metadatapb.Package{
+				"package/index.js":     {Ecosystem: "npm", Name: "@acme/left-pad", Version: "1.3.0"},
+				"package/package.json": {Ecosystem: "npm", Name: "@acme/left-pad", Version: "1.3.0"},
+			},
+		},
+		{
+			name: "acme-2.0.1.tar.gz",
+			data: gzipped(t, makeTar(t,
+				"acme-2.0.1/acme/settings.py", "TOKEN = 'pypi_secret'\n",
+				"acme-2.0.1/PKG-INFO", "Metadata-Version: 2.1\nName: acme\nVersion: 2.0.1\nSummary: Acme\n",
+			)),
+			want: map[string]*source_metadatapb.Package{
+				"acme-2.0.1/acme/settings.py": {Ecosystem: "pypi", Name: "acme", Version: "2.0.1"},
+				"acme-2.0.1/PKG-INFO":         {Ecosystem: "pypi", Name: "acme", Version: "2.0.1"},
+			},
+		},
+		{
+			name: "acme-2.0.1-py3-none-any.whl",
+			data: makeZip(t, map[string][]byte{
+				"acme/settings.py":              []byte("TOKEN = 'pypi_secret'\n"),
+				"acme-2.0.1.dist-info/METADATA": []byte("Metadata-Version: 2.1\nName: acme\nVersion: 2.0.1\n\nThe description.\n"),
+			}),
+			want: map[string]*source_metadatapb.Package{
+				"acme/settings.py":              {Ecosystem: "pypi", Name: "acme", Version: "2.0.1"},
+				"acme-2.0.1.dist-info/METADATA": {Ecosystem: "pypi", Name: "acme", Version: "2.0.1"},
+			},
+		},
+		{
+			name: "deploy-tools-0.4.1.gem",
+			data: makeTar(t,
+				"metadata.gz", string(gzipped(t, []byte(gemspec))),
+				"data.tar.gz", string(gzipped(t, makeTar(t, "lib/deploy.rb", "TOKEN = 'gem_secret'\n"))),
+			),
+			want: map[string]*source_metadatapb.Package{
+				"metadata.gz":                {Ecosystem: "rubygems", Name: "deploy-tools", Version: "0.4.1"},
+				"data.tar.gz!/lib/deploy.rb": {Ecosystem: "rubygems", Name: "deploy-tools", Version: "0.4.1"},
+			},
+		},
+		{
+			// Release tarballs without a PKG-INFO are not packages.
+			name: "tool-1.0.tar.gz",
+			data: gzipped(t, makeTar(t, "tool-1.0/config.ini", "token = secret\n")),
+			want: map[string]*source_metadatapb.Package{"tool-1.0/config.ini": nil},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, handlePackage(t, tc.name, tc.data))
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/mholt/archiver/v4"
+)
+
+// maxBufferedEntrySize is the size of the largest file of a tar archive read
+// into memory to be extracted concurrently with the next ones. Larger files
+// are streamed from the archive one at a time.
+const maxBufferedEntrySize = 4 * 1024 * 1024
+
+// entryPool extracts files of an archive on the workers of an archive handler
+// when it has one free, and in the calling goroutine otherwise. Running files
+// inline when all workers are busy bounds the goroutines of nested archives,
+// which extract their files on the same workers, without deadlocking, and
+// stops reading the archive while its files are extracted. Parts of files are
+// sent on the same channel, whose buffer bounds how far extraction gets ahead
+// of scanning.
+type entryPool struct {
+	archive *Archive
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	once    sync.Once
+	err     error
+}
+
+// newEntryPool returns a pool to extract the files of an archive with, and
+// the context to extract them in, which is canceled when one fails.
+func (a *Archive) newEntryPool(ctx context.Context) (*entryPool, context.Context) {
+	p := &entryPool{archive: a}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	return p, p.ctx
+}
+
+// concurrent reports whether the pool extracts files concurrently.
+func (p *entryPool) concurrent() bool {
+	return p.archive.workers != nil
+}
+
+// run extracts a file with extract, on a free worker if there is one.
+func (p *entryPool) run(extract func(ctx context.Context) error) {
+	select {
+	case p.archive.workers <- struct{}{}:
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			defer func() { <-p.archive.workers }()
+			// Panics of decompressors are recovered in the goroutine
+			// of the archive handler, like those of files extracted
+			// inline.
+			defer func() {
+				if r := recover(); r != nil {
+					err, ok := r.(error)
+					if !ok {
+						err = fmt.Errorf("Panic occurred: %v", r)
+					}
+					p.fail(err)
+				}
+			}()
+			if err := extract(p.ctx); err != nil {
+				p.fail(err)
+			}
+		}()
+	default:
+		if err := extract(p.ctx); err != nil {
+			p.fail(err)
+		}
+	}
+}
+
+// fail cancels the extraction of the other files with the first error.
+func (p *entryPool) fail(err error) {
+	p.once.Do(func() {
+		p.err = err
+		p.cancel()
+	})
+}
+
+// wait waits for the files being extracted, and returns the first error
+// extracting one.
+func (p *entryPool) wait() error {
+	p.wg.Wait()
+	p.cancel()
+	return p.err
+}
+
+// forEachEntry calls extract with the index of each of n files of an archive
+// that can be read in any order, like those of zip and 7z archives, extracting
+// them concurrently if the archive handler has workers.
+func (a *Archive) forEachEntry(ctx context.Context, n int, extract func(ctx context.Context, i int) error) error {
+	pool, poolCtx := a.newEntryPool(ctx)
+	for i := 0; i < n; i++ {
+		if poolCtx.Err() != nil {
+			break
+		}
+		i := i
+		pool.run(func(ctx context.Context) error { return extract(ctx, i) })
+	}
+	if err := pool.wait(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// bufferedHandler returns a handler of the files of an archive read in order,
+// like a tar archive, that reads small files into memory to extract them
+// concurrently while the next ones are read. It returns handleFile itself if
+// the pool does not extract files concurrently.
+func (p *entryPool) bufferedHandler(handleFile archiver.FileHandler) archiver.FileHandler {
+	if !p.concurrent() {
+		return handleFile
+	}
+	return func(ctx context.Context, f archiver.File) error {
+		if f.IsDir() || f.Size() > maxBufferedEntrySize || isEncrypted(f) {
+			return handleFile(ctx, f)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, maxBufferedEntrySize+1))
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		f.Open = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil }
+		p.run(func(ctx context.Context) error { return handleFile(ctx, f) })
+		return p.ctx.Err()
+	}
+}
@@ -0,0 +1,563 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// The types of the Thrift compact protocol the metadata of Parquet files is
+// encoded with.
+const (
+	thriftStop      = 0
+	thriftTrue      = 1
+	thriftFalse     = 2
+	thriftByte      = 3
+	thriftI16       = 4
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftDouble    = 7
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftSet       = 10
+	thriftMap       = 11
+	thriftStruct    = 12
+	thriftMaxNested = 64
+)
+
+const (
+	parquetTypeByteArray = 6
+
+	parquetRepetitionOptional = 1
+	parquetRepetitionRepeated = 2
+
+	parquetPageData       = 0
+	parquetPageDictionary = 2
+	parquetPageDataV2     = 3
+
+	parquetEncodingPlain                = 0
+	parquetEncodingRLE                  = 3
+	parquetEncodingBitPacked            = 4
+	parquetEncodingDeltaLengthByteArray = 6
+	parquetEncodingDeltaByteArray       = 7
+	parquetEncodingPlainDictionary      = 2
+	parquetEncodingRLEDictionary        = 8
+)
+
+// parquetCodecs are the names of the compression codecs of Parquet pages, by
+// their number. LZ4 pages with the Hadoop framing are not decompressed.
+var parquetCodecs = map[int64]string{0: "none", 1: "snappy", 2: "gzip", 4: "brotli", 6: "zstd", 7: "lz4"}
+
+// thriftFields are the fields of a Thrift struct by id: int64, float64,
+// bool, []byte, []interface{} or thriftFields values.
+type thriftFields map[int16]interface{}
+
+func (f thriftFields) int(id int16) int64 {
+	v, _ := f[id].(int64)
+	return v
+}
+
+func (f thriftFields) bytes(id int16) []byte {
+	v, _ := f[id].([]byte)
+	return v
+}
+
+func (f thriftFields) list(id int16) []interface{} {
+	v, _ := f[id].([]interface{})
+	return v
+}
+
+func (f thriftFields) fields(id int16) thriftFields {
+	v, _ := f[id].(thriftFields)
+	return v
+}
+
+// thriftReader decodes Thrift structs of the compact protocol.
+type thriftReader struct {
+	r     *bufio.Reader
+	depth int
+}
+
+func (t *thriftReader) varint() (int64, error) {
+	v, err := binary.ReadUvarint(t.r)
+	if err != nil {
+		return 0, err
+	}
+	// Integers are zigzag encoded.
+	return int64(v>>1) ^ -int64(v&1), nil
+}
+
+func (t *thriftReader) size() (int, error) {
+	v, err := binary.ReadUvarint(t.r)
+	if err != nil {
+		return 0, err
+	}
+	if v > maxMetadataSize {
+		return 0, errCorruptColumnar
+	}
+	return int(v), nil
+}
+
+// readStruct reads the fields of a struct, up to its stop field.
+func (t *thriftReader) readStruct() (thriftFields, error) {
+	if t.depth++; t.depth > thriftMaxNested {
+		return nil, errCorruptColumnar
+	}
+	defer func() { t.depth-- }()
+	fields := make(thriftFields)
+	var id int16
+	for {
+		header, err := t.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		typ := header & 0x0f
+		if typ == thriftStop {
+			return fields, nil
+		}
+		if delta := header >> 4; delta != 0 {
+			id += int16(delta)
+		} else {
+			v, err := t.varint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		if typ == thriftTrue || typ == thriftFalse {
+			fields[id] = typ == thriftTrue
+			continue
+		}
+		if fields[id], err = t.readValue(typ); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readValue reads a value of a type other than the booleans of structs,
+// which are encoded in the type of their field.
+func (t *thriftReader) readValue(typ byte) (interface{}, error) {
+	switch typ {
+	case thriftTrue, thriftFalse:
+		// Booleans of lists are a byte each.
+		b, err := t.r.ReadByte()
+		return b == thriftTrue, err
+	case thriftByte:
+		b, err := t.r.ReadByte()
+		return int64(int8(b)), err
+	case thriftI16, thriftI32, thriftI64:
+		return t.varint()
+	case thriftDouble:
+		var b [8]byte
+		if _, err := io.ReadFull(t.r, b[:]); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[:])), nil
+	case thriftBinary:
+		n, err := t.size()
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, n)
+		_, err = io.ReadFull(t.r, b)
+		return b, err
+	case thriftList, thriftSet:
+		header, err := t.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		n := int(header >> 4)
+		if n == 15 {
+			if n, err = t.size(); err != nil {
+				return nil, err
+			}
+		}
+		var values []interface{}
+		for i := 0; i < n; i++ {
+			v, err := t.readValue(header & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case thriftMap:
+		n, err := t.size()
+		if err != nil || n == 0 {
+			return nil, err
+		}
+		types, err := t.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		// Maps are not used by Parquet, and are read as lists of their
+		// keys and values.
+		var values []interface{}
+		for i := 0; i < 2*n; i++ {
+			typ := types >> 4
+			if i%2 == 1 {
+				typ = types & 0x0f
+			}
+			v, err := t.readValue(typ)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case thriftStruct:
+		return t.readStruct()
+	}
+	return nil, fmt.Errorf("%w: Thrift type %d", errCorruptColumnar, typ)
+}
+
+// parquetChunk is a chunk of a column in a row group of a Parquet file.
+type parquetChunk struct {
+	codec        string
+	offset, size int64
+}
+
+// parquetColumns reads the footer of a Parquet file, and returns its
+// key-value metadata and its columns of strings, which are read row group
+// by row group.
+func parquetColumns(r io.ReaderAt, size int64) ([]column, error) {
+	if size < 2*int64(len(parquetMagic))+4 {
+		return nil, errCorruptColumnar
+	}
+	tail := make([]byte, 8)
+	if err := readAt(r, tail, size-8); err != nil {
+		return nil, err
+	}
+	footerSize := int64(binary.LittleEndian.Uint32(tail))
+	if string(tail[4:]) != parquetMagic || footerSize > maxMetadataSize || footerSize > size-12 {
+		return nil, errCorruptColumnar
+	}
+	t := &thriftReader{r: bufio.NewReader(io.NewSectionReader(r, size-8-footerSize, footerSize))}
+	footer, err := t.readStruct()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptColumnar, err)
+	}
+
+	var columns []column
+	var metadata []byte
+	for _, kv := range footer.list(5) {
+		if kv, ok := kv.(thriftFields); ok {
+			metadata = append(metadata, fmt.Sprintf("%s = %s\n", kv.bytes(1), kv.bytes(2))...)
+		}
+	}
+	if createdBy := footer.bytes(6); len(createdBy) > 0 {
+		metadata = append(metadata, fmt.Sprintf("created_by = %s\n", createdBy)...)
+	}
+	if len(metadata) > 0 {
+		columns = append(columns, column{name: "metadata", values: strings.NewReader(string(metadata))})
+	}
+
+	levels := parquetLevels(footer.list(2))
+	var names []string
+	chunks := make(map[string][]parquetChunk)
+	for _, rowGroup := range footer.list(4) {
+		rowGroup, _ := rowGroup.(thriftFields)
+		for _, chunk := range rowGroup.list(1) {
+			chunk, _ := chunk.(thriftFields)
+			meta := chunk.fields(3)
+			// Chunks in other files are not read.
+			if meta == nil || len(chunk.bytes(1)) > 0 || meta.int(1) != parquetTypeByteArray {
+				continue
+			}
+			var path []string
+			for _, name := range meta.list(3) {
+				name, _ := name.([]byte)
+				path = append(path, string(name))
+			}
+			name := strings.Join(path, ".")
+			codec, ok := parquetCodecs[meta.int(4)]
+			if !ok {
+				return nil, fmt.Errorf("%w: unsupported codec %d", errCorruptColumnar, meta.int(4))
+			}
+			offset := meta.int(9)
+			if dictionary := meta.int(11); dictionary > 0 && dictionary < offset {
+				offset = dictionary
+			}
+			chunkSize := meta.int(7)
+			if offset < 0 || chunkSize < 0 || offset+chunkSize > size {
+				return nil, errCorruptColumnar
+			}
+			if _, ok := chunks[name]; !ok {
+				names = append(names, name)
+			}
+			chunks[name] = append(chunks[name], parquetChunk{codec: codec, offset: offset, size: chunkSize})
+		}
+	}
+	for _, name := range names {
+		columns = append(columns, column{name: name, values: parquetValues(r, chunks[name], levels[name])})
+	}
+	return columns, nil
+}
+
+// parquetLevels returns the maximum repetition and definition levels of the
+// columns of a Parquet schema, which is flattened depth first.
+func parquetLevels(schema []interface{}) map[string][2]int {
+	levels := make(map[string][2]int)
+	var walk func(i int, prefix string, rep, def int) int
+	walk = func(i int, prefix string, rep, def int) int {
+		if i >= len(schema) {
+			return i
+		}
+		element, _ := schema[i].(thriftFields)
+		name := string(element.bytes(4))
+		if i > 0 {
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			switch element.int(3) {
+			case parquetRepetitionOptional:
+				def++
+			case parquetRepetitionRepeated:
+				rep, def = rep+1, def+1
+			}
+		} else {
+			name = ""
+		}
+		children := int(element.int(5))
+		if children == 0 && i > 0 {
+			levels[name] = [2]int{rep, def}
+			return i + 1
+		}
+		next := i + 1
+		for c := 0; c < children && next < len(schema); c++ {
+			next = walk(next, name, rep, def)
+		}
+		return next
+	}
+	walk(0, "", 0, 0)
+	return levels
+}
+
+// parquetValues returns a reader of the values of the chunks of a column of
+// strings, read a page at a time. Dictionary encoded pages are not read, as
+// their values are those of the dictionary page of the chunk.
+func parquetValues(r io.ReaderAt, chunks []parquetChunk, levels [2]int) io.Reader {
+	var pages *thriftReader
+	var codec string
+	return &lineReader{next: func() ([][]byte, error) {
+		for {
+			if pages == nil {
+				if len(chunks) == 0 {
+					return nil, io.EOF
+				}
+				pages = &thriftReader{r: bufio.NewReader(io.NewSectionReader(r, chunks[0].offset, chunks[0].size))}
+				codec, chunks = chunks[0].codec, chunks[1:]
+			}
+			header, err := pages.readStruct()
+			if err == io.EOF {
+				pages = nil
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", errCorruptColumnar, err)
+			}
+			values, err := parquetPage(pages.r, codec, header, levels)
+			if err != nil || len(values) > 0 {
+				return values, err
+			}
+		}
+	}}
+}
+
+// parquetPage reads a page of a column of strings, and returns its values.
+func parquetPage(r io.Reader, codec string, header thriftFields, levels [2]int) ([][]byte, error) {
+	compressedSize, size := header.int(3), header.int(2)
+	if compressedSize < 0 || compressedSize > maxMetadataSize {
+		return nil, errCorruptColumnar
+	}
+	data := make([]byte, compressedSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	switch header.int(1) {
+	case parquetPageDictionary:
+		data, err := decompressColumnar(codec, data, int(size))
+		if err != nil {
+			return nil, err
+		}
+		return parquetPlainValues(data), nil
+	case parquetPageData:
+		data, err := decompressColumnar(codec, data, int(size))
+		if err != nil {
+			return nil, err
+		}
+		page := header.fields(5)
+		numValues := int(page.int(1))
+		// Pages start with their repetition then definition levels,
+		// for columns in repeated or optional fields.
+		for i, maxLevel := range levels {
+			if maxLevel == 0 {
+				continue
+			}
+			encoding := page.int(4)
+			if i == 1 {
+				encoding = page.int(3)
+			}
+			levelsSize := 0
+			if encoding == parquetEncodingBitPacked {
+				levelsSize = (numValues*bitWidth(uint64(maxLevel)) + 7) / 8
+			} else if len(data) >= 4 {
+				levelsSize = 4 + int(binary.LittleEndian.Uint32(data))
+			}
+			if levelsSize > len(data) || levelsSize < 0 {
+				return nil, errCorruptColumnar
+			}
+			data = data[levelsSize:]
+		}
+		return parquetDecodeValues(page.int(2), data)
+	case parquetPageDataV2:
+		page := header.fields(8)
+		levelsSize := page.int(5) + page.int(6)
+		if levelsSize < 0 || levelsSize > int64(len(data)) {
+			return nil, errCorruptColumnar
+		}
+		data = data[levelsSize:]
+		if compressed, ok := page[7].(bool); !ok || compressed {
+			var err error
+			if data, err = decompressColumnar(codec, data, int(size-levelsSize)); err != nil {
+				return nil, err
+			}
+		}
+		return parquetDecodeValues(page.int(4), data)
+	}
+	return nil, nil
+}
+
+// bitWidth returns the number of bits of max.
+func bitWidth(max uint64) int {
+	n := 0
+	for ; max > 0; max >>= 1 {
+		n++
+	}
+	return n
+}
+
+// parquetPlainValues decodes strings of the plain encoding, each after its
+// length.
+func parquetPlainValues(data []byte) [][]byte {
+	var values [][]byte
+	for len(data) >= 4 {
+		n := binary.LittleEndian.Uint32(data)
+		if uint64(n) > uint64(len(data)-4) {
+			break
+		}
+		values = append(values, data[4:4+n])
+		data = data[4+n:]
+	}
+	return values
+}
+
+// parquetDecodeValues decodes the strings of a data page. The values of
+// pages of unknown encodings are scanned as they are.
+func parquetDecodeValues(encoding int64, data []byte) ([][]byte, error) {
+	switch encoding {
+	case parquetEncodingPlain:
+		return parquetPlainValues(data), nil
+	case parquetEncodingPlainDictionary, parquetEncodingRLEDictionary, parquetEncodingRLE:
+		return nil, nil
+	case parquetEncodingDeltaLengthByteArray:
+		lengths, data, err := decodeDeltaBinaryPacked(data)
+		if err != nil {
+			return nil, err
+		}
+		var values [][]byte
+		for _, n := range lengths {
+			if n < 0 || n > int64(len(data)) {
+				return nil, errCorruptColumnar
+			}
+			values, data = append(values, data[:n]), data[n:]
+		}
+		return values, nil
+	case parquetEncodingDeltaByteArray:
+		prefixes, data, err := decodeDeltaBinaryPacked(data)
+		if err != nil {
+			return nil, err
+		}
+		suffixes, data, err := decodeDeltaBinaryPacked(data)
+		if err != nil || len(suffixes) != len(prefixes) {
+			return nil, errCorruptColumnar
+		}
+		var values [][]byte
+		var previous []byte
+		for i, n := range suffixes {
+			prefix := prefixes[i]
+			if n < 0 || n > int64(len(data)) || prefix < 0 || prefix > int64(len(previous)) {
+				return nil, errCorruptColumnar
+			}
+			value := append(append([]byte{}, previous[:prefix]...), data[:n]...)
+			values, data, previous = append(values, value), data[n:], value
+		}
+		return values, nil
+	}
+	return [][]byte{data}, nil
+}
+
+// decodeDeltaBinaryPacked decodes integers of the delta binary packed
+// encoding, like the lengths of strings, and returns them with the data
+// after them.
+func decodeDeltaBinaryPacked(data []byte) ([]int64, []byte, error) {
+	var header [3]uint64
+	for i := range header {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, nil, errCorruptColumnar
+		}
+		header[i], data = v, data[n:]
+	}
+	blockSize, miniblocks, total := header[0], header[1], header[2]
+	first, n := binary.Varint(data)
+	if n <= 0 || blockSize == 0 || miniblocks == 0 || blockSize%miniblocks != 0 || (blockSize/miniblocks)%8 != 0 || total > uint64(maxMetadataSize) {
+		return nil, nil, errCorruptColumnar
+	}
+	data = data[n:]
+	perMiniblock := int(blockSize / miniblocks)
+	values := make([]int64, 0, total)
+	if total > 0 {
+		values = append(values, first)
+	}
+	previous := uint64(first)
+	for uint64(len(values)) < total {
+		minDelta, n := binary.Varint(data)
+		if n <= 0 || len(data) < n+int(miniblocks) {
+			return nil, nil, errCorruptColumnar
+		}
+		widths := data[n : n+int(miniblocks)]
+		data = data[n+int(miniblocks):]
+		for _, width := range widths {
+			if uint64(len(values)) >= total {
+				break
+			}
+			size := perMiniblock * int(width) / 8
+			if width > 64 || size > len(data) {
+				return nil, nil, errCorruptColumnar
+			}
+			for i := 0; i < perMiniblock && uint64(len(values)) < total; i++ {
+				previous += uint64(minDelta) + unpackBitsLSB(data, i*int(width), int(width))
+				values = append(values, int64(previous))
+			}
+			data = data[size:]
+		}
+	}
+	return values, data, nil
+}
+
+// unpackBitsLSB reads width bits at bit offset of data, least significant
+// bits first.
+func unpackBitsLSB(data []byte, offset, width int) uint64 {
+	var v uint64
+	for i := 0; i < width; i++ {
+		bit := offset + i
+		if data[bit/8]>>(bit%8)&1 != 0 {
+			v |= 1 << i
+		}
+	}
+	return v
+}
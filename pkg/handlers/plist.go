@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	bplistMagic       = "bplist00"
+	bplistTrailerSize = 32
+	// bplistMaxDepth bounds the nesting of arrays and dictionaries, which
+	// also stops objects that contain themselves.
+	bplistMaxDepth = 64
+)
+
+var errCorruptPlist = errors.New("corrupt binary property list")
+
+// bplist is a binary property list, the format of the Info.plist and other
+// property lists of iOS apps.
+type bplist struct {
+	data    []byte
+	offsets []uint64
+	refSize int
+}
+
+// decodeBinaryPlist decodes a binary property list into the path and value
+// of each of its values, one per line, like
+// CFBundleURLTypes[0].CFBundleURLSchemes[0] = value.
+func decodeBinaryPlist(data []byte) ([]byte, error) {
+	if len(data) < len(bplistMagic)+bplistTrailerSize || !bytes.HasPrefix(data, []byte(bplistMagic)) {
+		return nil, errCorruptPlist
+	}
+	trailer := data[len(data)-bplistTrailerSize:]
+	offsetSize := int(trailer[6])
+	p := &bplist{data: data, refSize: int(trailer[7])}
+	count := binary.BigEndian.Uint64(trailer[8:])
+	top := binary.BigEndian.Uint64(trailer[16:])
+	tableOffset := binary.BigEndian.Uint64(trailer[24:])
+	if offsetSize < 1 || offsetSize > 8 || p.refSize < 1 || p.refSize > 8 ||
+		count > uint64(len(data)) || tableOffset > uint64(len(data)) || tableOffset+count*uint64(offsetSize) > uint64(len(data)) {
+		return nil, errCorruptPlist
+	}
+	p.offsets = make([]uint64, count)
+	for i := range p.offsets {
+		p.offsets[i] = bplistUint(data[tableOffset+uint64(i*offsetSize):], offsetSize)
+	}
+
+	var out strings.Builder
+	if err := p.write(&out, "", top, 0); err != nil {
+		return nil, err
+	}
+	return []byte(out.String()), nil
+}
+
+// object returns the marker and the rest of the object ref.
+func (p *bplist) object(ref uint64) (byte, []byte, error) {
+	if ref >= uint64(len(p.offsets)) || p.offsets[ref] >= uint64(len(p.data)) {
+		return 0, nil, errCorruptPlist
+	}
+	offset := p.offsets[ref]
+	return p.data[offset], p.data[offset+1:], nil
+}
+
+// write writes the values of the object ref, at path. The values of arrays
+// and dictionaries are written at the path of the index or key they are at.
+func (p *bplist) write(out *strings.Builder, path string, ref uint64, depth int) error {
+	if depth > bplistMaxDepth {
+		return errCorruptPlist
+	}
+	marker, body, err := p.object(ref)
+	if err != nil {
+		return err
+	}
+	kind := marker >> 4
+	if kind != 0xa && kind != 0xd {
+		value, ok, err := p.scalar(marker, body)
+		if ok {
+			out.WriteString(path + " = " + value + "\n")
+		}
+		return err
+	}
+
+	n, body, err := p.length(marker, body)
+	if err != nil {
+		return err
+	}
+	refs := n
+	if kind == 0xd {
+		refs *= 2
+	}
+	if n > uint64(len(body)) || refs*uint64(p.refSize) > uint64(len(body)) {
+		return errCorruptPlist
+	}
+	childRef := func(i uint64) uint64 { return bplistUint(body[i*uint64(p.refSize):], p.refSize) }
+	for i := uint64(0); i < n; i++ {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+		valueRef := childRef(i)
+		if kind == 0xd {
+			keyMarker, keyBody, err := p.object(childRef(i))
+			if err != nil {
+				return err
+			}
+			key, _, err := p.scalar(keyMarker, keyBody)
+			if err != nil {
+				return err
+			}
+			childPath = key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			valueRef = childRef(n + i)
+		}
+		if err := p.write(out, childPath, valueRef, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scalar returns the text of an object that is not an array or a
+// dictionary, and false for null and unknown objects.
+func (p *bplist) scalar(marker byte, body []byte) (string, bool, error) {
+	kind, info := marker>>4, int(marker&0xf)
+	switch kind {
+	case 0x0:
+		switch marker {
+		case 0x08:
+			return "false", true, nil
+		case 0x09:
+			return "true", true, nil
+		}
+	case 0x1, 0x8:
+		// Integers and UIDs.
+		size := 1 << info
+		if kind == 0x8 {
+			size = info + 1
+		}
+		if size > 8 || size > len(body) {
+			return "", false, errCorruptPlist
+		}
+		return strconv.FormatUint(bplistUint(body, size), 10), true, nil
+	case 0x2, 0x3:
+		// Reals, and dates, which are seconds since 2001.
+		size := 1 << info
+		if kind == 0x3 {
+			size = 8
+		}
+		switch {
+		case size == 4 && len(body) >= 4:
+			return strconv.FormatFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(body))), 'g', -1, 32), true, nil
+		case size == 8 && len(body) >= 8:
+			return strconv.FormatFloat(math.Float64frombits(binary.BigEndian.Uint64(body)), 'g', -1, 64), true, nil
+		}
+		return "", false, errCorruptPlist
+	case 0x4, 0x5, 0x6, 0x7:
+		n, body, err := p.length(marker, body)
+		if err != nil {
+			return "", false, err
+		}
+		if n > uint64(len(body)) {
+			return "", false, errCorruptPlist
+		}
+		if kind == 0x6 {
+			n *= 2
+		}
+		if n > uint64(len(body)) {
+			return "", false, errCorruptPlist
+		}
+		content := body[:n]
+		switch kind {
+		case 0x4:
+			// Data is scanned as text if it is, like nested property
+			// lists and certificates in PEM.
+			if utf8.Valid(content) {
+				return string(content), true, nil
+			}
+			return base64.StdEncoding.EncodeToString(content), true, nil
+		case 0x6:
+			units := make([]uint16, len(content)/2)
+			for i := range units {
+				units[i] = binary.BigEndian.Uint16(content[2*i:])
+			}
+			return string(utf16.Decode(units)), true, nil
+		}
+		return string(content), true, nil
+	}
+	return "", false, nil
+}
+
+// length returns the length of an object, in its marker or in the integer
+// after it, and the rest of the object.
+func (p *bplist) length(marker byte, body []byte) (uint64, []byte, error) {
+	if marker&0xf != 0xf {
+		return uint64(marker & 0xf), body, nil
+	}
+	if len(body) < 1 || body[0]>>4 != 0x1 {
+		return 0, nil, errCorruptPlist
+	}
+	size := 1 << (body[0] & 0xf)
+	if size > 8 || 1+size > len(body) {
+		return 0, nil, errCorruptPlist
+	}
+	return bplistUint(body[1:], size), body[1+size:], nil
+}
+
+// bplistUint reads a big-endian unsigned integer of size bytes.
+func bplistUint(b []byte, size int) uint64 {
+	var n uint64
+	for i := 0; i < size && i < len(b); i++ {
+		n = n<<8 | uint64(b[i])
+	}
+	return n
+}
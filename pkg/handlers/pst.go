@@ -0,0 +1,748 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf16"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	pstMagic     = "!BDN"
+	pstClientPST = "SM"
+	pstClientOST = "SO"
+	pstPageSize  = 512
+	// pstHeaderSize is the size of the header of Unicode files, which is
+	// larger than that of ANSI files.
+	pstHeaderSize = 564
+
+	pstCryptNone    = 0x00
+	pstCryptPermute = 0x01
+
+	pstPageBBT = 0x80
+	pstPageNBT = 0x81
+
+	pstBlockX  = 0x01
+	pstBlockSL = 0x02
+	pstHeapSig = 0xec
+	pstTypePC  = 0xbc
+	pstTypeBTH = 0xb5
+
+	pstNIDTypeMessage    = 0x04
+	pstNIDTypeAttachment = 0x05
+
+	pstTypeInteger32 = 0x0003
+	pstTypeObject    = 0x000d
+	pstTypeString8   = 0x001e
+	pstTypeString    = 0x001f
+	pstTypeBinary    = 0x0102
+)
+
+// The properties of messages and attachments read.
+const (
+	pstTagSubject               = 0x0037
+	pstTagSentRepresentingEmail = 0x0065
+	pstTagTransportHeaders      = 0x007d
+	pstTagSenderName            = 0x0c1a
+	pstTagSenderEmailAddress    = 0x0c1f
+	pstTagDisplayCc             = 0x0e03
+	pstTagDisplayTo             = 0x0e04
+	pstTagBody                  = 0x1000
+	pstTagHTML                  = 0x1013
+	pstTagInternetMessageID     = 0x1035
+	pstTagAttachDataBinary      = 0x3701
+	pstTagAttachFilename        = 0x3704
+	pstTagAttachLongFilename    = 0x3707
+	pstTagSenderSMTPAddress     = 0x5d01
+	pstTagSentRepresentingSMTP  = 0x5d02
+)
+
+var (
+	errCorruptPST = errors.New("corrupt PST file")
+	// errEncryptedPST is returned for PST files encrypted with the cyclic
+	// "high" encryption of old Outlook versions.
+	errEncryptedPST = errors.New("encrypted PST file")
+	// errUnsupportedPST is returned for the OST files of Outlook 2013 and
+	// later, which have 4 KiB pages and compressed blocks.
+	errUnsupportedPST = errors.New("unsupported PST file version")
+)
+
+// pstPermutation is the substitution of bytes of the "compressible"
+// encryption most PST files are encrypted with, and pstDecryption its
+// inverse.
+var (
+	pstPermutation = [256]byte{
+		65, 54, 19, 98, 168, 33, 110, 187, 244, 22, 204, 4, 127, 100, 232, 93,
+		30, 242, 203, 42, 116, 197, 94, 53, 210, 149, 71, 158, 150, 45, 154, 136,
+		76, 125, 132, 63, 219, 172, 49, 182, 72, 95, 246, 196, 216, 57, 139, 231,
+		35, 59, 56, 142, 200, 193, 223, 37, 177, 32, 165, 70, 96, 78, 156, 251,
+		170, 211, 86, 81, 69, 124, 85, 0, 7, 201, 43, 157, 133, 155, 9, 160,
+		143, 173, 179, 15, 99, 171, 137, 75, 215, 167, 21, 90, 113, 102, 66, 191,
+		38, 74, 107, 152, 250, 234, 119, 83, 178, 112, 5, 44, 253, 89, 58, 134,
+		126, 206, 6, 235, 130, 120, 87, 199, 141, 67, 175, 180, 28, 212, 91, 205,
+		226, 233, 39, 79, 195, 8, 114, 128, 207, 176, 239, 245, 40, 109, 190, 48,
+		77, 52, 146, 213, 14, 60, 34, 50, 229, 228, 249, 159, 194, 209, 10, 129,
+		18, 225, 238, 145, 131, 118, 227, 151, 230, 97, 138, 23, 121, 164, 183, 220,
+		144, 122, 92, 140, 2, 166, 202, 105, 222, 80, 26, 17, 147, 185, 82, 135,
+		88, 252, 237, 29, 55, 73, 27, 106, 224, 41, 51, 153, 189, 108, 217, 148,
+		243, 64, 84, 111, 240, 198, 115, 184, 214, 62, 101, 24, 68, 31, 221, 103,
+		16, 241, 12, 25, 236, 174, 3, 161, 20, 123, 169, 11, 255, 248, 163, 192,
+		162, 1, 247, 46, 188, 36, 104, 117, 13, 254, 186, 47, 181, 208, 218, 61,
+	}
+	pstDecryption = func() (decryption [256]byte) {
+		for i, b := range pstPermutation {
+			decryption[b] = byte(i)
+		}
+		return decryption
+	}()
+)
+
+// pstFile is an Outlook PST or OST file. Its nodes, like messages and
+// folders, are listed in the node B-tree, and their data is stored in blocks
+// listed in the block B-tree.
+type pstFile struct {
+	r       io.ReaderAt
+	unicode bool
+	crypt   byte
+	blocks  map[uint64]pstBlockRef
+	nodes   []pstNode
+}
+
+type pstBlockRef struct {
+	offset int64
+	size   int
+}
+
+// pstNode is a node or subnode: the block of its data and the block of the
+// B-tree of its subnodes.
+type pstNode struct {
+	nid  uint32
+	data uint64
+	sub  uint64
+}
+
+// openPST reads the B-trees of a PST or OST file in the Unicode format of
+// Outlook 2003 and later, or the ANSI format of earlier versions.
+func openPST(r io.ReaderAt, size int64) (*pstFile, error) {
+	header := make([]byte, pstHeaderSize)
+	if err := readAt(r, header, 0); err != nil {
+		return nil, err
+	}
+	if string(header[:4]) != pstMagic {
+		return nil, errCorruptPST
+	}
+	f := &pstFile{r: r, blocks: make(map[uint64]pstBlockRef)}
+	var nbt, bbt int64
+	switch version := binary.LittleEndian.Uint16(header[10:]); {
+	case version == 14 || version == 15:
+		nbt = int64(binary.LittleEndian.Uint32(header[188:]))
+		bbt = int64(binary.LittleEndian.Uint32(header[196:]))
+		f.crypt = header[461]
+	case version >= 23 && version != 36:
+		f.unicode = true
+		nbt = int64(binary.LittleEndian.Uint64(header[224:]))
+		bbt = int64(binary.LittleEndian.Uint64(header[240:]))
+		f.crypt = header[513]
+	default:
+		return nil, fmt.Errorf("%w: %d", errUnsupportedPST, version)
+	}
+	if f.crypt != pstCryptNone && f.crypt != pstCryptPermute {
+		return nil, errEncryptedPST
+	}
+	if nbt <= 0 || nbt+pstPageSize > size || bbt <= 0 || bbt+pstPageSize > size {
+		return nil, errCorruptPST
+	}
+
+	visited := make(map[int64]bool)
+	err := f.walkBTree(bbt, pstPageBBT, -1, visited, func(entry []byte) error {
+		bid, rest := f.id(entry)
+		offset, rest := f.id(rest)
+		if len(rest) < 2 {
+			return errCorruptPST
+		}
+		blockSize := int(binary.LittleEndian.Uint16(rest))
+		if int64(offset)+int64(blockSize) > size {
+			return errCorruptPST
+		}
+		f.blocks[bid&^1] = pstBlockRef{offset: int64(offset), size: blockSize}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = f.walkBTree(nbt, pstPageNBT, -1, visited, func(entry []byte) error {
+		nid, rest := f.id(entry)
+		data, rest := f.id(rest)
+		sub, _ := f.id(rest)
+		f.nodes = append(f.nodes, pstNode{nid: uint32(nid), data: data, sub: sub})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// idSize returns the size of the ids of blocks and nodes in the file.
+func (f *pstFile) idSize() int {
+	if f.unicode {
+		return 8
+	}
+	return 4
+}
+
+// id reads an id from the start of b, and returns it with the rest of b.
+func (f *pstFile) id(b []byte) (uint64, []byte) {
+	size := f.idSize()
+	if len(b) < size {
+		return 0, nil
+	}
+	if f.unicode {
+		return binary.LittleEndian.Uint64(b), b[size:]
+	}
+	return uint64(binary.LittleEndian.Uint32(b)), b[size:]
+}
+
+// walkBTree calls visit with each leaf entry of the node or block B-tree
+// with its root page at offset. Pages are visited once at most, and the
+// levels of pages decrease to the leaves, so corrupt files cannot loop.
+func (f *pstFile) walkBTree(offset int64, pageType byte, level int, visited map[int64]bool, visit func(entry []byte) error) error {
+	if visited[offset] {
+		return errCorruptPST
+	}
+	visited[offset] = true
+	page := make([]byte, pstPageSize)
+	if err := readAt(f.r, page, offset); err != nil {
+		return err
+	}
+	entriesSize, trailer := 496, 500
+	if f.unicode {
+		entriesSize, trailer = 488, 496
+	}
+	count, entrySize, pageLevel := int(page[entriesSize]), int(page[entriesSize+2]), int(page[entriesSize+3])
+	if page[trailer] != pageType || count*entrySize > entriesSize || entrySize < 3*f.idSize() || (level >= 0 && pageLevel != level) {
+		return errCorruptPST
+	}
+	for i := 0; i < count; i++ {
+		entry := page[i*entrySize : (i+1)*entrySize]
+		if pageLevel == 0 {
+			if err := visit(entry); err != nil {
+				return err
+			}
+			continue
+		}
+		child, _ := f.id(entry[2*f.idSize():])
+		if err := f.walkBTree(int64(child), pageType, pageLevel-1, visited, visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// block returns the content of a block, decrypted if it is a data block.
+func (f *pstFile) block(bid uint64) ([]byte, error) {
+	ref, ok := f.blocks[bid&^1]
+	if !ok {
+		return nil, errCorruptPST
+	}
+	data := make([]byte, ref.size)
+	if err := readAt(f.r, data, ref.offset); err != nil {
+		return nil, err
+	}
+	// The bit 1 of the ids of blocks is set for the internal blocks of
+	// trees of blocks and subnodes, which are not encrypted.
+	if bid&2 == 0 && f.crypt == pstCryptPermute {
+		for i, b := range data {
+			data[i] = pstDecryption[b]
+		}
+	}
+	return data, nil
+}
+
+// dataBlocks returns the ids of the data blocks of a node in order, from the
+// tree of XBLOCK and XXBLOCK blocks of nodes larger than a block.
+func (f *pstFile) dataBlocks(bid uint64) ([]uint64, error) {
+	if bid&2 == 0 {
+		return []uint64{bid}, nil
+	}
+	var blocks []uint64
+	var walk func(bid uint64, level int) error
+	walk = func(bid uint64, level int) error {
+		data, err := f.block(bid)
+		if err != nil {
+			return err
+		}
+		if len(data) < 8 || data[0] != pstBlockX || (level >= 0 && int(data[1]) != level) || data[1] < 1 || data[1] > 2 {
+			return errCorruptPST
+		}
+		blockLevel, count := int(data[1]), int(binary.LittleEndian.Uint16(data[2:]))
+		entries := data[8:]
+		if count*f.idSize() > len(entries) {
+			return errCorruptPST
+		}
+		for i := 0; i < count; i++ {
+			child, _ := f.id(entries[i*f.idSize():])
+			if blockLevel == 1 {
+				blocks = append(blocks, child)
+				continue
+			}
+			if err := walk(child, 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return blocks, walk(bid, -1)
+}
+
+// nodeReader returns a reader of the data of a node, reading its blocks as
+// they are needed.
+func (f *pstFile) nodeReader(bid uint64) (io.Reader, error) {
+	blocks, err := f.dataBlocks(bid)
+	if err != nil {
+		return nil, err
+	}
+	return &pstNodeReader{file: f, blocks: blocks}, nil
+}
+
+type pstNodeReader struct {
+	file   *pstFile
+	blocks []uint64
+	data   []byte
+}
+
+func (r *pstNodeReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		if len(r.blocks) == 0 {
+			return 0, io.EOF
+		}
+		data, err := r.file.block(r.blocks[0])
+		if err != nil {
+			return 0, err
+		}
+		r.data, r.blocks = data, r.blocks[1:]
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// subnodes returns the subnodes of a node from the SLBLOCK and SIBLOCK blocks
+// of their B-tree.
+func (f *pstFile) subnodes(bid uint64) (map[uint32]pstNode, error) {
+	subnodes := make(map[uint32]pstNode)
+	if bid == 0 {
+		return subnodes, nil
+	}
+	headerSize := 4
+	if f.unicode {
+		headerSize = 8
+	}
+	var walk func(bid uint64, level int) error
+	walk = func(bid uint64, level int) error {
+		data, err := f.block(bid)
+		if err != nil {
+			return err
+		}
+		if len(data) < headerSize || data[0] != pstBlockSL || (level >= 0 && int(data[1]) != level) || data[1] > 1 {
+			return errCorruptPST
+		}
+		blockLevel, count := int(data[1]), int(binary.LittleEndian.Uint16(data[2:]))
+		entrySize := 3 * f.idSize()
+		if blockLevel == 1 {
+			entrySize = 2 * f.idSize()
+		}
+		entries := data[headerSize:]
+		if count*entrySize > len(entries) {
+			return errCorruptPST
+		}
+		for i := 0; i < count; i++ {
+			nid, rest := f.id(entries[i*entrySize:])
+			child, rest := f.id(rest)
+			if blockLevel == 1 {
+				if err := walk(child, 0); err != nil {
+					return err
+				}
+				continue
+			}
+			sub, _ := f.id(rest)
+			subnodes[uint32(nid)] = pstNode{nid: uint32(nid), data: child, sub: sub}
+		}
+		return nil
+	}
+	return subnodes, walk(bid, -1)
+}
+
+// pstHeap is the heap-on-node the properties of a node are stored in, made
+// of the allocations listed in the page map of each of its blocks.
+type pstHeap struct {
+	blocks [][]byte
+	// clientType is the type of structure stored in the heap, and root
+	// the id of its allocation with the header of the structure.
+	clientType byte
+	root       uint32
+}
+
+// heap reads the heap-on-node of the data of a node.
+func (f *pstFile) heap(bid uint64) (*pstHeap, error) {
+	ids, err := f.dataBlocks(bid)
+	if err != nil {
+		return nil, err
+	}
+	h := &pstHeap{}
+	size := 0
+	for _, id := range ids {
+		block, err := f.block(id)
+		if err != nil {
+			return nil, err
+		}
+		if size += len(block); size > maxMetadataSize {
+			return nil, errCorruptPST
+		}
+		h.blocks = append(h.blocks, block)
+	}
+	if len(h.blocks) == 0 || len(h.blocks[0]) < 12 || h.blocks[0][2] != pstHeapSig {
+		return nil, errCorruptPST
+	}
+	h.clientType, h.root = h.blocks[0][3], binary.LittleEndian.Uint32(h.blocks[0][4:])
+	return h, nil
+}
+
+// alloc returns the allocation of the heap with id hid.
+func (h *pstHeap) alloc(hid uint32) ([]byte, error) {
+	index, blockIndex := int(hid>>5&0x7ff), int(hid>>16)
+	if hid&0x1f != 0 || index == 0 || blockIndex >= len(h.blocks) {
+		return nil, errCorruptPST
+	}
+	block := h.blocks[blockIndex]
+	if len(block) < 2 {
+		return nil, errCorruptPST
+	}
+	pageMap := int(binary.LittleEndian.Uint16(block))
+	if pageMap+4 > len(block) || index > int(binary.LittleEndian.Uint16(block[pageMap:])) || pageMap+4+2*(index+1) > len(block) {
+		return nil, errCorruptPST
+	}
+	offsets := block[pageMap+4:]
+	start, end := int(binary.LittleEndian.Uint16(offsets[2*(index-1):])), int(binary.LittleEndian.Uint16(offsets[2*index:]))
+	if start > end || end > len(block) {
+		return nil, errCorruptPST
+	}
+	return block[start:end], nil
+}
+
+// records calls visit with the key and data of each record of the B-tree
+// on the heap with its header at hid.
+func (h *pstHeap) records(hid uint32, visit func(key, data []byte) error) error {
+	header, err := h.alloc(hid)
+	if err != nil {
+		return err
+	}
+	if len(header) < 8 || header[0] != pstTypeBTH {
+		return errCorruptPST
+	}
+	keySize, dataSize, levels := int(header[1]), int(header[2]), int(header[3])
+	root := binary.LittleEndian.Uint32(header[4:])
+	if root == 0 {
+		return nil
+	}
+	visited := make(map[uint32]bool)
+	var walk func(hid uint32, level int) error
+	walk = func(hid uint32, level int) error {
+		if visited[hid] {
+			return errCorruptPST
+		}
+		visited[hid] = true
+		records, err := h.alloc(hid)
+		if err != nil {
+			return err
+		}
+		recordSize := keySize + dataSize
+		if level > 0 {
+			recordSize = keySize + 4
+		}
+		for ; len(records) >= recordSize && recordSize > 0; records = records[recordSize:] {
+			if level == 0 {
+				if err := visit(records[:keySize], records[keySize:recordSize]); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := walk(binary.LittleEndian.Uint32(records[keySize:]), level-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(root, levels)
+}
+
+// pstValue is the value of a property, stored in the heap of its node, or
+// in a subnode if it is large.
+type pstValue struct {
+	typ     uint16
+	data    []byte
+	subnode *pstNode
+}
+
+// properties reads the property context of a node, with the subnodes of the
+// node the values of its large properties are stored in.
+func (f *pstFile) properties(node pstNode, subnodes map[uint32]pstNode) (map[uint16]pstValue, error) {
+	h, err := f.heap(node.data)
+	if err != nil {
+		return nil, err
+	}
+	if h.clientType != pstTypePC {
+		return nil, errCorruptPST
+	}
+	properties := make(map[uint16]pstValue)
+	err = h.records(h.root, func(key, data []byte) error {
+		if len(key) != 2 || len(data) != 6 {
+			return errCorruptPST
+		}
+		tag, typ, hnid := binary.LittleEndian.Uint16(key), binary.LittleEndian.Uint16(data), binary.LittleEndian.Uint32(data[2:])
+		value := pstValue{typ: typ}
+		switch {
+		case typ == pstTypeInteger32 || typ == 0x0002 || typ == 0x0004 || typ == 0x000a || typ == 0x000b:
+			// Values of 4 bytes or less are stored in the record.
+			value.data = data[2:]
+		case hnid == 0:
+		case hnid&0x1f == 0:
+			if value.data, err = h.alloc(hnid); err != nil {
+				return err
+			}
+		default:
+			subnode, ok := subnodes[hnid]
+			if !ok {
+				return errCorruptPST
+			}
+			value.subnode = &subnode
+		}
+		properties[tag] = value
+		return nil
+	})
+	return properties, err
+}
+
+// reader returns a reader of a value, reading it from its subnode if it is
+// stored in one.
+func (f *pstFile) reader(value pstValue) (io.Reader, error) {
+	if value.subnode != nil {
+		return f.nodeReader(value.subnode.data)
+	}
+	return bytes.NewReader(value.data), nil
+}
+
+// text returns a string value as UTF-8. Large strings stored in subnodes are
+// read up to maxMetadataSize.
+func (f *pstFile) text(value pstValue) string {
+	if value.typ != pstTypeString && value.typ != pstTypeString8 {
+		return ""
+	}
+	reader, err := f.reader(value)
+	if err != nil {
+		return ""
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxMetadataSize))
+	if err != nil {
+		return ""
+	}
+	if value.typ == pstTypeString8 {
+		return string(data)
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// extractPST extracts the messages of an Outlook PST or OST file, named
+// after their Internet Message-ID and sender like messages of mailboxes,
+// or their node id if they were not sent over the Internet. Files are
+// spooled to a temporary file to read their B-trees.
+func (a *Archive) extractPST(ctx context.Context, depth int, path string, reader io.Reader, archiveChan chan Part) error {
+	seekableReader, cleanup, err := seekable(reader)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	r := seekableReader.(readerAtSeeker)
+	size, err := readerSize(r)
+	if err != nil {
+		return err
+	}
+	logger := logContext.AddLogger(ctx).Logger()
+	f, err := openPST(r, size)
+	if errors.Is(err, errEncryptedPST) {
+		logger.V(3).Info("Skipping encrypted PST file.")
+		a.reportSkip(ctx, path, sources.SkipReasonEncrypted, size)
+		return nil
+	}
+	if errors.Is(err, errUnsupportedPST) {
+		logger.V(3).Info("Skipping PST file of an unsupported version.", "error", err)
+		a.reportSkip(ctx, path, sources.SkipReasonUnsupported, size)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, node := range f.nodes {
+		if node.nid&0x1f != pstNIDTypeMessage {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := a.extractPSTMessage(ctx, depth, path, f, node, fmt.Sprintf("message %d", node.nid>>5), archiveChan)
+		if errors.Is(err, errCorruptPST) || errors.Is(err, io.ErrUnexpectedEOF) {
+			logger.V(2).Info("Error reading PST message.", "nid", node.nid, "error", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractPSTMessage extracts the header, bodies and attachments of a message
+// of a PST file, or of a message attached to one.
+func (a *Archive) extractPSTMessage(ctx context.Context, depth int, path string, f *pstFile, node pstNode, fallbackName string, archiveChan chan Part) error {
+	subnodes, err := f.subnodes(node.sub)
+	if err != nil {
+		return err
+	}
+	properties, err := f.properties(node, subnodes)
+	if err != nil {
+		return err
+	}
+	sender := ""
+	for _, tag := range []uint16{pstTagSenderSMTPAddress, pstTagSenderEmailAddress, pstTagSentRepresentingSMTP, pstTagSentRepresentingEmail, pstTagSenderName} {
+		if sender = f.text(properties[tag]); sender != "" {
+			break
+		}
+	}
+	id := f.text(properties[pstTagInternetMessageID])
+	if id == "" {
+		id = fallbackName
+	}
+	messagePath := memberPath(path, messageName(id, sender, 0))
+
+	// The header of messages sent over the Internet is kept, after the
+	// fields Outlook shows.
+	var header bytes.Buffer
+	for _, field := range []struct {
+		name string
+		tag  uint16
+	}{{"Subject", pstTagSubject}, {"From", pstTagSenderName}, {"To", pstTagDisplayTo}, {"Cc", pstTagDisplayCc}} {
+		if value := f.text(properties[field.tag]); value != "" {
+			fmt.Fprintf(&header, "%s: %s\n", field.name, value)
+		}
+	}
+	header.WriteString(f.text(properties[pstTagTransportHeaders]))
+	if err := sendParts(ctx, memberPath(messagePath, "headers"), &header, archiveChan); err != nil {
+		return err
+	}
+	for _, body := range []struct {
+		name string
+		tag  uint16
+	}{{"body.txt", pstTagBody}, {"body.html", pstTagHTML}} {
+		value, ok := properties[body.tag]
+		if !ok {
+			continue
+		}
+		var content io.Reader
+		if value.typ == pstTypeBinary {
+			if content, err = f.reader(value); err != nil {
+				return err
+			}
+		} else {
+			content = bytes.NewReader([]byte(f.text(value)))
+		}
+		bodyPath := memberPath(messagePath, body.name)
+		if err := sendParts(ctx, bodyPath, a.limitReader(ctx, bodyPath, content), archiveChan); err != nil {
+			return err
+		}
+	}
+
+	names := make(partNames)
+	for _, subnode := range sortedPSTNodes(subnodes) {
+		if subnode.nid&0x1f != pstNIDTypeAttachment {
+			continue
+		}
+		if err := a.extractPSTAttachment(ctx, depth, messagePath, f, subnode, names, archiveChan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractPSTAttachment extracts an attached file, or an attached message.
+func (a *Archive) extractPSTAttachment(ctx context.Context, depth int, messagePath string, f *pstFile, node pstNode, names partNames, archiveChan chan Part) error {
+	subnodes, err := f.subnodes(node.sub)
+	if err != nil {
+		return err
+	}
+	properties, err := f.properties(node, subnodes)
+	if err != nil {
+		return err
+	}
+	filename := attachmentName(f.text(properties[pstTagAttachLongFilename]), f.text(properties[pstTagAttachFilename]))
+	value, ok := properties[pstTagAttachDataBinary]
+	if !ok {
+		// Attachments by reference, like links to files, have no data.
+		return nil
+	}
+	if value.typ == pstTypeObject {
+		// Attached messages are stored in a subnode of the attachment,
+		// whose id starts the value.
+		embedded := value.subnode
+		if embedded == nil && len(value.data) >= 4 {
+			if subnode, ok := subnodes[binary.LittleEndian.Uint32(value.data)]; ok {
+				embedded = &subnode
+			}
+		}
+		if embedded == nil {
+			return errCorruptPST
+		}
+		if filename == "" {
+			filename = "message"
+		}
+		if depth+1 >= a.options().MaxDepth {
+			a.reportSkip(ctx, memberPath(messagePath, filename), sources.SkipReasonDepth, 0)
+			return errMaxArchiveDepthReached
+		}
+		return a.extractPSTMessage(ctx, depth+1, messagePath, f, *embedded, names.unique(filename), archiveChan)
+	}
+	if filename == "" {
+		filename = "attachment"
+	}
+	content, err := f.reader(value)
+	if err != nil {
+		return err
+	}
+	name := memberPath(messagePath, names.unique(filename))
+	err = a.openArchive(ctx, depth+1, name, a.limitReader(ctx, name, content), archiveChan)
+	if errors.Is(err, errMaxArchiveDepthReached) {
+		a.reportSkip(ctx, name, sources.SkipReasonDepth, 0)
+	}
+	return err
+}
+
+// sortedPSTNodes returns subnodes in the order of their ids, which is the
+// order attachments were added in.
+func sortedPSTNodes(subnodes map[uint32]pstNode) []pstNode {
+	nodes := make([]pstNode, 0, len(subnodes))
+	for _, node := range subnodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].nid < nodes[j].nid })
+	return nodes
+}
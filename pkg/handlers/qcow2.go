@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	qcow2Magic      = "QFI\xfb"
+	qcow2HeaderSize = 105
+	// qcow2OffsetMask masks the offsets of table entries, and the flags of
+	// entries of compressed clusters and clusters that read as zeros.
+	qcow2OffsetMask     = 0x00fffffffffffe00
+	qcow2FlagCompressed = 1 << 62
+	qcow2FlagZero       = 1
+	// qcow2ExtendedL2 and qcow2ExternalData are the incompatible features of
+	// images with subclusters, and with their data in another file.
+	qcow2ExternalData     = 1 << 2
+	qcow2ExtendedL2       = 1 << 4
+	qcow2CompressionZstd  = 1
+	qcow2MinClusterBits   = 9
+	qcow2MaxClusterBits   = 21
+	qcow2MaxL1Size        = 1 << 25
+	qcow2DefaultHeaderLen = 72
+)
+
+var errEncryptedDiskImage = errors.New("encrypted disk image")
+
+// openQCOW2 returns the disk of a QCOW2 image. Clusters in the backing file
+// of an image read as zeros.
+func openQCOW2(r io.ReaderAt, size int64) (*virtualDisk, error) {
+	header := make([]byte, qcow2HeaderSize)
+	if err := readAt(r, header[:qcow2DefaultHeaderLen], 0); err != nil {
+		return nil, err
+	}
+	if string(header[:4]) != qcow2Magic {
+		return nil, errCorruptDiskImage
+	}
+	version := binary.BigEndian.Uint32(header[4:])
+	clusterBits := binary.BigEndian.Uint32(header[20:])
+	diskSize := int64(binary.BigEndian.Uint64(header[24:]))
+	l1Size := int64(binary.BigEndian.Uint32(header[36:]))
+	l1Offset := int64(binary.BigEndian.Uint64(header[40:]))
+	if clusterBits < qcow2MinClusterBits || clusterBits > qcow2MaxClusterBits || l1Size > qcow2MaxL1Size || l1Size*8 > size {
+		return nil, errCorruptDiskImage
+	}
+	if binary.BigEndian.Uint32(header[32:]) != 0 {
+		return nil, errEncryptedDiskImage
+	}
+	zstdCompressed := false
+	if version >= 3 {
+		if err := readAt(r, header[qcow2DefaultHeaderLen:], qcow2DefaultHeaderLen); err != nil {
+			return nil, err
+		}
+		features := binary.BigEndian.Uint64(header[72:])
+		if features&(qcow2ExternalData|qcow2ExtendedL2) != 0 {
+			return nil, errCorruptDiskImage
+		}
+		zstdCompressed = binary.BigEndian.Uint32(header[100:]) > 104 && header[104] == qcow2CompressionZstd
+	}
+
+	clusterSize := int64(1) << clusterBits
+	l1 := make([]byte, l1Size*8)
+	if err := readAt(r, l1, l1Offset); err != nil {
+		return nil, err
+	}
+	// L2 tables, of a cluster each, are read as needed.
+	l2Entries := clusterSize / 8
+	l2 := make([]byte, clusterSize)
+	l2Loaded := int64(-1)
+
+	return &virtualDisk{
+		size:       diskSize,
+		blockSize:  clusterSize,
+		sectorSize: diskSectorSize,
+		block: func(n int64) (io.ReaderAt, error) {
+			index := n / l2Entries
+			if index >= l1Size {
+				return nil, nil
+			}
+			l2Offset := int64(binary.BigEndian.Uint64(l1[index*8:]) & qcow2OffsetMask)
+			if l2Offset == 0 {
+				return nil, nil
+			}
+			if l2Loaded != index {
+				if err := readAt(r, l2, l2Offset); err != nil {
+					return nil, err
+				}
+				l2Loaded = index
+			}
+			entry := binary.BigEndian.Uint64(l2[(n%l2Entries)*8:])
+			if entry&qcow2FlagCompressed == 0 {
+				offset := int64(entry & qcow2OffsetMask)
+				if offset == 0 || entry&qcow2FlagZero != 0 {
+					return nil, nil
+				}
+				return io.NewSectionReader(r, offset, clusterSize), nil
+			}
+
+			// Compressed clusters are a number of sectors from an
+			// offset, whose bits depend on the cluster size.
+			offsetBits := 62 - (clusterBits - 8)
+			offset := int64(entry & (1<<offsetBits - 1))
+			sectors := int64((entry>>offsetBits)&(1<<(clusterBits-8)-1)) + 1
+			compressed := io.NewSectionReader(r, offset, sectors*diskSectorSize-offset%diskSectorSize)
+			var data []byte
+			var err error
+			if zstdCompressed {
+				var zr *zstd.Decoder
+				if zr, err = zstd.NewReader(compressed); err != nil {
+					return nil, err
+				}
+				defer zr.Close()
+				data, err = io.ReadAll(io.LimitReader(zr, clusterSize))
+			} else {
+				fr := flate.NewReader(compressed)
+				defer fr.Close()
+				data, err = io.ReadAll(io.LimitReader(fr, clusterSize))
+			}
+			if err != nil {
+				return nil, err
+			}
+			return bytes.NewReader(data), nil
+		},
+	}, nil
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/klauspost/compress/zip"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// compressionRatioGrace is the decompressed size of a file up to which its
+// compression ratio is not checked, as small files of repeated content are
+// legitimately very compressible.
+const compressionRatioGrace = 1024 * 1024
+
+var errCompressionRatioExceeded = errors.New("max compression ratio exceeded")
+
+// ratioLimitedReader ends the extraction of an archive with
+// errCompressionRatioExceeded when the content of a file read from it grows
+// past the maximum compression ratio of the compressed bytes it is read from.
+type ratioLimitedReader struct {
+	ctx      context.Context
+	archive  *Archive
+	name     string
+	reader   io.Reader
+	maxRatio float64
+	// compressed returns the number of compressed bytes read so far, or the
+	// compressed size of the file if that is all that is known.
+	compressed   func() int64
+	decompressed int64
+}
+
+// ratioLimitReader returns a reader of the content of a file decompressed from
+// an archive, which fails when the file decompresses to more than the maximum
+// compression ratio times the compressed bytes it was read from.
+func (a *Archive) ratioLimitReader(ctx context.Context, name string, reader io.Reader, compressed func() int64) io.Reader {
+	maxRatio := a.options().MaxCompressionRatio
+	if maxRatio < 0 {
+		return reader
+	}
+	return &ratioLimitedReader{ctx: ctx, archive: a, name: name, reader: reader, maxRatio: maxRatio, compressed: compressed}
+}
+
+func (r *ratioLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.decompressed += int64(n)
+	if r.decompressed > compressionRatioGrace && float64(r.decompressed) > r.maxRatio*float64(r.compressed()) {
+		logContext.AddLogger(r.ctx).Logger().V(2).Info("Max compression ratio exceeded, abandoning archive.",
+			"filename", r.name, "compressed", r.compressed(), "decompressed", r.decompressed)
+		r.archive.reportSkip(r.ctx, r.name, sources.SkipReasonCompressionRatio, 0)
+		return n, errCompressionRatioExceeded
+	}
+	return n, err
+}
+
+// ratioLimitZipFile returns a reader of the content of a file of a zip archive
+// limited by the maximum compression ratio of its compressed size.
+func (a *Archive) ratioLimitZipFile(ctx context.Context, name string, hdr zip.FileHeader, reader io.Reader) io.Reader {
+	if hdr.Method == zip.Store {
+		return reader
+	}
+	compressed := int64(hdr.CompressedSize64)
+	return a.ratioLimitReader(ctx, name, reader, func() int64 { return compressed })
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/mholt/archiver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// makeZipBomb returns a zip archive of content deflated as much as it can be,
+// like the files of zip bombs.
+func makeZipBomb(t *testing.T, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	w.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, flate.BestCompression)
+	})
+	f, err := w.Create("bomb.bin")
+	require.NoError(t, err)
+	_, err = f.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestHandleFile_CompressionRatio(t *testing.T) {
+	zeros := make([]byte, 8*1024*1024)
+	var xz bytes.Buffer
+	w, err := archiver.Xz{}.OpenWriter(&xz)
+	require.NoError(t, err)
+	_, err = w.Write(zeros)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	// Logs compress far less than zeros.
+	log := bytes.Repeat([]byte("GET /healthz 200\n"), 200000)
+
+	tests := []struct {
+		name    string
+		archive []byte
+		ratio   float64
+		want    int
+	}{
+		{name: "deflated zeros", archive: makeZipBomb(t, zeros)},
+		{name: "xz zeros", archive: xz.Bytes()},
+		{name: "check off", archive: makeZipBomb(t, zeros), ratio: -1, want: len(zeros)},
+		{name: "repetitive log", archive: makeTarGz(t, map[string][]byte{"app.log": log}), want: len(log)},
+	}
+	for _, tt := range tests {
+		ch := make(chan *sources.Chunk, 1024)
+		HandleFile(context.Background(), bytes.NewReader(tt.archive), &sources.Chunk{}, ch,
+			WithArchiveOptions(sources.ArchiveOptions{MaxCompressionRatio: tt.ratio}))
+		close(ch)
+
+		// Chunks overlap the start of the next one.
+		var size int
+		for chunk := range ch {
+			size = int(chunk.Offset) + len(chunk.Data)
+		}
+		// Files of zip archives are stopped once they reach the ratio
+		// of their compressed size, and compressed streams once they
+		// exceed it.
+		if tt.want == 0 {
+			assert.Less(t, size, len(zeros), tt.name)
+			continue
+		}
+		assert.Equal(t, tt.want, size, tt.name)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// matcherPeekSize is how much of the start of a file matchers are given.
+const matcherPeekSize = 4096
+
+// Matcher reports whether a file is of the format of a registered handler,
+// from its name, which is empty if the source has none, and the start of its
+// content, which is shorter than the file if the file is large.
+type Matcher func(name string, header []byte) bool
+
+type registration struct {
+	matcher Matcher
+	handler SpecializedHandler
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []registration
+)
+
+// Register adds a handler of the files matcher matches, for formats the
+// built-in handlers do not read, like proprietary backups or game assets.
+// HandleFile calls the HandleSpecialized method of the handler of the first
+// registered matcher that matches a file, before the built-in handlers. The
+// reader it returns is handled like the files of archives: it is extracted if
+// it is an archive, e.g. a tar stream of the files of a backup, and scanned as
+// it is otherwise. If the handler returns false, or an error, the file is not
+// handled by it, and it must then return a reader of the whole file.
+//
+// Handlers are only called on the files sources scan, not on the files of
+// archives. Register is meant to be called before scanning, e.g. in init.
+func Register(matcher Matcher, handler SpecializedHandler) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, registration{matcher: matcher, handler: handler})
+}
+
+func registeredHandlers() []Handler {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	handlers := make([]Handler, 0, len(registry))
+	for _, r := range registry {
+		handlers = append(handlers, &registeredHandler{registration: r, archive: &Archive{}})
+	}
+	return handlers
+}
+
+// Ensure the registeredHandler satisfies the interfaces at compile time.
+var _ SpecializedHandler = (*registeredHandler)(nil)
+
+// registeredHandler is a Handler of a registered handler, whose readers are
+// extracted by an archive handler.
+type registeredHandler struct {
+	registration
+	archive *Archive
+}
+
+func (h *registeredHandler) New() {
+	h.archive.New()
+}
+
+// HandleSpecialized calls the registered handler if the matcher matches the
+// file.
+func (h *registeredHandler) HandleSpecialized(ctx context.Context, reader io.Reader) (io.Reader, bool, error) {
+	header := make([]byte, matcherPeekSize)
+	n, err := io.ReadFull(reader, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, false, fmt.Errorf("unable to read file to match it: %w", err)
+	}
+	// Rewind seekable readers, which archives like zip need to be extracted,
+	// or read the header again before the rest of the file.
+	if seeker, ok := reader.(io.Seeker); ok {
+		if _, err := seeker.Seek(-int64(n), io.SeekCurrent); err != nil {
+			return nil, false, fmt.Errorf("unable to rewind file after matching it: %w", err)
+		}
+	} else {
+		reader = io.MultiReader(bytes.NewReader(header[:n]), reader)
+	}
+	if !h.matcher(h.archive.name, header[:n]) {
+		return reader, false, nil
+	}
+	return h.handler.HandleSpecialized(ctx, reader)
+}
+
+func (h *registeredHandler) FromFile(ctx context.Context, reader io.Reader) chan Part {
+	return h.archive.FromFile(ctx, reader)
+}
+
+// IsFiletype is false, as registered handlers only handle the files their
+// matcher matches.
+func (h *registeredHandler) IsFiletype(_ context.Context, reader io.Reader) (io.Reader, bool) {
+	return reader, false
+}
+
+// configure sets the options and name of the archive handler of a handler
+// of a file, if it has one.
+func configure(h Handler, opts sources.ArchiveOptions, name string) {
+	archive, ok := h.(*Archive)
+	if registered, isRegistered := h.(*registeredHandler); isRegistered {
+		archive, ok = registered.archive, true
+	}
+	if ok {
+		archive.opts = opts
+		archive.name = name
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// backupHandler reads a made up backup format: a magic number, then files
+// named on a line before their content, all XORed with 0x5a.
+type backupHandler struct {
+	t *testing.T
+}
+
+func (h backupHandler) HandleSpecialized(_ context.Context, reader io.Reader) (io.Reader, bool, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, err
+	}
+	files := make(map[string][]byte)
+	lines := strings.Split(string(xor(bytes.TrimPrefix(data, []byte("BAK1")))), "\n")
+	for i := 0; i+1 < len(lines); i += 2 {
+		files[lines[i]] = []byte(lines[i+1] + "\n")
+	}
+	return bytes.NewReader(makeTarGz(h.t, files)), true, nil
+}
+
+func xor(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ 0x5a
+	}
+	return out
+}
+
+func TestRegister(t *testing.T) {
+	saved := registry
+	t.Cleanup(func() { registry = saved })
+
+	var names []string
+	Register(func(name string, header []byte) bool {
+		names = append(names, name)
+		return bytes.HasPrefix(header, []byte("BAK1"))
+	}, backupHandler{t: t})
+
+	handle := func(name string, data []byte) map[string]string {
+		skel := &sources.Chunk{
+			SourceMetadata: &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: name}},
+			},
+		}
+		ch := make(chan *sources.Chunk, 16)
+		HandleFile(context.Background(), bytes.NewReader(data), skel, ch)
+		close(ch)
+		files := make(map[string]string)
+		for chunk := range ch {
+			files[chunk.SourceMetadata.GetFilesystem().GetArchivePath()] += string(chunk.Data)
+		}
+		return files
+	}
+
+	backup := append([]byte("BAK1"), xor([]byte("etc/app.env\ntoken = backup\nREADME\nhello\n"))...)
+	assert.Equal(t, map[string]string{"etc/app.env": "token = backup\n", "README": "hello\n"}, handle("app.bak", backup))
+
+	// Other files are handled by the built-in handlers.
+	zipped := makeZip(t, map[string][]byte{"config.env": []byte("token = zip\n")})
+	assert.Equal(t, map[string]string{"config.env": "token = zip\n"}, handle("app.zip", zipped))
+	assert.Equal(t, []string{"app.bak", "app.zip"}, names)
+}
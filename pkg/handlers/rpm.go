@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mholt/archiver/v4"
+)
+
+const (
+	rpmLeadSize     = 96
+	rpmHeaderMagic  = "\x8e\xad\xe8"
+	rpmHeaderPrefix = 16
+)
+
+// rpmPayload returns the decompressed payload of an RPM package, a cpio
+// archive of the files of the package. RPM packages are a lead, a signature
+// header padded to 8 bytes, a header, and the compressed payload.
+// The caller is responsible for closing the returned reader.
+func rpmPayload(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	lead := make([]byte, rpmLeadSize)
+	if _, err := io.ReadFull(br, lead); err != nil {
+		return nil, fmt.Errorf("unable to read rpm lead: %w", err)
+	}
+	if !bytes.HasPrefix(lead, []byte{0xed, 0xab, 0xee, 0xdb}) {
+		return nil, errors.New("not an rpm package")
+	}
+
+	offset := int64(rpmLeadSize)
+	for _, pad := range []bool{true, false} {
+		size, err := skipRPMHeader(br)
+		if err != nil {
+			return nil, err
+		}
+		offset += size
+		if pad && offset%8 != 0 {
+			padding := 8 - offset%8
+			if _, err := br.Discard(int(padding)); err != nil {
+				return nil, fmt.Errorf("unable to read rpm header: %w", err)
+			}
+			offset += padding
+		}
+	}
+
+	format, payload, err := archiver.Identify("", br)
+	if err != nil {
+		return nil, fmt.Errorf("unable to identify rpm payload compression: %w", err)
+	}
+	decompressor, ok := format.(archiver.Decompressor)
+	if !ok {
+		return nil, fmt.Errorf("unsupported rpm payload format %s", format.Name())
+	}
+	return decompressor.OpenReader(payload)
+}
+
+// skipRPMHeader skips a header structure of an RPM package and returns its
+// size. Headers are a magic, the number of index entries and the size of the
+// data, followed by 16 byte index entries and the data.
+func skipRPMHeader(r *bufio.Reader) (int64, error) {
+	prefix := make([]byte, rpmHeaderPrefix)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return 0, fmt.Errorf("unable to read rpm header: %w", err)
+	}
+	if string(prefix[:3]) != rpmHeaderMagic {
+		return 0, errors.New("invalid rpm header")
+	}
+	entries := int64(binary.BigEndian.Uint32(prefix[8:12]))
+	dataSize := int64(binary.BigEndian.Uint32(prefix[12:16]))
+	size := entries*16 + dataSize
+	if _, err := io.CopyN(io.Discard, r, size); err != nil {
+		return 0, fmt.Errorf("unable to read rpm header: %w", err)
+	}
+	return rpmHeaderPrefix + size, nil
+}
+
+const (
+	cpioHeaderSize = 110
+	cpioTrailer    = "TRAILER!!!"
+	// cpioTypeMask and cpioTypeRegular are the file type bits of the mode of
+	// cpio entries.
+	cpioTypeMask    = 0o170000
+	cpioTypeRegular = 0o100000
+)
+
+// cpioReader reads the entries of a cpio archive in the new ASCII format RPM
+// payloads use, in order, like archive/tar.
+type cpioReader struct {
+	r *bufio.Reader
+	// remaining is the unread content of the current entry, and padding
+	// the bytes that align the next header to 4 bytes.
+	remaining, padding int64
+}
+
+// cpioHeader is the header of an entry of a cpio archive.
+type cpioHeader struct {
+	Name    string
+	Size    int64
+	Regular bool
+}
+
+func newCPIOReader(r io.Reader) *cpioReader {
+	return &cpioReader{r: bufio.NewReader(r)}
+}
+
+// Next advances to the next entry of the archive, whose content is then read
+// by Read. It returns io.EOF at the end of the archive.
+func (c *cpioReader) Next() (*cpioHeader, error) {
+	if _, err := io.CopyN(io.Discard, c.r, c.remaining+c.padding); err != nil {
+		return nil, err
+	}
+	c.remaining, c.padding = 0, 0
+
+	header := make([]byte, cpioHeaderSize)
+	if _, err := io.ReadFull(c.r, header); err != nil {
+		return nil, err
+	}
+	// 070701 is the new ASCII format, 070702 the same with checksums.
+	if magic := string(header[:6]); magic != "070701" && magic != "070702" {
+		return nil, fmt.Errorf("unsupported cpio format %q", magic)
+	}
+	field := func(i int) (int64, error) {
+		return strconv.ParseInt(string(header[6+8*i:14+8*i]), 16, 64)
+	}
+	mode, err := field(1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cpio header: %w", err)
+	}
+	size, err := field(6)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cpio header: %w", err)
+	}
+	nameSize, err := field(11)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cpio header: %w", err)
+	}
+
+	// The name is NUL terminated, and padded so the header and name are a
+	// multiple of 4 bytes.
+	name := make([]byte, nameSize+pad4(cpioHeaderSize+nameSize))
+	if _, err := io.ReadFull(c.r, name); err != nil {
+		return nil, fmt.Errorf("truncated cpio entry name: %w", err)
+	}
+	hdr := &cpioHeader{
+		Name:    strings.TrimPrefix(strings.TrimRight(string(name[:nameSize]), "\x00"), "./"),
+		Size:    size,
+		Regular: mode&cpioTypeMask == cpioTypeRegular,
+	}
+	if hdr.Name == cpioTrailer {
+		return nil, io.EOF
+	}
+	c.remaining, c.padding = size, pad4(size)
+	return hdr, nil
+}
+
+// Read reads the content of the current entry.
+func (c *cpioReader) Read(p []byte) (int, error) {
+	if c.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	if errors.Is(err, io.EOF) && c.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// pad4 returns the number of bytes that pad n to a multiple of 4.
+func pad4(n int64) int64 {
+	return (4 - n%4) % 4
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+
+	"github.com/bodgit/sevenzip"
+	"github.com/mholt/archiver/v4"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// extractSevenZip calls handleFile with each file of a 7z archive, like
+// archiver.SevenZip.Extract, but opens encrypted archives with the first of
+// the archive passwords that decrypts them. Encrypted archives no password
+// decrypts are skipped.
+func (a *Archive) extractSevenZip(ctx context.Context, r readerAtSeeker, handleFile archiver.FileHandler) error {
+	size, err := readerSize(r)
+	if err != nil {
+		return fmt.Errorf("determining 7z size: %w", err)
+	}
+	zr, err := openSevenZip(r, size, a.options())
+	if errors.Is(err, errWrongPassword) {
+		name, _ := ctx.Value(pathKey).(string)
+		a.reportSkip(ctx, name, sources.SkipReasonEncrypted, size)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Files of 7z archives may be read concurrently, even in solid blocks.
+	return a.forEachEntry(ctx, len(zr.File), func(ctx context.Context, i int) error {
+		f := zr.File[i]
+		file := archiver.File{
+			FileInfo:      f.FileInfo(),
+			Header:        f.FileHeader,
+			NameInArchive: f.Name,
+			Open:          f.Open,
+		}
+		if err := handleFile(ctx, file); err != nil {
+			return fmt.Errorf("handling file %d: %s: %w", i, f.Name, err)
+		}
+		return nil
+	})
+}
+
+// sevenZipAESCoder is the ID of the AES-256 + SHA-256 coder of encrypted 7z
+// archives.
+var sevenZipAESCoder = []byte{0x06, 0xf1, 0x07, 0x01}
+
+// openSevenZip opens a 7z archive, with the first archive password that
+// decrypts it if it is encrypted. Either its header or only the content of its
+// files may be encrypted.
+func openSevenZip(r io.ReaderAt, size int64, opts sources.ArchiveOptions) (*sevenzip.Reader, error) {
+	if !isSevenZipEncrypted(r) {
+		return sevenzip.NewReader(r, size)
+	}
+	for _, password := range opts.Passwords {
+		zr, err := sevenzip.NewReaderWithPassword(r, size, password)
+		if err != nil {
+			continue
+		}
+		if checkSevenZipPassword(zr, opts.MaxSize) {
+			return zr, nil
+		}
+	}
+	return nil, errWrongPassword
+}
+
+// isSevenZipEncrypted reports whether a 7z archive is encrypted, from the
+// coders listed in its header. The header of archives with an encrypted
+// header is an encoded header, whose coders are not encrypted.
+func isSevenZipEncrypted(r io.ReaderAt) bool {
+	// The signature header is the signature, the version, a CRC, and the
+	// offset and size of the header after the signature header.
+	signature := make([]byte, 32)
+	if _, err := r.ReadAt(signature, 0); err != nil {
+		return false
+	}
+	offset := binary.LittleEndian.Uint64(signature[12:20])
+	size := binary.LittleEndian.Uint64(signature[20:28])
+	if size > sevenZipMaxHeaderSize || offset > math.MaxInt64-32 {
+		return false
+	}
+	header := make([]byte, size)
+	if _, err := r.ReadAt(header, int64(offset)+32); err != nil {
+		return false
+	}
+	return bytes.Contains(header, sevenZipAESCoder)
+}
+
+// sevenZipMaxHeaderSize is the size of the largest 7z header read to find
+// whether an archive is encrypted.
+const sevenZipMaxHeaderSize = 16 * 1024 * 1024
+
+// checkSevenZipPassword reports whether the password an encrypted archive was
+// opened with decrypts it, from the checksum of its first file, read up to
+// maxSize. Wrong passwords decrypt the header of archives whose content only
+// is encrypted.
+func checkSevenZipPassword(zr *sevenzip.Reader, maxSize int) bool {
+	for _, f := range zr.File {
+		if f.UncompressedSize == 0 {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return false
+		}
+		defer rc.Close()
+
+		hash := crc32.NewIEEE()
+		n, err := io.Copy(hash, io.LimitReader(rc, int64(maxSize)))
+		if err != nil {
+			return false
+		}
+		return f.CRC32 == 0 || uint64(n) != f.UncompressedSize || hash.Sum32() == f.CRC32
+	}
+	return true
+}
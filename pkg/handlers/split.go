@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nwaples/rardecode/v2"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// rarSignature starts RAR archives of all versions, and each volume of
+// multi-volume ones.
+const rarSignature = "Rar!\x1a\x07"
+
+var (
+	// splitPartPattern matches the names of the parts of files split by
+	// 7-Zip or split, e.g. backup.tar.gz.001.
+	splitPartPattern = regexp.MustCompile(`^(.+\.[A-Za-z][A-Za-z0-9]*)\.(\d{3,})$`)
+	// rarPartPattern and rarOldPartPattern match the names of the volumes
+	// of RAR archives, e.g. app.part1.rar, and app.r00 after app.rar in the
+	// older naming.
+	rarPartPattern    = regexp.MustCompile(`(?i)^(.+)\.part(\d+)\.rar$`)
+	rarOldPartPattern = regexp.MustCompile(`(?i)^(.+)\.r(\d{2})$`)
+	rarPattern        = regexp.MustCompile(`(?i)^.+\.rar$`)
+)
+
+// SplitPart returns the name of the file a file is a part of, and the number
+// of the part, from the name of the file: backup.zip.001 and backup.zip.002
+// are parts 1 and 2 of backup.zip, app.part1.rar is part 1 of app.rar, and
+// app.rar, app.r00 and app.r01 are parts 0, 1 and 2 of app.rar in the older
+// naming of RAR volumes. It returns false if the name is not that of a part,
+// though files named like parts can be whole, like most RAR archives. The
+// parts of a file are scanned together with HandleSplitFile, in the order of
+// their numbers.
+func SplitPart(name string) (whole string, number int, ok bool) {
+	if m := rarPartPattern.FindStringSubmatch(name); m != nil {
+		number, _ = strconv.Atoi(m[2])
+		return m[1] + ".rar", number, true
+	}
+	if m := rarOldPartPattern.FindStringSubmatch(name); m != nil {
+		number, _ = strconv.Atoi(m[2])
+		return m[1] + ".rar", number + 1, true
+	}
+	if rarPattern.MatchString(name) {
+		return name, 0, true
+	}
+	if m := splitPartPattern.FindStringSubmatch(name); m != nil {
+		var err error
+		if number, err = strconv.Atoi(m[2]); err == nil {
+			return m[1], number, true
+		}
+	}
+	return "", 0, false
+}
+
+// SplitFiles returns the names among names of the parts of split files, part
+// by part, grouped by the file they are parts of: the files in the same
+// directory, that is with the same path before their last slash, and the
+// same name without their part number. Files of one part, or whose numbers
+// do not follow each other, are left out.
+func SplitFiles(names []string) [][]string {
+	type part struct {
+		name   string
+		number int
+	}
+	files := make(map[string][]part)
+	var wholes []string
+	for _, name := range names {
+		dir, base := path.Split(name)
+		whole, number, ok := SplitPart(base)
+		if !ok {
+			continue
+		}
+		if _, seen := files[dir+whole]; !seen {
+			wholes = append(wholes, dir+whole)
+		}
+		files[dir+whole] = append(files[dir+whole], part{name: name, number: number})
+	}
+	var split [][]string
+	for _, whole := range wholes {
+		parts := files[whole]
+		if len(parts) < 2 {
+			continue
+		}
+		sort.Slice(parts, func(i, j int) bool { return parts[i].number < parts[j].number })
+		names := make([]string, len(parts))
+		for i, p := range parts {
+			if i > 0 && p.number != parts[i-1].number+1 {
+				names = nil
+				break
+			}
+			names[i] = p.name
+		}
+		if names != nil {
+			split = append(split, names)
+		}
+	}
+	return split
+}
+
+// HandleSplitFile is HandleFile for a file split in parts, which are read in
+// order, like the parts of a file split by 7-Zip or split, or the volumes of
+// a multi-volume RAR archive. The file is scanned as a whole: the files of
+// RAR volumes are extracted across them, and other parts are joined back
+// together. Zip archives split by zip -s, whose parts are not parts of the
+// bytes of a whole zip archive, are not supported.
+func HandleSplitFile(ctx context.Context, parts []io.Reader, chunkSkel *sources.Chunk, chunksChan chan *sources.Chunk, opts ...Option) bool {
+	if len(parts) == 0 {
+		return false
+	}
+	if len(parts) > 1 {
+		first := bufio.NewReader(parts[0])
+		parts = append([]io.Reader{first}, parts[1:]...)
+		signature, _ := first.Peek(len(rarSignature))
+		var config handleConfig
+		for _, opt := range opts {
+			opt(&config)
+		}
+		if string(signature) == rarSignature && !config.archiveOptions.SkipsFormat("rar") {
+			a := &Archive{}
+			a.New()
+			configure(a, config.archiveOptions, sources.MetadataFile(chunkSkel.SourceMetadata))
+			return handleChunks(ctx, a.fromVolumes(ctx, parts), chunkSkel, chunksChan)
+		}
+	}
+	return HandleFile(ctx, io.MultiReader(parts...), chunkSkel, chunksChan, opts...)
+}
+
+// fromVolumes extracts the files of the volumes of a multi-volume RAR
+// archive, in order.
+func (a *Archive) fromVolumes(ctx context.Context, volumes []io.Reader) chan Part {
+	return a.extract(ctx, func(ctx context.Context, archiveChan chan Part) error {
+		return a.extractRarVolumes(ctx, volumes, archiveChan)
+	})
+}
+
+func (a *Archive) extractRarVolumes(ctx context.Context, volumes []io.Reader, archiveChan chan Part) error {
+	logger := logContext.AddLogger(ctx).Logger()
+	logger.V(3).Info("Handling multi-volume RAR archive.", "volumes", len(volumes))
+	// rardecode opens the volumes after the first by the name that
+	// follows the name of the previous one.
+	r, err := rardecode.OpenReader("archive.part1.rar", rardecode.FileSystem(rarVolumes(volumes)))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hdr, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.IsDir {
+			continue
+		}
+		name := memberPath("", hdr.Name)
+		if !a.memberFilter(ctx).Includes(name) {
+			a.reportSkip(ctx, name, sources.SkipReasonFiltered, hdr.UnPackedSize)
+			continue
+		}
+		err = a.openArchive(ctx, 1, name, &sizeLimitedReader{ctx: ctx, archive: a, name: name, reader: r, size: hdr.UnPackedSize}, archiveChan)
+		if errors.Is(err, errMaxArchiveDepthReached) {
+			a.reportSkip(ctx, name, sources.SkipReasonDepth, hdr.UnPackedSize)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// rarVolumes is a file system of the volumes of a RAR archive, which are
+// opened by their name in the naming of either the volume they follow, like
+// archive.part2.rar, or the older naming, like archive.part1.r00.
+type rarVolumes []io.Reader
+
+func (v rarVolumes) Open(name string) (fs.File, error) {
+	volume := -1
+	if m := rarPartPattern.FindStringSubmatch(name); m != nil {
+		number, _ := strconv.Atoi(m[2])
+		volume = number - 1
+	} else if m := rarOldPartPattern.FindStringSubmatch(name); m != nil {
+		number, _ := strconv.Atoi(m[2])
+		volume = number + 1
+	}
+	if volume < 0 || volume >= len(v) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &rarVolume{Reader: v[volume], name: name}, nil
+}
+
+// rarVolume is a volume of a RAR archive, as a file of rarVolumes.
+type rarVolume struct {
+	io.Reader
+	name string
+}
+
+func (v *rarVolume) Stat() (fs.FileInfo, error) { return v, nil }
+func (v *rarVolume) Close() error               { return nil }
+func (v *rarVolume) Name() string               { return v.name }
+func (v *rarVolume) Size() int64                { return 0 }
+func (v *rarVolume) Mode() fs.FileMode          { return 0 }
+func (v *rarVolume) ModTime() time.Time         { return time.Time{} }
+func (v *rarVolume) IsDir() bool                { return false }
+func (v *rarVolume) Sys() any                   { return nil }
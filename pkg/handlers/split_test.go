@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestSplitPart(t *testing.T) {
+	for name, want := range map[string]struct {
+		whole  string
+		number int
+		ok     bool
+	}{
+		"backup.zip.001":    {"backup.zip", 1, true},
+		"backup.tar.gz.012": {"backup.tar.gz", 12, true},
+		"app.part1.rar":     {"app.rar", 1, true},
+		"app.PART02.RAR":    {"app.rar", 2, true},
+		"app.rar":           {"app.rar", 0, true},
+		"app.r00":           {"app.rar", 1, true},
+		"app.r11":           {"app.rar", 12, true},
+		"access.log.1":      {},
+		"photo.001":         {},
+		"backup.zip":        {},
+	} {
+		whole, number, ok := SplitPart(name)
+		assert.Equal(t, want.ok, ok, name)
+		assert.Equal(t, want.whole, whole, name)
+		assert.Equal(t, want.number, number, name)
+	}
+}
+
+// handleSplitFile handles the parts of a file, and returns the content of the
+// files extracted from it by path.
+func handleSplitFile(t *testing.T, parts ...[]byte) map[string]string {
+	t.Helper()
+	readers := make([]io.Reader, len(parts))
+	for i, part := range parts {
+		readers[i] = bytes.NewReader(part)
+	}
+	skel := &sources.Chunk{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: "backup.001"}},
+		},
+	}
+	ch := make(chan *sources.Chunk, 1024)
+	assert.True(t, HandleSplitFile(context.Background(), readers, skel, ch))
+	close(ch)
+	files := make(map[string]string)
+	for chunk := range ch {
+		path := chunk.SourceMetadata.GetFilesystem().GetArchivePath()
+		files[path] = files[path][:chunk.Offset] + string(chunk.Data)
+	}
+	return files
+}
+
+func TestHandleSplitFile(t *testing.T) {
+	files := map[string][]byte{
+		"config/app.env": []byte("token = split\n"),
+		"README":         bytes.Repeat([]byte("readme\n"), 100),
+	}
+	archive := makeZip(t, files)
+	third := len(archive) / 3
+	got := handleSplitFile(t, archive[:third], archive[third:2*third], archive[2*third:])
+	assert.Equal(t, map[string]string{"config/app.env": string(files["config/app.env"]), "README": string(files["README"])}, got)
+}
+
+// rarVint appends a variable length integer of RAR 5.
+func rarVint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// rarBlock appends a block of RAR 5 of a type, with its header fields and
+// data.
+func rarBlock(b []byte, blockType, flags uint64, fields, data []byte) []byte {
+	header := rarVint(nil, blockType)
+	if data != nil {
+		flags |= 0x2
+	}
+	header = rarVint(header, flags)
+	if data != nil {
+		header = rarVint(header, uint64(len(data)))
+	}
+	header = append(header, fields...)
+	header = append(rarVint(nil, uint64(len(header))), header...)
+	b = binary.LittleEndian.AppendUint32(b, crc32.ChecksumIEEE(header))
+	return append(append(b, header...), data...)
+}
+
+// makeRarVolumes returns the volumes of a RAR 5 archive of stored files, the
+// volumes after the first starting at each offset of starts in the content of
+// the files one after the other.
+func makeRarVolumes(names, contents []string, starts ...int) [][]byte {
+	var total int
+	for _, content := range contents {
+		total += len(content)
+	}
+	bounds := append(append([]int{0}, starts...), total)
+	var volumes [][]byte
+	for v := 0; v+1 < len(bounds); v++ {
+		volume := []byte("Rar!\x1a\x07\x01\x00")
+		// A multi-volume archive.
+		volume = rarBlock(volume, 1, 0, rarVint(nil, 1), nil)
+		fileStart := 0
+		for i, name := range names {
+			fileEnd := fileStart + len(contents[i])
+			start, end := bounds[v], bounds[v+1]
+			if start < fileStart {
+				start = fileStart
+			}
+			if end > fileEnd {
+				end = fileEnd
+			}
+			if start < end {
+				var flags uint64
+				if start > fileStart {
+					flags |= 0x8
+				}
+				if end < fileEnd {
+					flags |= 0x10
+				}
+				// No file flags, the size, no attributes, stored, on
+				// Unix.
+				fields := rarVint(nil, 0)
+				fields = rarVint(fields, uint64(len(contents[i])))
+				fields = rarVint(fields, 0)
+				fields = rarVint(fields, 0)
+				fields = rarVint(fields, 1)
+				fields = rarVint(fields, uint64(len(name)))
+				fields = append(fields, name...)
+				volume = rarBlock(volume, 2, flags, fields, []byte(contents[i][start-fileStart:end-fileStart]))
+			}
+			fileStart = fileEnd
+		}
+		var notLast uint64
+		if v+2 < len(bounds) {
+			notLast = 1
+		}
+		volumes = append(volumes, rarBlock(volume, 5, 0, rarVint(nil, notLast), nil))
+	}
+	return volumes
+}
+
+func TestHandleSplitFile_RarVolumes(t *testing.T) {
+	names := []string{"README", "config/app.env", "notes.txt"}
+	contents := []string{"readme\n", "token = split across volumes\n", "notes\n"}
+	// The second file starts in the first volume, and ends in the third.
+	volumes := makeRarVolumes(names, contents, 12, 20)
+	got := handleSplitFile(t, volumes...)
+	assert.Equal(t, map[string]string{"README": contents[0], "config/app.env": contents[1], "notes.txt": contents[2]}, got)
+}
+
+func TestSplitFiles(t *testing.T) {
+	assert.Equal(t, [][]string{
+		{"backups/db.zip.001", "backups/db.zip.002", "backups/db.zip.003"},
+		{"app.rar", "app.r00"},
+	}, SplitFiles([]string{
+		"backups/db.zip.002", "backups/db.zip.001", "app.rar", "backups/db.zip.003", "app.r00",
+		// Parts of other files, a part alone, and parts that do not
+		// follow each other.
+		"db.zip.004", "other.rar", "logs.tar.001", "logs.tar.003", "README",
+	}))
+}
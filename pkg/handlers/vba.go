@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// The records of the dir stream of VBA projects read to find the source
+	// of modules.
+	vbaRecordProjectVersion   = 0x0009
+	vbaRecordModuleName       = 0x0019
+	vbaRecordModuleStreamName = 0x001a
+	vbaRecordModuleOffset     = 0x0031
+	vbaRecordModuleEnd        = 0x002b
+	vbaChunkSize              = 4096
+)
+
+var errCorruptVBA = errors.New("corrupt VBA project")
+
+// vbaModule is the source code of a module of a VBA project.
+type vbaModule struct {
+	name   string
+	source []byte
+}
+
+// vbaModules returns the source code of the modules of the VBA project of a
+// compound file, like the vbaProject.bin of macro-enabled Office documents.
+// Module sources are compressed, after the compiled code, in streams of the
+// VBA storage listed in its compressed dir stream.
+func vbaModules(c *compoundFile) ([]vbaModule, error) {
+	dirEntry, ok := c.find("VBA/dir")
+	if !ok {
+		return nil, errCorruptVBA
+	}
+	compressedDir, err := c.stream(dirEntry)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := vbaDecompress(compressedDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []vbaModule
+	var name, streamName string
+	var offset uint32
+	for len(dir) >= 6 {
+		id := binary.LittleEndian.Uint16(dir)
+		size := int(binary.LittleEndian.Uint32(dir[2:]))
+		// The size of the version record leaves out its minor version.
+		if id == vbaRecordProjectVersion {
+			size = 6
+		}
+		if 6+size > len(dir) {
+			return nil, errCorruptVBA
+		}
+		data := dir[6 : 6+size]
+		dir = dir[6+size:]
+
+		switch id {
+		case vbaRecordModuleName:
+			name = string(data)
+		case vbaRecordModuleStreamName:
+			streamName = string(data)
+		case vbaRecordModuleOffset:
+			if len(data) >= 4 {
+				offset = binary.LittleEndian.Uint32(data)
+			}
+		case vbaRecordModuleEnd:
+			entry, ok := c.find("VBA/" + streamName)
+			if !ok {
+				continue
+			}
+			stream, err := c.stream(entry)
+			if err != nil || int64(offset) > int64(len(stream)) {
+				continue
+			}
+			source, err := vbaDecompress(stream[offset:])
+			if err != nil {
+				continue
+			}
+			modules = append(modules, vbaModule{name: name, source: source})
+		}
+	}
+	return modules, nil
+}
+
+// vbaDecompress decompresses data compressed with the compression of VBA
+// projects: a signature byte, then chunks of up to 4096 bytes, each of them
+// stored or made of literal bytes and copy tokens.
+func vbaDecompress(data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != 1 {
+		return nil, errCorruptVBA
+	}
+	data = data[1:]
+	var out []byte
+	for len(data) >= 2 {
+		header := binary.LittleEndian.Uint16(data)
+		size := int(header&0x0fff) + 3
+		if size > len(data) {
+			size = len(data)
+		}
+		chunk := data[2:size]
+		data = data[size:]
+		if header&0x8000 == 0 {
+			out = append(out, chunk...)
+			continue
+		}
+
+		chunkStart := len(out)
+		for len(chunk) > 0 {
+			flags := chunk[0]
+			chunk = chunk[1:]
+			for bit := 0; bit < 8 && len(chunk) > 0; bit++ {
+				if flags&(1<<bit) == 0 {
+					out = append(out, chunk[0])
+					chunk = chunk[1:]
+					continue
+				}
+				if len(chunk) < 2 {
+					return nil, errCorruptVBA
+				}
+				token := binary.LittleEndian.Uint16(chunk)
+				chunk = chunk[2:]
+				// The bits of the offset grow with the position in the
+				// chunk, from 4 to 12.
+				position := len(out) - chunkStart
+				bitCount := 4
+				for (1 << bitCount) < position {
+					bitCount++
+				}
+				lengthMask := uint16(0xffff) >> bitCount
+				copyOffset := int(token>>(16-bitCount)) + 1
+				length := int(token&lengthMask) + 3
+				if copyOffset > position {
+					return nil, errCorruptVBA
+				}
+				for i := 0; i < length; i++ {
+					out = append(out, out[len(out)-copyOffset])
+				}
+			}
+		}
+		if len(out)-chunkStart > vbaChunkSize {
+			return nil, errCorruptVBA
+		}
+	}
+	return out, nil
+}
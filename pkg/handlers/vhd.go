@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+const (
+	vhdCookie        = "conectix"
+	vhdSparseCookie  = "cxsparse"
+	vhdFooterSize    = 512
+	vhdBATUnused     = 0xffffffff
+	vhdMaxBlockSize  = 1 << 28
+	vhdxSignature    = "vhdxfile"
+	vhdxRegionTable  = 192 * 1024
+	vhdxMetadataSig  = "metadata"
+	vhdxMaxEntries   = 2047
+	vhdxMB           = 1 << 20
+	vhdxBlockPresent = 6
+	vhdxBlockPartial = 7
+	vhdxMaxBlockSize = 256 * vhdxMB
+)
+
+// The GUIDs of the regions and metadata items of VHDX images read, in their
+// mixed-endian byte order.
+var (
+	vhdxRegionBAT       = vhdxGUID(0x2dc27766, 0xf623, 0x4200, 0x9d64115e9bfd4a08)
+	vhdxRegionMetadata  = vhdxGUID(0x8b7ca206, 0x4790, 0x4b9a, 0xb8fe575f050f886e)
+	vhdxFileParameters  = vhdxGUID(0xcaa16737, 0xfa36, 0x4d43, 0xb3b633f0aa44e76b)
+	vhdxVirtualDiskSize = vhdxGUID(0x2fa54224, 0xcd1b, 0x4876, 0xb2115dbed83bf4b8)
+	vhdxSectorSize      = vhdxGUID(0x8141bf1d, 0xa96f, 0x4709, 0xba47f233a8faab5f)
+)
+
+func vhdxGUID(a uint32, b, c uint16, d uint64) []byte {
+	guid := make([]byte, 16)
+	binary.LittleEndian.PutUint32(guid, a)
+	binary.LittleEndian.PutUint16(guid[4:], b)
+	binary.LittleEndian.PutUint16(guid[6:], c)
+	binary.BigEndian.PutUint64(guid[8:], d)
+	return guid
+}
+
+// openVHD returns the disk of a dynamic or differencing VHD image, which
+// starts with a copy of its footer. Fixed images are disks followed by a
+// footer, and are read as raw disks.
+func openVHD(r io.ReaderAt, size int64) (*virtualDisk, error) {
+	footer := make([]byte, vhdFooterSize)
+	if err := readAt(r, footer, 0); err != nil {
+		return nil, err
+	}
+	if string(footer[:8]) != vhdCookie {
+		return nil, errCorruptDiskImage
+	}
+	diskSize := int64(binary.BigEndian.Uint64(footer[48:]))
+	sparse := make([]byte, 1024)
+	if err := readAt(r, sparse, int64(binary.BigEndian.Uint64(footer[16:]))); err != nil {
+		return nil, err
+	}
+	if string(sparse[:8]) != vhdSparseCookie {
+		return nil, errCorruptDiskImage
+	}
+	batOffset := int64(binary.BigEndian.Uint64(sparse[16:]))
+	entries := int64(binary.BigEndian.Uint32(sparse[28:]))
+	blockSize := int64(binary.BigEndian.Uint32(sparse[32:]))
+	if blockSize < diskSectorSize || blockSize > vhdMaxBlockSize || entries*4 > size || diskSize <= 0 {
+		return nil, errCorruptDiskImage
+	}
+	bat := make([]byte, entries*4)
+	if err := readAt(r, bat, batOffset); err != nil {
+		return nil, err
+	}
+	// Blocks start with the bitmap of their sectors, padded to a sector.
+	bitmapSize := (blockSize/diskSectorSize/8 + diskSectorSize - 1) / diskSectorSize * diskSectorSize
+
+	return &virtualDisk{
+		size:       diskSize,
+		blockSize:  blockSize,
+		sectorSize: diskSectorSize,
+		block: func(n int64) (io.ReaderAt, error) {
+			if n >= entries {
+				return nil, nil
+			}
+			sector := binary.BigEndian.Uint32(bat[n*4:])
+			if sector == vhdBATUnused {
+				return nil, nil
+			}
+			return io.NewSectionReader(r, int64(sector)*diskSectorSize+bitmapSize, blockSize), nil
+		},
+	}, nil
+}
+
+// openVHDX returns the disk of a VHDX image. Its log is not replayed, so
+// images of VMs that were not shut down cleanly may read as they were before
+// their last writes.
+func openVHDX(r io.ReaderAt, size int64) (*virtualDisk, error) {
+	regions := make(map[string][2]int64)
+	table := make([]byte, 64*1024)
+	if err := readAt(r, table, vhdxRegionTable); err != nil {
+		return nil, err
+	}
+	if string(table[:4]) != "regi" {
+		return nil, errCorruptDiskImage
+	}
+	count := int(binary.LittleEndian.Uint32(table[8:]))
+	if count > vhdxMaxEntries {
+		return nil, errCorruptDiskImage
+	}
+	for i := 0; i < count; i++ {
+		entry := table[16+32*i:]
+		regions[string(entry[:16])] = [2]int64{int64(binary.LittleEndian.Uint64(entry[16:])), int64(binary.LittleEndian.Uint32(entry[24:]))}
+	}
+	batRegion, okBAT := regions[string(vhdxRegionBAT)]
+	metadataRegion, okMetadata := regions[string(vhdxRegionMetadata)]
+	if !okBAT || !okMetadata || batRegion[1] > size || metadataRegion[1] > size {
+		return nil, errCorruptDiskImage
+	}
+
+	metadata := make([]byte, metadataRegion[1])
+	if err := readAt(r, metadata, metadataRegion[0]); err != nil {
+		return nil, err
+	}
+	if len(metadata) < 32 || string(metadata[:8]) != vhdxMetadataSig {
+		return nil, errCorruptDiskImage
+	}
+	item := func(guid []byte, length int) []byte {
+		entries := int(binary.LittleEndian.Uint16(metadata[10:]))
+		for i := 0; i < entries && 32+32*(i+1) <= len(metadata); i++ {
+			entry := metadata[32+32*i:]
+			offset := int(binary.LittleEndian.Uint32(entry[16:]))
+			if bytes.Equal(entry[:16], guid) && offset >= 0 && offset+length <= len(metadata) {
+				return metadata[offset : offset+length]
+			}
+		}
+		return nil
+	}
+	parameters := item(vhdxFileParameters, 8)
+	diskSize := item(vhdxVirtualDiskSize, 8)
+	sectorSize := item(vhdxSectorSize, 4)
+	if parameters == nil || diskSize == nil || sectorSize == nil {
+		return nil, errCorruptDiskImage
+	}
+	blockSize := int64(binary.LittleEndian.Uint32(parameters))
+	logicalSectorSize := int64(binary.LittleEndian.Uint32(sectorSize))
+	if blockSize < vhdxMB || blockSize > vhdxMaxBlockSize || (logicalSectorSize != 512 && logicalSectorSize != 4096) {
+		return nil, errCorruptDiskImage
+	}
+
+	bat := make([]byte, batRegion[1])
+	if err := readAt(r, bat, batRegion[0]); err != nil {
+		return nil, err
+	}
+	// The entries of blocks are interleaved with the entries of sector
+	// bitmaps, one every chunk of blocks.
+	chunkRatio := (1 << 23) * logicalSectorSize / blockSize
+	return &virtualDisk{
+		size:       int64(binary.LittleEndian.Uint64(diskSize)),
+		blockSize:  blockSize,
+		sectorSize: logicalSectorSize,
+		block: func(n int64) (io.ReaderAt, error) {
+			index := n + n/chunkRatio
+			if (index+1)*8 > int64(len(bat)) {
+				return nil, nil
+			}
+			entry := binary.LittleEndian.Uint64(bat[index*8:])
+			if state := entry & 0x7; state != vhdxBlockPresent && state != vhdxBlockPartial {
+				return nil, nil
+			}
+			return io.NewSectionReader(r, int64(entry>>20)*vhdxMB, blockSize), nil
+		},
+	}, nil
+}
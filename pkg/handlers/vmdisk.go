@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strconv"
+)
+
+const (
+	diskSectorSize = 512
+	// mbrPartitionTable is the offset of the partition table of master boot
+	// records, of 4 entries.
+	mbrPartitionTable = 446
+	mbrTypeGPT        = 0xee
+	// mbrMaxLogical bounds the logical partitions read, for extended
+	// partitions whose boot records loop.
+	mbrMaxLogical   = 128
+	gptSignature    = "EFI PART"
+	gptMaxEntries   = 1024
+	virtualDiskKeep = 64
+)
+
+// virtualDisk is the disk of a VM disk image, whose content is stored in
+// blocks (grains of VMDK images, clusters of QCOW2 images) mapped by tables.
+// Blocks that are not allocated, like the blocks of a differencing image in
+// its parent, read as zeros.
+type virtualDisk struct {
+	size       int64
+	blockSize  int64
+	sectorSize int64
+	// block returns the content of a block, or nil if it reads as zeros.
+	block func(n int64) (io.ReaderAt, error)
+	// blocks caches blocks, which are small reads of filesystems read over
+	// and over, and may be compressed.
+	blocks map[int64]io.ReaderAt
+}
+
+func (d *virtualDisk) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off >= d.size {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) && off < d.size {
+		within := off % d.blockSize
+		length := int64(len(p) - n)
+		if d.blockSize-within < length {
+			length = d.blockSize - within
+		}
+		if d.size-off < length {
+			length = d.size - off
+		}
+		buf := p[n : n+int(length)]
+
+		block, err := d.cachedBlock(off / d.blockSize)
+		if err != nil {
+			return n, err
+		}
+		read := 0
+		if block != nil {
+			// Blocks at the end of images may be short.
+			read, err = block.ReadAt(buf, within)
+			if err != nil && err != io.EOF {
+				return n, err
+			}
+		}
+		for i := read; i < len(buf); i++ {
+			buf[i] = 0
+		}
+		n += len(buf)
+		off += length
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (d *virtualDisk) cachedBlock(n int64) (io.ReaderAt, error) {
+	if block, ok := d.blocks[n]; ok {
+		return block, nil
+	}
+	block, err := d.block(n)
+	if err != nil {
+		return nil, err
+	}
+	if d.blocks == nil || len(d.blocks) >= virtualDiskKeep {
+		d.blocks = make(map[int64]io.ReaderAt, virtualDiskKeep)
+	}
+	d.blocks[n] = block
+	return block, nil
+}
+
+// diskPartition is a partition of a disk, or the whole disk if it has no
+// partition table.
+type diskPartition struct {
+	name         string
+	offset, size int64
+}
+
+// diskPartitions returns the partitions of a disk, from its GUID partition
+// table or its master boot record, with the logical partitions of extended
+// partitions. Partitions are named partitionN, by their number in the table.
+func diskPartitions(r io.ReaderAt, size, sectorSize int64) []diskPartition {
+	mbr := make([]byte, diskSectorSize)
+	if err := readAt(r, mbr, 0); err != nil || !isPartitionTable(mbr) {
+		return []diskPartition{{size: size}}
+	}
+	if mbr[mbrPartitionTable+4] == mbrTypeGPT {
+		if partitions, ok := gptPartitions(r, size, sectorSize); ok {
+			return partitions
+		}
+	}
+
+	var partitions []diskPartition
+	add := func(number int, start, sectors int64) {
+		offset, length := start*sectorSize, sectors*sectorSize
+		if sectors > 0 && offset > 0 && offset < size {
+			if offset+length > size {
+				length = size - offset
+			}
+			partitions = append(partitions, diskPartition{name: "partition" + strconv.Itoa(number), offset: offset, size: length})
+		}
+	}
+	for i := 0; i < 4; i++ {
+		entry := mbr[mbrPartitionTable+16*i:]
+		start := int64(binary.LittleEndian.Uint32(entry[8:]))
+		sectors := int64(binary.LittleEndian.Uint32(entry[12:]))
+		switch entry[4] {
+		case 0:
+		case 0x05, 0x0f, 0x85:
+			// Logical partitions are numbered from 5, in a chain of boot
+			// records whose second entry is the next one, relative to
+			// the extended partition.
+			ebr := make([]byte, diskSectorSize)
+			next := int64(0)
+			for number := 5; number < 5+mbrMaxLogical; number++ {
+				ebrStart := start + next
+				if err := readAt(r, ebr, ebrStart*sectorSize); err != nil || ebr[510] != 0x55 || ebr[511] != 0xaa {
+					break
+				}
+				logical := ebr[mbrPartitionTable:]
+				add(number, ebrStart+int64(binary.LittleEndian.Uint32(logical[8:])), int64(binary.LittleEndian.Uint32(logical[12:])))
+				next = int64(binary.LittleEndian.Uint32(logical[16+8:]))
+				if next == 0 {
+					break
+				}
+			}
+		default:
+			add(i+1, start, sectors)
+		}
+	}
+	return partitions
+}
+
+// isPartitionTable reports whether a sector is a master boot record with a
+// partition table: it has the boot signature and valid partition entries, at
+// least one of them used.
+func isPartitionTable(sector []byte) bool {
+	if len(sector) < diskSectorSize || sector[510] != 0x55 || sector[511] != 0xaa {
+		return false
+	}
+	used := false
+	for i := 0; i < 4; i++ {
+		entry := sector[mbrPartitionTable+16*i:]
+		if entry[0] != 0 && entry[0] != 0x80 {
+			return false
+		}
+		if entry[4] != 0 {
+			if binary.LittleEndian.Uint32(entry[8:]) == 0 || binary.LittleEndian.Uint32(entry[12:]) == 0 {
+				return false
+			}
+			used = true
+		}
+	}
+	return used
+}
+
+// gptPartitions returns the partitions of a GUID partition table, which is
+// in the sector after the master boot record.
+func gptPartitions(r io.ReaderAt, size, sectorSize int64) ([]diskPartition, bool) {
+	header := make([]byte, 92)
+	if err := readAt(r, header, sectorSize); err != nil || string(header[:8]) != gptSignature {
+		return nil, false
+	}
+	entriesStart := int64(binary.LittleEndian.Uint64(header[72:]))
+	entries := int64(binary.LittleEndian.Uint32(header[80:]))
+	entrySize := int64(binary.LittleEndian.Uint32(header[84:]))
+	if entrySize < 128 || entries > gptMaxEntries {
+		return nil, false
+	}
+	table := make([]byte, entries*entrySize)
+	if err := readAt(r, table, entriesStart*sectorSize); err != nil {
+		return nil, false
+	}
+
+	var partitions []diskPartition
+	var unused [16]byte
+	for i := int64(0); i < entries; i++ {
+		entry := table[i*entrySize:]
+		if bytes.Equal(entry[:16], unused[:]) {
+			continue
+		}
+		first := int64(binary.LittleEndian.Uint64(entry[32:]))
+		last := int64(binary.LittleEndian.Uint64(entry[40:]))
+		offset, length := first*sectorSize, (last-first+1)*sectorSize
+		if last < first || offset >= size {
+			continue
+		}
+		if offset+length > size {
+			length = size - offset
+		}
+		partitions = append(partitions, diskPartition{name: "partition" + strconv.FormatInt(i+1, 10), offset: offset, size: length})
+	}
+	return partitions, true
+}
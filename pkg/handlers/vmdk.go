@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+)
+
+const (
+	vmdkMagic      = "KDMV"
+	vmdkHeaderSize = 79
+	// vmdkFlagCompressed is the flag of stream-optimized images, like the
+	// disks of OVA appliances, whose grains are compressed with deflate.
+	vmdkFlagCompressed = 1 << 16
+	// vmdkGDAtEnd is the offset of the grain directory of stream-optimized
+	// images written as a stream, which is in the footer of the image.
+	vmdkGDAtEnd = 0xffffffffffffffff
+	// vmdkMaxGrainSize and vmdkMaxGTEs bound the grain size and grain
+	// table entries, 64KiB and 512 in images made by VMware.
+	vmdkMaxGrainSize = 1 << 20
+	vmdkMaxGTEs      = 1 << 16
+)
+
+// openVMDK returns the disk of a monolithic sparse or stream-optimized VMDK
+// image. Images split into several files, and descriptors of flat extents,
+// reference other files and are not read.
+func openVMDK(r io.ReaderAt, size int64) (*virtualDisk, error) {
+	header := make([]byte, vmdkHeaderSize)
+	if err := readAt(r, header, 0); err != nil {
+		return nil, err
+	}
+	gdOffset := binary.LittleEndian.Uint64(header[56:])
+	if gdOffset == vmdkGDAtEnd {
+		// The footer is the sector before the end-of-stream marker.
+		if size < 2*diskSectorSize {
+			return nil, errCorruptDiskImage
+		}
+		if err := readAt(r, header, size-2*diskSectorSize); err != nil {
+			return nil, err
+		}
+		gdOffset = binary.LittleEndian.Uint64(header[56:])
+	}
+	if string(header[:4]) != vmdkMagic {
+		return nil, errCorruptDiskImage
+	}
+	flags := binary.LittleEndian.Uint32(header[8:])
+	capacity := int64(binary.LittleEndian.Uint64(header[12:]))
+	grainSize := int64(binary.LittleEndian.Uint64(header[20:])) * diskSectorSize
+	gtes := int64(binary.LittleEndian.Uint32(header[44:]))
+	if grainSize <= 0 || grainSize > vmdkMaxGrainSize || gtes <= 0 || gtes > vmdkMaxGTEs || capacity <= 0 || gdOffset > uint64(size) {
+		return nil, errCorruptDiskImage
+	}
+
+	grains := (capacity*diskSectorSize + grainSize - 1) / grainSize
+	tables := (grains + gtes - 1) / gtes
+	if tables*4 > size {
+		return nil, errCorruptDiskImage
+	}
+	gd := make([]byte, tables*4)
+	if err := readAt(r, gd, int64(gdOffset)*diskSectorSize); err != nil {
+		return nil, err
+	}
+	// Grain tables are read as needed.
+	gt := make([]byte, gtes*4)
+	gtLoaded := int64(-1)
+
+	return &virtualDisk{
+		size:       capacity * diskSectorSize,
+		blockSize:  grainSize,
+		sectorSize: diskSectorSize,
+		block: func(n int64) (io.ReaderAt, error) {
+			table := n / gtes
+			gtOffset := int64(binary.LittleEndian.Uint32(gd[table*4:])) * diskSectorSize
+			if gtOffset == 0 {
+				return nil, nil
+			}
+			if gtLoaded != table {
+				if err := readAt(r, gt, gtOffset); err != nil {
+					return nil, err
+				}
+				gtLoaded = table
+			}
+			// Grains at sector 0 are not allocated, and at sector 1
+			// are zeroed.
+			grain := int64(binary.LittleEndian.Uint32(gt[(n%gtes)*4:]))
+			if grain <= 1 {
+				return nil, nil
+			}
+			if flags&vmdkFlagCompressed == 0 {
+				return io.NewSectionReader(r, grain*diskSectorSize, grainSize), nil
+			}
+			// Compressed grains start with their sector number and the
+			// size of their zlib stream.
+			marker := make([]byte, 12)
+			if err := readAt(r, marker, grain*diskSectorSize); err != nil {
+				return nil, err
+			}
+			compressedSize := int64(binary.LittleEndian.Uint32(marker[8:]))
+			zr, err := zlib.NewReader(io.NewSectionReader(r, grain*diskSectorSize+12, compressedSize))
+			if err != nil {
+				return nil, err
+			}
+			defer zr.Close()
+			data, err := io.ReadAll(io.LimitReader(zr, grainSize))
+			if err != nil {
+				return nil, err
+			}
+			return bytes.NewReader(data), nil
+		},
+	}, nil
+}
@@ -0,0 +1,438 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"unicode/utf16"
+)
+
+const (
+	wimMagic              = "MSWIM\x00\x00\x00"
+	wimHeaderSize         = 208
+	wimFlagCompressed     = 0x2
+	wimFlagXPRESS         = 0x20000
+	wimFlagLZX            = 0x40000
+	wimResourceSize       = 24
+	wimLookupEntrySize    = 50
+	wimResourceMetadata   = 0x2
+	wimResourceCompressed = 0x4
+	wimResourceSpanned    = 0x8
+	wimResourceSolid      = 0x10
+	wimDefaultChunkSize   = 32768
+	wimDentrySize         = 102
+	wimStreamEntrySize    = 38
+	wimAttrDirectory      = 0x10
+	wimAttrReparsePoint   = 0x400
+	// wimMaxDirectories bounds the directories read, for images whose
+	// directories loop.
+	wimMaxDirectories = 1 << 20
+	xpressTableSize   = 256
+	xpressSymbols     = 512
+	xpressMaxBlock    = 65536
+	xpressMinMatch    = 3
+)
+
+// errUnsupportedWIM is returned for WIM images compressed with LZMS, like
+// the ESD files of Windows Update, and for split images.
+var errUnsupportedWIM = errors.New("unsupported WIM image")
+
+// wimResource is a resource of a WIM image: its location, which is of its
+// compressed chunks if it is compressed, and its size.
+type wimResource struct {
+	flags        byte
+	offset, size int64
+	originalSize int64
+}
+
+func parseWIMResource(b []byte) wimResource {
+	return wimResource{
+		size:         int64(binary.LittleEndian.Uint64(b) & (1<<56 - 1)),
+		flags:        b[7],
+		offset:       int64(binary.LittleEndian.Uint64(b[8:])),
+		originalSize: int64(binary.LittleEndian.Uint64(b[16:])),
+	}
+}
+
+type wimImage struct {
+	r         io.ReaderAt
+	size      int64
+	lzx       bool
+	chunkSize int64
+	streams   map[[20]byte]wimResource
+	metadata  []wimResource
+}
+
+// walkWIM calls walk with each file of the images of a WIM image, like
+// install.wim of Windows installation media, prefixed by the index of their
+// image if it has more than one, e.g. image2/Windows/Panther/unattend.xml.
+// Images are made of resources, the directory trees of the images and the
+// content of their files, stored as is or in chunks compressed with XPRESS or
+// LZX.
+func walkWIM(r io.ReaderAt, size int64, walk walkFunc) error {
+	w, err := openWIM(r, size)
+	if err != nil {
+		return err
+	}
+	for i, res := range w.metadata {
+		prefix := ""
+		if len(w.metadata) > 1 {
+			prefix = fmt.Sprintf("image%d/", i+1)
+		}
+		if err := w.walkImage(res, prefix, walk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func openWIM(r io.ReaderAt, size int64) (*wimImage, error) {
+	header := make([]byte, wimHeaderSize)
+	if err := readAt(r, header, 0); err != nil || !bytes.HasPrefix(header, []byte(wimMagic)) {
+		return nil, errCorruptDiskImage
+	}
+	w := &wimImage{r: r, size: size, chunkSize: wimDefaultChunkSize, streams: make(map[[20]byte]wimResource)}
+	flags := binary.LittleEndian.Uint32(header[16:])
+	if chunkSize := binary.LittleEndian.Uint32(header[20:]); chunkSize != 0 {
+		w.chunkSize = int64(chunkSize)
+	}
+	if flags&wimFlagCompressed != 0 {
+		switch {
+		case flags&wimFlagLZX != 0:
+			w.lzx = true
+			if w.chunkSize < 1<<lzxMinWindowBits || w.chunkSize > 1<<lzxMaxWindowBits {
+				return nil, errUnsupportedWIM
+			}
+		case flags&wimFlagXPRESS != 0:
+			if w.chunkSize > xpressMaxBlock {
+				return nil, errUnsupportedWIM
+			}
+		default:
+			return nil, errUnsupportedWIM
+		}
+	}
+	if w.chunkSize&(w.chunkSize-1) != 0 {
+		return nil, errCorruptDiskImage
+	}
+	if binary.LittleEndian.Uint16(header[42:]) > 1 {
+		return nil, errUnsupportedWIM
+	}
+
+	lookup := parseWIMResource(header[48:])
+	if lookup.originalSize > maxMetadataSize {
+		return nil, errCorruptDiskImage
+	}
+	table, err := w.readResource(lookup)
+	if err != nil {
+		return nil, err
+	}
+	for offset := 0; offset+wimLookupEntrySize <= len(table); offset += wimLookupEntrySize {
+		entry := table[offset : offset+wimLookupEntrySize]
+		res := parseWIMResource(entry)
+		if res.flags&wimResourceSolid != 0 {
+			return nil, errUnsupportedWIM
+		}
+		if res.flags&wimResourceMetadata != 0 {
+			w.metadata = append(w.metadata, res)
+			continue
+		}
+		var hash [20]byte
+		copy(hash[:], entry[30:])
+		w.streams[hash] = res
+	}
+	return w, nil
+}
+
+// readResource reads a resource whole, for metadata.
+func (w *wimImage) readResource(res wimResource) ([]byte, error) {
+	content, err := w.open(res)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// open returns a reader of the content of a resource.
+func (w *wimImage) open(res wimResource) (io.Reader, error) {
+	if res.offset < 0 || res.size < 0 || res.offset+res.size > w.size || res.originalSize < 0 {
+		return nil, errCorruptDiskImage
+	}
+	if res.flags&wimResourceCompressed == 0 {
+		return io.NewSectionReader(w.r, res.offset, res.originalSize), nil
+	}
+	// Compressed resources start with the offsets of their chunks after the
+	// first, relative to the end of the table.
+	chunks := (res.originalSize + w.chunkSize - 1) / w.chunkSize
+	entrySize := int64(4)
+	if res.originalSize > 1<<32-1 {
+		entrySize = 8
+	}
+	tableSize := (chunks - 1) * entrySize
+	if chunks == 0 {
+		tableSize = 0
+	}
+	if tableSize > res.size || tableSize > maxMetadataSize {
+		return nil, errCorruptDiskImage
+	}
+	table := make([]byte, tableSize)
+	if err := readAt(w.r, table, res.offset); err != nil {
+		return nil, err
+	}
+	offsets := make([]int64, chunks+1)
+	for i := int64(1); i < chunks; i++ {
+		if entrySize == 4 {
+			offsets[i] = int64(binary.LittleEndian.Uint32(table[(i-1)*4:]))
+		} else {
+			offsets[i] = int64(binary.LittleEndian.Uint64(table[(i-1)*8:]))
+		}
+	}
+	offsets[chunks] = res.size - tableSize
+	return &wimChunkReader{w: w, base: res.offset + tableSize, offsets: offsets, remaining: res.originalSize}, nil
+}
+
+// wimChunkReader reads the compressed chunks of a resource.
+type wimChunkReader struct {
+	w         *wimImage
+	base      int64
+	offsets   []int64
+	chunk     int
+	remaining int64
+	buf       []byte
+}
+
+func (c *wimChunkReader) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		if c.remaining == 0 || c.chunk+1 >= len(c.offsets) {
+			return 0, io.EOF
+		}
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+func (c *wimChunkReader) next() error {
+	start, end := c.offsets[c.chunk], c.offsets[c.chunk+1]
+	c.chunk++
+	size := c.w.chunkSize
+	if size > c.remaining {
+		size = c.remaining
+	}
+	if start < 0 || end < start || end-start > size {
+		return errCorruptDiskImage
+	}
+	data := make([]byte, end-start)
+	if err := readAt(c.w.r, data, c.base+start); err != nil {
+		return err
+	}
+	c.remaining -= size
+	// Chunks that do not compress are stored as they are.
+	if int64(len(data)) == size {
+		c.buf = data
+		return nil
+	}
+	var err error
+	if c.w.lzx {
+		var d *lzxDecoder
+		if d, err = newLZXDecoder(bits.Len64(uint64(c.w.chunkSize-1)), true); err == nil {
+			c.buf, err = d.decodeFrame(data, int(size))
+		}
+	} else {
+		c.buf, err = xpressDecompress(data, int(size))
+	}
+	if err != nil {
+		return errCorruptDiskImage
+	}
+	return nil
+}
+
+// walkImage calls walk with each file of the directory tree of an image,
+// which follows the security descriptors of its files.
+func (w *wimImage) walkImage(res wimResource, prefix string, walk walkFunc) error {
+	if res.originalSize > maxMetadataSize {
+		return errCorruptDiskImage
+	}
+	meta, err := w.readResource(res)
+	if err != nil {
+		return err
+	}
+	if len(meta) < 8 {
+		return errCorruptDiskImage
+	}
+	root := (int64(binary.LittleEndian.Uint32(meta)) + 7) &^ 7
+	if root < 8 {
+		root = 8
+	}
+	rootDentry, _, ok := parseWIMDentry(meta, root)
+	if !ok {
+		return errCorruptDiskImage
+	}
+
+	visited := make(map[int64]bool)
+	var walkDir func(offset int64, parent string) error
+	walkDir = func(offset int64, parent string) error {
+		if offset == 0 || visited[offset] || len(visited) >= wimMaxDirectories {
+			return nil
+		}
+		visited[offset] = true
+		for {
+			d, next, ok := parseWIMDentry(meta, offset)
+			if !ok {
+				return nil
+			}
+			offset = next
+			path := parent + d.name
+			if d.attributes&wimAttrDirectory != 0 {
+				if err := walkDir(d.subdir, path+"/"); err != nil {
+					return err
+				}
+				continue
+			}
+			res, ok := w.streams[d.hash]
+			if d.attributes&wimAttrReparsePoint != 0 || d.hash == ([20]byte{}) || !ok || res.flags&wimResourceSpanned != 0 {
+				continue
+			}
+			content, err := w.open(res)
+			if err != nil {
+				return err
+			}
+			if err := walk(path, content); err != nil {
+				return err
+			}
+		}
+	}
+	return walkDir(rootDentry.subdir, prefix)
+}
+
+// wimDentry is a directory entry of an image.
+type wimDentry struct {
+	name       string
+	attributes uint32
+	subdir     int64
+	hash       [20]byte
+}
+
+// parseWIMDentry parses the directory entry at an offset of the metadata of
+// an image, and returns the offset of the next entry of its directory. Its
+// alternate data streams follow it, one of which is its content if it has no
+// hash of its own.
+func parseWIMDentry(meta []byte, offset int64) (wimDentry, int64, bool) {
+	if offset < 0 || offset+8 > int64(len(meta)) {
+		return wimDentry{}, 0, false
+	}
+	length := int64(binary.LittleEndian.Uint64(meta[offset:]))
+	// Directories end with an entry of length 0.
+	if length < wimDentrySize || offset+length > int64(len(meta)) {
+		return wimDentry{}, 0, false
+	}
+	raw := meta[offset : offset+length]
+	d := wimDentry{
+		attributes: binary.LittleEndian.Uint32(raw[8:]),
+		subdir:     int64(binary.LittleEndian.Uint64(raw[16:])),
+	}
+	copy(d.hash[:], raw[64:84])
+	nameSize := int(binary.LittleEndian.Uint16(raw[100:]))
+	if wimDentrySize+nameSize > len(raw) {
+		return wimDentry{}, 0, false
+	}
+	d.name = decodeUTF16LE(raw[wimDentrySize : wimDentrySize+nameSize])
+
+	next := offset + (length+7)&^7
+	streams := int(binary.LittleEndian.Uint16(raw[96:]))
+	for i := 0; i < streams; i++ {
+		if next+wimStreamEntrySize > int64(len(meta)) {
+			return wimDentry{}, 0, false
+		}
+		stream := meta[next:]
+		streamLength := int64(binary.LittleEndian.Uint64(stream))
+		if streamLength < wimStreamEntrySize {
+			return wimDentry{}, 0, false
+		}
+		if binary.LittleEndian.Uint16(stream[36:]) == 0 && d.hash == ([20]byte{}) {
+			copy(d.hash[:], stream[16:36])
+		}
+		next += (streamLength + 7) &^ 7
+	}
+	return d, next, true
+}
+
+func decodeUTF16LE(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// xpressDecompress decompresses a chunk compressed with XPRESS Huffman, of
+// up to 64 KiB: the code lengths of its 512 symbols, 4 bits each, then the
+// codes of literals and matches. The lengths of long matches are in the bytes
+// that follow the bits of their symbol.
+func xpressDecompress(in []byte, size int) ([]byte, error) {
+	if len(in) < xpressTableSize || size > xpressMaxBlock {
+		return nil, errCorruptDiskImage
+	}
+	lengths := make([]uint8, xpressSymbols)
+	for i, b := range in[:xpressTableSize] {
+		lengths[2*i], lengths[2*i+1] = b&0xf, b>>4
+	}
+	h, err := newHuffman(lengths)
+	if err != nil {
+		return nil, errCorruptDiskImage
+	}
+	b := &bitReader16{data: in, pos: xpressTableSize}
+	b.ensure(32)
+	out := make([]byte, 0, size)
+	for len(out) < size {
+		sym, err := h.decode(b)
+		if err != nil || b.overrun() {
+			return nil, errCorruptDiskImage
+		}
+		b.ensure(16)
+		if sym < 256 {
+			out = append(out, byte(sym))
+			continue
+		}
+		sym -= 256
+		length, offsetBits := sym&0xf, uint(sym>>4)
+		if length == 0xf {
+			if b.pos >= len(in) {
+				return nil, errCorruptDiskImage
+			}
+			length = int(in[b.pos])
+			b.pos++
+			if length == 0xff {
+				if b.pos+2 > len(in) {
+					return nil, errCorruptDiskImage
+				}
+				length = int(binary.LittleEndian.Uint16(in[b.pos:]))
+				b.pos += 2
+				if length < 0xf {
+					return nil, errCorruptDiskImage
+				}
+				length -= 0xf
+			}
+			length += 0xf
+		}
+		length += xpressMinMatch
+		offset := int(b.bits(offsetBits)) + 1<<offsetBits
+		b.ensure(16)
+		if offset > len(out) || len(out)+length > size {
+			return nil, errCorruptDiskImage
+		}
+		for i := 0; i < length; i++ {
+			out = append(out, out[len(out)-offset])
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"math/bits"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// greedyOps returns literals and matches of data, of lengths between
+// minLength and maxLength at offsets of up to 256.
+func greedyOps(data []byte, minLength, maxLength int) []lzxOp {
+	var ops []lzxOp
+	for i := 0; i < len(data); {
+		length, offset := 0, 0
+		for o := 1; o <= 256 && o <= i; o++ {
+			l := 0
+			for l < maxLength && i+l < len(data) && data[i+l] == data[i+l-o] {
+				l++
+			}
+			if l > length {
+				length, offset = l, o
+			}
+		}
+		if length < minLength {
+			ops = append(ops, lzxOp{literal: string(data[i : i+1])})
+			i++
+			continue
+		}
+		ops = append(ops, lzxOp{length: length, offset: offset})
+		i += length
+	}
+	return ops
+}
+
+// xpressCompress compresses data with XPRESS Huffman, with codes of 9 bits
+// for all symbols, and matches of at most 17 bytes.
+func xpressCompress(data []byte) []byte {
+	out := []byte(strings.Repeat("\x99", xpressTableSize))
+	w := &bitWriter16{}
+	for _, op := range greedyOps(data, xpressMinMatch, 17) {
+		if op.length == 0 {
+			w.write(uint32(op.literal[0]), 9)
+			continue
+		}
+		offsetBits := bits.Len(uint(op.offset)) - 1
+		w.write(uint32(256+offsetBits<<4|(op.length-xpressMinMatch)), 9)
+		w.write(uint32(op.offset-1<<offsetBits), uint(offsetBits))
+	}
+	w.align()
+	return append(out, w.out...)
+}
+
+// lzxCompressWIM compresses a chunk of a WIM resource with LZX.
+func lzxCompressWIM(data []byte) []byte {
+	w := newLZXWriter(lzxMinWindowBits, true)
+	w.verbatim(len(data), greedyOps(data, lzxMinMatch, 8)...)
+	return w.frame()
+}
+
+type wimDir struct {
+	dirs  map[string]*wimDir
+	files map[string]string
+}
+
+// makeWIM returns a WIM image of one image of files by path, whose resources
+// are compressed in chunks with compress if it is not nil.
+func makeWIM(t *testing.T, flags uint32, compress func([]byte) []byte, files map[string]string) []byte {
+	t.Helper()
+	image := make([]byte, wimHeaderSize)
+	var lookup []byte
+	// addResource adds a resource, and its entry in the lookup table.
+	addResource := func(data []byte, resFlags byte) [20]byte {
+		offset := len(image)
+		if compress != nil {
+			resFlags |= wimResourceCompressed
+			var table, chunks []byte
+			for start := 0; start < len(data); start += wimDefaultChunkSize {
+				end := start + wimDefaultChunkSize
+				if end > len(data) {
+					end = len(data)
+				}
+				if start > 0 {
+					table = binary.LittleEndian.AppendUint32(table, uint32(len(chunks)))
+				}
+				chunk := compress(data[start:end])
+				if len(chunk) >= end-start {
+					chunk = data[start:end]
+				}
+				chunks = append(chunks, chunk...)
+			}
+			image = append(append(image, table...), chunks...)
+		} else {
+			image = append(image, data...)
+		}
+		hash := sha1.Sum(data)
+		lookup = append(lookup, wimResourceHeader(resFlags, offset, len(image)-offset, len(data))...)
+		lookup = binary.LittleEndian.AppendUint16(lookup, 1)
+		lookup = binary.LittleEndian.AppendUint32(lookup, 1)
+		lookup = append(lookup, hash[:]...)
+		return hash
+	}
+
+	root := &wimDir{dirs: map[string]*wimDir{}, files: map[string]string{}}
+	for path, content := range files {
+		dir := root
+		parts := strings.Split(path, "/")
+		for _, part := range parts[:len(parts)-1] {
+			if dir.dirs[part] == nil {
+				dir.dirs[part] = &wimDir{dirs: map[string]*wimDir{}, files: map[string]string{}}
+			}
+			dir = dir.dirs[part]
+		}
+		dir.files[parts[len(parts)-1]] = content
+	}
+
+	// The security data has no descriptors.
+	meta := binary.LittleEndian.AppendUint32(nil, 8)
+	meta = binary.LittleEndian.AppendUint32(meta, 0)
+	dentry := func(name string, attributes uint32, hash [20]byte) int {
+		offset := len(meta)
+		nameBytes := utf16LE(name)
+		length := wimDentrySize + len(nameBytes) + 2
+		raw := make([]byte, (length+7)&^7)
+		binary.LittleEndian.PutUint64(raw, uint64(length))
+		binary.LittleEndian.PutUint32(raw[8:], attributes)
+		binary.LittleEndian.PutUint32(raw[12:], 0xffffffff)
+		copy(raw[64:], hash[:])
+		binary.LittleEndian.PutUint16(raw[100:], uint16(len(nameBytes)))
+		copy(raw[wimDentrySize:], nameBytes)
+		meta = append(meta, raw...)
+		return offset
+	}
+	var writeChildren func(dir *wimDir) int
+	writeChildren = func(dir *wimDir) int {
+		start := len(meta)
+		subdirs := make(map[int]*wimDir)
+		for _, name := range sortedKeys(dir.dirs) {
+			subdirs[dentry(name, wimAttrDirectory, [20]byte{})] = dir.dirs[name]
+		}
+		for _, name := range sortedKeys(dir.files) {
+			// Empty files have no resource.
+			var hash [20]byte
+			if content := dir.files[name]; content != "" {
+				hash = addResource([]byte(content), 0)
+			}
+			dentry(name, 0x20, hash)
+		}
+		meta = append(meta, make([]byte, 8)...)
+		for offset, subdir := range subdirs {
+			children := writeChildren(subdir)
+			binary.LittleEndian.PutUint64(meta[offset+16:], uint64(children))
+		}
+		return start
+	}
+	rootOffset := dentry("", wimAttrDirectory, [20]byte{})
+	children := writeChildren(root)
+	binary.LittleEndian.PutUint64(meta[rootOffset+16:], uint64(children))
+	addResource(meta, wimResourceMetadata)
+
+	lookupOffset := len(image)
+	image = append(image, lookup...)
+	copy(image, wimMagic)
+	binary.LittleEndian.PutUint32(image[8:], wimHeaderSize)
+	binary.LittleEndian.PutUint32(image[12:], 0x10d00)
+	if compress != nil {
+		flags |= wimFlagCompressed
+	}
+	binary.LittleEndian.PutUint32(image[16:], flags)
+	binary.LittleEndian.PutUint32(image[20:], wimDefaultChunkSize)
+	binary.LittleEndian.PutUint16(image[40:], 1)
+	binary.LittleEndian.PutUint16(image[42:], 1)
+	binary.LittleEndian.PutUint32(image[44:], 1)
+	copy(image[48:], wimResourceHeader(0, lookupOffset, len(lookup), len(lookup)))
+	return image
+}
+
+func wimResourceHeader(flags byte, offset, size, originalSize int) []byte {
+	b := binary.LittleEndian.AppendUint64(nil, uint64(size)|uint64(flags)<<56)
+	b = binary.LittleEndian.AppendUint64(b, uint64(offset))
+	return binary.LittleEndian.AppendUint64(b, uint64(originalSize))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestHandleFile_WIM(t *testing.T) {
+	// The answer file spans chunks.
+	files := map[string]string{
+		"Windows/Panther/unattend.xml": strings.Repeat("<!-- settings -->\n", 2000) + "<AdministratorPassword><Value>hunter2</Value></AdministratorPassword>\n",
+		"readme.txt":                   "deployment image\n",
+		"Windows/Temp/empty.log":       "",
+	}
+	want := map[string]string{
+		"Windows/Panther/unattend.xml": files["Windows/Panther/unattend.xml"],
+		"readme.txt":                   files["readme.txt"],
+	}
+	for _, tc := range []struct {
+		name     string
+		flags    uint32
+		compress func([]byte) []byte
+	}{
+		{name: "stored"},
+		{name: "xpress", flags: wimFlagXPRESS, compress: xpressCompress},
+		{name: "lzx", flags: wimFlagLZX, compress: lzxCompressWIM},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, want, handleNamedFile(t, "install.wim", makeWIM(t, tc.flags, tc.compress, files)))
+		})
+	}
+}
+
+func TestHandleFile_WIMUnsupported(t *testing.T) {
+	// LZMS, of ESD files, is not supported.
+	image := makeWIM(t, 0x80000, func(data []byte) []byte { return data }, map[string]string{"readme.txt": "deployment image\n"})
+	assert.Empty(t, handleNamedFile(t, "install.esd", image))
+}
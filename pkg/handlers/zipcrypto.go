@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/zip"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// errWrongPassword is returned when none of the archive passwords decrypts an
+// encrypted file.
+var errWrongPassword = errors.New("no archive password decrypts the file")
+
+const (
+	// zipMethodAES is the compression method of files encrypted with WinZip
+	// AES encryption, whose actual method is in their AES extra field.
+	zipMethodAES      = 99
+	zipExtraAES       = 0x9901
+	zipFlagDescriptor = 0x8
+)
+
+// openEncryptedZipFile decrypts and decompresses a zip file encrypted with the
+// traditional PKWARE encryption or WinZip AES encryption, with the first of
+// the passwords that decrypts it. Files are read up to maxSize.
+func openEncryptedZipFile(f *zip.File, passwords []string, maxSize int) (io.ReadCloser, error) {
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+	// Encrypted files are read whole to try each password on them. They
+	// are limited by the maximum archive size like other extracted files.
+	data, err := io.ReadAll(io.LimitReader(raw, int64(maxSize)))
+	if err != nil {
+		return nil, err
+	}
+
+	method := f.Method
+	var decrypt func(password string) ([]byte, bool)
+	if method == zipMethodAES {
+		strength, actualMethod, ok := aesExtra(f.Extra)
+		if !ok {
+			return nil, errors.New("invalid zip AES extra field")
+		}
+		method = actualMethod
+		decrypt = func(password string) ([]byte, bool) { return decryptZipAES(data, password, strength) }
+	} else {
+		// The last byte of the encryption header is the high byte of the
+		// CRC, or of the modification time of files with data descriptors.
+		check := byte(f.CRC32 >> 24)
+		if f.Flags&zipFlagDescriptor != 0 {
+			check = byte(f.ModifiedTime >> 8)
+		}
+		decrypt = func(password string) ([]byte, bool) { return decryptZipCrypto(data, password, check) }
+	}
+
+	for _, password := range passwords {
+		plain, ok := decrypt(password)
+		if !ok {
+			continue
+		}
+		content, err := decompressZip(method, plain, maxSize)
+		if err != nil {
+			// The check of traditional encryption passes for one in 256
+			// wrong passwords.
+			continue
+		}
+		// Files encrypted with AES may have no CRC.
+		if f.CRC32 != 0 && crc32.ChecksumIEEE(content) != f.CRC32 {
+			continue
+		}
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+	return nil, errWrongPassword
+}
+
+// decompressZip decompresses the content of a zip file stored or deflated, up
+// to maxSize.
+func decompressZip(method uint16, data []byte, maxSize int) ([]byte, error) {
+	switch method {
+	case zip.Store:
+		return data, nil
+	case zip.Deflate:
+		rc := flate.NewReader(bytes.NewReader(data))
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, int64(maxSize)))
+	default:
+		return nil, zip.ErrAlgorithm
+	}
+}
+
+// decryptZipCrypto decrypts data encrypted with the traditional PKWARE
+// encryption, which starts with a 12 byte header whose last byte is check.
+func decryptZipCrypto(data []byte, password string, check byte) ([]byte, bool) {
+	if len(data) < 12 {
+		return nil, false
+	}
+	keys := [3]uint32{0x12345678, 0x23456789, 0x34567890}
+	update := func(b byte) {
+		keys[0] = crc32Update(keys[0], b)
+		keys[1] = (keys[1]+keys[0]&0xff)*134775813 + 1
+		keys[2] = crc32Update(keys[2], byte(keys[1]>>24))
+	}
+	for i := 0; i < len(password); i++ {
+		update(password[i])
+	}
+
+	plain := make([]byte, len(data))
+	for i, c := range data {
+		k := keys[2] | 2
+		p := c ^ byte((k*(k^1))>>8)
+		update(p)
+		plain[i] = p
+	}
+	if plain[11] != check {
+		return nil, false
+	}
+	return plain[12:], true
+}
+
+func crc32Update(crc uint32, b byte) uint32 {
+	return crc32.IEEETable[byte(crc)^b] ^ crc>>8
+}
+
+// aesExtra returns the key strength and actual compression method of a file
+// encrypted with WinZip AES encryption from its extra field.
+func aesExtra(extra []byte) (strength byte, method uint16, ok bool) {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if len(extra) < 4+size {
+			break
+		}
+		field := extra[4 : 4+size]
+		if id == zipExtraAES && size >= 7 {
+			return field[4], binary.LittleEndian.Uint16(field[5:]), true
+		}
+		extra = extra[4+size:]
+	}
+	return 0, 0, false
+}
+
+// decryptZipAES decrypts data encrypted with WinZip AES encryption: a salt, a
+// password verifier, the data encrypted with AES in counter mode, and a 10
+// byte HMAC-SHA1 of the encrypted data.
+func decryptZipAES(data []byte, password string, strength byte) ([]byte, bool) {
+	if strength < 1 || strength > 3 {
+		return nil, false
+	}
+	keyLen := 8 * (int(strength) + 1)
+	saltLen := keyLen / 2
+	if len(data) < saltLen+2+10 {
+		return nil, false
+	}
+	salt, verifier := data[:saltLen], data[saltLen:saltLen+2]
+	encrypted, authCode := data[saltLen+2:len(data)-10], data[len(data)-10:]
+
+	key := pbkdf2.Key([]byte(password), salt, 1000, 2*keyLen+2, sha1.New)
+	if !bytes.Equal(key[2*keyLen:], verifier) {
+		return nil, false
+	}
+	mac := hmac.New(sha1.New, key[keyLen:2*keyLen])
+	mac.Write(encrypted)
+	if !hmac.Equal(mac.Sum(nil)[:10], authCode) {
+		return nil, false
+	}
+
+	block, err := aes.NewCipher(key[:keyLen])
+	if err != nil {
+		return nil, false
+	}
+	plain := make([]byte, len(encrypted))
+	winZipCTR(block).XORKeyStream(plain, encrypted)
+	return plain, true
+}
+
+// winZipCTR returns AES in the counter mode of WinZip, whose counter starts at
+// 1 and is little endian.
+func winZipCTR(block cipher.Block) cipher.Stream {
+	return &ctrStream{block: block, counter: make([]byte, aes.BlockSize), keyStream: make([]byte, aes.BlockSize), used: aes.BlockSize}
+}
+
+type ctrStream struct {
+	block     cipher.Block
+	counter   []byte
+	keyStream []byte
+	used      int
+}
+
+func (s *ctrStream) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if s.used == len(s.keyStream) {
+			for j := range s.counter {
+				s.counter[j]++
+				if s.counter[j] != 0 {
+					break
+				}
+			}
+			s.block.Encrypt(s.keyStream, s.counter)
+			s.used = 0
+		}
+		dst[i] = src[i] ^ s.keyStream[s.used]
+		s.used++
+	}
+}
@@ -0,0 +1,77 @@
+package ignore
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Tag marks a line whose findings are not reported. It can be limited to some
+// detectors and give a reason:
+//
+//	key = "AKIA..." # trufflehog:ignore
+//	key = "AKIA..." # trufflehog:ignore[aws,stripe] reason: fake key for tests
+const Tag = "trufflehog:ignore"
+
+var commentPattern = regexp.MustCompile(regexp.QuoteMeta(Tag) + `(?:\[([^\]]*)\])?(?:.*?\breason:\s*(.*))?`)
+
+// Comment is an inline ignore comment.
+type Comment struct {
+	// detectors limits the comment to findings of these detectors. A nil map
+	// applies the comment to all detectors.
+	detectors map[detectorspb.DetectorType]struct{}
+	// Reason explains why findings on the line are not reported.
+	Reason string
+}
+
+// FindComment returns the ignore comment in line, if there is one.
+func FindComment(line []byte) (Comment, bool) {
+	if !bytes.Contains(line, []byte(Tag)) {
+		return Comment{}, false
+	}
+	m := commentPattern.FindSubmatch(line)
+	if m == nil {
+		return Comment{}, false
+	}
+
+	var c Comment
+	if m[1] != nil {
+		// Unknown detectors are dropped rather than failing, so a typo does not
+		// ignore findings of every detector.
+		c.detectors = make(map[detectorspb.DetectorType]struct{})
+		for _, name := range strings.Split(string(m[1]), ",") {
+			if id, err := config.ParseDetector(name); err == nil {
+				c.detectors[id.ID] = struct{}{}
+			}
+		}
+	}
+	c.Reason = cleanReason(string(m[2]))
+	return c, true
+}
+
+// Applies reports whether the comment ignores findings of the detector.
+func (c Comment) Applies(detector detectorspb.DetectorType) bool {
+	if c.detectors == nil {
+		return true
+	}
+	_, ok := c.detectors[detector]
+	return ok
+}
+
+// cleanReason removes everything from the end of the comment, and quotes
+// around a reason.
+func cleanReason(reason string) string {
+	for _, terminator := range []string{"*/", "-->", "#}", "%>"} {
+		if i := strings.Index(reason, terminator); i >= 0 {
+			reason = reason[:i]
+		}
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) >= 2 && (reason[0] == '"' || reason[0] == '\'') && reason[len(reason)-1] == reason[0] {
+		reason = reason[1 : len(reason)-1]
+	}
+	return strings.TrimSpace(reason)
+}
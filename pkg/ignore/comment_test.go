@@ -0,0 +1,52 @@
+package ignore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+func TestFindComment(t *testing.T) {
+	tests := []struct {
+		line    string
+		found   bool
+		applies map[detectorspb.DetectorType]bool
+		reason  string
+	}{
+		{
+			line:    `key = "AKIA" # trufflehog:ignore`,
+			found:   true,
+			applies: map[detectorspb.DetectorType]bool{detectorspb.DetectorType_AWS: true, detectorspb.DetectorType_Stripe: true},
+		},
+		{
+			line:    `key = "AKIA" // trufflehog:ignore[aws, stripe] reason: fake key for tests`,
+			found:   true,
+			applies: map[detectorspb.DetectorType]bool{detectorspb.DetectorType_AWS: true, detectorspb.DetectorType_Stripe: true, detectorspb.DetectorType_Github: false},
+			reason:  "fake key for tests",
+		},
+		{
+			line:    `<!-- trufflehog:ignore reason: "documented example" --> AKIA`,
+			found:   true,
+			applies: map[detectorspb.DetectorType]bool{detectorspb.DetectorType_AWS: true},
+			reason:  "documented example",
+		},
+		{
+			line:    `/* trufflehog:ignore[awss] */ AKIA`,
+			found:   true,
+			applies: map[detectorspb.DetectorType]bool{detectorspb.DetectorType_AWS: false},
+		},
+		{line: `key = "AKIA" # nothing to see`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			c, found := FindComment([]byte(tt.line))
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.reason, c.Reason)
+			for dt, applies := range tt.applies {
+				assert.Equal(t, applies, c.Applies(dt), dt.String())
+			}
+		})
+	}
+}
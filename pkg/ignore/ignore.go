@@ -0,0 +1,176 @@
+// Package ignore parses .trufflehogignore files, which let repository owners
+// exclude paths from scans, or exclude only some detectors for paths.
+//
+// Each line is a gitignore style pattern, optionally followed by a list of
+// detectors the pattern applies to:
+//
+//	# Never scan vendored code.
+//	vendor/
+//	# Test fixtures contain fake AWS and Stripe keys.
+//	testdata/** detectors=aws,stripe
+//	# But do report anything in this one.
+//	!testdata/real.env
+//
+// As in gitignore, the last matching pattern decides whether a path is
+// ignored, and patterns starting with ! include paths again.
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// FileName is the name of the ignore file at the root of a repository or
+// directory.
+const FileName = ".trufflehogignore"
+
+var detectorsPattern = regexp.MustCompile(`^(.*?)\s+detectors=(\S+)$`)
+
+type rule struct {
+	pattern gitignore.Pattern
+	// detectors limits the rule to findings of these detectors. A nil map
+	// applies the rule to the whole path.
+	detectors map[detectorspb.DetectorType]struct{}
+}
+
+// Rules are the parsed rules of an ignore file. A nil *Rules ignores nothing.
+type Rules struct {
+	// root is the directory the ignore file was loaded from. Paths below it
+	// are matched relative to it.
+	root  string
+	rules []rule
+}
+
+// Parse parses the rules of an ignore file.
+func Parse(r io.Reader) (*Rules, error) {
+	rules := &Rules{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var dets map[detectorspb.DetectorType]struct{}
+		if m := detectorsPattern.FindStringSubmatch(line); m != nil {
+			var err error
+			if dets, err = parseDetectors(m[2]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			line = m[1]
+		}
+		rules.rules = append(rules.rules, rule{
+			pattern:   gitignore.ParsePattern(line, nil),
+			detectors: dets,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Load reads the ignore file in dir. It returns nil rules if the directory has
+// no ignore file.
+func Load(dir string) (*Rules, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rules, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(dir, FileName), err)
+	}
+	rules.root = dir
+	return rules, nil
+}
+
+// IgnoresPath reports whether the path is excluded for all detectors, so it
+// does not need to be scanned at all.
+func (r *Rules) IgnoresPath(path string, isDir bool) bool {
+	if r == nil {
+		return false
+	}
+	parts := r.split(path)
+	ignored := false
+	for _, rule := range r.rules {
+		if rule.detectors != nil {
+			continue
+		}
+		switch rule.pattern.Match(parts, isDir) {
+		case gitignore.Exclude:
+			ignored = true
+		case gitignore.Include:
+			ignored = false
+		}
+	}
+	return ignored
+}
+
+// Ignores reports whether findings of the detector in the file at path are
+// excluded.
+func (r *Rules) Ignores(path string, detector detectorspb.DetectorType) bool {
+	if r == nil {
+		return false
+	}
+	parts := r.split(path)
+	ignored := false
+	for _, rule := range r.rules {
+		if rule.detectors != nil {
+			if _, ok := rule.detectors[detector]; !ok {
+				continue
+			}
+		}
+		switch rule.pattern.Match(parts, false) {
+		case gitignore.Exclude:
+			ignored = true
+		case gitignore.Include:
+			ignored = false
+		}
+	}
+	return ignored
+}
+
+// split returns the components of path relative to the root of the rules.
+func (r *Rules) split(path string) []string {
+	if r.root != "" {
+		if rel, err := filepath.Rel(r.root, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			path = rel
+		}
+	}
+	return strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+}
+
+// parseDetectors parses a comma separated list of detectors, in the format
+// of the --include-detectors flag. Versions are ignored, as rules apply to
+// every version of a detector.
+func parseDetectors(list string) (map[detectorspb.DetectorType]struct{}, error) {
+	ids, err := config.ParseDetectors(list)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("empty detector list")
+	}
+	dets := make(map[detectorspb.DetectorType]struct{}, len(ids))
+	for _, id := range ids {
+		dets[id.ID] = struct{}{}
+	}
+	return dets, nil
+}
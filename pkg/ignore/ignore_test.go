@@ -0,0 +1,81 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const testRules = `
+# Vendored code is never scanned.
+vendor/
+*.min.js
+
+# Test fixtures contain fake AWS and Stripe keys.
+testdata/** detectors=aws,STRIPE
+!testdata/real.env detectors=aws
+
+# Detectors are listed like --include-detectors, so versions are accepted.
+docs/** detectors=github.v1
+`
+
+func TestRules(t *testing.T) {
+	rules, err := Parse(strings.NewReader(testRules))
+	require.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		detector detectorspb.DetectorType
+		ignored  bool
+		skipPath bool
+	}{
+		{path: "vendor/lib/a.go", detector: detectorspb.DetectorType_AWS, ignored: true, skipPath: true},
+		{path: "web/app.min.js", detector: detectorspb.DetectorType_Github, ignored: true, skipPath: true},
+		{path: "testdata/keys.txt", detector: detectorspb.DetectorType_AWS, ignored: true},
+		{path: "testdata/keys.txt", detector: detectorspb.DetectorType_Stripe, ignored: true},
+		{path: "testdata/keys.txt", detector: detectorspb.DetectorType_Github},
+		{path: "testdata/real.env", detector: detectorspb.DetectorType_AWS},
+		{path: "testdata/real.env", detector: detectorspb.DetectorType_Stripe, ignored: true},
+		{path: "src/main.go", detector: detectorspb.DetectorType_AWS},
+		{path: "docs/setup.md", detector: detectorspb.DetectorType_Github, ignored: true},
+		{path: "docs/setup.md", detector: detectorspb.DetectorType_AWS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+"/"+tt.detector.String(), func(t *testing.T) {
+			assert.Equal(t, tt.ignored, rules.Ignores(tt.path, tt.detector))
+			assert.Equal(t, tt.skipPath, rules.IgnoresPath(tt.path, false))
+		})
+	}
+}
+
+func TestRules_Nil(t *testing.T) {
+	var rules *Rules
+	assert.False(t, rules.Ignores("a.txt", detectorspb.DetectorType_AWS))
+	assert.False(t, rules.IgnoresPath("a.txt", false))
+}
+
+func TestParse_UnknownDetector(t *testing.T) {
+	_, err := Parse(strings.NewReader("fixtures/ detectors=aws,nope\n"))
+	assert.ErrorContains(t, err, "line 1: unrecognized detector type: nope")
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	rules, err := Load(dir)
+	require.NoError(t, err)
+	assert.Nil(t, rules)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FileName), []byte("fixtures/\n"), 0644))
+	rules, err = Load(dir)
+	require.NoError(t, err)
+	// Paths below the directory are matched relative to it.
+	assert.True(t, rules.IgnoresPath(filepath.Join(dir, "fixtures", "a.txt"), false))
+	assert.True(t, rules.IgnoresPath("fixtures", true))
+	assert.False(t, rules.IgnoresPath(filepath.Join(dir, "src", "a.txt"), false))
+}
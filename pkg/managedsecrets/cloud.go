@@ -0,0 +1,90 @@
+package managedsecrets
+
+import (
+	"errors"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"google.golang.org/api/iterator"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// AWSSecretsManager is AWS Secrets Manager in a region, accessed with the
+// default credentials of the environment.
+type AWSSecretsManager struct {
+	Region string
+}
+
+var _ Store = (*AWSSecretsManager)(nil)
+
+func (s *AWSSecretsManager) Name() string { return "aws secrets manager" }
+
+// Secrets reads the current value of every secret.
+func (s *AWSSecretsManager) Secrets(ctx context.Context, add func(location, value string)) error {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            aws.Config{Region: aws.String(s.Region)},
+	})
+	if err != nil {
+		return err
+	}
+	client := secretsmanager.New(sess)
+	return client.ListSecretsPagesWithContext(ctx, &secretsmanager.ListSecretsInput{},
+		func(page *secretsmanager.ListSecretsOutput, _ bool) bool {
+			for _, secret := range page.SecretList {
+				out, err := client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: secret.ARN})
+				if err != nil {
+					ctx.Logger().V(2).Info("could not read aws secret", "name", aws.StringValue(secret.Name), "error", err)
+					continue
+				}
+				location := "aws:" + aws.StringValue(secret.Name)
+				if out.SecretString != nil {
+					add(location, *out.SecretString)
+				} else {
+					add(location, string(out.SecretBinary))
+				}
+			}
+			return true
+		})
+}
+
+// GCPSecretManager is Secret Manager in a Google Cloud project, accessed with
+// the application default credentials.
+type GCPSecretManager struct {
+	Project string
+}
+
+var _ Store = (*GCPSecretManager)(nil)
+
+func (s *GCPSecretManager) Name() string { return "gcp secret manager" }
+
+// Secrets reads the latest version of every secret.
+func (s *GCPSecretManager) Secrets(ctx context.Context, add func(location, value string)) error {
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	it := client.ListSecrets(ctx, &secretmanagerpb.ListSecretsRequest{Parent: "projects/" + s.Project})
+	for {
+		secret, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		version, err := client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: secret.Name + "/versions/latest"})
+		if err != nil {
+			// The latest version may be disabled or destroyed.
+			ctx.Logger().V(2).Info("could not read gcp secret", "name", secret.Name, "error", err)
+			continue
+		}
+		add("gcp:"+secret.Name, string(version.Payload.Data))
+	}
+}
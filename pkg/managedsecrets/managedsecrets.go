@@ -0,0 +1,111 @@
+// Package managedsecrets checks whether found secrets are stored in a secrets
+// manager, to tell leaked copies of managed secrets from unmanaged secrets.
+// Stores are only read.
+package managedsecrets
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// Labels added to the ExtraData of results.
+const (
+	// LabelKey is set to LabelManaged or LabelUnmanaged.
+	LabelKey       = "secret_manager"
+	LabelManaged   = "managed secret leaked outside manager"
+	LabelUnmanaged = "unmanaged secret"
+	// LocationKey is set to where a managed secret is stored.
+	LocationKey = "secret_manager_location"
+)
+
+// minValueLength is the length below which values in stores, like user names
+// or ports, are not indexed.
+const minValueLength = 8
+
+// Store is a secrets manager that can list the secrets it stores.
+type Store interface {
+	// Name identifies the store in logs.
+	Name() string
+	// Secrets calls add with every secret value in the store and where it
+	// is stored, e.g. vault:secret/app#password.
+	Secrets(ctx context.Context, add func(location, value string)) error
+}
+
+// Index maps hashes of the values stored in secrets managers to their
+// location. It implements engine.ResultLabeler.
+type Index struct {
+	locations map[[sha256.Size]byte]string
+}
+
+// NewIndex reads the secrets of every store. It fails if a store can't be
+// listed.
+func NewIndex(ctx context.Context, stores ...Store) (*Index, error) {
+	idx := &Index{locations: make(map[[sha256.Size]byte]string)}
+	for _, store := range stores {
+		before := len(idx.locations)
+		if err := store.Secrets(ctx, idx.add); err != nil {
+			return nil, fmt.Errorf("could not read the secrets of %s: %w", store.Name(), err)
+		}
+		ctx.Logger().V(2).Info("indexed managed secrets", "store", store.Name(), "count", len(idx.locations)-before)
+	}
+	return idx, nil
+}
+
+// add indexes the value, and the string fields of values that are JSON
+// objects, which is how secrets managers commonly store credentials.
+func (i *Index) add(location, value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minValueLength {
+		return
+	}
+	sum := sha256.Sum256([]byte(value))
+	if _, ok := i.locations[sum]; !ok {
+		i.locations[sum] = location
+	}
+
+	if !strings.HasPrefix(value, "{") {
+		return
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return
+	}
+	for k, v := range fields {
+		if s, ok := v.(string); ok {
+			i.add(location+"#"+k, s)
+		}
+	}
+}
+
+// Lookup returns where value is stored, if it is.
+func (i *Index) Lookup(value string) (string, bool) {
+	location, ok := i.locations[sha256.Sum256([]byte(strings.TrimSpace(value)))]
+	return location, ok
+}
+
+// Label labels the result as a managed or unmanaged secret. The secret of
+// multi-part results, like AWS keys, is looked up without their identifier.
+func (i *Index) Label(_ context.Context, r *detectors.ResultWithMetadata) {
+	candidates := []string{string(r.Raw), string(r.RawV2)}
+	if secret, ok := strings.CutPrefix(string(r.RawV2), string(r.Raw)); ok {
+		candidates = append(candidates, secret)
+	}
+
+	extra := make(map[string]string, len(r.ExtraData)+2)
+	for k, v := range r.ExtraData {
+		extra[k] = v
+	}
+	extra[LabelKey] = LabelUnmanaged
+	for _, candidate := range candidates {
+		if location, ok := i.Lookup(candidate); ok && candidate != "" {
+			extra[LabelKey], extra[LocationKey] = LabelManaged, location
+			break
+		}
+	}
+	r.ExtraData = extra
+}
@@ -0,0 +1,96 @@
+package managedsecrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// Vault is a KV version 2 secrets engine of HashiCorp Vault.
+type Vault struct {
+	// Addr is the address of the Vault server, e.g. https://vault:8200.
+	Addr  string
+	Token string
+	// Mount is the path the KV secrets engine is mounted at. It defaults to
+	// secret.
+	Mount  string
+	Client *http.Client
+}
+
+var _ Store = (*Vault)(nil)
+
+func (v *Vault) Name() string { return "vault" }
+
+// Secrets reads the current version of every secret under the mount.
+func (v *Vault) Secrets(ctx context.Context, add func(location, value string)) error {
+	if v.Mount == "" {
+		v.Mount = "secret"
+	}
+	if v.Client == nil {
+		v.Client = common.SaneHttpClient()
+	}
+	return v.walk(ctx, "", add)
+}
+
+func (v *Vault) walk(ctx context.Context, dir string, add func(location, value string)) error {
+	var list struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+	if err := v.get(ctx, "LIST", "metadata/"+dir, &list); err != nil {
+		return err
+	}
+	for _, key := range list.Data.Keys {
+		path := dir + key
+		if strings.HasSuffix(key, "/") {
+			if err := v.walk(ctx, path, add); err != nil {
+				return err
+			}
+			continue
+		}
+		var secret struct {
+			Data struct {
+				Data map[string]any `json:"data"`
+			} `json:"data"`
+		}
+		if err := v.get(ctx, "GET", "data/"+path, &secret); err != nil {
+			// Policies may allow listing secrets that can't be read.
+			ctx.Logger().V(2).Info("could not read vault secret", "path", path, "error", err)
+			continue
+		}
+		for field, value := range secret.Data.Data {
+			if s, ok := value.(string); ok {
+				add(fmt.Sprintf("vault:%s/%s#%s", v.Mount, path, field), s)
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Vault) get(ctx context.Context, method, path string, out any) error {
+	u := strings.TrimSuffix(v.Addr, "/") + "/v1/" + v.Mount + "/" + (&url.URL{Path: path}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	res, err := v.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusNotFound && method == "LIST":
+		// Empty directories are not found.
+		return nil
+	case res.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s %s returned status %d", method, u, res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,257 @@
+// Package notify delivers reports about finished scans to people.
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// EmailConfig configures how scan reports are emailed.
+type EmailConfig struct {
+	// Server is the SMTP server as host:port. Port 465 uses implicit TLS, all
+	// other ports use STARTTLS if the server supports it.
+	Server   string
+	Username string
+	Password string
+	From     string
+	To       []string
+	// Subject is prefixed to the generated subject line.
+	Subject string
+	// Attachments are paths of files attached to the email, e.g. an encrypted
+	// report.
+	Attachments []string
+}
+
+// sendMail sends a message. It is a variable so tests can capture messages.
+var sendMail = send
+
+// EmailSummary emails the summary of a scan, and any configured attachments, to
+// the configured recipients.
+func EmailSummary(cfg EmailConfig, summary engine.Summary) error {
+	if cfg.Server == "" {
+		return errors.New("email: SMTP server is required")
+	}
+	if cfg.From == "" {
+		return errors.New("email: sender is required")
+	}
+	if len(cfg.To) == 0 {
+		return errors.New("email: at least one recipient is required")
+	}
+
+	msg, err := buildMessage(cfg, summary, time.Now())
+	if err != nil {
+		return fmt.Errorf("email: could not build message: %w", err)
+	}
+	if err := sendMail(cfg, msg); err != nil {
+		return fmt.Errorf("email: could not send message: %w", err)
+	}
+	return nil
+}
+
+// buildMessage returns the MIME message for the summary.
+func buildMessage(cfg EmailConfig, summary engine.Summary, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	text, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"8bit"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := text.Write([]byte(formatSummary(summary))); err != nil {
+		return nil, err
+	}
+
+	for _, path := range cfg.Attachments {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read attachment: %w", err)
+		}
+		name := filepath.Base(path)
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType("application/octet-stream", map[string]string{"name": name})},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, data); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	headers := []struct{ key, value string }{
+		{"From", cfg.From},
+		{"To", strings.Join(cfg.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject(cfg.Subject, summary))},
+		{"Date", now.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()})},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h.key, h.value)
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// writeBase64 writes data base64 encoded in lines of 76 characters.
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := 76
+		if len(encoded) < n {
+			n = len(encoded)
+		}
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:n]); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
+
+func subject(prefix string, summary engine.Summary) string {
+	s := fmt.Sprintf("TruffleHog scan found %d verified and %d unverified secrets",
+		summary.VerifiedSecretsFound, summary.UnverifiedSecretsFound)
+	if prefix != "" {
+		s = prefix + " " + s
+	}
+	return s
+}
+
+// formatSummary renders the summary as plain text.
+func formatSummary(summary engine.Summary) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "TruffleHog finished scanning in %s.\n\n", summary.ScanDuration.Round(time.Second))
+	fmt.Fprintf(&sb, "Verified secrets:   %d\n", summary.VerifiedSecretsFound)
+	fmt.Fprintf(&sb, "Unverified secrets: %d\n", summary.UnverifiedSecretsFound)
+	fmt.Fprintf(&sb, "Chunks scanned:     %d\n", summary.ChunksScanned)
+	fmt.Fprintf(&sb, "Bytes scanned:      %d\n", summary.BytesScanned)
+
+	if len(summary.Sources) > 0 {
+		sb.WriteString("\nSources:\n")
+		for _, src := range summary.Sources {
+			fmt.Fprintf(&sb, "  %s (%s): %d verified, %d unverified, %d chunks, %d bytes\n",
+				src.Name, src.Type, src.VerifiedSecretsFound, src.UnverifiedSecretsFound, src.ChunksScanned, src.BytesScanned)
+
+			reasons := make([]sources.SkipReason, 0, len(src.Skipped))
+			for reason := range src.Skipped {
+				reasons = append(reasons, reason)
+			}
+			sort.Slice(reasons, func(i, j int) bool { return reasons[i] < reasons[j] })
+			for _, reason := range reasons {
+				fmt.Fprintf(&sb, "    skipped (%s): %d", reason, src.Skipped[reason])
+				if bytes := src.SkippedBytes[reason]; bytes > 0 {
+					fmt.Fprintf(&sb, ", %d bytes", bytes)
+				}
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	if len(summary.Detectors) > 0 {
+		sb.WriteString("\nDetectors:\n")
+		for _, det := range summary.Detectors {
+			fmt.Fprintf(&sb, "  %s: %d verified, %d unverified", det.Name, det.Verified, det.Unverified)
+			if det.Suppressed > 0 {
+				fmt.Fprintf(&sb, ", %d suppressed", det.Suppressed)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(summary.Suppressed) > 0 {
+		sb.WriteString("\nSuppressed findings:\n")
+		for _, supp := range summary.Suppressed {
+			fmt.Fprintf(&sb, "  %s in %s", supp.Detector, supp.SourceName)
+			if supp.File != "" {
+				fmt.Fprintf(&sb, " %s:%d", supp.File, supp.Line)
+			}
+			fmt.Fprintf(&sb, " (%s)", supp.Method)
+			if supp.Reason != "" {
+				fmt.Fprintf(&sb, ": %s", supp.Reason)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// send delivers the message with the configured SMTP server.
+func send(cfg EmailConfig, msg []byte) error {
+	host, port, err := net.SplitHostPort(cfg.Server)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP server: %w", err)
+	}
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+	if port != "465" {
+		return smtp.SendMail(cfg.Server, auth, cfg.From, cfg.To, msg)
+	}
+
+	// Port 465 expects TLS from the start, which smtp.SendMail does not support.
+	tlsConfig := common.TLSClientConfig()
+	tlsConfig.ServerName = host
+	conn, err := tls.Dial("tcp", cfg.Server, tlsConfig)
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, to := range cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
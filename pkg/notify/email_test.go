@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func testSummary() engine.Summary {
+	return engine.Summary{
+		ScanDuration:           90 * time.Second,
+		BytesScanned:           2048,
+		ChunksScanned:          12,
+		VerifiedSecretsFound:   1,
+		UnverifiedSecretsFound: 2,
+		Sources: []engine.SourceSummary{{
+			Name:                 "repo",
+			Type:                 "SOURCE_TYPE_GIT",
+			ChunksScanned:        12,
+			BytesScanned:         2048,
+			VerifiedSecretsFound: 1,
+			Skipped:              map[sources.SkipReason]uint64{sources.SkipReasonSize: 3},
+		}},
+		Detectors: []engine.DetectorSummary{{Name: "AWS", Verified: 1, Unverified: 2, Suppressed: 1}},
+		Suppressed: []engine.Suppression{{
+			SourceName: "repo",
+			Detector:   "AWS",
+			File:       "test/fixture.env",
+			Line:       4,
+			Method:     engine.SuppressedByComment,
+			Reason:     "fake key",
+		}},
+	}
+}
+
+func TestEmailSummary(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "report.age")
+	require.NoError(t, os.WriteFile(report, []byte("encrypted report"), 0600))
+
+	var sent []byte
+	var sentCfg EmailConfig
+	sendMail = func(cfg EmailConfig, msg []byte) error {
+		sentCfg, sent = cfg, msg
+		return nil
+	}
+	t.Cleanup(func() { sendMail = send })
+
+	cfg := EmailConfig{
+		Server:      "smtp.example.com:587",
+		From:        "trufflehog@example.com",
+		To:          []string{"security@example.com", "oncall@example.com"},
+		Subject:     "[nightly]",
+		Attachments: []string{report},
+	}
+	require.NoError(t, EmailSummary(cfg, testSummary()))
+	assert.Equal(t, cfg.To, sentCfg.To)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(sent)))
+	require.NoError(t, err)
+	assert.Equal(t, "security@example.com, oncall@example.com", msg.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "[nightly] TruffleHog scan found 1 verified and 2 unverified secrets", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	text, err := mr.NextPart()
+	require.NoError(t, err)
+	body, err := io.ReadAll(text)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "finished scanning in 1m30s")
+	assert.Contains(t, string(body), "repo (SOURCE_TYPE_GIT): 1 verified, 0 unverified, 12 chunks, 2048 bytes")
+	assert.Contains(t, string(body), "skipped (size): 3")
+	assert.Contains(t, string(body), "AWS: 1 verified, 2 unverified, 1 suppressed")
+	assert.Contains(t, string(body), "AWS in repo test/fixture.env:4 (comment): fake key")
+
+	attachment, err := mr.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "report.age", attachment.FileName())
+	// multipart.Part decodes quoted-printable only, so decode base64 by hand.
+	encoded, err := io.ReadAll(attachment)
+	require.NoError(t, err)
+	assert.Equal(t, "ZW5jcnlwdGVkIHJlcG9ydA==", strings.TrimSpace(string(encoded)))
+}
+
+func TestEmailSummary_InvalidConfig(t *testing.T) {
+	assert.Error(t, EmailSummary(EmailConfig{From: "a@example.com", To: []string{"b@example.com"}}, engine.Summary{}))
+	assert.Error(t, EmailSummary(EmailConfig{Server: "smtp.example.com:25", To: []string{"b@example.com"}}, engine.Summary{}))
+	assert.Error(t, EmailSummary(EmailConfig{Server: "smtp.example.com:25", From: "a@example.com"}, engine.Summary{}))
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// DryRunPrinter prints the targets sources would scan in dry runs, one per
+// line, and their totals with PrintTotals. It prints JSON lines if JSON is set.
+type DryRunPrinter struct {
+	JSON bool
+
+	mu      sync.Mutex
+	targets int
+	objects int64
+	bytes   int64
+	// partial is set if the size of some targets is unknown.
+	partial bool
+}
+
+// ReportTarget implements sources.TargetReporter.
+func (p *DryRunPrinter) ReportTarget(t sources.Target) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.targets++
+	if t.Objects >= 0 {
+		p.objects += t.Objects
+	}
+	if t.Bytes >= 0 {
+		p.bytes += t.Bytes
+	} else {
+		p.partial = true
+	}
+
+	if p.JSON {
+		out, err := json.Marshal(t)
+		if err == nil {
+			fmt.Fprintln(writer, string(out))
+		}
+		return
+	}
+	fmt.Fprintf(writer, "%s\t%s\t%s objects\t%s\n", t.SourceType, t.Name, countString(t.Objects), bytesString(t.Bytes))
+}
+
+// PrintTotals prints the number of targets and their total size. It prints
+// nothing in JSON mode.
+func (p *DryRunPrinter) PrintTotals() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.JSON {
+		return
+	}
+	size := bytesString(p.bytes)
+	if p.partial {
+		size = "at least " + size
+	}
+	greenPrinter.Printf("\nWould scan %d targets with %d objects and %s.\n", p.targets, p.objects, size)
+}
+
+func countString(n int64) string {
+	if n < 0 {
+		return "unknown"
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// bytesString formats a size in bytes for humans, e.g. 1.5 MiB.
+func bytesString(n int64) string {
+	if n < 0 {
+		return "unknown size"
+	}
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 4; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTP"[exp])
+}
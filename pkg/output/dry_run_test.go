@@ -0,0 +1,40 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestDryRunPrinter(t *testing.T) {
+	oldWriter, oldOutput, oldNoColor := writer, color.Output, color.NoColor
+	t.Cleanup(func() { writer, color.Output, color.NoColor = oldWriter, oldOutput, oldNoColor })
+	var buf bytes.Buffer
+	SetWriter(&buf)
+
+	p := new(DryRunPrinter)
+	p.ReportTarget(sources.Target{SourceType: sourcespb.SourceType_SOURCE_TYPE_S3, Name: "backups", Objects: 12, Bytes: 3 << 20})
+	p.ReportTarget(sources.Target{SourceType: sourcespb.SourceType_SOURCE_TYPE_GIT, Name: "https://github.com/org/repo", Objects: -1, Bytes: -1})
+	p.PrintTotals()
+	assert.Equal(t, "SOURCE_TYPE_S3\tbackups\t12 objects\t3.0 MiB\n"+
+		"SOURCE_TYPE_GIT\thttps://github.com/org/repo\tunknown objects\tunknown size\n"+
+		"\nWould scan 2 targets with 12 objects and at least 3.0 MiB.\n", buf.String())
+
+	buf.Reset()
+	p = &DryRunPrinter{JSON: true}
+	p.ReportTarget(sources.Target{SourceName: "s3", SourceType: sourcespb.SourceType_SOURCE_TYPE_S3, Name: "backups", Objects: 1, Bytes: 512})
+	p.PrintTotals()
+	assert.Equal(t, `{"SourceName":"s3","SourceType":13,"Name":"backups","Objects":1,"Bytes":512}`+"\n", buf.String())
+}
+
+func TestBytesString(t *testing.T) {
+	assert.Equal(t, "0 B", bytesString(0))
+	assert.Equal(t, "1023 B", bytesString(1023))
+	assert.Equal(t, "1.5 KiB", bytesString(1536))
+	assert.Equal(t, "2.0 GiB", bytesString(2<<30))
+}
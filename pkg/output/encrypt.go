@@ -0,0 +1,111 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgpArmor "github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+const pgpPublicKeyHeader = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+
+// NewEncryptingWriter returns a writer that encrypts everything written to it
+// for the given recipients and writes the ASCII armored ciphertext to w. The
+// returned writer must be closed to flush the encrypted data, which does not
+// close w.
+//
+// Each recipient is either an age public key ("age1...") or the path to a file
+// containing age public keys, one per line, or ASCII armored PGP public keys.
+// Age and PGP recipients cannot be mixed.
+func NewEncryptingWriter(w io.Writer, recipients []string) (io.WriteCloser, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients provided")
+	}
+
+	var (
+		ageRecipients []age.Recipient
+		pgpRecipients openpgp.EntityList
+	)
+	for _, spec := range recipients {
+		if strings.HasPrefix(spec, "age1") {
+			r, err := age.ParseX25519Recipient(spec)
+			if err != nil {
+				return nil, fmt.Errorf("invalid age recipient %q: %w", spec, err)
+			}
+			ageRecipients = append(ageRecipients, r)
+			continue
+		}
+
+		data, err := os.ReadFile(spec)
+		if err != nil {
+			return nil, fmt.Errorf("could not read recipients file: %w", err)
+		}
+		if bytes.Contains(data, []byte(pgpPublicKeyHeader)) {
+			keys, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("invalid PGP public key in %q: %w", spec, err)
+			}
+			pgpRecipients = append(pgpRecipients, keys...)
+			continue
+		}
+		rs, err := age.ParseRecipients(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipients in %q: %w", spec, err)
+		}
+		ageRecipients = append(ageRecipients, rs...)
+	}
+
+	switch {
+	case len(ageRecipients) > 0 && len(pgpRecipients) > 0:
+		return nil, errors.New("age and PGP recipients cannot be mixed")
+	case len(pgpRecipients) > 0:
+		return newPGPWriter(w, pgpRecipients)
+	default:
+		return newAgeWriter(w, ageRecipients)
+	}
+}
+
+func newAgeWriter(w io.Writer, recipients []age.Recipient) (io.WriteCloser, error) {
+	armored := armor.NewWriter(w)
+	encrypted, err := age.Encrypt(armored, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("could not encrypt output: %w", err)
+	}
+	return &encryptingWriter{encrypted: encrypted, armored: armored}, nil
+}
+
+func newPGPWriter(w io.Writer, recipients openpgp.EntityList) (io.WriteCloser, error) {
+	armored, err := pgpArmor.Encode(w, "PGP MESSAGE", nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not encrypt output: %w", err)
+	}
+	encrypted, err := openpgp.Encrypt(armored, recipients, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not encrypt output: %w", err)
+	}
+	return &encryptingWriter{encrypted: encrypted, armored: armored}, nil
+}
+
+// encryptingWriter closes the encryption and armor layers in order.
+type encryptingWriter struct {
+	encrypted io.WriteCloser
+	armored   io.WriteCloser
+}
+
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	return e.encrypted.Write(p)
+}
+
+func (e *encryptingWriter) Close() error {
+	if err := e.encrypted.Close(); err != nil {
+		return err
+	}
+	return e.armored.Close()
+}
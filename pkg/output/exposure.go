@@ -0,0 +1,159 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/workstation"
+)
+
+// ExposurePrinter collects the results of a workstation scan and prints them
+// when Flush is called, as a report ordered by risk: verified secrets first,
+// then by how exposed the location they were found in is. Secrets themselves
+// are never printed.
+type ExposurePrinter struct {
+	mu        sync.Mutex
+	jsonOut   bool
+	out       io.Writer
+	home      string
+	locations []workstation.Location
+	findings  []exposure
+}
+
+// exposure is a secret found in a location of a workstation.
+type exposure struct {
+	Risk         string
+	Kind         string
+	File         string
+	Line         int64
+	DetectorName string
+	Verified     bool
+	Advice       string
+
+	risk workstation.Risk
+}
+
+// NewExposurePrinter returns an ExposurePrinter for the locations of a
+// workstation, writing to the configured output. Paths below home are
+// shortened to ~.
+func NewExposurePrinter(jsonOut bool, home string, locations []workstation.Location) *ExposurePrinter {
+	return newExposurePrinter(jsonOut, home, locations, writer)
+}
+
+func newExposurePrinter(jsonOut bool, home string, locations []workstation.Location, out io.Writer) *ExposurePrinter {
+	return &ExposurePrinter{jsonOut: jsonOut, out: out, home: home, locations: locations}
+}
+
+func (p *ExposurePrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	file := r.SourceMetadata.GetFilesystem().GetFile()
+	finding := exposure{
+		Kind:         "other",
+		File:         file,
+		Line:         r.SourceMetadata.GetFilesystem().GetLine(),
+		DetectorName: r.DetectorType.String(),
+		Verified:     r.Verified,
+		risk:         workstation.RiskLow,
+	}
+	if r.DetectorName != "" {
+		finding.DetectorName = r.DetectorName
+	}
+	if location, ok := workstation.Locate(p.locations, file); ok {
+		finding.Kind, finding.Advice, finding.risk = location.Kind, location.Advice, location.Risk
+	}
+	finding.Risk = finding.risk.String()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.findings = append(p.findings, finding)
+	return nil
+}
+
+// Flush prints the collected findings, riskiest first.
+func (p *ExposurePrinter) Flush(_ context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	findings := p.findings
+	p.findings = nil
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Verified != b.Verified {
+			return a.Verified
+		}
+		if a.risk != b.risk {
+			return a.risk > b.risk
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	if p.jsonOut {
+		for _, finding := range findings {
+			out, err := json.Marshal(finding)
+			if err != nil {
+				return fmt.Errorf("could not marshal finding: %w", err)
+			}
+			if _, err := fmt.Fprintln(p.out, string(out)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return p.writePlain(findings)
+}
+
+func (p *ExposurePrinter) writePlain(findings []exposure) error {
+	var sb strings.Builder
+	files := make(map[string]bool)
+	for _, finding := range findings {
+		files[finding.File] = true
+	}
+	fmt.Fprintf(&sb, "Local exposure report: %d secret(s) in %d file(s), %d location(s) checked\n\n", len(findings), len(files), len(p.locations))
+
+	advice := make(map[string]string)
+	var kinds []string
+	for _, finding := range findings {
+		status := "unverified"
+		if finding.Verified {
+			status = "verified"
+		}
+		location := p.shorten(finding.File)
+		if finding.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, finding.Line)
+		}
+		fmt.Fprintf(&sb, "%-9s %-11s %-20s %s (%s)\n", strings.ToUpper(finding.Risk), status, finding.DetectorName, location, finding.Kind)
+		if _, ok := advice[finding.Kind]; !ok && finding.Advice != "" {
+			advice[finding.Kind] = finding.Advice
+			kinds = append(kinds, finding.Kind)
+		}
+	}
+	if len(kinds) > 0 {
+		sb.WriteString("\nRecommendations:\n")
+		for _, kind := range kinds {
+			fmt.Fprintf(&sb, "  %s: %s\n", kind, advice[kind])
+		}
+	}
+	_, err := io.WriteString(p.out, sb.String())
+	return err
+}
+
+// shorten replaces the home directory at the start of path with ~.
+func (p *ExposurePrinter) shorten(path string) string {
+	if p.home == "" {
+		return path
+	}
+	if rel, ok := strings.CutPrefix(path, p.home+string(os.PathSeparator)); ok {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
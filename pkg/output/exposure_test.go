@@ -0,0 +1,37 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/workstation"
+)
+
+func TestExposurePrinter(t *testing.T) {
+	ctx := context.Background()
+	locations := []workstation.Location{
+		{Path: "/home/dev/.aws", Kind: "cloud CLI credentials", Risk: workstation.RiskCritical, Advice: "use SSO"},
+		{Path: "/home/dev/.bash_history", Kind: "shell history", Risk: workstation.RiskMedium},
+	}
+	var out bytes.Buffer
+	p := newExposurePrinter(false, "/home/dev", locations, &out)
+
+	assert.NoError(t, p.Print(ctx, fsResult("/home/dev/.bash_history", "raw-1", false)))
+	assert.NoError(t, p.Print(ctx, fsResult("/home/dev/.aws/credentials", "raw-2", false)))
+	assert.NoError(t, p.Print(ctx, fsResult("/home/dev/.bash_history", "raw-3", true)))
+	assert.Empty(t, out.String())
+
+	assert.NoError(t, p.Flush(ctx))
+	report := out.String()
+	assert.NotContains(t, report, "raw-")
+	lines := strings.Split(report, "\n")
+	assert.Equal(t, "Local exposure report: 3 secret(s) in 2 file(s), 2 location(s) checked", lines[0])
+	assert.Contains(t, lines[2], "MEDIUM    verified")
+	assert.Contains(t, lines[3], "CRITICAL  unverified")
+	assert.Contains(t, lines[3], "~/.aws/credentials (cloud CLI credentials)")
+	assert.Contains(t, report, "cloud CLI credentials: use SSO")
+}
@@ -4,11 +4,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
 var dedupeCache = make(map[string]struct{})
@@ -59,13 +61,25 @@ func (p *GitHubActionsPrinter) Print(_ context.Context, r *detectors.ResultWithM
 
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	message := fmt.Sprintf("Found %s %s result 🐷🔑\n", verifiedStatus, out.DetectorType)
+	var where string
+	if path := sources.ArchivePath(r.SourceMetadata); path != "" {
+		where = " in " + path
+	}
+	message := fmt.Sprintf("Found %s %s result%s 🐷🔑\n", verifiedStatus, out.DetectorType, where)
 	if r.Result.DecoderType != detectorspb.DecoderType_PLAIN {
-		message = fmt.Sprintf("Found %s %s result with %s encoding 🐷🔑\n", verifiedStatus, out.DetectorType, out.DecoderType)
+		message = fmt.Sprintf("Found %s %s result with %s encoding%s 🐷🔑\n", verifiedStatus, out.DetectorType, out.DecoderType, where)
+	}
+
+	if link := resultLink(r); link != "" {
+		message = strings.TrimSuffix(message, "\n") + " " + link + "\n"
 	}
 
-	fmt.Printf("::warning file=%s,line=%d,endLine=%d::%s",
-		out.Filename, out.StartLine, out.StartLine, message)
+	var column string
+	if r.Column > 0 {
+		column = fmt.Sprintf(",col=%d", r.Column)
+	}
+	fmt.Fprintf(writer, "::warning file=%s,line=%d,endLine=%d%s::%s",
+		out.Filename, out.StartLine, out.StartLine, column, message)
 
 	return nil
 }
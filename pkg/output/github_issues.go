@@ -0,0 +1,371 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v42/github"
+	"golang.org/x/oauth2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+const githubAPIEndpoint = "https://api.github.com"
+
+// GitHubConfig configures a GitHubPrinter.
+type GitHubConfig struct {
+	Token string
+	// Endpoint is the API URL of a GitHub Enterprise Server instance. It
+	// defaults to github.com.
+	Endpoint string
+	// Repository is the "owner/name" of the repository containing
+	// PullRequest.
+	Repository string
+	// PullRequest is the number of the pull request to comment on. If it is
+	// zero, an issue is opened in the repository each secret was found in.
+	PullRequest int
+	// Labels are added to opened issues in addition to "trufflehog".
+	Labels []string
+	// AssignAuthor assigns opened issues to the author of the commit that
+	// introduced the secret, if they have a GitHub account.
+	AssignAuthor bool
+	// Redact is applied to secrets included in comments and issues.
+	Redact RedactMode
+}
+
+// GitHubPrinter is a printer that reports findings in GitHub repositories
+// back to GitHub. It either comments on the offending lines of a pull request,
+// or opens an issue in the repository for each finding. Findings that have
+// already been reported in an open issue or in the pull request are skipped.
+//
+// Findings that are not in a GitHub repository are ignored. The printer needs
+// unredacted results to fingerprint secrets.
+type GitHubPrinter struct {
+	mu     sync.Mutex
+	cfg    GitHubConfig
+	client *github.Client
+	host   string
+	// seen contains the fingerprint labels handled during this scan.
+	seen map[string]struct{}
+	// reported contains the fingerprint labels already present in comments
+	// on the pull request. It is loaded on first use.
+	reported map[string]struct{}
+}
+
+// githubLocation is where in a git repository a secret was found.
+type githubLocation struct {
+	owner, repo string
+	commit      string
+	file        string
+	line        int
+	link        string
+}
+
+// NewGitHubPrinter validates the configuration and returns a GitHubPrinter.
+func NewGitHubPrinter(cfg GitHubConfig) (*GitHubPrinter, error) {
+	if cfg.Token == "" {
+		return nil, errors.New("github: token is required")
+	}
+	if cfg.PullRequest != 0 {
+		if _, _, ok := strings.Cut(cfg.Repository, "/"); !ok {
+			return nil, errors.New("github: repository must be in the form owner/name when commenting on a pull request")
+		}
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = githubAPIEndpoint
+	}
+
+	httpClient := common.RetryableHttpClientTimeout(30)
+	httpClient.Transport = &oauth2.Transport{
+		Base:   httpClient.Transport,
+		Source: oauth2.ReuseTokenSource(nil, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cfg.Token})),
+	}
+	client, host, err := newGitHubClient(httpClient, cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &GitHubPrinter{
+		cfg:    cfg,
+		client: client,
+		host:   host,
+		seen:   make(map[string]struct{}),
+	}, nil
+}
+
+// newGitHubClient returns a client for the API endpoint and the host that
+// repositories are served from.
+func newGitHubClient(httpClient *http.Client, endpoint string) (*github.Client, string, error) {
+	if endpoint == githubAPIEndpoint {
+		return github.NewClient(httpClient), "github.com", nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, "", fmt.Errorf("github: invalid endpoint: %w", err)
+	}
+	client, err := github.NewEnterpriseClient(endpoint, endpoint, httpClient)
+	if err != nil {
+		return nil, "", fmt.Errorf("github: invalid endpoint: %w", err)
+	}
+	return client, u.Hostname(), nil
+}
+
+func (p *GitHubPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	loc, ok, err := p.location(r)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		ctx.Logger().V(3).Info("skipping finding outside of a GitHub repository")
+		return nil
+	}
+	group, err := singleFindingGroup(r, p.cfg.Redact)
+	if err != nil {
+		return err
+	}
+	label := fingerprintLabel(group.fingerprint)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.seen[label]; ok {
+		return nil
+	}
+	p.seen[label] = struct{}{}
+
+	if p.cfg.PullRequest != 0 {
+		return p.commentOnPullRequest(ctx, group, loc)
+	}
+	return p.openIssue(ctx, group, loc)
+}
+
+// location returns where the secret was found. It returns false if the
+// result is not from a GitHub repository, or when commenting on a pull
+// request, not from the pull request's repository.
+func (p *GitHubPrinter) location(r *detectors.ResultWithMetadata) (githubLocation, bool, error) {
+	meta, err := structToMap(r.SourceMetadata.GetData())
+	if err != nil {
+		return githubLocation{}, false, fmt.Errorf("could not marshal result: %w", err)
+	}
+	var loc githubLocation
+	var repository string
+	for _, data := range meta {
+		repository, _ = data["repository"].(string)
+		loc.commit, _ = data["commit"].(string)
+		loc.file, _ = data["file"].(string)
+		loc.link, _ = data["link"].(string)
+		if line, ok := data["line"].(float64); ok {
+			loc.line = int(line)
+		}
+	}
+
+	var ok bool
+	loc.owner, loc.repo, ok = parseGitHubRepository(repository, p.host)
+	if !ok {
+		return loc, false, nil
+	}
+	if p.cfg.PullRequest != 0 && !strings.EqualFold(loc.owner+"/"+loc.repo, p.cfg.Repository) {
+		return loc, false, nil
+	}
+	return loc, true, nil
+}
+
+// commentOnPullRequest comments on the line of the pull request the secret was
+// found on. If the line is not part of the diff, it comments on the pull
+// request instead. The caller must hold the lock.
+func (p *GitHubPrinter) commentOnPullRequest(ctx context.Context, group *secretGroup, loc githubLocation) error {
+	if err := p.loadReported(ctx, loc); err != nil {
+		return err
+	}
+	label := fingerprintLabel(group.fingerprint)
+	if _, ok := p.reported[label]; ok {
+		ctx.Logger().V(2).Info("skipping finding already reported on pull request")
+		return nil
+	}
+
+	body := githubBody(p.host, group, loc)
+	if loc.file != "" && loc.line > 0 && loc.commit != "" {
+		_, _, err := p.client.PullRequests.CreateComment(ctx, loc.owner, loc.repo, p.cfg.PullRequest, &github.PullRequestComment{
+			Body:     github.String(body),
+			CommitID: github.String(loc.commit),
+			Path:     github.String(loc.file),
+			Line:     github.Int(loc.line),
+			Side:     github.String("RIGHT"),
+		})
+		if err == nil {
+			return nil
+		}
+		ctx.Logger().V(2).Info("could not comment on pull request line, commenting on pull request", "error", err)
+	}
+	_, _, err := p.client.Issues.CreateComment(ctx, loc.owner, loc.repo, p.cfg.PullRequest, &github.IssueComment{
+		Body: github.String(body),
+	})
+	if err != nil {
+		return fmt.Errorf("github: could not comment on pull request: %w", err)
+	}
+	return nil
+}
+
+// loadReported collects the fingerprints of findings already reported on the
+// pull request. The caller must hold the lock.
+func (p *GitHubPrinter) loadReported(ctx context.Context, loc githubLocation) error {
+	if p.reported != nil {
+		return nil
+	}
+	reported := make(map[string]struct{})
+	addBody := func(body string) {
+		for _, field := range strings.Fields(body) {
+			field = strings.Trim(field, "`")
+			if strings.HasPrefix(field, "trufflehog-") {
+				reported[field] = struct{}{}
+			}
+		}
+	}
+
+	reviewOpts := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := p.client.PullRequests.ListComments(ctx, loc.owner, loc.repo, p.cfg.PullRequest, reviewOpts)
+		if err != nil {
+			return fmt.Errorf("github: could not list pull request comments: %w", err)
+		}
+		for _, c := range comments {
+			addBody(c.GetBody())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		reviewOpts.Page = resp.NextPage
+	}
+
+	issueOpts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := p.client.Issues.ListComments(ctx, loc.owner, loc.repo, p.cfg.PullRequest, issueOpts)
+		if err != nil {
+			return fmt.Errorf("github: could not list pull request comments: %w", err)
+		}
+		for _, c := range comments {
+			addBody(c.GetBody())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		issueOpts.Page = resp.NextPage
+	}
+
+	p.reported = reported
+	return nil
+}
+
+// openIssue opens an issue for the finding unless an open issue for it
+// exists. The caller must hold the lock.
+func (p *GitHubPrinter) openIssue(ctx context.Context, group *secretGroup, loc githubLocation) error {
+	label := fingerprintLabel(group.fingerprint)
+	query := fmt.Sprintf("repo:%s/%s is:issue is:open in:body %s", loc.owner, loc.repo, label)
+	existing, _, err := p.client.Search.Issues(ctx, query, &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}})
+	if err != nil {
+		return fmt.Errorf("github: could not search for existing issues: %w", err)
+	}
+	if existing.GetTotal() > 0 {
+		ctx.Logger().V(2).Info("skipping finding with open github issue", "issue", existing.Issues[0].GetHTMLURL())
+		return nil
+	}
+
+	req := &github.IssueRequest{
+		Title:  github.String(group.title()),
+		Body:   github.String(githubBody(p.host, group, loc)),
+		Labels: &[]string{"trufflehog"},
+	}
+	*req.Labels = append(*req.Labels, p.cfg.Labels...)
+	if p.cfg.AssignAuthor && loc.commit != "" {
+		commit, _, err := p.client.Repositories.GetCommit(ctx, loc.owner, loc.repo, loc.commit, nil)
+		if err != nil {
+			ctx.Logger().V(2).Info("could not look up commit author", "commit", loc.commit, "error", err)
+		} else if login := commit.GetAuthor().GetLogin(); login != "" {
+			req.Assignees = &[]string{login}
+		}
+	}
+
+	issue, _, err := p.client.Issues.Create(ctx, loc.owner, loc.repo, req)
+	if err != nil {
+		return fmt.Errorf("github: could not open issue: %w", err)
+	}
+	ctx.Logger().Info("opened github issue", "issue", issue.GetHTMLURL())
+	return nil
+}
+
+// githubBody renders a comment or issue body in GitHub flavored markdown.
+func githubBody(host string, group *secretGroup, loc githubLocation) string {
+	status := "an unverified"
+	if group.result.Verified {
+		status = "a **verified**"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "TruffleHog found %s %s secret", status, group.detectorName())
+	if loc.file != "" {
+		fmt.Fprintf(&sb, " in `%s`", loc.file)
+		if loc.line > 0 {
+			fmt.Fprintf(&sb, " on line %d", loc.line)
+		}
+	}
+	sb.WriteString(".\n\n")
+	fmt.Fprintf(&sb, "- **Secret:** `%s`\n", group.secret())
+	if loc.commit != "" {
+		fmt.Fprintf(&sb, "- **Commit:** %s\n", loc.commit)
+	}
+	if link := githubLink(host, loc); link != "" {
+		fmt.Fprintf(&sb, "- **Link:** %s\n", link)
+	}
+	fmt.Fprintf(&sb, "- **Fingerprint:** `%s`\n\n", fingerprintLabel(group.fingerprint))
+	fmt.Fprintf(&sb, "**Remediation:** %s\n", remediationHint(group.result.DetectorType))
+	return sb.String()
+}
+
+// githubLink returns a link to the secret at the commit it was found in.
+func githubLink(host string, loc githubLocation) string {
+	if loc.link != "" {
+		return loc.link
+	}
+	if loc.commit == "" {
+		return ""
+	}
+	if loc.file == "" {
+		return fmt.Sprintf("https://%s/%s/%s/commit/%s", host, loc.owner, loc.repo, loc.commit)
+	}
+	link := fmt.Sprintf("https://%s/%s/%s/blob/%s/%s", host, loc.owner, loc.repo, loc.commit, strings.TrimPrefix(loc.file, "/"))
+	if loc.line > 0 {
+		link += fmt.Sprintf("#L%d", loc.line)
+	}
+	return link
+}
+
+// parseGitHubRepository returns the owner and name of a repository URL on the
+// given host. It supports HTTPS and SSH URLs.
+func parseGitHubRepository(repository, host string) (string, string, bool) {
+	var path string
+	switch {
+	case strings.HasPrefix(repository, "git@"):
+		h, p, ok := strings.Cut(strings.TrimPrefix(repository, "git@"), ":")
+		if !ok || !strings.EqualFold(h, host) {
+			return "", "", false
+		}
+		path = p
+	default:
+		u, err := url.Parse(repository)
+		if err != nil || !strings.EqualFold(u.Hostname(), host) {
+			return "", "", false
+		}
+		path = u.Path
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	owner, repo, ok := strings.Cut(path, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", false
+	}
+	return owner, repo, true
+}
@@ -0,0 +1,240 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// GroupingPrinter collects results and prints each distinct secret once,
+// together with every location it was found in, when Flush is called.
+//
+// Results are grouped by their raw value, so the engine must not redact them
+// before they are printed. Redaction is instead applied by the GroupingPrinter
+// itself.
+type GroupingPrinter struct {
+	mu      sync.Mutex
+	jsonOut bool
+	out     io.Writer
+	groups  *secretGroups
+}
+
+// secretGroups collects results grouped by distinct secret. It is not safe
+// for concurrent use.
+type secretGroups struct {
+	redact RedactMode
+	groups map[string]*secretGroup
+	// order contains the group keys in the order they were first seen.
+	order []string
+}
+
+// secretGroup contains all occurrences of a single secret.
+type secretGroup struct {
+	// fingerprint identifies the secret without revealing it.
+	fingerprint string
+	result      detectors.ResultWithMetadata
+	occurrences []occurrence
+}
+
+func newSecretGroups(redact RedactMode) *secretGroups {
+	return &secretGroups{redact: redact, groups: make(map[string]*secretGroup)}
+}
+
+// add records an occurrence of the result's secret.
+func (g *secretGroups) add(r *detectors.ResultWithMetadata) {
+	key := groupKey(r)
+	group, ok := g.groups[key]
+	if !ok {
+		// Copy the result, as the caller may reuse it.
+		group = &secretGroup{fingerprint: fingerprint(key), result: *r}
+		g.redact.RedactResult(&group.result)
+		g.groups[key] = group
+		g.order = append(g.order, key)
+	}
+	if r.Verified && !group.result.Verified {
+		// Prefer the verified result, as it carries the verification data.
+		group.result = *r
+		g.redact.RedactResult(&group.result)
+	}
+	group.occurrences = append(group.occurrences, newOccurrence(r))
+}
+
+// drain returns all groups in the order they were first seen and resets the
+// collection.
+func (g *secretGroups) drain() []*secretGroup {
+	groups := make([]*secretGroup, 0, len(g.order))
+	for _, key := range g.order {
+		groups = append(groups, g.groups[key])
+	}
+	g.groups = make(map[string]*secretGroup)
+	g.order = nil
+	return groups
+}
+
+// occurrence is a single location a secret was found in.
+type occurrence struct {
+	SourceID       int64
+	SourceType     sourcespb.SourceType
+	SourceName     string
+	DecoderName    string
+	Verified       bool
+	SourceMetadata *source_metadatapb.MetaData
+}
+
+func newOccurrence(r *detectors.ResultWithMetadata) occurrence {
+	return occurrence{
+		SourceID:       r.SourceID,
+		SourceType:     r.SourceType,
+		SourceName:     r.SourceName,
+		DecoderName:    r.DecoderType.String(),
+		Verified:       r.Verified,
+		SourceMetadata: r.SourceMetadata,
+	}
+}
+
+// groupedFinding is the JSON representation of a secretGroup.
+type groupedFinding struct {
+	DetectorType detectorspb.DetectorType
+	DetectorName string
+	// Verified is true if any occurrence of the secret was verified.
+	Verified        bool
+	Raw             string
+	RawV2           string
+	Redacted        string
+	ExtraData       map[string]string
+	StructuredData  *detectorspb.StructuredData
+	OccurrenceCount int
+	Occurrences     []occurrence
+}
+
+// NewGroupingPrinter returns a GroupingPrinter that writes to the configured
+// output, either as JSON lines or plain text.
+func NewGroupingPrinter(jsonOut bool, redact RedactMode) *GroupingPrinter {
+	return newGroupingPrinter(jsonOut, redact, writer)
+}
+
+func newGroupingPrinter(jsonOut bool, redact RedactMode, out io.Writer) *GroupingPrinter {
+	return &GroupingPrinter{
+		jsonOut: jsonOut,
+		out:     out,
+		groups:  newSecretGroups(redact),
+	}
+}
+
+func (p *GroupingPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.groups.add(r)
+	return nil
+}
+
+// Flush prints all collected groups.
+func (p *GroupingPrinter) Flush(_ context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, group := range p.groups.drain() {
+		var err error
+		if p.jsonOut {
+			err = p.writeJSON(group)
+		} else {
+			err = p.writePlain(group)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *GroupingPrinter) writeJSON(group *secretGroup) error {
+	r := &group.result
+	v := groupedFinding{
+		DetectorType:    r.DetectorType,
+		DetectorName:    r.DetectorType.String(),
+		Verified:        r.Verified,
+		Raw:             string(r.Raw),
+		RawV2:           string(r.RawV2),
+		Redacted:        r.Redacted,
+		ExtraData:       r.ExtraData,
+		StructuredData:  r.StructuredData,
+		OccurrenceCount: len(group.occurrences),
+		Occurrences:     group.occurrences,
+	}
+	if r.DetectorName != "" {
+		v.DetectorName = r.DetectorName
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+	_, err = fmt.Fprintln(p.out, string(out))
+	return err
+}
+
+func (p *GroupingPrinter) writePlain(group *secretGroup) error {
+	r := &group.result
+	var sb strings.Builder
+	if r.Verified {
+		fmt.Fprintf(&sb, "Found verified result 🐷🔑 in %d location(s)\n", len(group.occurrences))
+	} else {
+		fmt.Fprintf(&sb, "Found unverified result 🐷🔑❓ in %d location(s)\n", len(group.occurrences))
+	}
+	fmt.Fprintf(&sb, "Detector Type: %s\n", r.DetectorType)
+	fmt.Fprintf(&sb, "Raw result: %s\n", strings.TrimSpace(string(r.Raw)))
+	for i, occ := range group.occurrences {
+		loc, err := describeLocation(occ.SourceMetadata)
+		if err != nil {
+			return fmt.Errorf("could not marshal result: %w", err)
+		}
+		fmt.Fprintf(&sb, "  %d. %s\n", i+1, loc)
+	}
+	sb.WriteString("\n")
+	_, err := io.WriteString(p.out, sb.String())
+	return err
+}
+
+// describeLocation returns the source metadata as a single line of sorted
+// key/value pairs.
+func describeLocation(meta *source_metadatapb.MetaData) (string, error) {
+	m, err := structToMap(meta.GetData())
+	if err != nil {
+		return "", err
+	}
+	var parts []string
+	for _, data := range m {
+		for k, v := range data {
+			parts = append(parts, fmt.Sprintf("%s: %v", k, v))
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", "), nil
+}
+
+// groupKey identifies a distinct secret. RawV2 is preferred, as it
+// distinguishes multi-part secrets that share an identifier.
+func groupKey(r *detectors.ResultWithMetadata) string {
+	raw := r.RawV2
+	if len(raw) == 0 {
+		raw = r.Raw
+	}
+	return fmt.Sprintf("%d\x00%s\x00%s", r.DetectorType, r.DetectorName, raw)
+}
+
+// fingerprint returns a stable identifier for a group key that does not
+// reveal the secret.
+func fingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
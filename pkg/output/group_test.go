@@ -0,0 +1,87 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func fsResult(file string, raw string, verified bool) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{File: file},
+			},
+		},
+		Result: detectors.Result{
+			DetectorType: detectorspb.DetectorType_AWS,
+			Verified:     verified,
+			Raw:          []byte(raw),
+		},
+	}
+}
+
+func TestGroupingPrinter_JSON(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	p := newGroupingPrinter(true, RedactRaw, &out)
+
+	assert.NoError(t, p.Print(ctx, fsResult("a.txt", "secret1", false)))
+	assert.NoError(t, p.Print(ctx, fsResult("b.txt", "secret2", false)))
+	assert.NoError(t, p.Print(ctx, fsResult("c.txt", "secret1", true)))
+	assert.Empty(t, out.String())
+
+	assert.NoError(t, p.Flush(ctx))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	type finding struct {
+		Raw             string
+		Verified        bool
+		OccurrenceCount int
+		Occurrences     []struct {
+			Verified       bool
+			SourceMetadata struct {
+				Data struct {
+					Filesystem struct{ File string }
+				}
+			}
+		}
+	}
+
+	var first finding
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "secret1", first.Raw)
+	assert.True(t, first.Verified)
+	assert.Equal(t, 2, first.OccurrenceCount)
+	assert.Equal(t, "a.txt", first.Occurrences[0].SourceMetadata.Data.Filesystem.File)
+	assert.False(t, first.Occurrences[0].Verified)
+	assert.Equal(t, "c.txt", first.Occurrences[1].SourceMetadata.Data.Filesystem.File)
+
+	var second finding
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "secret2", second.Raw)
+	assert.Equal(t, 1, second.OccurrenceCount)
+}
+
+func TestGroupingPrinter_RedactsAfterGrouping(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	p := newGroupingPrinter(false, RedactFull, &out)
+
+	assert.NoError(t, p.Print(ctx, fsResult("a.txt", "secret1", false)))
+	assert.NoError(t, p.Print(ctx, fsResult("b.txt", "secret2", false)))
+	assert.NoError(t, p.Flush(ctx))
+
+	assert.Equal(t, 2, strings.Count(out.String(), "in 1 location(s)"))
+	assert.NotContains(t, out.String(), "secret1")
+	assert.Contains(t, out.String(), "1. file: a.txt")
+}
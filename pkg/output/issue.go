@@ -0,0 +1,89 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// maxIssueLocations is the maximum number of locations listed in an issue
+// created by a sink. Larger groups are summarized with a count.
+const maxIssueLocations = 100
+
+// maxIssueTitleLength is the maximum length of an issue title. Jira limits
+// summaries to 255 characters, GitHub titles are limited to 256.
+const maxIssueTitleLength = 255
+
+// singleFindingGroup returns a group containing only the given result. Its
+// fingerprint includes the location so each finding is tracked separately.
+func singleFindingGroup(r *detectors.ResultWithMetadata, redact RedactMode) (*secretGroup, error) {
+	loc, err := describeLocation(r.SourceMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal result: %w", err)
+	}
+	group := &secretGroup{
+		fingerprint: fingerprint(groupKey(r) + "\x00" + r.SourceName + "\x00" + loc),
+		result:      *r,
+		occurrences: []occurrence{newOccurrence(r)},
+	}
+	redact.RedactResult(&group.result)
+	return group, nil
+}
+
+// detectorName returns the name of the detector that found the group's secret.
+func (g *secretGroup) detectorName() string {
+	if g.result.DetectorName != "" {
+		return g.result.DetectorName
+	}
+	return g.result.DetectorType.String()
+}
+
+// title returns a short, single line description of the group suitable as an
+// issue title.
+func (g *secretGroup) title() string {
+	status := "Unverified"
+	if g.result.Verified {
+		status = "Verified"
+	}
+	var where string
+	if len(g.occurrences) == 1 {
+		where, _ = describeLocation(g.occurrences[0].SourceMetadata)
+	}
+	if where == "" {
+		where = fmt.Sprintf("%d locations", len(g.occurrences))
+	}
+
+	title := fmt.Sprintf("[TruffleHog] %s %s secret found in %s", status, g.detectorName(), where)
+	if runes := []rune(title); len(runes) > maxIssueTitleLength {
+		title = string(runes[:maxIssueTitleLength-3]) + "..."
+	}
+	return title
+}
+
+// locations returns up to maxIssueLocations descriptions of where the secret
+// was found, and the number of locations left out.
+func (g *secretGroup) locations() ([]string, int) {
+	var locs []string
+	for i, occ := range g.occurrences {
+		if i == maxIssueLocations {
+			break
+		}
+		loc, err := describeLocation(occ.SourceMetadata)
+		if err != nil || loc == "" {
+			loc = "unknown location"
+		}
+		var src []string
+		if occ.SourceName != "" {
+			src = append(src, occ.SourceName)
+		}
+		src = append(src, occ.SourceType.String())
+		locs = append(locs, fmt.Sprintf("%s (%s)", loc, strings.Join(src, ", ")))
+	}
+	return locs, len(g.occurrences) - len(locs)
+}
+
+// secret returns the possibly redacted secret of the group.
+func (g *secretGroup) secret() string {
+	return strings.TrimSpace(string(g.result.Raw))
+}
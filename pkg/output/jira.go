@@ -0,0 +1,250 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// JiraConfig configures a JiraPrinter.
+type JiraConfig struct {
+	// URL is the base URL of the Jira instance, e.g.
+	// https://example.atlassian.net.
+	URL string
+	// User is the account email used with an API token on Jira Cloud. If it is
+	// empty, Token is used as a personal access token.
+	User  string
+	Token string
+	// Project is the key of the project issues are created in.
+	Project string
+	// IssueType is the name of the issue type, e.g. Bug.
+	IssueType string
+	// Labels are added to every issue in addition to the fingerprint label.
+	Labels []string
+	// Fields are additional issue fields keyed by field ID. Values that are JSON
+	// objects or arrays are sent as is, all other values as strings.
+	Fields map[string]string
+	// GroupBySecret creates one issue per distinct secret when the printer is
+	// flushed, instead of one issue per finding.
+	GroupBySecret bool
+	// Redact is applied to secrets included in issues.
+	Redact RedactMode
+}
+
+// JiraPrinter is a printer that creates a Jira issue for each finding, or for
+// each distinct secret. Issues are labeled with a fingerprint of the finding,
+// and no issue is created if an open issue with the same fingerprint exists.
+//
+// The printer needs unredacted results to fingerprint secrets. Secrets are
+// redacted with the configured mode before they are sent to Jira.
+type JiraPrinter struct {
+	mu     sync.Mutex
+	cfg    JiraConfig
+	client *http.Client
+	groups *secretGroups
+	// seen contains the fingerprint labels handled during this scan.
+	seen map[string]struct{}
+}
+
+// NewJiraPrinter validates the configuration and returns a JiraPrinter.
+func NewJiraPrinter(cfg JiraConfig) (*JiraPrinter, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("jira: URL is required")
+	}
+	if cfg.Project == "" {
+		return nil, errors.New("jira: project is required")
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("jira: token is required")
+	}
+	if cfg.IssueType == "" {
+		cfg.IssueType = "Bug"
+	}
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+
+	return &JiraPrinter{
+		cfg:    cfg,
+		client: common.RetryableHttpClientTimeout(30),
+		groups: newSecretGroups(cfg.Redact),
+		seen:   make(map[string]struct{}),
+	}, nil
+}
+
+func (p *JiraPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cfg.GroupBySecret {
+		p.groups.add(r)
+		return nil
+	}
+	group, err := singleFindingGroup(r, p.cfg.Redact)
+	if err != nil {
+		return err
+	}
+	return p.createIssue(ctx, group)
+}
+
+// Flush creates issues for all collected secrets when grouping by secret.
+func (p *JiraPrinter) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+	for _, group := range p.groups.drain() {
+		errs = append(errs, p.createIssue(ctx, group))
+	}
+	return errors.Join(errs...)
+}
+
+// createIssue creates an issue for the group unless an open issue with the
+// same fingerprint exists. The caller must hold the lock.
+func (p *JiraPrinter) createIssue(ctx context.Context, group *secretGroup) error {
+	label := fingerprintLabel(group.fingerprint)
+	if _, ok := p.seen[label]; ok {
+		return nil
+	}
+	p.seen[label] = struct{}{}
+
+	existing, err := p.findOpenIssue(ctx, label)
+	if err != nil {
+		return err
+	}
+	if existing != "" {
+		ctx.Logger().V(2).Info("skipping finding with open jira issue", "issue", existing)
+		return nil
+	}
+
+	fields := map[string]any{
+		"project":     map[string]string{"key": p.cfg.Project},
+		"issuetype":   map[string]string{"name": p.cfg.IssueType},
+		"summary":     group.title(),
+		"description": jiraDescription(group),
+		"labels":      append([]string{"trufflehog", label}, p.cfg.Labels...),
+	}
+	for k, v := range p.cfg.Fields {
+		fields[k] = jiraFieldValue(v)
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := p.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]any{"fields": fields}, &created); err != nil {
+		return fmt.Errorf("jira: could not create issue: %w", err)
+	}
+	ctx.Logger().Info("created jira issue", "issue", created.Key)
+	return nil
+}
+
+// findOpenIssue returns the key of an unresolved issue with the given label in
+// the configured project, or an empty string if there is none.
+func (p *JiraPrinter) findOpenIssue(ctx context.Context, label string) (string, error) {
+	jql := fmt.Sprintf("project = %q AND labels = %q AND statusCategory != Done", p.cfg.Project, label)
+	query := url.Values{
+		"jql":        {jql},
+		"maxResults": {"1"},
+		"fields":     {"key"},
+	}
+	var res struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/rest/api/2/search?"+query.Encode(), nil, &res); err != nil {
+		return "", fmt.Errorf("jira: could not search for existing issues: %w", err)
+	}
+	if len(res.Issues) == 0 {
+		return "", nil
+	}
+	return res.Issues[0].Key, nil
+}
+
+func (p *JiraPrinter) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.cfg.URL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.cfg.User != "" {
+		req.SetBasicAuth(p.cfg.User, p.cfg.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+p.cfg.Token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// fingerprintLabel returns the label used to find issues for a fingerprint.
+func fingerprintLabel(fingerprint string) string {
+	return "trufflehog-" + fingerprint[:16]
+}
+
+// jiraFieldValue returns the value to send for a user configured field.
+func jiraFieldValue(v string) any {
+	trimmed := strings.TrimSpace(v)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var raw json.RawMessage
+		if json.Unmarshal([]byte(trimmed), &raw) == nil {
+			return raw
+		}
+	}
+	return v
+}
+
+// jiraDescription renders the issue description in Jira wiki markup.
+func jiraDescription(group *secretGroup) string {
+	status := "an unverified"
+	if group.result.Verified {
+		status = "a verified"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "TruffleHog found %s %s secret.\n\n", status, group.detectorName())
+	fmt.Fprintf(&sb, "*Secret:* {noformat}%s{noformat}\n", group.secret())
+	fmt.Fprintf(&sb, "*Detector:* %s\n", group.detectorName())
+	fmt.Fprintf(&sb, "*Fingerprint:* %s\n\n", group.fingerprint)
+
+	locs, omitted := group.locations()
+	fmt.Fprintf(&sb, "*Locations (%d):*\n", len(group.occurrences))
+	for _, loc := range locs {
+		fmt.Fprintf(&sb, "# %s\n", loc)
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&sb, "\n...and %d more.\n", omitted)
+	}
+	fmt.Fprintf(&sb, "\n*Remediation:* %s\n", remediationHint(group.result.DetectorType))
+	return sb.String()
+}
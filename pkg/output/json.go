@@ -42,6 +42,10 @@ func (p *JSONPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 		Redacted       string
 		ExtraData      map[string]string
 		StructuredData *detectorspb.StructuredData
+		// Column is the column of the secret in its line.
+		Column int64 `json:",omitempty"`
+		// Snippet is the part of the line around the secret.
+		Snippet string `json:",omitempty"`
 	}{
 		SourceMetadata: r.SourceMetadata,
 		SourceID:       r.SourceID,
@@ -56,6 +60,8 @@ func (p *JSONPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 		Redacted:       r.Redacted,
 		ExtraData:      r.ExtraData,
 		StructuredData: r.StructuredData,
+		Column:         r.Column,
+		Snippet:        r.Snippet,
 	}
 	out, err := json.Marshal(v)
 	if err != nil {
@@ -63,7 +69,7 @@ func (p *JSONPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 	}
 
 	p.mu.Lock()
-	fmt.Println(string(out))
+	fmt.Fprintln(writer, string(out))
 	p.mu.Unlock()
 	return nil
 }
@@ -55,7 +55,7 @@ func (p *LegacyJSONPrinter) Print(ctx context.Context, r *detectors.ResultWithMe
 	}
 
 	p.mu.Lock()
-	fmt.Println(string(out))
+	fmt.Fprintln(writer, string(out))
 	p.mu.Unlock()
 	return nil
 }
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// Printer prints results. It has the same method set as engine.Printer.
+type Printer interface {
+	Print(ctx context.Context, r *detectors.ResultWithMetadata) error
+}
+
+// flusher is implemented by printers that buffer results until the scan has
+// finished.
+type flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// summaryPrinter is implemented by printers that can print a summary once the
+// scan has finished.
+type summaryPrinter interface {
+	PrintSummary(ctx context.Context, summary any) error
+}
+
+// MultiPrinter prints each result with several printers, e.g. stdout and an
+// issue tracker.
+type MultiPrinter struct {
+	printers []Printer
+}
+
+// NewMultiPrinter returns a printer that prints each result with all of the
+// given printers.
+func NewMultiPrinter(printers ...Printer) *MultiPrinter {
+	return &MultiPrinter{printers: printers}
+}
+
+// Print prints the result with every printer. Each printer receives its own
+// copy of the result. A failing printer does not prevent the others from
+// printing.
+func (p *MultiPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	var errs []error
+	for _, printer := range p.printers {
+		cp := *r
+		if err := printer.Print(ctx, &cp); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flush flushes all printers that buffer results.
+func (p *MultiPrinter) Flush(ctx context.Context) error {
+	var errs []error
+	for _, printer := range p.printers {
+		if f, ok := printer.(flusher); ok {
+			errs = append(errs, f.Flush(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PrintSummary prints the summary with all printers that support it.
+func (p *MultiPrinter) PrintSummary(ctx context.Context, summary any) error {
+	var errs []error
+	for _, printer := range p.printers {
+		if s, ok := printer.(summaryPrinter); ok {
+			errs = append(errs, s.PrintSummary(ctx, summary))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RedactingPrinter redacts results before passing them to another printer.
+// It is used when other printers need to see the unredacted results.
+type RedactingPrinter struct {
+	printer Printer
+	mode    RedactMode
+}
+
+// NewRedactingPrinter returns a printer that redacts results with the given
+// mode before printing them with printer.
+func NewRedactingPrinter(printer Printer, mode RedactMode) *RedactingPrinter {
+	return &RedactingPrinter{printer: printer, mode: mode}
+}
+
+func (p *RedactingPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	cp := *r
+	p.mode.RedactResult(&cp)
+	return p.printer.Print(ctx, &cp)
+}
+
+// Flush flushes the wrapped printer if it buffers results.
+func (p *RedactingPrinter) Flush(ctx context.Context) error {
+	if f, ok := p.printer.(flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// PrintSummary prints the summary with the wrapped printer if it supports it.
+func (p *RedactingPrinter) PrintSummary(ctx context.Context, summary any) error {
+	if s, ok := p.printer.(summaryPrinter); ok {
+		return s.PrintSummary(ctx, summary)
+	}
+	return nil
+}
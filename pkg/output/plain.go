@@ -89,7 +89,13 @@ func (p *PlainPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 	for _, k := range aggregateDataKeys {
 		printer.Printf("%s: %v\n", cases.Title(language.AmericanEnglish).String(k), aggregateData[k])
 	}
-	fmt.Println("")
+	if r.Column > 0 {
+		printer.Printf("Column: %d\n", r.Column)
+	}
+	if r.Snippet != "" {
+		printer.Printf("Context: %s\n", r.Snippet)
+	}
+	fmt.Fprintln(writer, "")
 	return nil
 }
 
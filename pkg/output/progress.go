@@ -0,0 +1,189 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// ProgressStats are the scan totals shown by a ProgressBar.
+type ProgressStats struct {
+	BytesScanned uint64
+	Findings     uint64
+}
+
+// ProgressBar renders the live progress of a scan to a terminal: the targets
+// each source completed out of its total with an estimate of the time left,
+// the bytes scanned and the findings so far. It learns about running sources
+// as a sources.JobProgressHook.
+type ProgressBar struct {
+	w     io.Writer
+	stats func() ProgressStats
+
+	mu    sync.Mutex
+	start time.Time
+	jobs  []sources.JobProgressRef
+	// lines is the number of lines currently drawn.
+	lines int
+	shown bool
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewProgressBar returns a progress bar that draws to w, which should be a
+// terminal.
+func NewProgressBar(w io.Writer) *ProgressBar {
+	return &ProgressBar{w: w, start: time.Now()}
+}
+
+// Show redraws the progress every interval until Stop is called. stats is
+// called on every redraw.
+func (p *ProgressBar) Show(interval time.Duration, stats func() ProgressStats) {
+	p.mu.Lock()
+	p.stats, p.shown = stats, true
+	p.redraw()
+	p.mu.Unlock()
+
+	p.stop, p.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.mu.Lock()
+				p.redraw()
+				p.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// Stop stops redrawing and clears the progress from the terminal.
+func (p *ProgressBar) Stop() {
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+	p.stop = nil
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprint(p.w, p.clear())
+	p.lines, p.shown = 0, false
+}
+
+// Printer returns a printer that clears the progress before printing results
+// with printer, so they don't get mixed up on the terminal.
+func (p *ProgressBar) Printer(printer Printer) Printer {
+	return &progressPrinter{bar: p, printer: printer}
+}
+
+type progressPrinter struct {
+	bar     *ProgressBar
+	printer Printer
+}
+
+func (pp *progressPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	pp.bar.mu.Lock()
+	defer pp.bar.mu.Unlock()
+	if !pp.bar.shown {
+		return pp.printer.Print(ctx, r)
+	}
+	fmt.Fprint(pp.bar.w, pp.bar.clear())
+	pp.bar.lines = 0
+	err := pp.printer.Print(ctx, r)
+	pp.bar.redraw()
+	return err
+}
+
+// Flush flushes the wrapped printer if it buffers results.
+func (pp *progressPrinter) Flush(ctx context.Context) error {
+	if f, ok := pp.printer.(flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// PrintSummary prints the summary with the wrapped printer if it supports it.
+func (pp *progressPrinter) PrintSummary(ctx context.Context, summary any) error {
+	if s, ok := pp.printer.(summaryPrinter); ok {
+		return s.PrintSummary(ctx, summary)
+	}
+	return nil
+}
+
+// clear returns the escape codes erasing the lines drawn.
+func (p *ProgressBar) clear() string {
+	return strings.Repeat("\x1b[1A\x1b[2K", p.lines)
+}
+
+// redraw replaces the lines drawn with the current progress.
+func (p *ProgressBar) redraw() {
+	now := time.Now()
+	var b strings.Builder
+	b.WriteString(p.clear())
+	for _, job := range p.jobs {
+		b.WriteString(progressLine(job.Snapshot(), now) + "\n")
+	}
+	var stats ProgressStats
+	if p.stats != nil {
+		stats = p.stats()
+	}
+	fmt.Fprintf(&b, "Scanned %s in %s, %d findings so far\n",
+		bytesString(int64(stats.BytesScanned)), now.Sub(p.start).Round(time.Second), stats.Findings)
+	fmt.Fprint(p.w, b.String())
+	p.lines = len(p.jobs) + 1
+}
+
+// progressLine describes the progress of a source job, e.g.
+// "git: 3/10 targets (30%), 1m20s left".
+func progressLine(m sources.JobProgressMetrics, now time.Time) string {
+	name := m.SourceName
+	if name == "" {
+		name = "source"
+	}
+	if !m.EndTime.IsZero() {
+		return fmt.Sprintf("%s: done in %s", name, m.EndTime.Sub(m.StartTime).Round(time.Second))
+	}
+	if m.SectionsTotal <= 0 {
+		return name + ": scanning"
+	}
+	completed, total := m.SectionsCompleted, m.SectionsTotal
+	line := fmt.Sprintf("%s: %d/%d targets (%d%%)", name, completed, total, 100*int64(completed)/int64(total))
+	if completed > 0 && completed < total && !m.StartTime.IsZero() {
+		elapsed := now.Sub(m.StartTime)
+		left := elapsed * time.Duration(total-completed) / time.Duration(completed)
+		line += fmt.Sprintf(", %s left", left.Round(time.Second))
+	}
+	return line
+}
+
+// Start implements sources.JobProgressHook.
+func (p *ProgressBar) Start(ref sources.JobProgressRef, _ time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.jobs = append(p.jobs, ref)
+}
+
+// The other events are read from the jobs' snapshots when redrawing.
+
+func (p *ProgressBar) End(sources.JobProgressRef, time.Time)                                   {}
+func (p *ProgressBar) StartEnumerating(sources.JobProgressRef, time.Time)                      {}
+func (p *ProgressBar) EndEnumerating(sources.JobProgressRef, time.Time)                        {}
+func (p *ProgressBar) StartUnitChunking(sources.JobProgressRef, sources.SourceUnit, time.Time) {}
+func (p *ProgressBar) EndUnitChunking(sources.JobProgressRef, sources.SourceUnit, time.Time)   {}
+func (p *ProgressBar) ReportError(sources.JobProgressRef, error)                               {}
+func (p *ProgressBar) ReportUnit(sources.JobProgressRef, sources.SourceUnit)                   {}
+func (p *ProgressBar) ReportChunk(sources.JobProgressRef, sources.SourceUnit, *sources.Chunk)  {}
+func (p *ProgressBar) Finish(sources.JobProgressRef)                                           {}
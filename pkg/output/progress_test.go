@@ -0,0 +1,77 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestProgressLine(t *testing.T) {
+	start := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		metrics sources.JobProgressMetrics
+		want    string
+	}{
+		{
+			name:    "no targets reported",
+			metrics: sources.JobProgressMetrics{SourceName: "fs", StartTime: start},
+			want:    "fs: scanning",
+		},
+		{
+			name:    "not started",
+			metrics: sources.JobProgressMetrics{SourceName: "s3", StartTime: start, SectionsTotal: 4},
+			want:    "s3: 0/4 targets (0%)",
+		},
+		{
+			name:    "estimate",
+			metrics: sources.JobProgressMetrics{SourceName: "git", StartTime: start, SectionsCompleted: 3, SectionsTotal: 10},
+			want:    "git: 3/10 targets (30%), 2m20s left",
+		},
+		{
+			name:    "done",
+			metrics: sources.JobProgressMetrics{StartTime: start, EndTime: start.Add(90 * time.Second), SectionsCompleted: 10, SectionsTotal: 10},
+			want:    "source: done in 1m30s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, progressLine(tt.metrics, start.Add(time.Minute)))
+		})
+	}
+}
+
+type bufferPrinter struct{ buf *bytes.Buffer }
+
+func (p bufferPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	p.buf.WriteString(r.SourceName + "\n")
+	return nil
+}
+
+func TestProgressBar_Printer(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewProgressBar(&buf)
+	printer := bar.Printer(bufferPrinter{&buf})
+
+	// Results are printed as is until the progress is shown.
+	assert.NoError(t, printer.Print(context.Background(), &detectors.ResultWithMetadata{SourceName: "first"}))
+	assert.Equal(t, "first\n", buf.String())
+
+	bar.Show(time.Hour, func() ProgressStats { return ProgressStats{BytesScanned: 2048, Findings: 1} })
+	assert.Equal(t, "first\nScanned 2.0 KiB in 0s, 1 findings so far\n", buf.String())
+
+	// The progress is erased before printing and drawn again after.
+	buf.Reset()
+	assert.NoError(t, printer.Print(context.Background(), &detectors.ResultWithMetadata{SourceName: "second"}))
+	assert.Equal(t, "\x1b[1A\x1b[2Ksecond\nScanned 2.0 KiB in 0s, 1 findings so far\n", buf.String())
+
+	buf.Reset()
+	bar.Stop()
+	assert.Equal(t, "\x1b[1A\x1b[2K", buf.String())
+}
@@ -0,0 +1,137 @@
+package output
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// RedactMode controls how secret values are rendered in results before they
+// are handed to a printer.
+type RedactMode string
+
+const (
+	// RedactRaw leaves secret values untouched. This is the behavior when no
+	// redaction mode is configured.
+	RedactRaw RedactMode = "raw"
+	// RedactFull replaces secret values with a fixed mask.
+	RedactFull RedactMode = "full"
+	// RedactPartial keeps the first and last few characters of a secret
+	// and masks the rest. At most a quarter of the secret is kept at each
+	// end.
+	RedactPartial RedactMode = "partial"
+	// RedactHash replaces secret values with their SHA-256 digest.
+	RedactHash RedactMode = "hash"
+)
+
+const (
+	redactMask = "********"
+	// partialRedactKeep is the most characters kept at each end of a secret
+	// when using RedactPartial.
+	partialRedactKeep = 4
+)
+
+// RedactModes returns the names of all supported redaction modes.
+func RedactModes() []string {
+	return []string{string(RedactFull), string(RedactPartial), string(RedactHash), string(RedactRaw)}
+}
+
+// ParseRedactMode converts a user provided string into a RedactMode. An empty
+// string is treated as RedactRaw.
+func ParseRedactMode(mode string) (RedactMode, error) {
+	switch m := RedactMode(strings.ToLower(strings.TrimSpace(mode))); m {
+	case "":
+		return RedactRaw, nil
+	case RedactRaw, RedactFull, RedactPartial, RedactHash:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown redaction mode %q, expected one of %s", mode, strings.Join(RedactModes(), ", "))
+	}
+}
+
+// Redact returns the secret rendered according to the mode.
+func (m RedactMode) Redact(secret string) string {
+	if secret == "" {
+		return secret
+	}
+	switch m {
+	case RedactFull:
+		return redactMask
+	case RedactPartial:
+		// The visible characters never make up more than half of the
+		// secret, and secrets too short to keep any are fully masked.
+		keep := len(secret) / 4
+		if keep > partialRedactKeep {
+			keep = partialRedactKeep
+		}
+		if keep == 0 {
+			return redactMask
+		}
+		return secret[:keep] + redactMask + secret[len(secret)-keep:]
+	case RedactHash:
+		h := sha256.Sum256([]byte(secret))
+		return "sha256:" + hex.EncodeToString(h[:])
+	default:
+		return secret
+	}
+}
+
+// RedactResult rewrites all secret material in the result according to the
+// mode. The raw values are replaced, and any occurrence of them in the
+// originating chunk data, the snippet and the values of ExtraData is replaced
+// as well so that printers which include surrounding context do not leak the
+// secret. StructuredData is left as is, as it only holds fingerprints, URLs
+// and users of the secret.
+func (m RedactMode) RedactResult(r *detectors.ResultWithMetadata) {
+	if m == "" || m == RedactRaw || r == nil {
+		return
+	}
+
+	raw, rawV2 := string(r.Raw), string(r.RawV2)
+	if len(r.ExtraData) > 0 {
+		// The map may be shared with other results of the detector.
+		extraData := make(map[string]string, len(r.ExtraData))
+		for k, v := range r.ExtraData {
+			extraData[k] = m.RedactIn(v, raw, rawV2)
+		}
+		r.ExtraData = extraData
+	}
+	// Replace the longer value first, as RawV2 commonly contains Raw.
+	for _, secret := range []string{rawV2, raw} {
+		if secret == "" || len(r.Data) == 0 {
+			continue
+		}
+		r.Data = bytes.ReplaceAll(r.Data, []byte(secret), []byte(m.Redact(secret)))
+	}
+	for _, secret := range []string{rawV2, raw} {
+		if secret != "" {
+			r.Snippet = strings.ReplaceAll(r.Snippet, secret, m.Redact(secret))
+		}
+	}
+
+	r.Raw = []byte(m.Redact(raw))
+	r.RawV2 = []byte(m.Redact(rawV2))
+}
+
+// RedactIn replaces every occurrence of the secrets in s according to the
+// mode. It is used for text that is not part of a result, such as log
+// messages and errors, which may quote a secret.
+func (m RedactMode) RedactIn(s string, secrets ...string) string {
+	if m == "" || m == RedactRaw {
+		return s
+	}
+	// Replace longer secrets first, as one secret may contain another.
+	sorted := append([]string(nil), secrets...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, secret := range sorted {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, m.Redact(secret))
+		}
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// defaultRemediationHint is used for detectors without a specific hint.
+const defaultRemediationHint = "Revoke or rotate the credential with its provider, update everything that uses it, " +
+	"and then remove it from the source. Removing the secret without rotating it is not enough, " +
+	"as it remains in version history and any copies that were made."
+
+// remediationHints contains detector specific instructions for revoking a
+// leaked credential.
+var remediationHints = map[detectorspb.DetectorType]string{
+	detectorspb.DetectorType_AWS: "Deactivate and delete the access key in the AWS IAM console, review CloudTrail for " +
+		"activity by the key, and create a new key if needed. " +
+		"See https://docs.aws.amazon.com/IAM/latest/UserGuide/id_credentials_access-keys.html",
+	detectorspb.DetectorType_GCP: "Delete the service account key in the Google Cloud console and review the audit logs " +
+		"for its use. See https://cloud.google.com/iam/docs/keys-create-delete#deleting",
+	detectorspb.DetectorType_Github: "Revoke the token in the GitHub settings of the owning account or app. " +
+		"See https://docs.github.com/en/authentication/keeping-your-account-and-data-secure/token-expiration-and-revocation",
+	detectorspb.DetectorType_Gitlab: "Revoke the token in the GitLab access token settings. " +
+		"See https://docs.gitlab.com/ee/user/profile/personal_access_tokens.html#revoke-a-personal-access-token",
+	detectorspb.DetectorType_Slack: "Revoke the token from the Slack app management page or with the auth.revoke API. " +
+		"See https://api.slack.com/methods/auth.revoke",
+	detectorspb.DetectorType_SlackWebhook: "Remove the incoming webhook from the Slack app configuration and create a new one.",
+	detectorspb.DetectorType_Stripe:       "Roll the key in the Stripe dashboard. See https://stripe.com/docs/keys#rolling-keys",
+	detectorspb.DetectorType_Twilio: "Delete the API key or rotate the auth token in the Twilio console. " +
+		"See https://www.twilio.com/docs/iam/api-keys",
+	detectorspb.DetectorType_SendGrid: "Delete the API key in the SendGrid settings and create a new one.",
+	detectorspb.DetectorType_Mailgun:  "Delete the API key in the Mailgun security settings and create a new one.",
+	detectorspb.DetectorType_NpmToken: "Revoke the token with `npm token revoke` or in the npm access token settings. " +
+		"See https://docs.npmjs.com/revoking-access-tokens",
+	detectorspb.DetectorType_OpenAI: "Revoke the key on the OpenAI API keys page and create a new one.",
+	detectorspb.DetectorType_PrivateKey: "Generate a new key pair, replace the public key wherever the old one is trusted, " +
+		"and revoke any certificates issued for the leaked key.",
+	detectorspb.DetectorType_URI: "Change the password embedded in the URI and update all clients that use it.",
+	detectorspb.DetectorType_MongoDB: "Change the password of the database user in the connection string " +
+		"and review the database access logs.",
+	detectorspb.DetectorType_JDBC: "Change the password of the database user in the connection string " +
+		"and review the database access logs.",
+}
+
+// remediationHint returns instructions for revoking a leaked credential found
+// by the given detector.
+func remediationHint(detectorType detectorspb.DetectorType) string {
+	if hint, ok := remediationHints[detectorType]; ok {
+		return hint
+	}
+	return defaultRemediationHint
+}
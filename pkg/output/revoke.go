@@ -0,0 +1,126 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Revocation actions recorded in the audit log.
+const (
+	RevokeActionRevoked     = "revoked"
+	RevokeActionWouldRevoke = "would revoke"
+	RevokeActionFailed      = "failed"
+)
+
+// RevokeConfig configures a RevokingPrinter.
+type RevokeConfig struct {
+	// Detectors are used to revoke the secrets they found. Detectors that
+	// don't implement detectors.Revoker are ignored.
+	Detectors []detectors.Detector
+	// DryRun only records which secrets would be revoked.
+	DryRun bool
+	// AuditLog receives a JSON line for every action taken, if set.
+	AuditLog io.Writer
+	// Redact is applied to secrets in the audit log and the log messages of
+	// the printer. Secrets are partially redacted if it is not set, so they
+	// are only written in full if RedactRaw is asked for.
+	Redact RedactMode
+}
+
+// RevokeAction is an entry of the audit log of a RevokingPrinter.
+type RevokeAction struct {
+	Time     time.Time `json:"time"`
+	Detector string    `json:"detector"`
+	Secret   string    `json:"secret"`
+	Location string    `json:"location"`
+	Action   string    `json:"action"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// RevokingPrinter is a printer that revokes verified secrets as soon as they
+// are found, if their detector supports it, and records what it did in an
+// audit log. Each secret is revoked once. The printer needs unredacted
+// results.
+type RevokingPrinter struct {
+	mu       sync.Mutex
+	cfg      RevokeConfig
+	revokers map[detectorspb.DetectorType]detectors.Revoker
+	// seen contains the secrets handled during this scan.
+	seen map[string]struct{}
+}
+
+// NewRevokingPrinter returns a RevokingPrinter for the detectors in cfg that
+// can revoke secrets.
+func NewRevokingPrinter(cfg RevokeConfig) (*RevokingPrinter, error) {
+	revokers := make(map[detectorspb.DetectorType]detectors.Revoker)
+	for _, d := range cfg.Detectors {
+		if revoker, ok := d.(detectors.Revoker); ok {
+			revokers[d.Type()] = revoker
+		}
+	}
+	if len(revokers) == 0 {
+		return nil, errors.New("none of the selected detectors can revoke secrets")
+	}
+	if cfg.Redact == "" {
+		cfg.Redact = RedactPartial
+	}
+	return &RevokingPrinter{cfg: cfg, revokers: revokers, seen: make(map[string]struct{})}, nil
+}
+
+// Print revokes the secret of the result if it is verified.
+func (p *RevokingPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	if !r.Verified {
+		return nil
+	}
+	revoker, ok := p.revokers[r.DetectorType]
+	if !ok {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := groupKey(r)
+	if _, ok := p.seen[key]; ok {
+		return nil
+	}
+	p.seen[key] = struct{}{}
+
+	location, err := describeLocation(r.SourceMetadata)
+	if err != nil {
+		return err
+	}
+	action := RevokeAction{
+		Time:     time.Now().UTC(),
+		Detector: r.DetectorType.String(),
+		Secret:   p.cfg.Redact.Redact(string(r.Raw)),
+		Location: location,
+		Action:   RevokeActionWouldRevoke,
+	}
+	logger := ctx.Logger().WithValues("detector", action.Detector, "secret", action.Secret, "location", location)
+	if p.cfg.DryRun {
+		logger.Info("would revoke secret")
+	} else if err := revoker.Revoke(ctx, r.Result); err != nil {
+		action.Action, action.Error = RevokeActionFailed, err.Error()
+		logger.Error(err, "could not revoke secret")
+	} else {
+		action.Action = RevokeActionRevoked
+		logger.Info("revoked secret")
+	}
+
+	if p.cfg.AuditLog == nil {
+		return nil
+	}
+	line, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+	_, err = p.cfg.AuditLog.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,241 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+const (
+	siemVendor  = "Truffle Security"
+	siemProduct = "TruffleHog"
+
+	// CEF severities range from 0 to 10.
+	cefSeverityVerified   = 10
+	cefSeverityUnverified = 5
+	// LEEF severities range from 1 to 10.
+	leefSeverityVerified   = 10
+	leefSeverityUnverified = 5
+)
+
+// CEFPrinter is a printer that prints results in ArcSight Common Event Format.
+type CEFPrinter struct{ mu sync.Mutex }
+
+func (p *CEFPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	line, err := formatCEF(r, time.Now())
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	fmt.Fprintln(writer, line)
+	p.mu.Unlock()
+	return nil
+}
+
+// LEEFPrinter is a printer that prints results in IBM QRadar Log Event
+// Extended Format.
+type LEEFPrinter struct{ mu sync.Mutex }
+
+func (p *LEEFPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	line, err := formatLEEF(r, time.Now())
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	fmt.Fprintln(writer, line)
+	p.mu.Unlock()
+	return nil
+}
+
+// siemEvent contains the result fields shared by the SIEM formats.
+type siemEvent struct {
+	eventID    string
+	name       string
+	detector   string
+	decoder    string
+	verified   bool
+	sourceType string
+	sourceName string
+	secret     string
+	file       string
+	line       int64
+	column     int64
+	commit     string
+	email      string
+	repository string
+	link       string
+}
+
+func newSIEMEvent(r *detectors.ResultWithMetadata) (siemEvent, error) {
+	ev := siemEvent{
+		eventID:    strconv.Itoa(int(r.DetectorType)),
+		detector:   r.DetectorType.String(),
+		decoder:    r.DecoderType.String(),
+		verified:   r.Verified,
+		sourceType: r.SourceType.String(),
+		sourceName: r.SourceName,
+		secret:     strings.TrimSpace(string(r.Raw)),
+	}
+	if r.DetectorName != "" {
+		ev.detector = r.DetectorName
+	}
+	if r.Verified {
+		ev.name = fmt.Sprintf("Verified %s secret found", ev.detector)
+	} else {
+		ev.name = fmt.Sprintf("Unverified %s secret found", ev.detector)
+	}
+
+	meta, err := structToMap(r.SourceMetadata.GetData())
+	if err != nil {
+		return ev, fmt.Errorf("could not marshal result: %w", err)
+	}
+	for _, data := range meta {
+		for k, v := range data {
+			switch k {
+			case "file", "path":
+				ev.file, _ = v.(string)
+			case "line":
+				if line, ok := v.(float64); ok {
+					ev.line = int64(line)
+				}
+			case "commit":
+				ev.commit, _ = v.(string)
+			case "email":
+				ev.email, _ = v.(string)
+			case "repository":
+				ev.repository, _ = v.(string)
+			case "link":
+				ev.link, _ = v.(string)
+			}
+		}
+	}
+	ev.file = sources.ArchiveFile(r.SourceMetadata, ev.file)
+	if ev.line > 0 {
+		ev.column = r.Column
+	}
+	return ev, nil
+}
+
+// formatCEF formats a result as a CEF:0 event.
+func formatCEF(r *detectors.ResultWithMetadata, now time.Time) (string, error) {
+	ev, err := newSIEMEvent(r)
+	if err != nil {
+		return "", err
+	}
+	severity := cefSeverityUnverified
+	if ev.verified {
+		severity = cefSeverityVerified
+	}
+
+	var ext []string
+	add := func(k, v string) {
+		if v != "" {
+			ext = append(ext, k+"="+cefEscapeExtension(v))
+		}
+	}
+	add("rt", strconv.FormatInt(now.UnixMilli(), 10))
+	add("cat", "secret")
+	add("cs1Label", "detector")
+	add("cs1", ev.detector)
+	add("cs2Label", "verified")
+	add("cs2", strconv.FormatBool(ev.verified))
+	add("cs3Label", "source")
+	add("cs3", ev.sourceName)
+	add("cs4Label", "repository")
+	add("cs4", ev.repository)
+	add("cs5Label", "commit")
+	add("cs5", ev.commit)
+	add("cs6Label", "decoder")
+	add("cs6", ev.decoder)
+	add("deviceExternalId", ev.sourceType)
+	add("fname", ev.file)
+	if ev.line > 0 {
+		add("cn1Label", "line")
+		add("cn1", strconv.FormatInt(ev.line, 10))
+	}
+	if ev.column > 0 {
+		add("cn2Label", "column")
+		add("cn2", strconv.FormatInt(ev.column, 10))
+	}
+	add("suser", ev.email)
+	add("request", ev.link)
+	add("msg", ev.secret)
+
+	header := []string{
+		"CEF:0",
+		cefEscapeHeader(siemVendor),
+		cefEscapeHeader(siemProduct),
+		cefEscapeHeader(version.BuildVersion),
+		cefEscapeHeader(ev.eventID),
+		cefEscapeHeader(ev.name),
+		strconv.Itoa(severity),
+	}
+	return strings.Join(header, "|") + "|" + strings.Join(ext, " "), nil
+}
+
+// formatLEEF formats a result as a tab delimited LEEF:1.0 event.
+func formatLEEF(r *detectors.ResultWithMetadata, now time.Time) (string, error) {
+	ev, err := newSIEMEvent(r)
+	if err != nil {
+		return "", err
+	}
+	severity := leefSeverityUnverified
+	if ev.verified {
+		severity = leefSeverityVerified
+	}
+
+	var attrs []string
+	add := func(k, v string) {
+		if v != "" {
+			attrs = append(attrs, k+"="+leefEscapeAttribute(v))
+		}
+	}
+	add("devTime", strconv.FormatInt(now.UnixMilli(), 10))
+	add("cat", "secret")
+	add("sev", strconv.Itoa(severity))
+	add("detector", ev.detector)
+	add("decoder", ev.decoder)
+	add("verified", strconv.FormatBool(ev.verified))
+	add("sourceType", ev.sourceType)
+	add("sourceName", ev.sourceName)
+	add("repository", ev.repository)
+	add("commit", ev.commit)
+	add("fileName", ev.file)
+	if ev.line > 0 {
+		add("line", strconv.FormatInt(ev.line, 10))
+	}
+	if ev.column > 0 {
+		add("column", strconv.FormatInt(ev.column, 10))
+	}
+	add("usrName", ev.email)
+	add("url", ev.link)
+	add("secret", ev.secret)
+
+	header := []string{
+		"LEEF:1.0",
+		leefEscapeHeader(siemVendor),
+		leefEscapeHeader(siemProduct),
+		leefEscapeHeader(version.BuildVersion),
+		leefEscapeHeader(ev.eventID),
+	}
+	return strings.Join(header, "|") + "|" + strings.Join(attrs, "\t"), nil
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+	leefHeaderEscaper   = strings.NewReplacer(`|`, `\|`, "\r", " ", "\n", " ")
+	leefAttrEscaper     = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+)
+
+func cefEscapeHeader(s string) string     { return cefHeaderEscaper.Replace(s) }
+func cefEscapeExtension(s string) string  { return cefExtensionEscaper.Replace(s) }
+func leefEscapeHeader(s string) string    { return leefHeaderEscaper.Replace(s) }
+func leefEscapeAttribute(s string) string { return leefAttrEscaper.Replace(s) }
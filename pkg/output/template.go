@@ -0,0 +1,134 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// summaryTemplateName is the name of the optional template, defined with
+// {{define "summary"}}, which is executed once after all results are printed.
+const summaryTemplateName = "summary"
+
+// TemplatePrinter is a printer that renders each result with a user supplied
+// Go text/template.
+type TemplatePrinter struct {
+	mu   sync.Mutex
+	tmpl *template.Template
+	out  io.Writer
+}
+
+// templateResult is the data passed to the template for each result.
+type templateResult struct {
+	*detectors.ResultWithMetadata
+	// DetectorName is the string name of the DetectorType.
+	DetectorName string
+	// DecoderName is the string name of the DecoderType.
+	DecoderName string
+	// SourceTypeName is the string name of the SourceType.
+	SourceTypeName string
+	// RawString and RawV2String are the raw values as strings.
+	RawString   string
+	RawV2String string
+	// Metadata is a flattened view of the source metadata, e.g. file, line,
+	// commit, or link depending on the source.
+	Metadata map[string]any
+}
+
+// NewTemplatePrinter parses the template in the given file and returns a
+// printer writing to the configured output.
+func NewTemplatePrinter(filename string) (*TemplatePrinter, error) {
+	text, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read output template: %w", err)
+	}
+	return newTemplatePrinter(filepath.Base(filename), string(text), writer)
+}
+
+func newTemplatePrinter(name, text string, out io.Writer) (*TemplatePrinter, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs()).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse output template: %w", err)
+	}
+	return &TemplatePrinter{tmpl: tmpl, out: out}, nil
+}
+
+func (p *TemplatePrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	meta, err := structToMap(r.SourceMetadata.GetData())
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+	flattened := make(map[string]any)
+	for _, data := range meta {
+		for k, v := range data {
+			flattened[k] = v
+		}
+	}
+
+	data := templateResult{
+		ResultWithMetadata: r,
+		DetectorName:       r.DetectorType.String(),
+		DecoderName:        r.DecoderType.String(),
+		SourceTypeName:     r.SourceType.String(),
+		RawString:          string(r.Raw),
+		RawV2String:        string(r.RawV2),
+		Metadata:           flattened,
+	}
+	if r.DetectorName != "" {
+		data.DetectorName = r.DetectorName
+	}
+
+	// Render into a buffer first so a failing template never produces a
+	// partially written record.
+	var buf bytes.Buffer
+	if err := p.tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("could not execute output template: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = buf.WriteTo(p.out)
+	return err
+}
+
+// PrintSummary renders the optional "summary" template with the provided data.
+// It is a no-op if the template does not define a summary.
+func (p *TemplatePrinter) PrintSummary(_ context.Context, summary any) error {
+	if p.tmpl.Lookup(summaryTemplateName) == nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := p.tmpl.ExecuteTemplate(&buf, summaryTemplateName, summary); err != nil {
+		return fmt.Errorf("could not execute summary template: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := buf.WriteTo(p.out)
+	return err
+}
+
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trimSpace": strings.TrimSpace,
+		"replace":   strings.ReplaceAll,
+		"join":      strings.Join,
+		"contains":  strings.Contains,
+	}
+}
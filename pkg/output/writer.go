@@ -0,0 +1,20 @@
+package output
+
+import (
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+)
+
+// writer is where printers write results to.
+var writer io.Writer = os.Stdout
+
+// SetWriter sets where printers write results to. It must be called before
+// any printer is created. Colors are disabled, as the output is assumed not to
+// be a terminal.
+func SetWriter(w io.Writer) {
+	writer = w
+	color.Output = w
+	color.NoColor = true
+}
@@ -0,0 +1,316 @@
+// Package packages lists the packages installed on a host, by the OS package
+// managers dpkg and rpm and globally by pip and npm, and the files they
+// installed, to audit the configuration files they dropped.
+package packages
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// Package is an installed package and the files it installed, as absolute
+// paths below the root it was found in.
+type Package struct {
+	Manager string
+	Name    string
+	Files   []string
+	// ConfigFiles are the files the package manager knows to be
+	// configuration, like dpkg's conffiles.
+	ConfigFiles []string
+}
+
+// Installed returns the packages of every package manager found below root.
+// Package managers that are not installed are skipped.
+func Installed(ctx context.Context, root string) ([]Package, error) {
+	var all []Package
+	for _, list := range []func(context.Context, string) ([]Package, error){Dpkg, RPM, Python, NPM} {
+		pkgs, err := list(ctx, root)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, pkgs...)
+	}
+	return all, nil
+}
+
+// Dpkg returns the packages installed by dpkg, from the file lists in
+// /var/lib/dpkg/info.
+func Dpkg(_ context.Context, root string) ([]Package, error) {
+	lists, err := filepath.Glob(filepath.Join(root, "var/lib/dpkg/info/*.list"))
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []Package
+	for _, list := range lists {
+		name := strings.TrimSuffix(filepath.Base(list), ".list")
+		files, err := readLines(list)
+		if err != nil {
+			return nil, fmt.Errorf("dpkg package %s: %w", name, err)
+		}
+		conffiles, err := readLines(strings.TrimSuffix(list, ".list") + ".conffiles")
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("dpkg package %s: %w", name, err)
+		}
+		for i, conffile := range conffiles {
+			// Conffiles may be followed by flags, like remove-on-upgrade.
+			conffiles[i], _, _ = strings.Cut(conffile, " ")
+		}
+		// Strip the architecture of multi-arch packages, e.g. libc6:amd64.
+		name, _, _ = strings.Cut(name, ":")
+		pkgs = append(pkgs, Package{
+			Manager:     "dpkg",
+			Name:        name,
+			Files:       rooted(root, files),
+			ConfigFiles: rooted(root, conffiles),
+		})
+	}
+	return pkgs, nil
+}
+
+// rpm runs rpm with the arguments and returns its output. It is a variable so
+// tests don't need rpm.
+var rpm = func(ctx context.Context, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, "rpm", args...).Output()
+}
+
+// RPM returns the packages installed by rpm, as listed by the rpm command,
+// if the host has an rpm database.
+func RPM(ctx context.Context, root string) ([]Package, error) {
+	if _, err := os.Stat(filepath.Join(root, "var/lib/rpm")); err != nil {
+		return nil, nil
+	}
+	out, err := rpm(ctx, "--root", root, "-qa", "--qf", `[%{NAME}\t%{FILEFLAGS:fflags}\t%{FILENAMES}\n]`)
+	if err != nil {
+		var notFound *exec.Error
+		if errors.As(err, &notFound) {
+			ctx.Logger().Info("skipping rpm packages, rpm is not installed")
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not list rpm packages: %w", err)
+	}
+
+	byName := make(map[string]*Package)
+	var names []string
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || fields[2] == "" {
+			continue
+		}
+		name, flags, file := fields[0], fields[1], filepath.Join(root, filepath.FromSlash(fields[2]))
+		pkg, ok := byName[name]
+		if !ok {
+			pkg = &Package{Manager: "rpm", Name: name}
+			byName[name] = pkg
+			names = append(names, name)
+		}
+		pkg.Files = append(pkg.Files, file)
+		// Configuration files are flagged with c.
+		if strings.Contains(flags, "c") {
+			pkg.ConfigFiles = append(pkg.ConfigFiles, file)
+		}
+	}
+	sort.Strings(names)
+	pkgs := make([]Package, len(names))
+	for i, name := range names {
+		pkgs[i] = *byName[name]
+	}
+	return pkgs, nil
+}
+
+// pythonDirs are the directories globally installed Python packages are in.
+var pythonDirs = []string{
+	"usr/lib/python3*/site-packages",
+	"usr/lib/python3*/dist-packages",
+	"usr/lib64/python3*/site-packages",
+	"usr/local/lib/python3*/site-packages",
+	"usr/local/lib/python3*/dist-packages",
+	"usr/local/lib64/python3*/site-packages",
+}
+
+// Python returns the packages installed globally by pip, from the RECORD
+// files of their .dist-info directories.
+func Python(_ context.Context, root string) ([]Package, error) {
+	var pkgs []Package
+	for _, pattern := range pythonDirs {
+		records, err := filepath.Glob(filepath.Join(root, pattern, "*.dist-info", "RECORD"))
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			f, err := os.Open(record)
+			if err != nil {
+				return nil, err
+			}
+			// RECORD is a CSV file of the paths, hashes and sizes of the
+			// files of the package.
+			reader := csv.NewReader(f)
+			reader.FieldsPerRecord = -1
+			rows, err := reader.ReadAll()
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", record, err)
+			}
+			distInfo := filepath.Dir(record)
+			sitePackages := filepath.Dir(distInfo)
+			pkg := Package{Manager: "pip", Name: distName(filepath.Base(distInfo))}
+			for _, row := range rows {
+				if len(row) > 0 && row[0] != "" {
+					pkg.Files = append(pkg.Files, filepath.Join(sitePackages, filepath.FromSlash(row[0])))
+				}
+			}
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs, nil
+}
+
+// distName returns the name of a package from its .dist-info directory, e.g.
+// requests from requests-2.31.0.dist-info.
+func distName(distInfo string) string {
+	name := strings.TrimSuffix(distInfo, ".dist-info")
+	if i := strings.Index(name, "-"); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// npmDirs are the directories globally installed npm packages are in.
+var npmDirs = []string{
+	"usr/lib/node_modules",
+	"usr/local/lib/node_modules",
+}
+
+// NPM returns the packages installed globally by npm, with the files of their
+// directories.
+func NPM(_ context.Context, root string) ([]Package, error) {
+	var pkgs []Package
+	for _, dir := range npmDirs {
+		manifests, err := filepath.Glob(filepath.Join(root, dir, "*", "package.json"))
+		if err != nil {
+			return nil, err
+		}
+		scoped, err := filepath.Glob(filepath.Join(root, dir, "@*", "*", "package.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, manifest := range append(manifests, scoped...) {
+			pkgDir := filepath.Dir(manifest)
+			rel, _ := filepath.Rel(filepath.Join(root, dir), pkgDir)
+			pkg := Package{Manager: "npm", Name: filepath.ToSlash(rel)}
+			err := filepath.WalkDir(pkgDir, func(file string, d fs.DirEntry, err error) error {
+				if err == nil && d.Type().IsRegular() {
+					pkg.Files = append(pkg.Files, file)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs, nil
+}
+
+// configExtensions are the extensions of files that usually hold
+// configuration.
+var configExtensions = map[string]bool{
+	".conf": true, ".cfg": true, ".cnf": true, ".ini": true, ".config": true,
+	".json": true, ".yaml": true, ".yml": true, ".toml": true, ".xml": true,
+	".properties": true, ".env": true, ".rc": true, ".pem": true, ".key": true,
+}
+
+// isConfig reports whether file, a path below root, is a configuration file:
+// any file under /etc, or a file with a configuration extension.
+func isConfig(root, file string) bool {
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		return false
+	}
+	rel = "/" + filepath.ToSlash(rel)
+	if strings.HasPrefix(rel, "/etc/") {
+		return true
+	}
+	base := path.Base(rel)
+	return configExtensions[strings.ToLower(path.Ext(base))] || strings.HasPrefix(base, ".env")
+}
+
+// Files returns the regular files of the packages that still exist, or only
+// their configuration files if configOnly is set. Files of OS packages are
+// only configuration files if the package manager says so or they are under
+// /etc or /opt.
+func Files(root string, pkgs []Package, configOnly bool) []string {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(file string) {
+		if seen[file] {
+			return
+		}
+		seen[file] = true
+		if info, err := os.Lstat(file); err == nil && info.Mode().IsRegular() {
+			files = append(files, file)
+		}
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.ConfigFiles {
+			add(file)
+		}
+		for _, file := range pkg.Files {
+			if configOnly && !isPackageConfig(root, pkg, file) {
+				continue
+			}
+			add(file)
+		}
+	}
+	return files
+}
+
+func isPackageConfig(root string, pkg Package, file string) bool {
+	if pkg.Manager == "dpkg" || pkg.Manager == "rpm" {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return false
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, "etc/") && !strings.HasPrefix(rel, "opt/") {
+			return false
+		}
+	}
+	return isConfig(root, file)
+}
+
+func readLines(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// rooted returns the absolute paths of files below root.
+func rooted(root string, files []string) []string {
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = filepath.Join(root, filepath.FromSlash(file))
+	}
+	return paths
+}
@@ -0,0 +1,74 @@
+package packages
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestInstalled(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"var/lib/dpkg/info/redis-server:amd64.list":      "/.\n/etc\n/etc/redis/redis.conf\n/usr/bin/redis-server\n/opt/redis/secrets.yml\n/opt/redis/README\n",
+		"var/lib/dpkg/info/redis-server:amd64.conffiles": "/etc/redis/redis.conf\n/etc/redis/old.conf remove-on-upgrade\n",
+		"etc/redis/redis.conf":                           "requirepass hunter2",
+		"usr/bin/redis-server":                           "binary",
+		"opt/redis/secrets.yml":                          "token: x",
+		"opt/redis/README":                               "docs",
+
+		"usr/lib/python3.11/site-packages/awscli-1.29.0.dist-info/RECORD": "awscli/data/config.json,sha256=x,10\nawscli/__init__.py,,\n\"awscli/a,b.ini\",,\n",
+		"usr/lib/python3.11/site-packages/awscli/data/config.json":        "{}",
+		"usr/lib/python3.11/site-packages/awscli/__init__.py":             "",
+
+		"usr/local/lib/node_modules/@vendor/cli/package.json": "{}",
+		"usr/local/lib/node_modules/@vendor/cli/.env":         "TOKEN=x",
+		"usr/local/lib/node_modules/@vendor/cli/index.js":     "",
+	})
+
+	pkgs, err := Installed(context.Background(), root)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 3)
+	assert.Equal(t, "redis-server", pkgs[0].Name)
+	assert.Equal(t, []string{filepath.Join(root, "etc/redis/redis.conf"), filepath.Join(root, "etc/redis/old.conf")}, pkgs[0].ConfigFiles)
+	assert.Equal(t, "awscli", pkgs[1].Name)
+	assert.Contains(t, pkgs[1].Files, filepath.Join(root, "usr/lib/python3.11/site-packages/awscli/a,b.ini"))
+	assert.Equal(t, "@vendor/cli", pkgs[2].Name)
+
+	assert.Equal(t, []string{
+		filepath.Join(root, "etc/redis/redis.conf"),
+		filepath.Join(root, "opt/redis/secrets.yml"),
+		filepath.Join(root, "usr/lib/python3.11/site-packages/awscli/data/config.json"),
+		filepath.Join(root, "usr/local/lib/node_modules/@vendor/cli/.env"),
+		filepath.Join(root, "usr/local/lib/node_modules/@vendor/cli/package.json"),
+	}, Files(root, pkgs, true))
+	assert.Len(t, Files(root, pkgs, false), 9)
+}
+
+func TestRPM(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "var/lib/rpm"), 0755))
+	rpm = func(_ context.Context, args ...string) ([]byte, error) {
+		assert.Equal(t, []string{"--root", root}, args[:2])
+		return []byte("httpd\tc\t/etc/httpd/conf/httpd.conf\nhttpd\t\t/usr/sbin/httpd\nbash\t\t/usr/bin/bash\n"), nil
+	}
+	pkgs, err := RPM(context.Background(), root)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 2)
+	assert.Equal(t, "bash", pkgs[0].Name)
+	assert.Equal(t, []string{filepath.Join(root, "etc/httpd/conf/httpd.conf")}, pkgs[1].ConfigFiles)
+	assert.Len(t, pkgs[1].Files, 2)
+}
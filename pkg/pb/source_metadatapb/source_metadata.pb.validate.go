@@ -66,6 +66,12 @@ func (m *Azure) validate(all bool) error {
 
 	// no validation rules for Email
 
+	// no validation rules for Account
+
+	// no validation rules for Snapshot
+
+	// no validation rules for VersionId
+
 	if len(errors) > 0 {
 		return AzureMultiError(errors)
 	}
@@ -185,6 +191,126 @@ func (m *Bitbucket) validate(all bool) error {
 
 	// no validation rules for Line
 
+	// no validation rules for ArchivePath
+
+	if all {
+		switch v := interface{}(m.GetImageLayer()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, BitbucketValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, BitbucketValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetImageLayer()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return BitbucketValidationError{
+				field:  "ImageLayer",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetPackedCommit()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, BitbucketValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, BitbucketValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackedCommit()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return BitbucketValidationError{
+				field:  "PackedCommit",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetHelmChart()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, BitbucketValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, BitbucketValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetHelmChart()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return BitbucketValidationError{
+				field:  "HelmChart",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetPackage()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, BitbucketValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, BitbucketValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackage()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return BitbucketValidationError{
+				field:  "Package",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Location
+
 	if len(errors) > 0 {
 		return BitbucketMultiError(errors)
 	}
@@ -630,6 +756,8 @@ func (m *Docker) validate(all bool) error {
 
 	// no validation rules for Tag
 
+	// no validation rules for Container
+
 	if len(errors) > 0 {
 		return DockerMultiError(errors)
 	}
@@ -819,49 +947,47 @@ var _ interface {
 	ErrorName() string
 } = ECRValidationError{}
 
-// Validate checks the field values on Filesystem with the rules defined in the
+// Validate checks the field values on ImageLayer with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Filesystem) Validate() error {
+func (m *ImageLayer) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Filesystem with the rules defined in
+// ValidateAll checks the field values on ImageLayer with the rules defined in
 // the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in FilesystemMultiError, or
+// result is a list of violation errors wrapped in ImageLayerMultiError, or
 // nil if none found.
-func (m *Filesystem) ValidateAll() error {
+func (m *ImageLayer) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Filesystem) validate(all bool) error {
+func (m *ImageLayer) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for File
-
-	// no validation rules for Link
+	// no validation rules for Digest
 
-	// no validation rules for Email
+	// no validation rules for CreatedBy
 
-	// no validation rules for Line
+	// no validation rules for Removed
 
 	if len(errors) > 0 {
-		return FilesystemMultiError(errors)
+		return ImageLayerMultiError(errors)
 	}
 
 	return nil
 }
 
-// FilesystemMultiError is an error wrapping multiple validation errors
-// returned by Filesystem.ValidateAll() if the designated constraints aren't met.
-type FilesystemMultiError []error
+// ImageLayerMultiError is an error wrapping multiple validation errors
+// returned by ImageLayer.ValidateAll() if the designated constraints aren't met.
+type ImageLayerMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m FilesystemMultiError) Error() string {
+func (m ImageLayerMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -870,11 +996,11 @@ func (m FilesystemMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m FilesystemMultiError) AllErrors() []error { return m }
+func (m ImageLayerMultiError) AllErrors() []error { return m }
 
-// FilesystemValidationError is the validation error returned by
-// Filesystem.Validate if the designated constraints aren't met.
-type FilesystemValidationError struct {
+// ImageLayerValidationError is the validation error returned by
+// ImageLayer.Validate if the designated constraints aren't met.
+type ImageLayerValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -882,22 +1008,22 @@ type FilesystemValidationError struct {
 }
 
 // Field function returns field value.
-func (e FilesystemValidationError) Field() string { return e.field }
+func (e ImageLayerValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e FilesystemValidationError) Reason() string { return e.reason }
+func (e ImageLayerValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e FilesystemValidationError) Cause() error { return e.cause }
+func (e ImageLayerValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e FilesystemValidationError) Key() bool { return e.key }
+func (e ImageLayerValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e FilesystemValidationError) ErrorName() string { return "FilesystemValidationError" }
+func (e ImageLayerValidationError) ErrorName() string { return "ImageLayerValidationError" }
 
 // Error satisfies the builtin error interface
-func (e FilesystemValidationError) Error() string {
+func (e ImageLayerValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -909,14 +1035,14 @@ func (e FilesystemValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sFilesystem.%s: %s%s",
+		"invalid %sImageLayer.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = FilesystemValidationError{}
+var _ error = ImageLayerValidationError{}
 
 var _ interface {
 	Field() string
@@ -924,23 +1050,24 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = FilesystemValidationError{}
+} = ImageLayerValidationError{}
 
-// Validate checks the field values on Git with the rules defined in the proto
-// definition for this message. If any rules are violated, the first error
-// encountered is returned, or nil if there are no violations.
-func (m *Git) Validate() error {
+// Validate checks the field values on PackedCommit with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *PackedCommit) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Git with the rules defined in the
-// proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in GitMultiError, or nil if none found.
-func (m *Git) ValidateAll() error {
+// ValidateAll checks the field values on PackedCommit with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in PackedCommitMultiError, or
+// nil if none found.
+func (m *PackedCommit) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Git) validate(all bool) error {
+func (m *PackedCommit) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
@@ -949,29 +1076,23 @@ func (m *Git) validate(all bool) error {
 
 	// no validation rules for Commit
 
-	// no validation rules for File
-
 	// no validation rules for Email
 
-	// no validation rules for Repository
-
 	// no validation rules for Timestamp
 
-	// no validation rules for Line
-
 	if len(errors) > 0 {
-		return GitMultiError(errors)
+		return PackedCommitMultiError(errors)
 	}
 
 	return nil
 }
 
-// GitMultiError is an error wrapping multiple validation errors returned by
-// Git.ValidateAll() if the designated constraints aren't met.
-type GitMultiError []error
+// PackedCommitMultiError is an error wrapping multiple validation errors
+// returned by PackedCommit.ValidateAll() if the designated constraints aren't met.
+type PackedCommitMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m GitMultiError) Error() string {
+func (m PackedCommitMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -980,11 +1101,11 @@ func (m GitMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m GitMultiError) AllErrors() []error { return m }
+func (m PackedCommitMultiError) AllErrors() []error { return m }
 
-// GitValidationError is the validation error returned by Git.Validate if the
-// designated constraints aren't met.
-type GitValidationError struct {
+// PackedCommitValidationError is the validation error returned by
+// PackedCommit.Validate if the designated constraints aren't met.
+type PackedCommitValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -992,22 +1113,22 @@ type GitValidationError struct {
 }
 
 // Field function returns field value.
-func (e GitValidationError) Field() string { return e.field }
+func (e PackedCommitValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e GitValidationError) Reason() string { return e.reason }
+func (e PackedCommitValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e GitValidationError) Cause() error { return e.cause }
+func (e PackedCommitValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e GitValidationError) Key() bool { return e.key }
+func (e PackedCommitValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e GitValidationError) ErrorName() string { return "GitValidationError" }
+func (e PackedCommitValidationError) ErrorName() string { return "PackedCommitValidationError" }
 
 // Error satisfies the builtin error interface
-func (e GitValidationError) Error() string {
+func (e PackedCommitValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -1019,14 +1140,14 @@ func (e GitValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sGit.%s: %s%s",
+		"invalid %sPackedCommit.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = GitValidationError{}
+var _ error = PackedCommitValidationError{}
 
 var _ interface {
 	Field() string
@@ -1034,60 +1155,47 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = GitValidationError{}
+} = PackedCommitValidationError{}
 
-// Validate checks the field values on Github with the rules defined in the
+// Validate checks the field values on HelmChart with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Github) Validate() error {
+func (m *HelmChart) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Github with the rules defined in the
-// proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in GithubMultiError, or nil if none found.
-func (m *Github) ValidateAll() error {
+// ValidateAll checks the field values on HelmChart with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in HelmChartMultiError, or nil
+// if none found.
+func (m *HelmChart) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Github) validate(all bool) error {
+func (m *HelmChart) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for Link
-
-	// no validation rules for Username
-
-	// no validation rules for Repository
-
-	// no validation rules for Commit
-
-	// no validation rules for Email
-
-	// no validation rules for File
-
-	// no validation rules for Timestamp
-
-	// no validation rules for Line
+	// no validation rules for Name
 
-	// no validation rules for Visibility
+	// no validation rules for Version
 
 	if len(errors) > 0 {
-		return GithubMultiError(errors)
+		return HelmChartMultiError(errors)
 	}
 
 	return nil
 }
 
-// GithubMultiError is an error wrapping multiple validation errors returned by
-// Github.ValidateAll() if the designated constraints aren't met.
-type GithubMultiError []error
+// HelmChartMultiError is an error wrapping multiple validation errors returned
+// by HelmChart.ValidateAll() if the designated constraints aren't met.
+type HelmChartMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m GithubMultiError) Error() string {
+func (m HelmChartMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -1096,11 +1204,11 @@ func (m GithubMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m GithubMultiError) AllErrors() []error { return m }
+func (m HelmChartMultiError) AllErrors() []error { return m }
 
-// GithubValidationError is the validation error returned by Github.Validate if
-// the designated constraints aren't met.
-type GithubValidationError struct {
+// HelmChartValidationError is the validation error returned by
+// HelmChart.Validate if the designated constraints aren't met.
+type HelmChartValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -1108,22 +1216,22 @@ type GithubValidationError struct {
 }
 
 // Field function returns field value.
-func (e GithubValidationError) Field() string { return e.field }
+func (e HelmChartValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e GithubValidationError) Reason() string { return e.reason }
+func (e HelmChartValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e GithubValidationError) Cause() error { return e.cause }
+func (e HelmChartValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e GithubValidationError) Key() bool { return e.key }
+func (e HelmChartValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e GithubValidationError) ErrorName() string { return "GithubValidationError" }
+func (e HelmChartValidationError) ErrorName() string { return "HelmChartValidationError" }
 
 // Error satisfies the builtin error interface
-func (e GithubValidationError) Error() string {
+func (e HelmChartValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -1135,14 +1243,14 @@ func (e GithubValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sGithub.%s: %s%s",
+		"invalid %sHelmChart.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = GithubValidationError{}
+var _ error = HelmChartValidationError{}
 
 var _ interface {
 	Field() string
@@ -1150,56 +1258,48 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = GithubValidationError{}
+} = HelmChartValidationError{}
 
-// Validate checks the field values on Gitlab with the rules defined in the
+// Validate checks the field values on Package with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Gitlab) Validate() error {
+func (m *Package) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Gitlab with the rules defined in the
+// ValidateAll checks the field values on Package with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in GitlabMultiError, or nil if none found.
-func (m *Gitlab) ValidateAll() error {
+// a list of violation errors wrapped in PackageMultiError, or nil if none found.
+func (m *Package) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Gitlab) validate(all bool) error {
+func (m *Package) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for Commit
-
-	// no validation rules for File
-
-	// no validation rules for Link
-
-	// no validation rules for Email
-
-	// no validation rules for Repository
+	// no validation rules for Ecosystem
 
-	// no validation rules for Timestamp
+	// no validation rules for Name
 
-	// no validation rules for Line
+	// no validation rules for Version
 
 	if len(errors) > 0 {
-		return GitlabMultiError(errors)
+		return PackageMultiError(errors)
 	}
 
 	return nil
 }
 
-// GitlabMultiError is an error wrapping multiple validation errors returned by
-// Gitlab.ValidateAll() if the designated constraints aren't met.
-type GitlabMultiError []error
+// PackageMultiError is an error wrapping multiple validation errors returned
+// by Package.ValidateAll() if the designated constraints aren't met.
+type PackageMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m GitlabMultiError) Error() string {
+func (m PackageMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -1208,11 +1308,11 @@ func (m GitlabMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m GitlabMultiError) AllErrors() []error { return m }
+func (m PackageMultiError) AllErrors() []error { return m }
 
-// GitlabValidationError is the validation error returned by Gitlab.Validate if
-// the designated constraints aren't met.
-type GitlabValidationError struct {
+// PackageValidationError is the validation error returned by Package.Validate
+// if the designated constraints aren't met.
+type PackageValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -1220,22 +1320,22 @@ type GitlabValidationError struct {
 }
 
 // Field function returns field value.
-func (e GitlabValidationError) Field() string { return e.field }
+func (e PackageValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e GitlabValidationError) Reason() string { return e.reason }
+func (e PackageValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e GitlabValidationError) Cause() error { return e.cause }
+func (e PackageValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e GitlabValidationError) Key() bool { return e.key }
+func (e PackageValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e GitlabValidationError) ErrorName() string { return "GitlabValidationError" }
+func (e PackageValidationError) ErrorName() string { return "PackageValidationError" }
 
 // Error satisfies the builtin error interface
-func (e GitlabValidationError) Error() string {
+func (e PackageValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -1247,14 +1347,14 @@ func (e GitlabValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sGitlab.%s: %s%s",
+		"invalid %sPackage.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = GitlabValidationError{}
+var _ error = PackageValidationError{}
 
 var _ interface {
 	Field() string
@@ -1262,56 +1362,169 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = GitlabValidationError{}
+} = PackageValidationError{}
 
-// Validate checks the field values on GCS with the rules defined in the proto
-// definition for this message. If any rules are violated, the first error
-// encountered is returned, or nil if there are no violations.
-func (m *GCS) Validate() error {
+// Validate checks the field values on Filesystem with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Filesystem) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on GCS with the rules defined in the
-// proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in GCSMultiError, or nil if none found.
-func (m *GCS) ValidateAll() error {
+// ValidateAll checks the field values on Filesystem with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in FilesystemMultiError, or
+// nil if none found.
+func (m *Filesystem) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *GCS) validate(all bool) error {
+func (m *Filesystem) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for Bucket
-
-	// no validation rules for Filename
+	// no validation rules for File
 
 	// no validation rules for Link
 
 	// no validation rules for Email
 
-	// no validation rules for CreatedAt
-
-	// no validation rules for UpdatedAt
+	// no validation rules for Line
 
-	// no validation rules for ContentType
+	// no validation rules for ArchivePath
 
-	if len(errors) > 0 {
-		return GCSMultiError(errors)
+	if all {
+		switch v := interface{}(m.GetImageLayer()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, FilesystemValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, FilesystemValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetImageLayer()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return FilesystemValidationError{
+				field:  "ImageLayer",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
 	}
 
-	return nil
-}
+	if all {
+		switch v := interface{}(m.GetPackedCommit()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, FilesystemValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, FilesystemValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackedCommit()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return FilesystemValidationError{
+				field:  "PackedCommit",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
 
-// GCSMultiError is an error wrapping multiple validation errors returned by
-// GCS.ValidateAll() if the designated constraints aren't met.
-type GCSMultiError []error
+	if all {
+		switch v := interface{}(m.GetHelmChart()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, FilesystemValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, FilesystemValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetHelmChart()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return FilesystemValidationError{
+				field:  "HelmChart",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetPackage()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, FilesystemValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, FilesystemValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackage()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return FilesystemValidationError{
+				field:  "Package",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return FilesystemMultiError(errors)
+	}
+
+	return nil
+}
+
+// FilesystemMultiError is an error wrapping multiple validation errors
+// returned by Filesystem.ValidateAll() if the designated constraints aren't met.
+type FilesystemMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m GCSMultiError) Error() string {
+func (m FilesystemMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -1320,11 +1533,11 @@ func (m GCSMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m GCSMultiError) AllErrors() []error { return m }
+func (m FilesystemMultiError) AllErrors() []error { return m }
 
-// GCSValidationError is the validation error returned by GCS.Validate if the
-// designated constraints aren't met.
-type GCSValidationError struct {
+// FilesystemValidationError is the validation error returned by
+// Filesystem.Validate if the designated constraints aren't met.
+type FilesystemValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -1332,22 +1545,22 @@ type GCSValidationError struct {
 }
 
 // Field function returns field value.
-func (e GCSValidationError) Field() string { return e.field }
+func (e FilesystemValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e GCSValidationError) Reason() string { return e.reason }
+func (e FilesystemValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e GCSValidationError) Cause() error { return e.cause }
+func (e FilesystemValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e GCSValidationError) Key() bool { return e.key }
+func (e FilesystemValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e GCSValidationError) ErrorName() string { return "GCSValidationError" }
+func (e FilesystemValidationError) ErrorName() string { return "FilesystemValidationError" }
 
 // Error satisfies the builtin error interface
-func (e GCSValidationError) Error() string {
+func (e FilesystemValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -1359,14 +1572,14 @@ func (e GCSValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sGCS.%s: %s%s",
+		"invalid %sFilesystem.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = GCSValidationError{}
+var _ error = FilesystemValidationError{}
 
 var _ interface {
 	Field() string
@@ -1374,54 +1587,174 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = GCSValidationError{}
+} = FilesystemValidationError{}
 
-// Validate checks the field values on Jira with the rules defined in the proto
+// Validate checks the field values on Git with the rules defined in the proto
 // definition for this message. If any rules are violated, the first error
 // encountered is returned, or nil if there are no violations.
-func (m *Jira) Validate() error {
+func (m *Git) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Jira with the rules defined in the
+// ValidateAll checks the field values on Git with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in JiraMultiError, or nil if none found.
-func (m *Jira) ValidateAll() error {
+// a list of violation errors wrapped in GitMultiError, or nil if none found.
+func (m *Git) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Jira) validate(all bool) error {
+func (m *Git) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for Issue
+	// no validation rules for Commit
 
-	// no validation rules for Author
+	// no validation rules for File
+
+	// no validation rules for Email
+
+	// no validation rules for Repository
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Line
+
+	// no validation rules for ArchivePath
 
 	// no validation rules for Link
 
-	// no validation rules for Location
+	if all {
+		switch v := interface{}(m.GetImageLayer()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetImageLayer()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitValidationError{
+				field:  "ImageLayer",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
 
-	// no validation rules for Email
+	if all {
+		switch v := interface{}(m.GetPackedCommit()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackedCommit()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitValidationError{
+				field:  "PackedCommit",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
 
-	// no validation rules for Timestamp
+	if all {
+		switch v := interface{}(m.GetHelmChart()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetHelmChart()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitValidationError{
+				field:  "HelmChart",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetPackage()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackage()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitValidationError{
+				field:  "Package",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
 
 	if len(errors) > 0 {
-		return JiraMultiError(errors)
+		return GitMultiError(errors)
 	}
 
 	return nil
 }
 
-// JiraMultiError is an error wrapping multiple validation errors returned by
-// Jira.ValidateAll() if the designated constraints aren't met.
-type JiraMultiError []error
+// GitMultiError is an error wrapping multiple validation errors returned by
+// Git.ValidateAll() if the designated constraints aren't met.
+type GitMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m JiraMultiError) Error() string {
+func (m GitMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -1430,11 +1763,11 @@ func (m JiraMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m JiraMultiError) AllErrors() []error { return m }
+func (m GitMultiError) AllErrors() []error { return m }
 
-// JiraValidationError is the validation error returned by Jira.Validate if the
+// GitValidationError is the validation error returned by Git.Validate if the
 // designated constraints aren't met.
-type JiraValidationError struct {
+type GitValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -1442,22 +1775,22 @@ type JiraValidationError struct {
 }
 
 // Field function returns field value.
-func (e JiraValidationError) Field() string { return e.field }
+func (e GitValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e JiraValidationError) Reason() string { return e.reason }
+func (e GitValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e JiraValidationError) Cause() error { return e.cause }
+func (e GitValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e JiraValidationError) Key() bool { return e.key }
+func (e GitValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e JiraValidationError) ErrorName() string { return "JiraValidationError" }
+func (e GitValidationError) ErrorName() string { return "GitValidationError" }
 
 // Error satisfies the builtin error interface
-func (e JiraValidationError) Error() string {
+func (e GitValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -1469,14 +1802,14 @@ func (e JiraValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sJira.%s: %s%s",
+		"invalid %sGit.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = JiraValidationError{}
+var _ error = GitValidationError{}
 
 var _ interface {
 	Field() string
@@ -1484,160 +1817,178 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = JiraValidationError{}
+} = GitValidationError{}
 
-// Validate checks the field values on NPM with the rules defined in the proto
-// definition for this message. If any rules are violated, the first error
-// encountered is returned, or nil if there are no violations.
-func (m *NPM) Validate() error {
+// Validate checks the field values on Github with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Github) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on NPM with the rules defined in the
+// ValidateAll checks the field values on Github with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in NPMMultiError, or nil if none found.
-func (m *NPM) ValidateAll() error {
+// a list of violation errors wrapped in GithubMultiError, or nil if none found.
+func (m *Github) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *NPM) validate(all bool) error {
+func (m *Github) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for File
-
-	// no validation rules for Package
-
-	// no validation rules for Release
-
 	// no validation rules for Link
 
-	// no validation rules for Email
-
-	if len(errors) > 0 {
-		return NPMMultiError(errors)
-	}
-
-	return nil
-}
-
-// NPMMultiError is an error wrapping multiple validation errors returned by
-// NPM.ValidateAll() if the designated constraints aren't met.
-type NPMMultiError []error
+	// no validation rules for Username
 
-// Error returns a concatenation of all the error messages it wraps.
-func (m NPMMultiError) Error() string {
-	var msgs []string
-	for _, err := range m {
-		msgs = append(msgs, err.Error())
-	}
-	return strings.Join(msgs, "; ")
-}
+	// no validation rules for Repository
 
-// AllErrors returns a list of validation violation errors.
-func (m NPMMultiError) AllErrors() []error { return m }
+	// no validation rules for Commit
 
-// NPMValidationError is the validation error returned by NPM.Validate if the
-// designated constraints aren't met.
-type NPMValidationError struct {
-	field  string
-	reason string
-	cause  error
-	key    bool
-}
+	// no validation rules for Email
 
-// Field function returns field value.
-func (e NPMValidationError) Field() string { return e.field }
+	// no validation rules for File
 
-// Reason function returns reason value.
-func (e NPMValidationError) Reason() string { return e.reason }
+	// no validation rules for Timestamp
 
-// Cause function returns cause value.
-func (e NPMValidationError) Cause() error { return e.cause }
+	// no validation rules for Line
 
-// Key function returns key value.
-func (e NPMValidationError) Key() bool { return e.key }
+	// no validation rules for Visibility
 
-// ErrorName returns error name.
-func (e NPMValidationError) ErrorName() string { return "NPMValidationError" }
+	// no validation rules for ArchivePath
 
-// Error satisfies the builtin error interface
-func (e NPMValidationError) Error() string {
-	cause := ""
-	if e.cause != nil {
-		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	if all {
+		switch v := interface{}(m.GetImageLayer()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GithubValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GithubValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetImageLayer()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GithubValidationError{
+				field:  "ImageLayer",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
 	}
 
-	key := ""
-	if e.key {
-		key = "key for "
+	if all {
+		switch v := interface{}(m.GetPackedCommit()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GithubValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GithubValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackedCommit()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GithubValidationError{
+				field:  "PackedCommit",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
 	}
 
-	return fmt.Sprintf(
-		"invalid %sNPM.%s: %s%s",
-		key,
-		e.field,
-		e.reason,
-		cause)
-}
-
-var _ error = NPMValidationError{}
-
-var _ interface {
-	Field() string
-	Reason() string
-	Key() bool
-	Cause() error
-	ErrorName() string
-} = NPMValidationError{}
-
-// Validate checks the field values on PyPi with the rules defined in the proto
-// definition for this message. If any rules are violated, the first error
-// encountered is returned, or nil if there are no violations.
-func (m *PyPi) Validate() error {
-	return m.validate(false)
-}
-
-// ValidateAll checks the field values on PyPi with the rules defined in the
-// proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in PyPiMultiError, or nil if none found.
-func (m *PyPi) ValidateAll() error {
-	return m.validate(true)
-}
-
-func (m *PyPi) validate(all bool) error {
-	if m == nil {
-		return nil
+	if all {
+		switch v := interface{}(m.GetHelmChart()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GithubValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GithubValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetHelmChart()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GithubValidationError{
+				field:  "HelmChart",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
 	}
 
-	var errors []error
-
-	// no validation rules for File
-
-	// no validation rules for Package
-
-	// no validation rules for Release
-
-	// no validation rules for Link
-
-	// no validation rules for Email
+	if all {
+		switch v := interface{}(m.GetPackage()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GithubValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GithubValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackage()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GithubValidationError{
+				field:  "Package",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
 
 	if len(errors) > 0 {
-		return PyPiMultiError(errors)
+		return GithubMultiError(errors)
 	}
 
 	return nil
 }
 
-// PyPiMultiError is an error wrapping multiple validation errors returned by
-// PyPi.ValidateAll() if the designated constraints aren't met.
-type PyPiMultiError []error
+// GithubMultiError is an error wrapping multiple validation errors returned by
+// Github.ValidateAll() if the designated constraints aren't met.
+type GithubMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m PyPiMultiError) Error() string {
+func (m GithubMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -1646,11 +1997,11 @@ func (m PyPiMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m PyPiMultiError) AllErrors() []error { return m }
+func (m GithubMultiError) AllErrors() []error { return m }
 
-// PyPiValidationError is the validation error returned by PyPi.Validate if the
-// designated constraints aren't met.
-type PyPiValidationError struct {
+// GithubValidationError is the validation error returned by Github.Validate if
+// the designated constraints aren't met.
+type GithubValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -1658,22 +2009,22 @@ type PyPiValidationError struct {
 }
 
 // Field function returns field value.
-func (e PyPiValidationError) Field() string { return e.field }
+func (e GithubValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e PyPiValidationError) Reason() string { return e.reason }
+func (e GithubValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e PyPiValidationError) Cause() error { return e.cause }
+func (e GithubValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e PyPiValidationError) Key() bool { return e.key }
+func (e GithubValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e PyPiValidationError) ErrorName() string { return "PyPiValidationError" }
+func (e GithubValidationError) ErrorName() string { return "GithubValidationError" }
 
 // Error satisfies the builtin error interface
-func (e PyPiValidationError) Error() string {
+func (e GithubValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -1685,14 +2036,14 @@ func (e PyPiValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sPyPi.%s: %s%s",
+		"invalid %sGithub.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = PyPiValidationError{}
+var _ error = GithubValidationError{}
 
 var _ interface {
 	Field() string
@@ -1700,30 +2051,30 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = PyPiValidationError{}
+} = GithubValidationError{}
 
-// Validate checks the field values on S3 with the rules defined in the proto
-// definition for this message. If any rules are violated, the first error
-// encountered is returned, or nil if there are no violations.
-func (m *S3) Validate() error {
+// Validate checks the field values on Gitlab with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Gitlab) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on S3 with the rules defined in the
+// ValidateAll checks the field values on Gitlab with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in S3MultiError, or nil if none found.
-func (m *S3) ValidateAll() error {
+// a list of violation errors wrapped in GitlabMultiError, or nil if none found.
+func (m *Gitlab) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *S3) validate(all bool) error {
+func (m *Gitlab) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for Bucket
+	// no validation rules for Commit
 
 	// no validation rules for File
 
@@ -1731,21 +2082,143 @@ func (m *S3) validate(all bool) error {
 
 	// no validation rules for Email
 
+	// no validation rules for Repository
+
 	// no validation rules for Timestamp
 
+	// no validation rules for Line
+
+	// no validation rules for ArchivePath
+
+	if all {
+		switch v := interface{}(m.GetImageLayer()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitlabValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitlabValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetImageLayer()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitlabValidationError{
+				field:  "ImageLayer",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetPackedCommit()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitlabValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitlabValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackedCommit()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitlabValidationError{
+				field:  "PackedCommit",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetHelmChart()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitlabValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitlabValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetHelmChart()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitlabValidationError{
+				field:  "HelmChart",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetPackage()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitlabValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitlabValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackage()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitlabValidationError{
+				field:  "Package",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	if len(errors) > 0 {
-		return S3MultiError(errors)
+		return GitlabMultiError(errors)
 	}
 
 	return nil
 }
 
-// S3MultiError is an error wrapping multiple validation errors returned by
-// S3.ValidateAll() if the designated constraints aren't met.
-type S3MultiError []error
+// GitlabMultiError is an error wrapping multiple validation errors returned by
+// Gitlab.ValidateAll() if the designated constraints aren't met.
+type GitlabMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m S3MultiError) Error() string {
+func (m GitlabMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -1754,11 +2227,11 @@ func (m S3MultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m S3MultiError) AllErrors() []error { return m }
+func (m GitlabMultiError) AllErrors() []error { return m }
 
-// S3ValidationError is the validation error returned by S3.Validate if the
-// designated constraints aren't met.
-type S3ValidationError struct {
+// GitlabValidationError is the validation error returned by Gitlab.Validate if
+// the designated constraints aren't met.
+type GitlabValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -1766,22 +2239,22 @@ type S3ValidationError struct {
 }
 
 // Field function returns field value.
-func (e S3ValidationError) Field() string { return e.field }
+func (e GitlabValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e S3ValidationError) Reason() string { return e.reason }
+func (e GitlabValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e S3ValidationError) Cause() error { return e.cause }
+func (e GitlabValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e S3ValidationError) Key() bool { return e.key }
+func (e GitlabValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e S3ValidationError) ErrorName() string { return "S3ValidationError" }
+func (e GitlabValidationError) ErrorName() string { return "GitlabValidationError" }
 
 // Error satisfies the builtin error interface
-func (e S3ValidationError) Error() string {
+func (e GitlabValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -1793,14 +2266,14 @@ func (e S3ValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sS3.%s: %s%s",
+		"invalid %sGitlab.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = S3ValidationError{}
+var _ error = GitlabValidationError{}
 
 var _ interface {
 	Field() string
@@ -1808,60 +2281,178 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = S3ValidationError{}
+} = GitlabValidationError{}
 
-// Validate checks the field values on Slack with the rules defined in the
-// proto definition for this message. If any rules are violated, the first
-// error encountered is returned, or nil if there are no violations.
-func (m *Slack) Validate() error {
+// Validate checks the field values on GCS with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *GCS) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Slack with the rules defined in the
+// ValidateAll checks the field values on GCS with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in SlackMultiError, or nil if none found.
-func (m *Slack) ValidateAll() error {
+// a list of violation errors wrapped in GCSMultiError, or nil if none found.
+func (m *GCS) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Slack) validate(all bool) error {
+func (m *GCS) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for ChannelId
+	// no validation rules for Bucket
 
-	// no validation rules for ChannelName
+	// no validation rules for Filename
 
-	// no validation rules for Timestamp
+	// no validation rules for Link
 
-	// no validation rules for UserId
+	// no validation rules for Email
 
-	// no validation rules for Link
+	// no validation rules for CreatedAt
 
-	// no validation rules for File
+	// no validation rules for UpdatedAt
 
-	// no validation rules for Email
+	// no validation rules for ContentType
 
-	// no validation rules for Visibility
+	// no validation rules for Line
 
-	// no validation rules for Location
+	// no validation rules for ArchivePath
+
+	if all {
+		switch v := interface{}(m.GetImageLayer()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GCSValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GCSValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetImageLayer()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GCSValidationError{
+				field:  "ImageLayer",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetPackedCommit()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GCSValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GCSValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackedCommit()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GCSValidationError{
+				field:  "PackedCommit",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetHelmChart()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GCSValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GCSValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetHelmChart()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GCSValidationError{
+				field:  "HelmChart",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetPackage()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GCSValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GCSValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackage()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GCSValidationError{
+				field:  "Package",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Generation
 
 	if len(errors) > 0 {
-		return SlackMultiError(errors)
+		return GCSMultiError(errors)
 	}
 
 	return nil
 }
 
-// SlackMultiError is an error wrapping multiple validation errors returned by
-// Slack.ValidateAll() if the designated constraints aren't met.
-type SlackMultiError []error
+// GCSMultiError is an error wrapping multiple validation errors returned by
+// GCS.ValidateAll() if the designated constraints aren't met.
+type GCSMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m SlackMultiError) Error() string {
+func (m GCSMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -1870,11 +2461,11 @@ func (m SlackMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m SlackMultiError) AllErrors() []error { return m }
+func (m GCSMultiError) AllErrors() []error { return m }
 
-// SlackValidationError is the validation error returned by Slack.Validate if
-// the designated constraints aren't met.
-type SlackValidationError struct {
+// GCSValidationError is the validation error returned by GCS.Validate if the
+// designated constraints aren't met.
+type GCSValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -1882,22 +2473,22 @@ type SlackValidationError struct {
 }
 
 // Field function returns field value.
-func (e SlackValidationError) Field() string { return e.field }
+func (e GCSValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e SlackValidationError) Reason() string { return e.reason }
+func (e GCSValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e SlackValidationError) Cause() error { return e.cause }
+func (e GCSValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e SlackValidationError) Key() bool { return e.key }
+func (e GCSValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e SlackValidationError) ErrorName() string { return "SlackValidationError" }
+func (e GCSValidationError) ErrorName() string { return "GCSValidationError" }
 
 // Error satisfies the builtin error interface
-func (e SlackValidationError) Error() string {
+func (e GCSValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -1909,14 +2500,14 @@ func (e SlackValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sSlack.%s: %s%s",
+		"invalid %sGCS.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = SlackValidationError{}
+var _ error = GCSValidationError{}
 
 var _ interface {
 	Field() string
@@ -1924,54 +2515,54 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = SlackValidationError{}
+} = GCSValidationError{}
 
-// Validate checks the field values on Gerrit with the rules defined in the
-// proto definition for this message. If any rules are violated, the first
-// error encountered is returned, or nil if there are no violations.
-func (m *Gerrit) Validate() error {
+// Validate checks the field values on Jira with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *Jira) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Gerrit with the rules defined in the
+// ValidateAll checks the field values on Jira with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in GerritMultiError, or nil if none found.
-func (m *Gerrit) ValidateAll() error {
+// a list of violation errors wrapped in JiraMultiError, or nil if none found.
+func (m *Jira) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Gerrit) validate(all bool) error {
+func (m *Jira) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for Commit
+	// no validation rules for Issue
 
-	// no validation rules for File
+	// no validation rules for Author
 
-	// no validation rules for Email
+	// no validation rules for Link
 
-	// no validation rules for Project
+	// no validation rules for Location
 
-	// no validation rules for Timestamp
+	// no validation rules for Email
 
-	// no validation rules for Line
+	// no validation rules for Timestamp
 
 	if len(errors) > 0 {
-		return GerritMultiError(errors)
+		return JiraMultiError(errors)
 	}
 
 	return nil
 }
 
-// GerritMultiError is an error wrapping multiple validation errors returned by
-// Gerrit.ValidateAll() if the designated constraints aren't met.
-type GerritMultiError []error
+// JiraMultiError is an error wrapping multiple validation errors returned by
+// Jira.ValidateAll() if the designated constraints aren't met.
+type JiraMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m GerritMultiError) Error() string {
+func (m JiraMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -1980,11 +2571,11 @@ func (m GerritMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m GerritMultiError) AllErrors() []error { return m }
+func (m JiraMultiError) AllErrors() []error { return m }
 
-// GerritValidationError is the validation error returned by Gerrit.Validate if
-// the designated constraints aren't met.
-type GerritValidationError struct {
+// JiraValidationError is the validation error returned by Jira.Validate if the
+// designated constraints aren't met.
+type JiraValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -1992,22 +2583,22 @@ type GerritValidationError struct {
 }
 
 // Field function returns field value.
-func (e GerritValidationError) Field() string { return e.field }
+func (e JiraValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e GerritValidationError) Reason() string { return e.reason }
+func (e JiraValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e GerritValidationError) Cause() error { return e.cause }
+func (e JiraValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e GerritValidationError) Key() bool { return e.key }
+func (e JiraValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e GerritValidationError) ErrorName() string { return "GerritValidationError" }
+func (e JiraValidationError) ErrorName() string { return "JiraValidationError" }
 
 // Error satisfies the builtin error interface
-func (e GerritValidationError) Error() string {
+func (e JiraValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -2019,14 +2610,14 @@ func (e GerritValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sGerrit.%s: %s%s",
+		"invalid %sJira.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = GerritValidationError{}
+var _ error = JiraValidationError{}
 
 var _ interface {
 	Field() string
@@ -2034,23 +2625,23 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = GerritValidationError{}
+} = JiraValidationError{}
 
-// Validate checks the field values on Test with the rules defined in the proto
+// Validate checks the field values on NPM with the rules defined in the proto
 // definition for this message. If any rules are violated, the first error
 // encountered is returned, or nil if there are no violations.
-func (m *Test) Validate() error {
+func (m *NPM) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Test with the rules defined in the
+// ValidateAll checks the field values on NPM with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in TestMultiError, or nil if none found.
-func (m *Test) ValidateAll() error {
+// a list of violation errors wrapped in NPMMultiError, or nil if none found.
+func (m *NPM) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Test) validate(all bool) error {
+func (m *NPM) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
@@ -2059,19 +2650,27 @@ func (m *Test) validate(all bool) error {
 
 	// no validation rules for File
 
+	// no validation rules for Package
+
+	// no validation rules for Release
+
+	// no validation rules for Link
+
+	// no validation rules for Email
+
 	if len(errors) > 0 {
-		return TestMultiError(errors)
+		return NPMMultiError(errors)
 	}
 
 	return nil
 }
 
-// TestMultiError is an error wrapping multiple validation errors returned by
-// Test.ValidateAll() if the designated constraints aren't met.
-type TestMultiError []error
+// NPMMultiError is an error wrapping multiple validation errors returned by
+// NPM.ValidateAll() if the designated constraints aren't met.
+type NPMMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m TestMultiError) Error() string {
+func (m NPMMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -2080,11 +2679,11 @@ func (m TestMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m TestMultiError) AllErrors() []error { return m }
+func (m NPMMultiError) AllErrors() []error { return m }
 
-// TestValidationError is the validation error returned by Test.Validate if the
+// NPMValidationError is the validation error returned by NPM.Validate if the
 // designated constraints aren't met.
-type TestValidationError struct {
+type NPMValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -2092,22 +2691,22 @@ type TestValidationError struct {
 }
 
 // Field function returns field value.
-func (e TestValidationError) Field() string { return e.field }
+func (e NPMValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e TestValidationError) Reason() string { return e.reason }
+func (e NPMValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e TestValidationError) Cause() error { return e.cause }
+func (e NPMValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e TestValidationError) Key() bool { return e.key }
+func (e NPMValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e TestValidationError) ErrorName() string { return "TestValidationError" }
+func (e NPMValidationError) ErrorName() string { return "NPMValidationError" }
 
 // Error satisfies the builtin error interface
-func (e TestValidationError) Error() string {
+func (e NPMValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -2119,14 +2718,14 @@ func (e TestValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sTest.%s: %s%s",
+		"invalid %sNPM.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = TestValidationError{}
+var _ error = NPMValidationError{}
 
 var _ interface {
 	Field() string
@@ -2134,50 +2733,52 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = TestValidationError{}
+} = NPMValidationError{}
 
-// Validate checks the field values on Jenkins with the rules defined in the
-// proto definition for this message. If any rules are violated, the first
-// error encountered is returned, or nil if there are no violations.
-func (m *Jenkins) Validate() error {
+// Validate checks the field values on PyPi with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *PyPi) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Jenkins with the rules defined in the
+// ValidateAll checks the field values on PyPi with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in JenkinsMultiError, or nil if none found.
-func (m *Jenkins) ValidateAll() error {
+// a list of violation errors wrapped in PyPiMultiError, or nil if none found.
+func (m *PyPi) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Jenkins) validate(all bool) error {
+func (m *PyPi) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for ProjectName
+	// no validation rules for File
 
-	// no validation rules for BuildNumber
+	// no validation rules for Package
+
+	// no validation rules for Release
 
 	// no validation rules for Link
 
-	// no validation rules for Timestamp
+	// no validation rules for Email
 
 	if len(errors) > 0 {
-		return JenkinsMultiError(errors)
+		return PyPiMultiError(errors)
 	}
 
 	return nil
 }
 
-// JenkinsMultiError is an error wrapping multiple validation errors returned
-// by Jenkins.ValidateAll() if the designated constraints aren't met.
-type JenkinsMultiError []error
+// PyPiMultiError is an error wrapping multiple validation errors returned by
+// PyPi.ValidateAll() if the designated constraints aren't met.
+type PyPiMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m JenkinsMultiError) Error() string {
+func (m PyPiMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -2186,11 +2787,11 @@ func (m JenkinsMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m JenkinsMultiError) AllErrors() []error { return m }
+func (m PyPiMultiError) AllErrors() []error { return m }
 
-// JenkinsValidationError is the validation error returned by Jenkins.Validate
-// if the designated constraints aren't met.
-type JenkinsValidationError struct {
+// PyPiValidationError is the validation error returned by PyPi.Validate if the
+// designated constraints aren't met.
+type PyPiValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -2198,22 +2799,22 @@ type JenkinsValidationError struct {
 }
 
 // Field function returns field value.
-func (e JenkinsValidationError) Field() string { return e.field }
+func (e PyPiValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e JenkinsValidationError) Reason() string { return e.reason }
+func (e PyPiValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e JenkinsValidationError) Cause() error { return e.cause }
+func (e PyPiValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e JenkinsValidationError) Key() bool { return e.key }
+func (e PyPiValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e JenkinsValidationError) ErrorName() string { return "JenkinsValidationError" }
+func (e PyPiValidationError) ErrorName() string { return "PyPiValidationError" }
 
 // Error satisfies the builtin error interface
-func (e JenkinsValidationError) Error() string {
+func (e PyPiValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -2225,14 +2826,14 @@ func (e JenkinsValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sJenkins.%s: %s%s",
+		"invalid %sPyPi.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = JenkinsValidationError{}
+var _ error = PyPiValidationError{}
 
 var _ interface {
 	Field() string
@@ -2240,62 +2841,176 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = JenkinsValidationError{}
+} = PyPiValidationError{}
 
-// Validate checks the field values on Teams with the rules defined in the
-// proto definition for this message. If any rules are violated, the first
-// error encountered is returned, or nil if there are no violations.
-func (m *Teams) Validate() error {
+// Validate checks the field values on S3 with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *S3) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Teams with the rules defined in the
+// ValidateAll checks the field values on S3 with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in TeamsMultiError, or nil if none found.
-func (m *Teams) ValidateAll() error {
+// a list of violation errors wrapped in S3MultiError, or nil if none found.
+func (m *S3) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Teams) validate(all bool) error {
+func (m *S3) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for ChannelId
+	// no validation rules for Bucket
 
-	// no validation rules for ChannelName
+	// no validation rules for File
+
+	// no validation rules for Link
+
+	// no validation rules for Email
 
 	// no validation rules for Timestamp
 
-	// no validation rules for UserId
+	// no validation rules for Line
 
-	// no validation rules for Link
+	// no validation rules for ArchivePath
 
-	// no validation rules for File
+	if all {
+		switch v := interface{}(m.GetImageLayer()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, S3ValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, S3ValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetImageLayer()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return S3ValidationError{
+				field:  "ImageLayer",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
 
-	// no validation rules for Email
+	if all {
+		switch v := interface{}(m.GetPackedCommit()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, S3ValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, S3ValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackedCommit()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return S3ValidationError{
+				field:  "PackedCommit",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
 
-	// no validation rules for Location
+	if all {
+		switch v := interface{}(m.GetHelmChart()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, S3ValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, S3ValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetHelmChart()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return S3ValidationError{
+				field:  "HelmChart",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
 
-	// no validation rules for TeamName
+	if all {
+		switch v := interface{}(m.GetPackage()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, S3ValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, S3ValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackage()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return S3ValidationError{
+				field:  "Package",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
 
-	// no validation rules for TeamId
+	// no validation rules for VersionId
+
+	// no validation rules for Deleted
 
 	if len(errors) > 0 {
-		return TeamsMultiError(errors)
+		return S3MultiError(errors)
 	}
 
 	return nil
 }
 
-// TeamsMultiError is an error wrapping multiple validation errors returned by
-// Teams.ValidateAll() if the designated constraints aren't met.
-type TeamsMultiError []error
+// S3MultiError is an error wrapping multiple validation errors returned by
+// S3.ValidateAll() if the designated constraints aren't met.
+type S3MultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m TeamsMultiError) Error() string {
+func (m S3MultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -2304,11 +3019,11 @@ func (m TeamsMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m TeamsMultiError) AllErrors() []error { return m }
+func (m S3MultiError) AllErrors() []error { return m }
 
-// TeamsValidationError is the validation error returned by Teams.Validate if
-// the designated constraints aren't met.
-type TeamsValidationError struct {
+// S3ValidationError is the validation error returned by S3.Validate if the
+// designated constraints aren't met.
+type S3ValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -2316,22 +3031,22 @@ type TeamsValidationError struct {
 }
 
 // Field function returns field value.
-func (e TeamsValidationError) Field() string { return e.field }
+func (e S3ValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e TeamsValidationError) Reason() string { return e.reason }
+func (e S3ValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e TeamsValidationError) Cause() error { return e.cause }
+func (e S3ValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e TeamsValidationError) Key() bool { return e.key }
+func (e S3ValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e TeamsValidationError) ErrorName() string { return "TeamsValidationError" }
+func (e S3ValidationError) ErrorName() string { return "S3ValidationError" }
 
 // Error satisfies the builtin error interface
-func (e TeamsValidationError) Error() string {
+func (e S3ValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -2343,14 +3058,14 @@ func (e TeamsValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sTeams.%s: %s%s",
+		"invalid %sS3.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = TeamsValidationError{}
+var _ error = S3ValidationError{}
 
 var _ interface {
 	Field() string
@@ -2358,55 +3073,62 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = TeamsValidationError{}
+} = S3ValidationError{}
 
-// Validate checks the field values on Artifactory with the rules defined in
-// the proto definition for this message. If any rules are violated, the first
+// Validate checks the field values on Slack with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Artifactory) Validate() error {
+func (m *Slack) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Artifactory with the rules defined in
-// the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in ArtifactoryMultiError, or
-// nil if none found.
-func (m *Artifactory) ValidateAll() error {
+// ValidateAll checks the field values on Slack with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in SlackMultiError, or nil if none found.
+func (m *Slack) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Artifactory) validate(all bool) error {
+func (m *Slack) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for Repo
-
-	// no validation rules for Path
+	// no validation rules for ChannelId
 
-	// no validation rules for Link
+	// no validation rules for ChannelName
 
 	// no validation rules for Timestamp
 
-	// no validation rules for Username
+	// no validation rules for UserId
+
+	// no validation rules for Link
+
+	// no validation rules for File
 
 	// no validation rules for Email
 
+	// no validation rules for Visibility
+
+	// no validation rules for Location
+
+	// no validation rules for UserName
+
 	if len(errors) > 0 {
-		return ArtifactoryMultiError(errors)
+		return SlackMultiError(errors)
 	}
 
 	return nil
 }
 
-// ArtifactoryMultiError is an error wrapping multiple validation errors
-// returned by Artifactory.ValidateAll() if the designated constraints aren't met.
-type ArtifactoryMultiError []error
+// SlackMultiError is an error wrapping multiple validation errors returned by
+// Slack.ValidateAll() if the designated constraints aren't met.
+type SlackMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m ArtifactoryMultiError) Error() string {
+func (m SlackMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -2415,11 +3137,11 @@ func (m ArtifactoryMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m ArtifactoryMultiError) AllErrors() []error { return m }
+func (m SlackMultiError) AllErrors() []error { return m }
 
-// ArtifactoryValidationError is the validation error returned by
-// Artifactory.Validate if the designated constraints aren't met.
-type ArtifactoryValidationError struct {
+// SlackValidationError is the validation error returned by Slack.Validate if
+// the designated constraints aren't met.
+type SlackValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -2427,22 +3149,22 @@ type ArtifactoryValidationError struct {
 }
 
 // Field function returns field value.
-func (e ArtifactoryValidationError) Field() string { return e.field }
+func (e SlackValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e ArtifactoryValidationError) Reason() string { return e.reason }
+func (e SlackValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e ArtifactoryValidationError) Cause() error { return e.cause }
+func (e SlackValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e ArtifactoryValidationError) Key() bool { return e.key }
+func (e SlackValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e ArtifactoryValidationError) ErrorName() string { return "ArtifactoryValidationError" }
+func (e SlackValidationError) ErrorName() string { return "SlackValidationError" }
 
 // Error satisfies the builtin error interface
-func (e ArtifactoryValidationError) Error() string {
+func (e SlackValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -2454,14 +3176,14 @@ func (e ArtifactoryValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sArtifactory.%s: %s%s",
+		"invalid %sSlack.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = ArtifactoryValidationError{}
+var _ error = SlackValidationError{}
 
 var _ interface {
 	Field() string
@@ -2469,67 +3191,193 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = ArtifactoryValidationError{}
+} = SlackValidationError{}
 
-// Validate checks the field values on Syslog with the rules defined in the
+// Validate checks the field values on Gerrit with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Syslog) Validate() error {
+func (m *Gerrit) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Syslog with the rules defined in the
+// ValidateAll checks the field values on Gerrit with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in SyslogMultiError, or nil if none found.
-func (m *Syslog) ValidateAll() error {
+// a list of violation errors wrapped in GerritMultiError, or nil if none found.
+func (m *Gerrit) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Syslog) validate(all bool) error {
+func (m *Gerrit) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for Hostname
-
-	// no validation rules for Appname
+	// no validation rules for Commit
 
-	// no validation rules for Procid
+	// no validation rules for File
 
-	// no validation rules for Timestamp
+	// no validation rules for Email
 
-	// no validation rules for Client
+	// no validation rules for Project
 
-	// no validation rules for Facility
+	// no validation rules for Timestamp
 
-	if len(errors) > 0 {
-		return SyslogMultiError(errors)
-	}
+	// no validation rules for Line
 
-	return nil
-}
+	// no validation rules for ArchivePath
 
-// SyslogMultiError is an error wrapping multiple validation errors returned by
-// Syslog.ValidateAll() if the designated constraints aren't met.
-type SyslogMultiError []error
+	// no validation rules for Link
 
-// Error returns a concatenation of all the error messages it wraps.
-func (m SyslogMultiError) Error() string {
-	var msgs []string
-	for _, err := range m {
-		msgs = append(msgs, err.Error())
+	if all {
+		switch v := interface{}(m.GetImageLayer()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GerritValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GerritValidationError{
+					field:  "ImageLayer",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetImageLayer()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GerritValidationError{
+				field:  "ImageLayer",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
 	}
-	return strings.Join(msgs, "; ")
-}
 
-// AllErrors returns a list of validation violation errors.
-func (m SyslogMultiError) AllErrors() []error { return m }
+	if all {
+		switch v := interface{}(m.GetPackedCommit()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GerritValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GerritValidationError{
+					field:  "PackedCommit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackedCommit()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GerritValidationError{
+				field:  "PackedCommit",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
 
-// SyslogValidationError is the validation error returned by Syslog.Validate if
-// the designated constraints aren't met.
-type SyslogValidationError struct {
+	if all {
+		switch v := interface{}(m.GetHelmChart()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GerritValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GerritValidationError{
+					field:  "HelmChart",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetHelmChart()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GerritValidationError{
+				field:  "HelmChart",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetPackage()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GerritValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GerritValidationError{
+					field:  "Package",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPackage()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GerritValidationError{
+				field:  "Package",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Change
+
+	// no validation rules for PatchSet
+
+	// no validation rules for Location
+
+	if len(errors) > 0 {
+		return GerritMultiError(errors)
+	}
+
+	return nil
+}
+
+// GerritMultiError is an error wrapping multiple validation errors returned by
+// Gerrit.ValidateAll() if the designated constraints aren't met.
+type GerritMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GerritMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GerritMultiError) AllErrors() []error { return m }
+
+// GerritValidationError is the validation error returned by Gerrit.Validate if
+// the designated constraints aren't met.
+type GerritValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -2537,22 +3385,22 @@ type SyslogValidationError struct {
 }
 
 // Field function returns field value.
-func (e SyslogValidationError) Field() string { return e.field }
+func (e GerritValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e SyslogValidationError) Reason() string { return e.reason }
+func (e GerritValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e SyslogValidationError) Cause() error { return e.cause }
+func (e GerritValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e SyslogValidationError) Key() bool { return e.key }
+func (e GerritValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e SyslogValidationError) ErrorName() string { return "SyslogValidationError" }
+func (e GerritValidationError) ErrorName() string { return "GerritValidationError" }
 
 // Error satisfies the builtin error interface
-func (e SyslogValidationError) Error() string {
+func (e GerritValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -2564,14 +3412,14 @@ func (e SyslogValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sSyslog.%s: %s%s",
+		"invalid %sGerrit.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = SyslogValidationError{}
+var _ error = GerritValidationError{}
 
 var _ interface {
 	Field() string
@@ -2579,79 +3427,44 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = SyslogValidationError{}
+} = GerritValidationError{}
 
-// Validate checks the field values on PublicEventMonitoring with the rules
-// defined in the proto definition for this message. If any rules are
-// violated, the first error encountered is returned, or nil if there are no violations.
-func (m *PublicEventMonitoring) Validate() error {
+// Validate checks the field values on Test with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *Test) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on PublicEventMonitoring with the rules
-// defined in the proto definition for this message. If any rules are
-// violated, the result is a list of violation errors wrapped in
-// PublicEventMonitoringMultiError, or nil if none found.
-func (m *PublicEventMonitoring) ValidateAll() error {
+// ValidateAll checks the field values on Test with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in TestMultiError, or nil if none found.
+func (m *Test) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *PublicEventMonitoring) validate(all bool) error {
+func (m *Test) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	switch m.Metadata.(type) {
-
-	case *PublicEventMonitoring_Github:
-
-		if all {
-			switch v := interface{}(m.GetGithub()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, PublicEventMonitoringValidationError{
-						field:  "Github",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, PublicEventMonitoringValidationError{
-						field:  "Github",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetGithub()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return PublicEventMonitoringValidationError{
-					field:  "Github",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
-
-	}
+	// no validation rules for File
 
 	if len(errors) > 0 {
-		return PublicEventMonitoringMultiError(errors)
+		return TestMultiError(errors)
 	}
 
 	return nil
 }
 
-// PublicEventMonitoringMultiError is an error wrapping multiple validation
-// errors returned by PublicEventMonitoring.ValidateAll() if the designated
-// constraints aren't met.
-type PublicEventMonitoringMultiError []error
+// TestMultiError is an error wrapping multiple validation errors returned by
+// Test.ValidateAll() if the designated constraints aren't met.
+type TestMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m PublicEventMonitoringMultiError) Error() string {
+func (m TestMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -2660,11 +3473,11 @@ func (m PublicEventMonitoringMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m PublicEventMonitoringMultiError) AllErrors() []error { return m }
+func (m TestMultiError) AllErrors() []error { return m }
 
-// PublicEventMonitoringValidationError is the validation error returned by
-// PublicEventMonitoring.Validate if the designated constraints aren't met.
-type PublicEventMonitoringValidationError struct {
+// TestValidationError is the validation error returned by Test.Validate if the
+// designated constraints aren't met.
+type TestValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -2672,24 +3485,22 @@ type PublicEventMonitoringValidationError struct {
 }
 
 // Field function returns field value.
-func (e PublicEventMonitoringValidationError) Field() string { return e.field }
+func (e TestValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e PublicEventMonitoringValidationError) Reason() string { return e.reason }
+func (e TestValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e PublicEventMonitoringValidationError) Cause() error { return e.cause }
+func (e TestValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e PublicEventMonitoringValidationError) Key() bool { return e.key }
+func (e TestValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e PublicEventMonitoringValidationError) ErrorName() string {
-	return "PublicEventMonitoringValidationError"
-}
+func (e TestValidationError) ErrorName() string { return "TestValidationError" }
 
 // Error satisfies the builtin error interface
-func (e PublicEventMonitoringValidationError) Error() string {
+func (e TestValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -2701,14 +3512,14 @@ func (e PublicEventMonitoringValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sPublicEventMonitoring.%s: %s%s",
+		"invalid %sTest.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = PublicEventMonitoringValidationError{}
+var _ error = TestValidationError{}
 
 var _ interface {
 	Field() string
@@ -2716,57 +3527,50 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = PublicEventMonitoringValidationError{}
+} = TestValidationError{}
 
-// Validate checks the field values on SharePoint with the rules defined in the
+// Validate checks the field values on Jenkins with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *SharePoint) Validate() error {
+func (m *Jenkins) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on SharePoint with the rules defined in
-// the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in SharePointMultiError, or
-// nil if none found.
-func (m *SharePoint) ValidateAll() error {
+// ValidateAll checks the field values on Jenkins with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in JenkinsMultiError, or nil if none found.
+func (m *Jenkins) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *SharePoint) validate(all bool) error {
+func (m *Jenkins) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for Link
-
-	// no validation rules for Timestamp
-
-	// no validation rules for Author
-
-	// no validation rules for Title
+	// no validation rules for ProjectName
 
-	// no validation rules for Views
+	// no validation rules for BuildNumber
 
-	// no validation rules for Docid
+	// no validation rules for Link
 
-	// no validation rules for Email
+	// no validation rules for Timestamp
 
 	if len(errors) > 0 {
-		return SharePointMultiError(errors)
+		return JenkinsMultiError(errors)
 	}
 
 	return nil
 }
 
-// SharePointMultiError is an error wrapping multiple validation errors
-// returned by SharePoint.ValidateAll() if the designated constraints aren't met.
-type SharePointMultiError []error
+// JenkinsMultiError is an error wrapping multiple validation errors returned
+// by Jenkins.ValidateAll() if the designated constraints aren't met.
+type JenkinsMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m SharePointMultiError) Error() string {
+func (m JenkinsMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -2775,11 +3579,11 @@ func (m SharePointMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m SharePointMultiError) AllErrors() []error { return m }
+func (m JenkinsMultiError) AllErrors() []error { return m }
 
-// SharePointValidationError is the validation error returned by
-// SharePoint.Validate if the designated constraints aren't met.
-type SharePointValidationError struct {
+// JenkinsValidationError is the validation error returned by Jenkins.Validate
+// if the designated constraints aren't met.
+type JenkinsValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -2787,22 +3591,22 @@ type SharePointValidationError struct {
 }
 
 // Field function returns field value.
-func (e SharePointValidationError) Field() string { return e.field }
+func (e JenkinsValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e SharePointValidationError) Reason() string { return e.reason }
+func (e JenkinsValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e SharePointValidationError) Cause() error { return e.cause }
+func (e JenkinsValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e SharePointValidationError) Key() bool { return e.key }
+func (e JenkinsValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e SharePointValidationError) ErrorName() string { return "SharePointValidationError" }
+func (e JenkinsValidationError) ErrorName() string { return "JenkinsValidationError" }
 
 // Error satisfies the builtin error interface
-func (e SharePointValidationError) Error() string {
+func (e JenkinsValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -2814,14 +3618,14 @@ func (e SharePointValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sSharePoint.%s: %s%s",
+		"invalid %sJenkins.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = SharePointValidationError{}
+var _ error = JenkinsValidationError{}
 
 var _ interface {
 	Field() string
@@ -2829,57 +3633,64 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = SharePointValidationError{}
+} = JenkinsValidationError{}
 
-// Validate checks the field values on GoogleDrive with the rules defined in
-// the proto definition for this message. If any rules are violated, the first
+// Validate checks the field values on Teams with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *GoogleDrive) Validate() error {
+func (m *Teams) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on GoogleDrive with the rules defined in
-// the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in GoogleDriveMultiError, or
-// nil if none found.
-func (m *GoogleDrive) ValidateAll() error {
+// ValidateAll checks the field values on Teams with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in TeamsMultiError, or nil if none found.
+func (m *Teams) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *GoogleDrive) validate(all bool) error {
+func (m *Teams) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for File
-
-	// no validation rules for Link
+	// no validation rules for ChannelId
 
-	// no validation rules for Email
+	// no validation rules for ChannelName
 
 	// no validation rules for Timestamp
 
-	// no validation rules for Shared
+	// no validation rules for UserId
 
-	// no validation rules for LastModifiedBy
+	// no validation rules for Link
 
-	// no validation rules for Path
+	// no validation rules for File
+
+	// no validation rules for Email
+
+	// no validation rules for Location
+
+	// no validation rules for TeamName
+
+	// no validation rules for TeamId
+
+	// no validation rules for UserName
 
 	if len(errors) > 0 {
-		return GoogleDriveMultiError(errors)
+		return TeamsMultiError(errors)
 	}
 
 	return nil
 }
 
-// GoogleDriveMultiError is an error wrapping multiple validation errors
-// returned by GoogleDrive.ValidateAll() if the designated constraints aren't met.
-type GoogleDriveMultiError []error
+// TeamsMultiError is an error wrapping multiple validation errors returned by
+// Teams.ValidateAll() if the designated constraints aren't met.
+type TeamsMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m GoogleDriveMultiError) Error() string {
+func (m TeamsMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -2888,11 +3699,11 @@ func (m GoogleDriveMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m GoogleDriveMultiError) AllErrors() []error { return m }
+func (m TeamsMultiError) AllErrors() []error { return m }
 
-// GoogleDriveValidationError is the validation error returned by
-// GoogleDrive.Validate if the designated constraints aren't met.
-type GoogleDriveValidationError struct {
+// TeamsValidationError is the validation error returned by Teams.Validate if
+// the designated constraints aren't met.
+type TeamsValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -2900,22 +3711,22 @@ type GoogleDriveValidationError struct {
 }
 
 // Field function returns field value.
-func (e GoogleDriveValidationError) Field() string { return e.field }
+func (e TeamsValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e GoogleDriveValidationError) Reason() string { return e.reason }
+func (e TeamsValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e GoogleDriveValidationError) Cause() error { return e.cause }
+func (e TeamsValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e GoogleDriveValidationError) Key() bool { return e.key }
+func (e TeamsValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e GoogleDriveValidationError) ErrorName() string { return "GoogleDriveValidationError" }
+func (e TeamsValidationError) ErrorName() string { return "TeamsValidationError" }
 
 // Error satisfies the builtin error interface
-func (e GoogleDriveValidationError) Error() string {
+func (e TeamsValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -2927,14 +3738,14 @@ func (e GoogleDriveValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sGoogleDrive.%s: %s%s",
+		"invalid %sTeams.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = GoogleDriveValidationError{}
+var _ error = TeamsValidationError{}
 
 var _ interface {
 	Field() string
@@ -2942,65 +3753,165 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = GoogleDriveValidationError{}
+} = TeamsValidationError{}
 
-// Validate checks the field values on AzureRepos with the rules defined in the
-// proto definition for this message. If any rules are violated, the first
+// Validate checks the field values on Artifactory with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *AzureRepos) Validate() error {
+func (m *Artifactory) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on AzureRepos with the rules defined in
+// ValidateAll checks the field values on Artifactory with the rules defined in
 // the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in AzureReposMultiError, or
+// result is a list of violation errors wrapped in ArtifactoryMultiError, or
 // nil if none found.
-func (m *AzureRepos) ValidateAll() error {
+func (m *Artifactory) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *AzureRepos) validate(all bool) error {
+func (m *Artifactory) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
+	// no validation rules for Repo
+
+	// no validation rules for Path
+
 	// no validation rules for Link
 
+	// no validation rules for Timestamp
+
 	// no validation rules for Username
 
-	// no validation rules for Repository
+	// no validation rules for Email
 
-	// no validation rules for Commit
+	if len(errors) > 0 {
+		return ArtifactoryMultiError(errors)
+	}
 
-	// no validation rules for Email
+	return nil
+}
 
-	// no validation rules for File
+// ArtifactoryMultiError is an error wrapping multiple validation errors
+// returned by Artifactory.ValidateAll() if the designated constraints aren't met.
+type ArtifactoryMultiError []error
 
-	// no validation rules for Timestamp
+// Error returns a concatenation of all the error messages it wraps.
+func (m ArtifactoryMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
 
-	// no validation rules for Line
+// AllErrors returns a list of validation violation errors.
+func (m ArtifactoryMultiError) AllErrors() []error { return m }
 
-	// no validation rules for Visibility
+// ArtifactoryValidationError is the validation error returned by
+// Artifactory.Validate if the designated constraints aren't met.
+type ArtifactoryValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
 
-	// no validation rules for Project
+// Field function returns field value.
+func (e ArtifactoryValidationError) Field() string { return e.field }
 
-	// no validation rules for Organization
+// Reason function returns reason value.
+func (e ArtifactoryValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ArtifactoryValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ArtifactoryValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ArtifactoryValidationError) ErrorName() string { return "ArtifactoryValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ArtifactoryValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sArtifactory.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ArtifactoryValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ArtifactoryValidationError{}
+
+// Validate checks the field values on Syslog with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Syslog) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Syslog with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in SyslogMultiError, or nil if none found.
+func (m *Syslog) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Syslog) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Hostname
+
+	// no validation rules for Appname
+
+	// no validation rules for Procid
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Client
+
+	// no validation rules for Facility
 
 	if len(errors) > 0 {
-		return AzureReposMultiError(errors)
+		return SyslogMultiError(errors)
 	}
 
 	return nil
 }
 
-// AzureReposMultiError is an error wrapping multiple validation errors
-// returned by AzureRepos.ValidateAll() if the designated constraints aren't met.
-type AzureReposMultiError []error
+// SyslogMultiError is an error wrapping multiple validation errors returned by
+// Syslog.ValidateAll() if the designated constraints aren't met.
+type SyslogMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m AzureReposMultiError) Error() string {
+func (m SyslogMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -3009,11 +3920,11 @@ func (m AzureReposMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m AzureReposMultiError) AllErrors() []error { return m }
+func (m SyslogMultiError) AllErrors() []error { return m }
 
-// AzureReposValidationError is the validation error returned by
-// AzureRepos.Validate if the designated constraints aren't met.
-type AzureReposValidationError struct {
+// SyslogValidationError is the validation error returned by Syslog.Validate if
+// the designated constraints aren't met.
+type SyslogValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -3021,22 +3932,22 @@ type AzureReposValidationError struct {
 }
 
 // Field function returns field value.
-func (e AzureReposValidationError) Field() string { return e.field }
+func (e SyslogValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e AzureReposValidationError) Reason() string { return e.reason }
+func (e SyslogValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e AzureReposValidationError) Cause() error { return e.cause }
+func (e SyslogValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e AzureReposValidationError) Key() bool { return e.key }
+func (e SyslogValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e AzureReposValidationError) ErrorName() string { return "AzureReposValidationError" }
+func (e SyslogValidationError) ErrorName() string { return "SyslogValidationError" }
 
 // Error satisfies the builtin error interface
-func (e AzureReposValidationError) Error() string {
+func (e SyslogValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -3048,14 +3959,14 @@ func (e AzureReposValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sAzureRepos.%s: %s%s",
+		"invalid %sSyslog.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = AzureReposValidationError{}
+var _ error = SyslogValidationError{}
 
 var _ interface {
 	Field() string
@@ -3063,40 +3974,3630 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = AzureReposValidationError{}
+} = SyslogValidationError{}
 
-// Validate checks the field values on MetaData with the rules defined in the
-// proto definition for this message. If any rules are violated, the first
-// error encountered is returned, or nil if there are no violations.
-func (m *MetaData) Validate() error {
+// Validate checks the field values on PublicEventMonitoring with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *PublicEventMonitoring) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on MetaData with the rules defined in
-// the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in MetaDataMultiError, or nil
-// if none found.
-func (m *MetaData) ValidateAll() error {
+// ValidateAll checks the field values on PublicEventMonitoring with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// PublicEventMonitoringMultiError, or nil if none found.
+func (m *PublicEventMonitoring) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *MetaData) validate(all bool) error {
-	if m == nil {
-		return nil
-	}
+func (m *PublicEventMonitoring) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	switch m.Metadata.(type) {
+
+	case *PublicEventMonitoring_Github:
+
+		if all {
+			switch v := interface{}(m.GetGithub()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, PublicEventMonitoringValidationError{
+						field:  "Github",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, PublicEventMonitoringValidationError{
+						field:  "Github",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetGithub()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return PublicEventMonitoringValidationError{
+					field:  "Github",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return PublicEventMonitoringMultiError(errors)
+	}
+
+	return nil
+}
+
+// PublicEventMonitoringMultiError is an error wrapping multiple validation
+// errors returned by PublicEventMonitoring.ValidateAll() if the designated
+// constraints aren't met.
+type PublicEventMonitoringMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m PublicEventMonitoringMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m PublicEventMonitoringMultiError) AllErrors() []error { return m }
+
+// PublicEventMonitoringValidationError is the validation error returned by
+// PublicEventMonitoring.Validate if the designated constraints aren't met.
+type PublicEventMonitoringValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e PublicEventMonitoringValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e PublicEventMonitoringValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e PublicEventMonitoringValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e PublicEventMonitoringValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e PublicEventMonitoringValidationError) ErrorName() string {
+	return "PublicEventMonitoringValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e PublicEventMonitoringValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sPublicEventMonitoring.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = PublicEventMonitoringValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = PublicEventMonitoringValidationError{}
+
+// Validate checks the field values on SharePoint with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *SharePoint) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SharePoint with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in SharePointMultiError, or
+// nil if none found.
+func (m *SharePoint) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SharePoint) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Link
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Author
+
+	// no validation rules for Title
+
+	// no validation rules for Views
+
+	// no validation rules for Docid
+
+	// no validation rules for Email
+
+	// no validation rules for Site
+
+	// no validation rules for Drive
+
+	// no validation rules for Path
+
+	// no validation rules for Location
+
+	if len(errors) > 0 {
+		return SharePointMultiError(errors)
+	}
+
+	return nil
+}
+
+// SharePointMultiError is an error wrapping multiple validation errors
+// returned by SharePoint.ValidateAll() if the designated constraints aren't met.
+type SharePointMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SharePointMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SharePointMultiError) AllErrors() []error { return m }
+
+// SharePointValidationError is the validation error returned by
+// SharePoint.Validate if the designated constraints aren't met.
+type SharePointValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SharePointValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SharePointValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SharePointValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SharePointValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SharePointValidationError) ErrorName() string { return "SharePointValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SharePointValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSharePoint.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SharePointValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SharePointValidationError{}
+
+// Validate checks the field values on GoogleDrive with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *GoogleDrive) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GoogleDrive with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in GoogleDriveMultiError, or
+// nil if none found.
+func (m *GoogleDrive) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GoogleDrive) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for File
+
+	// no validation rules for Link
+
+	// no validation rules for Email
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Shared
+
+	// no validation rules for LastModifiedBy
+
+	// no validation rules for Path
+
+	// no validation rules for DriveId
+
+	// no validation rules for FileId
+
+	if len(errors) > 0 {
+		return GoogleDriveMultiError(errors)
+	}
+
+	return nil
+}
+
+// GoogleDriveMultiError is an error wrapping multiple validation errors
+// returned by GoogleDrive.ValidateAll() if the designated constraints aren't met.
+type GoogleDriveMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GoogleDriveMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GoogleDriveMultiError) AllErrors() []error { return m }
+
+// GoogleDriveValidationError is the validation error returned by
+// GoogleDrive.Validate if the designated constraints aren't met.
+type GoogleDriveValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GoogleDriveValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GoogleDriveValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GoogleDriveValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GoogleDriveValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GoogleDriveValidationError) ErrorName() string { return "GoogleDriveValidationError" }
+
+// Error satisfies the builtin error interface
+func (e GoogleDriveValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGoogleDrive.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GoogleDriveValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GoogleDriveValidationError{}
+
+// Validate checks the field values on AzureRepos with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *AzureRepos) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on AzureRepos with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in AzureReposMultiError, or
+// nil if none found.
+func (m *AzureRepos) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *AzureRepos) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Link
+
+	// no validation rules for Username
+
+	// no validation rules for Repository
+
+	// no validation rules for Commit
+
+	// no validation rules for Email
+
+	// no validation rules for File
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Line
+
+	// no validation rules for Visibility
+
+	// no validation rules for Project
+
+	// no validation rules for Organization
+
+	// no validation rules for Location
+
+	if len(errors) > 0 {
+		return AzureReposMultiError(errors)
+	}
+
+	return nil
+}
+
+// AzureReposMultiError is an error wrapping multiple validation errors
+// returned by AzureRepos.ValidateAll() if the designated constraints aren't met.
+type AzureReposMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m AzureReposMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m AzureReposMultiError) AllErrors() []error { return m }
+
+// AzureReposValidationError is the validation error returned by
+// AzureRepos.Validate if the designated constraints aren't met.
+type AzureReposValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e AzureReposValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e AzureReposValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e AzureReposValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e AzureReposValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e AzureReposValidationError) ErrorName() string { return "AzureReposValidationError" }
+
+// Error satisfies the builtin error interface
+func (e AzureReposValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sAzureRepos.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = AzureReposValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = AzureReposValidationError{}
+
+// Validate checks the field values on Process with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Process) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Process with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in ProcessMultiError, or nil if none found.
+func (m *Process) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Process) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Pid
+
+	// no validation rules for Name
+
+	// no validation rules for User
+
+	// no validation rules for Field
+
+	if len(errors) > 0 {
+		return ProcessMultiError(errors)
+	}
+
+	return nil
+}
+
+// ProcessMultiError is an error wrapping multiple validation errors returned
+// by Process.ValidateAll() if the designated constraints aren't met.
+type ProcessMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ProcessMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ProcessMultiError) AllErrors() []error { return m }
+
+// ProcessValidationError is the validation error returned by Process.Validate
+// if the designated constraints aren't met.
+type ProcessValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ProcessValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ProcessValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ProcessValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ProcessValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ProcessValidationError) ErrorName() string { return "ProcessValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ProcessValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sProcess.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ProcessValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ProcessValidationError{}
+
+// Validate checks the field values on Dropbox with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Dropbox) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Dropbox with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in DropboxMultiError, or nil if none found.
+func (m *Dropbox) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Dropbox) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for File
+
+	// no validation rules for Link
+
+	// no validation rules for Email
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Namespace
+
+	// no validation rules for Id
+
+	if len(errors) > 0 {
+		return DropboxMultiError(errors)
+	}
+
+	return nil
+}
+
+// DropboxMultiError is an error wrapping multiple validation errors returned
+// by Dropbox.ValidateAll() if the designated constraints aren't met.
+type DropboxMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DropboxMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DropboxMultiError) AllErrors() []error { return m }
+
+// DropboxValidationError is the validation error returned by Dropbox.Validate
+// if the designated constraints aren't met.
+type DropboxValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DropboxValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DropboxValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DropboxValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DropboxValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DropboxValidationError) ErrorName() string { return "DropboxValidationError" }
+
+// Error satisfies the builtin error interface
+func (e DropboxValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDropbox.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DropboxValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DropboxValidationError{}
+
+// Validate checks the field values on FTP with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *FTP) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on FTP with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in FTPMultiError, or nil if none found.
+func (m *FTP) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *FTP) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for File
+
+	// no validation rules for Link
+
+	// no validation rules for Host
+
+	// no validation rules for Timestamp
+
+	if len(errors) > 0 {
+		return FTPMultiError(errors)
+	}
+
+	return nil
+}
+
+// FTPMultiError is an error wrapping multiple validation errors returned by
+// FTP.ValidateAll() if the designated constraints aren't met.
+type FTPMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m FTPMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m FTPMultiError) AllErrors() []error { return m }
+
+// FTPValidationError is the validation error returned by FTP.Validate if the
+// designated constraints aren't met.
+type FTPValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e FTPValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e FTPValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e FTPValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e FTPValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e FTPValidationError) ErrorName() string { return "FTPValidationError" }
+
+// Error satisfies the builtin error interface
+func (e FTPValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sFTP.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = FTPValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = FTPValidationError{}
+
+// Validate checks the field values on SMB with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *SMB) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SMB with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in SMBMultiError, or nil if none found.
+func (m *SMB) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SMB) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for File
+
+	// no validation rules for Link
+
+	// no validation rules for Share
+
+	// no validation rules for Host
+
+	// no validation rules for Timestamp
+
+	if len(errors) > 0 {
+		return SMBMultiError(errors)
+	}
+
+	return nil
+}
+
+// SMBMultiError is an error wrapping multiple validation errors returned by
+// SMB.ValidateAll() if the designated constraints aren't met.
+type SMBMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SMBMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SMBMultiError) AllErrors() []error { return m }
+
+// SMBValidationError is the validation error returned by SMB.Validate if the
+// designated constraints aren't met.
+type SMBValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SMBValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SMBValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SMBValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SMBValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SMBValidationError) ErrorName() string { return "SMBValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SMBValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSMB.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SMBValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SMBValidationError{}
+
+// Validate checks the field values on Kubernetes with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Kubernetes) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Kubernetes with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in KubernetesMultiError, or
+// nil if none found.
+func (m *Kubernetes) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Kubernetes) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Cluster
+
+	// no validation rules for Namespace
+
+	// no validation rules for Kind
+
+	// no validation rules for Name
+
+	// no validation rules for Field
+
+	if len(errors) > 0 {
+		return KubernetesMultiError(errors)
+	}
+
+	return nil
+}
+
+// KubernetesMultiError is an error wrapping multiple validation errors
+// returned by Kubernetes.ValidateAll() if the designated constraints aren't met.
+type KubernetesMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m KubernetesMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m KubernetesMultiError) AllErrors() []error { return m }
+
+// KubernetesValidationError is the validation error returned by
+// Kubernetes.Validate if the designated constraints aren't met.
+type KubernetesValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e KubernetesValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e KubernetesValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e KubernetesValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e KubernetesValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e KubernetesValidationError) ErrorName() string { return "KubernetesValidationError" }
+
+// Error satisfies the builtin error interface
+func (e KubernetesValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sKubernetes.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = KubernetesValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = KubernetesValidationError{}
+
+// Validate checks the field values on Consul with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Consul) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Consul with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in ConsulMultiError, or nil if none found.
+func (m *Consul) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Consul) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Key
+
+	// no validation rules for Link
+
+	// no validation rules for Datacenter
+
+	if len(errors) > 0 {
+		return ConsulMultiError(errors)
+	}
+
+	return nil
+}
+
+// ConsulMultiError is an error wrapping multiple validation errors returned by
+// Consul.ValidateAll() if the designated constraints aren't met.
+type ConsulMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ConsulMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ConsulMultiError) AllErrors() []error { return m }
+
+// ConsulValidationError is the validation error returned by Consul.Validate if
+// the designated constraints aren't met.
+type ConsulValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ConsulValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ConsulValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ConsulValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ConsulValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ConsulValidationError) ErrorName() string { return "ConsulValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ConsulValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sConsul.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ConsulValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ConsulValidationError{}
+
+// Validate checks the field values on Etcd with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *Etcd) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Etcd with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in EtcdMultiError, or nil if none found.
+func (m *Etcd) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Etcd) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Key
+
+	// no validation rules for Endpoint
+
+	// no validation rules for ModRevision
+
+	if len(errors) > 0 {
+		return EtcdMultiError(errors)
+	}
+
+	return nil
+}
+
+// EtcdMultiError is an error wrapping multiple validation errors returned by
+// Etcd.ValidateAll() if the designated constraints aren't met.
+type EtcdMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m EtcdMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m EtcdMultiError) AllErrors() []error { return m }
+
+// EtcdValidationError is the validation error returned by Etcd.Validate if the
+// designated constraints aren't met.
+type EtcdValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e EtcdValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e EtcdValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e EtcdValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e EtcdValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e EtcdValidationError) ErrorName() string { return "EtcdValidationError" }
+
+// Error satisfies the builtin error interface
+func (e EtcdValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sEtcd.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = EtcdValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = EtcdValidationError{}
+
+// Validate checks the field values on Elasticsearch with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Elasticsearch) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Elasticsearch with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in ElasticsearchMultiError, or
+// nil if none found.
+func (m *Elasticsearch) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Elasticsearch) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Index
+
+	// no validation rules for DocumentId
+
+	// no validation rules for Link
+
+	if len(errors) > 0 {
+		return ElasticsearchMultiError(errors)
+	}
+
+	return nil
+}
+
+// ElasticsearchMultiError is an error wrapping multiple validation errors
+// returned by Elasticsearch.ValidateAll() if the designated constraints
+// aren't met.
+type ElasticsearchMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ElasticsearchMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ElasticsearchMultiError) AllErrors() []error { return m }
+
+// ElasticsearchValidationError is the validation error returned by
+// Elasticsearch.Validate if the designated constraints aren't met.
+type ElasticsearchValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ElasticsearchValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ElasticsearchValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ElasticsearchValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ElasticsearchValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ElasticsearchValidationError) ErrorName() string { return "ElasticsearchValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ElasticsearchValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sElasticsearch.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ElasticsearchValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ElasticsearchValidationError{}
+
+// Validate checks the field values on Database with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Database) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Database with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in DatabaseMultiError, or nil
+// if none found.
+func (m *Database) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Database) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Database
+
+	// no validation rules for Table
+
+	// no validation rules for Column
+
+	// no validation rules for PrimaryKey
+
+	// no validation rules for Location
+
+	if len(errors) > 0 {
+		return DatabaseMultiError(errors)
+	}
+
+	return nil
+}
+
+// DatabaseMultiError is an error wrapping multiple validation errors returned
+// by Database.ValidateAll() if the designated constraints aren't met.
+type DatabaseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DatabaseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DatabaseMultiError) AllErrors() []error { return m }
+
+// DatabaseValidationError is the validation error returned by
+// Database.Validate if the designated constraints aren't met.
+type DatabaseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DatabaseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DatabaseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DatabaseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DatabaseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DatabaseValidationError) ErrorName() string { return "DatabaseValidationError" }
+
+// Error satisfies the builtin error interface
+func (e DatabaseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDatabase.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DatabaseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DatabaseValidationError{}
+
+// Validate checks the field values on MongoDB with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *MongoDB) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on MongoDB with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in MongoDBMultiError, or nil if none found.
+func (m *MongoDB) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *MongoDB) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Database
+
+	// no validation rules for Collection
+
+	// no validation rules for DocumentId
+
+	if len(errors) > 0 {
+		return MongoDBMultiError(errors)
+	}
+
+	return nil
+}
+
+// MongoDBMultiError is an error wrapping multiple validation errors returned
+// by MongoDB.ValidateAll() if the designated constraints aren't met.
+type MongoDBMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m MongoDBMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m MongoDBMultiError) AllErrors() []error { return m }
+
+// MongoDBValidationError is the validation error returned by MongoDB.Validate
+// if the designated constraints aren't met.
+type MongoDBValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e MongoDBValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e MongoDBValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e MongoDBValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e MongoDBValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e MongoDBValidationError) ErrorName() string { return "MongoDBValidationError" }
+
+// Error satisfies the builtin error interface
+func (e MongoDBValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sMongoDB.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = MongoDBValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = MongoDBValidationError{}
+
+// Validate checks the field values on Redis with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Redis) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Redis with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in RedisMultiError, or nil if none found.
+func (m *Redis) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Redis) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Key
+
+	// no validation rules for Type
+
+	// no validation rules for Database
+
+	// no validation rules for Address
+
+	if len(errors) > 0 {
+		return RedisMultiError(errors)
+	}
+
+	return nil
+}
+
+// RedisMultiError is an error wrapping multiple validation errors returned by
+// Redis.ValidateAll() if the designated constraints aren't met.
+type RedisMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m RedisMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m RedisMultiError) AllErrors() []error { return m }
+
+// RedisValidationError is the validation error returned by Redis.Validate if
+// the designated constraints aren't met.
+type RedisValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e RedisValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e RedisValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e RedisValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e RedisValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e RedisValidationError) ErrorName() string { return "RedisValidationError" }
+
+// Error satisfies the builtin error interface
+func (e RedisValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sRedis.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = RedisValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = RedisValidationError{}
+
+// Validate checks the field values on Nexus with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Nexus) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Nexus with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in NexusMultiError, or nil if none found.
+func (m *Nexus) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Nexus) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Repository
+
+	// no validation rules for Path
+
+	// no validation rules for Link
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Format
+
+	if len(errors) > 0 {
+		return NexusMultiError(errors)
+	}
+
+	return nil
+}
+
+// NexusMultiError is an error wrapping multiple validation errors returned by
+// Nexus.ValidateAll() if the designated constraints aren't met.
+type NexusMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m NexusMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m NexusMultiError) AllErrors() []error { return m }
+
+// NexusValidationError is the validation error returned by Nexus.Validate if
+// the designated constraints aren't met.
+type NexusValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e NexusValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e NexusValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e NexusValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e NexusValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e NexusValidationError) ErrorName() string { return "NexusValidationError" }
+
+// Error satisfies the builtin error interface
+func (e NexusValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sNexus.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = NexusValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = NexusValidationError{}
+
+// Validate checks the field values on Registry with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Registry) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Registry with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in RegistryMultiError, or nil
+// if none found.
+func (m *Registry) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Registry) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Registry
+
+	// no validation rules for Repository
+
+	// no validation rules for Tag
+
+	// no validation rules for Digest
+
+	// no validation rules for Platform
+
+	// no validation rules for Layer
+
+	// no validation rules for File
+
+	if len(errors) > 0 {
+		return RegistryMultiError(errors)
+	}
+
+	return nil
+}
+
+// RegistryMultiError is an error wrapping multiple validation errors returned
+// by Registry.ValidateAll() if the designated constraints aren't met.
+type RegistryMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m RegistryMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m RegistryMultiError) AllErrors() []error { return m }
+
+// RegistryValidationError is the validation error returned by
+// Registry.Validate if the designated constraints aren't met.
+type RegistryValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e RegistryValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e RegistryValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e RegistryValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e RegistryValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e RegistryValidationError) ErrorName() string { return "RegistryValidationError" }
+
+// Error satisfies the builtin error interface
+func (e RegistryValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sRegistry.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = RegistryValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = RegistryValidationError{}
+
+// Validate checks the field values on CloudLogs with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *CloudLogs) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CloudLogs with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in CloudLogsMultiError, or nil
+// if none found.
+func (m *CloudLogs) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CloudLogs) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Platform
+
+	// no validation rules for LogGroup
+
+	// no validation rules for LogStream
+
+	// no validation rules for Timestamp
+
+	// no validation rules for EventId
+
+	if len(errors) > 0 {
+		return CloudLogsMultiError(errors)
+	}
+
+	return nil
+}
+
+// CloudLogsMultiError is an error wrapping multiple validation errors returned
+// by CloudLogs.ValidateAll() if the designated constraints aren't met.
+type CloudLogsMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CloudLogsMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CloudLogsMultiError) AllErrors() []error { return m }
+
+// CloudLogsValidationError is the validation error returned by
+// CloudLogs.Validate if the designated constraints aren't met.
+type CloudLogsValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CloudLogsValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CloudLogsValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CloudLogsValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CloudLogsValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CloudLogsValidationError) ErrorName() string { return "CloudLogsValidationError" }
+
+// Error satisfies the builtin error interface
+func (e CloudLogsValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCloudLogs.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CloudLogsValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CloudLogsValidationError{}
+
+// Validate checks the field values on Splunk with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Splunk) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Splunk with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in SplunkMultiError, or nil if none found.
+func (m *Splunk) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Splunk) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Index
+
+	// no validation rules for Sourcetype
+
+	// no validation rules for Source
+
+	// no validation rules for Host
+
+	// no validation rules for Timestamp
+
+	// no validation rules for EventId
+
+	// no validation rules for Search
+
+	if len(errors) > 0 {
+		return SplunkMultiError(errors)
+	}
+
+	return nil
+}
+
+// SplunkMultiError is an error wrapping multiple validation errors returned by
+// Splunk.ValidateAll() if the designated constraints aren't met.
+type SplunkMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SplunkMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SplunkMultiError) AllErrors() []error { return m }
+
+// SplunkValidationError is the validation error returned by Splunk.Validate if
+// the designated constraints aren't met.
+type SplunkValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SplunkValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SplunkValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SplunkValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SplunkValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SplunkValidationError) ErrorName() string { return "SplunkValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SplunkValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSplunk.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SplunkValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SplunkValidationError{}
+
+// Validate checks the field values on Kafka with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Kafka) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Kafka with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in KafkaMultiError, or nil if none found.
+func (m *Kafka) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Kafka) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Topic
+
+	// no validation rules for Partition
+
+	// no validation rules for Offset
+
+	// no validation rules for Timestamp
+
+	// no validation rules for Key
+
+	if len(errors) > 0 {
+		return KafkaMultiError(errors)
+	}
+
+	return nil
+}
+
+// KafkaMultiError is an error wrapping multiple validation errors returned by
+// Kafka.ValidateAll() if the designated constraints aren't met.
+type KafkaMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m KafkaMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m KafkaMultiError) AllErrors() []error { return m }
+
+// KafkaValidationError is the validation error returned by Kafka.Validate if
+// the designated constraints aren't met.
+type KafkaValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e KafkaValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e KafkaValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e KafkaValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e KafkaValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e KafkaValidationError) ErrorName() string { return "KafkaValidationError" }
+
+// Error satisfies the builtin error interface
+func (e KafkaValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sKafka.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = KafkaValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = KafkaValidationError{}
+
+// Validate checks the field values on IMAP with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *IMAP) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on IMAP with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in IMAPMultiError, or nil if none found.
+func (m *IMAP) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *IMAP) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Folder
+
+	// no validation rules for Uid
+
+	// no validation rules for MessageId
+
+	// no validation rules for Subject
+
+	// no validation rules for From
+
+	// no validation rules for Date
+
+	// no validation rules for ArchivePath
+
+	if len(errors) > 0 {
+		return IMAPMultiError(errors)
+	}
+
+	return nil
+}
+
+// IMAPMultiError is an error wrapping multiple validation errors returned by
+// IMAP.ValidateAll() if the designated constraints aren't met.
+type IMAPMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m IMAPMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m IMAPMultiError) AllErrors() []error { return m }
+
+// IMAPValidationError is the validation error returned by IMAP.Validate if the
+// designated constraints aren't met.
+type IMAPValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e IMAPValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e IMAPValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e IMAPValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e IMAPValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e IMAPValidationError) ErrorName() string { return "IMAPValidationError" }
+
+// Error satisfies the builtin error interface
+func (e IMAPValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sIMAP.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = IMAPValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = IMAPValidationError{}
+
+// Validate checks the field values on Helpdesk with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Helpdesk) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Helpdesk with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in HelpdeskMultiError, or nil
+// if none found.
+func (m *Helpdesk) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Helpdesk) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Platform
+
+	// no validation rules for Ticket
+
+	// no validation rules for Subject
+
+	// no validation rules for Location
+
+	// no validation rules for Author
+
+	// no validation rules for Link
+
+	// no validation rules for Timestamp
+
+	// no validation rules for ArchivePath
+
+	if len(errors) > 0 {
+		return HelpdeskMultiError(errors)
+	}
+
+	return nil
+}
+
+// HelpdeskMultiError is an error wrapping multiple validation errors returned
+// by Helpdesk.ValidateAll() if the designated constraints aren't met.
+type HelpdeskMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m HelpdeskMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m HelpdeskMultiError) AllErrors() []error { return m }
+
+// HelpdeskValidationError is the validation error returned by
+// Helpdesk.Validate if the designated constraints aren't met.
+type HelpdeskValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e HelpdeskValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e HelpdeskValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e HelpdeskValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e HelpdeskValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e HelpdeskValidationError) ErrorName() string { return "HelpdeskValidationError" }
+
+// Error satisfies the builtin error interface
+func (e HelpdeskValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sHelpdesk.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = HelpdeskValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = HelpdeskValidationError{}
+
+// Validate checks the field values on ServiceNow with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *ServiceNow) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ServiceNow with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in ServiceNowMultiError, or
+// nil if none found.
+func (m *ServiceNow) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ServiceNow) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Table
+
+	// no validation rules for SysId
+
+	// no validation rules for Number
+
+	// no validation rules for Location
+
+	// no validation rules for Author
+
+	// no validation rules for Link
+
+	// no validation rules for Timestamp
+
+	// no validation rules for ArchivePath
+
+	if len(errors) > 0 {
+		return ServiceNowMultiError(errors)
+	}
+
+	return nil
+}
+
+// ServiceNowMultiError is an error wrapping multiple validation errors
+// returned by ServiceNow.ValidateAll() if the designated constraints aren't met.
+type ServiceNowMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ServiceNowMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ServiceNowMultiError) AllErrors() []error { return m }
+
+// ServiceNowValidationError is the validation error returned by
+// ServiceNow.Validate if the designated constraints aren't met.
+type ServiceNowValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ServiceNowValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ServiceNowValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ServiceNowValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ServiceNowValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ServiceNowValidationError) ErrorName() string { return "ServiceNowValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ServiceNowValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sServiceNow.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ServiceNowValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ServiceNowValidationError{}
+
+// Validate checks the field values on Notion with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Notion) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Notion with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in NotionMultiError, or nil if none found.
+func (m *Notion) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Notion) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Object
+
+	// no validation rules for Id
+
+	// no validation rules for Title
+
+	// no validation rules for Location
+
+	// no validation rules for Link
+
+	// no validation rules for Timestamp
+
+	// no validation rules for ArchivePath
+
+	if len(errors) > 0 {
+		return NotionMultiError(errors)
+	}
+
+	return nil
+}
+
+// NotionMultiError is an error wrapping multiple validation errors returned by
+// Notion.ValidateAll() if the designated constraints aren't met.
+type NotionMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m NotionMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m NotionMultiError) AllErrors() []error { return m }
+
+// NotionValidationError is the validation error returned by Notion.Validate if
+// the designated constraints aren't met.
+type NotionValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e NotionValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e NotionValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e NotionValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e NotionValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e NotionValidationError) ErrorName() string { return "NotionValidationError" }
+
+// Error satisfies the builtin error interface
+func (e NotionValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sNotion.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = NotionValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = NotionValidationError{}
+
+// Validate checks the field values on Tracker with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Tracker) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Tracker with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in TrackerMultiError, or nil if none found.
+func (m *Tracker) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Tracker) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Platform
+
+	// no validation rules for Project
+
+	// no validation rules for Item
+
+	// no validation rules for Title
+
+	// no validation rules for Location
+
+	// no validation rules for Author
+
+	// no validation rules for Link
+
+	// no validation rules for Timestamp
+
+	// no validation rules for ArchivePath
+
+	if len(errors) > 0 {
+		return TrackerMultiError(errors)
+	}
+
+	return nil
+}
+
+// TrackerMultiError is an error wrapping multiple validation errors returned
+// by Tracker.ValidateAll() if the designated constraints aren't met.
+type TrackerMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m TrackerMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m TrackerMultiError) AllErrors() []error { return m }
+
+// TrackerValidationError is the validation error returned by Tracker.Validate
+// if the designated constraints aren't met.
+type TrackerValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e TrackerValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e TrackerValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e TrackerValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e TrackerValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e TrackerValidationError) ErrorName() string { return "TrackerValidationError" }
+
+// Error satisfies the builtin error interface
+func (e TrackerValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sTracker.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = TrackerValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = TrackerValidationError{}
+
+// Validate checks the field values on Discord with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Discord) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Discord with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in DiscordMultiError, or nil if none found.
+func (m *Discord) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Discord) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for GuildId
+
+	// no validation rules for GuildName
+
+	// no validation rules for ChannelId
+
+	// no validation rules for ChannelName
+
+	// no validation rules for MessageId
+
+	// no validation rules for Author
+
+	// no validation rules for Location
+
+	// no validation rules for Link
+
+	// no validation rules for Timestamp
+
+	// no validation rules for ArchivePath
+
+	if len(errors) > 0 {
+		return DiscordMultiError(errors)
+	}
+
+	return nil
+}
+
+// DiscordMultiError is an error wrapping multiple validation errors returned
+// by Discord.ValidateAll() if the designated constraints aren't met.
+type DiscordMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DiscordMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DiscordMultiError) AllErrors() []error { return m }
+
+// DiscordValidationError is the validation error returned by Discord.Validate
+// if the designated constraints aren't met.
+type DiscordValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DiscordValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DiscordValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DiscordValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DiscordValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DiscordValidationError) ErrorName() string { return "DiscordValidationError" }
+
+// Error satisfies the builtin error interface
+func (e DiscordValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDiscord.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DiscordValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DiscordValidationError{}
+
+// Validate checks the field values on MetaData with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *MetaData) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on MetaData with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in MetaDataMultiError, or nil
+// if none found.
+func (m *MetaData) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *MetaData) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	switch m.Data.(type) {
+
+	case *MetaData_Azure:
+
+		if all {
+			switch v := interface{}(m.GetAzure()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Azure",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Azure",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetAzure()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Azure",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Bitbucket:
+
+		if all {
+			switch v := interface{}(m.GetBitbucket()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Bitbucket",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Bitbucket",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetBitbucket()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Bitbucket",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Circleci:
+
+		if all {
+			switch v := interface{}(m.GetCircleci()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Circleci",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Circleci",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetCircleci()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Circleci",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Confluence:
+
+		if all {
+			switch v := interface{}(m.GetConfluence()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Confluence",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Confluence",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetConfluence()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Confluence",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Docker:
+
+		if all {
+			switch v := interface{}(m.GetDocker()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Docker",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Docker",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetDocker()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Docker",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Ecr:
+
+		if all {
+			switch v := interface{}(m.GetEcr()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Ecr",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Ecr",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetEcr()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Ecr",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Gcs:
+
+		if all {
+			switch v := interface{}(m.GetGcs()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Gcs",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Gcs",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetGcs()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Gcs",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Github:
+
+		if all {
+			switch v := interface{}(m.GetGithub()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Github",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Github",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetGithub()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Github",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Gitlab:
+
+		if all {
+			switch v := interface{}(m.GetGitlab()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Gitlab",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Gitlab",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetGitlab()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Gitlab",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Jira:
+
+		if all {
+			switch v := interface{}(m.GetJira()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Jira",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Jira",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetJira()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Jira",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Npm:
+
+		if all {
+			switch v := interface{}(m.GetNpm()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Npm",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Npm",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetNpm()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Npm",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Pypi:
+
+		if all {
+			switch v := interface{}(m.GetPypi()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Pypi",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Pypi",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetPypi()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Pypi",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_S3:
+
+		if all {
+			switch v := interface{}(m.GetS3()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "S3",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "S3",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetS3()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "S3",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Slack:
+
+		if all {
+			switch v := interface{}(m.GetSlack()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Slack",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Slack",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetSlack()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Slack",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Filesystem:
+
+		if all {
+			switch v := interface{}(m.GetFilesystem()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Filesystem",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Filesystem",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetFilesystem()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Filesystem",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Git:
+
+		if all {
+			switch v := interface{}(m.GetGit()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Git",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Git",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetGit()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Git",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Test:
+
+		if all {
+			switch v := interface{}(m.GetTest()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Test",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Test",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetTest()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Test",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Buildkite:
+
+		if all {
+			switch v := interface{}(m.GetBuildkite()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Buildkite",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Buildkite",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetBuildkite()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Buildkite",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Gerrit:
+
+		if all {
+			switch v := interface{}(m.GetGerrit()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Gerrit",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Gerrit",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetGerrit()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Gerrit",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Jenkins:
+
+		if all {
+			switch v := interface{}(m.GetJenkins()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Jenkins",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Jenkins",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetJenkins()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Jenkins",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *MetaData_Teams:
+
+		if all {
+			switch v := interface{}(m.GetTeams()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Teams",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Teams",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetTeams()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Teams",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
 
-	var errors []error
+	case *MetaData_Artifactory:
 
-	switch m.Data.(type) {
+		if all {
+			switch v := interface{}(m.GetArtifactory()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Artifactory",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Artifactory",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetArtifactory()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Artifactory",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
 
-	case *MetaData_Azure:
+	case *MetaData_Syslog:
 
 		if all {
-			switch v := interface{}(m.GetAzure()).(type) {
+			switch v := interface{}(m.GetSyslog()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Azure",
+						field:  "Syslog",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3104,30 +7605,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Azure",
+						field:  "Syslog",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetAzure()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetSyslog()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Azure",
+					field:  "Syslog",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Bitbucket:
+	case *MetaData_PublicEventMonitoring:
 
 		if all {
-			switch v := interface{}(m.GetBitbucket()).(type) {
+			switch v := interface{}(m.GetPublicEventMonitoring()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Bitbucket",
+						field:  "PublicEventMonitoring",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3135,30 +7636,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Bitbucket",
+						field:  "PublicEventMonitoring",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetBitbucket()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetPublicEventMonitoring()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Bitbucket",
+					field:  "PublicEventMonitoring",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Circleci:
+	case *MetaData_Sharepoint:
 
 		if all {
-			switch v := interface{}(m.GetCircleci()).(type) {
+			switch v := interface{}(m.GetSharepoint()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Circleci",
+						field:  "Sharepoint",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3166,30 +7667,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Circleci",
+						field:  "Sharepoint",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetCircleci()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetSharepoint()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Circleci",
+					field:  "Sharepoint",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Confluence:
+	case *MetaData_GoogleDrive:
 
 		if all {
-			switch v := interface{}(m.GetConfluence()).(type) {
+			switch v := interface{}(m.GetGoogleDrive()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Confluence",
+						field:  "GoogleDrive",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3197,30 +7698,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Confluence",
+						field:  "GoogleDrive",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetConfluence()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetGoogleDrive()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Confluence",
+					field:  "GoogleDrive",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Docker:
+	case *MetaData_AzureRepos:
 
 		if all {
-			switch v := interface{}(m.GetDocker()).(type) {
+			switch v := interface{}(m.GetAzureRepos()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Docker",
+						field:  "AzureRepos",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3228,30 +7729,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Docker",
+						field:  "AzureRepos",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetDocker()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetAzureRepos()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Docker",
+					field:  "AzureRepos",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Ecr:
+	case *MetaData_Process:
 
 		if all {
-			switch v := interface{}(m.GetEcr()).(type) {
+			switch v := interface{}(m.GetProcess()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Ecr",
+						field:  "Process",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3259,30 +7760,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Ecr",
+						field:  "Process",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetEcr()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetProcess()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Ecr",
+					field:  "Process",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Gcs:
+	case *MetaData_Dropbox:
 
 		if all {
-			switch v := interface{}(m.GetGcs()).(type) {
+			switch v := interface{}(m.GetDropbox()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Gcs",
+						field:  "Dropbox",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3290,30 +7791,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Gcs",
+						field:  "Dropbox",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetGcs()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetDropbox()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Gcs",
+					field:  "Dropbox",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Github:
+	case *MetaData_Ftp:
 
 		if all {
-			switch v := interface{}(m.GetGithub()).(type) {
+			switch v := interface{}(m.GetFtp()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Github",
+						field:  "Ftp",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3321,30 +7822,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Github",
+						field:  "Ftp",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetGithub()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetFtp()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Github",
+					field:  "Ftp",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Gitlab:
+	case *MetaData_Smb:
 
 		if all {
-			switch v := interface{}(m.GetGitlab()).(type) {
+			switch v := interface{}(m.GetSmb()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Gitlab",
+						field:  "Smb",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3352,30 +7853,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Gitlab",
+						field:  "Smb",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetGitlab()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetSmb()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Gitlab",
+					field:  "Smb",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Jira:
+	case *MetaData_Kubernetes:
 
 		if all {
-			switch v := interface{}(m.GetJira()).(type) {
+			switch v := interface{}(m.GetKubernetes()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Jira",
+						field:  "Kubernetes",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3383,30 +7884,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Jira",
+						field:  "Kubernetes",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetJira()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetKubernetes()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Jira",
+					field:  "Kubernetes",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Npm:
+	case *MetaData_Consul:
 
 		if all {
-			switch v := interface{}(m.GetNpm()).(type) {
+			switch v := interface{}(m.GetConsul()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Npm",
+						field:  "Consul",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3414,30 +7915,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Npm",
+						field:  "Consul",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetNpm()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetConsul()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Npm",
+					field:  "Consul",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Pypi:
+	case *MetaData_Etcd:
 
 		if all {
-			switch v := interface{}(m.GetPypi()).(type) {
+			switch v := interface{}(m.GetEtcd()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Pypi",
+						field:  "Etcd",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3445,30 +7946,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Pypi",
+						field:  "Etcd",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetPypi()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetEtcd()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Pypi",
+					field:  "Etcd",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_S3:
+	case *MetaData_Elasticsearch:
 
 		if all {
-			switch v := interface{}(m.GetS3()).(type) {
+			switch v := interface{}(m.GetElasticsearch()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "S3",
+						field:  "Elasticsearch",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3476,30 +7977,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "S3",
+						field:  "Elasticsearch",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetS3()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetElasticsearch()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "S3",
+					field:  "Elasticsearch",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Slack:
+	case *MetaData_Database:
 
 		if all {
-			switch v := interface{}(m.GetSlack()).(type) {
+			switch v := interface{}(m.GetDatabase()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Slack",
+						field:  "Database",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3507,30 +8008,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Slack",
+						field:  "Database",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetSlack()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetDatabase()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Slack",
+					field:  "Database",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Filesystem:
+	case *MetaData_Mongodb:
 
 		if all {
-			switch v := interface{}(m.GetFilesystem()).(type) {
+			switch v := interface{}(m.GetMongodb()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Filesystem",
+						field:  "Mongodb",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3538,30 +8039,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Filesystem",
+						field:  "Mongodb",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetFilesystem()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetMongodb()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Filesystem",
+					field:  "Mongodb",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Git:
+	case *MetaData_Redis:
 
 		if all {
-			switch v := interface{}(m.GetGit()).(type) {
+			switch v := interface{}(m.GetRedis()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Git",
+						field:  "Redis",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3569,30 +8070,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Git",
+						field:  "Redis",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetGit()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetRedis()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Git",
+					field:  "Redis",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Test:
+	case *MetaData_Nexus:
 
 		if all {
-			switch v := interface{}(m.GetTest()).(type) {
+			switch v := interface{}(m.GetNexus()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Test",
+						field:  "Nexus",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3600,30 +8101,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Test",
+						field:  "Nexus",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetTest()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetNexus()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Test",
+					field:  "Nexus",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Buildkite:
+	case *MetaData_Registry:
 
 		if all {
-			switch v := interface{}(m.GetBuildkite()).(type) {
+			switch v := interface{}(m.GetRegistry()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Buildkite",
+						field:  "Registry",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3631,30 +8132,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Buildkite",
+						field:  "Registry",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetBuildkite()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetRegistry()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Buildkite",
+					field:  "Registry",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Gerrit:
+	case *MetaData_CloudLogs:
 
 		if all {
-			switch v := interface{}(m.GetGerrit()).(type) {
+			switch v := interface{}(m.GetCloudLogs()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Gerrit",
+						field:  "CloudLogs",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3662,30 +8163,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Gerrit",
+						field:  "CloudLogs",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetGerrit()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetCloudLogs()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Gerrit",
+					field:  "CloudLogs",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Jenkins:
+	case *MetaData_Splunk:
 
 		if all {
-			switch v := interface{}(m.GetJenkins()).(type) {
+			switch v := interface{}(m.GetSplunk()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Jenkins",
+						field:  "Splunk",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3693,30 +8194,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Jenkins",
+						field:  "Splunk",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetJenkins()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetSplunk()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Jenkins",
+					field:  "Splunk",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Teams:
+	case *MetaData_Kafka:
 
 		if all {
-			switch v := interface{}(m.GetTeams()).(type) {
+			switch v := interface{}(m.GetKafka()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Teams",
+						field:  "Kafka",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3724,30 +8225,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Teams",
+						field:  "Kafka",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetTeams()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetKafka()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Teams",
+					field:  "Kafka",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Artifactory:
+	case *MetaData_Imap:
 
 		if all {
-			switch v := interface{}(m.GetArtifactory()).(type) {
+			switch v := interface{}(m.GetImap()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Artifactory",
+						field:  "Imap",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3755,30 +8256,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Artifactory",
+						field:  "Imap",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetArtifactory()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetImap()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Artifactory",
+					field:  "Imap",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Syslog:
+	case *MetaData_Helpdesk:
 
 		if all {
-			switch v := interface{}(m.GetSyslog()).(type) {
+			switch v := interface{}(m.GetHelpdesk()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Syslog",
+						field:  "Helpdesk",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3786,30 +8287,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Syslog",
+						field:  "Helpdesk",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetSyslog()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetHelpdesk()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Syslog",
+					field:  "Helpdesk",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_PublicEventMonitoring:
+	case *MetaData_Servicenow:
 
 		if all {
-			switch v := interface{}(m.GetPublicEventMonitoring()).(type) {
+			switch v := interface{}(m.GetServicenow()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "PublicEventMonitoring",
+						field:  "Servicenow",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3817,30 +8318,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "PublicEventMonitoring",
+						field:  "Servicenow",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetPublicEventMonitoring()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetServicenow()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "PublicEventMonitoring",
+					field:  "Servicenow",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_Sharepoint:
+	case *MetaData_Notion:
 
 		if all {
-			switch v := interface{}(m.GetSharepoint()).(type) {
+			switch v := interface{}(m.GetNotion()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Sharepoint",
+						field:  "Notion",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3848,30 +8349,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "Sharepoint",
+						field:  "Notion",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetSharepoint()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetNotion()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "Sharepoint",
+					field:  "Notion",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_GoogleDrive:
+	case *MetaData_Tracker:
 
 		if all {
-			switch v := interface{}(m.GetGoogleDrive()).(type) {
+			switch v := interface{}(m.GetTracker()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "GoogleDrive",
+						field:  "Tracker",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3879,30 +8380,30 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "GoogleDrive",
+						field:  "Tracker",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetGoogleDrive()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetTracker()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "GoogleDrive",
+					field:  "Tracker",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *MetaData_AzureRepos:
+	case *MetaData_Discord:
 
 		if all {
-			switch v := interface{}(m.GetAzureRepos()).(type) {
+			switch v := interface{}(m.GetDiscord()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "AzureRepos",
+						field:  "Discord",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
@@ -3910,16 +8411,16 @@ func (m *MetaData) validate(all bool) error {
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
 					errors = append(errors, MetaDataValidationError{
-						field:  "AzureRepos",
+						field:  "Discord",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetAzureRepos()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetDiscord()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
 				return MetaDataValidationError{
-					field:  "AzureRepos",
+					field:  "Discord",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
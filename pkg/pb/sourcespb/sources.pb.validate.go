@@ -220,6 +220,28 @@ func (m *AzureStorage) validate(all bool) error {
 
 	var errors []error
 
+	// no validation rules for Account
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = AzureStorageValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for ManagedIdentityClientId
+
+	// no validation rules for IncludeSnapshots
+
+	// no validation rules for IncludeVersions
+
+	// no validation rules for MaxObjectSize
+
 	switch m.Credential.(type) {
 
 	case *AzureStorage_ConnectionString:
@@ -290,6 +312,71 @@ func (m *AzureStorage) validate(all bool) error {
 			}
 		}
 
+	case *AzureStorage_SasToken:
+		// no validation rules for SasToken
+
+	case *AzureStorage_ServicePrincipal:
+
+		if all {
+			switch v := interface{}(m.GetServicePrincipal()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, AzureStorageValidationError{
+						field:  "ServicePrincipal",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, AzureStorageValidationError{
+						field:  "ServicePrincipal",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetServicePrincipal()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return AzureStorageValidationError{
+					field:  "ServicePrincipal",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *AzureStorage_ManagedIdentity:
+
+		if all {
+			switch v := interface{}(m.GetManagedIdentity()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, AzureStorageValidationError{
+						field:  "ManagedIdentity",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, AzureStorageValidationError{
+						field:  "ManagedIdentity",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetManagedIdentity()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return AzureStorageValidationError{
+					field:  "ManagedIdentity",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
 	}
 
 	if len(errors) > 0 {
@@ -403,6 +490,10 @@ func (m *Bitbucket) validate(all bool) error {
 		errors = append(errors, err)
 	}
 
+	// no validation rules for SkipPullRequests
+
+	// no validation rules for SkipPipelineVariables
+
 	switch m.Credential.(type) {
 
 	case *Bitbucket_Token:
@@ -876,6 +967,10 @@ func (m *Docker) validate(all bool) error {
 
 	var errors []error
 
+	// no validation rules for AllLocalImages
+
+	// no validation rules for AllRunningContainers
+
 	switch m.Credential.(type) {
 
 	case *Docker_Unauthenticated:
@@ -1282,6 +1377,14 @@ func (m *GCS) validate(all bool) error {
 
 	// no validation rules for MaxObjectSize
 
+	// no validation rules for AllProjects
+
+	// no validation rules for OrganizationId
+
+	// no validation rules for IncludeGenerations
+
+	// no validation rules for StatePath
+
 	switch m.Credential.(type) {
 
 	case *GCS_JsonServiceAccount:
@@ -2085,11 +2188,97 @@ func (m *GoogleDrive) validate(all bool) error {
 
 	var errors []error
 
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = GoogleDriveValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for ImpersonateUser
+
+	// no validation rules for MaxObjectSize
+
+	// no validation rules for StatePath
+
 	switch m.Credential.(type) {
 
 	case *GoogleDrive_RefreshToken:
 		// no validation rules for RefreshToken
 
+	case *GoogleDrive_JsonServiceAccount:
+		// no validation rules for JsonServiceAccount
+
+	case *GoogleDrive_ServiceAccountFile:
+		// no validation rules for ServiceAccountFile
+
+	case *GoogleDrive_Oauth:
+
+		if all {
+			switch v := interface{}(m.GetOauth()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, GoogleDriveValidationError{
+						field:  "Oauth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, GoogleDriveValidationError{
+						field:  "Oauth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetOauth()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return GoogleDriveValidationError{
+					field:  "Oauth",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *GoogleDrive_Adc:
+
+		if all {
+			switch v := interface{}(m.GetAdc()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, GoogleDriveValidationError{
+						field:  "Adc",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, GoogleDriveValidationError{
+						field:  "Adc",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetAdc()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return GoogleDriveValidationError{
+					field:  "Adc",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
 	}
 
 	if len(errors) > 0 {
@@ -2202,6 +2391,39 @@ func (m *JIRA) validate(all bool) error {
 		errors = append(errors, err)
 	}
 
+	// no validation rules for Jql
+
+	if all {
+		switch v := interface{}(m.GetUpdatedSince()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, JIRAValidationError{
+					field:  "UpdatedSince",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, JIRAValidationError{
+					field:  "UpdatedSince",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetUpdatedSince()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return JIRAValidationError{
+				field:  "UpdatedSince",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for SkipAttachments
+
 	switch m.Credential.(type) {
 
 	case *JIRA_BasicAuth:
@@ -2676,6 +2898,44 @@ func (m *S3) validate(all bool) error {
 
 	// no validation rules for MaxObjectSize
 
+	// no validation rules for IncludeVersions
+
+	// no validation rules for RequesterPays
+
+	for idx, item := range m.GetRoleChains() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, S3ValidationError{
+						field:  fmt.Sprintf("RoleChains[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, S3ValidationError{
+						field:  fmt.Sprintf("RoleChains[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return S3ValidationError{
+					field:  fmt.Sprintf("RoleChains[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
 	switch m.Credential.(type) {
 
 	case *S3_AccessKey:
@@ -2881,69 +3141,54 @@ var _ interface {
 	ErrorName() string
 } = S3ValidationError{}
 
-// Validate checks the field values on Slack with the rules defined in the
-// proto definition for this message. If any rules are violated, the first
+// Validate checks the field values on AWSRoleChain with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Slack) Validate() error {
+func (m *AWSRoleChain) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Slack with the rules defined in the
-// proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in SlackMultiError, or nil if none found.
-func (m *Slack) ValidateAll() error {
+// ValidateAll checks the field values on AWSRoleChain with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in AWSRoleChainMultiError, or
+// nil if none found.
+func (m *AWSRoleChain) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Slack) validate(all bool) error {
+func (m *AWSRoleChain) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	if _, err := url.Parse(m.GetEndpoint()); err != nil {
-		err = SlackValidationError{
-			field:  "Endpoint",
-			reason: "value must be a valid URI",
-			cause:  err,
-		}
-		if !all {
-			return err
-		}
-		errors = append(errors, err)
-	}
-
-	switch m.Credential.(type) {
-
-	case *Slack_Token:
-		// no validation rules for Token
-
-	case *Slack_Tokens:
+	for idx, item := range m.GetRoles() {
+		_, _ = idx, item
 
 		if all {
-			switch v := interface{}(m.GetTokens()).(type) {
+			switch v := interface{}(item).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, SlackValidationError{
-						field:  "Tokens",
+					errors = append(errors, AWSRoleChainValidationError{
+						field:  fmt.Sprintf("Roles[%v]", idx),
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
-					errors = append(errors, SlackValidationError{
-						field:  "Tokens",
+					errors = append(errors, AWSRoleChainValidationError{
+						field:  fmt.Sprintf("Roles[%v]", idx),
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetTokens()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
-				return SlackValidationError{
-					field:  "Tokens",
+				return AWSRoleChainValidationError{
+					field:  fmt.Sprintf("Roles[%v]", idx),
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
@@ -2953,18 +3198,18 @@ func (m *Slack) validate(all bool) error {
 	}
 
 	if len(errors) > 0 {
-		return SlackMultiError(errors)
+		return AWSRoleChainMultiError(errors)
 	}
 
 	return nil
 }
 
-// SlackMultiError is an error wrapping multiple validation errors returned by
-// Slack.ValidateAll() if the designated constraints aren't met.
-type SlackMultiError []error
+// AWSRoleChainMultiError is an error wrapping multiple validation errors
+// returned by AWSRoleChain.ValidateAll() if the designated constraints aren't met.
+type AWSRoleChainMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m SlackMultiError) Error() string {
+func (m AWSRoleChainMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -2973,11 +3218,11 @@ func (m SlackMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m SlackMultiError) AllErrors() []error { return m }
+func (m AWSRoleChainMultiError) AllErrors() []error { return m }
 
-// SlackValidationError is the validation error returned by Slack.Validate if
-// the designated constraints aren't met.
-type SlackValidationError struct {
+// AWSRoleChainValidationError is the validation error returned by
+// AWSRoleChain.Validate if the designated constraints aren't met.
+type AWSRoleChainValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -2985,22 +3230,22 @@ type SlackValidationError struct {
 }
 
 // Field function returns field value.
-func (e SlackValidationError) Field() string { return e.field }
+func (e AWSRoleChainValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e SlackValidationError) Reason() string { return e.reason }
+func (e AWSRoleChainValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e SlackValidationError) Cause() error { return e.cause }
+func (e AWSRoleChainValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e SlackValidationError) Key() bool { return e.key }
+func (e AWSRoleChainValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e SlackValidationError) ErrorName() string { return "SlackValidationError" }
+func (e AWSRoleChainValidationError) ErrorName() string { return "AWSRoleChainValidationError" }
 
 // Error satisfies the builtin error interface
-func (e SlackValidationError) Error() string {
+func (e AWSRoleChainValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -3012,14 +3257,14 @@ func (e SlackValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sSlack.%s: %s%s",
+		"invalid %sAWSRoleChain.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = SlackValidationError{}
+var _ error = AWSRoleChainValidationError{}
 
 var _ interface {
 	Field() string
@@ -3027,42 +3272,46 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = SlackValidationError{}
+} = AWSRoleChainValidationError{}
 
-// Validate checks the field values on Test with the rules defined in the proto
-// definition for this message. If any rules are violated, the first error
-// encountered is returned, or nil if there are no violations.
-func (m *Test) Validate() error {
+// Validate checks the field values on AWSRole with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *AWSRole) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Test with the rules defined in the
+// ValidateAll checks the field values on AWSRole with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in TestMultiError, or nil if none found.
-func (m *Test) ValidateAll() error {
+// a list of violation errors wrapped in AWSRoleMultiError, or nil if none found.
+func (m *AWSRole) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Test) validate(all bool) error {
+func (m *AWSRole) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
+	// no validation rules for RoleArn
+
+	// no validation rules for ExternalId
+
 	if len(errors) > 0 {
-		return TestMultiError(errors)
+		return AWSRoleMultiError(errors)
 	}
 
 	return nil
 }
 
-// TestMultiError is an error wrapping multiple validation errors returned by
-// Test.ValidateAll() if the designated constraints aren't met.
-type TestMultiError []error
+// AWSRoleMultiError is an error wrapping multiple validation errors returned
+// by AWSRole.ValidateAll() if the designated constraints aren't met.
+type AWSRoleMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m TestMultiError) Error() string {
+func (m AWSRoleMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -3071,11 +3320,11 @@ func (m TestMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m TestMultiError) AllErrors() []error { return m }
+func (m AWSRoleMultiError) AllErrors() []error { return m }
 
-// TestValidationError is the validation error returned by Test.Validate if the
-// designated constraints aren't met.
-type TestValidationError struct {
+// AWSRoleValidationError is the validation error returned by AWSRole.Validate
+// if the designated constraints aren't met.
+type AWSRoleValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -3083,22 +3332,22 @@ type TestValidationError struct {
 }
 
 // Field function returns field value.
-func (e TestValidationError) Field() string { return e.field }
+func (e AWSRoleValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e TestValidationError) Reason() string { return e.reason }
+func (e AWSRoleValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e TestValidationError) Cause() error { return e.cause }
+func (e AWSRoleValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e TestValidationError) Key() bool { return e.key }
+func (e AWSRoleValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e TestValidationError) ErrorName() string { return "TestValidationError" }
+func (e AWSRoleValidationError) ErrorName() string { return "AWSRoleValidationError" }
 
 // Error satisfies the builtin error interface
-func (e TestValidationError) Error() string {
+func (e AWSRoleValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -3110,14 +3359,14 @@ func (e TestValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sTest.%s: %s%s",
+		"invalid %sAWSRole.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = TestValidationError{}
+var _ error = AWSRoleValidationError{}
 
 var _ interface {
 	Field() string
@@ -3125,50 +3374,154 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = TestValidationError{}
+} = AWSRoleValidationError{}
 
-// Validate checks the field values on Buildkite with the rules defined in the
+// Validate checks the field values on Slack with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Buildkite) Validate() error {
+func (m *Slack) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Buildkite with the rules defined in
-// the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in BuildkiteMultiError, or nil
-// if none found.
-func (m *Buildkite) ValidateAll() error {
+// ValidateAll checks the field values on Slack with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in SlackMultiError, or nil if none found.
+func (m *Slack) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Buildkite) validate(all bool) error {
+func (m *Slack) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = SlackValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for ExportPath
+
+	if all {
+		switch v := interface{}(m.GetSince()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, SlackValidationError{
+					field:  "Since",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, SlackValidationError{
+					field:  "Since",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSince()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return SlackValidationError{
+				field:  "Since",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetUntil()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, SlackValidationError{
+					field:  "Until",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, SlackValidationError{
+					field:  "Until",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetUntil()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return SlackValidationError{
+				field:  "Until",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for SkipFiles
+
 	switch m.Credential.(type) {
 
-	case *Buildkite_Token:
+	case *Slack_Token:
 		// no validation rules for Token
 
+	case *Slack_Tokens:
+
+		if all {
+			switch v := interface{}(m.GetTokens()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, SlackValidationError{
+						field:  "Tokens",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, SlackValidationError{
+						field:  "Tokens",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetTokens()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return SlackValidationError{
+					field:  "Tokens",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
 	}
 
 	if len(errors) > 0 {
-		return BuildkiteMultiError(errors)
+		return SlackMultiError(errors)
 	}
 
 	return nil
 }
 
-// BuildkiteMultiError is an error wrapping multiple validation errors returned
-// by Buildkite.ValidateAll() if the designated constraints aren't met.
-type BuildkiteMultiError []error
+// SlackMultiError is an error wrapping multiple validation errors returned by
+// Slack.ValidateAll() if the designated constraints aren't met.
+type SlackMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m BuildkiteMultiError) Error() string {
+func (m SlackMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -3177,11 +3530,11 @@ func (m BuildkiteMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m BuildkiteMultiError) AllErrors() []error { return m }
+func (m SlackMultiError) AllErrors() []error { return m }
 
-// BuildkiteValidationError is the validation error returned by
-// Buildkite.Validate if the designated constraints aren't met.
-type BuildkiteValidationError struct {
+// SlackValidationError is the validation error returned by Slack.Validate if
+// the designated constraints aren't met.
+type SlackValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -3189,22 +3542,4503 @@ type BuildkiteValidationError struct {
 }
 
 // Field function returns field value.
-func (e BuildkiteValidationError) Field() string { return e.field }
+func (e SlackValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e BuildkiteValidationError) Reason() string { return e.reason }
+func (e SlackValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e BuildkiteValidationError) Cause() error { return e.cause }
+func (e SlackValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e BuildkiteValidationError) Key() bool { return e.key }
+func (e SlackValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e BuildkiteValidationError) ErrorName() string { return "BuildkiteValidationError" }
+func (e SlackValidationError) ErrorName() string { return "SlackValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SlackValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSlack.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SlackValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SlackValidationError{}
+
+// Validate checks the field values on Test with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *Test) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Test with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in TestMultiError, or nil if none found.
+func (m *Test) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Test) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if len(errors) > 0 {
+		return TestMultiError(errors)
+	}
+
+	return nil
+}
+
+// TestMultiError is an error wrapping multiple validation errors returned by
+// Test.ValidateAll() if the designated constraints aren't met.
+type TestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m TestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m TestMultiError) AllErrors() []error { return m }
+
+// TestValidationError is the validation error returned by Test.Validate if the
+// designated constraints aren't met.
+type TestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e TestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e TestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e TestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e TestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e TestValidationError) ErrorName() string { return "TestValidationError" }
+
+// Error satisfies the builtin error interface
+func (e TestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sTest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = TestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = TestValidationError{}
+
+// Validate checks the field values on Buildkite with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Buildkite) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Buildkite with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in BuildkiteMultiError, or nil
+// if none found.
+func (m *Buildkite) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Buildkite) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	switch m.Credential.(type) {
+
+	case *Buildkite_Token:
+		// no validation rules for Token
+
+	}
+
+	if len(errors) > 0 {
+		return BuildkiteMultiError(errors)
+	}
+
+	return nil
+}
+
+// BuildkiteMultiError is an error wrapping multiple validation errors returned
+// by Buildkite.ValidateAll() if the designated constraints aren't met.
+type BuildkiteMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m BuildkiteMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m BuildkiteMultiError) AllErrors() []error { return m }
+
+// BuildkiteValidationError is the validation error returned by
+// Buildkite.Validate if the designated constraints aren't met.
+type BuildkiteValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e BuildkiteValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e BuildkiteValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e BuildkiteValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e BuildkiteValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e BuildkiteValidationError) ErrorName() string { return "BuildkiteValidationError" }
+
+// Error satisfies the builtin error interface
+func (e BuildkiteValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sBuildkite.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = BuildkiteValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = BuildkiteValidationError{}
+
+// Validate checks the field values on Gerrit with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Gerrit) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Gerrit with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in GerritMultiError, or nil if none found.
+func (m *Gerrit) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Gerrit) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = GerritValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for Query
+
+	switch m.Credential.(type) {
+
+	case *Gerrit_BasicAuth:
+
+		if all {
+			switch v := interface{}(m.GetBasicAuth()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, GerritValidationError{
+						field:  "BasicAuth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, GerritValidationError{
+						field:  "BasicAuth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetBasicAuth()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return GerritValidationError{
+					field:  "BasicAuth",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *Gerrit_Unauthenticated:
+
+		if all {
+			switch v := interface{}(m.GetUnauthenticated()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, GerritValidationError{
+						field:  "Unauthenticated",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, GerritValidationError{
+						field:  "Unauthenticated",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetUnauthenticated()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return GerritValidationError{
+					field:  "Unauthenticated",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return GerritMultiError(errors)
+	}
+
+	return nil
+}
+
+// GerritMultiError is an error wrapping multiple validation errors returned by
+// Gerrit.ValidateAll() if the designated constraints aren't met.
+type GerritMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GerritMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GerritMultiError) AllErrors() []error { return m }
+
+// GerritValidationError is the validation error returned by Gerrit.Validate if
+// the designated constraints aren't met.
+type GerritValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GerritValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GerritValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GerritValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GerritValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GerritValidationError) ErrorName() string { return "GerritValidationError" }
+
+// Error satisfies the builtin error interface
+func (e GerritValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGerrit.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GerritValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GerritValidationError{}
+
+// Validate checks the field values on Jenkins with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Jenkins) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Jenkins with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in JenkinsMultiError, or nil if none found.
+func (m *Jenkins) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Jenkins) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = JenkinsValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	switch m.Credential.(type) {
+
+	case *Jenkins_BasicAuth:
+
+		if all {
+			switch v := interface{}(m.GetBasicAuth()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, JenkinsValidationError{
+						field:  "BasicAuth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, JenkinsValidationError{
+						field:  "BasicAuth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetBasicAuth()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return JenkinsValidationError{
+					field:  "BasicAuth",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *Jenkins_Header:
+
+		if all {
+			switch v := interface{}(m.GetHeader()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, JenkinsValidationError{
+						field:  "Header",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, JenkinsValidationError{
+						field:  "Header",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetHeader()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return JenkinsValidationError{
+					field:  "Header",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return JenkinsMultiError(errors)
+	}
+
+	return nil
+}
+
+// JenkinsMultiError is an error wrapping multiple validation errors returned
+// by Jenkins.ValidateAll() if the designated constraints aren't met.
+type JenkinsMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m JenkinsMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m JenkinsMultiError) AllErrors() []error { return m }
+
+// JenkinsValidationError is the validation error returned by Jenkins.Validate
+// if the designated constraints aren't met.
+type JenkinsValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e JenkinsValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e JenkinsValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e JenkinsValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e JenkinsValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e JenkinsValidationError) ErrorName() string { return "JenkinsValidationError" }
+
+// Error satisfies the builtin error interface
+func (e JenkinsValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sJenkins.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = JenkinsValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = JenkinsValidationError{}
+
+// Validate checks the field values on Teams with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Teams) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Teams with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in TeamsMultiError, or nil if none found.
+func (m *Teams) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Teams) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = TeamsValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for SkipChats
+
+	// no validation rules for SkipFiles
+
+	// no validation rules for StatePath
+
+	switch m.Credential.(type) {
+
+	case *Teams_Token:
+		// no validation rules for Token
+
+	case *Teams_Authenticated:
+
+		if all {
+			switch v := interface{}(m.GetAuthenticated()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, TeamsValidationError{
+						field:  "Authenticated",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, TeamsValidationError{
+						field:  "Authenticated",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetAuthenticated()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return TeamsValidationError{
+					field:  "Authenticated",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *Teams_Oauth:
+
+		if all {
+			switch v := interface{}(m.GetOauth()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, TeamsValidationError{
+						field:  "Oauth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, TeamsValidationError{
+						field:  "Oauth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetOauth()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return TeamsValidationError{
+					field:  "Oauth",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return TeamsMultiError(errors)
+	}
+
+	return nil
+}
+
+// TeamsMultiError is an error wrapping multiple validation errors returned by
+// Teams.ValidateAll() if the designated constraints aren't met.
+type TeamsMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m TeamsMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m TeamsMultiError) AllErrors() []error { return m }
+
+// TeamsValidationError is the validation error returned by Teams.Validate if
+// the designated constraints aren't met.
+type TeamsValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e TeamsValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e TeamsValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e TeamsValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e TeamsValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e TeamsValidationError) ErrorName() string { return "TeamsValidationError" }
+
+// Error satisfies the builtin error interface
+func (e TeamsValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sTeams.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = TeamsValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = TeamsValidationError{}
+
+// Validate checks the field values on Artifactory with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Artifactory) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Artifactory with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in ArtifactoryMultiError, or
+// nil if none found.
+func (m *Artifactory) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Artifactory) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = ArtifactoryValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for MaxObjectSize
+
+	// no validation rules for StatePath
+
+	// no validation rules for Insecure
+
+	switch m.Credential.(type) {
+
+	case *Artifactory_BasicAuth:
+
+		if all {
+			switch v := interface{}(m.GetBasicAuth()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ArtifactoryValidationError{
+						field:  "BasicAuth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ArtifactoryValidationError{
+						field:  "BasicAuth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetBasicAuth()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ArtifactoryValidationError{
+					field:  "BasicAuth",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *Artifactory_AccessToken:
+		// no validation rules for AccessToken
+
+	}
+
+	if len(errors) > 0 {
+		return ArtifactoryMultiError(errors)
+	}
+
+	return nil
+}
+
+// ArtifactoryMultiError is an error wrapping multiple validation errors
+// returned by Artifactory.ValidateAll() if the designated constraints aren't met.
+type ArtifactoryMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ArtifactoryMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ArtifactoryMultiError) AllErrors() []error { return m }
+
+// ArtifactoryValidationError is the validation error returned by
+// Artifactory.Validate if the designated constraints aren't met.
+type ArtifactoryValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ArtifactoryValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ArtifactoryValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ArtifactoryValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ArtifactoryValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ArtifactoryValidationError) ErrorName() string { return "ArtifactoryValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ArtifactoryValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sArtifactory.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ArtifactoryValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ArtifactoryValidationError{}
+
+// Validate checks the field values on Syslog with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Syslog) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Syslog with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in SyslogMultiError, or nil if none found.
+func (m *Syslog) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Syslog) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Protocol
+
+	// no validation rules for ListenAddress
+
+	// no validation rules for TlsCert
+
+	// no validation rules for TlsKey
+
+	// no validation rules for Format
+
+	if len(errors) > 0 {
+		return SyslogMultiError(errors)
+	}
+
+	return nil
+}
+
+// SyslogMultiError is an error wrapping multiple validation errors returned by
+// Syslog.ValidateAll() if the designated constraints aren't met.
+type SyslogMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SyslogMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SyslogMultiError) AllErrors() []error { return m }
+
+// SyslogValidationError is the validation error returned by Syslog.Validate if
+// the designated constraints aren't met.
+type SyslogValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SyslogValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SyslogValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SyslogValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SyslogValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SyslogValidationError) ErrorName() string { return "SyslogValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SyslogValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSyslog.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SyslogValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SyslogValidationError{}
+
+// Validate checks the field values on PublicEventMonitoring with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *PublicEventMonitoring) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on PublicEventMonitoring with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// PublicEventMonitoringMultiError, or nil if none found.
+func (m *PublicEventMonitoring) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *PublicEventMonitoring) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Domain
+
+	// no validation rules for MaxDepth
+
+	if all {
+		switch v := interface{}(m.GetSince()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, PublicEventMonitoringValidationError{
+					field:  "Since",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, PublicEventMonitoringValidationError{
+					field:  "Since",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSince()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return PublicEventMonitoringValidationError{
+				field:  "Since",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	switch m.Credential.(type) {
+
+	case *PublicEventMonitoring_Unauthenticated:
+
+		if all {
+			switch v := interface{}(m.GetUnauthenticated()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, PublicEventMonitoringValidationError{
+						field:  "Unauthenticated",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, PublicEventMonitoringValidationError{
+						field:  "Unauthenticated",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetUnauthenticated()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return PublicEventMonitoringValidationError{
+					field:  "Unauthenticated",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return PublicEventMonitoringMultiError(errors)
+	}
+
+	return nil
+}
+
+// PublicEventMonitoringMultiError is an error wrapping multiple validation
+// errors returned by PublicEventMonitoring.ValidateAll() if the designated
+// constraints aren't met.
+type PublicEventMonitoringMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m PublicEventMonitoringMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m PublicEventMonitoringMultiError) AllErrors() []error { return m }
+
+// PublicEventMonitoringValidationError is the validation error returned by
+// PublicEventMonitoring.Validate if the designated constraints aren't met.
+type PublicEventMonitoringValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e PublicEventMonitoringValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e PublicEventMonitoringValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e PublicEventMonitoringValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e PublicEventMonitoringValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e PublicEventMonitoringValidationError) ErrorName() string {
+	return "PublicEventMonitoringValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e PublicEventMonitoringValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sPublicEventMonitoring.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = PublicEventMonitoringValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = PublicEventMonitoringValidationError{}
+
+// Validate checks the field values on SlackRealtime with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *SlackRealtime) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SlackRealtime with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in SlackRealtimeMultiError, or
+// nil if none found.
+func (m *SlackRealtime) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SlackRealtime) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	switch m.Credential.(type) {
+
+	case *SlackRealtime_Tokens:
+
+		if all {
+			switch v := interface{}(m.GetTokens()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, SlackRealtimeValidationError{
+						field:  "Tokens",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, SlackRealtimeValidationError{
+						field:  "Tokens",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetTokens()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return SlackRealtimeValidationError{
+					field:  "Tokens",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return SlackRealtimeMultiError(errors)
+	}
+
+	return nil
+}
+
+// SlackRealtimeMultiError is an error wrapping multiple validation errors
+// returned by SlackRealtime.ValidateAll() if the designated constraints
+// aren't met.
+type SlackRealtimeMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SlackRealtimeMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SlackRealtimeMultiError) AllErrors() []error { return m }
+
+// SlackRealtimeValidationError is the validation error returned by
+// SlackRealtime.Validate if the designated constraints aren't met.
+type SlackRealtimeValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SlackRealtimeValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SlackRealtimeValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SlackRealtimeValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SlackRealtimeValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SlackRealtimeValidationError) ErrorName() string { return "SlackRealtimeValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SlackRealtimeValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSlackRealtime.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SlackRealtimeValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SlackRealtimeValidationError{}
+
+// Validate checks the field values on Sharepoint with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Sharepoint) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Sharepoint with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in SharepointMultiError, or
+// nil if none found.
+func (m *Sharepoint) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Sharepoint) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for SiteUrl
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = SharepointValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for SkipOnedrive
+
+	// no validation rules for SkipLists
+
+	// no validation rules for MaxObjectSize
+
+	// no validation rules for StatePath
+
+	switch m.Credential.(type) {
+
+	case *Sharepoint_Oauth:
+
+		if all {
+			switch v := interface{}(m.GetOauth()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, SharepointValidationError{
+						field:  "Oauth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, SharepointValidationError{
+						field:  "Oauth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetOauth()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return SharepointValidationError{
+					field:  "Oauth",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *Sharepoint_Token:
+		// no validation rules for Token
+
+	case *Sharepoint_Authenticated:
+
+		if all {
+			switch v := interface{}(m.GetAuthenticated()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, SharepointValidationError{
+						field:  "Authenticated",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, SharepointValidationError{
+						field:  "Authenticated",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetAuthenticated()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return SharepointValidationError{
+					field:  "Authenticated",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return SharepointMultiError(errors)
+	}
+
+	return nil
+}
+
+// SharepointMultiError is an error wrapping multiple validation errors
+// returned by Sharepoint.ValidateAll() if the designated constraints aren't met.
+type SharepointMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SharepointMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SharepointMultiError) AllErrors() []error { return m }
+
+// SharepointValidationError is the validation error returned by
+// Sharepoint.Validate if the designated constraints aren't met.
+type SharepointValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SharepointValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SharepointValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SharepointValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SharepointValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SharepointValidationError) ErrorName() string { return "SharepointValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SharepointValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSharepoint.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SharepointValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SharepointValidationError{}
+
+// Validate checks the field values on AzureRepos with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *AzureRepos) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on AzureRepos with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in AzureReposMultiError, or
+// nil if none found.
+func (m *AzureRepos) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *AzureRepos) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = AzureReposValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for IncludeForks
+
+	// no validation rules for SkipPipelines
+
+	// no validation rules for SkipVariableGroups
+
+	// no validation rules for SkipWikis
+
+	// no validation rules for SkipWorkItems
+
+	switch m.Credential.(type) {
+
+	case *AzureRepos_Token:
+		// no validation rules for Token
+
+	case *AzureRepos_Oauth:
+
+		if all {
+			switch v := interface{}(m.GetOauth()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, AzureReposValidationError{
+						field:  "Oauth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, AzureReposValidationError{
+						field:  "Oauth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetOauth()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return AzureReposValidationError{
+					field:  "Oauth",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return AzureReposMultiError(errors)
+	}
+
+	return nil
+}
+
+// AzureReposMultiError is an error wrapping multiple validation errors
+// returned by AzureRepos.ValidateAll() if the designated constraints aren't met.
+type AzureReposMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m AzureReposMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m AzureReposMultiError) AllErrors() []error { return m }
+
+// AzureReposValidationError is the validation error returned by
+// AzureRepos.Validate if the designated constraints aren't met.
+type AzureReposValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e AzureReposValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e AzureReposValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e AzureReposValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e AzureReposValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e AzureReposValidationError) ErrorName() string { return "AzureReposValidationError" }
+
+// Error satisfies the builtin error interface
+func (e AzureReposValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sAzureRepos.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = AzureReposValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = AzureReposValidationError{}
+
+// Validate checks the field values on Process with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Process) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Process with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in ProcessMultiError, or nil if none found.
+func (m *Process) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Process) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for SkipCommandLine
+
+	if len(errors) > 0 {
+		return ProcessMultiError(errors)
+	}
+
+	return nil
+}
+
+// ProcessMultiError is an error wrapping multiple validation errors returned
+// by Process.ValidateAll() if the designated constraints aren't met.
+type ProcessMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ProcessMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ProcessMultiError) AllErrors() []error { return m }
+
+// ProcessValidationError is the validation error returned by Process.Validate
+// if the designated constraints aren't met.
+type ProcessValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ProcessValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ProcessValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ProcessValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ProcessValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ProcessValidationError) ErrorName() string { return "ProcessValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ProcessValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sProcess.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ProcessValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ProcessValidationError{}
+
+// Validate checks the field values on Dropbox with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Dropbox) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Dropbox with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in DropboxMultiError, or nil if none found.
+func (m *Dropbox) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Dropbox) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = DropboxValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if _, err := url.Parse(m.GetContentEndpoint()); err != nil {
+		err = DropboxValidationError{
+			field:  "ContentEndpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for SkipTeamFolders
+
+	// no validation rules for SkipMemberFolders
+
+	// no validation rules for MaxObjectSize
+
+	// no validation rules for StatePath
+
+	switch m.Credential.(type) {
+
+	case *Dropbox_Token:
+		// no validation rules for Token
+
+	case *Dropbox_Oauth:
+
+		if all {
+			switch v := interface{}(m.GetOauth()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, DropboxValidationError{
+						field:  "Oauth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, DropboxValidationError{
+						field:  "Oauth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetOauth()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return DropboxValidationError{
+					field:  "Oauth",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return DropboxMultiError(errors)
+	}
+
+	return nil
+}
+
+// DropboxMultiError is an error wrapping multiple validation errors returned
+// by Dropbox.ValidateAll() if the designated constraints aren't met.
+type DropboxMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DropboxMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DropboxMultiError) AllErrors() []error { return m }
+
+// DropboxValidationError is the validation error returned by Dropbox.Validate
+// if the designated constraints aren't met.
+type DropboxValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DropboxValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DropboxValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DropboxValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DropboxValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DropboxValidationError) ErrorName() string { return "DropboxValidationError" }
+
+// Error satisfies the builtin error interface
+func (e DropboxValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDropbox.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DropboxValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DropboxValidationError{}
+
+// Validate checks the field values on FTP with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *FTP) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on FTP with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in FTPMultiError, or nil if none found.
+func (m *FTP) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *FTP) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetUrl()); err != nil {
+		err = FTPValidationError{
+			field:  "Url",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for Username
+
+	// no validation rules for Password
+
+	// no validation rules for PrivateKey
+
+	// no validation rules for PrivateKeyPassphrase
+
+	// no validation rules for KnownHosts
+
+	// no validation rules for Insecure
+
+	// no validation rules for MaxConnections
+
+	// no validation rules for MaxObjectSize
+
+	if len(errors) > 0 {
+		return FTPMultiError(errors)
+	}
+
+	return nil
+}
+
+// FTPMultiError is an error wrapping multiple validation errors returned by
+// FTP.ValidateAll() if the designated constraints aren't met.
+type FTPMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m FTPMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m FTPMultiError) AllErrors() []error { return m }
+
+// FTPValidationError is the validation error returned by FTP.Validate if the
+// designated constraints aren't met.
+type FTPValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e FTPValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e FTPValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e FTPValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e FTPValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e FTPValidationError) ErrorName() string { return "FTPValidationError" }
+
+// Error satisfies the builtin error interface
+func (e FTPValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sFTP.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = FTPValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = FTPValidationError{}
+
+// Validate checks the field values on SMB with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *SMB) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SMB with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in SMBMultiError, or nil if none found.
+func (m *SMB) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SMB) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Host
+
+	// no validation rules for Username
+
+	// no validation rules for Password
+
+	// no validation rules for NtlmHash
+
+	// no validation rules for Domain
+
+	// no validation rules for MaxObjectSize
+
+	if len(errors) > 0 {
+		return SMBMultiError(errors)
+	}
+
+	return nil
+}
+
+// SMBMultiError is an error wrapping multiple validation errors returned by
+// SMB.ValidateAll() if the designated constraints aren't met.
+type SMBMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SMBMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SMBMultiError) AllErrors() []error { return m }
+
+// SMBValidationError is the validation error returned by SMB.Validate if the
+// designated constraints aren't met.
+type SMBValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SMBValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SMBValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SMBValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SMBValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SMBValidationError) ErrorName() string { return "SMBValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SMBValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSMB.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SMBValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SMBValidationError{}
+
+// Validate checks the field values on Kubernetes with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Kubernetes) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Kubernetes with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in KubernetesMultiError, or
+// nil if none found.
+func (m *Kubernetes) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Kubernetes) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Kubeconfig
+
+	// no validation rules for Context
+
+	// no validation rules for InCluster
+
+	// no validation rules for SkipSecrets
+
+	// no validation rules for SkipConfigMaps
+
+	// no validation rules for SkipPods
+
+	// no validation rules for SkipCustomResources
+
+	if len(errors) > 0 {
+		return KubernetesMultiError(errors)
+	}
+
+	return nil
+}
+
+// KubernetesMultiError is an error wrapping multiple validation errors
+// returned by Kubernetes.ValidateAll() if the designated constraints aren't met.
+type KubernetesMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m KubernetesMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m KubernetesMultiError) AllErrors() []error { return m }
+
+// KubernetesValidationError is the validation error returned by
+// Kubernetes.Validate if the designated constraints aren't met.
+type KubernetesValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e KubernetesValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e KubernetesValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e KubernetesValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e KubernetesValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e KubernetesValidationError) ErrorName() string { return "KubernetesValidationError" }
+
+// Error satisfies the builtin error interface
+func (e KubernetesValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sKubernetes.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = KubernetesValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = KubernetesValidationError{}
+
+// Validate checks the field values on Consul with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Consul) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Consul with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in ConsulMultiError, or nil if none found.
+func (m *Consul) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Consul) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetAddress()); err != nil {
+		err = ConsulValidationError{
+			field:  "Address",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for Token
+
+	// no validation rules for Datacenter
+
+	// no validation rules for Prefix
+
+	// no validation rules for CaCertificate
+
+	// no validation rules for Insecure
+
+	if len(errors) > 0 {
+		return ConsulMultiError(errors)
+	}
+
+	return nil
+}
+
+// ConsulMultiError is an error wrapping multiple validation errors returned by
+// Consul.ValidateAll() if the designated constraints aren't met.
+type ConsulMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ConsulMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ConsulMultiError) AllErrors() []error { return m }
+
+// ConsulValidationError is the validation error returned by Consul.Validate if
+// the designated constraints aren't met.
+type ConsulValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ConsulValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ConsulValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ConsulValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ConsulValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ConsulValidationError) ErrorName() string { return "ConsulValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ConsulValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sConsul.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ConsulValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ConsulValidationError{}
+
+// Validate checks the field values on Etcd with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *Etcd) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Etcd with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in EtcdMultiError, or nil if none found.
+func (m *Etcd) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Etcd) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = EtcdValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for Username
+
+	// no validation rules for Password
+
+	// no validation rules for Prefix
+
+	// no validation rules for ClientCertificate
+
+	// no validation rules for ClientKey
+
+	// no validation rules for CaCertificate
+
+	// no validation rules for Insecure
+
+	if len(errors) > 0 {
+		return EtcdMultiError(errors)
+	}
+
+	return nil
+}
+
+// EtcdMultiError is an error wrapping multiple validation errors returned by
+// Etcd.ValidateAll() if the designated constraints aren't met.
+type EtcdMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m EtcdMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m EtcdMultiError) AllErrors() []error { return m }
+
+// EtcdValidationError is the validation error returned by Etcd.Validate if the
+// designated constraints aren't met.
+type EtcdValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e EtcdValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e EtcdValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e EtcdValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e EtcdValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e EtcdValidationError) ErrorName() string { return "EtcdValidationError" }
+
+// Error satisfies the builtin error interface
+func (e EtcdValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sEtcd.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = EtcdValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = EtcdValidationError{}
+
+// Validate checks the field values on Elasticsearch with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Elasticsearch) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Elasticsearch with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in ElasticsearchMultiError, or
+// nil if none found.
+func (m *Elasticsearch) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Elasticsearch) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetUrl()); err != nil {
+		err = ElasticsearchValidationError{
+			field:  "Url",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for Username
+
+	// no validation rules for Password
+
+	// no validation rules for ApiKey
+
+	// no validation rules for TimestampField
+
+	// no validation rules for Since
+
+	// no validation rules for Until
+
+	// no validation rules for CaCertificate
+
+	// no validation rules for Insecure
+
+	if len(errors) > 0 {
+		return ElasticsearchMultiError(errors)
+	}
+
+	return nil
+}
+
+// ElasticsearchMultiError is an error wrapping multiple validation errors
+// returned by Elasticsearch.ValidateAll() if the designated constraints
+// aren't met.
+type ElasticsearchMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ElasticsearchMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ElasticsearchMultiError) AllErrors() []error { return m }
+
+// ElasticsearchValidationError is the validation error returned by
+// Elasticsearch.Validate if the designated constraints aren't met.
+type ElasticsearchValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ElasticsearchValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ElasticsearchValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ElasticsearchValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ElasticsearchValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ElasticsearchValidationError) ErrorName() string { return "ElasticsearchValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ElasticsearchValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sElasticsearch.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ElasticsearchValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ElasticsearchValidationError{}
+
+// Validate checks the field values on Database with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Database) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Database with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in DatabaseMultiError, or nil
+// if none found.
+func (m *Database) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Database) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Dsn
+
+	// no validation rules for BatchSize
+
+	if len(errors) > 0 {
+		return DatabaseMultiError(errors)
+	}
+
+	return nil
+}
+
+// DatabaseMultiError is an error wrapping multiple validation errors returned
+// by Database.ValidateAll() if the designated constraints aren't met.
+type DatabaseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DatabaseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DatabaseMultiError) AllErrors() []error { return m }
+
+// DatabaseValidationError is the validation error returned by
+// Database.Validate if the designated constraints aren't met.
+type DatabaseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DatabaseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DatabaseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DatabaseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DatabaseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DatabaseValidationError) ErrorName() string { return "DatabaseValidationError" }
+
+// Error satisfies the builtin error interface
+func (e DatabaseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDatabase.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DatabaseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DatabaseValidationError{}
+
+// Validate checks the field values on MongoDB with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *MongoDB) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on MongoDB with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in MongoDBMultiError, or nil if none found.
+func (m *MongoDB) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *MongoDB) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Uri
+
+	// no validation rules for BatchSize
+
+	if len(errors) > 0 {
+		return MongoDBMultiError(errors)
+	}
+
+	return nil
+}
+
+// MongoDBMultiError is an error wrapping multiple validation errors returned
+// by MongoDB.ValidateAll() if the designated constraints aren't met.
+type MongoDBMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m MongoDBMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m MongoDBMultiError) AllErrors() []error { return m }
+
+// MongoDBValidationError is the validation error returned by MongoDB.Validate
+// if the designated constraints aren't met.
+type MongoDBValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e MongoDBValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e MongoDBValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e MongoDBValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e MongoDBValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e MongoDBValidationError) ErrorName() string { return "MongoDBValidationError" }
+
+// Error satisfies the builtin error interface
+func (e MongoDBValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sMongoDB.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = MongoDBValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = MongoDBValidationError{}
+
+// Validate checks the field values on Redis with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Redis) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Redis with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in RedisMultiError, or nil if none found.
+func (m *Redis) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Redis) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Uri
+
+	// no validation rules for Insecure
+
+	if len(errors) > 0 {
+		return RedisMultiError(errors)
+	}
+
+	return nil
+}
+
+// RedisMultiError is an error wrapping multiple validation errors returned by
+// Redis.ValidateAll() if the designated constraints aren't met.
+type RedisMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m RedisMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m RedisMultiError) AllErrors() []error { return m }
+
+// RedisValidationError is the validation error returned by Redis.Validate if
+// the designated constraints aren't met.
+type RedisValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e RedisValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e RedisValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e RedisValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e RedisValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e RedisValidationError) ErrorName() string { return "RedisValidationError" }
+
+// Error satisfies the builtin error interface
+func (e RedisValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sRedis.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = RedisValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = RedisValidationError{}
+
+// Validate checks the field values on Nexus with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Nexus) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Nexus with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in NexusMultiError, or nil if none found.
+func (m *Nexus) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Nexus) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Endpoint
+
+	if all {
+		switch v := interface{}(m.GetBasicAuth()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, NexusValidationError{
+					field:  "BasicAuth",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, NexusValidationError{
+					field:  "BasicAuth",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetBasicAuth()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return NexusValidationError{
+				field:  "BasicAuth",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for MaxObjectSize
+
+	// no validation rules for StatePath
+
+	// no validation rules for Insecure
+
+	if len(errors) > 0 {
+		return NexusMultiError(errors)
+	}
+
+	return nil
+}
+
+// NexusMultiError is an error wrapping multiple validation errors returned by
+// Nexus.ValidateAll() if the designated constraints aren't met.
+type NexusMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m NexusMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m NexusMultiError) AllErrors() []error { return m }
+
+// NexusValidationError is the validation error returned by Nexus.Validate if
+// the designated constraints aren't met.
+type NexusValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e NexusValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e NexusValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e NexusValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e NexusValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e NexusValidationError) ErrorName() string { return "NexusValidationError" }
+
+// Error satisfies the builtin error interface
+func (e NexusValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sNexus.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = NexusValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = NexusValidationError{}
+
+// Validate checks the field values on Registry with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Registry) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Registry with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in RegistryMultiError, or nil
+// if none found.
+func (m *Registry) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Registry) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Registry
+
+	// no validation rules for MaxObjectSize
+
+	// no validation rules for StatePath
+
+	// no validation rules for Insecure
+
+	switch m.Credential.(type) {
+
+	case *Registry_Unauthenticated:
+
+		if all {
+			switch v := interface{}(m.GetUnauthenticated()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, RegistryValidationError{
+						field:  "Unauthenticated",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, RegistryValidationError{
+						field:  "Unauthenticated",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetUnauthenticated()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return RegistryValidationError{
+					field:  "Unauthenticated",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *Registry_BasicAuth:
+
+		if all {
+			switch v := interface{}(m.GetBasicAuth()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, RegistryValidationError{
+						field:  "BasicAuth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, RegistryValidationError{
+						field:  "BasicAuth",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetBasicAuth()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return RegistryValidationError{
+					field:  "BasicAuth",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *Registry_BearerToken:
+		// no validation rules for BearerToken
+
+	case *Registry_DockerKeychain:
+		// no validation rules for DockerKeychain
+
+	case *Registry_CloudCredentials:
+		// no validation rules for CloudCredentials
+
+	}
+
+	if len(errors) > 0 {
+		return RegistryMultiError(errors)
+	}
+
+	return nil
+}
+
+// RegistryMultiError is an error wrapping multiple validation errors returned
+// by Registry.ValidateAll() if the designated constraints aren't met.
+type RegistryMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m RegistryMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m RegistryMultiError) AllErrors() []error { return m }
+
+// RegistryValidationError is the validation error returned by
+// Registry.Validate if the designated constraints aren't met.
+type RegistryValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e RegistryValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e RegistryValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e RegistryValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e RegistryValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e RegistryValidationError) ErrorName() string { return "RegistryValidationError" }
+
+// Error satisfies the builtin error interface
+func (e RegistryValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sRegistry.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = RegistryValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = RegistryValidationError{}
+
+// Validate checks the field values on CloudLogs with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *CloudLogs) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CloudLogs with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in CloudLogsMultiError, or nil
+// if none found.
+func (m *CloudLogs) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CloudLogs) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetSince()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CloudLogsValidationError{
+					field:  "Since",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CloudLogsValidationError{
+					field:  "Since",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSince()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CloudLogsValidationError{
+				field:  "Since",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetUntil()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CloudLogsValidationError{
+					field:  "Until",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CloudLogsValidationError{
+					field:  "Until",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetUntil()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CloudLogsValidationError{
+				field:  "Until",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Filter
+
+	// no validation rules for BatchSize
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = CloudLogsValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	switch m.Platform.(type) {
+
+	case *CloudLogs_Cloudwatch:
+
+		if all {
+			switch v := interface{}(m.GetCloudwatch()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, CloudLogsValidationError{
+						field:  "Cloudwatch",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, CloudLogsValidationError{
+						field:  "Cloudwatch",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetCloudwatch()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return CloudLogsValidationError{
+					field:  "Cloudwatch",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *CloudLogs_CloudLogging:
+
+		if all {
+			switch v := interface{}(m.GetCloudLogging()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, CloudLogsValidationError{
+						field:  "CloudLogging",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, CloudLogsValidationError{
+						field:  "CloudLogging",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetCloudLogging()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return CloudLogsValidationError{
+					field:  "CloudLogging",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *CloudLogs_AzureMonitor:
+
+		if all {
+			switch v := interface{}(m.GetAzureMonitor()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, CloudLogsValidationError{
+						field:  "AzureMonitor",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, CloudLogsValidationError{
+						field:  "AzureMonitor",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetAzureMonitor()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return CloudLogsValidationError{
+					field:  "AzureMonitor",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return CloudLogsMultiError(errors)
+	}
+
+	return nil
+}
+
+// CloudLogsMultiError is an error wrapping multiple validation errors returned
+// by CloudLogs.ValidateAll() if the designated constraints aren't met.
+type CloudLogsMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CloudLogsMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CloudLogsMultiError) AllErrors() []error { return m }
+
+// CloudLogsValidationError is the validation error returned by
+// CloudLogs.Validate if the designated constraints aren't met.
+type CloudLogsValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CloudLogsValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CloudLogsValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CloudLogsValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CloudLogsValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CloudLogsValidationError) ErrorName() string { return "CloudLogsValidationError" }
+
+// Error satisfies the builtin error interface
+func (e CloudLogsValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCloudLogs.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CloudLogsValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CloudLogsValidationError{}
+
+// Validate checks the field values on CloudWatchLogs with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *CloudWatchLogs) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CloudWatchLogs with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in CloudWatchLogsMultiError,
+// or nil if none found.
+func (m *CloudWatchLogs) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CloudWatchLogs) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Region
+
+	switch m.Credential.(type) {
+
+	case *CloudWatchLogs_AccessKey:
+
+		if all {
+			switch v := interface{}(m.GetAccessKey()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, CloudWatchLogsValidationError{
+						field:  "AccessKey",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, CloudWatchLogsValidationError{
+						field:  "AccessKey",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetAccessKey()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return CloudWatchLogsValidationError{
+					field:  "AccessKey",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *CloudWatchLogs_SessionToken:
+
+		if all {
+			switch v := interface{}(m.GetSessionToken()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, CloudWatchLogsValidationError{
+						field:  "SessionToken",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, CloudWatchLogsValidationError{
+						field:  "SessionToken",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetSessionToken()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return CloudWatchLogsValidationError{
+					field:  "SessionToken",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *CloudWatchLogs_CloudEnvironment:
+
+		if all {
+			switch v := interface{}(m.GetCloudEnvironment()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, CloudWatchLogsValidationError{
+						field:  "CloudEnvironment",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, CloudWatchLogsValidationError{
+						field:  "CloudEnvironment",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetCloudEnvironment()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return CloudWatchLogsValidationError{
+					field:  "CloudEnvironment",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return CloudWatchLogsMultiError(errors)
+	}
+
+	return nil
+}
+
+// CloudWatchLogsMultiError is an error wrapping multiple validation errors
+// returned by CloudWatchLogs.ValidateAll() if the designated constraints
+// aren't met.
+type CloudWatchLogsMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CloudWatchLogsMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CloudWatchLogsMultiError) AllErrors() []error { return m }
+
+// CloudWatchLogsValidationError is the validation error returned by
+// CloudWatchLogs.Validate if the designated constraints aren't met.
+type CloudWatchLogsValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CloudWatchLogsValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CloudWatchLogsValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CloudWatchLogsValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CloudWatchLogsValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CloudWatchLogsValidationError) ErrorName() string { return "CloudWatchLogsValidationError" }
+
+// Error satisfies the builtin error interface
+func (e CloudWatchLogsValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCloudWatchLogs.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CloudWatchLogsValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CloudWatchLogsValidationError{}
+
+// Validate checks the field values on CloudLogging with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *CloudLogging) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CloudLogging with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in CloudLoggingMultiError, or
+// nil if none found.
+func (m *CloudLogging) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CloudLogging) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for ProjectId
+
+	switch m.Credential.(type) {
+
+	case *CloudLogging_ServiceAccountFile:
+		// no validation rules for ServiceAccountFile
+
+	case *CloudLogging_Adc:
+
+		if all {
+			switch v := interface{}(m.GetAdc()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, CloudLoggingValidationError{
+						field:  "Adc",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, CloudLoggingValidationError{
+						field:  "Adc",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetAdc()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return CloudLoggingValidationError{
+					field:  "Adc",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return CloudLoggingMultiError(errors)
+	}
+
+	return nil
+}
+
+// CloudLoggingMultiError is an error wrapping multiple validation errors
+// returned by CloudLogging.ValidateAll() if the designated constraints aren't met.
+type CloudLoggingMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CloudLoggingMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CloudLoggingMultiError) AllErrors() []error { return m }
+
+// CloudLoggingValidationError is the validation error returned by
+// CloudLogging.Validate if the designated constraints aren't met.
+type CloudLoggingValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CloudLoggingValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CloudLoggingValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CloudLoggingValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CloudLoggingValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CloudLoggingValidationError) ErrorName() string { return "CloudLoggingValidationError" }
+
+// Error satisfies the builtin error interface
+func (e CloudLoggingValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCloudLogging.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CloudLoggingValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CloudLoggingValidationError{}
+
+// Validate checks the field values on AzureMonitorLogs with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *AzureMonitorLogs) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on AzureMonitorLogs with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// AzureMonitorLogsMultiError, or nil if none found.
+func (m *AzureMonitorLogs) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *AzureMonitorLogs) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for WorkspaceId
+
+	// no validation rules for ManagedIdentityClientId
+
+	switch m.Credential.(type) {
+
+	case *AzureMonitorLogs_ServicePrincipal:
+
+		if all {
+			switch v := interface{}(m.GetServicePrincipal()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, AzureMonitorLogsValidationError{
+						field:  "ServicePrincipal",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, AzureMonitorLogsValidationError{
+						field:  "ServicePrincipal",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetServicePrincipal()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return AzureMonitorLogsValidationError{
+					field:  "ServicePrincipal",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	case *AzureMonitorLogs_ManagedIdentity:
+
+		if all {
+			switch v := interface{}(m.GetManagedIdentity()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, AzureMonitorLogsValidationError{
+						field:  "ManagedIdentity",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, AzureMonitorLogsValidationError{
+						field:  "ManagedIdentity",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetManagedIdentity()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return AzureMonitorLogsValidationError{
+					field:  "ManagedIdentity",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return AzureMonitorLogsMultiError(errors)
+	}
+
+	return nil
+}
+
+// AzureMonitorLogsMultiError is an error wrapping multiple validation errors
+// returned by AzureMonitorLogs.ValidateAll() if the designated constraints
+// aren't met.
+type AzureMonitorLogsMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m AzureMonitorLogsMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m AzureMonitorLogsMultiError) AllErrors() []error { return m }
+
+// AzureMonitorLogsValidationError is the validation error returned by
+// AzureMonitorLogs.Validate if the designated constraints aren't met.
+type AzureMonitorLogsValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e AzureMonitorLogsValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e AzureMonitorLogsValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e AzureMonitorLogsValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e AzureMonitorLogsValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e AzureMonitorLogsValidationError) ErrorName() string { return "AzureMonitorLogsValidationError" }
+
+// Error satisfies the builtin error interface
+func (e AzureMonitorLogsValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sAzureMonitorLogs.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = AzureMonitorLogsValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = AzureMonitorLogsValidationError{}
+
+// Validate checks the field values on Splunk with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Splunk) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Splunk with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in SplunkMultiError, or nil if none found.
+func (m *Splunk) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Splunk) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = SplunkValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for Username
+
+	// no validation rules for Password
+
+	// no validation rules for Token
+
+	// no validation rules for Search
+
+	// no validation rules for SavedSearch
+
+	// no validation rules for App
+
+	// no validation rules for Owner
+
+	// no validation rules for Earliest
+
+	// no validation rules for Latest
+
+	// no validation rules for CaCertificate
+
+	// no validation rules for Insecure
+
+	if len(errors) > 0 {
+		return SplunkMultiError(errors)
+	}
+
+	return nil
+}
+
+// SplunkMultiError is an error wrapping multiple validation errors returned by
+// Splunk.ValidateAll() if the designated constraints aren't met.
+type SplunkMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SplunkMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SplunkMultiError) AllErrors() []error { return m }
+
+// SplunkValidationError is the validation error returned by Splunk.Validate if
+// the designated constraints aren't met.
+type SplunkValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SplunkValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SplunkValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SplunkValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SplunkValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SplunkValidationError) ErrorName() string { return "SplunkValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SplunkValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSplunk.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SplunkValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SplunkValidationError{}
+
+// Validate checks the field values on Kafka with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Kafka) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Kafka with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in KafkaMultiError, or nil if none found.
+func (m *Kafka) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Kafka) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for StartOffset
+
+	if all {
+		switch v := interface{}(m.GetSince()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, KafkaValidationError{
+					field:  "Since",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, KafkaValidationError{
+					field:  "Since",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSince()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return KafkaValidationError{
+				field:  "Since",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for SaslMechanism
+
+	// no validation rules for Username
+
+	// no validation rules for Password
+
+	// no validation rules for Tls
+
+	// no validation rules for CaCertificate
+
+	// no validation rules for Insecure
+
+	// no validation rules for Encoding
+
+	// no validation rules for SchemaRegistryUrl
+
+	// no validation rules for SchemaRegistryUsername
+
+	// no validation rules for SchemaRegistryPassword
+
+	if len(errors) > 0 {
+		return KafkaMultiError(errors)
+	}
+
+	return nil
+}
+
+// KafkaMultiError is an error wrapping multiple validation errors returned by
+// Kafka.ValidateAll() if the designated constraints aren't met.
+type KafkaMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m KafkaMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m KafkaMultiError) AllErrors() []error { return m }
+
+// KafkaValidationError is the validation error returned by Kafka.Validate if
+// the designated constraints aren't met.
+type KafkaValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e KafkaValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e KafkaValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e KafkaValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e KafkaValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e KafkaValidationError) ErrorName() string { return "KafkaValidationError" }
+
+// Error satisfies the builtin error interface
+func (e KafkaValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sKafka.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = KafkaValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = KafkaValidationError{}
+
+// Validate checks the field values on IMAP with the rules defined in the proto
+// definition for this message. If any rules are violated, the first error
+// encountered is returned, or nil if there are no violations.
+func (m *IMAP) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on IMAP with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in IMAPMultiError, or nil if none found.
+func (m *IMAP) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *IMAP) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Address
+
+	// no validation rules for Username
+
+	if all {
+		switch v := interface{}(m.GetSince()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, IMAPValidationError{
+					field:  "Since",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, IMAPValidationError{
+					field:  "Since",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSince()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return IMAPValidationError{
+				field:  "Since",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Starttls
+
+	// no validation rules for Plaintext
+
+	// no validation rules for Insecure
+
+	// no validation rules for MaxMessageSize
+
+	switch m.Credential.(type) {
+
+	case *IMAP_Password:
+		// no validation rules for Password
+
+	case *IMAP_Oauth2Token:
+		// no validation rules for Oauth2Token
+
+	}
+
+	if len(errors) > 0 {
+		return IMAPMultiError(errors)
+	}
+
+	return nil
+}
+
+// IMAPMultiError is an error wrapping multiple validation errors returned by
+// IMAP.ValidateAll() if the designated constraints aren't met.
+type IMAPMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m IMAPMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m IMAPMultiError) AllErrors() []error { return m }
+
+// IMAPValidationError is the validation error returned by IMAP.Validate if the
+// designated constraints aren't met.
+type IMAPValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e IMAPValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e IMAPValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e IMAPValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e IMAPValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e IMAPValidationError) ErrorName() string { return "IMAPValidationError" }
 
 // Error satisfies the builtin error interface
-func (e BuildkiteValidationError) Error() string {
+func (e IMAPValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -3216,14 +8050,14 @@ func (e BuildkiteValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sBuildkite.%s: %s%s",
+		"invalid %sIMAP.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = BuildkiteValidationError{}
+var _ error = IMAPValidationError{}
 
 var _ interface {
 	Field() string
@@ -3231,99 +8065,121 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = BuildkiteValidationError{}
+} = IMAPValidationError{}
 
-// Validate checks the field values on Gerrit with the rules defined in the
+// Validate checks the field values on Helpdesk with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Gerrit) Validate() error {
+func (m *Helpdesk) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Gerrit with the rules defined in the
-// proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in GerritMultiError, or nil if none found.
-func (m *Gerrit) ValidateAll() error {
+// ValidateAll checks the field values on Helpdesk with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in HelpdeskMultiError, or nil
+// if none found.
+func (m *Helpdesk) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Gerrit) validate(all bool) error {
+func (m *Helpdesk) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	if _, err := url.Parse(m.GetEndpoint()); err != nil {
-		err = GerritValidationError{
-			field:  "Endpoint",
-			reason: "value must be a valid URI",
-			cause:  err,
+	if all {
+		switch v := interface{}(m.GetUpdatedSince()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, HelpdeskValidationError{
+					field:  "UpdatedSince",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, HelpdeskValidationError{
+					field:  "UpdatedSince",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
 		}
-		if !all {
-			return err
+	} else if v, ok := interface{}(m.GetUpdatedSince()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return HelpdeskValidationError{
+				field:  "UpdatedSince",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
 		}
-		errors = append(errors, err)
 	}
 
-	switch m.Credential.(type) {
+	// no validation rules for StatePath
 
-	case *Gerrit_BasicAuth:
+	// no validation rules for SkipAttachments
+
+	switch m.Platform.(type) {
+
+	case *Helpdesk_Zendesk:
 
 		if all {
-			switch v := interface{}(m.GetBasicAuth()).(type) {
+			switch v := interface{}(m.GetZendesk()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, GerritValidationError{
-						field:  "BasicAuth",
+					errors = append(errors, HelpdeskValidationError{
+						field:  "Zendesk",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
-					errors = append(errors, GerritValidationError{
-						field:  "BasicAuth",
+					errors = append(errors, HelpdeskValidationError{
+						field:  "Zendesk",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetBasicAuth()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetZendesk()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
-				return GerritValidationError{
-					field:  "BasicAuth",
+				return HelpdeskValidationError{
+					field:  "Zendesk",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
 			}
 		}
 
-	case *Gerrit_Unauthenticated:
+	case *Helpdesk_Freshdesk:
 
 		if all {
-			switch v := interface{}(m.GetUnauthenticated()).(type) {
+			switch v := interface{}(m.GetFreshdesk()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, GerritValidationError{
-						field:  "Unauthenticated",
+					errors = append(errors, HelpdeskValidationError{
+						field:  "Freshdesk",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
-					errors = append(errors, GerritValidationError{
-						field:  "Unauthenticated",
+					errors = append(errors, HelpdeskValidationError{
+						field:  "Freshdesk",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetUnauthenticated()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetFreshdesk()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
-				return GerritValidationError{
-					field:  "Unauthenticated",
+				return HelpdeskValidationError{
+					field:  "Freshdesk",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
@@ -3333,18 +8189,18 @@ func (m *Gerrit) validate(all bool) error {
 	}
 
 	if len(errors) > 0 {
-		return GerritMultiError(errors)
+		return HelpdeskMultiError(errors)
 	}
 
 	return nil
 }
 
-// GerritMultiError is an error wrapping multiple validation errors returned by
-// Gerrit.ValidateAll() if the designated constraints aren't met.
-type GerritMultiError []error
+// HelpdeskMultiError is an error wrapping multiple validation errors returned
+// by Helpdesk.ValidateAll() if the designated constraints aren't met.
+type HelpdeskMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m GerritMultiError) Error() string {
+func (m HelpdeskMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -3353,11 +8209,11 @@ func (m GerritMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m GerritMultiError) AllErrors() []error { return m }
+func (m HelpdeskMultiError) AllErrors() []error { return m }
 
-// GerritValidationError is the validation error returned by Gerrit.Validate if
-// the designated constraints aren't met.
-type GerritValidationError struct {
+// HelpdeskValidationError is the validation error returned by
+// Helpdesk.Validate if the designated constraints aren't met.
+type HelpdeskValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -3365,22 +8221,22 @@ type GerritValidationError struct {
 }
 
 // Field function returns field value.
-func (e GerritValidationError) Field() string { return e.field }
+func (e HelpdeskValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e GerritValidationError) Reason() string { return e.reason }
+func (e HelpdeskValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e GerritValidationError) Cause() error { return e.cause }
+func (e HelpdeskValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e GerritValidationError) Key() bool { return e.key }
+func (e HelpdeskValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e GerritValidationError) ErrorName() string { return "GerritValidationError" }
+func (e HelpdeskValidationError) ErrorName() string { return "HelpdeskValidationError" }
 
 // Error satisfies the builtin error interface
-func (e GerritValidationError) Error() string {
+func (e HelpdeskValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -3392,14 +8248,14 @@ func (e GerritValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sGerrit.%s: %s%s",
+		"invalid %sHelpdesk.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = GerritValidationError{}
+var _ error = HelpdeskValidationError{}
 
 var _ interface {
 	Field() string
@@ -3407,23 +8263,23 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = GerritValidationError{}
+} = HelpdeskValidationError{}
 
-// Validate checks the field values on Jenkins with the rules defined in the
+// Validate checks the field values on Zendesk with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Jenkins) Validate() error {
+func (m *Zendesk) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Jenkins with the rules defined in the
+// ValidateAll checks the field values on Zendesk with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in JenkinsMultiError, or nil if none found.
-func (m *Jenkins) ValidateAll() error {
+// a list of violation errors wrapped in ZendeskMultiError, or nil if none found.
+func (m *Zendesk) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Jenkins) validate(all bool) error {
+func (m *Zendesk) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
@@ -3431,7 +8287,7 @@ func (m *Jenkins) validate(all bool) error {
 	var errors []error
 
 	if _, err := url.Parse(m.GetEndpoint()); err != nil {
-		err = JenkinsValidationError{
+		err = ZendeskValidationError{
 			field:  "Endpoint",
 			reason: "value must be a valid URI",
 			cause:  err,
@@ -3442,85 +8298,31 @@ func (m *Jenkins) validate(all bool) error {
 		errors = append(errors, err)
 	}
 
-	switch m.Credential.(type) {
-
-	case *Jenkins_BasicAuth:
+	// no validation rules for Email
 
-		if all {
-			switch v := interface{}(m.GetBasicAuth()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, JenkinsValidationError{
-						field:  "BasicAuth",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, JenkinsValidationError{
-						field:  "BasicAuth",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetBasicAuth()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return JenkinsValidationError{
-					field:  "BasicAuth",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
+	switch m.Credential.(type) {
 
-	case *Jenkins_Header:
+	case *Zendesk_ApiToken:
+		// no validation rules for ApiToken
 
-		if all {
-			switch v := interface{}(m.GetHeader()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, JenkinsValidationError{
-						field:  "Header",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, JenkinsValidationError{
-						field:  "Header",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetHeader()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return JenkinsValidationError{
-					field:  "Header",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
+	case *Zendesk_OauthToken:
+		// no validation rules for OauthToken
 
 	}
 
 	if len(errors) > 0 {
-		return JenkinsMultiError(errors)
+		return ZendeskMultiError(errors)
 	}
 
 	return nil
 }
 
-// JenkinsMultiError is an error wrapping multiple validation errors returned
-// by Jenkins.ValidateAll() if the designated constraints aren't met.
-type JenkinsMultiError []error
+// ZendeskMultiError is an error wrapping multiple validation errors returned
+// by Zendesk.ValidateAll() if the designated constraints aren't met.
+type ZendeskMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m JenkinsMultiError) Error() string {
+func (m ZendeskMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -3529,11 +8331,11 @@ func (m JenkinsMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m JenkinsMultiError) AllErrors() []error { return m }
+func (m ZendeskMultiError) AllErrors() []error { return m }
 
-// JenkinsValidationError is the validation error returned by Jenkins.Validate
+// ZendeskValidationError is the validation error returned by Zendesk.Validate
 // if the designated constraints aren't met.
-type JenkinsValidationError struct {
+type ZendeskValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -3541,22 +8343,22 @@ type JenkinsValidationError struct {
 }
 
 // Field function returns field value.
-func (e JenkinsValidationError) Field() string { return e.field }
+func (e ZendeskValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e JenkinsValidationError) Reason() string { return e.reason }
+func (e ZendeskValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e JenkinsValidationError) Cause() error { return e.cause }
+func (e ZendeskValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e JenkinsValidationError) Key() bool { return e.key }
+func (e ZendeskValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e JenkinsValidationError) ErrorName() string { return "JenkinsValidationError" }
+func (e ZendeskValidationError) ErrorName() string { return "ZendeskValidationError" }
 
 // Error satisfies the builtin error interface
-func (e JenkinsValidationError) Error() string {
+func (e ZendeskValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -3568,14 +8370,14 @@ func (e JenkinsValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sJenkins.%s: %s%s",
+		"invalid %sZendesk.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = JenkinsValidationError{}
+var _ error = ZendeskValidationError{}
 
 var _ interface {
 	Field() string
@@ -3583,123 +8385,57 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = JenkinsValidationError{}
+} = ZendeskValidationError{}
 
-// Validate checks the field values on Teams with the rules defined in the
+// Validate checks the field values on Freshdesk with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Teams) Validate() error {
+func (m *Freshdesk) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Teams with the rules defined in the
-// proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in TeamsMultiError, or nil if none found.
-func (m *Teams) ValidateAll() error {
-	return m.validate(true)
-}
-
-func (m *Teams) validate(all bool) error {
-	if m == nil {
-		return nil
-	}
-
-	var errors []error
-
-	if _, err := url.Parse(m.GetEndpoint()); err != nil {
-		err = TeamsValidationError{
-			field:  "Endpoint",
-			reason: "value must be a valid URI",
-			cause:  err,
-		}
-		if !all {
-			return err
-		}
-		errors = append(errors, err)
-	}
-
-	switch m.Credential.(type) {
-
-	case *Teams_Token:
-		// no validation rules for Token
-
-	case *Teams_Authenticated:
-
-		if all {
-			switch v := interface{}(m.GetAuthenticated()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, TeamsValidationError{
-						field:  "Authenticated",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, TeamsValidationError{
-						field:  "Authenticated",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetAuthenticated()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return TeamsValidationError{
-					field:  "Authenticated",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
-
-	case *Teams_Oauth:
-
-		if all {
-			switch v := interface{}(m.GetOauth()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, TeamsValidationError{
-						field:  "Oauth",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, TeamsValidationError{
-						field:  "Oauth",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetOauth()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return TeamsValidationError{
-					field:  "Oauth",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
+// ValidateAll checks the field values on Freshdesk with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in FreshdeskMultiError, or nil
+// if none found.
+func (m *Freshdesk) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Freshdesk) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
 
+	var errors []error
+
+	if _, err := url.Parse(m.GetEndpoint()); err != nil {
+		err = FreshdeskValidationError{
+			field:  "Endpoint",
+			reason: "value must be a valid URI",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
 	}
 
+	// no validation rules for ApiKey
+
 	if len(errors) > 0 {
-		return TeamsMultiError(errors)
+		return FreshdeskMultiError(errors)
 	}
 
 	return nil
 }
 
-// TeamsMultiError is an error wrapping multiple validation errors returned by
-// Teams.ValidateAll() if the designated constraints aren't met.
-type TeamsMultiError []error
+// FreshdeskMultiError is an error wrapping multiple validation errors returned
+// by Freshdesk.ValidateAll() if the designated constraints aren't met.
+type FreshdeskMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m TeamsMultiError) Error() string {
+func (m FreshdeskMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -3708,11 +8444,11 @@ func (m TeamsMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m TeamsMultiError) AllErrors() []error { return m }
+func (m FreshdeskMultiError) AllErrors() []error { return m }
 
-// TeamsValidationError is the validation error returned by Teams.Validate if
-// the designated constraints aren't met.
-type TeamsValidationError struct {
+// FreshdeskValidationError is the validation error returned by
+// Freshdesk.Validate if the designated constraints aren't met.
+type FreshdeskValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -3720,22 +8456,22 @@ type TeamsValidationError struct {
 }
 
 // Field function returns field value.
-func (e TeamsValidationError) Field() string { return e.field }
+func (e FreshdeskValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e TeamsValidationError) Reason() string { return e.reason }
+func (e FreshdeskValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e TeamsValidationError) Cause() error { return e.cause }
+func (e FreshdeskValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e TeamsValidationError) Key() bool { return e.key }
+func (e FreshdeskValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e TeamsValidationError) ErrorName() string { return "TeamsValidationError" }
+func (e FreshdeskValidationError) ErrorName() string { return "FreshdeskValidationError" }
 
 // Error satisfies the builtin error interface
-func (e TeamsValidationError) Error() string {
+func (e FreshdeskValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -3747,14 +8483,14 @@ func (e TeamsValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sTeams.%s: %s%s",
+		"invalid %sFreshdesk.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = TeamsValidationError{}
+var _ error = FreshdeskValidationError{}
 
 var _ interface {
 	Field() string
@@ -3762,24 +8498,24 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = TeamsValidationError{}
+} = FreshdeskValidationError{}
 
-// Validate checks the field values on Artifactory with the rules defined in
-// the proto definition for this message. If any rules are violated, the first
+// Validate checks the field values on ServiceNow with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Artifactory) Validate() error {
+func (m *ServiceNow) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Artifactory with the rules defined in
+// ValidateAll checks the field values on ServiceNow with the rules defined in
 // the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in ArtifactoryMultiError, or
+// result is a list of violation errors wrapped in ServiceNowMultiError, or
 // nil if none found.
-func (m *Artifactory) ValidateAll() error {
+func (m *ServiceNow) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Artifactory) validate(all bool) error {
+func (m *ServiceNow) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
@@ -3787,7 +8523,7 @@ func (m *Artifactory) validate(all bool) error {
 	var errors []error
 
 	if _, err := url.Parse(m.GetEndpoint()); err != nil {
-		err = ArtifactoryValidationError{
+		err = ServiceNowValidationError{
 			field:  "Endpoint",
 			reason: "value must be a valid URI",
 			cause:  err,
@@ -3798,15 +8534,48 @@ func (m *Artifactory) validate(all bool) error {
 		errors = append(errors, err)
 	}
 
+	// no validation rules for Query
+
+	if all {
+		switch v := interface{}(m.GetUpdatedSince()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ServiceNowValidationError{
+					field:  "UpdatedSince",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ServiceNowValidationError{
+					field:  "UpdatedSince",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetUpdatedSince()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ServiceNowValidationError{
+				field:  "UpdatedSince",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for SkipAttachments
+
 	switch m.Credential.(type) {
 
-	case *Artifactory_BasicAuth:
+	case *ServiceNow_BasicAuth:
 
 		if all {
 			switch v := interface{}(m.GetBasicAuth()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, ArtifactoryValidationError{
+					errors = append(errors, ServiceNowValidationError{
 						field:  "BasicAuth",
 						reason: "embedded message failed validation",
 						cause:  err,
@@ -3814,7 +8583,7 @@ func (m *Artifactory) validate(all bool) error {
 				}
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
-					errors = append(errors, ArtifactoryValidationError{
+					errors = append(errors, ServiceNowValidationError{
 						field:  "BasicAuth",
 						reason: "embedded message failed validation",
 						cause:  err,
@@ -3823,7 +8592,7 @@ func (m *Artifactory) validate(all bool) error {
 			}
 		} else if v, ok := interface{}(m.GetBasicAuth()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
-				return ArtifactoryValidationError{
+				return ServiceNowValidationError{
 					field:  "BasicAuth",
 					reason: "embedded message failed validation",
 					cause:  err,
@@ -3831,24 +8600,24 @@ func (m *Artifactory) validate(all bool) error {
 			}
 		}
 
-	case *Artifactory_AccessToken:
-		// no validation rules for AccessToken
+	case *ServiceNow_OauthToken:
+		// no validation rules for OauthToken
 
 	}
 
 	if len(errors) > 0 {
-		return ArtifactoryMultiError(errors)
+		return ServiceNowMultiError(errors)
 	}
 
 	return nil
 }
 
-// ArtifactoryMultiError is an error wrapping multiple validation errors
-// returned by Artifactory.ValidateAll() if the designated constraints aren't met.
-type ArtifactoryMultiError []error
+// ServiceNowMultiError is an error wrapping multiple validation errors
+// returned by ServiceNow.ValidateAll() if the designated constraints aren't met.
+type ServiceNowMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m ArtifactoryMultiError) Error() string {
+func (m ServiceNowMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -3857,11 +8626,11 @@ func (m ArtifactoryMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m ArtifactoryMultiError) AllErrors() []error { return m }
+func (m ServiceNowMultiError) AllErrors() []error { return m }
 
-// ArtifactoryValidationError is the validation error returned by
-// Artifactory.Validate if the designated constraints aren't met.
-type ArtifactoryValidationError struct {
+// ServiceNowValidationError is the validation error returned by
+// ServiceNow.Validate if the designated constraints aren't met.
+type ServiceNowValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -3869,22 +8638,22 @@ type ArtifactoryValidationError struct {
 }
 
 // Field function returns field value.
-func (e ArtifactoryValidationError) Field() string { return e.field }
+func (e ServiceNowValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e ArtifactoryValidationError) Reason() string { return e.reason }
+func (e ServiceNowValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e ArtifactoryValidationError) Cause() error { return e.cause }
+func (e ServiceNowValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e ArtifactoryValidationError) Key() bool { return e.key }
+func (e ServiceNowValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e ArtifactoryValidationError) ErrorName() string { return "ArtifactoryValidationError" }
+func (e ServiceNowValidationError) ErrorName() string { return "ServiceNowValidationError" }
 
 // Error satisfies the builtin error interface
-func (e ArtifactoryValidationError) Error() string {
+func (e ServiceNowValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -3896,14 +8665,14 @@ func (e ArtifactoryValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sArtifactory.%s: %s%s",
+		"invalid %sServiceNow.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = ArtifactoryValidationError{}
+var _ error = ServiceNowValidationError{}
 
 var _ interface {
 	Field() string
@@ -3911,52 +8680,46 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = ArtifactoryValidationError{}
+} = ServiceNowValidationError{}
 
-// Validate checks the field values on Syslog with the rules defined in the
+// Validate checks the field values on Notion with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Syslog) Validate() error {
+func (m *Notion) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Syslog with the rules defined in the
+// ValidateAll checks the field values on Notion with the rules defined in the
 // proto definition for this message. If any rules are violated, the result is
-// a list of violation errors wrapped in SyslogMultiError, or nil if none found.
-func (m *Syslog) ValidateAll() error {
+// a list of violation errors wrapped in NotionMultiError, or nil if none found.
+func (m *Notion) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Syslog) validate(all bool) error {
+func (m *Notion) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for Protocol
-
-	// no validation rules for ListenAddress
-
-	// no validation rules for TlsCert
-
-	// no validation rules for TlsKey
+	// no validation rules for Token
 
-	// no validation rules for Format
+	// no validation rules for SkipFiles
 
 	if len(errors) > 0 {
-		return SyslogMultiError(errors)
+		return NotionMultiError(errors)
 	}
 
 	return nil
 }
 
-// SyslogMultiError is an error wrapping multiple validation errors returned by
-// Syslog.ValidateAll() if the designated constraints aren't met.
-type SyslogMultiError []error
+// NotionMultiError is an error wrapping multiple validation errors returned by
+// Notion.ValidateAll() if the designated constraints aren't met.
+type NotionMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m SyslogMultiError) Error() string {
+func (m NotionMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -3965,11 +8728,11 @@ func (m SyslogMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m SyslogMultiError) AllErrors() []error { return m }
+func (m NotionMultiError) AllErrors() []error { return m }
 
-// SyslogValidationError is the validation error returned by Syslog.Validate if
+// NotionValidationError is the validation error returned by Notion.Validate if
 // the designated constraints aren't met.
-type SyslogValidationError struct {
+type NotionValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -3977,22 +8740,22 @@ type SyslogValidationError struct {
 }
 
 // Field function returns field value.
-func (e SyslogValidationError) Field() string { return e.field }
+func (e NotionValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e SyslogValidationError) Reason() string { return e.reason }
+func (e NotionValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e SyslogValidationError) Cause() error { return e.cause }
+func (e NotionValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e SyslogValidationError) Key() bool { return e.key }
+func (e NotionValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e SyslogValidationError) ErrorName() string { return "SyslogValidationError" }
+func (e NotionValidationError) ErrorName() string { return "NotionValidationError" }
 
 // Error satisfies the builtin error interface
-func (e SyslogValidationError) Error() string {
+func (e NotionValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -4004,14 +8767,14 @@ func (e SyslogValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sSyslog.%s: %s%s",
+		"invalid %sNotion.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = SyslogValidationError{}
+var _ error = NotionValidationError{}
 
 var _ interface {
 	Field() string
@@ -4019,90 +8782,120 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = SyslogValidationError{}
+} = NotionValidationError{}
 
-// Validate checks the field values on PublicEventMonitoring with the rules
-// defined in the proto definition for this message. If any rules are
-// violated, the first error encountered is returned, or nil if there are no violations.
-func (m *PublicEventMonitoring) Validate() error {
+// Validate checks the field values on Tracker with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Tracker) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on PublicEventMonitoring with the rules
-// defined in the proto definition for this message. If any rules are
-// violated, the result is a list of violation errors wrapped in
-// PublicEventMonitoringMultiError, or nil if none found.
-func (m *PublicEventMonitoring) ValidateAll() error {
+// ValidateAll checks the field values on Tracker with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in TrackerMultiError, or nil if none found.
+func (m *Tracker) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *PublicEventMonitoring) validate(all bool) error {
+func (m *Tracker) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for Domain
+	// no validation rules for SkipAttachments
 
-	// no validation rules for MaxDepth
+	switch m.Platform.(type) {
 
-	if all {
-		switch v := interface{}(m.GetSince()).(type) {
-		case interface{ ValidateAll() error }:
-			if err := v.ValidateAll(); err != nil {
-				errors = append(errors, PublicEventMonitoringValidationError{
-					field:  "Since",
-					reason: "embedded message failed validation",
-					cause:  err,
-				})
+	case *Tracker_Trello:
+
+		if all {
+			switch v := interface{}(m.GetTrello()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, TrackerValidationError{
+						field:  "Trello",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, TrackerValidationError{
+						field:  "Trello",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
 			}
-		case interface{ Validate() error }:
+		} else if v, ok := interface{}(m.GetTrello()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
-				errors = append(errors, PublicEventMonitoringValidationError{
-					field:  "Since",
+				return TrackerValidationError{
+					field:  "Trello",
 					reason: "embedded message failed validation",
 					cause:  err,
-				})
+				}
 			}
 		}
-	} else if v, ok := interface{}(m.GetSince()).(interface{ Validate() error }); ok {
-		if err := v.Validate(); err != nil {
-			return PublicEventMonitoringValidationError{
-				field:  "Since",
-				reason: "embedded message failed validation",
-				cause:  err,
+
+	case *Tracker_Asana:
+
+		if all {
+			switch v := interface{}(m.GetAsana()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, TrackerValidationError{
+						field:  "Asana",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, TrackerValidationError{
+						field:  "Asana",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetAsana()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return TrackerValidationError{
+					field:  "Asana",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
 			}
 		}
-	}
-
-	switch m.Credential.(type) {
 
-	case *PublicEventMonitoring_Unauthenticated:
+	case *Tracker_Linear:
 
 		if all {
-			switch v := interface{}(m.GetUnauthenticated()).(type) {
+			switch v := interface{}(m.GetLinear()).(type) {
 			case interface{ ValidateAll() error }:
 				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, PublicEventMonitoringValidationError{
-						field:  "Unauthenticated",
+					errors = append(errors, TrackerValidationError{
+						field:  "Linear",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			case interface{ Validate() error }:
 				if err := v.Validate(); err != nil {
-					errors = append(errors, PublicEventMonitoringValidationError{
-						field:  "Unauthenticated",
+					errors = append(errors, TrackerValidationError{
+						field:  "Linear",
 						reason: "embedded message failed validation",
 						cause:  err,
 					})
 				}
 			}
-		} else if v, ok := interface{}(m.GetUnauthenticated()).(interface{ Validate() error }); ok {
+		} else if v, ok := interface{}(m.GetLinear()).(interface{ Validate() error }); ok {
 			if err := v.Validate(); err != nil {
-				return PublicEventMonitoringValidationError{
-					field:  "Unauthenticated",
+				return TrackerValidationError{
+					field:  "Linear",
 					reason: "embedded message failed validation",
 					cause:  err,
 				}
@@ -4112,19 +8905,120 @@ func (m *PublicEventMonitoring) validate(all bool) error {
 	}
 
 	if len(errors) > 0 {
-		return PublicEventMonitoringMultiError(errors)
+		return TrackerMultiError(errors)
+	}
+
+	return nil
+}
+
+// TrackerMultiError is an error wrapping multiple validation errors returned
+// by Tracker.ValidateAll() if the designated constraints aren't met.
+type TrackerMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m TrackerMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m TrackerMultiError) AllErrors() []error { return m }
+
+// TrackerValidationError is the validation error returned by Tracker.Validate
+// if the designated constraints aren't met.
+type TrackerValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e TrackerValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e TrackerValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e TrackerValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e TrackerValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e TrackerValidationError) ErrorName() string { return "TrackerValidationError" }
+
+// Error satisfies the builtin error interface
+func (e TrackerValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sTracker.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = TrackerValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = TrackerValidationError{}
+
+// Validate checks the field values on Trello with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Trello) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Trello with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in TrelloMultiError, or nil if none found.
+func (m *Trello) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Trello) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for ApiKey
+
+	// no validation rules for Token
+
+	if len(errors) > 0 {
+		return TrelloMultiError(errors)
 	}
 
 	return nil
 }
 
-// PublicEventMonitoringMultiError is an error wrapping multiple validation
-// errors returned by PublicEventMonitoring.ValidateAll() if the designated
-// constraints aren't met.
-type PublicEventMonitoringMultiError []error
+// TrelloMultiError is an error wrapping multiple validation errors returned by
+// Trello.ValidateAll() if the designated constraints aren't met.
+type TrelloMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m PublicEventMonitoringMultiError) Error() string {
+func (m TrelloMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -4133,11 +9027,11 @@ func (m PublicEventMonitoringMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m PublicEventMonitoringMultiError) AllErrors() []error { return m }
+func (m TrelloMultiError) AllErrors() []error { return m }
 
-// PublicEventMonitoringValidationError is the validation error returned by
-// PublicEventMonitoring.Validate if the designated constraints aren't met.
-type PublicEventMonitoringValidationError struct {
+// TrelloValidationError is the validation error returned by Trello.Validate if
+// the designated constraints aren't met.
+type TrelloValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -4145,24 +9039,22 @@ type PublicEventMonitoringValidationError struct {
 }
 
 // Field function returns field value.
-func (e PublicEventMonitoringValidationError) Field() string { return e.field }
+func (e TrelloValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e PublicEventMonitoringValidationError) Reason() string { return e.reason }
+func (e TrelloValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e PublicEventMonitoringValidationError) Cause() error { return e.cause }
+func (e TrelloValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e PublicEventMonitoringValidationError) Key() bool { return e.key }
+func (e TrelloValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e PublicEventMonitoringValidationError) ErrorName() string {
-	return "PublicEventMonitoringValidationError"
-}
+func (e TrelloValidationError) ErrorName() string { return "TrelloValidationError" }
 
 // Error satisfies the builtin error interface
-func (e PublicEventMonitoringValidationError) Error() string {
+func (e TrelloValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -4174,14 +9066,14 @@ func (e PublicEventMonitoringValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sPublicEventMonitoring.%s: %s%s",
+		"invalid %sTrello.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = PublicEventMonitoringValidationError{}
+var _ error = TrelloValidationError{}
 
 var _ interface {
 	Field() string
@@ -4189,79 +9081,44 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = PublicEventMonitoringValidationError{}
+} = TrelloValidationError{}
 
-// Validate checks the field values on SlackRealtime with the rules defined in
-// the proto definition for this message. If any rules are violated, the first
+// Validate checks the field values on Asana with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *SlackRealtime) Validate() error {
+func (m *Asana) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on SlackRealtime with the rules defined
-// in the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in SlackRealtimeMultiError, or
-// nil if none found.
-func (m *SlackRealtime) ValidateAll() error {
+// ValidateAll checks the field values on Asana with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in AsanaMultiError, or nil if none found.
+func (m *Asana) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *SlackRealtime) validate(all bool) error {
+func (m *Asana) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	switch m.Credential.(type) {
-
-	case *SlackRealtime_Tokens:
-
-		if all {
-			switch v := interface{}(m.GetTokens()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, SlackRealtimeValidationError{
-						field:  "Tokens",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, SlackRealtimeValidationError{
-						field:  "Tokens",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetTokens()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return SlackRealtimeValidationError{
-					field:  "Tokens",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
-
-	}
+	// no validation rules for Token
 
 	if len(errors) > 0 {
-		return SlackRealtimeMultiError(errors)
+		return AsanaMultiError(errors)
 	}
 
 	return nil
 }
 
-// SlackRealtimeMultiError is an error wrapping multiple validation errors
-// returned by SlackRealtime.ValidateAll() if the designated constraints
-// aren't met.
-type SlackRealtimeMultiError []error
+// AsanaMultiError is an error wrapping multiple validation errors returned by
+// Asana.ValidateAll() if the designated constraints aren't met.
+type AsanaMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m SlackRealtimeMultiError) Error() string {
+func (m AsanaMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -4270,11 +9127,11 @@ func (m SlackRealtimeMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m SlackRealtimeMultiError) AllErrors() []error { return m }
+func (m AsanaMultiError) AllErrors() []error { return m }
 
-// SlackRealtimeValidationError is the validation error returned by
-// SlackRealtime.Validate if the designated constraints aren't met.
-type SlackRealtimeValidationError struct {
+// AsanaValidationError is the validation error returned by Asana.Validate if
+// the designated constraints aren't met.
+type AsanaValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -4282,22 +9139,22 @@ type SlackRealtimeValidationError struct {
 }
 
 // Field function returns field value.
-func (e SlackRealtimeValidationError) Field() string { return e.field }
+func (e AsanaValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e SlackRealtimeValidationError) Reason() string { return e.reason }
+func (e AsanaValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e SlackRealtimeValidationError) Cause() error { return e.cause }
+func (e AsanaValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e SlackRealtimeValidationError) Key() bool { return e.key }
+func (e AsanaValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e SlackRealtimeValidationError) ErrorName() string { return "SlackRealtimeValidationError" }
+func (e AsanaValidationError) ErrorName() string { return "AsanaValidationError" }
 
 // Error satisfies the builtin error interface
-func (e SlackRealtimeValidationError) Error() string {
+func (e AsanaValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -4309,14 +9166,14 @@ func (e SlackRealtimeValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sSlackRealtime.%s: %s%s",
+		"invalid %sAsana.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = SlackRealtimeValidationError{}
+var _ error = AsanaValidationError{}
 
 var _ interface {
 	Field() string
@@ -4324,80 +9181,44 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = SlackRealtimeValidationError{}
+} = AsanaValidationError{}
 
-// Validate checks the field values on Sharepoint with the rules defined in the
+// Validate checks the field values on Linear with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *Sharepoint) Validate() error {
+func (m *Linear) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on Sharepoint with the rules defined in
-// the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in SharepointMultiError, or
-// nil if none found.
-func (m *Sharepoint) ValidateAll() error {
+// ValidateAll checks the field values on Linear with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in LinearMultiError, or nil if none found.
+func (m *Linear) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *Sharepoint) validate(all bool) error {
+func (m *Linear) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	// no validation rules for SiteUrl
-
-	switch m.Credential.(type) {
-
-	case *Sharepoint_Oauth:
-
-		if all {
-			switch v := interface{}(m.GetOauth()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, SharepointValidationError{
-						field:  "Oauth",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, SharepointValidationError{
-						field:  "Oauth",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetOauth()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return SharepointValidationError{
-					field:  "Oauth",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
-
-	}
+	// no validation rules for ApiKey
 
 	if len(errors) > 0 {
-		return SharepointMultiError(errors)
+		return LinearMultiError(errors)
 	}
 
 	return nil
 }
 
-// SharepointMultiError is an error wrapping multiple validation errors
-// returned by Sharepoint.ValidateAll() if the designated constraints aren't met.
-type SharepointMultiError []error
+// LinearMultiError is an error wrapping multiple validation errors returned by
+// Linear.ValidateAll() if the designated constraints aren't met.
+type LinearMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m SharepointMultiError) Error() string {
+func (m LinearMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -4406,11 +9227,11 @@ func (m SharepointMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m SharepointMultiError) AllErrors() []error { return m }
+func (m LinearMultiError) AllErrors() []error { return m }
 
-// SharepointValidationError is the validation error returned by
-// Sharepoint.Validate if the designated constraints aren't met.
-type SharepointValidationError struct {
+// LinearValidationError is the validation error returned by Linear.Validate if
+// the designated constraints aren't met.
+type LinearValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -4418,22 +9239,22 @@ type SharepointValidationError struct {
 }
 
 // Field function returns field value.
-func (e SharepointValidationError) Field() string { return e.field }
+func (e LinearValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e SharepointValidationError) Reason() string { return e.reason }
+func (e LinearValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e SharepointValidationError) Cause() error { return e.cause }
+func (e LinearValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e SharepointValidationError) Key() bool { return e.key }
+func (e LinearValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e SharepointValidationError) ErrorName() string { return "SharepointValidationError" }
+func (e LinearValidationError) ErrorName() string { return "LinearValidationError" }
 
 // Error satisfies the builtin error interface
-func (e SharepointValidationError) Error() string {
+func (e LinearValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -4445,14 +9266,14 @@ func (e SharepointValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sSharepoint.%s: %s%s",
+		"invalid %sLinear.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = SharepointValidationError{}
+var _ error = LinearValidationError{}
 
 var _ interface {
 	Field() string
@@ -4460,95 +9281,46 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = SharepointValidationError{}
+} = LinearValidationError{}
 
-// Validate checks the field values on AzureRepos with the rules defined in the
+// Validate checks the field values on Discord with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
-func (m *AzureRepos) Validate() error {
+func (m *Discord) Validate() error {
 	return m.validate(false)
 }
 
-// ValidateAll checks the field values on AzureRepos with the rules defined in
-// the proto definition for this message. If any rules are violated, the
-// result is a list of violation errors wrapped in AzureReposMultiError, or
-// nil if none found.
-func (m *AzureRepos) ValidateAll() error {
+// ValidateAll checks the field values on Discord with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in DiscordMultiError, or nil if none found.
+func (m *Discord) ValidateAll() error {
 	return m.validate(true)
 }
 
-func (m *AzureRepos) validate(all bool) error {
+func (m *Discord) validate(all bool) error {
 	if m == nil {
 		return nil
 	}
 
 	var errors []error
 
-	if _, err := url.Parse(m.GetEndpoint()); err != nil {
-		err = AzureReposValidationError{
-			field:  "Endpoint",
-			reason: "value must be a valid URI",
-			cause:  err,
-		}
-		if !all {
-			return err
-		}
-		errors = append(errors, err)
-	}
-
-	// no validation rules for IncludeForks
-
-	switch m.Credential.(type) {
-
-	case *AzureRepos_Token:
-		// no validation rules for Token
-
-	case *AzureRepos_Oauth:
-
-		if all {
-			switch v := interface{}(m.GetOauth()).(type) {
-			case interface{ ValidateAll() error }:
-				if err := v.ValidateAll(); err != nil {
-					errors = append(errors, AzureReposValidationError{
-						field:  "Oauth",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			case interface{ Validate() error }:
-				if err := v.Validate(); err != nil {
-					errors = append(errors, AzureReposValidationError{
-						field:  "Oauth",
-						reason: "embedded message failed validation",
-						cause:  err,
-					})
-				}
-			}
-		} else if v, ok := interface{}(m.GetOauth()).(interface{ Validate() error }); ok {
-			if err := v.Validate(); err != nil {
-				return AzureReposValidationError{
-					field:  "Oauth",
-					reason: "embedded message failed validation",
-					cause:  err,
-				}
-			}
-		}
+	// no validation rules for Token
 
-	}
+	// no validation rules for SkipAttachments
 
 	if len(errors) > 0 {
-		return AzureReposMultiError(errors)
+		return DiscordMultiError(errors)
 	}
 
 	return nil
 }
 
-// AzureReposMultiError is an error wrapping multiple validation errors
-// returned by AzureRepos.ValidateAll() if the designated constraints aren't met.
-type AzureReposMultiError []error
+// DiscordMultiError is an error wrapping multiple validation errors returned
+// by Discord.ValidateAll() if the designated constraints aren't met.
+type DiscordMultiError []error
 
 // Error returns a concatenation of all the error messages it wraps.
-func (m AzureReposMultiError) Error() string {
+func (m DiscordMultiError) Error() string {
 	var msgs []string
 	for _, err := range m {
 		msgs = append(msgs, err.Error())
@@ -4557,11 +9329,11 @@ func (m AzureReposMultiError) Error() string {
 }
 
 // AllErrors returns a list of validation violation errors.
-func (m AzureReposMultiError) AllErrors() []error { return m }
+func (m DiscordMultiError) AllErrors() []error { return m }
 
-// AzureReposValidationError is the validation error returned by
-// AzureRepos.Validate if the designated constraints aren't met.
-type AzureReposValidationError struct {
+// DiscordValidationError is the validation error returned by Discord.Validate
+// if the designated constraints aren't met.
+type DiscordValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -4569,22 +9341,22 @@ type AzureReposValidationError struct {
 }
 
 // Field function returns field value.
-func (e AzureReposValidationError) Field() string { return e.field }
+func (e DiscordValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e AzureReposValidationError) Reason() string { return e.reason }
+func (e DiscordValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e AzureReposValidationError) Cause() error { return e.cause }
+func (e DiscordValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e AzureReposValidationError) Key() bool { return e.key }
+func (e DiscordValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e AzureReposValidationError) ErrorName() string { return "AzureReposValidationError" }
+func (e DiscordValidationError) ErrorName() string { return "DiscordValidationError" }
 
 // Error satisfies the builtin error interface
-func (e AzureReposValidationError) Error() string {
+func (e DiscordValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -4596,14 +9368,14 @@ func (e AzureReposValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sAzureRepos.%s: %s%s",
+		"invalid %sDiscord.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = AzureReposValidationError{}
+var _ error = DiscordValidationError{}
 
 var _ interface {
 	Field() string
@@ -4611,4 +9383,4 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = AzureReposValidationError{}
+} = DiscordValidationError{}
@@ -0,0 +1,219 @@
+// Package policy evaluates user supplied rules against findings to decide
+// whether they are reported, how severe they are, and whether they fail the
+// scan. Rule conditions are CEL expressions (https://github.com/google/cel-spec).
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"sigs.k8s.io/yaml"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// Action is what happens to a finding matched by a rule.
+type Action string
+
+const (
+	// ActionReport reports the finding.
+	ActionReport Action = "report"
+	// ActionIgnore does not report the finding.
+	ActionIgnore Action = "ignore"
+	// ActionFail reports the finding and fails the scan.
+	ActionFail Action = "fail"
+)
+
+// Keys added to the ExtraData of findings matched by a rule.
+const (
+	RuleKey     = "policy_rule"
+	SeverityKey = "severity"
+)
+
+// Rule is a condition and what to do with the findings that match it.
+type Rule struct {
+	Name string `json:"name"`
+	// When is a CEL expression that evaluates to a bool.
+	When     string `json:"when"`
+	Action   Action `json:"action"`
+	Severity string `json:"severity"`
+
+	program cel.Program
+}
+
+// Policy is an ordered list of rules. The first rule that matches a finding
+// decides what happens to it. Findings that no rule matches are reported.
+//
+//	rules:
+//	  - name: test-fixtures
+//	    when: '!verified && file.matches("(^|/)testdata/")'
+//	    action: ignore
+//	  - name: verified-cloud-credentials-on-main
+//	    when: 'verified && detector in ["AWS", "GCP", "Azure"] && branch == "main"'
+//	    action: fail
+//	    severity: critical
+type Policy struct {
+	Rules []Rule `json:"rules"`
+	// Branch is the value of the branch variable, i.e. the branch being
+	// scanned if it is known.
+	Branch string `json:"-"`
+}
+
+// Decision is the outcome of evaluating a policy against a finding.
+type Decision struct {
+	// Rule is the name of the matching rule, or empty if none matched.
+	Rule     string
+	Action   Action
+	Severity string
+}
+
+// Read parses and compiles the policy in a YAML file.
+func Read(filename string) (*Policy, error) {
+	input, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return New(input)
+}
+
+// New parses and compiles a YAML policy.
+func New(input []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.UnmarshalStrict(input, &p); err != nil {
+		return nil, err
+	}
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		switch rule.Action {
+		case ActionReport, ActionIgnore, ActionFail:
+		case "":
+			rule.Action = ActionReport
+		default:
+			return nil, fmt.Errorf("%s: unknown action %q, expected report, ignore or fail", rule.Name, rule.Action)
+		}
+		ast, issues := env.Compile(rule.When)
+		if issues.Err() != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Name, issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType {
+			return nil, fmt.Errorf("%s: condition is a %s, expected a bool", rule.Name, ast.OutputType())
+		}
+		if rule.program, err = env.Program(ast); err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Name, err)
+		}
+	}
+	return &p, nil
+}
+
+// newEnv declares the variables available to conditions.
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("detector", cel.StringType),
+		cel.Variable("verified", cel.BoolType),
+		cel.Variable("decoder", cel.StringType),
+		cel.Variable("source_type", cel.StringType),
+		cel.Variable("source_name", cel.StringType),
+		cel.Variable("branch", cel.StringType),
+		cel.Variable("file", cel.StringType),
+		cel.Variable("line", cel.IntType),
+		cel.Variable("archive_path", cel.StringType),
+		cel.Variable("archive_depth", cel.IntType),
+		cel.Variable("commit", cel.StringType),
+		cel.Variable("repository", cel.StringType),
+		cel.Variable("extra", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("metadata", cel.MapType(cel.StringType, cel.DynType)),
+	)
+}
+
+// vars returns the variables of a finding.
+func (p *Policy) vars(r *detectors.ResultWithMetadata) map[string]any {
+	metadata := map[string]any{}
+	if data, err := json.Marshal(r.SourceMetadata.GetData()); err == nil {
+		// The data is a single field named after the source type.
+		var wrapper map[string]map[string]any
+		if json.Unmarshal(data, &wrapper) == nil {
+			for _, fields := range wrapper {
+				metadata = fields
+			}
+		}
+	}
+	str := func(key string) string {
+		s, _ := metadata[key].(string)
+		return s
+	}
+	line, _ := metadata["line"].(float64)
+
+	detector := r.DetectorName
+	if detector == "" {
+		detector = r.DetectorType.String()
+	}
+	extra := r.ExtraData
+	if extra == nil {
+		extra = map[string]string{}
+	}
+	return map[string]any{
+		"detector":    detector,
+		"verified":    r.Verified,
+		"decoder":     r.DecoderType.String(),
+		"source_type": strings.ToLower(strings.TrimPrefix(r.SourceType.String(), "SOURCE_TYPE_")),
+		"source_name": r.SourceName,
+		"branch":      p.Branch,
+		"file":        str("file"),
+		"line":        int64(line),
+		// The path of the file in the archive file it was extracted from,
+		// and how many archives it is nested in.
+		"archive_path":  sources.ArchivePath(r.SourceMetadata),
+		"archive_depth": int64(sources.ArchiveDepth(r.SourceMetadata)),
+		"commit":        str("commit"),
+		"repository":    str("repository"),
+		"extra":         extra,
+		"metadata":      metadata,
+	}
+}
+
+// Evaluate returns the decision of the first rule that matches the finding.
+// Rules that fail to evaluate, e.g. because they access a missing metadata
+// key, are logged and don't match.
+func (p *Policy) Evaluate(ctx context.Context, r *detectors.ResultWithMetadata) Decision {
+	vars := p.vars(r)
+	for _, rule := range p.Rules {
+		out, _, err := rule.program.Eval(vars)
+		if err != nil {
+			ctx.Logger().V(2).Info("could not evaluate policy rule", "rule", rule.Name, "error", err)
+			continue
+		}
+		if match, _ := out.Value().(bool); match {
+			return Decision{Rule: rule.Name, Action: rule.Action, Severity: rule.Severity}
+		}
+	}
+	return Decision{Action: ActionReport}
+}
+
+// Label adds the rule and severity of a decision to the ExtraData of a
+// finding.
+func (d Decision) Label(r *detectors.ResultWithMetadata) {
+	if d.Rule == "" {
+		return
+	}
+	extra := make(map[string]string, len(r.ExtraData)+2)
+	for k, v := range r.ExtraData {
+		extra[k] = v
+	}
+	extra[RuleKey] = d.Rule
+	if d.Severity != "" {
+		extra[SeverityKey] = d.Severity
+	}
+	r.ExtraData = extra
+}
@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+const testPolicy = `
+rules:
+  - name: test-fixtures
+    when: '!verified && file.matches("(^|/)testdata/")'
+    action: ignore
+  - name: verified-cloud-on-main
+    when: 'verified && detector in ["AWS", "GCP"] && branch == "main"'
+    action: fail
+    severity: critical
+  - name: internal-repos
+    when: 'has(metadata.repository) && metadata.repository.startsWith("https://git.internal/")'
+    severity: low
+`
+
+func gitResult(detector detectorspb.DetectorType, verified bool, file string) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceType: sourcespb.SourceType_SOURCE_TYPE_GIT,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{File: file, Line: 3, Repository: "https://github.com/org/repo"},
+			},
+		},
+		Result: detectors.Result{DetectorType: detector, Verified: verified},
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	p, err := New([]byte(testPolicy))
+	require.NoError(t, err)
+	p.Branch = "main"
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		result *detectors.ResultWithMetadata
+		want   Decision
+	}{
+		{
+			name:   "unverified in test path",
+			result: gitResult(detectorspb.DetectorType_AWS, false, "pkg/testdata/creds"),
+			want:   Decision{Rule: "test-fixtures", Action: ActionIgnore},
+		},
+		{
+			name:   "verified in test path",
+			result: gitResult(detectorspb.DetectorType_AWS, true, "pkg/testdata/creds"),
+			want:   Decision{Rule: "verified-cloud-on-main", Action: ActionFail, Severity: "critical"},
+		},
+		{
+			name:   "verified other detector",
+			result: gitResult(detectorspb.DetectorType_Slack, true, "main.go"),
+			want:   Decision{Action: ActionReport},
+		},
+		{
+			name: "metadata",
+			result: &detectors.ResultWithMetadata{
+				SourceMetadata: &source_metadatapb.MetaData{
+					Data: &source_metadatapb.MetaData_Git{
+						Git: &source_metadatapb.Git{Repository: "https://git.internal/org/repo"},
+					},
+				},
+			},
+			want: Decision{Rule: "internal-repos", Action: ActionReport, Severity: "low"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, p.Evaluate(ctx, tt.result))
+		})
+	}
+}
+
+func TestPolicy_Vars(t *testing.T) {
+	p := &Policy{Branch: "dev"}
+	vars := p.vars(gitResult(detectorspb.DetectorType_AWS, true, "a/b.go"))
+	assert.Equal(t, "AWS", vars["detector"])
+	assert.Equal(t, "git", vars["source_type"])
+	assert.Equal(t, "a/b.go", vars["file"])
+	assert.Equal(t, int64(3), vars["line"])
+	assert.Equal(t, "https://github.com/org/repo", vars["repository"])
+	assert.Equal(t, "dev", vars["branch"])
+	assert.Equal(t, "", vars["archive_path"])
+	assert.Equal(t, int64(0), vars["archive_depth"])
+
+	r := gitResult(detectorspb.DetectorType_AWS, true, "release.zip")
+	r.SourceMetadata.GetGit().ArchivePath = "lib/app.jar!/application.yml"
+	vars = p.vars(r)
+	assert.Equal(t, "lib/app.jar!/application.yml", vars["archive_path"])
+	assert.Equal(t, int64(2), vars["archive_depth"])
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := map[string]string{
+		"unknown action": "rules: [{when: verified, action: block}]",
+		"not a bool":     "rules: [{when: detector}]",
+		"syntax":         "rules: [{when: 'verified &&'}]",
+		"unknown var":    "rules: [{when: secret == 'x'}]",
+		"unknown field":  "rules: [{when: verified, severity: high, level: 1}]",
+	}
+	for name, policy := range tests {
+		_, err := New([]byte(policy))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestDecision_Label(t *testing.T) {
+	r := &detectors.ResultWithMetadata{Result: detectors.Result{ExtraData: map[string]string{"account": "1"}}}
+	Decision{Action: ActionReport}.Label(r)
+	assert.Equal(t, map[string]string{"account": "1"}, r.ExtraData)
+
+	Decision{Rule: "cloud", Action: ActionFail, Severity: "high"}.Label(r)
+	assert.Equal(t, map[string]string{"account": "1", RuleKey: "cloud", SeverityKey: "high"}, r.ExtraData)
+}
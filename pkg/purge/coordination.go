@@ -0,0 +1,164 @@
+package purge
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/google/go-github/v42/github"
+	"golang.org/x/oauth2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+// Coordination lists what still contains the secrets after the history of the
+// local repository is rewritten.
+type Coordination struct {
+	// Refs are the local refs, including remote-tracking branches, that
+	// contain the commits of the secrets and need to be force-pushed.
+	Refs []string
+	// Forks are the URLs of the forks of the GitHub repository of the
+	// origin remote, which keep their own copy of the history.
+	Forks []string
+	// ForksNotChecked is whether the origin remote is a GitHub repository
+	// whose forks were not listed, e.g. with --offline.
+	ForksNotChecked bool
+	// MissingCommits are commits of the report that are not in the
+	// repository.
+	MissingCommits []string
+}
+
+// FindRefs fills in the refs that contain the commits of the plan.
+func (c *Coordination) FindRefs(ctx context.Context, plan *Plan) error {
+	refs := make(map[string]struct{})
+	for _, commit := range plan.Commits {
+		if err := exec.CommandContext(ctx, "git", "-C", plan.RepoPath, "cat-file", "-e", commit+"^{commit}").Run(); err != nil {
+			c.MissingCommits = append(c.MissingCommits, commit)
+			continue
+		}
+		out, err := exec.CommandContext(ctx, "git", "-C", plan.RepoPath, "for-each-ref", "--format=%(refname)", "--contains", commit).Output()
+		if err != nil {
+			return fmt.Errorf("could not list the refs containing %s: %w", commit, err)
+		}
+		for _, ref := range strings.Fields(string(out)) {
+			refs[ref] = struct{}{}
+		}
+	}
+	c.Refs = sortedKeys(refs)
+	return nil
+}
+
+// NewGitHubClient returns a github.com API client, authenticated with token
+// unless it is empty.
+func NewGitHubClient(token string) *github.Client {
+	httpClient := common.SaneHttpClient()
+	if token != "" {
+		httpClient.Transport = &oauth2.Transport{
+			Base:   httpClient.Transport,
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}),
+		}
+	}
+	return github.NewClient(httpClient)
+}
+
+// FindForks fills in the forks of the GitHub repository of the origin remote.
+// Repositories hosted elsewhere have no forks.
+func (c *Coordination) FindForks(ctx context.Context, plan *Plan, client *github.Client) error {
+	owner, repo, ok := originRepository(ctx, plan)
+	if !ok {
+		return nil
+	}
+	opts := &github.RepositoryListForksOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		forks, res, err := client.Repositories.ListForks(ctx, owner, repo, opts)
+		if err != nil {
+			return fmt.Errorf("could not list the forks of %s/%s: %w", owner, repo, err)
+		}
+		for _, fork := range forks {
+			c.Forks = append(c.Forks, fork.GetHTMLURL())
+		}
+		if res.NextPage == 0 {
+			return nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// SkipForks records that the forks of the GitHub repository of the origin
+// remote, if it has one, were not listed, so the report asks to check them.
+func (c *Coordination) SkipForks(ctx context.Context, plan *Plan) {
+	_, _, c.ForksNotChecked = originRepository(ctx, plan)
+}
+
+// originRepository returns the owner and name of the GitHub repository of the
+// origin remote, if there is one.
+func originRepository(ctx context.Context, plan *Plan) (string, string, bool) {
+	out, err := exec.CommandContext(ctx, "git", "-C", plan.RepoPath, "remote", "get-url", "origin").Output()
+	if err != nil {
+		// No origin remote.
+		return "", "", false
+	}
+	return githubRepository(strings.TrimSpace(string(out)))
+}
+
+// githubRepository returns the owner and name of a github.com repository URL.
+func githubRepository(remote string) (string, string, bool) {
+	var path string
+	if rest, ok := strings.CutPrefix(remote, "git@github.com:"); ok {
+		path = rest
+	} else {
+		u, err := url.Parse(remote)
+		if err != nil || !strings.EqualFold(u.Hostname(), "github.com") {
+			return "", "", false
+		}
+		path = u.Path
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	owner, repo, ok := strings.Cut(path, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", false
+	}
+	return owner, repo, true
+}
+
+// WriteReport explains how to rewrite the history with the plan, and what to
+// coordinate afterwards.
+func WriteReport(w io.Writer, plan *Plan, c *Coordination, expressionsPath string, executed bool) {
+	fmt.Fprintf(w, "Found %d secrets in %d commits", len(plan.Secrets), len(plan.Commits))
+	if len(plan.Paths) > 0 {
+		fmt.Fprintf(w, ", %d files will be removed", len(plan.Paths))
+	}
+	fmt.Fprintln(w, ".")
+	fmt.Fprintln(w, "Rewriting history does not make leaked secrets safe, revoke them first.")
+	if len(plan.Secrets) > 0 {
+		fmt.Fprintf(w, "\nThe replacements are in %s. It contains the secrets, delete it once you are done.\n", expressionsPath)
+	}
+	if !executed {
+		fmt.Fprintf(w, "\nRewrite the history with git filter-repo:\n  git %s\n", strings.Join(plan.FilterRepoArgs(expressionsPath, false), " "))
+	}
+	if len(c.MissingCommits) > 0 {
+		fmt.Fprintf(w, "\nThese commits are not in %s, scan the repository they are from:\n", plan.RepoPath)
+		for _, commit := range c.MissingCommits {
+			fmt.Fprintf(w, "  %s\n", commit)
+		}
+	}
+	if len(c.Refs) > 0 {
+		fmt.Fprintln(w, "\nThen force-push these refs, and ask everyone with a clone to clone again:")
+		for _, ref := range c.Refs {
+			fmt.Fprintf(w, "  %s\n", ref)
+		}
+	}
+	if len(c.Forks) > 0 {
+		fmt.Fprintln(w, "\nThese forks keep their own copy of the history. Ask their owners to delete or rewrite them:")
+		for _, fork := range c.Forks {
+			fmt.Fprintf(w, "  %s\n", fork)
+		}
+	}
+	if c.ForksNotChecked {
+		fmt.Fprintln(w, "\nThe forks of the GitHub repository were not checked offline. Forks keep their own copy of the history, ask their owners to delete or rewrite them.")
+	}
+	fmt.Fprintln(w, "\nHosts may keep the old commits reachable from pull requests and caches, e.g. contact GitHub Support to purge them.")
+}
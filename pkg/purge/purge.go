@@ -0,0 +1,169 @@
+// Package purge plans rewriting the history of a git repository with git
+// filter-repo to remove the secrets found by a scan, and finds the refs and
+// forks that still contain them.
+package purge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/tui/triage"
+)
+
+// Replacement is what secrets are replaced with in the rewritten history.
+const Replacement = "***REMOVED***"
+
+// minSecretLength is the length below which a secret, or a line of a multiline
+// secret, is too likely to occur in unrelated content to be replaced.
+const minSecretLength = 8
+
+// Options configure a Plan.
+type Options struct {
+	// RepoPath is the repository to rewrite.
+	RepoPath string
+	// RemovePaths removes the files the secrets were found in from the
+	// history, in addition to replacing the secrets.
+	RemovePaths bool
+}
+
+// Plan describes how to rewrite the history of a repository to remove the
+// secrets of a report.
+type Plan struct {
+	RepoPath string
+	// Secrets are replaced with Replacement in every blob of the history.
+	Secrets []string
+	// Paths are removed from the history.
+	Paths []string
+	// Commits are the commits the secrets were found in.
+	Commits []string
+}
+
+// NewPlan returns a plan removing the secrets of findings. The findings must
+// come from a report written without --redact.
+func NewPlan(findings []triage.Finding, opts Options) (*Plan, error) {
+	plan := &Plan{RepoPath: opts.RepoPath}
+	secrets, paths, commits := make(map[string]struct{}), make(map[string]struct{}), make(map[string]struct{})
+	for _, f := range findings {
+		if redacted(f.Raw) {
+			return nil, fmt.Errorf("the secret of %s at %s is redacted, scan again without --redact", f.DetectorType, f.Location())
+		}
+		for _, secret := range replaceable(f) {
+			secrets[secret] = struct{}{}
+		}
+		if file := f.File(); opts.RemovePaths && file != "" {
+			paths[filepath.ToSlash(file)] = struct{}{}
+		}
+		if commit := f.Commit(); commit != "" {
+			commits[commit] = struct{}{}
+		}
+	}
+	if len(secrets) == 0 && len(paths) == 0 {
+		return nil, errors.New("no secrets to purge")
+	}
+	plan.Secrets, plan.Paths, plan.Commits = sortedKeys(secrets), sortedKeys(paths), sortedKeys(commits)
+	return plan, nil
+}
+
+// redacted reports whether raw was redacted by the RedactMode of the output
+// package.
+func redacted(raw string) bool {
+	return strings.Contains(raw, "********") || strings.HasPrefix(raw, "sha256:")
+}
+
+// replaceable returns the literal strings to replace to remove the secret of
+// the finding. git filter-repo replaces text line by line, so multiline
+// secrets like private keys are replaced line by line, except for their
+// BEGIN and END markers.
+func replaceable(f triage.Finding) []string {
+	parts := []string{f.Raw}
+	// Multi-part secrets, like AWS keys, have the identifier in Raw followed
+	// by the secret in RawV2.
+	if secret, ok := strings.CutPrefix(f.RawV2, f.Raw); ok && secret != "" {
+		parts = append(parts, secret)
+	}
+	var out []string
+	for _, part := range parts {
+		for _, line := range strings.Split(part, "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) < minSecretLength || strings.HasPrefix(line, "-----") {
+				continue
+			}
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// Expressions returns the contents of the --replace-text file of git
+// filter-repo, which replaces every secret with Replacement.
+func (p *Plan) Expressions() string {
+	var b strings.Builder
+	for _, secret := range p.Secrets {
+		fmt.Fprintf(&b, "literal:%s==>%s\n", secret, Replacement)
+	}
+	return b.String()
+}
+
+// WriteExpressions writes the --replace-text file of git filter-repo to the
+// git directory of the repository, where it can't be committed by accident,
+// and returns its path.
+func (p *Plan) WriteExpressions(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", p.RepoPath, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not a git repository: %w", p.RepoPath, err)
+	}
+	path := filepath.Join(strings.TrimSpace(string(out)), "trufflehog-purge-expressions.txt")
+	if err := os.WriteFile(path, []byte(p.Expressions()), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// FilterRepoArgs returns the arguments of git to rewrite the history with the
+// --replace-text file at expressionsPath.
+func (p *Plan) FilterRepoArgs(expressionsPath string, force bool) []string {
+	args := []string{"-C", p.RepoPath, "filter-repo"}
+	if len(p.Secrets) > 0 {
+		args = append(args, "--replace-text", expressionsPath)
+	}
+	if len(p.Paths) > 0 {
+		args = append(args, "--invert-paths")
+		for _, path := range p.Paths {
+			args = append(args, "--path", path)
+		}
+	}
+	if force {
+		args = append(args, "--force")
+	}
+	return args
+}
+
+// Execute rewrites the history of the repository with git filter-repo, which
+// must be installed.
+func (p *Plan) Execute(ctx context.Context, expressionsPath string, force bool, stdout, stderr io.Writer) error {
+	if _, err := exec.LookPath("git-filter-repo"); err != nil {
+		return errors.New("git filter-repo is not installed, see https://github.com/newren/git-filter-repo/blob/main/INSTALL.md")
+	}
+	cmd := exec.CommandContext(ctx, "git", p.FilterRepoArgs(expressionsPath, force)...)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git filter-repo failed: %w", err)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
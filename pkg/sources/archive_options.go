@@ -0,0 +1,119 @@
+package sources
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ArchiveOptions configures how the archives a source scans are extracted, so
+// sources scanned at the same time can extract them differently, e.g. S3
+// buckets of build artifacts to a greater depth than git repositories. Zero
+// fields take the defaults of the archive handler, which are set from the
+// command line.
+type ArchiveOptions struct {
+	// MaxDepth is the maximum depth of nested archives extracted.
+	MaxDepth int
+	// MaxSize is the maximum size of the files extracted from an archive.
+	MaxSize int
+	// MaxTimeout is the maximum time spent extracting a file of an archive,
+	// which starts over each time another MiB of the file is read. Files
+	// that take longer are abandoned, and the files after them are still
+	// extracted. Archives none of whose files are read for MaxTimeout are
+	// abandoned.
+	MaxTimeout time.Duration
+	// MaxCompressionRatio is the largest ratio of the decompressed to the
+	// compressed size of a file an archive is extracted past, so archives
+	// crafted to decompress to far more than their size, like zip bombs,
+	// are abandoned early. Negative ratios turn the check off.
+	MaxCompressionRatio float64
+	// Concurrency is the number of files of an archive extracted at the same
+	// time. The files of zip and 7z archives and the small files of tar
+	// archives are extracted concurrently if it is more than one.
+	Concurrency int
+	// Passwords are tried on encrypted zip and 7z archives.
+	Passwords []string
+	// SkipFormats are the formats of archives that are scanned as they are
+	// rather than extracted, by their usual extension without the dot, e.g.
+	// zip, tar, gz, 7z, deb, rpm, iso9660 or vmdk. Compressed tarballs are
+	// skipped if either of their formats is.
+	SkipFormats []string
+	// IncludeMembers and ExcludeMembers are globs of the paths of files in
+	// archives, e.g. node_modules/** or *.png. Only the files that match an
+	// include glob, if there are any, and no exclude glob are extracted. See
+	// MemberFilter for how paths are matched.
+	IncludeMembers []string
+	ExcludeMembers []string
+	// ChunkOverlap is how much of the next chunk is scanned with each chunk
+	// of the large files and archive members the source reads, so secrets
+	// spanning a chunk boundary are found. Zero takes PeekSize.
+	ChunkOverlap int
+}
+
+// ChunkOptions returns the options of the chunk readers of the files the
+// source reads.
+func (o ArchiveOptions) ChunkOptions() []ConfigOption {
+	if o.ChunkOverlap <= 0 {
+		return nil
+	}
+	return []ConfigOption{WithPeekSize(o.ChunkOverlap)}
+}
+
+// SkipsFormat reports whether archives of a format, named by its usual
+// extension with or without the dot, like .tar.gz, are not extracted.
+func (o ArchiveOptions) SkipsFormat(format string) bool {
+	for _, part := range strings.Split(strings.TrimPrefix(format, "."), ".") {
+		for _, skipped := range o.SkipFormats {
+			if strings.EqualFold(strings.TrimPrefix(skipped, "."), part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MemberFilter decides which files of archives are extracted, from include
+// and exclude globs of their path in the archive. * matches any part of a
+// path segment and ** any number of segments. Globs match the whole path or
+// any trailing part of it starting at a directory, so *.png matches
+// img/logo.png and node_modules/** matches package/node_modules/a/index.js.
+type MemberFilter struct {
+	include, exclude Globs
+}
+
+// NewMemberFilter returns a filter of the include and exclude globs. Invalid
+// globs are left out of the filter, and returned as an error.
+func NewMemberFilter(include, exclude []string) (*MemberFilter, error) {
+	includeGlobs, includeErr := CompileGlobs(include, '/')
+	excludeGlobs, excludeErr := CompileGlobs(exclude, '/')
+	f := &MemberFilter{include: includeGlobs, exclude: excludeGlobs}
+	return f, errors.Join(includeErr, excludeErr)
+}
+
+// Includes reports whether the file at a path in an archive is extracted.
+func (f *MemberFilter) Includes(path string) bool {
+	if f == nil {
+		return true
+	}
+	if matchesMember(f.exclude, path) {
+		return false
+	}
+	return len(f.include) == 0 || matchesMember(f.include, path)
+}
+
+func matchesMember(globs Globs, path string) bool {
+	if len(globs) == 0 {
+		return false
+	}
+	path = strings.TrimSuffix(path, "/")
+	for {
+		if globs.Match(path) {
+			return true
+		}
+		i := strings.IndexByte(path, '/')
+		if i < 0 {
+			return false
+		}
+		path = path[i+1:]
+	}
+}
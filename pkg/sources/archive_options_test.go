@@ -0,0 +1,48 @@
+package sources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveOptions_SkipsFormat(t *testing.T) {
+	opts := ArchiveOptions{SkipFormats: []string{"gz", ".VMDK"}}
+	assert.True(t, opts.SkipsFormat(".gz"))
+	assert.True(t, opts.SkipsFormat(".tar.gz"))
+	assert.True(t, opts.SkipsFormat("vmdk"))
+	assert.False(t, opts.SkipsFormat(".tar"))
+	assert.False(t, opts.SkipsFormat(""))
+	assert.False(t, ArchiveOptions{}.SkipsFormat(".zip"))
+}
+
+func TestArchiveOptions_ChunkOptions(t *testing.T) {
+	assert.Nil(t, ArchiveOptions{}.ChunkOptions())
+	assert.Equal(t, PeekSize, applyOptions(ArchiveOptions{}.ChunkOptions()).peekSize)
+	assert.Equal(t, 64, applyOptions(ArchiveOptions{ChunkOverlap: 64}.ChunkOptions()).peekSize)
+}
+
+func TestMemberFilter(t *testing.T) {
+	filter, err := NewMemberFilter(nil, []string{"node_modules/**", "*.png"})
+	require.NoError(t, err)
+	assert.False(t, filter.Includes("node_modules/lodash/index.js"))
+	assert.False(t, filter.Includes("package/node_modules/lodash/index.js"))
+	assert.False(t, filter.Includes("img/logo.png"))
+	assert.True(t, filter.Includes("package/index.js"))
+	assert.True(t, filter.Includes("my_node_modules/index.js"))
+
+	filter, err = NewMemberFilter([]string{"*.yaml", "config/**"}, []string{"*.test.yaml"})
+	require.NoError(t, err)
+	assert.True(t, filter.Includes("app/values.yaml"))
+	assert.True(t, filter.Includes("config/app.properties"))
+	assert.False(t, filter.Includes("app/values.test.yaml"))
+	assert.False(t, filter.Includes("app/main.go"))
+
+	filter, err = NewMemberFilter(nil, []string{"[", "*.png"})
+	assert.Error(t, err)
+	assert.False(t, filter.Includes("logo.png"))
+
+	var none *MemberFilter
+	assert.True(t, none.Includes("logo.png"))
+}
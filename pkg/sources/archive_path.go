@@ -0,0 +1,241 @@
+package sources
+
+import (
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// ArchivePathSeparator separates the paths of nested archives in archive
+// paths, like in jar URLs, e.g. lib/app.jar!/config/application.yml.
+const ArchivePathSeparator = "!/"
+
+// SetArchivePath sets the path of the file in the archive that data of the
+// metadata was extracted from, for the sources that scan archives. It reports
+// whether the metadata has an archive path.
+func SetArchivePath(metadata *source_metadatapb.MetaData, path string) bool {
+	switch metadata := metadata.GetData().(type) {
+	case *source_metadatapb.MetaData_Filesystem:
+		metadata.Filesystem.ArchivePath = path
+	case *source_metadatapb.MetaData_S3:
+		metadata.S3.ArchivePath = path
+	case *source_metadatapb.MetaData_Gcs:
+		metadata.Gcs.ArchivePath = path
+	case *source_metadatapb.MetaData_Git:
+		metadata.Git.ArchivePath = path
+	case *source_metadatapb.MetaData_Github:
+		metadata.Github.ArchivePath = path
+	case *source_metadatapb.MetaData_Gitlab:
+		metadata.Gitlab.ArchivePath = path
+	case *source_metadatapb.MetaData_Bitbucket:
+		metadata.Bitbucket.ArchivePath = path
+	case *source_metadatapb.MetaData_Gerrit:
+		metadata.Gerrit.ArchivePath = path
+	case *source_metadatapb.MetaData_Imap:
+		metadata.Imap.ArchivePath = path
+	case *source_metadatapb.MetaData_Helpdesk:
+		metadata.Helpdesk.ArchivePath = path
+	case *source_metadatapb.MetaData_Servicenow:
+		metadata.Servicenow.ArchivePath = path
+	case *source_metadatapb.MetaData_Notion:
+		metadata.Notion.ArchivePath = path
+	case *source_metadatapb.MetaData_Tracker:
+		metadata.Tracker.ArchivePath = path
+	case *source_metadatapb.MetaData_Discord:
+		metadata.Discord.ArchivePath = path
+	default:
+		return false
+	}
+	return true
+}
+
+// SetImageLayer sets the image layer that data of the metadata was extracted
+// from, for the sources that scan archives. It reports whether the metadata
+// has an image layer.
+func SetImageLayer(metadata *source_metadatapb.MetaData, layer *source_metadatapb.ImageLayer) bool {
+	switch metadata := metadata.GetData().(type) {
+	case *source_metadatapb.MetaData_Filesystem:
+		metadata.Filesystem.ImageLayer = layer
+	case *source_metadatapb.MetaData_S3:
+		metadata.S3.ImageLayer = layer
+	case *source_metadatapb.MetaData_Gcs:
+		metadata.Gcs.ImageLayer = layer
+	case *source_metadatapb.MetaData_Git:
+		metadata.Git.ImageLayer = layer
+	case *source_metadatapb.MetaData_Github:
+		metadata.Github.ImageLayer = layer
+	case *source_metadatapb.MetaData_Gitlab:
+		metadata.Gitlab.ImageLayer = layer
+	case *source_metadatapb.MetaData_Bitbucket:
+		metadata.Bitbucket.ImageLayer = layer
+	case *source_metadatapb.MetaData_Gerrit:
+		metadata.Gerrit.ImageLayer = layer
+	default:
+		return false
+	}
+	return true
+}
+
+// SetPackedCommit sets the commit of the git bundle or packfile that data of
+// the metadata was extracted from, for the sources that scan archives. It
+// reports whether the metadata has a packed commit.
+func SetPackedCommit(metadata *source_metadatapb.MetaData, commit *source_metadatapb.PackedCommit) bool {
+	switch metadata := metadata.GetData().(type) {
+	case *source_metadatapb.MetaData_Filesystem:
+		metadata.Filesystem.PackedCommit = commit
+	case *source_metadatapb.MetaData_S3:
+		metadata.S3.PackedCommit = commit
+	case *source_metadatapb.MetaData_Gcs:
+		metadata.Gcs.PackedCommit = commit
+	case *source_metadatapb.MetaData_Git:
+		metadata.Git.PackedCommit = commit
+	case *source_metadatapb.MetaData_Github:
+		metadata.Github.PackedCommit = commit
+	case *source_metadatapb.MetaData_Gitlab:
+		metadata.Gitlab.PackedCommit = commit
+	case *source_metadatapb.MetaData_Bitbucket:
+		metadata.Bitbucket.PackedCommit = commit
+	case *source_metadatapb.MetaData_Gerrit:
+		metadata.Gerrit.PackedCommit = commit
+	default:
+		return false
+	}
+	return true
+}
+
+// SetHelmChart sets the Helm chart that data of the metadata was extracted
+// from, for the sources that scan archives. It reports whether the metadata
+// has a Helm chart.
+func SetHelmChart(metadata *source_metadatapb.MetaData, chart *source_metadatapb.HelmChart) bool {
+	switch metadata := metadata.GetData().(type) {
+	case *source_metadatapb.MetaData_Filesystem:
+		metadata.Filesystem.HelmChart = chart
+	case *source_metadatapb.MetaData_S3:
+		metadata.S3.HelmChart = chart
+	case *source_metadatapb.MetaData_Gcs:
+		metadata.Gcs.HelmChart = chart
+	case *source_metadatapb.MetaData_Git:
+		metadata.Git.HelmChart = chart
+	case *source_metadatapb.MetaData_Github:
+		metadata.Github.HelmChart = chart
+	case *source_metadatapb.MetaData_Gitlab:
+		metadata.Gitlab.HelmChart = chart
+	case *source_metadatapb.MetaData_Bitbucket:
+		metadata.Bitbucket.HelmChart = chart
+	case *source_metadatapb.MetaData_Gerrit:
+		metadata.Gerrit.HelmChart = chart
+	default:
+		return false
+	}
+	return true
+}
+
+// SetPackage sets the package of a package registry that data of the
+// metadata was extracted from, for the sources that scan archives. It reports
+// whether the metadata has a package.
+func SetPackage(metadata *source_metadatapb.MetaData, pkg *source_metadatapb.Package) bool {
+	switch metadata := metadata.GetData().(type) {
+	case *source_metadatapb.MetaData_Filesystem:
+		metadata.Filesystem.Package = pkg
+	case *source_metadatapb.MetaData_S3:
+		metadata.S3.Package = pkg
+	case *source_metadatapb.MetaData_Gcs:
+		metadata.Gcs.Package = pkg
+	case *source_metadatapb.MetaData_Git:
+		metadata.Git.Package = pkg
+	case *source_metadatapb.MetaData_Github:
+		metadata.Github.Package = pkg
+	case *source_metadatapb.MetaData_Gitlab:
+		metadata.Gitlab.Package = pkg
+	case *source_metadatapb.MetaData_Bitbucket:
+		metadata.Bitbucket.Package = pkg
+	case *source_metadatapb.MetaData_Gerrit:
+		metadata.Gerrit.Package = pkg
+	default:
+		return false
+	}
+	return true
+}
+
+// ArchivePath returns the path of the file in the archive that data of the
+// metadata was extracted from, or "" if it was not extracted from an archive.
+func ArchivePath(metadata *source_metadatapb.MetaData) string {
+	switch metadata := metadata.GetData().(type) {
+	case *source_metadatapb.MetaData_Filesystem:
+		return metadata.Filesystem.GetArchivePath()
+	case *source_metadatapb.MetaData_S3:
+		return metadata.S3.GetArchivePath()
+	case *source_metadatapb.MetaData_Gcs:
+		return metadata.Gcs.GetArchivePath()
+	case *source_metadatapb.MetaData_Git:
+		return metadata.Git.GetArchivePath()
+	case *source_metadatapb.MetaData_Github:
+		return metadata.Github.GetArchivePath()
+	case *source_metadatapb.MetaData_Gitlab:
+		return metadata.Gitlab.GetArchivePath()
+	case *source_metadatapb.MetaData_Bitbucket:
+		return metadata.Bitbucket.GetArchivePath()
+	case *source_metadatapb.MetaData_Gerrit:
+		return metadata.Gerrit.GetArchivePath()
+	case *source_metadatapb.MetaData_Imap:
+		return metadata.Imap.GetArchivePath()
+	case *source_metadatapb.MetaData_Helpdesk:
+		return metadata.Helpdesk.GetArchivePath()
+	case *source_metadatapb.MetaData_Servicenow:
+		return metadata.Servicenow.GetArchivePath()
+	case *source_metadatapb.MetaData_Notion:
+		return metadata.Notion.GetArchivePath()
+	case *source_metadatapb.MetaData_Tracker:
+		return metadata.Tracker.GetArchivePath()
+	case *source_metadatapb.MetaData_Discord:
+		return metadata.Discord.GetArchivePath()
+	default:
+		return ""
+	}
+}
+
+// ArchiveDepth returns how many archives the file of the archive path of the
+// metadata is nested in: 1 for a file in an archive, 2 for a file in an
+// archive in an archive, and 0 if it was not extracted from an archive.
+func ArchiveDepth(metadata *source_metadatapb.MetaData) int {
+	path := ArchivePath(metadata)
+	if path == "" {
+		return 0
+	}
+	return strings.Count(path, ArchivePathSeparator) + 1
+}
+
+// ArchiveFile returns the file of the metadata followed by the archive path,
+// e.g. release.zip!/lib/app.jar!/application.yml, if it was extracted from an
+// archive, or file otherwise.
+func ArchiveFile(metadata *source_metadatapb.MetaData, file string) string {
+	if path := ArchivePath(metadata); path != "" {
+		return file + ArchivePathSeparator + path
+	}
+	return file
+}
+
+// MetadataFile returns the file in the metadata of sources that have files,
+// or an empty string.
+func MetadataFile(metadata *source_metadatapb.MetaData) string {
+	switch metadata := metadata.GetData().(type) {
+	case *source_metadatapb.MetaData_Git:
+		return metadata.Git.File
+	case *source_metadatapb.MetaData_Github:
+		return metadata.Github.File
+	case *source_metadatapb.MetaData_Gitlab:
+		return metadata.Gitlab.File
+	case *source_metadatapb.MetaData_Bitbucket:
+		return metadata.Bitbucket.File
+	case *source_metadatapb.MetaData_Gerrit:
+		return metadata.Gerrit.File
+	case *source_metadatapb.MetaData_Filesystem:
+		return metadata.Filesystem.File
+	case *source_metadatapb.MetaData_S3:
+		return metadata.S3.File
+	case *source_metadatapb.MetaData_Gcs:
+		return metadata.Gcs.Filename
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,396 @@
+package artifactory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultMaxObjectSize = 250 * 1024 * 1024 // 250 MiB
+	maxObjectSizeLimit   = 250 * 1024 * 1024 // 250 MiB
+)
+
+// Source scans the artifacts of the local repositories of a JFrog
+// Artifactory instance.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn          *sourcespb.Artifactory
+	endpoint      string
+	client        *http.Client
+	repositories  sources.Globs
+	include       sources.Globs
+	exclude       sources.Globs
+	maxObjectSize int64
+	checksums     *sources.Checksums
+
+	// archiveOptions configures how the archives of artifacts are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// repository is a repository of the API.
+type repository struct {
+	Key  string `json:"key"`
+	Type string `json:"type"`
+}
+
+// artifact is a file of the deep listing of a repository, whose URI is its
+// path in the repository.
+type artifact struct {
+	URI          string `json:"uri"`
+	Size         int64  `json:"size"`
+	LastModified string `json:"lastModified"`
+	Folder       bool   `json:"folder"`
+	SHA1         string `json:"sha1"`
+	SHA2         string `json:"sha2"`
+}
+
+// checksum returns the strongest checksum of an artifact.
+func (a artifact) checksum() string {
+	switch {
+	case a.SHA2 != "":
+		return "sha256:" + a.SHA2
+	case a.SHA1 != "":
+		return "sha1:" + a.SHA1
+	default:
+		return ""
+	}
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_JFROG_ARTIFACTORY
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of artifacts are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized Artifactory source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Artifactory
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	u, err := url.Parse(conn.GetEndpoint())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid endpoint %q, it should be the URL of Artifactory like https://example.jfrog.io/artifactory", conn.GetEndpoint())
+	}
+	s.endpoint = strings.TrimSuffix(conn.GetEndpoint(), "/")
+	tlsConfig := common.TLSClientConfig()
+	tlsConfig.InsecureSkipVerify = conn.GetInsecure()
+	s.client = common.RetryableHttpClientTLS(120, tlsConfig)
+
+	if s.repositories, err = sources.CompileGlobs(conn.GetRepositories(), '/'); err != nil {
+		return err
+	}
+	if s.include, err = sources.CompileGlobs(conn.GetIncludePaths(), '/'); err != nil {
+		return err
+	}
+	if s.exclude, err = sources.CompileGlobs(conn.GetExcludePaths(), '/'); err != nil {
+		return err
+	}
+
+	s.maxObjectSize = conn.GetMaxObjectSize()
+	if s.maxObjectSize <= 0 || s.maxObjectSize > maxObjectSizeLimit {
+		s.maxObjectSize = defaultMaxObjectSize
+	}
+	return nil
+}
+
+// Chunks emits the artifacts of the repositories as chunks. Artifacts whose
+// checksum was already scanned, in this scan or in those of the state
+// path, are skipped.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	checksums, err := sources.LoadChecksums(s.conn.GetStatePath())
+	if err != nil {
+		return err
+	}
+	s.checksums = checksums
+
+	repositories, err := s.listRepositories(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, repo := range repositories {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(repositories), fmt.Sprintf("Repository: %s", repo), "")
+		artifacts, err := s.listArtifacts(ctx, repo)
+		if errors.Is(err, errUnavailable) {
+			ctx.Logger().V(2).Info("could not list repository", "repository", repo, "error", err)
+			sources.ReportSkip(ctx, repo, sources.SkipReasonError)
+			continue
+		}
+		if err != nil {
+			_ = s.jobPool.Wait()
+			return err
+		}
+		for _, a := range artifacts {
+			repo, a := repo, a
+			s.jobPool.Go(func() error {
+				if err := s.scanArtifact(ctx, repo, a, chunksChan); err != nil {
+					sources.ReportSkip(ctx, repo+a.URI, sources.SkipReasonError)
+					scanErrs.Add(fmt.Errorf("error scanning artifact %s%s: %w", repo, a.URI, err))
+				}
+				return nil
+			})
+		}
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return s.checksums.Save()
+}
+
+// EnumerateTargets reports each repository with the number of its
+// artifacts that would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	repositories, err := s.listRepositories(ctx)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repositories {
+		artifacts, err := s.listArtifacts(ctx, repo)
+		if err != nil && !errors.Is(err, errUnavailable) {
+			return err
+		}
+		var size int64
+		for _, a := range artifacts {
+			size += a.Size
+		}
+		report(sources.Target{Name: s.endpoint + "/" + repo, Objects: int64(len(artifacts)), Bytes: size})
+	}
+	return nil
+}
+
+// listRepositories returns the keys of the local and federated repositories
+// of the globs of the connection. Remote and virtual repositories only have
+// the artifacts of others.
+func (s *Source) listRepositories(ctx context.Context) ([]string, error) {
+	var repos []repository
+	if err := s.get(ctx, "/api/repositories", &repos); err != nil {
+		return nil, fmt.Errorf("error listing repositories: %w", err)
+	}
+	var keys []string
+	for _, repo := range repos {
+		switch strings.ToUpper(repo.Type) {
+		case "LOCAL", "FEDERATED":
+		default:
+			continue
+		}
+		if len(s.repositories) > 0 && !s.repositories.Match(repo.Key) {
+			continue
+		}
+		keys = append(keys, repo.Key)
+	}
+	return keys, nil
+}
+
+// listArtifacts returns the artifacts of a repository that the globs of
+// the connection leave.
+func (s *Source) listArtifacts(ctx context.Context, repo string) ([]artifact, error) {
+	var list struct {
+		Files []artifact `json:"files"`
+	}
+	if err := s.get(ctx, "/api/storage/"+url.PathEscape(repo)+"?list&deep=1&listFolders=0&mdTimestamps=0", &list); err != nil {
+		return nil, err
+	}
+	var artifacts []artifact
+	for _, a := range list.Files {
+		if a.Folder {
+			continue
+		}
+		p := strings.TrimPrefix(a.URI, "/")
+		if (len(s.include) > 0 && !s.include.Match(p)) || s.exclude.Match(p) {
+			continue
+		}
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, nil
+}
+
+// scanArtifact downloads an artifact and scans it through the handlers of
+// archives, unless its checksum was already scanned.
+func (s *Source) scanArtifact(ctx context.Context, repo string, a artifact, chunksChan chan *sources.Chunk) error {
+	location := repo + a.URI
+	switch {
+	case a.Size > s.maxObjectSize:
+		sources.ReportSkipBytes(ctx, location, sources.SkipReasonSize, a.Size)
+		return nil
+	case a.Size == 0:
+		sources.ReportSkip(ctx, location, sources.SkipReasonEmpty)
+		return nil
+	case common.SkipFile(a.URI):
+		sources.ReportSkipBytes(ctx, location, sources.SkipReasonUnsupported, a.Size)
+		return nil
+	}
+	sum := a.checksum()
+	if !s.checksums.Claim(sum) {
+		ctx.Logger().V(5).Info("skipping artifact already scanned", "artifact", location, "checksum", sum)
+		return nil
+	}
+
+	link := s.endpoint + "/" + url.PathEscape(repo) + escapePath(a.URI)
+	err := s.download(ctx, link, func(body io.Reader) error {
+		return s.scanReader(ctx, s.chunkSkeleton(repo, a, link), body, chunksChan)
+	})
+	if err != nil {
+		s.checksums.Forget(sum)
+	}
+	return err
+}
+
+func (s *Source) scanReader(ctx context.Context, skel *sources.Chunk, body io.Reader, chunksChan chan *sources.Chunk) error {
+	reader, err := diskbufferreader.New(io.LimitReader(body, s.maxObjectSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+
+	chunkReader := sources.NewChunkReader(s.archiveOptions.ChunkOptions()...)
+	for data := range chunkReader(ctx, reader) {
+		if err := data.Error(); err != nil {
+			return err
+		}
+		chunk := *skel
+		chunk.Data = data.Bytes()
+		chunk.Offset, chunk.LineStart, chunk.LineOffset = data.Offset(), data.LineStart(), data.LineOffset()
+		if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Source) chunkSkeleton(repo string, a artifact, link string) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Artifactory{
+				Artifactory: &source_metadatapb.Artifactory{
+					Repo:      repo,
+					Path:      sanitizer.UTF8(strings.TrimPrefix(a.URI, "/")),
+					Link:      sanitizer.UTF8(link),
+					Timestamp: a.LastModified,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// escapePath escapes the segments of the path of an artifact.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// errUnavailable is the error of the repositories that the credentials
+// cannot read, or that no longer exist.
+var errUnavailable = errors.New("unavailable")
+
+func (s *Source) get(ctx context.Context, path string, out any) error {
+	return s.download(ctx, s.endpoint+path, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(out)
+	})
+}
+
+// download requests a URL of the API and reads its body.
+func (s *Source) download(ctx context.Context, u string, read func(io.Reader) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	switch {
+	case s.conn.GetAccessToken() != "":
+		req.Header.Set("Authorization", "Bearer "+s.conn.GetAccessToken())
+	case s.conn.GetBasicAuth() != nil:
+		req.SetBasicAuth(s.conn.GetBasicAuth().GetUsername(), s.conn.GetBasicAuth().GetPassword())
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return read(resp.Body)
+	case http.StatusUnauthorized:
+		return fmt.Errorf("invalid credentials, status %d", resp.StatusCode)
+	case http.StatusForbidden, http.StatusNotFound:
+		return fmt.Errorf("%w: status %d", errUnavailable, resp.StatusCode)
+	default:
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,194 @@
+package artifactory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const testToken = "artifactory-token"
+
+// testRepositories are the files of the repositories of testServer.
+var testRepositories = map[string]map[string]string{
+	"libs-release-local": {
+		"/com/acme/app/1.0/app.properties": "db.password=hunter2",
+		"/com/acme/app/1.0/app.pom":        "<project/>",
+		"/com/acme/app/1.0/notes.txt":      "token AKIA",
+	},
+	"docker-local": {
+		"/app/latest/config.json": "db.password=hunter2",
+	},
+	"forbidden-local": {
+		"/secret.txt": "x",
+	},
+}
+
+// testServer is an Artifactory with local repositories, a remote one, and a
+// repository that the token cannot read. It records the downloads.
+type testServer struct {
+	mu        sync.Mutex
+	downloads []string
+}
+
+func (ts *testServer) start(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/artifactory")
+		switch {
+		case path == "/api/repositories":
+			_ = json.NewEncoder(w).Encode([]map[string]string{
+				{"key": "docker-local", "type": "LOCAL"},
+				{"key": "forbidden-local", "type": "LOCAL"},
+				{"key": "libs-release-local", "type": "LOCAL"},
+				{"key": "maven-remote", "type": "REMOTE"},
+			})
+		case strings.HasPrefix(path, "/api/storage/"):
+			repo := strings.TrimPrefix(path, "/api/storage/")
+			if repo == "forbidden-local" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			assert.Equal(t, "1", r.URL.Query().Get("deep"))
+			var files []map[string]any
+			for uri, content := range testRepositories[repo] {
+				sum := sha256.Sum256([]byte(content))
+				files = append(files, map[string]any{"uri": uri, "size": len(content), "folder": false, "sha2": hex.EncodeToString(sum[:]), "lastModified": "2024-01-02T03:04:05.000Z"})
+			}
+			files = append(files, map[string]any{"uri": "/com", "folder": true})
+			sort.Slice(files, func(i, j int) bool { return files[i]["uri"].(string) < files[j]["uri"].(string) })
+			_ = json.NewEncoder(w).Encode(map[string]any{"files": files})
+		default:
+			repo, file, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+			content, ok := testRepositories[repo]["/"+file]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			ts.mu.Lock()
+			ts.downloads = append(ts.downloads, repo+"/"+file)
+			ts.mu.Unlock()
+			_, _ = w.Write([]byte(content))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// chunks returns the data of the chunks of a source by repository and
+// path, and their metadata.
+func chunks(t *testing.T, conn *sourcespb.Artifactory) (map[string]string, map[string]*source_metadatapb.Artifactory) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+	return sources.ChunksByKey(sources.RequireChunks(t, s), (*source_metadatapb.MetaData).GetArtifactory, func(m *source_metadatapb.Artifactory) string {
+		return m.GetRepo() + "/" + m.GetPath()
+	})
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server := (&testServer{}).start(t)
+	got, metadata := chunks(t, &sourcespb.Artifactory{
+		Endpoint:     server.URL + "/artifactory/",
+		Credential:   &sourcespb.Artifactory_AccessToken{AccessToken: testToken},
+		ExcludePaths: []string{"**.pom"},
+	})
+	// The artifacts with the same checksum are only scanned once.
+	assert.Len(t, got, 2)
+	assert.Equal(t, "token AKIA", got["libs-release-local/com/acme/app/1.0/notes.txt"])
+	assert.Equal(t, &source_metadatapb.Artifactory{
+		Repo:      "libs-release-local",
+		Path:      "com/acme/app/1.0/notes.txt",
+		Link:      server.URL + "/artifactory/libs-release-local/com/acme/app/1.0/notes.txt",
+		Timestamp: "2024-01-02T03:04:05.000Z",
+	}, metadata["libs-release-local/com/acme/app/1.0/notes.txt"])
+}
+
+func TestSource_ChunksFilters(t *testing.T) {
+	server := (&testServer{}).start(t)
+	got, _ := chunks(t, &sourcespb.Artifactory{
+		Endpoint:     server.URL + "/artifactory",
+		Credential:   &sourcespb.Artifactory_AccessToken{AccessToken: testToken},
+		Repositories: []string{"libs-*"},
+		IncludePaths: []string{"com/acme/**.properties"},
+	})
+	assert.Equal(t, map[string]string{"libs-release-local/com/acme/app/1.0/app.properties": "db.password=hunter2"}, got)
+}
+
+func TestSource_ChunksState(t *testing.T) {
+	ts := &testServer{}
+	server := ts.start(t)
+	conn := &sourcespb.Artifactory{
+		Endpoint:   server.URL + "/artifactory",
+		Credential: &sourcespb.Artifactory_AccessToken{AccessToken: testToken},
+		StatePath:  filepath.Join(t.TempDir(), "state.json"),
+	}
+	got, _ := chunks(t, conn)
+	assert.Len(t, got, 3)
+	assert.Len(t, ts.downloads, 3)
+
+	// The next scan skips the artifacts that did not change.
+	got, _ = chunks(t, conn)
+	assert.Empty(t, got)
+	assert.Len(t, ts.downloads, 3)
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := (&testServer{}).start(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Artifactory{
+		Endpoint:   server.URL + "/artifactory",
+		Credential: &sourcespb.Artifactory_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: "admin", Password: "wrong"}},
+	}, 2)
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	assert.ErrorContains(t, err, "invalid credentials")
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	server := (&testServer{}).start(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Artifactory{
+		Endpoint:   server.URL + "/artifactory",
+		Credential: &sourcespb.Artifactory_AccessToken{AccessToken: testToken},
+	}, 2)
+	objects := make(map[string]int64)
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		objects[target.Name] = target.Objects
+	}))
+	assert.Equal(t, map[string]int64{
+		server.URL + "/artifactory/docker-local":       1,
+		server.URL + "/artifactory/forbidden-local":    0,
+		server.URL + "/artifactory/libs-release-local": 3,
+	}, objects)
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.Artifactory{
+		"no endpoint":  {},
+		"no scheme":    {Endpoint: "example.jfrog.io/artifactory"},
+		"invalid glob": {Endpoint: "https://example.jfrog.io/artifactory", IncludePaths: []string{"[a"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
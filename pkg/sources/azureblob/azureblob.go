@@ -0,0 +1,386 @@
+package azureblob
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultMaxObjectSize = 250 * 1024 * 1024 // 250 MiB
+	maxObjectSizeLimit   = 250 * 1024 * 1024 // 250 MiB
+
+	// storageResource is the resource of the access tokens of Azure Storage.
+	storageResource = "https://storage.azure.com/"
+)
+
+var (
+	// authorityHost is the host of the Microsoft identity platform, which
+	// issues the access tokens of service principals.
+	authorityHost = "https://login.microsoftonline.com"
+	// imdsEndpoint is where the instance metadata service of Azure issues
+	// the access tokens of managed identities.
+	imdsEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+)
+
+// Source scans the blobs of the containers of an Azure Storage account, and
+// their snapshots and previous versions.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn          *sourcespb.AzureStorage
+	client        *client
+	maxObjectSize int64
+	// archiveOptions configures how the archives of blobs are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_AZURE_STORAGE
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of blobs are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized Azure Blob Storage source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.AzureStorage
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	s.maxObjectSize = conn.GetMaxObjectSize()
+	if s.maxObjectSize <= 0 || s.maxObjectSize > maxObjectSizeLimit {
+		s.maxObjectSize = defaultMaxObjectSize
+	}
+
+	c, err := newClient(&conn)
+	if err != nil {
+		return err
+	}
+	s.client = c
+	return nil
+}
+
+// newClient returns a client of the blob service of the account of a
+// connection, with its credentials.
+func newClient(conn *sourcespb.AzureStorage) (*client, error) {
+	c := &client{
+		http:     common.RetryableHttpClientTimeout(120),
+		account:  conn.GetAccount(),
+		endpoint: strings.TrimSuffix(conn.GetEndpoint(), "/"),
+	}
+
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, c.http)
+	switch cred := conn.GetCredential().(type) {
+	case *sourcespb.AzureStorage_ConnectionString:
+		cs, err := parseConnectionString(cred.ConnectionString)
+		if err != nil {
+			return nil, err
+		}
+		c.account, c.sas = cs.account, cs.sas
+		if c.endpoint == "" {
+			c.endpoint = cs.endpoint
+		}
+		if cs.key != "" {
+			if c.key, err = base64.StdEncoding.DecodeString(cs.key); err != nil {
+				return nil, errors.WrapPrefix(err, "invalid account key", 0)
+			}
+		}
+	case *sourcespb.AzureStorage_BasicAuth:
+		c.account = cred.BasicAuth.GetUsername()
+		key, err := base64.StdEncoding.DecodeString(cred.BasicAuth.GetPassword())
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "invalid account key", 0)
+		}
+		c.key = key
+	case *sourcespb.AzureStorage_SasToken:
+		c.sas = strings.TrimPrefix(cred.SasToken, "?")
+	case *sourcespb.AzureStorage_ServicePrincipal:
+		config := clientcredentials.Config{
+			ClientID:     cred.ServicePrincipal.GetClientId(),
+			ClientSecret: cred.ServicePrincipal.GetClientSecret(),
+			TokenURL:     authorityHost + "/" + url.PathEscape(cred.ServicePrincipal.GetTenantId()) + "/oauth2/v2.0/token",
+			Scopes:       []string{storageResource + ".default"},
+		}
+		c.tokens = config.TokenSource(tokenCtx)
+	case *sourcespb.AzureStorage_ManagedIdentity:
+		c.tokens = oauth2.ReuseTokenSource(nil, managedIdentityTokens{
+			ctx:      tokenCtx,
+			client:   c.http,
+			clientID: conn.GetManagedIdentityClientId(),
+		})
+	case *sourcespb.AzureStorage_Unauthenticated:
+		// Public containers are read anonymously.
+	case *sourcespb.AzureStorage_ClientCertificate:
+		return nil, errors.New("client certificates are not supported, use a service principal with a secret instead")
+	}
+
+	if c.endpoint == "" {
+		if c.account == "" {
+			return nil, errors.New("an account or endpoint is required")
+		}
+		c.endpoint = "https://" + c.account + ".blob.core.windows.net"
+	}
+	if c.account == "" {
+		// The account is the first label of the host of the endpoint, or
+		// the first element of the path of emulators like Azurite.
+		if u, err := url.Parse(c.endpoint); err == nil {
+			c.account, _, _ = strings.Cut(u.Hostname(), ".")
+			if first, _, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/"); first != "" {
+				c.account = first
+			}
+		}
+	}
+	return c, nil
+}
+
+// Chunks emits the chunks of the blobs of the containers.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	containers, err := s.containersToScan(ctx)
+	if err != nil {
+		return err
+	}
+
+	var blobCount uint64
+	for i, containerName := range containers {
+		if common.IsDone(ctx) {
+			return nil
+		}
+		s.SetProgressComplete(i, len(containers), fmt.Sprintf("Container: %s", containerName), "")
+		ctx.Logger().V(2).Info("Scanning container", "container", containerName)
+
+		err := s.listBlobs(ctx, containerName, func(b blob) {
+			if reason, skip := s.skipReason(b); skip {
+				if reason != "" {
+					ctx.Logger().V(5).Info("Skipping blob", "container", containerName, "blob", b.Name, "reason", reason)
+					sources.ReportSkipBytes(ctx, b.Name, reason, b.Properties.ContentLength)
+				}
+				return
+			}
+			s.jobPool.Go(func() error {
+				defer common.RecoverWithExit(ctx)
+				if err := s.scanBlob(ctx, containerName, b, chunksChan); err != nil {
+					ctx.Logger().Error(err, "could not scan blob", "container", containerName, "blob", b.Name)
+					sources.ReportSkip(ctx, b.Name, sources.SkipReasonError)
+					return nil
+				}
+				atomic.AddUint64(&blobCount, 1)
+				return nil
+			})
+		})
+		_ = s.jobPool.Wait()
+		if err != nil {
+			return fmt.Errorf("could not list the blobs of container %s: %w", containerName, err)
+		}
+	}
+	s.SetProgressComplete(len(containers), len(containers), fmt.Sprintf("Completed scanning source %s. %d blobs scanned.", s.name, blobCount), "")
+	return nil
+}
+
+// EnumerateTargets reports each container with the number and size of the
+// blobs that would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	containers, err := s.containersToScan(ctx)
+	if err != nil {
+		return err
+	}
+	for _, containerName := range containers {
+		target := sources.Target{Name: containerName}
+		err = s.listBlobs(ctx, containerName, func(b blob) {
+			if _, skip := s.skipReason(b); skip {
+				return
+			}
+			target.Objects++
+			target.Bytes += b.Properties.ContentLength
+		})
+		if err != nil {
+			return fmt.Errorf("could not list the blobs of container %s: %w", containerName, err)
+		}
+		report(target)
+	}
+	return nil
+}
+
+// containersToScan returns the configured containers, or all the containers
+// of the account if none are configured.
+func (s *Source) containersToScan(ctx context.Context) ([]string, error) {
+	if len(s.conn.GetStorageContainers()) > 0 {
+		return s.conn.GetStorageContainers(), nil
+	}
+	if _, ok := s.conn.GetCredential().(*sourcespb.AzureStorage_Unauthenticated); ok {
+		return nil, errors.New("containers are required to scan without credentials")
+	}
+	containers, err := s.client.listContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list containers: %w", err)
+	}
+	return containers, nil
+}
+
+// listBlobs calls fn with each blob of a container under the prefixes of the
+// connection.
+func (s *Source) listBlobs(ctx context.Context, containerName string, fn func(blob)) error {
+	var include []string
+	if s.conn.GetIncludeSnapshots() {
+		include = append(include, "snapshots")
+	}
+	if s.conn.GetIncludeVersions() {
+		include = append(include, "versions")
+	}
+	prefixes := s.conn.GetPrefixes()
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+	for _, prefix := range prefixes {
+		err := s.client.listBlobs(ctx, containerName, prefix, include, func(blobs []blob) error {
+			for _, b := range blobs {
+				if common.IsDone(ctx) {
+					return ctx.Err()
+				}
+				fn(b)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipReason returns whether a blob is skipped, and why if it is reported.
+func (s *Source) skipReason(b blob) (sources.SkipReason, bool) {
+	for _, prefix := range s.conn.GetIgnorePrefixes() {
+		if strings.HasPrefix(b.Name, prefix) {
+			return sources.SkipReasonFiltered, true
+		}
+	}
+	switch size := b.Properties.ContentLength; {
+	case b.Properties.ResourceType == "directory" || strings.HasSuffix(b.Name, "/"):
+		return "", true
+	case strings.EqualFold(b.Properties.AccessTier, "Archive"):
+		// Archived blobs must be rehydrated before they can be read.
+		return sources.SkipReasonUnsupported, true
+	case size > s.maxObjectSize:
+		return sources.SkipReasonSize, true
+	case size == 0:
+		return sources.SkipReasonEmpty, true
+	case common.SkipFile(b.Name):
+		return sources.SkipReasonUnsupported, true
+	}
+	return "", false
+}
+
+// scanBlob emits the chunks of a blob, or of its snapshot or version.
+func (s *Source) scanBlob(ctx context.Context, containerName string, b blob, chunksChan chan *sources.Chunk) error {
+	body, err := s.client.getBlob(ctx, containerName, b)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	reader, err := diskbufferreader.New(body)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	skel := s.chunkSkeleton(containerName, b)
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+
+	chunkReader := sources.NewChunkReader(s.archiveOptions.ChunkOptions()...)
+	for data := range chunkReader(ctx, reader) {
+		if err := data.Error(); err != nil {
+			return err
+		}
+		chunk := *skel
+		chunk.Data = data.Bytes()
+		chunk.Offset, chunk.LineStart, chunk.LineOffset = data.Offset(), data.LineStart(), data.LineOffset()
+		if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkSkeleton returns the skeleton of the chunks of a blob.
+func (s *Source) chunkSkeleton(containerName string, b blob) *sources.Chunk {
+	var versionID string
+	if !b.current() && b.Snapshot == "" {
+		versionID = b.VersionID
+	}
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Azure{
+				Azure: &source_metadatapb.Azure{
+					Account:   s.client.account,
+					Container: containerName,
+					File:      sanitizer.UTF8(b.Name),
+					Uploaded:  b.Properties.LastModified,
+					Link:      sanitizer.UTF8(s.client.blobURL(containerName, b)),
+					Snapshot:  b.Snapshot,
+					VersionId: versionID,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
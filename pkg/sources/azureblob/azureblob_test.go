@@ -0,0 +1,309 @@
+package azureblob
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// accountKey is the base64 encoded key of the account acme of
+	// fakeAccount.
+	accountKey   = "c2VjcmV0LWtleQ=="
+	snapshotTime = "2024-03-01T09:00:00.0000000Z"
+)
+
+type fakeBlob struct {
+	blob
+	content string
+}
+
+// fakeAccount serves the blob service of the account acme below /acme, and
+// issues access tokens of service principals and managed identities. Requests
+// are authorized if they are signed with accountKey, have the signature sig
+// or have the access token storage-token.
+func fakeAccount(t *testing.T, containers map[string][]fakeBlob) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tenant-id/oauth2/v2.0/token":
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "https://storage.azure.com/.default", r.PostForm.Get("scope"))
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "storage-token", "token_type": "Bearer", "expires_in": 3600})
+			return
+		case "/metadata/identity/oauth2/token":
+			assert.Equal(t, "true", r.Header.Get("Metadata"))
+			assert.Equal(t, "https://storage.azure.com/", r.URL.Query().Get("resource"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "storage-token", "expires_in": "3600"})
+			return
+		}
+
+		assert.Equal(t, apiVersion, r.Header.Get("x-ms-version"))
+		auth := r.Header.Get("Authorization")
+		signed := auth == "SharedKey acme:"+signature(r, "acme", []byte("secret-key"))
+		if !signed && auth != "Bearer storage-token" && r.URL.Query().Get("sig") != "sig" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/acme/")
+		query := r.URL.Query()
+		switch {
+		case path == "" && query.Get("comp") == "list":
+			// The containers are listed in pages of one container.
+			var names []string
+			for name := range containers {
+				names = append(names, name)
+			}
+			if len(names) > 1 && names[0] > names[1] {
+				names[0], names[1] = names[1], names[0]
+			}
+			page := struct {
+				XMLName    xml.Name    `xml:"EnumerationResults"`
+				Containers []container `xml:"Containers>Container"`
+				NextMarker string      `xml:"NextMarker"`
+			}{Containers: []container{{Name: names[0]}}}
+			if query.Get("marker") != "" {
+				page.Containers = []container{{Name: names[1]}}
+			} else if len(names) > 1 {
+				page.NextMarker = names[1]
+			}
+			_ = xml.NewEncoder(w).Encode(page)
+		case query.Get("restype") == "container":
+			blobs, ok := containers[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			page := struct {
+				XMLName xml.Name `xml:"EnumerationResults"`
+				Blobs   []blob   `xml:"Blobs>Blob"`
+			}{}
+			include := query.Get("include")
+			for _, b := range blobs {
+				if !strings.HasPrefix(b.Name, query.Get("prefix")) ||
+					(b.Snapshot != "" && !strings.Contains(include, "snapshots")) ||
+					(!b.current() && b.Snapshot == "" && !strings.Contains(include, "versions")) {
+					continue
+				}
+				page.Blobs = append(page.Blobs, b.blob)
+			}
+			_ = xml.NewEncoder(w).Encode(page)
+		default:
+			containerName, name, _ := strings.Cut(path, "/")
+			for _, b := range containers[containerName] {
+				if b.Name == name && b.Snapshot == query.Get("snapshot") && blobQuery(b.blob).Get("versionid") == query.Get("versionid") {
+					_, _ = w.Write([]byte(b.content))
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	authorityHost = server.URL
+	imdsEndpoint = server.URL + "/metadata/identity/oauth2/token"
+	t.Cleanup(func() {
+		authorityHost = "https://login.microsoftonline.com"
+		imdsEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+	})
+	return server
+}
+
+// newBlob returns a blob of content.
+func newBlob(name, content string) fakeBlob {
+	b := fakeBlob{content: content}
+	b.Name = name
+	b.Properties.ContentLength = int64(len(content))
+	b.Properties.LastModified = "Fri, 01 Mar 2024 10:00:00 GMT"
+	return b
+}
+
+// version returns the blob as a version of a blob.
+func (b fakeBlob) version(id string, current bool) fakeBlob {
+	b.VersionID, b.IsCurrentVersion = id, current
+	return b
+}
+
+// snapshot returns the blob as a snapshot of a blob.
+func (b fakeBlob) snapshot(snapshot string) fakeBlob {
+	b.Snapshot = snapshot
+	return b
+}
+
+func testContainers() map[string][]fakeBlob {
+	archived := newBlob("archived.env", "TOKEN=archived\n")
+	archived.Properties.AccessTier = "Archive"
+	return map[string][]fakeBlob{
+		"app": {
+			newBlob("config.env", "TOKEN=current\n").version("v2", true),
+			newBlob("config.env", "TOKEN=previous\n").version("v1", false),
+			newBlob("config.env", "TOKEN=snapshot\n").snapshot(snapshotTime),
+			newBlob("deploy/key.pem", "KEY\n"),
+			newBlob("tmp/cache.env", "TOKEN=ignored\n"),
+			newBlob("empty.env", ""),
+			newBlob("large.bin", strings.Repeat("a", 200)),
+			archived,
+		},
+		"logs": {newBlob("app.log", "password=hunter2\n")},
+	}
+}
+
+// chunks returns the data of the chunks of a source by container, blob and
+// snapshot or version, and their metadata.
+func chunks(t *testing.T, conn *sourcespb.AzureStorage) (map[string]string, map[string]*source_metadatapb.Azure) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.Azure)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetAzure()
+		key := m.GetContainer() + "/" + m.GetFile() + " " + m.GetSnapshot() + m.GetVersionId()
+		got[key] += string(chunk.Data)
+		metadata[key] = m
+	}
+	return got, metadata
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server := fakeAccount(t, testContainers())
+
+	got, metadata := chunks(t, &sourcespb.AzureStorage{
+		Credential:       &sourcespb.AzureStorage_ConnectionString{ConnectionString: "AccountName=acme;AccountKey=" + accountKey + ";BlobEndpoint=" + server.URL + "/acme/"},
+		IgnorePrefixes:   []string{"tmp/"},
+		IncludeSnapshots: true,
+		IncludeVersions:  true,
+		MaxObjectSize:    100,
+	})
+	assert.Equal(t, map[string]string{
+		"app/config.env ":                "TOKEN=current\n",
+		"app/config.env v1":              "TOKEN=previous\n",
+		"app/config.env " + snapshotTime: "TOKEN=snapshot\n",
+		"app/deploy/key.pem ":            "KEY\n",
+		"logs/app.log ":                  "password=hunter2\n",
+	}, got)
+	assert.Equal(t, &source_metadatapb.Azure{
+		Account:   "acme",
+		Container: "app",
+		File:      "config.env",
+		Uploaded:  "Fri, 01 Mar 2024 10:00:00 GMT",
+		Link:      server.URL + "/acme/app/config.env?versionid=v1",
+		VersionId: "v1",
+	}, metadata["app/config.env v1"])
+	assert.Equal(t, server.URL+"/acme/app/config.env", metadata["app/config.env "].GetLink())
+	assert.Equal(t, server.URL+"/acme/app/config.env?snapshot=2024-03-01T09%3A00%3A00.0000000Z", metadata["app/config.env "+snapshotTime].GetLink())
+}
+
+func TestSource_ChunksCredentials(t *testing.T) {
+	tests := map[string]*sourcespb.AzureStorage{
+		"account key": {
+			Credential: &sourcespb.AzureStorage_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: "acme", Password: accountKey}},
+		},
+		"sas token": {
+			Credential: &sourcespb.AzureStorage_SasToken{SasToken: "?sv=2021-08-06&sig=sig"},
+		},
+		"service principal": {
+			Credential: &sourcespb.AzureStorage_ServicePrincipal{ServicePrincipal: &credentialspb.ClientCredentials{
+				TenantId: "tenant-id", ClientId: "client-id", ClientSecret: "client-secret",
+			}},
+		},
+		"managed identity": {
+			Credential: &sourcespb.AzureStorage_ManagedIdentity{ManagedIdentity: &credentialspb.CloudEnvironment{}},
+		},
+	}
+	for name, conn := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := fakeAccount(t, testContainers())
+			conn.Endpoint = server.URL + "/acme"
+			conn.StorageContainers = []string{"app"}
+			conn.Prefixes = []string{"deploy/"}
+			got, _ := chunks(t, conn)
+			assert.Equal(t, map[string]string{"app/deploy/key.pem ": "KEY\n"}, got)
+		})
+	}
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	server := fakeAccount(t, testContainers())
+	conn, err := anypb.New(&sourcespb.AzureStorage{
+		Endpoint:   server.URL + "/acme",
+		Credential: &sourcespb.AzureStorage_SasToken{SasToken: "sv=2021-08-06&sig=sig"},
+	})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+
+	var targets []sources.Target
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		targets = append(targets, target)
+	}))
+	assert.Equal(t, []sources.Target{
+		{Name: "app", Objects: 4, Bytes: 14 + 4 + 14 + 200},
+		{Name: "logs", Objects: 1, Bytes: 17},
+	}, targets)
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := fakeAccount(t, testContainers())
+	conn, err := anypb.New(&sourcespb.AzureStorage{
+		Endpoint:   server.URL + "/acme",
+		Credential: &sourcespb.AzureStorage_SasToken{SasToken: "sig=expired"},
+	})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "invalid credentials")
+}
+
+func TestSignature(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:10000/devstoreaccount1/backups/a%20b.txt?include=snapshots%2Cversions&comp=list", nil)
+	require.NoError(t, err)
+	req.Header.Set("x-ms-version", apiVersion)
+	req.Header.Set("x-ms-date", "Mon, 01 Jan 2024 00:00:00 GMT")
+	assert.Equal(t, "uFOJndeL29Sn8cLXkeHLAdQL+8KsTpa2m0WX4Pp1uYw=", signature(req, "devstoreaccount1", []byte("secret-key")))
+}
+
+func TestParseConnectionString(t *testing.T) {
+	tests := map[string]struct {
+		connectionString string
+		want             connectionString
+		wantErr          bool
+	}{
+		"account key": {
+			connectionString: "DefaultEndpointsProtocol=https;AccountName=acme;AccountKey=" + accountKey + ";EndpointSuffix=core.chinacloudapi.cn",
+			want:             connectionString{account: "acme", key: accountKey, endpoint: "https://acme.blob.core.chinacloudapi.cn"},
+		},
+		"shared access signature": {
+			connectionString: "BlobEndpoint=https://acme.blob.core.windows.net/;SharedAccessSignature=sv=2021-08-06&sig=abc%3D",
+			want:             connectionString{endpoint: "https://acme.blob.core.windows.net", sas: "sv=2021-08-06&sig=abc%3D"},
+		},
+		"no endpoint":    {connectionString: "AccountKey=" + accountKey, wantErr: true},
+		"invalid format": {connectionString: "acme", wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseConnectionString(tt.connectionString)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package azureblob
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// apiVersion is the version of the REST API of Blob Storage, which supports
+// versions of blobs.
+const apiVersion = "2021-08-06"
+
+// client is a client of the REST API of the blob service of a storage
+// account, which authenticates with the key of the account, a shared access
+// signature, an access token of Microsoft Entra ID, or not at all.
+type client struct {
+	http *http.Client
+	// endpoint is the URL of the blob service, without a trailing slash.
+	endpoint string
+	account  string
+	// key is the decoded key of the account, which signs requests.
+	key []byte
+	// sas is the query of a shared access signature, without a leading ?.
+	sas    string
+	tokens oauth2.TokenSource
+}
+
+type container struct {
+	Name string `xml:"Name"`
+}
+
+type blob struct {
+	Name             string `xml:"Name"`
+	Snapshot         string `xml:"Snapshot"`
+	VersionID        string `xml:"VersionId"`
+	IsCurrentVersion bool   `xml:"IsCurrentVersion"`
+	Properties       struct {
+		LastModified  string `xml:"Last-Modified"`
+		ContentLength int64  `xml:"Content-Length"`
+		AccessTier    string `xml:"AccessTier"`
+		// ResourceType is directory for the directories of accounts with
+		// a hierarchical namespace.
+		ResourceType string `xml:"ResourceType"`
+	} `xml:"Properties"`
+}
+
+// current returns whether a blob is the current version of a blob rather
+// than a snapshot or previous version.
+func (b blob) current() bool {
+	return b.Snapshot == "" && (b.VersionID == "" || b.IsCurrentVersion)
+}
+
+// listContainers returns the names of the containers of the account.
+func (c *client) listContainers(ctx context.Context) ([]string, error) {
+	var names []string
+	marker := ""
+	for {
+		var page struct {
+			Containers []container `xml:"Containers>Container"`
+			NextMarker string      `xml:"NextMarker"`
+		}
+		query := url.Values{"comp": {"list"}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		if err := c.getXML(ctx, "/", query, &page); err != nil {
+			return nil, err
+		}
+		for _, ct := range page.Containers {
+			names = append(names, ct.Name)
+		}
+		if marker = page.NextMarker; marker == "" {
+			return names, nil
+		}
+	}
+}
+
+// listBlobs calls fn with each page of the blobs of a container whose names
+// start with prefix, and their snapshots and previous versions if include
+// lists them.
+func (c *client) listBlobs(ctx context.Context, containerName, prefix string, include []string, fn func([]blob) error) error {
+	marker := ""
+	for {
+		var page struct {
+			Blobs      []blob `xml:"Blobs>Blob"`
+			NextMarker string `xml:"NextMarker"`
+		}
+		query := url.Values{"restype": {"container"}, "comp": {"list"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if len(include) > 0 {
+			query.Set("include", strings.Join(include, ","))
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		if err := c.getXML(ctx, "/"+containerName, query, &page); err != nil {
+			return err
+		}
+		if err := fn(page.Blobs); err != nil {
+			return err
+		}
+		if marker = page.NextMarker; marker == "" {
+			return nil
+		}
+	}
+}
+
+// blobQuery returns the query of a snapshot or version of a blob.
+func blobQuery(b blob) url.Values {
+	query := url.Values{}
+	switch {
+	case b.Snapshot != "":
+		query.Set("snapshot", b.Snapshot)
+	case !b.current():
+		query.Set("versionid", b.VersionID)
+	}
+	return query
+}
+
+// getBlob returns the content of a blob.
+func (c *client) getBlob(ctx context.Context, containerName string, b blob) (io.ReadCloser, error) {
+	res, err := c.do(ctx, "/"+containerName+"/"+b.Name, blobQuery(b))
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// blobURL returns the URL of a blob, or of its snapshot or version.
+func (c *client) blobURL(containerName string, b blob) string {
+	link := c.endpoint + "/" + escapePath(containerName+"/"+b.Name)
+	if query := blobQuery(b); len(query) > 0 {
+		link += "?" + query.Encode()
+	}
+	return link
+}
+
+func (c *client) getXML(ctx context.Context, path string, query url.Values, v any) error {
+	res, err := c.do(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return xml.NewDecoder(res.Body).Decode(v)
+}
+
+var errNotFound = errors.New("not found")
+
+// do sends a GET request of a path of the blob service, and returns its
+// response if it succeeded.
+func (c *client) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	reqURL := c.endpoint + escapePath(path)
+	rawQuery := query.Encode()
+	if c.sas != "" {
+		rawQuery = strings.TrimPrefix(rawQuery+"&"+c.sas, "&")
+	}
+	if rawQuery != "" {
+		reqURL += "?" + rawQuery
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-version", apiVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	switch {
+	case c.tokens != nil:
+		token, err := c.tokens.Token()
+		if err != nil {
+			return nil, fmt.Errorf("error getting access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	case c.key != nil:
+		req.Header.Set("Authorization", "SharedKey "+c.account+":"+signature(req, c.account, c.key))
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusOK {
+		return res, nil
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+	case http.StatusNotFound:
+		return nil, errNotFound
+	}
+	return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+}
+
+// signature returns the signature of a request with the key of an account,
+// as described in https://learn.microsoft.com/rest/api/storageservices/authorize-with-shared-key.
+func signature(req *http.Request, account string, key []byte) string {
+	var headers []string
+	for name := range req.Header {
+		if name := strings.ToLower(name); strings.HasPrefix(name, "x-ms-") {
+			headers = append(headers, name)
+		}
+	}
+	sort.Strings(headers)
+	var canonical strings.Builder
+	for _, name := range headers {
+		canonical.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+
+	canonical.WriteString("/" + account + req.URL.EscapedPath())
+	query := make(map[string][]string)
+	for name, values := range req.URL.Query() {
+		query[strings.ToLower(name)] = append(query[strings.ToLower(name)], values...)
+	}
+	params := make([]string, 0, len(query))
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		sort.Strings(query[name])
+		canonical.WriteString("\n" + name + ":" + strings.Join(query[name], ","))
+	}
+
+	// The standard headers after the verb are all empty for GET requests,
+	// and the date is in x-ms-date.
+	toSign := req.Method + strings.Repeat("\n", 12) + canonical.String()
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(toSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// escapePath escapes each element of a slash separated path.
+func escapePath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// connectionString is the configuration of a connection string of a storage
+// account.
+type connectionString struct {
+	account, key, endpoint, sas string
+}
+
+// parseConnectionString parses a connection string like
+// DefaultEndpointsProtocol=https;AccountName=name;AccountKey=key;EndpointSuffix=core.windows.net
+// or BlobEndpoint=https://name.blob.core.windows.net/;SharedAccessSignature=sv=...
+func parseConnectionString(s string) (connectionString, error) {
+	settings := make(map[string]string)
+	for _, setting := range strings.Split(s, ";") {
+		if setting = strings.TrimSpace(setting); setting == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(setting, "=")
+		if !ok {
+			return connectionString{}, errors.New("invalid connection string")
+		}
+		settings[strings.ToLower(name)] = value
+	}
+	cs := connectionString{
+		account:  settings["accountname"],
+		key:      settings["accountkey"],
+		endpoint: strings.TrimSuffix(settings["blobendpoint"], "/"),
+		sas:      strings.TrimPrefix(settings["sharedaccesssignature"], "?"),
+	}
+	if cs.endpoint == "" {
+		if cs.account == "" {
+			return connectionString{}, errors.New("connection string has no account name or blob endpoint")
+		}
+		protocol, suffix := settings["defaultendpointsprotocol"], settings["endpointsuffix"]
+		if protocol == "" {
+			protocol = "https"
+		}
+		if suffix == "" {
+			suffix = "core.windows.net"
+		}
+		cs.endpoint = protocol + "://" + cs.account + ".blob." + suffix
+	}
+	return cs, nil
+}
+
+// managedIdentityTokens are the access tokens of the managed identity of the
+// Azure resource trufflehog runs on, from its instance metadata service.
+type managedIdentityTokens struct {
+	ctx    context.Context
+	client *http.Client
+	// clientID is the client ID of a user-assigned managed identity, or
+	// empty for the system-assigned one.
+	clientID string
+}
+
+func (m managedIdentityTokens) Token() (*oauth2.Token, error) {
+	query := url.Values{"api-version": {"2018-02-01"}, "resource": {storageResource}}
+	if m.clientID != "" {
+		query.Set("client_id", m.clientID)
+	}
+	req, err := http.NewRequestWithContext(m.ctx, http.MethodGet, imdsEndpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+	res, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get the token of the managed identity, status %d", res.StatusCode)
+	}
+	var token struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+	expiresIn, _ := token.ExpiresIn.Int64()
+	return &oauth2.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   "Bearer",
+		Expiry:      time.Now().Add(time.Duration(expiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,435 @@
+package azurerepos
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const apiVersion = "api-version=7.0"
+
+type identity struct {
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+}
+
+type repository struct {
+	Name       string `json:"name"`
+	RemoteURL  string `json:"remoteUrl"`
+	IsFork     bool   `json:"isFork"`
+	IsDisabled bool   `json:"isDisabled"`
+}
+
+type variable struct {
+	Value    string `json:"value"`
+	IsSecret bool   `json:"isSecret"`
+}
+
+type definition struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Process has the steps of classic pipelines.
+	Process    json.RawMessage     `json:"process"`
+	Variables  map[string]variable `json:"variables"`
+	AuthoredBy identity            `json:"authoredBy"`
+	CreatedOn  string              `json:"createdDate"`
+	Links      struct {
+		Web struct {
+			Href string `json:"href"`
+		} `json:"web"`
+	} `json:"_links"`
+}
+
+type variableGroup struct {
+	ID         int                 `json:"id"`
+	Name       string              `json:"name"`
+	Variables  map[string]variable `json:"variables"`
+	ModifiedBy identity            `json:"modifiedBy"`
+	ModifiedOn string              `json:"modifiedOn"`
+}
+
+type wikiPage struct {
+	Path      string     `json:"path"`
+	RemoteURL string     `json:"remoteUrl"`
+	Content   string     `json:"content"`
+	SubPages  []wikiPage `json:"subPages"`
+}
+
+type comment struct {
+	ID          int      `json:"id"`
+	Text        string   `json:"text"`
+	IsDeleted   bool     `json:"isDeleted"`
+	CreatedBy   identity `json:"createdBy"`
+	CreatedDate string   `json:"createdDate"`
+}
+
+// base returns the URL of an organization or collection.
+func (s *Source) base(org string) string {
+	return s.endpoint + "/" + url.PathEscape(org)
+}
+
+// projectBase returns the URL of a project.
+func (s *Source) projectBase(p project) string {
+	return s.base(p.org) + "/" + url.PathEscape(p.name)
+}
+
+// projects returns the projects of the connection, or of all the
+// organizations or collections the credentials can read.
+func (s *Source) projects(ctx context.Context) ([]project, error) {
+	orgs := s.conn.GetOrganizations()
+	if len(orgs) == 0 {
+		var err error
+		if orgs, err = s.organizations(ctx); err != nil {
+			return nil, fmt.Errorf("error listing organizations: %w", err)
+		}
+	}
+
+	var projects []project
+	for _, org := range orgs {
+		names := s.conn.GetProjects()
+		if len(names) == 0 {
+			all, err := list[struct {
+				Name string `json:"name"`
+			}](ctx, s, s.base(org)+"/_apis/projects?"+apiVersion)
+			if err != nil {
+				return nil, fmt.Errorf("error listing the projects of %s: %w", org, err)
+			}
+			for _, p := range all {
+				names = append(names, p.Name)
+			}
+		}
+		for _, name := range names {
+			if !s.ignoreProject(ctx, org+"/"+name) {
+				projects = append(projects, project{org: org, name: name})
+			}
+		}
+	}
+	return projects, nil
+}
+
+// organizations returns the organizations of the user of the credentials on
+// Azure DevOps Services, or the collections of Azure DevOps Server.
+func (s *Source) organizations(ctx context.Context) ([]string, error) {
+	var names []string
+	if s.endpoint != cloudEndpoint {
+		collections, err := list[struct {
+			Name string `json:"name"`
+		}](ctx, s, s.endpoint+"/_apis/projectCollections?"+apiVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range collections {
+			names = append(names, c.Name)
+		}
+		return names, nil
+	}
+
+	var profile struct {
+		ID string `json:"id"`
+	}
+	if err := s.get(ctx, profileEndpoint+"/_apis/profile/profiles/me?"+apiVersion, &profile); err != nil {
+		return nil, err
+	}
+	accounts, err := list[struct {
+		AccountName string `json:"accountName"`
+	}](ctx, s, profileEndpoint+"/_apis/accounts?memberId="+url.QueryEscape(profile.ID)+"&"+apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range accounts {
+		names = append(names, a.AccountName)
+	}
+	return names, nil
+}
+
+// repos returns the enabled repositories of a project, and their forks if
+// the connection includes them.
+func (s *Source) repos(ctx context.Context, p project) ([]repo, error) {
+	gitRepos, err := list[repository](ctx, s, s.projectBase(p)+"/_apis/git/repositories?"+apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	var repos []repo
+	for _, r := range gitRepos {
+		if r.IsDisabled || (r.IsFork && !s.conn.GetIncludeForks()) {
+			continue
+		}
+		cloneURL := repoFromURL(r.RemoteURL).cloneURL
+		repos = append(repos, repo{org: p.org, project: p.name, name: r.Name, cloneURL: cloneURL})
+	}
+	return repos, nil
+}
+
+// scanPipelines scans the final YAML of the YAML pipelines of a project, or
+// the steps of classic pipelines, and the values of the variables of the
+// pipelines that aren't secret.
+func (s *Source) scanPipelines(ctx context.Context, p project, chunksChan chan *sources.Chunk) error {
+	base := s.projectBase(p)
+	definitions, err := list[definition](ctx, s, base+"/_apis/build/definitions?includeAllProperties=true&"+apiVersion)
+	if err != nil {
+		return err
+	}
+	for _, d := range definitions {
+		var process struct {
+			// Type is 1 for classic pipelines and 2 for YAML pipelines.
+			Type int `json:"type"`
+		}
+		if err := json.Unmarshal(d.Process, &process); len(d.Process) > 0 && err != nil {
+			return fmt.Errorf("error decoding the process of pipeline %s: %w", d.Name, err)
+		}
+		location := fmt.Sprintf("%s/%d", locationPipeline, d.ID)
+		skel := s.chunkSkeleton(p, location, d.AuthoredBy.DisplayName, d.AuthoredBy.UniqueName, d.Links.Web.Href, d.CreatedOn)
+
+		// The YAML of the pipelines is previewed with its templates
+		// expanded, which also covers YAML outside of Azure Repos.
+		data := string(d.Process)
+		if process.Type == 2 {
+			var preview struct {
+				FinalYaml string `json:"finalYaml"`
+			}
+			path := fmt.Sprintf("%s/_apis/pipelines/%d/preview?api-version=7.0-preview.1", base, d.ID)
+			if err := s.post(ctx, path, map[string]any{"previewRun": true}, &preview); err != nil {
+				ctx.Logger().V(2).Info("could not preview the YAML of pipeline", "pipeline", d.Name, "error", err)
+				data = ""
+			} else {
+				data = preview.FinalYaml
+			}
+		}
+		if data != "" {
+			if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(data), chunksChan, s.chunkOptions()...); err != nil {
+				return err
+			}
+		}
+
+		if variables := variableLines(d.Variables, false); variables != "" {
+			skel := s.chunkSkeleton(p, location+"/variables", d.AuthoredBy.DisplayName, d.AuthoredBy.UniqueName, d.Links.Web.Href, d.CreatedOn)
+			if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(variables), chunksChan, s.chunkOptions()...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scanVariableGroups scans the names of the variable groups of a project and
+// of their variables, and the values of those that aren't secret.
+func (s *Source) scanVariableGroups(ctx context.Context, p project, chunksChan chan *sources.Chunk) error {
+	groups, err := list[variableGroup](ctx, s, s.projectBase(p)+"/_apis/distributedtask/variablegroups?"+apiVersion)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		link := fmt.Sprintf("%s/_library?itemType=VariableGroups&view=VariableGroupView&variableGroupId=%d", s.projectBase(p), g.ID)
+		skel := s.chunkSkeleton(p, fmt.Sprintf("%s/%d", locationVariableGroup, g.ID), g.ModifiedBy.DisplayName, g.ModifiedBy.UniqueName, link, g.ModifiedOn)
+		data := g.Name + "\n" + variableLines(g.Variables, true)
+		if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(data), chunksChan, s.chunkOptions()...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// variableLines returns the NAME=value lines of variables by name, and the
+// names of secret variables if withSecretNames, whose values can't be read.
+func variableLines(variables map[string]variable, withSecretNames bool) string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var lines strings.Builder
+	for _, name := range names {
+		v := variables[name]
+		switch {
+		case !v.IsSecret:
+			lines.WriteString(name + "=" + v.Value + "\n")
+		case withSecretNames:
+			lines.WriteString(name + "\n")
+		}
+	}
+	return lines.String()
+}
+
+// scanWikis scans the pages of the project wikis of a project. Code wikis
+// are pages of repositories, which are scanned with them.
+func (s *Source) scanWikis(ctx context.Context, p project, chunksChan chan *sources.Chunk) error {
+	base := s.projectBase(p) + "/_apis/wiki/wikis"
+	wikis, err := list[struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}](ctx, s, base+"?"+apiVersion)
+	if err != nil {
+		return err
+	}
+	for _, w := range wikis {
+		if w.Type != "projectWiki" {
+			continue
+		}
+		pagesURL := base + "/" + url.PathEscape(w.ID) + "/pages?" + apiVersion
+		var root wikiPage
+		if err := s.get(ctx, pagesURL+"&path=/&recursionLevel=full", &root); err != nil {
+			return fmt.Errorf("error listing the pages of wiki %s: %w", w.Name, err)
+		}
+		pages := []wikiPage{root}
+		for len(pages) > 0 && !common.IsDone(ctx) {
+			page := pages[0]
+			pages = append(pages[1:], page.SubPages...)
+			if page.Path == "/" {
+				continue
+			}
+			var content wikiPage
+			if err := s.get(ctx, pagesURL+"&includeContent=true&path="+url.QueryEscape(page.Path), &content); err != nil {
+				return fmt.Errorf("error getting wiki page %s: %w", page.Path, err)
+			}
+			if content.Content == "" {
+				continue
+			}
+			skel := s.chunkSkeleton(p, locationWiki+"/"+w.Name+page.Path, "", "", page.RemoteURL, "")
+			if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(content.Content), chunksChan, s.chunkOptions()...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scanWorkItems scans the comments of the work items of a project. Queries
+// return at most the first 20000 work items.
+func (s *Source) scanWorkItems(ctx context.Context, p project, chunksChan chan *sources.Chunk) error {
+	base := s.projectBase(p)
+	var result struct {
+		WorkItems []struct {
+			ID int `json:"id"`
+		} `json:"workItems"`
+	}
+	query := map[string]string{"query": "SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project ORDER BY [System.Id]"}
+	if err := s.post(ctx, base+"/_apis/wit/wiql?"+apiVersion, query, &result); err != nil {
+		return err
+	}
+	for _, item := range result.WorkItems {
+		if common.IsDone(ctx) {
+			return nil
+		}
+		link := fmt.Sprintf("%s/_workitems/edit/%d", base, item.ID)
+		commentsURL := fmt.Sprintf("%s/_apis/wit/workItems/%d/comments?api-version=7.0-preview.3", base, item.ID)
+		for next := commentsURL; next != ""; {
+			var page struct {
+				Comments          []comment `json:"comments"`
+				ContinuationToken string    `json:"continuationToken"`
+			}
+			if err := s.get(ctx, next, &page); err != nil {
+				return fmt.Errorf("error listing the comments of work item %d: %w", item.ID, err)
+			}
+			for _, c := range page.Comments {
+				if c.IsDeleted || c.Text == "" {
+					continue
+				}
+				location := fmt.Sprintf("%s/%d/%s/%d", locationWorkItem, item.ID, locationComment, c.ID)
+				skel := s.chunkSkeleton(p, location, c.CreatedBy.DisplayName, c.CreatedBy.UniqueName, link, c.CreatedDate)
+				if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(c.Text), chunksChan, s.chunkOptions()...); err != nil {
+					return err
+				}
+			}
+			next = ""
+			if page.ContinuationToken != "" {
+				next = commentsURL + "&continuationToken=" + url.QueryEscape(page.ContinuationToken)
+			}
+		}
+	}
+	return nil
+}
+
+// list returns the values of all the pages of a collection, which give the
+// continuation token of their next page in a header.
+func list[T any](ctx context.Context, s *Source, reqURL string) ([]T, error) {
+	var all []T
+	next := reqURL
+	for next != "" && !common.IsDone(ctx) {
+		var page struct {
+			Value []T `json:"value"`
+		}
+		header, err := s.do(ctx, http.MethodGet, next, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Value...)
+		next = ""
+		if token := header.Get("x-ms-continuationtoken"); token != "" {
+			next = reqURL + "&continuationToken=" + url.QueryEscape(token)
+		}
+	}
+	return all, nil
+}
+
+var errNotFound = errors.New("not found")
+
+// get decodes the JSON response of a request of the API.
+func (s *Source) get(ctx context.Context, reqURL string, v any) error {
+	_, err := s.do(ctx, http.MethodGet, reqURL, nil, v)
+	return err
+}
+
+// post decodes the JSON response of a request of the API with a JSON body.
+func (s *Source) post(ctx context.Context, reqURL string, body, v any) error {
+	_, err := s.do(ctx, http.MethodPost, reqURL, body, v)
+	return err
+}
+
+// do decodes the JSON response of a request of the API, and returns its
+// header.
+func (s *Source) do(ctx context.Context, method, reqURL string, body, v any) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case s.tokens != nil:
+		token, err := s.tokens.Token()
+		if err != nil {
+			return nil, fmt.Errorf("error getting access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	case s.pat != "":
+		req.SetBasicAuth("", s.pat)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+		return res.Header, json.NewDecoder(res.Body).Decode(v)
+	case http.StatusUnauthorized, http.StatusNonAuthoritativeInfo:
+		// Azure DevOps answers requests of invalid tokens with a sign-in
+		// page and a status of 203.
+		return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+	case http.StatusNotFound:
+		return nil, errNotFound
+	}
+	return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+}
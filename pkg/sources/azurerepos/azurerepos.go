@@ -0,0 +1,410 @@
+package azurerepos
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+	gogit "github.com/go-git/go-git/v5"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+const (
+	locationPipeline      = "pipeline"
+	locationVariableGroup = "variable_group"
+	locationWiki          = "wiki"
+	locationWorkItem      = "work_item"
+	locationComment       = "comment"
+
+	// cloudEndpoint is the URL of Azure DevOps Services, whose
+	// organizations are below it.
+	cloudEndpoint = "https://dev.azure.com"
+)
+
+var (
+	// profileEndpoint is the URL of the API of the profiles and accounts of
+	// Azure DevOps Services, which lists the organizations of a user.
+	profileEndpoint = "https://app.vssps.visualstudio.com"
+	// tokenURL is where the access tokens of Azure DevOps are refreshed.
+	tokenURL = "https://login.microsoftonline.com/organizations/oauth2/v2.0/token"
+)
+
+// Source scans the repositories of the projects of the organizations of Azure
+// DevOps Services, or the collections of Azure DevOps Server, and the YAML
+// and variables of their pipelines, their variable groups, the pages of
+// their wikis and the comments of their work items.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn *sourcespb.AzureRepos
+	// endpoint is the URL of Azure DevOps Services or Server, which the
+	// organizations or collections are below.
+	endpoint string
+	client   *http.Client
+	// tokens are the access tokens of OAuth, nil for personal access tokens.
+	tokens oauth2.TokenSource
+	pat    string
+
+	git         *git.Git
+	scanOptions *git.ScanOptions
+
+	includeRepos, ignoreRepos       sources.Globs
+	includeProjects, ignoreProjects sources.Globs
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_AZURE_REPOS
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+func (s *Source) WithScanOptions(scanOptions *git.ScanOptions) {
+	s.scanOptions = scanOptions
+}
+
+// chunkOptions returns the options of the chunk readers of the API content,
+// taken from the archive options of the scan.
+func (s *Source) chunkOptions() []sources.ConfigOption {
+	if s.scanOptions == nil {
+		return nil
+	}
+	return s.scanOptions.ArchiveOptions.ChunkOptions()
+}
+
+// Init returns an initialized Azure DevOps source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.AzureRepos
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	// The globs of organization/project/repo names match across slashes.
+	var err error
+	if s.includeRepos, err = sources.CompileGlobs(conn.GetIncludeRepos()); err != nil {
+		return err
+	}
+	if s.ignoreRepos, err = sources.CompileGlobs(conn.GetIgnoreRepos()); err != nil {
+		return err
+	}
+	if s.includeProjects, err = sources.CompileGlobs(conn.GetIncludeProjects()); err != nil {
+		return err
+	}
+	if s.ignoreProjects, err = sources.CompileGlobs(conn.GetIgnoreProjects()); err != nil {
+		return err
+	}
+
+	s.endpoint = strings.TrimSuffix(conn.GetEndpoint(), "/")
+	if s.endpoint == "" {
+		s.endpoint = cloudEndpoint
+	}
+	s.client = common.RetryableHttpClientTimeout(120)
+
+	switch cred := conn.Credential.(type) {
+	case *sourcespb.AzureRepos_Token:
+		s.pat = cred.Token
+	case *sourcespb.AzureRepos_Oauth:
+		token := &oauth2.Token{AccessToken: cred.Oauth.GetAccessToken(), RefreshToken: cred.Oauth.GetRefreshToken()}
+		s.tokens = oauth2.StaticTokenSource(token)
+		if token.RefreshToken != "" {
+			config := oauth2.Config{
+				ClientID:     cred.Oauth.GetClientId(),
+				ClientSecret: cred.Oauth.GetClientSecret(),
+				Endpoint:     oauth2.Endpoint{TokenURL: tokenURL},
+				// The resource of Azure DevOps.
+				Scopes: []string{"499b84ac-1321-427f-aa17-267ca6975798/.default", "offline_access"},
+			}
+			tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, s.client)
+			s.tokens = oauth2.ReuseTokenSource(nil, config.TokenSource(tokenCtx, token))
+		}
+	}
+
+	if err := git.GitCmdCheck(); err != nil {
+		return err
+	}
+	s.git = git.NewGit(s.Type(), s.JobID(), s.SourceID(), s.name, s.verify, runtime.NumCPU(),
+		func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+			r := repoFromURL(repository)
+			return &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_AzureRepos{
+					AzureRepos: &source_metadatapb.AzureRepos{
+						Commit:       sanitizer.UTF8(commit),
+						File:         sanitizer.UTF8(file),
+						Email:        sanitizer.UTF8(email),
+						Repository:   sanitizer.UTF8(repository),
+						Link:         git.GenerateLink(repository, commit, file, line),
+						Timestamp:    sanitizer.UTF8(timestamp),
+						Line:         line,
+						Project:      sanitizer.UTF8(r.project),
+						Organization: sanitizer.UTF8(r.org),
+					},
+				},
+			}
+		})
+	return nil
+}
+
+// project is a project of an organization or collection.
+type project struct {
+	org  string
+	name string
+}
+
+// repo is a repository to scan.
+type repo struct {
+	org     string
+	project string
+	name    string
+	// cloneURL is the URL of the repository without its user.
+	cloneURL string
+}
+
+func (r repo) fullName() string {
+	return r.org + "/" + r.project + "/" + r.name
+}
+
+// repoFromURL returns the repository of a clone URL, with its organization
+// and project if it is a URL of Azure DevOps, like
+// https://dev.azure.com/org/project/_git/repo or
+// https://org.visualstudio.com/project/_git/repo.
+func repoFromURL(cloneURL string) repo {
+	r := repo{cloneURL: cloneURL}
+	u, err := url.Parse(cloneURL)
+	if err != nil {
+		return r
+	}
+	u.User = nil
+	r.cloneURL = u.String()
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, part := range parts {
+		if part != "_git" || i == 0 || i == len(parts)-1 {
+			continue
+		}
+		r.project, r.name = parts[i-1], parts[i+1]
+		switch {
+		case i >= 2:
+			r.org = parts[i-2]
+		case strings.HasSuffix(u.Hostname(), ".visualstudio.com"):
+			r.org = strings.TrimSuffix(u.Hostname(), ".visualstudio.com")
+		}
+		break
+	}
+	return r
+}
+
+// Chunks emits the chunks of the history of the repositories, and of the
+// pipelines, variable groups, wiki pages and work item comments of their
+// projects.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	var repos []repo
+	var projects []project
+	if len(s.conn.GetRepositories()) > 0 {
+		for _, cloneURL := range s.conn.GetRepositories() {
+			repos = append(repos, repoFromURL(cloneURL))
+		}
+	} else {
+		var err error
+		if projects, err = s.projects(ctx); err != nil {
+			return err
+		}
+		for _, p := range projects {
+			projectRepos, err := s.repos(ctx, p)
+			if err != nil {
+				return fmt.Errorf("error listing the repositories of project %s/%s: %w", p.org, p.name, err)
+			}
+			repos = append(repos, projectRepos...)
+		}
+	}
+
+	kept := repos[:0]
+	for _, r := range repos {
+		if !s.ignoreRepo(ctx, r.fullName()) {
+			kept = append(kept, r)
+		}
+	}
+	repos = kept
+	sort.Slice(repos, func(i, j int) bool { return repos[i].cloneURL < repos[j].cloneURL })
+
+	scanErrs := sources.NewScanErrors()
+	total := len(repos) + len(projects)
+	for i, r := range repos {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, total, fmt.Sprintf("Repo: %s", r.cloneURL), "")
+		r := r
+		s.jobPool.Go(func() error {
+			if err := s.scanRepo(ctx, r, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error scanning repo %s: %w", r.cloneURL, err))
+			}
+			return nil
+		})
+	}
+	for i, p := range projects {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(len(repos)+i, total, fmt.Sprintf("Project: %s/%s", p.org, p.name), "")
+		p := p
+		s.jobPool.Go(func() error {
+			for _, err := range s.scanProject(ctx, p, chunksChan) {
+				scanErrs.Add(fmt.Errorf("error scanning project %s/%s: %w", p.org, p.name, err))
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	s.SetProgressComplete(total, total, "Completed Azure DevOps scan", "")
+	return nil
+}
+
+// scanProject scans the pipelines, variable groups, wiki pages and work item
+// comments of a project, and returns the errors of each. Those of the
+// services disabled in the project, which are not found, are skipped.
+func (s *Source) scanProject(ctx context.Context, p project, chunksChan chan *sources.Chunk) []error {
+	scans := []struct {
+		name string
+		skip bool
+		scan func(context.Context, project, chan *sources.Chunk) error
+	}{
+		{"pipelines", s.conn.GetSkipPipelines(), s.scanPipelines},
+		{"variable groups", s.conn.GetSkipVariableGroups(), s.scanVariableGroups},
+		{"wikis", s.conn.GetSkipWikis(), s.scanWikis},
+		{"work items", s.conn.GetSkipWorkItems(), s.scanWorkItems},
+	}
+	var errs []error
+	for _, scan := range scans {
+		if scan.skip {
+			continue
+		}
+		if err := scan.scan(ctx, p, chunksChan); err != nil && !errors.Is(err, errNotFound) {
+			errs = append(errs, fmt.Errorf("error scanning %s: %w", scan.name, err))
+		}
+	}
+	return errs
+}
+
+// ignoreRepo returns whether the organization/project/repo name of a
+// repository is ignored, or not included when only some are.
+func (s *Source) ignoreRepo(ctx context.Context, name string) bool {
+	if len(s.includeRepos) > 0 && !s.includeRepos.Match(name) {
+		ctx.Logger().V(2).Info("Ignoring repo", "repo", name)
+		return true
+	}
+	if s.ignoreRepos.Match(name) {
+		ctx.Logger().V(2).Info("Ignoring repo", "repo", name)
+		return true
+	}
+	return false
+}
+
+// ignoreProject returns whether the organization/project name of a project
+// is ignored, or not included when only some are.
+func (s *Source) ignoreProject(ctx context.Context, name string) bool {
+	if len(s.includeProjects) > 0 && !s.includeProjects.Match(name) {
+		ctx.Logger().V(2).Info("Ignoring project", "project", name)
+		return true
+	}
+	if s.ignoreProjects.Match(name) {
+		ctx.Logger().V(2).Info("Ignoring project", "project", name)
+		return true
+	}
+	return false
+}
+
+// scanRepo scans the history of a repository.
+func (s *Source) scanRepo(ctx context.Context, r repo, chunksChan chan *sources.Chunk) error {
+	path, gitRepo, err := s.clone(ctx, r.cloneURL)
+	defer os.RemoveAll(path)
+	if err != nil {
+		return err
+	}
+	return s.git.ScanRepo(ctx, gitRepo, path, s.scanOptions, chunksChan)
+}
+
+// clone clones a repository with the credentials of the source if it is
+// cloned over HTTP.
+func (s *Source) clone(ctx context.Context, cloneURL string) (string, *gogit.Repository, error) {
+	if !strings.HasPrefix(cloneURL, "https://") && !strings.HasPrefix(cloneURL, "http://") {
+		return git.CloneRepoUsingUnauthenticated(ctx, cloneURL)
+	}
+	switch {
+	case s.tokens != nil:
+		token, err := s.tokens.Token()
+		if err != nil {
+			return "", nil, fmt.Errorf("error getting access token: %w", err)
+		}
+		return git.CloneRepoUsingToken(ctx, token.AccessToken, cloneURL, "oauth")
+	case s.pat != "":
+		// Azure DevOps ignores the username of personal access tokens.
+		return git.CloneRepoUsingToken(ctx, s.pat, cloneURL, "pat")
+	}
+	return git.CloneRepoUsingUnauthenticated(ctx, cloneURL)
+}
+
+func (s *Source) chunkSkeleton(p project, location, username, email, link, timestamp string) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_AzureRepos{
+				AzureRepos: &source_metadatapb.AzureRepos{
+					Organization: p.org,
+					Project:      p.name,
+					Username:     username,
+					Email:        email,
+					Link:         link,
+					Timestamp:    timestamp,
+					Location:     location,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
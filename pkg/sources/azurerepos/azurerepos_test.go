@@ -0,0 +1,197 @@
+package azurerepos
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+// gitRepo returns the file:// URL of a repository of a commit of a file.
+func gitRepo(t *testing.T, file, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=Ada", "-c", "user.email=ada@example.com", "commit", "-qm", "init"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return "file://" + dir
+}
+
+// fakeServer serves a collection of Azure DevOps Server of two projects, the
+// second of which is ignored, which requires the personal access token pat.
+func fakeServer(t *testing.T, cloneURL string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	handle := func(path string, v func(r *http.Request) any) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if _, pass, _ := r.BasicAuth(); pass != "pat" {
+				w.WriteHeader(http.StatusNonAuthoritativeInfo)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(v(r))
+		})
+	}
+	values := func(v ...any) func(*http.Request) any {
+		return func(*http.Request) any { return map[string]any{"value": v} }
+	}
+	handle("/_apis/projectCollections", values(map[string]any{"name": "acme"}))
+	mux.HandleFunc("/acme/_apis/projects", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("continuationToken") == "" {
+			w.Header().Set("x-ms-continuationtoken", "2")
+			_ = json.NewEncoder(w).Encode(map[string]any{"value": []any{map[string]any{"name": "Platform"}}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"value": []any{map[string]any{"name": "Legacy"}}})
+	})
+	handle("/acme/Platform/_apis/git/repositories", values(
+		map[string]any{"name": "api", "remoteUrl": cloneURL},
+		map[string]any{"name": "api-fork", "remoteUrl": "https://ado.example.com/acme/Platform/_git/api-fork", "isFork": true},
+		map[string]any{"name": "old", "remoteUrl": "https://ado.example.com/acme/Platform/_git/old", "isDisabled": true},
+	))
+	handle("/acme/Platform/_apis/build/definitions", values(
+		map[string]any{"id": 1, "name": "api", "process": map[string]any{"type": 2, "yamlFilename": "azure-pipelines.yml"}},
+		map[string]any{
+			"id": 2, "name": "deploy", "createdDate": "2024-03-01T10:00:00Z",
+			"authoredBy": map[string]any{"displayName": "Ada", "uniqueName": "ada@example.com"},
+			"process":    map[string]any{"type": 1, "phases": []any{map[string]any{"steps": []any{map[string]any{"inputs": map[string]any{"script": "deploy --token hunter2"}}}}}},
+			"variables":  map[string]any{"REGION": map[string]any{"value": "eu"}, "PASSWORD": map[string]any{"isSecret": true}},
+			"_links":     map[string]any{"web": map[string]any{"href": "https://ado.example.com/acme/Platform/_build/definition?definitionId=2"}},
+		},
+	))
+	handle("/acme/Platform/_apis/pipelines/1/preview", func(r *http.Request) any {
+		assert.Equal(t, http.MethodPost, r.Method)
+		return map[string]any{"finalYaml": "steps:\n- script: echo $(TOKEN)\n"}
+	})
+	handle("/acme/Platform/_apis/distributedtask/variablegroups", values(map[string]any{
+		"id": 5, "name": "deploy-secrets",
+		"variables": map[string]any{"API_URL": map[string]any{"value": "https://api.example.com"}, "API_KEY": map[string]any{"isSecret": true}},
+	}))
+	handle("/acme/Platform/_apis/wiki/wikis", values(
+		map[string]any{"id": "W1", "name": "Platform.wiki", "type": "projectWiki"},
+		map[string]any{"id": "W2", "name": "docs", "type": "codeWiki"},
+	))
+	handle("/acme/Platform/_apis/wiki/wikis/W1/pages", func(r *http.Request) any {
+		if r.URL.Query().Get("includeContent") == "" {
+			assert.Equal(t, "full", r.URL.Query().Get("recursionLevel"))
+			return map[string]any{"path": "/", "subPages": []any{map[string]any{"path": "/Setup", "remoteUrl": "https://ado.example.com/acme/Platform/_wiki/wikis/Platform.wiki/1/Setup"}}}
+		}
+		assert.Equal(t, "/Setup", r.URL.Query().Get("path"))
+		return map[string]any{"path": "/Setup", "content": "password: hunter3"}
+	})
+	handle("/acme/Platform/_apis/wit/wiql", func(r *http.Request) any {
+		assert.Equal(t, http.MethodPost, r.Method)
+		return map[string]any{"workItems": []any{map[string]any{"id": 9}}}
+	})
+	handle("/acme/Platform/_apis/wit/workItems/9/comments", func(r *http.Request) any {
+		if r.URL.Query().Get("continuationToken") == "" {
+			return map[string]any{"continuationToken": "next", "comments": []any{
+				map[string]any{"id": 90, "text": "deleted", "isDeleted": true},
+			}}
+		}
+		return map[string]any{"comments": []any{map[string]any{
+			"id": 91, "text": "<div>key AKIA</div>", "createdDate": "2024-03-02T09:00:00Z",
+			"createdBy": map[string]any{"displayName": "Bob", "uniqueName": "bob@example.com"},
+		}}}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// chunks returns the data of the chunks of a source by location, or file
+// for the chunks of git, and their metadata.
+func chunks(t *testing.T, conn *sourcespb.AzureRepos) (map[string]string, map[string]*source_metadatapb.AzureRepos) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+	s.WithScanOptions(git.NewScanOptions(git.ScanOptionFilter(common.FilterEmpty())))
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.AzureRepos)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetAzureRepos()
+		key := m.GetLocation()
+		if key == "" {
+			key = m.GetFile()
+		}
+		got[key] += string(chunk.Data)
+		metadata[key] = m
+	}
+	return got, metadata
+}
+
+func TestSource_Chunks(t *testing.T) {
+	cloneURL := gitRepo(t, "config.env", "TOKEN=secret\n")
+	server := fakeServer(t, cloneURL)
+
+	got, metadata := chunks(t, &sourcespb.AzureRepos{
+		Endpoint:       server.URL,
+		Credential:     &sourcespb.AzureRepos_Token{Token: "pat"},
+		IgnoreProjects: []string{"acme/Legacy"},
+	})
+	assert.Equal(t, map[string]string{
+		"config.env":               "TOKEN=secret\n",
+		"pipeline/1":               "steps:\n- script: echo $(TOKEN)\n",
+		"pipeline/2":               `{"phases":[{"steps":[{"inputs":{"script":"deploy --token hunter2"}}]}],"type":1}`,
+		"pipeline/2/variables":     "REGION=eu\n",
+		"variable_group/5":         "deploy-secrets\nAPI_KEY\nAPI_URL=https://api.example.com\n",
+		"wiki/Platform.wiki/Setup": "password: hunter3",
+		"work_item/9/comment/91":   "<div>key AKIA</div>",
+	}, got)
+	assert.Equal(t, &source_metadatapb.AzureRepos{
+		Organization: "acme",
+		Project:      "Platform",
+		Username:     "Bob",
+		Email:        "bob@example.com",
+		Link:         server.URL + "/acme/Platform/_workitems/edit/9",
+		Timestamp:    "2024-03-02T09:00:00Z",
+		Location:     "work_item/9/comment/91",
+	}, metadata["work_item/9/comment/91"])
+	assert.Equal(t, "https://ado.example.com/acme/Platform/_build/definition?definitionId=2", metadata["pipeline/2"].GetLink())
+	assert.Equal(t, server.URL+"/acme/Platform/_library?itemType=VariableGroups&view=VariableGroupView&variableGroupId=5", metadata["variable_group/5"].GetLink())
+	assert.Equal(t, "https://ado.example.com/acme/Platform/_wiki/wikis/Platform.wiki/1/Setup", metadata["wiki/Platform.wiki/Setup"].GetLink())
+	assert.Equal(t, cloneURL, metadata["config.env"].GetRepository())
+}
+
+func TestSource_RepoFromURL(t *testing.T) {
+	assert.Equal(t, repo{org: "acme", project: "Platform", name: "api", cloneURL: "https://dev.azure.com/acme/Platform/_git/api"},
+		repoFromURL("https://acme@dev.azure.com/acme/Platform/_git/api"))
+	assert.Equal(t, repo{org: "acme", project: "Platform", name: "api", cloneURL: "https://acme.visualstudio.com/Platform/_git/api"},
+		repoFromURL("https://acme.visualstudio.com/Platform/_git/api"))
+	assert.Equal(t, repo{org: "DefaultCollection", project: "Platform", name: "api", cloneURL: "https://ado.example.com/tfs/DefaultCollection/Platform/_git/api"},
+		repoFromURL("https://ado.example.com/tfs/DefaultCollection/Platform/_git/api"))
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := fakeServer(t, "")
+	conn, err := anypb.New(&sourcespb.AzureRepos{Endpoint: server.URL, Credential: &sourcespb.AzureRepos_Token{Token: "expired"}})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "invalid credentials")
+}
+
+func TestSource_InitInvalidGlob(t *testing.T) {
+	conn, err := anypb.New(&sourcespb.AzureRepos{IncludeRepos: []string{"[a"}, Credential: &sourcespb.AzureRepos_Token{Token: "token"}})
+	require.NoError(t, err)
+	assert.ErrorContains(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, conn, 1), "invalid glob")
+}
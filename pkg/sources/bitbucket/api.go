@@ -0,0 +1,373 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+type link struct {
+	Name string `json:"name"`
+	Href string `json:"href"`
+}
+
+type cloudRepo struct {
+	Slug  string `json:"slug"`
+	Links struct {
+		HTML  link   `json:"html"`
+		Clone []link `json:"clone"`
+	} `json:"links"`
+}
+
+type serverRepo struct {
+	Slug    string `json:"slug"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Links struct {
+		Self  []link `json:"self"`
+		Clone []link `json:"clone"`
+	} `json:"links"`
+}
+
+type cloudPullRequest struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	CreatedOn   string `json:"created_on"`
+	Links       struct {
+		HTML link `json:"html"`
+	} `json:"links"`
+}
+
+type cloudComment struct {
+	ID      int    `json:"id"`
+	Deleted bool   `json:"deleted"`
+	Created string `json:"created_on"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Links struct {
+		HTML link `json:"html"`
+	} `json:"links"`
+}
+
+type serverUser struct {
+	EmailAddress string `json:"emailAddress"`
+}
+
+type serverPullRequest struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	CreatedDate int64  `json:"createdDate"`
+	Author      struct {
+		User serverUser `json:"user"`
+	} `json:"author"`
+	Links struct {
+		Self []link `json:"self"`
+	} `json:"links"`
+}
+
+type serverComment struct {
+	ID          int             `json:"id"`
+	Text        string          `json:"text"`
+	CreatedDate int64           `json:"createdDate"`
+	Author      serverUser      `json:"author"`
+	Comments    []serverComment `json:"comments"`
+}
+
+type variable struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Secured bool   `json:"secured"`
+}
+
+// enumerate returns the repositories of the workspaces or projects of the
+// connection, or of all those the credentials can read, and the workspaces
+// on Bitbucket Cloud.
+func (s *Source) enumerate(ctx context.Context) ([]repo, []string, error) {
+	if !s.cloud {
+		repos, err := s.serverRepos(ctx)
+		return repos, nil, err
+	}
+
+	workspaces := s.conn.GetWorkspaces()
+	if len(workspaces) == 0 {
+		permissions, err := cloudList[struct {
+			Workspace struct {
+				Slug string `json:"slug"`
+			} `json:"workspace"`
+		}](ctx, s, "/user/permissions/workspaces?pagelen=100")
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing workspaces: %w", err)
+		}
+		for _, p := range permissions {
+			workspaces = append(workspaces, p.Workspace.Slug)
+		}
+	}
+
+	query := url.Values{"pagelen": {"100"}}
+	if projects := s.conn.GetProjects(); len(projects) > 0 {
+		clauses := make([]string, len(projects))
+		for i, key := range projects {
+			clauses[i] = "project.key=" + strconv.Quote(key)
+		}
+		query.Set("q", strings.Join(clauses, " OR "))
+	}
+	var repos []repo
+	for _, workspace := range workspaces {
+		cloudRepos, err := cloudList[cloudRepo](ctx, s, "/repositories/"+url.PathEscape(workspace)+"?"+query.Encode())
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing the repositories of workspace %s: %w", workspace, err)
+		}
+		for _, r := range cloudRepos {
+			repos = append(repos, repo{owner: workspace, slug: r.Slug, link: r.Links.HTML.Href, cloneURL: cloneLink(r.Links.Clone, "https")})
+		}
+	}
+	return repos, workspaces, nil
+}
+
+// serverRepos returns the repositories of the projects of the connection, or
+// of all the projects the credentials can read.
+func (s *Source) serverRepos(ctx context.Context) ([]repo, error) {
+	projects := s.conn.GetProjects()
+	if len(projects) == 0 {
+		all, err := serverList[struct {
+			Key string `json:"key"`
+		}](ctx, s, "/projects")
+		if err != nil {
+			return nil, fmt.Errorf("error listing projects: %w", err)
+		}
+		for _, p := range all {
+			projects = append(projects, p.Key)
+		}
+	}
+
+	var repos []repo
+	for _, project := range projects {
+		serverRepos, err := serverList[serverRepo](ctx, s, "/projects/"+url.PathEscape(project)+"/repos")
+		if err != nil {
+			return nil, fmt.Errorf("error listing the repositories of project %s: %w", project, err)
+		}
+		for _, r := range serverRepos {
+			var web string
+			if len(r.Links.Self) > 0 {
+				web = strings.TrimSuffix(r.Links.Self[0].Href, "/browse")
+			}
+			repos = append(repos, repo{owner: r.Project.Key, slug: r.Slug, link: web, cloneURL: cloneLink(r.Links.Clone, "http")})
+		}
+	}
+	return repos, nil
+}
+
+// cloneLink returns the clone link of a protocol.
+func cloneLink(links []link, name string) string {
+	for _, l := range links {
+		if l.Name == name {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+// scanPullRequests scans the titles, descriptions and comments of the pull
+// requests of a repository, in all their states.
+func (s *Source) scanPullRequests(ctx context.Context, r repo, chunksChan chan *sources.Chunk) error {
+	if s.cloud {
+		base := "/repositories/" + url.PathEscape(r.owner) + "/" + url.PathEscape(r.slug) + "/pullrequests"
+		query := url.Values{"state": {"OPEN", "MERGED", "DECLINED", "SUPERSEDED"}, "pagelen": {"50"}}
+		pullRequests, err := cloudList[cloudPullRequest](ctx, s, base+"?"+query.Encode())
+		if err != nil {
+			return err
+		}
+		for _, pr := range pullRequests {
+			location := fmt.Sprintf("%s/%d", locationPullRequest, pr.ID)
+			skel := s.chunkSkeleton(r, location, pr.Title, "", pr.Links.HTML.Href, pr.CreatedOn)
+			if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(pr.Title+"\n\n"+pr.Description), chunksChan, s.chunkOptions()...); err != nil {
+				return err
+			}
+			comments, err := cloudList[cloudComment](ctx, s, fmt.Sprintf("%s/%d/comments?pagelen=100", base, pr.ID))
+			if err != nil {
+				return fmt.Errorf("error listing the comments of pull request %d: %w", pr.ID, err)
+			}
+			for _, c := range comments {
+				if c.Deleted || c.Content.Raw == "" {
+					continue
+				}
+				skel := s.chunkSkeleton(r, fmt.Sprintf("%s/%s/%d", location, locationComment, c.ID), pr.Title, "", c.Links.HTML.Href, c.Created)
+				if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(c.Content.Raw), chunksChan, s.chunkOptions()...); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	base := "/projects/" + url.PathEscape(r.owner) + "/repos/" + url.PathEscape(r.slug) + "/pull-requests"
+	pullRequests, err := serverList[serverPullRequest](ctx, s, base+"?state=ALL")
+	if err != nil {
+		return err
+	}
+	for _, pr := range pullRequests {
+		var prLink string
+		if len(pr.Links.Self) > 0 {
+			prLink = strings.TrimSuffix(pr.Links.Self[0].Href, "/overview")
+		}
+		location := fmt.Sprintf("%s/%d", locationPullRequest, pr.ID)
+		skel := s.chunkSkeleton(r, location, pr.Title, pr.Author.User.EmailAddress, prLink, serverTime(pr.CreatedDate))
+		if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(pr.Title+"\n\n"+pr.Description), chunksChan, s.chunkOptions()...); err != nil {
+			return err
+		}
+		activities, err := serverList[struct {
+			Action  string        `json:"action"`
+			Comment serverComment `json:"comment"`
+		}](ctx, s, fmt.Sprintf("%s/%d/activities", base, pr.ID))
+		if err != nil {
+			return fmt.Errorf("error listing the activities of pull request %d: %w", pr.ID, err)
+		}
+		// Replies are in the comments of their comment.
+		var comments []serverComment
+		for _, a := range activities {
+			if a.Action == "COMMENTED" {
+				comments = append(comments, a.Comment)
+			}
+		}
+		for len(comments) > 0 {
+			c := comments[0]
+			comments = append(comments[1:], c.Comments...)
+			link := fmt.Sprintf("%s/overview?commentId=%d", prLink, c.ID)
+			skel := s.chunkSkeleton(r, fmt.Sprintf("%s/%s/%d", location, locationComment, c.ID), pr.Title, c.Author.EmailAddress, link, serverTime(c.CreatedDate))
+			if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(c.Text), chunksChan, s.chunkOptions()...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scanVariables scans the values of the pipeline variables of a repository
+// or workspace of Bitbucket Cloud. The values of secured variables can't be
+// read. Repositories without pipelines are skipped.
+func (s *Source) scanVariables(ctx context.Context, r repo, path string, chunksChan chan *sources.Chunk) error {
+	variables, err := cloudList[variable](ctx, s, path)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var link string
+	if r.link != "" {
+		link = r.link + "/admin/pipelines/repository-variables"
+	}
+	for _, v := range variables {
+		if v.Secured || v.Value == "" {
+			continue
+		}
+		skel := s.chunkSkeleton(r, locationPipelineVariable+"/"+v.Key, "", "", link, "")
+		if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(v.Key+"="+v.Value), chunksChan, s.chunkOptions()...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serverTime formats the milliseconds since the epoch of Bitbucket Server.
+func serverTime(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
+
+// cloudList returns the values of all the pages of a collection of the API
+// of Bitbucket Cloud, which link to their next page.
+func cloudList[T any](ctx context.Context, s *Source, path string) ([]T, error) {
+	var all []T
+	next := s.api + path
+	for next != "" && !common.IsDone(ctx) {
+		var page struct {
+			Values []T    `json:"values"`
+			Next   string `json:"next"`
+		}
+		if err := s.get(ctx, next, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Values...)
+		next = page.Next
+	}
+	return all, nil
+}
+
+// serverList returns the values of all the pages of a collection of the API
+// of Bitbucket Server, which give the start of their next page.
+func serverList[T any](ctx context.Context, s *Source, path string) ([]T, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	var all []T
+	start := 0
+	for !common.IsDone(ctx) {
+		var page struct {
+			Values        []T  `json:"values"`
+			IsLastPage    bool `json:"isLastPage"`
+			NextPageStart int  `json:"nextPageStart"`
+		}
+		if err := s.get(ctx, fmt.Sprintf("%s%s%sstart=%d&limit=100", s.api, path, sep, start), &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Values...)
+		if page.IsLastPage || len(page.Values) == 0 {
+			break
+		}
+		start = page.NextPageStart
+	}
+	return all, nil
+}
+
+var errNotFound = errors.New("not found")
+
+// get decodes the JSON response of a request of the API.
+func (s *Source) get(ctx context.Context, reqURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	switch {
+	case s.tokens != nil:
+		token, err := s.tokens.Token()
+		if err != nil {
+			return fmt.Errorf("error getting access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	case s.user != "":
+		req.SetBasicAuth(s.user, s.password)
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+		return json.NewDecoder(res.Body).Decode(v)
+	case http.StatusUnauthorized:
+		return fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+	case http.StatusNotFound:
+		return errNotFound
+	}
+	return fmt.Errorf("unexpected status %d", res.StatusCode)
+}
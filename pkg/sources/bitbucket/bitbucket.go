@@ -0,0 +1,356 @@
+package bitbucket
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+	gogit "github.com/go-git/go-git/v5"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+const (
+	locationPullRequest      = "pull_request"
+	locationComment          = "comment"
+	locationPipelineVariable = "pipeline_variable"
+)
+
+// cloudEndpoint is the URL of the REST API of Bitbucket Cloud.
+var cloudEndpoint = "https://api.bitbucket.org/2.0"
+
+// Source scans the repositories of the workspaces of Bitbucket Cloud or of
+// the projects of Bitbucket Server, the descriptions and comments of their
+// pull requests, and the variables of their pipelines.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn *sourcespb.Bitbucket
+	// cloud is whether the source scans Bitbucket Cloud rather than Server.
+	cloud bool
+	// api is the URL of the REST API.
+	api    string
+	client *http.Client
+	// tokens are the access tokens of OAuth and access tokens, nil for app
+	// passwords and unauthenticated scans.
+	tokens   oauth2.TokenSource
+	user     string
+	password string
+
+	git         *git.Git
+	scanOptions *git.ScanOptions
+
+	ignoreRepos sources.Globs
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_BITBUCKET
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+func (s *Source) WithScanOptions(scanOptions *git.ScanOptions) {
+	s.scanOptions = scanOptions
+}
+
+// chunkOptions returns the options of the chunk readers of the API content,
+// taken from the archive options of the scan.
+func (s *Source) chunkOptions() []sources.ConfigOption {
+	if s.scanOptions == nil {
+		return nil
+	}
+	return s.scanOptions.ArchiveOptions.ChunkOptions()
+}
+
+// Init returns an initialized Bitbucket source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Bitbucket
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	var err error
+	if s.ignoreRepos, err = sources.CompileGlobs(conn.GetIgnoreRepos()); err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimSuffix(conn.GetEndpoint(), "/")
+	s.cloud = isCloud(endpoint)
+	s.api = cloudEndpoint
+	if !s.cloud {
+		s.api = endpoint + "/rest/api/1.0"
+	}
+	s.client = common.RetryableHttpClientTimeout(120)
+
+	switch cred := conn.Credential.(type) {
+	case *sourcespb.Bitbucket_BasicAuth:
+		// The username and app password on Bitbucket Cloud, or the username
+		// and password on Server.
+		s.user, s.password = cred.BasicAuth.GetUsername(), cred.BasicAuth.GetPassword()
+	case *sourcespb.Bitbucket_Token:
+		// Repository, project and workspace access tokens, or HTTP access
+		// tokens on Server.
+		s.tokens = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cred.Token})
+	case *sourcespb.Bitbucket_Oauth:
+		token := &oauth2.Token{AccessToken: cred.Oauth.GetAccessToken(), RefreshToken: cred.Oauth.GetRefreshToken()}
+		s.tokens = oauth2.StaticTokenSource(token)
+		if token.RefreshToken != "" {
+			tokenURL := "https://bitbucket.org/site/oauth2/access_token"
+			if !s.cloud {
+				tokenURL = endpoint + "/rest/oauth2/latest/token"
+			}
+			config := oauth2.Config{
+				ClientID:     cred.Oauth.GetClientId(),
+				ClientSecret: cred.Oauth.GetClientSecret(),
+				Endpoint:     oauth2.Endpoint{TokenURL: tokenURL},
+			}
+			tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, s.client)
+			s.tokens = oauth2.ReuseTokenSource(nil, config.TokenSource(tokenCtx, token))
+		}
+	}
+
+	if err := git.GitCmdCheck(); err != nil {
+		return err
+	}
+	s.git = git.NewGit(s.Type(), s.JobID(), s.SourceID(), s.name, s.verify, runtime.NumCPU(),
+		func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Bitbucket{
+					Bitbucket: &source_metadatapb.Bitbucket{
+						Commit:     sanitizer.UTF8(commit),
+						File:       sanitizer.UTF8(file),
+						Email:      sanitizer.UTF8(email),
+						Repository: sanitizer.UTF8(repository),
+						Link:       git.GenerateLink(repository, commit, file, line),
+						Timestamp:  sanitizer.UTF8(timestamp),
+						Line:       line,
+					},
+				},
+			}
+		})
+	return nil
+}
+
+// isCloud returns whether an endpoint is Bitbucket Cloud, which is the
+// default.
+func isCloud(endpoint string) bool {
+	if endpoint == "" {
+		return true
+	}
+	u, err := url.Parse(endpoint)
+	return err == nil && (u.Host == "bitbucket.org" || u.Host == "api.bitbucket.org")
+}
+
+// repo is a repository to scan.
+type repo struct {
+	// owner is the workspace on Bitbucket Cloud, or the key of the project
+	// on Server. It is empty for repositories whose API isn't known.
+	owner string
+	slug  string
+	// link is the URL of the repository on the web.
+	link     string
+	cloneURL string
+}
+
+func (r repo) fullName() string {
+	return r.owner + "/" + r.slug
+}
+
+// Chunks emits the chunks of the history of the repositories, of the
+// descriptions and comments of their pull requests, and of the variables of
+// their pipelines.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	var repos []repo
+	var workspaces []string
+	if len(s.conn.GetRepositories()) > 0 {
+		for _, cloneURL := range s.conn.GetRepositories() {
+			repos = append(repos, s.repoFromURL(cloneURL))
+		}
+	} else {
+		var err error
+		if repos, workspaces, err = s.enumerate(ctx); err != nil {
+			return err
+		}
+	}
+
+	kept := repos[:0]
+	for _, r := range repos {
+		if !s.ignoreRepo(ctx, r.fullName()) {
+			kept = append(kept, r)
+		}
+	}
+	repos = kept
+	sort.Slice(repos, func(i, j int) bool { return repos[i].cloneURL < repos[j].cloneURL })
+
+	scanErrs := sources.NewScanErrors()
+	for i, r := range repos {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(repos), fmt.Sprintf("Repo: %s", r.cloneURL), "")
+		r := r
+		s.jobPool.Go(func() error {
+			if err := s.scanRepo(ctx, r, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error scanning repo %s: %w", r.cloneURL, err))
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if s.cloud && !s.conn.GetSkipPipelineVariables() {
+		for _, workspace := range workspaces {
+			path := "/workspaces/" + url.PathEscape(workspace) + "/pipelines-config/variables?pagelen=100"
+			if err := s.scanVariables(ctx, repo{owner: workspace}, path, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error scanning the variables of workspace %s: %w", workspace, err))
+			}
+		}
+	}
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	s.SetProgressComplete(len(repos), len(repos), "Completed Bitbucket scan", "")
+	return nil
+}
+
+// repoFromURL returns the repository of a clone URL, with its owner if it is
+// a URL of Bitbucket Cloud or Server.
+func (s *Source) repoFromURL(cloneURL string) repo {
+	r := repo{cloneURL: cloneURL}
+	u, err := url.Parse(cloneURL)
+	if err != nil {
+		return r
+	}
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/"), "/")
+	switch {
+	case s.cloud && len(parts) == 2:
+		// https://bitbucket.org/workspace/repo.git
+		r.owner, r.slug = parts[0], parts[1]
+		r.link = "https://bitbucket.org/" + r.fullName()
+	case !s.cloud && len(parts) >= 3 && parts[len(parts)-3] == "scm":
+		// https://bitbucket.example.com/scm/project/repo.git
+		r.owner, r.slug = strings.ToUpper(parts[len(parts)-2]), parts[len(parts)-1]
+		r.link = strings.TrimSuffix(s.conn.GetEndpoint(), "/") + "/projects/" + r.owner + "/repos/" + r.slug
+	}
+	return r
+}
+
+func (s *Source) ignoreRepo(ctx context.Context, name string) bool {
+	if s.ignoreRepos.Match(name) {
+		ctx.Logger().V(2).Info("Ignoring repo", "repo", name)
+		return true
+	}
+	return false
+}
+
+// scanRepo scans the history of a repository, its pull requests, and the
+// variables of its pipelines.
+func (s *Source) scanRepo(ctx context.Context, r repo, chunksChan chan *sources.Chunk) error {
+	path, gitRepo, err := s.clone(ctx, r.cloneURL)
+	defer os.RemoveAll(path)
+	if err != nil {
+		return err
+	}
+	if err := s.git.ScanRepo(ctx, gitRepo, path, s.scanOptions, chunksChan); err != nil {
+		return err
+	}
+
+	if r.owner == "" {
+		return nil
+	}
+	if !s.conn.GetSkipPullRequests() {
+		if err := s.scanPullRequests(ctx, r, chunksChan); err != nil {
+			return fmt.Errorf("error scanning pull requests: %w", err)
+		}
+	}
+	if s.cloud && !s.conn.GetSkipPipelineVariables() {
+		path := "/repositories/" + url.PathEscape(r.owner) + "/" + url.PathEscape(r.slug) + "/pipelines_config/variables?pagelen=100"
+		if err := s.scanVariables(ctx, r, path, chunksChan); err != nil {
+			return fmt.Errorf("error scanning pipeline variables: %w", err)
+		}
+	}
+	return nil
+}
+
+// clone clones a repository with the credentials of the source if it is
+// cloned over HTTP.
+func (s *Source) clone(ctx context.Context, cloneURL string) (string, *gogit.Repository, error) {
+	if !strings.HasPrefix(cloneURL, "https://") && !strings.HasPrefix(cloneURL, "http://") {
+		return git.CloneRepoUsingUnauthenticated(ctx, cloneURL)
+	}
+	switch {
+	case s.tokens != nil:
+		token, err := s.tokens.Token()
+		if err != nil {
+			return "", nil, fmt.Errorf("error getting access token: %w", err)
+		}
+		return git.CloneRepoUsingToken(ctx, token.AccessToken, cloneURL, "x-token-auth")
+	case s.user != "":
+		return git.CloneRepoUsingToken(ctx, s.password, cloneURL, s.user)
+	}
+	return git.CloneRepoUsingUnauthenticated(ctx, cloneURL)
+}
+
+func (s *Source) chunkSkeleton(r repo, location, title, email, link, timestamp string) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Bitbucket{
+				Bitbucket: &source_metadatapb.Bitbucket{
+					Repository: r.cloneURL,
+					Workspace:  r.owner,
+					Title:      title,
+					Email:      email,
+					Link:       link,
+					Timestamp:  timestamp,
+					Location:   location,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
@@ -0,0 +1,192 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+// gitRepo returns the file:// URL of a repository of a commit of a file.
+func gitRepo(t *testing.T, file, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=Ada", "-c", "user.email=ada@example.com", "commit", "-qm", "init"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return "file://" + dir
+}
+
+// serve returns a server of paths whose responses are JSON, which requires
+// the basic auth of ada:app-password.
+func serve(t *testing.T, responses map[string]any) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "ada" || pass != "app-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		res, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// chunks returns the data of the chunks of a source by location, or file
+// for the chunks of git, and their metadata.
+func chunks(t *testing.T, conn *sourcespb.Bitbucket) (map[string]string, map[string]*source_metadatapb.Bitbucket) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+	s.WithScanOptions(git.NewScanOptions(git.ScanOptionFilter(common.FilterEmpty())))
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.Bitbucket)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetBitbucket()
+		key := m.GetLocation()
+		if key == "" {
+			key = m.GetFile()
+		}
+		got[key] += string(chunk.Data)
+		metadata[key] = m
+	}
+	return got, metadata
+}
+
+func TestSource_ChunksCloud(t *testing.T) {
+	cloneURL := gitRepo(t, "config.env", "TOKEN=secret\n")
+	server := serve(t, map[string]any{
+		"/2.0/user/permissions/workspaces": map[string]any{"values": []any{map[string]any{"workspace": map[string]any{"slug": "acme"}}}},
+		"/2.0/repositories/acme": map[string]any{"values": []any{
+			map[string]any{"slug": "api", "links": map[string]any{
+				"html":  map[string]any{"href": "https://bitbucket.org/acme/api"},
+				"clone": []any{map[string]any{"name": "https", "href": cloneURL}, map[string]any{"name": "ssh", "href": "git@bitbucket.org:acme/api.git"}},
+			}},
+			map[string]any{"slug": "archived", "links": map[string]any{"clone": []any{map[string]any{"name": "https", "href": "https://bitbucket.org/acme/archived.git"}}}},
+		}},
+		"/2.0/repositories/acme/api/pullrequests": map[string]any{"values": []any{map[string]any{
+			"id": 7, "title": "Add deploy", "description": "uses password hunter2", "created_on": "2024-03-01T10:00:00+00:00",
+			"links": map[string]any{"html": map[string]any{"href": "https://bitbucket.org/acme/api/pull-requests/7"}},
+		}}},
+		"/2.0/repositories/acme/api/pullrequests/7/comments": map[string]any{"values": []any{
+			map[string]any{"id": 70, "content": map[string]any{"raw": "rotated to hunter3"}, "links": map[string]any{"html": map[string]any{"href": "https://bitbucket.org/acme/api/pull-requests/7#comment-70"}}},
+			map[string]any{"id": 71, "deleted": true, "content": map[string]any{"raw": "deleted"}},
+		}},
+		"/2.0/repositories/acme/api/pipelines_config/variables": map[string]any{"values": []any{
+			map[string]any{"key": "DEPLOY_KEY", "value": "plain"},
+			map[string]any{"key": "SECURED", "secured": true},
+		}},
+		"/2.0/workspaces/acme/pipelines-config/variables": map[string]any{"values": []any{map[string]any{"key": "NPM_TOKEN", "value": "npm_abc"}}},
+	})
+	cloudEndpoint = server.URL + "/2.0"
+	t.Cleanup(func() { cloudEndpoint = "https://api.bitbucket.org/2.0" })
+
+	got, metadata := chunks(t, &sourcespb.Bitbucket{
+		Credential:  &sourcespb.Bitbucket_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: "ada", Password: "app-password"}},
+		IgnoreRepos: []string{"acme/archived"},
+	})
+	assert.Equal(t, map[string]string{
+		"config.env":                   "TOKEN=secret\n",
+		"pull_request/7":               "Add deploy\n\nuses password hunter2",
+		"pull_request/7/comment/70":    "rotated to hunter3",
+		"pipeline_variable/DEPLOY_KEY": "DEPLOY_KEY=plain",
+		"pipeline_variable/NPM_TOKEN":  "NPM_TOKEN=npm_abc",
+	}, got)
+	assert.Equal(t, &source_metadatapb.Bitbucket{
+		Repository: cloneURL,
+		Workspace:  "acme",
+		Title:      "Add deploy",
+		Link:       "https://bitbucket.org/acme/api/pull-requests/7#comment-70",
+		Location:   "pull_request/7/comment/70",
+	}, metadata["pull_request/7/comment/70"])
+	assert.Equal(t, "https://bitbucket.org/acme/api/admin/pipelines/repository-variables", metadata["pipeline_variable/DEPLOY_KEY"].GetLink())
+	assert.Equal(t, "Ada <ada@example.com>", metadata["config.env"].GetEmail())
+}
+
+func TestSource_ChunksServer(t *testing.T) {
+	cloneURL := gitRepo(t, "config.env", "TOKEN=secret\n")
+	server := serve(t, map[string]any{
+		"/rest/api/1.0/projects/OPS/repos": map[string]any{"isLastPage": true, "values": []any{map[string]any{
+			"slug": "infra", "project": map[string]any{"key": "OPS"},
+			"links": map[string]any{"clone": []any{map[string]any{"name": "http", "href": cloneURL}}},
+		}}},
+		"/rest/api/1.0/projects/OPS/repos/infra/pull-requests": map[string]any{"isLastPage": true, "values": []any{map[string]any{
+			"id": 3, "title": "Terraform", "description": "password hunter2", "createdDate": 1709287200000,
+			"author": map[string]any{"user": map[string]any{"emailAddress": "ada@example.com"}},
+			"links":  map[string]any{"self": []any{map[string]any{"href": "https://bitbucket.example.com/projects/OPS/repos/infra/pull-requests/3/overview"}}},
+		}}},
+		"/rest/api/1.0/projects/OPS/repos/infra/pull-requests/3/activities": map[string]any{"isLastPage": true, "values": []any{
+			map[string]any{"action": "APPROVED"},
+			map[string]any{"action": "COMMENTED", "comment": map[string]any{
+				"id": 30, "text": "first", "author": map[string]any{"emailAddress": "bob@example.com"},
+				"comments": []any{map[string]any{"id": 31, "text": "reply with hunter3"}},
+			}},
+		}},
+	})
+
+	got, metadata := chunks(t, &sourcespb.Bitbucket{
+		Endpoint:   server.URL,
+		Credential: &sourcespb.Bitbucket_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: "ada", Password: "app-password"}},
+		Projects:   []string{"OPS"},
+	})
+	assert.Equal(t, map[string]string{
+		"config.env":                "TOKEN=secret\n",
+		"pull_request/3":            "Terraform\n\npassword hunter2",
+		"pull_request/3/comment/30": "first",
+		"pull_request/3/comment/31": "reply with hunter3",
+	}, got)
+	assert.Equal(t, "2024-03-01T10:00:00Z", metadata["pull_request/3"].GetTimestamp())
+	assert.Equal(t, "ada@example.com", metadata["pull_request/3"].GetEmail())
+	assert.Equal(t, "https://bitbucket.example.com/projects/OPS/repos/infra/pull-requests/3/overview?commentId=31", metadata["pull_request/3/comment/31"].GetLink())
+}
+
+func TestSource_RepoFromURL(t *testing.T) {
+	cloud := &Source{cloud: true, conn: &sourcespb.Bitbucket{}}
+	assert.Equal(t, repo{owner: "acme", slug: "api", link: "https://bitbucket.org/acme/api", cloneURL: "https://bitbucket.org/acme/api.git"},
+		cloud.repoFromURL("https://bitbucket.org/acme/api.git"))
+	server := &Source{conn: &sourcespb.Bitbucket{Endpoint: "https://bitbucket.example.com/"}}
+	assert.Equal(t, repo{owner: "OPS", slug: "infra", link: "https://bitbucket.example.com/projects/OPS/repos/infra", cloneURL: "https://bitbucket.example.com/scm/ops/infra.git"},
+		server.repoFromURL("https://bitbucket.example.com/scm/ops/infra.git"))
+	assert.Equal(t, repo{cloneURL: "https://bitbucket.example.com/other.git"}, server.repoFromURL("https://bitbucket.example.com/other.git"))
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := serve(t, nil)
+	conn, err := anypb.New(&sourcespb.Bitbucket{Endpoint: server.URL, Credential: &sourcespb.Bitbucket_Token{Token: "expired"}})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "invalid credentials")
+}
+
+func TestSource_InitInvalidGlob(t *testing.T) {
+	conn, err := anypb.New(&sourcespb.Bitbucket{IgnoreRepos: []string{"[a"}, Credential: &sourcespb.Bitbucket_Token{Token: "token"}})
+	require.NoError(t, err)
+	assert.ErrorContains(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, conn, 1), "invalid glob")
+}
@@ -0,0 +1,97 @@
+package sources
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Checksums is the set of the checksums of the objects that were scanned,
+// so that objects with the same content are only scanned once. Sets loaded
+// from a file are saved back to it, for the next scans to skip the objects
+// that did not change.
+type Checksums struct {
+	path string
+
+	mu   sync.Mutex
+	sums map[string]struct{}
+}
+
+type checksumsFile struct {
+	Checksums []string `json:"checksums"`
+}
+
+// LoadChecksums reads the checksums of the file at path, which may not
+// exist yet. The set of an empty path is only kept in memory.
+func LoadChecksums(path string) (*Checksums, error) {
+	c := &Checksums{path: path, sums: make(map[string]struct{})}
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading checksums: %w", err)
+	}
+	var file checksumsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error decoding checksums %s: %w", path, err)
+	}
+	for _, sum := range file.Checksums {
+		c.sums[sum] = struct{}{}
+	}
+	return c, nil
+}
+
+// Claim adds a checksum to the set, and returns whether it was not in it,
+// which means that its object should be scanned. Objects without a
+// checksum are always scanned.
+func (c *Checksums) Claim(sum string) bool {
+	if sum == "" {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.sums[sum]; ok {
+		return false
+	}
+	c.sums[sum] = struct{}{}
+	return true
+}
+
+// Forget removes a checksum from the set, for the objects that could not be
+// scanned to be scanned again.
+func (c *Checksums) Forget(sum string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sums, sum)
+}
+
+// Save writes the checksums to the file they were loaded from, if any. The
+// file is replaced at once, like the files of SaveState.
+func (c *Checksums) Save() error {
+	if c.path == "" {
+		return nil
+	}
+	c.mu.Lock()
+	file := checksumsFile{Checksums: make([]string, 0, len(c.sums))}
+	for sum := range c.sums {
+		file.Checksums = append(file.Checksums, sum)
+	}
+	c.mu.Unlock()
+	sort.Strings(file.Checksums)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.path, data); err != nil {
+		return fmt.Errorf("error saving checksums: %w", err)
+	}
+	return nil
+}
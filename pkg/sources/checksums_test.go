@@ -0,0 +1,50 @@
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksums(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checksums.json")
+	c, err := LoadChecksums(path)
+	require.NoError(t, err)
+
+	assert.True(t, c.Claim("sha256:a"))
+	assert.False(t, c.Claim("sha256:a"))
+	assert.True(t, c.Claim(""))
+	assert.True(t, c.Claim(""))
+	assert.True(t, c.Claim("sha256:b"))
+	c.Forget("sha256:b")
+	assert.True(t, c.Claim("sha1:c"))
+	require.NoError(t, c.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"checksums":["sha1:c","sha256:a"]}`, string(data))
+
+	// The next scans skip the checksums of the last ones.
+	c, err = LoadChecksums(path)
+	require.NoError(t, err)
+	assert.False(t, c.Claim("sha256:a"))
+	assert.True(t, c.Claim("sha256:b"))
+}
+
+func TestChecksums_InMemory(t *testing.T) {
+	c, err := LoadChecksums("")
+	require.NoError(t, err)
+	assert.True(t, c.Claim("sha256:a"))
+	assert.False(t, c.Claim("sha256:a"))
+	assert.NoError(t, c.Save())
+}
+
+func TestLoadChecksums_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checksums.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	_, err := LoadChecksums(path)
+	assert.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 )
 
@@ -18,32 +19,78 @@ const (
 	TotalChunkSize = ChunkSize + PeekSize
 )
 
-// Chunker takes a chunk and splits it into chunks of ChunkSize.
-func Chunker(originalChunk *Chunk) chan *Chunk {
+// boundaries are the bytes chunks are preferably split after when a line does
+// not end in the second half of a chunk, as in minified files.
+var boundaries = []byte{' ', '\t', ',', ';', '{', '}'}
+
+// splitPoint returns where to split the window of data read ahead so that the
+// next chunk starts at the beginning of a line, or at least between tokens of
+// a long line. The split is never before half of the chunk size, so chunks
+// don't get small.
+func splitPoint(window []byte, chunkSize int) int {
+	if len(window) <= chunkSize {
+		return len(window)
+	}
+	if i := bytes.LastIndexByte(window[:chunkSize], '\n'); i >= chunkSize/2 {
+		return i + 1
+	}
+	if i := bytes.LastIndexAny(window[:chunkSize], string(boundaries)); i >= chunkSize/2 {
+		return i + 1
+	}
+	return chunkSize
+}
+
+// position is where a chunk starts in the data it was read from.
+type position struct {
+	offset    int64
+	lineStart int64
+	lines     int64
+}
+
+// advance returns the position of the data following consumed, which starts
+// at p.
+func (p position) advance(consumed []byte) position {
+	next := position{offset: p.offset + int64(len(consumed)), lineStart: p.lineStart, lines: p.lines}
+	if i := bytes.LastIndexByte(consumed, '\n'); i >= 0 {
+		next.lineStart = p.offset + int64(i) + 1
+		next.lines += int64(bytes.Count(consumed, []byte{'\n'}))
+	}
+	return next
+}
+
+// Chunker takes a chunk and splits it into chunks of about ChunkSize, each
+// followed by the start of the next one. Chunks are split at line ends where
+// possible, and the offsets of the chunks are kept. The options set the size
+// of the chunks and of the start of the next one, which default to ChunkSize
+// and PeekSize.
+func Chunker(originalChunk *Chunk, opts ...ConfigOption) chan *Chunk {
+	config := applyOptions(opts)
 	chunkChan := make(chan *Chunk)
 	go func() {
 		defer close(chunkChan)
-		if len(originalChunk.Data) <= TotalChunkSize {
+		totalSize := config.totalSize
+		if len(originalChunk.Data) <= totalSize {
 			chunkChan <- originalChunk
 			return
 		}
 
-		r := bytes.NewReader(originalChunk.Data)
-		reader := bufio.NewReaderSize(bufio.NewReader(r), ChunkSize)
-		for {
-			chunkBytes := make([]byte, TotalChunkSize)
-			chunk := *originalChunk
-			chunkBytes = chunkBytes[:ChunkSize]
-			n, err := reader.Read(chunkBytes)
-			if n > 0 {
-				peekData, _ := reader.Peek(TotalChunkSize - n)
-				chunkBytes = append(chunkBytes[:n], peekData...)
-				chunk.Data = chunkBytes
-				chunkChan <- &chunk
-			}
-			if err != nil {
-				break
+		data := originalChunk.Data
+		p := position{offset: originalChunk.Offset, lineStart: originalChunk.LineStart, lines: originalChunk.LineOffset}
+		for pos := 0; pos < len(data); {
+			end := pos + totalSize
+			if end > len(data) {
+				end = len(data)
 			}
+			window := data[pos:end]
+			cut := splitPoint(window, config.chunkSize)
+
+			chunk := *originalChunk
+			chunk.Data = append(make([]byte, 0, len(window)), window...)
+			chunk.Offset, chunk.LineStart, chunk.LineOffset = p.offset, p.lineStart, p.lines
+			chunkChan <- &chunk
+
+			p = p.advance(window[:cut])
+			pos += cut
 		}
 	}()
 	return chunkChan
@@ -76,7 +123,8 @@ func WithPeekSize(size int) ConfigOption {
 // it contains the data and error of a chunk.
 type ChunkResult struct {
 	data []byte
-	err  error
+	position
+	err error
 }
 
 // Bytes for a ChunkResult.
@@ -84,6 +132,21 @@ func (cr ChunkResult) Bytes() []byte {
 	return cr.data
 }
 
+// Offset is the offset of the chunk in the data read.
+func (cr ChunkResult) Offset() int64 {
+	return cr.offset
+}
+
+// LineStart is the offset of the start of the line the chunk begins in.
+func (cr ChunkResult) LineStart() int64 {
+	return cr.lineStart
+}
+
+// LineOffset is the number of lines before the chunk in the data read.
+func (cr ChunkResult) LineOffset() int64 {
+	return cr.lines
+}
+
 // Error for a ChunkResult.
 func (cr ChunkResult) Error() error {
 	return cr.err
@@ -101,6 +164,23 @@ func NewChunkReader(opts ...ConfigOption) ChunkReader {
 	return createReaderFn(config)
 }
 
+// ChunkReaderTo reads reader in chunks and sends them to chunksChan, each a
+// copy of skel with the data and position of the chunk.
+func ChunkReaderTo(ctx context.Context, skel *Chunk, reader io.Reader, chunksChan chan *Chunk, opts ...ConfigOption) error {
+	for data := range NewChunkReader(opts...)(ctx, reader) {
+		if err := data.Error(); err != nil {
+			return err
+		}
+		chunk := *skel
+		chunk.Data = data.Bytes()
+		chunk.Offset, chunk.LineStart, chunk.LineOffset = data.Offset(), data.LineStart(), data.LineOffset()
+		if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func applyOptions(opts []ConfigOption) *chunkReaderConfig {
 	// Set defaults.
 	config := &chunkReaderConfig{
@@ -125,35 +205,43 @@ func createReaderFn(config *chunkReaderConfig) ChunkReader {
 
 func readInChunks(ctx context.Context, reader io.Reader, config *chunkReaderConfig) <-chan ChunkResult {
 	const channelSize = 1
-	chunkReader := bufio.NewReaderSize(reader, config.chunkSize)
+	chunkReader := bufio.NewReaderSize(reader, config.totalSize)
 	chunkResultChan := make(chan ChunkResult, channelSize)
 
 	go func() {
 		defer close(chunkResultChan)
 
+		var p position
 		for {
-			chunkRes := ChunkResult{}
-			chunkBytes := make([]byte, config.totalSize)
-			chunkBytes = chunkBytes[:config.chunkSize]
-			n, err := chunkReader.Read(chunkBytes)
-			if n > 0 {
-				peekData, _ := chunkReader.Peek(config.totalSize - n)
-				chunkBytes = append(chunkBytes[:n], peekData...)
-				chunkRes.data = chunkBytes
+			// Read ahead a whole chunk with its peek data, and only consume
+			// the data up to the split point.
+			window, err := chunkReader.Peek(config.totalSize)
+			if errors.Is(err, bufio.ErrBufferFull) || errors.Is(err, io.EOF) {
+				err = nil
+			}
+			if len(window) == 0 && err == nil {
+				return
 			}
 
-			// If there is an error other than EOF, or if we have read some bytes, send the chunk.
-			if err != nil && !errors.Is(err, io.EOF) || n > 0 {
-				if err != nil && !errors.Is(err, io.EOF) {
-					ctx.Logger().Error(err, "error reading chunk")
-					chunkRes.err = err
-				}
-				chunkResultChan <- chunkRes
+			chunkRes := ChunkResult{position: p}
+			cut := splitPoint(window, config.chunkSize)
+			if len(window) > 0 {
+				chunkRes.data = append(make([]byte, 0, len(window)), window...)
 			}
+			if err != nil {
+				ctx.Logger().Error(err, "error reading chunk")
+				chunkRes.err = err
+				cut = len(window)
+			}
+			chunkResultChan <- chunkRes
 
 			if err != nil {
 				return
 			}
+			p = p.advance(window[:cut])
+			if _, err := chunkReader.Discard(cut); err != nil {
+				return
+			}
 		}
 	}()
 	return chunkResultChan
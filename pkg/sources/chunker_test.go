@@ -8,6 +8,7 @@ import (
 
 	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 )
@@ -174,6 +175,107 @@ func TestNewChunkedReader(t *testing.T) {
 	}
 }
 
+func TestNewChunkedReader_Lines(t *testing.T) {
+	line := strings.Repeat("a", 99) + "\n"
+	readerFunc := NewChunkReader(WithChunkSize(1024), WithPeekSize(256))
+
+	var results []ChunkResult
+	for res := range readerFunc(context.Background(), strings.NewReader(strings.Repeat(line, 30))) {
+		results = append(results, res)
+	}
+
+	// Chunks are split after the last line ending before the chunk size.
+	assert.Len(t, results, 3)
+	assert.Equal(t, []int64{0, 1000, 2000}, []int64{results[0].Offset(), results[1].Offset(), results[2].Offset()})
+	assert.Equal(t, []int64{0, 10, 20}, []int64{results[0].LineOffset(), results[1].LineOffset(), results[2].LineOffset()})
+	for _, res := range results {
+		assert.Equal(t, res.Offset(), res.LineStart())
+		assert.True(t, strings.HasPrefix(string(res.Bytes()), line))
+	}
+	assert.Len(t, results[0].Bytes(), 1024+256)
+}
+
+func TestNewChunkedReader_LongLine(t *testing.T) {
+	// A minified file is a single line, split between tokens.
+	input := strings.Repeat("var x=1;", 400)
+	readerFunc := NewChunkReader(WithChunkSize(1024), WithPeekSize(256))
+
+	var results []ChunkResult
+	for res := range readerFunc(context.Background(), strings.NewReader(input)) {
+		results = append(results, res)
+	}
+
+	var offset int64
+	for _, res := range results {
+		assert.Equal(t, offset, res.Offset())
+		assert.Equal(t, int64(0), res.LineStart())
+		assert.True(t, strings.HasPrefix(string(res.Bytes()), "var x=1;"))
+		assert.Equal(t, input[offset:offset+int64(len(res.Bytes()))], string(res.Bytes()))
+		offset += 1024
+	}
+}
+
+func TestChunkReaderTo(t *testing.T) {
+	line := strings.Repeat("a", 99) + "\n"
+	skel := &Chunk{SourceName: "test", SourceID: 7}
+	chunksChan := make(chan *Chunk, 10)
+	err := ChunkReaderTo(context.Background(), skel, strings.NewReader(strings.Repeat(line, 30)), chunksChan, WithChunkSize(1024), WithPeekSize(256))
+	assert.NoError(t, err)
+	close(chunksChan)
+
+	var chunks []*Chunk
+	for chunk := range chunksChan {
+		chunks = append(chunks, chunk)
+	}
+	assert.Len(t, chunks, 3)
+	for i, chunk := range chunks {
+		assert.Equal(t, "test", chunk.SourceName)
+		assert.Equal(t, int64(7), chunk.SourceID)
+		assert.Equal(t, int64(i*1000), chunk.Offset)
+		assert.Equal(t, int64(i*10), chunk.LineOffset)
+	}
+	assert.Nil(t, skel.Data)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = ChunkReaderTo(ctx, skel, strings.NewReader(line), make(chan *Chunk))
+	assert.ErrorIs(t, err, ctx.Err())
+}
+
+func TestChunker_Offsets(t *testing.T) {
+	line := strings.Repeat("b", 999) + "\n"
+	original := &Chunk{Data: []byte(strings.Repeat(line, 40)), Offset: 500, LineStart: 500}
+
+	var chunks []*Chunk
+	for chunk := range Chunker(original) {
+		chunks = append(chunks, chunk)
+	}
+
+	assert.Len(t, chunks, 4)
+	for i, chunk := range chunks {
+		assert.Equal(t, int64(500+i*10000), chunk.Offset)
+		assert.Equal(t, chunk.Offset, chunk.LineStart)
+		assert.Equal(t, int64(i*10), chunk.LineOffset)
+		assert.Equal(t, byte('b'), chunk.Data[0])
+	}
+}
+
+func TestChunker_PeekSize(t *testing.T) {
+	line := strings.Repeat("b", 999) + "\n"
+	original := &Chunk{Data: []byte(strings.Repeat(line, 40))}
+
+	var chunks []*Chunk
+	for chunk := range Chunker(original, WithPeekSize(100)) {
+		chunks = append(chunks, chunk)
+	}
+
+	require.Len(t, chunks, 4)
+	for _, chunk := range chunks[:3] {
+		assert.Equal(t, ChunkSize+100, len(chunk.Data))
+	}
+	assert.Equal(t, 10000, len(chunks[3].Data))
+}
+
 func BenchmarkChunkReader(b *testing.B) {
 	var bigChunk = make([]byte, 1<<24) // 16MB
 
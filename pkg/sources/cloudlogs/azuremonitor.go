@@ -0,0 +1,293 @@
+package cloudlogs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// logAnalyticsResource is the resource of the access tokens of the Logs API
+// of Azure Monitor.
+const logAnalyticsResource = "https://api.loganalytics.io"
+
+var (
+	// authorityHost is the host of the Microsoft identity platform, which
+	// issues the access tokens of service principals.
+	authorityHost = "https://login.microsoftonline.com"
+	// imdsEndpoint is where the instance metadata service of Azure issues
+	// the access tokens of managed identities.
+	imdsEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+)
+
+// azureMonitor is the Logs API of Azure Monitor, whose log groups are the
+// tables of a Log Analytics workspace, and whose events are filtered by KQL
+// predicates. The rows of tables are scanned as JSON objects.
+type azureMonitor struct {
+	client    *http.Client
+	endpoint  string
+	workspace string
+	tokens    oauth2.TokenSource
+}
+
+// Columns added to the results of queries for the resource and the ID of
+// their rows, which are hidden columns.
+const (
+	resourceColumn = "trufflehog_resource_id"
+	itemColumn     = "trufflehog_item_id"
+)
+
+func newAzureMonitor(conn *sourcespb.AzureMonitorLogs, endpoint string) (*azureMonitor, error) {
+	if conn.GetWorkspaceId() == "" {
+		return nil, errors.New("a workspace ID is required")
+	}
+	if endpoint == "" {
+		endpoint = logAnalyticsResource
+	}
+	a := &azureMonitor{
+		client:    common.RetryableHttpClientTimeout(120),
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		workspace: conn.GetWorkspaceId(),
+	}
+
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, a.client)
+	switch cred := conn.GetCredential().(type) {
+	case *sourcespb.AzureMonitorLogs_ServicePrincipal:
+		config := clientcredentials.Config{
+			ClientID:     cred.ServicePrincipal.GetClientId(),
+			ClientSecret: cred.ServicePrincipal.GetClientSecret(),
+			TokenURL:     authorityHost + "/" + url.PathEscape(cred.ServicePrincipal.GetTenantId()) + "/oauth2/v2.0/token",
+			Scopes:       []string{logAnalyticsResource + "/.default"},
+		}
+		a.tokens = config.TokenSource(tokenCtx)
+	case *sourcespb.AzureMonitorLogs_ManagedIdentity:
+		a.tokens = oauth2.ReuseTokenSource(nil, managedIdentityTokens{
+			ctx:      tokenCtx,
+			client:   a.client,
+			clientID: conn.GetManagedIdentityClientId(),
+		})
+	default:
+		return nil, errors.New("a service principal or managed identity is required")
+	}
+	return a, nil
+}
+
+func (a *azureMonitor) name() string {
+	return "azure_monitor"
+}
+
+// groups returns the tables of the workspace that have rows.
+func (a *azureMonitor) groups(ctx context.Context) ([]string, error) {
+	table, err := a.query(ctx, "union withsource=TableName * | distinct TableName | order by TableName asc")
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		if len(row) > 0 {
+			if name, ok := row[0].(string); ok {
+				groups = append(groups, name)
+			}
+		}
+	}
+	return groups, nil
+}
+
+// events pages through the rows of a table in the order of their time and
+// IDs, as the API has no cursors. Rows of tables without IDs that have the
+// same time as the last row of a batch are not paged to.
+func (a *azureMonitor) events(ctx context.Context, group string, q query, fn func([]event) error) error {
+	base := "['" + strings.ReplaceAll(group, "'", `\'`) + "']"
+	if !q.since.IsZero() {
+		base += fmt.Sprintf(" | where TimeGenerated >= datetime(%s)", q.since.UTC().Format(time.RFC3339Nano))
+	}
+	if !q.until.IsZero() {
+		base += fmt.Sprintf(" | where TimeGenerated < datetime(%s)", q.until.UTC().Format(time.RFC3339Nano))
+	}
+	if q.filter != "" {
+		base += " | where " + q.filter
+	}
+	base += fmt.Sprintf(` | extend %s = column_ifexists("_ResourceId", ""), %s = column_ifexists("_ItemId", "")`, resourceColumn, itemColumn)
+
+	var after string
+	for {
+		kql := base + after + fmt.Sprintf(" | order by TimeGenerated asc, %s asc | take %d", itemColumn, q.batchSize)
+		table, err := a.query(ctx, kql)
+		if err != nil {
+			return err
+		}
+		events := table.events()
+		if err := fn(events); err != nil {
+			return err
+		}
+		if len(table.Rows) < q.batchSize || len(events) == 0 {
+			return nil
+		}
+		last := events[len(events)-1]
+		ts := last.timestamp.UTC().Format(time.RFC3339Nano)
+		after = fmt.Sprintf(" | where TimeGenerated > datetime(%s) or (TimeGenerated == datetime(%s) and %s > %s)", ts, ts, itemColumn, strconv.Quote(last.id))
+	}
+}
+
+// table is the table of the results of a query.
+type table struct {
+	Columns []struct {
+		Name string `json:"name"`
+	} `json:"columns"`
+	Rows [][]any `json:"rows"`
+}
+
+// events returns the rows of the table as events, with the values of their
+// columns as JSON objects.
+func (t *table) events() []event {
+	events := make([]event, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		var e event
+		var message bytes.Buffer
+		message.WriteByte('{')
+		for i, value := range row {
+			if i >= len(t.Columns) {
+				break
+			}
+			column := t.Columns[i].Name
+			switch column {
+			case resourceColumn:
+				e.stream, _ = value.(string)
+				continue
+			case itemColumn:
+				e.id, _ = value.(string)
+				continue
+			case "TimeGenerated":
+				if ts, ok := value.(string); ok {
+					e.timestamp, _ = time.Parse(time.RFC3339Nano, ts)
+				}
+			}
+			if value == nil || value == "" {
+				continue
+			}
+			k, _ := json.Marshal(column)
+			v, err := json.Marshal(value)
+			if err != nil {
+				continue
+			}
+			if message.Len() > 1 {
+				message.WriteByte(',')
+			}
+			message.Write(k)
+			message.WriteByte(':')
+			message.Write(v)
+		}
+		message.WriteByte('}')
+		e.message = message.String()
+		events = append(events, e)
+	}
+	return events
+}
+
+// query runs a KQL query in the workspace, and returns its primary result.
+func (a *azureMonitor) query(ctx context.Context, kql string) (*table, error) {
+	body, err := json.Marshal(map[string]string{"query": kql})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/v1/workspaces/"+url.PathEscape(a.workspace)+"/query", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	token, err := a.tokens.Token()
+	if err != nil {
+		return nil, fmt.Errorf("could not get access token: %w", err)
+	}
+	token.SetAuthHeader(req)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("invalid credentials, status %d", resp.StatusCode)
+	default:
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&apiErr)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, apiErr.Error.Message)
+	}
+
+	var result struct {
+		Tables []table `json:"tables"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding query results: %w", err)
+	}
+	if len(result.Tables) == 0 {
+		return &table{}, nil
+	}
+	return &result.Tables[0], nil
+}
+
+// managedIdentityTokens are the access tokens of the managed identity of the
+// Azure resource trufflehog runs on, from its instance metadata service.
+type managedIdentityTokens struct {
+	ctx    context.Context
+	client *http.Client
+	// clientID is the client ID of a user-assigned managed identity, or
+	// empty for the system-assigned one.
+	clientID string
+}
+
+func (m managedIdentityTokens) Token() (*oauth2.Token, error) {
+	query := url.Values{"api-version": {"2018-02-01"}, "resource": {logAnalyticsResource}}
+	if m.clientID != "" {
+		query.Set("client_id", m.clientID)
+	}
+	req, err := http.NewRequestWithContext(m.ctx, http.MethodGet, imdsEndpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+	res, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get the token of the managed identity, status %d", res.StatusCode)
+	}
+	var token struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+	expiresIn, _ := token.ExpiresIn.Int64()
+	return &oauth2.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   "Bearer",
+		Expiry:      time.Now().Add(time.Duration(expiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,105 @@
+package cloudlogs
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	logging "google.golang.org/api/logging/v2"
+	"google.golang.org/api/option"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// cloudLogging is the Cloud Logging of a Google Cloud project, whose log
+// groups are its logs, and whose events are filtered by queries of the
+// logging query language.
+type cloudLogging struct {
+	service *logging.Service
+	project string
+}
+
+func newCloudLogging(ctx context.Context, conn *sourcespb.CloudLogging, endpoint string) (*cloudLogging, error) {
+	if conn.GetProjectId() == "" {
+		return nil, errors.New("a project ID is required")
+	}
+	opts := []option.ClientOption{option.WithScopes(logging.LoggingReadScope)}
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+	switch cred := conn.GetCredential().(type) {
+	case *sourcespb.CloudLogging_ServiceAccountFile:
+		opts = append(opts, option.WithCredentialsFile(cred.ServiceAccountFile))
+	case *sourcespb.CloudLogging_Adc, nil:
+		// The application default credentials are used.
+	}
+	service, err := logging.NewService(ctx, opts...)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "error creating Cloud Logging client", 0)
+	}
+	return &cloudLogging{service: service, project: conn.GetProjectId()}, nil
+}
+
+func (c *cloudLogging) name() string {
+	return "cloud_logging"
+}
+
+// groups returns the IDs of the logs of the project, like
+// cloudaudit.googleapis.com/activity.
+func (c *cloudLogging) groups(ctx context.Context) ([]string, error) {
+	var groups []string
+	prefix := "projects/" + c.project + "/logs/"
+	err := c.service.Projects.Logs.List("projects/"+c.project).Pages(ctx, func(resp *logging.ListLogsResponse) error {
+		for _, name := range resp.LogNames {
+			id, err := url.PathUnescape(strings.TrimPrefix(name, prefix))
+			if err != nil {
+				return fmt.Errorf("invalid log name %q: %w", name, err)
+			}
+			groups = append(groups, id)
+		}
+		return nil
+	})
+	return groups, err
+}
+
+func (c *cloudLogging) events(ctx context.Context, group string, q query, fn func([]event) error) error {
+	// The slashes of the IDs of logs are escaped in their names.
+	filters := []string{fmt.Sprintf("logName=%q", "projects/"+c.project+"/logs/"+strings.ReplaceAll(group, "/", "%2F"))}
+	if !q.since.IsZero() {
+		filters = append(filters, fmt.Sprintf("timestamp>=%q", q.since.UTC().Format(time.RFC3339Nano)))
+	}
+	if !q.until.IsZero() {
+		filters = append(filters, fmt.Sprintf("timestamp<%q", q.until.UTC().Format(time.RFC3339Nano)))
+	}
+	if q.filter != "" {
+		filters = append(filters, "("+q.filter+")")
+	}
+
+	req := &logging.ListLogEntriesRequest{
+		ResourceNames: []string{"projects/" + c.project},
+		Filter:        strings.Join(filters, " AND "),
+		OrderBy:       "timestamp asc",
+		PageSize:      int64(q.batchSize),
+	}
+	return c.service.Entries.List(req).Pages(ctx, func(resp *logging.ListLogEntriesResponse) error {
+		events := make([]event, 0, len(resp.Entries))
+		for _, entry := range resp.Entries {
+			e := event{id: entry.InsertId, message: entry.TextPayload}
+			if entry.Resource != nil {
+				e.stream = entry.Resource.Type
+			}
+			e.timestamp, _ = time.Parse(time.RFC3339Nano, entry.Timestamp)
+			switch {
+			case len(entry.JsonPayload) > 0:
+				e.message = string(entry.JsonPayload)
+			case len(entry.ProtoPayload) > 0:
+				e.message = string(entry.ProtoPayload)
+			}
+			events = append(events, e)
+		}
+		return fn(events)
+	})
+}
@@ -0,0 +1,257 @@
+package cloudlogs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultBatchSize = 1000
+	maxBatchSize     = 10000
+)
+
+// Source scans the events of the log groups of a log platform: CloudWatch
+// Logs, Cloud Logging or Azure Monitor. Each event is a chunk.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn     *sourcespb.CloudLogs
+	platform platform
+	query    query
+	// names are the log groups named by the connection, and globs the
+	// globs of the names of log groups, which need their list.
+	names []string
+	globs sources.Globs
+}
+
+// platform lists the log groups of a log platform and their events.
+type platform interface {
+	// name is the name of the platform in the metadata of chunks.
+	name() string
+	// groups returns the names of all the log groups.
+	groups(ctx context.Context) ([]string, error)
+	// events calls fn with each batch of the events of a log group that the
+	// query selects, in the order they were logged.
+	events(ctx context.Context, group string, q query, fn func([]event) error) error
+}
+
+// query selects the events of a log group to scan.
+type query struct {
+	// since and until bound the time of the events, unless they are zero.
+	since, until time.Time
+	filter       string
+	batchSize    int
+}
+
+// event is an event of a log group.
+type event struct {
+	stream    string
+	timestamp time.Time
+	id        string
+	message   string
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_CLOUD_LOGS
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized log platform source.
+func (s *Source) Init(ctx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.CloudLogs
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	var err error
+	switch platform := conn.GetPlatform().(type) {
+	case *sourcespb.CloudLogs_Cloudwatch:
+		s.platform, err = newCloudWatch(platform.Cloudwatch, conn.GetEndpoint())
+	case *sourcespb.CloudLogs_CloudLogging:
+		s.platform, err = newCloudLogging(ctx, platform.CloudLogging, conn.GetEndpoint())
+	case *sourcespb.CloudLogs_AzureMonitor:
+		s.platform, err = newAzureMonitor(platform.AzureMonitor, conn.GetEndpoint())
+	default:
+		return errors.New("a log platform is required")
+	}
+	if err != nil {
+		return err
+	}
+
+	s.query = query{filter: conn.GetFilter(), batchSize: int(conn.GetBatchSize())}
+	if conn.GetSince() != nil {
+		s.query.since = conn.GetSince().AsTime()
+	}
+	if conn.GetUntil() != nil {
+		s.query.until = conn.GetUntil().AsTime()
+	}
+	if s.query.batchSize <= 0 || s.query.batchSize > maxBatchSize {
+		s.query.batchSize = defaultBatchSize
+	}
+
+	// Log groups without glob characters are named, and need no list.
+	var patterns []string
+	for _, pattern := range conn.GetLogGroups() {
+		if sources.IsGlob(pattern) {
+			patterns = append(patterns, pattern)
+		} else {
+			s.names = append(s.names, pattern)
+		}
+	}
+	globs, err := sources.CompileGlobs(patterns)
+	if err != nil {
+		return err
+	}
+	s.globs = globs
+	return nil
+}
+
+// Chunks emits the events of the log groups as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	groups, err := s.groupsToScan(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, group := range groups {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(groups), fmt.Sprintf("Log group: %s", group), "")
+		group := group
+		s.jobPool.Go(func() error {
+			if err := s.scanGroup(ctx, group, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error scanning log group %s: %w", group, err))
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// EnumerateTargets reports each log group, whose number of events is not
+// known without scanning it.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	groups, err := s.groupsToScan(ctx)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		report(sources.Target{Name: group, Objects: -1, Bytes: -1})
+	}
+	return nil
+}
+
+// groupsToScan returns the named log groups of the connection, and the log
+// groups of the platform that match its globs, or all of them if it has
+// neither.
+func (s *Source) groupsToScan(ctx context.Context) ([]string, error) {
+	groups := append([]string(nil), s.names...)
+	if len(s.globs) == 0 && len(s.names) > 0 {
+		return groups, nil
+	}
+
+	all, err := s.platform.groups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing log groups: %w", err)
+	}
+	named := make(map[string]bool, len(s.names))
+	for _, name := range s.names {
+		named[name] = true
+	}
+	for _, group := range all {
+		if named[group] || !s.matches(group) {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// matches reports whether a log group matches the globs of the connection,
+// or whether it has none.
+func (s *Source) matches(group string) bool {
+	return len(s.globs) == 0 || s.globs.Match(group)
+}
+
+func (s *Source) scanGroup(ctx context.Context, group string, chunksChan chan *sources.Chunk) error {
+	return s.platform.events(ctx, group, s.query, func(events []event) error {
+		for _, e := range events {
+			if strings.TrimSpace(e.message) == "" {
+				continue
+			}
+			if err := common.CancellableWrite(ctx, chunksChan, s.chunk(group, e)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Source) chunk(group string, e event) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		Data:       []byte(e.message),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_CloudLogs{
+				CloudLogs: &source_metadatapb.CloudLogs{
+					Platform:  s.platform.name(),
+					LogGroup:  sanitizer.UTF8(group),
+					LogStream: sanitizer.UTF8(e.stream),
+					Timestamp: e.timestamp.UTC().Format(time.RFC3339Nano),
+					EventId:   sanitizer.UTF8(e.id),
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
@@ -0,0 +1,332 @@
+package cloudlogs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+	logging "google.golang.org/api/logging/v2"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+var (
+	testSince = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	testUntil = time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+)
+
+// chunks returns the metadata of the chunks of a source by their data.
+func chunks(t *testing.T, s *Source) map[string]*source_metadatapb.CloudLogs {
+	t.Helper()
+	got := make(map[string]*source_metadatapb.CloudLogs)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		got[string(chunk.Data)] = chunk.SourceMetadata.GetCloudLogs()
+	}
+	return got
+}
+
+// cloudWatchServer is a CloudWatch Logs API with the log groups /app/api,
+// /app/worker and /audit, whose events are returned one at a time. The
+// FilterLogEvents requests are recorded.
+type cloudWatchServer struct {
+	mu       sync.Mutex
+	requests []map[string]any
+}
+
+func (cs *cloudWatchServer) start(t *testing.T) *httptest.Server {
+	t.Helper()
+	events := map[string][]map[string]any{
+		"/app/api": {
+			{"logStreamName": "api-1", "timestamp": testSince.Add(time.Hour).UnixMilli(), "eventId": "e1", "message": "password=hunter2"},
+			{"logStreamName": "api-2", "timestamp": testSince.Add(2 * time.Hour).UnixMilli(), "eventId": "e2", "message": "token=abc"},
+			{"logStreamName": "api-2", "timestamp": testSince.Add(3 * time.Hour).UnixMilli(), "eventId": "e3", "message": " "},
+		},
+		"/app/worker": {
+			{"logStreamName": "worker", "timestamp": testSince.UnixMilli(), "eventId": "e4", "message": "worker started"},
+		},
+		"/audit": {
+			{"logStreamName": "audit", "timestamp": testSince.UnixMilli(), "eventId": "e5", "message": "audit"},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		switch r.Header.Get("X-Amz-Target") {
+		case "Logs_20140328.DescribeLogGroups":
+			_ = json.NewEncoder(w).Encode(map[string]any{"logGroups": []map[string]string{
+				{"logGroupName": "/app/api"}, {"logGroupName": "/app/worker"}, {"logGroupName": "/audit"},
+			}})
+		case "Logs_20140328.FilterLogEvents":
+			cs.mu.Lock()
+			cs.requests = append(cs.requests, input)
+			cs.mu.Unlock()
+			group := events[input["logGroupName"].(string)]
+			var i int
+			if token, ok := input["nextToken"].(string); ok {
+				i = len(token)
+			}
+			resp := map[string]any{"events": group[i : i+1]}
+			if i+1 < len(group) {
+				resp["nextToken"] = strings.Repeat("x", i+1)
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func cloudWatchConn(endpoint string) *sourcespb.CloudLogs {
+	return &sourcespb.CloudLogs{
+		Platform: &sourcespb.CloudLogs_Cloudwatch{Cloudwatch: &sourcespb.CloudWatchLogs{
+			Region:     "us-east-1",
+			Credential: &sourcespb.CloudWatchLogs_AccessKey{AccessKey: &credentialspb.KeySecret{Key: "AKID", Secret: "secret"}},
+		}},
+		Endpoint: endpoint,
+	}
+}
+
+func TestSource_ChunksCloudWatch(t *testing.T) {
+	cs := &cloudWatchServer{}
+	server := cs.start(t)
+	conn := cloudWatchConn(server.URL)
+	conn.LogGroups = []string{"/app/a*"}
+	conn.Since = timestamppb.New(testSince)
+	conn.Until = timestamppb.New(testUntil)
+	conn.Filter = `"password" || "token"`
+	conn.BatchSize = 100
+
+	got := chunks(t, sources.InitTestSource(t, &Source{}, conn, 2))
+	// Events without a message are not scanned.
+	assert.Len(t, got, 2)
+	assert.Equal(t, &source_metadatapb.CloudLogs{
+		Platform:  "cloudwatch",
+		LogGroup:  "/app/api",
+		LogStream: "api-2",
+		Timestamp: "2024-01-02T02:00:00Z",
+		EventId:   "e2",
+	}, got["token=abc"])
+
+	require.NotEmpty(t, cs.requests)
+	assert.Equal(t, float64(testSince.UnixMilli()), cs.requests[0]["startTime"])
+	assert.Equal(t, float64(testUntil.UnixMilli()-1), cs.requests[0]["endTime"])
+	assert.Equal(t, `"password" || "token"`, cs.requests[0]["filterPattern"])
+	assert.Equal(t, float64(100), cs.requests[0]["limit"])
+}
+
+func TestSource_ChunksCloudWatchNamed(t *testing.T) {
+	server := (&cloudWatchServer{}).start(t)
+	conn := cloudWatchConn(server.URL)
+	conn.LogGroups = []string{"/audit", "/app/w*"}
+	got := chunks(t, sources.InitTestSource(t, &Source{}, conn, 2))
+	assert.Equal(t, []string{"audit", "worker started"}, keys(got))
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	server := (&cloudWatchServer{}).start(t)
+	s := sources.InitTestSource(t, &Source{}, cloudWatchConn(server.URL), 2)
+	var targets []sources.Target
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		targets = append(targets, target)
+	}))
+	assert.Equal(t, []sources.Target{
+		{Name: "/app/api", Objects: -1, Bytes: -1},
+		{Name: "/app/worker", Objects: -1, Bytes: -1},
+		{Name: "/audit", Objects: -1, Bytes: -1},
+	}, targets)
+}
+
+func TestSource_ChunksCloudLogging(t *testing.T) {
+	var mu sync.Mutex
+	var filters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/projects/proj/logs":
+			_ = json.NewEncoder(w).Encode(map[string]any{"logNames": []string{
+				"projects/proj/logs/cloudaudit.googleapis.com%2Factivity", "projects/proj/logs/app",
+			}})
+		case "/v2/entries:list":
+			var req logging.ListLogEntriesRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			filters = append(filters, req.Filter)
+			mu.Unlock()
+			if !strings.Contains(req.Filter, `logName="projects/proj/logs/app"`) {
+				_ = json.NewEncoder(w).Encode(map[string]any{})
+				return
+			}
+			if req.PageToken == "" {
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"entries": []map[string]any{{
+						"insertId":    "i1",
+						"timestamp":   "2024-01-02T03:04:05.123Z",
+						"textPayload": "password=hunter2",
+						"resource":    map[string]any{"type": "k8s_container"},
+					}},
+					"nextPageToken": "next",
+				})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"entries": []map[string]any{{
+					"insertId":    "i2",
+					"timestamp":   "2024-01-02T03:04:06Z",
+					"jsonPayload": map[string]string{"token": "abc"},
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	service, err := logging.NewService(context.Background(), option.WithEndpoint(server.URL+"/"), option.WithoutAuthentication())
+	require.NoError(t, err)
+	s := &Source{
+		conn:     &sourcespb.CloudLogs{},
+		platform: &cloudLogging{service: service, project: "proj"},
+		query:    query{since: testSince, filter: `severity>=WARNING`, batchSize: 10},
+		jobPool:  &errgroup.Group{},
+	}
+
+	got := chunks(t, s)
+	assert.Equal(t, &source_metadatapb.CloudLogs{
+		Platform:  "cloud_logging",
+		LogGroup:  "app",
+		LogStream: "k8s_container",
+		Timestamp: "2024-01-02T03:04:05.123Z",
+		EventId:   "i1",
+	}, got["password=hunter2"])
+	assert.Equal(t, "i2", got[`{"token":"abc"}`].GetEventId())
+
+	sort.Strings(filters)
+	assert.Contains(t, filters, `logName="projects/proj/logs/cloudaudit.googleapis.com%2Factivity" AND timestamp>="2024-01-02T00:00:00Z" AND (severity>=WARNING)`)
+}
+
+func TestSource_ChunksAzureMonitor(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tenant/oauth2/v2.0/token":
+			_ = r.ParseForm()
+			if r.Form.Get("client_secret") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "token_type": "Bearer", "expires_in": 3600})
+		case "/v1/workspaces/ws/query":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var body struct {
+				Query string `json:"query"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			queries = append(queries, body.Query)
+			mu.Unlock()
+
+			columns := []map[string]string{{"name": "TimeGenerated"}, {"name": "Message"}, {"name": "Computer"}, {"name": resourceColumn}, {"name": itemColumn}}
+			var rows [][]any
+			switch {
+			case strings.HasPrefix(body.Query, "union"):
+				columns = []map[string]string{{"name": "TableName"}}
+				rows = [][]any{{"AppTraces"}, {"Syslog"}}
+			case strings.HasPrefix(body.Query, "['AppTraces']") && !strings.Contains(body.Query, "TimeGenerated >"):
+				rows = [][]any{
+					{"2024-01-02T03:04:05.1234567Z", "password=hunter2", nil, "/subscriptions/s/vm", "id-1"},
+					{"2024-01-02T03:04:06Z", "token=abc", "vm-1", "/subscriptions/s/vm", "id-2"},
+				}
+			case strings.HasPrefix(body.Query, "['AppTraces']"):
+				rows = [][]any{{"2024-01-02T03:04:07Z", "secret=xyz", "vm-1", "/subscriptions/s/vm", "id-3"}}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"tables": []map[string]any{{"name": "PrimaryResult", "columns": columns, "rows": rows}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer func(host string) { authorityHost = host }(authorityHost)
+	authorityHost = server.URL
+
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.CloudLogs{
+		Platform: &sourcespb.CloudLogs_AzureMonitor{AzureMonitor: &sourcespb.AzureMonitorLogs{
+			WorkspaceId: "ws",
+			Credential: &sourcespb.AzureMonitorLogs_ServicePrincipal{ServicePrincipal: &credentialspb.ClientCredentials{
+				TenantId: "tenant", ClientId: "client", ClientSecret: "secret",
+			}},
+		}},
+		LogGroups: []string{"AppTraces"},
+		Until:     timestamppb.New(testUntil),
+		Filter:    `Message has "="`,
+		BatchSize: 2,
+		Endpoint:  server.URL,
+	}, 2)
+
+	got := chunks(t, s)
+	assert.Equal(t, &source_metadatapb.CloudLogs{
+		Platform:  "azure_monitor",
+		LogGroup:  "AppTraces",
+		LogStream: "/subscriptions/s/vm",
+		Timestamp: "2024-01-02T03:04:05.1234567Z",
+		EventId:   "id-1",
+	}, got[`{"TimeGenerated":"2024-01-02T03:04:05.1234567Z","Message":"password=hunter2"}`])
+	assert.Len(t, got, 3)
+
+	// The second batch starts after the last row of the first.
+	require.Len(t, queries, 2)
+	assert.Contains(t, queries[0], `| where TimeGenerated < datetime(2024-01-03T00:00:00Z) | where Message has "="`)
+	assert.Contains(t, queries[1], `| where TimeGenerated > datetime(2024-01-02T03:04:06Z) or (TimeGenerated == datetime(2024-01-02T03:04:06Z) and `+itemColumn+` > "id-2")`)
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.CloudLogs{
+		"no platform":  {},
+		"no region":    {Platform: &sourcespb.CloudLogs_Cloudwatch{Cloudwatch: &sourcespb.CloudWatchLogs{}}},
+		"no project":   {Platform: &sourcespb.CloudLogs_CloudLogging{CloudLogging: &sourcespb.CloudLogging{}}},
+		"no workspace": {Platform: &sourcespb.CloudLogs_AzureMonitor{AzureMonitor: &sourcespb.AzureMonitorLogs{}}},
+		"no azure credentials": {Platform: &sourcespb.CloudLogs_AzureMonitor{AzureMonitor: &sourcespb.AzureMonitorLogs{
+			WorkspaceId: "ws",
+		}}},
+		"invalid glob": {
+			Platform:  &sourcespb.CloudLogs_Cloudwatch{Cloudwatch: &sourcespb.CloudWatchLogs{Region: "us-east-1"}},
+			LogGroups: []string{"[a"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
+
+func keys(m map[string]*source_metadatapb.CloudLogs) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
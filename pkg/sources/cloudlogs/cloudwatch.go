@@ -0,0 +1,102 @@
+package cloudlogs
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/go-errors/errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// cloudWatch is CloudWatch Logs, whose events are filtered by filter
+// patterns.
+type cloudWatch struct {
+	client cloudwatchlogsiface.CloudWatchLogsAPI
+}
+
+func newCloudWatch(conn *sourcespb.CloudWatchLogs, endpoint string) (*cloudWatch, error) {
+	if conn.GetRegion() == "" {
+		return nil, errors.New("a region is required")
+	}
+	cfg := aws.NewConfig()
+	cfg.CredentialsChainVerboseErrors = aws.Bool(true)
+	cfg.Region = aws.String(conn.GetRegion())
+	if endpoint != "" {
+		cfg.Endpoint = aws.String(endpoint)
+	}
+	switch cred := conn.GetCredential().(type) {
+	case *sourcespb.CloudWatchLogs_AccessKey:
+		cfg.Credentials = credentials.NewStaticCredentials(cred.AccessKey.GetKey(), cred.AccessKey.GetSecret(), "")
+	case *sourcespb.CloudWatchLogs_SessionToken:
+		cfg.Credentials = credentials.NewStaticCredentials(cred.SessionToken.GetKey(), cred.SessionToken.GetSecret(), cred.SessionToken.GetSessionToken())
+	case *sourcespb.CloudWatchLogs_CloudEnvironment, nil:
+		// The default credentials of the environment are used.
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            *cfg,
+	})
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "error creating AWS session", 0)
+	}
+	return &cloudWatch{client: cloudwatchlogs.New(sess)}, nil
+}
+
+func (c *cloudWatch) name() string {
+	return "cloudwatch"
+}
+
+func (c *cloudWatch) groups(ctx context.Context) ([]string, error) {
+	var groups []string
+	err := c.client.DescribeLogGroupsPagesWithContext(ctx, &cloudwatchlogs.DescribeLogGroupsInput{},
+		func(page *cloudwatchlogs.DescribeLogGroupsOutput, _ bool) bool {
+			for _, group := range page.LogGroups {
+				groups = append(groups, aws.StringValue(group.LogGroupName))
+			}
+			return true
+		})
+	return groups, err
+}
+
+func (c *cloudWatch) events(ctx context.Context, group string, q query, fn func([]event) error) error {
+	input := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName: aws.String(group),
+		Limit:        aws.Int64(int64(q.batchSize)),
+	}
+	if !q.since.IsZero() {
+		input.StartTime = aws.Int64(q.since.UnixMilli())
+	}
+	if !q.until.IsZero() {
+		// The end time is inclusive.
+		input.EndTime = aws.Int64(q.until.UnixMilli() - 1)
+	}
+	if q.filter != "" {
+		input.FilterPattern = aws.String(q.filter)
+	}
+
+	var fnErr error
+	err := c.client.FilterLogEventsPagesWithContext(ctx, input, func(page *cloudwatchlogs.FilterLogEventsOutput, _ bool) bool {
+		events := make([]event, 0, len(page.Events))
+		for _, e := range page.Events {
+			events = append(events, event{
+				stream:    aws.StringValue(e.LogStreamName),
+				timestamp: time.UnixMilli(aws.Int64Value(e.Timestamp)),
+				id:        aws.StringValue(e.EventId),
+				message:   aws.StringValue(e.Message),
+			})
+		}
+		fnErr = fn(events)
+		return fnErr == nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
@@ -0,0 +1,409 @@
+package confluence
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"github.com/hashicorp/go-retryablehttp"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// pageSize is the number of spaces, pages and attachments requested at
+	// a time. Confluence Cloud returns at most 50 pages with their body.
+	pageSize = 50
+	// maxAttachmentSize is the size of the largest attachments scanned.
+	maxAttachmentSize = 250 * 1024 * 1024
+
+	locationBody       = "body"
+	locationAttachment = "attachment"
+)
+
+// Source scans the pages of the spaces of Confluence Cloud, Server and Data
+// Center: the storage format of their body in each of their versions, and
+// their attachments.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	client   *http.Client
+	endpoint string
+	// authorize sets the credentials of requests.
+	authorize func(*http.Request)
+	conn      *sourcespb.Confluence
+	// archiveOptions configures how the archives of attachments are
+	// extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_CONFLUENCE
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of attachments are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized Confluence source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Confluence
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	if conn.GetEndpoint() == "" {
+		return errors.New("the endpoint of Confluence is required")
+	}
+	s.conn = &conn
+	s.endpoint = strings.TrimSuffix(conn.GetEndpoint(), "/")
+	s.client = newClient(conn.GetInsecureSkipVerifyTls())
+
+	s.authorize = func(*http.Request) {}
+	switch cred := conn.Credential.(type) {
+	case *sourcespb.Confluence_BasicAuth:
+		// The email and API token on Confluence Cloud, or the username and
+		// password on Server.
+		s.authorize = func(req *http.Request) {
+			req.SetBasicAuth(cred.BasicAuth.GetUsername(), cred.BasicAuth.GetPassword())
+		}
+	case *sourcespb.Confluence_Token:
+		// A personal access token on Server and Data Center.
+		s.authorize = func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+cred.Token) }
+	}
+	return nil
+}
+
+// newClient returns a client that retries requests, and does not verify the
+// certificates of servers if insecure is set, like those of self-signed
+// Confluence servers.
+func newClient(insecure bool) *http.Client {
+	client := retryablehttp.NewClient()
+	client.RetryMax = 3
+	client.Logger = nil
+	client.HTTPClient.Timeout = 120 * time.Second
+	var transport http.RoundTripper
+	if insecure {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = common.TLSClientConfig()
+		t.TLSClientConfig.InsecureSkipVerify = true
+		transport = t
+	}
+	client.HTTPClient.Transport = common.NewCustomTransport(transport)
+	return client.StandardClient()
+}
+
+type user struct {
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+}
+
+type version struct {
+	Number int    `json:"number"`
+	When   string `json:"when"`
+	By     user   `json:"by"`
+}
+
+type space struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type page struct {
+	ID      string  `json:"id"`
+	Title   string  `json:"title"`
+	Version version `json:"version"`
+	Body    struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Links struct {
+		WebUI string `json:"webui"`
+	} `json:"_links"`
+}
+
+type attachment struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	Version    version `json:"version"`
+	Extensions struct {
+		FileSize int64 `json:"fileSize"`
+	} `json:"extensions"`
+	Links struct {
+		Download string `json:"download"`
+	} `json:"_links"`
+}
+
+// Chunks emits the bodies of the versions of the pages of each space, and
+// their attachments, as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	spaces, err := s.spaces(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing spaces: %w", err)
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, sp := range spaces {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(spaces), fmt.Sprintf("Space: %s", sp.Key), "")
+
+		query := url.Values{"spaceKey": {sp.Key}, "type": {"page"}, "expand": {"body.storage,version"}}
+		pages, err := getAll[page](ctx, s, "/rest/api/content", query)
+		if err != nil {
+			scanErrs.Add(fmt.Errorf("error listing pages of space %s: %w", sp.Key, err))
+			continue
+		}
+		for _, p := range pages {
+			p := p
+			s.jobPool.Go(func() error {
+				if err := s.scanPage(ctx, sp, p, chunksChan); err != nil {
+					scanErrs.Add(fmt.Errorf("error scanning page %s: %w", p.ID, err))
+				}
+				return nil
+			})
+		}
+		_ = s.jobPool.Wait()
+		ctx.Logger().V(2).Info("scanned space", "space", sp.Key, "pages", len(pages))
+	}
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// spaces returns the spaces of the connection, or those of its scope if it
+// has none, except for those it ignores.
+func (s *Source) spaces(ctx context.Context) ([]space, error) {
+	var spaces []space
+	if keys := s.conn.GetSpaces(); len(keys) > 0 {
+		for _, key := range keys {
+			spaces = append(spaces, space{Key: key})
+		}
+	} else {
+		query := url.Values{}
+		switch s.conn.GetSpacesScope() {
+		case sourcespb.Confluence_GLOBAL:
+			query.Set("type", "global")
+		case sourcespb.Confluence_PERSONAL:
+			query.Set("type", "personal")
+		}
+		var err error
+		if spaces, err = getAll[space](ctx, s, "/rest/api/space", query); err != nil {
+			return nil, err
+		}
+	}
+
+	ignored := make(map[string]bool)
+	for _, key := range s.conn.GetIgnoreSpaces() {
+		ignored[key] = true
+	}
+	kept := spaces[:0]
+	for _, sp := range spaces {
+		if !ignored[sp.Key] {
+			kept = append(kept, sp)
+		}
+	}
+	return kept, nil
+}
+
+func (s *Source) scanPage(ctx context.Context, sp space, p page, chunksChan chan *sources.Chunk) error {
+	skel := s.chunkSkeleton(sp, p, locationBody, s.endpoint+p.Links.WebUI)
+	if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(p.Body.Storage.Value), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+		return err
+	}
+
+	if !s.conn.GetSkipHistory() {
+		for n := p.Version.Number - 1; n > 0; n-- {
+			if err := s.scanVersion(ctx, sp, p.ID, n, chunksChan); err != nil {
+				return fmt.Errorf("error scanning version %d: %w", n, err)
+			}
+		}
+	}
+
+	if !s.conn.GetIncludeAttachments() {
+		return nil
+	}
+	query := url.Values{"expand": {"version"}}
+	attachments, err := getAll[attachment](ctx, s, "/rest/api/content/"+url.PathEscape(p.ID)+"/child/attachment", query)
+	if err != nil {
+		return fmt.Errorf("error listing attachments: %w", err)
+	}
+	for _, a := range attachments {
+		if a.Extensions.FileSize > maxAttachmentSize {
+			sources.ReportSkipBytes(ctx, p.ID+"/"+a.Title, sources.SkipReasonSize, a.Extensions.FileSize)
+			continue
+		}
+		withVersion := p
+		withVersion.Version = a.Version
+		skel := s.chunkSkeleton(sp, withVersion, locationAttachment, s.endpoint+p.Links.WebUI)
+		skel.SourceMetadata.GetConfluence().File = a.Title
+		if err := s.scanAttachment(ctx, skel, s.endpoint+a.Links.Download, chunksChan); err != nil {
+			return fmt.Errorf("error scanning attachment %s: %w", a.Title, err)
+		}
+	}
+	return nil
+}
+
+// scanVersion scans the body of a historical version of a page. Versions
+// that were deleted are skipped.
+func (s *Source) scanVersion(ctx context.Context, sp space, id string, number int, chunksChan chan *sources.Chunk) error {
+	query := url.Values{"status": {"historical"}, "version": {strconv.Itoa(number)}, "expand": {"body.storage,version"}}
+	var p page
+	err := s.get(ctx, "/rest/api/content/"+url.PathEscape(id)+"?"+query.Encode(), &p)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	link := fmt.Sprintf("%s/pages/viewpage.action?pageId=%s&pageVersion=%d", s.endpoint, url.QueryEscape(id), number)
+	p.ID = id
+	return sources.ChunkReaderTo(ctx, s.chunkSkeleton(sp, p, locationBody, link), strings.NewReader(p.Body.Storage.Value), chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+// scanAttachment scans an attachment, through the handlers of archives.
+func (s *Source) scanAttachment(ctx context.Context, skel *sources.Chunk, downloadURL string, chunksChan chan *sources.Chunk) error {
+	res, err := s.do(ctx, downloadURL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	reader, err := diskbufferreader.New(res.Body)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+	return sources.ChunkReaderTo(ctx, skel, reader, chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+func (s *Source) chunkSkeleton(sp space, p page, location, link string) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Confluence{
+				Confluence: &source_metadatapb.Confluence{
+					Page:      p.Title,
+					Space:     sp.Key,
+					Version:   strconv.Itoa(p.Version.Number),
+					Link:      link,
+					Email:     p.Version.By.Email,
+					Timestamp: p.Version.When,
+					Location:  location,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// getAll returns all the results of a paginated request of the REST API.
+func getAll[T any](ctx context.Context, s *Source, path string, query url.Values) ([]T, error) {
+	var all []T
+	for {
+		var res struct {
+			Results []T `json:"results"`
+		}
+		query.Set("start", strconv.Itoa(len(all)))
+		query.Set("limit", strconv.Itoa(pageSize))
+		if err := s.get(ctx, path+"?"+query.Encode(), &res); err != nil {
+			return nil, err
+		}
+		all = append(all, res.Results...)
+		if len(res.Results) == 0 || common.IsDone(ctx) {
+			return all, nil
+		}
+	}
+}
+
+var errNotFound = errors.New("not found")
+
+// get decodes the JSON response of a request of the REST API.
+func (s *Source) get(ctx context.Context, path string, v any) error {
+	res, err := s.do(ctx, s.endpoint+path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// do sends an authorized GET request, and returns its response if it
+// succeeded.
+func (s *Source) do(ctx context.Context, reqURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.authorize(req)
+	req.Header.Set("Accept", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		switch res.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+		case http.StatusNotFound:
+			return nil, errNotFound
+		}
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res, nil
+}
@@ -0,0 +1,144 @@
+package confluence
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// fakeConfluence serves a Confluence site of two spaces of one page, whose
+// first page has two versions and an attachment.
+func fakeConfluence(t *testing.T, attachment []byte) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	// results returns the results of the first page of a paginated request.
+	results := func(w http.ResponseWriter, r *http.Request, v ...any) {
+		if r.URL.Query().Get("start") != "0" {
+			v = nil
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"results": v})
+	}
+	authorized := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if user, pass, _ := r.BasicAuth(); user != "ada@example.com" || pass != "api-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next(w, r)
+		}
+	}
+	page := func(id, title, body string, number int) map[string]any {
+		return map[string]any{
+			"id":      id,
+			"title":   title,
+			"version": map[string]any{"number": number, "when": "2024-03-01T10:00:00.000Z", "by": map[string]any{"email": "ada@example.com"}},
+			"body":    map[string]any{"storage": map[string]any{"value": body}},
+			"_links":  map[string]any{"webui": "/spaces/" + title},
+		}
+	}
+	mux.HandleFunc("/rest/api/space", authorized(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "global", r.URL.Query().Get("type"))
+		results(w, r, map[string]any{"key": "OPS"}, map[string]any{"key": "HR"})
+	}))
+	mux.HandleFunc("/rest/api/content", authorized(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("spaceKey") {
+		case "OPS":
+			results(w, r, page("1", "Runbook", "<p>password: hunter3</p>", 2))
+		default:
+			t.Errorf("unexpected space %q", r.URL.Query().Get("spaceKey"))
+		}
+	}))
+	mux.HandleFunc("/rest/api/content/1", authorized(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "historical", r.URL.Query().Get("status"))
+		assert.Equal(t, "1", r.URL.Query().Get("version"))
+		_ = json.NewEncoder(w).Encode(page("1", "Runbook", "<p>password: hunter2</p>", 1))
+	}))
+	mux.HandleFunc("/rest/api/content/1/child/attachment", authorized(func(w http.ResponseWriter, r *http.Request) {
+		results(w, r, map[string]any{
+			"id":         "att2",
+			"title":      "config.zip",
+			"version":    map[string]any{"number": 1},
+			"extensions": map[string]any{"fileSize": len(attachment)},
+			"_links":     map[string]any{"download": "/download/attachments/1/config.zip"},
+		})
+	}))
+	mux.HandleFunc("/download/attachments/1/config.zip", authorized(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(attachment)
+	}))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSource_Chunks(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create("app.env")
+	require.NoError(t, err)
+	_, err = f.Write([]byte("TOKEN=secret\n"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	server := fakeConfluence(t, buf.Bytes())
+
+	conn, err := anypb.New(&sourcespb.Confluence{
+		Endpoint:           server.URL + "/",
+		Credential:         &sourcespb.Confluence_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: "ada@example.com", Password: "api-token"}},
+		SpacesScope:        sourcespb.Confluence_GLOBAL,
+		IgnoreSpaces:       []string{"HR"},
+		IncludeAttachments: true,
+	})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 2))
+
+	chunksChan := make(chan *sources.Chunk, 64)
+	require.NoError(t, s.Chunks(context.Background(), chunksChan))
+	close(chunksChan)
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.Confluence)
+	for chunk := range chunksChan {
+		m := chunk.SourceMetadata.GetConfluence()
+		key := m.GetLocation() + " " + m.GetVersion()
+		got[key] += string(chunk.Data)
+		metadata[key] = m
+	}
+	assert.Equal(t, map[string]string{
+		"body 2":       "<p>password: hunter3</p>",
+		"body 1":       "<p>password: hunter2</p>",
+		"attachment 1": "TOKEN=secret\n",
+	}, got)
+	assert.Equal(t, &source_metadatapb.Confluence{
+		Page:      "Runbook",
+		Space:     "OPS",
+		Version:   "1",
+		Link:      server.URL + "/pages/viewpage.action?pageId=1&pageVersion=1",
+		Email:     "ada@example.com",
+		Timestamp: "2024-03-01T10:00:00.000Z",
+		Location:  "body",
+	}, metadata["body 1"])
+	assert.Equal(t, server.URL+"/spaces/Runbook", metadata["body 2"].GetLink())
+	assert.Equal(t, "config.zip", metadata["attachment 1"].GetFile())
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := fakeConfluence(t, nil)
+	conn, err := anypb.New(&sourcespb.Confluence{Endpoint: server.URL, Credential: &sourcespb.Confluence_Token{Token: "expired"}})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "invalid credentials")
+}
@@ -0,0 +1,317 @@
+package consul
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const defaultAddress = "http://127.0.0.1:8500"
+
+// Source scans the values of the keys of the KV store of a Consul
+// datacenter.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn    *sourcespb.Consul
+	address string
+	client  *http.Client
+	exclude sources.Globs
+}
+
+// pair is a key of the KV store and its value.
+type pair struct {
+	Key string `json:"Key"`
+	// Value is decoded from the base64 of the API.
+	Value []byte `json:"Value"`
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_CONSUL
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized Consul source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Consul
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	s.address = strings.TrimSuffix(conn.GetAddress(), "/")
+	if s.address == "" {
+		s.address = defaultAddress
+	}
+	// Addresses without a scheme are those of the CLI of Consul, like
+	// 127.0.0.1:8500.
+	if !strings.Contains(s.address, "://") {
+		s.address = "http://" + s.address
+	}
+	if _, err := url.Parse(s.address); err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+
+	tlsConfig := common.TLSClientConfig()
+	tlsConfig.InsecureSkipVerify = conn.GetInsecure()
+	if ca := conn.GetCaCertificate(); ca != "" {
+		if err := common.AddRootCAs(tlsConfig, []byte(ca)); err != nil {
+			return fmt.Errorf("invalid CA certificate: %w", err)
+		}
+	}
+	s.client = common.RetryableHttpClientTLS(120, tlsConfig)
+
+	exclude, err := sources.CompileGlobs(conn.GetExcludeGlobs(), '/')
+	if err != nil {
+		return err
+	}
+	s.exclude = exclude
+	return nil
+}
+
+// Chunks emits the values of the keys as chunks. The keys are scanned by the
+// top-level folders of the prefix, so that large stores are not read at
+// once.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	datacenter := s.datacenter(ctx)
+	entries, err := s.topLevel(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, entry := range entries {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(entries), fmt.Sprintf("Key: %s", entry), "")
+		entry := entry
+		s.jobPool.Go(func() error {
+			pairs, err := s.pairs(ctx, entry)
+			if err != nil {
+				scanErrs.Add(fmt.Errorf("error reading %s: %w", entry, err))
+				return nil
+			}
+			for _, p := range pairs {
+				if err := s.scanPair(ctx, datacenter, p, chunksChan); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err := s.jobPool.Wait(); err != nil {
+		return err
+	}
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// EnumerateTargets reports each top-level key or folder of the prefix with
+// the number and size of the values that would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	entries, err := s.topLevel(ctx)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		pairs, err := s.pairs(ctx, entry)
+		if err != nil {
+			return err
+		}
+		target := sources.Target{Name: entry}
+		for _, p := range pairs {
+			if len(p.Value) > 0 && !s.exclude.Match(p.Key) {
+				target.Objects++
+				target.Bytes += int64(len(p.Value))
+			}
+		}
+		report(target)
+	}
+	return nil
+}
+
+// datacenter returns the datacenter of the keys, which is that of the agent
+// if it is not set.
+func (s *Source) datacenter(ctx context.Context) string {
+	if dc := s.conn.GetDatacenter(); dc != "" {
+		return dc
+	}
+	var self struct {
+		Config struct {
+			Datacenter string `json:"Datacenter"`
+		} `json:"Config"`
+	}
+	if err := s.get(ctx, "/v1/agent/self", nil, &self); err != nil {
+		ctx.Logger().V(2).Info("could not read the datacenter of the agent", "error", err)
+	}
+	return self.Config.Datacenter
+}
+
+// topLevel returns the keys and folders right under the prefix.
+func (s *Source) topLevel(ctx context.Context) ([]string, error) {
+	var entries []string
+	err := s.get(ctx, kvPath(s.conn.GetPrefix()), url.Values{"keys": {""}, "separator": {"/"}}, &entries)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing keys: %w", err)
+	}
+	return entries, nil
+}
+
+// pairs returns the pairs of a key, or of all the keys of a folder.
+// Non-folder keys are read alone, as recursive reads return all the keys
+// they are a prefix of.
+func (s *Source) pairs(ctx context.Context, entry string) ([]pair, error) {
+	query := url.Values{}
+	if strings.HasSuffix(entry, "/") {
+		query.Set("recurse", "")
+	}
+	var pairs []pair
+	err := s.get(ctx, kvPath(entry), query, &pairs)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	return pairs, err
+}
+
+func (s *Source) scanPair(ctx context.Context, datacenter string, p pair, chunksChan chan *sources.Chunk) error {
+	// Folders are keys without values.
+	if len(p.Value) == 0 {
+		return nil
+	}
+	if s.exclude.Match(p.Key) {
+		sources.ReportSkipBytes(ctx, p.Key, sources.SkipReasonFiltered, int64(len(p.Value)))
+		return nil
+	}
+	chunk := &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		Data:       p.Value,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Consul{
+				Consul: &source_metadatapb.Consul{
+					Key:        sanitizer.UTF8(p.Key),
+					Link:       sanitizer.UTF8(s.link(datacenter, p.Key)),
+					Datacenter: datacenter,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+	return common.CancellableWrite(ctx, chunksChan, chunk)
+}
+
+// link returns the URL of a key in the UI of Consul, which needs the
+// datacenter of the key.
+func (s *Source) link(datacenter, key string) string {
+	if datacenter == "" {
+		return ""
+	}
+	return s.address + "/ui/" + url.PathEscape(datacenter) + "/kv/" + escapeKey(key) + "/edit"
+}
+
+// kvPath returns the path of a key in the KV API.
+func kvPath(key string) string {
+	return "/v1/kv/" + escapeKey(key)
+}
+
+// escapeKey escapes the segments of a key, keeping its slashes.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+var errNotFound = errors.New("not found")
+
+// get decodes the response of a request to the API.
+func (s *Source) get(ctx context.Context, path string, query url.Values, out any) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	if dc := s.conn.GetDatacenter(); dc != "" {
+		query.Set("dc", dc)
+	}
+	// Flags like keys and recurse are set by their presence, so that their
+	// empty values are ignored.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.address+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if token := s.conn.GetToken(); token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return json.NewDecoder(resp.Body).Decode(out)
+	case http.StatusNotFound:
+		return errNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		// Consul answers 403 both to unknown tokens and to tokens without
+		// the permission.
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("invalid credentials, status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	default:
+		return fmt.Errorf("unexpected status %d for %s", resp.StatusCode, path)
+	}
+}
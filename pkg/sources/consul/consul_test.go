@@ -0,0 +1,157 @@
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const testToken = "consul-token"
+
+// testServer returns an agent with a KV store, whose folders are keys
+// without values.
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	store := map[string]string{
+		"app/":                  "",
+		"app/db/password":       "hunter2",
+		"app/db/user":           "admin",
+		"app/cache/ttl":         "60",
+		"apple":                 "fruit",
+		"global":                "AKIA",
+		"vault/tokens/root key": "s.root",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != testToken {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("ACL not found"))
+			return
+		}
+		if r.URL.Path == "/v1/agent/self" {
+			_, _ = w.Write([]byte(`{"Config":{"Datacenter":"dc1"}}`))
+			return
+		}
+		key, ok := strings.CutPrefix(r.URL.Path, "/v1/kv/")
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query := r.URL.Query()
+		_, keys := query["keys"]
+		_, recurse := query["recurse"]
+
+		var matches []string
+		for k := range store {
+			if k == key || ((keys || recurse) && strings.HasPrefix(k, key)) {
+				matches = append(matches, k)
+			}
+		}
+		sort.Strings(matches)
+		if len(matches) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var resp any
+		if keys {
+			// Keys under the separator are folded into their folder.
+			seen := make(map[string]bool)
+			var folded []string
+			for _, k := range matches {
+				if i := strings.Index(k[len(key):], query.Get("separator")); i >= 0 {
+					k = k[:len(key)+i+1]
+				}
+				if !seen[k] {
+					seen[k] = true
+					folded = append(folded, k)
+				}
+			}
+			resp = folded
+		} else {
+			var pairs []map[string]any
+			for _, k := range matches {
+				p := map[string]any{"Key": k, "Value": nil}
+				if store[k] != "" {
+					p["Value"] = []byte(store[k])
+				}
+				pairs = append(pairs, p)
+			}
+			resp = pairs
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// chunks returns the data of the chunks of a source by key, and their
+// metadata.
+func chunks(t *testing.T, conn *sourcespb.Consul) (map[string]string, map[string]*source_metadatapb.Consul) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+	return sources.ChunksByKey(sources.RequireChunks(t, s), (*source_metadatapb.MetaData).GetConsul, (*source_metadatapb.Consul).GetKey)
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server := testServer(t)
+	got, metadata := chunks(t, &sourcespb.Consul{
+		Address:      server.URL,
+		Token:        testToken,
+		ExcludeGlobs: []string{"app/cache/**"},
+	})
+	assert.Equal(t, map[string]string{
+		"app/db/password":       "hunter2",
+		"app/db/user":           "admin",
+		"apple":                 "fruit",
+		"global":                "AKIA",
+		"vault/tokens/root key": "s.root",
+	}, got)
+	assert.Equal(t, &source_metadatapb.Consul{
+		Key:        "vault/tokens/root key",
+		Link:       server.URL + "/ui/dc1/kv/vault/tokens/root%20key/edit",
+		Datacenter: "dc1",
+	}, metadata["vault/tokens/root key"])
+}
+
+func TestSource_ChunksPrefix(t *testing.T) {
+	server := testServer(t)
+	got, metadata := chunks(t, &sourcespb.Consul{
+		Address:    strings.TrimPrefix(server.URL, "http://"),
+		Token:      testToken,
+		Datacenter: "dc2",
+		Prefix:     "app/db/",
+	})
+	assert.Equal(t, map[string]string{
+		"app/db/password": "hunter2",
+		"app/db/user":     "admin",
+	}, got)
+	assert.Equal(t, "dc2", metadata["app/db/user"].GetDatacenter())
+}
+
+func TestSource_ChunksInvalidToken(t *testing.T) {
+	server := testServer(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Consul{Address: server.URL, Token: "wrong"}, 2)
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	assert.ErrorContains(t, err, "invalid credentials, status 403: ACL not found")
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	server := testServer(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Consul{Address: server.URL, Token: testToken}, 2)
+	objects := make(map[string]int64)
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		objects[target.Name] = target.Objects
+	}))
+	assert.Equal(t, map[string]int64{"app/": 3, "apple": 1, "global": 1, "vault/": 1}, objects)
+}
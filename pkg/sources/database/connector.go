@@ -0,0 +1,231 @@
+package database
+
+import (
+	gocontext "context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/denisenkom/go-mssqldb/msdsn"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+// connector returns the connector of a network database, whose TLS
+// connections start from the TLS config of the flags rather than from the
+// drivers' own.
+func (s *Source) connector() (driver.Connector, error) {
+	switch s.dialect {
+	case dialectPostgres:
+		return postgresConnector(s.dsn)
+	case dialectMySQL:
+		cfg, err := mysqlConfig(s.dsn)
+		if err != nil {
+			return nil, err
+		}
+		return mysql.NewConnector(cfg)
+	case dialectSQLServer:
+		cfg, err := sqlServerConfig(s.dsn)
+		if err != nil {
+			return nil, err
+		}
+		connector := mssql.NewConnectorConfig(cfg)
+		connector.Dialer = common.ContextDialer{}
+		return connector, nil
+	default:
+		return nil, fmt.Errorf("no connector for %s databases", s.dialect)
+	}
+}
+
+// mysqlProxyNet is the network of MySQL connections over TCP, which
+// connect through common.DialContext.
+const mysqlProxyNet = "trufflehog-tcp"
+
+// mysqlConfig returns the config of a MySQL DSN, whose tls parameter selects
+// whether the certificate of the server is verified.
+func mysqlConfig(dsn string) (*mysql.Config, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+	if cfg.Net == "tcp" {
+		mysql.RegisterDialContext(mysqlProxyNet, func(ctx gocontext.Context, addr string) (net.Conn, error) {
+			return common.DialContext(ctx, "tcp", addr)
+		})
+		cfg.Net = mysqlProxyNet
+	}
+	if cfg.TLS != nil {
+		tlsConfig := common.TLSClientConfig()
+		tlsConfig.ServerName = cfg.TLS.ServerName
+		tlsConfig.InsecureSkipVerify = cfg.TLS.InsecureSkipVerify
+		cfg.TLS = tlsConfig
+	}
+	return cfg, nil
+}
+
+// sqlServerConfig returns the config of a SQL Server DSN, whose
+// TrustServerCertificate and certificate parameters are kept.
+func sqlServerConfig(dsn string) (msdsn.Config, error) {
+	cfg, _, err := msdsn.Parse(dsn)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid DSN: %w", err)
+	}
+	if dsnConfig := cfg.TLSConfig; dsnConfig != nil {
+		tlsConfig := common.TLSClientConfig()
+		tlsConfig.ServerName = dsnConfig.ServerName
+		tlsConfig.InsecureSkipVerify = dsnConfig.InsecureSkipVerify
+		tlsConfig.DynamicRecordSizingDisabled = dsnConfig.DynamicRecordSizingDisabled
+		// The certificate parameter replaces the trusted CAs, as it does
+		// for the driver.
+		if dsnConfig.RootCAs != nil {
+			tlsConfig.RootCAs = dsnConfig.RootCAs
+		}
+		tlsConfig.VerifyPeerCertificate = dsnConfig.VerifyPeerCertificate
+		cfg.TLSConfig = tlsConfig
+	}
+	return cfg, nil
+}
+
+// postgresConnector returns a connector of a Postgres URL. lib/pq builds
+// its TLS configs itself, so the TLS of the sslmode of the URL is set up by
+// postgresDialer instead, and lib/pq connects without it.
+func postgresConnector(dsn string) (driver.Connector, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+	query := u.Query()
+	tlsConfig, err := postgresTLSConfig(u.Hostname(), query)
+	if err != nil {
+		return nil, err
+	}
+	query.Set("sslmode", "disable")
+	u.RawQuery = query.Encode()
+
+	connector, err := pq.NewConnector(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+	connector.Dialer(postgresDialer{tlsConfig: tlsConfig})
+	return connector, nil
+}
+
+// postgresTLSConfig returns the TLS config of the sslmode, sslrootcert,
+// sslcert and sslkey parameters, as lib/pq interprets them, or nil if
+// sslmode is disable.
+func postgresTLSConfig(host string, query url.Values) (*tls.Config, error) {
+	mode := query.Get("sslmode")
+	switch mode {
+	case "disable":
+		return nil, nil
+	case "", "require", "verify-ca", "verify-full":
+	default:
+		return nil, fmt.Errorf("unsupported sslmode %q, it should be disable, require, verify-ca or verify-full", mode)
+	}
+
+	tlsConfig := common.TLSClientConfig()
+	tlsConfig.ServerName = host
+	tlsConfig.Renegotiation = tls.RenegotiateFreelyAsClient
+	if rootCert := query.Get("sslrootcert"); rootCert != "" {
+		pem, err := os.ReadFile(rootCert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading sslrootcert: %w", err)
+		}
+		if err := common.AddRootCAs(tlsConfig, pem); err != nil {
+			return nil, fmt.Errorf("invalid sslrootcert: %w", err)
+		}
+		// Like libpq, require verifies the CA when a root certificate is set.
+		if mode == "" || mode == "require" {
+			mode = "verify-ca"
+		}
+	}
+	if certFile, keyFile := query.Get("sslcert"), query.Get("sslkey"); certFile != "" && keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading sslcert: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		tlsConfig.GetClientCertificate = nil
+	}
+
+	switch mode {
+	case "", "require":
+		tlsConfig.InsecureSkipVerify = true
+	case "verify-ca":
+		// The chain is verified, but not the name of the host.
+		tlsConfig.InsecureSkipVerify = true
+		roots := tlsConfig.RootCAs
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			intermediates := x509.NewCertPool()
+			for _, cert := range state.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+			return err
+		}
+	}
+	return tlsConfig, nil
+}
+
+// sslRequest is the SSLRequest message of the Postgres protocol, which asks
+// the server to switch to TLS.
+var sslRequest = []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}
+
+// postgresDialer connects to Postgres servers through common.DialContext,
+// switching to TLS with its config unless it is nil. Unix sockets are not
+// encrypted, like with libpq.
+type postgresDialer struct {
+	tlsConfig *tls.Config
+}
+
+func (d postgresDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(gocontext.Background(), network, address)
+}
+
+func (d postgresDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), timeout)
+	defer cancel()
+	return d.DialContext(ctx, network, address)
+}
+
+func (d postgresDialer) DialContext(ctx gocontext.Context, network, address string) (net.Conn, error) {
+	conn, err := common.DialContext(ctx, network, address)
+	if err != nil || d.tlsConfig == nil || network == "unix" {
+		return conn, err
+	}
+	tlsConn, err := startTLS(ctx, conn, d.tlsConfig)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// startTLS switches a connection to a Postgres server to TLS.
+func startTLS(ctx gocontext.Context, conn net.Conn, tlsConfig *tls.Config) (net.Conn, error) {
+	if _, err := conn.Write(sslRequest); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, 1)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return nil, err
+	}
+	if reply[0] != 'S' {
+		return nil, errors.New("the server does not support SSL, set sslmode=disable to connect without it")
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
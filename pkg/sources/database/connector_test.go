@@ -0,0 +1,204 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestMySQLConfig(t *testing.T) {
+	require.NoError(t, common.ConfigureTLS(common.TLSConfig{MinVersion: "1.3"}))
+
+	cfg, err := mysqlConfig("user:pw@tcp(db:3306)/app?tls=true")
+	require.NoError(t, err)
+	require.NotNil(t, cfg.TLS)
+	assert.Equal(t, uint16(tls.VersionTLS13), cfg.TLS.MinVersion)
+	assert.Equal(t, "db", cfg.TLS.ServerName)
+	assert.False(t, cfg.TLS.InsecureSkipVerify)
+	assert.Equal(t, mysqlProxyNet, cfg.Net)
+
+	cfg, err = mysqlConfig("user:pw@tcp(db:3306)/app?tls=skip-verify")
+	require.NoError(t, err)
+	require.NotNil(t, cfg.TLS)
+	assert.Equal(t, uint16(tls.VersionTLS13), cfg.TLS.MinVersion)
+	assert.True(t, cfg.TLS.InsecureSkipVerify)
+
+	cfg, err = mysqlConfig("user:pw@tcp(db:3306)/app")
+	require.NoError(t, err)
+	assert.Nil(t, cfg.TLS)
+}
+
+func TestSQLServerConfig(t *testing.T) {
+	require.NoError(t, common.ConfigureTLS(common.TLSConfig{MinVersion: "1.3"}))
+
+	cfg, err := sqlServerConfig("sqlserver://sa:pw@db?database=app&encrypt=true")
+	require.NoError(t, err)
+	require.NotNil(t, cfg.TLSConfig)
+	assert.Equal(t, uint16(tls.VersionTLS13), cfg.TLSConfig.MinVersion)
+	assert.Equal(t, "db", cfg.TLSConfig.ServerName)
+	assert.False(t, cfg.TLSConfig.InsecureSkipVerify)
+
+	cfg, err = sqlServerConfig("sqlserver://sa:pw@db?database=app&TrustServerCertificate=true")
+	require.NoError(t, err)
+	require.NotNil(t, cfg.TLSConfig)
+	assert.True(t, cfg.TLSConfig.InsecureSkipVerify)
+
+	cfg, err = sqlServerConfig("sqlserver://sa:pw@db?database=app&encrypt=disable")
+	require.NoError(t, err)
+	assert.Nil(t, cfg.TLSConfig)
+}
+
+// testPostgresServer returns the address of a server that accepts the
+// SSLRequest of Postgres clients and completes their TLS handshakes, and the
+// file of its self-signed certificate, which is valid for 127.0.0.1 but not
+// localhost.
+func testPostgresServer(t *testing.T) (string, string) {
+	t.Helper()
+	https := httptest.NewTLSServer(nil)
+	t.Cleanup(https.Close)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				request := make([]byte, len(sslRequest))
+				if _, err := io.ReadFull(conn, request); err != nil || !bytes.Equal(request, sslRequest) {
+					return
+				}
+				if _, err := conn.Write([]byte("S")); err != nil {
+					return
+				}
+				_ = tls.Server(conn, https.TLS).Handshake()
+			}()
+		}
+	}()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: https.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0644))
+	return listener.Addr().String(), caFile
+}
+
+func TestPostgresDialer(t *testing.T) {
+	addr, caFile := testPostgresServer(t)
+	_, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+
+	for name, tt := range map[string]struct {
+		host    string
+		query   url.Values
+		wantErr bool
+	}{
+		"require":                  {host: "127.0.0.1", query: url.Values{}},
+		"verify-full":              {host: "127.0.0.1", query: url.Values{"sslmode": {"verify-full"}, "sslrootcert": {caFile}}},
+		"verify-full untrusted":    {host: "127.0.0.1", query: url.Values{"sslmode": {"verify-full"}}, wantErr: true},
+		"verify-full wrong host":   {host: "localhost", query: url.Values{"sslmode": {"verify-full"}, "sslrootcert": {caFile}}, wantErr: true},
+		"verify-ca wrong host":     {host: "localhost", query: url.Values{"sslmode": {"verify-ca"}, "sslrootcert": {caFile}}},
+		"require with sslrootcert": {host: "localhost", query: url.Values{"sslmode": {"require"}, "sslrootcert": {caFile}}},
+		"verify-ca untrusted":      {host: "localhost", query: url.Values{"sslmode": {"verify-ca"}}, wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tlsConfig, err := postgresTLSConfig(tt.host, tt.query)
+			require.NoError(t, err)
+			conn, err := postgresDialer{tlsConfig: tlsConfig}.DialContext(context.Background(), "tcp", net.JoinHostPort(tt.host, port))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				_ = conn.Close()
+			}
+		})
+	}
+}
+
+// connectProxy returns the address of an HTTP proxy that allows CONNECT, and
+// a channel of the targets of its tunnels.
+func connectProxy(t *testing.T) (string, chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	tunnels := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				req, err := http.ReadRequest(r)
+				if err != nil || req.Method != http.MethodConnect {
+					return
+				}
+				upstream, err := net.Dial("tcp", req.Host)
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+				tunnels <- req.Host
+				fmt.Fprint(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+				go func() { _, _ = io.Copy(upstream, r) }()
+				_, _ = io.Copy(conn, upstream)
+			}()
+		}
+	}()
+	return listener.Addr().String(), tunnels
+}
+
+func TestPostgresDialer_Proxy(t *testing.T) {
+	addr, caFile := testPostgresServer(t)
+	proxyAddr, tunnels := connectProxy(t)
+	require.NoError(t, common.ConfigureProxy(common.ProxyConfig{
+		Rules: []common.ProxyRule{{Host: "127.0.0.1", Proxy: "http://" + proxyAddr}},
+	}))
+	t.Cleanup(func() { _ = common.ConfigureProxy(common.ProxyConfig{}) })
+
+	tlsConfig, err := postgresTLSConfig("127.0.0.1", url.Values{"sslmode": {"verify-full"}, "sslrootcert": {caFile}})
+	require.NoError(t, err)
+	conn, err := postgresDialer{tlsConfig: tlsConfig}.DialContext(context.Background(), "tcp", addr)
+	require.NoError(t, err)
+	_ = conn.Close()
+	assert.Equal(t, addr, <-tunnels)
+}
+
+func TestPostgresTLSConfig(t *testing.T) {
+	require.NoError(t, common.ConfigureTLS(common.TLSConfig{MinVersion: "1.3"}))
+
+	tlsConfig, err := postgresTLSConfig("db", url.Values{"sslmode": {"verify-full"}})
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.Equal(t, "db", tlsConfig.ServerName)
+	assert.False(t, tlsConfig.InsecureSkipVerify)
+
+	tlsConfig, err = postgresTLSConfig("db", url.Values{"sslmode": {"disable"}})
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	_, err = postgresTLSConfig("db", url.Values{"sslmode": {"prefer"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// testDatabase returns the DSN of a SQLite database with tables of a single
+// key, of a composite key and without a key.
+func testDatabase(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name VARCHAR(64), password TEXT, age INTEGER, avatar BLOB)`,
+		`INSERT INTO users VALUES (1, 'ada', 'hunter2', 36, x'00'), (2, 'bob', NULL, 40, NULL), (3, 'eve', '', 22, NULL), (10, 'mal', 'AKIA', 30, NULL)`,
+		`CREATE TABLE settings (scope TEXT, name TEXT, value, PRIMARY KEY (scope, name))`,
+		`INSERT INTO settings VALUES ('smtp', 'password', 'p4ss')`,
+		`CREATE TABLE notes (body TEXT)`,
+		`INSERT INTO notes VALUES ('token=xyz')`,
+		`CREATE TABLE counters (id INTEGER PRIMARY KEY, n INTEGER)`,
+		`INSERT INTO counters VALUES (1, 2)`,
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+	return "sqlite://" + path
+}
+
+// chunks returns the data of the chunks of a source by location, and their
+// metadata.
+func chunks(t *testing.T, conn *sourcespb.Database) (map[string]string, map[string]*source_metadatapb.Database) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+	return sources.ChunksByKey(sources.RequireChunks(t, s), (*source_metadatapb.MetaData).GetDatabase, (*source_metadatapb.Database).GetLocation)
+}
+
+func TestSource_Chunks(t *testing.T) {
+	got, metadata := chunks(t, &sourcespb.Database{Dsn: testDatabase(t), BatchSize: 2})
+	assert.Equal(t, map[string]string{
+		"app.db.main.users.name@id=1":                         "ada",
+		"app.db.main.users.password@id=1":                     "hunter2",
+		"app.db.main.users.name@id=2":                         "bob",
+		"app.db.main.users.name@id=3":                         "eve",
+		"app.db.main.users.name@id=10":                        "mal",
+		"app.db.main.users.password@id=10":                    "AKIA",
+		"app.db.main.settings.scope@scope=smtp,name=password": "smtp",
+		"app.db.main.settings.name@scope=smtp,name=password":  "password",
+		"app.db.main.settings.value@scope=smtp,name=password": "p4ss",
+		"app.db.main.notes.body":                              "token=xyz",
+	}, got)
+	assert.Equal(t, &source_metadatapb.Database{
+		Database:   "app.db",
+		Table:      "main.users",
+		Column:     "password",
+		PrimaryKey: "id=10",
+		Location:   "app.db.main.users.password@id=10",
+	}, metadata["app.db.main.users.password@id=10"])
+}
+
+func TestSource_ChunksGlobs(t *testing.T) {
+	got, _ := chunks(t, &sourcespb.Database{
+		Dsn:          testDatabase(t),
+		IncludeGlobs: []string{"main.users", "main.settings.value"},
+		ExcludeGlobs: []string{"*.*.name"},
+	})
+	assert.Equal(t, map[string]string{
+		"app.db.main.users.password@id=1":                     "hunter2",
+		"app.db.main.users.password@id=10":                    "AKIA",
+		"app.db.main.settings.value@scope=smtp,name=password": "p4ss",
+	}, got)
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Database{Dsn: testDatabase(t)}, 2)
+	objects := make(map[string]int64)
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		objects[target.Name] = target.Objects
+	}))
+	// Tables without text columns are not scanned.
+	assert.Equal(t, map[string]int64{"main.users": 4, "main.settings": 1, "main.notes": 1}, objects)
+}
+
+func TestParseDSN(t *testing.T) {
+	for dsn, want := range map[string]struct {
+		dialect dialect
+		dsn     string
+	}{
+		"postgres://user:pw@localhost:5432/app?sslmode=disable": {dialectPostgres, "postgres://user:pw@localhost:5432/app?sslmode=disable"},
+		"mysql://user:pw@db:3306/app?tls=true":                  {dialectMySQL, "user:pw@tcp(db:3306)/app?tls=true"},
+		"mssql://sa:pw@localhost?database=app":                  {dialectSQLServer, "sqlserver://sa:pw@localhost?database=app"},
+		"sqlite:///var/lib/app.db":                              {dialectSQLite, "file:/var/lib/app.db?mode=ro"},
+	} {
+		d, driverDSN, _, err := parseDSN(dsn)
+		require.NoError(t, err, dsn)
+		assert.Equal(t, want.dialect, d, dsn)
+		assert.Equal(t, want.dsn, driverDSN, dsn)
+	}
+}
+
+func TestIsLocal(t *testing.T) {
+	for dsn, want := range map[string]bool{
+		"sqlite:///var/lib/app.db":         true,
+		"sqlite3:app.db":                   true,
+		"postgres://user:pw@localhost/app": false,
+		"mysql://user:pw@db:3306/app":      false,
+		"sqlite://":                        false,
+		"app.db":                           false,
+	} {
+		assert.Equal(t, want, IsLocal(dsn), dsn)
+	}
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.Database{
+		"no dsn":       {},
+		"no scheme":    {Dsn: "localhost:5432"},
+		"unsupported":  {Dsn: "oracle://localhost/app"},
+		"no path":      {Dsn: "sqlite://"},
+		"invalid glob": {Dsn: "postgres://localhost/app", ExcludeGlobs: []string{"[a"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
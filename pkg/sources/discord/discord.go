@@ -0,0 +1,474 @@
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// defaultAPIURL is the URL of the Discord API.
+	defaultAPIURL = "https://discord.com/api/v10"
+	// webURL is the URL links to messages are relative to.
+	webURL = "https://discord.com"
+	// pageSize is the number of messages and threads requested at a time,
+	// the most Discord returns.
+	pageSize = 100
+	// guildsPageSize is the number of guilds requested at a time.
+	guildsPageSize = 200
+	// maxAttachmentSize is the size of the largest attachments scanned.
+	maxAttachmentSize = 250 * 1024 * 1024
+
+	locationMessage    = "message"
+	locationAttachment = "attachment"
+)
+
+// Types of channels.
+const (
+	channelText         = 0
+	channelVoice        = 2
+	channelAnnouncement = 5
+	channelStage        = 13
+	channelForum        = 15
+)
+
+// errMissingAccess is returned for the channels the bot cannot read, which
+// are skipped.
+var errMissingAccess = errors.New("missing access")
+
+// Source scans the messages of the channels and threads of the Discord
+// guilds (servers) of a bot, and their attachments.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	client *http.Client
+	apiURL string
+	conn   *sourcespb.Discord
+	// archiveOptions configures how the archives of attachments are
+	// extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_DISCORD
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of attachments are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized Discord source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+	s.client = common.RetryableHttpClientTimeout(120)
+	s.apiURL = defaultAPIURL
+
+	var conn sourcespb.Discord
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	if conn.GetToken() == "" {
+		return errors.New("the token of a bot is required")
+	}
+	s.conn = &conn
+	return nil
+}
+
+type guild struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type channel struct {
+	ID       string `json:"id"`
+	Type     int    `json:"type"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id"`
+	// ThreadMetadata is that of threads.
+	ThreadMetadata struct {
+		ArchiveTimestamp string `json:"archive_timestamp"`
+	} `json:"thread_metadata"`
+}
+
+type message struct {
+	ID        string    `json:"id"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+	Author    struct {
+		ID         string `json:"id"`
+		Username   string `json:"username"`
+		GlobalName string `json:"global_name"`
+	} `json:"author"`
+	Attachments []struct {
+		Filename string `json:"filename"`
+		Size     int64  `json:"size"`
+		URL      string `json:"url"`
+	} `json:"attachments"`
+	Embeds []struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		URL         string `json:"url"`
+		Fields      []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	} `json:"embeds"`
+}
+
+// Chunks emits the messages of the channels and threads of each guild, and
+// their attachments, as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	guilds, err := s.guilds(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing guilds: %w", err)
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, g := range guilds {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(guilds), fmt.Sprintf("Guild: %s", g.Name), "")
+		channels, err := s.channels(ctx, g)
+		if err != nil {
+			scanErrs.Add(fmt.Errorf("error listing channels of guild %s: %w", g.Name, err))
+			continue
+		}
+		for _, ch := range channels {
+			g, ch := g, ch
+			s.jobPool.Go(func() error {
+				if err := s.scanChannel(ctx, g, ch, chunksChan); err != nil {
+					scanErrs.Add(fmt.Errorf("error scanning channel %s of guild %s: %w", ch.Name, g.Name, err))
+				}
+				return nil
+			})
+		}
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// guilds returns the guilds of the bot that the connection selects.
+func (s *Source) guilds(ctx context.Context) ([]guild, error) {
+	var guilds []guild
+	query := url.Values{"limit": {strconv.Itoa(guildsPageSize)}}
+	for {
+		var page []guild
+		if err := s.get(ctx, "/users/@me/guilds?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+		for _, g := range page {
+			if len(s.conn.GetGuilds()) == 0 || matches(s.conn.GetGuilds(), g.ID, g.Name) {
+				guilds = append(guilds, g)
+			}
+		}
+		if len(page) < guildsPageSize {
+			return guilds, nil
+		}
+		query.Set("after", page[len(page)-1].ID)
+	}
+}
+
+// channels returns the channels and threads of a guild that have messages
+// and that the connection selects. Channels are also selected by their
+// category, and threads by the channel they are in.
+func (s *Source) channels(ctx context.Context, g guild) ([]channel, error) {
+	var all []channel
+	if err := s.get(ctx, "/guilds/"+g.ID+"/channels", &all); err != nil {
+		return nil, err
+	}
+	var active struct {
+		Threads []channel `json:"threads"`
+	}
+	if err := s.get(ctx, "/guilds/"+g.ID+"/threads/active", &active); err != nil {
+		return nil, fmt.Errorf("error listing active threads: %w", err)
+	}
+
+	names := make(map[string]string, len(all))
+	for _, ch := range all {
+		names[ch.ID] = ch.Name
+	}
+	selected := func(ch channel) bool {
+		return len(s.conn.GetChannels()) == 0 ||
+			matches(s.conn.GetChannels(), ch.ID, ch.Name) ||
+			(ch.ParentID != "" && matches(s.conn.GetChannels(), ch.ParentID, names[ch.ParentID]))
+	}
+
+	var channels []channel
+	for _, ch := range all {
+		switch ch.Type {
+		case channelText, channelVoice, channelAnnouncement, channelStage:
+			if selected(ch) {
+				channels = append(channels, ch)
+			}
+		}
+	}
+	for _, ch := range active.Threads {
+		if selected(ch) {
+			channels = append(channels, ch)
+		}
+	}
+	// Archived threads are only listed by the channels they are in.
+	for _, ch := range all {
+		if ch.Type != channelText && ch.Type != channelAnnouncement && ch.Type != channelForum {
+			continue
+		}
+		if !selected(ch) {
+			continue
+		}
+		threads, err := s.archivedThreads(ctx, ch)
+		if errors.Is(err, errMissingAccess) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error listing archived threads of channel %s: %w", ch.Name, err)
+		}
+		channels = append(channels, threads...)
+	}
+	return channels, nil
+}
+
+// archivedThreads returns the public archived threads of a channel.
+func (s *Source) archivedThreads(ctx context.Context, ch channel) ([]channel, error) {
+	var threads []channel
+	query := url.Values{"limit": {strconv.Itoa(pageSize)}}
+	for {
+		var page struct {
+			Threads []channel `json:"threads"`
+			HasMore bool      `json:"has_more"`
+		}
+		if err := s.get(ctx, "/channels/"+ch.ID+"/threads/archived/public?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+		threads = append(threads, page.Threads...)
+		if !page.HasMore || len(page.Threads) == 0 {
+			return threads, nil
+		}
+		query.Set("before", page.Threads[len(page.Threads)-1].ThreadMetadata.ArchiveTimestamp)
+	}
+}
+
+// matches returns whether a list has the ID or the name of a guild or a
+// channel.
+func matches(list []string, id, name string) bool {
+	for _, item := range list {
+		item = strings.TrimPrefix(item, "#")
+		if item == id || (name != "" && item == name) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanChannel scans the history of a channel or a thread, from its latest
+// message.
+func (s *Source) scanChannel(ctx context.Context, g guild, ch channel, chunksChan chan *sources.Chunk) error {
+	query := url.Values{"limit": {strconv.Itoa(pageSize)}}
+	for {
+		var page []message
+		err := s.get(ctx, "/channels/"+ch.ID+"/messages?"+query.Encode(), &page)
+		if errors.Is(err, errMissingAccess) {
+			ctx.Logger().V(3).Info("skipping channel the bot cannot read", "guild", g.Name, "channel", ch.Name)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, msg := range page {
+			if err := s.scanMessage(ctx, g, ch, msg, chunksChan); err != nil {
+				return err
+			}
+		}
+		if len(page) < pageSize || common.IsDone(ctx) {
+			return nil
+		}
+		query.Set("before", page[len(page)-1].ID)
+	}
+}
+
+// scanMessage scans the content and the embeds of a message, and its
+// attachments.
+func (s *Source) scanMessage(ctx context.Context, g guild, ch channel, msg message, chunksChan chan *sources.Chunk) error {
+	var text strings.Builder
+	text.WriteString(msg.Content)
+	for _, e := range msg.Embeds {
+		for _, value := range []string{e.Title, e.Description, e.URL} {
+			if value != "" {
+				text.WriteString("\n" + value)
+			}
+		}
+		for _, f := range e.Fields {
+			text.WriteString("\n" + f.Name + ": " + f.Value)
+		}
+	}
+	if text.Len() > 0 {
+		skel := s.chunkSkeleton(g, ch, msg, locationMessage)
+		if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(text.String()), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+			return err
+		}
+	}
+
+	if s.conn.GetSkipAttachments() {
+		return nil
+	}
+	for _, a := range msg.Attachments {
+		location := locationAttachment + "/" + a.Filename
+		if a.Size > maxAttachmentSize {
+			sources.ReportSkipBytes(ctx, ch.Name+"/"+msg.ID+"/"+a.Filename, sources.SkipReasonSize, a.Size)
+			continue
+		}
+		skel := s.chunkSkeleton(g, ch, msg, location)
+		if err := s.scanAttachment(ctx, skel, a.URL, chunksChan); err != nil {
+			return fmt.Errorf("error scanning attachment %s: %w", a.Filename, err)
+		}
+	}
+	return nil
+}
+
+// scanAttachment scans an attachment, through the handlers of archives.
+func (s *Source) scanAttachment(ctx context.Context, skel *sources.Chunk, attachmentURL string, chunksChan chan *sources.Chunk) error {
+	res, err := s.do(ctx, attachmentURL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	reader, err := diskbufferreader.New(io.LimitReader(res.Body, maxAttachmentSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+	return sources.ChunkReaderTo(ctx, skel, reader, chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+func (s *Source) chunkSkeleton(g guild, ch channel, msg message, location string) *sources.Chunk {
+	author := msg.Author.GlobalName
+	if author == "" {
+		author = msg.Author.Username
+	}
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Discord{
+				Discord: &source_metadatapb.Discord{
+					GuildId:     g.ID,
+					GuildName:   sanitizer.UTF8(g.Name),
+					ChannelId:   ch.ID,
+					ChannelName: sanitizer.UTF8(ch.Name),
+					MessageId:   msg.ID,
+					Author:      sanitizer.UTF8(author),
+					Location:    sanitizer.UTF8(location),
+					Link:        webURL + "/channels/" + g.ID + "/" + ch.ID + "/" + msg.ID,
+					Timestamp:   msg.Timestamp.UTC().Format(time.RFC3339),
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// get decodes the JSON response of a request of the API.
+func (s *Source) get(ctx context.Context, path string, v any) error {
+	res, err := s.do(ctx, s.apiURL+path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// do sends a GET request, authorized if it is to the API, and returns its
+// response if it succeeded. Attachments are served from the CDN, whose
+// URLs are signed. Rate limited requests are retried after the time
+// Discord asks for, and when the rate limit of a request is exhausted, do
+// waits for it to reset before returning, so that the next page is not
+// rate limited.
+func (s *Source) do(ctx context.Context, reqURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(reqURL, s.apiURL+"/") {
+		req.Header.Set("Authorization", "Bot "+s.conn.GetToken())
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		switch res.StatusCode {
+		case http.StatusUnauthorized:
+			return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+		case http.StatusForbidden:
+			return nil, errMissingAccess
+		}
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	if res.Header.Get("X-RateLimit-Remaining") == "0" {
+		resetAfter, _ := strconv.ParseFloat(res.Header.Get("X-RateLimit-Reset-After"), 64)
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Duration(resetAfter * float64(time.Second))):
+		}
+	}
+	return res, nil
+}
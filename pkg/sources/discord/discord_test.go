@@ -0,0 +1,170 @@
+package discord
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func initSource(t *testing.T, conn *sourcespb.Discord, apiURL string) *Source {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 4)
+	s.apiURL = apiURL
+	return s
+}
+
+// fakeDiscord serves the API for a bot in two guilds, whose first has a
+// text channel with two pages of history, a thread and a channel the bot
+// cannot read. The first request of the history is rate limited.
+type fakeDiscord struct {
+	mu          sync.Mutex
+	rateLimited bool
+}
+
+func (f *fakeDiscord) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, "AWS_SECRET_ACCESS_KEY=xyz")
+	}))
+	t.Cleanup(cdn.Close)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bot token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/users/@me/guilds":
+			fmt.Fprint(w, `[{"id":"g1","name":"Acme"},{"id":"g2","name":"Gaming"}]`)
+		case "/guilds/g1/channels":
+			fmt.Fprint(w, `[{"id":"cat","type":4,"name":"Engineering"},{"id":"c1","type":0,"name":"ops","parent_id":"cat"},{"id":"c2","type":0,"name":"secret"},{"id":"v1","type":2,"name":"voice"}]`)
+		case "/guilds/g2/channels":
+			fmt.Fprint(w, `[{"id":"c3","type":0,"name":"general"}]`)
+		case "/guilds/g1/threads/active":
+			fmt.Fprint(w, `{"threads":[{"id":"t1","type":11,"name":"incident","parent_id":"c1"}]}`)
+		case "/guilds/g2/threads/active":
+			fmt.Fprint(w, `{"threads":[]}`)
+		case "/channels/c1/threads/archived/public", "/channels/c3/threads/archived/public":
+			fmt.Fprint(w, `{"threads":[],"has_more":false}`)
+		case "/channels/c2/threads/archived/public", "/channels/c2/messages":
+			w.WriteHeader(http.StatusForbidden)
+		case "/channels/c1/messages":
+			f.mu.Lock()
+			limited := f.rateLimited
+			f.rateLimited = true
+			f.mu.Unlock()
+			if !limited {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				fmt.Fprint(w, `{"message":"You are being rate limited.","retry_after":0.01,"global":false}`)
+				return
+			}
+			if r.URL.Query().Get("before") == "" {
+				// A full page, whose next page is requested before its last
+				// message.
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset-After", "0.01")
+				messages := make([]string, 0, pageSize)
+				messages = append(messages, fmt.Sprintf(`{"id":"m1","content":"password=hunter2","timestamp":"2024-01-02T03:04:05.000000+00:00","author":{"id":"u1","username":"ada","global_name":"Ada"},
+					"attachments":[{"filename":"config.env","size":25,"url":%q}]}`, cdn.URL+"/attachments/c1/a1/config.env?ex=1&hm=abc"))
+				for i := 1; i < pageSize; i++ {
+					messages = append(messages, fmt.Sprintf(`{"id":"m%d","content":"","timestamp":"2024-01-01T00:00:00Z","author":{"username":"bot"}}`, 1000+i))
+				}
+				fmt.Fprintf(w, "[%s]", strings.Join(messages, ","))
+				return
+			}
+			assert.Equal(t, fmt.Sprintf("m%d", 1000+pageSize-1), r.URL.Query().Get("before"))
+			fmt.Fprint(w, `[{"id":"m0","content":"","timestamp":"2024-01-01T00:00:00Z","author":{"username":"deploy"},"embeds":[{"title":"Deploy","fields":[{"name":"token","value":"abc123"}]}]}]`)
+		case "/channels/t1/messages":
+			fmt.Fprint(w, `[{"id":"m5","content":"rotated to hunter3","timestamp":"2024-01-03T00:00:00Z","author":{"username":"bob"}}]`)
+		case "/channels/v1/messages":
+			fmt.Fprint(w, `[{"id":"m8","content":"standup notes","timestamp":"2024-01-03T00:00:00Z","author":{"username":"dave"}}]`)
+		case "/channels/c3/messages":
+			fmt.Fprint(w, `[{"id":"m9","content":"gg","timestamp":"2024-01-03T00:00:00Z","author":{"username":"carol"}}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSource_Chunks(t *testing.T) {
+	f := &fakeDiscord{}
+	server := f.server(t)
+	s := initSource(t, &sourcespb.Discord{Token: "token"}, server.URL)
+
+	got := sources.RequireChunks(t, s)
+	assert.Equal(t, &source_metadatapb.Discord{
+		GuildId:     "g1",
+		GuildName:   "Acme",
+		ChannelId:   "c1",
+		ChannelName: "ops",
+		MessageId:   "m1",
+		Author:      "Ada",
+		Location:    "message",
+		Link:        "https://discord.com/channels/g1/c1/m1",
+		Timestamp:   "2024-01-02T03:04:05Z",
+	}, sources.MetadataWith(got, "password=hunter2").GetDiscord())
+	assert.Equal(t, "attachment/config.env", sources.MetadataWith(got, "AWS_SECRET_ACCESS_KEY=xyz").GetDiscord().GetLocation())
+	assert.Equal(t, "m0", sources.MetadataWith(got, "Deploy\ntoken: abc123").GetDiscord().GetMessageId())
+	thread := sources.MetadataWith(got, "rotated to hunter3").GetDiscord()
+	require.NotNil(t, thread)
+	assert.Equal(t, "incident", thread.GetChannelName())
+	assert.Equal(t, "https://discord.com/channels/g1/t1/m5", thread.GetLink())
+	assert.Equal(t, "voice", sources.MetadataWith(got, "standup notes").GetDiscord().GetChannelName())
+	assert.Equal(t, "Gaming", sources.MetadataWith(got, "gg").GetDiscord().GetGuildName())
+}
+
+func TestSource_ChunksSelected(t *testing.T) {
+	f := &fakeDiscord{}
+	server := f.server(t)
+	s := initSource(t, &sourcespb.Discord{
+		Token:           "token",
+		Guilds:          []string{"Acme"},
+		Channels:        []string{"#ops"},
+		SkipAttachments: true,
+	}, server.URL)
+
+	got := sources.RequireChunks(t, s)
+	assert.NotNil(t, sources.MetadataWith(got, "password=hunter2").GetDiscord())
+	assert.NotNil(t, sources.MetadataWith(got, "rotated to hunter3").GetDiscord())
+	assert.Nil(t, sources.MetadataWith(got, "gg").GetDiscord())
+	assert.Nil(t, sources.MetadataWith(got, "AWS_SECRET_ACCESS_KEY=xyz").GetDiscord())
+
+	// Channels are selected by their category.
+	s = initSource(t, &sourcespb.Discord{Token: "token", Channels: []string{"Engineering"}}, server.URL)
+	got = sources.RequireChunks(t, s)
+	assert.NotNil(t, sources.MetadataWith(got, "password=hunter2").GetDiscord())
+	assert.Nil(t, sources.MetadataWith(got, "gg").GetDiscord())
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := (&fakeDiscord{}).server(t)
+	s := initSource(t, &sourcespb.Discord{Token: "wrong"}, server.URL)
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid credentials")
+}
+
+func TestSource_Init(t *testing.T) {
+	anyConn, err := anypb.New(&sourcespb.Discord{})
+	require.NoError(t, err)
+	assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+}
@@ -0,0 +1,233 @@
+package docker
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// DaemonPrefix marks images that are read from the local Docker daemon
+// instead of a registry, e.g. docker://nginx:latest.
+const DaemonPrefix = "docker://"
+
+// daemonClient is the part of the Docker Engine API used to scan local images
+// and containers. Podman serves the same API on its socket.
+type daemonClient interface {
+	ImageList(ctx gocontext.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
+	ImageSave(ctx gocontext.Context, imageIDs []string) (io.ReadCloser, error)
+	ContainerList(ctx gocontext.Context, options types.ContainerListOptions) ([]types.Container, error)
+	ContainerInspect(ctx gocontext.Context, containerID string) (types.ContainerJSON, error)
+	ContainerExport(ctx gocontext.Context, containerID string) (io.ReadCloser, error)
+}
+
+// daemonClient returns the client of the daemon DOCKER_HOST points to, or of
+// the default socket.
+func (s *Source) daemonClient() (daemonClient, error) {
+	if s.daemon == nil {
+		c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+		if err != nil {
+			return nil, fmt.Errorf("could not connect to the Docker daemon: %w", err)
+		}
+		s.daemon = c
+	}
+	return s.daemon, nil
+}
+
+// scanDaemon scans the containers of the connection, and all local images
+// and running containers if asked to.
+func (s *Source) scanDaemon(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	containers := s.conn.GetContainers()
+	if len(containers) == 0 && !s.conn.GetAllLocalImages() && !s.conn.GetAllRunningContainers() {
+		return nil
+	}
+	daemon, err := s.daemonClient()
+	if err != nil {
+		return err
+	}
+
+	if s.conn.GetAllLocalImages() {
+		images, err := daemon.ImageList(ctx, types.ImageListOptions{})
+		if err != nil {
+			return fmt.Errorf("could not list local images: %w", err)
+		}
+		for _, image := range images {
+			ref := image.ID
+			if len(image.RepoTags) > 0 && image.RepoTags[0] != "<none>:<none>" {
+				ref = image.RepoTags[0]
+			}
+			if err := s.scanDaemonImage(ctx, chunksChan, ref); err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.conn.GetAllRunningContainers() {
+		running, err := daemon.ContainerList(ctx, types.ContainerListOptions{})
+		if err != nil {
+			return fmt.Errorf("could not list running containers: %w", err)
+		}
+		for _, container := range running {
+			containers = append(containers, container.ID)
+		}
+	}
+	for _, container := range containers {
+		if err := s.scanContainer(ctx, chunksChan, container); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanDaemonImage scans the layers and configuration of an image of the
+// local daemon, streaming them from the daemon's export of the image.
+func (s *Source) scanDaemonImage(ctx context.Context, chunksChan chan *sources.Chunk, image string) error {
+	daemon, err := s.daemonClient()
+	if err != nil {
+		return err
+	}
+	base, tag, _ := baseAndTagFromImage(image)
+	ctx.Logger().V(2).Info("scanning local image", "image", base, "tag", tag)
+
+	rc, err := daemon.ImageSave(ctx, []string{image})
+	if err != nil {
+		return fmt.Errorf("could not export image %s: %w", image, err)
+	}
+	defer rc.Close()
+
+	err = s.scanSavedImage(ctx, rc, chunksChan, func(file, layer string) *source_metadatapb.Docker {
+		return &source_metadatapb.Docker{File: file, Image: base, Tag: tag, Layer: layer}
+	})
+	if err != nil {
+		return fmt.Errorf("image %s: %w", image, err)
+	}
+	dockerImagesScanned.WithLabelValues(s.name).Inc()
+	return nil
+}
+
+// scanSavedImage scans an image in the format of docker save: the files of
+// its layers, and its configuration, which holds its environment variables
+// and the commands it was built with. Both the legacy format, with layers in
+// <id>/layer.tar, and the OCI layout, with layers and configuration in
+// blobs/sha256, are supported.
+func (s *Source) scanSavedImage(ctx context.Context, r io.Reader, chunksChan chan *sources.Chunk, metadata func(file, layer string) *source_metadatapb.Docker) error {
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		layer := savedLayerName(header.Name)
+		content := bufio.NewReaderSize(tarReader, 1024)
+		head, _ := content.Peek(512)
+		switch {
+		case isTar(head):
+			err = s.scanTar(ctx, content, chunksChan, func(file string) *source_metadatapb.Docker { return metadata(file, layer) })
+		case len(head) > 2 && head[0] == 0x1f && head[1] == 0x8b:
+			var gzipReader *gzip.Reader
+			if gzipReader, err = gzip.NewReader(content); err == nil {
+				err = s.scanTar(ctx, gzipReader, chunksChan, func(file string) *source_metadatapb.Docker { return metadata(file, layer) })
+				gzipReader.Close()
+			}
+		case header.Size <= FilesizeLimitBytes:
+			var data []byte
+			if data, err = io.ReadAll(content); err == nil && isImageConfig(data) {
+				err = s.emit(ctx, chunksChan, data, metadata("", ""))
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", header.Name, err)
+		}
+		if layer != "" {
+			dockerLayersScanned.WithLabelValues(s.name).Inc()
+		}
+	}
+}
+
+// savedLayerName returns the name of the layer at a path of a saved image.
+func savedLayerName(name string) string {
+	if digest, ok := strings.CutPrefix(name, "blobs/sha256/"); ok {
+		return "sha256:" + digest
+	}
+	if path.Base(name) == "layer.tar" {
+		return path.Dir(name)
+	}
+	return ""
+}
+
+func isTar(head []byte) bool {
+	return len(head) >= 262 && string(head[257:262]) == "ustar"
+}
+
+// isImageConfig reports whether a JSON file of a saved image is the image's
+// configuration, rather than a manifest.
+func isImageConfig(data []byte) bool {
+	var config struct {
+		RootFS json.RawMessage `json:"rootfs"`
+	}
+	return json.Unmarshal(data, &config) == nil && config.RootFS != nil
+}
+
+// scanContainer scans the configuration of a container, like its environment
+// variables and command, and the files of its filesystem, including those
+// changed since it started.
+func (s *Source) scanContainer(ctx context.Context, chunksChan chan *sources.Chunk, container string) error {
+	daemon, err := s.daemonClient()
+	if err != nil {
+		return err
+	}
+	info, err := daemon.ContainerInspect(ctx, container)
+	if err != nil {
+		return fmt.Errorf("could not inspect container %s: %w", container, err)
+	}
+	name := strings.TrimPrefix(info.Name, "/")
+	var base, tag string
+	if info.Config != nil {
+		base, tag, _ = baseAndTagFromImage(info.Config.Image)
+	}
+	metadata := func(file string) *source_metadatapb.Docker {
+		return &source_metadatapb.Docker{File: file, Image: base, Tag: tag, Container: name}
+	}
+	ctx.Logger().V(2).Info("scanning container", "container", name, "image", base)
+
+	if info.Config != nil {
+		config, err := json.Marshal(info.Config)
+		if err != nil {
+			return err
+		}
+		if err := s.emit(ctx, chunksChan, config, metadata("")); err != nil {
+			return err
+		}
+	}
+
+	rc, err := daemon.ContainerExport(ctx, info.ID)
+	if err != nil {
+		return fmt.Errorf("could not export container %s: %w", name, err)
+	}
+	defer rc.Close()
+	if err := s.scanTar(ctx, rc, chunksChan, metadata); err != nil {
+		return fmt.Errorf("container %s: %w", name, err)
+	}
+	dockerContainersScanned.WithLabelValues(s.name).Inc()
+	return nil
+}
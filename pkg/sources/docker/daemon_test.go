@@ -0,0 +1,178 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	gocontext "context"
+	"io"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+type tarFile struct {
+	name     string
+	content  []byte
+	typeflag byte
+}
+
+func makeTar(t *testing.T, files ...tarFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for _, f := range files {
+		typeflag := f.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		require.NoError(t, w.WriteHeader(&tar.Header{Name: f.name, Typeflag: typeflag, Size: int64(len(f.content)), Mode: 0644}))
+		_, err := w.Write(f.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+type fakeDaemon struct {
+	images     map[string][]byte
+	containers map[string]types.ContainerJSON
+	exports    map[string][]byte
+}
+
+func (d *fakeDaemon) ImageList(gocontext.Context, types.ImageListOptions) ([]types.ImageSummary, error) {
+	var list []types.ImageSummary
+	for name := range d.images {
+		list = append(list, types.ImageSummary{ID: "sha256:1", RepoTags: []string{name}})
+	}
+	return list, nil
+}
+
+func (d *fakeDaemon) ImageSave(_ gocontext.Context, images []string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(d.images[images[0]])), nil
+}
+
+func (d *fakeDaemon) ContainerList(gocontext.Context, types.ContainerListOptions) ([]types.Container, error) {
+	var list []types.Container
+	for id := range d.containers {
+		list = append(list, types.Container{ID: id})
+	}
+	return list, nil
+}
+
+func (d *fakeDaemon) ContainerInspect(_ gocontext.Context, id string) (types.ContainerJSON, error) {
+	return d.containers[id], nil
+}
+
+func (d *fakeDaemon) ContainerExport(_ gocontext.Context, id string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(d.exports[id])), nil
+}
+
+func newFakeDaemon(t *testing.T) *fakeDaemon {
+	layer := makeTar(t,
+		tarFile{name: "etc/", typeflag: tar.TypeDir},
+		tarFile{name: "etc/app.env", content: []byte("API_KEY=layer")},
+	)
+	ociLayer := gzipped(t, makeTar(t, tarFile{name: "srv/config.yaml", content: []byte("token: oci")}))
+	image := makeTar(t,
+		tarFile{name: "manifest.json", content: []byte(`[{"Config":"abc.json","Layers":["1/layer.tar"]}]`)},
+		tarFile{name: "abc.json", content: []byte(`{"config":{"Env":["PASSWORD=config"]},"rootfs":{"type":"layers"}}`)},
+		tarFile{name: "1/layer.tar", content: layer},
+		tarFile{name: "blobs/sha256/2", content: ociLayer},
+	)
+
+	export := makeTar(t,
+		tarFile{name: "dev/null", typeflag: tar.TypeChar},
+		tarFile{name: "root/.aws/credentials", content: []byte("aws_secret_access_key=container")},
+	)
+	return &fakeDaemon{
+		images: map[string][]byte{"app:1.0": image},
+		containers: map[string]types.ContainerJSON{
+			"c1": {
+				ContainerJSONBase: &types.ContainerJSONBase{ID: "c1", Name: "/web"},
+				Config:            &container.Config{Image: "nginx:1.25", Env: []string{"DB_PASSWORD=env"}},
+			},
+		},
+		exports: map[string][]byte{"c1": export},
+	}
+}
+
+func scanDaemonChunks(t *testing.T, conn *sourcespb.Docker) []*sources.Chunk {
+	t.Helper()
+	conn.Credential = &sourcespb.Docker_Unauthenticated{Unauthenticated: &credentialspb.Unauthenticated{}}
+	anyConn, err := anypb.New(conn)
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+	s.daemon = newFakeDaemon(t)
+
+	chunksChan := make(chan *sources.Chunk, 16)
+	require.NoError(t, s.Chunks(context.Background(), chunksChan))
+	close(chunksChan)
+	var chunks []*sources.Chunk
+	for chunk := range chunksChan {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func TestDaemonImageScan(t *testing.T) {
+	chunks := scanDaemonChunks(t, &sourcespb.Docker{Images: []string{DaemonPrefix + "app:1.0"}})
+	require.Len(t, chunks, 3)
+
+	assert.Contains(t, string(chunks[0].Data), "PASSWORD=config")
+	assert.Equal(t, "", chunks[0].SourceMetadata.GetDocker().File)
+
+	layer := chunks[1].SourceMetadata.GetDocker()
+	assert.Equal(t, "API_KEY=layer", string(chunks[1].Data))
+	assert.Equal(t, "/etc/app.env", layer.File)
+	assert.Equal(t, "app", layer.Image)
+	assert.Equal(t, "1.0", layer.Tag)
+	assert.Equal(t, "1", layer.Layer)
+
+	assert.Equal(t, "token: oci", string(chunks[2].Data))
+	assert.Equal(t, "sha256:2", chunks[2].SourceMetadata.GetDocker().Layer)
+}
+
+func TestDaemonContainerScan(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.Docker{
+		"by name": {Containers: []string{"c1"}},
+		"running": {AllRunningContainers: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			chunks := scanDaemonChunks(t, conn)
+			require.Len(t, chunks, 2)
+			assert.Contains(t, string(chunks[0].Data), "DB_PASSWORD=env")
+			assert.Equal(t, "aws_secret_access_key=container", string(chunks[1].Data))
+			metadata := chunks[1].SourceMetadata.GetDocker()
+			assert.Equal(t, "/root/.aws/credentials", metadata.File)
+			assert.Equal(t, "web", metadata.Container)
+			assert.Equal(t, "nginx", metadata.Image)
+			assert.Equal(t, "1.25", metadata.Tag)
+		})
+	}
+}
+
+func TestDaemonAllLocalImages(t *testing.T) {
+	chunks := scanDaemonChunks(t, &sourcespb.Docker{AllLocalImages: true})
+	assert.Len(t, chunks, 3)
+}
@@ -29,6 +29,9 @@ type Source struct {
 	jobId    int64
 	verify   bool
 	conn     sourcespb.Docker
+	// daemon is the client of the local Docker daemon, created when first
+	// needed.
+	daemon daemonClient
 	sources.Progress
 	sources.CommonSourceUnitUnmarshaller
 }
@@ -63,6 +66,7 @@ func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, ver
 	// Reset metrics for this source at initialization time.
 	dockerImagesScanned.WithLabelValues(s.name).Set(0)
 	dockerLayersScanned.WithLabelValues(s.name).Set(0)
+	dockerContainersScanned.WithLabelValues(s.name).Set(0)
 
 	if err := anypb.UnmarshalTo(connection, &s.conn, proto.UnmarshalOptions{}); err != nil {
 		return fmt.Errorf("error unmarshalling connection: %w", err)
@@ -87,6 +91,13 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 		var hasDigest bool
 		var imageName name.Reference
 
+		if local, ok := strings.CutPrefix(image, DaemonPrefix); ok {
+			if err := s.scanDaemonImage(ctx, chunksChan, local); err != nil {
+				return err
+			}
+			continue
+		}
+
 		if strings.HasPrefix(image, "file://") {
 			image = strings.TrimPrefix(image, "file://")
 			base = image
@@ -147,61 +158,84 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 
 			defer gzipReader.Close()
 
-			tarReader := tar.NewReader(gzipReader)
-
-			for {
-				header, err := tarReader.Next()
-				if errors.Is(err, io.EOF) {
-					break // End of archive
-				}
-				if err != nil {
-					return err
+			layer := digest.String()
+			err = s.scanTar(ctx, gzipReader, chunksChan, func(file string) *source_metadatapb.Docker {
+				return &source_metadatapb.Docker{
+					File:  file,
+					Image: base,
+					Tag:   tag,
+					Layer: layer,
 				}
+			})
+			if err != nil {
+				return err
+			}
 
-				// Skip files larger than FilesizeLimitBytes
-				if header.Size > FilesizeLimitBytes {
-					continue
-				}
+			dockerLayersScanned.WithLabelValues(s.name).Inc()
+		}
 
-				file := bytes.NewBuffer(make([]byte, 0, header.Size))
+		dockerImagesScanned.WithLabelValues(s.name).Inc()
+	}
 
-				_, err = io.Copy(file, tarReader)
-				if err != nil {
-					return err
-				}
+	return s.scanDaemon(ctx, chunksChan)
+}
 
-				chunk := &sources.Chunk{
-					SourceType: s.Type(),
-					SourceName: s.name,
-					SourceID:   s.SourceID(),
-					Data:       file.Bytes(),
-					SourceMetadata: &source_metadatapb.MetaData{
-						Data: &source_metadatapb.MetaData_Docker{
-							Docker: &source_metadatapb.Docker{
-								File:  "/" + header.Name, // Prepend /, because the file is relative to the root of the image
-								Image: base,
-								Tag:   tag,
-								Layer: digest.String(),
-							},
-						},
-					},
-					Verify: s.verify,
-				}
+// scanTar emits a chunk for each file of a tar archive of a filesystem, like
+// an image layer or an exported container, with the metadata returned for
+// its path.
+func (s *Source) scanTar(ctx context.Context, r io.Reader, chunksChan chan *sources.Chunk, metadata func(file string) *source_metadatapb.Docker) error {
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil // End of archive
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
 
-				select {
-				case chunksChan <- chunk:
-				case <-ctx.Done():
-					return ctx.Err()
-				}
-			}
+		// Skip files larger than FilesizeLimitBytes
+		if header.Size > FilesizeLimitBytes {
+			sources.ReportSkip(ctx, header.Name, sources.SkipReasonSize)
+			continue
+		}
 
-			dockerLayersScanned.WithLabelValues(s.name).Inc()
+		file := bytes.NewBuffer(make([]byte, 0, header.Size))
+
+		_, err = io.Copy(file, tarReader)
+		if err != nil {
+			return err
 		}
 
-		dockerImagesScanned.WithLabelValues(s.name).Inc()
+		// Prepend /, because the file is relative to the root of the filesystem.
+		if err := s.emit(ctx, chunksChan, file.Bytes(), metadata("/"+strings.TrimPrefix(header.Name, "/"))); err != nil {
+			return err
+		}
 	}
+}
 
-	return nil
+// emit sends a chunk of data with Docker metadata.
+func (s *Source) emit(ctx context.Context, chunksChan chan *sources.Chunk, data []byte, metadata *source_metadatapb.Docker) error {
+	chunk := &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		Data:       data,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Docker{Docker: metadata},
+		},
+		Verify: s.verify,
+	}
+
+	select {
+	case chunksChan <- chunk:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func baseAndTagFromImage(image string) (base, tag string, hasDigest bool) {
@@ -23,4 +23,12 @@ var (
 		Help:      "Total number of Docker images scanned.",
 	},
 		[]string{"source_name"})
+
+	dockerContainersScanned = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: common.MetricsNamespace,
+		Subsystem: common.MetricsSubsystem,
+		Name:      "docker_containers_scanned",
+		Help:      "Total number of Docker containers scanned.",
+	},
+		[]string{"source_name"})
 )
@@ -0,0 +1,536 @@
+package dropbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultEndpoint        = "https://api.dropboxapi.com"
+	defaultContentEndpoint = "https://content.dropboxapi.com"
+	// webURL is the URL of the files in the web app of Dropbox.
+	webURL = "https://www.dropbox.com/home"
+
+	defaultMaxObjectSize = 250 * 1024 * 1024 // 250 MiB
+	maxObjectSizeLimit   = 250 * 1024 * 1024 // 250 MiB
+
+	namespaceTeamFolder   = "team_folder"
+	namespaceMemberFolder = "team_member_folder"
+)
+
+// Source scans the team folders and the home folders of the members of a
+// Dropbox team, through the team API.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	client          *http.Client
+	endpoint        string
+	contentEndpoint string
+	conn            *sourcespb.Dropbox
+	maxObjectSize   int64
+	// adminID is the team member ID of the admin of the token, which the
+	// requests of team folders act as.
+	adminID string
+	// state is read from and saved to the state path of the connection.
+	stateMu sync.Mutex
+	state   state
+	// archiveOptions configures how the archives of files are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// state is what a scan saves for the next one to only scan the files that
+// changed since.
+type state struct {
+	// Cursors are the cursors of the files of namespaces, by namespace ID.
+	Cursors map[string]string `json:"cursors"`
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_DROPBOX
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of files are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized Dropbox source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Dropbox
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+	s.endpoint = strings.TrimSuffix(conn.GetEndpoint(), "/")
+	if s.endpoint == "" {
+		s.endpoint = defaultEndpoint
+	}
+	s.contentEndpoint = strings.TrimSuffix(conn.GetContentEndpoint(), "/")
+	if s.contentEndpoint == "" {
+		s.contentEndpoint = defaultContentEndpoint
+	}
+	s.maxObjectSize = conn.GetMaxObjectSize()
+	if s.maxObjectSize <= 0 || s.maxObjectSize > maxObjectSizeLimit {
+		s.maxObjectSize = defaultMaxObjectSize
+	}
+
+	// Tokens are requested with the retrying client, and authorize the
+	// requests of another.
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, common.RetryableHttpClientTimeout(120))
+	var tokens oauth2.TokenSource
+	switch cred := conn.Credential.(type) {
+	case *sourcespb.Dropbox_Token:
+		tokens = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cred.Token})
+	case *sourcespb.Dropbox_Oauth:
+		token := &oauth2.Token{AccessToken: cred.Oauth.GetAccessToken(), RefreshToken: cred.Oauth.GetRefreshToken()}
+		tokens = oauth2.StaticTokenSource(token)
+		if token.RefreshToken != "" {
+			config := oauth2.Config{
+				ClientID:     cred.Oauth.GetClientId(),
+				ClientSecret: cred.Oauth.GetClientSecret(),
+				Endpoint:     oauth2.Endpoint{TokenURL: s.endpoint + "/oauth2/token"},
+			}
+			tokens = config.TokenSource(tokenCtx, token)
+		}
+	default:
+		return errors.New("credentials are required")
+	}
+	s.client = &http.Client{
+		Timeout:   120 * time.Second,
+		Transport: &oauth2.Transport{Source: oauth2.ReuseTokenSource(nil, tokens), Base: common.RetryableHttpClientTimeout(120).Transport},
+	}
+
+	s.state = state{Cursors: map[string]string{}}
+	return nil
+}
+
+type namespace struct {
+	ID   string `json:"namespace_id"`
+	Name string `json:"name"`
+	Type struct {
+		Tag string `json:".tag"`
+	} `json:"namespace_type"`
+	// MemberID is the team member ID of the member of a member folder.
+	MemberID string `json:"team_member_id"`
+	// email is the email of the member of a member folder.
+	email string
+}
+
+type entry struct {
+	Tag            string `json:".tag"`
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	PathDisplay    string `json:"path_display"`
+	Size           int64  `json:"size"`
+	ServerModified string `json:"server_modified"`
+	IsDownloadable *bool  `json:"is_downloadable"`
+	ExportInfo     *struct {
+		ExportAs string `json:"export_as"`
+	} `json:"export_info"`
+}
+
+// Chunks emits the files of the team folders and of the home folders of the
+// members that changed since the last scan as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	if err := s.loadState(); err != nil {
+		return err
+	}
+
+	var admin struct {
+		Profile struct {
+			MemberID string `json:"team_member_id"`
+		} `json:"admin_profile"`
+	}
+	if err := s.rpc(ctx, namespace{}, "/2/team/token/get_authenticated_admin", nil, &admin); err != nil {
+		return fmt.Errorf("error getting the admin of the token: %w", err)
+	}
+	s.adminID = admin.Profile.MemberID
+
+	namespaces, err := s.namespaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, ns := range namespaces {
+		if common.IsDone(ctx) {
+			break
+		}
+		ns := ns
+		s.SetProgressComplete(i, len(namespaces), fmt.Sprintf("Namespace: %s", ns.Name), "")
+		s.jobPool.Go(func() error {
+			if err := s.scanNamespace(ctx, ns, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error scanning namespace %s: %w", ns.Name, err))
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return s.saveState()
+}
+
+// namespaces returns the team folders and the home folders of the members
+// to scan.
+func (s *Source) namespaces(ctx context.Context) ([]namespace, error) {
+	var emails map[string]string
+	if !s.conn.GetSkipMemberFolders() {
+		var err error
+		if emails, err = s.members(ctx); err != nil {
+			return nil, fmt.Errorf("error listing members: %w", err)
+		}
+	}
+	include := make(map[string]bool)
+	for _, email := range s.conn.GetMembers() {
+		include[strings.ToLower(email)] = true
+	}
+
+	var namespaces []namespace
+	endpoint, args := "/2/team/namespaces/list", map[string]any{"limit": 1000}
+	for {
+		var page struct {
+			Namespaces []namespace `json:"namespaces"`
+			Cursor     string      `json:"cursor"`
+			HasMore    bool        `json:"has_more"`
+		}
+		if err := s.rpc(ctx, namespace{}, endpoint, args, &page); err != nil {
+			return nil, fmt.Errorf("error listing namespaces: %w", err)
+		}
+		for _, ns := range page.Namespaces {
+			switch ns.Type.Tag {
+			case namespaceTeamFolder:
+				if s.conn.GetSkipTeamFolders() {
+					continue
+				}
+			case namespaceMemberFolder:
+				ns.email = emails[ns.MemberID]
+				if s.conn.GetSkipMemberFolders() || len(include) > 0 && !include[strings.ToLower(ns.email)] {
+					continue
+				}
+			default:
+				// Shared folders are scanned in the folders of their
+				// members, and app folders in those of their apps' members.
+				continue
+			}
+			namespaces = append(namespaces, ns)
+		}
+		if !page.HasMore {
+			return namespaces, nil
+		}
+		endpoint, args = "/2/team/namespaces/list/continue", map[string]any{"cursor": page.Cursor}
+	}
+}
+
+// members returns the emails of the members of the team by team member ID.
+func (s *Source) members(ctx context.Context) (map[string]string, error) {
+	emails := make(map[string]string)
+	endpoint, args := "/2/team/members/list_v2", map[string]any{"limit": 1000}
+	for {
+		var page struct {
+			Members []struct {
+				Profile struct {
+					MemberID string `json:"team_member_id"`
+					Email    string `json:"email"`
+				} `json:"profile"`
+			} `json:"members"`
+			Cursor  string `json:"cursor"`
+			HasMore bool   `json:"has_more"`
+		}
+		if err := s.rpc(ctx, namespace{}, endpoint, args, &page); err != nil {
+			return nil, err
+		}
+		for _, m := range page.Members {
+			emails[m.Profile.MemberID] = m.Profile.Email
+		}
+		if !page.HasMore {
+			return emails, nil
+		}
+		endpoint, args = "/2/team/members/list/continue_v2", map[string]any{"cursor": page.Cursor}
+	}
+}
+
+// scanNamespace scans the files of a namespace changed since the last scan.
+func (s *Source) scanNamespace(ctx context.Context, ns namespace, chunksChan chan *sources.Chunk) error {
+	s.stateMu.Lock()
+	cursor, incremental := s.state.Cursors[ns.ID]
+	s.stateMu.Unlock()
+
+	endpoint, args := "/2/files/list_folder", map[string]any{"path": "", "recursive": true, "limit": 2000}
+	if incremental {
+		endpoint, args = "/2/files/list_folder/continue", map[string]any{"cursor": cursor}
+	}
+	for {
+		var page struct {
+			Entries []entry `json:"entries"`
+			Cursor  string  `json:"cursor"`
+			HasMore bool    `json:"has_more"`
+		}
+		if err := s.rpc(ctx, ns, endpoint, args, &page); err != nil {
+			return err
+		}
+		for _, e := range page.Entries {
+			if common.IsDone(ctx) {
+				return ctx.Err()
+			}
+			if e.Tag != "file" {
+				continue
+			}
+			if err := s.scanFile(ctx, ns, e, chunksChan); err != nil {
+				ctx.Logger().Error(err, "could not scan file", "namespace", ns.Name, "file", e.PathDisplay)
+				sources.ReportSkip(ctx, e.PathDisplay, sources.SkipReasonError)
+			}
+		}
+		cursor = page.Cursor
+		if !page.HasMore {
+			break
+		}
+		endpoint, args = "/2/files/list_folder/continue", map[string]any{"cursor": cursor}
+	}
+
+	s.stateMu.Lock()
+	s.state.Cursors[ns.ID] = cursor
+	s.stateMu.Unlock()
+	return nil
+}
+
+// scanFile downloads a file, or exports it if it is a Paper doc or another
+// file that can't be downloaded, and scans it through the handlers of
+// archives.
+func (s *Source) scanFile(ctx context.Context, ns namespace, e entry, chunksChan chan *sources.Chunk) error {
+	endpoint, arg := "/2/files/download", map[string]any{"path": e.ID}
+	if e.IsDownloadable != nil && !*e.IsDownloadable {
+		if e.ExportInfo == nil {
+			sources.ReportSkipBytes(ctx, e.PathDisplay, sources.SkipReasonUnsupported, e.Size)
+			return nil
+		}
+		endpoint = "/2/files/export"
+		if strings.HasSuffix(e.Name, ".paper") {
+			arg["export_format"] = "markdown"
+		}
+	} else {
+		switch {
+		case e.Size > s.maxObjectSize:
+			sources.ReportSkipBytes(ctx, e.PathDisplay, sources.SkipReasonSize, e.Size)
+			return nil
+		case e.Size == 0:
+			sources.ReportSkip(ctx, e.PathDisplay, sources.SkipReasonEmpty)
+			return nil
+		case common.SkipFile(e.Name):
+			sources.ReportSkipBytes(ctx, e.PathDisplay, sources.SkipReasonUnsupported, e.Size)
+			return nil
+		}
+	}
+
+	body, err := s.content(ctx, ns, endpoint, arg)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	reader, err := diskbufferreader.New(io.LimitReader(body, s.maxObjectSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	skel := s.chunkSkeleton(ns, e)
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+
+	chunkReader := sources.NewChunkReader(s.archiveOptions.ChunkOptions()...)
+	for data := range chunkReader(ctx, reader) {
+		if err := data.Error(); err != nil {
+			return err
+		}
+		chunk := *skel
+		chunk.Data = data.Bytes()
+		chunk.Offset, chunk.LineStart, chunk.LineOffset = data.Offset(), data.LineStart(), data.LineOffset()
+		if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Source) chunkSkeleton(ns namespace, e entry) *sources.Chunk {
+	// Team folders are at the root of the team space, and the paths of the
+	// files of member folders are from the home of the member.
+	link := webURL + e.PathDisplay
+	if ns.Type.Tag == namespaceTeamFolder {
+		link = webURL + "/" + ns.Name + e.PathDisplay
+	}
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Dropbox{
+				Dropbox: &source_metadatapb.Dropbox{
+					File:      sanitizer.UTF8(e.PathDisplay),
+					Link:      sanitizer.UTF8(link),
+					Email:     ns.email,
+					Timestamp: e.ServerModified,
+					Namespace: sanitizer.UTF8(ns.Name),
+					Id:        e.ID,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// loadState reads the state of the last scan, if there was one.
+func (s *Source) loadState() error {
+	if err := sources.LoadState(s.conn.GetStatePath(), &s.state); err != nil {
+		return err
+	}
+	if s.state.Cursors == nil {
+		s.state.Cursors = map[string]string{}
+	}
+	return nil
+}
+
+// saveState saves the state of the scan for the next one. The namespaces
+// that failed keep the state of the last scan.
+func (s *Source) saveState() error {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return sources.SaveState(s.conn.GetStatePath(), s.state)
+}
+
+// rpc calls an endpoint of the API with the JSON of args, or none if it is
+// nil, and decodes its JSON response. The requests of namespaces act in
+// them as their member, or as the admin of the token for team folders.
+func (s *Source) rpc(ctx context.Context, ns namespace, endpoint string, args, v any) error {
+	var body io.Reader
+	if args != nil {
+		data, err := json.Marshal(args)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+endpoint, body)
+	if err != nil {
+		return err
+	}
+	if args != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := s.do(req, ns)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// content returns the content of a file from a content endpoint of the API.
+func (s *Source) content(ctx context.Context, ns namespace, endpoint string, arg any) (io.ReadCloser, error) {
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.contentEndpoint+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Dropbox-API-Arg", string(data))
+	res, err := s.do(req, ns)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// do sends a request in a namespace, and returns its response if it
+// succeeded.
+func (s *Source) do(req *http.Request, ns namespace) (*http.Response, error) {
+	if ns.ID != "" {
+		root, err := json.Marshal(map[string]string{".tag": "namespace_id", "namespace_id": ns.ID})
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Dropbox-API-Path-Root", string(root))
+		if ns.MemberID != "" {
+			req.Header.Set("Dropbox-API-Select-User", ns.MemberID)
+		} else {
+			req.Header.Set("Dropbox-API-Select-Admin", s.adminID)
+		}
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusOK {
+		return res, nil
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+	case http.StatusConflict:
+		// Errors of endpoints are described by a summary.
+		var apiErr struct {
+			Summary string `json:"error_summary"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&apiErr)
+		return nil, fmt.Errorf("error calling %s: %s", req.URL.Path, apiErr.Summary)
+	}
+	return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+}
@@ -0,0 +1,191 @@
+package dropbox
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// fakeTeam serves the API of a team of an admin, whose token is token, with
+// a team folder of a file, and the home folders of two members with a Paper
+// doc and a file. The cursors of the folders list a change of the file of
+// the team folder.
+func fakeTeam(t *testing.T) *httptest.Server {
+	t.Helper()
+	files := map[string][]any{
+		"ns-team": {
+			map[string]any{".tag": "folder", "id": "id:dir", "path_display": "/ops"},
+			map[string]any{".tag": "file", "id": "id:env", "name": "prod.env", "path_display": "/ops/prod.env", "size": 13, "server_modified": "2024-03-01T10:00:00Z"},
+		},
+		"ns-ada": {
+			map[string]any{".tag": "file", "id": "id:paper", "name": "Notes.paper", "path_display": "/Notes.paper", "is_downloadable": false, "export_info": map[string]any{"export_as": "html"}},
+			map[string]any{".tag": "file", "id": "id:empty", "name": "empty.txt", "path_display": "/empty.txt", "size": 0},
+		},
+		"ns-bob": {
+			map[string]any{".tag": "file", "id": "id:bob", "name": "bob.txt", "path_display": "/bob.txt", "size": 3},
+		},
+	}
+	contents := map[string]string{"id:env": "TOKEN=secret\n", "id:paper": "# Notes\npassword: hunter2", "id:bob": "bob"}
+
+	mux := http.NewServeMux()
+	handle := func(path string, v func(r *http.Request, args map[string]any) any) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			args := map[string]any{}
+			if arg := r.Header.Get("Dropbox-API-Arg"); arg != "" {
+				require.NoError(t, json.Unmarshal([]byte(arg), &args))
+			} else if r.ContentLength > 0 {
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&args))
+			}
+			switch body := v(r, args).(type) {
+			case string:
+				_, _ = w.Write([]byte(body))
+			default:
+				_ = json.NewEncoder(w).Encode(body)
+			}
+		})
+	}
+	// namespaceOf returns the namespace of a request, after checking who it
+	// acts as.
+	namespaceOf := func(r *http.Request) string {
+		var root map[string]string
+		require.NoError(t, json.Unmarshal([]byte(r.Header.Get("Dropbox-API-Path-Root")), &root))
+		ns := root["namespace_id"]
+		if ns == "ns-team" {
+			assert.Equal(t, "dbmid:admin", r.Header.Get("Dropbox-API-Select-Admin"))
+		} else {
+			assert.Equal(t, "dbmid:"+ns[3:], r.Header.Get("Dropbox-API-Select-User"))
+		}
+		return ns
+	}
+
+	handle("/2/team/token/get_authenticated_admin", func(*http.Request, map[string]any) any {
+		return map[string]any{"admin_profile": map[string]any{"team_member_id": "dbmid:admin"}}
+	})
+	handle("/2/team/members/list_v2", func(*http.Request, map[string]any) any {
+		return map[string]any{"has_more": true, "cursor": "m2", "members": []any{
+			map[string]any{"profile": map[string]any{"team_member_id": "dbmid:ada", "email": "ada@example.com"}},
+		}}
+	})
+	handle("/2/team/members/list/continue_v2", func(_ *http.Request, args map[string]any) any {
+		assert.Equal(t, "m2", args["cursor"])
+		return map[string]any{"members": []any{
+			map[string]any{"profile": map[string]any{"team_member_id": "dbmid:bob", "email": "bob@example.com"}},
+		}}
+	})
+	handle("/2/team/namespaces/list", func(*http.Request, map[string]any) any {
+		return map[string]any{"namespaces": []any{
+			map[string]any{"namespace_id": "ns-team", "name": "Engineering", "namespace_type": map[string]any{".tag": "team_folder"}},
+			map[string]any{"namespace_id": "ns-ada", "name": "Ada", "namespace_type": map[string]any{".tag": "team_member_folder"}, "team_member_id": "dbmid:ada"},
+			map[string]any{"namespace_id": "ns-bob", "name": "Bob", "namespace_type": map[string]any{".tag": "team_member_folder"}, "team_member_id": "dbmid:bob"},
+			map[string]any{"namespace_id": "ns-shared", "name": "Shared", "namespace_type": map[string]any{".tag": "shared_folder"}},
+		}}
+	})
+	handle("/2/files/list_folder", func(r *http.Request, args map[string]any) any {
+		assert.Equal(t, true, args["recursive"])
+		ns := namespaceOf(r)
+		return map[string]any{"entries": files[ns], "cursor": "c-" + ns}
+	})
+	handle("/2/files/list_folder/continue", func(r *http.Request, args map[string]any) any {
+		ns := namespaceOf(r)
+		assert.Equal(t, "c-"+ns, args["cursor"])
+		entries := []any{}
+		if ns == "ns-team" {
+			entries = append(entries, files[ns][1], map[string]any{".tag": "deleted", "path_display": "/old.env"})
+		}
+		return map[string]any{"entries": entries, "cursor": "c-" + ns}
+	})
+	handle("/2/files/download", func(r *http.Request, args map[string]any) any {
+		namespaceOf(r)
+		return contents[args["path"].(string)]
+	})
+	handle("/2/files/export", func(r *http.Request, args map[string]any) any {
+		namespaceOf(r)
+		assert.Equal(t, "markdown", args["export_format"])
+		return contents[args["path"].(string)]
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// chunks returns the data of the chunks of a source by file, and their
+// metadata.
+func chunks(t *testing.T, conn *sourcespb.Dropbox) (map[string]string, map[string]*source_metadatapb.Dropbox) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.Dropbox)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetDropbox()
+		got[m.GetFile()] += string(chunk.Data)
+		metadata[m.GetFile()] = m
+	}
+	return got, metadata
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server := fakeTeam(t)
+	conn := &sourcespb.Dropbox{
+		Endpoint:        server.URL,
+		ContentEndpoint: server.URL,
+		Credential:      &sourcespb.Dropbox_Token{Token: "token"},
+		StatePath:       filepath.Join(t.TempDir(), "state.json"),
+	}
+
+	got, metadata := chunks(t, conn)
+	assert.Equal(t, map[string]string{
+		"/ops/prod.env": "TOKEN=secret\n",
+		"/Notes.paper":  "# Notes\npassword: hunter2",
+		"/bob.txt":      "bob",
+	}, got)
+	assert.Equal(t, &source_metadatapb.Dropbox{
+		File:      "/ops/prod.env",
+		Link:      "https://www.dropbox.com/home/Engineering/ops/prod.env",
+		Timestamp: "2024-03-01T10:00:00Z",
+		Namespace: "Engineering",
+		Id:        "id:env",
+	}, metadata["/ops/prod.env"])
+	assert.Equal(t, "ada@example.com", metadata["/Notes.paper"].GetEmail())
+	assert.Equal(t, "https://www.dropbox.com/home/Notes.paper", metadata["/Notes.paper"].GetLink())
+
+	// The next scan only scans the files that changed since the first.
+	got, _ = chunks(t, conn)
+	assert.Equal(t, map[string]string{"/ops/prod.env": "TOKEN=secret\n"}, got)
+}
+
+func TestSource_ChunksMembers(t *testing.T) {
+	server := fakeTeam(t)
+	got, _ := chunks(t, &sourcespb.Dropbox{
+		Endpoint:        server.URL,
+		ContentEndpoint: server.URL,
+		Credential:      &sourcespb.Dropbox_Token{Token: "token"},
+		Members:         []string{"BOB@example.com"},
+		SkipTeamFolders: true,
+	})
+	assert.Equal(t, map[string]string{"/bob.txt": "bob"}, got)
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := fakeTeam(t)
+	conn, err := anypb.New(&sourcespb.Dropbox{Endpoint: server.URL, Credential: &sourcespb.Dropbox_Token{Token: "expired"}})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "invalid credentials")
+}
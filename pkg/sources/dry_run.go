@@ -0,0 +1,56 @@
+package sources
+
+import (
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// Target is something a source would scan, like a repository or a bucket. It
+// is reported instead of scanning in dry runs.
+type Target struct {
+	SourceName string
+	SourceType sourcespb.SourceType
+	// Name identifies the target, e.g. a repository URL or bucket name.
+	Name string
+	// Objects is the number of objects in the target, like files or commits,
+	// or -1 if it is unknown.
+	Objects int64
+	// Bytes is the estimated size of the target, or -1 if it is unknown.
+	Bytes int64
+}
+
+// TargetReporter receives the targets of sources in dry runs.
+// Implementations must be safe for concurrent use.
+type TargetReporter interface {
+	ReportTarget(Target)
+}
+
+// DryRunner is implemented by sources that can list what they would scan
+// without scanning it.
+type DryRunner interface {
+	// EnumerateTargets calls report for each target the source would scan.
+	// The source name and type of the targets are filled in by the caller.
+	EnumerateTargets(ctx context.Context, report func(Target)) error
+}
+
+// WithDryRun makes the manager report the targets of the sources it runs to
+// reporter instead of scanning them. Sources that are not DryRunners are
+// reported as a single target of unknown size.
+func WithDryRun(reporter TargetReporter) func(*SourceManager) {
+	return func(mgr *SourceManager) { mgr.dryRun = reporter }
+}
+
+// runDryRun reports the targets of the source.
+func (s *SourceManager) runDryRun(ctx context.Context, name string, source Source) error {
+	report := func(t Target) {
+		t.SourceName, t.SourceType = name, source.Type()
+		s.dryRun.ReportTarget(t)
+	}
+	runner, ok := source.(DryRunner)
+	if !ok {
+		ctx.Logger().V(2).Info("source can't enumerate its targets")
+		report(Target{Name: name, Objects: -1, Bytes: -1})
+		return nil
+	}
+	return runner.EnumerateTargets(ctx, report)
+}
@@ -0,0 +1,363 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultTimestampField = "@timestamp"
+
+	// scrollKeepAlive is how long the search contexts of scrolls are kept
+	// between their pages.
+	scrollKeepAlive = "5m"
+)
+
+// pageSize is the number of documents of the pages of scrolls.
+var pageSize = 500
+
+// errForbidden and errNotFound are returned for the indices that the
+// credentials are not allowed to read, or that were deleted since they were
+// listed, which are skipped.
+var (
+	errForbidden = errors.New("forbidden")
+	errNotFound  = errors.New("not found")
+)
+
+// Source scans the documents of the indices of an Elasticsearch or
+// OpenSearch cluster, through scroll queries.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn   *sourcespb.Elasticsearch
+	url    string
+	client *http.Client
+}
+
+// hit is a document of the results of a search.
+type hit struct {
+	Index  string          `json:"_index"`
+	ID     string          `json:"_id"`
+	Source json.RawMessage `json:"_source"`
+}
+
+type searchResponse struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []hit `json:"hits"`
+	} `json:"hits"`
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_ELASTICSEARCH
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized Elasticsearch source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Elasticsearch
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	if conn.GetUrl() == "" {
+		return errors.New("a URL is required")
+	}
+	u, err := url.Parse(conn.GetUrl())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid URL %q", conn.GetUrl())
+	}
+	s.url = strings.TrimSuffix(conn.GetUrl(), "/")
+
+	tlsConfig := common.TLSClientConfig()
+	tlsConfig.InsecureSkipVerify = conn.GetInsecure()
+	if ca := conn.GetCaCertificate(); ca != "" {
+		if err := common.AddRootCAs(tlsConfig, []byte(ca)); err != nil {
+			return fmt.Errorf("invalid CA certificate: %w", err)
+		}
+	}
+	s.client = common.RetryableHttpClientTLS(120, tlsConfig)
+	return nil
+}
+
+// Chunks emits the _source of the documents of the indices as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	indices, err := s.indices(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, index := range indices {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(indices), fmt.Sprintf("Index: %s", index), "")
+		index := index
+		s.jobPool.Go(func() error {
+			err := s.scanIndex(ctx, index, chunksChan)
+			switch {
+			case errors.Is(err, errForbidden), errors.Is(err, errNotFound):
+				ctx.Logger().V(2).Info("could not read index", "index", index, "error", err)
+				sources.ReportSkip(ctx, s.url+"/"+index, sources.SkipReasonError)
+			case err != nil:
+				scanErrs.Add(fmt.Errorf("error scanning index %s: %w", index, err))
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// EnumerateTargets reports each index with the number of the documents that
+// would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	indices, err := s.indices(ctx)
+	if err != nil {
+		return err
+	}
+	for _, index := range indices {
+		var resp struct {
+			Count int64 `json:"count"`
+		}
+		err := s.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_count", nil, map[string]any{"query": s.query()}, &resp)
+		if err != nil && !errors.Is(err, errForbidden) && !errors.Is(err, errNotFound) {
+			return err
+		}
+		report(sources.Target{Name: s.url + "/" + index, Objects: resp.Count})
+	}
+	return nil
+}
+
+// indices returns the names of the open indices of the patterns of the
+// connection, or else of all the open indices but the system ones. The
+// patterns also match the hidden backing indices of data streams.
+func (s *Source) indices(ctx context.Context) ([]string, error) {
+	patterns := strings.Join(s.conn.GetIndices(), ",")
+	all := patterns == ""
+	if all {
+		patterns = "*"
+	}
+	var rows []struct {
+		Index string `json:"index"`
+	}
+	query := url.Values{"format": {"json"}, "h": {"index"}, "s": {"index"}, "expand_wildcards": {"open,hidden"}}
+	err := s.do(ctx, http.MethodGet, "/_cat/indices/"+url.PathEscape(patterns), query, nil, &rows)
+	if errors.Is(err, errNotFound) {
+		// Names that match no index are not found.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing indices: %w", err)
+	}
+	indices := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if all && strings.HasPrefix(row.Index, ".") && !strings.HasPrefix(row.Index, ".ds-") {
+			continue
+		}
+		indices = append(indices, row.Index)
+	}
+	return indices, nil
+}
+
+// query returns the query of the documents to scan: those of the time range
+// of the connection, or all of them.
+func (s *Source) query() map[string]any {
+	if s.conn.GetSince() == "" && s.conn.GetUntil() == "" {
+		return map[string]any{"match_all": map[string]any{}}
+	}
+	field := s.conn.GetTimestampField()
+	if field == "" {
+		field = defaultTimestampField
+	}
+	timeRange := make(map[string]string)
+	if since := s.conn.GetSince(); since != "" {
+		timeRange["gte"] = since
+	}
+	if until := s.conn.GetUntil(); until != "" {
+		timeRange["lt"] = until
+	}
+	return map[string]any{"range": map[string]any{field: timeRange}}
+}
+
+// scanIndex scrolls through the documents of an index.
+func (s *Source) scanIndex(ctx context.Context, index string, chunksChan chan *sources.Chunk) error {
+	search := map[string]any{
+		"size":  pageSize,
+		"query": s.query(),
+		// Scrolls are fastest in the order of the index.
+		"sort": []string{"_doc"},
+	}
+	if fields := s.conn.GetIncludeFields(); len(fields) > 0 {
+		search["_source"] = map[string]any{"includes": fields}
+	}
+	var resp searchResponse
+	err := s.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", url.Values{"scroll": {scrollKeepAlive}}, search, &resp)
+	if err != nil {
+		return err
+	}
+	defer func() { s.clearScroll(ctx, resp.ScrollID) }()
+
+	for len(resp.Hits.Hits) > 0 {
+		for _, h := range resp.Hits.Hits {
+			if err := s.scanHit(ctx, h, chunksChan); err != nil {
+				return err
+			}
+		}
+		if common.IsDone(ctx) {
+			return ctx.Err()
+		}
+		scroll := map[string]string{"scroll": scrollKeepAlive, "scroll_id": resp.ScrollID}
+		resp = searchResponse{ScrollID: resp.ScrollID}
+		if err := s.do(ctx, http.MethodPost, "/_search/scroll", nil, scroll, &resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearScroll frees the search context of a scroll, which would otherwise
+// be kept until it expires.
+func (s *Source) clearScroll(ctx context.Context, scrollID string) {
+	if scrollID == "" {
+		return
+	}
+	// The scroll is cleared even if the scan was cancelled.
+	clearCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.do(clearCtx, http.MethodDelete, "/_search/scroll", nil, map[string]any{"scroll_id": []string{scrollID}}, nil); err != nil {
+		ctx.Logger().V(2).Info("could not clear scroll", "error", err)
+	}
+}
+
+func (s *Source) scanHit(ctx context.Context, h hit, chunksChan chan *sources.Chunk) error {
+	// Documents of indices without _source, or whose fields are all
+	// filtered out, have nothing to scan.
+	if len(h.Source) == 0 || string(h.Source) == "{}" {
+		return nil
+	}
+	chunk := &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		Data:       h.Source,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Elasticsearch{
+				Elasticsearch: &source_metadatapb.Elasticsearch{
+					Index:      h.Index,
+					DocumentId: sanitizer.UTF8(h.ID),
+					Link:       sanitizer.UTF8(s.url + "/" + url.PathEscape(h.Index) + "/_doc/" + url.PathEscape(h.ID)),
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+	return common.CancellableWrite(ctx, chunksChan, chunk)
+}
+
+// do sends a request to the API, and decodes its response into out unless
+// it is nil.
+func (s *Source) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	u := s.url + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case s.conn.GetApiKey() != "":
+		req.Header.Set("Authorization", "ApiKey "+s.conn.GetApiKey())
+	case s.conn.GetUsername() != "":
+		req.SetBasicAuth(s.conn.GetUsername(), s.conn.GetPassword())
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if out == nil {
+			return nil
+		}
+		return json.NewDecoder(resp.Body).Decode(out)
+	case http.StatusUnauthorized:
+		return fmt.Errorf("invalid credentials, status %d", resp.StatusCode)
+	case http.StatusForbidden:
+		return fmt.Errorf("%w: %s", errForbidden, path)
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", errNotFound, path)
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d for %s: %s", resp.StatusCode, path, bytes.TrimSpace(msg))
+	}
+}
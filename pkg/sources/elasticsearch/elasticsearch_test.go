@@ -0,0 +1,191 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// testCluster is a cluster of indices of documents, which records the
+// searches and the scrolls that are cleared.
+type testCluster struct {
+	mu       sync.Mutex
+	searches []map[string]any
+	cleared  []string
+}
+
+var testIndices = map[string][]string{
+	"logs-2024":               {`{"msg":"login","password":"hunter2"}`, `{"msg":"logout"}`, `{"msg":"token AKIA"}`},
+	"users":                   {`{"name":"ada","api_key":"xyz"}`},
+	".ds-logs-app-2024.01.01": {`{"msg":"from a data stream"}`},
+	".security-7":             {`{"hash":"secret"}`},
+	"forbidden":               {`{"x":"y"}`},
+}
+
+func (c *testCluster) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "ApiKey a2V5" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/_cat/indices/"):
+			assert.Equal(t, "open,hidden", r.URL.Query().Get("expand_wildcards"))
+			var rows []map[string]string
+			for _, pattern := range strings.Split(strings.TrimPrefix(r.URL.Path, "/_cat/indices/"), ",") {
+				for _, index := range []string{".ds-logs-app-2024.01.01", ".security-7", "forbidden", "logs-2024", "users"} {
+					if pattern == "*" || pattern == index || (strings.HasSuffix(pattern, "*") && strings.HasPrefix(index, strings.TrimSuffix(pattern, "*"))) {
+						rows = append(rows, map[string]string{"index": index})
+					}
+				}
+			}
+			_ = json.NewEncoder(w).Encode(rows)
+		case r.Method == http.MethodDelete && r.URL.Path == "/_search/scroll":
+			c.mu.Lock()
+			c.cleared = append(c.cleared, body["scroll_id"].([]any)[0].(string))
+			c.mu.Unlock()
+		case r.URL.Path == "/_search/scroll":
+			// Scroll IDs are the index and the offset of the next page.
+			index, offset, _ := strings.Cut(body["scroll_id"].(string), ":")
+			from, _ := strconv.Atoi(offset)
+			_ = json.NewEncoder(w).Encode(page(index, from))
+		case strings.HasSuffix(r.URL.Path, "/_search"):
+			index := strings.Trim(strings.TrimSuffix(r.URL.Path, "/_search"), "/")
+			if index == "forbidden" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			assert.Equal(t, "5m", r.URL.Query().Get("scroll"))
+			c.mu.Lock()
+			c.searches = append(c.searches, body)
+			c.mu.Unlock()
+			_ = json.NewEncoder(w).Encode(page(index, 0))
+		case strings.HasSuffix(r.URL.Path, "/_count"):
+			index := strings.Trim(strings.TrimSuffix(r.URL.Path, "/_count"), "/")
+			_ = json.NewEncoder(w).Encode(map[string]int{"count": len(testIndices[index])})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// page returns the page of the documents of an index from an offset, of
+// pageSize documents.
+func page(index string, from int) map[string]any {
+	var hits []map[string]any
+	docs := testIndices[index]
+	for i := from; i < len(docs) && i < from+pageSize; i++ {
+		hits = append(hits, map[string]any{"_index": index, "_id": fmt.Sprint(i), "_source": json.RawMessage(docs[i])})
+	}
+	return map[string]any{
+		"_scroll_id": fmt.Sprintf("%s:%d", index, from+pageSize),
+		"hits":       map[string]any{"hits": hits},
+	}
+}
+
+// chunks returns the data of the chunks of a source by link, and their
+// metadata.
+func chunks(t *testing.T, s *Source) (map[string]string, map[string]*source_metadatapb.Elasticsearch) {
+	t.Helper()
+	return sources.ChunksByKey(sources.RequireChunks(t, s), (*source_metadatapb.MetaData).GetElasticsearch, func(m *source_metadatapb.Elasticsearch) string {
+		return m.GetIndex() + "/" + m.GetDocumentId()
+	})
+}
+
+func TestSource_Chunks(t *testing.T) {
+	defer func(size int) { pageSize = size }(pageSize)
+	pageSize = 2
+
+	cluster := &testCluster{}
+	server := cluster.server(t)
+	got, metadata := chunks(t, sources.InitTestSource(t, &Source{}, &sourcespb.Elasticsearch{Url: server.URL + "/", ApiKey: "a2V5"}, 2))
+	assert.Equal(t, map[string]string{
+		"logs-2024/0":               `{"msg":"login","password":"hunter2"}`,
+		"logs-2024/1":               `{"msg":"logout"}`,
+		"logs-2024/2":               `{"msg":"token AKIA"}`,
+		"users/0":                   `{"name":"ada","api_key":"xyz"}`,
+		".ds-logs-app-2024.01.01/0": `{"msg":"from a data stream"}`,
+	}, got)
+	assert.Equal(t, &source_metadatapb.Elasticsearch{
+		Index:      "users",
+		DocumentId: "0",
+		Link:       server.URL + "/users/_doc/0",
+	}, metadata["users/0"])
+
+	// The scrolls of the indices that could be read are cleared.
+	assert.ElementsMatch(t, []string{"logs-2024:6", "users:4", ".ds-logs-app-2024.01.01:4"}, cluster.cleared)
+	for _, search := range cluster.searches {
+		assert.Equal(t, map[string]any{"match_all": map[string]any{}}, search["query"])
+		assert.Nil(t, search["_source"])
+	}
+}
+
+func TestSource_ChunksFilters(t *testing.T) {
+	cluster := &testCluster{}
+	server := cluster.server(t)
+	got, _ := chunks(t, sources.InitTestSource(t, &Source{}, &sourcespb.Elasticsearch{
+		Url:           server.URL,
+		ApiKey:        "a2V5",
+		Indices:       []string{"logs-*", ".security-7"},
+		IncludeFields: []string{"msg", "user.*"},
+		Since:         "now-7d",
+		Until:         "2024-02-01",
+	}, 2))
+	assert.Len(t, got, 4)
+	assert.Contains(t, got, ".security-7/0")
+
+	require.Len(t, cluster.searches, 2)
+	assert.Equal(t, map[string]any{"range": map[string]any{"@timestamp": map[string]any{"gte": "now-7d", "lt": "2024-02-01"}}}, cluster.searches[0]["query"])
+	assert.Equal(t, map[string]any{"includes": []any{"msg", "user.*"}}, cluster.searches[0]["_source"])
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := (&testCluster{}).server(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Elasticsearch{Url: server.URL, Username: "elastic", Password: "wrong"}, 2)
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	assert.ErrorContains(t, err, "invalid credentials")
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	server := (&testCluster{}).server(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Elasticsearch{Url: server.URL, ApiKey: "a2V5", Indices: []string{"logs-2024", "users"}}, 2)
+	objects := make(map[string]int64)
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		objects[target.Name] = target.Objects
+	}))
+	assert.Equal(t, map[string]int64{server.URL + "/logs-2024": 3, server.URL + "/users": 1}, objects)
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.Elasticsearch{
+		"no url":     {},
+		"no scheme":  {Url: "localhost:9200"},
+		"invalid ca": {Url: "https://localhost:9200", CaCertificate: "not a certificate"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
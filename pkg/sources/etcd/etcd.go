@@ -0,0 +1,332 @@
+package etcd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const defaultEndpoint = "http://127.0.0.1:2379"
+
+// pageSize is the number of keys of the pages of ranges. Values are at most
+// 1.5 MiB by default.
+var pageSize int64 = 100
+
+// Source scans the values of the keys of an etcd cluster, through the JSON
+// gateway of its v3 API.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn     *sourcespb.Etcd
+	endpoint string
+	client   *http.Client
+	exclude  sources.Globs
+
+	// tokenMu guards token, the token of the user of the connection, which
+	// expires when it is not used.
+	tokenMu sync.Mutex
+	token   string
+}
+
+// keyValue is a key and its value. Keys and values are decoded from the
+// base64 of the gateway, and its int64s are strings.
+type keyValue struct {
+	Key         []byte `json:"key"`
+	Value       []byte `json:"value"`
+	ModRevision int64  `json:"mod_revision,string"`
+}
+
+// rangeRequest reads the keys of [Key, RangeEnd).
+type rangeRequest struct {
+	Key       []byte `json:"key"`
+	RangeEnd  []byte `json:"range_end"`
+	Limit     int64  `json:"limit,omitempty"`
+	Revision  int64  `json:"revision,omitempty,string"`
+	CountOnly bool   `json:"count_only,omitempty"`
+}
+
+type rangeResponse struct {
+	Header struct {
+		Revision int64 `json:"revision,string"`
+	} `json:"header"`
+	Kvs   []keyValue `json:"kvs"`
+	More  bool       `json:"more"`
+	Count int64      `json:"count,string"`
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_ETCD
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized etcd source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, _ int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+
+	var conn sourcespb.Etcd
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	s.endpoint = strings.TrimSuffix(conn.GetEndpoint(), "/")
+	if s.endpoint == "" {
+		s.endpoint = defaultEndpoint
+	}
+	// Endpoints without a scheme are those of etcdctl, like 127.0.0.1:2379.
+	if !strings.Contains(s.endpoint, "://") {
+		s.endpoint = "http://" + s.endpoint
+	}
+	if _, err := url.Parse(s.endpoint); err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	tlsConfig := common.TLSClientConfig()
+	tlsConfig.InsecureSkipVerify = conn.GetInsecure()
+	if ca := conn.GetCaCertificate(); ca != "" {
+		if err := common.AddRootCAs(tlsConfig, []byte(ca)); err != nil {
+			return fmt.Errorf("invalid CA certificate: %w", err)
+		}
+	}
+	if cert := conn.GetClientCertificate(); cert != "" {
+		pair, err := tls.X509KeyPair([]byte(cert), []byte(conn.GetClientKey()))
+		if err != nil {
+			return fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+	s.client = common.RetryableHttpClientTLS(120, tlsConfig)
+
+	exclude, err := sources.CompileGlobs(conn.GetExcludeGlobs(), '/')
+	if err != nil {
+		return err
+	}
+	s.exclude = exclude
+	return nil
+}
+
+// Chunks emits the values of the keys of the prefix as chunks. The keys are
+// read by pages at the revision of the first page, so that they are a
+// consistent snapshot.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	count, err := s.count(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := rangeRequest{Key: s.start(), RangeEnd: s.end(), Limit: pageSize}
+	for scanned := int64(0); ; {
+		if common.IsDone(ctx) {
+			return nil
+		}
+		s.SetProgressComplete(int(scanned), int(count), fmt.Sprintf("Keys: %d of %d", scanned, count), "")
+		var resp rangeResponse
+		if err := s.post(ctx, "/v3/kv/range", req, &resp); err != nil {
+			return fmt.Errorf("error reading keys: %w", err)
+		}
+		for _, kv := range resp.Kvs {
+			if err := s.scanKeyValue(ctx, kv, chunksChan); err != nil {
+				return err
+			}
+		}
+		scanned += int64(len(resp.Kvs))
+		if !resp.More || len(resp.Kvs) == 0 {
+			return nil
+		}
+		// The next page starts right after the last key of this one.
+		req.Key = append(resp.Kvs[len(resp.Kvs)-1].Key, 0)
+		if req.Revision == 0 {
+			req.Revision = resp.Header.Revision
+		}
+	}
+}
+
+// EnumerateTargets reports the prefix with the number of its keys.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	count, err := s.count(ctx)
+	if err != nil {
+		return err
+	}
+	report(sources.Target{Name: s.endpoint + "/" + s.conn.GetPrefix(), Objects: count})
+	return nil
+}
+
+// count returns the number of the keys of the prefix.
+func (s *Source) count(ctx context.Context) (int64, error) {
+	var resp rangeResponse
+	if err := s.post(ctx, "/v3/kv/range", rangeRequest{Key: s.start(), RangeEnd: s.end(), CountOnly: true}, &resp); err != nil {
+		return 0, fmt.Errorf("error counting keys: %w", err)
+	}
+	return resp.Count, nil
+}
+
+// start returns the first key of the prefix, which is the zero byte for all
+// the keys.
+func (s *Source) start() []byte {
+	if s.conn.GetPrefix() == "" {
+		return []byte{0}
+	}
+	return []byte(s.conn.GetPrefix())
+}
+
+// end returns the end of the range of the keys of the prefix, like
+// clientv3.GetPrefixRangeEnd: the prefix up to its last byte below 0xff,
+// which is incremented, or the zero byte for all the keys.
+func (s *Source) end() []byte {
+	end := []byte(s.conn.GetPrefix())
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{0}
+}
+
+func (s *Source) scanKeyValue(ctx context.Context, kv keyValue, chunksChan chan *sources.Chunk) error {
+	key := string(kv.Key)
+	if len(kv.Value) == 0 {
+		return nil
+	}
+	if s.exclude.Match(key) {
+		sources.ReportSkipBytes(ctx, key, sources.SkipReasonFiltered, int64(len(kv.Value)))
+		return nil
+	}
+	chunk := &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		Data:       kv.Value,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Etcd{
+				Etcd: &source_metadatapb.Etcd{
+					Key:         sanitizer.UTF8(key),
+					Endpoint:    s.endpoint,
+					ModRevision: kv.ModRevision,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+	return common.CancellableWrite(ctx, chunksChan, chunk)
+}
+
+// authenticate returns the token of the user of the connection, which is
+// requested again if renew is set.
+func (s *Source) authenticate(ctx context.Context, renew bool) (string, error) {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+	if s.token != "" && !renew {
+		return s.token, nil
+	}
+	req := map[string]string{"name": s.conn.GetUsername(), "password": s.conn.GetPassword()}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := s.do(ctx, "/v3/auth/authenticate", "", req, &resp); err != nil {
+		return "", fmt.Errorf("error authenticating as %s: %w", s.conn.GetUsername(), err)
+	}
+	s.token = resp.Token
+	return s.token, nil
+}
+
+// post decodes the response of a request to the API, authenticated as the
+// user of the connection if it has one. Requests whose token expired are
+// sent again with a new one.
+func (s *Source) post(ctx context.Context, path string, req, out any) error {
+	if s.conn.GetUsername() == "" {
+		return s.do(ctx, path, "", req, out)
+	}
+	token, err := s.authenticate(ctx, false)
+	if err != nil {
+		return err
+	}
+	err = s.do(ctx, path, token, req, out)
+	if !errors.Is(err, errUnauthenticated) {
+		return err
+	}
+	if token, err = s.authenticate(ctx, true); err != nil {
+		return err
+	}
+	return s.do(ctx, path, token, req, out)
+}
+
+var errUnauthenticated = errors.New("unauthenticated")
+
+func (s *Source) do(ctx context.Context, path, token string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusOK {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	var status struct {
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&status)
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: %s", errUnauthenticated, status.Message)
+	case http.StatusForbidden:
+		return fmt.Errorf("permission denied: %s", status.Message)
+	default:
+		return fmt.Errorf("unexpected status %d for %s: %s", resp.StatusCode, path, status.Message)
+	}
+}
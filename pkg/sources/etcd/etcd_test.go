@@ -0,0 +1,133 @@
+package etcd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// testServer returns the JSON gateway of a member with keys, whose user is
+// root, and whose first token expires.
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	store := map[string]string{
+		"/config/app/db_password": "hunter2",
+		"/config/app/user":        "admin",
+		"/config/empty":           "",
+		"/registry/x":             "AKIA",
+		"/other":                  "value",
+	}
+	var keys []string
+	for k := range store {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tokens := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/auth/authenticate":
+			var req map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			if req["name"] != "root" || req["password"] != "pw" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":3,"message":"etcdserver: authentication failed, invalid user ID or password"}`))
+				return
+			}
+			tokens++
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": fmt.Sprintf("token%d", tokens)})
+		case "/v3/kv/range":
+			// The first token expires.
+			if r.Header.Get("Authorization") != "token2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"code":16,"message":"etcdserver: invalid auth token"}`))
+				return
+			}
+			var req rangeRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			var kvs []map[string]any
+			more := false
+			for _, k := range keys {
+				if bytes.Compare([]byte(k), req.Key) < 0 || (!bytes.Equal(req.RangeEnd, []byte{0}) && bytes.Compare([]byte(k), req.RangeEnd) >= 0) {
+					continue
+				}
+				if req.Limit > 0 && int64(len(kvs)) == req.Limit {
+					more = true
+					break
+				}
+				kvs = append(kvs, map[string]any{"key": []byte(k), "value": []byte(store[k]), "mod_revision": "7"})
+			}
+			resp := map[string]any{"header": map[string]string{"revision": "9"}, "count": "3", "more": more}
+			if !req.CountOnly {
+				resp["kvs"] = kvs
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server := testServer(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Etcd{
+		Endpoint:     server.URL,
+		Username:     "root",
+		Password:     "pw",
+		ExcludeGlobs: []string{"/registry/**"},
+	}, 1)
+	got, metadata := sources.ChunksByKey(sources.RequireChunks(t, s), (*source_metadatapb.MetaData).GetEtcd, (*source_metadatapb.Etcd).GetKey)
+	assert.Equal(t, map[string]string{
+		"/config/app/db_password": "hunter2",
+		"/config/app/user":        "admin",
+		"/other":                  "value",
+	}, got)
+	assert.Equal(t, &source_metadatapb.Etcd{Key: "/other", Endpoint: server.URL, ModRevision: 7}, metadata["/other"])
+}
+
+func TestSource_ChunksPages(t *testing.T) {
+	defer func(size int64) { pageSize = size }(pageSize)
+	pageSize = 1
+
+	server := testServer(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Etcd{Endpoint: server.URL, Username: "root", Password: "pw", Prefix: "/config/"}, 1)
+	var keys []string
+	for _, chunk := range sources.RequireChunks(t, s) {
+		keys = append(keys, chunk.SourceMetadata.GetEtcd().GetKey())
+	}
+	assert.Equal(t, []string{"/config/app/db_password", "/config/app/user"}, keys)
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := testServer(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Etcd{Endpoint: server.URL, Username: "root", Password: "wrong"}, 1)
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	assert.ErrorContains(t, err, "authentication failed")
+}
+
+func TestSource_End(t *testing.T) {
+	for prefix, want := range map[string][]byte{
+		"":           {0},
+		"/config/":   []byte("/config0"),
+		"a\xff":      []byte("b"),
+		"\xff\xff":   {0},
+		"/registry/": []byte("/registry0"),
+	} {
+		s := &Source{conn: &sourcespb.Etcd{Prefix: prefix}}
+		assert.Equal(t, want, s.end(), prefix)
+	}
+}
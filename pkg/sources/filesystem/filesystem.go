@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/fs"
@@ -13,9 +14,11 @@ import (
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/browser"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignore"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
@@ -30,6 +33,13 @@ type Source struct {
 	paths    []string
 	log      logr.Logger
 	filter   *common.Filter
+	// skipIgnoreFile disables honoring .trufflehogignore files in scanned
+	// directories.
+	skipIgnoreFile bool
+	// browserStorage decodes the LevelDB and SQLite files of browser
+	// profiles before scanning them.
+	browserStorage bool
+	archiveOptions sources.ArchiveOptions
 	sources.Progress
 	sources.CommonSourceUnitUnmarshaller
 }
@@ -76,6 +86,24 @@ func (s *Source) WithFilter(filter *common.Filter) {
 	s.filter = filter
 }
 
+// WithSkipIgnoreFile disables honoring .trufflehogignore files in scanned
+// directories.
+func (s *Source) WithSkipIgnoreFile(skip bool) {
+	s.skipIgnoreFile = skip
+}
+
+// WithBrowserStorage decodes the LevelDB and SQLite files that browsers keep
+// Local Storage, cookies and extension storage in, so their values are
+// scanned as text.
+func (s *Source) WithBrowserStorage(decode bool) {
+	s.browserStorage = decode
+}
+
+// WithArchiveOptions sets how the archives scanned are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
 	for i, path := range s.paths {
@@ -95,7 +123,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 		if fileInfo.IsDir() {
 			err = s.scanDir(ctx, cleanPath, chunksChan)
 		} else {
-			err = s.scanFile(ctx, cleanPath, chunksChan)
+			err = s.scanFile(ctx, cleanPath, nil, chunksChan)
 		}
 
 		if err != nil && err != io.EOF {
@@ -106,11 +134,47 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 }
 
 func (s *Source) scanDir(ctx context.Context, path string, chunksChan chan *sources.Chunk) error {
+	return s.walkDir(ctx, path, func(fullPath string, _ fs.FileInfo, rules *ignore.Rules) {
+		var err error
+		if parts := splitParts(fullPath); len(parts) > 1 {
+			if parts[0] != fullPath {
+				// The parts after the first are scanned with it.
+				return
+			}
+			err = s.scanSplitFile(ctx, parts, rules, chunksChan)
+		} else {
+			err = s.scanFile(ctx, fullPath, rules, chunksChan)
+		}
+		if err != nil {
+			ctx.Logger().Info("error scanning file", "path", fullPath, "error", err)
+			sources.ReportSkip(ctx, fullPath, sources.SkipReasonError)
+		}
+	})
+}
+
+// walkDir calls fn for each regular file below path that is not excluded by
+// the filter or the ignore file.
+func (s *Source) walkDir(ctx context.Context, path string, fn func(fullPath string, info fs.FileInfo, rules *ignore.Rules)) error {
+	var rules *ignore.Rules
+	if !s.skipIgnoreFile {
+		var err error
+		if rules, err = ignore.Load(path); err != nil {
+			ctx.Logger().Error(err, "could not load ignore file, scanning without it", "path", path)
+		}
+	}
+
 	return fs.WalkDir(os.DirFS(path), ".", func(relativePath string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
 		fullPath := filepath.Join(path, relativePath)
+		if relativePath != "." && rules.IgnoresPath(relativePath, d.IsDir()) {
+			sources.ReportSkip(ctx, fullPath, sources.SkipReasonFiltered)
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
 
 		// Skip over non-regular files. We do this check here to suppress noisy
 		// logs for trying to scan directories and other non-regular files in
@@ -124,17 +188,44 @@ func (s *Source) scanDir(ctx context.Context, path string, chunksChan chan *sour
 			return nil
 		}
 		if s.filter != nil && !s.filter.Pass(fullPath) {
+			sources.ReportSkip(ctx, fullPath, sources.SkipReasonFiltered)
 			return nil
 		}
 
-		if err = s.scanFile(ctx, fullPath, chunksChan); err != nil {
-			ctx.Logger().Info("error scanning file", "path", fullPath, "error", err)
-		}
+		fn(fullPath, fileStat, rules)
 		return nil
 	})
 }
 
-func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sources.Chunk) error {
+// EnumerateTargets reports each path with the number and size of the files
+// that would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	for _, path := range s.paths {
+		cleanPath := filepath.Clean(path)
+		fileInfo, err := os.Stat(cleanPath)
+		if err != nil {
+			ctx.Logger().Error(err, "unable to get file info", "path", path)
+			continue
+		}
+		target := sources.Target{Name: cleanPath}
+		if !fileInfo.IsDir() {
+			target.Objects, target.Bytes = 1, fileInfo.Size()
+			report(target)
+			continue
+		}
+		err = s.walkDir(ctx, cleanPath, func(_ string, info fs.FileInfo, _ *ignore.Rules) {
+			target.Objects++
+			target.Bytes += info.Size()
+		})
+		if err != nil {
+			return err
+		}
+		report(target)
+	}
+	return nil
+}
+
+func (s *Source) scanFile(ctx context.Context, path string, rules *ignore.Rules, chunksChan chan *sources.Chunk) error {
 	logger := ctx.Logger().WithValues("path", path)
 	fileStat, err := os.Stat(path)
 	if err != nil {
@@ -144,6 +235,17 @@ func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sou
 		return fmt.Errorf("not a regular file")
 	}
 
+	if s.browserStorage {
+		data, ok, err := browser.Decode(path)
+		if err != nil {
+			logger.Info("could not decode browser storage, scanning it as is", "error", err)
+		}
+		if ok && err == nil {
+			logger.V(3).Info("scanning decoded browser storage")
+			return s.scanReader(ctx, bytes.NewReader(data), path, rules, chunksChan)
+		}
+	}
+
 	inputFile, err := os.Open(path)
 	if err != nil {
 		return fmt.Errorf("unable to open file: %w", err)
@@ -157,7 +259,20 @@ func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sou
 	}
 	defer reReader.Close()
 
-	chunkSkel := &sources.Chunk{
+	if handlers.HandleFile(ctx, reReader, s.chunkSkeleton(path, rules), chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+
+	if err := reReader.Reset(); err != nil {
+		return err
+	}
+	reReader.Stop()
+	return s.scanReader(ctx, reReader, path, rules, chunksChan)
+}
+
+// chunkSkeleton returns the skeleton of the chunks of a file handled.
+func (s *Source) chunkSkeleton(path string, rules *ignore.Rules) *sources.Chunk {
+	return &sources.Chunk{
 		SourceType: s.Type(),
 		SourceName: s.name,
 		SourceID:   s.SourceID(),
@@ -165,22 +280,78 @@ func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sou
 			Data: &source_metadatapb.MetaData_Filesystem{
 				Filesystem: &source_metadatapb.Filesystem{
 					File: sanitizer.UTF8(path),
+					Line: 1,
 				},
 			},
 		},
-		Verify: s.verify,
+		Verify:      s.verify,
+		IgnoreRules: rules,
 	}
-	if handlers.HandleFile(ctx, reReader, chunkSkel, chunksChan) {
-		return nil
+}
+
+// splitParts returns the paths of the parts of the split file a file is a
+// part of, in order, from the files of its directory named as its parts, e.g.
+// backup.zip.001 and backup.zip.002, or only the file if it is not one of
+// them.
+func splitParts(path string) []string {
+	if _, _, ok := handlers.SplitPart(filepath.Base(path)); !ok {
+		return []string{path}
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return []string{path}
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	for _, parts := range handlers.SplitFiles(names) {
+		for _, part := range parts {
+			if part != filepath.Base(path) {
+				continue
+			}
+			paths := make([]string, len(parts))
+			for i, name := range parts {
+				paths[i] = filepath.Join(filepath.Dir(path), name)
+			}
+			return paths
+		}
 	}
+	return []string{path}
+}
 
-	if err := reReader.Reset(); err != nil {
-		return err
+// scanSplitFile scans the parts of a split file, like the volumes of a
+// multi-volume archive, as the file they are parts of, under the path of its
+// first part. Parts that are not parts of a file that is handled are
+// scanned one by one.
+func (s *Source) scanSplitFile(ctx context.Context, paths []string, rules *ignore.Rules, chunksChan chan *sources.Chunk) error {
+	ctx.Logger().V(3).Info("scanning split file", "parts", paths)
+	readers := make([]io.Reader, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("unable to open file: %w", err)
+		}
+		defer f.Close()
+		readers = append(readers, f)
 	}
-	reReader.Stop()
+	if handlers.HandleSplitFile(ctx, readers, s.chunkSkeleton(paths[0], rules), chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	for _, path := range paths {
+		if err := s.scanFile(ctx, path, rules, chunksChan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	chunkReader := sources.NewChunkReader()
-	chunkResChan := chunkReader(ctx, reReader)
+// scanReader sends the contents of a file in chunks.
+func (s *Source) scanReader(ctx context.Context, reader io.Reader, path string, rules *ignore.Rules, chunksChan chan *sources.Chunk) error {
+	chunkReader := sources.NewChunkReader(s.archiveOptions.ChunkOptions()...)
+	chunkResChan := chunkReader(ctx, reader)
 	for data := range chunkResChan {
 		if err := data.Error(); err != nil {
 			s.log.Error(err, "error reading chunk.")
@@ -192,14 +363,19 @@ func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sou
 			SourceName: s.name,
 			SourceID:   s.SourceID(),
 			Data:       data.Bytes(),
+			Offset:     data.Offset(),
+			LineStart:  data.LineStart(),
+			LineOffset: data.LineOffset(),
 			SourceMetadata: &source_metadatapb.MetaData{
 				Data: &source_metadatapb.MetaData_Filesystem{
 					Filesystem: &source_metadatapb.Filesystem{
 						File: sanitizer.UTF8(path),
+						Line: 1,
 					},
 				},
 			},
-			Verify: s.verify,
+			Verify:      s.verify,
+			IgnoreRules: rules,
 		}
 		if err := common.CancellableWrite(ctx, chunksChan, chunk); err != nil {
 			return err
@@ -243,7 +419,7 @@ func (s *Source) ChunkUnit(ctx context.Context, unit sources.SourceUnit, reporte
 		} else {
 			// TODO: Finer grain error tracking of individual
 			// chunks (in the case of archives).
-			scanErr = s.scanFile(ctx, cleanPath, ch)
+			scanErr = s.scanFile(ctx, cleanPath, nil, ch)
 		}
 	}()
 
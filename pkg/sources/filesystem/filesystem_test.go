@@ -1,7 +1,10 @@
 package filesystem
 
 import (
+	"archive/zip"
+	"bytes"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -9,6 +12,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/kylelemons/godebug/pretty"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
@@ -45,6 +49,7 @@ func TestSource_Scan(t *testing.T) {
 				Data: &source_metadatapb.MetaData_Filesystem{
 					Filesystem: &source_metadatapb.Filesystem{
 						File: "filesystem.go",
+						Line: 1,
 					},
 				},
 			},
@@ -108,7 +113,7 @@ func TestScanFile(t *testing.T) {
 	ctx := context.WithLogger(context.Background(), logr.Discard())
 	go func() {
 		defer close(chunksChan)
-		err = source.scanFile(ctx, tmpfile.Name(), chunksChan)
+		err = source.scanFile(ctx, tmpfile.Name(), nil, chunksChan)
 		assert.Nil(t, err)
 	}()
 
@@ -120,3 +125,55 @@ func TestScanFile(t *testing.T) {
 
 	assert.Contains(t, foundSecret, secretPart1+secretPart2)
 }
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "vendor"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("12345"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("123"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "vendor", "c.txt"), []byte("1234567"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".trufflehogignore"), []byte("vendor/\n"), 0644))
+
+	source := &Source{paths: []string{dir, filepath.Join(dir, "a.txt")}}
+	var targets []sources.Target
+	err := source.EnumerateTargets(context.Background(), func(target sources.Target) {
+		targets = append(targets, target)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []sources.Target{
+		{Name: dir, Objects: 3, Bytes: 5 + 3 + int64(len("vendor/\n"))},
+		{Name: filepath.Join(dir, "a.txt"), Objects: 1, Bytes: 5},
+	}, targets)
+}
+
+func TestScanDir_SplitFile(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("config/app.env")
+	require.NoError(t, err)
+	_, err = f.Write([]byte("token = split\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	archive := buf.Bytes()
+
+	dir := t.TempDir()
+	half := len(archive) / 2
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "backup.zip.001"), archive[:half], 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "backup.zip.002"), archive[half:], 0644))
+
+	source := &Source{}
+	chunksChan := make(chan *sources.Chunk, 16)
+	ctx := context.WithLogger(context.Background(), logr.Discard())
+	require.NoError(t, source.scanDir(ctx, dir, chunksChan))
+	close(chunksChan)
+
+	// The parts are scanned once, as the archive they are parts of.
+	var chunks []*sources.Chunk
+	for chunk := range chunksChan {
+		chunks = append(chunks, chunk)
+	}
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "token = split\n", string(chunks[0].Data))
+	assert.Equal(t, filepath.Join(dir, "backup.zip.001"), chunks[0].SourceMetadata.GetFilesystem().GetFile())
+	assert.Equal(t, "config/app.env", chunks[0].SourceMetadata.GetFilesystem().GetArchivePath())
+}
@@ -0,0 +1,554 @@
+package ftp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	goerrors "github.com/go-errors/errors"
+	"github.com/jlaffaye/ftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultMaxObjectSize = 250 * 1024 * 1024 // 250 MiB
+	maxObjectSizeLimit   = 250 * 1024 * 1024 // 250 MiB
+
+	defaultMaxConnections = 4
+	dialTimeout           = 30 * time.Second
+)
+
+// Source scans the files of a directory of an SFTP, FTP or FTPS server.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	// url is the server, without credentials, and root is the directory
+	// scanned, or the working directory if it is empty.
+	url  *url.URL
+	root string
+	// dial opens a connection to the server.
+	dial          func() (remote, error)
+	include       sources.Globs
+	exclude       sources.Globs
+	maxObjectSize int64
+	// sem limits the number of connections to the server, and idle are the
+	// connections that are not in use.
+	sem    chan struct{}
+	idleMu sync.Mutex
+	idle   []remote
+	// archiveOptions configures how the archives of files are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// remote is a connection to a server, which is used by one goroutine at a
+// time.
+type remote interface {
+	// readDir returns the files of a directory.
+	readDir(dir string) ([]remoteFile, error)
+	// open returns the content of a file, which must be closed before the
+	// connection is used again.
+	open(name string) (io.ReadCloser, error)
+	// workDir returns the directory the connection starts in.
+	workDir() (string, error)
+	close() error
+}
+
+type remoteFile struct {
+	path     string
+	size     int64
+	mode     os.FileMode
+	modified time.Time
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_FTP
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of files are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized FTP source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+
+	var conn sourcespb.FTP
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return goerrors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+
+	u, err := url.Parse(conn.GetUrl())
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	username, password := conn.GetUsername(), conn.GetPassword()
+	if username == "" {
+		username = u.User.Username()
+	}
+	if urlPassword, ok := u.User.Password(); ok && password == "" {
+		password = urlPassword
+	}
+	s.url = &url.URL{Scheme: u.Scheme, Host: u.Host}
+	s.root = u.Path
+
+	switch u.Scheme {
+	case "sftp":
+		config, err := sshConfig(&conn, username, password)
+		if err != nil {
+			return err
+		}
+		addr := hostPort(u, "22")
+		s.dial = func() (remote, error) { return dialSFTP(addr, config) }
+	case "ftp", "ftps":
+		if username == "" {
+			username, password = "anonymous", "anonymous"
+		}
+		addr := hostPort(u, "21")
+		options := []ftp.DialOption{ftp.DialWithTimeout(dialTimeout)}
+		if u.Scheme == "ftps" {
+			tlsConfig := common.TLSClientConfig()
+			tlsConfig.ServerName = u.Hostname()
+			tlsConfig.InsecureSkipVerify = conn.GetInsecure()
+			options = append(options, ftp.DialWithExplicitTLS(tlsConfig))
+		} else {
+			// The client does not wrap the data connections it opens with a
+			// dial function in TLS, so only plain FTP goes through proxies.
+			options = append(options, ftp.DialWithDialFunc(dialTCP))
+		}
+		s.dial = func() (remote, error) { return dialFTP(addr, username, password, options) }
+	default:
+		return fmt.Errorf("unsupported url scheme %q, expected sftp, ftp or ftps", u.Scheme)
+	}
+
+	if s.include, err = sources.CompileGlobs(conn.GetIncludeGlobs(), '/'); err != nil {
+		return err
+	}
+	if s.exclude, err = sources.CompileGlobs(conn.GetExcludeGlobs(), '/'); err != nil {
+		return err
+	}
+
+	s.maxObjectSize = conn.GetMaxObjectSize()
+	if s.maxObjectSize <= 0 || s.maxObjectSize > maxObjectSizeLimit {
+		s.maxObjectSize = defaultMaxObjectSize
+	}
+	maxConnections := int(conn.GetMaxConnections())
+	if maxConnections <= 0 {
+		maxConnections = defaultMaxConnections
+	}
+	s.sem = make(chan struct{}, maxConnections)
+	// Each file scanned holds a connection.
+	if concurrency > maxConnections {
+		concurrency = maxConnections
+	}
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+	return nil
+}
+
+// sshConfig returns the configuration of the SSH connections to a server,
+// which log in with a private key, a password or both.
+func sshConfig(conn *sourcespb.FTP, username, password string) (*ssh.ClientConfig, error) {
+	config := &ssh.ClientConfig{User: username, Timeout: dialTimeout}
+	if key := conn.GetPrivateKey(); key != "" {
+		var signer ssh.Signer
+		var err error
+		if passphrase := conn.GetPrivateKeyPassphrase(); passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(key))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		config.Auth = append(config.Auth, ssh.PublicKeys(signer))
+	}
+	if password != "" {
+		config.Auth = append(config.Auth, ssh.Password(password))
+	}
+	if len(config.Auth) == 0 {
+		return nil, errors.New("a password or a private key is required")
+	}
+
+	if conn.GetInsecure() {
+		config.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		return config, nil
+	}
+	knownHostsPath := conn.GetKnownHosts()
+	if knownHostsPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("error finding known hosts: %w", err)
+		}
+		knownHostsPath = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(knownHostsPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading known hosts: %w", err)
+	}
+	config.HostKeyCallback = callback
+	return config, nil
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
+
+// Chunks emits the files of the directory and of its subdirectories as
+// chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	defer s.closeIdle()
+	files, err := s.files(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, f := range files {
+		if common.IsDone(ctx) {
+			break
+		}
+		f := f
+		s.SetProgressComplete(i, len(files), fmt.Sprintf("File: %s", f.path), "")
+		s.jobPool.Go(func() error {
+			if err := s.scanFile(ctx, f, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error scanning file %s: %w", f.path, err))
+				sources.ReportSkipBytes(ctx, f.path, sources.SkipReasonError, f.size)
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// EnumerateTargets reports the directory with the number and size of the
+// files that would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	defer s.closeIdle()
+	files, err := s.files(ctx)
+	if err != nil {
+		return err
+	}
+	target := sources.Target{Name: s.link(s.root)}
+	for _, f := range files {
+		target.Objects++
+		target.Bytes += f.size
+	}
+	report(target)
+	return nil
+}
+
+// files returns the regular files below the directory to scan that the
+// globs don't filter out. Directories that can't be read are skipped, except
+// the directory to scan.
+func (s *Source) files(ctx context.Context) ([]remoteFile, error) {
+	if s.root == "" {
+		c, err := s.acquire(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to %s: %w", s.url.Host, err)
+		}
+		s.root, err = c.workDir()
+		s.release(c, err)
+		if err != nil {
+			return nil, fmt.Errorf("error getting the working directory: %w", err)
+		}
+	}
+
+	var files []remoteFile
+	dirs := []string{s.root}
+	for len(dirs) > 0 {
+		if common.IsDone(ctx) {
+			return nil, ctx.Err()
+		}
+		dir := dirs[len(dirs)-1]
+		dirs = dirs[:len(dirs)-1]
+
+		c, err := s.acquire(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to %s: %w", s.url.Host, err)
+		}
+		entries, err := c.readDir(dir)
+		s.release(c, err)
+		if err != nil {
+			if dir == s.root {
+				return nil, fmt.Errorf("error listing %s: %w", dir, err)
+			}
+			ctx.Logger().Error(err, "could not list directory", "dir", dir)
+			sources.ReportSkip(ctx, dir, sources.SkipReasonError)
+			continue
+		}
+
+		for _, f := range entries {
+			if s.exclude.Match(f.path) {
+				sources.ReportSkipBytes(ctx, f.path, sources.SkipReasonFiltered, f.size)
+				continue
+			}
+			// Links are not followed, as they could loop.
+			switch {
+			case f.mode.IsDir():
+				dirs = append(dirs, f.path)
+			case !f.mode.IsRegular():
+			case len(s.include) > 0 && !s.include.Match(f.path):
+				sources.ReportSkipBytes(ctx, f.path, sources.SkipReasonFiltered, f.size)
+			default:
+				files = append(files, f)
+			}
+		}
+	}
+	return files, nil
+}
+
+// scanFile reads a file on a connection of its own and scans it through the
+// handlers of archives.
+func (s *Source) scanFile(ctx context.Context, f remoteFile, chunksChan chan *sources.Chunk) error {
+	switch {
+	case f.size > s.maxObjectSize:
+		sources.ReportSkipBytes(ctx, f.path, sources.SkipReasonSize, f.size)
+		return nil
+	case f.size == 0:
+		sources.ReportSkip(ctx, f.path, sources.SkipReasonEmpty)
+		return nil
+	case common.SkipFile(f.path):
+		sources.ReportSkipBytes(ctx, f.path, sources.SkipReasonUnsupported, f.size)
+		return nil
+	}
+
+	c, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := c.open(f.path)
+	if err != nil {
+		s.release(c, err)
+		return err
+	}
+	err = s.scanReader(ctx, f, body, chunksChan)
+	if closeErr := body.Close(); err == nil {
+		err = closeErr
+	}
+	s.release(c, err)
+	return err
+}
+
+func (s *Source) scanReader(ctx context.Context, f remoteFile, body io.Reader, chunksChan chan *sources.Chunk) error {
+	reader, err := diskbufferreader.New(io.LimitReader(body, s.maxObjectSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	skel := s.chunkSkeleton(f)
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+
+	chunkReader := sources.NewChunkReader(s.archiveOptions.ChunkOptions()...)
+	for data := range chunkReader(ctx, reader) {
+		if err := data.Error(); err != nil {
+			return err
+		}
+		chunk := *skel
+		chunk.Data = data.Bytes()
+		chunk.Offset, chunk.LineStart, chunk.LineOffset = data.Offset(), data.LineStart(), data.LineOffset()
+		if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Source) chunkSkeleton(f remoteFile) *sources.Chunk {
+	var timestamp string
+	if !f.modified.IsZero() {
+		timestamp = f.modified.UTC().Format(time.RFC3339)
+	}
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Ftp{
+				Ftp: &source_metadatapb.FTP{
+					File:      sanitizer.UTF8(f.path),
+					Link:      sanitizer.UTF8(s.link(f.path)),
+					Host:      s.url.Host,
+					Timestamp: timestamp,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// link returns the URL of a path of the server.
+func (s *Source) link(p string) string {
+	u := *s.url
+	u.Path = p
+	return u.String()
+}
+
+// acquire returns an idle connection, or a new one if there is none, once
+// fewer than the maximum number of connections are in use.
+func (s *Source) acquire(ctx context.Context) (remote, error) {
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	s.idleMu.Lock()
+	if n := len(s.idle); n > 0 {
+		c := s.idle[n-1]
+		s.idle = s.idle[:n-1]
+		s.idleMu.Unlock()
+		return c, nil
+	}
+	s.idleMu.Unlock()
+
+	c, err := s.dial()
+	if err != nil {
+		<-s.sem
+		return nil, err
+	}
+	return c, nil
+}
+
+// release returns a connection after it was used, which is closed instead
+// if the error of its use is not one of the server.
+func (s *Source) release(c remote, err error) {
+	defer func() { <-s.sem }()
+	var sftpErr *sftpError
+	var ftpErr *textproto.Error
+	if err != nil && !errors.As(err, &sftpErr) && !errors.As(err, &ftpErr) {
+		_ = c.close()
+		return
+	}
+	s.idleMu.Lock()
+	s.idle = append(s.idle, c)
+	s.idleMu.Unlock()
+}
+
+func (s *Source) closeIdle() {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+	for _, c := range s.idle {
+		_ = c.close()
+	}
+	s.idle = nil
+}
+
+// ftpClient is a connection to an FTP server.
+type ftpClient struct {
+	conn *ftp.ServerConn
+}
+
+var _ remote = (*ftpClient)(nil)
+
+// dialTCP connects to the address through the configured proxy.
+func dialTCP(network, addr string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	return common.DialContext(ctx, network, addr)
+}
+
+func dialFTP(addr, username, password string, options []ftp.DialOption) (*ftpClient, error) {
+	conn, err := ftp.Dial(addr, options...)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Login(username, password); err != nil {
+		_ = conn.Quit()
+		return nil, fmt.Errorf("error logging in: %w", err)
+	}
+	return &ftpClient{conn: conn}, nil
+}
+
+func (c *ftpClient) readDir(dir string) ([]remoteFile, error) {
+	entries, err := c.conn.List(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]remoteFile, 0, len(entries))
+	for _, e := range entries {
+		if e.Name == "." || e.Name == ".." {
+			continue
+		}
+		f := remoteFile{path: path.Join(dir, e.Name), size: int64(e.Size), modified: e.Time}
+		switch e.Type {
+		case ftp.EntryTypeFolder:
+			f.mode = os.ModeDir
+		case ftp.EntryTypeLink:
+			f.mode = os.ModeSymlink
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func (c *ftpClient) open(name string) (io.ReadCloser, error) {
+	return c.conn.Retr(name)
+}
+
+func (c *ftpClient) workDir() (string, error) {
+	return c.conn.CurrentDir()
+}
+
+func (c *ftpClient) close() error {
+	return c.conn.Quit()
+}
@@ -0,0 +1,419 @@
+package ftp
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// testFiles are the files of the servers, below the directory /home/ada
+// that connections start in.
+var testFiles = map[string]string{
+	"/home/ada/.env":               "TOKEN=secret\n",
+	"/home/ada/app/config.yml":     "password: hunter2",
+	"/home/ada/app/node_modules/x": "module",
+	"/home/ada/empty.txt":          "",
+}
+
+var testModified = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// list returns the files and directories of a directory of testFiles, and
+// whether it exists. The working directory also has a link to itself.
+func list(dir string) (files []remoteFile, ok bool) {
+	seen := make(map[string]bool)
+	for name, content := range testFiles {
+		rel, found := strings.CutPrefix(name, dir+"/")
+		if !found {
+			continue
+		}
+		ok = true
+		child, _, isDir := strings.Cut(rel, "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		f := remoteFile{path: path.Join(dir, child), size: int64(len(content)), modified: testModified}
+		if isDir {
+			f.mode, f.size = os.ModeDir, 0
+		}
+		files = append(files, f)
+	}
+	if dir == "/home/ada" {
+		files = append(files, remoteFile{path: "/home/ada/loop", mode: os.ModeSymlink})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, ok
+}
+
+// serveSFTP serves testFiles over SFTP to ada, who logs in with the password
+// pw or the key authorized, and returns the address of the server, its host
+// key and the highest number of connections it had at once.
+func serveSFTP(t *testing.T, authorized ssh.PublicKey) (string, ssh.PublicKey, func() int64) {
+	t.Helper()
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if c.User() == "ada" && string(password) == "pw" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid password")
+		},
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if c.User() == "ada" && authorized != nil && string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unauthorized key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	var open, peak atomic.Int64
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, channels, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				n := open.Add(1)
+				defer open.Add(-1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+				go ssh.DiscardRequests(reqs)
+				for newChannel := range channels {
+					channel, requests, err := newChannel.Accept()
+					if err != nil {
+						return
+					}
+					go func() {
+						for req := range requests {
+							ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+							_ = req.Reply(ok, nil)
+							if ok {
+								go serveSFTPChannel(channel)
+							}
+						}
+					}()
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), hostSigner.PublicKey(), peak.Load
+}
+
+// serveSFTPChannel serves the requests of the SFTP protocol on a channel.
+func serveSFTPChannel(channel ssh.Channel) {
+	defer channel.Close()
+	read := make(map[string]bool)
+	for {
+		typ, data, err := readPacket(channel)
+		if err != nil {
+			return
+		}
+		if typ == sftpInit {
+			_ = writePacket(channel, sftpVersion, uint32(3))
+			continue
+		}
+		id, data, _ := readUint32(data)
+		arg, data, _ := readString(data)
+		status := func(code uint32) { _ = writePacket(channel, sftpStatus, id, code, "", "") }
+		switch typ {
+		case sftpRealPath:
+			_ = writePacket(channel, sftpName, id, uint32(1), "/home/ada", "", uint32(0))
+		case sftpOpenDir:
+			if _, ok := list(arg); !ok {
+				status(2)
+				continue
+			}
+			read[arg] = false
+			_ = writePacket(channel, sftpHandle, id, arg)
+		case sftpReadDir:
+			if read[arg] {
+				status(sftpStatusEOF)
+				continue
+			}
+			read[arg] = true
+			files, _ := list(arg)
+			fields := []any{id, uint32(len(files))}
+			for _, f := range files {
+				perm := uint32(0o100644)
+				switch {
+				case f.mode.IsDir():
+					perm = 0o040755
+				case f.mode&os.ModeSymlink != 0:
+					perm = 0o120777
+				}
+				fields = append(fields, path.Base(f.path), "-rw-r--r-- ada", uint32(sftpAttrSize|sftpAttrPermissions|sftpAttrTimes),
+					uint64(f.size), perm, uint32(testModified.Unix()), uint32(testModified.Unix()))
+			}
+			_ = writePacket(channel, sftpName, fields...)
+		case sftpOpen:
+			if _, ok := testFiles[arg]; !ok {
+				status(2)
+				continue
+			}
+			_ = writePacket(channel, sftpHandle, id, arg)
+		case sftpRead:
+			offset, data, _ := readUint64(data)
+			length, _, _ := readUint32(data)
+			content := testFiles[arg]
+			if offset >= uint64(len(content)) {
+				status(sftpStatusEOF)
+				continue
+			}
+			end := offset + uint64(length)
+			if end > uint64(len(content)) {
+				end = uint64(len(content))
+			}
+			_ = writePacket(channel, sftpData, id, content[offset:end])
+		case sftpClose:
+			status(0)
+		default:
+			status(8)
+		}
+	}
+}
+
+// serveFTP serves testFiles over FTP to bob, who logs in with the password
+// pw, and returns the address of the server.
+func serveFTP(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFTPConn(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serveFTPConn(conn net.Conn) {
+	defer conn.Close()
+	reply := func(format string, args ...any) { _, _ = fmt.Fprintf(conn, format+"\r\n", args...) }
+	// transfer sends data over the data connection of the last EPSV.
+	var data net.Listener
+	transfer := func(content string) {
+		reply("150 Opening data connection")
+		dataConn, err := data.Accept()
+		if err != nil {
+			return
+		}
+		_, _ = io.WriteString(dataConn, content)
+		_ = dataConn.Close()
+		_ = data.Close()
+		reply("226 Transfer complete")
+	}
+
+	reply("220 Ready")
+	scanner := bufio.NewScanner(conn)
+	var user string
+	loggedIn := false
+	for scanner.Scan() {
+		cmd, arg, _ := strings.Cut(scanner.Text(), " ")
+		switch {
+		case cmd == "USER":
+			user = arg
+			reply("331 Password required")
+		case cmd == "PASS":
+			if user != "bob" || arg != "pw" {
+				reply("530 Login incorrect")
+				continue
+			}
+			loggedIn = true
+			reply("230 Logged in")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		case !loggedIn:
+			reply("530 Not logged in")
+		case cmd == "FEAT":
+			reply("211 End")
+		case cmd == "TYPE":
+			reply("200 Type set")
+		case cmd == "PWD":
+			reply(`257 "/home/ada" is the current directory`)
+		case cmd == "EPSV":
+			var err error
+			if data, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+				reply("425 Can't open data connection")
+				continue
+			}
+			reply("229 Entering Extended Passive Mode (|||%d|)", data.Addr().(*net.TCPAddr).Port)
+		case cmd == "LIST":
+			files, ok := list(arg)
+			if !ok {
+				_ = data.Close()
+				reply("550 No such directory")
+				continue
+			}
+			var lines strings.Builder
+			for _, f := range files {
+				mode := "-rw-r--r--"
+				switch {
+				case f.mode.IsDir():
+					mode = "drwxr-xr-x"
+				case f.mode&os.ModeSymlink != 0:
+					mode = "lrwxrwxrwx"
+				}
+				name := path.Base(f.path)
+				if f.mode&os.ModeSymlink != 0 {
+					name += " -> app"
+				}
+				fmt.Fprintf(&lines, "%s 1 ada ada %d Mar 01 2024 %s\r\n", mode, f.size, name)
+			}
+			transfer(lines.String())
+		case cmd == "RETR":
+			content, ok := testFiles[arg]
+			if !ok {
+				_ = data.Close()
+				reply("550 No such file")
+				continue
+			}
+			transfer(content)
+		default:
+			reply("502 Not implemented")
+		}
+	}
+}
+
+// chunks returns the data of the chunks of a source by file, and their
+// metadata.
+func chunks(t *testing.T, conn *sourcespb.FTP) (map[string]string, map[string]*source_metadatapb.FTP) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 4)
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.FTP)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetFtp()
+		got[m.GetFile()] += string(chunk.Data)
+		metadata[m.GetFile()] = m
+	}
+	return got, metadata
+}
+
+func TestSource_ChunksSFTP(t *testing.T) {
+	addr, _, peak := serveSFTP(t, nil)
+	got, metadata := chunks(t, &sourcespb.FTP{
+		Url:            "sftp://ada:pw@" + addr,
+		Insecure:       true,
+		ExcludeGlobs:   []string{"**/node_modules"},
+		MaxConnections: 2,
+	})
+	assert.Equal(t, map[string]string{
+		"/home/ada/.env":           "TOKEN=secret\n",
+		"/home/ada/app/config.yml": "password: hunter2",
+	}, got)
+	assert.Equal(t, &source_metadatapb.FTP{
+		File:      "/home/ada/.env",
+		Link:      "sftp://" + addr + "/home/ada/.env",
+		Host:      addr,
+		Timestamp: "2024-03-01T10:00:00Z",
+	}, metadata["/home/ada/.env"])
+	assert.LessOrEqual(t, peak(), int64(2))
+}
+
+func TestSource_ChunksSFTPPrivateKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	addr, hostKey, _ := serveSFTP(t, signer.PublicKey())
+	knownHostsPath := filepath.Join(t.TempDir(), "known_hosts")
+	require.NoError(t, os.WriteFile(knownHostsPath, []byte(knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostKey)+"\n"), 0o600))
+
+	got, _ := chunks(t, &sourcespb.FTP{
+		Url:          "sftp://" + addr + "/home/ada/app",
+		Username:     "ada",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		KnownHosts:   knownHostsPath,
+		IncludeGlobs: []string{"**/*.yml"},
+	})
+	assert.Equal(t, map[string]string{"/home/ada/app/config.yml": "password: hunter2"}, got)
+
+	// Servers whose keys are not known are not trusted.
+	require.NoError(t, os.WriteFile(knownHostsPath, nil, 0o600))
+	conn, err := anypb.New(&sourcespb.FTP{Url: "sftp://ada:pw@" + addr, KnownHosts: knownHostsPath})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "key is unknown")
+}
+
+func TestSource_ChunksFTP(t *testing.T) {
+	addr := serveFTP(t)
+	got, metadata := chunks(t, &sourcespb.FTP{Url: "ftp://bob:pw@" + addr})
+	assert.Equal(t, map[string]string{
+		"/home/ada/.env":               "TOKEN=secret\n",
+		"/home/ada/app/config.yml":     "password: hunter2",
+		"/home/ada/app/node_modules/x": "module",
+	}, got)
+	assert.Equal(t, "ftp://"+addr+"/home/ada/.env", metadata["/home/ada/.env"].GetLink())
+
+	conn, err := anypb.New(&sourcespb.FTP{Url: "ftp://" + addr})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "Login incorrect")
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	addr := serveFTP(t)
+	conn, err := anypb.New(&sourcespb.FTP{Url: "ftp://bob:pw@" + addr + "/home/ada/app"})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+
+	var targets []sources.Target
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		targets = append(targets, target)
+	}))
+	assert.Equal(t, []sources.Target{{Name: "ftp://" + addr + "/home/ada/app", Objects: 2, Bytes: 23}}, targets)
+}
@@ -0,0 +1,420 @@
+package ftp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// The packets of version 3 of the SFTP protocol, which all servers support,
+// that the client sends and receives.
+// https://datatracker.ietf.org/doc/html/draft-ietf-secsh-filexfer-02
+const (
+	sftpInit     = 1
+	sftpVersion  = 2
+	sftpOpen     = 3
+	sftpClose    = 4
+	sftpRead     = 5
+	sftpOpenDir  = 11
+	sftpReadDir  = 12
+	sftpRealPath = 16
+	sftpStatus   = 101
+	sftpHandle   = 102
+	sftpData     = 103
+	sftpName     = 104
+
+	sftpStatusEOF = 1
+
+	sftpFlagRead = 1
+
+	sftpAttrSize        = 0x1
+	sftpAttrUIDGID      = 0x2
+	sftpAttrPermissions = 0x4
+	sftpAttrTimes       = 0x8
+	sftpAttrExtended    = 0x80000000
+
+	// sftpReadSize is the size of the reads of files, which all servers
+	// support.
+	sftpReadSize = 32 * 1024
+	// sftpMaxPacket is the largest packet read, above which a server is
+	// considered broken.
+	sftpMaxPacket = 1 << 20
+)
+
+// sftpClient is a client of the SFTP subsystem of an SSH connection, which
+// sends one request at a time.
+type sftpClient struct {
+	client *ssh.Client
+	w      io.WriteCloser
+	r      io.Reader
+	id     uint32
+}
+
+var _ remote = (*sftpClient)(nil)
+
+// sftpError is a status of a failed request.
+type sftpError struct {
+	code uint32
+	msg  string
+}
+
+func (e *sftpError) Error() string {
+	return fmt.Sprintf("sftp error %d: %s", e.code, e.msg)
+}
+
+// dialSFTP connects to an SSH server and starts its SFTP subsystem.
+func dialSFTP(addr string, config *ssh.ClientConfig) (*sftpClient, error) {
+	conn, err := dialTCP("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	c, err := newSFTPClient(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func newSFTPClient(client *ssh.Client) (*sftpClient, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	w, err := session.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return nil, fmt.Errorf("error starting sftp: %w", err)
+	}
+
+	c := &sftpClient{client: client, w: w, r: r}
+	if err := writePacket(c.w, sftpInit, uint32(3)); err != nil {
+		return nil, err
+	}
+	typ, _, err := readPacket(c.r)
+	if err != nil {
+		return nil, err
+	}
+	if typ != sftpVersion {
+		return nil, fmt.Errorf("unexpected sftp packet %d", typ)
+	}
+	return c, nil
+}
+
+// request sends a request and returns the type and the data of its
+// response, after its ID.
+func (c *sftpClient) request(typ byte, fields ...any) (byte, []byte, error) {
+	c.id++
+	if err := writePacket(c.w, typ, append([]any{c.id}, fields...)...); err != nil {
+		return 0, nil, err
+	}
+	typ, data, err := readPacket(c.r)
+	if err != nil {
+		return 0, nil, err
+	}
+	id, data, err := readUint32(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	if id != c.id {
+		return 0, nil, fmt.Errorf("unexpected sftp response %d to request %d", id, c.id)
+	}
+	if typ == sftpStatus {
+		code, data, err := readUint32(data)
+		if err != nil {
+			return 0, nil, err
+		}
+		msg, _, _ := readString(data)
+		return typ, nil, &sftpError{code: code, msg: msg}
+	}
+	return typ, data, nil
+}
+
+// handle sends a request that returns a handle.
+func (c *sftpClient) handle(typ byte, fields ...any) (string, error) {
+	respType, data, err := c.request(typ, fields...)
+	if err != nil {
+		return "", err
+	}
+	if respType != sftpHandle {
+		return "", fmt.Errorf("unexpected sftp packet %d", respType)
+	}
+	handle, _, err := readString(data)
+	return handle, err
+}
+
+func (c *sftpClient) closeHandle(handle string) error {
+	_, _, err := c.request(sftpClose, handle)
+	return err
+}
+
+func (c *sftpClient) readDir(dir string) ([]remoteFile, error) {
+	handle, err := c.handle(sftpOpenDir, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = c.closeHandle(handle) }()
+
+	var files []remoteFile
+	for {
+		typ, data, err := c.request(sftpReadDir, handle)
+		var statusErr *sftpError
+		if errors.As(err, &statusErr) && statusErr.code == sftpStatusEOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if typ != sftpName {
+			return nil, fmt.Errorf("unexpected sftp packet %d", typ)
+		}
+		count, data, err := readUint32(data)
+		if err != nil {
+			return nil, err
+		}
+		for i := uint32(0); i < count; i++ {
+			var name string
+			if name, data, err = readString(data); err != nil {
+				return nil, err
+			}
+			// The long name is the line of ls -l, which the attributes
+			// describe.
+			if _, data, err = readString(data); err != nil {
+				return nil, err
+			}
+			var f remoteFile
+			if f, data, err = readAttrs(data); err != nil {
+				return nil, err
+			}
+			if name == "." || name == ".." {
+				continue
+			}
+			f.path = path.Join(dir, name)
+			files = append(files, f)
+		}
+	}
+}
+
+func (c *sftpClient) workDir() (string, error) {
+	typ, data, err := c.request(sftpRealPath, ".")
+	if err != nil {
+		return "", err
+	}
+	if typ != sftpName {
+		return "", fmt.Errorf("unexpected sftp packet %d", typ)
+	}
+	// The response names the one path requested.
+	if _, data, err = readUint32(data); err != nil {
+		return "", err
+	}
+	dir, _, err := readString(data)
+	return dir, err
+}
+
+func (c *sftpClient) open(name string) (io.ReadCloser, error) {
+	handle, err := c.handle(sftpOpen, name, uint32(sftpFlagRead), uint32(0))
+	if err != nil {
+		return nil, err
+	}
+	return &sftpFile{c: c, handle: handle}, nil
+}
+
+func (c *sftpClient) close() error {
+	_ = c.w.Close()
+	return c.client.Close()
+}
+
+// sftpFile reads a file from its start.
+type sftpFile struct {
+	c      *sftpClient
+	handle string
+	offset uint64
+	buf    []byte
+	eof    bool
+}
+
+func (f *sftpFile) Read(p []byte) (int, error) {
+	for len(f.buf) == 0 {
+		if f.eof {
+			return 0, io.EOF
+		}
+		typ, data, err := f.c.request(sftpRead, f.handle, f.offset, uint32(sftpReadSize))
+		var statusErr *sftpError
+		if errors.As(err, &statusErr) && statusErr.code == sftpStatusEOF {
+			f.eof = true
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if typ != sftpData {
+			return 0, fmt.Errorf("unexpected sftp packet %d", typ)
+		}
+		chunk, _, err := readString(data)
+		if err != nil {
+			return 0, err
+		}
+		f.buf = []byte(chunk)
+		f.offset += uint64(len(f.buf))
+	}
+	n := copy(p, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+func (f *sftpFile) Close() error {
+	return f.c.closeHandle(f.handle)
+}
+
+// writePacket writes a packet of fields, which are uint32s, uint64s,
+// strings or byte slices.
+func writePacket(w io.Writer, typ byte, fields ...any) error {
+	data := []byte{0, 0, 0, 0, typ}
+	for _, field := range fields {
+		switch v := field.(type) {
+		case uint32:
+			data = binary.BigEndian.AppendUint32(data, v)
+		case uint64:
+			data = binary.BigEndian.AppendUint64(data, v)
+		case string:
+			data = binary.BigEndian.AppendUint32(data, uint32(len(v)))
+			data = append(data, v...)
+		case []byte:
+			data = binary.BigEndian.AppendUint32(data, uint32(len(v)))
+			data = append(data, v...)
+		default:
+			return fmt.Errorf("unsupported sftp field %T", field)
+		}
+	}
+	binary.BigEndian.PutUint32(data, uint32(len(data)-4))
+	_, err := w.Write(data)
+	return err
+}
+
+// readPacket reads a packet, and returns its type and its data.
+func readPacket(r io.Reader) (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > sftpMaxPacket {
+		return 0, nil, fmt.Errorf("invalid sftp packet length %d", length)
+	}
+	data := make([]byte, length-1)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, err
+	}
+	return header[4], data, nil
+}
+
+var errShortPacket = errors.New("short sftp packet")
+
+func readUint32(data []byte) (uint32, []byte, error) {
+	if len(data) < 4 {
+		return 0, nil, errShortPacket
+	}
+	return binary.BigEndian.Uint32(data), data[4:], nil
+}
+
+func readUint64(data []byte) (uint64, []byte, error) {
+	if len(data) < 8 {
+		return 0, nil, errShortPacket
+	}
+	return binary.BigEndian.Uint64(data), data[8:], nil
+}
+
+func readString(data []byte) (string, []byte, error) {
+	n, data, err := readUint32(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if uint32(len(data)) < n {
+		return "", nil, errShortPacket
+	}
+	return string(data[:n]), data[n:], nil
+}
+
+// readAttrs reads the attributes of a file that it is described by.
+func readAttrs(data []byte) (remoteFile, []byte, error) {
+	var f remoteFile
+	flags, data, err := readUint32(data)
+	if err != nil {
+		return f, nil, err
+	}
+	if flags&sftpAttrSize != 0 {
+		var size uint64
+		if size, data, err = readUint64(data); err != nil {
+			return f, nil, err
+		}
+		f.size = int64(size)
+	}
+	if flags&sftpAttrUIDGID != 0 {
+		if len(data) < 8 {
+			return f, nil, errShortPacket
+		}
+		data = data[8:]
+	}
+	// Files without permissions are considered regular files.
+	if flags&sftpAttrPermissions != 0 {
+		var perm uint32
+		if perm, data, err = readUint32(data); err != nil {
+			return f, nil, err
+		}
+		f.mode = fileMode(perm)
+	}
+	if flags&sftpAttrTimes != 0 {
+		var mtime uint32
+		// The access time is followed by the modification time.
+		if _, data, err = readUint32(data); err != nil {
+			return f, nil, err
+		}
+		if mtime, data, err = readUint32(data); err != nil {
+			return f, nil, err
+		}
+		f.modified = time.Unix(int64(mtime), 0).UTC()
+	}
+	if flags&sftpAttrExtended != 0 {
+		var count uint32
+		if count, data, err = readUint32(data); err != nil {
+			return f, nil, err
+		}
+		for i := uint32(0); i < 2*count; i++ {
+			if _, data, err = readString(data); err != nil {
+				return f, nil, err
+			}
+		}
+	}
+	return f, data, nil
+}
+
+// fileMode returns the type of a file of SFTP permissions.
+func fileMode(mode uint32) os.FileMode {
+	switch mode & 0o170000 {
+	case 0o040000:
+		return os.ModeDir
+	case 0o100000:
+		return 0
+	case 0o120000:
+		return os.ModeSymlink
+	}
+	return os.ModeIrregular
+}
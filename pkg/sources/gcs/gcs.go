@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,6 +16,7 @@ import (
 	"github.com/go-logr/logr"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/endpoints"
+	"google.golang.org/api/option"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
@@ -50,6 +52,11 @@ func (s *Source) JobID() int64 {
 	return s.jobId
 }
 
+// WithArchiveOptions sets how the archives of objects are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
 type objectManager interface {
 	ListObjects(context.Context) (chan io.Reader, error)
 	Attributes(ctx context.Context) (*attributes, error)
@@ -67,6 +74,11 @@ type Source struct {
 	stats      *attributes
 	log        logr.Logger
 	chunksCh   chan *sources.Chunk
+	// statePath is the file of the objects scanned, which resumes the next
+	// scans.
+	statePath string
+	// archiveOptions configures how the archives of objects are extracted.
+	archiveOptions sources.ArchiveOptions
 
 	mu               sync.Mutex
 	sources.Progress // progress is not thread safe
@@ -80,6 +92,9 @@ type persistableCache struct {
 	persistIncrement int
 	cache.Cache
 	*sources.Progress
+	// onPersist is called with the contents of the cache when they are
+	// persisted, if set.
+	onPersist func(contents string)
 }
 
 func newPersistableCache(increment int, cache cache.Cache, p *sources.Progress) *persistableCache {
@@ -96,6 +111,9 @@ func (c *persistableCache) Set(key, val string) {
 	c.Cache.Set(key, val)
 	if ok, contents := c.shouldPersist(); ok {
 		c.Progress.EncodedResumeInfo = contents
+		if c.onPersist != nil {
+			c.onPersist(contents)
+		}
 	}
 }
 
@@ -121,6 +139,7 @@ func (s *Source) Init(aCtx context.Context, name string, id int64, sourceID int6
 	if err != nil {
 		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
 	}
+	s.statePath = conn.GetStatePath()
 
 	gcsManager, err := configureGCSManager(aCtx, &conn, concurrency)
 	if err != nil {
@@ -142,21 +161,30 @@ func configureGCSManager(aCtx context.Context, conn *sourcespb.GCS, concurrency
 	}
 
 	var gcsManagerAuthOption gcsManagerOption
+	// apiOpts authenticate the clients of the APIs that list projects and
+	// the buckets of organizations with the same credentials.
+	var apiOpts []option.ClientOption
 
 	switch conn.Credential.(type) {
 	case *sourcespb.GCS_ApiKey:
 		gcsManagerAuthOption = withAPIKey(aCtx, conn.GetApiKey())
+		apiOpts = append(apiOpts, option.WithAPIKey(conn.GetApiKey()))
 	case *sourcespb.GCS_ServiceAccountFile:
 		b, err := os.ReadFile(conn.GetServiceAccountFile())
 		if err != nil {
 			return nil, fmt.Errorf("error reading GCS JSON Service Account file: %w", err)
 		}
 		gcsManagerAuthOption = withJSONServiceAccount(aCtx, b)
+		apiOpts = append(apiOpts, option.WithCredentialsJSON(b))
 	case *sourcespb.GCS_JsonServiceAccount:
 		gcsManagerAuthOption = withJSONServiceAccount(aCtx, []byte(conn.GetJsonServiceAccount()))
+		apiOpts = append(apiOpts, option.WithCredentialsJSON([]byte(conn.GetJsonServiceAccount())))
 	case *sourcespb.GCS_Adc:
 		gcsManagerAuthOption = withDefaultADC(aCtx)
 	case *sourcespb.GCS_Unauthenticated:
+		if conn.GetAllProjects() || conn.GetOrganizationId() != "" {
+			return nil, fmt.Errorf("listing the buckets of all projects or of an organization requires authentication")
+		}
 		gcsManagerAuthOption = withoutAuthentication()
 	case *sourcespb.GCS_Oauth:
 		client, err := oauth2Client(aCtx, conn.GetOauth())
@@ -164,6 +192,7 @@ func configureGCSManager(aCtx context.Context, conn *sourcespb.GCS, concurrency
 			return nil, fmt.Errorf("error creating oauth2 client: %w", err)
 		}
 		gcsManagerAuthOption = withHTTPClient(aCtx, client)
+		apiOpts = append(apiOpts, option.WithHTTPClient(client))
 	default:
 		return nil, fmt.Errorf("unknown GCS authentication type: %T", conn.Credential)
 
@@ -180,6 +209,18 @@ func configureGCSManager(aCtx context.Context, conn *sourcespb.GCS, concurrency
 	if setGCSManagerObjectOptions(conn) != nil {
 		gcsManagerOpts = append(gcsManagerOpts, setGCSManagerObjectOptions(conn))
 	}
+	if len(conn.GetProjectIds()) > 0 {
+		gcsManagerOpts = append(gcsManagerOpts, withProjectIDs(conn.GetProjectIds()))
+	}
+	if conn.GetAllProjects() {
+		gcsManagerOpts = append(gcsManagerOpts, withAllProjects(aCtx, apiOpts...))
+	}
+	if conn.GetOrganizationId() != "" {
+		gcsManagerOpts = append(gcsManagerOpts, withOrganization(aCtx, conn.GetOrganizationId(), apiOpts...))
+	}
+	if conn.GetIncludeGenerations() {
+		gcsManagerOpts = append(gcsManagerOpts, withGenerations())
+	}
 
 	gcsManager, err := newGCSManager(conn.ProjectId, gcsManagerOpts...)
 	if err != nil {
@@ -247,8 +288,31 @@ func (s *Source) enumerate(ctx context.Context) error {
 	return nil
 }
 
+// EnumerateTargets reports each bucket with the number and size of its
+// objects.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	stats, err := s.gcsManager.Attributes(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting attributes during enumeration: %w", err)
+	}
+	stats.mu.RLock()
+	defer stats.mu.RUnlock()
+	buckets := make([]string, 0, len(stats.bucketObjects))
+	for bkt := range stats.bucketObjects {
+		buckets = append(buckets, bkt)
+	}
+	sort.Strings(buckets)
+	for _, bkt := range buckets {
+		report(sources.Target{Name: bkt, Objects: int64(stats.bucketObjects[bkt]), Bytes: stats.bucketBytes[bkt]})
+	}
+	return nil
+}
+
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	if err := s.loadState(); err != nil {
+		return err
+	}
 	persistableCache := s.setupCache(ctx)
 
 	objectCh, err := s.gcsManager.ListObjects(ctx)
@@ -267,28 +331,63 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 			continue
 		}
 
-		if persistableCache.Exists(o.md5) {
+		if persistableCache.Exists(o.cacheKey()) {
 			ctx.Logger().V(5).Info("skipping object, object already processed", "name", o.name)
+			o.close()
 			continue
 		}
 
 		wg.Add(1)
 		go func(obj object) {
 			defer wg.Done()
+			defer o.close()
 
 			if err := s.processObject(ctx, o); err != nil {
 				ctx.Logger().V(1).Info("error setting start progress progress", "name", o.name, "error", err)
 				return
 			}
-			s.setProgress(ctx, o.md5, o.name, persistableCache)
+			s.setProgress(ctx, o.cacheKey(), o.name, persistableCache)
 		}(o)
 	}
 	wg.Wait()
 
 	s.completeProgress(ctx)
+	return s.saveState(persistableCache.Contents())
+}
+
+// gcsState is the state file of the objects scanned.
+type gcsState struct {
+	Objects []string `json:"objects"`
+}
+
+// loadState resumes from the objects of the state file, unless the source
+// has resume info already.
+func (s *Source) loadState() error {
+	if s.statePath == "" || s.Progress.EncodedResumeInfo != "" {
+		return nil
+	}
+	var state gcsState
+	if err := sources.LoadState(s.statePath, &state); err != nil {
+		return err
+	}
+	s.Progress.EncodedResumeInfo = strings.Join(state.Objects, ",")
 	return nil
 }
 
+// saveState saves the objects of the contents of the cache to the state
+// file, if there is one.
+func (s *Source) saveState(contents string) error {
+	if s.statePath == "" {
+		return nil
+	}
+	var state gcsState
+	if contents != "" {
+		state.Objects = strings.Split(contents, ",")
+	}
+	sort.Strings(state.Objects)
+	return sources.SaveState(s.statePath, state)
+}
+
 func (s *Source) setupCache(ctx context.Context) *persistableCache {
 	var c cache.Cache
 	if s.Progress.EncodedResumeInfo != "" {
@@ -299,6 +398,13 @@ func (s *Source) setupCache(ctx context.Context) *persistableCache {
 
 	// TODO (ahrav): Make this configurable via conn.
 	persistCache := newPersistableCache(defaultCachePersistIncrement, c, &s.Progress)
+	if s.statePath != "" {
+		persistCache.onPersist = func(contents string) {
+			if err := s.saveState(contents); err != nil {
+				ctx.Logger().Error(err, "error saving GCS state")
+			}
+		}
+	}
 	return persistCache
 }
 
@@ -337,6 +443,8 @@ func (s *Source) processObject(ctx context.Context, o object) error {
 					Acls:        o.acl,
 					CreatedAt:   strconv.FormatInt(o.createdAt.Unix(), 10), // Unix time as string
 					UpdatedAt:   o.updatedAt.String(),
+					Line:        1,
+					Generation:  o.generation,
 				},
 			},
 		},
@@ -370,7 +478,7 @@ func (s *Source) readObjectData(ctx context.Context, o object, chunk *sources.Ch
 	}
 	defer reader.Close()
 
-	if handlers.HandleFile(ctx, reader, chunk, s.chunksCh) {
+	if handlers.HandleFile(ctx, reader, chunk, s.chunksCh, handlers.WithArchiveOptions(s.archiveOptions)) {
 		ctx.Logger().V(3).Info("File was handled", "name", s.name, "bucket", o.bucket, "object", o.name)
 		return nil, nil
 	}
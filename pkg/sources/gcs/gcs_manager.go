@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -16,6 +19,8 @@ import (
 	"github.com/googleapis/gax-go/v2"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/cloudasset/v1"
+	"google.golang.org/api/cloudresourcemanager/v1"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 
@@ -62,6 +67,15 @@ type gcsManager struct {
 	buckets map[string]bucket
 	attr    *attributes
 
+	// projectIDs are the other projects whose buckets are listed.
+	projectIDs []string
+	// listProjects and listOrgBuckets list the projects that the
+	// credentials can list, and the buckets of an organization.
+	listProjects,
+	listOrgBuckets func(context.Context) ([]string, error)
+	// includeGenerations lists the noncurrent generations of objects too.
+	includeGenerations bool
+
 	client bucketManager
 }
 
@@ -91,12 +105,14 @@ type attributes struct {
 	numObjects    uint64
 	mu            sync.RWMutex
 	bucketObjects map[string]uint64
+	bucketBytes   map[string]int64
 }
 
 func newStats(numBkts int) *attributes {
 	return &attributes{
 		numBuckets:    uint32(numBkts),
 		bucketObjects: make(map[string]uint64, numBkts),
+		bucketBytes:   make(map[string]int64, numBkts),
 	}
 }
 
@@ -112,6 +128,12 @@ func (s *attributes) setBucketCnt(bkt string, cnt uint64) {
 	s.mu.Unlock()
 }
 
+func (s *attributes) setBucketBytes(bkt string, size int64) {
+	s.mu.Lock()
+	s.bucketBytes[bkt] = size
+	s.mu.Unlock()
+}
+
 type gcsManagerOption func(*gcsManager) error
 
 // withHTTPClient uses the provided HTTP client when creating a new GCS client.
@@ -273,6 +295,73 @@ func withExcludeObjects(objects []string) gcsManagerOption {
 	}
 }
 
+// withProjectIDs sets other projects whose buckets are listed, besides the
+// project of the manager.
+func withProjectIDs(projectIDs []string) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.projectIDs = projectIDs
+		return nil
+	}
+}
+
+// withAllProjects lists the buckets of all the projects that the
+// credentials of the options can list with the Resource Manager API.
+func withAllProjects(ctx context.Context, opts ...option.ClientOption) gcsManagerOption {
+	opts = append(opts, option.WithScopes(cloudresourcemanager.CloudPlatformReadOnlyScope))
+	svc, err := cloudresourcemanager.NewService(ctx, opts...)
+	return func(m *gcsManager) error {
+		if err != nil {
+			return err
+		}
+		m.listProjects = func(ctx context.Context) ([]string, error) {
+			var projects []string
+			err := svc.Projects.List().Filter("lifecycleState:ACTIVE").Pages(ctx, func(resp *cloudresourcemanager.ListProjectsResponse) error {
+				for _, p := range resp.Projects {
+					projects = append(projects, p.ProjectId)
+				}
+				return nil
+			})
+			return projects, err
+		}
+		return nil
+	}
+}
+
+// withOrganization lists the buckets of an organization across its
+// projects with the Cloud Asset Inventory API, which needs the
+// cloudasset.assets.searchAllResources permission on the organization.
+func withOrganization(ctx context.Context, organizationID string, opts ...option.ClientOption) gcsManagerOption {
+	opts = append(opts, option.WithScopes(cloudasset.CloudPlatformScope))
+	svc, err := cloudasset.NewService(ctx, opts...)
+	return func(m *gcsManager) error {
+		if err != nil {
+			return err
+		}
+		scope := "organizations/" + strings.TrimPrefix(organizationID, "organizations/")
+		m.listOrgBuckets = func(ctx context.Context) ([]string, error) {
+			var buckets []string
+			call := svc.V1.SearchAllResources(scope).AssetTypes("storage.googleapis.com/Bucket")
+			err := call.Pages(ctx, func(resp *cloudasset.SearchAllResourcesResponse) error {
+				for _, r := range resp.Results {
+					buckets = append(buckets, strings.TrimPrefix(r.Name, "//storage.googleapis.com/"))
+				}
+				return nil
+			})
+			return buckets, err
+		}
+		return nil
+	}
+}
+
+// withGenerations lists the noncurrent generations of the objects of
+// buckets with versioning too.
+func withGenerations() gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.includeGenerations = true
+		return nil
+	}
+}
+
 // withConcurrency sets the number of concurrent workers that will be used
 // to process objects.
 // If not set, or set to a negative number the default value is runtime.NumCPU().
@@ -335,7 +424,7 @@ func newGCSManager(projectID string, opts ...gcsManagerOption) (*gcsManager, err
 		}
 	}
 
-	if projectID == "" && !gcs.withoutAuth {
+	if projectID == "" && !gcs.withoutAuth && len(gcs.projectIDs) == 0 && gcs.listProjects == nil && gcs.listOrgBuckets == nil {
 		return nil, fmt.Errorf("project ID is required, when using authentication")
 	}
 
@@ -366,6 +455,7 @@ type object struct {
 	owner       string
 	link        string
 	md5         string
+	generation  int64
 	// acl represents an ACLEntities.
 	// https://pkg.go.dev/cloud.google.com/go/storage#ACLEntity
 	acl       []string
@@ -376,6 +466,23 @@ type object struct {
 	io.Reader
 }
 
+// cacheKey identifies the content of the object in the cache of the objects
+// scanned. Composite objects have no MD5, and are identified by their
+// generation instead.
+func (o object) cacheKey() string {
+	if o.md5 != "" {
+		return o.md5
+	}
+	return "gs://" + o.bucket + "/" + url.QueryEscape(o.name) + "#" + strconv.FormatInt(o.generation, 10)
+}
+
+// close closes the reader of the object, if it has one.
+func (o object) close() {
+	if c, ok := o.Reader.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
 func (g *gcsManager) Attributes(ctx context.Context) (*attributes, error) {
 	// Get all the buckets in the project.
 	buckets, err := g.listBuckets(ctx)
@@ -402,13 +509,14 @@ func (g *gcsManager) enumerate(ctx context.Context, bkts []bucket) (*attributes,
 			// List all the objects in the bucket and calculate attributes.
 			g.setupBktHandle(&bkt)
 
-			q, err := setObjectQuery(&bkt)
+			q, err := setObjectQuery(&bkt, g.includeGenerations)
 			if err != nil {
 				logger.Error(err, "failed to set object query", "bucket", bkt.name)
 				return nil
 			}
 
 			var count uint64
+			var size int64
 			objs := bkt.Objects(ctx, q)
 			for {
 				obj, err := objs.Next()
@@ -425,14 +533,16 @@ func (g *gcsManager) enumerate(ctx context.Context, bkts []bucket) (*attributes,
 					continue
 				}
 
-				if !g.shouldIncludeObject(ctx, obj.Name) || g.shouldExcludeObject(ctx, obj.Name) {
+				if !g.shouldIncludeObject(ctx, bkt.name, obj.Name) || g.shouldExcludeObject(ctx, bkt.name, obj.Name) {
 					continue
 				}
 				count++
+				size += obj.Size
 				stats.incObjects()
 			}
 
 			stats.setBucketCnt(bkt.name, count)
+			stats.setBucketBytes(bkt.name, size)
 			return nil
 		})
 	}
@@ -503,31 +613,84 @@ func (g *gcsManager) listBuckets(ctx context.Context) ([]bucket, error) {
 		return buckets, nil
 	}
 
-	bkts := g.client.Buckets(ctx, g.projectID)
-	for {
-		bkt, err := bkts.Next()
-		if errors.Is(err, iterator.Done) {
-			ctx.Logger().V(5).Info("finished listing buckets", "num_buckets", len(buckets))
-			break
+	seen := make(map[string]struct{})
+	addBucket := func(name string) {
+		if _, ok := seen[name]; ok {
+			return
 		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to retrieve bucket: %w", err)
-		}
-
+		seen[name] = struct{}{}
 		// If the bucket is already in the map, skip it, it's already accounted for.
 		// This is used to resume listing objects for a bucket.
-		if _, ok := g.buckets[bkt.Name]; ok {
-			continue
+		if _, ok := g.buckets[name]; ok {
+			return
 		}
-		if !g.shouldIncludeBucket(ctx, bkt.Name) || g.shouldExcludeBucket(ctx, bkt.Name) {
-			continue
+		if !g.shouldIncludeBucket(ctx, name) || g.shouldExcludeBucket(ctx, name) {
+			return
 		}
-		buckets = append(buckets, bucket{name: bkt.Name})
+		buckets = append(buckets, bucket{name: name})
+	}
+
+	projects, err := g.listProjectIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		bkts := g.client.Buckets(ctx, project)
+		for {
+			bkt, err := bkts.Next()
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			if err != nil && project != g.projectID {
+				// Listed projects may not have the storage API enabled.
+				ctx.Logger().V(2).Info("failed to list buckets of project", "project", project, "error", err)
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to retrieve bucket: %w", err)
+			}
+			addBucket(bkt.Name)
+		}
+	}
 
+	if g.listOrgBuckets != nil {
+		names, err := g.listOrgBuckets(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search buckets of organization: %w", err)
+		}
+		for _, name := range names {
+			addBucket(name)
+		}
 	}
+	ctx.Logger().V(5).Info("finished listing buckets", "num_buckets", len(buckets))
 	return buckets, nil
 }
 
+// listProjectIDs returns the projects whose buckets are listed: the project
+// of the manager, the other projects, and the projects that the credentials
+// can list.
+func (g *gcsManager) listProjectIDs(ctx context.Context) ([]string, error) {
+	projects := append([]string{g.projectID}, g.projectIDs...)
+	if g.listProjects != nil {
+		listed, err := g.listProjects(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+		projects = append(projects, listed...)
+	}
+
+	seen := make(map[string]struct{}, len(projects))
+	unique := make([]string, 0, len(projects))
+	for _, project := range projects {
+		if _, ok := seen[project]; ok || project == "" {
+			continue
+		}
+		seen[project] = struct{}{}
+		unique = append(unique, project)
+	}
+	return unique, nil
+}
+
 func (g *gcsManager) listBucketObjects(ctx context.Context, bkt *bucket) (chan io.Reader, chan error) {
 	ch := make(chan io.Reader, 100)
 	errCh := make(chan error, 1)
@@ -563,7 +726,7 @@ func (g *gcsManager) setupBktHandle(bkt *bucket) {
 }
 
 func (g *gcsManager) bucketObjects(ctx context.Context, bkt *bucket, ch chan<- io.Reader) error {
-	q, err := setObjectQuery(bkt)
+	q, err := setObjectQuery(bkt, g.includeGenerations)
 	if err != nil {
 		return fmt.Errorf("failed to set object query: %w", err)
 	}
@@ -583,11 +746,15 @@ func (g *gcsManager) bucketObjects(ctx context.Context, bkt *bucket, ch chan<- i
 			continue
 		}
 
-		if !g.shouldIncludeObject(ctx, obj.Name) || g.shouldExcludeObject(ctx, obj.Name) {
+		if !g.shouldIncludeObject(ctx, bkt.name, obj.Name) || g.shouldExcludeObject(ctx, bkt.name, obj.Name) {
 			continue
 		}
 
-		o, err := g.constructObject(ctx, bkt.Object(obj.Name))
+		handle := bkt.Object(obj.Name)
+		if g.includeGenerations {
+			handle = handle.Generation(obj.Generation)
+		}
+		o, err := g.constructObject(ctx, handle)
 		if err != nil {
 			ctx.Logger().V(1).Info("failed to create object", "object-name", obj.Name, "error", err)
 			continue
@@ -597,12 +764,15 @@ func (g *gcsManager) bucketObjects(ctx context.Context, bkt *bucket, ch chan<- i
 	return nil
 }
 
-func setObjectQuery(bkt *bucket) (*storage.Query, error) {
+// setObjectQuery returns the query of the objects of a bucket, which lists
+// their noncurrent generations too with versions.
+func setObjectQuery(bkt *bucket, versions bool) (*storage.Query, error) {
 	// Setting the attribute selection is a performance optimization.
 	// https://pkg.go.dev/cloud.google.com/go/storage#Query.SetAttrSelection
-	q := new(storage.Query)
+	q := &storage.Query{Versions: versions}
 	err := q.SetAttrSelection([]string{
 		"Name",
+		"Generation",
 		"ContentType",
 		"Owner",
 		"Size",
@@ -629,7 +799,16 @@ func (g *gcsManager) constructObject(ctx context.Context, obj *storage.ObjectHan
 		return o, fmt.Errorf("failed to retrieve object attributes: %w", err)
 	}
 
-	if !isObjectTypeValid(ctx, attrs.Name) || !g.isObjectSizeValid(ctx, attrs.Size) {
+	if !isObjectTypeValid(ctx, attrs.Name) {
+		sources.ReportSkip(ctx, attrs.Name, sources.SkipReasonUnsupported)
+		return o, fmt.Errorf("object is not valid")
+	}
+	if !g.isObjectSizeValid(ctx, attrs.Size) {
+		reason := sources.SkipReasonSize
+		if attrs.Size <= 0 {
+			reason = sources.SkipReasonEmpty
+		}
+		sources.ReportSkip(ctx, attrs.Name, reason)
 		return o, fmt.Errorf("object is not valid")
 	}
 
@@ -644,6 +823,7 @@ func (g *gcsManager) constructObject(ctx context.Context, obj *storage.ObjectHan
 	o.owner = attrs.Owner
 	o.link = attrs.MediaLink
 	o.md5 = hex.EncodeToString(attrs.MD5)
+	o.generation = attrs.Generation
 	o.createdAt = attrs.Created
 	o.updatedAt = attrs.Updated
 	o.acl = objectACLs(attrs.ACL)
@@ -692,15 +872,34 @@ func (g *gcsManager) shouldExcludeBucket(ctx context.Context, bkt string) bool {
 	return shouldProcess(ctx, bkt, g.excludeBuckets, globMatches)
 }
 
-func (g *gcsManager) shouldIncludeObject(ctx context.Context, obj string) bool {
-	if len(g.includeObjects) == 0 {
+func (g *gcsManager) shouldIncludeObject(ctx context.Context, bkt, obj string) bool {
+	include := bucketObjectPatterns(g.includeObjects, bkt)
+	if len(include) == 0 {
 		return true
 	}
-	return shouldProcess(ctx, obj, g.includeObjects, globMatches)
+	return shouldProcess(ctx, obj, include, globMatches)
 }
 
-func (g *gcsManager) shouldExcludeObject(ctx context.Context, obj string) bool {
-	return shouldProcess(ctx, obj, g.excludeObjects, globMatches)
+func (g *gcsManager) shouldExcludeObject(ctx context.Context, bkt, obj string) bool {
+	return shouldProcess(ctx, obj, bucketObjectPatterns(g.excludeObjects, bkt), globMatches)
+}
+
+// bucketObjectPatterns returns the object patterns that apply to a bucket.
+// Patterns like gs://bucket/glob only apply to the objects of their bucket,
+// and the others to the objects of all of them.
+func bucketObjectPatterns(patterns map[string]struct{}, bkt string) map[string]struct{} {
+	applicable := make(map[string]struct{}, len(patterns))
+	for p := range patterns {
+		if rest, ok := strings.CutPrefix(p, "gs://"); ok {
+			name, pattern, _ := strings.Cut(rest, "/")
+			if name != bkt {
+				continue
+			}
+			p = pattern
+		}
+		applicable[p] = struct{}{}
+	}
+	return applicable
 }
 
 type globMatcherFn func(string, glob.Glob) bool
@@ -1,15 +1,24 @@
 package gcs
 
 import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"cloud.google.com/go/storage"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
@@ -425,7 +434,7 @@ func TestGCSManagerStats(t *testing.T) {
 				t.Errorf("Attributes() error = %v", err)
 			}
 
-			if diff := cmp.Diff(got, tc.wantStats, cmp.AllowUnexported(attributes{}), cmpopts.IgnoreFields(attributes{}, "mu")); diff != "" {
+			if diff := cmp.Diff(got, tc.wantStats, cmp.AllowUnexported(attributes{}), cmpopts.IgnoreFields(attributes{}, "mu", "bucketBytes")); diff != "" {
 				t.Errorf("Attributes() got: %v, want: %v, diff: %v", got, tc.wantStats, diff)
 			}
 		})
@@ -727,7 +736,7 @@ func TestGCSManagerListObjects(t *testing.T) {
 				sort.Slice(tc.want, func(i, j int) bool { return tc.want[i].name < tc.want[j].name })
 
 				// Test the objects are equal.
-				if diff := cmp.Diff(res, tc.want, cmp.AllowUnexported(object{}), cmpopts.IgnoreFields(object{}, "Reader", "createdAt", "updatedAt")); diff != "" {
+				if diff := cmp.Diff(res, tc.want, cmp.AllowUnexported(object{}), cmpopts.IgnoreFields(object{}, "Reader", "createdAt", "updatedAt", "generation")); diff != "" {
 					t.Errorf("gcsManager.ListObjects() mismatch (-want +got):\n%s", diff)
 				}
 			}()
@@ -815,7 +824,7 @@ func TestGCSManagerListObjects_Resuming(t *testing.T) {
 				sort.Slice(tc.want, func(i, j int) bool { return tc.want[i].name < tc.want[j].name })
 
 				// Test the objects are equal.
-				if diff := cmp.Diff(res, tc.want, cmp.AllowUnexported(object{}), cmpopts.IgnoreFields(object{}, "Reader", "createdAt", "updatedAt")); diff != "" {
+				if diff := cmp.Diff(res, tc.want, cmp.AllowUnexported(object{}), cmpopts.IgnoreFields(object{}, "Reader", "createdAt", "updatedAt", "generation")); diff != "" {
 					t.Errorf("gcsManager.ListObjects() mismatch (-want +got):\n%s", diff)
 				}
 			}()
@@ -911,3 +920,209 @@ func Test_isObjectSizeValid(t *testing.T) {
 		})
 	}
 }
+
+// fakeObject is a generation of an object of fakeGCS.
+type fakeObject struct {
+	name       string
+	generation int64
+	content    string
+}
+
+// fakeGCS serves the buckets of projects and their objects with the JSON
+// API, and the content of objects with the XML API. The last generation of
+// an object is the live one.
+type fakeGCS struct {
+	projects map[string][]string
+	buckets  map[string][]fakeObject
+}
+
+func (f *fakeGCS) start(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		objectJSON := func(bkt string, o fakeObject) map[string]any {
+			sum := md5.Sum([]byte(o.content))
+			return map[string]any{
+				"name":        o.name,
+				"bucket":      bkt,
+				"generation":  strconv.FormatInt(o.generation, 10),
+				"size":        strconv.Itoa(len(o.content)),
+				"md5Hash":     base64.StdEncoding.EncodeToString(sum[:]),
+				"contentType": "text/plain",
+				"timeCreated": "2024-01-02T03:04:05Z",
+				"updated":     "2024-01-02T03:04:05Z",
+			}
+		}
+		// live returns whether an object is the last generation of its name.
+		live := func(objs []fakeObject, i int) bool {
+			for _, o := range objs[i+1:] {
+				if o.name == objs[i].name {
+					return false
+				}
+			}
+			return true
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/storage/v1/")
+		switch {
+		case path == "b":
+			names, ok := f.projects[r.URL.Query().Get("project")]
+			if !ok {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			var items []map[string]any
+			for _, name := range names {
+				items = append(items, map[string]any{"name": name})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+		case strings.HasPrefix(path, "b/") && path != r.URL.Path:
+			bkt, rest, _ := strings.Cut(strings.TrimPrefix(path, "b/"), "/o")
+			objs := f.buckets[bkt]
+			if rest == "" {
+				var items []map[string]any
+				for i, o := range objs {
+					if r.URL.Query().Get("versions") == "true" || live(objs, i) {
+						items = append(items, objectJSON(bkt, o))
+					}
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+				return
+			}
+			name := strings.TrimPrefix(rest, "/")
+			for i, o := range objs {
+				gen := r.URL.Query().Get("generation")
+				if o.name == name && ((gen == "" && live(objs, i)) || gen == strconv.FormatInt(o.generation, 10)) {
+					_ = json.NewEncoder(w).Encode(objectJSON(bkt, o))
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			bkt, name, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+			objs := f.buckets[bkt]
+			for i, o := range objs {
+				gen := r.URL.Query().Get("generation")
+				if o.name == name && ((gen == "" && live(objs, i)) || gen == strconv.FormatInt(o.generation, 10)) {
+					_, _ = w.Write([]byte(o.content))
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGCSManagerListObjects_Projects(t *testing.T) {
+	ctx := context.Background()
+	server := (&fakeGCS{
+		projects: map[string][]string{
+			"proj-a": {"bkt-a"},
+			"proj-b": {"bkt-b", "bkt-a"},
+		},
+		buckets: map[string][]fakeObject{
+			"bkt-a": {
+				{name: "config.env", generation: 1, content: "SECRET=old"},
+				{name: "config.env", generation: 2, content: "SECRET=new"},
+				{name: "notes.txt", generation: 1, content: "notes"},
+			},
+			"bkt-b": {
+				{name: "config.env", generation: 1, content: "B=1"},
+				{name: "skip.log", generation: 1, content: "log"},
+			},
+			"bkt-org": {
+				{name: "org.txt", generation: 3, content: "org"},
+			},
+		},
+	}).start(t)
+	client, err := storage.NewClient(ctx, option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	assert.Nil(t, err)
+
+	list := func(opts ...gcsManagerOption) map[string]string {
+		opts = append(opts,
+			func(m *gcsManager) error { m.client = client; return nil },
+			withProjectIDs([]string{"proj-b", "proj-unavailable"}),
+			func(m *gcsManager) error {
+				m.listOrgBuckets = func(context.Context) ([]string, error) { return []string{"bkt-org", "bkt-a"}, nil }
+				return nil
+			},
+			withExcludeObjects([]string{"gs://bkt-b/*.log"}),
+		)
+		mgr, err := newGCSManager("proj-a", opts...)
+		assert.Nil(t, err)
+		objs, err := mgr.ListObjects(ctx)
+		assert.Nil(t, err)
+
+		got := make(map[string]string)
+		for r := range objs {
+			o := r.(object)
+			data, err := io.ReadAll(o)
+			assert.Nil(t, err)
+			got[fmt.Sprintf("%s/%s#%d", o.bucket, o.name, o.generation)] = string(data)
+		}
+		return got
+	}
+
+	// The buckets of the projects and of the organization are listed once,
+	// and the exclude glob of bkt-b only applies to its objects.
+	assert.Equal(t, map[string]string{
+		"bkt-a/config.env#2": "SECRET=new",
+		"bkt-a/notes.txt#1":  "notes",
+		"bkt-b/config.env#1": "B=1",
+		"bkt-org/org.txt#3":  "org",
+	}, list())
+
+	assert.Equal(t, map[string]string{
+		"bkt-a/config.env#1": "SECRET=old",
+		"bkt-a/config.env#2": "SECRET=new",
+		"bkt-a/notes.txt#1":  "notes",
+		"bkt-b/config.env#1": "B=1",
+		"bkt-org/org.txt#3":  "org",
+	}, list(withGenerations()))
+}
+
+func TestGCSManager_ProjectsAndOrganization(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/projects":
+			assert.Equal(t, "lifecycleState:ACTIVE", r.URL.Query().Get("filter"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"projects": []map[string]string{{"projectId": "proj-a"}, {"projectId": "proj-b"}}})
+		case "/v1/organizations/123:searchAllResources":
+			assert.Equal(t, "storage.googleapis.com/Bucket", r.URL.Query().Get("assetTypes"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"results": []map[string]string{{"name": "//storage.googleapis.com/bkt-org"}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	mgr, err := newGCSManager("",
+		withoutAuthentication(),
+		withAllProjects(ctx, option.WithEndpoint(server.URL), option.WithoutAuthentication()),
+		withOrganization(ctx, "123", option.WithEndpoint(server.URL), option.WithoutAuthentication()),
+	)
+	assert.Nil(t, err)
+
+	projects, err := mgr.listProjects(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"proj-a", "proj-b"}, projects)
+
+	buckets, err := mgr.listOrgBuckets(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bkt-org"}, buckets)
+}
+
+func Test_bucketObjectPatterns(t *testing.T) {
+	patterns := map[string]struct{}{"*.env": {}, "gs://bkt-a/secrets/*": {}, "gs://bkt-b/*.log": {}}
+	assert.Equal(t, map[string]struct{}{"*.env": {}, "secrets/*": {}}, bucketObjectPatterns(patterns, "bkt-a"))
+	assert.Equal(t, map[string]struct{}{"*.env": {}}, bucketObjectPatterns(patterns, "bkt-c"))
+
+	g := &gcsManager{includeObjects: map[string]struct{}{"gs://bkt-a/*.env": {}}}
+	ctx := context.Background()
+	assert.True(t, g.shouldIncludeObject(ctx, "bkt-a", "app.env"))
+	assert.False(t, g.shouldIncludeObject(ctx, "bkt-a", "app.txt"))
+	// Buckets without include patterns include all their objects.
+	assert.True(t, g.shouldIncludeObject(ctx, "bkt-b", "app.txt"))
+}
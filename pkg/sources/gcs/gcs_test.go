@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -219,6 +221,7 @@ func (m *mockObjectManager) Attributes(_ context.Context) (*attributes, error) {
 		numObjects:    uint64(m.numObjects),
 		numBuckets:    1,
 		bucketObjects: map[string]uint64{testBucket: 5},
+		bucketBytes:   map[string]int64{testBucket: 1024},
 	}, nil
 }
 
@@ -286,6 +289,7 @@ func createTestSourceChunk(id int) *sources.Chunk {
 					Link:        fmt.Sprintf("https://storage.googleapis.com/%s/%s", testBucket, fmt.Sprintf("object%d", id)),
 					Acls:        []string{"authenticatedUsers"},
 					CreatedAt:   "1577836800",
+					Line:        1,
 				},
 			},
 		},
@@ -355,6 +359,21 @@ func TestSourceInit_Enumerate(t *testing.T) {
 	assert.Equal(t, uint64(5), source.stats.bucketObjects[testBucket])
 }
 
+func TestSource_EnumerateTargets(t *testing.T) {
+	ctx := context.Background()
+	source := &Source{gcsManager: &mockObjectManager{numObjects: 5}}
+
+	var targets []sources.Target
+	err := source.EnumerateTargets(ctx, func(target sources.Target) {
+		targets = append(targets, target)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []sources.Target{{Name: testBucket, Objects: 5, Bytes: 1024}}, targets)
+
+	source = &Source{gcsManager: &mockObjectManager{wantErr: true}}
+	assert.Error(t, source.EnumerateTargets(ctx, func(sources.Target) {}))
+}
+
 func TestSourceChunks_ListObjects_Error(t *testing.T) {
 	ctx := context.Background()
 	source := &Source{gcsManager: &mockObjectManager{wantErr: true}}
@@ -462,3 +481,29 @@ func TestSource_CachePersistence(t *testing.T) {
 	assert.Equal(t, int64(100), source.Progress.PercentComplete)
 	assert.Equal(t, fmt.Sprintf("GCS source finished processing %d objects", wantObjCnt), source.Progress.Message)
 }
+
+func TestSourceChunks_StateFile(t *testing.T) {
+	ctx := context.Background()
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	scan := func() int {
+		chunksCh := make(chan *sources.Chunk, 8)
+		source := &Source{
+			gcsManager: &mockObjectManager{numObjects: 4},
+			statePath:  statePath,
+		}
+		assert.Nil(t, source.enumerate(ctx))
+		assert.Nil(t, source.Chunks(ctx, chunksCh))
+		close(chunksCh)
+		return len(chunksCh)
+	}
+
+	assert.Equal(t, 4, scan())
+	data, err := os.ReadFile(statePath)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"objects":["md5hash0","md5hash1","md5hash2","md5hash3"]}`, string(data))
+
+	// The next scan resumes from the state file, and skips the objects
+	// scanned already.
+	assert.Equal(t, 0, scan())
+}
@@ -0,0 +1,498 @@
+package gerrit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// pageSize is the number of projects and changes requested at a time.
+	pageSize = 100
+	// timeLayout is the layout of the timestamps of Gerrit, which are UTC.
+	timeLayout = "2006-01-02 15:04:05.999999999"
+
+	locationCommitMessage = "commit_message"
+	locationComment       = "comment"
+)
+
+// xssiPrefix starts the JSON responses of Gerrit, so that they cannot be
+// included as scripts.
+var xssiPrefix = []byte(")]}'")
+
+// Source scans the patch sets of the changes of the projects of a Gerrit
+// server, and their inline review comments, through its REST API.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	client *http.Client
+	// webURL is the URL of the server, which links are relative to.
+	webURL string
+	// apiURL is the URL of the REST API, below /a/ for authenticated
+	// requests.
+	apiURL    string
+	authorize func(*http.Request)
+	projects  []string
+	query     string
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_GERRIT
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized Gerrit source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+	s.client = common.RetryableHttpClientTimeout(120)
+
+	var conn sourcespb.Gerrit
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	if conn.GetEndpoint() == "" {
+		return errors.New("the endpoint of the server is required")
+	}
+	u, err := url.Parse(conn.GetEndpoint())
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("invalid endpoint %q", conn.GetEndpoint())
+	}
+	s.webURL = strings.TrimSuffix(strings.TrimSuffix(conn.GetEndpoint(), "/"), "/a")
+	s.apiURL = s.webURL
+
+	switch cred := conn.GetCredential().(type) {
+	case *sourcespb.Gerrit_BasicAuth:
+		// The password is the HTTP password generated in the settings of the
+		// user, which only the API below /a/ accepts.
+		s.apiURL = s.webURL + "/a"
+		s.authorize = func(req *http.Request) {
+			req.SetBasicAuth(cred.BasicAuth.GetUsername(), cred.BasicAuth.GetPassword())
+		}
+	default:
+		s.authorize = func(*http.Request) {}
+	}
+
+	s.projects = conn.GetProjects()
+	s.query = conn.GetQuery()
+	return nil
+}
+
+// account is a user of Gerrit.
+type account struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// change is a change with its patch sets.
+type change struct {
+	Number    int64               `json:"_number"`
+	Project   string              `json:"project"`
+	Revisions map[string]revision `json:"revisions"`
+	// MoreChanges is set on the last change of a page when there are more.
+	MoreChanges bool `json:"_more_changes"`
+}
+
+// id returns the ID of the change in the URLs of the REST API.
+func (c change) id() string {
+	return url.PathEscape(c.Project) + "~" + strconv.FormatInt(c.Number, 10)
+}
+
+// revision is a patch set of a change.
+type revision struct {
+	Number   int64   `json:"_number"`
+	Created  string  `json:"created"`
+	Uploader account `json:"uploader"`
+}
+
+// comment is an inline or patch set level comment of a review.
+type comment struct {
+	ID       string  `json:"id"`
+	PatchSet int64   `json:"patch_set"`
+	Message  string  `json:"message"`
+	Updated  string  `json:"updated"`
+	Author   account `json:"author"`
+}
+
+// Chunks emits the chunks of the patch sets and review comments of the
+// changes of the projects.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	projects := s.projects
+	if len(projects) == 0 {
+		var err error
+		if projects, err = s.listProjects(ctx); err != nil {
+			return fmt.Errorf("error listing projects: %w", err)
+		}
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, project := range projects {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(projects), fmt.Sprintf("Project: %s", project), "")
+		project := project
+		s.jobPool.Go(func() error {
+			if err := s.scanProject(ctx, project, scanErrs, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error listing changes of project %s: %w", project, err))
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	s.SetProgressComplete(len(projects), len(projects), "Completed Gerrit scan", "")
+	return nil
+}
+
+// listProjects returns the names of the projects the user can see.
+func (s *Source) listProjects(ctx context.Context) ([]string, error) {
+	var projects []string
+	for skip := 0; ; {
+		var page map[string]json.RawMessage
+		if err := s.get(ctx, fmt.Sprintf("/projects/?n=%d&S=%d", pageSize, skip), &page); err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(page))
+		for name := range page {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		projects = append(projects, names...)
+		skip += len(page)
+		if len(page) < pageSize || common.IsDone(ctx) {
+			return projects, nil
+		}
+	}
+}
+
+// scanProject scans the changes of a project, open, merged and abandoned,
+// which query narrows down.
+func (s *Source) scanProject(ctx context.Context, project string, scanErrs *sources.ScanErrors, chunksChan chan *sources.Chunk) error {
+	q := "project:" + project
+	if s.query != "" {
+		q += " " + s.query
+	}
+	for skip := 0; ; {
+		query := url.Values{
+			"q": {q},
+			"o": {"ALL_REVISIONS", "DETAILED_ACCOUNTS"},
+			"n": {strconv.Itoa(pageSize)},
+			"S": {strconv.Itoa(skip)},
+		}
+		var changes []change
+		if err := s.get(ctx, "/changes/?"+query.Encode(), &changes); err != nil {
+			return err
+		}
+		for _, c := range changes {
+			if common.IsDone(ctx) {
+				return nil
+			}
+			if err := s.scanChange(ctx, c, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error scanning change %d of project %s: %w", c.Number, project, err))
+			}
+		}
+		skip += len(changes)
+		if len(changes) == 0 || !changes[len(changes)-1].MoreChanges {
+			return nil
+		}
+	}
+}
+
+// scanChange scans the patch sets of a change, from the first, and the
+// comments of its reviews. The hunks of a patch set that an earlier one has
+// too are skipped.
+func (s *Source) scanChange(ctx context.Context, c change, chunksChan chan *sources.Chunk) error {
+	commits := make([]string, 0, len(c.Revisions))
+	for commit := range c.Revisions {
+		commits = append(commits, commit)
+	}
+	sort.Slice(commits, func(i, j int) bool { return c.Revisions[commits[i]].Number < c.Revisions[commits[j]].Number })
+
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		if err := s.scanPatchSet(ctx, c, commit, seen, chunksChan); err != nil {
+			return fmt.Errorf("error scanning patch set %d: %w", c.Revisions[commit].Number, err)
+		}
+	}
+
+	var comments map[string][]comment
+	if err := s.get(ctx, "/changes/"+c.id()+"/comments", &comments); err != nil {
+		return fmt.Errorf("error listing comments: %w", err)
+	}
+	commitOf := make(map[int64]string, len(c.Revisions))
+	for commit, rev := range c.Revisions {
+		commitOf[rev.Number] = commit
+	}
+	for file, fileComments := range comments {
+		for _, cm := range fileComments {
+			skel := s.chunkSkeleton(c, commitOf[cm.PatchSet], cm.PatchSet, file, cm.Author.Email, formatTime(cm.Updated))
+			md := skel.SourceMetadata.GetGerrit()
+			md.Location = locationComment + "/" + sanitizer.UTF8(cm.ID)
+			md.Link = fmt.Sprintf("%s/c/%s/+/%d/comment/%s/", s.webURL, c.Project, c.Number, url.PathEscape(cm.ID))
+			if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(cm.Message), chunksChan); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scanPatchSet scans the commit message of a patch set and the lines its
+// diff adds, in hunks that seen does not have.
+func (s *Source) scanPatchSet(ctx context.Context, c change, commit string, seen map[string]bool, chunksChan chan *sources.Chunk) error {
+	res, err := s.do(ctx, s.apiURL+"/changes/"+c.id()+"/revisions/"+commit+"/patch")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	patch, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, res.Body))
+	if err != nil {
+		return fmt.Errorf("error reading patch: %w", err)
+	}
+
+	rev := c.Revisions[commit]
+	email := rev.Uploader.Email
+	message, author, hunks := parsePatch(patch)
+	if author != "" {
+		email = author
+	}
+	timestamp := formatTime(rev.Created)
+
+	if !seen[message] {
+		seen[message] = true
+		skel := s.chunkSkeleton(c, commit, rev.Number, "", email, timestamp)
+		md := skel.SourceMetadata.GetGerrit()
+		md.Location = locationCommitMessage
+		md.Link = fmt.Sprintf("%s/c/%s/+/%d/%d", s.webURL, c.Project, c.Number, rev.Number)
+		if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(message), chunksChan); err != nil {
+			return err
+		}
+	}
+	for _, h := range hunks {
+		key := h.file + "\x00" + h.content
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		skel := s.chunkSkeleton(c, commit, rev.Number, h.file, email, timestamp)
+		md := skel.SourceMetadata.GetGerrit()
+		md.Line = h.line
+		md.Link = fmt.Sprintf("%s/c/%s/+/%d/%d/%s#%d", s.webURL, c.Project, c.Number, rev.Number, escapePath(h.file), h.line)
+		if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(h.content), chunksChan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hunk is what a hunk of a diff adds to a file.
+type hunk struct {
+	file string
+	// line is the line of the file that the hunk starts at.
+	line int64
+	// content is the lines the hunk adds, with empty lines in place of the
+	// lines it keeps, so that its lines are those of the file.
+	content string
+}
+
+// parsePatch returns the commit message of a patch made by git format-patch,
+// the email of its author, and the hunks of its diff that add lines.
+// Removed lines and binary files are skipped.
+func parsePatch(patch []byte) (string, string, []hunk) {
+	header, diff := string(patch), ""
+	if i := strings.Index(header, "\ndiff --git "); i >= 0 {
+		header, diff = header[:i+1], header[i+1:]
+	}
+
+	var author string
+	for _, line := range strings.Split(header, "\n") {
+		if from, ok := strings.CutPrefix(line, "From: "); ok {
+			if addr, err := mail.ParseAddress(from); err == nil {
+				author = addr.Address
+			}
+			break
+		}
+	}
+	// The message starts at the subject, after the commit and the date that
+	// change with each patch set, and the diffstat follows it after a line
+	// of three dashes.
+	message, _, _ := strings.Cut(header, "\n---\n")
+	if i := strings.Index(message, "\nSubject: "); i >= 0 {
+		message = message[i+1:]
+	}
+
+	var (
+		hunks   []hunk
+		file    string
+		current *hunk
+		content strings.Builder
+		inHunk  bool
+	)
+	flush := func() {
+		if current != nil && strings.Trim(content.String(), "\n") != "" {
+			current.content = strings.TrimRight(content.String(), "\n")
+			hunks = append(hunks, *current)
+		}
+		current = nil
+		content.Reset()
+	}
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			inHunk, file = false, ""
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			file = strings.TrimPrefix(strings.TrimRight(line[4:], "\t"), "b/")
+			if file == "/dev/null" {
+				file = ""
+			}
+		case strings.HasPrefix(line, "@@ ") && file != "":
+			flush()
+			// @@ -<start>,<lines> +<start>,<lines> @@
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				inHunk = false
+				continue
+			}
+			start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+			n, err := strconv.ParseInt(start, 10, 64)
+			if err != nil {
+				inHunk = false
+				continue
+			}
+			inHunk = true
+			current = &hunk{file: file, line: n}
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			content.WriteString(line[1:])
+			content.WriteByte('\n')
+		case strings.HasPrefix(line, " "):
+			content.WriteByte('\n')
+		}
+	}
+	flush()
+	return message, author, hunks
+}
+
+// formatTime returns a timestamp of Gerrit in RFC 3339, or as is if it
+// cannot be parsed.
+func formatTime(timestamp string) string {
+	t, err := time.Parse(timeLayout, timestamp)
+	if err != nil {
+		return sanitizer.UTF8(timestamp)
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// escapePath escapes the segments of the path of a file for a URL.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func (s *Source) chunkSkeleton(c change, commit string, patchSet int64, file, email, timestamp string) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Gerrit{
+				Gerrit: &source_metadatapb.Gerrit{
+					Project:   sanitizer.UTF8(c.Project),
+					Change:    c.Number,
+					PatchSet:  patchSet,
+					Commit:    sanitizer.UTF8(commit),
+					File:      sanitizer.UTF8(file),
+					Email:     sanitizer.UTF8(email),
+					Timestamp: timestamp,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// get decodes the JSON response to a request of the REST API.
+func (s *Source) get(ctx context.Context, path string, v any) error {
+	res, err := s.do(ctx, s.apiURL+path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes.TrimPrefix(body, xssiPrefix), v)
+}
+
+func (s *Source) do(ctx context.Context, reqURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.authorize(req)
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+		}
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res, nil
+}
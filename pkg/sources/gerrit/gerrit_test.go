@@ -0,0 +1,219 @@
+package gerrit
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	commit1 = "1111111111111111111111111111111111111111"
+	commit2 = "2222222222222222222222222222222222222222"
+)
+
+// patch returns a patch of git format-patch of a commit.
+func patch(commit, diff string) string {
+	return "From " + commit + ` Mon Sep 17 00:00:00 2001
+From: Ada Lovelace <ada@example.com>
+Date: Tue, 2 Jan 2024 03:04:05 +0000
+Subject: [PATCH] Configure the deploy
+
+Uses the deploy key.
+
+Change-Id: I0123456789abcdef0123456789abcdef01234567
+---
+ deploy/config.yml | 2 +-
+ 1 file changed, 1 insertion(+), 1 deletion(-)
+
+` + diff + `--
+2.43.0
+`
+}
+
+const diff1 = `diff --git a/deploy/config.yml b/deploy/config.yml
+index 0123456..789abcd 100644
+--- a/deploy/config.yml
++++ b/deploy/config.yml
+@@ -10,3 +10,4 @@ deploy:
+   host: prod
+-  user: deploy
++  user: admin
++  password: hunter2
+   port: 22
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..fedcba9
+Binary files /dev/null and b/logo.png differ
+`
+
+// diff2 removes the password again, and adds a file.
+const diff2 = `diff --git a/deploy/config.yml b/deploy/config.yml
+index 0123456..789abcd 100644
+--- a/deploy/config.yml
++++ b/deploy/config.yml
+@@ -10,3 +10,3 @@ deploy:
+   host: prod
+-  user: deploy
++  user: admin
+   port: 22
+diff --git a/deploy/new key.env b/deploy/new key.env
+new file mode 100644
+--- /dev/null
++++ b/deploy/new key.env
+@@ -0,0 +1,2 @@
++# rotated
++KEY=abc123
+`
+
+func server(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "ada" || password != "secret" || !strings.HasPrefix(r.URL.Path, "/a/") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.EscapedPath(), "/a")
+		if strings.HasSuffix(path, "/patch") {
+			diff := diff1
+			if strings.Contains(path, commit2) {
+				diff = diff2
+			}
+			commit := commit1
+			if strings.Contains(path, commit2) {
+				commit = commit2
+			}
+			fmt.Fprint(w, base64.StdEncoding.EncodeToString([]byte(patch(commit, diff))))
+			return
+		}
+		fmt.Fprint(w, ")]}'\n")
+		switch path {
+		case "/projects/":
+			fmt.Fprint(w, `{"platform/infra":{"id":"platform%2Finfra"},"web":{"id":"web"}}`)
+		case "/changes/":
+			q := r.URL.Query()
+			assert.Equal(t, []string{"ALL_REVISIONS", "DETAILED_ACCOUNTS"}, q["o"])
+			switch {
+			case q.Get("q") == "project:web":
+				fmt.Fprint(w, `[]`)
+			case q.Get("S") == "0":
+				fmt.Fprint(w, `[{"_number":41,"project":"platform/infra","revisions":{},"_more_changes":true}]`)
+			default:
+				fmt.Fprintf(w, `[{"_number":42,"project":"platform/infra","revisions":{
+					%q:{"_number":2,"created":"2024-01-03 10:00:00.000000000","uploader":{"name":"Bob","email":"bob@example.com"}},
+					%q:{"_number":1,"created":"2024-01-02 03:04:05.000000000","uploader":{"name":"Ada","email":"ada@example.com"}}}}]`, commit2, commit1)
+			}
+		case "/changes/platform%2Finfra~41/comments":
+			fmt.Fprint(w, `{}`)
+		case "/changes/platform%2Finfra~42/comments":
+			fmt.Fprint(w, `{"deploy/config.yml":[{"id":"c0ffee","patch_set":1,"line":11,"message":"Don't commit AKIAEXAMPLE here","updated":"2024-01-02 05:00:00.000000000","author":{"name":"Carol","email":"carol@example.com"}}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func basicAuth(user, password string) *sourcespb.Gerrit_BasicAuth {
+	return &sourcespb.Gerrit_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: user, Password: password}}
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server := server(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Gerrit{Endpoint: server.URL + "/", Credential: basicAuth("ada", "secret")}, 4)
+
+	chunks := sources.RequireChunks(t, s)
+
+	// Only the first patch set adds the password.
+	password := sources.ChunksWith(chunks, "password: hunter2")
+	require.Len(t, password, 1)
+	assert.Equal(t, &source_metadatapb.Gerrit{
+		Project:   "platform/infra",
+		Change:    42,
+		PatchSet:  1,
+		Commit:    commit1,
+		File:      "deploy/config.yml",
+		Email:     "ada@example.com",
+		Timestamp: "2024-01-02T03:04:05Z",
+		Line:      10,
+		Link:      server.URL + "/c/platform/infra/+/42/1/deploy/config.yml#10",
+	}, password[0].SourceMetadata.GetGerrit())
+	for _, chunk := range chunks {
+		if strings.Contains(string(chunk.Data), "password: hunter2") {
+			assert.Equal(t, "\n  user: admin\n  password: hunter2", string(chunk.Data))
+		}
+		assert.NotContains(t, string(chunk.Data), "user: deploy")
+	}
+
+	key := sources.ChunksWith(chunks, "KEY=abc123")
+	require.Len(t, key, 1)
+	assert.Equal(t, int64(2), key[0].SourceMetadata.GetGerrit().GetPatchSet())
+	assert.Equal(t, commit2, key[0].SourceMetadata.GetGerrit().GetCommit())
+	assert.Equal(t, "deploy/new key.env", key[0].SourceMetadata.GetGerrit().GetFile())
+	assert.Equal(t, server.URL+"/c/platform/infra/+/42/2/deploy/new%20key.env#1", key[0].SourceMetadata.GetGerrit().GetLink())
+
+	// The commit message is the same in both patch sets.
+	message := sources.ChunksWith(chunks, "Uses the deploy key.")
+	require.Len(t, message, 1)
+	assert.Equal(t, locationCommitMessage, message[0].SourceMetadata.GetGerrit().GetLocation())
+	assert.Equal(t, server.URL+"/c/platform/infra/+/42/1", message[0].SourceMetadata.GetGerrit().GetLink())
+
+	comment := sources.ChunksWith(chunks, "AKIAEXAMPLE")
+	require.Len(t, comment, 1)
+	assert.Equal(t, &source_metadatapb.Gerrit{
+		Project:   "platform/infra",
+		Change:    42,
+		PatchSet:  1,
+		Commit:    commit1,
+		File:      "deploy/config.yml",
+		Email:     "carol@example.com",
+		Timestamp: "2024-01-02T05:00:00Z",
+		Location:  "comment/c0ffee",
+		Link:      server.URL + "/c/platform/infra/+/42/comment/c0ffee/",
+	}, comment[0].SourceMetadata.GetGerrit())
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := server(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Gerrit{Endpoint: server.URL, Credential: basicAuth("ada", "wrong")}, 4)
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid credentials")
+}
+
+func TestSource_Init(t *testing.T) {
+	tests := map[string]*sourcespb.Gerrit{
+		"no endpoint":      {},
+		"invalid endpoint": {Endpoint: "gerrit.example.com"},
+	}
+	for name, conn := range tests {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
+
+func Test_parsePatch(t *testing.T) {
+	message, author, hunks := parsePatch([]byte(patch(commit2, diff2)))
+	assert.Equal(t, "Subject: [PATCH] Configure the deploy\n\nUses the deploy key.\n\nChange-Id: I0123456789abcdef0123456789abcdef01234567", message)
+	assert.Equal(t, "ada@example.com", author)
+	assert.Equal(t, []hunk{
+		{file: "deploy/config.yml", line: 10, content: "\n  user: admin"},
+		{file: "deploy/new key.env", line: 1, content: "# rotated\nKEY=abc123"},
+	}, hunks)
+}
@@ -0,0 +1,96 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestParseDiffRange(t *testing.T) {
+	tests := []struct {
+		diffRange  string
+		base, head string
+		wantErr    bool
+	}{
+		{diffRange: "main..feature", base: "main", head: "feature"},
+		{diffRange: "main...feature", base: "main", head: "feature"},
+		{diffRange: "origin/main..", base: "origin/main", head: "HEAD"},
+		{diffRange: "..feature", wantErr: true},
+		{diffRange: "main", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.diffRange, func(t *testing.T) {
+			base, head, err := ParseDiffRange(tt.diffRange)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.base, base)
+			assert.Equal(t, tt.head, head)
+		})
+	}
+}
+
+func TestGit_ScanDiff(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644))
+	}
+
+	run("init", "-q", "-b", "main")
+	write("config.env", "user=admin\nold_secret=unchanged\n")
+	run("add", ".")
+	run("commit", "-qm", "base")
+	run("checkout", "-qb", "feature")
+	write("config.env", "user=admin\nold_secret=unchanged\nnew_secret=added\n")
+	run("commit", "-qam", "feature")
+
+	repo, err := git.PlainOpen(repoPath)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	s := NewGit(sourcespb.SourceType_SOURCE_TYPE_GIT, 0, 0, "test", false, 1,
+		func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Git{
+					Git: &source_metadatapb.Git{File: file, Commit: commit, Line: line},
+				},
+			}
+		})
+	chunksChan := make(chan *sources.Chunk, 10)
+	opts := NewScanOptions(ScanOptionFilter(common.FilterEmpty()), ScanOptionDiffRange("main..feature"))
+	require.NoError(t, s.ScanRepo(ctx, repo, repoPath, opts, chunksChan))
+	close(chunksChan)
+
+	var chunks []*sources.Chunk
+	for chunk := range chunksChan {
+		chunks = append(chunks, chunk)
+	}
+	require.Len(t, chunks, 1)
+	meta := chunks[0].SourceMetadata.GetGit()
+	assert.Equal(t, "config.env", meta.File)
+	assert.Equal(t, head.Hash().String(), meta.Commit)
+	assert.Equal(t, "new_secret=added\n", string(chunks[0].Data))
+	assert.Equal(t, int64(3), meta.Line)
+}
@@ -28,7 +28,9 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/gitparse"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/giturl"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignore"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
@@ -146,6 +148,7 @@ func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64,
 						Repository: sanitizer.UTF8(repository),
 						Timestamp:  sanitizer.UTF8(timestamp),
 						Line:       line,
+						Link:       giturl.GenerateLink(repository, commit, file, line),
 					},
 				},
 			}
@@ -171,6 +174,70 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 	return nil
 }
 
+// EnumerateTargets reports the configured repositories. Local repositories
+// are reported with the number of commits that would be scanned and the size
+// of their objects, remote ones are not cloned and have no size.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	for _, repoURI := range s.conn.Repositories {
+		if repoURI != "" {
+			report(sources.Target{Name: repoURI, Objects: -1, Bytes: -1})
+		}
+	}
+	for _, dir := range s.conn.Directories {
+		if dir != "" {
+			report(repoTarget(ctx, dir, s.scanOptions))
+		}
+	}
+	return nil
+}
+
+// repoTarget describes the local repository at path for dry runs.
+func repoTarget(ctx context.Context, path string, scanOptions *ScanOptions) sources.Target {
+	target := sources.Target{Name: path, Objects: -1, Bytes: -1}
+	bare := scanOptions != nil && scanOptions.Bare
+	if repo, err := RepoFromPath(path, bare); err == nil {
+		if url := getSafeRemoteURL(repo, "origin"); url != "" {
+			target.Name = url
+		}
+	}
+
+	revisions := []string{"--all"}
+	switch {
+	case scanOptions == nil:
+	case scanOptions.StagedOnly:
+		return target
+	case scanOptions.DiffRange != "":
+		revisions = []string{strings.Replace(scanOptions.DiffRange, "...", "..", 1)}
+	case len(scanOptions.Revisions) > 0:
+		revisions = scanOptions.Revisions
+	case scanOptions.HeadHash != "" && scanOptions.BaseHash != "":
+		revisions = []string{scanOptions.BaseHash + ".." + scanOptions.HeadHash}
+	case scanOptions.HeadHash != "":
+		revisions = []string{scanOptions.HeadHash}
+	}
+	args := append([]string{"-C", path, "rev-list", "--count"}, revisions...)
+	if out, err := exec.CommandContext(ctx, "git", args...).Output(); err == nil {
+		target.Objects, _ = strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	} else {
+		ctx.Logger().V(2).Info("could not count commits", "path", path, "error", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "git", "-C", path, "count-objects", "-v").Output()
+	if err != nil {
+		ctx.Logger().V(2).Info("could not get repository size", "path", path, "error", err)
+		return target
+	}
+	target.Bytes = 0
+	for _, line := range strings.Split(string(out), "\n") {
+		key, value, _ := strings.Cut(line, ": ")
+		if key == "size" || key == "size-pack" {
+			kib, _ := strconv.ParseInt(value, 10, 64)
+			target.Bytes += kib * 1024
+		}
+	}
+	return target
+}
+
 // scanRepos scans the configured repositories in s.conn.Repositories.
 func (s *Source) scanRepos(ctx context.Context, chunksChan chan *sources.Chunk) error {
 	if len(s.conn.Repositories) == 0 {
@@ -397,7 +464,13 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 		return err
 	}
 
-	commitChan, err := gitparse.NewParser().RepoPath(ctx, path, scanOptions.HeadHash, scanOptions.BaseHash == "", scanOptions.ExcludeGlobs, scanOptions.Bare)
+	var commitChan chan gitparse.Commit
+	var err error
+	if len(scanOptions.Revisions) > 0 {
+		commitChan, err = gitparse.NewParser().RepoRevisions(ctx, path, scanOptions.Revisions, scanOptions.BaseHash == "", scanOptions.ExcludeGlobs, scanOptions.Bare)
+	} else {
+		commitChan, err = gitparse.NewParser().RepoPath(ctx, path, scanOptions.HeadHash, scanOptions.BaseHash == "", scanOptions.ExcludeGlobs, scanOptions.Bare)
+	}
 	if err != nil {
 		return err
 	}
@@ -407,6 +480,7 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 
 	// get the URL metadata for reporting (may be empty)
 	urlMetadata := getSafeRemoteURL(repo, "origin")
+	ignoreRules := loadIgnoreRules(ctx, repo, scanOptions)
 
 	var depth int64
 
@@ -427,7 +501,7 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 		atomic.AddUint64(&s.metrics.commitsScanned, 1)
 		logger.V(5).Info("scanning commit", "commit", commit.Hash)
 		for _, diff := range commit.Diffs {
-			if !scanOptions.Filter.Pass(diff.PathB) {
+			if !scanOptions.Filter.Pass(diff.PathB) || ignoreRules.IgnoresPath(diff.PathB, false) {
 				continue
 			}
 
@@ -443,22 +517,23 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 			// Handle binary files by reading the entire file rather than using the diff.
 			if diff.IsBinary {
 				commitHash := plumbing.NewHash(hash)
-				metadata := s.sourceMetadataFunc(fileName, email, hash, when, urlMetadata, 0)
+				metadata := s.sourceMetadataFunc(fileName, email, hash, when, urlMetadata, 1)
 				chunkSkel := &sources.Chunk{
 					SourceName:     s.sourceName,
 					SourceID:       s.sourceID,
 					SourceType:     s.sourceType,
 					SourceMetadata: metadata,
 					Verify:         s.verify,
+					IgnoreRules:    ignoreRules,
 				}
-				if err := handleBinary(ctx, repo, chunksChan, chunkSkel, commitHash, fileName); err != nil {
+				if err := handleBinary(ctx, repo, chunksChan, chunkSkel, commitHash, fileName, scanOptions.ArchiveOptions); err != nil {
 					logger.V(1).Info("error handling binary file", "error", err, "filename", fileName, "commit", commitHash, "file", diff.PathB)
 				}
 				continue
 			}
 
 			if diff.Content.Len() > sources.ChunkSize+sources.PeekSize {
-				s.gitChunk(ctx, diff, fileName, email, hash, when, urlMetadata, chunksChan)
+				s.gitChunk(ctx, diff, fileName, email, hash, when, urlMetadata, ignoreRules, chunksChan)
 				continue
 			}
 			metadata := s.sourceMetadataFunc(fileName, email, hash, when, urlMetadata, int64(diff.LineStart))
@@ -469,13 +544,14 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 				SourceMetadata: metadata,
 				Data:           diff.Content.Bytes(),
 				Verify:         s.verify,
+				IgnoreRules:    ignoreRules,
 			}
 		}
 	}
 	return nil
 }
 
-func (s *Git) gitChunk(ctx context.Context, diff gitparse.Diff, fileName, email, hash, when, urlMetadata string, chunksChan chan *sources.Chunk) {
+func (s *Git) gitChunk(ctx context.Context, diff gitparse.Diff, fileName, email, hash, when, urlMetadata string, ignoreRules *ignore.Rules, chunksChan chan *sources.Chunk) {
 	originalChunk := bufio.NewScanner(&diff.Content)
 	newChunkBuffer := bytes.Buffer{}
 	lastOffset := 0
@@ -495,6 +571,7 @@ func (s *Git) gitChunk(ctx context.Context, diff gitparse.Diff, fileName, email,
 					SourceMetadata: metadata,
 					Data:           append([]byte{}, newChunkBuffer.Bytes()...),
 					Verify:         s.verify,
+					IgnoreRules:    ignoreRules,
 				}
 				newChunkBuffer.Reset()
 				lastOffset = offset
@@ -509,6 +586,7 @@ func (s *Git) gitChunk(ctx context.Context, diff gitparse.Diff, fileName, email,
 					SourceMetadata: metadata,
 					Data:           line,
 					Verify:         s.verify,
+					IgnoreRules:    ignoreRules,
 				}
 				continue
 			}
@@ -528,6 +606,7 @@ func (s *Git) gitChunk(ctx context.Context, diff gitparse.Diff, fileName, email,
 			SourceMetadata: metadata,
 			Data:           append([]byte{}, newChunkBuffer.Bytes()...),
 			Verify:         s.verify,
+			IgnoreRules:    ignoreRules,
 		}
 	}
 }
@@ -536,6 +615,7 @@ func (s *Git) gitChunk(ctx context.Context, diff gitparse.Diff, fileName, email,
 func (s *Git) ScanStaged(ctx context.Context, repo *git.Repository, path string, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) error {
 	// Get the URL metadata for reporting (may be empty).
 	urlMetadata := getSafeRemoteURL(repo, "origin")
+	ignoreRules := loadIgnoreRules(ctx, repo, scanOptions)
 
 	commitChan, err := gitparse.NewParser().Staged(ctx, path)
 	if err != nil {
@@ -545,14 +625,63 @@ func (s *Git) ScanStaged(ctx context.Context, repo *git.Repository, path string,
 		return nil
 	}
 
+	ctx.Logger().V(1).Info("scanning staged changes", "path", path)
+	s.scanDiffs(ctx, repo, commitChan, "Staged", plumbing.ZeroHash, urlMetadata, ignoreRules, scanOptions, chunksChan)
+	return nil
+}
+
+// ScanDiff scans only the lines added between the two revisions of
+// scanOptions.DiffRange. Findings are reported at their line in the head
+// revision.
+func (s *Git) ScanDiff(ctx context.Context, repo *git.Repository, path string, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) error {
+	_, head, err := ParseDiffRange(scanOptions.DiffRange)
+	if err != nil {
+		return err
+	}
+	headHash, err := repo.ResolveRevision(plumbing.Revision(head))
+	if err != nil {
+		return fmt.Errorf("could not resolve %s: %w", head, err)
+	}
+	urlMetadata := getSafeRemoteURL(repo, "origin")
+	headOptions := *scanOptions
+	headOptions.HeadHash = headHash.String()
+	ignoreRules := loadIgnoreRules(ctx, repo, &headOptions)
+
+	commitChan, err := gitparse.NewParser().Diff(ctx, path, scanOptions.DiffRange)
+	if err != nil {
+		return err
+	}
+
+	ctx.Logger().V(1).Info("scanning diff", "path", path, "range", scanOptions.DiffRange)
+	s.scanDiffs(ctx, repo, commitChan, headHash.String(), *headHash, urlMetadata, ignoreRules, scanOptions, chunksChan)
+	return nil
+}
+
+// ParseDiffRange splits a range like `base..head` or `base...head` into its
+// revisions. The head defaults to HEAD.
+func ParseDiffRange(diffRange string) (base, head string, err error) {
+	base, head, found := strings.Cut(diffRange, "..")
+	if !found || base == "" {
+		return "", "", fmt.Errorf("invalid diff range %q, expected base..head", diffRange)
+	}
+	head = strings.TrimPrefix(head, ".")
+	if head == "" {
+		head = "HEAD"
+	}
+	return base, head, nil
+}
+
+// scanDiffs sends the added lines of the diffs from commitChan, which come
+// from `git diff` rather than `git log`, as chunks attributed to commit. Binary
+// files are read from the tree of blobCommit.
+func (s *Git) scanDiffs(ctx context.Context, repo *git.Repository, commitChan chan gitparse.Commit, commitName string, blobCommit plumbing.Hash, urlMetadata string, ignoreRules *ignore.Rules, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) {
 	var depth int64
 	reachedBase := false
 
-	ctx.Logger().V(1).Info("scanning staged changes", "path", path)
 	for commit := range commitChan {
 		for _, diff := range commit.Diffs {
 			logger := ctx.Logger().WithValues("filename", diff.PathB, "commit", commit.Hash, "file", diff.PathB)
-			logger.V(2).Info("scanning staged changes from git")
+			logger.V(2).Info("scanning diff from git")
 
 			if scanOptions.MaxDepth > 0 && depth >= scanOptions.MaxDepth {
 				logger.V(1).Info("reached max depth")
@@ -569,7 +698,7 @@ func (s *Git) ScanStaged(ctx context.Context, repo *git.Repository, path string,
 				}
 			}
 
-			if !scanOptions.Filter.Pass(diff.PathB) {
+			if !scanOptions.Filter.Pass(diff.PathB) || ignoreRules.IgnoresPath(diff.PathB, false) {
 				continue
 			}
 
@@ -577,29 +706,28 @@ func (s *Git) ScanStaged(ctx context.Context, repo *git.Repository, path string,
 			if fileName == "" {
 				continue
 			}
-			var email, hash, when string
+			var email, when string
 			email = commit.Author
-			hash = commit.Hash
 			when = commit.Date.UTC().Format("2006-01-02 15:04:05 -0700")
 
 			// Handle binary files by reading the entire file rather than using the diff.
 			if diff.IsBinary {
-				commitHash := plumbing.NewHash(hash)
-				metadata := s.sourceMetadataFunc(fileName, email, "Staged", when, urlMetadata, 0)
+				metadata := s.sourceMetadataFunc(fileName, email, commitName, when, urlMetadata, 1)
 				chunkSkel := &sources.Chunk{
 					SourceName:     s.sourceName,
 					SourceID:       s.sourceID,
 					SourceType:     s.sourceType,
 					SourceMetadata: metadata,
 					Verify:         s.verify,
+					IgnoreRules:    ignoreRules,
 				}
-				if err := handleBinary(ctx, repo, chunksChan, chunkSkel, commitHash, fileName); err != nil {
+				if err := handleBinary(ctx, repo, chunksChan, chunkSkel, blobCommit, fileName, scanOptions.ArchiveOptions); err != nil {
 					logger.V(1).Info("error handling binary file", "error", err, "filename", fileName)
 				}
 				continue
 			}
 
-			metadata := s.sourceMetadataFunc(fileName, email, "Staged", when, urlMetadata, int64(diff.LineStart))
+			metadata := s.sourceMetadataFunc(fileName, email, commitName, when, urlMetadata, int64(diff.LineStart))
 			chunksChan <- &sources.Chunk{
 				SourceName:     s.sourceName,
 				SourceID:       s.sourceID,
@@ -607,10 +735,10 @@ func (s *Git) ScanStaged(ctx context.Context, repo *git.Repository, path string,
 				SourceMetadata: metadata,
 				Data:           diff.Content.Bytes(),
 				Verify:         s.verify,
+				IgnoreRules:    ignoreRules,
 			}
 		}
 	}
-	return nil
 }
 
 func (s *Git) ScanRepo(ctx context.Context, repo *git.Repository, repoPath string, scanOptions *ScanOptions, chunksChan chan *sources.Chunk) error {
@@ -622,6 +750,12 @@ func (s *Git) ScanRepo(ctx context.Context, repo *git.Repository, repoPath strin
 	}
 	start := time.Now().Unix()
 
+	if scanOptions.StagedOnly {
+		return s.ScanStaged(ctx, repo, repoPath, scanOptions, chunksChan)
+	}
+	if scanOptions.DiffRange != "" {
+		return s.ScanDiff(ctx, repo, repoPath, scanOptions, chunksChan)
+	}
 	if err := s.ScanCommits(ctx, repo, repoPath, scanOptions, chunksChan); err != nil {
 		return err
 	}
@@ -644,6 +778,44 @@ func (s *Git) ScanRepo(ctx context.Context, repo *git.Repository, repoPath strin
 	return nil
 }
 
+// loadIgnoreRules reads the ignore file committed at the head of the scan, or
+// HEAD if no head commit is configured. It returns nil if there is no ignore
+// file or it could not be read.
+func loadIgnoreRules(ctx context.Context, repo *git.Repository, scanOptions *ScanOptions) *ignore.Rules {
+	if scanOptions.SkipIgnoreFile {
+		return nil
+	}
+	rev := scanOptions.HeadHash
+	if rev == "" {
+		rev = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil
+	}
+	file, err := commit.File(ignore.FileName)
+	if err != nil {
+		// Most repositories don't have an ignore file.
+		return nil
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		ctx.Logger().Error(err, "could not read ignore file")
+		return nil
+	}
+	defer reader.Close()
+	rules, err := ignore.Parse(reader)
+	if err != nil {
+		ctx.Logger().Error(err, "could not parse ignore file, scanning without it")
+		return nil
+	}
+	return rules
+}
+
 func normalizeConfig(scanOptions *ScanOptions, repo *git.Repository) (err error) {
 	var baseCommit *object.Commit
 	if len(scanOptions.BaseHash) > 0 {
@@ -693,26 +865,17 @@ func normalizeConfig(scanOptions *ScanOptions, repo *git.Repository) (err error)
 	return nil
 }
 
-// GenerateLink crafts a link to the specific file from a commit. This works in most major git providers (Github/Gitlab)
+// GenerateLink crafts a link to the specific file from a commit. Repositories
+// on GitHub, GitLab, Bitbucket and Gerrit get a permalink to the line, others
+// a link in the format most git providers use.
 func GenerateLink(repo, commit, file string, line int64) string {
-	// bitbucket links are commits not commit...
-	if strings.Contains(repo, "bitbucket.org/") {
-		return repo[:len(repo)-4] + "/commits/" + commit
+	if link := giturl.GenerateLink(repo, commit, file, line); link != "" {
+		return link
 	}
-	var link string
 	if file == "" {
-		link = repo[:len(repo)-4] + "/commit/" + commit
-	} else {
-		link = repo[:len(repo)-4] + "/blob/" + commit + "/" + file
-
-		// Both GitHub and Gitlab support hyperlinking to a specific line with #L<number>, e.g.:
-		// https://github.com/trufflesecurity/trufflehog/blob/e856a6890d0da5a218f4f9283500b80043884641/go.mod#L169
-		// https://gitlab.com/pdftk-java/pdftk/-/blob/88559a08f34175b6fae76c40a88f0377f64a12d7/java/com/gitlab/pdftk_java/report.java#L893
-		if line > 0 && (strings.Contains(repo, "github") || strings.Contains(repo, "gitlab")) {
-			link += "#L" + strconv.FormatInt(line, 10)
-		}
+		return strings.TrimSuffix(repo, ".git") + "/commit/" + commit
 	}
-	return link
+	return strings.TrimSuffix(repo, ".git") + "/blob/" + commit + "/" + file
 }
 
 func stripPassword(u string) (string, error) {
@@ -896,7 +1059,7 @@ func getSafeRemoteURL(repo *git.Repository, preferred string) string {
 	return safeURL
 }
 
-func handleBinary(ctx context.Context, repo *git.Repository, chunksChan chan *sources.Chunk, chunkSkel *sources.Chunk, commitHash plumbing.Hash, path string) error {
+func handleBinary(ctx context.Context, repo *git.Repository, chunksChan chan *sources.Chunk, chunkSkel *sources.Chunk, commitHash plumbing.Hash, path string, archiveOptions sources.ArchiveOptions) error {
 	ctx.Logger().V(5).Info("handling binary file", "path", path)
 	commit, err := repo.CommitObject(commitHash)
 	if err != nil {
@@ -920,7 +1083,7 @@ func handleBinary(ctx context.Context, repo *git.Repository, chunksChan chan *so
 	}
 	defer reader.Close()
 
-	if handlers.HandleFile(ctx, reader, chunkSkel, chunksChan) {
+	if handlers.HandleFile(ctx, reader, chunkSkel, chunksChan, handlers.WithArchiveOptions(archiveOptions)) {
 		return nil
 	}
 
@@ -930,11 +1093,12 @@ func handleBinary(ctx context.Context, repo *git.Repository, chunksChan chan *so
 	}
 	reader.Stop()
 
-	chunkReader := sources.NewChunkReader()
+	chunkReader := sources.NewChunkReader(archiveOptions.ChunkOptions()...)
 	chunkResChan := chunkReader(ctx, reader)
 	for data := range chunkResChan {
 		chunk := *chunkSkel
 		chunk.Data = data.Bytes()
+		chunk.Offset, chunk.LineStart, chunk.LineOffset = data.Offset(), data.LineStart(), data.LineOffset()
 		if err := data.Error(); err != nil {
 			return err
 		}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// ErrHookExists is returned by InstallHook if the hook already exists and
+// should not be replaced.
+var ErrHookExists = errors.New("hook already exists")
+
+// InstallHook writes a hook script named name, e.g. pre-commit, to the hooks
+// directory of the repository at repoPath and returns its path. The hooks
+// directory is resolved by git, so core.hooksPath and worktrees are honored.
+// An existing hook is only replaced if force is set.
+func InstallHook(ctx context.Context, repoPath, name, script string, force bool) (string, error) {
+	if err := GitCmdCheck(); err != nil {
+		return "", err
+	}
+	out, err := exec.CommandContext(ctx, "git", "-C", repoPath, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("could not find hooks directory of %s: %w", repoPath, err)
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoPath, dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil && !force {
+		return path, ErrHookExists
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return "", err
+	}
+	// WriteFile keeps the mode of existing files.
+	return path, os.Chmod(path, 0755)
+}
+
+// HookScript returns a shell script for a hook that runs the given command
+// with the arguments git passes to the hook.
+func HookScript(command ...string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return "#!/bin/sh\n# Installed by trufflehog.\nexec " + strings.Join(quoted, " ") + " \"$@\"\n"
+}
+
+// PreReceiveRevisions parses the `<old> <new> <ref>` lines a pre-receive hook
+// reads from stdin and returns the git log revision arguments selecting only
+// the pushed commits, i.e. those reachable from the new tips but from no
+// existing ref. It returns nil if the push only deletes refs.
+func PreReceiveRevisions(r io.Reader) ([]string, error) {
+	var revisions []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid ref update %q", line)
+		}
+		if strings.Trim(fields[1], "0") == "" {
+			continue
+		}
+		revisions = append(revisions, fields[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	return append(revisions, "--not", "--all"), nil
+}
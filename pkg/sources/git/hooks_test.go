@@ -0,0 +1,65 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestInstallHook(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", repoPath).Run())
+
+	script := HookScript("/opt/trufflehog's bin/trufflehog", "precommit")
+	assert.Equal(t, "#!/bin/sh\n# Installed by trufflehog.\nexec '/opt/trufflehog'\\''s bin/trufflehog' 'precommit' \"$@\"\n", script)
+
+	path, err := InstallHook(ctx, repoPath, "pre-commit", script, false)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(repoPath, ".git", "hooks", "pre-commit"), path)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	_, err = InstallHook(ctx, repoPath, "pre-commit", "#!/bin/sh\n", false)
+	assert.ErrorIs(t, err, ErrHookExists)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, script, string(data))
+
+	_, err = InstallHook(ctx, repoPath, "pre-commit", "#!/bin/sh\n", true)
+	require.NoError(t, err)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\n", string(data))
+}
+
+func TestPreReceiveRevisions(t *testing.T) {
+	input := strings.Join([]string{
+		"1111111111111111111111111111111111111111 2222222222222222222222222222222222222222 refs/heads/main",
+		"0000000000000000000000000000000000000000 3333333333333333333333333333333333333333 refs/heads/feature",
+		"4444444444444444444444444444444444444444 0000000000000000000000000000000000000000 refs/heads/old",
+		"",
+	}, "\n")
+	revisions, err := PreReceiveRevisions(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"2222222222222222222222222222222222222222",
+		"3333333333333333333333333333333333333333",
+		"--not", "--all",
+	}, revisions)
+
+	revisions, err = PreReceiveRevisions(strings.NewReader("4444444444444444444444444444444444444444 0000000000000000000000000000000000000000 refs/heads/old\n"))
+	require.NoError(t, err)
+	assert.Nil(t, revisions)
+
+	_, err = PreReceiveRevisions(strings.NewReader("garbage\n"))
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package git
 import (
 	"github.com/go-git/go-git/v5"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
 type ScanOptions struct {
@@ -13,10 +14,30 @@ type ScanOptions struct {
 	Bare         bool
 	ExcludeGlobs []string
 	LogOptions   *git.LogOptions
+	// SkipIgnoreFile disables honoring the .trufflehogignore file committed
+	// in the repository.
+	SkipIgnoreFile bool
+	// StagedOnly scans only the changes staged for commit, not the history.
+	StagedOnly bool
+	// Revisions are passed to git log instead of HeadHash, e.g. to scan only
+	// the commits of a push with `<new> --not --all`.
+	Revisions []string
+	// DiffRange scans only the lines added between two revisions, e.g.
+	// `main..HEAD`, instead of the history.
+	DiffRange string
+	// ArchiveOptions configures how archives committed to the repository
+	// are extracted.
+	ArchiveOptions sources.ArchiveOptions
 }
 
 type ScanOption func(*ScanOptions)
 
+func ScanOptionArchiveOptions(opts sources.ArchiveOptions) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.ArchiveOptions = opts
+	}
+}
+
 func ScanOptionFilter(filter *common.Filter) ScanOption {
 	return func(scanOptions *ScanOptions) {
 		scanOptions.Filter = filter
@@ -59,6 +80,30 @@ func ScanOptionBare(bare bool) ScanOption {
 	}
 }
 
+func ScanOptionSkipIgnoreFile(skip bool) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.SkipIgnoreFile = skip
+	}
+}
+
+func ScanOptionStagedOnly(stagedOnly bool) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.StagedOnly = stagedOnly
+	}
+}
+
+func ScanOptionRevisions(revisions []string) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.Revisions = revisions
+	}
+}
+
+func ScanOptionDiffRange(diffRange string) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.DiffRange = diffRange
+	}
+}
+
 func NewScanOptions(options ...ScanOption) *ScanOptions {
 	scanOptions := &ScanOptions{
 		Filter:   common.FilterEmpty(),
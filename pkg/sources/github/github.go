@@ -131,6 +131,14 @@ func (r *repoSize) getRepo(repo string) int {
 	return r.repoSizes[repo]
 }
 
+// lookupRepo returns the size of the repo, and whether it is known.
+func (r *repoSize) lookupRepo(repo string) (int, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	size, ok := r.repoSizes[repo]
+	return size, ok
+}
+
 func newRepoSize() repoSize {
 	return repoSize{repoSizes: make(map[string]int)}
 }
@@ -352,12 +360,7 @@ func (s *Source) visibilityOf(ctx context.Context, repoURL string) (visibility s
 
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
-	apiEndpoint := s.conn.Endpoint
-	if len(apiEndpoint) == 0 || endsWithGithub.MatchString(apiEndpoint) {
-		apiEndpoint = "https://api.github.com"
-	}
-
-	installationClient, err := s.enumerate(ctx, apiEndpoint)
+	installationClient, err := s.enumerate(ctx, s.apiEndpoint())
 	if err != nil {
 		return err
 	}
@@ -365,6 +368,30 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 	return s.scan(ctx, installationClient, chunksChan)
 }
 
+// EnumerateTargets reports the repositories that would be scanned, with their
+// size as reported by GitHub.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	if _, err := s.enumerate(ctx, s.apiEndpoint()); err != nil {
+		return err
+	}
+	for _, repoURL := range s.repos {
+		target := sources.Target{Name: repoURL, Objects: -1, Bytes: -1}
+		// GitHub reports sizes in kilobytes.
+		if size, ok := s.repoSizes.lookupRepo(repoURL); ok {
+			target.Bytes = int64(size) * 1024
+		}
+		report(target)
+	}
+	return nil
+}
+
+func (s *Source) apiEndpoint() string {
+	if len(s.conn.Endpoint) == 0 || endsWithGithub.MatchString(s.conn.Endpoint) {
+		return "https://api.github.com"
+	}
+	return s.conn.Endpoint
+}
+
 func (s *Source) enumerate(ctx context.Context, apiEndpoint string) (*github.Client, error) {
 	var (
 		installationClient *github.Client
@@ -1179,7 +1206,7 @@ func (s *Source) chunkGistComments(ctx context.Context, gistUrl string, comments
 			SourceMetadata: &source_metadatapb.MetaData{
 				Data: &source_metadatapb.MetaData_Github{
 					Github: &source_metadatapb.Github{
-						Link:       sanitizer.UTF8(comment.GetURL()),
+						Link:       sanitizer.UTF8(strings.TrimSuffix(gistUrl, ".git") + "#gistcomment-" + strconv.FormatInt(comment.GetID(), 10)),
 						Username:   sanitizer.UTF8(comment.GetUser().GetLogin()),
 						Email:      sanitizer.UTF8(comment.GetUser().GetEmail()),
 						Repository: sanitizer.UTF8(gistUrl),
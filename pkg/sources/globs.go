@@ -0,0 +1,47 @@
+package sources
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// Globs are compiled glob patterns, like the include and exclude globs of a
+// source's connection.
+type Globs []glob.Glob
+
+// CompileGlobs compiles the patterns. The wildcards of the globs don't match
+// the separators, e.g. '/' for paths, so * matches within a path segment and
+// ** across segments. Without separators, * matches anything. Invalid
+// patterns are left out of the globs, and returned as an error.
+func CompileGlobs(patterns []string, separators ...rune) (Globs, error) {
+	globs := make(Globs, 0, len(patterns))
+	var errs []error
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, separators...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid glob %q: %w", pattern, err))
+			continue
+		}
+		globs = append(globs, g)
+	}
+	return globs, errors.Join(errs...)
+}
+
+// Match reports whether any of the globs matches s.
+func (g Globs) Match(s string) bool {
+	for _, gl := range g {
+		if gl.Match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGlob reports whether the pattern has any glob syntax, so that names
+// without it can be looked up directly rather than matched against a listing.
+func IsGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[{")
+}
@@ -0,0 +1,35 @@
+package sources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileGlobs(t *testing.T) {
+	globs, err := CompileGlobs([]string{"libs-*/**/*.jar", "docs/*"}, '/')
+	require.NoError(t, err)
+	assert.True(t, globs.Match("libs-release/com/acme/app.jar"))
+	assert.True(t, globs.Match("docs/index.md"))
+	assert.False(t, globs.Match("docs/api/index.md"))
+	assert.False(t, globs.Match("libs-release/app.war"))
+
+	globs, err = CompileGlobs([]string{"prod-*"})
+	require.NoError(t, err)
+	assert.True(t, globs.Match("prod-eu/payments"))
+
+	globs, err = CompileGlobs([]string{"[a", "*.log", "[z"})
+	assert.ErrorContains(t, err, `invalid glob "[a"`)
+	assert.ErrorContains(t, err, `invalid glob "[z"`)
+	assert.True(t, globs.Match("app.log"))
+
+	assert.False(t, Globs(nil).Match("anything"))
+}
+
+func TestIsGlob(t *testing.T) {
+	assert.True(t, IsGlob("orders-*"))
+	assert.True(t, IsGlob("/aws/lambda/{a,b}"))
+	assert.False(t, IsGlob("orders"))
+	assert.False(t, IsGlob("team/app"))
+}
@@ -0,0 +1,552 @@
+package googledrive
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultMaxObjectSize = 250 * 1024 * 1024 // 250 MiB
+	maxObjectSizeLimit   = 250 * 1024 * 1024 // 250 MiB
+
+	folderMimeType = "application/vnd.google-apps.folder"
+	// googleAppsPrefix is the prefix of the MIME types of the files of Google
+	// Workspace, like Docs and Sheets, which have no content of their own and
+	// are exported.
+	googleAppsPrefix = "application/vnd.google-apps."
+
+	// fileFields are the fields of the files listed.
+	fileFields = "id,name,mimeType,size,webViewLink,modifiedTime,shared,owners(emailAddress),lastModifyingUser(emailAddress),parents,driveId,trashed"
+)
+
+// exportTypes are the MIME types the files of Google Workspace are exported
+// as, by their own MIME type. Spreadsheets export the first sheet only, as
+// CSV is the only text format they export to.
+var exportTypes = map[string]string{
+	googleAppsPrefix + "document":     "text/plain",
+	googleAppsPrefix + "spreadsheet":  "text/csv",
+	googleAppsPrefix + "presentation": "text/plain",
+	googleAppsPrefix + "script":       googleAppsPrefix + "script+json",
+}
+
+// errExportTooLarge is returned for the files of Google Workspace too large
+// to be exported.
+var errExportTooLarge = errors.New("file too large to be exported")
+
+// Source scans the files of Google Drive and of shared drives that the
+// credentials can access, exporting the files of Google Workspace as text.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn          *sourcespb.GoogleDrive
+	service       *drive.Service
+	maxObjectSize int64
+	// folders caches the paths of folders by ID.
+	foldersMu sync.Mutex
+	folders   map[string]string
+	// state is read from and saved to the state path of the connection.
+	stateMu sync.Mutex
+	state   state
+	// archiveOptions configures how the archives of files are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// state is what a scan saves for the next one to only scan the files that
+// changed since.
+type state struct {
+	// PageTokens are the tokens of the changes API from which the next scan
+	// lists changes, by shared drive ID, or empty for all the files the
+	// credentials can access.
+	PageTokens map[string]string `json:"page_tokens"`
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_GOOGLE_DRIVE
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of files are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized Google Drive source.
+func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.GoogleDrive
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	s.maxObjectSize = conn.GetMaxObjectSize()
+	if s.maxObjectSize <= 0 || s.maxObjectSize > maxObjectSizeLimit {
+		s.maxObjectSize = defaultMaxObjectSize
+	}
+
+	opts, err := clientOptions(&conn)
+	if err != nil {
+		return err
+	}
+	if endpoint := conn.GetEndpoint(); endpoint != "" {
+		opts = append(opts, option.WithEndpoint(strings.TrimSuffix(endpoint, "/")+"/"))
+	}
+	if s.service, err = drive.NewService(aCtx, opts...); err != nil {
+		return fmt.Errorf("error creating Drive client: %w", err)
+	}
+
+	s.folders = make(map[string]string)
+	s.state = state{PageTokens: map[string]string{}}
+	return nil
+}
+
+// clientOptions returns the options of the client of the Drive API that
+// authenticate with the credentials of a connection.
+func clientOptions(conn *sourcespb.GoogleDrive) ([]option.ClientOption, error) {
+	// Tokens are requested with the retrying client.
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, common.RetryableHttpClientTimeout(120))
+	switch cred := conn.Credential.(type) {
+	case *sourcespb.GoogleDrive_JsonServiceAccount:
+		return serviceAccountOptions(tokenCtx, []byte(cred.JsonServiceAccount), conn.GetImpersonateUser())
+	case *sourcespb.GoogleDrive_ServiceAccountFile:
+		data, err := os.ReadFile(cred.ServiceAccountFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading service account file: %w", err)
+		}
+		return serviceAccountOptions(tokenCtx, data, conn.GetImpersonateUser())
+	case *sourcespb.GoogleDrive_Oauth:
+		if cred.Oauth.GetAccessToken() == "" && (cred.Oauth.GetRefreshToken() == "" || cred.Oauth.GetClientId() == "") {
+			return nil, errors.New("oauth2 credentials are incomplete, an access token, or a client ID and refresh token, are required")
+		}
+		config := oauth2.Config{
+			ClientID:     cred.Oauth.GetClientId(),
+			ClientSecret: cred.Oauth.GetClientSecret(),
+			Endpoint:     endpoints.Google,
+			Scopes:       []string{drive.DriveReadonlyScope},
+		}
+		token := &oauth2.Token{AccessToken: cred.Oauth.GetAccessToken(), RefreshToken: cred.Oauth.GetRefreshToken()}
+		return []option.ClientOption{option.WithHTTPClient(config.Client(tokenCtx, token))}, nil
+	case *sourcespb.GoogleDrive_Adc:
+		return []option.ClientOption{option.WithScopes(drive.DriveReadonlyScope)}, nil
+	case *sourcespb.GoogleDrive_RefreshToken:
+		return nil, errors.New("a refresh token needs the ID of its OAuth client, use oauth credentials instead")
+	default:
+		return nil, errors.New("credentials are required")
+	}
+}
+
+// serviceAccountOptions returns the options of a client authenticating as a
+// service account, or as the user it impersonates with domain-wide
+// delegation.
+func serviceAccountOptions(ctx context.Context, data []byte, user string) ([]option.ClientOption, error) {
+	config, err := google.JWTConfigFromJSON(data, drive.DriveReadonlyScope)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing service account: %w", err)
+	}
+	config.Subject = user
+	return []option.ClientOption{option.WithHTTPClient(config.Client(ctx))}, nil
+}
+
+// corpora returns the IDs of the shared drives of the connection, or the
+// empty ID of all the files the credentials can access.
+func (s *Source) corpora() []string {
+	if ids := s.conn.GetDriveIds(); len(ids) > 0 {
+		return ids
+	}
+	return []string{""}
+}
+
+// Chunks emits the chunks of the files of the drives, or of those that
+// changed since the last scan if there is one.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	if err := s.loadState(); err != nil {
+		return err
+	}
+
+	corpora := s.corpora()
+	var fileCount uint64
+	for i, driveID := range corpora {
+		if common.IsDone(ctx) {
+			return nil
+		}
+		if driveID == "" {
+			s.SetProgressComplete(i, len(corpora), "Drive: all files", "")
+		} else {
+			s.SetProgressComplete(i, len(corpora), fmt.Sprintf("Drive: %s", driveID), "")
+		}
+
+		s.stateMu.Lock()
+		pageToken, incremental := s.state.PageTokens[driveID]
+		s.stateMu.Unlock()
+		// The token of the next scan is taken before listing, so that the
+		// files changed while scanning are scanned again next time.
+		var err error
+		nextToken := pageToken
+		if !incremental {
+			if nextToken, err = s.startPageToken(ctx, driveID); err != nil {
+				return err
+			}
+		}
+
+		scanErrs := sources.NewScanErrors()
+		scan := func(f *drive.File) {
+			if reason, skip := s.skipReason(f); skip {
+				if reason != "" {
+					ctx.Logger().V(5).Info("Skipping file", "file", f.Name, "reason", reason)
+					sources.ReportSkipBytes(ctx, f.Name, reason, f.Size)
+				}
+				return
+			}
+			s.jobPool.Go(func() error {
+				defer common.RecoverWithExit(ctx)
+				err := s.scanFile(ctx, f, chunksChan)
+				if errors.Is(err, errExportTooLarge) {
+					sources.ReportSkip(ctx, f.Name, sources.SkipReasonSize)
+					return nil
+				}
+				if err != nil {
+					scanErrs.Add(fmt.Errorf("could not scan file %s: %w", f.Id, err))
+					sources.ReportSkip(ctx, f.Name, sources.SkipReasonError)
+					return nil
+				}
+				atomic.AddUint64(&fileCount, 1)
+				return nil
+			})
+		}
+		if incremental {
+			nextToken, err = s.listChanges(ctx, driveID, pageToken, scan)
+		} else {
+			err = s.listFiles(ctx, driveID, scan)
+		}
+		_ = s.jobPool.Wait()
+		if err != nil {
+			return err
+		}
+
+		if scanErrs.Count() > 0 {
+			// The drive is scanned from the same changes next time.
+			ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+			continue
+		}
+		s.stateMu.Lock()
+		s.state.PageTokens[driveID] = nextToken
+		s.stateMu.Unlock()
+	}
+	s.SetProgressComplete(len(corpora), len(corpora), fmt.Sprintf("Completed scanning source %s. %d files scanned.", s.name, fileCount), "")
+	return s.saveState()
+}
+
+// EnumerateTargets reports each drive with the number and size of the files
+// that would be scanned. The size of the files of Google Workspace is
+// unknown until they are exported.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	for _, driveID := range s.corpora() {
+		target := sources.Target{Name: driveID}
+		if driveID == "" {
+			target.Name = "all files"
+		}
+		err := s.listFiles(ctx, driveID, func(f *drive.File) {
+			if _, skip := s.skipReason(f); skip {
+				return
+			}
+			target.Objects++
+			target.Bytes += f.Size
+		})
+		if err != nil {
+			return err
+		}
+		report(target)
+	}
+	return nil
+}
+
+// listFiles calls fn with each file of a shared drive, or of all the drives
+// if its ID is empty.
+func (s *Source) listFiles(ctx context.Context, driveID string, fn func(*drive.File)) error {
+	call := s.service.Files.List().
+		Q("trashed = false").
+		SupportsAllDrives(true).
+		IncludeItemsFromAllDrives(true).
+		Fields(googleapi.Field("nextPageToken,files(" + fileFields + ")"))
+	if driveID != "" {
+		call = call.Corpora("drive").DriveId(driveID)
+	} else {
+		call = call.Corpora("allDrives")
+	}
+	err := call.Pages(ctx, func(page *drive.FileList) error {
+		for _, f := range page.Files {
+			if common.IsDone(ctx) {
+				return ctx.Err()
+			}
+			fn(f)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not list files: %w", apiError(err))
+	}
+	return nil
+}
+
+// listChanges calls fn with each file of a shared drive, or of all the
+// drives, that changed since a page token, and returns the token of the
+// next changes.
+func (s *Source) listChanges(ctx context.Context, driveID, pageToken string, fn func(*drive.File)) (string, error) {
+	for {
+		call := s.service.Changes.List(pageToken).
+			Context(ctx).
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true).
+			Fields(googleapi.Field("nextPageToken,newStartPageToken,changes(fileId,removed,file(" + fileFields + "))"))
+		if driveID != "" {
+			call = call.DriveId(driveID)
+		}
+		page, err := call.Do()
+		if err != nil {
+			return "", fmt.Errorf("could not list changes: %w", apiError(err))
+		}
+		for _, change := range page.Changes {
+			if common.IsDone(ctx) {
+				return "", ctx.Err()
+			}
+			if change.Removed || change.File == nil || change.File.Trashed {
+				continue
+			}
+			fn(change.File)
+		}
+		if page.NextPageToken == "" {
+			return page.NewStartPageToken, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// startPageToken returns the page token of the changes from now on of a
+// shared drive, or of all the drives.
+func (s *Source) startPageToken(ctx context.Context, driveID string) (string, error) {
+	call := s.service.Changes.GetStartPageToken().Context(ctx).SupportsAllDrives(true)
+	if driveID != "" {
+		call = call.DriveId(driveID)
+	}
+	token, err := call.Do()
+	if err != nil {
+		return "", fmt.Errorf("could not get the start page token of changes: %w", apiError(err))
+	}
+	return token.StartPageToken, nil
+}
+
+// skipReason returns whether a file is skipped, and why if it is reported.
+func (s *Source) skipReason(f *drive.File) (sources.SkipReason, bool) {
+	if strings.HasPrefix(f.MimeType, googleAppsPrefix) {
+		switch _, ok := exportTypes[f.MimeType]; {
+		case f.MimeType == folderMimeType || f.MimeType == googleAppsPrefix+"shortcut":
+			return "", true
+		case !ok:
+			return sources.SkipReasonUnsupported, true
+		}
+		return "", false
+	}
+	switch {
+	case f.Size > s.maxObjectSize:
+		return sources.SkipReasonSize, true
+	case f.Size == 0:
+		return sources.SkipReasonEmpty, true
+	case common.SkipFile(f.Name):
+		return sources.SkipReasonUnsupported, true
+	}
+	return "", false
+}
+
+// scanFile emits the chunks of a file, downloaded or exported as text.
+func (s *Source) scanFile(ctx context.Context, f *drive.File, chunksChan chan *sources.Chunk) error {
+	var res *http.Response
+	var err error
+	if exportType, ok := exportTypes[f.MimeType]; ok {
+		res, err = s.service.Files.Export(f.Id, exportType).Context(ctx).Download()
+	} else {
+		res, err = s.service.Files.Get(f.Id).SupportsAllDrives(true).Context(ctx).Download()
+	}
+	if err != nil {
+		return apiError(err)
+	}
+	defer res.Body.Close()
+	reader, err := diskbufferreader.New(io.LimitReader(res.Body, s.maxObjectSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	skel := s.chunkSkeleton(ctx, f)
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+
+	chunkReader := sources.NewChunkReader(s.archiveOptions.ChunkOptions()...)
+	for data := range chunkReader(ctx, reader) {
+		if err := data.Error(); err != nil {
+			return err
+		}
+		chunk := *skel
+		chunk.Data = data.Bytes()
+		chunk.Offset, chunk.LineStart, chunk.LineOffset = data.Offset(), data.LineStart(), data.LineOffset()
+		if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkSkeleton returns the skeleton of the chunks of a file.
+func (s *Source) chunkSkeleton(ctx context.Context, f *drive.File) *sources.Chunk {
+	var owner, lastModifiedBy string
+	if len(f.Owners) > 0 {
+		owner = f.Owners[0].EmailAddress
+	}
+	if f.LastModifyingUser != nil {
+		lastModifiedBy = f.LastModifyingUser.EmailAddress
+	}
+	path := f.Name
+	if len(f.Parents) > 0 {
+		path = s.folderPath(ctx, f.Parents[0]) + "/" + f.Name
+	}
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_GoogleDrive{
+				GoogleDrive: &source_metadatapb.GoogleDrive{
+					File:           sanitizer.UTF8(f.Name),
+					Link:           f.WebViewLink,
+					Email:          owner,
+					Timestamp:      f.ModifiedTime,
+					Shared:         f.Shared,
+					LastModifiedBy: lastModifiedBy,
+					Path:           sanitizer.UTF8(path),
+					DriveId:        f.DriveId,
+					FileId:         f.Id,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// folderPath returns the path of a folder from the root of its drive, or
+// its ID if it can't be read.
+func (s *Source) folderPath(ctx context.Context, id string) string {
+	s.foldersMu.Lock()
+	path, ok := s.folders[id]
+	s.foldersMu.Unlock()
+	if ok {
+		return path
+	}
+
+	folder, err := s.service.Files.Get(id).SupportsAllDrives(true).Fields("name,parents").Context(ctx).Do()
+	switch {
+	case err != nil:
+		ctx.Logger().V(3).Info("could not get folder", "folder", id, "error", err)
+		path = id
+	case len(folder.Parents) > 0:
+		path = s.folderPath(ctx, folder.Parents[0]) + "/" + folder.Name
+	default:
+		path = folder.Name
+	}
+	s.foldersMu.Lock()
+	s.folders[id] = path
+	s.foldersMu.Unlock()
+	return path
+}
+
+// apiError returns the error of a request of the Drive API, which is an
+// error of invalid credentials or errExportTooLarge for the responses that
+// say so.
+func apiError(err error) error {
+	var gErr *googleapi.Error
+	if !errors.As(err, &gErr) {
+		return err
+	}
+	if gErr.Code == http.StatusUnauthorized {
+		return fmt.Errorf("invalid credentials, status %d", gErr.Code)
+	}
+	for _, item := range gErr.Errors {
+		if item.Reason == "exportSizeLimitExceeded" {
+			return errExportTooLarge
+		}
+	}
+	return err
+}
+
+// loadState reads the state of the last scan, if there was one.
+func (s *Source) loadState() error {
+	if err := sources.LoadState(s.conn.GetStatePath(), &s.state); err != nil {
+		return err
+	}
+	if s.state.PageTokens == nil {
+		s.state.PageTokens = map[string]string{}
+	}
+	return nil
+}
+
+// saveState saves the state of the scan for the next one. The drives that
+// failed keep the state of the last scan.
+func (s *Source) saveState() error {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return sources.SaveState(s.conn.GetStatePath(), s.state)
+}
@@ -0,0 +1,193 @@
+package googledrive
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// fakeDrive serves the Drive API of a drive of a folder of a document, a
+// text file, an image of Google Drawings and an empty file, which requires
+// the access token token. A change of the text file follows the start page
+// token 7.
+func fakeDrive(t *testing.T) *httptest.Server {
+	t.Helper()
+	file := func(id, name, mimeType string, size int) map[string]any {
+		f := map[string]any{
+			"id": id, "name": name, "mimeType": mimeType, "parents": []string{"folder"},
+			"webViewLink": "https://drive.google.com/file/d/" + id, "modifiedTime": "2024-03-01T10:00:00Z",
+			"owners": []any{map[string]any{"emailAddress": "ada@example.com"}}, "shared": true,
+			"lastModifyingUser": map[string]any{"emailAddress": "bob@example.com"},
+		}
+		if size > 0 {
+			f["size"] = strconv.Itoa(size)
+		}
+		return f
+	}
+	files := []any{
+		file("folder", "Ops", folderMimeType, 0),
+		file("doc", "Runbook", googleAppsPrefix+"document", 0),
+		file("txt", "keys.txt", "text/plain", 8),
+		file("drawing", "Diagram", googleAppsPrefix+"drawing", 0),
+		file("empty", "empty.txt", "text/plain", 0),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":401,"message":"Invalid Credentials"}}`))
+			return
+		}
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/files":
+			assert.Equal(t, "allDrives", query.Get("corpora"))
+			assert.Equal(t, "trashed = false", query.Get("q"))
+			if query.Get("pageToken") == "" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"files": files[:2], "nextPageToken": "2"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"files": files[2:]})
+		case "/files/folder":
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "Ops", "parents": []string{"root"}})
+		case "/files/root":
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "My Drive"})
+		case "/files/doc/export":
+			assert.Equal(t, "text/plain", query.Get("mimeType"))
+			_, _ = w.Write([]byte("password: hunter2"))
+		case "/files/txt":
+			assert.Equal(t, "media", query.Get("alt"))
+			_, _ = w.Write([]byte("AKIAKEYS"))
+		case "/changes/startPageToken":
+			_ = json.NewEncoder(w).Encode(map[string]any{"startPageToken": "7"})
+		case "/changes":
+			assert.Equal(t, "7", query.Get("pageToken"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"newStartPageToken": "8", "changes": []any{
+				map[string]any{"fileId": "gone", "removed": true},
+				map[string]any{"fileId": "txt", "file": files[2]},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// chunks returns the data of the chunks of a source by file ID, and their
+// metadata.
+func chunks(t *testing.T, conn *sourcespb.GoogleDrive) (map[string]string, map[string]*source_metadatapb.GoogleDrive) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.GoogleDrive)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetGoogleDrive()
+		got[m.GetFileId()] += string(chunk.Data)
+		metadata[m.GetFileId()] = m
+	}
+	return got, metadata
+}
+
+func oauthCredential() *sourcespb.GoogleDrive_Oauth {
+	return &sourcespb.GoogleDrive_Oauth{Oauth: &credentialspb.Oauth2{AccessToken: "token"}}
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server := fakeDrive(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	conn := &sourcespb.GoogleDrive{Endpoint: server.URL, Credential: oauthCredential(), StatePath: statePath}
+
+	got, metadata := chunks(t, conn)
+	assert.Equal(t, map[string]string{"doc": "password: hunter2", "txt": "AKIAKEYS"}, got)
+	assert.Equal(t, &source_metadatapb.GoogleDrive{
+		File:           "keys.txt",
+		Link:           "https://drive.google.com/file/d/txt",
+		Email:          "ada@example.com",
+		Timestamp:      "2024-03-01T10:00:00Z",
+		Shared:         true,
+		LastModifiedBy: "bob@example.com",
+		Path:           "My Drive/Ops/keys.txt",
+		FileId:         "txt",
+	}, metadata["txt"])
+
+	// The next scan only scans the changes since the first.
+	got, _ = chunks(t, conn)
+	assert.Equal(t, map[string]string{"txt": "AKIAKEYS"}, got)
+	s := &Source{conn: conn}
+	require.NoError(t, s.loadState())
+	assert.Equal(t, map[string]string{"": "8"}, s.state.PageTokens)
+}
+
+func TestSource_ChunksServiceAccount(t *testing.T) {
+	server := fakeDrive(t)
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.FormValue("grant_type"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	account, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "scanner@acme.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"token_uri":    tokenServer.URL,
+	})
+	require.NoError(t, err)
+
+	got, _ := chunks(t, &sourcespb.GoogleDrive{
+		Endpoint:        server.URL,
+		Credential:      &sourcespb.GoogleDrive_JsonServiceAccount{JsonServiceAccount: string(account)},
+		ImpersonateUser: "ada@example.com",
+	})
+	assert.Equal(t, map[string]string{"doc": "password: hunter2", "txt": "AKIAKEYS"}, got)
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	server := fakeDrive(t)
+	conn, err := anypb.New(&sourcespb.GoogleDrive{Endpoint: server.URL, Credential: oauthCredential()})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+
+	var targets []sources.Target
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		targets = append(targets, target)
+	}))
+	assert.Equal(t, []sources.Target{{Name: "all files", Objects: 2, Bytes: 8}}, targets)
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := fakeDrive(t)
+	conn, err := anypb.New(&sourcespb.GoogleDrive{
+		Endpoint:   server.URL,
+		Credential: &sourcespb.GoogleDrive_Oauth{Oauth: &credentialspb.Oauth2{AccessToken: "expired"}},
+	})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "invalid credentials")
+}
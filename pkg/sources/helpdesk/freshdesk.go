@@ -0,0 +1,162 @@
+package helpdesk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+const (
+	// freshdeskPageSize is the number of tickets and conversations requested
+	// at a time, the most Freshdesk returns.
+	freshdeskPageSize = 100
+	// freshdeskMaxPages is the number of pages of tickets Freshdesk lists
+	// for a query, after which the listing starts over from the time of the
+	// last ticket.
+	freshdeskMaxPages = 300
+)
+
+// freshdesk lists the tickets of a Freshdesk account.
+type freshdesk struct {
+	api
+}
+
+func newFreshdesk(client *http.Client, conn *sourcespb.Freshdesk) (*freshdesk, error) {
+	endpoint, err := endpointURL(conn.GetEndpoint())
+	if err != nil {
+		return nil, err
+	}
+	if conn.GetApiKey() == "" {
+		return nil, errors.New("an API key is required")
+	}
+	f := &freshdesk{api{client: client, endpoint: endpoint}}
+	f.authorize = func(req *http.Request) { req.SetBasicAuth(conn.GetApiKey(), "X") }
+	return f, nil
+}
+
+func (f *freshdesk) name() string {
+	return "freshdesk"
+}
+
+type freshdeskAttachment struct {
+	Name          string `json:"name"`
+	Size          int64  `json:"size"`
+	AttachmentURL string `json:"attachment_url"`
+}
+
+type freshdeskTicket struct {
+	ID              int64                 `json:"id"`
+	Subject         string                `json:"subject"`
+	DescriptionText string                `json:"description_text"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+	Attachments     []freshdeskAttachment `json:"attachments"`
+	Requester       struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"requester"`
+}
+
+func (f *freshdesk) tickets(ctx context.Context, since time.Time, fn func([]ticket) error) error {
+	// Tickets updated at the time a listing starts over from were listed by
+	// the last one.
+	seen := make(map[int64]bool)
+	for {
+		var last time.Time
+		for pageNum := 1; pageNum <= freshdeskMaxPages; pageNum++ {
+			query := url.Values{
+				"updated_since": {since.UTC().Format(time.RFC3339)},
+				"order_by":      {"updated_at"},
+				"order_type":    {"asc"},
+				"per_page":      {strconv.Itoa(freshdeskPageSize)},
+				"page":          {strconv.Itoa(pageNum)},
+			}
+			var page []freshdeskTicket
+			if _, err := f.get(ctx, "/api/v2/tickets?"+query.Encode(), &page); err != nil {
+				return err
+			}
+			tickets := make([]ticket, 0, len(page))
+			for _, t := range page {
+				last = t.UpdatedAt
+				if seen[t.ID] {
+					continue
+				}
+				seen[t.ID] = true
+				tickets = append(tickets, ticket{
+					id:      t.ID,
+					subject: t.Subject,
+					updated: t.UpdatedAt,
+					link:    fmt.Sprintf("%s/a/tickets/%d", f.endpoint, t.ID),
+				})
+			}
+			if err := fn(tickets); err != nil {
+				return err
+			}
+			if len(page) < freshdeskPageSize {
+				return nil
+			}
+		}
+		if !last.After(since) {
+			return fmt.Errorf("more than %d tickets were updated at %s", freshdeskMaxPages*freshdeskPageSize, since)
+		}
+		since = last
+	}
+}
+
+type freshdeskConversation struct {
+	ID          int64                 `json:"id"`
+	BodyText    string                `json:"body_text"`
+	FromEmail   string                `json:"from_email"`
+	CreatedAt   time.Time             `json:"created_at"`
+	Attachments []freshdeskAttachment `json:"attachments"`
+}
+
+// posts returns the description of a ticket and its conversations, which
+// are its replies and notes.
+func (f *freshdesk) posts(ctx context.Context, t ticket) ([]post, error) {
+	var details freshdeskTicket
+	if _, err := f.get(ctx, fmt.Sprintf("/api/v2/tickets/%d?include=requester", t.id), &details); err != nil {
+		return nil, fmt.Errorf("error getting ticket: %w", err)
+	}
+	posts := []post{{
+		location:    locationDescription,
+		author:      formatAuthor(details.Requester.Name, details.Requester.Email),
+		body:        details.Subject + "\n\n" + details.DescriptionText,
+		created:     details.CreatedAt,
+		attachments: freshdeskAttachments(details.Attachments),
+	}}
+
+	for pageNum := 1; ; pageNum++ {
+		query := url.Values{"per_page": {strconv.Itoa(freshdeskPageSize)}, "page": {strconv.Itoa(pageNum)}}
+		var page []freshdeskConversation
+		if _, err := f.get(ctx, fmt.Sprintf("/api/v2/tickets/%d/conversations?%s", t.id, query.Encode()), &page); err != nil {
+			return nil, fmt.Errorf("error listing conversations: %w", err)
+		}
+		for _, c := range page {
+			posts = append(posts, post{
+				location:    fmt.Sprintf("%s/%d", locationComment, c.ID),
+				author:      c.FromEmail,
+				body:        c.BodyText,
+				created:     c.CreatedAt,
+				attachments: freshdeskAttachments(c.Attachments),
+			})
+		}
+		if len(page) < freshdeskPageSize {
+			return posts, nil
+		}
+	}
+}
+
+func freshdeskAttachments(attachments []freshdeskAttachment) []attachment {
+	converted := make([]attachment, 0, len(attachments))
+	for _, a := range attachments {
+		converted = append(converted, attachment{name: a.Name, url: a.AttachmentURL, size: a.Size})
+	}
+	return converted
+}
@@ -0,0 +1,373 @@
+package helpdesk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	goerrors "github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// maxAttachmentSize is the size of the largest attachments scanned.
+	maxAttachmentSize = 250 * 1024 * 1024
+
+	locationDescription = "description"
+	locationComment     = "comment"
+	locationAttachment  = "attachment"
+)
+
+// Source scans the descriptions, comments and attachments of the tickets of
+// a helpdesk: Zendesk or Freshdesk.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn     *sourcespb.Helpdesk
+	platform platform
+	// state is read from and saved to the state path of the connection.
+	stateMu sync.Mutex
+	state   state
+	// archiveOptions configures how the archives of attachments are
+	// extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// platform lists the tickets of a helpdesk and their posts.
+type platform interface {
+	// name is the name of the platform in the metadata of chunks.
+	name() string
+	// tickets calls fn with each page of the tickets updated since a time,
+	// the least recently updated first.
+	tickets(ctx context.Context, since time.Time, fn func([]ticket) error) error
+	// posts returns the description and the comments of a ticket.
+	posts(ctx context.Context, t ticket) ([]post, error)
+	// download returns the content of an attachment.
+	download(ctx context.Context, a attachment) (io.ReadCloser, error)
+}
+
+// ticket is a ticket of a helpdesk.
+type ticket struct {
+	id      int64
+	subject string
+	updated time.Time
+	link    string
+}
+
+// post is the description or a comment of a ticket.
+type post struct {
+	location    string
+	author      string
+	body        string
+	created     time.Time
+	attachments []attachment
+}
+
+// attachment is a file attached to a post.
+type attachment struct {
+	name string
+	url  string
+	size int64
+}
+
+// state is what a scan saves for the next one to only scan the tickets
+// updated since.
+type state struct {
+	// Updated is the time of the last update of the tickets scanned.
+	Updated time.Time `json:"updated"`
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_HELPDESK
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of attachments are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized helpdesk source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Helpdesk
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return goerrors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	client := common.RetryableHttpClientTimeout(120)
+	var err error
+	switch p := conn.GetPlatform().(type) {
+	case *sourcespb.Helpdesk_Zendesk:
+		s.platform, err = newZendesk(client, p.Zendesk)
+	case *sourcespb.Helpdesk_Freshdesk:
+		s.platform, err = newFreshdesk(client, p.Freshdesk)
+	default:
+		err = errors.New("a platform is required")
+	}
+	return err
+}
+
+// endpointURL validates the URL of the account of a platform, and returns
+// it without its trailing slash.
+func endpointURL(endpoint string) (string, error) {
+	if endpoint == "" {
+		return "", errors.New("the endpoint of the account is required")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+	return strings.TrimSuffix(endpoint, "/"), nil
+}
+
+// Chunks emits the description, comments and attachments of each ticket as
+// chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	if err := s.loadState(); err != nil {
+		return err
+	}
+	// Full scans list the tickets updated since the epoch.
+	since := time.Unix(0, 0).UTC()
+	if s.state.Updated.After(since) {
+		since = s.state.Updated
+	}
+	if updatedSince := s.conn.GetUpdatedSince(); updatedSince != nil && updatedSince.AsTime().After(since) {
+		since = updatedSince.AsTime()
+	}
+
+	var listed, scanned int64
+	scanErrs := sources.NewScanErrors()
+	var latestMu sync.Mutex
+	latest := s.state.Updated
+	err := s.platform.tickets(ctx, since, func(tickets []ticket) error {
+		total := atomic.AddInt64(&listed, int64(len(tickets)))
+		for _, t := range tickets {
+			if common.IsDone(ctx) {
+				return ctx.Err()
+			}
+			t := t
+			s.jobPool.Go(func() error {
+				if err := s.scanTicket(ctx, t, chunksChan); err != nil {
+					scanErrs.Add(fmt.Errorf("error scanning ticket %d: %w", t.id, err))
+				} else {
+					latestMu.Lock()
+					if t.updated.After(latest) {
+						latest = t.updated
+					}
+					latestMu.Unlock()
+				}
+				n := atomic.AddInt64(&scanned, 1)
+				s.SetProgressComplete(int(n), int(total), fmt.Sprintf("Ticket: %d", t.id), "")
+				return nil
+			})
+		}
+		return nil
+	})
+	_ = s.jobPool.Wait()
+	if err != nil {
+		return fmt.Errorf("error listing tickets: %w", err)
+	}
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+		// The tickets that failed are scanned again by the next scan.
+		return nil
+	}
+	if common.IsDone(ctx) {
+		return nil
+	}
+	s.state.Updated = latest
+	return s.saveState()
+}
+
+func (s *Source) scanTicket(ctx context.Context, t ticket, chunksChan chan *sources.Chunk) error {
+	posts, err := s.platform.posts(ctx, t)
+	if err != nil {
+		return err
+	}
+	for _, p := range posts {
+		skel := s.chunkSkeleton(t, p.location, p.author, p.created)
+		if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(p.body), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+			return err
+		}
+		if s.conn.GetSkipAttachments() {
+			continue
+		}
+		for _, a := range p.attachments {
+			location := locationAttachment + "/" + a.name
+			if a.size > maxAttachmentSize {
+				sources.ReportSkipBytes(ctx, fmt.Sprintf("%d/%s", t.id, location), sources.SkipReasonSize, a.size)
+				continue
+			}
+			skel := s.chunkSkeleton(t, location, p.author, p.created)
+			if err := s.scanAttachment(ctx, skel, a, chunksChan); err != nil {
+				return fmt.Errorf("error scanning attachment %s: %w", a.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// scanAttachment scans an attachment, through the handlers of archives.
+func (s *Source) scanAttachment(ctx context.Context, skel *sources.Chunk, a attachment, chunksChan chan *sources.Chunk) error {
+	body, err := s.platform.download(ctx, a)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	reader, err := diskbufferreader.New(io.LimitReader(body, maxAttachmentSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+	return sources.ChunkReaderTo(ctx, skel, reader, chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+func (s *Source) chunkSkeleton(t ticket, location, author string, created time.Time) *sources.Chunk {
+	var timestamp string
+	if !created.IsZero() {
+		timestamp = created.UTC().Format(time.RFC3339)
+	}
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Helpdesk{
+				Helpdesk: &source_metadatapb.Helpdesk{
+					Platform:  s.platform.name(),
+					Ticket:    t.id,
+					Subject:   sanitizer.UTF8(t.subject),
+					Location:  sanitizer.UTF8(location),
+					Author:    sanitizer.UTF8(author),
+					Link:      t.link,
+					Timestamp: timestamp,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// loadState reads the state of the last scan, if there was one.
+func (s *Source) loadState() error {
+	return sources.LoadState(s.conn.GetStatePath(), &s.state)
+}
+
+// saveState saves the state of the scan for the next one.
+func (s *Source) saveState() error {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return sources.SaveState(s.conn.GetStatePath(), s.state)
+}
+
+// api sends the requests of the API of a platform.
+type api struct {
+	client   *http.Client
+	endpoint string
+	// authorize sets the credentials of requests.
+	authorize func(*http.Request)
+}
+
+// get decodes the JSON response of a request of the API. The path is
+// relative to the endpoint, or a URL of the next page.
+func (a *api) get(ctx context.Context, path string, v any) (http.Header, error) {
+	reqURL := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		reqURL = a.endpoint + path
+	}
+	res, err := a.do(ctx, reqURL)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return res.Header, json.NewDecoder(res.Body).Decode(v)
+}
+
+// do sends a GET request, authorized if it is to the endpoint, and returns
+// its response if it succeeded. Attachments are served from other hosts,
+// whose URLs carry their own authorization.
+func (a *api) do(ctx context.Context, reqURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint, err := url.Parse(a.endpoint); err == nil && req.URL.Host == endpoint.Host {
+		a.authorize(req)
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+		}
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res, nil
+}
+
+// download returns the content of an attachment.
+func (a *api) download(ctx context.Context, att attachment) (io.ReadCloser, error) {
+	res, err := a.do(ctx, att.url)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
@@ -0,0 +1,220 @@
+package helpdesk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// testZendesk is a Zendesk account with the API token "token" of
+// "agent@example.com", which records the start times of the exports.
+type testZendesk struct {
+	mu         sync.Mutex
+	startTimes []string
+}
+
+func (z *testZendesk) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "agent@example.com/token" || pass != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v2/incremental/tickets/cursor.json":
+			if r.URL.Query().Get("cursor") == "" {
+				z.mu.Lock()
+				z.startTimes = append(z.startTimes, r.URL.Query().Get("start_time"))
+				z.mu.Unlock()
+				fmt.Fprintf(w, `{"tickets":[{"id":1,"subject":"Cannot log in","status":"open","updated_at":"2024-01-02T03:04:05Z"},{"id":2,"status":"deleted","updated_at":"2024-01-03T00:00:00Z"}],"after_url":%q,"end_of_stream":false}`,
+					server.URL+"/api/v2/incremental/tickets/cursor.json?cursor=next")
+				return
+			}
+			fmt.Fprint(w, `{"tickets":[{"id":3,"subject":"Webhook","status":"solved","updated_at":"2024-01-04T00:00:00Z"}],"end_of_stream":true}`)
+		case "/api/v2/tickets/1/comments.json":
+			assert.Equal(t, "users", r.URL.Query().Get("include"))
+			if r.URL.Query().Get("page[after]") != "" {
+				fmt.Fprint(w, `{"comments":[{"id":11,"author_id":100,"body":"Never mind","created_at":"2024-01-02T03:04:05Z"}],"users":[{"id":100,"name":"Ada","email":"ada@example.com"}],"meta":{"has_more":false}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"comments":[{"id":10,"author_id":100,"body":"My password is hunter2","created_at":"2024-01-02T03:00:00Z","attachments":[{"file_name":"config.env","content_url":%q,"size":25}]}],"users":[{"id":100,"name":"Ada","email":"ada@example.com"}],"meta":{"has_more":true},"links":{"next":%q}}`,
+				server.URL+"/attachments/config.env", server.URL+"/api/v2/tickets/1/comments.json?include=users&page[after]=x")
+		case "/api/v2/tickets/3/comments.json":
+			fmt.Fprint(w, `{"comments":[{"id":30,"author_id":300,"body":"Webhook is down","created_at":"2024-01-04T00:00:00Z"},{"id":31,"author_id":301,"body":"Use token=abc123","created_at":"2024-01-04T00:00:00Z"}],"users":[{"id":301,"name":"Bob"}],"meta":{"has_more":false}}`)
+		case "/attachments/config.env":
+			fmt.Fprint(w, "AWS_SECRET_ACCESS_KEY=xyz")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSource_ChunksZendesk(t *testing.T) {
+	z := &testZendesk{}
+	server := z.server(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Helpdesk{
+		Platform: &sourcespb.Helpdesk_Zendesk{Zendesk: &sourcespb.Zendesk{
+			Endpoint:   server.URL + "/",
+			Email:      "agent@example.com",
+			Credential: &sourcespb.Zendesk_ApiToken{ApiToken: "token"},
+		}},
+		StatePath: statePath,
+	}, 4)
+
+	got := sources.RequireChunks(t, s)
+	assert.Equal(t, &source_metadatapb.Helpdesk{
+		Platform:  "zendesk",
+		Ticket:    1,
+		Subject:   "Cannot log in",
+		Location:  "description",
+		Author:    "Ada <ada@example.com>",
+		Link:      server.URL + "/agent/tickets/1",
+		Timestamp: "2024-01-02T03:00:00Z",
+	}, sources.MetadataWith(got, "Cannot log in\n\nMy password is hunter2").GetHelpdesk())
+	attachment := sources.MetadataWith(got, "AWS_SECRET_ACCESS_KEY=xyz").GetHelpdesk()
+	require.NotNil(t, attachment)
+	assert.Equal(t, "attachment/config.env", attachment.GetLocation())
+	assert.Equal(t, "comment/11", sources.MetadataWith(got, "Never mind").GetHelpdesk().GetLocation())
+	comment := sources.MetadataWith(got, "Use token=abc123").GetHelpdesk()
+	require.NotNil(t, comment)
+	assert.Equal(t, "comment/31", comment.GetLocation())
+	assert.Equal(t, "Bob", comment.GetAuthor())
+	assert.Equal(t, server.URL+"/agent/tickets/3", comment.GetLink())
+	for _, chunk := range got {
+		assert.NotEqual(t, int64(2), chunk.SourceMetadata.GetHelpdesk().GetTicket())
+	}
+
+	// The next scan starts from the last update of the tickets scanned.
+	data, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	var st state
+	require.NoError(t, json.Unmarshal(data, &st))
+	assert.Equal(t, time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC), st.Updated)
+	s = sources.InitTestSource(t, &Source{}, &sourcespb.Helpdesk{
+		Platform: &sourcespb.Helpdesk_Zendesk{Zendesk: &sourcespb.Zendesk{
+			Endpoint:   server.URL,
+			Email:      "agent@example.com",
+			Credential: &sourcespb.Zendesk_ApiToken{ApiToken: "token"},
+		}},
+		StatePath: statePath,
+	}, 4)
+	_ = sources.RequireChunks(t, s)
+	assert.Equal(t, []string{"0", "1704326400"}, z.startTimes)
+}
+
+func TestSource_ChunksZendeskInvalidCredentials(t *testing.T) {
+	server := (&testZendesk{}).server(t)
+	anyConn, err := anypb.New(&sourcespb.Helpdesk{
+		Platform: &sourcespb.Helpdesk_Zendesk{Zendesk: &sourcespb.Zendesk{
+			Endpoint:   server.URL,
+			Credential: &sourcespb.Zendesk_OauthToken{OauthToken: "wrong"},
+		}},
+	})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+	err = s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid credentials")
+}
+
+func TestSource_ChunksFreshdesk(t *testing.T) {
+	// Attachments are served from another host, without the credentials of
+	// the account.
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, "db_password=s3cr3t")
+	}))
+	t.Cleanup(files.Close)
+
+	var updatedSince []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "key" || pass != "X" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v2/tickets":
+			mu.Lock()
+			updatedSince = append(updatedSince, r.URL.Query().Get("updated_since"))
+			mu.Unlock()
+			assert.Equal(t, "updated_at", r.URL.Query().Get("order_by"))
+			assert.Equal(t, "asc", r.URL.Query().Get("order_type"))
+			fmt.Fprint(w, `[{"id":7,"subject":"Printer","updated_at":"2024-02-01T00:00:00Z"}]`)
+		case "/api/v2/tickets/7":
+			fmt.Fprintf(w, `{"id":7,"subject":"Printer","description_text":"It does not print","created_at":"2024-01-31T00:00:00Z","requester":{"name":"Carol","email":"carol@example.com"},"attachments":[{"name":"db.conf","size":18,"attachment_url":%q}]}`, files.URL+"/db.conf?signature=x")
+		case "/api/v2/tickets/7/conversations":
+			fmt.Fprint(w, `[{"id":70,"body_text":"api_key = 0123456789abcdef","from_email":"agent@example.com","created_at":"2024-02-01T00:00:00Z"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Helpdesk{
+		Platform:     &sourcespb.Helpdesk_Freshdesk{Freshdesk: &sourcespb.Freshdesk{Endpoint: server.URL, ApiKey: "key"}},
+		UpdatedSince: timestamppb.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}, 4)
+	got := sources.RequireChunks(t, s)
+	assert.Equal(t, []string{"2024-01-01T00:00:00Z"}, updatedSince)
+
+	description := sources.MetadataWith(got, "Printer\n\nIt does not print").GetHelpdesk()
+	require.NotNil(t, description)
+	assert.Equal(t, "freshdesk", description.GetPlatform())
+	assert.Equal(t, "Carol <carol@example.com>", description.GetAuthor())
+	assert.Equal(t, server.URL+"/a/tickets/7", description.GetLink())
+	attachment := sources.MetadataWith(got, "db_password=s3cr3t").GetHelpdesk()
+	require.NotNil(t, attachment)
+	assert.Equal(t, "attachment/db.conf", attachment.GetLocation())
+	comment := sources.MetadataWith(got, "api_key = 0123456789abcdef").GetHelpdesk()
+	require.NotNil(t, comment)
+	assert.Equal(t, "comment/70", comment.GetLocation())
+	assert.Equal(t, "agent@example.com", comment.GetAuthor())
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.Helpdesk{
+		"no platform": {},
+		"no endpoint": {Platform: &sourcespb.Helpdesk_Freshdesk{Freshdesk: &sourcespb.Freshdesk{ApiKey: "key"}}},
+		"invalid endpoint": {Platform: &sourcespb.Helpdesk_Freshdesk{Freshdesk: &sourcespb.Freshdesk{
+			Endpoint: "acme.freshdesk.com", ApiKey: "key",
+		}}},
+		"no API key":             {Platform: &sourcespb.Helpdesk_Freshdesk{Freshdesk: &sourcespb.Freshdesk{Endpoint: "https://acme.freshdesk.com"}}},
+		"no Zendesk credentials": {Platform: &sourcespb.Helpdesk_Zendesk{Zendesk: &sourcespb.Zendesk{Endpoint: "https://acme.zendesk.com"}}},
+		"API token without email": {Platform: &sourcespb.Helpdesk_Zendesk{Zendesk: &sourcespb.Zendesk{
+			Endpoint: "https://acme.zendesk.com", Credential: &sourcespb.Zendesk_ApiToken{ApiToken: "token"},
+		}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package helpdesk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// zendeskPageSize is the number of comments requested at a time, the most
+// Zendesk returns.
+const zendeskPageSize = 100
+
+// zendesk lists the tickets of a Zendesk account with the incremental
+// export API, which lists them by the time of their last update.
+type zendesk struct {
+	api
+}
+
+func newZendesk(client *http.Client, conn *sourcespb.Zendesk) (*zendesk, error) {
+	endpoint, err := endpointURL(conn.GetEndpoint())
+	if err != nil {
+		return nil, err
+	}
+	z := &zendesk{api{client: client, endpoint: endpoint}}
+	switch cred := conn.GetCredential().(type) {
+	case *sourcespb.Zendesk_ApiToken:
+		if conn.GetEmail() == "" {
+			return nil, errors.New("the email of the API token is required")
+		}
+		z.authorize = func(req *http.Request) { req.SetBasicAuth(conn.GetEmail()+"/token", cred.ApiToken) }
+	case *sourcespb.Zendesk_OauthToken:
+		z.authorize = func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+cred.OauthToken) }
+	default:
+		return nil, errors.New("an API token or an OAuth token is required")
+	}
+	return z, nil
+}
+
+func (z *zendesk) name() string {
+	return "zendesk"
+}
+
+type zendeskTicket struct {
+	ID        int64     `json:"id"`
+	Subject   string    `json:"subject"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (z *zendesk) tickets(ctx context.Context, since time.Time, fn func([]ticket) error) error {
+	// The export starts at least a minute ago.
+	if latest := time.Now().Add(-time.Minute); since.After(latest) {
+		since = latest
+	}
+	next := "/api/v2/incremental/tickets/cursor.json?start_time=" + strconv.FormatInt(since.Unix(), 10)
+	for {
+		var page struct {
+			Tickets     []zendeskTicket `json:"tickets"`
+			AfterURL    string          `json:"after_url"`
+			EndOfStream bool            `json:"end_of_stream"`
+		}
+		if _, err := z.get(ctx, next, &page); err != nil {
+			return err
+		}
+		tickets := make([]ticket, 0, len(page.Tickets))
+		for _, t := range page.Tickets {
+			if t.Status == "deleted" {
+				continue
+			}
+			tickets = append(tickets, ticket{
+				id:      t.ID,
+				subject: t.Subject,
+				updated: t.UpdatedAt,
+				link:    fmt.Sprintf("%s/agent/tickets/%d", z.endpoint, t.ID),
+			})
+		}
+		if err := fn(tickets); err != nil {
+			return err
+		}
+		if page.EndOfStream || page.AfterURL == "" {
+			return nil
+		}
+		next = page.AfterURL
+	}
+}
+
+type zendeskComment struct {
+	ID          int64     `json:"id"`
+	AuthorID    int64     `json:"author_id"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+	Attachments []struct {
+		FileName   string `json:"file_name"`
+		ContentURL string `json:"content_url"`
+		Size       int64  `json:"size"`
+	} `json:"attachments"`
+}
+
+type zendeskUser struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// posts returns the comments of a ticket, the first of which is its
+// description.
+func (z *zendesk) posts(ctx context.Context, t ticket) ([]post, error) {
+	query := url.Values{"include": {"users"}, "page[size]": {strconv.Itoa(zendeskPageSize)}}
+	next := fmt.Sprintf("/api/v2/tickets/%d/comments.json?%s", t.id, query.Encode())
+	var posts []post
+	users := make(map[int64]zendeskUser)
+	for {
+		var page struct {
+			Comments []zendeskComment `json:"comments"`
+			Users    []zendeskUser    `json:"users"`
+			Meta     struct {
+				HasMore bool `json:"has_more"`
+			} `json:"meta"`
+			Links struct {
+				Next string `json:"next"`
+			} `json:"links"`
+		}
+		if _, err := z.get(ctx, next, &page); err != nil {
+			return nil, fmt.Errorf("error listing comments: %w", err)
+		}
+		for _, u := range page.Users {
+			users[u.ID] = u
+		}
+		for _, c := range page.Comments {
+			p := post{
+				location: fmt.Sprintf("%s/%d", locationComment, c.ID),
+				author:   formatAuthor(users[c.AuthorID].Name, users[c.AuthorID].Email),
+				body:     c.Body,
+				created:  c.CreatedAt,
+			}
+			if len(posts) == 0 {
+				p.location = locationDescription
+				p.body = t.subject + "\n\n" + c.Body
+			}
+			for _, a := range c.Attachments {
+				p.attachments = append(p.attachments, attachment{name: a.FileName, url: a.ContentURL, size: a.Size})
+			}
+			posts = append(posts, p)
+		}
+		if !page.Meta.HasMore || page.Links.Next == "" {
+			return posts, nil
+		}
+		next = page.Links.Next
+	}
+}
+
+// formatAuthor returns the name and the email of an author.
+func formatAuthor(name, email string) string {
+	switch {
+	case email == "":
+		return name
+	case name == "":
+		return email
+	default:
+		return fmt.Sprintf("%s <%s>", name, email)
+	}
+}
@@ -0,0 +1,450 @@
+package imap
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultMaxMessageSize = 250 * 1024 * 1024 // 250 MiB
+	maxMessageSizeLimit   = 250 * 1024 * 1024 // 250 MiB
+
+	// maxConnections is the maximum number of connections to the server,
+	// which servers like Gmail limit per account.
+	maxConnections = 4
+	// fetchBatchSize is the number of messages fetched at a time.
+	fetchBatchSize = 100
+	dialTimeout    = 30 * time.Second
+	commandTimeout = 5 * time.Minute
+)
+
+// bodySection is the whole message, fetched without marking it as seen.
+var bodySection = &imap.BodySectionName{Peek: true}
+
+// Source scans the messages of the folders of an IMAP mailbox.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn *sourcespb.IMAP
+	// address is the host:port of the server.
+	address        string
+	tlsConfig      *tls.Config
+	include        sources.Globs
+	exclude        sources.Globs
+	maxMessageSize int64
+	// archiveOptions configures how the attachments of messages are
+	// extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_IMAP
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the attachments of messages are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized IMAP source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	if concurrency > maxConnections {
+		concurrency = maxConnections
+	}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.IMAP
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	if conn.GetAddress() == "" {
+		return errors.New("an address is required")
+	}
+	if conn.GetUsername() == "" {
+		return errors.New("a username is required")
+	}
+	if conn.GetStarttls() && conn.GetPlaintext() {
+		return errors.New("starttls and plaintext are mutually exclusive")
+	}
+	s.address = conn.GetAddress()
+	if _, _, err := net.SplitHostPort(s.address); err != nil {
+		port := "993"
+		if conn.GetStarttls() || conn.GetPlaintext() {
+			port = "143"
+		}
+		s.address = net.JoinHostPort(s.address, port)
+	}
+	s.tlsConfig = common.TLSClientConfig()
+	s.tlsConfig.InsecureSkipVerify = conn.GetInsecure()
+
+	var err error
+	if s.include, err = sources.CompileGlobs(conn.GetFolders()); err != nil {
+		return err
+	}
+	if s.exclude, err = sources.CompileGlobs(conn.GetExcludeFolders()); err != nil {
+		return err
+	}
+
+	s.maxMessageSize = conn.GetMaxMessageSize()
+	if s.maxMessageSize <= 0 || s.maxMessageSize > maxMessageSizeLimit {
+		s.maxMessageSize = defaultMaxMessageSize
+	}
+	return nil
+}
+
+// dial connects and authenticates to the server.
+func (s *Source) dial(ctx context.Context) (*client.Client, error) {
+	dialer := proxyDialer{ctx}
+	var (
+		c   *client.Client
+		err error
+	)
+	if s.conn.GetStarttls() || s.conn.GetPlaintext() {
+		c, err = client.DialWithDialer(dialer, s.address)
+	} else {
+		c, err = client.DialWithDialerTLS(dialer, s.address, s.tlsConfig)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", s.address, err)
+	}
+	c.ErrorLog = errorLog{ctx}
+	c.Timeout = commandTimeout
+
+	if s.conn.GetStarttls() {
+		if err := c.StartTLS(s.tlsConfig); err != nil {
+			_ = c.Logout()
+			return nil, fmt.Errorf("error starting TLS: %w", err)
+		}
+	}
+	switch cred := s.conn.GetCredential().(type) {
+	case *sourcespb.IMAP_Oauth2Token:
+		err = c.Authenticate(&xoauth2{username: s.conn.GetUsername(), token: cred.Oauth2Token})
+	default:
+		err = c.Login(s.conn.GetUsername(), s.conn.GetPassword())
+	}
+	if err != nil {
+		_ = c.Logout()
+		return nil, fmt.Errorf("error authenticating: %w", err)
+	}
+	return c, nil
+}
+
+// proxyDialer connects to the server through the configured proxy.
+type proxyDialer struct {
+	ctx context.Context
+}
+
+func (d proxyDialer) Dial(network, addr string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, dialTimeout)
+	defer cancel()
+	return common.DialContext(ctx, network, addr)
+}
+
+// errorLog logs the errors of a client, which go to stderr by default.
+type errorLog struct {
+	ctx context.Context
+}
+
+func (l errorLog) Printf(format string, v ...any) {
+	l.ctx.Logger().V(3).Info("IMAP client error", "error", fmt.Sprintf(format, v...))
+}
+
+func (l errorLog) Println(v ...any) {
+	l.ctx.Logger().V(3).Info("IMAP client error", "error", strings.TrimSpace(fmt.Sprintln(v...)))
+}
+
+// Chunks emits the messages of the folders as chunks, with their
+// attachments extracted.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	folders, err := s.foldersToScan(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, folder := range folders {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(folders), fmt.Sprintf("Folder: %s", folder), "")
+		folder := folder
+		s.jobPool.Go(func() error {
+			if err := s.scanFolder(ctx, folder, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error scanning folder %s: %w", folder, err))
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// EnumerateTargets reports each folder with the number of the messages that
+// would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	folders, err := s.foldersToScan(ctx)
+	if err != nil {
+		return err
+	}
+	c, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Logout() }()
+	for _, folder := range folders {
+		uids, err := s.search(c, folder)
+		if err != nil {
+			return fmt.Errorf("error searching folder %s: %w", folder, err)
+		}
+		report(sources.Target{Name: folder, Objects: int64(len(uids)), Bytes: -1})
+	}
+	return nil
+}
+
+// foldersToScan returns the selectable folders of the mailbox that match
+// the folders of the connection, or all of them if it has none, but not its
+// excluded ones.
+func (s *Source) foldersToScan(ctx context.Context) ([]string, error) {
+	c, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = c.Logout() }()
+
+	infos := make(chan *imap.MailboxInfo, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.List("", "*", infos)
+	}()
+	var folders []string
+	for info := range infos {
+		if s.selected(info) {
+			folders = append(folders, info.Name)
+		}
+	}
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("error listing folders: %w", err)
+	}
+	sort.Strings(folders)
+	return folders, nil
+}
+
+func (s *Source) selected(info *imap.MailboxInfo) bool {
+	for _, attr := range info.Attributes {
+		if strings.EqualFold(attr, imap.NoSelectAttr) || strings.EqualFold(attr, "\\NonExistent") {
+			return false
+		}
+	}
+	if s.exclude.Match(info.Name) {
+		return false
+	}
+	return len(s.include) == 0 || s.include.Match(info.Name)
+}
+
+// search selects a folder read-only and returns the UIDs of its messages
+// received since the time of the connection.
+func (s *Source) search(c *client.Client, folder string) ([]uint32, error) {
+	if _, err := c.Select(folder, true); err != nil {
+		return nil, err
+	}
+	criteria := imap.NewSearchCriteria()
+	if since := s.conn.GetSince(); since != nil {
+		criteria.Since = since.AsTime()
+	}
+	return c.UidSearch(criteria)
+}
+
+func (s *Source) scanFolder(ctx context.Context, folder string, chunksChan chan *sources.Chunk) error {
+	c, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Logout() }()
+
+	uids, err := s.search(c, folder)
+	if err != nil {
+		return err
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+	for start := 0; start < len(uids); start += fetchBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := start + fetchBatchSize
+		if end > len(uids) {
+			end = len(uids)
+		}
+		if err := s.scanBatch(ctx, c, folder, uids[start:end], chunksChan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanBatch fetches the envelopes and sizes of messages, and then the
+// bodies of those that are not too large.
+func (s *Source) scanBatch(ctx context.Context, c *client.Client, folder string, uids []uint32, chunksChan chan *sources.Chunk) error {
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uids...)
+	envelopes, err := fetch(c, seqSet, []imap.FetchItem{imap.FetchUid, imap.FetchEnvelope, imap.FetchRFC822Size})
+	if err != nil {
+		return fmt.Errorf("error fetching envelopes: %w", err)
+	}
+
+	toFetch := new(imap.SeqSet)
+	for _, msg := range envelopes {
+		if int64(msg.Size) > s.maxMessageSize {
+			ctx.Logger().V(3).Info("skipping message larger than the maximum size", "folder", folder, "uid", msg.Uid, "size", msg.Size)
+			continue
+		}
+		toFetch.AddNum(msg.Uid)
+	}
+	if toFetch.Empty() {
+		return nil
+	}
+	bodies, err := fetch(c, toFetch, []imap.FetchItem{imap.FetchUid, bodySection.FetchItem()})
+	if err != nil {
+		return fmt.Errorf("error fetching messages: %w", err)
+	}
+
+	byUID := make(map[uint32]*imap.Envelope, len(envelopes))
+	for _, msg := range envelopes {
+		byUID[msg.Uid] = msg.Envelope
+	}
+	for _, msg := range bodies {
+		body := msg.GetBody(bodySection)
+		if body == nil {
+			continue
+		}
+		skel := s.chunkSkeleton(folder, msg.Uid, byUID[msg.Uid])
+		if err := s.scanMessage(ctx, skel, body, chunksChan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetch returns the messages of a set of UIDs.
+func fetch(c *client.Client, seqSet *imap.SeqSet, items []imap.FetchItem) ([]*imap.Message, error) {
+	messages := make(chan *imap.Message, fetchBatchSize)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.UidFetch(seqSet, items, messages)
+	}()
+	var fetched []*imap.Message
+	for msg := range messages {
+		fetched = append(fetched, msg)
+	}
+	return fetched, <-done
+}
+
+// scanMessage scans a message through the handlers, which parse its MIME
+// parts and extract its attachments, or as it is if they do not.
+func (s *Source) scanMessage(ctx context.Context, skel *sources.Chunk, body io.Reader, chunksChan chan *sources.Chunk) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if handlers.HandleFile(ctx, bytes.NewReader(data), skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+
+	chunkReader := sources.NewChunkReader(s.archiveOptions.ChunkOptions()...)
+	for data := range chunkReader(ctx, bytes.NewReader(data)) {
+		if err := data.Error(); err != nil {
+			return err
+		}
+		chunk := *skel
+		chunk.Data = data.Bytes()
+		if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Source) chunkSkeleton(folder string, uid uint32, envelope *imap.Envelope) *sources.Chunk {
+	metadata := &source_metadatapb.IMAP{
+		Folder: sanitizer.UTF8(folder),
+		Uid:    int64(uid),
+	}
+	if envelope != nil {
+		metadata.MessageId = sanitizer.UTF8(strings.Trim(envelope.MessageId, "<>"))
+		metadata.Subject = sanitizer.UTF8(envelope.Subject)
+		if len(envelope.From) > 0 {
+			metadata.From = sanitizer.UTF8(formatAddress(envelope.From[0]))
+		}
+		if !envelope.Date.IsZero() {
+			metadata.Date = envelope.Date.UTC().Format(time.RFC3339)
+		}
+	}
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Imap{Imap: metadata},
+		},
+		Verify: s.verify,
+	}
+}
+
+func formatAddress(addr *imap.Address) string {
+	if addr.PersonalName == "" {
+		return addr.Address()
+	}
+	return fmt.Sprintf("%s <%s>", addr.PersonalName, addr.Address())
+}
@@ -0,0 +1,240 @@
+package imap
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+	"github.com/emersion/go-imap/backend/memory"
+	"github.com/emersion/go-imap/server"
+	"github.com/emersion/go-sasl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const attachmentMessage = "From: Ada <ada@example.com>\r\n" +
+	"To: bob@example.com\r\n" +
+	"Subject: Deploy keys\r\n" +
+	"Date: Tue, 02 Jan 2024 03:04:05 +0000\r\n" +
+	"Message-ID: <keys@example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=BOUNDARY\r\n" +
+	"\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"The keys are attached.\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"Content-Disposition: attachment; filename=keys.txt\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"YXdzX3NlY3JldF9hY2Nlc3Nfa2V5ID0gaHVudGVyMg==\r\n" +
+	"--BOUNDARY--\r\n"
+
+const oldMessage = "From: carol@example.com\r\n" +
+	"Subject: Old news\r\n" +
+	"Date: Mon, 01 Jan 2018 00:00:00 +0000\r\n" +
+	"\r\n" +
+	"password = archived\r\n"
+
+// testServer serves the memory backend, whose user "username" has the
+// password "password" and the OAuth2 token "token", on a plaintext
+// connection. Its INBOX has a message with an attachment besides the one of
+// the backend, its Archive folder an old message and its Trash folder a
+// message that is never scanned.
+func testServer(t *testing.T) string {
+	t.Helper()
+	be := memory.New()
+	user, err := be.Login(nil, "username", "password")
+	require.NoError(t, err)
+	addMessage(t, user, "INBOX", time.Now(), attachmentMessage)
+	require.NoError(t, user.CreateMailbox("Archive"))
+	addMessage(t, user, "Archive", time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC), oldMessage)
+	require.NoError(t, user.CreateMailbox("Trash"))
+	addMessage(t, user, "Trash", time.Now(), "Subject: Deleted\r\n\r\nsecret = deleted\r\n")
+
+	srv := server.New(be)
+	srv.AllowInsecureAuth = true
+	srv.ErrorLog = nopLogger{}
+	srv.EnableAuth(xoauth2Mechanism, func(conn server.Conn) sasl.Server {
+		return &xoauth2Server{conn: conn, user: user}
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return listener.Addr().String()
+}
+
+func addMessage(t *testing.T, user backend.User, folder string, date time.Time, body string) {
+	t.Helper()
+	mailbox, err := user.GetMailbox(folder)
+	require.NoError(t, err)
+	require.NoError(t, mailbox.(*memory.Mailbox).CreateMessage(nil, date, bytes.NewBufferString(body)))
+}
+
+// xoauth2Server authenticates the user of the backend with the token
+// "token".
+type xoauth2Server struct {
+	conn server.Conn
+	user backend.User
+}
+
+func (s *xoauth2Server) Next(response []byte) ([]byte, bool, error) {
+	if response == nil {
+		return []byte{}, false, nil
+	}
+	if string(response) != "user=username\x01auth=Bearer token\x01\x01" {
+		return nil, true, errors.New("invalid token")
+	}
+	ctx := s.conn.Context()
+	ctx.State = imap.AuthenticatedState
+	ctx.User = s.user
+	return nil, true, nil
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+func (nopLogger) Println(...any)        {}
+
+// chunkWith returns the first chunk whose data contains a string.
+func chunkWith(chunks []*sources.Chunk, data string) *sources.Chunk {
+	for _, chunk := range chunks {
+		if strings.Contains(string(chunk.Data), data) {
+			return chunk
+		}
+	}
+	return nil
+}
+
+func TestSource_Chunks(t *testing.T) {
+	addr := testServer(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.IMAP{
+		Address:        addr,
+		Username:       "username",
+		Credential:     &sourcespb.IMAP_Password{Password: "password"},
+		ExcludeFolders: []string{"Tr*"},
+		Plaintext:      true,
+	}, 4)
+
+	got := sources.RequireChunks(t, s)
+	attachment := chunkWith(got, "aws_secret_access_key = hunter2")
+	require.NotNil(t, attachment)
+	metadata := attachment.SourceMetadata.GetImap()
+	assert.Equal(t, "INBOX", metadata.GetFolder())
+	assert.Equal(t, "keys@example.com", metadata.GetMessageId())
+	assert.Equal(t, "Deploy keys", metadata.GetSubject())
+	assert.Equal(t, "Ada <ada@example.com>", metadata.GetFrom())
+	assert.Equal(t, "2024-01-02T03:04:05Z", metadata.GetDate())
+	assert.NotZero(t, metadata.GetUid())
+	assert.Contains(t, metadata.GetArchivePath(), "keys.txt")
+
+	assert.NotNil(t, chunkWith(got, "Hi there :)"))
+	archived := chunkWith(got, "password = archived")
+	require.NotNil(t, archived)
+	assert.Equal(t, "Archive", archived.SourceMetadata.GetImap().GetFolder())
+	assert.Nil(t, chunkWith(got, "secret = deleted"))
+}
+
+func TestSource_ChunksFolders(t *testing.T) {
+	addr := testServer(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.IMAP{
+		Address:    addr,
+		Username:   "username",
+		Credential: &sourcespb.IMAP_Oauth2Token{Oauth2Token: "token"},
+		Folders:    []string{"INBOX", "Arch*"},
+		Since:      timestamppb.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Plaintext:  true,
+	}, 4)
+
+	var targets []sources.Target
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		targets = append(targets, target)
+	}))
+	assert.Equal(t, []sources.Target{
+		{Name: "Archive", Objects: 0, Bytes: -1},
+		{Name: "INBOX", Objects: 2, Bytes: -1},
+	}, targets)
+
+	got := sources.RequireChunks(t, s)
+	assert.NotNil(t, chunkWith(got, "aws_secret_access_key = hunter2"))
+	assert.Nil(t, chunkWith(got, "password = archived"))
+	assert.Nil(t, chunkWith(got, "secret = deleted"))
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	addr := testServer(t)
+	for name, credential := range map[string]*sourcespb.IMAP{
+		"password": {Credential: &sourcespb.IMAP_Password{Password: "wrong"}},
+		"token":    {Credential: &sourcespb.IMAP_Oauth2Token{Oauth2Token: "wrong"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := sources.InitTestSource(t, &Source{}, &sourcespb.IMAP{
+				Address:    addr,
+				Username:   "username",
+				Credential: credential.GetCredential(),
+				Plaintext:  true,
+			}, 4)
+			err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "error authenticating")
+		})
+	}
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, tt := range map[string]struct {
+		conn    *sourcespb.IMAP
+		wantErr bool
+		address string
+	}{
+		"no address":  {conn: &sourcespb.IMAP{Username: "username"}, wantErr: true},
+		"no username": {conn: &sourcespb.IMAP{Address: "imap.example.com"}, wantErr: true},
+		"starttls and plaintext": {
+			conn:    &sourcespb.IMAP{Address: "imap.example.com", Username: "username", Starttls: true, Plaintext: true},
+			wantErr: true,
+		},
+		"invalid glob": {
+			conn:    &sourcespb.IMAP{Address: "imap.example.com", Username: "username", Folders: []string{"[INBOX"}},
+			wantErr: true,
+		},
+		"implicit TLS port": {
+			conn:    &sourcespb.IMAP{Address: "imap.example.com", Username: "username"},
+			address: "imap.example.com:993",
+		},
+		"STARTTLS port": {
+			conn:    &sourcespb.IMAP{Address: "imap.example.com", Username: "username", Starttls: true},
+			address: "imap.example.com:143",
+		},
+		"explicit port": {
+			conn:    &sourcespb.IMAP{Address: "imap.example.com:1993", Username: "username"},
+			address: "imap.example.com:1993",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(tt.conn)
+			require.NoError(t, err)
+			s := &Source{}
+			err = s.Init(context.Background(), "test", 0, 0, false, anyConn, 1)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.address, s.address)
+		})
+	}
+}
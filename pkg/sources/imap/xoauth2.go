@@ -0,0 +1,25 @@
+package imap
+
+import (
+	"fmt"
+)
+
+// xoauth2Mechanism is the SASL mechanism of Gmail and Office 365 to
+// authenticate with OAuth2 access tokens.
+const xoauth2Mechanism = "XOAUTH2"
+
+// xoauth2 is a SASL client of the XOAUTH2 mechanism.
+type xoauth2 struct {
+	username string
+	token    string
+}
+
+func (a *xoauth2) Start() (string, []byte, error) {
+	return xoauth2Mechanism, []byte(fmt.Sprintf("user=%s\x01auth=Bearer %s\x01\x01", a.username, a.token)), nil
+}
+
+// Next answers the challenge of a failed authentication, which is a JSON
+// error, with an empty response for the server to fail the command.
+func (a *xoauth2) Next([]byte) ([]byte, error) {
+	return []byte{}, nil
+}
@@ -0,0 +1,376 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// pageSize is the number of issues and comments requested at a time,
+	// the most Jira returns.
+	pageSize = 100
+	// maxAttachmentSize is the size of the largest attachments scanned.
+	maxAttachmentSize = 250 * 1024 * 1024
+
+	locationDescription = "description"
+	locationComment     = "comment"
+	locationAttachment  = "attachment"
+)
+
+// orderBy matches the ORDER BY clause of JQL queries.
+var orderBy = regexp.MustCompile(`(?i)\s+order\s+by\s.*$`)
+
+// Source scans the descriptions, comments and attachments of the issues of
+// Jira Cloud, Server and Data Center.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	client   *http.Client
+	endpoint string
+	// authorize sets the credentials of requests.
+	authorize       func(*http.Request)
+	jql             string
+	skipAttachments bool
+	// archiveOptions configures how the archives of attachments are
+	// extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_JIRA
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of attachments are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized Jira source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+	s.client = common.RetryableHttpClientTimeout(120)
+
+	var conn sourcespb.JIRA
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	if conn.GetEndpoint() == "" {
+		return errors.New("the endpoint of Jira is required")
+	}
+	s.endpoint = strings.TrimSuffix(conn.GetEndpoint(), "/")
+
+	s.authorize = func(*http.Request) {}
+	switch cred := conn.Credential.(type) {
+	case *sourcespb.JIRA_BasicAuth:
+		// The email and API token on Jira Cloud, or the username and
+		// password on Server.
+		s.authorize = func(req *http.Request) {
+			req.SetBasicAuth(cred.BasicAuth.GetUsername(), cred.BasicAuth.GetPassword())
+		}
+	case *sourcespb.JIRA_Token:
+		// A personal access token on Server and Data Center.
+		s.authorize = func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+cred.Token) }
+	case *sourcespb.JIRA_Oauth:
+		s.authorize = func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+cred.Oauth.GetAccessToken())
+		}
+	}
+	s.jql = buildJQL(&conn, time.Now())
+	s.skipAttachments = conn.GetSkipAttachments()
+	return nil
+}
+
+// buildJQL returns the query of the issues to scan: those of the JQL of the
+// connection, in its projects and not in the projects it ignores, updated
+// since its updated_since, the least recently updated first.
+func buildJQL(conn *sourcespb.JIRA, now time.Time) string {
+	var clauses []string
+	if jql := orderBy.ReplaceAllString(strings.TrimSpace(conn.GetJql()), ""); jql != "" {
+		clauses = append(clauses, "("+jql+")")
+	}
+	if projects := conn.GetProjects(); len(projects) > 0 {
+		clauses = append(clauses, "project in ("+quoteAll(projects)+")")
+	}
+	if projects := conn.GetIgnoreProjects(); len(projects) > 0 {
+		clauses = append(clauses, "project not in ("+quoteAll(projects)+")")
+	}
+	if since := conn.GetUpdatedSince(); since != nil {
+		// Times are relative to now, as absolute ones are in the time zone
+		// of the user.
+		minutes := int64(now.Sub(since.AsTime()).Minutes()) + 1
+		clauses = append(clauses, fmt.Sprintf("updated >= -%dm", minutes))
+	}
+	return strings.Join(clauses, " AND ") + " ORDER BY updated ASC"
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+type user struct {
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+type comment struct {
+	ID      string `json:"id"`
+	Author  user   `json:"author"`
+	Body    string `json:"body"`
+	Updated string `json:"updated"`
+}
+
+type comments struct {
+	Comments []comment `json:"comments"`
+	Total    int       `json:"total"`
+}
+
+type attachment struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	Author   user   `json:"author"`
+	Created  string `json:"created"`
+	Size     int64  `json:"size"`
+	Content  string `json:"content"`
+}
+
+type issue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string       `json:"summary"`
+		Description string       `json:"description"`
+		Updated     string       `json:"updated"`
+		Reporter    user         `json:"reporter"`
+		Comment     comments     `json:"comment"`
+		Attachment  []attachment `json:"attachment"`
+	} `json:"fields"`
+}
+
+// Chunks emits the description, comments and attachments of each issue as
+// chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	var scanned uint64
+	scanErrs := sources.NewScanErrors()
+	for startAt := 0; ; {
+		var page struct {
+			Total  int     `json:"total"`
+			Issues []issue `json:"issues"`
+		}
+		query := url.Values{
+			"jql":        {s.jql},
+			"startAt":    {strconv.Itoa(startAt)},
+			"maxResults": {strconv.Itoa(pageSize)},
+			"fields":     {"summary,description,updated,reporter,comment,attachment"},
+		}
+		if err := s.get(ctx, "/rest/api/2/search?"+query.Encode(), &page); err != nil {
+			_ = s.jobPool.Wait()
+			return fmt.Errorf("error searching issues: %w", err)
+		}
+		for _, iss := range page.Issues {
+			iss := iss
+			s.jobPool.Go(func() error {
+				if err := s.scanIssue(ctx, iss, chunksChan); err != nil {
+					scanErrs.Add(fmt.Errorf("error scanning issue %s: %w", iss.Key, err))
+				}
+				n := atomic.AddUint64(&scanned, 1)
+				s.SetProgressComplete(int(n), page.Total, fmt.Sprintf("Issue: %s", iss.Key), "")
+				return nil
+			})
+		}
+		startAt += len(page.Issues)
+		if len(page.Issues) == 0 || startAt >= page.Total || common.IsDone(ctx) {
+			break
+		}
+	}
+
+	_ = s.jobPool.Wait()
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+func (s *Source) scanIssue(ctx context.Context, iss issue, chunksChan chan *sources.Chunk) error {
+	link := s.endpoint + "/browse/" + iss.Key
+	description := iss.Fields.Summary
+	if iss.Fields.Description != "" {
+		description += "\n\n" + iss.Fields.Description
+	}
+	skel := s.chunkSkeleton(iss.Key, locationDescription, link, iss.Fields.Reporter, iss.Fields.Updated)
+	if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(description), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+		return err
+	}
+
+	comments := iss.Fields.Comment.Comments
+	// Search results only have the first comments of issues.
+	if len(comments) < iss.Fields.Comment.Total {
+		var err error
+		if comments, err = s.comments(ctx, iss.Key); err != nil {
+			return err
+		}
+	}
+	for _, c := range comments {
+		commentLink := link + "?focusedCommentId=" + url.QueryEscape(c.ID)
+		skel := s.chunkSkeleton(iss.Key, locationComment+"/"+c.ID, commentLink, c.Author, c.Updated)
+		if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(c.Body), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+			return err
+		}
+	}
+
+	if s.skipAttachments {
+		return nil
+	}
+	for _, a := range iss.Fields.Attachment {
+		location := locationAttachment + "/" + a.Filename
+		if a.Size > maxAttachmentSize {
+			sources.ReportSkipBytes(ctx, iss.Key+"/"+location, sources.SkipReasonSize, a.Size)
+			continue
+		}
+		skel := s.chunkSkeleton(iss.Key, location, link, a.Author, a.Created)
+		if err := s.scanAttachment(ctx, skel, a, chunksChan); err != nil {
+			return fmt.Errorf("error scanning attachment %s: %w", a.Filename, err)
+		}
+	}
+	return nil
+}
+
+// comments returns all the comments of an issue.
+func (s *Source) comments(ctx context.Context, key string) ([]comment, error) {
+	var all []comment
+	for {
+		var page comments
+		query := url.Values{"startAt": {strconv.Itoa(len(all))}, "maxResults": {strconv.Itoa(pageSize)}}
+		if err := s.get(ctx, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment?"+query.Encode(), &page); err != nil {
+			return nil, fmt.Errorf("error listing comments: %w", err)
+		}
+		all = append(all, page.Comments...)
+		if len(page.Comments) == 0 || len(all) >= page.Total {
+			return all, nil
+		}
+	}
+}
+
+// scanAttachment scans an attachment, through the handlers of archives.
+func (s *Source) scanAttachment(ctx context.Context, skel *sources.Chunk, a attachment, chunksChan chan *sources.Chunk) error {
+	res, err := s.do(ctx, a.Content)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	reader, err := diskbufferreader.New(res.Body)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+	return sources.ChunkReaderTo(ctx, skel, reader, chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+func (s *Source) chunkSkeleton(key, location, link string, author user, timestamp string) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Jira{
+				Jira: &source_metadatapb.Jira{
+					Issue:     key,
+					Author:    author.DisplayName,
+					Email:     author.EmailAddress,
+					Link:      link,
+					Location:  location,
+					Timestamp: timestamp,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// get decodes the JSON response of a request of the REST API.
+func (s *Source) get(ctx context.Context, path string, v any) error {
+	res, err := s.do(ctx, s.endpoint+path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// do sends an authorized GET request, and returns its response if it
+// succeeded.
+func (s *Source) do(ctx context.Context, reqURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.authorize(req)
+	req.Header.Set("Accept", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+		}
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res, nil
+}
@@ -0,0 +1,166 @@
+package jira
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestBuildJQL(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		conn *sourcespb.JIRA
+		want string
+	}{
+		{&sourcespb.JIRA{}, " ORDER BY updated ASC"},
+		{
+			&sourcespb.JIRA{Jql: "labels = infra ORDER BY created DESC", Projects: []string{"OPS", "SEC"}, IgnoreProjects: []string{"HR"}},
+			`(labels = infra) AND project in ("OPS", "SEC") AND project not in ("HR") ORDER BY updated ASC`,
+		},
+		{
+			&sourcespb.JIRA{UpdatedSince: timestamppb.New(now.Add(-90 * time.Minute))},
+			"updated >= -91m ORDER BY updated ASC",
+		},
+	} {
+		assert.Equal(t, tc.want, buildJQL(tc.conn, now))
+	}
+}
+
+// fakeJira serves the issues of a Jira site of one issue per page of search
+// results, whose comments are not all in the search results.
+func fakeJira(t *testing.T, attachment []byte) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	issues := []map[string]any{
+		{
+			"key": "OPS-1",
+			"fields": map[string]any{
+				"summary":     "Rotate the database password",
+				"description": "The password is hunter2",
+				"updated":     "2024-03-01T10:00:00.000+0000",
+				"reporter":    map[string]any{"displayName": "Ada", "emailAddress": "ada@example.com"},
+				"comment": map[string]any{
+					"comments": []map[string]any{{"id": "10", "author": map[string]any{"displayName": "Bob"}, "body": "first comment"}},
+					"total":    2,
+				},
+			},
+		},
+		{
+			"key": "OPS-2",
+			"fields": map[string]any{
+				"summary": "Config of the service",
+				"attachment": []map[string]any{{
+					"id": "20", "filename": "config.zip", "size": len(attachment),
+					"author": map[string]any{"displayName": "Cy"}, "created": "2024-03-01T11:00:00.000+0000",
+				}},
+			},
+		},
+	}
+	mux.HandleFunc("/rest/api/2/search", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "ada@example.com" || pass != "api-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, `project in ("OPS") ORDER BY updated ASC`, r.URL.Query().Get("jql"))
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		page := issues[startAt : startAt+1]
+		for _, iss := range page {
+			attachments, _ := iss["fields"].(map[string]any)["attachment"].([]map[string]any)
+			for _, a := range attachments {
+				a["content"] = server.URL + "/secure/attachment/20/config.zip"
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"startAt": startAt, "total": len(issues), "issues": page})
+	})
+	mux.HandleFunc("/rest/api/2/issue/OPS-1/comment", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"total": 2,
+			"comments": []map[string]any{
+				{"id": "10", "author": map[string]any{"displayName": "Bob"}, "body": "first comment"},
+				{"id": "11", "author": map[string]any{"displayName": "Bob"}, "body": "second comment"},
+			},
+		})
+	})
+	mux.HandleFunc("/secure/attachment/20/config.zip", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(attachment)
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSource_Chunks(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create("app.env")
+	require.NoError(t, err)
+	_, err = f.Write([]byte("TOKEN=secret\n"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	server := fakeJira(t, buf.Bytes())
+
+	conn, err := anypb.New(&sourcespb.JIRA{
+		Endpoint:   server.URL + "/",
+		Credential: &sourcespb.JIRA_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: "ada@example.com", Password: "api-token"}},
+		Projects:   []string{"OPS"},
+	})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 2))
+
+	chunksChan := make(chan *sources.Chunk, 64)
+	require.NoError(t, s.Chunks(context.Background(), chunksChan))
+	close(chunksChan)
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.Jira)
+	for chunk := range chunksChan {
+		m := chunk.SourceMetadata.GetJira()
+		key := m.GetIssue() + " " + m.GetLocation()
+		got[key] += string(chunk.Data)
+		metadata[key] = m
+	}
+	assert.Equal(t, map[string]string{
+		"OPS-1 description":           "Rotate the database password\n\nThe password is hunter2",
+		"OPS-1 comment/10":            "first comment",
+		"OPS-1 comment/11":            "second comment",
+		"OPS-2 description":           "Config of the service",
+		"OPS-2 attachment/config.zip": "TOKEN=secret\n",
+	}, got)
+	assert.Equal(t, &source_metadatapb.Jira{
+		Issue:     "OPS-1",
+		Author:    "Ada",
+		Email:     "ada@example.com",
+		Link:      server.URL + "/browse/OPS-1",
+		Location:  "description",
+		Timestamp: "2024-03-01T10:00:00.000+0000",
+	}, metadata["OPS-1 description"])
+	assert.Equal(t, fmt.Sprintf("%s/browse/OPS-1?focusedCommentId=11", server.URL), metadata["OPS-1 comment/11"].GetLink())
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := fakeJira(t, nil)
+	conn, err := anypb.New(&sourcespb.JIRA{Endpoint: server.URL, Credential: &sourcespb.JIRA_Token{Token: "expired"}})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "invalid credentials")
+}
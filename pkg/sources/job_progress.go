@@ -98,6 +98,8 @@ type JobProgress struct {
 	metricsLock sync.Mutex
 	// Coarse grained hooks for adding extra functionality when events trigger.
 	hooks []JobProgressHook
+	// The initialized source, used to report its own progress.
+	source Source
 }
 
 // JobProgressMetrics tracks the metrics of a job.
@@ -113,6 +115,12 @@ type JobProgressMetrics struct {
 	TotalChunks     uint64
 	Errors          []error
 	DoneEnumerating bool
+	// Name of the source, set once it is initialized.
+	SourceName string
+	// Progress of the source through its top level targets, like
+	// repositories or buckets, as reported by the source itself.
+	SectionsCompleted int32
+	SectionsTotal     int32
 }
 
 // WithHooks adds hooks to be called when an event triggers.
@@ -209,10 +217,21 @@ func (jp *JobProgress) Snapshot() JobProgressMetrics {
 	metrics := jp.metrics
 	metrics.Errors = make([]error, len(metrics.Errors))
 	copy(metrics.Errors, jp.metrics.Errors)
+	if jp.source != nil {
+		metrics.SectionsCompleted, metrics.SectionsTotal = jp.source.GetProgress().sections()
+	}
 
 	return metrics
 }
 
+// setSource records the initialized source of the job.
+func (jp *JobProgress) setSource(name string, source Source) {
+	jp.metricsLock.Lock()
+	defer jp.metricsLock.Unlock()
+	jp.metrics.SourceName = name
+	jp.source = source
+}
+
 // ReportError adds a non-nil error to the aggregate of errors
 // encountered during scanning.
 func (jp *JobProgress) ReportError(err error) {
@@ -0,0 +1,145 @@
+package kafka
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	// maxBatchBytes is the maximum size of the batches of messages fetched,
+	// which brokers exceed for single messages larger than it.
+	maxBatchBytes = 10 << 20
+	// readTimeout is how long fetching a batch of messages may take.
+	readTimeout = time.Minute
+)
+
+// cluster reads the partitions of the topics of a Kafka cluster.
+type cluster interface {
+	// partitions returns the IDs of the partitions of the topics, or of all
+	// the topics if it is empty.
+	partitions(ctx context.Context, topics []string) (map[string][]int, error)
+	// offsets returns the offset of the first message of a partition, and
+	// the offset after its last one.
+	offsets(ctx context.Context, topic string, partition int) (first, last int64, err error)
+	// offsetAt returns the offset of the first message of a partition
+	// produced at or after t, or -1 if there is none.
+	offsetAt(ctx context.Context, topic string, partition int, t time.Time) (int64, error)
+	// read calls fn with the messages of a partition from the start offset
+	// until the end one.
+	read(ctx context.Context, topic string, partition int, start, end int64, fn func(kafka.Message) error) error
+}
+
+// kafkaCluster is a cluster reached through its bootstrap brokers.
+type kafkaCluster struct {
+	brokers []string
+	dialer  *kafka.Dialer
+}
+
+// dial connects to the first bootstrap broker that accepts the connection.
+func (c *kafkaCluster) dial(ctx context.Context) (*kafka.Conn, error) {
+	var err error
+	for _, broker := range c.brokers {
+		var conn *kafka.Conn
+		if conn, err = c.dialer.DialContext(ctx, "tcp", broker); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, fmt.Errorf("could not connect to the brokers: %w", err)
+}
+
+// leader connects to the leader of a partition.
+func (c *kafkaCluster) leader(ctx context.Context, topic string, partition int) (*kafka.Conn, error) {
+	var err error
+	for _, broker := range c.brokers {
+		var conn *kafka.Conn
+		if conn, err = c.dialer.DialLeader(ctx, "tcp", broker, topic, partition); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, fmt.Errorf("could not connect to the leader of partition %d: %w", partition, err)
+}
+
+func (c *kafkaCluster) partitions(ctx context.Context, topics []string) (map[string][]int, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	partitions, err := conn.ReadPartitions(topics...)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string][]int)
+	for _, p := range partitions {
+		ids[p.Topic] = append(ids[p.Topic], p.ID)
+	}
+	return ids, nil
+}
+
+func (c *kafkaCluster) offsets(ctx context.Context, topic string, partition int) (int64, int64, error) {
+	conn, err := c.leader(ctx, topic, partition)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer conn.Close()
+	return conn.ReadOffsets()
+}
+
+func (c *kafkaCluster) offsetAt(ctx context.Context, topic string, partition int, t time.Time) (int64, error) {
+	conn, err := c.leader(ctx, topic, partition)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	return conn.ReadOffset(t)
+}
+
+func (c *kafkaCluster) read(ctx context.Context, topic string, partition int, start, end int64, fn func(kafka.Message) error) error {
+	conn, err := c.leader(ctx, topic, partition)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.Seek(start, kafka.SeekAbsolute); err != nil {
+		return err
+	}
+
+	offset := start
+	for offset < end {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(readTimeout)); err != nil {
+			return err
+		}
+		batch := conn.ReadBatchWith(kafka.ReadBatchConfig{MinBytes: 1, MaxBytes: maxBatchBytes, MaxWait: time.Second})
+		var fnErr error
+		for {
+			msg, err := batch.ReadMessage()
+			if err != nil || msg.Offset >= end {
+				break
+			}
+			if fnErr = fn(msg); fnErr != nil {
+				break
+			}
+		}
+		next := batch.Offset()
+		if err := batch.Close(); err != nil {
+			return err
+		}
+		if fnErr != nil {
+			return fnErr
+		}
+		// Offsets of transaction markers and compacted messages have no
+		// messages, but batches always move past them.
+		if next <= offset {
+			return fmt.Errorf("could not read past offset %d", offset)
+		}
+		offset = next
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+)
+
+// Encodings of the values of messages.
+const (
+	encodingAuto  = "auto"
+	encodingPlain = "plain"
+	encodingJSON  = "json"
+	encodingAvro  = "avro"
+)
+
+// wireHeaderSize is the size of the header of the values of the Confluent
+// wire format: a zero magic byte, and the ID of their schema in the schema
+// registry.
+const wireHeaderSize = 5
+
+// decoder deserializes the values of messages into what is scanned. Values
+// that cannot be deserialized are scanned as they are.
+type decoder struct {
+	encoding string
+	registry *schemaRegistry
+}
+
+func (d *decoder) decode(ctx context.Context, value []byte) []byte {
+	switch d.encoding {
+	case encodingPlain:
+		return value
+	case encodingJSON:
+		if out, ok := flattenJSON(value); ok {
+			return out
+		}
+		return value
+	}
+
+	if d.registry != nil && len(value) > wireHeaderSize && value[0] == 0 {
+		id := binary.BigEndian.Uint32(value[1:wireHeaderSize])
+		schema, err := d.registry.schema(ctx, id)
+		if err != nil {
+			ctx.Logger().V(3).Info("could not get schema", "id", id, "error", err)
+			return value
+		}
+		payload := value[wireHeaderSize:]
+		switch schema.Type {
+		case "", "AVRO":
+			out, err := handlers.AvroDatum([]byte(schema.Schema), payload)
+			if err == nil {
+				return out
+			}
+			ctx.Logger().V(3).Info("could not decode Avro message", "schema", id, "error", err)
+		case "JSON":
+			if out, ok := flattenJSON(payload); ok {
+				return out
+			}
+		}
+		// Protobuf messages are scanned as they are, as their strings are
+		// not encoded.
+		return value
+	}
+
+	if d.encoding == encodingAuto {
+		if out, ok := flattenJSON(value); ok {
+			return out
+		}
+	}
+	return value
+}
+
+// flattenJSON returns the strings of a JSON object or array, one per line
+// after their path, like the records of Avro messages. This scans the
+// strings unescaped.
+func flattenJSON(data []byte) ([]byte, bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') || !json.Valid(data) {
+		return nil, false
+	}
+	var out bytes.Buffer
+	if err := flattenJSONValue(json.NewDecoder(bytes.NewReader(data)), "", &out); err != nil {
+		return nil, false
+	}
+	return out.Bytes(), true
+}
+
+func flattenJSONValue(dec *json.Decoder, path string, out *bytes.Buffer) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		for dec.More() {
+			itemPath := path
+			if tok == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				itemPath = key.(string)
+				if path != "" {
+					itemPath = path + "." + itemPath
+				}
+			}
+			if err := flattenJSONValue(dec, itemPath, out); err != nil {
+				return err
+			}
+		}
+		// The closing delimiter.
+		_, err := dec.Token()
+		return err
+	case string:
+		if tok == "" {
+			return nil
+		}
+		if path != "" {
+			out.WriteString(path + " = ")
+		}
+		out.WriteString(tok)
+		out.WriteByte('\n')
+	}
+	return nil
+}
+
+// schemaRegistry gets the schemas of the messages of the Confluent wire
+// format from a schema registry.
+type schemaRegistry struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+
+	mu      sync.Mutex
+	schemas map[uint32]*registeredSchema
+	errs    map[uint32]error
+}
+
+// registeredSchema is a schema of a schema registry, whose type is empty
+// for Avro.
+type registeredSchema struct {
+	Schema string `json:"schema"`
+	Type   string `json:"schemaType"`
+}
+
+func newSchemaRegistry(url, username, password string) *schemaRegistry {
+	return &schemaRegistry{
+		url:      strings.TrimSuffix(url, "/"),
+		username: username,
+		password: password,
+		client:   common.RetryableHttpClientTimeout(30),
+		schemas:  make(map[uint32]*registeredSchema),
+		errs:     make(map[uint32]error),
+	}
+}
+
+// schema returns the schema of an ID. Schemas never change, so they and the
+// errors of getting them are cached.
+func (r *schemaRegistry) schema(ctx context.Context, id uint32) (*registeredSchema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if schema, ok := r.schemas[id]; ok {
+		return schema, nil
+	}
+	if err, ok := r.errs[id]; ok {
+		return nil, err
+	}
+	schema, err := r.get(ctx, id)
+	if err != nil {
+		r.errs[id] = err
+		return nil, err
+	}
+	r.schemas[id] = schema
+	return schema, nil
+}
+
+func (r *schemaRegistry) get(ctx context.Context, id uint32) (*registeredSchema, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/schemas/ids/%d", r.url, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	var schema registeredSchema
+	if err := json.NewDecoder(resp.Body).Decode(&schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
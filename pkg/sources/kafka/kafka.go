@@ -0,0 +1,318 @@
+package kafka
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// Source scans the messages of the partitions of the topics of a Kafka
+// cluster, from an offset or a time until the last message of when the scan
+// started.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn    *sourcespb.Kafka
+	cluster cluster
+	decoder *decoder
+	// names are the topics named by the connection, and globs the globs of
+	// the names of topics, which need the list of all of them.
+	names []string
+	globs sources.Globs
+}
+
+// partition is a partition of a topic.
+type partition struct {
+	topic string
+	id    int
+}
+
+func (p partition) String() string {
+	return fmt.Sprintf("%s/%d", p.topic, p.id)
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_KAFKA
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized Kafka source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Kafka
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	if len(conn.GetBrokers()) == 0 {
+		return errors.New("a broker is required")
+	}
+	dialer := &kafka.Dialer{Timeout: 30 * time.Second, DialFunc: common.DialContext, ClientID: "trufflehog"}
+	var err error
+	if dialer.SASLMechanism, err = saslMechanism(&conn); err != nil {
+		return err
+	}
+	if conn.GetTls() {
+		dialer.TLS = common.TLSClientConfig()
+		dialer.TLS.InsecureSkipVerify = conn.GetInsecure()
+		if ca := conn.GetCaCertificate(); ca != "" {
+			if err := common.AddRootCAs(dialer.TLS, []byte(ca)); err != nil {
+				return fmt.Errorf("invalid CA certificate: %w", err)
+			}
+		}
+	}
+	s.cluster = &kafkaCluster{brokers: conn.GetBrokers(), dialer: dialer}
+
+	s.decoder = &decoder{encoding: conn.GetEncoding()}
+	switch s.decoder.encoding {
+	case "":
+		s.decoder.encoding = encodingAuto
+	case encodingAuto, encodingPlain, encodingJSON, encodingAvro:
+	default:
+		return fmt.Errorf("unknown encoding %q", conn.GetEncoding())
+	}
+	if registry := conn.GetSchemaRegistryUrl(); registry != "" {
+		s.decoder.registry = newSchemaRegistry(registry, conn.GetSchemaRegistryUsername(), conn.GetSchemaRegistryPassword())
+	} else if s.decoder.encoding == encodingAvro {
+		return errors.New("the avro encoding requires a schema registry")
+	}
+
+	// Topics without glob characters are named, and need no list.
+	var patterns []string
+	for _, pattern := range conn.GetTopics() {
+		if sources.IsGlob(pattern) {
+			patterns = append(patterns, pattern)
+		} else {
+			s.names = append(s.names, pattern)
+		}
+	}
+	globs, err := sources.CompileGlobs(patterns)
+	if err != nil {
+		return err
+	}
+	s.globs = globs
+	return nil
+}
+
+func saslMechanism(conn *sourcespb.Kafka) (sasl.Mechanism, error) {
+	switch strings.ToLower(conn.GetSaslMechanism()) {
+	case "":
+		return nil, nil
+	case "plain":
+		return plain.Mechanism{Username: conn.GetUsername(), Password: conn.GetPassword()}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, conn.GetUsername(), conn.GetPassword())
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, conn.GetUsername(), conn.GetPassword())
+	default:
+		return nil, fmt.Errorf("unknown SASL mechanism %q", conn.GetSaslMechanism())
+	}
+}
+
+// Chunks emits the values of the messages of the partitions as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	partitions, err := s.partitionsToScan(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, p := range partitions {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(partitions), fmt.Sprintf("Partition: %s", p), "")
+		p := p
+		s.jobPool.Go(func() error {
+			if err := s.scanPartition(ctx, p, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error scanning partition %s: %w", p, err))
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// EnumerateTargets reports each partition with the number of the messages
+// that would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	partitions, err := s.partitionsToScan(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range partitions {
+		start, end, err := s.offsets(ctx, p)
+		if err != nil {
+			return err
+		}
+		report(sources.Target{Name: p.String(), Objects: end - start, Bytes: -1})
+	}
+	return nil
+}
+
+// partitionsToScan returns the partitions of the named topics of the
+// connection and of the topics that match its globs, or of all the topics
+// if it has neither. Internal topics, whose names start with an
+// underscore, are only scanned when named.
+func (s *Source) partitionsToScan(ctx context.Context) ([]partition, error) {
+	var topics []string
+	if len(s.globs) == 0 {
+		topics = s.names
+	}
+	ids, err := s.cluster.partitions(ctx, topics)
+	if err != nil {
+		return nil, fmt.Errorf("error listing partitions: %w", err)
+	}
+
+	named := make(map[string]bool, len(s.names))
+	for _, name := range s.names {
+		named[name] = true
+	}
+	var partitions []partition
+	for topic, topicIDs := range ids {
+		if !named[topic] && !s.selected(topic) {
+			continue
+		}
+		for _, id := range topicIDs {
+			partitions = append(partitions, partition{topic: topic, id: id})
+		}
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].topic != partitions[j].topic {
+			return partitions[i].topic < partitions[j].topic
+		}
+		return partitions[i].id < partitions[j].id
+	})
+	return partitions, nil
+}
+
+// selected reports whether a topic that is not named matches the globs of
+// the connection, or whether the connection selects all the topics.
+func (s *Source) selected(topic string) bool {
+	if strings.HasPrefix(topic, "_") {
+		return false
+	}
+	if len(s.globs) == 0 {
+		return len(s.names) == 0
+	}
+	return s.globs.Match(topic)
+}
+
+// offsets returns the offsets of a partition to scan from and until: from
+// the start offset or the time of the connection, until the offset after
+// the last message.
+func (s *Source) offsets(ctx context.Context, p partition) (int64, int64, error) {
+	first, last, err := s.cluster.offsets(ctx, p.topic, p.id)
+	if err != nil {
+		return 0, 0, err
+	}
+	var start int64
+	switch {
+	case s.conn.GetSince() != nil:
+		if start, err = s.cluster.offsetAt(ctx, p.topic, p.id, s.conn.GetSince().AsTime()); err != nil {
+			return 0, 0, err
+		}
+		if start < 0 {
+			// No message was produced since then.
+			start = last
+		}
+	case s.conn.GetStartOffset() < 0:
+		start = last + s.conn.GetStartOffset()
+	default:
+		start = s.conn.GetStartOffset()
+	}
+	if start < first {
+		start = first
+	}
+	if start > last {
+		start = last
+	}
+	return start, last, nil
+}
+
+func (s *Source) scanPartition(ctx context.Context, p partition, chunksChan chan *sources.Chunk) error {
+	start, end, err := s.offsets(ctx, p)
+	if err != nil || start >= end {
+		return err
+	}
+	return s.cluster.read(ctx, p.topic, p.id, start, end, func(msg kafka.Message) error {
+		if len(msg.Value) == 0 {
+			return nil
+		}
+		return common.CancellableWrite(ctx, chunksChan, s.chunk(ctx, msg))
+	})
+}
+
+func (s *Source) chunk(ctx context.Context, msg kafka.Message) *sources.Chunk {
+	var timestamp string
+	if !msg.Time.IsZero() {
+		timestamp = msg.Time.UTC().Format(time.RFC3339Nano)
+	}
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		Data:       s.decoder.decode(ctx, msg.Value),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Kafka{
+				Kafka: &source_metadatapb.Kafka{
+					Topic:     sanitizer.UTF8(msg.Topic),
+					Partition: int64(msg.Partition),
+					Offset:    msg.Offset,
+					Timestamp: timestamp,
+					Key:       sanitizer.UTF8(string(msg.Key)),
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
@@ -0,0 +1,318 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+var testTime = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+// fakeCluster is a cluster of the messages of partitions, whose offsets
+// start at those of their first messages.
+type fakeCluster map[partition][]kafka.Message
+
+func (c fakeCluster) partitions(_ context.Context, topics []string) (map[string][]int, error) {
+	ids := make(map[string][]int)
+	for p := range c {
+		ids[p.topic] = append(ids[p.topic], p.id)
+	}
+	if len(topics) == 0 {
+		return ids, nil
+	}
+	selected := make(map[string][]int)
+	for _, topic := range topics {
+		if _, ok := ids[topic]; !ok {
+			return nil, kafka.UnknownTopicOrPartition
+		}
+		selected[topic] = ids[topic]
+	}
+	return selected, nil
+}
+
+func (c fakeCluster) offsets(_ context.Context, topic string, id int) (int64, int64, error) {
+	msgs := c[partition{topic: topic, id: id}]
+	if len(msgs) == 0 {
+		return 0, 0, nil
+	}
+	return msgs[0].Offset, msgs[len(msgs)-1].Offset + 1, nil
+}
+
+func (c fakeCluster) offsetAt(_ context.Context, topic string, id int, t time.Time) (int64, error) {
+	for _, msg := range c[partition{topic: topic, id: id}] {
+		if !msg.Time.Before(t) {
+			return msg.Offset, nil
+		}
+	}
+	return -1, nil
+}
+
+func (c fakeCluster) read(_ context.Context, topic string, id int, start, end int64, fn func(kafka.Message) error) error {
+	for _, msg := range c[partition{topic: topic, id: id}] {
+		if msg.Offset >= start && msg.Offset < end {
+			if err := fn(msg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// messages returns the messages of the values of a partition from offset
+// first, one hour apart.
+func messages(topic string, id int, first int64, values ...string) []kafka.Message {
+	msgs := make([]kafka.Message, len(values))
+	for i, value := range values {
+		offset := first + int64(i)
+		msgs[i] = kafka.Message{
+			Topic:     topic,
+			Partition: id,
+			Offset:    offset,
+			Key:       []byte("key-" + strconv.FormatInt(offset, 10)),
+			Value:     []byte(value),
+			Time:      testTime.Add(time.Duration(offset) * time.Hour),
+		}
+	}
+	return msgs
+}
+
+func testCluster() fakeCluster {
+	return fakeCluster{
+		{topic: "orders", id: 0}:             messages("orders", 0, 10, "order 10", "order 11", "order 12"),
+		{topic: "orders", id: 1}:             messages("orders", 1, 0, "order 0", "", "order 2"),
+		{topic: "users", id: 0}:              messages("users", 0, 0, `{"name":"ada","auth":{"token":"ghp_x"}}`),
+		{topic: "__consumer_offsets", id: 0}: messages("__consumer_offsets", 0, 0, "offsets"),
+	}
+}
+
+func initSource(t *testing.T, conn *sourcespb.Kafka, c cluster) *Source {
+	t.Helper()
+	if len(conn.Brokers) == 0 {
+		conn.Brokers = []string{"localhost:9092"}
+	}
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+	s.cluster = c
+	return s
+}
+
+// chunks returns the chunks of a source by the topic/partition@offset of
+// their message.
+func chunks(t *testing.T, s *Source) map[string]*sources.Chunk {
+	t.Helper()
+	got := make(map[string]*sources.Chunk)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		meta := chunk.SourceMetadata.GetKafka()
+		got[fmt.Sprintf("%s/%d@%d", meta.GetTopic(), meta.GetPartition(), meta.GetOffset())] = chunk
+	}
+	return got
+}
+
+func keys(m map[string]*sources.Chunk) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestSource_Chunks(t *testing.T) {
+	got := chunks(t, initSource(t, &sourcespb.Kafka{}, testCluster()))
+	// Internal topics are skipped, and so are messages without values.
+	assert.ElementsMatch(t, []string{"orders/0@10", "orders/0@11", "orders/0@12", "orders/1@0", "orders/1@2", "users/0@0"}, keys(got))
+
+	chunk := got["orders/0@11"]
+	assert.Equal(t, "order 11", string(chunk.Data))
+	assert.Equal(t, &source_metadatapb.Kafka{
+		Topic:     "orders",
+		Partition: 0,
+		Offset:    11,
+		Timestamp: "2024-01-02T11:00:00Z",
+		Key:       "key-11",
+	}, chunk.SourceMetadata.GetKafka())
+	// JSON values are scanned as their strings.
+	assert.Equal(t, "name = ada\nauth.token = ghp_x\n", string(got["users/0@0"].Data))
+}
+
+func TestSource_ChunksTopics(t *testing.T) {
+	for name, tt := range map[string]struct {
+		topics []string
+		want   []string
+	}{
+		"named": {
+			topics: []string{"users", "__consumer_offsets"},
+			want:   []string{"users/0@0", "__consumer_offsets/0@0"},
+		},
+		"globs": {
+			topics: []string{"ord*", "*offsets"},
+			want:   []string{"orders/0@10", "orders/0@11", "orders/0@12", "orders/1@0", "orders/1@2"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := chunks(t, initSource(t, &sourcespb.Kafka{Topics: tt.topics}, testCluster()))
+			assert.ElementsMatch(t, tt.want, keys(got))
+		})
+	}
+}
+
+func TestSource_Offsets(t *testing.T) {
+	for name, tt := range map[string]struct {
+		conn       *sourcespb.Kafka
+		start, end int64
+	}{
+		"earliest":            {conn: &sourcespb.Kafka{}, start: 10, end: 13},
+		"offset":              {conn: &sourcespb.Kafka{StartOffset: 11}, start: 11, end: 13},
+		"offset past the end": {conn: &sourcespb.Kafka{StartOffset: 20}, start: 13, end: 13},
+		"last messages":       {conn: &sourcespb.Kafka{StartOffset: -2}, start: 11, end: 13},
+		"more last messages":  {conn: &sourcespb.Kafka{StartOffset: -10}, start: 10, end: 13},
+		"since":               {conn: &sourcespb.Kafka{Since: timestamppb.New(testTime.Add(11*time.Hour + time.Minute))}, start: 12, end: 13},
+		"since the last":      {conn: &sourcespb.Kafka{Since: timestamppb.New(testTime.Add(24 * time.Hour))}, start: 13, end: 13},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := initSource(t, tt.conn, testCluster())
+			start, end, err := s.offsets(context.Background(), partition{topic: "orders", id: 0})
+			require.NoError(t, err)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	s := initSource(t, &sourcespb.Kafka{Topics: []string{"orders"}, StartOffset: -1}, testCluster())
+	var targets []sources.Target
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		targets = append(targets, target)
+	}))
+	assert.Equal(t, []sources.Target{
+		{Name: "orders/0", Objects: 1, Bytes: -1},
+		{Name: "orders/1", Objects: 1, Bytes: -1},
+	}, targets)
+}
+
+// wireFormat returns a value of the Confluent wire format.
+func wireFormat(id uint32, payload []byte) []byte {
+	value := []byte{0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(value[1:], id)
+	return append(value, payload...)
+}
+
+func avroString(b []byte, s string) []byte {
+	return append(binary.AppendVarint(b, int64(len(s))), s...)
+}
+
+func TestSource_ChunksSchemaRegistry(t *testing.T) {
+	var requests int
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "registry", user)
+		assert.Equal(t, "secret", pass)
+		switch r.URL.Path {
+		case "/schemas/ids/1":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"schema": `{"type":"record","name":"Login","fields":[{"name":"user","type":"string"},{"name":"password","type":"string"}]}`,
+			})
+		case "/schemas/ids/2":
+			_ = json.NewEncoder(w).Encode(map[string]string{"schema": `{"type":"object"}`, "schemaType": "JSON"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+
+	login := avroString(avroString(nil, "ada"), "hunter2")
+	c := fakeCluster{
+		{topic: "logins", id: 0}: messages("logins", 0, 0,
+			string(wireFormat(1, login)),
+			string(wireFormat(1, login)),
+			string(wireFormat(2, []byte(`{"api_key":"xyz"}`))),
+			string(wireFormat(3, []byte("unknown schema"))),
+			string(wireFormat(3, []byte("unknown schema"))),
+		),
+	}
+	s := initSource(t, &sourcespb.Kafka{
+		Encoding:               encodingAvro,
+		SchemaRegistryUrl:      registry.URL,
+		SchemaRegistryUsername: "registry",
+		SchemaRegistryPassword: "secret",
+	}, c)
+	s.jobPool.SetLimit(1)
+
+	got := chunks(t, s)
+	assert.Equal(t, "user = ada\npassword = hunter2\n", string(got["logins/0@0"].Data))
+	assert.Equal(t, "user = ada\npassword = hunter2\n", string(got["logins/0@1"].Data))
+	assert.Equal(t, "api_key = xyz\n", string(got["logins/0@2"].Data))
+	// Values whose schema is missing are scanned as they are.
+	assert.Equal(t, wireFormat(3, []byte("unknown schema")), got["logins/0@3"].Data)
+	// Schemas and their errors are cached.
+	assert.Equal(t, 3, requests)
+}
+
+func TestDecoder(t *testing.T) {
+	ctx := context.Background()
+	for name, tt := range map[string]struct {
+		encoding string
+		value    string
+		want     string
+	}{
+		"auto json":        {encoding: encodingAuto, value: ` [{"a":"x+y"}, "b", {"c":{"d":"e","n":1}}]`, want: "a = x+y\nb\nc.d = e\n"},
+		"auto plain":       {encoding: encodingAuto, value: `password=hunter2`, want: `password=hunter2`},
+		"auto json scalar": {encoding: encodingAuto, value: `"quoted"`, want: `"quoted"`},
+		"invalid json":     {encoding: encodingJSON, value: `{"a":`, want: `{"a":`},
+		"plain":            {encoding: encodingPlain, value: `{"a":"b"}`, want: `{"a":"b"}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := &decoder{encoding: tt.encoding}
+			assert.Equal(t, tt.want, string(d.decode(ctx, []byte(tt.value))))
+		})
+	}
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, tt := range map[string]struct {
+		conn    *sourcespb.Kafka
+		wantErr string
+	}{
+		"no brokers":       {conn: &sourcespb.Kafka{}, wantErr: "a broker is required"},
+		"unknown SASL":     {conn: &sourcespb.Kafka{Brokers: []string{"b:9092"}, SaslMechanism: "gssapi"}, wantErr: "unknown SASL mechanism"},
+		"unknown encoding": {conn: &sourcespb.Kafka{Brokers: []string{"b:9092"}, Encoding: "xml"}, wantErr: "unknown encoding"},
+		"avro without registry": {
+			conn:    &sourcespb.Kafka{Brokers: []string{"b:9092"}, Encoding: encodingAvro},
+			wantErr: "requires a schema registry",
+		},
+		"invalid glob": {conn: &sourcespb.Kafka{Brokers: []string{"b:9092"}, Topics: []string{"[a"}}, wantErr: "invalid glob"},
+		"invalid CA": {
+			conn:    &sourcespb.Kafka{Brokers: []string{"b:9092"}, Tls: true, CaCertificate: "not a certificate"},
+			wantErr: "invalid CA certificate",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(tt.conn)
+			require.NoError(t, err)
+			err = (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1)
+			require.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), tt.wantErr), err.Error())
+		})
+	}
+
+	anyConn, err := anypb.New(&sourcespb.Kafka{Brokers: []string{"b:9092"}, SaslMechanism: "SCRAM-SHA-512", Username: "u", Password: "p"})
+	require.NoError(t, err)
+	assert.NoError(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+}
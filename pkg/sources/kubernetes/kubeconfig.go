@@ -0,0 +1,310 @@
+package kubernetes
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+// The files of the credentials of the service account of pods.
+var (
+	serviceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCA    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// kubeconfig is the part of a kubeconfig file that describes how to connect
+// to clusters.
+type kubeconfig struct {
+	CurrentContext string `json:"current-context"`
+	Contexts       []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster string `json:"cluster"`
+			User    string `json:"user"`
+		} `json:"context"`
+	} `json:"contexts"`
+	Clusters []struct {
+		Name    string `json:"name"`
+		Cluster struct {
+			Server                   string `json:"server"`
+			CertificateAuthority     string `json:"certificate-authority"`
+			CertificateAuthorityData string `json:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify"`
+			TLSServerName            string `json:"tls-server-name"`
+		} `json:"cluster"`
+	} `json:"clusters"`
+	Users []struct {
+		Name string   `json:"name"`
+		User authInfo `json:"user"`
+	} `json:"users"`
+}
+
+type authInfo struct {
+	Token                 string `json:"token"`
+	TokenFile             string `json:"tokenFile"`
+	ClientCertificate     string `json:"client-certificate"`
+	ClientCertificateData string `json:"client-certificate-data"`
+	ClientKey             string `json:"client-key"`
+	ClientKeyData         string `json:"client-key-data"`
+	Username              string `json:"username"`
+	Password              string `json:"password"`
+	Exec                  *struct {
+		Command string   `json:"command"`
+		Args    []string `json:"args"`
+		Env     []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"env"`
+	} `json:"exec"`
+	AuthProvider *struct {
+		Name string `json:"name"`
+	} `json:"auth-provider"`
+}
+
+// cluster is how to connect to the API server of a cluster.
+type cluster struct {
+	// name is the name of the context of the cluster.
+	name   string
+	server string
+	client *http.Client
+	// authorize sets the credentials of requests.
+	authorize func(*http.Request) error
+}
+
+// loadKubeconfig returns the cluster of a context of a kubeconfig file, or
+// of its current context if contextName is empty. Relative paths in the file
+// are relative to its directory.
+func loadKubeconfig(path, contextName string) (*cluster, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading kubeconfig: %w", err)
+	}
+	var config kubeconfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error decoding kubeconfig %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+	var clusterName, userName string
+	found := false
+	for _, c := range config.Contexts {
+		if c.Name == contextName {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("context %q not found in kubeconfig %s", contextName, path)
+	}
+
+	c := &cluster{name: contextName}
+	tlsConfig := common.TLSClientConfig()
+	found = false
+	for _, cl := range config.Clusters {
+		if cl.Name != clusterName {
+			continue
+		}
+		found = true
+		c.server = cl.Cluster.Server
+		tlsConfig.InsecureSkipVerify = cl.Cluster.InsecureSkipTLSVerify
+		tlsConfig.ServerName = cl.Cluster.TLSServerName
+		ca, err := dataOrFile(cl.Cluster.CertificateAuthorityData, resolve(cl.Cluster.CertificateAuthority))
+		if err != nil {
+			return nil, fmt.Errorf("error reading the certificate authority: %w", err)
+		}
+		if ca != nil {
+			if tlsConfig.RootCAs, err = certPool(ca); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("cluster %q not found in kubeconfig %s", clusterName, path)
+	}
+
+	var user authInfo
+	for _, u := range config.Users {
+		if u.Name == userName {
+			user = u.User
+		}
+	}
+	if user.AuthProvider != nil {
+		return nil, fmt.Errorf("the %s auth provider is not supported, use an exec credential plugin instead", user.AuthProvider.Name)
+	}
+	cert, err := dataOrFile(user.ClientCertificateData, resolve(user.ClientCertificate))
+	if err != nil {
+		return nil, fmt.Errorf("error reading the client certificate: %w", err)
+	}
+	key, err := dataOrFile(user.ClientKeyData, resolve(user.ClientKey))
+	if err != nil {
+		return nil, fmt.Errorf("error reading the client key: %w", err)
+	}
+	if cert != nil || key != nil {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	c.client = common.RetryableHttpClientTLS(120, tlsConfig)
+	switch {
+	case user.Token != "":
+		c.authorize = bearer(func() (string, error) { return user.Token, nil })
+	case user.TokenFile != "":
+		c.authorize = bearer(tokenFile(resolve(user.TokenFile)))
+	case user.Exec != nil:
+		exec := user.Exec
+		env := make([]string, 0, len(exec.Env))
+		for _, e := range exec.Env {
+			env = append(env, e.Name+"="+e.Value)
+		}
+		c.authorize = bearer(cachedToken(func() (string, time.Time, error) {
+			return execToken(exec.Command, exec.Args, env)
+		}))
+	case user.Username != "":
+		c.authorize = func(req *http.Request) error {
+			req.SetBasicAuth(user.Username, user.Password)
+			return nil
+		}
+	default:
+		c.authorize = func(*http.Request) error { return nil }
+	}
+	return c, nil
+}
+
+// inCluster returns the cluster of the pod it runs in, which is accessed
+// as the service account of the pod.
+func inCluster() (*cluster, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a cluster")
+	}
+	ca, err := os.ReadFile(serviceAccountCA)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the certificate authority: %w", err)
+	}
+	tlsConfig := common.TLSClientConfig()
+	if tlsConfig.RootCAs, err = certPool(ca); err != nil {
+		return nil, err
+	}
+	return &cluster{
+		name:      "in-cluster",
+		server:    "https://" + net.JoinHostPort(host, port),
+		client:    common.RetryableHttpClientTLS(120, tlsConfig),
+		authorize: bearer(tokenFile(serviceAccountToken)),
+	}, nil
+}
+
+// bearer authorizes requests with the tokens of a function.
+func bearer(token func() (string, error)) func(*http.Request) error {
+	return func(req *http.Request) error {
+		t, err := token()
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+t)
+		return nil
+	}
+}
+
+// cachedToken returns the token of fetch until it expires, or for good if
+// its expiry is zero.
+func cachedToken(fetch func() (string, time.Time, error)) func() (string, error) {
+	var mu sync.Mutex
+	var token string
+	var expiry time.Time
+	return func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if token != "" && (expiry.IsZero() || time.Now().Before(expiry)) {
+			return token, nil
+		}
+		t, exp, err := fetch()
+		if err != nil {
+			return "", err
+		}
+		token, expiry = t, exp
+		return token, nil
+	}
+}
+
+// tokenFile returns the token of a file, which is read again every minute
+// as service account tokens are rotated.
+func tokenFile(path string) func() (string, error) {
+	return cachedToken(func() (string, time.Time, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("error reading token: %w", err)
+		}
+		return string(bytes.TrimSpace(data)), time.Now().Add(time.Minute), nil
+	})
+}
+
+// execToken returns the token of an exec credential plugin, and when it
+// expires.
+func execToken(command string, args, env []string) (string, time.Time, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error running the credential plugin %s: %w", command, err)
+	}
+	var credential struct {
+		Status struct {
+			Token               string    `json:"token"`
+			ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(out, &credential); err != nil {
+		return "", time.Time{}, fmt.Errorf("error decoding the credential of %s: %w", command, err)
+	}
+	if credential.Status.Token == "" {
+		return "", time.Time{}, fmt.Errorf("the credential plugin %s returned no token", command)
+	}
+	return credential.Status.Token, credential.Status.ExpirationTimestamp, nil
+}
+
+// dataOrFile returns the decoded base64 data, or else the content of the
+// file, or nil if both are empty.
+func dataOrFile(data, path string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if path != "" {
+		return os.ReadFile(path)
+	}
+	return nil, nil
+}
+
+func certPool(pem []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("invalid certificate authority")
+	}
+	return pool, nil
+}
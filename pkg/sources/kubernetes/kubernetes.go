@@ -0,0 +1,560 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// listLimit is the number of objects of the pages of lists.
+const listLimit = 500
+
+// errUnavailable is returned for the resources that the credentials are not
+// allowed to list, or that were deleted since they were listed, which are
+// skipped.
+var errUnavailable = errors.New("resource unavailable")
+
+// Source scans the objects of a Kubernetes cluster: the data of ConfigMaps
+// and Secrets, the environment variables and arguments of the containers of
+// Pods, the specs of custom resources and the annotations of all of them.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn              *sourcespb.Kubernetes
+	cluster           *cluster
+	excludeNamespaces sources.Globs
+}
+
+// resource is a kind of object of the API.
+type resource struct {
+	kind string
+	// groupVersion is the API group and version of the resource, v1 for the
+	// core group.
+	groupVersion string
+	plural       string
+	namespaced   bool
+}
+
+// path returns the path of the list of the objects of the resource in a
+// namespace, or in the cluster if namespace is empty.
+func (r resource) path(namespace string) string {
+	p := "/apis/" + r.groupVersion
+	if r.groupVersion == "v1" {
+		p = "/api/v1"
+	}
+	if namespace != "" {
+		p += "/namespaces/" + url.PathEscape(namespace)
+	}
+	return p + "/" + r.plural
+}
+
+var (
+	secrets    = resource{kind: "Secret", groupVersion: "v1", plural: "secrets", namespaced: true}
+	configMaps = resource{kind: "ConfigMap", groupVersion: "v1", plural: "configmaps", namespaced: true}
+	pods       = resource{kind: "Pod", groupVersion: "v1", plural: "pods", namespaced: true}
+)
+
+// objectMeta is the metadata of an object.
+type objectMeta struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// object is the part of an object that is scanned. Which fields are set
+// depends on its kind.
+type object struct {
+	Metadata objectMeta `json:"metadata"`
+	// Data is the data of ConfigMaps, or the base64 data of Secrets, which
+	// is decoded as a []byte.
+	Data       json.RawMessage   `json:"data"`
+	BinaryData map[string][]byte `json:"binaryData"`
+	Spec       json.RawMessage   `json:"spec"`
+}
+
+// podSpec is the part of the spec of a Pod that is scanned.
+type podSpec struct {
+	Containers          []container `json:"containers"`
+	InitContainers      []container `json:"initContainers"`
+	EphemeralContainers []container `json:"ephemeralContainers"`
+}
+
+type container struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+	Args    []string `json:"args"`
+	Env     []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"env"`
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_KUBERNETES
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized Kubernetes source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Kubernetes
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	var err error
+	if conn.GetInCluster() {
+		s.cluster, err = inCluster()
+	} else {
+		// KUBECONFIG can be a list of files, which are not merged: the
+		// first one is used.
+		path := conn.GetKubeconfig()
+		if paths := filepath.SplitList(path); len(paths) > 0 {
+			path = paths[0]
+		}
+		if path == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return errors.WrapPrefix(err, "error finding the kubeconfig", 0)
+			}
+			path = filepath.Join(home, ".kube", "config")
+		}
+		s.cluster, err = loadKubeconfig(path, conn.GetContext())
+	}
+	if err != nil {
+		return err
+	}
+	if s.cluster.server == "" {
+		return fmt.Errorf("the cluster of %s has no server", s.cluster.name)
+	}
+
+	excludeNamespaces, err := sources.CompileGlobs(conn.GetExcludeNamespaces())
+	if err != nil {
+		return err
+	}
+	s.excludeNamespaces = excludeNamespaces
+	return nil
+}
+
+// Chunks emits the scanned fields of the objects of the cluster as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	namespaces, err := s.namespaces(ctx)
+	if err != nil {
+		return err
+	}
+	resources, err := s.resources(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, namespace := range namespaces {
+		if common.IsDone(ctx) {
+			break
+		}
+		if namespace == "" {
+			s.SetProgressComplete(i, len(namespaces), "Cluster-scoped resources", "")
+		} else {
+			s.SetProgressComplete(i, len(namespaces), fmt.Sprintf("Namespace: %s", namespace), "")
+		}
+		for _, r := range resources {
+			if r.namespaced != (namespace != "") {
+				continue
+			}
+			r, namespace := r, namespace
+			s.jobPool.Go(func() error {
+				err := s.list(ctx, r.path(namespace), func(raw json.RawMessage) error {
+					return s.scanObject(ctx, r, raw, chunksChan)
+				})
+				switch {
+				case errors.Is(err, errUnavailable):
+					ctx.Logger().V(2).Info("could not list resource", "kind", r.kind, "namespace", namespace)
+					sources.ReportSkip(ctx, s.link(r, namespace), sources.SkipReasonError)
+				case err != nil:
+					scanErrs.Add(fmt.Errorf("error scanning %s: %w", s.link(r, namespace), err))
+				}
+				return nil
+			})
+		}
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// EnumerateTargets reports each namespace with the number and size of the
+// objects that would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	namespaces, err := s.namespaces(ctx)
+	if err != nil {
+		return err
+	}
+	resources, err := s.resources(ctx)
+	if err != nil {
+		return err
+	}
+	for _, namespace := range namespaces {
+		target := sources.Target{Name: s.cluster.name + "/" + namespace}
+		if namespace == "" {
+			target.Name = s.cluster.name
+		}
+		for _, r := range resources {
+			if r.namespaced != (namespace != "") {
+				continue
+			}
+			err := s.list(ctx, r.path(namespace), func(raw json.RawMessage) error {
+				target.Objects++
+				target.Bytes += int64(len(raw))
+				return nil
+			})
+			if err != nil && !errors.Is(err, errUnavailable) {
+				return err
+			}
+		}
+		report(target)
+	}
+	return nil
+}
+
+// namespaces returns the namespaces to scan: those of the connection, or
+// else all the namespaces of the cluster, less the excluded ones. The
+// cluster-scoped resources are scanned with all the namespaces, as the
+// namespace "".
+func (s *Source) namespaces(ctx context.Context) ([]string, error) {
+	names := s.conn.GetNamespaces()
+	all := len(names) == 0
+	if all {
+		err := s.list(ctx, "/api/v1/namespaces", func(raw json.RawMessage) error {
+			var ns object
+			if err := json.Unmarshal(raw, &ns); err != nil {
+				return err
+			}
+			names = append(names, ns.Metadata.Name)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing namespaces: %w", err)
+		}
+	}
+
+	var namespaces []string
+	for _, name := range names {
+		if !s.excludeNamespaces.Match(name) {
+			namespaces = append(namespaces, name)
+		}
+	}
+	if all {
+		namespaces = append(namespaces, "")
+	}
+	return namespaces, nil
+}
+
+// resources returns the resources to scan: the built-in ones that are not
+// skipped and, unless they are skipped, the custom resources of the
+// definitions of the cluster.
+func (s *Source) resources(ctx context.Context) ([]resource, error) {
+	var resources []resource
+	if !s.conn.GetSkipSecrets() {
+		resources = append(resources, secrets)
+	}
+	if !s.conn.GetSkipConfigMaps() {
+		resources = append(resources, configMaps)
+	}
+	if !s.conn.GetSkipPods() {
+		resources = append(resources, pods)
+	}
+	if s.conn.GetSkipCustomResources() {
+		return resources, nil
+	}
+
+	err := s.list(ctx, "/apis/apiextensions.k8s.io/v1/customresourcedefinitions", func(raw json.RawMessage) error {
+		var crd struct {
+			Spec struct {
+				Group string `json:"group"`
+				Names struct {
+					Kind   string `json:"kind"`
+					Plural string `json:"plural"`
+				} `json:"names"`
+				Scope    string `json:"scope"`
+				Versions []struct {
+					Name    string `json:"name"`
+					Served  bool   `json:"served"`
+					Storage bool   `json:"storage"`
+				} `json:"versions"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(raw, &crd); err != nil {
+			return err
+		}
+		// The objects are listed in the version they are stored in, or
+		// else in the first version that is served.
+		var version string
+		for _, v := range crd.Spec.Versions {
+			if v.Served && (v.Storage || version == "") {
+				version = v.Name
+			}
+		}
+		if version == "" {
+			return nil
+		}
+		resources = append(resources, resource{
+			kind:         crd.Spec.Names.Kind,
+			groupVersion: crd.Spec.Group + "/" + version,
+			plural:       crd.Spec.Names.Plural,
+			namespaced:   crd.Spec.Scope != "Cluster",
+		})
+		return nil
+	})
+	switch {
+	case errors.Is(err, errUnavailable):
+		ctx.Logger().V(2).Info("could not list custom resource definitions", "error", err)
+	case err != nil:
+		return nil, fmt.Errorf("error listing custom resource definitions: %w", err)
+	}
+	return resources, nil
+}
+
+// scanObject emits the scanned fields of an object.
+func (s *Source) scanObject(ctx context.Context, r resource, raw json.RawMessage, chunksChan chan *sources.Chunk) error {
+	var obj object
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return fmt.Errorf("error decoding %s: %w", r.kind, err)
+	}
+	emit := func(field string, data []byte) error {
+		if len(data) == 0 {
+			return nil
+		}
+		return common.CancellableWrite(ctx, chunksChan, s.chunk(r, obj.Metadata, field, data))
+	}
+
+	if err := emit("metadata.annotations", keyValues(obj.Metadata.Annotations)); err != nil {
+		return err
+	}
+	switch r {
+	case secrets:
+		var data map[string][]byte
+		if err := json.Unmarshal(nonNull(obj.Data), &data); err != nil {
+			return fmt.Errorf("error decoding the data of %s: %w", obj.Metadata.Name, err)
+		}
+		return emit("data", keyValues(data))
+	case configMaps:
+		var data map[string]string
+		if err := json.Unmarshal(nonNull(obj.Data), &data); err != nil {
+			return fmt.Errorf("error decoding the data of %s: %w", obj.Metadata.Name, err)
+		}
+		if err := emit("data", keyValues(data)); err != nil {
+			return err
+		}
+		return emit("binaryData", keyValues(obj.BinaryData))
+	case pods:
+		var spec podSpec
+		if err := json.Unmarshal(nonNull(obj.Spec), &spec); err != nil {
+			return fmt.Errorf("error decoding the spec of %s: %w", obj.Metadata.Name, err)
+		}
+		for _, list := range []struct {
+			field      string
+			containers []container
+		}{
+			{"initContainers", spec.InitContainers},
+			{"containers", spec.Containers},
+			{"ephemeralContainers", spec.EphemeralContainers},
+		} {
+			for _, c := range list.containers {
+				if err := s.scanContainer(emit, fmt.Sprintf("spec.%s[%s]", list.field, c.Name), c); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	default:
+		if string(obj.Spec) == "null" {
+			return nil
+		}
+		return emit("spec", obj.Spec)
+	}
+}
+
+// scanContainer emits the environment variables, command and arguments of a
+// container, whose values are not references to other objects.
+func (s *Source) scanContainer(emit func(string, []byte) error, field string, c container) error {
+	env := make(map[string]string, len(c.Env))
+	for _, e := range c.Env {
+		if e.Value != "" {
+			env[e.Name] = e.Value
+		}
+	}
+	if err := emit(field+".env", keyValues(env)); err != nil {
+		return err
+	}
+	if err := emit(field+".command", []byte(strings.Join(c.Command, " "))); err != nil {
+		return err
+	}
+	return emit(field+".args", []byte(strings.Join(c.Args, " ")))
+}
+
+func (s *Source) chunk(r resource, meta objectMeta, field string, data []byte) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		Data:       data,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Kubernetes{
+				Kubernetes: &source_metadatapb.Kubernetes{
+					Cluster:   sanitizer.UTF8(s.cluster.name),
+					Namespace: meta.Namespace,
+					Kind:      r.kind,
+					Name:      meta.Name,
+					Field:     sanitizer.UTF8(field),
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// link returns the name of the objects of a resource in a namespace.
+func (s *Source) link(r resource, namespace string) string {
+	if namespace == "" {
+		return s.cluster.name + "/" + r.plural
+	}
+	return s.cluster.name + "/" + namespace + "/" + r.plural
+}
+
+// list calls fn with each object of a list of the API, which is read by
+// pages.
+func (s *Source) list(ctx context.Context, path string, fn func(json.RawMessage) error) error {
+	var continueToken string
+	for {
+		query := url.Values{"limit": {fmt.Sprint(listLimit)}}
+		if continueToken != "" {
+			query.Set("continue", continueToken)
+		}
+		var page struct {
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := s.get(ctx, path, query, &page); err != nil {
+			return err
+		}
+		for _, item := range page.Items {
+			if common.IsDone(ctx) {
+				return ctx.Err()
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if page.Metadata.Continue == "" {
+			return nil
+		}
+		continueToken = page.Metadata.Continue
+	}
+}
+
+// get decodes the response of a request to the API.
+func (s *Source) get(ctx context.Context, path string, query url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(s.cluster.server, "/")+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if err := s.cluster.authorize(req); err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.cluster.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return json.NewDecoder(resp.Body).Decode(out)
+	case http.StatusUnauthorized:
+		return fmt.Errorf("invalid credentials, status %d", resp.StatusCode)
+	case http.StatusForbidden, http.StatusNotFound:
+		return fmt.Errorf("%w: status %d for %s", errUnavailable, resp.StatusCode, path)
+	default:
+		return fmt.Errorf("unexpected status %d for %s", resp.StatusCode, path)
+	}
+}
+
+// keyValues returns the key=value lines of a map, sorted by key, which keeps
+// the names of the values next to them for the detectors.
+func keyValues[V string | []byte](m map[string]V) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(string(m[k]))
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// nonNull returns null for missing fields, which decodes to zero values.
+func nonNull(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+	return raw
+}
@@ -0,0 +1,242 @@
+package kubernetes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const testToken = "k8s-token"
+
+func list(items ...string) string {
+	return page("", items...)
+}
+
+// page returns a page of a list, which is followed by the page of the
+// continue token if it is not empty.
+func page(continueToken string, items ...string) string {
+	data, _ := json.Marshal(map[string]any{
+		"metadata": map[string]string{"continue": continueToken},
+		"items":    json.RawMessage("[" + strings.Join(items, ",") + "]"),
+	})
+	return string(data)
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// testServer returns an API server of a cluster with a few objects.
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	responses := map[string]string{
+		"/api/v1/namespaces": list(
+			`{"metadata":{"name":"prod"}}`,
+			`{"metadata":{"name":"dev"}}`,
+			`{"metadata":{"name":"kube-system"}}`,
+		),
+		"/api/v1/namespaces/prod/secrets": page("page2",
+			fmt.Sprintf(`{"metadata":{"name":"db","namespace":"prod"},"data":{"password":%q,"user":%q}}`, b64("hunter2"), b64("admin")),
+		),
+		"/api/v1/namespaces/prod/secrets?page2": list(
+			`{"metadata":{"name":"empty","namespace":"prod"}}`,
+		),
+		"/api/v1/namespaces/prod/configmaps": list(
+			fmt.Sprintf(`{"metadata":{"name":"app","namespace":"prod","annotations":{"owner":"team-a"}},"data":{"app.properties":"token=abc"},"binaryData":{"key.bin":%q}}`, b64("AKIA")),
+		),
+		"/api/v1/namespaces/prod/pods": list(
+			`{"metadata":{"name":"web-1","namespace":"prod"},"spec":{"containers":[{"name":"web","command":["/bin/web"],"args":["--password","s3cret"],"env":[{"name":"API_KEY","value":"xyz"},{"name":"FROM_SECRET","valueFrom":{"secretKeyRef":{"name":"db","key":"password"}}}]}],"initContainers":[{"name":"init","env":[{"name":"TOKEN","value":"t0k"}]}]}}`,
+		),
+		"/api/v1/namespaces/dev/secrets":    list(),
+		"/api/v1/namespaces/dev/configmaps": list(),
+		"/api/v1/namespaces/dev/pods":       list(),
+		"/apis/apiextensions.k8s.io/v1/customresourcedefinitions": list(
+			`{"spec":{"group":"example.com","names":{"kind":"Database","plural":"databases"},"scope":"Namespaced","versions":[{"name":"v1beta1","served":true},{"name":"v1","served":true,"storage":true}]}}`,
+			`{"spec":{"group":"example.com","names":{"kind":"Vault","plural":"vaults"},"scope":"Cluster","versions":[{"name":"v1","served":true,"storage":true}]}}`,
+		),
+		"/apis/example.com/v1/namespaces/prod/databases": list(
+			`{"metadata":{"name":"orders","namespace":"prod"},"spec":{"url":"postgres://u:p@db"}}`,
+		),
+		"/apis/example.com/v1/namespaces/dev/databases": list(),
+		"/apis/example.com/v1/vaults": list(
+			`{"metadata":{"name":"main"},"spec":{"unsealKey":"k"}}`,
+		),
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "500", r.URL.Query().Get("limit"))
+		key := r.URL.Path
+		if c := r.URL.Query().Get("continue"); c != "" {
+			key += "?" + c
+		}
+		resp, ok := responses[key]
+		if !ok {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeKubeconfig writes a kubeconfig of a server that authenticates with a
+// token file, and returns its path.
+func writeKubeconfig(t *testing.T, server *httptest.Server, token string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte(token+"\n"), 0o600))
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	config := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: test
+contexts:
+- name: test
+  context:
+    cluster: test-cluster
+    user: test-user
+- name: other
+  context:
+    cluster: missing
+clusters:
+- name: test-cluster
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+users:
+- name: test-user
+  user:
+    tokenFile: token
+`, server.URL, base64.StdEncoding.EncodeToString(ca))
+	path := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(path, []byte(config), 0o600))
+	return path
+}
+
+// chunks returns the data of the chunks of a source by
+// kind/namespace/name/field.
+func chunks(t *testing.T, conn *sourcespb.Kubernetes) map[string]string {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+
+	got := make(map[string]string)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetKubernetes()
+		assert.Equal(t, "test", m.GetCluster())
+		got[m.GetKind()+"/"+m.GetNamespace()+"/"+m.GetName()+"/"+m.GetField()] = string(chunk.Data)
+	}
+	return got
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server := testServer(t)
+	got := chunks(t, &sourcespb.Kubernetes{
+		Kubeconfig:        writeKubeconfig(t, server, testToken),
+		ExcludeNamespaces: []string{"kube-*"},
+	})
+	assert.Equal(t, map[string]string{
+		"Secret/prod/db/data":                          "password=hunter2\nuser=admin\n",
+		"ConfigMap/prod/app/metadata.annotations":      "owner=team-a\n",
+		"ConfigMap/prod/app/data":                      "app.properties=token=abc\n",
+		"ConfigMap/prod/app/binaryData":                "key.bin=AKIA\n",
+		"Pod/prod/web-1/spec.containers[web].env":      "API_KEY=xyz\n",
+		"Pod/prod/web-1/spec.containers[web].command":  "/bin/web",
+		"Pod/prod/web-1/spec.containers[web].args":     "--password s3cret",
+		"Pod/prod/web-1/spec.initContainers[init].env": "TOKEN=t0k\n",
+		"Database/prod/orders/spec":                    `{"url":"postgres://u:p@db"}`,
+		"Vault//main/spec":                             `{"unsealKey":"k"}`,
+	}, got)
+}
+
+func TestSource_ChunksNamespaces(t *testing.T) {
+	// Cluster-scoped resources are not scanned with selected namespaces.
+	server := testServer(t)
+	got := chunks(t, &sourcespb.Kubernetes{
+		Kubeconfig:  writeKubeconfig(t, server, testToken),
+		Context:     "test",
+		Namespaces:  []string{"prod", "kube-system"},
+		SkipSecrets: true,
+		SkipPods:    true,
+	})
+	assert.Equal(t, map[string]string{
+		"ConfigMap/prod/app/metadata.annotations": "owner=team-a\n",
+		"ConfigMap/prod/app/data":                 "app.properties=token=abc\n",
+		"ConfigMap/prod/app/binaryData":           "key.bin=AKIA\n",
+		"Database/prod/orders/spec":               `{"url":"postgres://u:p@db"}`,
+	}, got)
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := testServer(t)
+	anyConn, err := anypb.New(&sourcespb.Kubernetes{Kubeconfig: writeKubeconfig(t, server, "wrong")})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+	err = s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	assert.ErrorContains(t, err, "invalid credentials")
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	server := testServer(t)
+	anyConn, err := anypb.New(&sourcespb.Kubernetes{
+		Kubeconfig:        writeKubeconfig(t, server, testToken),
+		ExcludeNamespaces: []string{"kube-*"},
+	})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+
+	objects := make(map[string]int64)
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		objects[target.Name] = target.Objects
+	}))
+	assert.Equal(t, map[string]int64{"test/prod": 5, "test/dev": 0, "test": 1}, objects)
+}
+
+func TestSource_Init(t *testing.T) {
+	server := testServer(t)
+	kubeconfig := writeKubeconfig(t, server, testToken)
+	for name, conn := range map[string]*sourcespb.Kubernetes{
+		"missing kubeconfig": {Kubeconfig: filepath.Join(t.TempDir(), "config")},
+		"missing context":    {Kubeconfig: kubeconfig, Context: "missing"},
+		"missing cluster":    {Kubeconfig: kubeconfig, Context: "other"},
+		"invalid glob":       {Kubeconfig: kubeconfig, ExcludeNamespaces: []string{"[a"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	s := &Source{name: "test", cluster: &cluster{name: "prod-cluster"}}
+	chunk := s.chunk(pods, objectMeta{Name: "web-1", Namespace: "prod"}, "spec.containers[web].env", []byte("A=b"))
+	assert.Equal(t, &source_metadatapb.Kubernetes{
+		Cluster:   "prod-cluster",
+		Namespace: "prod",
+		Kind:      "Pod",
+		Name:      "web-1",
+		Field:     "spec.containers[web].env",
+	}, chunk.SourceMetadata.GetKubernetes())
+}
@@ -0,0 +1,359 @@
+package mongodb
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const defaultBatchSize = 1000
+
+// systemDatabases are the databases of the deployment itself, which are
+// only scanned when they are selected.
+var systemDatabases = map[string]bool{"admin": true, "config": true, "local": true}
+
+// Source scans the documents of the collections of a MongoDB deployment.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn      *sourcespb.MongoDB
+	batchSize int32
+	include   sources.Globs
+	exclude   sources.Globs
+}
+
+// collection is a collection of a database.
+type collection struct {
+	database string
+	name     string
+}
+
+func (c collection) String() string {
+	return c.database + "." + c.name
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_MONGODB
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized MongoDB source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.MongoDB
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	if conn.GetUri() == "" {
+		return errors.New("a connection string is required")
+	}
+	if _, err := connstring.ParseAndValidate(conn.GetUri()); err != nil {
+		return fmt.Errorf("invalid connection string: %w", err)
+	}
+
+	s.batchSize = conn.GetBatchSize()
+	if s.batchSize <= 0 {
+		s.batchSize = defaultBatchSize
+	}
+	// The globs of database.collection match across dots, since collection
+	// names may have them.
+	var err error
+	if s.include, err = sources.CompileGlobs(conn.GetIncludeGlobs()); err != nil {
+		return err
+	}
+	if s.exclude, err = sources.CompileGlobs(conn.GetExcludeGlobs()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// clientOptions returns the options of the connection string, with the TLS
+// of the flags if it enables TLS. The connections go through the proxy of
+// the flags, but the SRV records of mongodb+srv:// are resolved directly.
+func (s *Source) clientOptions() *options.ClientOptions {
+	opts := options.Client().ApplyURI(s.conn.GetUri()).SetDialer(common.ContextDialer{})
+	if uriConfig := opts.TLSConfig; uriConfig != nil {
+		tlsConfig := common.TLSClientConfig()
+		tlsConfig.InsecureSkipVerify = uriConfig.InsecureSkipVerify
+		// The tlsCAFile of the connection string replaces the trusted CAs,
+		// as it does for the driver.
+		if uriConfig.RootCAs != nil {
+			tlsConfig.RootCAs = uriConfig.RootCAs
+		}
+		if len(uriConfig.Certificates) > 0 {
+			tlsConfig.Certificates = uriConfig.Certificates
+			tlsConfig.GetClientCertificate = nil
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+	return opts
+}
+
+func (s *Source) connect(ctx context.Context) (*mongo.Client, error) {
+	client, err := mongo.Connect(ctx, s.clientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to MongoDB: %w", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("error connecting to MongoDB: %w", err)
+	}
+	return client, nil
+}
+
+// Chunks emits the documents of the collections as chunks, flattened into
+// a line of path=value for each of their strings.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	client, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Disconnect(ctx) }()
+
+	collections, err := s.collections(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, c := range collections {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(collections), fmt.Sprintf("Collection: %s", c), "")
+		c := c
+		s.jobPool.Go(func() error {
+			if err := s.scanCollection(ctx, client, c, chunksChan); err != nil {
+				sources.ReportSkip(ctx, c.String(), sources.SkipReasonError)
+				scanErrs.Add(fmt.Errorf("error scanning collection %s: %w", c, err))
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// EnumerateTargets reports each collection with the estimated number of its
+// documents.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	client, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Disconnect(ctx) }()
+
+	collections, err := s.collections(ctx, client)
+	if err != nil {
+		return err
+	}
+	for _, c := range collections {
+		count, err := client.Database(c.database).Collection(c.name).EstimatedDocumentCount(ctx)
+		if err != nil {
+			return fmt.Errorf("error counting the documents of %s: %w", c, err)
+		}
+		report(sources.Target{Name: c.String(), Objects: count})
+	}
+	return nil
+}
+
+// collections returns the collections of the selected databases that the
+// globs leave, without views and system collections.
+func (s *Source) collections(ctx context.Context, client *mongo.Client) ([]collection, error) {
+	databases := s.conn.GetDatabases()
+	if len(databases) == 0 {
+		names, err := client.ListDatabaseNames(ctx, bson.D{})
+		if err != nil {
+			return nil, fmt.Errorf("error listing databases: %w", err)
+		}
+		for _, name := range names {
+			if !systemDatabases[name] {
+				databases = append(databases, name)
+			}
+		}
+	}
+
+	var collections []collection
+	for _, database := range databases {
+		names, err := client.Database(database).ListCollectionNames(ctx, bson.D{{Key: "type", Value: "collection"}})
+		if err != nil {
+			return nil, fmt.Errorf("error listing the collections of %s: %w", database, err)
+		}
+		for _, name := range names {
+			c := collection{database: database, name: name}
+			if s.selected(c) {
+				collections = append(collections, c)
+			}
+		}
+	}
+	return collections, nil
+}
+
+// selected returns whether a collection is scanned: those that are not
+// system collections and that the globs leave.
+func (s *Source) selected(c collection) bool {
+	if strings.HasPrefix(c.name, "system.") {
+		return false
+	}
+	name := c.String()
+	if len(s.include) > 0 && !s.include.Match(name) {
+		return false
+	}
+	return !s.exclude.Match(name)
+}
+
+// scanCollection iterates over the documents of a collection with a cursor
+// of batches of batchSize documents.
+func (s *Source) scanCollection(ctx context.Context, client *mongo.Client, c collection, chunksChan chan *sources.Chunk) error {
+	cursor, err := client.Database(c.database).Collection(c.name).Find(ctx, bson.D{}, options.Find().SetBatchSize(s.batchSize))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	for cursor.Next(ctx) {
+		data, err := flatten(cursor.Current)
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			continue
+		}
+		chunk := &sources.Chunk{
+			SourceType: s.Type(),
+			SourceName: s.name,
+			SourceID:   s.SourceID(),
+			Data:       data,
+			SourceMetadata: &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Mongodb{
+					Mongodb: &source_metadatapb.MongoDB{
+						Database:   c.database,
+						Collection: c.name,
+						DocumentId: sanitizer.UTF8(documentID(cursor.Current)),
+					},
+				},
+			},
+			Verify: s.verify,
+		}
+		if err := common.CancellableWrite(ctx, chunksChan, chunk); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// flatten returns the strings of a document as lines of path=value, whose
+// paths are the keys of the fields joined by dots and the indices of the
+// elements of arrays, like user.tokens[0]=value.
+func flatten(doc bson.Raw) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := flattenDocument(&buf, "", doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func flattenDocument(buf *bytes.Buffer, prefix string, doc bson.Raw) error {
+	elements, err := doc.Elements()
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		path := element.Key()
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		if err := flattenValue(buf, path, element.Value()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flattenValue(buf *bytes.Buffer, path string, value bson.RawValue) error {
+	switch value.Type {
+	case bsontype.String:
+		if str := value.StringValue(); str != "" {
+			buf.WriteString(path + "=" + str + "\n")
+		}
+	case bsontype.EmbeddedDocument:
+		return flattenDocument(buf, path, value.Document())
+	case bsontype.Array:
+		values, err := value.Array().Values()
+		if err != nil {
+			return err
+		}
+		for i, v := range values {
+			if err := flattenValue(buf, path+"["+strconv.Itoa(i)+"]", v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// documentID returns the _id of a document as text.
+func documentID(doc bson.Raw) string {
+	id, err := doc.LookupErr("_id")
+	if err != nil {
+		return ""
+	}
+	switch id.Type {
+	case bsontype.ObjectID:
+		return id.ObjectID().Hex()
+	case bsontype.String:
+		return id.StringValue()
+	default:
+		return id.String()
+	}
+}
@@ -0,0 +1,96 @@
+package mongodb
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestFlatten(t *testing.T) {
+	doc, err := bson.Marshal(bson.D{
+		{Key: "_id", Value: primitive.NewObjectID()},
+		{Key: "name", Value: "ada"},
+		{Key: "age", Value: 36},
+		{Key: "empty", Value: ""},
+		{Key: "credentials", Value: bson.D{
+			{Key: "password", Value: "hunter2"},
+			{Key: "tokens", Value: bson.A{"AKIA", 42, bson.D{{Key: "github", Value: "ghp_x"}}}},
+		}},
+		{Key: "avatar", Value: primitive.Binary{Data: []byte("secret")}},
+	})
+	require.NoError(t, err)
+	data, err := flatten(doc)
+	require.NoError(t, err)
+	assert.Equal(t, "name=ada\ncredentials.password=hunter2\ncredentials.tokens[0]=AKIA\ncredentials.tokens[2].github=ghp_x\n", string(data))
+}
+
+func TestDocumentID(t *testing.T) {
+	oid, err := primitive.ObjectIDFromHex("65a1b2c3d4e5f60718293a4b")
+	require.NoError(t, err)
+	for want, id := range map[string]any{
+		"65a1b2c3d4e5f60718293a4b": oid,
+		"user-1":                   "user-1",
+		`{"$numberInt":"42"}`:      int32(42),
+	} {
+		doc, err := bson.Marshal(bson.D{{Key: "_id", Value: id}})
+		require.NoError(t, err)
+		assert.Equal(t, want, documentID(doc))
+	}
+}
+
+func TestSource_Selected(t *testing.T) {
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.MongoDB{
+		Uri:          "mongodb://localhost:27017",
+		IncludeGlobs: []string{"app.*", "billing.invoices"},
+		ExcludeGlobs: []string{"app.sessions*"},
+	}, 1)
+	for c, want := range map[collection]bool{
+		{database: "app", name: "users"}:           true,
+		{database: "app", name: "users.archive"}:   true,
+		{database: "app", name: "sessions"}:        false,
+		{database: "app", name: "system.profile"}:  false,
+		{database: "billing", name: "invoices"}:    true,
+		{database: "billing", name: "customers"}:   false,
+		{database: "analytics", name: "pageviews"}: false,
+	} {
+		assert.Equal(t, want, s.selected(c), c.String())
+	}
+}
+
+func TestSource_ClientOptions(t *testing.T) {
+	require.NoError(t, common.ConfigureTLS(common.TLSConfig{MinVersion: "1.3"}))
+
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.MongoDB{Uri: "mongodb://localhost"}, 1)
+	assert.Nil(t, s.clientOptions().TLSConfig)
+	assert.Equal(t, common.ContextDialer{}, s.clientOptions().Dialer)
+
+	s = sources.InitTestSource(t, &Source{}, &sourcespb.MongoDB{Uri: "mongodb://localhost/?tls=true&tlsInsecure=true"}, 1)
+	tlsConfig := s.clientOptions().TLSConfig
+	require.NotNil(t, tlsConfig)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.MongoDB{
+		"no uri":       {},
+		"invalid uri":  {Uri: "localhost:27017"},
+		"invalid glob": {Uri: "mongodb://localhost", ExcludeGlobs: []string{"[a"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
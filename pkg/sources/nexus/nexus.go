@@ -0,0 +1,378 @@
+package nexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultMaxObjectSize = 250 * 1024 * 1024 // 250 MiB
+	maxObjectSizeLimit   = 250 * 1024 * 1024 // 250 MiB
+)
+
+// Source scans the assets of the hosted repositories of a Nexus Repository
+// 3 server.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn          *sourcespb.Nexus
+	endpoint      string
+	client        *http.Client
+	repositories  sources.Globs
+	include       sources.Globs
+	exclude       sources.Globs
+	maxObjectSize int64
+	checksums     *sources.Checksums
+
+	// archiveOptions configures how the archives of assets are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// repository is a repository of the API.
+type repository struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Type   string `json:"type"`
+}
+
+// asset is a file of a repository.
+type asset struct {
+	DownloadURL  string            `json:"downloadUrl"`
+	Path         string            `json:"path"`
+	Repository   string            `json:"repository"`
+	Format       string            `json:"format"`
+	Checksum     map[string]string `json:"checksum"`
+	LastModified string            `json:"lastModified"`
+	// FileSize is only returned by the servers of 3.47 and later.
+	FileSize int64 `json:"fileSize"`
+}
+
+// checksum returns the strongest checksum of an asset.
+func (a asset) checksum() string {
+	for _, algorithm := range []string{"sha512", "sha256", "sha1"} {
+		if sum := a.Checksum[algorithm]; sum != "" {
+			return algorithm + ":" + sum
+		}
+	}
+	return ""
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_NEXUS
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of assets are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized Nexus source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Nexus
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	u, err := url.Parse(conn.GetEndpoint())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid endpoint %q, it should be the URL of Nexus like https://nexus.example.com", conn.GetEndpoint())
+	}
+	s.endpoint = strings.TrimSuffix(conn.GetEndpoint(), "/")
+	tlsConfig := common.TLSClientConfig()
+	tlsConfig.InsecureSkipVerify = conn.GetInsecure()
+	s.client = common.RetryableHttpClientTLS(120, tlsConfig)
+
+	if s.repositories, err = sources.CompileGlobs(conn.GetRepositories(), '/'); err != nil {
+		return err
+	}
+	if s.include, err = sources.CompileGlobs(conn.GetIncludePaths(), '/'); err != nil {
+		return err
+	}
+	if s.exclude, err = sources.CompileGlobs(conn.GetExcludePaths(), '/'); err != nil {
+		return err
+	}
+
+	s.maxObjectSize = conn.GetMaxObjectSize()
+	if s.maxObjectSize <= 0 || s.maxObjectSize > maxObjectSizeLimit {
+		s.maxObjectSize = defaultMaxObjectSize
+	}
+	return nil
+}
+
+// Chunks emits the assets of the repositories as chunks. Assets whose
+// checksum was already scanned, in this scan or in those of the state
+// path, are skipped.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	checksums, err := sources.LoadChecksums(s.conn.GetStatePath())
+	if err != nil {
+		return err
+	}
+	s.checksums = checksums
+
+	repositories, err := s.listRepositories(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, repo := range repositories {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(repositories), fmt.Sprintf("Repository: %s", repo), "")
+		repo := repo
+		err := s.listAssets(ctx, repo, func(a asset) {
+			s.jobPool.Go(func() error {
+				if err := s.scanAsset(ctx, a, chunksChan); err != nil {
+					sources.ReportSkip(ctx, repo+"/"+a.Path, sources.SkipReasonError)
+					scanErrs.Add(fmt.Errorf("error scanning asset %s/%s: %w", repo, a.Path, err))
+				}
+				return nil
+			})
+		})
+		if errors.Is(err, errUnavailable) {
+			ctx.Logger().V(2).Info("could not list repository", "repository", repo, "error", err)
+			sources.ReportSkip(ctx, repo, sources.SkipReasonError)
+			continue
+		}
+		if err != nil {
+			_ = s.jobPool.Wait()
+			return err
+		}
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return s.checksums.Save()
+}
+
+// EnumerateTargets reports each repository with the number of its assets
+// that would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	repositories, err := s.listRepositories(ctx)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repositories {
+		var assets int64
+		err := s.listAssets(ctx, repo, func(asset) { assets++ })
+		if err != nil && !errors.Is(err, errUnavailable) {
+			return err
+		}
+		report(sources.Target{Name: s.endpoint + "/repository/" + repo, Objects: assets, Bytes: -1})
+	}
+	return nil
+}
+
+// listRepositories returns the names of the hosted repositories of the
+// globs of the connection. Proxy and group repositories only have the
+// assets of others.
+func (s *Source) listRepositories(ctx context.Context) ([]string, error) {
+	var repos []repository
+	if err := s.get(ctx, s.endpoint+"/service/rest/v1/repositories", &repos); err != nil {
+		return nil, fmt.Errorf("error listing repositories: %w", err)
+	}
+	var names []string
+	for _, repo := range repos {
+		if repo.Type != "hosted" {
+			continue
+		}
+		if len(s.repositories) > 0 && !s.repositories.Match(repo.Name) {
+			continue
+		}
+		names = append(names, repo.Name)
+	}
+	return names, nil
+}
+
+// listAssets calls fn with the assets of a repository that the globs of the
+// connection leave, which are listed by pages.
+func (s *Source) listAssets(ctx context.Context, repo string, fn func(asset)) error {
+	query := url.Values{"repository": {repo}}
+	for {
+		var page struct {
+			Items             []asset `json:"items"`
+			ContinuationToken string  `json:"continuationToken"`
+		}
+		if err := s.get(ctx, s.endpoint+"/service/rest/v1/assets?"+query.Encode(), &page); err != nil {
+			return err
+		}
+		for _, a := range page.Items {
+			p := strings.TrimPrefix(a.Path, "/")
+			if (len(s.include) > 0 && !s.include.Match(p)) || s.exclude.Match(p) {
+				continue
+			}
+			fn(a)
+		}
+		if page.ContinuationToken == "" || common.IsDone(ctx) {
+			return nil
+		}
+		query.Set("continuationToken", page.ContinuationToken)
+	}
+}
+
+// scanAsset downloads an asset and scans it through the handlers of
+// archives, unless its checksum was already scanned.
+func (s *Source) scanAsset(ctx context.Context, a asset, chunksChan chan *sources.Chunk) error {
+	location := a.Repository + "/" + strings.TrimPrefix(a.Path, "/")
+	switch {
+	case a.FileSize > s.maxObjectSize:
+		sources.ReportSkipBytes(ctx, location, sources.SkipReasonSize, a.FileSize)
+		return nil
+	case common.SkipFile(a.Path):
+		sources.ReportSkipBytes(ctx, location, sources.SkipReasonUnsupported, a.FileSize)
+		return nil
+	}
+	sum := a.checksum()
+	if !s.checksums.Claim(sum) {
+		ctx.Logger().V(5).Info("skipping asset already scanned", "asset", location, "checksum", sum)
+		return nil
+	}
+
+	err := s.download(ctx, a.DownloadURL, func(body io.Reader) error {
+		return s.scanReader(ctx, s.chunkSkeleton(a), body, chunksChan)
+	})
+	if err != nil {
+		s.checksums.Forget(sum)
+	}
+	return err
+}
+
+func (s *Source) scanReader(ctx context.Context, skel *sources.Chunk, body io.Reader, chunksChan chan *sources.Chunk) error {
+	reader, err := diskbufferreader.New(io.LimitReader(body, s.maxObjectSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+
+	chunkReader := sources.NewChunkReader(s.archiveOptions.ChunkOptions()...)
+	for data := range chunkReader(ctx, reader) {
+		if err := data.Error(); err != nil {
+			return err
+		}
+		chunk := *skel
+		chunk.Data = data.Bytes()
+		chunk.Offset, chunk.LineStart, chunk.LineOffset = data.Offset(), data.LineStart(), data.LineOffset()
+		if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Source) chunkSkeleton(a asset) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Nexus{
+				Nexus: &source_metadatapb.Nexus{
+					Repository: a.Repository,
+					Path:       sanitizer.UTF8(strings.TrimPrefix(a.Path, "/")),
+					Link:       sanitizer.UTF8(a.DownloadURL),
+					Timestamp:  a.LastModified,
+					Format:     a.Format,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// errUnavailable is the error of the repositories that the credentials
+// cannot read, or that no longer exist.
+var errUnavailable = errors.New("unavailable")
+
+func (s *Source) get(ctx context.Context, u string, out any) error {
+	return s.download(ctx, u, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(out)
+	})
+}
+
+// download requests a URL of the server and reads its body.
+func (s *Source) download(ctx context.Context, u string, read func(io.Reader) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if auth := s.conn.GetBasicAuth(); auth != nil {
+		req.SetBasicAuth(auth.GetUsername(), auth.GetPassword())
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return read(resp.Body)
+	case http.StatusUnauthorized:
+		return fmt.Errorf("invalid credentials, status %d", resp.StatusCode)
+	case http.StatusForbidden, http.StatusNotFound:
+		return fmt.Errorf("%w: status %d", errUnavailable, resp.StatusCode)
+	default:
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
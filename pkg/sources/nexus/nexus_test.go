@@ -0,0 +1,203 @@
+package nexus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// testRepositories are the assets of the repositories of testServer.
+var testRepositories = map[string]map[string]string{
+	"maven-releases": {
+		"com/acme/app/1.0/app.properties": "db.password=hunter2",
+		"com/acme/app/1.0/app.pom":        "<project/>",
+		"com/acme/app/1.0/notes.txt":      "token AKIA",
+	},
+	"raw-hosted": {
+		"config/app.properties": "db.password=hunter2",
+	},
+	"forbidden": {
+		"secret.txt": "x",
+	},
+}
+
+// testServer is a Nexus with hosted repositories, a proxy one, and a
+// repository that the user cannot read. Assets are listed two at a time,
+// and downloads are recorded.
+type testServer struct {
+	mu        sync.Mutex
+	downloads []string
+}
+
+func (ts *testServer) start(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, _ := r.BasicAuth(); user != "admin" || password != "pw" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/service/rest/v1/repositories":
+			_ = json.NewEncoder(w).Encode([]map[string]string{
+				{"name": "forbidden", "format": "raw", "type": "hosted"},
+				{"name": "maven-central", "format": "maven2", "type": "proxy"},
+				{"name": "maven-releases", "format": "maven2", "type": "hosted"},
+				{"name": "raw-hosted", "format": "raw", "type": "hosted"},
+			})
+		case r.URL.Path == "/service/rest/v1/assets":
+			repo := r.URL.Query().Get("repository")
+			if repo == "forbidden" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			var paths []string
+			for p := range testRepositories[repo] {
+				paths = append(paths, p)
+			}
+			sort.Strings(paths)
+			start, _ := strconv.Atoi(r.URL.Query().Get("continuationToken"))
+			end := start + 2
+			token := strconv.Itoa(end)
+			if end >= len(paths) {
+				end, token = len(paths), ""
+			}
+			var items []map[string]any
+			for _, p := range paths[start:end] {
+				sum := sha256.Sum256([]byte(testRepositories[repo][p]))
+				items = append(items, map[string]any{
+					"downloadUrl":  server.URL + "/repository/" + repo + "/" + p,
+					"path":         p,
+					"repository":   repo,
+					"format":       "maven2",
+					"checksum":     map[string]string{"sha1": "x", "sha256": hex.EncodeToString(sum[:])},
+					"lastModified": "2024-01-02T03:04:05.000+00:00",
+				})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": items, "continuationToken": token})
+		case strings.HasPrefix(r.URL.Path, "/repository/"):
+			repo, p, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/repository/"), "/")
+			ts.mu.Lock()
+			ts.downloads = append(ts.downloads, repo+"/"+p)
+			ts.mu.Unlock()
+			_, _ = w.Write([]byte(testRepositories[repo][p]))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// chunks returns the data of the chunks of a source by repository and
+// path, and their metadata.
+func chunks(t *testing.T, conn *sourcespb.Nexus) (map[string]string, map[string]*source_metadatapb.Nexus) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+	return sources.ChunksByKey(sources.RequireChunks(t, s), (*source_metadatapb.MetaData).GetNexus, func(m *source_metadatapb.Nexus) string {
+		return m.GetRepository() + "/" + m.GetPath()
+	})
+}
+
+var testAuth = &credentialspb.BasicAuth{Username: "admin", Password: "pw"}
+
+func TestSource_Chunks(t *testing.T) {
+	server := (&testServer{}).start(t)
+	got, metadata := chunks(t, &sourcespb.Nexus{
+		Endpoint:     server.URL + "/",
+		BasicAuth:    testAuth,
+		ExcludePaths: []string{"**.pom"},
+	})
+	// The assets with the same checksum are only scanned once.
+	assert.Len(t, got, 2)
+	assert.Equal(t, "token AKIA", got["maven-releases/com/acme/app/1.0/notes.txt"])
+	assert.Equal(t, &source_metadatapb.Nexus{
+		Repository: "maven-releases",
+		Path:       "com/acme/app/1.0/notes.txt",
+		Link:       server.URL + "/repository/maven-releases/com/acme/app/1.0/notes.txt",
+		Timestamp:  "2024-01-02T03:04:05.000+00:00",
+		Format:     "maven2",
+	}, metadata["maven-releases/com/acme/app/1.0/notes.txt"])
+}
+
+func TestSource_ChunksFilters(t *testing.T) {
+	server := (&testServer{}).start(t)
+	got, _ := chunks(t, &sourcespb.Nexus{
+		Endpoint:     server.URL,
+		BasicAuth:    testAuth,
+		Repositories: []string{"maven-*"},
+		IncludePaths: []string{"com/acme/**.properties"},
+	})
+	assert.Equal(t, map[string]string{"maven-releases/com/acme/app/1.0/app.properties": "db.password=hunter2"}, got)
+}
+
+func TestSource_ChunksState(t *testing.T) {
+	ts := &testServer{}
+	server := ts.start(t)
+	conn := &sourcespb.Nexus{
+		Endpoint:  server.URL,
+		BasicAuth: testAuth,
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+	}
+	got, _ := chunks(t, conn)
+	assert.Len(t, got, 3)
+	assert.Len(t, ts.downloads, 3)
+
+	// The next scan skips the assets that did not change.
+	got, _ = chunks(t, conn)
+	assert.Empty(t, got)
+	assert.Len(t, ts.downloads, 3)
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := (&testServer{}).start(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Nexus{Endpoint: server.URL, BasicAuth: &credentialspb.BasicAuth{Username: "admin", Password: "wrong"}}, 2)
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	assert.ErrorContains(t, err, "invalid credentials")
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	server := (&testServer{}).start(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Nexus{Endpoint: server.URL, BasicAuth: testAuth}, 2)
+	objects := make(map[string]int64)
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		objects[target.Name] = target.Objects
+	}))
+	assert.Equal(t, map[string]int64{
+		server.URL + "/repository/forbidden":      0,
+		server.URL + "/repository/maven-releases": 3,
+		server.URL + "/repository/raw-hosted":     1,
+	}, objects)
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.Nexus{
+		"no endpoint":  {},
+		"no scheme":    {Endpoint: "nexus.example.com"},
+		"invalid glob": {Endpoint: "https://nexus.example.com", Repositories: []string{"[a"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
@@ -0,0 +1,509 @@
+package notion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// defaultAPIURL is the URL of the Notion API.
+	defaultAPIURL = "https://api.notion.com"
+	// notionVersion is the version of the API the responses are decoded as.
+	notionVersion = "2022-06-28"
+	// pageSize is the number of results requested at a time, the most Notion
+	// returns.
+	pageSize = 100
+	// maxFileSize is the size of the largest files scanned.
+	maxFileSize = 250 * 1024 * 1024
+
+	locationProperties = "properties"
+	locationContent    = "content"
+	locationFile       = "file"
+)
+
+// Source scans the pages and databases of a Notion workspace shared with an
+// integration: the properties and the content of pages, the titles and
+// descriptions of databases, and the files of file blocks.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	client    *http.Client
+	apiURL    string
+	token     string
+	skipFiles bool
+	// archiveOptions configures how the archives of files are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_NOTION
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of files are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized Notion source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+	s.client = common.RetryableHttpClientTimeout(120)
+	s.apiURL = defaultAPIURL
+
+	var conn sourcespb.Notion
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	if conn.GetToken() == "" {
+		return errors.New("a token is required")
+	}
+	s.token = conn.GetToken()
+	s.skipFiles = conn.GetSkipFiles()
+	return nil
+}
+
+// richText is a run of rich text, of which only the plain text is scanned.
+type richText struct {
+	PlainText string `json:"plain_text"`
+}
+
+func plainText(texts []richText) string {
+	var b strings.Builder
+	for _, t := range texts {
+		b.WriteString(t.PlainText)
+	}
+	return b.String()
+}
+
+// object is a page or a database found by the search.
+type object struct {
+	Object         string                     `json:"object"`
+	ID             string                     `json:"id"`
+	URL            string                     `json:"url"`
+	LastEditedTime time.Time                  `json:"last_edited_time"`
+	Properties     map[string]json.RawMessage `json:"properties"`
+	// Title and Description are those of databases.
+	Title       []richText `json:"title"`
+	Description []richText `json:"description"`
+}
+
+// file is a file of a block or of a property, uploaded to Notion or
+// external.
+type file struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	File struct {
+		URL string `json:"url"`
+	} `json:"file"`
+	External struct {
+		URL string `json:"url"`
+	} `json:"external"`
+}
+
+// Chunks emits the properties, the content and the files of each page, and
+// the title and the description of each database, as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	scanErrs := sources.NewScanErrors()
+	var found, scanned int64
+	var cursor string
+	for {
+		body := map[string]any{"page_size": pageSize}
+		if cursor != "" {
+			body["start_cursor"] = cursor
+		}
+		var page struct {
+			Results    []object `json:"results"`
+			HasMore    bool     `json:"has_more"`
+			NextCursor string   `json:"next_cursor"`
+		}
+		if err := s.call(ctx, http.MethodPost, "/v1/search", body, &page); err != nil {
+			_ = s.jobPool.Wait()
+			return fmt.Errorf("error searching pages and databases: %w", err)
+		}
+		total := atomic.AddInt64(&found, int64(len(page.Results)))
+		for _, obj := range page.Results {
+			obj := obj
+			s.jobPool.Go(func() error {
+				if err := s.scanObject(ctx, obj, chunksChan); err != nil {
+					scanErrs.Add(fmt.Errorf("error scanning %s %s: %w", obj.Object, obj.ID, err))
+				}
+				n := atomic.AddInt64(&scanned, 1)
+				s.SetProgressComplete(int(n), int(total), fmt.Sprintf("Object: %s %s", obj.Object, obj.ID), "")
+				return nil
+			})
+		}
+		if !page.HasMore || page.NextCursor == "" || common.IsDone(ctx) {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+func (s *Source) scanObject(ctx context.Context, obj object, chunksChan chan *sources.Chunk) error {
+	if obj.Object == "database" {
+		title := plainText(obj.Title)
+		skel := s.chunkSkeleton(obj, title, locationProperties)
+		text := title + "\n" + plainText(obj.Description)
+		return sources.ChunkReaderTo(ctx, skel, strings.NewReader(text), chunksChan, s.archiveOptions.ChunkOptions()...)
+	}
+
+	properties, title, files := flattenProperties(obj.Properties)
+	if err := sources.ChunkReaderTo(ctx, s.chunkSkeleton(obj, title, locationProperties), strings.NewReader(properties), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+		return err
+	}
+
+	var content strings.Builder
+	if err := s.walkBlocks(ctx, obj.ID, 0, &content, &files); err != nil {
+		return fmt.Errorf("error listing blocks: %w", err)
+	}
+	if content.Len() > 0 {
+		if err := sources.ChunkReaderTo(ctx, s.chunkSkeleton(obj, title, locationContent), strings.NewReader(content.String()), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+			return err
+		}
+	}
+
+	if s.skipFiles {
+		return nil
+	}
+	for _, f := range files {
+		skel := s.chunkSkeleton(obj, title, locationFile+"/"+f.Name)
+		if err := s.scanFile(ctx, skel, f.File.URL, chunksChan); err != nil {
+			return fmt.Errorf("error scanning file %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// flattenProperties returns the values of the properties of a page, one
+// per line sorted by name, its title, and the files uploaded to its file
+// properties.
+func flattenProperties(properties map[string]json.RawMessage) (string, string, []file) {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	var title string
+	var files []file
+	for _, name := range names {
+		var property struct {
+			Type        string     `json:"type"`
+			Title       []richText `json:"title"`
+			RichText    []richText `json:"rich_text"`
+			URL         string     `json:"url"`
+			Email       string     `json:"email"`
+			PhoneNumber string     `json:"phone_number"`
+			Number      *float64   `json:"number"`
+			Select      struct {
+				Name string `json:"name"`
+			} `json:"select"`
+			Status struct {
+				Name string `json:"name"`
+			} `json:"status"`
+			MultiSelect []struct {
+				Name string `json:"name"`
+			} `json:"multi_select"`
+			Formula struct {
+				String string `json:"string"`
+			} `json:"formula"`
+			Files []file `json:"files"`
+		}
+		if err := json.Unmarshal(properties[name], &property); err != nil {
+			continue
+		}
+
+		var value string
+		switch property.Type {
+		case "title":
+			value = plainText(property.Title)
+			title = value
+		case "rich_text":
+			value = plainText(property.RichText)
+		case "url":
+			value = property.URL
+		case "email":
+			value = property.Email
+		case "phone_number":
+			value = property.PhoneNumber
+		case "number":
+			if property.Number != nil {
+				value = strconv.FormatFloat(*property.Number, 'f', -1, 64)
+			}
+		case "select":
+			value = property.Select.Name
+		case "status":
+			value = property.Status.Name
+		case "multi_select":
+			selected := make([]string, 0, len(property.MultiSelect))
+			for _, option := range property.MultiSelect {
+				selected = append(selected, option.Name)
+			}
+			value = strings.Join(selected, ", ")
+		case "formula":
+			value = property.Formula.String
+		case "files":
+			names := make([]string, 0, len(property.Files))
+			for _, f := range property.Files {
+				names = append(names, f.Name+" "+f.External.URL)
+				if f.Type == "file" {
+					files = append(files, f)
+				}
+			}
+			value = strings.Join(names, ", ")
+		}
+		if value != "" {
+			b.WriteString(name + ": " + value + "\n")
+		}
+	}
+	return b.String(), title, files
+}
+
+// block is a block of the content of a page. Its content is under the key
+// of its type.
+type block struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	HasChildren bool   `json:"has_children"`
+	content     json.RawMessage
+}
+
+func (b *block) UnmarshalJSON(data []byte) error {
+	type plain block
+	if err := json.Unmarshal(data, (*plain)(b)); err != nil {
+		return err
+	}
+	var contents map[string]json.RawMessage
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return err
+	}
+	b.content = contents[b.Type]
+	return nil
+}
+
+// blockContent holds the fields of the contents of the types of blocks
+// that have text or files.
+type blockContent struct {
+	RichText   []richText   `json:"rich_text"`
+	Caption    []richText   `json:"caption"`
+	Cells      [][]richText `json:"cells"`
+	Expression string       `json:"expression"`
+	URL        string       `json:"url"`
+	Title      string       `json:"title"`
+	file
+}
+
+// fileBlocks are the types of blocks of files.
+var fileBlocks = map[string]bool{"file": true, "image": true, "pdf": true, "video": true, "audio": true}
+
+// walkBlocks appends the text of the blocks of a parent block or page to
+// content, indented by their depth, and their uploaded files to files.
+// Child pages and databases are found by the search instead.
+func (s *Source) walkBlocks(ctx context.Context, parentID string, depth int, content *strings.Builder, files *[]file) error {
+	var cursor string
+	for {
+		query := url.Values{"page_size": {strconv.Itoa(pageSize)}}
+		if cursor != "" {
+			query.Set("start_cursor", cursor)
+		}
+		var page struct {
+			Results    []block `json:"results"`
+			HasMore    bool    `json:"has_more"`
+			NextCursor string  `json:"next_cursor"`
+		}
+		if err := s.call(ctx, http.MethodGet, "/v1/blocks/"+url.PathEscape(parentID)+"/children?"+query.Encode(), nil, &page); err != nil {
+			return err
+		}
+		for _, b := range page.Results {
+			var c blockContent
+			if len(b.content) > 0 {
+				if err := json.Unmarshal(b.content, &c); err != nil {
+					return fmt.Errorf("error decoding block %s: %w", b.ID, err)
+				}
+			}
+			text := plainText(c.RichText)
+			for _, cell := range c.Cells {
+				text += plainText(cell) + "\t"
+			}
+			for _, value := range []string{c.Expression, c.URL, c.External.URL, plainText(c.Caption)} {
+				if value != "" {
+					text += " " + value
+				}
+			}
+			if text = strings.TrimSpace(text); text != "" {
+				content.WriteString(strings.Repeat("\t", depth) + text + "\n")
+			}
+			if fileBlocks[b.Type] && c.file.Type == "file" {
+				f := c.file
+				if f.Name == "" {
+					f.Name = fileName(f.File.URL)
+				}
+				*files = append(*files, f)
+			}
+			if b.HasChildren && b.Type != "child_page" && b.Type != "child_database" {
+				if err := s.walkBlocks(ctx, b.ID, depth+1, content, files); err != nil {
+					return err
+				}
+			}
+		}
+		if !page.HasMore || page.NextCursor == "" || common.IsDone(ctx) {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// fileName returns the name of an uploaded file from its URL.
+func fileName(fileURL string) string {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return ""
+	}
+	name, _ := url.PathUnescape(u.Path[strings.LastIndex(u.Path, "/")+1:])
+	return name
+}
+
+// scanFile scans an uploaded file, through the handlers of archives. The
+// URLs of uploaded files are signed, and are not sent the token.
+func (s *Source) scanFile(ctx context.Context, skel *sources.Chunk, fileURL string, chunksChan chan *sources.Chunk) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	if res.ContentLength > maxFileSize {
+		sources.ReportSkipBytes(ctx, skel.SourceMetadata.GetNotion().GetId()+"/"+skel.SourceMetadata.GetNotion().GetLocation(), sources.SkipReasonSize, res.ContentLength)
+		return nil
+	}
+
+	reader, err := diskbufferreader.New(io.LimitReader(res.Body, maxFileSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+	return sources.ChunkReaderTo(ctx, skel, reader, chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+func (s *Source) chunkSkeleton(obj object, title, location string) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Notion{
+				Notion: &source_metadatapb.Notion{
+					Object:    obj.Object,
+					Id:        obj.ID,
+					Title:     sanitizer.UTF8(title),
+					Location:  sanitizer.UTF8(location),
+					Link:      obj.URL,
+					Timestamp: obj.LastEditedTime.Format(time.RFC3339),
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// call sends an authorized request of the API, and decodes its JSON
+// response.
+func (s *Source) call(ctx context.Context, method, path string, body, v any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.apiURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	req.Header.Set("Notion-Version", notionVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+			return fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+		}
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
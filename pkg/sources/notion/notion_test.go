@@ -0,0 +1,134 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func initSource(t *testing.T, conn *sourcespb.Notion, apiURL string) *Source {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 4)
+	s.apiURL = apiURL
+	return s
+}
+
+// workspaceServer serves a workspace shared with the token "secret_token",
+// with a page and a database, and the files of the page from another host,
+// which rejects the token.
+func workspaceServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, "AWS_SECRET_ACCESS_KEY=xyz")
+	}))
+	t.Cleanup(files.Close)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret_token" || r.Header.Get("Notion-Version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/search":
+			var body struct {
+				StartCursor string `json:"start_cursor"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body.StartCursor == "" {
+				fmt.Fprint(w, `{"results":[{"object":"page","id":"p1","url":"https://www.notion.so/Runbook-p1","last_edited_time":"2024-01-02T03:04:05.000Z","properties":{
+					"Name":{"type":"title","title":[{"plain_text":"Run"},{"plain_text":"book"}]},
+					"Notes":{"type":"rich_text","rich_text":[{"plain_text":"password=hunter2"}]},
+					"Tags":{"type":"multi_select","multi_select":[{"name":"ops"},{"name":"db"}]},
+					"Empty":{"type":"url","url":null}}}],"has_more":true,"next_cursor":"c2"}`)
+				return
+			}
+			fmt.Fprint(w, `{"results":[{"object":"database","id":"d1","url":"https://www.notion.so/d1","last_edited_time":"2024-01-03T00:00:00.000Z",
+				"title":[{"plain_text":"Servers"}],"description":[{"plain_text":"root password is swordfish"}],"properties":{}}],"has_more":false,"next_cursor":null}`)
+		case "/v1/blocks/p1/children":
+			if r.URL.Query().Get("start_cursor") == "" {
+				fmt.Fprint(w, `{"results":[
+					{"id":"b1","type":"paragraph","has_children":true,"paragraph":{"rich_text":[{"plain_text":"Connect with:"}]}},
+					{"id":"b2","type":"child_page","has_children":true,"child_page":{"title":"Child"}}],"has_more":true,"next_cursor":"b3"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"results":[
+				{"id":"b3","type":"file","has_children":false,"file":{"type":"file","name":"config.env","file":{"url":%q}}},
+				{"id":"b4","type":"image","has_children":false,"image":{"type":"external","external":{"url":"https://example.com/diagram.png"}}}],"has_more":false,"next_cursor":null}`,
+				files.URL+"/secure/config.env?X-Amz-Signature=abc")
+		case "/v1/blocks/b1/children":
+			fmt.Fprint(w, `{"results":[
+				{"id":"b5","type":"code","has_children":false,"code":{"rich_text":[{"plain_text":"psql postgres://admin:s3cr3t@db"}],"caption":[]}},
+				{"id":"b6","type":"table_row","has_children":false,"table_row":{"cells":[[{"plain_text":"token"}],[{"plain_text":"abc123"}]]}}],"has_more":false,"next_cursor":null}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server := workspaceServer(t)
+	s := initSource(t, &sourcespb.Notion{Token: "secret_token"}, server.URL)
+
+	got := sources.RequireChunks(t, s)
+	assert.Equal(t, &source_metadatapb.Notion{
+		Object:    "page",
+		Id:        "p1",
+		Title:     "Runbook",
+		Location:  "properties",
+		Link:      "https://www.notion.so/Runbook-p1",
+		Timestamp: "2024-01-02T03:04:05Z",
+	}, sources.MetadataWith(got, "Notes: password=hunter2\n").GetNotion())
+	assert.NotNil(t, sources.MetadataWith(got, "Tags: ops, db\n").GetNotion())
+
+	content := sources.MetadataWith(got, "Connect with:\n\tpsql postgres://admin:s3cr3t@db\n\ttoken\tabc123").GetNotion()
+	require.NotNil(t, content)
+	assert.Equal(t, "content", content.GetLocation())
+	assert.NotNil(t, sources.MetadataWith(got, "https://example.com/diagram.png").GetNotion())
+	assert.Equal(t, "file/config.env", sources.MetadataWith(got, "AWS_SECRET_ACCESS_KEY=xyz").GetNotion().GetLocation())
+
+	database := sources.MetadataWith(got, "root password is swordfish").GetNotion()
+	require.NotNil(t, database)
+	assert.Equal(t, "database", database.GetObject())
+	assert.Equal(t, "Servers", database.GetTitle())
+	assert.Equal(t, "https://www.notion.so/d1", database.GetLink())
+}
+
+func TestSource_ChunksSkipFiles(t *testing.T) {
+	server := workspaceServer(t)
+	s := initSource(t, &sourcespb.Notion{Token: "secret_token", SkipFiles: true}, server.URL)
+
+	got := sources.RequireChunks(t, s)
+	assert.NotNil(t, sources.MetadataWith(got, "password=hunter2").GetNotion())
+	assert.Nil(t, sources.MetadataWith(got, "AWS_SECRET_ACCESS_KEY=xyz").GetNotion())
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := workspaceServer(t)
+	s := initSource(t, &sourcespb.Notion{Token: "wrong"}, server.URL)
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid credentials")
+}
+
+func TestSource_Init(t *testing.T) {
+	anyConn, err := anypb.New(&sourcespb.Notion{})
+	require.NoError(t, err)
+	assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+}
@@ -0,0 +1,16 @@
+package process
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+var processesScanned = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: common.MetricsNamespace,
+	Subsystem: common.MetricsSubsystem,
+	Name:      "processes_scanned",
+	Help:      "Total number of processes scanned.",
+},
+	[]string{"source_name"})
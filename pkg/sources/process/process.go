@@ -0,0 +1,315 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	fieldEnviron     = "environ"
+	fieldCommandLine = "cmdline"
+)
+
+// Source scans the environment variables and command lines of running
+// processes. Processes of other users can only be read with the privileges to
+// do so, usually root.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	conn     *sourcespb.Process
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_PROCESS
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized process source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, _ int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+
+	var conn sourcespb.Process
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+	return nil
+}
+
+// process is a running process and what could be read of it.
+type process struct {
+	pid     int64
+	name    string
+	user    string
+	environ []string
+	cmdline []string
+}
+
+// Chunks emits the environment and command line of each process as a chunk.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	processes, err := listProcesses(ctx)
+	if err != nil {
+		return err
+	}
+	processes = s.filter(processes)
+
+	for i, p := range processes {
+		if common.IsDone(ctx) {
+			return nil
+		}
+		s.SetProgressComplete(i, len(processes), fmt.Sprintf("Process: %d %s", p.pid, p.name), "")
+
+		if len(p.environ) > 0 {
+			if err := s.emit(ctx, chunksChan, p, fieldEnviron, strings.Join(p.environ, "\n")); err != nil {
+				return err
+			}
+		}
+		if len(p.cmdline) > 0 && !s.conn.GetSkipCommandLine() {
+			if err := s.emit(ctx, chunksChan, p, fieldCommandLine, strings.Join(p.cmdline, " ")); err != nil {
+				return err
+			}
+		}
+		processesScanned.WithLabelValues(s.name).Inc()
+	}
+	return nil
+}
+
+// filter returns the processes selected by pid or name, or all of them if no
+// process is selected, except for this one, whose command line holds the
+// flags of the scan.
+func (s *Source) filter(processes []process) []process {
+	pids := make(map[int64]bool)
+	for _, pid := range s.conn.GetPids() {
+		pids[pid] = true
+	}
+	names := make(map[string]bool)
+	for _, name := range s.conn.GetNames() {
+		names[name] = true
+	}
+	self := int64(os.Getpid())
+
+	var selected []process
+	for _, p := range processes {
+		if p.pid == self {
+			continue
+		}
+		if len(pids) > 0 || len(names) > 0 {
+			var exe string
+			if len(p.cmdline) > 0 {
+				exe = filepath.Base(p.cmdline[0])
+			}
+			if !pids[p.pid] && !names[p.name] && !names[exe] {
+				continue
+			}
+		}
+		selected = append(selected, p)
+	}
+	return selected
+}
+
+func (s *Source) emit(ctx context.Context, chunksChan chan *sources.Chunk, p process, field, data string) error {
+	chunk := &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		Data:       []byte(data),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Process{
+				Process: &source_metadatapb.Process{
+					Pid:   p.pid,
+					Name:  p.name,
+					User:  p.user,
+					Field: field,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+	return common.CancellableWrite(ctx, chunksChan, chunk)
+}
+
+// listProcesses returns the running processes, from /proc on Linux and from
+// ps on macOS.
+func listProcesses(ctx context.Context) ([]process, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return readProc(ctx, procRoot)
+	case "darwin":
+		return readPS(ctx)
+	default:
+		return nil, fmt.Errorf("scanning processes is not supported on %s", runtime.GOOS)
+	}
+}
+
+// procRoot is where Linux exposes processes. It is a variable for tests.
+var procRoot = "/proc"
+
+// readProc reads the processes of a proc filesystem. The environment of
+// processes of other users is not readable without privileges, and is
+// skipped.
+func readProc(ctx context.Context, root string) ([]process, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("could not list processes: %w", err)
+	}
+	users := make(map[string]string)
+	var processes []process
+	var denied int
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		p := process{pid: pid}
+		if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+			p.name = strings.TrimSpace(string(comm))
+		}
+		if status, err := os.ReadFile(filepath.Join(dir, "status")); err == nil {
+			p.user = lookupUser(users, statusUID(status))
+		}
+		if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+			p.cmdline = splitNUL(cmdline)
+		}
+		environ, err := os.ReadFile(filepath.Join(dir, "environ"))
+		switch {
+		case err == nil:
+			p.environ = splitNUL(environ)
+		case os.IsPermission(err):
+			denied++
+		}
+		processes = append(processes, p)
+	}
+	if denied > 0 {
+		ctx.Logger().Info("could not read the environment of some processes, run as root to scan them", "processes", denied)
+	}
+	return processes, nil
+}
+
+// statusUID returns the real user ID of a process from its status file.
+func statusUID(status []byte) string {
+	for _, line := range strings.Split(string(status), "\n") {
+		if uids, ok := strings.CutPrefix(line, "Uid:"); ok {
+			if fields := strings.Fields(uids); len(fields) > 0 {
+				return fields[0]
+			}
+		}
+	}
+	return ""
+}
+
+func lookupUser(cache map[string]string, uid string) string {
+	if uid == "" {
+		return ""
+	}
+	name, ok := cache[uid]
+	if !ok {
+		name = uid
+		if u, err := user.LookupId(uid); err == nil {
+			name = u.Username
+		}
+		cache[uid] = name
+	}
+	return name
+}
+
+func splitNUL(data []byte) []string {
+	var fields []string
+	for _, field := range bytes.Split(bytes.TrimRight(data, "\x00"), []byte{0}) {
+		if len(field) > 0 {
+			fields = append(fields, string(field))
+		}
+	}
+	return fields
+}
+
+// ps runs ps with the arguments and returns its output. It is a variable for
+// tests.
+var ps = func(ctx context.Context, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, "ps", args...).Output()
+}
+
+// readPS reads the processes listed by ps. ps appends the environment to the
+// command line of processes it may read it of with -E, so the environment is
+// what follows the command line.
+func readPS(ctx context.Context) ([]process, error) {
+	commands, err := ps(ctx, "-axww", "-o", "pid=,user=,comm=,command=")
+	if err != nil {
+		return nil, fmt.Errorf("could not list processes: %w", err)
+	}
+	withEnv, err := ps(ctx, "-axwwE", "-o", "pid=,command=")
+	if err != nil {
+		return nil, fmt.Errorf("could not list process environments: %w", err)
+	}
+
+	environs := make(map[int64]string)
+	for _, line := range strings.Split(string(withEnv), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		pid, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		environs[pid] = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), fields[0]))
+	}
+
+	var processes []process
+	for _, line := range strings.Split(string(commands), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		p := process{pid: pid, user: fields[1], name: filepath.Base(fields[2]), cmdline: fields[3:]}
+		command := strings.Join(fields[3:], " ")
+		if env, ok := strings.CutPrefix(environs[pid], command); ok && env != "" {
+			p.environ = strings.Fields(env)
+		}
+		processes = append(processes, p)
+	}
+	sort.Slice(processes, func(i, j int) bool { return processes[i].pid < processes[j].pid })
+	return processes, nil
+}
@@ -0,0 +1,91 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func writeProc(t *testing.T, root, pid string, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, pid)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+}
+
+func TestReadProc(t *testing.T) {
+	root := t.TempDir()
+	writeProc(t, root, "42", map[string]string{
+		"comm":    "java\n",
+		"status":  "Name:\tjava\nUid:\t0\t0\t0\t0\n",
+		"cmdline": "/usr/bin/java\x00-Ddb.password=hunter2\x00-jar\x00app.jar\x00",
+		"environ": "PATH=/usr/bin\x00AWS_SECRET_ACCESS_KEY=abc\x00",
+	})
+	writeProc(t, root, "7", map[string]string{"comm": "kthreadd\n"})
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "self"), 0755))
+
+	processes, err := readProc(context.Background(), root)
+	require.NoError(t, err)
+	require.Len(t, processes, 2)
+	java := processes[0]
+	if java.pid != 42 {
+		java = processes[1]
+	}
+	assert.Equal(t, "java", java.name)
+	assert.Equal(t, "root", java.user)
+	assert.Equal(t, []string{"/usr/bin/java", "-Ddb.password=hunter2", "-jar", "app.jar"}, java.cmdline)
+	assert.Equal(t, []string{"PATH=/usr/bin", "AWS_SECRET_ACCESS_KEY=abc"}, java.environ)
+}
+
+func TestReadPS(t *testing.T) {
+	ps = func(_ context.Context, args ...string) ([]byte, error) {
+		if strings.Contains(args[0], "E") {
+			return []byte("  12 /usr/bin/node server.js API_TOKEN=abc HOME=/Users/dev\n   1 /sbin/launchd\n"), nil
+		}
+		return []byte("  12 dev /usr/bin/node /usr/bin/node server.js\n   1 root /sbin/launchd /sbin/launchd\n"), nil
+	}
+	processes, err := readPS(context.Background())
+	require.NoError(t, err)
+	require.Len(t, processes, 2)
+	assert.Equal(t, process{pid: 1, user: "root", name: "launchd", cmdline: []string{"/sbin/launchd"}}, processes[0])
+	assert.Equal(t, []string{"API_TOKEN=abc", "HOME=/Users/dev"}, processes[1].environ)
+	assert.Equal(t, "node", processes[1].name)
+}
+
+func TestSource_Chunks(t *testing.T) {
+	procRoot = t.TempDir()
+	t.Cleanup(func() { procRoot = "/proc" })
+	writeProc(t, procRoot, "42", map[string]string{
+		"comm":    "java\n",
+		"cmdline": "/usr/bin/java\x00-Dtoken=abc\x00",
+		"environ": "TOKEN=abc\x00",
+	})
+	writeProc(t, procRoot, "43", map[string]string{"comm": "nginx\n", "environ": "A=b\x00"})
+
+	conn, err := anypb.New(&sourcespb.Process{Names: []string{"java"}})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+
+	chunksChan := make(chan *sources.Chunk, 4)
+	require.NoError(t, s.Chunks(context.Background(), chunksChan))
+	close(chunksChan)
+	var fields []string
+	for chunk := range chunksChan {
+		metadata := chunk.SourceMetadata.GetProcess()
+		assert.Equal(t, int64(42), metadata.Pid)
+		fields = append(fields, metadata.Field+": "+string(chunk.Data))
+	}
+	assert.Equal(t, []string{"environ: TOKEN=abc", "cmdline: /usr/bin/java -Dtoken=abc"}, fields)
+}
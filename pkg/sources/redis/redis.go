@@ -0,0 +1,369 @@
+package redis
+
+import (
+	"bytes"
+	gocontext "context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/go-redis/redis"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// dialTimeout is the timeout of the connections to the server, like the
+// default of the client.
+const dialTimeout = 5 * time.Second
+
+// scanCount is the COUNT hint of the SCANs of keys and of the elements of
+// hashes and sets, and the number of the elements of the pages of lists.
+var scanCount int64 = 1000
+
+// Source scans the values of the string, hash, list and set keys of the
+// databases of a Redis server.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn     *sourcespb.Redis
+	options  *redis.Options
+	username string
+	password string
+	database int
+	exclude  sources.Globs
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_REDIS
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized Redis source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Redis
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	if conn.GetUri() == "" {
+		return errors.New("a URI is required")
+	}
+	options, err := redis.ParseURL(conn.GetUri())
+	if err != nil {
+		return fmt.Errorf("invalid URI: %w", err)
+	}
+	if options.TLSConfig != nil {
+		tlsConfig := common.TLSClientConfig()
+		tlsConfig.ServerName = options.TLSConfig.ServerName
+		tlsConfig.InsecureSkipVerify = conn.GetInsecure()
+		options.TLSConfig = tlsConfig
+	}
+	// The client dials directly, and only wraps its own dials in TLS.
+	options.Dialer = func() (net.Conn, error) {
+		ctx, cancel := gocontext.WithTimeout(gocontext.Background(), dialTimeout)
+		defer cancel()
+		conn, err := common.DialContext(ctx, options.Network, options.Addr)
+		if err != nil || options.TLSConfig == nil {
+			return conn, err
+		}
+		tlsConn := tls.Client(conn, options.TLSConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+	if concurrency > options.PoolSize {
+		options.PoolSize = concurrency
+	}
+	// The client of v6 authenticates without a user, so the connections are
+	// authenticated by onConnect instead, which supports the users of ACLs.
+	u, _ := url.Parse(conn.GetUri())
+	s.username = u.User.Username()
+	s.password = options.Password
+	s.database = options.DB
+	options.Password = ""
+	options.DB = 0
+	s.options = options
+
+	exclude, err := sources.CompileGlobs(conn.GetExcludeGlobs())
+	if err != nil {
+		return err
+	}
+	s.exclude = exclude
+	return nil
+}
+
+// client returns a client of a database of the server.
+func (s *Source) client(ctx context.Context, database int) *redis.Client {
+	options := *s.options
+	options.OnConnect = func(conn *redis.Conn) error {
+		if s.password != "" {
+			args := []any{"AUTH", s.password}
+			if s.username != "" {
+				args = []any{"AUTH", s.username, s.password}
+			}
+			if err := conn.Do(args...).Err(); err != nil {
+				return fmt.Errorf("error authenticating: %w", err)
+			}
+		}
+		if database > 0 {
+			return conn.Select(database).Err()
+		}
+		return nil
+	}
+	return redis.NewClient(&options).WithContext(ctx)
+}
+
+// Chunks emits the values of the keys of the databases as chunks, one for
+// each key.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	databases, err := s.databases(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, database := range databases {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(databases), fmt.Sprintf("Database: %d", database), "")
+		if err := s.scanDatabase(ctx, database, scanErrs, chunksChan); err != nil {
+			scanErrs.Add(fmt.Errorf("error scanning database %d: %w", database, err))
+		}
+	}
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// EnumerateTargets reports each database with the number of its keys.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	databases, err := s.databases(ctx)
+	if err != nil {
+		return err
+	}
+	for _, database := range databases {
+		client := s.client(ctx, database)
+		keys, err := client.DBSize().Result()
+		_ = client.Close()
+		if err != nil {
+			return fmt.Errorf("error counting the keys of database %d: %w", database, err)
+		}
+		report(sources.Target{Name: fmt.Sprintf("%s/%d", s.options.Addr, database), Objects: keys})
+	}
+	return nil
+}
+
+// databases returns the databases to scan: those of the connection, or else
+// those with keys in the keyspace section of INFO. Servers that do not
+// allow INFO are scanned in the database of the URI.
+func (s *Source) databases(ctx context.Context) ([]int, error) {
+	var databases []int
+	for _, database := range s.conn.GetDatabases() {
+		databases = append(databases, int(database))
+	}
+	if len(databases) > 0 {
+		return databases, nil
+	}
+
+	client := s.client(ctx, s.database)
+	defer client.Close()
+	if err := client.Ping().Err(); err != nil {
+		return nil, fmt.Errorf("error connecting to Redis: %w", err)
+	}
+	info, err := client.Info("keyspace").Result()
+	if err != nil {
+		ctx.Logger().V(2).Info("could not list keyspaces", "error", err)
+		return []int{s.database}, nil
+	}
+	return keyspaces(info), nil
+}
+
+// keyspaces returns the databases of the keyspace section of INFO, whose
+// lines are like db0:keys=1,expires=0,avg_ttl=0.
+func keyspaces(info string) []int {
+	var databases []int
+	for _, line := range strings.Split(info, "\n") {
+		name, _, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || !strings.HasPrefix(name, "db") {
+			continue
+		}
+		if database, err := strconv.Atoi(strings.TrimPrefix(name, "db")); err == nil {
+			databases = append(databases, database)
+		}
+	}
+	return databases
+}
+
+// scanDatabase SCANs the keys of the patterns of a database, and reads
+// their values concurrently.
+func (s *Source) scanDatabase(ctx context.Context, database int, scanErrs *sources.ScanErrors, chunksChan chan *sources.Chunk) error {
+	client := s.client(ctx, database)
+	defer client.Close()
+
+	patterns := s.conn.GetKeyPatterns()
+	if len(patterns) == 0 {
+		patterns = []string{"*"}
+	}
+	// Keys of several patterns are only scanned once.
+	seen := make(map[string]struct{})
+	defer func() { _ = s.jobPool.Wait() }()
+	for _, pattern := range patterns {
+		keys := client.Scan(0, pattern, scanCount).Iterator()
+		for keys.Next() {
+			if common.IsDone(ctx) {
+				return nil
+			}
+			key := keys.Val()
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			if s.exclude.Match(key) {
+				sources.ReportSkip(ctx, key, sources.SkipReasonFiltered)
+				continue
+			}
+			s.jobPool.Go(func() error {
+				if err := s.scanKey(ctx, client, database, key, chunksChan); err != nil {
+					sources.ReportSkip(ctx, key, sources.SkipReasonError)
+					scanErrs.Add(fmt.Errorf("error scanning key %q of database %d: %w", key, database, err))
+				}
+				return nil
+			})
+		}
+		if err := keys.Err(); err != nil {
+			return fmt.Errorf("error scanning keys: %w", err)
+		}
+	}
+	return nil
+}
+
+// scanKey reads the value of a key according to its type. Hashes are read
+// as lines of field=value, and lists and sets as lines of their elements.
+func (s *Source) scanKey(ctx context.Context, client *redis.Client, database int, key string, chunksChan chan *sources.Chunk) error {
+	keyType, err := client.Type(key).Result()
+	if err != nil {
+		return err
+	}
+	var data []byte
+	switch keyType {
+	case "string":
+		data, err = client.Get(key).Bytes()
+		if err == redis.Nil {
+			// The key expired since it was scanned.
+			return nil
+		}
+	case "hash":
+		data, err = lines(client.HScan(key, 0, "", scanCount).Iterator(), true)
+	case "set":
+		data, err = lines(client.SScan(key, 0, "", scanCount).Iterator(), false)
+	case "list":
+		data, err = listLines(client, key)
+	case "none":
+		return nil
+	default:
+		sources.ReportSkip(ctx, key, sources.SkipReasonUnsupported)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	chunk := &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		Data:       data,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Redis{
+				Redis: &source_metadatapb.Redis{
+					Key:      sanitizer.UTF8(key),
+					Type:     keyType,
+					Database: int32(database),
+					Address:  s.options.Addr,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+	return common.CancellableWrite(ctx, chunksChan, chunk)
+}
+
+// lines returns the elements of a scan as lines, or its pairs of fields and
+// values as lines of field=value.
+func lines(elements *redis.ScanIterator, pairs bool) ([]byte, error) {
+	var buf bytes.Buffer
+	for elements.Next() {
+		buf.WriteString(elements.Val())
+		if pairs && elements.Next() {
+			buf.WriteString("=" + elements.Val())
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), elements.Err()
+}
+
+// listLines returns the elements of a list as lines, read by pages.
+func listLines(client *redis.Client, key string) ([]byte, error) {
+	var buf bytes.Buffer
+	for start := int64(0); ; start += scanCount {
+		elements, err := client.LRange(key, start, start+scanCount-1).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, element := range elements {
+			buf.WriteString(element + "\n")
+		}
+		if int64(len(elements)) < scanCount {
+			return buf.Bytes(), nil
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package redis
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// set and zset are the values of the keys of those types of testDatabases.
+type (
+	set  []string
+	zset []string
+)
+
+// testDatabases are the databases of testServer, whose values are strings,
+// hashes, lists, sets and sorted sets.
+var testDatabases = map[int]map[string]any{
+	0: {
+		"session:1":    "token=abc",
+		"session:2":    "",
+		"user:1":       map[string]string{"name": "ada", "password": "hunter2"},
+		"queue:jobs":   []string{"job1", "AKIA", "job3"},
+		"tags":         set{"b", "a"},
+		"leaderboard":  zset{"ada"},
+		"cache:ignore": "secret",
+	},
+	2: {
+		"config": "db_password=p4ss",
+	},
+}
+
+// testServer returns the address of a server of the RESP protocol with the
+// databases of testDatabases, which requires the ACL user "scanner" with
+// the password "pw". SCANs return two keys at a time.
+func testServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	return listen(t, listener)
+}
+
+// testTLSServer returns the address of a testServer of TLS up to the
+// version, and the PEM of its self-signed certificate.
+func testTLSServer(t *testing.T, maxVersion uint16) (string, []byte) {
+	t.Helper()
+	https := httptest.NewTLSServer(nil)
+	t.Cleanup(https.Close)
+	config := https.TLS.Clone()
+	config.MaxVersion = maxVersion
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: https.Certificate().Raw})
+	return listen(t, tls.NewListener(listener, config)), caPEM
+}
+
+func listen(t *testing.T, listener net.Listener) string {
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	authenticated := false
+	database := 0
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(args[0])
+		db := testDatabases[database]
+		switch {
+		case command == "AUTH":
+			if len(args) == 3 && args[1] == "scanner" && args[2] == "pw" {
+				authenticated = true
+				w.WriteString("+OK\r\n")
+			} else {
+				w.WriteString("-WRONGPASS invalid username-password pair\r\n")
+			}
+		case !authenticated:
+			w.WriteString("-NOAUTH Authentication required.\r\n")
+		case command == "SELECT":
+			database, _ = strconv.Atoi(args[1])
+			w.WriteString("+OK\r\n")
+		case command == "PING":
+			w.WriteString("+PONG\r\n")
+		case command == "INFO":
+			writeBulk(w, "# Keyspace\r\ndb0:keys=7,expires=0,avg_ttl=0\r\ndb2:keys=1,expires=0,avg_ttl=0\r\n")
+		case command == "DBSIZE":
+			fmt.Fprintf(w, ":%d\r\n", len(db))
+		case command == "SCAN":
+			var keys []string
+			for key := range db {
+				if ok, _ := path.Match(args[3], key); ok {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			cursor, _ := strconv.Atoi(args[1])
+			next := cursor + 2
+			if next >= len(keys) {
+				next = 0
+			}
+			fmt.Fprintf(w, "*2\r\n")
+			writeBulk(w, strconv.Itoa(next))
+			writeArray(w, keys[cursor:cursor+min(2, len(keys)-cursor)])
+		case command == "TYPE":
+			switch db[args[1]].(type) {
+			case string:
+				w.WriteString("+string\r\n")
+			case map[string]string:
+				w.WriteString("+hash\r\n")
+			case []string:
+				w.WriteString("+list\r\n")
+			case set:
+				w.WriteString("+set\r\n")
+			case zset:
+				w.WriteString("+zset\r\n")
+			default:
+				w.WriteString("+none\r\n")
+			}
+		case command == "GET":
+			writeBulk(w, db[args[1]].(string))
+		case command == "HSCAN":
+			var fields []string
+			for field := range db[args[1]].(map[string]string) {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			var elements []string
+			for _, field := range fields {
+				elements = append(elements, field, db[args[1]].(map[string]string)[field])
+			}
+			fmt.Fprintf(w, "*2\r\n")
+			writeBulk(w, "0")
+			writeArray(w, elements)
+		case command == "SSCAN":
+			elements := append([]string{}, db[args[1]].(set)...)
+			sort.Strings(elements)
+			fmt.Fprintf(w, "*2\r\n")
+			writeBulk(w, "0")
+			writeArray(w, elements)
+		case command == "LRANGE":
+			list := db[args[1]].([]string)
+			start, _ := strconv.Atoi(args[2])
+			stop, _ := strconv.Atoi(args[3])
+			if start > len(list) {
+				start = len(list)
+			}
+			if stop >= len(list) {
+				stop = len(list) - 1
+			}
+			writeArray(w, list[start:stop+1])
+		default:
+			w.WriteString("-ERR unknown command\r\n")
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:size])
+	}
+	return args, nil
+}
+
+func writeBulk(w *bufio.Writer, s string) {
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(s), s)
+}
+
+func writeArray(w *bufio.Writer, elements []string) {
+	fmt.Fprintf(w, "*%d\r\n", len(elements))
+	for _, element := range elements {
+		writeBulk(w, element)
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// chunks returns the data of the chunks of a source by database and key,
+// and their metadata.
+func chunks(t *testing.T, conn *sourcespb.Redis) (map[string]string, map[string]*source_metadatapb.Redis) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+	return sources.ChunksByKey(sources.RequireChunks(t, s), (*source_metadatapb.MetaData).GetRedis, func(m *source_metadatapb.Redis) string {
+		return fmt.Sprintf("%d/%s", m.GetDatabase(), m.GetKey())
+	})
+}
+
+func TestSource_Chunks(t *testing.T) {
+	defer func(count int64) { scanCount = count }(scanCount)
+	scanCount = 2
+
+	addr := testServer(t)
+	got, metadata := chunks(t, &sourcespb.Redis{
+		Uri:          "redis://scanner:pw@" + addr,
+		ExcludeGlobs: []string{"cache:*"},
+	})
+	assert.Equal(t, map[string]string{
+		"0/session:1":  "token=abc",
+		"0/user:1":     "name=ada\npassword=hunter2\n",
+		"0/queue:jobs": "job1\nAKIA\njob3\n",
+		"0/tags":       "a\nb\n",
+		"2/config":     "db_password=p4ss",
+	}, got)
+	assert.Equal(t, &source_metadatapb.Redis{Key: "user:1", Type: "hash", Database: 0, Address: addr}, metadata["0/user:1"])
+}
+
+func TestSource_ChunksPatterns(t *testing.T) {
+	addr := testServer(t)
+	got, _ := chunks(t, &sourcespb.Redis{
+		Uri:         "redis://scanner:pw@" + addr + "/2",
+		Databases:   []int32{0},
+		KeyPatterns: []string{"session:*", "user:*", "session:1"},
+	})
+	assert.Equal(t, map[string]string{
+		"0/session:1": "token=abc",
+		"0/user:1":    "name=ada\npassword=hunter2\n",
+	}, got)
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	addr := testServer(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Redis{Uri: "redis://scanner:wrong@" + addr}, 2)
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	assert.ErrorContains(t, err, "WRONGPASS")
+}
+
+func TestSource_ChunksTLS(t *testing.T) {
+	addr, caPEM := testTLSServer(t, tls.VersionTLS12)
+	conn := &sourcespb.Redis{Uri: "rediss://scanner:pw@" + addr}
+	err := sources.InitTestSource(t, &Source{}, conn, 2).Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	assert.Error(t, err, "self-signed certificate should not be trusted by default")
+
+	// The CAs and minimum version of the flags apply to the connections.
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0644))
+	require.NoError(t, common.ConfigureTLS(common.TLSConfig{CAFiles: []string{caFile}}))
+	got, _ := chunks(t, &sourcespb.Redis{Uri: conn.Uri, Databases: []int32{2}})
+	assert.Equal(t, map[string]string{"2/config": "db_password=p4ss"}, got)
+
+	require.NoError(t, common.ConfigureTLS(common.TLSConfig{MinVersion: "1.3"}))
+	err = sources.InitTestSource(t, &Source{}, conn, 2).Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	assert.Error(t, err, "TLS 1.2 should be refused")
+}
+
+// connectProxy returns the address of an HTTP proxy that allows CONNECT, and
+// a channel of the targets of its tunnels.
+func connectProxy(t *testing.T) (string, chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	tunnels := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				req, err := http.ReadRequest(r)
+				if err != nil || req.Method != http.MethodConnect {
+					return
+				}
+				upstream, err := net.Dial("tcp", req.Host)
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+				tunnels <- req.Host
+				fmt.Fprint(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+				go func() { _, _ = io.Copy(upstream, r) }()
+				_, _ = io.Copy(conn, upstream)
+			}()
+		}
+	}()
+	return listener.Addr().String(), tunnels
+}
+
+func TestSource_ChunksProxy(t *testing.T) {
+	addr := testServer(t)
+	proxyAddr, tunnels := connectProxy(t)
+	require.NoError(t, common.ConfigureProxy(common.ProxyConfig{
+		Rules: []common.ProxyRule{{Host: "127.0.0.1", Proxy: "http://" + proxyAddr}},
+	}))
+	t.Cleanup(func() { _ = common.ConfigureProxy(common.ProxyConfig{}) })
+
+	got, _ := chunks(t, &sourcespb.Redis{Uri: "redis://scanner:pw@" + addr, Databases: []int32{2}})
+	assert.Equal(t, map[string]string{"2/config": "db_password=p4ss"}, got)
+	assert.Equal(t, addr, <-tunnels)
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	addr := testServer(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Redis{Uri: "redis://scanner:pw@" + addr}, 2)
+	objects := make(map[string]int64)
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		objects[target.Name] = target.Objects
+	}))
+	assert.Equal(t, map[string]int64{addr + "/0": 7, addr + "/2": 1}, objects)
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.Redis{
+		"no uri":       {},
+		"wrong scheme": {Uri: "http://localhost:6379"},
+		"invalid db":   {Uri: "redis://localhost:6379/x"},
+		"invalid glob": {Uri: "redis://localhost:6379", ExcludeGlobs: []string{"[a"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
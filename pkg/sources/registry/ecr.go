@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/google/go-containerregistry/pkg/authn"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// ecrAPI is the part of the ECR API that the source uses.
+type ecrAPI interface {
+	GetAuthorizationTokenWithContext(aws.Context, *ecr.GetAuthorizationTokenInput, ...request.Option) (*ecr.GetAuthorizationTokenOutput, error)
+	DescribeRepositoriesPagesWithContext(aws.Context, *ecr.DescribeRepositoriesInput, func(*ecr.DescribeRepositoriesOutput, bool) bool, ...request.Option) error
+}
+
+var ecrHostPat = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// parseECRHost returns the account and the region of the host of an ECR
+// registry.
+func parseECRHost(host string) (account, region string, ok bool) {
+	m := ecrHostPat.FindStringSubmatch(host)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// newECRClient returns a client of the ECR API of a region with the
+// default AWS credentials.
+func newECRClient(region string) (ecrAPI, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            aws.Config{Region: aws.String(region)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating AWS session: %w", err)
+	}
+	return ecr.New(sess), nil
+}
+
+// ecrAuthenticator exchanges the AWS credentials of the client for the
+// basic auth of the registry of an account, whose token is "AWS:password".
+func ecrAuthenticator(ctx context.Context, client ecrAPI, account string) (authn.Authenticator, error) {
+	out, err := client.GetAuthorizationTokenWithContext(ctx, &ecr.GetAuthorizationTokenInput{
+		RegistryIds: []*string{aws.String(account)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting ECR authorization token: %w", err)
+	}
+	if len(out.AuthorizationData) == 0 {
+		return nil, fmt.Errorf("no ECR authorization token")
+	}
+	token, err := base64.StdEncoding.DecodeString(aws.StringValue(out.AuthorizationData[0].AuthorizationToken))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding ECR authorization token: %w", err)
+	}
+	username, password, ok := strings.Cut(string(token), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid ECR authorization token")
+	}
+	return &authn.Basic{Username: username, Password: password}, nil
+}
+
+// listECRRepositories returns the names of the repositories of the
+// registry of an account.
+func listECRRepositories(ctx context.Context, client ecrAPI, account string) ([]string, error) {
+	var names []string
+	input := &ecr.DescribeRepositoriesInput{RegistryId: aws.String(account)}
+	err := client.DescribeRepositoriesPagesWithContext(ctx, input, func(page *ecr.DescribeRepositoriesOutput, _ bool) bool {
+		for _, repo := range page.Repositories {
+			names = append(names, aws.StringValue(repo.RepositoryName))
+		}
+		return true
+	})
+	return names, err
+}
@@ -0,0 +1,563 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/google"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultMaxObjectSize = 250 * 1024 * 1024 // 250 MiB
+	maxObjectSizeLimit   = 250 * 1024 * 1024 // 250 MiB
+)
+
+// Source scans the images of the repositories of a container registry
+// through the distribution API, without a Docker daemon. It scans the
+// config of each image and the files of its layers, and layers shared by
+// images are only scanned once.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn          *sourcespb.Registry
+	registry      name.Registry
+	transport     http.RoundTripper
+	names         []string
+	include       sources.Globs
+	exclude       sources.Globs
+	tags          sources.Globs
+	maxObjectSize int64
+	// ecr is the client of the ECR API of ECR registries with cloud
+	// credentials, which list their repositories with it.
+	ecr ecrAPI
+	// blobs are the digests of the configs and layers scanned.
+	blobs *sources.Checksums
+
+	// archiveOptions configures how the archives of images are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// image is an image of a repository, which is a platform of the index of a
+// tag for multi-platform images.
+type image struct {
+	repository string
+	tag        string
+	digest     string
+	platform   string
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_REGISTRY
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of images are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized container registry source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Registry
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	registry, err := parseRegistry(conn.GetRegistry(), conn.GetInsecure())
+	if err != nil {
+		return err
+	}
+	s.registry = registry
+	tlsConfig := common.TLSClientConfig()
+	tlsConfig.InsecureSkipVerify = conn.GetInsecure()
+	s.transport = common.NewCustomTransport(&http.Transport{
+		TLSClientConfig:     tlsConfig,
+		Proxy:               common.Proxy,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	})
+
+	if conn.GetCloudCredentials() {
+		if _, _, ok := parseECRHost(registry.RegistryStr()); !ok && !isGoogle(registry.RegistryStr()) {
+			return fmt.Errorf("cloud credentials are only supported for ECR, GCR and Artifact Registry, not %s", registry.RegistryStr())
+		}
+	}
+
+	// Repositories without glob characters are named, and need no catalog.
+	var repoGlobs []string
+	for _, repo := range conn.GetRepositories() {
+		if sources.IsGlob(repo) {
+			repoGlobs = append(repoGlobs, repo)
+		} else {
+			s.names = append(s.names, repo)
+		}
+	}
+	if s.include, err = sources.CompileGlobs(repoGlobs, '/'); err != nil {
+		return err
+	}
+	if s.exclude, err = sources.CompileGlobs(conn.GetExcludeRepositories(), '/'); err != nil {
+		return err
+	}
+	if s.tags, err = sources.CompileGlobs(conn.GetTags()); err != nil {
+		return err
+	}
+
+	s.maxObjectSize = conn.GetMaxObjectSize()
+	if s.maxObjectSize <= 0 || s.maxObjectSize > maxObjectSizeLimit {
+		s.maxObjectSize = defaultMaxObjectSize
+	}
+	return nil
+}
+
+// parseRegistry returns the registry of a host, which may be given as a
+// URL. Registries of http:// URLs are insecure.
+func parseRegistry(registry string, insecure bool) (name.Registry, error) {
+	host := strings.TrimSuffix(registry, "/")
+	if h, ok := strings.CutPrefix(host, "http://"); ok {
+		host, insecure = h, true
+	}
+	host = strings.TrimPrefix(host, "https://")
+	if host == "" || strings.Contains(host, "/") {
+		return name.Registry{}, fmt.Errorf("invalid registry %q, it should be the host of a registry like ghcr.io", registry)
+	}
+	var opts []name.Option
+	if insecure {
+		opts = append(opts, name.Insecure)
+	}
+	reg, err := name.NewRegistry(host, opts...)
+	if err != nil {
+		return name.Registry{}, fmt.Errorf("invalid registry %q: %w", registry, err)
+	}
+	return reg, nil
+}
+
+// isGoogle returns whether a host is GCR or Artifact Registry, whose
+// credentials the Google keychain resolves.
+func isGoogle(host string) bool {
+	return host == "gcr.io" || strings.HasSuffix(host, ".gcr.io") || strings.HasSuffix(host, ".pkg.dev")
+}
+
+// remoteOptions returns the options of the requests to the registry, with
+// the credentials of the connection.
+func (s *Source) remoteOptions(ctx context.Context) ([]remote.Option, error) {
+	opts := []remote.Option{remote.WithContext(ctx), remote.WithTransport(s.transport)}
+	switch s.conn.GetCredential().(type) {
+	case nil, *sourcespb.Registry_Unauthenticated:
+		return append(opts, remote.WithAuth(authn.Anonymous)), nil
+	case *sourcespb.Registry_BasicAuth:
+		return append(opts, remote.WithAuth(&authn.Basic{
+			Username: s.conn.GetBasicAuth().GetUsername(),
+			Password: s.conn.GetBasicAuth().GetPassword(),
+		})), nil
+	case *sourcespb.Registry_BearerToken:
+		return append(opts, remote.WithAuth(&authn.Bearer{Token: s.conn.GetBearerToken()})), nil
+	case *sourcespb.Registry_DockerKeychain:
+		return append(opts, remote.WithAuthFromKeychain(authn.DefaultKeychain)), nil
+	case *sourcespb.Registry_CloudCredentials:
+		account, region, ok := parseECRHost(s.registry.RegistryStr())
+		if !ok {
+			return append(opts, remote.WithAuthFromKeychain(google.Keychain)), nil
+		}
+		if s.ecr == nil {
+			client, err := newECRClient(region)
+			if err != nil {
+				return nil, err
+			}
+			s.ecr = client
+		}
+		auth, err := ecrAuthenticator(ctx, s.ecr, account)
+		if err != nil {
+			return nil, err
+		}
+		return append(opts, remote.WithAuth(auth)), nil
+	default:
+		return nil, fmt.Errorf("unknown credential type: %T", s.conn.GetCredential())
+	}
+}
+
+// Chunks emits the configs and the files of the layers of the images of
+// the tags of the repositories as chunks. Configs and layers whose digest
+// was already scanned, in this scan or in those of the state path, are
+// skipped.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	opts, err := s.remoteOptions(ctx)
+	if err != nil {
+		return err
+	}
+	blobs, err := sources.LoadChecksums(s.conn.GetStatePath())
+	if err != nil {
+		return err
+	}
+	s.blobs = blobs
+
+	repositories, err := s.listRepositories(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, repo := range repositories {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(repositories), fmt.Sprintf("Repository: %s", repo), "")
+		tags, err := s.listTags(repo, opts)
+		if err != nil {
+			ctx.Logger().V(2).Info("could not list tags", "repository", repo, "error", err)
+			sources.ReportSkip(ctx, repo, sources.SkipReasonError)
+			continue
+		}
+		for _, tag := range tags {
+			repo, tag := repo, tag
+			s.jobPool.Go(func() error {
+				if err := s.scanTag(ctx, repo, tag, opts, chunksChan); err != nil {
+					sources.ReportSkip(ctx, repo+":"+tag, sources.SkipReasonError)
+					scanErrs.Add(fmt.Errorf("error scanning image %s:%s: %w", repo, tag, err))
+				}
+				return nil
+			})
+		}
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return s.blobs.Save()
+}
+
+// EnumerateTargets reports each repository with the number of its tags
+// that would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	opts, err := s.remoteOptions(ctx)
+	if err != nil {
+		return err
+	}
+	repositories, err := s.listRepositories(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repositories {
+		tags, err := s.listTags(repo, opts)
+		if err != nil {
+			ctx.Logger().V(2).Info("could not list tags", "repository", repo, "error", err)
+		}
+		report(sources.Target{Name: s.registry.Name() + "/" + repo, Objects: int64(len(tags)), Bytes: -1})
+	}
+	return nil
+}
+
+// listRepositories returns the named repositories of the connection, and
+// the repositories of the catalog of the registry that match its globs.
+// ECR, which has no catalog, lists them with its API.
+func (s *Source) listRepositories(ctx context.Context, opts []remote.Option) ([]string, error) {
+	repositories := make(map[string]struct{})
+	for _, repo := range s.names {
+		repositories[repo] = struct{}{}
+	}
+	if len(s.include) > 0 || len(s.names) == 0 {
+		var catalog []string
+		var err error
+		if s.ecr != nil {
+			account, _, _ := parseECRHost(s.registry.RegistryStr())
+			catalog, err = listECRRepositories(ctx, s.ecr, account)
+		} else {
+			catalog, err = remote.Catalog(ctx, s.registry, opts...)
+		}
+		switch statusCode(err) {
+		case 0:
+		case http.StatusUnauthorized:
+			return nil, fmt.Errorf("invalid credentials, status %d", http.StatusUnauthorized)
+		case http.StatusNotFound, http.StatusForbidden:
+			return nil, fmt.Errorf("error listing repositories, the registry may not have a catalog and the repositories should be named instead: %w", err)
+		default:
+			return nil, fmt.Errorf("error listing repositories: %w", err)
+		}
+		for _, repo := range catalog {
+			if len(s.include) == 0 || s.include.Match(repo) {
+				repositories[repo] = struct{}{}
+			}
+		}
+	}
+
+	var names []string
+	for repo := range repositories {
+		if !s.exclude.Match(repo) {
+			names = append(names, repo)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// statusCode returns the HTTP status of the response of a registry error,
+// or 0 for no error.
+func statusCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var terr *transport.Error
+	if errors.As(err, &terr) {
+		return terr.StatusCode
+	}
+	return -1
+}
+
+// listTags returns the tags of a repository that match the tag globs of
+// the connection.
+func (s *Source) listTags(repo string, opts []remote.Option) ([]string, error) {
+	all, err := remote.List(s.registry.Repo(repo), opts...)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, tag := range all {
+		if len(s.tags) == 0 || s.tags.Match(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// scanTag scans the image of a tag, or each image of its index.
+func (s *Source) scanTag(ctx context.Context, repo, tag string, opts []remote.Option, chunksChan chan *sources.Chunk) error {
+	desc, err := remote.Get(s.registry.Repo(repo).Tag(tag), opts...)
+	if err != nil {
+		return err
+	}
+	img := image{repository: repo, tag: tag, digest: desc.Digest.String()}
+
+	switch {
+	case desc.MediaType.IsIndex():
+		index, err := desc.ImageIndex()
+		if err != nil {
+			return err
+		}
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			return err
+		}
+		for _, m := range manifest.Manifests {
+			if !m.MediaType.IsImage() {
+				sources.ReportSkip(ctx, repo+"@"+m.Digest.String(), sources.SkipReasonUnsupported)
+				continue
+			}
+			platformImg := img
+			platformImg.digest = m.Digest.String()
+			if m.Platform != nil {
+				platformImg.platform = m.Platform.String()
+			}
+			v1Img, err := index.Image(m.Digest)
+			if err != nil {
+				return err
+			}
+			if err := s.scanImage(ctx, platformImg, v1Img, chunksChan); err != nil {
+				return err
+			}
+		}
+		return nil
+	case desc.MediaType.IsSchema1():
+		v1Img, err := desc.Schema1()
+		if err != nil {
+			return err
+		}
+		return s.scanImage(ctx, img, v1Img, chunksChan)
+	case desc.MediaType.IsImage():
+		v1Img, err := desc.Image()
+		if err != nil {
+			return err
+		}
+		return s.scanImage(ctx, img, v1Img, chunksChan)
+	default:
+		// Artifacts like Helm charts and signatures are not images.
+		ctx.Logger().V(3).Info("skipping artifact", "repository", repo, "tag", tag, "media_type", desc.MediaType)
+		sources.ReportSkip(ctx, repo+":"+tag, sources.SkipReasonUnsupported)
+		return nil
+	}
+}
+
+// scanImage scans the config and the layers of an image that were not
+// scanned yet.
+func (s *Source) scanImage(ctx context.Context, img image, v1Img v1.Image, chunksChan chan *sources.Chunk) error {
+	ctx.Logger().V(2).Info("scanning image", "repository", img.repository, "tag", img.tag, "digest", img.digest)
+
+	// The config has the environment variables and the history of the
+	// commands of the image.
+	configName, err := v1Img.ConfigName()
+	if err != nil {
+		return err
+	}
+	if s.blobs.Claim(configName.String()) {
+		config, err := v1Img.RawConfigFile()
+		if err == nil {
+			err = s.scanReader(ctx, s.chunkSkeleton(img, configName.String(), ""), bytes.NewReader(config), chunksChan)
+		}
+		if err != nil {
+			s.blobs.Forget(configName.String())
+			return err
+		}
+	}
+
+	layers, err := v1Img.Layers()
+	if err != nil {
+		return err
+	}
+	for _, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			return err
+		}
+		if mediaType, err := layer.MediaType(); err == nil && !mediaType.IsDistributable() {
+			// Foreign layers are not in the registry.
+			sources.ReportSkip(ctx, img.repository+"@"+digest.String(), sources.SkipReasonUnsupported)
+			continue
+		}
+		if !s.blobs.Claim(digest.String()) {
+			ctx.Logger().V(5).Info("skipping layer already scanned", "layer", digest.String())
+			continue
+		}
+		if err := s.scanLayer(ctx, img, digest.String(), layer, chunksChan); err != nil {
+			s.blobs.Forget(digest.String())
+			return fmt.Errorf("error scanning layer %s: %w", digest, err)
+		}
+	}
+	return nil
+}
+
+// scanLayer scans the regular files of a layer.
+func (s *Source) scanLayer(ctx context.Context, img image, digest string, layer v1.Layer, chunksChan chan *sources.Chunk) error {
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	tarReader := tar.NewReader(rc)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Whiteouts mark the files that the layer removes.
+		if header.Typeflag != tar.TypeReg || strings.HasPrefix(path.Base(header.Name), ".wh.") || header.Size == 0 {
+			continue
+		}
+		file := "/" + strings.TrimPrefix(header.Name, "/")
+		if header.Size > s.maxObjectSize {
+			sources.ReportSkipBytes(ctx, img.repository+"@"+digest+":"+file, sources.SkipReasonSize, header.Size)
+			continue
+		}
+		if err := s.scanReader(ctx, s.chunkSkeleton(img, digest, file), tarReader, chunksChan); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Source) scanReader(ctx context.Context, skel *sources.Chunk, body io.Reader, chunksChan chan *sources.Chunk) error {
+	reader, err := diskbufferreader.New(io.LimitReader(body, s.maxObjectSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+
+	chunkReader := sources.NewChunkReader(s.archiveOptions.ChunkOptions()...)
+	for data := range chunkReader(ctx, reader) {
+		if err := data.Error(); err != nil {
+			return err
+		}
+		chunk := *skel
+		chunk.Data = data.Bytes()
+		chunk.Offset, chunk.LineStart, chunk.LineOffset = data.Offset(), data.LineStart(), data.LineOffset()
+		if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Source) chunkSkeleton(img image, layer, file string) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Registry{
+				Registry: &source_metadatapb.Registry{
+					Registry:   s.registry.RegistryStr(),
+					Repository: img.repository,
+					Tag:        img.tag,
+					Digest:     img.digest,
+					Platform:   img.platform,
+					Layer:      layer,
+					File:       sanitizer.UTF8(file),
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
@@ -0,0 +1,296 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	ggcrregistry "github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+var testAuth = &credentialspb.BasicAuth{Username: "admin", Password: "pw"}
+
+// testLayer returns a layer of files by path.
+func testLayer(t *testing.T, files map[string]string) v1.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for path, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: path, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	})
+	require.NoError(t, err)
+	return layer
+}
+
+func testImage(t *testing.T, env string, layers ...v1.Layer) v1.Image {
+	t.Helper()
+	img, err := mutate.AppendLayers(empty.Image, layers...)
+	require.NoError(t, err)
+	img, err = mutate.Config(img, v1.Config{Env: []string{env}})
+	require.NoError(t, err)
+	return img
+}
+
+// startRegistry starts a registry that requires testAuth with the images:
+//
+//	app:1.0 and app:latest, the same image of the base layer and an app layer
+//	app:2.0, the base layer and another app layer
+//	tools/cli:1.0, an index of two platforms
+func startRegistry(t *testing.T) string {
+	t.Helper()
+	handler := ggcrregistry.New(ggcrregistry.Logger(log.New(io.Discard, "", 0)))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, _ := r.BasicAuth(); user != testAuth.Username || password != testAuth.Password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	base := testLayer(t, map[string]string{
+		"etc/os-release":       "NAME=test",
+		"etc/.wh.removed.conf": "",
+	})
+	app1 := testImage(t, "DB_PASSWORD=hunter2", base, testLayer(t, map[string]string{"app/config.yaml": "token: AKIA1"}))
+	app2 := testImage(t, "DB_PASSWORD=hunter3", base, testLayer(t, map[string]string{"app/config.yaml": "token: AKIA2"}))
+	amd64 := testImage(t, "ARCH=amd64", testLayer(t, map[string]string{"usr/bin/cli.conf": "amd64 secret"}))
+	arm64 := testImage(t, "ARCH=arm64", testLayer(t, map[string]string{"usr/bin/cli.conf": "arm64 secret"}))
+	index := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+	)
+
+	auth := remote.WithAuth(&authn.Basic{Username: testAuth.Username, Password: testAuth.Password})
+	for ref, img := range map[string]v1.Image{"app:1.0": app1, "app:latest": app1, "app:2.0": app2} {
+		tag, err := name.NewTag(host+"/"+ref, name.Insecure)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(tag, img, auth))
+	}
+	tag, err := name.NewTag(host+"/tools/cli:1.0", name.Insecure)
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(tag, index, auth))
+	return server.URL
+}
+
+// chunks returns the data of the chunks of the files of a source by
+// repository and path, the data of the chunks of the configs of images,
+// and the metadata of the last chunk of each file.
+func chunks(t *testing.T, conn *sourcespb.Registry) (map[string][]string, []string, map[string]*source_metadatapb.Registry) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+
+	files := make(map[string][]string)
+	var configs []string
+	metadata := make(map[string]*source_metadatapb.Registry)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetRegistry()
+		if m.GetFile() == "" {
+			configs = append(configs, string(chunk.Data))
+			continue
+		}
+		key := m.GetRepository() + ":" + m.GetFile()
+		files[key] = append(files[key], string(chunk.Data))
+		sort.Strings(files[key])
+		metadata[key] = m
+	}
+	return files, configs, metadata
+}
+
+// configsWith returns the number of configs with an environment variable.
+func configsWith(configs []string, env string) int {
+	n := 0
+	for _, config := range configs {
+		if strings.Contains(config, `"`+env+`"`) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSource_Chunks(t *testing.T) {
+	endpoint := startRegistry(t)
+	files, configs, metadata := chunks(t, &sourcespb.Registry{
+		Registry:   endpoint,
+		Credential: &sourcespb.Registry_BasicAuth{BasicAuth: testAuth},
+	})
+
+	// The layers and configs shared by images are only scanned once.
+	assert.Equal(t, map[string][]string{
+		"app:/etc/os-release":         {"NAME=test"},
+		"app:/app/config.yaml":        {"token: AKIA1", "token: AKIA2"},
+		"tools/cli:/usr/bin/cli.conf": {"amd64 secret", "arm64 secret"},
+	}, files)
+	assert.Len(t, configs, 4)
+	for _, env := range []string{"DB_PASSWORD=hunter2", "DB_PASSWORD=hunter3", "ARCH=amd64", "ARCH=arm64"} {
+		assert.Equal(t, 1, configsWith(configs, env), env)
+	}
+
+	m := metadata["tools/cli:/usr/bin/cli.conf"]
+	assert.Equal(t, strings.TrimPrefix(endpoint, "http://"), m.GetRegistry())
+	assert.Equal(t, "1.0", m.GetTag())
+	assert.Contains(t, []string{"linux/amd64", "linux/arm64"}, m.GetPlatform())
+	assert.True(t, strings.HasPrefix(m.GetDigest(), "sha256:"))
+	assert.True(t, strings.HasPrefix(m.GetLayer(), "sha256:"))
+}
+
+func TestSource_ChunksFilters(t *testing.T) {
+	endpoint := startRegistry(t)
+	files, configs, metadata := chunks(t, &sourcespb.Registry{
+		Registry:     endpoint,
+		Credential:   &sourcespb.Registry_BasicAuth{BasicAuth: testAuth},
+		Repositories: []string{"app"},
+		Tags:         []string{"2.*"},
+	})
+	assert.Equal(t, []string{"token: AKIA2"}, files["app:/app/config.yaml"])
+	assert.Equal(t, "2.0", metadata["app:/app/config.yaml"].GetTag())
+	assert.NotContains(t, files, "tools/cli:/usr/bin/cli.conf")
+	assert.Len(t, configs, 1)
+
+	files, _, _ = chunks(t, &sourcespb.Registry{
+		Registry:            endpoint,
+		Credential:          &sourcespb.Registry_BasicAuth{BasicAuth: testAuth},
+		Repositories:        []string{"**"},
+		ExcludeRepositories: []string{"app"},
+	})
+	assert.Equal(t, map[string][]string{"tools/cli:/usr/bin/cli.conf": {"amd64 secret", "arm64 secret"}}, files)
+}
+
+func TestSource_ChunksState(t *testing.T) {
+	endpoint := startRegistry(t)
+	conn := &sourcespb.Registry{
+		Registry:   endpoint,
+		Credential: &sourcespb.Registry_BasicAuth{BasicAuth: testAuth},
+		StatePath:  filepath.Join(t.TempDir(), "state.json"),
+	}
+	files, configs, _ := chunks(t, conn)
+	assert.Len(t, files, 3)
+	assert.Len(t, configs, 4)
+
+	// The next scan skips the layers and configs that did not change.
+	files, configs, _ = chunks(t, conn)
+	assert.Empty(t, files)
+	assert.Empty(t, configs)
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	endpoint := startRegistry(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Registry{
+		Registry:   endpoint,
+		Credential: &sourcespb.Registry_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: "admin", Password: "wrong"}},
+	}, 2)
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	assert.ErrorContains(t, err, "invalid credentials")
+}
+
+func TestSource_EnumerateTargets(t *testing.T) {
+	endpoint := startRegistry(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Registry{
+		Registry:   endpoint,
+		Credential: &sourcespb.Registry_BasicAuth{BasicAuth: testAuth},
+	}, 2)
+	objects := make(map[string]int64)
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		objects[target.Name] = target.Objects
+	}))
+	host := strings.TrimPrefix(endpoint, "http://")
+	assert.Equal(t, map[string]int64{
+		host + "/app":       3,
+		host + "/tools/cli": 1,
+	}, objects)
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.Registry{
+		"no registry":       {},
+		"path":              {Registry: "https://ghcr.io/trufflesecurity"},
+		"invalid glob":      {Registry: "ghcr.io", Repositories: []string{"[a"}},
+		"cloud credentials": {Registry: "ghcr.io", Credential: &sourcespb.Registry_CloudCredentials{CloudCredentials: true}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
+
+func TestParseECRHost(t *testing.T) {
+	account, region, ok := parseECRHost("123456789012.dkr.ecr.eu-west-1.amazonaws.com")
+	assert.True(t, ok)
+	assert.Equal(t, "123456789012", account)
+	assert.Equal(t, "eu-west-1", region)
+
+	_, region, ok = parseECRHost("123456789012.dkr.ecr-fips.us-gov-west-1.amazonaws.com")
+	assert.True(t, ok)
+	assert.Equal(t, "us-gov-west-1", region)
+
+	_, _, ok = parseECRHost("public.ecr.aws")
+	assert.False(t, ok)
+}
+
+// fakeECR is an ECR API of repositories in pages of one.
+type fakeECR struct {
+	repositories []string
+}
+
+func (f *fakeECR) GetAuthorizationTokenWithContext(_ aws.Context, in *ecr.GetAuthorizationTokenInput, _ ...request.Option) (*ecr.GetAuthorizationTokenOutput, error) {
+	return &ecr.GetAuthorizationTokenOutput{AuthorizationData: []*ecr.AuthorizationData{
+		{AuthorizationToken: aws.String("QVdTOnNlY3JldA==")}, // AWS:secret
+	}}, nil
+}
+
+func (f *fakeECR) DescribeRepositoriesPagesWithContext(_ aws.Context, in *ecr.DescribeRepositoriesInput, fn func(*ecr.DescribeRepositoriesOutput, bool) bool, _ ...request.Option) error {
+	for i, repo := range f.repositories {
+		page := &ecr.DescribeRepositoriesOutput{Repositories: []*ecr.Repository{{RepositoryName: aws.String(repo), RegistryId: in.RegistryId}}}
+		if !fn(page, i == len(f.repositories)-1) {
+			break
+		}
+	}
+	return nil
+}
+
+func TestECR(t *testing.T) {
+	client := &fakeECR{repositories: []string{"api", "web"}}
+	auth, err := ecrAuthenticator(context.Background(), client, "123456789012")
+	require.NoError(t, err)
+	assert.Equal(t, &authn.Basic{Username: "AWS", Password: "secret"}, auth)
+
+	names, err := listECRRepositories(context.Background(), client, "123456789012")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "web"}, names)
+}
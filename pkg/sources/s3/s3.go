@@ -2,6 +2,9 @@ package s3
 
 import (
 	"fmt"
+	"io"
+	"net/url"
+	"path"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -9,6 +12,8 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
@@ -45,6 +50,11 @@ type Source struct {
 	conn          *sourcespb.S3
 	jobPool       *errgroup.Group
 	maxObjectSize int64
+	// archiveOptions configures how the archives of objects are extracted.
+	archiveOptions sources.ArchiveOptions
+	// endpoint overrides the endpoint of the S3 API, which is then
+	// addressed path-style.
+	endpoint string
 	sources.CommonSourceUnitUnmarshaller
 }
 
@@ -88,6 +98,20 @@ func (s *Source) Init(aCtx context.Context, name string, jobId, sourceId int64,
 
 	s.setMaxObjectSize(conn.GetMaxObjectSize())
 
+	for _, chain := range conn.GetRoleChains() {
+		if _, ok := conn.GetCredential().(*sourcespb.S3_Unauthenticated); ok {
+			return errors.New("roles cannot be assumed without credentials")
+		}
+		if len(chain.GetRoles()) == 0 {
+			return errors.New("role chains need a role")
+		}
+		for _, role := range chain.GetRoles() {
+			if role.GetRoleArn() == "" {
+				return errors.New("roles need an ARN")
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -102,10 +126,29 @@ func (s *Source) setMaxObjectSize(maxObjectSize int64) {
 	}
 }
 
-func (s *Source) newClient(region string) (*s3.S3, error) {
+// WithArchiveOptions sets how the archives of objects are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// newClient returns a client of a region with the credentials of the
+// source, or with creds if it is not nil.
+func (s *Source) newClient(region string, creds *credentials.Credentials) (*s3.S3, error) {
+	sess, err := s.newSession(region, creds)
+	if err != nil {
+		return nil, err
+	}
+	return s3.New(sess), nil
+}
+
+func (s *Source) newSession(region string, creds *credentials.Credentials) (*session.Session, error) {
 	cfg := aws.NewConfig()
 	cfg.CredentialsChainVerboseErrors = aws.Bool(true)
 	cfg.Region = aws.String(region)
+	if s.endpoint != "" {
+		cfg.Endpoint = aws.String(s.endpoint)
+		cfg.S3ForcePathStyle = aws.Bool(true)
+	}
 
 	switch cred := s.conn.GetCredential().(type) {
 	case *sourcespb.S3_SessionToken:
@@ -119,81 +162,138 @@ func (s *Source) newClient(region string) (*s3.S3, error) {
 	default:
 		return nil, errors.Errorf("invalid configuration given for %s source", s.name)
 	}
+	if creds != nil {
+		cfg.Credentials = creds
+	}
 
-	sess, err := session.NewSessionWithOptions(session.Options{
+	return session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
 		Config:            *cfg,
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	return s3.New(sess), nil
 }
 
-// Chunks emits chunks of bytes over a channel.
-func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
-	const defaultAWSRegion = "us-east-1"
+// roleSessionName is the name of the sessions of the roles assumed.
+const roleSessionName = "trufflehog"
 
-	client, err := s.newClient(defaultAWSRegion)
-	if err != nil {
-		return errors.WrapPrefix(err, "could not create s3 client", 0)
+// roleCredentials returns the credentials of the last role of a chain, each
+// role of which is assumed with the credentials of the role before it.
+func (s *Source) roleCredentials(chain *sourcespb.AWSRoleChain) (*credentials.Credentials, error) {
+	var creds *credentials.Credentials
+	for _, role := range chain.GetRoles() {
+		role := role
+		sess, err := s.newSession(defaultAWSRegion, creds)
+		if err != nil {
+			return nil, err
+		}
+		creds = stscreds.NewCredentials(sess, role.GetRoleArn(), func(p *stscreds.AssumeRoleProvider) {
+			p.RoleSessionName = roleSessionName
+			if role.GetExternalId() != "" {
+				p.ExternalID = aws.String(role.GetExternalId())
+			}
+		})
 	}
+	return creds, nil
+}
 
-	var bucketsToScan []string
+// bucketClient is a bucket to scan, with a client of its account.
+type bucketClient struct {
+	name   string
+	client *s3.S3
+}
 
-	switch s.conn.GetCredential().(type) {
-	case *sourcespb.S3_AccessKey, *sourcespb.S3_SessionToken, *sourcespb.S3_CloudEnvironment:
-		if len(s.conn.Buckets) == 0 {
-			res, err := client.ListBuckets(&s3.ListBucketsInput{})
-			if err != nil {
-				return fmt.Errorf("could not list s3 buckets: %w", err)
-			}
-			buckets := res.Buckets
-			for _, bucket := range buckets {
-				bucketsToScan = append(bucketsToScan, *bucket.Name)
+// buckets returns the buckets to scan with the credentials of the source, or
+// with those of the last role of each chain of roles. Buckets of more than
+// one chain are scanned with the first, and chains whose buckets cannot be
+// listed are skipped.
+func (s *Source) buckets(ctx context.Context) ([]bucketClient, error) {
+	chains := s.conn.GetRoleChains()
+	if len(chains) == 0 {
+		client, err := s.newClient(defaultAWSRegion, nil)
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "could not create s3 client", 0)
+		}
+		names, err := s.bucketsToScan(client)
+		if err != nil {
+			return nil, err
+		}
+		buckets := make([]bucketClient, 0, len(names))
+		for _, name := range names {
+			buckets = append(buckets, bucketClient{name: name, client: client})
+		}
+		return buckets, nil
+	}
+
+	var buckets []bucketClient
+	seen := make(map[string]bool)
+	for _, chain := range chains {
+		roles := chain.GetRoles()
+		role := roles[len(roles)-1].GetRoleArn()
+		creds, err := s.roleCredentials(chain)
+		if err != nil {
+			return nil, err
+		}
+		client, err := s.newClient(defaultAWSRegion, creds)
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "could not create s3 client", 0)
+		}
+		names, err := s.bucketsToScan(client)
+		if err != nil {
+			s.log.Error(err, "could not list the buckets of role, skipping", "role", role)
+			continue
+		}
+		for _, name := range names {
+			if seen[name] {
+				continue
 			}
-		} else {
-			bucketsToScan = s.conn.Buckets
+			seen[name] = true
+			buckets = append(buckets, bucketClient{name: name, client: client})
 		}
-	case *sourcespb.S3_Unauthenticated:
-		bucketsToScan = s.conn.Buckets
-	default:
-		return errors.Errorf("invalid configuration given for %s source", s.name)
+	}
+	return buckets, nil
+}
+
+// requestOptions are the options of the requests of buckets and objects.
+func (s *Source) requestOptions() []request.Option {
+	if !s.conn.GetRequesterPays() {
+		return nil
+	}
+	return []request.Option{request.WithSetRequestHeaders(map[string]string{"x-amz-request-payer": s3.RequestPayerRequester})}
+}
+
+const defaultAWSRegion = "us-east-1"
+
+// Chunks emits chunks of bytes over a channel.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	bucketsToScan, err := s.buckets(ctx)
+	if err != nil {
+		return err
 	}
 
 	objectCount := uint64(0)
-	for i, bucket := range bucketsToScan {
+	for i, b := range bucketsToScan {
 		if common.IsDone(ctx) {
 			return nil
 		}
+		bucket := b.name
 
 		s.SetProgressComplete(i, len(bucketsToScan), fmt.Sprintf("Bucket: %s", bucket), "")
 
 		s.log.Info("Scanning bucket", "bucket", bucket)
-		region, err := s3manager.GetBucketRegionWithClient(context.Background(), client, bucket)
+		regionalClient, err := s.regionalClient(b.client, bucket)
 		if err != nil {
-			s.log.Error(err, "could not get s3 region for bucket", "bucket", bucket)
+			s.log.Error(err, "could not create s3 client for bucket", "bucket", bucket)
 			continue
 		}
-		var regionalClient *s3.S3
-		if region != defaultAWSRegion {
-			regionalClient, err = s.newClient(region)
-			if err != nil {
-				s.log.Error(err, "could not make regional s3 client")
-			}
-		} else {
-			regionalClient = client
-		}
 
 		errorCount := sync.Map{}
+		splits := newSplitObjects()
 
 		err = regionalClient.ListObjectsV2PagesWithContext(
 			ctx, &s3.ListObjectsV2Input{Bucket: &bucket},
 			func(page *s3.ListObjectsV2Output, last bool) bool {
-				s.pageChunker(ctx, regionalClient, chunksChan, bucket, page, &errorCount, i+1, &objectCount)
+				s.pageChunker(ctx, regionalClient, chunksChan, bucket, page, &errorCount, i+1, &objectCount, splits)
 				return true
-			})
+			}, s.requestOptions()...)
 
 		if err != nil {
 			return fmt.Errorf(
@@ -201,168 +301,485 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) err
 				bucket,
 				err)
 		}
+		s.splitChunker(ctx, regionalClient, chunksChan, bucket, splits, &errorCount, i+1, &objectCount)
+
+		if s.conn.GetIncludeVersions() {
+			deleted := make(map[string]bool)
+			err = regionalClient.ListObjectVersionsPagesWithContext(
+				ctx, &s3.ListObjectVersionsInput{Bucket: &bucket},
+				func(page *s3.ListObjectVersionsOutput, last bool) bool {
+					s.versionChunker(ctx, regionalClient, chunksChan, bucket, page, deleted, &errorCount, i+1, &objectCount)
+					return true
+				}, s.requestOptions()...)
+			if err != nil {
+				return fmt.Errorf("could not list object versions in s3 bucket: bucket %s: %w", bucket, err)
+			}
+		}
 	}
 	s.SetProgressComplete(len(bucketsToScan), len(bucketsToScan), fmt.Sprintf("Completed scanning source %s. %d objects scanned.", s.name, objectCount), "")
 
 	return nil
 }
 
-// pageChunker emits chunks onto the given channel from a page
-func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, page *s3.ListObjectsV2Output, errorCount *sync.Map, pageNumber int, objectCount *uint64) {
+// EnumerateTargets reports each bucket with the number and size of the
+// objects that would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	buckets, err := s.buckets(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range buckets {
+		bucket := b.name
+		regionalClient, err := s.regionalClient(b.client, bucket)
+		if err != nil {
+			s.log.Error(err, "could not create s3 client for bucket", "bucket", bucket)
+			continue
+		}
+		target := sources.Target{Name: bucket}
+		err = regionalClient.ListObjectsV2PagesWithContext(
+			ctx, &s3.ListObjectsV2Input{Bucket: &bucket},
+			func(page *s3.ListObjectsV2Output, last bool) bool {
+				for _, obj := range page.Contents {
+					if s.shouldScan(obj) {
+						target.Objects++
+						target.Bytes += *obj.Size
+					}
+				}
+				return true
+			}, s.requestOptions()...)
+		if err != nil {
+			return fmt.Errorf("could not list objects in s3 bucket: bucket %s: %w", bucket, err)
+		}
+		if s.conn.GetIncludeVersions() {
+			err = regionalClient.ListObjectVersionsPagesWithContext(
+				ctx, &s3.ListObjectVersionsInput{Bucket: &bucket},
+				func(page *s3.ListObjectVersionsOutput, last bool) bool {
+					for _, v := range page.Versions {
+						if obj := noncurrentObject(v); obj != nil && s.shouldScan(obj) {
+							target.Objects++
+							target.Bytes += *obj.Size
+						}
+					}
+					return true
+				}, s.requestOptions()...)
+			if err != nil {
+				return fmt.Errorf("could not list object versions in s3 bucket: bucket %s: %w", bucket, err)
+			}
+		}
+		report(target)
+	}
+	return nil
+}
+
+// bucketsToScan returns the configured buckets, or all buckets the
+// credentials can list if none are configured.
+func (s *Source) bucketsToScan(client *s3.S3) ([]string, error) {
+	switch s.conn.GetCredential().(type) {
+	case *sourcespb.S3_AccessKey, *sourcespb.S3_SessionToken, *sourcespb.S3_CloudEnvironment:
+		if len(s.conn.Buckets) != 0 {
+			return s.conn.Buckets, nil
+		}
+		res, err := client.ListBuckets(&s3.ListBucketsInput{})
+		if err != nil {
+			return nil, fmt.Errorf("could not list s3 buckets: %w", err)
+		}
+		var buckets []string
+		for _, bucket := range res.Buckets {
+			buckets = append(buckets, *bucket.Name)
+		}
+		return buckets, nil
+	case *sourcespb.S3_Unauthenticated:
+		return s.conn.Buckets, nil
+	default:
+		return nil, errors.Errorf("invalid configuration given for %s source", s.name)
+	}
+}
+
+// regionalClient returns a client for the region of the bucket.
+func (s *Source) regionalClient(client *s3.S3, bucket string) (*s3.S3, error) {
+	region, err := s3manager.GetBucketRegionWithClient(context.Background(), client, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("could not get s3 region: %w", err)
+	}
+	if region == defaultAWSRegion {
+		return client, nil
+	}
+	regionalClient, err := s.newClient(region, client.Config.Credentials)
+	if err != nil {
+		return nil, fmt.Errorf("could not make regional s3 client: %w", err)
+	}
+	return regionalClient, nil
+}
+
+// shouldScan reports whether the object would be scanned, without reporting
+// why it would be skipped.
+func (s *Source) shouldScan(obj *s3.Object) bool {
+	if obj == nil || obj.StorageClass == nil || strings.Contains(*obj.StorageClass, "GLACIER") {
+		return false
+	}
+	if *obj.Size > s.maxObjectSize || *obj.Size == 0 || common.SkipFile(*obj.Key) {
+		return false
+	}
+	return !strings.HasSuffix(*obj.Key, "/")
+}
+
+// pageChunker emits chunks onto the given channel from a page. The objects
+// named as parts of split files are added to splits instead, if it is not
+// nil, to be scanned once the bucket is listed.
+func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, page *s3.ListObjectsV2Output, errorCount *sync.Map, pageNumber int, objectCount *uint64, splits *splitObjects) {
 	for _, obj := range page.Contents {
 		obj := obj
 		if common.IsDone(ctx) {
 			return
 		}
 
-		if obj == nil {
+		if s.skipObject(ctx, obj) {
 			continue
 		}
 
-		// skip GLACIER and GLACIER_IR objects
-		if obj.StorageClass == nil || strings.Contains(*obj.StorageClass, "GLACIER") {
-			s.log.V(5).Info("Skipping object in storage class", "storage_class", *obj.StorageClass, "object", *obj.Key)
+		if splits != nil && splits.add(obj) {
 			continue
 		}
 
-		// ignore large files
-		if *obj.Size > s.maxObjectSize {
-			s.log.V(3).Info("Skipping %d byte file (over maxObjectSize limit)", "object", *obj.Key)
-			continue
+		s.jobPool.Go(func() error {
+			defer common.RecoverWithExit(ctx)
+			return s.scanObject(ctx, client, chunksChan, bucket, obj, nil, errorCount, pageNumber, objectCount)
+		})
+	}
+
+	_ = s.jobPool.Wait()
+}
+
+// objectVersion is a noncurrent version of an object.
+type objectVersion struct {
+	id string
+	// deleted is whether the current version of the object is a delete
+	// marker.
+	deleted bool
+}
+
+// versionChunker emits chunks onto the given channel from the noncurrent
+// versions of a page of the versions of a bucket. deleted holds the keys
+// whose current version is a delete marker, which is listed on the page of
+// their newest versions.
+func (s *Source) versionChunker(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, page *s3.ListObjectVersionsOutput, deleted map[string]bool, errorCount *sync.Map, pageNumber int, objectCount *uint64) {
+	for _, marker := range page.DeleteMarkers {
+		if aws.BoolValue(marker.IsLatest) {
+			deleted[aws.StringValue(marker.Key)] = true
 		}
+	}
 
-		// file empty file
-		if *obj.Size == 0 {
-			s.log.V(5).Info("Skipping 0 byte file", "object", *obj.Key)
-			continue
+	for _, v := range page.Versions {
+		if common.IsDone(ctx) {
+			return
 		}
 
-		// skip incompatible extensions
-		if common.SkipFile(*obj.Key) {
-			s.log.V(5).Info("Skipping file with incompatible extension", "object", *obj.Key)
+		obj := noncurrentObject(v)
+		if obj == nil || s.skipObject(ctx, obj) {
 			continue
 		}
 
+		version := &objectVersion{id: aws.StringValue(v.VersionId), deleted: deleted[*obj.Key]}
 		s.jobPool.Go(func() error {
 			defer common.RecoverWithExit(ctx)
+			return s.scanObject(ctx, client, chunksChan, bucket, obj, version, errorCount, pageNumber, objectCount)
+		})
+	}
 
-			if strings.HasSuffix(*obj.Key, "/") {
-				s.log.V(5).Info("Skipping directory", "object", *obj.Key)
-				return nil
-			}
+	_ = s.jobPool.Wait()
+}
 
-			path := strings.Split(*obj.Key, "/")
-			prefix := strings.Join(path[:len(path)-1], "/")
+// noncurrentObject returns the object of a version, or nil if it is the
+// current version, which is scanned with the objects of the bucket.
+func noncurrentObject(v *s3.ObjectVersion) *s3.Object {
+	if v == nil || v.Key == nil || v.Size == nil || aws.BoolValue(v.IsLatest) {
+		return nil
+	}
+	return &s3.Object{
+		Key:          v.Key,
+		Size:         v.Size,
+		StorageClass: v.StorageClass,
+		Owner:        v.Owner,
+		LastModified: v.LastModified,
+		ETag:         v.ETag,
+	}
+}
 
-			nErr, ok := errorCount.Load(prefix)
-			if !ok {
-				nErr = 0
-			}
-			if nErr.(int) > 3 {
-				s.log.V(2).Info("Skipped due to excessive errors", "object", *obj.Key)
-				return nil
-			}
+// skipObject reports whether the object is skipped, and reports why.
+func (s *Source) skipObject(ctx context.Context, obj *s3.Object) bool {
+	if obj == nil {
+		return true
+	}
 
-			// files break with spaces, must replace with +
-			// objKey := strings.ReplaceAll(*obj.Key, " ", "+")
-			ctx, cancel := context.WithTimeout(ctx, time.Second*5)
-			defer cancel()
-			res, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
-				Bucket: &bucket,
-				Key:    obj.Key,
-			})
-			if err != nil {
-				if !strings.Contains(err.Error(), "AccessDenied") {
-					s.log.Error(err, "could not get S3 object", "object", *obj.Key)
-				}
+	// skip GLACIER and GLACIER_IR objects
+	if obj.StorageClass == nil || strings.Contains(*obj.StorageClass, "GLACIER") {
+		s.log.V(5).Info("Skipping object in storage class", "storage_class", aws.StringValue(obj.StorageClass), "object", *obj.Key)
+		sources.ReportSkip(ctx, *obj.Key, sources.SkipReasonUnsupported)
+		return true
+	}
 
-				nErr, ok := errorCount.Load(prefix)
-				if !ok {
-					nErr = 0
-				}
-				if nErr.(int) > 3 {
-					s.log.V(3).Info("Skipped due to excessive errors", "object", *obj.Key)
-					return nil
-				}
-				nErr = nErr.(int) + 1
-				errorCount.Store(prefix, nErr)
-				// too many consective errors on this page
-				if nErr.(int) > 3 {
-					s.log.V(2).Info("Too many consecutive errors, excluding prefix", "prefix", prefix)
-				}
-				return nil
-			}
+	// ignore large files
+	if *obj.Size > s.maxObjectSize {
+		s.log.V(3).Info("Skipping %d byte file (over maxObjectSize limit)", "object", *obj.Key)
+		sources.ReportSkip(ctx, *obj.Key, sources.SkipReasonSize)
+		return true
+	}
 
-			defer res.Body.Close()
-			reader, err := diskbufferreader.New(res.Body)
-			if err != nil {
-				s.log.Error(err, "Could not create reader.")
-				return nil
-			}
-			defer reader.Close()
+	// file empty file
+	if *obj.Size == 0 {
+		s.log.V(5).Info("Skipping 0 byte file", "object", *obj.Key)
+		sources.ReportSkip(ctx, *obj.Key, sources.SkipReasonEmpty)
+		return true
+	}
 
-			email := "Unknown"
-			if obj.Owner != nil {
-				email = *obj.Owner.DisplayName
-			}
-			modified := obj.LastModified.String()
-			chunkSkel := &sources.Chunk{
-				SourceType: s.Type(),
-				SourceName: s.name,
-				SourceID:   s.SourceID(),
-				SourceMetadata: &source_metadatapb.MetaData{
-					Data: &source_metadatapb.MetaData_S3{
-						S3: &source_metadatapb.S3{
-							Bucket:    bucket,
-							File:      sanitizer.UTF8(*obj.Key),
-							Link:      sanitizer.UTF8(makeS3Link(bucket, *client.Config.Region, *obj.Key)),
-							Email:     sanitizer.UTF8(email),
-							Timestamp: sanitizer.UTF8(modified),
-						},
-					},
+	// skip incompatible extensions
+	if common.SkipFile(*obj.Key) {
+		s.log.V(5).Info("Skipping file with incompatible extension", "object", *obj.Key)
+		sources.ReportSkip(ctx, *obj.Key, sources.SkipReasonUnsupported)
+		return true
+	}
+
+	return false
+}
+
+// scanObject emits chunks onto the given channel from an object, or from a
+// version of it if version is not nil.
+func (s *Source) scanObject(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, obj *s3.Object, version *objectVersion, errorCount *sync.Map, pageNumber int, objectCount *uint64) error {
+	if strings.HasSuffix(*obj.Key, "/") {
+		s.log.V(5).Info("Skipping directory", "object", *obj.Key)
+		return nil
+	}
+
+	path := strings.Split(*obj.Key, "/")
+	prefix := strings.Join(path[:len(path)-1], "/")
+
+	nErr, ok := errorCount.Load(prefix)
+	if !ok {
+		nErr = 0
+	}
+	if nErr.(int) > 3 {
+		s.log.V(2).Info("Skipped due to excessive errors", "object", *obj.Key)
+		sources.ReportSkip(ctx, *obj.Key, sources.SkipReasonError)
+		return nil
+	}
+
+	// files break with spaces, must replace with +
+	// objKey := strings.ReplaceAll(*obj.Key, " ", "+")
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+	input := &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    obj.Key,
+	}
+	if version != nil {
+		input.VersionId = aws.String(version.id)
+	}
+	res, err := client.GetObjectWithContext(ctx, input, s.requestOptions()...)
+	if err != nil {
+		if !strings.Contains(err.Error(), "AccessDenied") {
+			s.log.Error(err, "could not get S3 object", "object", *obj.Key)
+		}
+		sources.ReportSkip(ctx, *obj.Key, sources.SkipReasonError)
+
+		nErr, ok := errorCount.Load(prefix)
+		if !ok {
+			nErr = 0
+		}
+		if nErr.(int) > 3 {
+			s.log.V(3).Info("Skipped due to excessive errors", "object", *obj.Key)
+			return nil
+		}
+		nErr = nErr.(int) + 1
+		errorCount.Store(prefix, nErr)
+		// too many consective errors on this page
+		if nErr.(int) > 3 {
+			s.log.V(2).Info("Too many consecutive errors, excluding prefix", "prefix", prefix)
+		}
+		return nil
+	}
+
+	defer res.Body.Close()
+	reader, err := diskbufferreader.New(res.Body)
+	if err != nil {
+		s.log.Error(err, "Could not create reader.")
+		return nil
+	}
+	defer reader.Close()
+
+	chunkSkel := s.chunkSkeleton(client, bucket, obj, version)
+	if handlers.HandleFile(ctx, reader, chunkSkel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		atomic.AddUint64(objectCount, 1)
+		s.log.V(5).Info("S3 object scanned.", "object_count", objectCount, "page_number", pageNumber)
+		return nil
+	}
+
+	if err := reader.Reset(); err != nil {
+		s.log.Error(err, "Error resetting reader to start.")
+	}
+	reader.Stop()
+
+	chunkReader := sources.NewChunkReader(s.archiveOptions.ChunkOptions()...)
+	chunkResChan := chunkReader(ctx, reader)
+	for data := range chunkResChan {
+		if err := data.Error(); err != nil {
+			s.log.Error(err, "error reading chunk.")
+			continue
+		}
+		chunk := *chunkSkel
+		chunk.Data = data.Bytes()
+		chunk.Offset, chunk.LineStart, chunk.LineOffset = data.Offset(), data.LineStart(), data.LineOffset()
+		if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
+			return err
+		}
+	}
+
+	atomic.AddUint64(objectCount, 1)
+	s.log.V(5).Info("S3 object scanned.", "object_count", objectCount, "page_number", pageNumber)
+	nErr, ok = errorCount.Load(prefix)
+	if !ok {
+		nErr = 0
+	}
+	if nErr.(int) > 0 {
+		errorCount.Store(prefix, 0)
+	}
+
+	return nil
+}
+
+// chunkSkeleton returns the skeleton of the chunks of an object handled, or
+// of a version of it if version is not nil.
+func (s *Source) chunkSkeleton(client *s3.S3, bucket string, obj *s3.Object, version *objectVersion) *sources.Chunk {
+	email := "Unknown"
+	if obj.Owner != nil {
+		email = *obj.Owner.DisplayName
+	}
+	modified := obj.LastModified.String()
+	link := makeS3Link(bucket, *client.Config.Region, *obj.Key)
+	var versionID string
+	var deleted bool
+	if version != nil {
+		versionID, deleted = version.id, version.deleted
+		link += "?versionId=" + url.QueryEscape(versionID)
+	}
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_S3{
+				S3: &source_metadatapb.S3{
+					Bucket:    bucket,
+					File:      sanitizer.UTF8(*obj.Key),
+					Link:      sanitizer.UTF8(link),
+					Email:     sanitizer.UTF8(email),
+					Timestamp: sanitizer.UTF8(modified),
+					Line:      1,
+					VersionId: sanitizer.UTF8(versionID),
+					Deleted:   deleted,
 				},
-				Verify: s.verify,
-			}
-			if handlers.HandleFile(ctx, reader, chunkSkel, chunksChan) {
-				atomic.AddUint64(objectCount, 1)
-				s.log.V(5).Info("S3 object scanned.", "object_count", objectCount, "page_number", pageNumber)
-				return nil
-			}
+			},
+		},
+		Verify: s.verify,
+	}
+}
 
-			if err := reader.Reset(); err != nil {
-				s.log.Error(err, "Error resetting reader to start.")
-			}
-			reader.Stop()
-
-			chunk := *chunkSkel
-			chunkReader := sources.NewChunkReader()
-			chunkResChan := chunkReader(ctx, reader)
-			for data := range chunkResChan {
-				chunk.Data = data.Bytes()
-				if err := data.Error(); err != nil {
-					s.log.Error(err, "error reading chunk.")
-					continue
-				}
-				if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
-					return err
-				}
-			}
+// splitObjects are the objects of a bucket named as parts of split files,
+// like backup.zip.001 or app.part1.rar, in the order they are listed.
+type splitObjects struct {
+	keys    []string
+	objects map[string]*s3.Object
+}
 
-			atomic.AddUint64(objectCount, 1)
-			s.log.V(5).Info("S3 object scanned.", "object_count", objectCount, "page_number", pageNumber)
-			nErr, ok = errorCount.Load(prefix)
-			if !ok {
-				nErr = 0
-			}
-			if nErr.(int) > 0 {
-				errorCount.Store(prefix, 0)
-			}
+func newSplitObjects() *splitObjects {
+	return &splitObjects{objects: make(map[string]*s3.Object)}
+}
+
+// add adds an object if it is named as a part of a split file.
+func (o *splitObjects) add(obj *s3.Object) bool {
+	if _, _, ok := handlers.SplitPart(path.Base(*obj.Key)); !ok {
+		return false
+	}
+	o.keys = append(o.keys, *obj.Key)
+	o.objects[*obj.Key] = obj
+	return true
+}
 
+// splitChunker emits chunks onto the given channel from the parts of split
+// files, the parts of each file together, like the volumes of a multi-volume
+// archive. Objects that are not parts of a file with other parts, or whose
+// parts are not handled together, are scanned one by one.
+func (s *Source) splitChunker(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, splits *splitObjects, errorCount *sync.Map, pageNumber int, objectCount *uint64) {
+	var single []*s3.Object
+	var mu sync.Mutex
+	grouped := make(map[string]bool)
+	for _, keys := range handlers.SplitFiles(splits.keys) {
+		parts := make([]*s3.Object, len(keys))
+		for i, key := range keys {
+			parts[i] = splits.objects[key]
+			grouped[key] = true
+		}
+		s.jobPool.Go(func() error {
+			defer common.RecoverWithExit(ctx)
+			if s.scanSplitObject(ctx, client, chunksChan, bucket, parts) {
+				atomic.AddUint64(objectCount, uint64(len(parts)))
+				return nil
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			single = append(single, parts...)
 			return nil
 		})
 	}
-
 	_ = s.jobPool.Wait()
+	for _, key := range splits.keys {
+		if !grouped[key] {
+			single = append(single, splits.objects[key])
+		}
+	}
+	s.pageChunker(ctx, client, chunksChan, bucket, &s3.ListObjectsV2Output{Contents: single}, errorCount, pageNumber, objectCount, nil)
+}
+
+// scanSplitObject scans the parts of a split file as the file they are parts
+// of, under the key of its first part, and reports whether it was handled.
+func (s *Source) scanSplitObject(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, parts []*s3.Object) bool {
+	s.log.V(3).Info("Scanning split object", "object", *parts[0].Key, "parts", len(parts))
+	readers := make([]io.Reader, len(parts))
+	for i, obj := range parts {
+		r := &objectReader{ctx: ctx, client: client, bucket: bucket, key: *obj.Key, opts: s.requestOptions()}
+		defer r.Close()
+		readers[i] = r
+	}
+	return handlers.HandleSplitFile(ctx, readers, s.chunkSkeleton(client, bucket, parts[0], nil), chunksChan, handlers.WithArchiveOptions(s.archiveOptions))
+}
+
+// objectReader reads an object, which it gets when it is first read, so the
+// parts of a split file are downloaded one at a time.
+type objectReader struct {
+	ctx         context.Context
+	client      *s3.S3
+	bucket, key string
+	opts        []request.Option
+	body        io.ReadCloser
+}
+
+func (r *objectReader) Read(p []byte) (int, error) {
+	if r.body == nil {
+		res, err := r.client.GetObjectWithContext(r.ctx, &s3.GetObjectInput{Bucket: &r.bucket, Key: &r.key}, r.opts...)
+		if err != nil {
+			sources.ReportSkip(r.ctx, r.key, sources.SkipReasonError)
+			return 0, fmt.Errorf("could not get S3 object %s: %w", r.key, err)
+		}
+		r.body = res.Body
+	}
+	return r.body.Read(p)
+}
+
+func (r *objectReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
 }
 
 // S3 links currently have the general format of:
@@ -3,18 +3,25 @@ package s3
 import (
 	"encoding/base64"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/kylelemons/godebug/pretty"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
@@ -95,3 +102,268 @@ func TestSource_Chunks(t *testing.T) {
 		})
 	}
 }
+
+// testVersions are the versions of the objects of testServer, newest first.
+// Versions without content are delete markers.
+var testVersions = map[string][]struct{ id, content string }{
+	"config.env":  {{"v3", "SECRET=current"}, {"v1", "SECRET=old"}},
+	"deleted.txt": {{"v4", ""}, {"v2", "password=deleted"}},
+}
+
+// testServer is an S3 API with a versioned bucket.
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	const modified = "2024-01-02T03:04:05.000Z"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("X-Amz-Bucket-Region", "us-east-1")
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/bkt/")
+		if r.URL.Path == "/bkt" || r.URL.Path == "/bkt/" {
+			keys := make([]string, 0, len(testVersions))
+			for key := range testVersions {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			var body strings.Builder
+			if _, ok := r.URL.Query()["versions"]; ok {
+				body.WriteString(`<ListVersionsResult><Name>bkt</Name><IsTruncated>false</IsTruncated>`)
+				for _, key := range keys {
+					for i, v := range testVersions[key] {
+						if v.content == "" {
+							fmt.Fprintf(&body, `<DeleteMarker><Key>%s</Key><VersionId>%s</VersionId><IsLatest>%t</IsLatest><LastModified>%s</LastModified></DeleteMarker>`, key, v.id, i == 0, modified)
+							continue
+						}
+						fmt.Fprintf(&body, `<Version><Key>%s</Key><VersionId>%s</VersionId><IsLatest>%t</IsLatest><LastModified>%s</LastModified><Size>%d</Size><StorageClass>STANDARD</StorageClass></Version>`, key, v.id, i == 0, modified, len(v.content))
+					}
+				}
+				body.WriteString(`</ListVersionsResult>`)
+			} else {
+				body.WriteString(`<ListBucketResult><Name>bkt</Name><IsTruncated>false</IsTruncated>`)
+				for _, key := range keys {
+					if v := testVersions[key][0]; v.content != "" {
+						fmt.Fprintf(&body, `<Contents><Key>%s</Key><LastModified>%s</LastModified><Size>%d</Size><StorageClass>STANDARD</StorageClass></Contents>`, key, modified, len(v.content))
+					}
+				}
+				body.WriteString(`</ListBucketResult>`)
+			}
+			_, _ = w.Write([]byte(body.String()))
+			return
+		}
+
+		id := r.URL.Query().Get("versionId")
+		for i, v := range testVersions[key] {
+			if v.content != "" && (v.id == id || (id == "" && i == 0)) {
+				_, _ = w.Write([]byte(v.content))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code></Error>`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSource_ChunksVersions(t *testing.T) {
+	server := testServer(t)
+	chunks := func(includeVersions bool) map[string]*source_metadatapb.S3 {
+		conn, err := anypb.New(&sourcespb.S3{
+			Credential:      &sourcespb.S3_Unauthenticated{},
+			Buckets:         []string{"bkt"},
+			IncludeVersions: includeVersions,
+		})
+		require.NoError(t, err)
+		s := Source{}
+		require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 2))
+		s.endpoint = server.URL
+
+		chunksCh := make(chan *sources.Chunk, 16)
+		require.NoError(t, s.Chunks(context.Background(), chunksCh))
+		close(chunksCh)
+		got := make(map[string]*source_metadatapb.S3)
+		for chunk := range chunksCh {
+			got[string(chunk.Data)] = chunk.SourceMetadata.GetS3()
+		}
+		return got
+	}
+
+	got := chunks(false)
+	assert.Len(t, got, 1)
+	assert.Equal(t, "", got["SECRET=current"].GetVersionId())
+
+	// The noncurrent versions are scanned too, with those of deleted
+	// objects marked as such.
+	got = chunks(true)
+	assert.Len(t, got, 3)
+	assert.Equal(t, "", got["SECRET=current"].GetVersionId())
+	assert.Equal(t, "v1", got["SECRET=old"].GetVersionId())
+	assert.False(t, got["SECRET=old"].GetDeleted())
+	assert.Equal(t, "https://bkt.s3.amazonaws.com/config.env?versionId=v1", got["SECRET=old"].GetLink())
+	assert.Equal(t, "v2", got["password=deleted"].GetVersionId())
+	assert.True(t, got["password=deleted"].GetDeleted())
+}
+
+func TestSource_EnumerateTargetsVersions(t *testing.T) {
+	server := testServer(t)
+	conn, err := anypb.New(&sourcespb.S3{
+		Credential:      &sourcespb.S3_Unauthenticated{},
+		Buckets:         []string{"bkt"},
+		IncludeVersions: true,
+	})
+	require.NoError(t, err)
+	s := Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 2))
+	s.endpoint = server.URL
+
+	var targets []sources.Target
+	require.NoError(t, s.EnumerateTargets(context.Background(), func(target sources.Target) {
+		targets = append(targets, target)
+	}))
+	assert.Equal(t, []sources.Target{{Name: "bkt", Objects: 3, Bytes: int64(len("SECRET=current") + len("SECRET=old") + len("password=deleted"))}}, targets)
+}
+
+// testAccounts are the buckets of the credentials of the accounts of
+// accountServer, by access key. bkt-b is a requester-pays bucket.
+var testAccounts = map[string][]string{
+	"AKIDA": {"bkt-a", "shared"},
+	"AKIDB": {"bkt-b", "shared"},
+}
+
+// testRoles are the roles of accountServer, which can be assumed with the
+// access key of caller and external ID, and whose access key is key.
+var testRoles = map[string]struct{ caller, externalID, key string }{
+	"arn:aws:iam::111111111111:role/hub":     {caller: "AKIDBASE", key: "AKIDHUB"},
+	"arn:aws:iam::222222222222:role/scanner": {caller: "AKIDHUB", externalID: "ext-a", key: "AKIDA"},
+	"arn:aws:iam::333333333333:role/scanner": {caller: "AKIDHUB", key: "AKIDB"},
+}
+
+// accountServer is an STS and S3 API of the accounts of testAccounts. Its
+// buckets hold secret.txt, and the requests of each object are counted.
+func accountServer(t *testing.T, gets *sync.Map) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, credential, _ := strings.Cut(r.Header.Get("Authorization"), "Credential=")
+		key, _, _ := strings.Cut(credential, "/")
+		denied := func() {
+			w.WriteHeader(http.StatusForbidden)
+			if r.Method == http.MethodPost {
+				_, _ = w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>denied</Message></Error></ErrorResponse>`))
+				return
+			}
+			_, _ = w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>denied</Message></Error>`))
+		}
+
+		switch {
+		case r.Method == http.MethodPost:
+			_ = r.ParseForm()
+			role, ok := testRoles[r.Form.Get("RoleArn")]
+			if r.Form.Get("Action") != "AssumeRole" || !ok || role.caller != key || role.externalID != r.Form.Get("ExternalId") {
+				denied()
+				return
+			}
+			fmt.Fprintf(w, `<AssumeRoleResponse><AssumeRoleResult><Credentials><AccessKeyId>%s</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken><Expiration>2099-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`, role.key)
+		case r.Method == http.MethodHead:
+			w.Header().Set("X-Amz-Bucket-Region", "us-east-1")
+		case r.URL.Path == "/":
+			var body strings.Builder
+			body.WriteString(`<ListAllMyBucketsResult><Buckets>`)
+			for _, bkt := range testAccounts[key] {
+				fmt.Fprintf(&body, `<Bucket><Name>%s</Name></Bucket>`, bkt)
+			}
+			body.WriteString(`</Buckets></ListAllMyBucketsResult>`)
+			_, _ = w.Write([]byte(body.String()))
+		default:
+			bkt, obj, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+			var owned bool
+			for _, b := range testAccounts[key] {
+				owned = owned || b == bkt
+			}
+			if !owned || (bkt == "bkt-b" && r.Header.Get("X-Amz-Request-Payer") != "requester") {
+				denied()
+				return
+			}
+			content := bkt + " password=hunter2"
+			if obj == "" {
+				fmt.Fprintf(w, `<ListBucketResult><Name>%s</Name><IsTruncated>false</IsTruncated><Contents><Key>secret.txt</Key><LastModified>2024-01-02T03:04:05.000Z</LastModified><Size>%d</Size><StorageClass>STANDARD</StorageClass></Contents></ListBucketResult>`, bkt, len(content))
+				return
+			}
+			n, _ := gets.LoadOrStore(bkt, new(int32))
+			atomic.AddInt32(n.(*int32), 1)
+			_, _ = w.Write([]byte(content))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSource_ChunksRoleChains(t *testing.T) {
+	hub := &sourcespb.AWSRole{RoleArn: "arn:aws:iam::111111111111:role/hub"}
+	chains := []*sourcespb.AWSRoleChain{
+		{Roles: []*sourcespb.AWSRole{hub, {RoleArn: "arn:aws:iam::222222222222:role/scanner", ExternalId: "ext-a"}}},
+		{Roles: []*sourcespb.AWSRole{hub, {RoleArn: "arn:aws:iam::333333333333:role/scanner"}}},
+		// The external ID is wrong, so the buckets of the chain are skipped.
+		{Roles: []*sourcespb.AWSRole{hub, {RoleArn: "arn:aws:iam::222222222222:role/scanner", ExternalId: "wrong"}}},
+	}
+
+	scan := func(requesterPays bool) ([]string, *sync.Map, error) {
+		var gets sync.Map
+		server := accountServer(t, &gets)
+		conn, err := anypb.New(&sourcespb.S3{
+			Credential:    &sourcespb.S3_AccessKey{AccessKey: &credentialspb.KeySecret{Key: "AKIDBASE", Secret: "secret"}},
+			RequesterPays: requesterPays,
+			RoleChains:    chains,
+		})
+		require.NoError(t, err)
+		s := Source{}
+		require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 2))
+		s.endpoint = server.URL
+
+		chunksCh := make(chan *sources.Chunk, 16)
+		err = s.Chunks(context.Background(), chunksCh)
+		close(chunksCh)
+		var got []string
+		for chunk := range chunksCh {
+			got = append(got, string(chunk.Data))
+		}
+		sort.Strings(got)
+		return got, &gets, err
+	}
+
+	// The bucket of both accounts is scanned once.
+	got, gets, err := scan(true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bkt-a password=hunter2", "bkt-b password=hunter2", "shared password=hunter2"}, got)
+	n, _ := gets.Load("shared")
+	assert.Equal(t, int32(1), *n.(*int32))
+
+	// The requester-pays bucket cannot be listed without accepting its
+	// charges.
+	_, _, err = scan(false)
+	assert.ErrorContains(t, err, "bucket bkt-b: AccessDenied")
+}
+
+func TestSource_InitRoleChains(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.S3{
+		"unauthenticated": {
+			Credential: &sourcespb.S3_Unauthenticated{},
+			RoleChains: []*sourcespb.AWSRoleChain{{Roles: []*sourcespb.AWSRole{{RoleArn: "arn:aws:iam::111111111111:role/hub"}}}},
+		},
+		"no roles": {
+			Credential: &sourcespb.S3_CloudEnvironment{},
+			RoleChains: []*sourcespb.AWSRoleChain{{}},
+		},
+		"no ARN": {
+			Credential: &sourcespb.S3_CloudEnvironment{},
+			RoleChains: []*sourcespb.AWSRoleChain{{Roles: []*sourcespb.AWSRole{{ExternalId: "ext"}}}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package servicenow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// pageSize is the number of records requested at a time.
+	pageSize = 100
+	// maxAttachmentSize is the size of the largest attachments scanned.
+	maxAttachmentSize = 250 * 1024 * 1024
+	// timeLayout is the layout of the times of the Table API, in UTC.
+	timeLayout = "2006-01-02 15:04:05"
+
+	locationRecord     = "record"
+	locationJournal    = "journal"
+	locationAttachment = "attachment"
+)
+
+// defaultTables are the tables scanned when the connection has none.
+var defaultTables = []string{"incident", "change_request"}
+
+// tableName matches the names of tables, which are part of the paths of
+// requests.
+var tableName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Source scans the records of the tables of a ServiceNow instance, their
+// journal entries, which are their comments and work notes, and their
+// attachments.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	client   *http.Client
+	endpoint string
+	// authorize sets the credentials of requests.
+	authorize       func(*http.Request)
+	tables          []string
+	query           string
+	skipAttachments bool
+	// archiveOptions configures how the archives of attachments are
+	// extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_SERVICENOW
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of attachments are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized ServiceNow source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+	s.client = common.RetryableHttpClientTimeout(120)
+
+	var conn sourcespb.ServiceNow
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	if conn.GetEndpoint() == "" {
+		return errors.New("the endpoint of the instance is required")
+	}
+	u, err := url.Parse(conn.GetEndpoint())
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("invalid endpoint %q", conn.GetEndpoint())
+	}
+	s.endpoint = strings.TrimSuffix(conn.GetEndpoint(), "/")
+
+	switch cred := conn.GetCredential().(type) {
+	case *sourcespb.ServiceNow_BasicAuth:
+		s.authorize = func(req *http.Request) {
+			req.SetBasicAuth(cred.BasicAuth.GetUsername(), cred.BasicAuth.GetPassword())
+		}
+	case *sourcespb.ServiceNow_OauthToken:
+		s.authorize = func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+cred.OauthToken) }
+	default:
+		return errors.New("credentials are required")
+	}
+
+	s.tables = conn.GetTables()
+	if len(s.tables) == 0 {
+		s.tables = defaultTables
+	}
+	for _, table := range s.tables {
+		if !tableName.MatchString(table) {
+			return fmt.Errorf("invalid table %q", table)
+		}
+	}
+	s.query = buildQuery(&conn)
+	s.skipAttachments = conn.GetSkipAttachments()
+	return nil
+}
+
+// buildQuery returns the encoded query of the records to scan: those of
+// the query of the connection updated since its updated_since, the least
+// recently updated first.
+func buildQuery(conn *sourcespb.ServiceNow) string {
+	var clauses []string
+	if query := strings.Trim(strings.TrimSpace(conn.GetQuery()), "^"); query != "" {
+		clauses = append(clauses, query)
+	}
+	if since := conn.GetUpdatedSince(); since != nil {
+		clauses = append(clauses, "sys_updated_on>="+since.AsTime().UTC().Format(timeLayout))
+	}
+	clauses = append(clauses, "ORDERBYsys_updated_on")
+	return strings.Join(clauses, "^")
+}
+
+// record is a record of a table, whose fields are strings as the Table API
+// returns them without display values.
+type record map[string]any
+
+func (r record) field(name string) string {
+	value, _ := r[name].(string)
+	return value
+}
+
+type journalEntry struct {
+	SysID        string `json:"sys_id"`
+	Element      string `json:"element"`
+	Value        string `json:"value"`
+	SysCreatedBy string `json:"sys_created_by"`
+	SysCreatedOn string `json:"sys_created_on"`
+}
+
+type attachment struct {
+	SysID        string `json:"sys_id"`
+	FileName     string `json:"file_name"`
+	SizeBytes    string `json:"size_bytes"`
+	SysCreatedBy string `json:"sys_created_by"`
+	SysCreatedOn string `json:"sys_created_on"`
+}
+
+// Chunks emits the fields, journal entries and attachments of each record
+// as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	scanErrs := sources.NewScanErrors()
+	for i, table := range s.tables {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(s.tables), fmt.Sprintf("Table: %s", table), "")
+		if err := s.scanTable(ctx, table, scanErrs, chunksChan); err != nil {
+			_ = s.jobPool.Wait()
+			return fmt.Errorf("error listing records of table %s: %w", table, err)
+		}
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// scanTable lists the records of a table that the query selects, and scans
+// each of them in a job.
+func (s *Source) scanTable(ctx context.Context, table string, scanErrs *sources.ScanErrors, chunksChan chan *sources.Chunk) error {
+	for offset := 0; ; {
+		query := url.Values{
+			"sysparm_query":                  {s.query},
+			"sysparm_limit":                  {strconv.Itoa(pageSize)},
+			"sysparm_offset":                 {strconv.Itoa(offset)},
+			"sysparm_exclude_reference_link": {"true"},
+		}
+		var page struct {
+			Result []record `json:"result"`
+		}
+		if err := s.get(ctx, "/api/now/table/"+table+"?"+query.Encode(), &page); err != nil {
+			return err
+		}
+		for _, r := range page.Result {
+			r := r
+			s.jobPool.Go(func() error {
+				if err := s.scanRecord(ctx, table, r, chunksChan); err != nil {
+					scanErrs.Add(fmt.Errorf("error scanning record %s of table %s: %w", r.field("sys_id"), table, err))
+				}
+				return nil
+			})
+		}
+		offset += len(page.Result)
+		if len(page.Result) < pageSize || common.IsDone(ctx) {
+			return nil
+		}
+	}
+}
+
+func (s *Source) scanRecord(ctx context.Context, table string, r record, chunksChan chan *sources.Chunk) error {
+	sysID := r.field("sys_id")
+	skel := s.chunkSkeleton(table, r, locationRecord, r.field("sys_created_by"), r.field("sys_updated_on"))
+	if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(formatRecord(r)), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+		return err
+	}
+
+	var journal struct {
+		Result []journalEntry `json:"result"`
+	}
+	query := url.Values{
+		"sysparm_query":  {"element_id=" + sysID + "^ORDERBYsys_created_on"},
+		"sysparm_fields": {"sys_id,element,value,sys_created_by,sys_created_on"},
+	}
+	if err := s.get(ctx, "/api/now/table/sys_journal_field?"+query.Encode(), &journal); err != nil {
+		return fmt.Errorf("error listing journal entries: %w", err)
+	}
+	for _, entry := range journal.Result {
+		location := locationJournal + "/" + entry.Element + "/" + entry.SysID
+		skel := s.chunkSkeleton(table, r, location, entry.SysCreatedBy, entry.SysCreatedOn)
+		if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(entry.Value), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+			return err
+		}
+	}
+
+	if s.skipAttachments {
+		return nil
+	}
+	var attachments struct {
+		Result []attachment `json:"result"`
+	}
+	query = url.Values{"sysparm_query": {"table_name=" + table + "^table_sys_id=" + sysID}}
+	if err := s.get(ctx, "/api/now/attachment?"+query.Encode(), &attachments); err != nil {
+		return fmt.Errorf("error listing attachments: %w", err)
+	}
+	for _, a := range attachments.Result {
+		location := locationAttachment + "/" + a.FileName
+		if size, _ := strconv.ParseInt(a.SizeBytes, 10, 64); size > maxAttachmentSize {
+			sources.ReportSkipBytes(ctx, table+"/"+sysID+"/"+location, sources.SkipReasonSize, size)
+			continue
+		}
+		skel := s.chunkSkeleton(table, r, location, a.SysCreatedBy, a.SysCreatedOn)
+		if err := s.scanAttachment(ctx, skel, a, chunksChan); err != nil {
+			return fmt.Errorf("error scanning attachment %s: %w", a.FileName, err)
+		}
+	}
+	return nil
+}
+
+// formatRecord returns the fields of a record that have a value, one per
+// line, sorted by name.
+func formatRecord(r record) string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		if r.field(name) != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ": " + r.field(name) + "\n")
+	}
+	return b.String()
+}
+
+// scanAttachment scans an attachment, through the handlers of archives.
+func (s *Source) scanAttachment(ctx context.Context, skel *sources.Chunk, a attachment, chunksChan chan *sources.Chunk) error {
+	res, err := s.do(ctx, s.endpoint+"/api/now/attachment/"+url.PathEscape(a.SysID)+"/file")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	reader, err := diskbufferreader.New(io.LimitReader(res.Body, maxAttachmentSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+	return sources.ChunkReaderTo(ctx, skel, reader, chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+func (s *Source) chunkSkeleton(table string, r record, location, author, timestamp string) *sources.Chunk {
+	if t, err := time.Parse(timeLayout, timestamp); err == nil {
+		timestamp = t.Format(time.RFC3339)
+	}
+	sysID := r.field("sys_id")
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Servicenow{
+				Servicenow: &source_metadatapb.ServiceNow{
+					Table:     table,
+					SysId:     sysID,
+					Number:    sanitizer.UTF8(r.field("number")),
+					Location:  sanitizer.UTF8(location),
+					Author:    sanitizer.UTF8(author),
+					Link:      s.endpoint + "/nav_to.do?uri=" + url.QueryEscape(table+".do?sys_id="+sysID),
+					Timestamp: timestamp,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// get decodes the JSON response of a request of the REST API.
+func (s *Source) get(ctx context.Context, path string, v any) error {
+	res, err := s.do(ctx, s.endpoint+path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// do sends an authorized GET request, and returns its response if it
+// succeeded.
+func (s *Source) do(ctx context.Context, reqURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.authorize(req)
+	req.Header.Set("Accept", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+		}
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res, nil
+}
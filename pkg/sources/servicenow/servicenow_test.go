@@ -0,0 +1,148 @@
+package servicenow
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// testInstance is a ServiceNow instance with the user "admin" and the
+// password "password", which records the queries of the tables.
+type testInstance struct {
+	mu      sync.Mutex
+	queries map[string][]string
+}
+
+func (i *testInstance) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	i.queries = make(map[string][]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "admin" || pass != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		query := r.URL.Query().Get("sysparm_query")
+		switch r.URL.Path {
+		case "/api/now/table/incident":
+			i.mu.Lock()
+			i.queries["incident"] = append(i.queries["incident"], query)
+			i.mu.Unlock()
+			fmt.Fprint(w, `{"result":[{"sys_id":"inc1","number":"INC0001","short_description":"VPN is down","description":"password=hunter2","assigned_to":"","sys_created_by":"ada","sys_updated_on":"2024-01-02 03:04:05"}]}`)
+		case "/api/now/table/change_request":
+			i.mu.Lock()
+			i.queries["change_request"] = append(i.queries["change_request"], query)
+			i.mu.Unlock()
+			fmt.Fprint(w, `{"result":[]}`)
+		case "/api/now/table/sys_journal_field":
+			if query != "element_id=inc1^ORDERBYsys_created_on" {
+				fmt.Fprint(w, `{"result":[]}`)
+				return
+			}
+			fmt.Fprint(w, `{"result":[{"sys_id":"j1","element":"work_notes","value":"Use token=abc123","sys_created_by":"bob","sys_created_on":"2024-01-03 00:00:00"}]}`)
+		case "/api/now/attachment":
+			if query != "table_name=incident^table_sys_id=inc1" {
+				fmt.Fprint(w, `{"result":[]}`)
+				return
+			}
+			fmt.Fprint(w, `{"result":[{"sys_id":"a1","file_name":"config.env","size_bytes":"25","sys_created_by":"ada","sys_created_on":"2024-01-02 03:04:05"}]}`)
+		case "/api/now/attachment/a1/file":
+			fmt.Fprint(w, "AWS_SECRET_ACCESS_KEY=xyz")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func basicAuth() *sourcespb.ServiceNow_BasicAuth {
+	return &sourcespb.ServiceNow_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: "admin", Password: "password"}}
+}
+
+func TestSource_Chunks(t *testing.T) {
+	instance := &testInstance{}
+	server := instance.server(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.ServiceNow{Endpoint: server.URL + "/", Credential: basicAuth()}, 4)
+
+	got := sources.RequireChunks(t, s)
+	link := server.URL + "/nav_to.do?uri=" + url.QueryEscape("incident.do?sys_id=inc1")
+	record := sources.MetadataWith(got, "description: password=hunter2\n").GetServicenow()
+	assert.Equal(t, &source_metadatapb.ServiceNow{
+		Table:     "incident",
+		SysId:     "inc1",
+		Number:    "INC0001",
+		Location:  "record",
+		Author:    "ada",
+		Link:      link,
+		Timestamp: "2024-01-02T03:04:05Z",
+	}, record)
+	for _, chunk := range got {
+		assert.NotContains(t, string(chunk.Data), "assigned_to")
+	}
+	note := sources.MetadataWith(got, "Use token=abc123").GetServicenow()
+	require.NotNil(t, note)
+	assert.Equal(t, "journal/work_notes/j1", note.GetLocation())
+	assert.Equal(t, "bob", note.GetAuthor())
+	assert.Equal(t, "attachment/config.env", sources.MetadataWith(got, "AWS_SECRET_ACCESS_KEY=xyz").GetServicenow().GetLocation())
+	assert.Equal(t, []string{"ORDERBYsys_updated_on"}, instance.queries["change_request"])
+}
+
+func TestSource_ChunksQuery(t *testing.T) {
+	instance := &testInstance{}
+	server := instance.server(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.ServiceNow{
+		Endpoint:        server.URL,
+		Credential:      basicAuth(),
+		Tables:          []string{"incident"},
+		Query:           "active=true^priority=1^",
+		UpdatedSince:    timestamppb.New(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)),
+		SkipAttachments: true,
+	}, 4)
+
+	got := sources.RequireChunks(t, s)
+	assert.Equal(t, []string{"active=true^priority=1^sys_updated_on>=2024-01-01 12:00:00^ORDERBYsys_updated_on"}, instance.queries["incident"])
+	assert.Nil(t, instance.queries["change_request"])
+	assert.Nil(t, sources.MetadataWith(got, "AWS_SECRET_ACCESS_KEY=xyz").GetServicenow())
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := (&testInstance{}).server(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.ServiceNow{
+		Endpoint:   server.URL,
+		Credential: &sourcespb.ServiceNow_OauthToken{OauthToken: "wrong"},
+	}, 4)
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid credentials")
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.ServiceNow{
+		"no endpoint":      {Credential: basicAuth()},
+		"invalid endpoint": {Endpoint: "acme.service-now.com", Credential: basicAuth()},
+		"no credentials":   {Endpoint: "https://acme.service-now.com"},
+		"invalid table":    {Endpoint: "https://acme.service-now.com", Credential: basicAuth(), Tables: []string{"incident/1"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
@@ -0,0 +1,753 @@
+package sharepoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// defaultEndpoint is the URL of Microsoft Graph.
+	defaultEndpoint = "https://graph.microsoft.com/v1.0"
+	// graphScope is the scope of the tokens of Microsoft Graph.
+	graphScope = "https://graph.microsoft.com/.default"
+
+	defaultMaxObjectSize = 250 * 1024 * 1024 // 250 MiB
+	maxObjectSizeLimit   = 250 * 1024 * 1024 // 250 MiB
+)
+
+// authorityHost is the URL of the Microsoft identity platform, which issues
+// the tokens of Microsoft Graph and SharePoint.
+var authorityHost = "https://login.microsoftonline.com"
+
+var (
+	// errForbidden is returned for requests that the permissions of the app
+	// or user don't allow.
+	errForbidden = errors.New("forbidden")
+	errNotFound  = errors.New("not found")
+)
+
+// Source scans the files of the document libraries of SharePoint sites and
+// of OneDrive accounts, and the items of SharePoint lists with their
+// attachments, through Microsoft Graph.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	// client authorizes its requests of Microsoft Graph with the credentials
+	// of the source.
+	client   *http.Client
+	endpoint string
+	// tokens returns the tokens of a scope, like that of a SharePoint host,
+	// or nil if the credentials can't get tokens of other scopes.
+	tokens func(scope string) oauth2.TokenSource
+	// restClients are the clients of the REST API of SharePoint by host.
+	restClientsMu sync.Mutex
+	restClients   map[string]*http.Client
+	// appPermissions is whether the source authenticates as an app of the
+	// tenant rather than as a user.
+	appPermissions bool
+	conn           *sourcespb.Sharepoint
+	maxObjectSize  int64
+	// sites and ignoreSites are the globs of the URLs of the sites scanned
+	// and ignored.
+	sites, ignoreSites sources.Globs
+	// state is read from and saved to the state path of the connection.
+	stateMu sync.Mutex
+	state   state
+	// archiveOptions configures how the archives of files are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// state is what a scan saves for the next one to only scan what changed
+// since.
+type state struct {
+	// Drives are the delta links of drives, by drive ID.
+	Drives map[string]string `json:"drives"`
+	// Lists are the times of the last change of the items of lists, by site
+	// and list ID.
+	Lists map[string]time.Time `json:"lists"`
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_SHAREPOINT
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of files are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized SharePoint source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Sharepoint
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+	s.endpoint = strings.TrimSuffix(conn.GetEndpoint(), "/")
+	if s.endpoint == "" {
+		s.endpoint = defaultEndpoint
+	}
+	s.maxObjectSize = conn.GetMaxObjectSize()
+	if s.maxObjectSize <= 0 || s.maxObjectSize > maxObjectSizeLimit {
+		s.maxObjectSize = defaultMaxObjectSize
+	}
+	var err error
+	if s.sites, err = sources.CompileGlobs(conn.GetSites()); err != nil {
+		return err
+	}
+	if s.ignoreSites, err = sources.CompileGlobs(conn.GetIgnoreSites()); err != nil {
+		return err
+	}
+
+	// Tokens are requested with the retrying client, and authorize the
+	// requests of another.
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, common.RetryableHttpClientTimeout(120))
+	switch cred := conn.Credential.(type) {
+	case *sourcespb.Sharepoint_Token:
+		token := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cred.Token})
+		s.tokens = func(scope string) oauth2.TokenSource {
+			if scope == graphScope {
+				return token
+			}
+			return nil
+		}
+	case *sourcespb.Sharepoint_Authenticated:
+		s.appPermissions = true
+		s.tokens = func(scope string) oauth2.TokenSource {
+			config := clientcredentials.Config{
+				ClientID:     cred.Authenticated.GetClientId(),
+				ClientSecret: cred.Authenticated.GetClientSecret(),
+				TokenURL:     authorityHost + "/" + url.PathEscape(cred.Authenticated.GetTenantId()) + "/oauth2/v2.0/token",
+				Scopes:       []string{scope},
+			}
+			return config.TokenSource(tokenCtx)
+		}
+	case *sourcespb.Sharepoint_Oauth:
+		s.tokens = func(scope string) oauth2.TokenSource {
+			token := &oauth2.Token{RefreshToken: cred.Oauth.GetRefreshToken()}
+			if scope == graphScope {
+				token.AccessToken = cred.Oauth.GetAccessToken()
+			}
+			if token.RefreshToken == "" {
+				if token.AccessToken == "" {
+					return nil
+				}
+				return oauth2.StaticTokenSource(token)
+			}
+			config := oauth2.Config{
+				ClientID:     cred.Oauth.GetClientId(),
+				ClientSecret: cred.Oauth.GetClientSecret(),
+				Endpoint:     oauth2.Endpoint{TokenURL: authorityHost + "/common/oauth2/v2.0/token"},
+				Scopes:       []string{scope, "offline_access"},
+			}
+			return config.TokenSource(tokenCtx, token)
+		}
+	default:
+		return errors.New("credentials are required")
+	}
+	tokens := s.tokens(graphScope)
+	if tokens == nil {
+		return errors.New("an access token or refresh token is required")
+	}
+	s.client = newClient(tokens)
+	s.restClients = make(map[string]*http.Client)
+
+	s.state = state{Drives: map[string]string{}, Lists: map[string]time.Time{}}
+	return nil
+}
+
+// newClient returns a client whose requests are authorized with tokens.
+func newClient(tokens oauth2.TokenSource) *http.Client {
+	return &http.Client{
+		Timeout:   120 * time.Second,
+		Transport: &oauth2.Transport{Source: oauth2.ReuseTokenSource(nil, tokens), Base: common.RetryableHttpClientTimeout(120).Transport},
+	}
+}
+
+type identitySet struct {
+	User struct {
+		DisplayName string `json:"displayName"`
+		Email       string `json:"email"`
+	} `json:"user"`
+}
+
+type site struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	WebURL      string `json:"webUrl"`
+}
+
+type drive struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	WebURL string `json:"webUrl"`
+}
+
+type list struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	WebURL      string `json:"webUrl"`
+	List        struct {
+		Hidden   bool   `json:"hidden"`
+		Template string `json:"template"`
+	} `json:"list"`
+}
+
+type driveItem struct {
+	ID             string      `json:"id"`
+	Name           string      `json:"name"`
+	Size           int64       `json:"size"`
+	WebURL         string      `json:"webUrl"`
+	LastModified   time.Time   `json:"lastModifiedDateTime"`
+	CreatedBy      identitySet `json:"createdBy"`
+	LastModifiedBy identitySet `json:"lastModifiedBy"`
+	File           *struct{}   `json:"file"`
+	Deleted        *struct{}   `json:"deleted"`
+	Parent         struct {
+		DriveID string `json:"driveId"`
+		Path    string `json:"path"`
+	} `json:"parentReference"`
+}
+
+type listItem struct {
+	ID             string         `json:"id"`
+	WebURL         string         `json:"webUrl"`
+	LastModified   time.Time      `json:"lastModifiedDateTime"`
+	CreatedBy      identitySet    `json:"createdBy"`
+	LastModifiedBy identitySet    `json:"lastModifiedBy"`
+	Fields         map[string]any `json:"fields"`
+}
+
+// unit is a drive or list to scan, with the site it belongs to.
+type unit struct {
+	site  site
+	drive drive
+	list  list
+}
+
+// Chunks emits the files of the drives of the sites and OneDrive accounts,
+// and the items of the lists of the sites with their attachments, that
+// changed since the last scan, as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	if err := s.loadState(); err != nil {
+		return err
+	}
+
+	units, err := s.siteUnits(ctx)
+	if err != nil {
+		return err
+	}
+	if !s.conn.GetSkipOnedrive() {
+		drives, err := s.oneDrives(ctx)
+		switch {
+		case errors.Is(err, errForbidden):
+			ctx.Logger().Info("skipping OneDrive accounts, which the permissions of the credentials don't allow reading")
+		case err != nil:
+			return fmt.Errorf("error listing OneDrive accounts: %w", err)
+		}
+		for _, d := range drives {
+			units = append(units, unit{drive: d})
+		}
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, u := range units {
+		if common.IsDone(ctx) {
+			break
+		}
+		u := u
+		if u.list.ID != "" {
+			s.SetProgressComplete(i, len(units), fmt.Sprintf("List: %s", u.list.WebURL), "")
+		} else {
+			s.SetProgressComplete(i, len(units), fmt.Sprintf("Drive: %s", u.drive.WebURL), "")
+		}
+		s.jobPool.Go(func() error {
+			var err error
+			if u.list.ID != "" {
+				err = s.scanList(ctx, u.site, u.list, chunksChan)
+			} else {
+				err = s.scanDrive(ctx, u.site, u.drive, chunksChan)
+			}
+			if errors.Is(err, errForbidden) {
+				ctx.Logger().V(1).Info("skipping what the permissions of the credentials don't allow reading", "error", err)
+			} else if err != nil {
+				scanErrs.Add(err)
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return s.saveState()
+}
+
+// siteUnits returns the document libraries and lists of the site of the
+// connection and of the sites matching its patterns, or of all the sites
+// the credentials can read if there are neither.
+func (s *Source) siteUnits(ctx context.Context) ([]unit, error) {
+	var sites []site
+	if siteURL := s.conn.GetSiteUrl(); siteURL != "" {
+		u, err := url.Parse(siteURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid site URL %s: %w", siteURL, err)
+		}
+		var st site
+		if err := s.get(ctx, "/sites/"+u.Host+":"+strings.TrimSuffix(u.EscapedPath(), "/"), &st); err != nil {
+			return nil, fmt.Errorf("error getting site %s: %w", siteURL, err)
+		}
+		sites = append(sites, st)
+	}
+	if s.conn.GetSiteUrl() == "" || len(s.conn.GetSites()) > 0 {
+		all, _, err := listAll[site](ctx, s, "/sites?search=*")
+		if err != nil {
+			return nil, fmt.Errorf("error listing sites: %w", err)
+		}
+		for _, st := range all {
+			if len(s.sites) > 0 && !s.sites.Match(st.WebURL) {
+				continue
+			}
+			if len(sites) > 0 && st.ID == sites[0].ID {
+				continue
+			}
+			sites = append(sites, st)
+		}
+	}
+
+	var units []unit
+	for _, st := range sites {
+		if s.ignoreSites.Match(st.WebURL) {
+			ctx.Logger().V(2).Info("Ignoring site", "site", st.WebURL)
+			continue
+		}
+		drives, _, err := listAll[drive](ctx, s, "/sites/"+url.PathEscape(st.ID)+"/drives")
+		if err != nil {
+			return nil, fmt.Errorf("error listing the document libraries of site %s: %w", st.WebURL, err)
+		}
+		for _, d := range drives {
+			units = append(units, unit{site: st, drive: d})
+		}
+		if s.conn.GetSkipLists() {
+			continue
+		}
+		lists, _, err := listAll[list](ctx, s, "/sites/"+url.PathEscape(st.ID)+"/lists")
+		if err != nil {
+			return nil, fmt.Errorf("error listing the lists of site %s: %w", st.WebURL, err)
+		}
+		for _, l := range lists {
+			// Document libraries are scanned as drives, and the other
+			// templates are of pages and system lists.
+			if !l.List.Hidden && l.List.Template == "genericList" {
+				units = append(units, unit{site: st, list: l})
+			}
+		}
+	}
+	return units, nil
+}
+
+// oneDrives returns the OneDrive accounts of the users of the connection, or
+// of all the users of the tenant with app permissions, or of the user
+// otherwise.
+func (s *Source) oneDrives(ctx context.Context) ([]drive, error) {
+	if !s.appPermissions {
+		var d drive
+		if err := s.get(ctx, "/me/drive", &d); err != nil {
+			return nil, err
+		}
+		return []drive{d}, nil
+	}
+
+	users := s.conn.GetUsers()
+	if len(users) == 0 {
+		all, _, err := listAll[struct {
+			ID string `json:"id"`
+		}](ctx, s, "/users?$select=id")
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range all {
+			users = append(users, u.ID)
+		}
+	}
+	var drives []drive
+	for _, user := range users {
+		var d drive
+		err := s.get(ctx, "/users/"+url.PathEscape(user)+"/drive", &d)
+		switch {
+		case errors.Is(err, errNotFound):
+			// Users without a license have no OneDrive.
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("error getting the OneDrive of user %s: %w", user, err)
+		}
+		drives = append(drives, d)
+	}
+	return drives, nil
+}
+
+// scanDrive scans the files of a drive changed since the last scan.
+func (s *Source) scanDrive(ctx context.Context, st site, d drive, chunksChan chan *sources.Chunk) error {
+	path := "/drives/" + url.PathEscape(d.ID) + "/root/delta"
+	s.stateMu.Lock()
+	if link, ok := s.state.Drives[d.ID]; ok {
+		path = link
+	}
+	s.stateMu.Unlock()
+
+	deltaLink, err := pages(ctx, s, path, func(items []driveItem) error {
+		for _, item := range items {
+			if item.File == nil || item.Deleted != nil {
+				continue
+			}
+			switch {
+			case item.Size > s.maxObjectSize:
+				sources.ReportSkipBytes(ctx, item.Name, sources.SkipReasonSize, item.Size)
+				continue
+			case item.Size == 0:
+				sources.ReportSkip(ctx, item.Name, sources.SkipReasonEmpty)
+				continue
+			case common.SkipFile(item.Name):
+				sources.ReportSkipBytes(ctx, item.Name, sources.SkipReasonUnsupported, item.Size)
+				continue
+			}
+			skel := s.itemSkeleton(st, d, item)
+			contentURL := s.endpoint + "/drives/" + url.PathEscape(item.Parent.DriveID) + "/items/" + url.PathEscape(item.ID) + "/content"
+			if err := s.scanFile(ctx, s.client, contentURL, skel, chunksChan); err != nil {
+				ctx.Logger().Error(err, "could not scan file", "drive", d.WebURL, "file", item.Name)
+				sources.ReportSkip(ctx, item.Name, sources.SkipReasonError)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error listing the files of drive %s: %w", d.WebURL, err)
+	}
+	if deltaLink != "" {
+		s.stateMu.Lock()
+		s.state.Drives[d.ID] = deltaLink
+		s.stateMu.Unlock()
+	}
+	return nil
+}
+
+// scanList scans the fields of the items of a list changed since the last
+// scan, and their attachments.
+func (s *Source) scanList(ctx context.Context, st site, l list, chunksChan chan *sources.Chunk) error {
+	key := st.ID + "/" + l.ID
+	s.stateMu.Lock()
+	since := s.state.Lists[key]
+	s.stateMu.Unlock()
+
+	latest := since
+	path := "/sites/" + url.PathEscape(st.ID) + "/lists/" + url.PathEscape(l.ID) + "/items?expand=fields"
+	_, err := pages(ctx, s, path, func(items []listItem) error {
+		for _, item := range items {
+			if !item.LastModified.After(since) {
+				continue
+			}
+			if item.LastModified.After(latest) {
+				latest = item.LastModified
+			}
+			location := "list/" + l.DisplayName + "/item/" + item.ID
+			skel := s.chunkSkeleton(st, item.WebURL, location, item.LastModified, item.CreatedBy, item.LastModifiedBy)
+			skel.SourceMetadata.GetSharepoint().Docid = item.ID
+			if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(fieldsText(item.Fields)), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+				return err
+			}
+			if hasAttachments, _ := item.Fields["Attachments"].(bool); hasAttachments {
+				if err := s.scanAttachments(ctx, st, l, item, chunksChan); err != nil {
+					ctx.Logger().Error(err, "could not scan attachments", "list", l.WebURL, "item", item.ID)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error listing the items of list %s: %w", l.WebURL, err)
+	}
+
+	s.stateMu.Lock()
+	s.state.Lists[key] = latest
+	s.stateMu.Unlock()
+	return nil
+}
+
+// fieldsText returns the text fields of a list item, one per line, without
+// the fields of OData and SharePoint.
+func fieldsText(fields map[string]any) string {
+	names := make([]string, 0, len(fields))
+	for name, value := range fields {
+		if _, ok := value.(string); ok && !strings.HasPrefix(name, "@") && !strings.HasPrefix(name, "_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var text strings.Builder
+	for _, name := range names {
+		text.WriteString(name + ": " + fields[name].(string) + "\n")
+	}
+	return text.String()
+}
+
+// scanAttachments scans the attachments of a list item, which only the REST
+// API of SharePoint serves.
+func (s *Source) scanAttachments(ctx context.Context, st site, l list, item listItem, chunksChan chan *sources.Chunk) error {
+	siteURL, err := url.Parse(st.WebURL)
+	if err != nil {
+		return err
+	}
+	client := s.restClient(siteURL.Host)
+	if client == nil {
+		ctx.Logger().V(2).Info("skipping attachments, which the credentials can't read", "list", l.WebURL)
+		return nil
+	}
+
+	var attachments struct {
+		Value []struct {
+			FileName          string `json:"FileName"`
+			ServerRelativeURL string `json:"ServerRelativeUrl"`
+		} `json:"value"`
+	}
+	attachmentsURL := st.WebURL + "/_api/web/lists(guid'" + url.PathEscape(l.ID) + "')/items(" + url.PathEscape(item.ID) + ")/AttachmentFiles"
+	res, err := s.do(ctx, client, attachmentsURL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if err := json.NewDecoder(res.Body).Decode(&attachments); err != nil {
+		return err
+	}
+
+	for _, a := range attachments.Value {
+		location := "list/" + l.DisplayName + "/item/" + item.ID + "/attachment/" + a.FileName
+		skel := s.chunkSkeleton(st, siteURL.Scheme+"://"+siteURL.Host+a.ServerRelativeURL, location, item.LastModified, item.CreatedBy, item.LastModifiedBy)
+		skel.SourceMetadata.GetSharepoint().Docid = item.ID
+		skel.SourceMetadata.GetSharepoint().Title = sanitizer.UTF8(a.FileName)
+		query := url.Values{"@f": {"'" + strings.ReplaceAll(a.ServerRelativeURL, "'", "''") + "'"}}
+		contentURL := st.WebURL + "/_api/web/GetFileByServerRelativeUrl(@f)/$value?" + query.Encode()
+		if err := s.scanFile(ctx, client, contentURL, skel, chunksChan); err != nil {
+			return fmt.Errorf("error scanning attachment %s: %w", a.FileName, err)
+		}
+	}
+	return nil
+}
+
+// restClient returns the client of the REST API of a SharePoint host, or nil
+// if the credentials can't get its tokens.
+func (s *Source) restClient(host string) *http.Client {
+	s.restClientsMu.Lock()
+	defer s.restClientsMu.Unlock()
+	if client, ok := s.restClients[host]; ok {
+		return client
+	}
+	var client *http.Client
+	if tokens := s.tokens("https://" + host + "/.default"); tokens != nil {
+		client = newClient(tokens)
+	}
+	s.restClients[host] = client
+	return client
+}
+
+// scanFile downloads a file, and scans it through the handlers of archives.
+func (s *Source) scanFile(ctx context.Context, client *http.Client, contentURL string, skel *sources.Chunk, chunksChan chan *sources.Chunk) error {
+	res, err := s.do(ctx, client, contentURL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	reader, err := diskbufferreader.New(io.LimitReader(res.Body, s.maxObjectSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+	return sources.ChunkReaderTo(ctx, skel, reader, chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+// itemSkeleton returns the skeleton of the chunks of a file of a drive.
+func (s *Source) itemSkeleton(st site, d drive, item driveItem) *sources.Chunk {
+	skel := s.chunkSkeleton(st, item.WebURL, "", item.LastModified, item.CreatedBy, item.LastModifiedBy)
+	metadata := skel.SourceMetadata.GetSharepoint()
+	metadata.Title = sanitizer.UTF8(item.Name)
+	metadata.Docid = item.ID
+	metadata.Drive = d.WebURL
+	// The path of the parent is like /drives/ID/root:/folder.
+	_, folder, _ := strings.Cut(item.Parent.Path, "root:")
+	if folder, err := url.PathUnescape(folder); err == nil {
+		metadata.Path = sanitizer.UTF8(strings.TrimPrefix(folder+"/"+item.Name, "/"))
+	}
+	return skel
+}
+
+func (s *Source) chunkSkeleton(st site, link, location string, modified time.Time, createdBy, modifiedBy identitySet) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Sharepoint{
+				Sharepoint: &source_metadatapb.SharePoint{
+					Link:      link,
+					Timestamp: modified.Format(time.RFC3339),
+					Author:    createdBy.User.DisplayName,
+					Email:     modifiedBy.User.Email,
+					Site:      st.WebURL,
+					Location:  sanitizer.UTF8(location),
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// loadState reads the state of the last scan, if there was one.
+func (s *Source) loadState() error {
+	return sources.LoadState(s.conn.GetStatePath(), &s.state)
+}
+
+// saveState saves the state of the scan for the next one. The drives and
+// lists that failed keep the state of the last scan.
+func (s *Source) saveState() error {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return sources.SaveState(s.conn.GetStatePath(), s.state)
+}
+
+// pages calls fn with the values of each page of a collection of Microsoft
+// Graph, and returns the delta link of its last page for delta queries. The
+// path is relative to the endpoint, or a next or delta link.
+func pages[T any](ctx context.Context, s *Source, path string, fn func([]T) error) (string, error) {
+	next := path
+	for {
+		var page struct {
+			Value     []T    `json:"value"`
+			NextLink  string `json:"@odata.nextLink"`
+			DeltaLink string `json:"@odata.deltaLink"`
+		}
+		if err := s.get(ctx, next, &page); err != nil {
+			return "", err
+		}
+		if err := fn(page.Value); err != nil {
+			return "", err
+		}
+		if common.IsDone(ctx) {
+			return "", ctx.Err()
+		}
+		if page.NextLink == "" {
+			return page.DeltaLink, nil
+		}
+		next = page.NextLink
+	}
+}
+
+// listAll returns the values of all the pages of a collection of Microsoft
+// Graph, and the delta link of its last page for delta queries.
+func listAll[T any](ctx context.Context, s *Source, path string) ([]T, string, error) {
+	var all []T
+	deltaLink, err := pages(ctx, s, path, func(values []T) error {
+		all = append(all, values...)
+		return nil
+	})
+	return all, deltaLink, err
+}
+
+// get decodes the JSON response of a request of Microsoft Graph.
+func (s *Source) get(ctx context.Context, path string, v any) error {
+	reqURL := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		reqURL = s.endpoint + path
+	}
+	res, err := s.do(ctx, s.client, reqURL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// do sends an authorized GET request, and returns its response if it
+// succeeded.
+func (s *Source) do(ctx context.Context, client *http.Client, reqURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The REST API of SharePoint answers in XML without it.
+	req.Header.Set("Accept", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		switch res.StatusCode {
+		case http.StatusUnauthorized:
+			return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+		case http.StatusForbidden:
+			return nil, fmt.Errorf("%w: %s", errForbidden, req.URL.Path)
+		case http.StatusNotFound:
+			return nil, errNotFound
+		}
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res, nil
+}
@@ -0,0 +1,224 @@
+package sharepoint
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// fakeTenant serves the tokens of a tenant, and Microsoft Graph and
+// SharePoint for a site of a document library and a list, another site
+// that is ignored, and two users of which one has a OneDrive. It returns the
+// scopes of the tokens requested.
+func fakeTenant(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var scopes []string
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		scopes = append(scopes, r.FormValue("scope"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+	})
+	handle := func(path string, v func(r *http.Request) any) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if v == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			switch body := v(r).(type) {
+			case string:
+				_, _ = w.Write([]byte(body))
+			default:
+				_ = json.NewEncoder(w).Encode(body)
+			}
+		})
+	}
+	values := func(v ...any) func(*http.Request) any {
+		return func(*http.Request) any { return map[string]any{"value": v} }
+	}
+	modified := map[string]any{"lastModifiedDateTime": "2024-03-01T10:00:00Z"}
+	item := func(fields map[string]any) map[string]any {
+		for k, v := range modified {
+			fields[k] = v
+		}
+		return fields
+	}
+
+	handle("/sites", values(
+		map[string]any{"id": "s1", "webUrl": server.URL + "/sites/eng"},
+		map[string]any{"id": "s2", "webUrl": server.URL + "/sites/legacy"},
+	))
+	handle("/sites/s1/drives", values(map[string]any{"id": "d1", "name": "Documents", "webUrl": server.URL + "/sites/eng/Shared%20Documents"}))
+	handle("/drives/d1/root/delta", func(r *http.Request) any {
+		switch r.URL.Query().Get("token") {
+		case "":
+			return map[string]any{"value": []any{map[string]any{"id": "root", "name": "root", "folder": map[string]any{}}}, "@odata.nextLink": server.URL + "/drives/d1/root/delta?token=2"}
+		case "2":
+			return map[string]any{"@odata.deltaLink": server.URL + "/drives/d1/root/delta?token=next", "value": []any{
+				item(map[string]any{
+					"id": "f1", "name": "config.env", "size": 13, "webUrl": server.URL + "/sites/eng/Shared%20Documents/ops/config.env",
+					"file": map[string]any{}, "parentReference": map[string]any{"driveId": "d1", "path": "/drives/d1/root:/ops"},
+					"createdBy":      map[string]any{"user": map[string]any{"displayName": "Ada"}},
+					"lastModifiedBy": map[string]any{"user": map[string]any{"displayName": "Bob", "email": "bob@example.com"}},
+				}),
+				item(map[string]any{"id": "f2", "name": "old.env", "deleted": map[string]any{}, "parentReference": map[string]any{"driveId": "d1"}}),
+			}}
+		}
+		return map[string]any{"value": []any{}, "@odata.deltaLink": server.URL + "/drives/d1/root/delta?token=next"}
+	})
+	handle("/drives/d1/items/f1/content", func(*http.Request) any { return "TOKEN=secret\n" })
+	handle("/sites/s1/lists", values(
+		map[string]any{"id": "l1", "displayName": "Vendors", "webUrl": server.URL + "/sites/eng/Lists/Vendors", "list": map[string]any{"template": "genericList"}},
+		map[string]any{"id": "l2", "displayName": "Documents", "list": map[string]any{"template": "documentLibrary"}},
+	))
+	handle("/sites/s1/lists/l1/items", values(item(map[string]any{
+		"id": "12", "webUrl": server.URL + "/sites/eng/Lists/Vendors/DispForm.aspx?ID=12",
+		"fields": map[string]any{"@odata.etag": "1", "Title": "Acme", "Notes": "password: hunter2", "Attachments": true},
+	})))
+	handle("/sites/eng/_api/web/lists(guid'l1')/items(12)/AttachmentFiles", values(map[string]any{
+		"FileName": "contract.txt", "ServerRelativeUrl": "/sites/eng/Lists/Vendors/Attachments/12/contract.txt",
+	}))
+	handle("/sites/eng/_api/web/GetFileByServerRelativeUrl(@f)/$value", func(r *http.Request) any {
+		assert.Equal(t, "'/sites/eng/Lists/Vendors/Attachments/12/contract.txt'", r.URL.Query().Get("@f"))
+		return "api_key=AKIA"
+	})
+	handle("/users", values(map[string]any{"id": "u1"}, map[string]any{"id": "u2"}))
+	handle("/users/u1/drive", func(*http.Request) any {
+		return map[string]any{"id": "d2", "name": "OneDrive", "webUrl": server.URL + "/personal/ada/Documents"}
+	})
+	handle("/users/u2/drive", nil)
+	handle("/drives/d2/root/delta", func(r *http.Request) any {
+		if r.URL.Query().Get("token") == "next" {
+			return map[string]any{"value": []any{}, "@odata.deltaLink": server.URL + "/drives/d2/root/delta?token=next"}
+		}
+		return map[string]any{"@odata.deltaLink": server.URL + "/drives/d2/root/delta?token=next", "value": []any{
+			item(map[string]any{"id": "f3", "name": "notes.txt", "size": 4, "file": map[string]any{}, "parentReference": map[string]any{"driveId": "d2", "path": "/drives/d2/root:"}}),
+		}}
+	})
+	handle("/drives/d2/items/f3/content", func(*http.Request) any { return "pass" })
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return scopes
+	}
+}
+
+// chunks returns the data of the chunks of a source by path or location, and
+// their metadata.
+func chunks(t *testing.T, conn *sourcespb.Sharepoint) (map[string]string, map[string]*source_metadatapb.SharePoint) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.SharePoint)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetSharepoint()
+		key := m.GetLocation()
+		if key == "" {
+			key = m.GetPath()
+		}
+		got[key] += string(chunk.Data)
+		metadata[key] = m
+	}
+	return got, metadata
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server, scopes := fakeTenant(t)
+	authority := authorityHost
+	authorityHost = server.URL
+	t.Cleanup(func() { authorityHost = authority })
+
+	conn := &sourcespb.Sharepoint{
+		Endpoint: server.URL,
+		Credential: &sourcespb.Sharepoint_Authenticated{
+			Authenticated: &credentialspb.ClientCredentials{TenantId: "tenant", ClientId: "app", ClientSecret: "secret"},
+		},
+		IgnoreSites: []string{"*/legacy"},
+		StatePath:   filepath.Join(t.TempDir(), "state.json"),
+	}
+	got, metadata := chunks(t, conn)
+	assert.Equal(t, map[string]string{
+		"ops/config.env":       "TOKEN=secret\n",
+		"notes.txt":            "pass",
+		"list/Vendors/item/12": "Notes: password: hunter2\nTitle: Acme\n",
+		"list/Vendors/item/12/attachment/contract.txt": "api_key=AKIA",
+	}, got)
+	assert.Equal(t, &source_metadatapb.SharePoint{
+		Link:      server.URL + "/sites/eng/Shared%20Documents/ops/config.env",
+		Timestamp: "2024-03-01T10:00:00Z",
+		Author:    "Ada",
+		Title:     "config.env",
+		Docid:     "f1",
+		Email:     "bob@example.com",
+		Site:      server.URL + "/sites/eng",
+		Drive:     server.URL + "/sites/eng/Shared%20Documents",
+		Path:      "ops/config.env",
+	}, metadata["ops/config.env"])
+	assert.Equal(t, server.URL+"/personal/ada/Documents", metadata["notes.txt"].GetDrive())
+	assert.Equal(t, server.URL+"/sites/eng/Lists/Vendors/Attachments/12/contract.txt", metadata["list/Vendors/item/12/attachment/contract.txt"].GetLink())
+	assert.Contains(t, scopes(), "https://"+server.Listener.Addr().String()+"/.default")
+
+	// The next scan only scans what changed since the first.
+	got, _ = chunks(t, conn)
+	assert.Empty(t, got)
+}
+
+func TestSource_ChunksSiteURL(t *testing.T) {
+	server, _ := fakeTenant(t)
+	server.Config.Handler.(*http.ServeMux).HandleFunc("/sites/"+server.Listener.Addr().String()+":/sites/eng", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "s1", "webUrl": server.URL + "/sites/eng"})
+	})
+
+	// Attachments are skipped, as a token of Microsoft Graph can't read
+	// them.
+	got, _ := chunks(t, &sourcespb.Sharepoint{
+		Endpoint:     server.URL,
+		Credential:   &sourcespb.Sharepoint_Token{Token: "token"},
+		SiteUrl:      server.URL + "/sites/eng",
+		SkipOnedrive: true,
+	})
+	assert.Equal(t, map[string]string{
+		"ops/config.env":       "TOKEN=secret\n",
+		"list/Vendors/item/12": "Notes: password: hunter2\nTitle: Acme\n",
+	}, got)
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server, _ := fakeTenant(t)
+	conn, err := anypb.New(&sourcespb.Sharepoint{Endpoint: server.URL, Credential: &sourcespb.Sharepoint_Token{Token: "expired"}})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "invalid credentials")
+}
+
+func TestSource_InitInvalidGlob(t *testing.T) {
+	conn, err := anypb.New(&sourcespb.Sharepoint{IgnoreSites: []string{"[a"}, Credential: &sourcespb.Sharepoint_Token{Token: "token"}})
+	require.NoError(t, err)
+	assert.ErrorContains(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, conn, 1), "invalid glob")
+}
@@ -0,0 +1,92 @@
+package sources
+
+import (
+	"context"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// SkipReason categorizes why an item was not scanned.
+type SkipReason string
+
+const (
+	// SkipReasonSize is used when an item exceeds a configured size limit.
+	SkipReasonSize SkipReason = "size"
+	// SkipReasonDepth is used when nested archives exceed the maximum depth.
+	SkipReasonDepth SkipReason = "depth"
+	// SkipReasonTimeout is used when an item could not be fully processed in time.
+	SkipReasonTimeout SkipReason = "timeout"
+	// SkipReasonUnsupported is used for formats that are not scanned.
+	SkipReasonUnsupported SkipReason = "unsupported_format"
+	// SkipReasonEncrypted is used for encrypted or password protected items.
+	SkipReasonEncrypted SkipReason = "encrypted"
+	// SkipReasonEmpty is used for items that have no content.
+	SkipReasonEmpty SkipReason = "empty"
+	// SkipReasonFiltered is used for items excluded by user configuration.
+	SkipReasonFiltered SkipReason = "filtered"
+	// SkipReasonCompressionRatio is used for archives whose files decompress
+	// to many times their compressed size, like zip bombs.
+	SkipReasonCompressionRatio SkipReason = "compression_ratio"
+	// SkipReasonError is used for items that could not be read.
+	SkipReasonError SkipReason = "error"
+)
+
+// SkippedItem describes an item that a source or handler did not scan.
+type SkippedItem struct {
+	SourceName string
+	SourceType sourcespb.SourceType
+	// Item identifies what was skipped, e.g. a file path or object key.
+	Item   string
+	Reason SkipReason
+	// Bytes is the size of the item, or zero if it is not known.
+	Bytes int64
+}
+
+// SkipReporter receives notifications about items that were not scanned.
+// Implementations must be safe for concurrent use.
+type SkipReporter interface {
+	ReportSkip(SkippedItem)
+}
+
+type skipReporterKey struct{}
+
+// skipReporterValue is stored in a context to attribute skipped items to the
+// source that is currently running.
+type skipReporterValue struct {
+	reporter   SkipReporter
+	sourceName string
+	sourceType sourcespb.SourceType
+}
+
+// ContextWithSkipReporter returns a context in which the skipped items are
+// reported to reporter as items of the named source, like the contexts the
+// SourceManager runs sources in. It is for scanning outside of a
+// SourceManager, e.g. with handlers.HandleFile.
+func ContextWithSkipReporter(ctx context.Context, reporter SkipReporter, sourceName string, sourceType sourcespb.SourceType) context.Context {
+	return context.WithValue(ctx, skipReporterKey{}, skipReporterValue{
+		reporter:   reporter,
+		sourceName: sourceName,
+		sourceType: sourceType,
+	})
+}
+
+// ReportSkip records that item was not scanned for the given reason. It is a
+// no-op if no SkipReporter is configured for the running source.
+func ReportSkip(ctx context.Context, item string, reason SkipReason) {
+	ReportSkipBytes(ctx, item, reason, 0)
+}
+
+// ReportSkipBytes is ReportSkip for items whose size is known.
+func ReportSkipBytes(ctx context.Context, item string, reason SkipReason, bytes int64) {
+	v, ok := ctx.Value(skipReporterKey{}).(skipReporterValue)
+	if !ok || v.reporter == nil {
+		return
+	}
+	v.reporter.ReportSkip(SkippedItem{
+		SourceName: v.sourceName,
+		SourceType: v.sourceType,
+		Item:       item,
+		Reason:     reason,
+		Bytes:      bytes,
+	})
+}
@@ -0,0 +1,607 @@
+package slack
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// defaultEndpoint is the URL the Web API is called at when the
+	// connection has no workspace URL.
+	defaultEndpoint = "https://slack.com"
+	// pageSize is the number of users, channels and messages requested at
+	// a time.
+	pageSize = 200
+	// maxFileSize is the size of the largest uploaded files scanned.
+	maxFileSize = 250 * 1024 * 1024
+
+	locationMessage = "message"
+	locationThread  = "thread"
+	locationFile    = "file"
+)
+
+// Source scans the messages of the channels of a Slack workspace, their
+// threads and the files uploaded to them, through the Web API or from the
+// zip of a workspace export.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	client   *http.Client
+	endpoint string
+	token    string
+	conn     *sourcespb.Slack
+	// workspaceURL is the URL permalinks are relative to, empty if unknown.
+	workspaceURL string
+	// users are the users of the workspace by ID.
+	users map[string]user
+	// archiveOptions configures how the archives of files are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_SLACK
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of uploaded files are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized Slack source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Slack
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	switch cred := conn.Credential.(type) {
+	case *sourcespb.Slack_Token:
+		s.token = cred.Token
+	case *sourcespb.Slack_Tokens:
+		// User tokens see the private channels of their user, bot tokens
+		// those the bot was added to.
+		s.token = cred.Tokens.GetClientToken()
+		if s.token == "" {
+			s.token = cred.Tokens.GetBotToken()
+		}
+	}
+	if s.token == "" && conn.GetExportPath() == "" {
+		return errors.New("a token or the path of an export is required")
+	}
+
+	s.endpoint = strings.TrimSuffix(conn.GetEndpoint(), "/")
+	if conn.GetEndpoint() != "" {
+		s.workspaceURL = s.endpoint + "/"
+	} else {
+		s.endpoint = defaultEndpoint
+	}
+	s.client = common.RetryableHttpClientTimeout(120)
+	s.users = make(map[string]user)
+	return nil
+}
+
+type user struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Profile  struct {
+		Email string `json:"email"`
+	} `json:"profile"`
+}
+
+type channel struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	IsPrivate bool   `json:"is_private"`
+}
+
+type file struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	URLPrivateDownload string `json:"url_private_download"`
+	Permalink          string `json:"permalink"`
+}
+
+type message struct {
+	User       string `json:"user"`
+	Username   string `json:"username"`
+	Text       string `json:"text"`
+	TS         string `json:"ts"`
+	ThreadTS   string `json:"thread_ts"`
+	ReplyCount int    `json:"reply_count"`
+	Files      []file `json:"files"`
+}
+
+// response is the response of a method of the Web API.
+type response struct {
+	OK       bool      `json:"ok"`
+	Error    string    `json:"error"`
+	URL      string    `json:"url"`
+	Members  []user    `json:"members"`
+	Channels []channel `json:"channels"`
+	Messages []message `json:"messages"`
+	Metadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
+// Chunks emits the messages of the channels of the workspace, the replies of
+// their threads, and their files, as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	if s.conn.GetExportPath() != "" {
+		return s.scanExport(ctx, chunksChan)
+	}
+
+	var auth response
+	if err := s.call(ctx, "auth.test", nil, &auth); err != nil {
+		return err
+	}
+	s.workspaceURL = auth.URL
+	err := s.pages(ctx, "users.list", url.Values{}, func(res *response) {
+		for _, u := range res.Members {
+			s.users[u.ID] = u
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("error listing users: %w", err)
+	}
+	var channels []channel
+	err = s.pages(ctx, "conversations.list", url.Values{"types": {"public_channel,private_channel"}}, func(res *response) {
+		channels = append(channels, res.Channels...)
+	})
+	if err != nil {
+		return fmt.Errorf("error listing channels: %w", err)
+	}
+	channels = s.filterChannels(channels)
+
+	scanErrs := sources.NewScanErrors()
+	for i, ch := range channels {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(channels), fmt.Sprintf("Channel: #%s", ch.Name), "")
+		ch := ch
+		s.jobPool.Go(func() error {
+			if err := s.scanChannel(ctx, ch, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error scanning channel %s: %w", ch.Name, err))
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// filterChannels returns the channels named or identified by the channels
+// of the connection, or all of them if it has none, except for those it
+// ignores.
+func (s *Source) filterChannels(channels []channel) []channel {
+	matches := func(list []string, ch channel) bool {
+		for _, c := range list {
+			c = strings.TrimPrefix(c, "#")
+			if c == ch.ID || c == ch.Name {
+				return true
+			}
+		}
+		return false
+	}
+	kept := channels[:0]
+	for _, ch := range channels {
+		if len(s.conn.GetChannels()) > 0 && !matches(s.conn.GetChannels(), ch) {
+			continue
+		}
+		if matches(s.conn.GetIgnoreList(), ch) {
+			continue
+		}
+		kept = append(kept, ch)
+	}
+	return kept
+}
+
+// scanChannel scans the history of a channel and the replies of its threads.
+func (s *Source) scanChannel(ctx context.Context, ch channel, chunksChan chan *sources.Chunk) error {
+	query := url.Values{"channel": {ch.ID}}
+	if since := s.conn.GetSince(); since != nil {
+		query.Set("oldest", formatTS(since.AsTime()))
+	}
+	if until := s.conn.GetUntil(); until != nil {
+		query.Set("latest", formatTS(until.AsTime()))
+	}
+	var history []message
+	err := s.pages(ctx, "conversations.history", query, func(res *response) {
+		history = append(history, res.Messages...)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range history {
+		if err := s.scanMessage(ctx, ch, msg, chunksChan); err != nil {
+			return err
+		}
+		if msg.ReplyCount == 0 || msg.ThreadTS != msg.TS {
+			continue
+		}
+		query.Set("ts", msg.TS)
+		var replies []message
+		err := s.pages(ctx, "conversations.replies", query, func(res *response) {
+			replies = append(replies, res.Messages...)
+		})
+		if err != nil {
+			return fmt.Errorf("error listing the replies of %s: %w", msg.TS, err)
+		}
+		for _, reply := range replies {
+			// The parent of a thread is its first message.
+			if reply.TS == msg.TS {
+				continue
+			}
+			if err := s.scanMessage(ctx, ch, reply, chunksChan); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scanExport scans the messages of the channels of the zip of a workspace
+// export, in their directories of a file of messages per day. The files
+// uploaded to them are downloaded if the source has a token.
+func (s *Source) scanExport(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	zr, err := zip.OpenReader(s.conn.GetExportPath())
+	if err != nil {
+		return fmt.Errorf("error opening export: %w", err)
+	}
+	defer zr.Close()
+
+	var users []user
+	if err := readExportFile(&zr.Reader, "users.json", &users); err != nil {
+		return err
+	}
+	for _, u := range users {
+		s.users[u.ID] = u
+	}
+	var channels, private []channel
+	if err := readExportFile(&zr.Reader, "channels.json", &channels); err != nil {
+		return err
+	}
+	if err := readExportFile(&zr.Reader, "groups.json", &private); err != nil {
+		return err
+	}
+	for i := range private {
+		private[i].IsPrivate = true
+	}
+	byName := make(map[string]channel)
+	for _, ch := range s.filterChannels(append(channels, private...)) {
+		byName[ch.Name] = ch
+	}
+
+	days := make(map[string][]*zip.File)
+	for _, f := range zr.File {
+		dir, name := path.Split(f.Name)
+		dir = strings.TrimSuffix(dir, "/")
+		if _, ok := byName[dir]; !ok || !s.inRange(strings.TrimSuffix(name, ".json")) {
+			continue
+		}
+		days[dir] = append(days[dir], f)
+	}
+	names := make([]string, 0, len(days))
+	for name := range days {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	scanErrs := sources.NewScanErrors()
+	for i, name := range names {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(names), fmt.Sprintf("Channel: #%s", name), "")
+		for _, f := range days[name] {
+			if err := s.scanExportDay(ctx, byName[name], f, chunksChan); err != nil {
+				scanErrs.Add(fmt.Errorf("error scanning %s: %w", f.Name, err))
+			}
+		}
+	}
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// inRange returns whether the messages of a day of an export may have been
+// posted between the since and until of the connection.
+func (s *Source) inRange(day string) bool {
+	date, err := time.Parse(time.DateOnly, day)
+	if err != nil {
+		return false
+	}
+	if since := s.conn.GetSince(); since != nil && date.Add(24*time.Hour).Before(since.AsTime()) {
+		return false
+	}
+	if until := s.conn.GetUntil(); until != nil && date.After(until.AsTime()) {
+		return false
+	}
+	return true
+}
+
+func (s *Source) scanExportDay(ctx context.Context, ch channel, f *zip.File, chunksChan chan *sources.Chunk) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	var messages []message
+	if err := json.NewDecoder(rc).Decode(&messages); err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		posted := parseTS(msg.TS)
+		if since := s.conn.GetSince(); since != nil && posted.Before(since.AsTime()) {
+			continue
+		}
+		if until := s.conn.GetUntil(); until != nil && posted.After(until.AsTime()) {
+			continue
+		}
+		if err := s.scanMessage(ctx, ch, msg, chunksChan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readExportFile decodes a JSON file at the root of an export, if it has it.
+func readExportFile(zr *zip.Reader, name string, v any) error {
+	rc, err := zr.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("error decoding %s: %w", name, err)
+	}
+	return nil
+}
+
+// scanMessage scans the text of a message and its files.
+func (s *Source) scanMessage(ctx context.Context, ch channel, msg message, chunksChan chan *sources.Chunk) error {
+	location := locationMessage
+	if msg.ThreadTS != "" && msg.ThreadTS != msg.TS {
+		location = locationThread
+	}
+	if msg.Text != "" {
+		skel := s.chunkSkeleton(ch, msg, location, s.permalink(ch, msg))
+		if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(msg.Text), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+			return err
+		}
+	}
+
+	if s.conn.GetSkipFiles() || s.token == "" {
+		return nil
+	}
+	for _, f := range msg.Files {
+		if f.URLPrivateDownload == "" {
+			continue
+		}
+		if f.Size > maxFileSize {
+			sources.ReportSkipBytes(ctx, ch.Name+"/"+f.Name, sources.SkipReasonSize, f.Size)
+			continue
+		}
+		link := f.Permalink
+		if link == "" {
+			link = s.permalink(ch, msg)
+		}
+		skel := s.chunkSkeleton(ch, msg, locationFile, link)
+		skel.SourceMetadata.GetSlack().File = f.Name
+		if err := s.scanFile(ctx, skel, f.URLPrivateDownload, chunksChan); err != nil {
+			return fmt.Errorf("error scanning file %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// scanFile scans an uploaded file, through the handlers of archives.
+func (s *Source) scanFile(ctx context.Context, skel *sources.Chunk, downloadURL string, chunksChan chan *sources.Chunk) error {
+	res, err := s.do(ctx, downloadURL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	reader, err := diskbufferreader.New(res.Body)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+	return sources.ChunkReaderTo(ctx, skel, reader, chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+func (s *Source) chunkSkeleton(ch channel, msg message, location, link string) *sources.Chunk {
+	author := s.users[msg.User]
+	name := author.RealName
+	if name == "" {
+		name = author.Name
+	}
+	if name == "" {
+		// Messages of bots and integrations have no user.
+		name = msg.Username
+	}
+	visibility := source_metadatapb.Visibility_public
+	if ch.IsPrivate {
+		visibility = source_metadatapb.Visibility_private
+	}
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Slack{
+				Slack: &source_metadatapb.Slack{
+					ChannelId:   ch.ID,
+					ChannelName: ch.Name,
+					Timestamp:   parseTS(msg.TS).Format(time.RFC3339),
+					UserId:      msg.User,
+					UserName:    name,
+					Email:       author.Profile.Email,
+					Link:        link,
+					Visibility:  visibility,
+					Location:    location,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// permalink returns the link to a message, or to a reply in its thread. It
+// is empty if the URL of the workspace is unknown.
+func (s *Source) permalink(ch channel, msg message) string {
+	if s.workspaceURL == "" {
+		return ""
+	}
+	link := s.workspaceURL + "archives/" + ch.ID + "/p" + strings.Replace(msg.TS, ".", "", 1)
+	if msg.ThreadTS != "" && msg.ThreadTS != msg.TS {
+		link += "?thread_ts=" + msg.ThreadTS + "&cid=" + ch.ID
+	}
+	return link
+}
+
+// parseTS returns the time of the timestamp of a message, in seconds since
+// the epoch with a fraction.
+func parseTS(ts string) time.Time {
+	seconds, _, _ := strings.Cut(ts, ".")
+	sec, _ := strconv.ParseInt(seconds, 10, 64)
+	return time.Unix(sec, 0).UTC()
+}
+
+func formatTS(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10) + ".000000"
+}
+
+// pages calls a paginated method of the Web API until its last page.
+func (s *Source) pages(ctx context.Context, method string, query url.Values, fn func(*response)) error {
+	query.Set("limit", strconv.Itoa(pageSize))
+	query.Del("cursor")
+	for {
+		var res response
+		if err := s.call(ctx, method, query, &res); err != nil {
+			return err
+		}
+		fn(&res)
+		if res.Metadata.NextCursor == "" || common.IsDone(ctx) {
+			return nil
+		}
+		query.Set("cursor", res.Metadata.NextCursor)
+	}
+}
+
+// call calls a method of the Web API, which returns its errors in the body of
+// successful responses.
+func (s *Source) call(ctx context.Context, method string, query url.Values, res *response) error {
+	reqURL := s.endpoint + "/api/" + method
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	httpRes, err := s.do(ctx, reqURL)
+	if err != nil {
+		return err
+	}
+	defer httpRes.Body.Close()
+	if err := json.NewDecoder(httpRes.Body).Decode(res); err != nil {
+		return err
+	}
+	switch {
+	case res.OK:
+		return nil
+	case res.Error == "invalid_auth", res.Error == "not_authed", res.Error == "token_revoked", res.Error == "account_inactive":
+		return fmt.Errorf("invalid credentials: %s", res.Error)
+	}
+	return fmt.Errorf("%s failed: %s", method, res.Error)
+}
+
+// do sends an authorized GET request, and returns its response if it
+// succeeded. Requests that were rate limited are retried after the time
+// Slack asks for.
+func (s *Source) do(ctx context.Context, reqURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+		}
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res, nil
+}
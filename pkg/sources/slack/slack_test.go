@@ -0,0 +1,168 @@
+package slack
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+var users = []map[string]any{{"id": "U1", "name": "ada", "real_name": "Ada", "profile": map[string]any{"email": "ada@example.com"}}}
+
+// fakeSlack serves the Web API of a workspace of two channels, whose first
+// has a thread and a file, and whose history is in two pages.
+func fakeSlack(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	reply := func(w http.ResponseWriter, r *http.Request, res map[string]any) {
+		if r.Header.Get("Authorization") != "Bearer xoxb-token" {
+			res = map[string]any{"error": "invalid_auth"}
+		} else {
+			res["ok"] = true
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}
+	mux.HandleFunc("/api/auth.test", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, r, map[string]any{"url": server.URL + "/"})
+	})
+	mux.HandleFunc("/api/users.list", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, r, map[string]any{"members": users})
+	})
+	mux.HandleFunc("/api/conversations.list", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "public_channel,private_channel", r.URL.Query().Get("types"))
+		reply(w, r, map[string]any{"channels": []map[string]any{
+			{"id": "C1", "name": "ops", "is_private": true},
+			{"id": "C2", "name": "random"},
+		}})
+	})
+	mux.HandleFunc("/api/conversations.history", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "C1", r.URL.Query().Get("channel"))
+		assert.Equal(t, "1709251200.000000", r.URL.Query().Get("oldest"))
+		if r.URL.Query().Get("cursor") == "" {
+			reply(w, r, map[string]any{
+				"messages":          []map[string]any{{"user": "U1", "text": "password: hunter2", "ts": "1709287200.000100", "thread_ts": "1709287200.000100", "reply_count": 1}},
+				"response_metadata": map[string]any{"next_cursor": "page2"},
+			})
+			return
+		}
+		reply(w, r, map[string]any{"messages": []map[string]any{{
+			"username": "deploy-bot", "ts": "1709290800.000200",
+			"files": []map[string]any{{"name": "app.env", "size": 13, "url_private_download": server.URL + "/files/app.env", "permalink": server.URL + "/files/F1"}},
+		}}})
+	})
+	mux.HandleFunc("/api/conversations.replies", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1709287200.000100", r.URL.Query().Get("ts"))
+		reply(w, r, map[string]any{"messages": []map[string]any{
+			{"user": "U1", "text": "password: hunter2", "ts": "1709287200.000100", "thread_ts": "1709287200.000100"},
+			{"user": "U1", "text": "rotated to hunter3", "ts": "1709287260.000300", "thread_ts": "1709287200.000100"},
+		}})
+	})
+	mux.HandleFunc("/files/app.env", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xoxb-token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte("TOKEN=secret\n"))
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// chunks returns the data of the chunks of a source by location and
+// timestamp, and their metadata.
+func chunks(t *testing.T, conn *sourcespb.Slack) (map[string]string, map[string]*source_metadatapb.Slack) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.Slack)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetSlack()
+		key := m.GetLocation() + " " + m.GetTimestamp()
+		got[key] += string(chunk.Data)
+		metadata[key] = m
+	}
+	return got, metadata
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server := fakeSlack(t)
+	got, metadata := chunks(t, &sourcespb.Slack{
+		Endpoint:   server.URL,
+		Credential: &sourcespb.Slack_Token{Token: "xoxb-token"},
+		IgnoreList: []string{"#random"},
+		Since:      timestamppb.New(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+	})
+	assert.Equal(t, map[string]string{
+		"message 2024-03-01T10:00:00Z": "password: hunter2",
+		"thread 2024-03-01T10:01:00Z":  "rotated to hunter3",
+		"file 2024-03-01T11:00:00Z":    "TOKEN=secret\n",
+	}, got)
+	assert.Equal(t, &source_metadatapb.Slack{
+		ChannelId:   "C1",
+		ChannelName: "ops",
+		Timestamp:   "2024-03-01T10:01:00Z",
+		UserId:      "U1",
+		UserName:    "Ada",
+		Email:       "ada@example.com",
+		Link:        server.URL + "/archives/C1/p1709287260000300?thread_ts=1709287200.000100&cid=C1",
+		Visibility:  source_metadatapb.Visibility_private,
+		Location:    "thread",
+	}, metadata["thread 2024-03-01T10:01:00Z"])
+	assert.Equal(t, "deploy-bot", metadata["file 2024-03-01T11:00:00Z"].GetUserName())
+	assert.Equal(t, "app.env", metadata["file 2024-03-01T11:00:00Z"].GetFile())
+}
+
+func TestSource_ChunksExport(t *testing.T) {
+	exportPath := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(exportPath)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	for name, content := range map[string]any{
+		"users.json":               users,
+		"channels.json":            []map[string]any{{"id": "C1", "name": "ops"}, {"id": "C2", "name": "random"}},
+		"ops/2024-02-28.json":      []map[string]any{{"user": "U1", "text": "too old", "ts": "1709114400.000100"}},
+		"ops/2024-03-01.json":      []map[string]any{{"user": "U1", "text": "password: hunter2", "ts": "1709287200.000100"}},
+		"random/2024-03-01.json":   []map[string]any{{"user": "U1", "text": "not in the channels", "ts": "1709287200.000100"}},
+		"integration_logs.json":    []any{},
+		"ops/not-a-day-of-history": []any{},
+	} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(content))
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	got, metadata := chunks(t, &sourcespb.Slack{
+		Endpoint:   "https://acme.slack.com",
+		ExportPath: exportPath,
+		Channels:   []string{"ops"},
+		Since:      timestamppb.New(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+	})
+	assert.Equal(t, map[string]string{"message 2024-03-01T10:00:00Z": "password: hunter2"}, got)
+	assert.Equal(t, "https://acme.slack.com/archives/C1/p1709287200000100", metadata["message 2024-03-01T10:00:00Z"].GetLink())
+	assert.Equal(t, source_metadatapb.Visibility_public, metadata["message 2024-03-01T10:00:00Z"].GetVisibility())
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := fakeSlack(t)
+	conn, err := anypb.New(&sourcespb.Slack{Endpoint: server.URL, Credential: &sourcespb.Slack_Token{Token: "xoxb-revoked"}})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "invalid credentials")
+}
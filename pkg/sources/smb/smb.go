@@ -0,0 +1,467 @@
+package smb
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"github.com/hirochachacha/go-smb2"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultMaxObjectSize = 250 * 1024 * 1024 // 250 MiB
+	maxObjectSizeLimit   = 250 * 1024 * 1024 // 250 MiB
+
+	defaultPort = "445"
+	dialTimeout = 30 * time.Second
+)
+
+// Source scans the files of the shares of an SMB server, through the SMB2
+// and SMB3 protocols.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn *sourcespb.SMB
+	// host is the server, without its port if it is the default one.
+	host string
+	// dial opens an authenticated session with the server.
+	dial          func(ctx context.Context) (session, error)
+	include       sources.Globs
+	exclude       sources.Globs
+	excludeShares sources.Globs
+	maxObjectSize int64
+	// archiveOptions configures how the archives of files are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// session is an authenticated session with a server, which can be used
+// concurrently.
+type session interface {
+	shareNames() ([]string, error)
+	mount(name string) (share, error)
+	close() error
+}
+
+// share is a mounted share, whose paths are relative to its root and
+// separated by slashes.
+type share interface {
+	readDir(dir string) ([]os.FileInfo, error)
+	open(name string) (io.ReadCloser, error)
+	umount() error
+}
+
+// file is a file of a share.
+type file struct {
+	share string
+	path  string
+	info  os.FileInfo
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_SMB
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of files are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized SMB source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.SMB
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	if conn.GetHost() == "" {
+		return errors.New("a host is required")
+	}
+	addr := conn.GetHost()
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, defaultPort)
+	}
+	s.host = strings.TrimSuffix(addr, ":"+defaultPort)
+
+	// Anonymous sessions are not supported, and guest sessions log in as
+	// the guest user.
+	if conn.GetUsername() == "" {
+		return errors.New("a username is required")
+	}
+	initiator := &smb2.NTLMInitiator{
+		User:     conn.GetUsername(),
+		Password: conn.GetPassword(),
+		Domain:   conn.GetDomain(),
+	}
+	if conn.GetNtlmHash() != "" {
+		hash, err := hex.DecodeString(conn.GetNtlmHash())
+		if err != nil || len(hash) != 16 {
+			return errors.New("the NTLM hash must be 32 hexadecimal characters")
+		}
+		initiator.Hash = hash
+	}
+	s.dial = func(ctx context.Context) (session, error) {
+		return dialSMB(ctx, addr, initiator)
+	}
+
+	var err error
+	if s.include, err = sources.CompileGlobs(conn.GetIncludeGlobs(), '/'); err != nil {
+		return err
+	}
+	if s.exclude, err = sources.CompileGlobs(conn.GetExcludeGlobs(), '/'); err != nil {
+		return err
+	}
+	if s.excludeShares, err = sources.CompileGlobs(conn.GetExcludeShares(), '/'); err != nil {
+		return err
+	}
+
+	s.maxObjectSize = conn.GetMaxObjectSize()
+	if s.maxObjectSize <= 0 || s.maxObjectSize > maxObjectSizeLimit {
+		s.maxObjectSize = defaultMaxObjectSize
+	}
+	return nil
+}
+
+// Chunks emits the files of the shares as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	sess, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("error connecting to %s: %w", s.host, err)
+	}
+	defer sess.close()
+
+	names, err := s.shares(sess)
+	if err != nil {
+		return err
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, name := range names {
+		if common.IsDone(ctx) {
+			break
+		}
+		s.SetProgressComplete(i, len(names), fmt.Sprintf("Share: %s", name), "")
+		if err := s.scanShare(ctx, sess, name, chunksChan); err != nil {
+			scanErrs.Add(fmt.Errorf("error scanning share %s: %w", name, err))
+		}
+	}
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+// EnumerateTargets reports each share with the number and size of the files
+// that would be scanned.
+func (s *Source) EnumerateTargets(ctx context.Context, report func(sources.Target)) error {
+	sess, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("error connecting to %s: %w", s.host, err)
+	}
+	defer sess.close()
+
+	names, err := s.shares(sess)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		sh, err := sess.mount(name)
+		if err != nil {
+			ctx.Logger().Error(err, "could not mount share", "share", name)
+			continue
+		}
+		files, err := s.files(ctx, sh, name)
+		_ = sh.umount()
+		if err != nil {
+			return err
+		}
+		target := sources.Target{Name: s.link(name, "")}
+		for _, f := range files {
+			target.Objects++
+			target.Bytes += f.info.Size()
+		}
+		report(target)
+	}
+	return nil
+}
+
+// shares returns the names of the shares to scan: those of the connection,
+// or else those of the server that are not hidden, like the administrative
+// shares, less the excluded ones.
+func (s *Source) shares(sess session) ([]string, error) {
+	names := s.conn.GetShares()
+	if len(names) == 0 {
+		all, err := sess.shareNames()
+		if err != nil {
+			return nil, fmt.Errorf("error listing shares: %w", err)
+		}
+		for _, name := range all {
+			if !strings.HasSuffix(name, "$") {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var shares []string
+	for _, name := range names {
+		if !s.excludeShares.Match(name) {
+			shares = append(shares, name)
+		}
+	}
+	return shares, nil
+}
+
+// scanShare scans the files of a share.
+func (s *Source) scanShare(ctx context.Context, sess session, name string, chunksChan chan *sources.Chunk) error {
+	sh, err := sess.mount(name)
+	if err != nil {
+		return fmt.Errorf("error mounting share: %w", err)
+	}
+	defer sh.umount()
+
+	files, err := s.files(ctx, sh, name)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if common.IsDone(ctx) {
+			break
+		}
+		f := f
+		s.jobPool.Go(func() error {
+			if err := s.scanFile(ctx, sh, f, chunksChan); err != nil {
+				ctx.Logger().Error(err, "could not scan file", "share", f.share, "file", f.path)
+				sources.ReportSkipBytes(ctx, s.link(f.share, f.path), sources.SkipReasonError, f.info.Size())
+			}
+			return nil
+		})
+	}
+	return s.jobPool.Wait()
+}
+
+// files returns the regular files of a share that the globs don't filter
+// out. Directories that can't be read are skipped, except the root of the
+// share.
+func (s *Source) files(ctx context.Context, sh share, name string) ([]file, error) {
+	var files []file
+	dirs := []string{""}
+	for len(dirs) > 0 {
+		if common.IsDone(ctx) {
+			return nil, ctx.Err()
+		}
+		dir := dirs[len(dirs)-1]
+		dirs = dirs[:len(dirs)-1]
+
+		infos, err := sh.readDir(dir)
+		if err != nil {
+			if dir == "" {
+				return nil, fmt.Errorf("error listing share: %w", err)
+			}
+			ctx.Logger().Error(err, "could not list directory", "share", name, "dir", dir)
+			sources.ReportSkip(ctx, s.link(name, dir), sources.SkipReasonError)
+			continue
+		}
+
+		for _, info := range infos {
+			f := file{share: name, path: path.Join(dir, info.Name()), info: info}
+			fullPath := name + "/" + f.path
+			if s.exclude.Match(fullPath) {
+				sources.ReportSkipBytes(ctx, s.link(name, f.path), sources.SkipReasonFiltered, info.Size())
+				continue
+			}
+			// Links and junctions are not followed, as they could loop.
+			switch {
+			case info.IsDir():
+				dirs = append(dirs, f.path)
+			case !info.Mode().IsRegular():
+			case len(s.include) > 0 && !s.include.Match(fullPath):
+				sources.ReportSkipBytes(ctx, s.link(name, f.path), sources.SkipReasonFiltered, info.Size())
+			default:
+				files = append(files, f)
+			}
+		}
+	}
+	return files, nil
+}
+
+// scanFile scans a file through the handlers of archives.
+func (s *Source) scanFile(ctx context.Context, sh share, f file, chunksChan chan *sources.Chunk) error {
+	link := s.link(f.share, f.path)
+	switch size := f.info.Size(); {
+	case size > s.maxObjectSize:
+		sources.ReportSkipBytes(ctx, link, sources.SkipReasonSize, size)
+		return nil
+	case size == 0:
+		sources.ReportSkip(ctx, link, sources.SkipReasonEmpty)
+		return nil
+	case common.SkipFile(f.path):
+		sources.ReportSkipBytes(ctx, link, sources.SkipReasonUnsupported, size)
+		return nil
+	}
+
+	body, err := sh.open(f.path)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	reader, err := diskbufferreader.New(io.LimitReader(body, s.maxObjectSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	skel := s.chunkSkeleton(f)
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+
+	chunkReader := sources.NewChunkReader(s.archiveOptions.ChunkOptions()...)
+	for data := range chunkReader(ctx, reader) {
+		if err := data.Error(); err != nil {
+			return err
+		}
+		chunk := *skel
+		chunk.Data = data.Bytes()
+		chunk.Offset, chunk.LineStart, chunk.LineOffset = data.Offset(), data.LineStart(), data.LineOffset()
+		if err := common.CancellableWrite(ctx, chunksChan, &chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Source) chunkSkeleton(f file) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Smb{
+				Smb: &source_metadatapb.SMB{
+					File:      sanitizer.UTF8(f.path),
+					Link:      sanitizer.UTF8(s.link(f.share, f.path)),
+					Share:     sanitizer.UTF8(f.share),
+					Host:      s.host,
+					Timestamp: f.info.ModTime().UTC().Format(time.RFC3339),
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// link returns the UNC path of a path of a share.
+func (s *Source) link(shareName, p string) string {
+	link := `\\` + s.host + `\` + shareName
+	if p != "" {
+		link += `\` + strings.ReplaceAll(p, "/", `\`)
+	}
+	return link
+}
+
+// smbSession is a session of go-smb2.
+type smbSession struct {
+	ctx     context.Context
+	session *smb2.Session
+	conn    net.Conn
+}
+
+func dialSMB(ctx context.Context, addr string, initiator smb2.Initiator) (*smbSession, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+	conn, err := common.DialContext(dialCtx, "tcp", addr)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	d := &smb2.Dialer{Initiator: initiator}
+	sess, err := d.DialContext(ctx, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &smbSession{ctx: ctx, session: sess.WithContext(ctx), conn: conn}, nil
+}
+
+func (s *smbSession) shareNames() ([]string, error) {
+	return s.session.ListSharenames()
+}
+
+func (s *smbSession) mount(name string) (share, error) {
+	sh, err := s.session.Mount(name)
+	if err != nil {
+		return nil, err
+	}
+	return &smbShare{share: sh.WithContext(s.ctx)}, nil
+}
+
+func (s *smbSession) close() error {
+	_ = s.session.Logoff()
+	return s.conn.Close()
+}
+
+type smbShare struct {
+	share *smb2.Share
+}
+
+func (s *smbShare) readDir(dir string) ([]os.FileInfo, error) {
+	return s.share.ReadDir(dir)
+}
+
+func (s *smbShare) open(name string) (io.ReadCloser, error) {
+	return s.share.Open(name)
+}
+
+func (s *smbShare) umount() error {
+	return s.share.Umount()
+}
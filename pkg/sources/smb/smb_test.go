@@ -0,0 +1,156 @@
+package smb
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+var modified = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// fakeSession is a session with a server of shares of files.
+type fakeSession map[string]fstest.MapFS
+
+func (s fakeSession) shareNames() ([]string, error) {
+	return []string{"Finance", "IPC$", "C$", "Public"}, nil
+}
+
+func (s fakeSession) mount(name string) (share, error) {
+	fsys, ok := s[name]
+	if !ok {
+		return nil, errors.New("access denied")
+	}
+	return fakeShare{fsys}, nil
+}
+
+func (s fakeSession) close() error { return nil }
+
+type fakeShare struct {
+	fsys fstest.MapFS
+}
+
+func (s fakeShare) readDir(dir string) ([]os.FileInfo, error) {
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := fs.ReadDir(s.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (s fakeShare) open(name string) (io.ReadCloser, error) {
+	return s.fsys.Open(name)
+}
+
+func (s fakeShare) umount() error { return nil }
+
+func testSession() fakeSession {
+	file := func(data string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(data), ModTime: modified}
+	}
+	return fakeSession{
+		"Finance": {
+			"payroll/db.config":        file("password=hunter2"),
+			"payroll/empty.txt":        file(""),
+			"payroll/cache/tmp.txt":    file("cached"),
+			"readme.txt":               file("hello"),
+			"payroll/link":             &fstest.MapFile{Mode: fs.ModeSymlink},
+			"payroll/cache/nested/key": file("AKIA"),
+		},
+		"Public": {"setup.ps1": file("$token = 'secret'")},
+		"C$":     {"Windows/win.ini": file("admin")},
+	}
+}
+
+// chunks returns the data of the chunks of a source by link, and their
+// metadata.
+func chunks(t *testing.T, conn *sourcespb.SMB) (map[string]string, map[string]*source_metadatapb.SMB) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+	s.dial = func(context.Context) (session, error) { return testSession(), nil }
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.SMB)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetSmb()
+		got[m.GetLink()] += string(chunk.Data)
+		metadata[m.GetLink()] = m
+	}
+	return got, metadata
+}
+
+func TestSource_Chunks(t *testing.T) {
+	got, metadata := chunks(t, &sourcespb.SMB{
+		Host:         "fs01.corp.example.com",
+		Username:     "ada",
+		Password:     "pw",
+		ExcludeGlobs: []string{"**/cache"},
+	})
+	assert.Equal(t, map[string]string{
+		`\\fs01.corp.example.com\Finance\payroll\db.config`: "password=hunter2",
+		`\\fs01.corp.example.com\Finance\readme.txt`:        "hello",
+		`\\fs01.corp.example.com\Public\setup.ps1`:          "$token = 'secret'",
+	}, got)
+	assert.Equal(t, &source_metadatapb.SMB{
+		File:      "payroll/db.config",
+		Link:      `\\fs01.corp.example.com\Finance\payroll\db.config`,
+		Share:     "Finance",
+		Host:      "fs01.corp.example.com",
+		Timestamp: "2024-03-01T10:00:00Z",
+	}, metadata[`\\fs01.corp.example.com\Finance\payroll\db.config`])
+}
+
+func TestSource_ChunksShares(t *testing.T) {
+	// Hidden shares are scanned when they are listed.
+	got, _ := chunks(t, &sourcespb.SMB{
+		Host:         "10.0.0.5:4455",
+		Username:     "ada",
+		NtlmHash:     "8846f7eaee8fb117ad06bdd830b7586c",
+		Shares:       []string{"C$", "Finance", "Missing"},
+		IncludeGlobs: []string{"C$/**.ini", "Finance/payroll/**"},
+	})
+	assert.Equal(t, map[string]string{
+		`\\10.0.0.5:4455\C$\Windows\win.ini`:               "admin",
+		`\\10.0.0.5:4455\Finance\payroll\db.config`:        "password=hunter2",
+		`\\10.0.0.5:4455\Finance\payroll\cache\tmp.txt`:    "cached",
+		`\\10.0.0.5:4455\Finance\payroll\cache\nested\key`: "AKIA",
+	}, got)
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.SMB{
+		"no host":      {Username: "ada"},
+		"no username":  {Host: "fs01"},
+		"invalid hash": {Host: "fs01", Username: "ada", NtlmHash: "8846"},
+		"invalid glob": {Host: "fs01", Username: "ada", IncludeGlobs: []string{"[a"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
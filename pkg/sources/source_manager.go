@@ -43,6 +43,10 @@ type SourceManager struct {
 	outputChunks chan *Chunk
 	// Set when Wait() returns.
 	done bool
+	// Optional reporter for items the sources did not scan.
+	skipReporter SkipReporter
+	// Set to report the targets of sources instead of scanning them.
+	dryRun TargetReporter
 }
 
 // apiClient is an interface for optionally communicating with an external API.
@@ -65,6 +69,12 @@ func WithReportHook(hook JobProgressHook) func(*SourceManager) {
 	}
 }
 
+// WithSkipReporter sets a SkipReporter that is notified about items the
+// running sources, or the handlers they use, did not scan.
+func WithSkipReporter(reporter SkipReporter) func(*SourceManager) {
+	return func(mgr *SourceManager) { mgr.skipReporter = reporter }
+}
+
 // WithConcurrentSources limits the concurrent number of sources a manager can run.
 func WithConcurrentSources(concurrency int) func(*SourceManager) {
 	return func(mgr *SourceManager) { mgr.pool.SetLimit(concurrency) }
@@ -226,10 +236,25 @@ func (s *SourceManager) run(ctx context.Context, handle handle, jobID int64, rep
 		report.ReportError(Fatal{err})
 		return Fatal{err}
 	}
+	report.setSource(sourceInfo.name, source)
 	ctx = context.WithValues(ctx,
 		"source_type", source.Type().String(),
 		"source_name", sourceInfo.name,
 	)
+	if s.skipReporter != nil {
+		ctx = context.WithValue(ctx, skipReporterKey{}, skipReporterValue{
+			reporter:   s.skipReporter,
+			sourceName: sourceInfo.name,
+			sourceType: source.Type(),
+		})
+	}
+	if s.dryRun != nil {
+		if err := s.runDryRun(ctx, sourceInfo.name, source); err != nil {
+			report.ReportError(Fatal{err})
+			return Fatal{err}
+		}
+		return nil
+	}
 	// Check for the preferred method of tracking source units.
 	if enumChunker, ok := source.(SourceUnitEnumChunker); ok && s.useSourceUnits {
 		return s.runWithUnits(ctx, handle, enumChunker, report)
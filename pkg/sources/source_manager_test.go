@@ -245,3 +245,70 @@ func TestSourceManagerContextCancelled(t *testing.T) {
 	report := ref.Snapshot()
 	assert.Error(t, report.FatalError())
 }
+
+// targetCollector implements TargetReporter.
+type targetCollector struct{ targets []Target }
+
+func (c *targetCollector) ReportTarget(t Target) { c.targets = append(c.targets, t) }
+
+// dryRunSource is a DummySource that implements DryRunner.
+type dryRunSource struct{ DummySource }
+
+func (d *dryRunSource) EnumerateTargets(_ context.Context, report func(Target)) error {
+	report(Target{Name: "bucket", Objects: 2, Bytes: 10})
+	return nil
+}
+
+func TestSourceManagerDryRun(t *testing.T) {
+	targets := &targetCollector{}
+	mgr := NewManager(WithBufferedOutput(8), WithDryRun(targets))
+	handle, err := enrollDummy(mgr, &counterChunker{count: 1})
+	assert.NoError(t, err)
+	_, err = mgr.Run(context.Background(), handle)
+	assert.NoError(t, err)
+
+	handle, err = mgr.Enroll(context.Background(), "dry", 1337,
+		func(ctx context.Context, jobID, sourceID int64) (Source, error) {
+			return &dryRunSource{DummySource{chunker: &counterChunker{count: 1}}}, nil
+		})
+	assert.NoError(t, err)
+	_, err = mgr.Run(context.Background(), handle)
+	assert.NoError(t, err)
+
+	// Nothing is scanned.
+	_, err = tryRead(mgr.Chunks())
+	assert.Error(t, err)
+	assert.Equal(t, []Target{
+		{SourceName: "dummy", SourceType: 1337, Name: "dummy", Objects: -1, Bytes: -1},
+		{SourceName: "dry", SourceType: 1337, Name: "bucket", Objects: 2, Bytes: 10},
+	}, targets.targets)
+}
+
+// progressSource reports its progress through two targets.
+type progressSource struct {
+	DummySource
+	Progress
+}
+
+func (p *progressSource) GetProgress() *Progress { return &p.Progress }
+
+func (p *progressSource) Chunks(ctx context.Context, ch chan *Chunk) error {
+	p.SetProgressComplete(1, 2, "first", "")
+	return nil
+}
+
+func TestSourceManagerSourceProgress(t *testing.T) {
+	mgr := NewManager()
+	handle, err := mgr.Enroll(context.Background(), "progress", 1337,
+		func(ctx context.Context, jobID, sourceID int64) (Source, error) {
+			return &progressSource{}, nil
+		})
+	assert.NoError(t, err)
+	ref, err := mgr.Run(context.Background(), handle)
+	assert.NoError(t, err)
+
+	metrics := ref.Snapshot()
+	assert.Equal(t, "progress", metrics.SourceName)
+	assert.Equal(t, int32(1), metrics.SectionsCompleted)
+	assert.Equal(t, int32(2), metrics.SectionsTotal)
+}
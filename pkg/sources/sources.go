@@ -2,11 +2,13 @@ package sources
 
 import (
 	"sync"
+	"time"
 
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 )
@@ -24,8 +26,27 @@ type Chunk struct {
 
 	// Data is the data to decode and scan.
 	Data []byte
+	// Offset is the offset of Data in the file or object it was read from,
+	// if known.
+	Offset int64
+	// LineStart is the offset of the start of the line Data begins in, which
+	// is before Offset if Data begins in the middle of a line.
+	LineStart int64
+	// LineOffset is the number of lines before Data in the file or object it
+	// was read from. It is added to the line of the SourceMetadata, which is
+	// the line the file starts at for sources that chunk whole files.
+	LineOffset int64
 	// Verify specifies whether any secrets in the Chunk should be verified.
 	Verify bool
+	// IgnoreRules are the rules of the ignore file of the repository or
+	// directory the Chunk was found in, if any.
+	IgnoreRules IgnoreRules
+}
+
+// IgnoreRules decides whether findings of a detector in a file are ignored,
+// as the rules of an ignore file do.
+type IgnoreRules interface {
+	Ignores(path string, detector detectorspb.DetectorType) bool
 }
 
 // Source defines the interface required to implement a source chunker.
@@ -129,6 +150,20 @@ type GCSConfig struct {
 	IncludeObjects,
 	// ExcludeObjects is a list of objects to exclude from the scan.
 	ExcludeObjects []string
+	// ProjectIDs are the projects whose buckets to scan, besides ProjectID.
+	ProjectIDs []string
+	// AllProjects scans the buckets of all the projects of the credential.
+	AllProjects bool
+	// OrganizationID scans the buckets of an organization, listed with
+	// Cloud Asset Inventory.
+	OrganizationID string
+	// IncludeGenerations scans the noncurrent generations of objects too.
+	IncludeGenerations bool
+	// StatePath is the file of the objects scanned, which resumes the next
+	// scans.
+	StatePath string
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
 }
 
 // GitConfig defines the optional configuration for a git source.
@@ -148,6 +183,15 @@ type GitConfig struct {
 	// ExcludeGlobs is a list of globs to exclude from the scan.
 	// This differs from the Filter exclusions as ExcludeGlobs is applied at the `git log -p` level
 	ExcludeGlobs []string
+	// StagedOnly scans only the changes staged for commit, e.g. in a pre-commit hook.
+	StagedOnly bool
+	// Revisions limits the scan to these git log revision arguments instead of
+	// HeadRef, e.g. to scan only the pushed commits in a pre-receive hook.
+	Revisions []string
+	// DiffRange scans only the lines added between two refs, e.g. `main..HEAD`.
+	DiffRange string
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
 }
 
 // GithubConfig defines the optional configuration for a github source.
@@ -172,6 +216,8 @@ type GithubConfig struct {
 	IncludeRepos []string
 	// Filter is the filter to use to scan the source.
 	Filter *common.Filter
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
 }
 
 // GitlabConfig defines the optional configuration for a gitlab source.
@@ -184,6 +230,84 @@ type GitlabConfig struct {
 	Repos []string
 	// Filter is the filter to use to scan the source.
 	Filter *common.Filter
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// BitbucketConfig defines the optional configuration for a Bitbucket source.
+type BitbucketConfig struct {
+	// Endpoint is the URL of Bitbucket Server, or empty for Bitbucket Cloud.
+	Endpoint,
+	// Username is the username of the app password or password.
+	Username,
+	// Token is the app password if Username is set, or an access token.
+	Token string
+	// OAuthClientID, OAuthClientSecret and OAuthRefreshToken authenticate
+	// with OAuth, whose access tokens are refreshed with the refresh token.
+	OAuthClientID,
+	OAuthClientSecret,
+	OAuthRefreshToken string
+	// Workspaces is the list of the workspaces of Bitbucket Cloud to scan.
+	Workspaces,
+	// Projects is the list of the keys of the projects to scan.
+	Projects,
+	// Repos is the list of the clone URLs of the repositories to scan.
+	Repos,
+	// ExcludeRepos is the list of the globs of the workspace/repo or
+	// project/repo names of the repositories not to scan.
+	ExcludeRepos []string
+	// SkipPullRequests does not scan pull requests.
+	SkipPullRequests,
+	// SkipPipelineVariables does not scan the variables of pipelines.
+	SkipPipelineVariables bool
+	// Filter is the filter to use to scan the source.
+	Filter *common.Filter
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// AzureDevOpsConfig defines the optional configuration for an Azure DevOps
+// source.
+type AzureDevOpsConfig struct {
+	// Endpoint is the URL of Azure DevOps Server, or empty for Azure DevOps
+	// Services.
+	Endpoint,
+	// Token is a personal access token, or the access token of OAuth.
+	Token string
+	// OAuthClientID, OAuthClientSecret and OAuthRefreshToken authenticate
+	// with OAuth, whose access tokens are refreshed with the refresh token.
+	OAuthClientID,
+	OAuthClientSecret,
+	OAuthRefreshToken string
+	// Organizations is the list of the organizations, or collections of
+	// Azure DevOps Server, to scan.
+	Organizations,
+	// Projects is the list of the names of the projects to scan.
+	Projects,
+	// Repos is the list of the clone URLs of the repositories to scan.
+	Repos,
+	// IncludeProjects and ExcludeProjects are the lists of the globs of
+	// the organization/project names of the projects to scan or not.
+	IncludeProjects,
+	ExcludeProjects,
+	// IncludeRepos and ExcludeRepos are the lists of the globs of the
+	// organization/project/repo names of the repositories to scan or not.
+	IncludeRepos,
+	ExcludeRepos []string
+	// IncludeForks scans the forks of the projects too.
+	IncludeForks,
+	// SkipPipelines does not scan the YAML and variables of pipelines.
+	SkipPipelines,
+	// SkipVariableGroups does not scan variable groups.
+	SkipVariableGroups,
+	// SkipWikis does not scan the pages of project wikis.
+	SkipWikis,
+	// SkipWorkItems does not scan the comments of work items.
+	SkipWorkItems bool
+	// Filter is the filter to use to scan the source.
+	Filter *common.Filter
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
 }
 
 // FilesystemConfig defines the optional configuration for a filesystem source.
@@ -192,6 +316,11 @@ type FilesystemConfig struct {
 	Paths []string
 	// Filter is the filter to use to scan the source.
 	Filter *common.Filter
+	// BrowserStorage decodes the LevelDB and SQLite files of browser
+	// profiles before scanning them.
+	BrowserStorage bool
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
 }
 
 // S3Config defines the optional configuration for an S3 source.
@@ -209,6 +338,614 @@ type S3Config struct {
 	Buckets []string
 	// MaxObjectSize is the maximum object size to scan.
 	MaxObjectSize int64
+	// IncludeVersions scans the noncurrent versions of objects too.
+	IncludeVersions bool
+	// RequesterPays accepts the charges of the requests to requester-pays
+	// buckets.
+	RequesterPays bool
+	// RoleChains are the chains of roles assumed with the credentials, whose
+	// last roles' buckets are scanned instead of those of the credentials.
+	RoleChains [][]AWSRole
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// AWSRole is a role assumed by an AWS source.
+type AWSRole struct {
+	// ARN is the ARN of the role.
+	ARN,
+	// ExternalID is the external ID that the trust policy of the role
+	// requires, if any.
+	ExternalID string
+}
+
+// AzureBlobConfig defines the optional configuration for an Azure Blob
+// Storage source.
+type AzureBlobConfig struct {
+	// Account is the name of the storage account.
+	Account,
+	// Endpoint is the URL of the blob service, which defaults to that of the
+	// account.
+	Endpoint,
+	// ConnectionString is a connection string of the account.
+	ConnectionString,
+	// AccountKey is the key of the account.
+	AccountKey,
+	// SASToken is a shared access signature of the account or a container.
+	SASToken string
+	// TenantID, ClientID and ClientSecret authenticate as a service
+	// principal.
+	TenantID,
+	ClientID,
+	ClientSecret string
+	// ManagedIdentity authenticates as the managed identity of the Azure
+	// resource, or the user-assigned one of ManagedIdentityClientID.
+	ManagedIdentity         bool
+	ManagedIdentityClientID string
+	// Containers is the list of containers to scan.
+	Containers,
+	// Prefixes is the list of the prefixes of the names of the blobs to
+	// scan, and ExcludePrefixes of those not to scan.
+	Prefixes,
+	ExcludePrefixes []string
+	// IncludeSnapshots and IncludeVersions scan the snapshots and previous
+	// versions of blobs too.
+	IncludeSnapshots,
+	IncludeVersions bool
+	// MaxObjectSize is the maximum blob size to scan.
+	MaxObjectSize int64
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// GoogleDriveConfig defines the optional configuration for a Google Drive
+// source.
+type GoogleDriveConfig struct {
+	// ServiceAccountFile is the path of the JSON key of a service account.
+	ServiceAccountFile,
+	// ImpersonateUser is the email of the user the service account acts as
+	// with domain-wide delegation.
+	ImpersonateUser string
+	// ClientID, ClientSecret and RefreshToken authenticate as a user through
+	// an OAuth client.
+	ClientID,
+	ClientSecret,
+	RefreshToken string
+	// UseADC authenticates with the application default credentials.
+	UseADC bool
+	// DriveIDs is the list of the shared drives to scan, instead of all the
+	// files the credentials can access.
+	DriveIDs []string
+	// MaxObjectSize is the maximum file size to scan.
+	MaxObjectSize int64
+	// StatePath is the file of the change tokens of the last scan, whose next
+	// scans only scan the files that changed since.
+	StatePath string
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// FTPConfig defines the optional configuration for an FTP source.
+type FTPConfig struct {
+	// URL is the server and the directory to scan, as an sftp://, ftp:// or
+	// ftps:// URL.
+	URL string
+	// Username and Password override those of the URL.
+	Username,
+	Password string
+	// PrivateKeyFile is the path of a private key to log in to SFTP servers
+	// with, which is decrypted with PrivateKeyPassphrase.
+	PrivateKeyFile,
+	PrivateKeyPassphrase string
+	// KnownHosts is the file of the keys of the SFTP servers to trust.
+	KnownHosts string
+	// Insecure skips the verification of the keys and certificates of
+	// servers.
+	Insecure bool
+	// IncludeGlobs and ExcludeGlobs filter the paths of the files scanned.
+	IncludeGlobs,
+	ExcludeGlobs []string
+	// MaxConnections is the maximum number of connections to the server.
+	MaxConnections int
+	// MaxObjectSize is the maximum file size to scan.
+	MaxObjectSize int64
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// SMBConfig defines the optional configuration for an SMB source.
+type SMBConfig struct {
+	// Host is the server, with its port if it is not 445.
+	Host string
+	// Username, Password and Domain log in to the server with NTLM. NTLMHash
+	// is the NT hash of the password, in hex, to log in with instead.
+	Username,
+	Password,
+	NTLMHash,
+	Domain string
+	// Shares is the list of the shares to scan, instead of all the shares of
+	// the server but the hidden ones.
+	Shares []string
+	// ExcludeShares are globs of the shares not to scan.
+	ExcludeShares []string
+	// IncludeGlobs and ExcludeGlobs filter the share/path of the files
+	// scanned.
+	IncludeGlobs,
+	ExcludeGlobs []string
+	// MaxObjectSize is the maximum file size to scan.
+	MaxObjectSize int64
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// KubernetesConfig defines the optional configuration for a Kubernetes
+// source.
+type KubernetesConfig struct {
+	// Kubeconfig is the kubeconfig file of the cluster, ~/.kube/config if it
+	// is empty, and Context the context of the file to use instead of its
+	// current context.
+	Kubeconfig,
+	Context string
+	// InCluster connects to the cluster the scan runs in, as the service
+	// account of its pod, instead of with a kubeconfig.
+	InCluster bool
+	// Namespaces is the list of the namespaces to scan, instead of all of
+	// them and the cluster-scoped custom resources.
+	Namespaces []string
+	// ExcludeNamespaces are globs of the namespaces not to scan.
+	ExcludeNamespaces []string
+	// SkipSecrets, SkipConfigMaps, SkipPods and SkipCustomResources skip
+	// the objects of these kinds.
+	SkipSecrets,
+	SkipConfigMaps,
+	SkipPods,
+	SkipCustomResources bool
+}
+
+// ConsulConfig defines the optional configuration for a Consul source.
+type ConsulConfig struct {
+	// Address is the URL of the HTTP API of an agent, and Token the ACL
+	// token to read the keys with.
+	Address,
+	Token string
+	// Datacenter is the datacenter of the keys, that of the agent if it is
+	// empty.
+	Datacenter string
+	// Prefix is the prefix of the keys to scan.
+	Prefix string
+	// ExcludeGlobs are globs of the keys not to scan.
+	ExcludeGlobs []string
+	// CACertFile is the path of the certificate authority of the agent.
+	CACertFile string
+	// Insecure skips the verification of the certificate of the agent.
+	Insecure bool
+}
+
+// EtcdConfig defines the optional configuration for an etcd source.
+type EtcdConfig struct {
+	// Endpoint is the URL of the client API of a member of the cluster.
+	Endpoint string
+	// Username and Password authenticate with the auth of etcd.
+	Username,
+	Password string
+	// Prefix is the prefix of the keys to scan.
+	Prefix string
+	// ExcludeGlobs are globs of the keys not to scan.
+	ExcludeGlobs []string
+	// CertFile and KeyFile are the paths of the client certificate and key
+	// to authenticate with TLS, and CACertFile the path of the certificate
+	// authority of the member.
+	CertFile,
+	KeyFile,
+	CACertFile string
+	// Insecure skips the verification of the certificate of the member.
+	Insecure bool
+}
+
+// ElasticsearchConfig defines the optional configuration for an
+// Elasticsearch source.
+type ElasticsearchConfig struct {
+	// URL is the URL of the HTTP API of an Elasticsearch or OpenSearch
+	// cluster.
+	URL string
+	// Username and Password authenticate with basic auth, and APIKey, the
+	// base64 id:key of an Elasticsearch API key, instead of them.
+	Username,
+	Password,
+	APIKey string
+	// Indices are the names or patterns of the indices to scan, instead of
+	// all the open indices but the system ones.
+	Indices []string
+	// IncludeFields are the fields of the documents to scan, instead of all
+	// of them.
+	IncludeFields []string
+	// TimestampField is the field of the time of the documents that Since
+	// and Until filter, @timestamp if it is empty. They are dates or date
+	// math like now-7d.
+	TimestampField,
+	Since,
+	Until string
+	// CACertFile is the path of the certificate authority of the cluster.
+	CACertFile string
+	// Insecure skips the verification of the certificate of the cluster.
+	Insecure bool
+}
+
+// DatabaseConfig defines the optional configuration for a database source.
+type DatabaseConfig struct {
+	// DSN is the URL of a Postgres, MySQL, SQL Server or SQLite database.
+	DSN string
+	// IncludeGlobs and ExcludeGlobs filter the tables and columns scanned,
+	// which they match as schema.table and schema.table.column.
+	IncludeGlobs,
+	ExcludeGlobs []string
+	// BatchSize is the number of rows read at once from the tables with a
+	// primary key of one column.
+	BatchSize int64
+}
+
+// MongoDBConfig defines the optional configuration for a MongoDB source.
+type MongoDBConfig struct {
+	// URI is the connection string of the deployment.
+	URI string
+	// Databases are the databases to scan, instead of all of them but admin,
+	// config and local.
+	Databases []string
+	// IncludeGlobs and ExcludeGlobs filter the collections scanned, which
+	// they match as database.collection.
+	IncludeGlobs,
+	ExcludeGlobs []string
+	// BatchSize is the number of documents of the batches of the cursors.
+	BatchSize int32
+}
+
+// RedisConfig defines the optional configuration for a Redis source.
+type RedisConfig struct {
+	// URI is the redis:// or rediss:// URL of the server.
+	URI string
+	// Databases are the numbers of the databases to scan, instead of all
+	// those with keys.
+	Databases []int32
+	// KeyPatterns are the SCAN MATCH patterns of the keys to scan, and
+	// ExcludeGlobs the globs of the keys to skip.
+	KeyPatterns,
+	ExcludeGlobs []string
+	// Insecure skips the verification of the certificate of the server.
+	Insecure bool
+}
+
+// ArtifactoryConfig defines the optional configuration for an Artifactory
+// source.
+type ArtifactoryConfig struct {
+	// Endpoint is the URL of Artifactory.
+	Endpoint string
+	// Username and Password authenticate with basic auth, and AccessToken
+	// instead of them.
+	Username,
+	Password,
+	AccessToken string
+	// Repositories are the globs of the keys of the repositories to scan.
+	Repositories []string
+	// IncludePaths and ExcludePaths are the globs of the paths of the
+	// artifacts to scan and to skip.
+	IncludePaths,
+	ExcludePaths []string
+	// MaxObjectSize is the maximum artifact size to scan.
+	MaxObjectSize int64
+	// StatePath is the file of the checksums of the artifacts scanned, for
+	// scans to skip the artifacts that did not change.
+	StatePath string
+	// Insecure skips the verification of the certificate of Artifactory.
+	Insecure bool
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// NexusConfig defines the optional configuration for a Nexus source.
+type NexusConfig struct {
+	// Endpoint is the URL of Nexus.
+	Endpoint string
+	// Username and Password authenticate with basic auth.
+	Username,
+	Password string
+	// Repositories are the globs of the names of the repositories to scan.
+	Repositories []string
+	// IncludePaths and ExcludePaths are the globs of the paths of the
+	// assets to scan and to skip.
+	IncludePaths,
+	ExcludePaths []string
+	// MaxObjectSize is the maximum asset size to scan.
+	MaxObjectSize int64
+	// StatePath is the file of the checksums of the assets scanned, for
+	// scans to skip the assets that did not change.
+	StatePath string
+	// Insecure skips the verification of the certificate of Nexus.
+	Insecure bool
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// RegistryConfig defines the optional configuration for a container
+// registry source.
+type RegistryConfig struct {
+	// Registry is the host of the registry, like ghcr.io.
+	Registry string
+	// Username and Password authenticate with basic auth, and BearerToken
+	// instead of them.
+	Username,
+	Password,
+	BearerToken string
+	// DockerKeychain authenticates with the credentials of the Docker
+	// config, and CloudCredentials with the default AWS or Google
+	// credentials for ECR, GCR and Artifact Registry.
+	DockerKeychain,
+	CloudCredentials bool
+	// Repositories are the names or the globs of the names of the
+	// repositories to scan, and ExcludeRepositories the globs of those to
+	// skip.
+	Repositories,
+	ExcludeRepositories []string
+	// Tags are the globs of the tags to scan.
+	Tags []string
+	// MaxObjectSize is the maximum size of the files of images to scan.
+	MaxObjectSize int64
+	// StatePath is the file of the digests of the layers scanned, for scans
+	// to skip the layers that did not change.
+	StatePath string
+	// Insecure connects with plain HTTP, or skips the verification of the
+	// certificate of the registry.
+	Insecure bool
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// CloudLogsConfig defines the optional configuration for a log platform
+// source.
+type CloudLogsConfig struct {
+	// Platform is the log platform to scan: cloudwatch, cloud-logging or
+	// azure-monitor.
+	Platform string
+	// Region is the AWS region of CloudWatch Logs, and Key, Secret and
+	// SessionToken its credentials, instead of the default AWS credentials.
+	Region,
+	Key,
+	Secret,
+	SessionToken string
+	// ProjectID is the Google Cloud project of Cloud Logging, and
+	// ServiceAccountFile its credentials, instead of the application default
+	// credentials.
+	ProjectID,
+	ServiceAccountFile string
+	// WorkspaceID is the Log Analytics workspace of Azure Monitor, and
+	// TenantID, ClientID and ClientSecret the service principal to
+	// authenticate as. ManagedIdentity authenticates as the managed identity
+	// of ClientID instead, or the system-assigned one if it is empty.
+	WorkspaceID,
+	TenantID,
+	ClientID,
+	ClientSecret string
+	ManagedIdentity bool
+	// LogGroups are the names or the globs of the names of the log groups,
+	// logs or tables to scan. All of them are scanned if it is empty.
+	LogGroups []string
+	// Since and Until only scan the events logged between them, if they are
+	// set.
+	Since,
+	Until time.Time
+	// Filter is a filter pattern of CloudWatch Logs, a query of the logging
+	// query language, or a KQL predicate, that events must match.
+	Filter string
+	// BatchSize is the number of events fetched by request.
+	BatchSize int
+	// Endpoint overrides the endpoint of the API of the platform.
+	Endpoint string
+}
+
+// SplunkConfig defines the optional configuration for a Splunk source.
+type SplunkConfig struct {
+	// Endpoint is the URL of the REST API of Splunk, like
+	// https://splunk.example.com:8089.
+	Endpoint string
+	// Username and Password authenticate with basic auth, and Token, a
+	// Splunk authentication token, instead of them.
+	Username,
+	Password,
+	Token string
+	// Search is an ad-hoc SPL search, and SavedSearch the name of a saved
+	// search to run instead. The events of all the indices are searched if
+	// both are empty.
+	Search,
+	SavedSearch string
+	// App and Owner are the namespace the search runs in.
+	App,
+	Owner string
+	// Earliest and Latest are times or time modifiers like -24h@h, which
+	// bound the events searched.
+	Earliest,
+	Latest string
+	// CACertFile is the path of the certificate authority of Splunk.
+	CACertFile string
+	// Insecure skips the verification of the certificate of Splunk.
+	Insecure bool
+}
+
+// KafkaConfig defines the optional configuration for a Kafka source.
+type KafkaConfig struct {
+	// Brokers are the host:port of the bootstrap brokers of the cluster.
+	Brokers []string
+	// Topics are the names or the globs of the names of the topics to scan,
+	// all the topics but the internal ones if it is empty.
+	Topics []string
+	// StartOffset is the offset of the partitions to start from, or the
+	// number of their last messages to scan if it is negative. Since starts
+	// from the first messages of that time instead, if it is set.
+	StartOffset int64
+	Since       time.Time
+	// SASLMechanism is plain, scram-sha-256 or scram-sha-512, to
+	// authenticate with Username and Password.
+	SASLMechanism,
+	Username,
+	Password string
+	// TLS connects to the brokers with TLS, and CACertFile is the path of
+	// their certificate authority. Insecure skips the verification of their
+	// certificates.
+	TLS        bool
+	CACertFile string
+	Insecure   bool
+	// Encoding is how the values of messages are deserialized: plain, json,
+	// avro, or auto to detect it from each message.
+	Encoding string
+	// SchemaRegistryURL is the URL of the schema registry of the messages
+	// of the Confluent wire format, and SchemaRegistryUsername and
+	// SchemaRegistryPassword its credentials.
+	SchemaRegistryURL,
+	SchemaRegistryUsername,
+	SchemaRegistryPassword string
+}
+
+// IMAPConfig defines the optional configuration for an IMAP source.
+type IMAPConfig struct {
+	// Address is the host:port of the server, with port 993 if it has none.
+	Address string
+	// Username logs in with Password, or authenticates with the OAuth2
+	// access token OAuth2Token with XOAUTH2, like Gmail and Office 365
+	// accounts do.
+	Username,
+	Password,
+	OAuth2Token string
+	// Folders are the names or globs of the names of the folders to scan,
+	// all the folders if it is empty, and ExcludeFolders those not to scan.
+	Folders,
+	ExcludeFolders []string
+	// Since only scans the messages received since then.
+	Since time.Time
+	// StartTLS connects without TLS and upgrades the connection with
+	// STARTTLS, and Plaintext connects without TLS at all. Insecure skips
+	// the verification of the certificate of the server.
+	StartTLS,
+	Plaintext,
+	Insecure bool
+	// MaxMessageSize is the size of the largest messages scanned.
+	MaxMessageSize int64
+	// ArchiveOptions configures how the attachments of messages are
+	// extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// HelpdeskConfig defines the optional configuration for a helpdesk source.
+type HelpdeskConfig struct {
+	// Platform is the helpdesk to scan: zendesk or freshdesk.
+	Platform string
+	// Endpoint is the URL of the account, e.g. https://acme.zendesk.com.
+	Endpoint string
+	// Email and APIToken authenticate to Zendesk, or OAuthToken does.
+	Email,
+	APIToken,
+	OAuthToken string
+	// APIKey authenticates to Freshdesk.
+	APIKey string
+	// UpdatedSince only scans the tickets updated since then.
+	UpdatedSince time.Time
+	// StatePath is the file of the time of the last update of the tickets
+	// of the last scan, whose next scans only scan the tickets updated
+	// since.
+	StatePath string
+	// SkipAttachments does not scan the attachments of tickets.
+	SkipAttachments bool
+	// ArchiveOptions configures how the archives of attachments are
+	// extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// ServiceNowConfig defines the optional configuration for a ServiceNow source.
+type ServiceNowConfig struct {
+	// Endpoint is the URL of the instance, e.g. https://acme.service-now.com.
+	Endpoint string
+	// Username and Password authenticate with basic auth, or OAuthToken
+	// does.
+	Username,
+	Password,
+	OAuthToken string
+	// Tables are the tables whose records are scanned, incident and
+	// change_request if empty.
+	Tables []string
+	// Query is an encoded query that selects the records to scan.
+	Query string
+	// UpdatedSince only scans the records updated since then.
+	UpdatedSince time.Time
+	// SkipAttachments does not scan the attachments of records.
+	SkipAttachments bool
+	// ArchiveOptions configures how the archives of attachments are
+	// extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// NotionConfig defines the optional configuration for a Notion source.
+type NotionConfig struct {
+	// Token is the secret of an internal integration, or an OAuth access
+	// token.
+	Token string
+	// SkipFiles does not scan the files of file blocks.
+	SkipFiles bool
+	// ArchiveOptions configures how the archives of files are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// TrackerConfig defines the optional configuration for a tracker source.
+type TrackerConfig struct {
+	// Platform is the project management tool to scan: trello, asana or
+	// linear.
+	Platform string
+	// TrelloAPIKey and TrelloToken authenticate to Trello.
+	TrelloAPIKey,
+	TrelloToken string
+	// AsanaToken authenticates to Asana.
+	AsanaToken string
+	// LinearAPIKey authenticates to Linear.
+	LinearAPIKey string
+	// Boards are the Trello boards to scan, all if empty.
+	Boards []string
+	// Projects are the Asana projects to scan, all if empty.
+	Projects []string
+	// Teams are the keys of the Linear teams to scan, all if empty.
+	Teams []string
+	// SkipAttachments does not scan the attachments of items.
+	SkipAttachments bool
+	// ArchiveOptions configures how the archives of attachments are
+	// extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// DiscordConfig defines the optional configuration for a Discord source.
+type DiscordConfig struct {
+	// Token is the token of the bot to scan as.
+	Token string
+	// Guilds are the IDs or names of the guilds to scan, all the guilds of
+	// the bot if empty.
+	Guilds []string
+	// Channels are the IDs or names of the channels to scan, all the
+	// channels the bot can read if empty.
+	Channels []string
+	// SkipAttachments does not scan the attachments of messages.
+	SkipAttachments bool
+	// ArchiveOptions configures how the archives of attachments are
+	// extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// GerritConfig defines the optional configuration for a Gerrit source.
+type GerritConfig struct {
+	// Endpoint is the URL of the server, e.g. https://gerrit.example.com.
+	Endpoint string
+	// Username and Password authenticate with the HTTP password of the
+	// user. Public projects are scanned anonymously without them.
+	Username,
+	Password string
+	// Projects are the projects to scan, all the projects of the user if
+	// empty.
+	Projects []string
+	// Query narrows down the changes scanned, e.g. status:open.
+	Query string
 }
 
 // SyslogConfig defines the optional configuration for a syslog source.
@@ -227,6 +964,187 @@ type SyslogConfig struct {
 	Concurrency int
 }
 
+// JiraConfig defines the optional configuration for a Jira source.
+type JiraConfig struct {
+	// Endpoint is the URL of the Jira site.
+	Endpoint,
+	// Username is the email of the account of the API token on Jira Cloud,
+	// or the username on Server. Token is a personal access token if it is
+	// empty.
+	Username,
+	// Token is the API token, password or personal access token to use to
+	// authenticate with the source.
+	Token string
+	// Projects is the list of the keys of the projects to scan.
+	Projects,
+	// ExcludeProjects is the list of the keys of the projects not to scan.
+	ExcludeProjects []string
+	// JQL selects the issues to scan.
+	JQL string
+	// UpdatedSince only scans the issues updated since then, if it is set.
+	UpdatedSince time.Time
+	// SkipAttachments does not scan the attachments of issues.
+	SkipAttachments bool
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// ConfluenceConfig defines the optional configuration for a Confluence source.
+type ConfluenceConfig struct {
+	// Endpoint is the URL of the Confluence site, with the /wiki path on
+	// Confluence Cloud.
+	Endpoint,
+	// Username is the email of the account of the API token on Confluence
+	// Cloud, or the username on Server. Token is a personal access token if
+	// it is empty.
+	Username,
+	// Token is the API token, password or personal access token to use to
+	// authenticate with the source.
+	Token string
+	// Spaces is the list of the keys of the spaces to scan.
+	Spaces,
+	// ExcludeSpaces is the list of the keys of the spaces not to scan.
+	ExcludeSpaces []string
+	// SkipHistory only scans the current version of pages.
+	SkipHistory,
+	// SkipAttachments does not scan the attachments of pages.
+	SkipAttachments,
+	// Insecure skips the verification of the certificate of the server.
+	Insecure bool
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// SlackConfig defines the optional configuration for a Slack source.
+type SlackConfig struct {
+	// Endpoint is the URL of the workspace, used for the links of the
+	// results of exports.
+	Endpoint,
+	// Token is the bot or user token to use to authenticate with the
+	// source.
+	Token,
+	// ExportPath is the path of the zip of a workspace export to scan
+	// instead of the history of the API.
+	ExportPath string
+	// Channels is the list of the names or IDs of the channels to scan.
+	Channels,
+	// ExcludeChannels is the list of the names or IDs of the channels not to
+	// scan.
+	ExcludeChannels []string
+	// Since and Until only scan the messages posted between them, if they
+	// are set.
+	Since,
+	Until time.Time
+	// SkipFiles does not scan the files uploaded to channels.
+	SkipFiles bool
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// TeamsConfig defines the optional configuration for a Microsoft Teams
+// source.
+type TeamsConfig struct {
+	// TenantID is the ID of the tenant of the app to authenticate as.
+	TenantID,
+	// ClientID is the ID of the app to authenticate as, with the app
+	// permissions of the tenant.
+	ClientID,
+	// ClientSecret is the secret of the app to authenticate as.
+	ClientSecret,
+	// Token is an access token of Microsoft Graph of a user, used if there
+	// is no app.
+	Token string
+	// Teams is the list of the IDs of the teams to scan.
+	Teams,
+	// Channels is the list of the names or IDs of the channels to scan.
+	Channels,
+	// ExcludeChannels is the list of the names or IDs of the channels not to
+	// scan.
+	ExcludeChannels []string
+	// SkipChats does not scan chats.
+	SkipChats,
+	// SkipFiles does not scan the files shared in channels and chats.
+	SkipFiles bool
+	// StatePath is the file of the state of the last scan, for scans to only
+	// scan what changed since.
+	StatePath string
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// SharePointConfig defines the optional configuration for a SharePoint and
+// OneDrive source.
+type SharePointConfig struct {
+	// TenantID is the ID of the tenant of the app to authenticate as.
+	TenantID,
+	// ClientID is the ID of the app to authenticate as, with the app
+	// permissions of the tenant.
+	ClientID,
+	// ClientSecret is the secret of the app to authenticate as.
+	ClientSecret,
+	// Token is an access token of Microsoft Graph of a user, used if there
+	// is no app.
+	Token string
+	// SiteURL is the URL of a site to scan.
+	SiteURL string
+	// Sites is the list of the glob patterns of the URLs of the sites to
+	// scan.
+	Sites,
+	// ExcludeSites is the list of the glob patterns of the URLs of the sites
+	// not to scan.
+	ExcludeSites,
+	// Users is the list of the user principal names of the OneDrive accounts
+	// to scan.
+	Users []string
+	// SkipOneDrive does not scan OneDrive accounts.
+	SkipOneDrive,
+	// SkipLists does not scan the items of lists and their attachments.
+	SkipLists bool
+	// MaxObjectSize is the maximum file size to scan.
+	MaxObjectSize int64
+	// StatePath is the file of the state of the last scan, for scans to only
+	// scan what changed since.
+	StatePath string
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// DropboxConfig defines the optional configuration for a Dropbox source.
+type DropboxConfig struct {
+	// Token is an access token of the team.
+	Token string
+	// AppKey, AppSecret and RefreshToken get access tokens of the team
+	// through an app instead.
+	AppKey,
+	AppSecret,
+	RefreshToken string
+	// Members is the list of the emails of the members whose home folders
+	// are scanned.
+	Members []string
+	// SkipTeamFolders does not scan team folders.
+	SkipTeamFolders,
+	// SkipMemberFolders does not scan the home folders of members.
+	SkipMemberFolders bool
+	// MaxObjectSize is the maximum file size to scan.
+	MaxObjectSize int64
+	// StatePath is the file of the state of the last scan, for scans to only
+	// scan what changed since.
+	StatePath string
+	// ArchiveOptions configures how the archives scanned are extracted.
+	ArchiveOptions ArchiveOptions
+}
+
+// ProcessConfig defines the optional configuration for a process source.
+type ProcessConfig struct {
+	// Pids are the IDs of the processes to scan. All processes are scanned
+	// if neither Pids nor Names are set.
+	Pids []int64
+	// Names are the names of the processes to scan.
+	Names []string
+	// SkipCommandLine only scans the environment of processes.
+	SkipCommandLine bool
+}
+
 // Progress is used to update job completion progress across sources.
 type Progress struct {
 	mut               sync.Mutex
@@ -266,6 +1184,16 @@ func (p *Progress) SetProgressComplete(i, scope int, message, encodedResumeInfo
 	p.PercentComplete = int64((float64(i) / float64(scope)) * 100)
 }
 
+// sections returns the number of completed and total sections.
+func (p *Progress) sections() (completed, total int32) {
+	if p == nil {
+		return 0, 0
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return p.SectionsCompleted, p.SectionsRemaining
+}
+
 // GetProgress gets job completion percentage for metrics reporting.
 func (p *Progress) GetProgress() *Progress {
 	p.mut.Lock()
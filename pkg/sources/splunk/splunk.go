@@ -0,0 +1,315 @@
+package splunk
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/hashicorp/go-retryablehttp"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// defaultSearch searches the events of all the indices but the internal
+// ones.
+const defaultSearch = "search index=*"
+
+// Source scans the events that a saved or ad-hoc SPL search of Splunk
+// finds, streamed from its export endpoint as the search runs.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn   *sourcespb.Splunk
+	url    string
+	client *http.Client
+}
+
+// result is a line of the output of the export endpoint, which is either a
+// result of the search or messages about it.
+type result struct {
+	Preview  bool                       `json:"preview"`
+	Result   map[string]json.RawMessage `json:"result"`
+	Messages []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"messages"`
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.DryRunner = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_SPLUNK
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// Init returns an initialized Splunk source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Splunk
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	if conn.GetEndpoint() == "" {
+		return errors.New("an endpoint is required")
+	}
+	u, err := url.Parse(conn.GetEndpoint())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid endpoint %q", conn.GetEndpoint())
+	}
+	s.url = strings.TrimSuffix(conn.GetEndpoint(), "/")
+	if conn.GetSearch() != "" && conn.GetSavedSearch() != "" {
+		return errors.New("a search and a saved search cannot both be set")
+	}
+
+	tlsConfig := common.TLSClientConfig()
+	tlsConfig.InsecureSkipVerify = conn.GetInsecure()
+	if ca := conn.GetCaCertificate(); ca != "" {
+		if err := common.AddRootCAs(tlsConfig, []byte(ca)); err != nil {
+			return fmt.Errorf("invalid CA certificate: %w", err)
+		}
+	}
+	s.client = httpClient(tlsConfig)
+	return nil
+}
+
+func httpClient(tlsConfig *tls.Config) *http.Client {
+	client := retryablehttp.NewClient()
+	client.RetryMax = 3
+	client.Logger = nil
+	// The results of exports stream for as long as their search runs, so
+	// only the response headers have a timeout.
+	client.HTTPClient.Transport = common.NewCustomTransport(&http.Transport{
+		TLSClientConfig:       tlsConfig,
+		Proxy:                 common.Proxy,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Minute,
+	})
+	return client.StandardClient()
+}
+
+// Chunks runs the search, and emits the raw events it finds as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	s.SetProgressComplete(0, 1, fmt.Sprintf("Search: %s", s.label()), "")
+	if err := s.export(ctx, chunksChan); err != nil {
+		return fmt.Errorf("error running search %q: %w", s.label(), err)
+	}
+	s.SetProgressComplete(1, 1, fmt.Sprintf("Search: %s", s.label()), "")
+	return nil
+}
+
+// EnumerateTargets reports the search, whose number of results is not known
+// without running it.
+func (s *Source) EnumerateTargets(_ context.Context, report func(sources.Target)) error {
+	report(sources.Target{Name: s.url + ": " + s.label(), Objects: -1, Bytes: -1})
+	return nil
+}
+
+// label is the name of the saved search, or the SPL of the search.
+func (s *Source) label() string {
+	if name := s.conn.GetSavedSearch(); name != "" {
+		return name
+	}
+	return s.search()
+}
+
+// search returns the SPL of the search to run. Searches that start with
+// neither a command nor a pipe have an implicit search command, which the
+// REST API needs explicitly.
+func (s *Source) search() string {
+	if name := s.conn.GetSavedSearch(); name != "" {
+		return "| savedsearch " + quote(name)
+	}
+	search := strings.TrimSpace(s.conn.GetSearch())
+	switch {
+	case search == "":
+		return defaultSearch
+	case strings.HasPrefix(search, "|"), strings.HasPrefix(strings.ToLower(search), "search "):
+		return search
+	default:
+		return "search " + search
+	}
+}
+
+// quote quotes a string for SPL.
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// exportPath returns the path of the export endpoint, in the namespace of
+// the app and owner of the connection if they are set.
+func (s *Source) exportPath() string {
+	app, owner := s.conn.GetApp(), s.conn.GetOwner()
+	if app == "" && owner == "" {
+		return "/services/search/jobs/export"
+	}
+	if app == "" {
+		app = "search"
+	}
+	if owner == "" {
+		owner = "nobody"
+	}
+	return "/servicesNS/" + url.PathEscape(owner) + "/" + url.PathEscape(app) + "/search/jobs/export"
+}
+
+// export streams the results of the search.
+func (s *Source) export(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	form := url.Values{"search": {s.search()}, "output_mode": {"json"}}
+	if earliest := s.conn.GetEarliest(); earliest != "" {
+		form.Set("earliest_time", earliest)
+	}
+	if latest := s.conn.GetLatest(); latest != "" {
+		form.Set("latest_time", latest)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+s.exportPath(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	switch {
+	case s.conn.GetToken() != "":
+		req.Header.Set("Authorization", "Bearer "+s.conn.GetToken())
+	case s.conn.GetUsername() != "":
+		req.SetBasicAuth(s.conn.GetUsername(), s.conn.GetPassword())
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return fmt.Errorf("invalid credentials, status %d", resp.StatusCode)
+	default:
+		var body result
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(msg, &body) == nil && len(body.Messages) > 0 {
+			msg = []byte(body.Messages[0].Text)
+		}
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var line result
+		if err := dec.Decode(&line); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error decoding results: %w", err)
+		}
+		for _, m := range line.Messages {
+			if m.Type == "FATAL" || m.Type == "ERROR" {
+				return errors.New(m.Text)
+			}
+		}
+		// Previews of transforming searches are followed by their final
+		// results.
+		if line.Preview || len(line.Result) == 0 {
+			continue
+		}
+		if err := s.scanResult(ctx, line.Result, chunksChan); err != nil {
+			return err
+		}
+	}
+}
+
+// scanResult emits the raw event of a result, or all its fields if it has
+// none, like the results of transforming searches.
+func (s *Source) scanResult(ctx context.Context, r map[string]json.RawMessage, chunksChan chan *sources.Chunk) error {
+	data := []byte(field(r, "_raw"))
+	if len(data) == 0 {
+		var err error
+		if data, err = json.Marshal(r); err != nil {
+			return err
+		}
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	chunk := &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		Data:       data,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Splunk{
+				Splunk: &source_metadatapb.Splunk{
+					Index:      sanitizer.UTF8(field(r, "index")),
+					Sourcetype: sanitizer.UTF8(field(r, "sourcetype")),
+					Source:     sanitizer.UTF8(field(r, "source")),
+					Host:       sanitizer.UTF8(field(r, "host")),
+					Timestamp:  field(r, "_time"),
+					EventId:    field(r, "_cd"),
+					Search:     sanitizer.UTF8(s.label()),
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+	return common.CancellableWrite(ctx, chunksChan, chunk)
+}
+
+// field returns the value of a field of a result, whose values are joined
+// by newlines if it has several.
+func field(r map[string]json.RawMessage, name string) string {
+	raw, ok := r[name]
+	if !ok {
+		return ""
+	}
+	var value string
+	if json.Unmarshal(raw, &value) == nil {
+		return value
+	}
+	var values []string
+	if json.Unmarshal(raw, &values) == nil {
+		return strings.Join(values, "\n")
+	}
+	return ""
+}
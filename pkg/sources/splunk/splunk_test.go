@@ -0,0 +1,171 @@
+package splunk
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// testSplunk is a Splunk whose export endpoint streams fixed results, and
+// records the paths and forms of the searches.
+type testSplunk struct {
+	mu       sync.Mutex
+	paths    []string
+	searches []url.Values
+}
+
+const testResults = `{"preview":false,"offset":0,"result":{"_raw":"user=ada password=hunter2","_time":"2024-01-02T03:04:05.000+00:00","_cd":"12:345","index":"main","sourcetype":"app","source":"/var/log/app.log","host":["web-1","web-2"]}}
+{"preview":false,"offset":1,"result":{"_raw":"   ","index":"main"}}
+{"preview":true,"offset":0,"result":{"count":"1"}}
+{"preview":false,"offset":2,"lastrow":true,"result":{"user":"bob","api_key":"xyz"}}
+`
+
+func (sp *testSplunk) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "admin" || pass != "changeme" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		require.NoError(t, r.ParseForm())
+		sp.mu.Lock()
+		sp.paths = append(sp.paths, r.URL.Path)
+		sp.searches = append(sp.searches, r.PostForm)
+		sp.mu.Unlock()
+
+		switch r.PostForm.Get("search") {
+		case "search index=broken":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"messages":[{"type":"ERROR","text":"Unknown search command 'broken'."}]}`)
+		case "search index=fatal":
+			fmt.Fprint(w, `{"messages":[{"type":"FATAL","text":"Search was cancelled."}]}`)
+		default:
+			fmt.Fprint(w, testResults)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSource_Chunks(t *testing.T) {
+	sp := &testSplunk{}
+	server := sp.server(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Splunk{
+		Endpoint: server.URL,
+		Token:    "token",
+		Search:   "index=main password",
+		Earliest: "-24h@h",
+		Latest:   "now",
+	}, 1)
+
+	got := sources.RequireChunks(t, s)
+	require.Len(t, got, 2)
+	assert.Equal(t, "user=ada password=hunter2", string(got[0].Data))
+	assert.Equal(t, &source_metadatapb.Splunk{
+		Index:      "main",
+		Sourcetype: "app",
+		Source:     "/var/log/app.log",
+		Host:       "web-1\nweb-2",
+		Timestamp:  "2024-01-02T03:04:05.000+00:00",
+		EventId:    "12:345",
+		Search:     "search index=main password",
+	}, got[0].SourceMetadata.GetSplunk())
+	// Results without raw events are scanned as their fields.
+	assert.JSONEq(t, `{"user":"bob","api_key":"xyz"}`, string(got[1].Data))
+
+	require.Len(t, sp.searches, 1)
+	assert.Equal(t, "/services/search/jobs/export", sp.paths[0])
+	assert.Equal(t, url.Values{
+		"search":        {"search index=main password"},
+		"output_mode":   {"json"},
+		"earliest_time": {"-24h@h"},
+		"latest_time":   {"now"},
+	}, sp.searches[0])
+}
+
+func TestSource_ChunksSavedSearch(t *testing.T) {
+	sp := &testSplunk{}
+	server := sp.server(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Splunk{
+		Endpoint:    server.URL,
+		Username:    "admin",
+		Password:    "changeme",
+		SavedSearch: `Leaked "keys"`,
+		App:         "security",
+	}, 1)
+
+	got := sources.RequireChunks(t, s)
+	require.Len(t, got, 2)
+	assert.Equal(t, `Leaked "keys"`, got[0].SourceMetadata.GetSplunk().GetSearch())
+	assert.Equal(t, "/servicesNS/nobody/security/search/jobs/export", sp.paths[0])
+	assert.Equal(t, `| savedsearch "Leaked \"keys\""`, sp.searches[0].Get("search"))
+}
+
+func TestSource_ChunksErrors(t *testing.T) {
+	server := (&testSplunk{}).server(t)
+	for name, tt := range map[string]struct {
+		conn    *sourcespb.Splunk
+		wantErr string
+	}{
+		"invalid credentials": {
+			conn:    &sourcespb.Splunk{Endpoint: server.URL, Token: "wrong"},
+			wantErr: "invalid credentials",
+		},
+		"invalid search": {
+			conn:    &sourcespb.Splunk{Endpoint: server.URL, Token: "token", Search: "index=broken"},
+			wantErr: "Unknown search command 'broken'.",
+		},
+		"fatal message": {
+			conn:    &sourcespb.Splunk{Endpoint: server.URL, Token: "token", Search: "search index=fatal"},
+			wantErr: "Search was cancelled.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := sources.CollectChunks(sources.InitTestSource(t, &Source{}, tt.conn, 1))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestSource_search(t *testing.T) {
+	for search, want := range map[string]string{
+		"":                           defaultSearch,
+		"index=main":                 "search index=main",
+		"Search index=main":          "Search index=main",
+		"| tstats count where index": "| tstats count where index",
+	} {
+		s := &Source{conn: &sourcespb.Splunk{Search: search}}
+		assert.Equal(t, want, s.search(), search)
+	}
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.Splunk{
+		"no endpoint":      {},
+		"invalid endpoint": {Endpoint: "splunk:8089"},
+		"two searches":     {Endpoint: "https://splunk:8089", Search: "index=main", SavedSearch: "saved"},
+		"invalid CA":       {Endpoint: "https://splunk:8089", CaCertificate: "not a certificate"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package sources
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LoadState decodes the JSON state a source saved at path with SaveState
+// into state. A missing file or an empty path leaves state as it is, as
+// there was no earlier scan.
+func LoadState(path string, state any) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return fmt.Errorf("error decoding state %s: %w", path, err)
+	}
+	return nil
+}
+
+// SaveState saves the state of a scan as JSON at path for the next one,
+// unless path is empty. The file is replaced at once, so a scan that is
+// killed while saving leaves the state of the last scan rather than a
+// truncated file.
+func SaveState(path string, state any) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("error saving state: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path, readable only
+// by the user, and renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,54 @@
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testState struct {
+	Cursors map[string]string `json:"cursors"`
+}
+
+func TestState(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	// There is no state before the first scan.
+	var state testState
+	require.NoError(t, LoadState(path, &state))
+	assert.Nil(t, state.Cursors)
+
+	require.NoError(t, SaveState(path, testState{Cursors: map[string]string{"general": "42"}}))
+	require.NoError(t, SaveState(path, testState{Cursors: map[string]string{"general": "43"}}))
+	require.NoError(t, LoadState(path, &state))
+	assert.Equal(t, map[string]string{"general": "43"}, state.Cursors)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"cursors":`), 0o600))
+	assert.ErrorContains(t, LoadState(path, &state), "error decoding state")
+
+	assert.NoError(t, LoadState("", &state))
+	assert.NoError(t, SaveState("", state))
+}
+
+func TestState_FailedSaveKeepsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, SaveState(path, testState{Cursors: map[string]string{"general": "42"}}))
+
+	// A state that can't be encoded leaves the saved state as it was.
+	assert.Error(t, SaveState(path, map[string]any{"cursor": func() {}}))
+	var state testState
+	require.NoError(t, LoadState(path, &state))
+	assert.Equal(t, map[string]string{"general": "42"}, state.Cursors)
+}
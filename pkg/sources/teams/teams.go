@@ -0,0 +1,671 @@
+package teams
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	"github.com/go-errors/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// defaultEndpoint is the URL of Microsoft Graph.
+	defaultEndpoint = "https://graph.microsoft.com/v1.0"
+	// graphScope is the scope of the tokens of the app permissions of the
+	// tenant.
+	graphScope = "https://graph.microsoft.com/.default"
+	// maxFileSize is the size of the largest files scanned.
+	maxFileSize = 250 * 1024 * 1024
+
+	locationMessage = "message"
+	locationReply   = "reply"
+	locationChat    = "chat"
+	locationFile    = "file"
+)
+
+// authorityHost is the URL of the Microsoft identity platform, which issues
+// the tokens of Microsoft Graph.
+var authorityHost = "https://login.microsoftonline.com"
+
+// errForbidden is returned for requests that the permissions of the app or
+// user don't allow.
+var errForbidden = errors.New("forbidden")
+
+// Source scans the messages of the channels of Microsoft Teams, with their
+// replies, the messages of chats, and the files shared in channels and chats,
+// through Microsoft Graph.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	// client authorizes its requests with the credentials of the source.
+	client   *http.Client
+	endpoint string
+	// appPermissions is whether the source authenticates as an app of the
+	// tenant rather than as a user.
+	appPermissions bool
+	conn           *sourcespb.Teams
+	// state is read from and saved to the state path of the connection.
+	stateMu sync.Mutex
+	state   state
+	// archiveOptions configures how the archives of files are extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// state is what a scan saves for the next one to only scan what changed
+// since.
+type state struct {
+	// Channels are the delta links of the messages of channels, by team and
+	// channel ID.
+	Channels map[string]string `json:"channels"`
+	// Chats are the times of the last change of the messages of chats, by
+	// chat ID.
+	Chats map[string]time.Time `json:"chats"`
+	// Files are the times the files of channels were scanned, by team and
+	// channel ID.
+	Files map[string]time.Time `json:"files"`
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_TEAMS
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of shared files are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized Teams source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Teams
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+	s.endpoint = strings.TrimSuffix(conn.GetEndpoint(), "/")
+	if s.endpoint == "" {
+		s.endpoint = defaultEndpoint
+	}
+
+	// Tokens are requested with the retrying client, and authorize the
+	// requests of another.
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, common.RetryableHttpClientTimeout(120))
+	var tokens oauth2.TokenSource
+	switch cred := conn.Credential.(type) {
+	case *sourcespb.Teams_Token:
+		tokens = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cred.Token})
+	case *sourcespb.Teams_Authenticated:
+		s.appPermissions = true
+		config := clientcredentials.Config{
+			ClientID:     cred.Authenticated.GetClientId(),
+			ClientSecret: cred.Authenticated.GetClientSecret(),
+			TokenURL:     authorityHost + "/" + url.PathEscape(cred.Authenticated.GetTenantId()) + "/oauth2/v2.0/token",
+			Scopes:       []string{graphScope},
+		}
+		tokens = config.TokenSource(tokenCtx)
+	case *sourcespb.Teams_Oauth:
+		token := &oauth2.Token{AccessToken: cred.Oauth.GetAccessToken(), RefreshToken: cred.Oauth.GetRefreshToken()}
+		tokens = oauth2.StaticTokenSource(token)
+		if token.RefreshToken != "" {
+			config := oauth2.Config{
+				ClientID:     cred.Oauth.GetClientId(),
+				ClientSecret: cred.Oauth.GetClientSecret(),
+				Endpoint:     oauth2.Endpoint{TokenURL: authorityHost + "/common/oauth2/v2.0/token"},
+				Scopes:       []string{graphScope, "offline_access"},
+			}
+			tokens = config.TokenSource(tokenCtx, token)
+		}
+	default:
+		return errors.New("credentials are required")
+	}
+	s.client = &http.Client{
+		Timeout:   120 * time.Second,
+		Transport: &oauth2.Transport{Source: oauth2.ReuseTokenSource(nil, tokens), Base: common.RetryableHttpClientTimeout(120).Transport},
+	}
+
+	s.state = state{Channels: map[string]string{}, Chats: map[string]time.Time{}, Files: map[string]time.Time{}}
+	return nil
+}
+
+type identity struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+type team struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+type channel struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	WebURL      string `json:"webUrl"`
+}
+
+type chat struct {
+	ID     string `json:"id"`
+	Topic  string `json:"topic"`
+	WebURL string `json:"webUrl"`
+}
+
+type chatMessage struct {
+	ID           string     `json:"id"`
+	CreatedAt    string     `json:"createdDateTime"`
+	LastModified time.Time  `json:"lastModifiedDateTime"`
+	Deleted      *time.Time `json:"deletedDateTime"`
+	WebURL       string     `json:"webUrl"`
+	From         struct {
+		User        *identity `json:"user"`
+		Application *identity `json:"application"`
+	} `json:"from"`
+	Subject string `json:"subject"`
+	Body    struct {
+		Content string `json:"content"`
+	} `json:"body"`
+	Attachments []struct {
+		ContentType string `json:"contentType"`
+		ContentURL  string `json:"contentUrl"`
+		Name        string `json:"name"`
+	} `json:"attachments"`
+}
+
+type driveItem struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Size         int64     `json:"size"`
+	WebURL       string    `json:"webUrl"`
+	LastModified time.Time `json:"lastModifiedDateTime"`
+	Folder       *struct{} `json:"folder"`
+	Parent       struct {
+		DriveID string `json:"driveId"`
+	} `json:"parentReference"`
+}
+
+// unit is a channel or chat to scan, and the team of channels.
+type unit struct {
+	team    team
+	channel channel
+	chat    chat
+}
+
+// Chunks emits the messages of the channels of the teams and of the chats,
+// the replies of channel messages, and the files shared in them, as chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	if err := s.loadState(); err != nil {
+		return err
+	}
+
+	units, err := s.channels(ctx)
+	if err != nil {
+		return err
+	}
+	if !s.conn.GetSkipChats() {
+		chats, err := s.chats(ctx)
+		switch {
+		case errors.Is(err, errForbidden):
+			ctx.Logger().Info("skipping chats, which the permissions of the credentials don't allow reading")
+		case err != nil:
+			return fmt.Errorf("error listing chats: %w", err)
+		}
+		for _, c := range chats {
+			units = append(units, unit{chat: c})
+		}
+	}
+
+	scanErrs := sources.NewScanErrors()
+	for i, u := range units {
+		if common.IsDone(ctx) {
+			break
+		}
+		u := u
+		if u.chat.ID != "" {
+			s.SetProgressComplete(i, len(units), fmt.Sprintf("Chat: %s", u.chat.ID), "")
+		} else {
+			s.SetProgressComplete(i, len(units), fmt.Sprintf("Channel: %s/%s", u.team.DisplayName, u.channel.DisplayName), "")
+		}
+		s.jobPool.Go(func() error {
+			var err error
+			if u.chat.ID != "" {
+				err = s.scanChat(ctx, u.chat, chunksChan)
+			} else {
+				err = s.scanChannel(ctx, u.team, u.channel, chunksChan)
+			}
+			if errors.Is(err, errForbidden) {
+				ctx.Logger().V(1).Info("skipping what the permissions of the credentials don't allow reading", "error", err)
+			} else if err != nil {
+				scanErrs.Add(err)
+			}
+			return nil
+		})
+	}
+	_ = s.jobPool.Wait()
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return s.saveState()
+}
+
+// channels returns the channels of the teams of the connection, or of all
+// the teams the credentials can read, filtered by the channels of the
+// connection.
+func (s *Source) channels(ctx context.Context) ([]unit, error) {
+	var teams []team
+	if ids := s.conn.GetTeamIds(); len(ids) > 0 {
+		for _, id := range ids {
+			var t team
+			if err := s.get(ctx, "/teams/"+url.PathEscape(id), &t); err != nil {
+				return nil, fmt.Errorf("error getting team %s: %w", id, err)
+			}
+			teams = append(teams, t)
+		}
+	} else {
+		path := "/me/joinedTeams"
+		if s.appPermissions {
+			path = "/teams"
+		}
+		var err error
+		if teams, _, err = list[team](ctx, s, path); err != nil {
+			return nil, fmt.Errorf("error listing teams: %w", err)
+		}
+	}
+
+	matches := func(list []string, c channel) bool {
+		for _, name := range list {
+			if name == c.ID || name == c.DisplayName {
+				return true
+			}
+		}
+		return false
+	}
+	var units []unit
+	for _, t := range teams {
+		channels, _, err := list[channel](ctx, s, "/teams/"+url.PathEscape(t.ID)+"/channels")
+		if err != nil {
+			return nil, fmt.Errorf("error listing the channels of team %s: %w", t.DisplayName, err)
+		}
+		for _, c := range channels {
+			if len(s.conn.GetChannels()) > 0 && !matches(s.conn.GetChannels(), c) || matches(s.conn.GetIgnoreList(), c) {
+				continue
+			}
+			units = append(units, unit{team: t, channel: c})
+		}
+	}
+	return units, nil
+}
+
+// chats returns the chats of the user, or those of all the users of the
+// tenant with app permissions.
+func (s *Source) chats(ctx context.Context) ([]chat, error) {
+	if !s.appPermissions {
+		chats, _, err := list[chat](ctx, s, "/me/chats")
+		return chats, err
+	}
+	users, _, err := list[identity](ctx, s, "/users?$select=id")
+	if err != nil {
+		return nil, err
+	}
+	var chats []chat
+	seen := make(map[string]bool)
+	for _, u := range users {
+		userChats, _, err := list[chat](ctx, s, "/users/"+url.PathEscape(u.ID)+"/chats")
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range userChats {
+			if !seen[c.ID] {
+				seen[c.ID] = true
+				chats = append(chats, c)
+			}
+		}
+	}
+	return chats, nil
+}
+
+// scanChannel scans the messages of a channel that changed since the last
+// scan, their replies, and the files of the channel.
+func (s *Source) scanChannel(ctx context.Context, t team, c channel, chunksChan chan *sources.Chunk) error {
+	key := t.ID + "/" + c.ID
+	base := "/teams/" + url.PathEscape(t.ID) + "/channels/" + url.PathEscape(c.ID)
+	path := base + "/messages/delta"
+	s.stateMu.Lock()
+	if link, ok := s.state.Channels[key]; ok {
+		path = link
+	}
+	s.stateMu.Unlock()
+
+	messages, deltaLink, err := list[chatMessage](ctx, s, path)
+	if err != nil {
+		return fmt.Errorf("error listing the messages of channel %s: %w", c.DisplayName, err)
+	}
+	for _, m := range messages {
+		if err := s.scanMessage(ctx, t, c, m, locationMessage, chunksChan); err != nil {
+			return err
+		}
+		replies, _, err := list[chatMessage](ctx, s, base+"/messages/"+url.PathEscape(m.ID)+"/replies")
+		if err != nil {
+			return fmt.Errorf("error listing the replies of message %s: %w", m.ID, err)
+		}
+		for _, r := range replies {
+			if err := s.scanMessage(ctx, t, c, r, locationReply, chunksChan); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !s.conn.GetSkipFiles() {
+		if err := s.scanChannelFiles(ctx, t, c, base, chunksChan); err != nil {
+			return err
+		}
+	}
+	if deltaLink != "" {
+		s.stateMu.Lock()
+		s.state.Channels[key] = deltaLink
+		s.stateMu.Unlock()
+	}
+	return nil
+}
+
+// scanChannelFiles scans the files of the folder of a channel changed since
+// the last scan.
+func (s *Source) scanChannelFiles(ctx context.Context, t team, c channel, base string, chunksChan chan *sources.Chunk) error {
+	key := t.ID + "/" + c.ID
+	started := time.Now()
+	s.stateMu.Lock()
+	since := s.state.Files[key]
+	s.stateMu.Unlock()
+
+	var folder driveItem
+	if err := s.get(ctx, base+"/filesFolder", &folder); err != nil {
+		return fmt.Errorf("error getting the files folder of channel %s: %w", c.DisplayName, err)
+	}
+	folders := []driveItem{folder}
+	for len(folders) > 0 {
+		dir := folders[0]
+		folders = folders[1:]
+		items, _, err := list[driveItem](ctx, s, "/drives/"+url.PathEscape(dir.Parent.DriveID)+"/items/"+url.PathEscape(dir.ID)+"/children")
+		if err != nil {
+			return fmt.Errorf("error listing the files of channel %s: %w", c.DisplayName, err)
+		}
+		for _, item := range items {
+			if item.Folder != nil {
+				folders = append(folders, item)
+				continue
+			}
+			if !item.LastModified.After(since) {
+				continue
+			}
+			skel := s.chunkSkeleton(t.ID, t.DisplayName, c.ID, c.DisplayName, locationFile, item.WebURL)
+			metadata := skel.SourceMetadata.GetTeams()
+			metadata.File = item.Name
+			metadata.Timestamp = item.LastModified.Format(time.RFC3339)
+			contentPath := "/drives/" + url.PathEscape(item.Parent.DriveID) + "/items/" + url.PathEscape(item.ID) + "/content"
+			if err := s.scanFile(ctx, skel, item.Name, item.Size, contentPath, chunksChan); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.stateMu.Lock()
+	s.state.Files[key] = started
+	s.stateMu.Unlock()
+	return nil
+}
+
+// scanChat scans the messages of a chat changed since the last scan, and the
+// files shared in them.
+func (s *Source) scanChat(ctx context.Context, c chat, chunksChan chan *sources.Chunk) error {
+	s.stateMu.Lock()
+	since, incremental := s.state.Chats[c.ID]
+	s.stateMu.Unlock()
+
+	path := "/chats/" + url.PathEscape(c.ID) + "/messages"
+	if incremental {
+		query := url.Values{
+			"$filter":  {"lastModifiedDateTime gt " + since.UTC().Format(time.RFC3339Nano)},
+			"$orderby": {"lastModifiedDateTime desc"},
+		}
+		path += "?" + query.Encode()
+	}
+	messages, _, err := list[chatMessage](ctx, s, path)
+	if err != nil {
+		return fmt.Errorf("error listing the messages of chat %s: %w", c.ID, err)
+	}
+
+	latest := since
+	for _, m := range messages {
+		if !m.LastModified.After(since) {
+			continue
+		}
+		if m.LastModified.After(latest) {
+			latest = m.LastModified
+		}
+		if err := s.scanMessage(ctx, team{}, channel{ID: c.ID, DisplayName: c.Topic}, m, locationChat, chunksChan); err != nil {
+			return err
+		}
+		if s.conn.GetSkipFiles() {
+			continue
+		}
+		for _, a := range m.Attachments {
+			// Files shared in chats are references to their OneDrive.
+			if a.ContentType != "reference" || a.ContentURL == "" {
+				continue
+			}
+			skel := s.messageSkeleton(team{}, channel{ID: c.ID, DisplayName: c.Topic}, m, locationFile)
+			skel.SourceMetadata.GetTeams().File = a.Name
+			skel.SourceMetadata.GetTeams().Link = a.ContentURL
+			if err := s.scanFile(ctx, skel, a.Name, 0, "/shares/"+shareID(a.ContentURL)+"/driveItem/content", chunksChan); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.stateMu.Lock()
+	s.state.Chats[c.ID] = latest
+	s.stateMu.Unlock()
+	return nil
+}
+
+// shareID returns the ID of a sharing URL for the shares API.
+func shareID(sharingURL string) string {
+	return "u!" + strings.TrimRight(base64.URLEncoding.EncodeToString([]byte(sharingURL)), "=")
+}
+
+// scanMessage scans the subject and body of a message, unless it was
+// deleted.
+func (s *Source) scanMessage(ctx context.Context, t team, c channel, m chatMessage, location string, chunksChan chan *sources.Chunk) error {
+	if m.Deleted != nil {
+		return nil
+	}
+	content := m.Body.Content
+	if m.Subject != "" {
+		content = m.Subject + "\n\n" + content
+	}
+	if content == "" {
+		return nil
+	}
+	skel := s.messageSkeleton(t, c, m, location)
+	return sources.ChunkReaderTo(ctx, skel, strings.NewReader(content), chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+// scanFile downloads a file, and scans it through the handlers of archives.
+func (s *Source) scanFile(ctx context.Context, skel *sources.Chunk, name string, size int64, contentPath string, chunksChan chan *sources.Chunk) error {
+	if size > maxFileSize {
+		sources.ReportSkipBytes(ctx, name, sources.SkipReasonSize, size)
+		return nil
+	}
+	res, err := s.do(ctx, s.endpoint+contentPath)
+	if err != nil {
+		return fmt.Errorf("error downloading file %s: %w", name, err)
+	}
+	defer res.Body.Close()
+
+	reader, err := diskbufferreader.New(res.Body)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+	return sources.ChunkReaderTo(ctx, skel, reader, chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+func (s *Source) messageSkeleton(t team, c channel, m chatMessage, location string) *sources.Chunk {
+	skel := s.chunkSkeleton(t.ID, t.DisplayName, c.ID, c.DisplayName, location, m.WebURL)
+	metadata := skel.SourceMetadata.GetTeams()
+	metadata.Timestamp = m.CreatedAt
+	switch {
+	case m.From.User != nil:
+		metadata.UserId, metadata.UserName = m.From.User.ID, m.From.User.DisplayName
+	case m.From.Application != nil:
+		metadata.UserName = m.From.Application.DisplayName
+	}
+	return skel
+}
+
+func (s *Source) chunkSkeleton(teamID, teamName, channelID, channelName, location, link string) *sources.Chunk {
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Teams{
+				Teams: &source_metadatapb.Teams{
+					TeamId:      teamID,
+					TeamName:    teamName,
+					ChannelId:   channelID,
+					ChannelName: channelName,
+					Link:        link,
+					Location:    location,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// loadState reads the state of the last scan, if there was one.
+func (s *Source) loadState() error {
+	return sources.LoadState(s.conn.GetStatePath(), &s.state)
+}
+
+// saveState saves the state of the scan for the next one. The channels and
+// chats that failed keep the state of the last scan.
+func (s *Source) saveState() error {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return sources.SaveState(s.conn.GetStatePath(), s.state)
+}
+
+// list returns the values of all the pages of a collection of Microsoft
+// Graph, and the delta link of its last page for delta queries. The path
+// is relative to the endpoint, or a next or delta link.
+func list[T any](ctx context.Context, s *Source, path string) ([]T, string, error) {
+	var all []T
+	next := path
+	for {
+		var page struct {
+			Value     []T    `json:"value"`
+			NextLink  string `json:"@odata.nextLink"`
+			DeltaLink string `json:"@odata.deltaLink"`
+		}
+		if err := s.get(ctx, next, &page); err != nil {
+			return nil, "", err
+		}
+		all = append(all, page.Value...)
+		if page.NextLink == "" || common.IsDone(ctx) {
+			return all, page.DeltaLink, nil
+		}
+		next = page.NextLink
+	}
+}
+
+// get decodes the JSON response of a request of Microsoft Graph.
+func (s *Source) get(ctx context.Context, path string, v any) error {
+	reqURL := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		reqURL = s.endpoint + path
+	}
+	res, err := s.do(ctx, reqURL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// do sends an authorized GET request, and returns its response if it
+// succeeded. Requests that were throttled are retried after the time
+// Microsoft Graph asks for.
+func (s *Source) do(ctx context.Context, reqURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		switch res.StatusCode {
+		case http.StatusUnauthorized:
+			return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+		case http.StatusForbidden:
+			return nil, fmt.Errorf("%w: %s", errForbidden, req.URL.Path)
+		}
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res, nil
+}
@@ -0,0 +1,200 @@
+package teams
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// fakeGraph serves the identity platform and Microsoft Graph of a tenant of a
+// team of two channels, whose first has a message with a reply and a file,
+// and of a chat with a shared file. Delta queries and filtered chat messages
+// return nothing new.
+func fakeGraph(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	value := func(w http.ResponseWriter, v ...any) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"value": v})
+	}
+	mux.HandleFunc("/tenant-id/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, graphScope, r.PostForm.Get("scope"))
+		if user, pass, _ := r.BasicAuth(); user != "client-id" || pass != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "graph-token", "token_type": "Bearer", "expires_in": 3600})
+	})
+	graph := func(path string, handler http.HandlerFunc) {
+		mux.HandleFunc("/v1.0"+path, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer graph-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			handler(w, r)
+		})
+	}
+	graph("/teams", func(w http.ResponseWriter, r *http.Request) {
+		value(w, map[string]any{"id": "T1", "displayName": "Platform"})
+	})
+	graph("/me/joinedTeams", func(w http.ResponseWriter, r *http.Request) {
+		value(w, map[string]any{"id": "T1", "displayName": "Platform"})
+	})
+	graph("/teams/T1/channels", func(w http.ResponseWriter, r *http.Request) {
+		value(w, map[string]any{"id": "CH1", "displayName": "General"}, map[string]any{"id": "CH2", "displayName": "Random"})
+	})
+	graph("/teams/T1/channels/CH1/messages/delta", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("$deltatoken") != "" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"value": []any{}, "@odata.deltaLink": server.URL + r.URL.String()})
+			return
+		}
+		if r.URL.Query().Get("$skiptoken") == "" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"value": []any{map[string]any{
+					"id": "M1", "createdDateTime": "2024-03-01T10:00:00Z", "lastModifiedDateTime": "2024-03-01T10:00:00Z",
+					"webUrl":  "https://teams.example/M1",
+					"from":    map[string]any{"user": map[string]any{"id": "U1", "displayName": "Ada"}},
+					"subject": "Deploy", "body": map[string]any{"content": "<p>password: hunter2</p>"},
+				}},
+				"@odata.nextLink": server.URL + "/v1.0/teams/T1/channels/CH1/messages/delta?$skiptoken=page2",
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"value":            []any{map[string]any{"id": "M2", "deletedDateTime": "2024-03-01T11:00:00Z", "body": map[string]any{"content": "deleted"}}},
+			"@odata.deltaLink": server.URL + "/v1.0/teams/T1/channels/CH1/messages/delta?$deltatoken=next",
+		})
+	})
+	graph("/teams/T1/channels/CH1/messages/M1/replies", func(w http.ResponseWriter, r *http.Request) {
+		value(w, map[string]any{
+			"id": "M3", "createdDateTime": "2024-03-01T10:05:00Z",
+			"from": map[string]any{"application": map[string]any{"displayName": "Deploy Bot"}},
+			"body": map[string]any{"content": "rotated to hunter3"},
+		})
+	})
+	graph("/teams/T1/channels/CH1/messages/M2/replies", func(w http.ResponseWriter, r *http.Request) {
+		value(w)
+	})
+	graph("/teams/T1/channels/CH1/filesFolder", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "F0", "parentReference": map[string]any{"driveId": "D1"}})
+	})
+	graph("/drives/D1/items/F0/children", func(w http.ResponseWriter, r *http.Request) {
+		value(w,
+			map[string]any{"id": "F1", "name": "app.env", "size": 13, "lastModifiedDateTime": "2024-03-01T12:00:00Z", "webUrl": "https://sharepoint.example/app.env", "parentReference": map[string]any{"driveId": "D1"}},
+			map[string]any{"id": "F2", "name": "docs", "folder": map[string]any{}, "parentReference": map[string]any{"driveId": "D1"}},
+		)
+	})
+	graph("/drives/D1/items/F2/children", func(w http.ResponseWriter, r *http.Request) {
+		value(w)
+	})
+	graph("/drives/D1/items/F1/content", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("TOKEN=secret\n"))
+	})
+	graph("/users", func(w http.ResponseWriter, r *http.Request) {
+		value(w, map[string]any{"id": "U1"}, map[string]any{"id": "U2"})
+	})
+	graph("/users/U1/chats", func(w http.ResponseWriter, r *http.Request) {
+		value(w, map[string]any{"id": "C1", "topic": "Incident"})
+	})
+	graph("/users/U2/chats", func(w http.ResponseWriter, r *http.Request) {
+		value(w, map[string]any{"id": "C1", "topic": "Incident"})
+	})
+	graph("/chats/C1/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("$filter") != "" {
+			assert.Equal(t, "lastModifiedDateTime gt 2024-03-02T09:00:00Z", r.URL.Query().Get("$filter"))
+			value(w)
+			return
+		}
+		value(w, map[string]any{
+			"id": "CM1", "createdDateTime": "2024-03-02T09:00:00Z", "lastModifiedDateTime": "2024-03-02T09:00:00Z",
+			"from":        map[string]any{"user": map[string]any{"id": "U2", "displayName": "Bob"}},
+			"body":        map[string]any{"content": "the key is in the file"},
+			"attachments": []any{map[string]any{"contentType": "reference", "contentUrl": "https://sharepoint.example/key.txt", "name": "key.txt"}},
+		})
+	})
+	graph("/shares/"+shareID("https://sharepoint.example/key.txt")+"/driveItem/content", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("KEY=shared\n"))
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	authorityHost = server.URL
+	t.Cleanup(func() { authorityHost = "https://login.microsoftonline.com" })
+	return server
+}
+
+// chunks returns the data of the chunks of a source by location and
+// message or file, and their metadata.
+func chunks(t *testing.T, conn *sourcespb.Teams) (map[string]string, map[string]*source_metadatapb.Teams) {
+	t.Helper()
+	s := sources.InitTestSource(t, &Source{}, conn, 2)
+
+	got := make(map[string]string)
+	metadata := make(map[string]*source_metadatapb.Teams)
+	for _, chunk := range sources.RequireChunks(t, s) {
+		m := chunk.SourceMetadata.GetTeams()
+		key := m.GetLocation() + " " + m.GetChannelName() + " " + m.GetFile()
+		got[key] += string(chunk.Data)
+		metadata[key] = m
+	}
+	return got, metadata
+}
+
+func TestSource_Chunks(t *testing.T) {
+	server := fakeGraph(t)
+	conn := &sourcespb.Teams{
+		Endpoint: server.URL + "/v1.0",
+		Credential: &sourcespb.Teams_Authenticated{Authenticated: &credentialspb.ClientCredentials{
+			TenantId: "tenant-id", ClientId: "client-id", ClientSecret: "client-secret",
+		}},
+		IgnoreList: []string{"Random"},
+		StatePath:  filepath.Join(t.TempDir(), "teams-state.json"),
+	}
+	got, metadata := chunks(t, conn)
+	assert.Equal(t, map[string]string{
+		"message General ":      "Deploy\n\n<p>password: hunter2</p>",
+		"reply General ":        "rotated to hunter3",
+		"file General app.env":  "TOKEN=secret\n",
+		"chat Incident ":        "the key is in the file",
+		"file Incident key.txt": "KEY=shared\n",
+	}, got)
+	assert.Equal(t, &source_metadatapb.Teams{
+		ChannelId:   "CH1",
+		ChannelName: "General",
+		Timestamp:   "2024-03-01T10:00:00Z",
+		UserId:      "U1",
+		UserName:    "Ada",
+		Link:        "https://teams.example/M1",
+		Location:    "message",
+		TeamName:    "Platform",
+		TeamId:      "T1",
+	}, metadata["message General "])
+	assert.Equal(t, "Deploy Bot", metadata["reply General "].GetUserName())
+	assert.Equal(t, "https://sharepoint.example/app.env", metadata["file General app.env"].GetLink())
+
+	// The next scan only scans what changed since.
+	got, _ = chunks(t, conn)
+	assert.Empty(t, got)
+}
+
+func TestSource_ChunksInvalidCredentials(t *testing.T) {
+	server := fakeGraph(t)
+	conn, err := anypb.New(&sourcespb.Teams{Endpoint: server.URL + "/v1.0", Credential: &sourcespb.Teams_Token{Token: "expired"}})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 0, 0, false, conn, 1))
+	assert.ErrorContains(t, s.Chunks(context.Background(), make(chan *sources.Chunk, 1)), "invalid credentials")
+}
@@ -3,7 +3,15 @@ package sources
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"testing"
 	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 )
 
 type ChunkFunc func(chunk *Chunk) error
@@ -27,3 +35,82 @@ func HandleTestChannel(chunksCh chan *Chunk, cf ChunkFunc) error {
 		}
 	}
 }
+
+// InitTestSource initializes the source with the connection, as a source
+// named "test" that does not verify results, and returns it. The test fails
+// if the source can't be initialized.
+func InitTestSource[S Source](t testing.TB, s S, conn proto.Message, concurrency int) S {
+	t.Helper()
+	anyConn, err := anypb.New(conn)
+	if err != nil {
+		t.Fatalf("error marshalling connection: %v", err)
+	}
+	if err := s.Init(context.Background(), "test", 0, 0, false, anyConn, concurrency); err != nil {
+		t.Fatalf("error initializing source: %v", err)
+	}
+	return s
+}
+
+// CollectChunks returns the chunks emitted by the source, and the error of
+// its Chunks.
+func CollectChunks(s Source) ([]*Chunk, error) {
+	chunksChan := make(chan *Chunk)
+	done := make(chan struct{})
+	var chunks []*Chunk
+	go func() {
+		defer close(done)
+		for chunk := range chunksChan {
+			chunks = append(chunks, chunk)
+		}
+	}()
+	err := s.Chunks(context.Background(), chunksChan)
+	close(chunksChan)
+	<-done
+	return chunks, err
+}
+
+// RequireChunks returns the chunks emitted by the source. The test fails if
+// its Chunks fails.
+func RequireChunks(t testing.TB, s Source) []*Chunk {
+	t.Helper()
+	chunks, err := CollectChunks(s)
+	if err != nil {
+		t.Fatalf("error getting chunks: %v", err)
+	}
+	return chunks
+}
+
+// ChunksWith returns the chunks whose data contains the string.
+func ChunksWith(chunks []*Chunk, data string) []*Chunk {
+	var with []*Chunk
+	for _, chunk := range chunks {
+		if strings.Contains(string(chunk.Data), data) {
+			with = append(with, chunk)
+		}
+	}
+	return with
+}
+
+// MetadataWith returns the metadata of the first chunk whose data contains
+// the string, or nil if there is no such chunk. Its getters, like GetJira,
+// return nil as well then.
+func MetadataWith(chunks []*Chunk, data string) *source_metadatapb.MetaData {
+	if with := ChunksWith(chunks, data); len(with) > 0 {
+		return with[0].SourceMetadata
+	}
+	return nil
+}
+
+// ChunksByKey returns the data and the metadata of the chunks by the key of
+// their metadata. The data of the chunks of a key is concatenated, as large
+// files are split into several chunks.
+func ChunksByKey[M any](chunks []*Chunk, get func(*source_metadatapb.MetaData) M, key func(M) string) (map[string]string, map[string]M) {
+	data := make(map[string]string, len(chunks))
+	metadata := make(map[string]M, len(chunks))
+	for _, chunk := range chunks {
+		m := get(chunk.SourceMetadata)
+		data[key(m)] += string(chunk.Data)
+		metadata[key(m)] = m
+	}
+	return data, metadata
+}
@@ -0,0 +1,187 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+const (
+	// asanaEndpoint is the URL of the Asana API.
+	asanaEndpoint = "https://app.asana.com/api/1.0"
+	// asanaPageSize is the number of results requested at a time, the most
+	// Asana returns.
+	asanaPageSize = 100
+)
+
+// asana lists the tasks of Asana projects.
+type asana struct {
+	api
+	projects []string
+}
+
+func newAsana(client *http.Client, conn *sourcespb.Asana) (*asana, error) {
+	if conn.GetToken() == "" {
+		return nil, errors.New("a token is required")
+	}
+	a := &asana{api: api{client: client, endpoint: asanaEndpoint}, projects: conn.GetProjects()}
+	a.authorize = func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+conn.GetToken()) }
+	return a, nil
+}
+
+func (a *asana) name() string {
+	return "asana"
+}
+
+// asanaList returns the results of all the pages of a list of the Asana API.
+func asanaList[T any](ctx context.Context, a *asana, path string, query url.Values) ([]T, error) {
+	query.Set("limit", strconv.Itoa(asanaPageSize))
+	var results []T
+	for {
+		var page struct {
+			Data     []T `json:"data"`
+			NextPage *struct {
+				Offset string `json:"offset"`
+			} `json:"next_page"`
+		}
+		if err := a.get(ctx, path+"?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+		results = append(results, page.Data...)
+		if page.NextPage == nil || page.NextPage.Offset == "" {
+			return results, nil
+		}
+		query.Set("offset", page.NextPage.Offset)
+	}
+}
+
+type asanaResource struct {
+	GID  string `json:"gid"`
+	Name string `json:"name"`
+}
+
+type asanaTask struct {
+	GID          string `json:"gid"`
+	Name         string `json:"name"`
+	PermalinkURL string `json:"permalink_url"`
+}
+
+type asanaUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// items lists the tasks of each project, complete and incomplete.
+func (a *asana) items(ctx context.Context, fn func([]item) error) error {
+	var projects []asanaResource
+	if len(a.projects) == 0 {
+		workspaces, err := asanaList[asanaResource](ctx, a, "/workspaces", url.Values{})
+		if err != nil {
+			return err
+		}
+		for _, w := range workspaces {
+			wsProjects, err := asanaList[asanaResource](ctx, a, "/projects", url.Values{"workspace": {w.GID}, "opt_fields": {"name"}})
+			if err != nil {
+				return fmt.Errorf("error listing projects of workspace %s: %w", w.GID, err)
+			}
+			projects = append(projects, wsProjects...)
+		}
+	}
+	for _, gid := range a.projects {
+		var project struct {
+			Data asanaResource `json:"data"`
+		}
+		if err := a.get(ctx, "/projects/"+url.PathEscape(gid)+"?opt_fields=name", &project); err != nil {
+			return fmt.Errorf("error getting project %s: %w", gid, err)
+		}
+		projects = append(projects, project.Data)
+	}
+
+	for _, project := range projects {
+		tasks, err := asanaList[asanaTask](ctx, a, "/projects/"+project.GID+"/tasks", url.Values{"opt_fields": {"name,permalink_url"}})
+		if err != nil {
+			return fmt.Errorf("error listing tasks of project %s: %w", project.GID, err)
+		}
+		items := make([]item, 0, len(tasks))
+		for _, t := range tasks {
+			items = append(items, item{id: t.GID, project: project.Name, title: t.Name, link: t.PermalinkURL})
+		}
+		if err := fn(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type asanaStory struct {
+	GID             string    `json:"gid"`
+	ResourceSubtype string    `json:"resource_subtype"`
+	Text            string    `json:"text"`
+	CreatedAt       time.Time `json:"created_at"`
+	CreatedBy       asanaUser `json:"created_by"`
+}
+
+type asanaAttachment struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"download_url"`
+	Size        int64  `json:"size"`
+}
+
+// posts returns the notes of a task with its attachments, and its comments.
+func (a *asana) posts(ctx context.Context, it item) ([]post, error) {
+	var task struct {
+		Data struct {
+			Name      string    `json:"name"`
+			Notes     string    `json:"notes"`
+			CreatedAt time.Time `json:"created_at"`
+			CreatedBy asanaUser `json:"created_by"`
+		} `json:"data"`
+	}
+	if err := a.get(ctx, "/tasks/"+it.id+"?opt_fields=name,notes,created_at,created_by.name,created_by.email", &task); err != nil {
+		return nil, fmt.Errorf("error getting task: %w", err)
+	}
+	description := post{
+		location: locationDescription,
+		author:   formatAuthor(task.Data.CreatedBy.Name, task.Data.CreatedBy.Email),
+		body:     task.Data.Name + "\n\n" + task.Data.Notes,
+		created:  task.Data.CreatedAt,
+	}
+
+	// Attachments hosted elsewhere, like on Google Drive, have no download
+	// URL. Download URLs are signed, and expire after a few minutes.
+	attachments, err := asanaList[asanaAttachment](ctx, a, "/attachments", url.Values{"parent": {it.id}, "opt_fields": {"name,download_url,size"}})
+	if err != nil {
+		return nil, fmt.Errorf("error listing attachments: %w", err)
+	}
+	for _, att := range attachments {
+		if att.DownloadURL != "" {
+			description.attachments = append(description.attachments, attachment{name: att.Name, url: att.DownloadURL, size: att.Size})
+		}
+	}
+	posts := []post{description}
+
+	stories, err := asanaList[asanaStory](ctx, a, "/tasks/"+it.id+"/stories", url.Values{
+		"opt_fields": {"resource_subtype,text,created_at,created_by.name,created_by.email"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing comments: %w", err)
+	}
+	for _, story := range stories {
+		if story.ResourceSubtype != "comment_added" {
+			continue
+		}
+		posts = append(posts, post{
+			location: locationComment + "/" + story.GID,
+			author:   formatAuthor(story.CreatedBy.Name, story.CreatedBy.Email),
+			body:     story.Text,
+			created:  story.CreatedAt,
+		})
+	}
+	return posts, nil
+}
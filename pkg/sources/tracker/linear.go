@@ -0,0 +1,217 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+const (
+	// linearEndpoint is the URL of the Linear API.
+	linearEndpoint = "https://api.linear.app"
+	// linearUploads is the URL of the files uploaded to Linear, which are
+	// linked from the Markdown of issues and comments.
+	linearUploads = "https://uploads.linear.app"
+	// linearPageSize is the number of issues and comments requested at a
+	// time.
+	linearPageSize = 100
+)
+
+// linear lists the issues of Linear teams through the GraphQL API.
+type linear struct {
+	api
+	teams []string
+	// uploads matches the URLs of uploaded files.
+	uploads *regexp.Regexp
+}
+
+func newLinear(client *http.Client, conn *sourcespb.Linear) (*linear, error) {
+	key := conn.GetApiKey()
+	if key == "" {
+		return nil, errors.New("an API key is required")
+	}
+	l := &linear{api: api{client: client, endpoint: linearEndpoint}, teams: conn.GetTeams()}
+	l.authorize = func(req *http.Request) {
+		// Personal API keys are sent as is, OAuth tokens as bearer tokens.
+		if strings.HasPrefix(key, "lin_api_") {
+			req.Header.Set("Authorization", key)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+	}
+	l.setUploads(linearUploads)
+	return l, nil
+}
+
+// setUploads sets the URL of uploaded files, which are downloaded with the
+// credentials of the API.
+func (l *linear) setUploads(uploadsURL string) {
+	u, _ := url.Parse(uploadsURL)
+	l.hosts = []string{u.Host}
+	l.uploads = regexp.MustCompile(regexp.QuoteMeta(uploadsURL) + `/[^\s()\[\]<>"']+`)
+}
+
+func (l *linear) name() string {
+	return "linear"
+}
+
+type linearPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type linearUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// query sends a GraphQL query, and decodes its data.
+func (l *linear) query(ctx context.Context, query string, variables map[string]any, v any) error {
+	var res struct {
+		Data   any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	res.Data = v
+	if err := l.post(ctx, "/graphql", map[string]any{"query": query, "variables": variables}, &res); err != nil {
+		return err
+	}
+	if len(res.Errors) > 0 {
+		return fmt.Errorf("error querying: %s", res.Errors[0].Message)
+	}
+	return nil
+}
+
+const linearIssuesQuery = `query Issues($first: Int!, $after: String, $filter: IssueFilter) {
+  issues(first: $first, after: $after, filter: $filter, includeArchived: true) {
+    nodes { id identifier title url team { key } }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+
+// items lists the issues of the teams, archived included.
+func (l *linear) items(ctx context.Context, fn func([]item) error) error {
+	variables := map[string]any{"first": linearPageSize}
+	if len(l.teams) > 0 {
+		variables["filter"] = map[string]any{"team": map[string]any{"key": map[string]any{"in": l.teams}}}
+	}
+	for {
+		var data struct {
+			Issues struct {
+				Nodes []struct {
+					ID         string `json:"id"`
+					Identifier string `json:"identifier"`
+					Title      string `json:"title"`
+					URL        string `json:"url"`
+					Team       struct {
+						Key string `json:"key"`
+					} `json:"team"`
+				} `json:"nodes"`
+				PageInfo linearPageInfo `json:"pageInfo"`
+			} `json:"issues"`
+		}
+		if err := l.query(ctx, linearIssuesQuery, variables, &data); err != nil {
+			return err
+		}
+		items := make([]item, 0, len(data.Issues.Nodes))
+		for _, issue := range data.Issues.Nodes {
+			items = append(items, item{id: issue.Identifier, project: issue.Team.Key, title: issue.Title, link: issue.URL})
+		}
+		if err := fn(items); err != nil {
+			return err
+		}
+		if !data.Issues.PageInfo.HasNextPage {
+			return nil
+		}
+		variables["after"] = data.Issues.PageInfo.EndCursor
+	}
+}
+
+const linearIssueQuery = `query Issue($id: String!, $first: Int!, $after: String) {
+  issue(id: $id) {
+    title description createdAt creator { name email }
+    comments(first: $first, after: $after) {
+      nodes { id body createdAt user { name email } }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+// posts returns the description of an issue and its comments, with the
+// files uploaded to them.
+func (l *linear) posts(ctx context.Context, it item) ([]post, error) {
+	var posts []post
+	variables := map[string]any{"id": it.id, "first": linearPageSize}
+	for {
+		var data struct {
+			Issue struct {
+				Title       string     `json:"title"`
+				Description string     `json:"description"`
+				CreatedAt   time.Time  `json:"createdAt"`
+				Creator     linearUser `json:"creator"`
+				Comments    struct {
+					Nodes []struct {
+						ID        string     `json:"id"`
+						Body      string     `json:"body"`
+						CreatedAt time.Time  `json:"createdAt"`
+						User      linearUser `json:"user"`
+					} `json:"nodes"`
+					PageInfo linearPageInfo `json:"pageInfo"`
+				} `json:"comments"`
+			} `json:"issue"`
+		}
+		if err := l.query(ctx, linearIssueQuery, variables, &data); err != nil {
+			return nil, fmt.Errorf("error getting issue: %w", err)
+		}
+		issue := data.Issue
+		if len(posts) == 0 {
+			posts = append(posts, post{
+				location:    locationDescription,
+				author:      formatAuthor(issue.Creator.Name, issue.Creator.Email),
+				body:        issue.Title + "\n\n" + issue.Description,
+				created:     issue.CreatedAt,
+				attachments: l.uploadsOf(issue.Description),
+			})
+		}
+		for _, c := range issue.Comments.Nodes {
+			posts = append(posts, post{
+				location:    locationComment + "/" + c.ID,
+				author:      formatAuthor(c.User.Name, c.User.Email),
+				body:        c.Body,
+				created:     c.CreatedAt,
+				attachments: l.uploadsOf(c.Body),
+			})
+		}
+		if !issue.Comments.PageInfo.HasNextPage {
+			return posts, nil
+		}
+		variables["after"] = issue.Comments.PageInfo.EndCursor
+	}
+}
+
+// uploadsOf returns the files uploaded to Linear that Markdown links to.
+func (l *linear) uploadsOf(markdown string) []attachment {
+	var attachments []attachment
+	seen := make(map[string]bool)
+	for _, fileURL := range l.uploads.FindAllString(markdown, -1) {
+		if seen[fileURL] {
+			continue
+		}
+		seen[fileURL] = true
+		name := fileURL
+		if u, err := url.Parse(fileURL); err == nil {
+			name = path.Base(u.Path)
+		}
+		attachments = append(attachments, attachment{name: name, url: fileURL})
+	}
+	return attachments
+}
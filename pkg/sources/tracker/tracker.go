@@ -0,0 +1,354 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	diskbufferreader "github.com/bill-rich/disk-buffer-reader"
+	goerrors "github.com/go-errors/errors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// maxAttachmentSize is the size of the largest attachments scanned.
+	maxAttachmentSize = 250 * 1024 * 1024
+
+	locationDescription = "description"
+	locationComment     = "comment"
+	locationAttachment  = "attachment"
+)
+
+// Source scans the descriptions, comments and attachments of the items of
+// a project management tool: the cards of Trello, the tasks of Asana or the
+// issues of Linear.
+type Source struct {
+	name     string
+	sourceId int64
+	jobId    int64
+	verify   bool
+	jobPool  *errgroup.Group
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+
+	conn     *sourcespb.Tracker
+	platform platform
+	// archiveOptions configures how the archives of attachments are
+	// extracted.
+	archiveOptions sources.ArchiveOptions
+}
+
+// platform lists the items of a project management tool and their posts.
+type platform interface {
+	// name is the name of the platform in the metadata of chunks.
+	name() string
+	// items calls fn with each page of the items of the projects to scan.
+	items(ctx context.Context, fn func([]item) error) error
+	// posts returns the description and the comments of an item.
+	posts(ctx context.Context, it item) ([]post, error)
+	// download returns the content of an attachment.
+	download(ctx context.Context, a attachment) (io.ReadCloser, error)
+}
+
+// item is a card, a task or an issue.
+type item struct {
+	id      string
+	project string
+	title   string
+	link    string
+}
+
+// post is the description or a comment of an item.
+type post struct {
+	location    string
+	author      string
+	body        string
+	created     time.Time
+	attachments []attachment
+}
+
+// attachment is a file attached to a post.
+type attachment struct {
+	name string
+	url  string
+	size int64
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return sourcespb.SourceType_SOURCE_TYPE_TRACKER
+}
+
+func (s *Source) SourceID() int64 {
+	return s.sourceId
+}
+
+func (s *Source) JobID() int64 {
+	return s.jobId
+}
+
+// WithArchiveOptions sets how the archives of attachments are extracted.
+func (s *Source) WithArchiveOptions(opts sources.ArchiveOptions) {
+	s.archiveOptions = opts
+}
+
+// Init returns an initialized tracker source.
+func (s *Source) Init(_ context.Context, name string, jobId, sourceId int64, verify bool, connection *anypb.Any, concurrency int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	s.jobPool = &errgroup.Group{}
+	s.jobPool.SetLimit(concurrency)
+
+	var conn sourcespb.Tracker
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return goerrors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	s.conn = &conn
+
+	client := common.RetryableHttpClientTimeout(120)
+	var err error
+	switch p := conn.GetPlatform().(type) {
+	case *sourcespb.Tracker_Trello:
+		s.platform, err = newTrello(client, p.Trello)
+	case *sourcespb.Tracker_Asana:
+		s.platform, err = newAsana(client, p.Asana)
+	case *sourcespb.Tracker_Linear:
+		s.platform, err = newLinear(client, p.Linear)
+	default:
+		err = errors.New("a platform is required")
+	}
+	return err
+}
+
+// Chunks emits the description, comments and attachments of each item as
+// chunks.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk) error {
+	var listed, scanned int64
+	scanErrs := sources.NewScanErrors()
+	err := s.platform.items(ctx, func(items []item) error {
+		total := atomic.AddInt64(&listed, int64(len(items)))
+		for _, it := range items {
+			if common.IsDone(ctx) {
+				return ctx.Err()
+			}
+			it := it
+			s.jobPool.Go(func() error {
+				if err := s.scanItem(ctx, it, chunksChan); err != nil {
+					scanErrs.Add(fmt.Errorf("error scanning item %s: %w", it.id, err))
+				}
+				n := atomic.AddInt64(&scanned, 1)
+				s.SetProgressComplete(int(n), int(total), fmt.Sprintf("Item: %s", it.id), "")
+				return nil
+			})
+		}
+		return nil
+	})
+	_ = s.jobPool.Wait()
+	if err != nil && !common.IsDone(ctx) {
+		return fmt.Errorf("error listing items: %w", err)
+	}
+
+	if scanErrs.Count() > 0 {
+		ctx.Logger().V(2).Info("encountered errors while scanning", "count", scanErrs.Count(), "errors", scanErrs)
+	}
+	return nil
+}
+
+func (s *Source) scanItem(ctx context.Context, it item, chunksChan chan *sources.Chunk) error {
+	posts, err := s.platform.posts(ctx, it)
+	if err != nil {
+		return err
+	}
+	for _, p := range posts {
+		skel := s.chunkSkeleton(it, p.location, p.author, p.created)
+		if err := sources.ChunkReaderTo(ctx, skel, strings.NewReader(p.body), chunksChan, s.archiveOptions.ChunkOptions()...); err != nil {
+			return err
+		}
+		if s.conn.GetSkipAttachments() {
+			continue
+		}
+		for _, a := range p.attachments {
+			location := locationAttachment + "/" + a.name
+			if a.size > maxAttachmentSize {
+				sources.ReportSkipBytes(ctx, it.id+"/"+location, sources.SkipReasonSize, a.size)
+				continue
+			}
+			skel := s.chunkSkeleton(it, location, p.author, p.created)
+			if err := s.scanAttachment(ctx, skel, a, chunksChan); err != nil {
+				return fmt.Errorf("error scanning attachment %s: %w", a.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// scanAttachment scans an attachment, through the handlers of archives.
+func (s *Source) scanAttachment(ctx context.Context, skel *sources.Chunk, a attachment, chunksChan chan *sources.Chunk) error {
+	body, err := s.platform.download(ctx, a)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	reader, err := diskbufferreader.New(io.LimitReader(body, maxAttachmentSize))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if handlers.HandleFile(ctx, reader, skel, chunksChan, handlers.WithArchiveOptions(s.archiveOptions)) {
+		return nil
+	}
+	if err := reader.Reset(); err != nil {
+		return err
+	}
+	reader.Stop()
+	return sources.ChunkReaderTo(ctx, skel, reader, chunksChan, s.archiveOptions.ChunkOptions()...)
+}
+
+func (s *Source) chunkSkeleton(it item, location, author string, created time.Time) *sources.Chunk {
+	var timestamp string
+	if !created.IsZero() {
+		timestamp = created.UTC().Format(time.RFC3339)
+	}
+	return &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Tracker{
+				Tracker: &source_metadatapb.Tracker{
+					Platform:  s.platform.name(),
+					Project:   sanitizer.UTF8(it.project),
+					Item:      it.id,
+					Title:     sanitizer.UTF8(it.title),
+					Location:  sanitizer.UTF8(location),
+					Author:    sanitizer.UTF8(author),
+					Link:      it.link,
+					Timestamp: timestamp,
+				},
+			},
+		},
+		Verify: s.verify,
+	}
+}
+
+// formatAuthor returns the name and the email of an author.
+func formatAuthor(name, email string) string {
+	switch {
+	case email == "":
+		return name
+	case name == "":
+		return email
+	default:
+		return fmt.Sprintf("%s <%s>", name, email)
+	}
+}
+
+// api sends the requests of the API of a platform.
+type api struct {
+	client   *http.Client
+	endpoint string
+	// authorize sets the credentials of requests.
+	authorize func(*http.Request)
+	// hosts are the other hosts whose requests are authorized.
+	hosts []string
+}
+
+// get decodes the JSON response of a GET request of the API. The path is
+// relative to the endpoint.
+func (a *api) get(ctx context.Context, path string, v any) error {
+	res, err := a.do(ctx, http.MethodGet, a.endpoint+path, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// post sends body as JSON to the API, and decodes its JSON response.
+func (a *api) post(ctx context.Context, path string, body, v any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	res, err := a.do(ctx, http.MethodPost, a.endpoint+path, data)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// do sends a request, authorized if it is to the endpoint or to one of the
+// hosts, and returns its response if it succeeded. Attachments may be
+// served from other hosts, whose URLs carry their own authorization.
+func (a *api) do(ctx context.Context, method, reqURL string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if a.authorizes(req.URL.Host) {
+		a.authorize(req)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("invalid credentials, status %d", res.StatusCode)
+		}
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res, nil
+}
+
+func (a *api) authorizes(host string) bool {
+	if endpoint, err := url.Parse(a.endpoint); err == nil && host == endpoint.Host {
+		return true
+	}
+	for _, h := range a.hosts {
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// download returns the content of an attachment.
+func (a *api) download(ctx context.Context, att attachment) (io.ReadCloser, error) {
+	res, err := a.do(ctx, http.MethodGet, att.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
@@ -0,0 +1,259 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// fileServer serves attachments without credentials, like signed URLs of
+// storage, and rejects requests that carry any.
+func fileServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, "AWS_SECRET_ACCESS_KEY=xyz")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func trelloServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != `OAuth oauth_consumer_key="key", oauth_token="token"` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/1/members/me/boards":
+			fmt.Fprint(w, `[{"id":"b1","name":"Ops"},{"id":"b2","name":"Marketing"}]`)
+		case "/1/boards/ops":
+			fmt.Fprint(w, `{"id":"b1","name":"Ops"}`)
+		case "/1/boards/b1/cards/all":
+			fmt.Fprint(w, `[{"id":"c1","name":"Rotate keys","shortUrl":"https://trello.com/c/abc"}]`)
+		case "/1/boards/b2/cards/all":
+			fmt.Fprint(w, `[{"id":"c2","name":"Launch","shortUrl":"https://trello.com/c/def"}]`)
+		case "/1/cards/c1":
+			fmt.Fprint(w, `{"id":"c1","name":"Rotate keys","desc":"password=hunter2","dateLastActivity":"2024-01-02T03:04:05.000Z","attachments":[
+				{"id":"a1","name":"Config","url":"https://trello.com/1/cards/c1/attachments/a1/download/config.env","bytes":25,"isUpload":true},
+				{"id":"a2","name":"Docs","url":"https://docs.example.com/runbook","isUpload":false}]}`)
+		case "/1/cards/c2":
+			fmt.Fprint(w, `{"id":"c2","name":"Launch","desc":"nothing here"}`)
+		case "/1/cards/c1/actions":
+			fmt.Fprint(w, `[{"id":"x1","date":"2024-01-03T00:00:00.000Z","data":{"text":"Use token=abc123"},"memberCreator":{"fullName":"Ada","username":"ada"}}]`)
+		case "/1/cards/c2/actions":
+			fmt.Fprint(w, `[]`)
+		case "/1/cards/c1/attachments/a1/download/config.env":
+			fmt.Fprint(w, "AWS_SECRET_ACCESS_KEY=xyz")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSource_ChunksTrello(t *testing.T) {
+	server := trelloServer(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Tracker{
+		Platform: &sourcespb.Tracker_Trello{Trello: &sourcespb.Trello{ApiKey: "key", Token: "token"}},
+	}, 4)
+	s.platform.(*trello).endpoint = server.URL
+
+	got := sources.RequireChunks(t, s)
+	assert.Equal(t, &source_metadatapb.Tracker{
+		Platform:  "trello",
+		Project:   "Ops",
+		Item:      "c1",
+		Title:     "Rotate keys",
+		Location:  "description",
+		Link:      "https://trello.com/c/abc",
+		Timestamp: "2024-01-02T03:04:05Z",
+	}, sources.MetadataWith(got, "Rotate keys\n\npassword=hunter2\nhttps://docs.example.com/runbook").GetTracker())
+	comment := sources.MetadataWith(got, "Use token=abc123").GetTracker()
+	require.NotNil(t, comment)
+	assert.Equal(t, "comment/x1", comment.GetLocation())
+	assert.Equal(t, "Ada", comment.GetAuthor())
+	assert.Equal(t, "attachment/Config", sources.MetadataWith(got, "AWS_SECRET_ACCESS_KEY=xyz").GetTracker().GetLocation())
+	assert.Equal(t, "Marketing", sources.MetadataWith(got, "nothing here").GetTracker().GetProject())
+
+	// Boards filter the cards scanned.
+	s = sources.InitTestSource(t, &Source{}, &sourcespb.Tracker{
+		Platform:        &sourcespb.Tracker_Trello{Trello: &sourcespb.Trello{ApiKey: "key", Token: "token", Boards: []string{"ops"}}},
+		SkipAttachments: true,
+	}, 4)
+	s.platform.(*trello).endpoint = server.URL
+	got = sources.RequireChunks(t, s)
+	assert.NotNil(t, sources.MetadataWith(got, "password=hunter2").GetTracker())
+	assert.Nil(t, sources.MetadataWith(got, "nothing here").GetTracker())
+	assert.Nil(t, sources.MetadataWith(got, "AWS_SECRET_ACCESS_KEY=xyz").GetTracker())
+}
+
+func TestSource_ChunksTrelloInvalidCredentials(t *testing.T) {
+	server := trelloServer(t)
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Tracker{
+		Platform: &sourcespb.Tracker_Trello{Trello: &sourcespb.Trello{ApiKey: "key", Token: "wrong"}},
+	}, 4)
+	s.platform.(*trello).endpoint = server.URL
+	err := s.Chunks(context.Background(), make(chan *sources.Chunk, 1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid credentials")
+}
+
+func TestSource_ChunksAsana(t *testing.T) {
+	files := fileServer(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/workspaces":
+			fmt.Fprint(w, `{"data":[{"gid":"w1","name":"Acme"}],"next_page":null}`)
+		case "/projects":
+			fmt.Fprint(w, `{"data":[{"gid":"p1","name":"Infra"}],"next_page":null}`)
+		case "/projects/p1/tasks":
+			if r.URL.Query().Get("offset") == "" {
+				fmt.Fprint(w, `{"data":[{"gid":"t1","name":"Set up CI","permalink_url":"https://app.asana.com/0/p1/t1"}],"next_page":{"offset":"o2"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":[{"gid":"t2","name":"Second","permalink_url":"https://app.asana.com/0/p1/t2"}],"next_page":null}`)
+		case "/tasks/t1":
+			fmt.Fprint(w, `{"data":{"name":"Set up CI","notes":"password=hunter2","created_at":"2024-01-02T03:04:05.000Z","created_by":{"name":"Ada","email":"ada@example.com"}}}`)
+		case "/tasks/t2":
+			fmt.Fprint(w, `{"data":{"name":"Second","notes":"second notes"}}`)
+		case "/attachments":
+			if r.URL.Query().Get("parent") != "t1" {
+				fmt.Fprint(w, `{"data":[],"next_page":null}`)
+				return
+			}
+			fmt.Fprintf(w, `{"data":[{"name":"config.env","download_url":%q,"size":25},{"name":"Drive doc","download_url":null}],"next_page":null}`, files.URL+"/config.env?signature=abc")
+		case "/tasks/t1/stories":
+			fmt.Fprint(w, `{"data":[
+				{"gid":"s1","resource_subtype":"assigned","text":"Ada assigned to Bob"},
+				{"gid":"s2","resource_subtype":"comment_added","text":"Use token=abc123","created_at":"2024-01-03T00:00:00.000Z","created_by":{"name":"Bob"}}],"next_page":null}`)
+		case "/tasks/t2/stories":
+			fmt.Fprint(w, `{"data":[],"next_page":null}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Tracker{
+		Platform: &sourcespb.Tracker_Asana{Asana: &sourcespb.Asana{Token: "token"}},
+	}, 4)
+	s.platform.(*asana).endpoint = server.URL
+
+	got := sources.RequireChunks(t, s)
+	assert.Equal(t, &source_metadatapb.Tracker{
+		Platform:  "asana",
+		Project:   "Infra",
+		Item:      "t1",
+		Title:     "Set up CI",
+		Location:  "description",
+		Author:    "Ada <ada@example.com>",
+		Link:      "https://app.asana.com/0/p1/t1",
+		Timestamp: "2024-01-02T03:04:05Z",
+	}, sources.MetadataWith(got, "password=hunter2").GetTracker())
+	comment := sources.MetadataWith(got, "Use token=abc123").GetTracker()
+	require.NotNil(t, comment)
+	assert.Equal(t, "comment/s2", comment.GetLocation())
+	assert.Nil(t, sources.MetadataWith(got, "assigned to Bob").GetTracker())
+	assert.Equal(t, "attachment/config.env", sources.MetadataWith(got, "AWS_SECRET_ACCESS_KEY=xyz").GetTracker().GetLocation())
+	assert.NotNil(t, sources.MetadataWith(got, "second notes").GetTracker())
+}
+
+func TestSource_ChunksLinear(t *testing.T) {
+	uploads := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "AWS_SECRET_ACCESS_KEY=xyz")
+	}))
+	t.Cleanup(uploads.Close)
+
+	var filters []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_key" || r.URL.Path != "/graphql" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch {
+		case strings.HasPrefix(req.Query, "query Issues"):
+			filters = append(filters, req.Variables["filter"])
+			fmt.Fprint(w, `{"data":{"issues":{"nodes":[{"id":"u1","identifier":"ENG-1","title":"DB access","url":"https://linear.app/acme/issue/ENG-1","team":{"key":"ENG"}}],"pageInfo":{"hasNextPage":false,"endCursor":"e1"}}}}`)
+		case req.Variables["id"] == "ENG-1" && req.Variables["after"] == nil:
+			fmt.Fprintf(w, `{"data":{"issue":{"title":"DB access","description":"password=hunter2 ![config](%s/acme/f1/config.env)","createdAt":"2024-01-02T03:04:05.000Z","creator":{"name":"Ada","email":"ada@example.com"},
+				"comments":{"nodes":[{"id":"m1","body":"first comment","createdAt":"2024-01-03T00:00:00.000Z","user":{"name":"Bob"}}],"pageInfo":{"hasNextPage":true,"endCursor":"m1"}}}}}`, uploads.URL)
+		case req.Variables["id"] == "ENG-1":
+			fmt.Fprint(w, `{"data":{"issue":{"title":"DB access","description":"password=hunter2",
+				"comments":{"nodes":[{"id":"m2","body":"Use token=abc123","user":{"name":"Bob"}}],"pageInfo":{"hasNextPage":false,"endCursor":"m2"}}}}}`)
+		default:
+			fmt.Fprint(w, `{"errors":[{"message":"unknown query"}]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	s := sources.InitTestSource(t, &Source{}, &sourcespb.Tracker{
+		Platform: &sourcespb.Tracker_Linear{Linear: &sourcespb.Linear{ApiKey: "lin_api_key", Teams: []string{"ENG"}}},
+	}, 4)
+	l := s.platform.(*linear)
+	l.endpoint = server.URL
+	l.setUploads(uploads.URL)
+
+	got := sources.RequireChunks(t, s)
+	description := sources.MetadataWith(got, "DB access\n\npassword=hunter2").GetTracker()
+	assert.Equal(t, &source_metadatapb.Tracker{
+		Platform:  "linear",
+		Project:   "ENG",
+		Item:      "ENG-1",
+		Title:     "DB access",
+		Location:  "description",
+		Author:    "Ada <ada@example.com>",
+		Link:      "https://linear.app/acme/issue/ENG-1",
+		Timestamp: "2024-01-02T03:04:05Z",
+	}, description)
+	assert.Equal(t, "comment/m1", sources.MetadataWith(got, "first comment").GetTracker().GetLocation())
+	assert.Equal(t, "comment/m2", sources.MetadataWith(got, "Use token=abc123").GetTracker().GetLocation())
+	assert.Equal(t, "attachment/config.env", sources.MetadataWith(got, "AWS_SECRET_ACCESS_KEY=xyz").GetTracker().GetLocation())
+	assert.Equal(t, []any{map[string]any{"team": map[string]any{"key": map[string]any{"in": []any{"ENG"}}}}}, filters)
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.Tracker{
+		"no platform":       {},
+		"no Trello token":   {Platform: &sourcespb.Tracker_Trello{Trello: &sourcespb.Trello{ApiKey: "key"}}},
+		"no Asana token":    {Platform: &sourcespb.Tracker_Asana{Asana: &sourcespb.Asana{}}},
+		"no Linear API key": {Platform: &sourcespb.Tracker_Linear{Linear: &sourcespb.Linear{}}},
+		"no Trello API key": {Platform: &sourcespb.Tracker_Trello{Trello: &sourcespb.Trello{Token: "token"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			anyConn, err := anypb.New(conn)
+			require.NoError(t, err)
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 0, 0, false, anyConn, 1))
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+const (
+	// trelloEndpoint is the URL of the Trello API.
+	trelloEndpoint = "https://api.trello.com"
+	// trelloPageSize is the number of comments requested at a time, the most
+	// Trello returns.
+	trelloPageSize = 1000
+)
+
+// trello lists the cards of Trello boards.
+type trello struct {
+	api
+	boards []string
+}
+
+func newTrello(client *http.Client, conn *sourcespb.Trello) (*trello, error) {
+	if conn.GetApiKey() == "" || conn.GetToken() == "" {
+		return nil, errors.New("an API key and a token are required")
+	}
+	t := &trello{api: api{client: client, endpoint: trelloEndpoint}, boards: conn.GetBoards()}
+	t.authorize = func(req *http.Request) {
+		req.Header.Set("Authorization", fmt.Sprintf("OAuth oauth_consumer_key=%q, oauth_token=%q", conn.GetApiKey(), conn.GetToken()))
+	}
+	return t, nil
+}
+
+func (t *trello) name() string {
+	return "trello"
+}
+
+type trelloBoard struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type trelloCard struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	Desc             string    `json:"desc"`
+	ShortURL         string    `json:"shortUrl"`
+	DateLastActivity time.Time `json:"dateLastActivity"`
+	Attachments      []struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		URL      string `json:"url"`
+		Bytes    int64  `json:"bytes"`
+		IsUpload bool   `json:"isUpload"`
+	} `json:"attachments"`
+}
+
+// items lists the cards of each board, open and archived.
+func (t *trello) items(ctx context.Context, fn func([]item) error) error {
+	var boards []trelloBoard
+	if len(t.boards) == 0 {
+		if err := t.get(ctx, "/1/members/me/boards?fields=id,name", &boards); err != nil {
+			return err
+		}
+	}
+	for _, id := range t.boards {
+		var board trelloBoard
+		if err := t.get(ctx, "/1/boards/"+url.PathEscape(id)+"?fields=id,name", &board); err != nil {
+			return fmt.Errorf("error getting board %s: %w", id, err)
+		}
+		boards = append(boards, board)
+	}
+
+	for _, board := range boards {
+		var cards []trelloCard
+		if err := t.get(ctx, "/1/boards/"+board.ID+"/cards/all?fields=id,name,shortUrl", &cards); err != nil {
+			return fmt.Errorf("error listing cards of board %s: %w", board.ID, err)
+		}
+		items := make([]item, 0, len(cards))
+		for _, c := range cards {
+			items = append(items, item{id: c.ID, project: board.Name, title: c.Name, link: c.ShortURL})
+		}
+		if err := fn(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type trelloComment struct {
+	ID   string    `json:"id"`
+	Date time.Time `json:"date"`
+	Data struct {
+		Text string `json:"text"`
+	} `json:"data"`
+	MemberCreator struct {
+		FullName string `json:"fullName"`
+		Username string `json:"username"`
+	} `json:"memberCreator"`
+}
+
+// posts returns the description of a card, with the URLs of the links it
+// is attached, and its comments.
+func (t *trello) posts(ctx context.Context, it item) ([]post, error) {
+	var card trelloCard
+	query := url.Values{
+		"fields":            {"name,desc,dateLastActivity"},
+		"attachments":       {"true"},
+		"attachment_fields": {"id,name,url,bytes,isUpload"},
+	}
+	if err := t.get(ctx, "/1/cards/"+it.id+"?"+query.Encode(), &card); err != nil {
+		return nil, fmt.Errorf("error getting card: %w", err)
+	}
+	description := post{
+		location: locationDescription,
+		body:     card.Name + "\n\n" + card.Desc,
+		created:  card.DateLastActivity,
+	}
+	for _, a := range card.Attachments {
+		if !a.IsUpload {
+			description.body += "\n" + a.URL
+			continue
+		}
+		// Uploads are downloaded through the API, by the name of their file.
+		u, err := url.Parse(a.URL)
+		if err != nil {
+			continue
+		}
+		description.attachments = append(description.attachments, attachment{
+			name: a.Name,
+			url:  fmt.Sprintf("%s/1/cards/%s/attachments/%s/download/%s", t.endpoint, it.id, a.ID, url.PathEscape(path.Base(u.Path))),
+			size: a.Bytes,
+		})
+	}
+	posts := []post{description}
+
+	before := ""
+	for {
+		query := url.Values{"filter": {"commentCard"}, "limit": {strconv.Itoa(trelloPageSize)}}
+		if before != "" {
+			query.Set("before", before)
+		}
+		var comments []trelloComment
+		if err := t.get(ctx, "/1/cards/"+it.id+"/actions?"+query.Encode(), &comments); err != nil {
+			return nil, fmt.Errorf("error listing comments: %w", err)
+		}
+		for _, c := range comments {
+			author := c.MemberCreator.FullName
+			if author == "" {
+				author = c.MemberCreator.Username
+			}
+			posts = append(posts, post{
+				location: locationComment + "/" + c.ID,
+				author:   author,
+				body:     c.Data.Text,
+				created:  c.Date,
+			})
+		}
+		if len(comments) < trelloPageSize {
+			return posts, nil
+		}
+		before = comments[len(comments)-1].ID
+	}
+}
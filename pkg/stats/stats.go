@@ -0,0 +1,364 @@
+// Package stats reports trends over the findings of a history of scans, like
+// the findings opened and resolved per week and the mean time to remediation.
+package stats
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/tui/triage"
+)
+
+// Unassigned is the team of repositories that match no team's patterns.
+const Unassigned = "unassigned"
+
+// week is the period findings are grouped by in trends.
+const week = 7 * 24 * time.Hour
+
+// Scan is the findings of one scan.
+type Scan struct {
+	Time     time.Time
+	Findings []triage.Finding
+}
+
+// ReadScans reads the JSON reports at the paths, one per scan. Directories
+// are read for reports with a .json or .jsonl extension. The time of a scan is
+// the date or RFC 3339 time at the start of the report's name, e.g.
+// 2023-07-31-nightly.json, or else the report's modification time. Scans are
+// sorted by time.
+func ReadScans(paths ...string) ([]Scan, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if ext := filepath.Ext(entry.Name()); !entry.IsDir() && (ext == ".json" || ext == ".jsonl") {
+				files = append(files, filepath.Join(p, entry.Name()))
+			}
+		}
+	}
+
+	scans := make([]Scan, 0, len(files))
+	for _, file := range files {
+		scan, err := readScan(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		scans = append(scans, scan)
+	}
+	sort.SliceStable(scans, func(i, j int) bool { return scans[i].Time.Before(scans[j].Time) })
+	return scans, nil
+}
+
+func readScan(file string) (Scan, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return Scan{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return Scan{}, err
+	}
+	findings, err := triage.ReadFindings(f)
+	if err != nil {
+		return Scan{}, err
+	}
+	return Scan{Time: scanTime(filepath.Base(file), info.ModTime()), Findings: findings}, nil
+}
+
+// scanTime parses the time at the start of a report's name.
+func scanTime(name string, modTime time.Time) time.Time {
+	if len(name) >= len("2006-01-02T15:04:05Z") {
+		if t, err := time.Parse(time.RFC3339, name[:len("2006-01-02T15:04:05Z")]); err == nil {
+			return t
+		}
+	}
+	if len(name) >= len("2006-01-02") {
+		if t, err := time.Parse("2006-01-02", name[:len("2006-01-02")]); err == nil {
+			return t
+		}
+	}
+	return modTime
+}
+
+// Teams maps team names to the patterns of the repositories they own, e.g.
+// https://github.com/org/payments-*. Patterns use path.Match syntax.
+type Teams map[string][]string
+
+// ReadTeams parses teams from a YAML file.
+func ReadTeams(filename string) (Teams, error) {
+	input, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var teams Teams
+	if err := yaml.UnmarshalStrict(input, &teams); err != nil {
+		return nil, err
+	}
+	for team, patterns := range teams {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("team %s: %q: %w", team, pattern, err)
+			}
+		}
+	}
+	return teams, nil
+}
+
+// team returns the first team, by name, that owns the repository.
+func (t Teams) team(repository string) string {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, pattern := range t[name] {
+			if ok, _ := path.Match(pattern, repository); ok {
+				return name
+			}
+		}
+	}
+	return Unassigned
+}
+
+// Report is the trends over a history of scans. A finding is a secret found
+// by a detector in a file of a repository, and is resolved at the first scan
+// after the last scan that found it.
+type Report struct {
+	Scans int
+	From  time.Time
+	To    time.Time
+	// Open is the number of findings of the last scan.
+	Open     int
+	Resolved int
+	// MeanTimeToRemediation is the mean time between the first scan that
+	// found a finding and its resolution, over resolved findings.
+	MeanTimeToRemediation time.Duration
+	// Weeks are the trends per week, starting on Mondays.
+	Weeks        []Week
+	Detectors    []Group
+	Repositories []Group
+	// Teams is only set if teams are given.
+	Teams []Group
+}
+
+// Week is the findings opened and resolved in a week, and those still open
+// at its end.
+type Week struct {
+	Start    time.Time
+	New      int
+	Resolved int
+	Open     int
+}
+
+// Group is the findings of a detector, repository or team.
+type Group struct {
+	Name                  string
+	Open                  int
+	Resolved              int
+	MeanTimeToRemediation time.Duration
+}
+
+type finding struct {
+	detector   string
+	repository string
+	firstSeen  time.Time
+	lastScan   int
+	resolved   time.Time
+}
+
+func findingKey(f triage.Finding) string {
+	secret := f.RawV2
+	if secret == "" {
+		secret = f.Raw
+	}
+	return strings.Join([]string{f.DetectorType.String(), f.DetectorName, secret, f.SourceName, f.Repository(), f.File()}, "\x00")
+}
+
+// Compute reports the trends of the scans, which must be sorted by time.
+// Findings are grouped by team if teams is not nil.
+func Compute(scans []Scan, teams Teams) Report {
+	var report Report
+	if len(scans) == 0 {
+		return report
+	}
+	report.Scans = len(scans)
+	report.From, report.To = scans[0].Time, scans[len(scans)-1].Time
+
+	findings := make(map[string]*finding)
+	for i, scan := range scans {
+		for _, f := range scan.Findings {
+			key := findingKey(f)
+			fi, ok := findings[key]
+			if !ok {
+				detector := f.DetectorName
+				if detector == "" {
+					detector = f.DetectorType.String()
+				}
+				repository := f.Repository()
+				if repository == "" {
+					repository = f.SourceName
+				}
+				fi = &finding{detector: detector, repository: repository, firstSeen: scan.Time}
+				findings[key] = fi
+			}
+			fi.lastScan = i
+		}
+	}
+
+	all := newGroups()
+	detectors := newGroups()
+	repositories := newGroups()
+	teamGroups := newGroups()
+	for _, fi := range findings {
+		if fi.lastScan < len(scans)-1 {
+			fi.resolved = scans[fi.lastScan+1].Time
+		}
+		all.add("", fi)
+		detectors.add(fi.detector, fi)
+		repositories.add(fi.repository, fi)
+		if teams != nil {
+			teamGroups.add(teams.team(fi.repository), fi)
+		}
+	}
+	if total := all.list(); len(total) > 0 {
+		report.Open, report.Resolved, report.MeanTimeToRemediation = total[0].Open, total[0].Resolved, total[0].MeanTimeToRemediation
+	}
+	report.Detectors = detectors.list()
+	report.Repositories = repositories.list()
+	if teams != nil {
+		report.Teams = teamGroups.list()
+	}
+
+	for start := weekStart(report.From); !start.After(report.To); start = start.Add(week) {
+		end := start.Add(week)
+		w := Week{Start: start}
+		for _, fi := range findings {
+			if within(fi.firstSeen, start, end) {
+				w.New++
+			}
+			if !fi.resolved.IsZero() && within(fi.resolved, start, end) {
+				w.Resolved++
+			}
+			if fi.firstSeen.Before(end) && (fi.resolved.IsZero() || !fi.resolved.Before(end)) {
+				w.Open++
+			}
+		}
+		report.Weeks = append(report.Weeks, w)
+	}
+	return report
+}
+
+// weekStart returns the start of the Monday of the week of t, in UTC.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+func within(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
+
+type groups struct {
+	byName map[string]*Group
+	// remediation is the total time to remediation of each group.
+	remediation map[string]time.Duration
+}
+
+func newGroups() *groups {
+	return &groups{byName: make(map[string]*Group), remediation: make(map[string]time.Duration)}
+}
+
+func (g *groups) add(name string, fi *finding) {
+	group, ok := g.byName[name]
+	if !ok {
+		group = &Group{Name: name}
+		g.byName[name] = group
+	}
+	if fi.resolved.IsZero() {
+		group.Open++
+		return
+	}
+	group.Resolved++
+	g.remediation[name] += fi.resolved.Sub(fi.firstSeen)
+}
+
+// list returns the groups with the most open findings first.
+func (g *groups) list() []Group {
+	list := make([]Group, 0, len(g.byName))
+	for name, group := range g.byName {
+		if group.Resolved > 0 {
+			group.MeanTimeToRemediation = g.remediation[name] / time.Duration(group.Resolved)
+		}
+		list = append(list, *group)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Open != list[j].Open {
+			return list[i].Open > list[j].Open
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// WriteText writes the report as tables.
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Scans:\t%d\t(%s to %s)\n", r.Scans, r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
+	fmt.Fprintf(tw, "Open findings:\t%d\n", r.Open)
+	fmt.Fprintf(tw, "Resolved findings:\t%d\n", r.Resolved)
+	fmt.Fprintf(tw, "Mean time to remediation:\t%s\n", formatDuration(r.MeanTimeToRemediation))
+
+	fmt.Fprintf(tw, "\nWeek of\tNew\tResolved\tOpen\n")
+	for _, week := range r.Weeks {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", week.Start.Format("2006-01-02"), week.New, week.Resolved, week.Open)
+	}
+	writeGroups(tw, "Detector", r.Detectors)
+	writeGroups(tw, "Repository", r.Repositories)
+	if r.Teams != nil {
+		writeGroups(tw, "Team", r.Teams)
+	}
+	return tw.Flush()
+}
+
+func writeGroups(w io.Writer, title string, groups []Group) {
+	fmt.Fprintf(w, "\n%s\tOpen\tResolved\tMean time to remediation\n", title)
+	for _, group := range groups {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", group.Name, group.Open, group.Resolved, formatDuration(group.MeanTimeToRemediation))
+	}
+}
+
+// formatDuration formats durations in days and hours, e.g. 3d4h.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	hours := int64(d.Round(time.Hour) / time.Hour)
+	days, hours := hours/24, hours%24
+	if days == 0 {
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dd%dh", days, hours)
+}
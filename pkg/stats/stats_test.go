@@ -0,0 +1,112 @@
+package stats
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/tui/triage"
+)
+
+const (
+	awsFinding    = `{"SourceName":"git","SourceMetadata":{"Data":{"Git":{"file":"a.env","repository":"https://github.com/org/pay-api"}}},"DetectorType":2,"Raw":"AKIA1"}`
+	stripeFinding = `{"SourceName":"git","SourceMetadata":{"Data":{"Git":{"file":"b.env","repository":"https://github.com/org/web"}}},"DetectorType":16,"Raw":"sk_1"}`
+	slackFinding  = `{"SourceName":"fs","SourceMetadata":{"Data":{"Filesystem":{"file":"c.txt"}}},"DetectorType":13,"Raw":"xoxb"}`
+)
+
+func scan(t *testing.T, date string, findings ...string) Scan {
+	t.Helper()
+	tm, err := time.Parse("2006-01-02", date)
+	require.NoError(t, err)
+	fs, err := triage.ReadFindings(strings.NewReader(strings.Join(findings, "\n")))
+	require.NoError(t, err)
+	return Scan{Time: tm, Findings: fs}
+}
+
+func day(date string) time.Time {
+	t, _ := time.Parse("2006-01-02", date)
+	return t
+}
+
+func TestCompute(t *testing.T) {
+	scans := []Scan{
+		scan(t, "2023-07-04", awsFinding, stripeFinding),
+		scan(t, "2023-07-11", stripeFinding, slackFinding),
+		scan(t, "2023-07-13", stripeFinding),
+		scan(t, "2023-07-20", stripeFinding),
+	}
+	teams := Teams{"payments": {"https://github.com/org/pay-*"}}
+	report := Compute(scans, teams)
+
+	assert.Equal(t, 4, report.Scans)
+	assert.Equal(t, 1, report.Open)
+	assert.Equal(t, 2, report.Resolved)
+	// AWS took 7 days and Slack 2 days to resolve.
+	assert.Equal(t, 108*time.Hour, report.MeanTimeToRemediation)
+	assert.Equal(t, []Week{
+		{Start: day("2023-07-03"), New: 2, Open: 2},
+		{Start: day("2023-07-10"), New: 1, Resolved: 2, Open: 1},
+		{Start: day("2023-07-17"), Open: 1},
+	}, report.Weeks)
+	assert.Equal(t, []Group{
+		{Name: "Stripe", Open: 1},
+		{Name: "AWS", Resolved: 1, MeanTimeToRemediation: 7 * 24 * time.Hour},
+		{Name: "Slack", Resolved: 1, MeanTimeToRemediation: 2 * 24 * time.Hour},
+	}, report.Detectors)
+	assert.Equal(t, []Group{
+		{Name: "https://github.com/org/web", Open: 1},
+		{Name: "fs", Resolved: 1, MeanTimeToRemediation: 2 * 24 * time.Hour},
+		{Name: "https://github.com/org/pay-api", Resolved: 1, MeanTimeToRemediation: 7 * 24 * time.Hour},
+	}, report.Repositories)
+	assert.Equal(t, []Group{
+		{Name: Unassigned, Open: 1, Resolved: 1, MeanTimeToRemediation: 2 * 24 * time.Hour},
+		{Name: "payments", Resolved: 1, MeanTimeToRemediation: 7 * 24 * time.Hour},
+	}, report.Teams)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out))
+	assert.Contains(t, out.String(), "Mean time to remediation:  4d12h\n")
+	assert.Contains(t, out.String(), "2023-07-10  1    2         1\n")
+
+	assert.Nil(t, Compute(scans, nil).Teams)
+	assert.Equal(t, Report{}, Compute(nil, nil))
+}
+
+func TestReadScans(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2023-07-11-nightly.json"), []byte(stripeFinding+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2023-07-04T02:00:00Z.jsonl"), []byte("log line\n"+awsFinding+"\n"+stripeFinding+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "teams.yaml"), []byte("payments: []\n"), 0644))
+	undated := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, os.WriteFile(undated, nil, 0644))
+	modTime := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(undated, modTime, modTime))
+
+	scans, err := ReadScans(undated, dir)
+	require.NoError(t, err)
+	require.Len(t, scans, 3)
+	assert.Equal(t, time.Date(2023, 7, 4, 2, 0, 0, 0, time.UTC), scans[0].Time)
+	assert.Len(t, scans[0].Findings, 2)
+	assert.Equal(t, day("2023-07-11"), scans[1].Time)
+	assert.True(t, modTime.Equal(scans[2].Time))
+	assert.Empty(t, scans[2].Findings)
+}
+
+func TestReadTeams(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "teams.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("payments: [\"https://github.com/org/pay-*\"]\n"), 0644))
+	teams, err := ReadTeams(path)
+	require.NoError(t, err)
+	assert.Equal(t, "payments", teams.team("https://github.com/org/pay-api"))
+	assert.Equal(t, Unassigned, teams.team("https://github.com/org/web"))
+
+	require.NoError(t, os.WriteFile(path, []byte("payments: [\"[\"]\n"), 0644))
+	_, err = ReadTeams(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,145 @@
+package triage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Finding is a result read from a report written with --json.
+type Finding struct {
+	SourceMetadata struct {
+		// Data maps the source type, e.g. Git, to its metadata fields.
+		Data map[string]map[string]any
+	}
+	SourceName   string
+	DetectorType detectorspb.DetectorType
+	DetectorName string
+	DecoderName  string
+	Verified     bool
+	Raw          string
+	RawV2        string
+	Redacted     string
+	ExtraData    map[string]string
+}
+
+// ReadFindings reads the findings of a JSON report, one per line. Lines that
+// are not JSON objects, like log messages, are skipped.
+func ReadFindings(r io.Reader) ([]Finding, error) {
+	var findings []Finding
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if !bytes.HasPrefix(line, []byte("{")) {
+			continue
+		}
+		var f Finding
+		if err := json.Unmarshal(line, &f); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		findings = append(findings, f)
+	}
+	return findings, scanner.Err()
+}
+
+// metadata returns the source type and metadata fields of the finding.
+func (f Finding) metadata() (string, map[string]any) {
+	for source, data := range f.SourceMetadata.Data {
+		return source, data
+	}
+	return "", nil
+}
+
+func (f Finding) field(name string) string {
+	_, data := f.metadata()
+	if s, ok := data[name].(string); ok {
+		return s
+	}
+	return ""
+}
+
+// File is the file the secret was found in, if the source has files.
+func (f Finding) File() string { return f.field("file") }
+
+// Commit is the commit the secret was found in, if the source is git.
+func (f Finding) Commit() string { return f.field("commit") }
+
+// Repository is the repository the secret was found in, if the source has
+// repositories.
+func (f Finding) Repository() string { return f.field("repository") }
+
+// Line is the line the secret was found on, or 0 if it is unknown.
+func (f Finding) Line() int {
+	_, data := f.metadata()
+	if line, ok := data["line"].(float64); ok {
+		return int(line)
+	}
+	return 0
+}
+
+// Location describes where the secret was found, e.g. config/prod.env:3.
+func (f Finding) Location() string {
+	if file := f.File(); file != "" {
+		if line := f.Line(); line > 0 {
+			return fmt.Sprintf("%s:%d", file, line)
+		}
+		return file
+	}
+	source, data := f.metadata()
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := []string{source}
+	for _, k := range keys {
+		if v := fmt.Sprint(data[k]); v != "" {
+			parts = append(parts, k+"="+v)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Context returns up to radius lines before and after the line of the secret,
+// and the line number of the first of them. Files of git findings are read from
+// their commit in the repository at repoPath, other files from disk.
+func (f Finding) Context(repoPath string, radius int) ([]string, int, error) {
+	file, line := f.File(), f.Line()
+	if file == "" {
+		return nil, 0, fmt.Errorf("%s findings have no file", f.SourceName)
+	}
+
+	var content []byte
+	var err error
+	if commit := f.Commit(); commit != "" {
+		content, err = exec.Command("git", "-C", repoPath, "show", commit+":"+filepath.ToSlash(file)).Output()
+	} else {
+		content, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not read %s: %w", file, err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if line < 1 || line > len(lines) {
+		line = 1
+	}
+	start, end := line-1-radius, line+radius
+	if start < 0 {
+		start = 0
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return lines[start:end], start + 1, nil
+}
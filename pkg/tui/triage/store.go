@@ -0,0 +1,70 @@
+package triage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Status is the verdict for a triaged finding.
+type Status string
+
+const (
+	FalsePositive Status = "false positive"
+	AcceptedRisk  Status = "accepted risk"
+)
+
+// Suppress appends a rule to the ignore file at path that excludes the
+// finding's detector in its file, so later scans don't report it again. The
+// status and reason are recorded in a comment above the rule.
+func Suppress(path string, f Finding, status Status, reason string) error {
+	file := f.File()
+	if file == "" {
+		return fmt.Errorf("%s findings have no file to ignore", f.SourceName)
+	}
+	if filepath.IsAbs(file) {
+		dir, err := filepath.Abs(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("%s is outside of %s", file, dir)
+		}
+		file = rel
+	}
+
+	comment := fmt.Sprintf("# %s: %s at %s", status, f.DetectorType, f.Location())
+	if reason = strings.TrimSpace(reason); reason != "" {
+		comment += ", " + strings.Join(strings.Fields(reason), " ")
+	}
+	rule := fmt.Sprintf("%s\n/%s detectors=%s\n", comment, escapePattern(filepath.ToSlash(file)), strings.ToLower(f.DetectorType.String()))
+
+	out, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if info, err := out.Stat(); err == nil && info.Size() > 0 {
+		rule = "\n" + rule
+	}
+	if _, err := out.WriteString(rule); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// escapePattern escapes the characters with a special meaning in ignore
+// patterns, so the pattern only matches the path itself.
+func escapePattern(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		switch r {
+		case '*', '?', '[', '\\', ' ':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
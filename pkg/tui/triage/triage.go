@@ -0,0 +1,317 @@
+// Package triage implements a terminal UI to browse the findings of a report,
+// view the code around them, re-verify them, and suppress false positives and
+// accepted risks in the ignore file.
+package triage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/tui/keymap"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/tui/styles"
+)
+
+// contextRadius is the number of lines shown before and after a secret.
+const contextRadius = 4
+
+// Options configure the triage UI.
+type Options struct {
+	// RepoPath is the git repository to read the files of git findings from.
+	RepoPath string
+	// IgnoreFile is the ignore file suppressed findings are added to.
+	IgnoreFile string
+	// Detectors are used to re-verify findings. Re-verifying is disabled if
+	// there are none.
+	Detectors []detectors.Detector
+}
+
+type findingContext struct {
+	lines []string
+	first int
+	err   error
+}
+
+type verifiedMsg struct {
+	index    int
+	verified bool
+	err      error
+}
+
+var keys = struct {
+	FalsePositive key.Binding
+	AcceptRisk    key.Binding
+	Reverify      key.Binding
+	Confirm       key.Binding
+	Cancel        key.Binding
+}{
+	FalsePositive: key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "false positive")),
+	AcceptRisk:    key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "accept risk")),
+	Reverify:      key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "re-verify")),
+	Confirm:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "confirm")),
+	Cancel:        key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+}
+
+// Model is the triage UI model.
+type Model struct {
+	opts     Options
+	keyMap   *keymap.KeyMap
+	findings []Finding
+	statuses []Status
+	contexts map[int]findingContext
+
+	cursor int
+	height int
+
+	// prompting is the status a reason is entered for, if any.
+	prompting Status
+	reason    textinput.Model
+	message   string
+}
+
+// New returns a triage UI model for the findings.
+func New(findings []Finding, opts Options) *Model {
+	reason := textinput.New()
+	reason.Placeholder = "optional reason"
+	reason.CharLimit = 200
+	reason.Width = 60
+	return &Model{
+		opts:     opts,
+		keyMap:   keymap.DefaultKeyMap(),
+		findings: findings,
+		statuses: make([]Status, len(findings)),
+		contexts: make(map[int]findingContext),
+		reason:   reason,
+	}
+}
+
+// Run shows the triage UI until the user quits.
+func Run(findings []Finding, opts Options) error {
+	// Read keys from the terminal, as stdin may be the report.
+	_, err := tea.NewProgram(New(findings, opts), tea.WithAltScreen(), tea.WithInputTTY()).Run()
+	return err
+}
+
+// Init implements tea.Model.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case verifiedMsg:
+		f := &m.findings[msg.index]
+		switch {
+		case msg.err != nil:
+			m.message = fmt.Sprintf("Could not verify %s: %v", f.Location(), msg.err)
+		default:
+			f.Verified = msg.verified
+			m.message = fmt.Sprintf("%s is %s.", f.Location(), verifiedString(msg.verified))
+		}
+	case tea.KeyMsg:
+		if m.prompting != "" {
+			return m.updatePrompt(msg)
+		}
+		return m.updateList(msg)
+	}
+	return m, nil
+}
+
+func (m *Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.CmdQuit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keyMap.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		m.message = ""
+	case key.Matches(msg, m.keyMap.Down):
+		if m.cursor < len(m.findings)-1 {
+			m.cursor++
+		}
+		m.message = ""
+	case len(m.findings) == 0:
+	case key.Matches(msg, keys.FalsePositive):
+		m.startPrompt(FalsePositive)
+		return m, textinput.Blink
+	case key.Matches(msg, keys.AcceptRisk):
+		m.startPrompt(AcceptedRisk)
+		return m, textinput.Blink
+	case key.Matches(msg, keys.Reverify):
+		index, f := m.cursor, m.findings[m.cursor]
+		data := verificationData(f, m.context(index).lines)
+		dets := m.opts.Detectors
+		m.message = fmt.Sprintf("Verifying %s...", f.Location())
+		return m, func() tea.Msg {
+			verified, err := Reverify(context.Background(), dets, f, data)
+			return verifiedMsg{index: index, verified: verified, err: err}
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) startPrompt(status Status) {
+	m.prompting = status
+	m.reason.SetValue("")
+	m.reason.Focus()
+}
+
+func (m *Model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Cancel):
+		m.prompting = ""
+		m.reason.Blur()
+		return m, nil
+	case key.Matches(msg, keys.Confirm):
+		f := m.findings[m.cursor]
+		if err := Suppress(m.opts.IgnoreFile, f, m.prompting, m.reason.Value()); err != nil {
+			m.message = fmt.Sprintf("Could not suppress %s: %v", f.Location(), err)
+		} else {
+			m.statuses[m.cursor] = m.prompting
+			m.message = fmt.Sprintf("Marked %s as %s in %s.", f.Location(), m.prompting, m.opts.IgnoreFile)
+		}
+		m.prompting = ""
+		m.reason.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.reason, cmd = m.reason.Update(msg)
+	return m, cmd
+}
+
+// context returns the context of the finding at index, reading it on first
+// use.
+func (m *Model) context(index int) findingContext {
+	c, ok := m.contexts[index]
+	if !ok {
+		c.lines, c.first, c.err = m.findings[index].Context(m.opts.RepoPath, contextRadius)
+		m.contexts[index] = c
+	}
+	return c
+}
+
+// View implements tea.Model.
+func (m *Model) View() string {
+	var b strings.Builder
+	suppressed := 0
+	for _, s := range m.statuses {
+		if s != "" {
+			suppressed++
+		}
+	}
+	b.WriteString(styles.BoldTextStyle.Render(fmt.Sprintf("TruffleHog triage: %d findings, %d suppressed", len(m.findings), suppressed)))
+	b.WriteString("\n\n")
+	if len(m.findings) == 0 {
+		b.WriteString("No findings to triage.\n\n")
+		b.WriteString(styles.HintTextStyle.Render("q quit"))
+		return styles.AppStyle.Render(b.String())
+	}
+
+	b.WriteString(m.listView())
+	b.WriteString("\n")
+	b.WriteString(m.detailView())
+	b.WriteString("\n")
+	if m.prompting != "" {
+		fmt.Fprintf(&b, "Reason for %s: %s\n", m.prompting, m.reason.View())
+		b.WriteString(styles.HintTextStyle.Render("enter confirm • esc cancel"))
+	} else {
+		if m.message != "" {
+			b.WriteString(m.message + "\n")
+		}
+		b.WriteString(styles.HintTextStyle.Render("↑/↓ move • f false positive • a accept risk • v re-verify • q quit"))
+	}
+	return styles.AppStyle.Render(b.String())
+}
+
+// listView shows the findings around the cursor that fit the screen.
+func (m *Model) listView() string {
+	size := 10
+	if m.height > 0 {
+		// Leave room for the header, details, context and help.
+		size = m.height - 2*contextRadius - 16
+	}
+	if size < 3 {
+		size = 3
+	}
+	start := m.cursor - size/2
+	if start > len(m.findings)-size {
+		start = len(m.findings) - size
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + size
+	if end > len(m.findings) {
+		end = len(m.findings)
+	}
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		f := m.findings[i]
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		mark := "  "
+		if f.Verified {
+			mark = "✔ "
+		}
+		line := fmt.Sprintf("%s%s%-20s %s", cursor, mark, f.DetectorType, f.Location())
+		if s := m.statuses[i]; s != "" {
+			line += styles.HintTextStyle.Render(" (" + string(s) + ")")
+		}
+		if i == m.cursor {
+			line = styles.PrimaryTextStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// detailView shows the selected finding and the lines around it.
+func (m *Model) detailView() string {
+	f := m.findings[m.cursor]
+	var b strings.Builder
+	fmt.Fprintf(&b, "Detector: %s  Decoder: %s  %s\n", f.DetectorType, f.DecoderName, verifiedString(f.Verified))
+	fmt.Fprintf(&b, "Location: %s %s\n", f.SourceName, f.Location())
+	if commit := f.Commit(); commit != "" {
+		fmt.Fprintf(&b, "Commit: %s\n", commit)
+	}
+	secret := f.Redacted
+	if secret == "" {
+		secret = f.Raw
+	}
+	fmt.Fprintf(&b, "Secret: %s\n\n", secret)
+
+	c := m.context(m.cursor)
+	if c.err != nil {
+		b.WriteString(styles.HintTextStyle.Render("No context: "+c.err.Error()) + "\n")
+		return b.String()
+	}
+	for i, line := range c.lines {
+		text := fmt.Sprintf("%5d | %s", c.first+i, line)
+		if c.first+i == f.Line() {
+			text = styles.CodeTextStyle.Render(text)
+		}
+		b.WriteString(text + "\n")
+	}
+	return b.String()
+}
+
+func verifiedString(verified bool) string {
+	if verified {
+		return "verified"
+	}
+	return "unverified"
+}
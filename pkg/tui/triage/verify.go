@@ -0,0 +1,59 @@
+package triage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// errNotFound is returned by Reverify if the detector doesn't find the secret
+// in the data anymore, e.g. because the file changed.
+var errNotFound = errors.New("secret not found")
+
+// errNoDetectors is returned by Reverify if re-verifying is disabled.
+var errNoDetectors = errors.New("re-verifying is disabled")
+
+// Reverify verifies the secret of the finding again with the detectors of its
+// type. The detectors scan data, which should contain the secret and the text
+// around it, as secrets that consist of multiple parts are only found together.
+func Reverify(ctx context.Context, dets []detectors.Detector, f Finding, data []byte) (bool, error) {
+	if len(dets) == 0 {
+		return false, errNoDetectors
+	}
+	found := false
+	for _, d := range dets {
+		if d.Type() != f.DetectorType {
+			continue
+		}
+		found = true
+		results, err := d.FromData(ctx, true, data)
+		if err != nil {
+			return false, err
+		}
+		for _, r := range results {
+			if string(r.Raw) != f.Raw || (f.RawV2 != "" && string(r.RawV2) != f.RawV2) {
+				continue
+			}
+			if r.VerificationError != nil {
+				return false, r.VerificationError
+			}
+			return r.Verified, nil
+		}
+	}
+	if !found {
+		return false, fmt.Errorf("no %s detector", f.DetectorType)
+	}
+	return false, errNotFound
+}
+
+// verificationData returns the text to re-verify a finding with: its context,
+// or the raw secret if there is none.
+func verificationData(f Finding, context []string) []byte {
+	if len(context) > 0 {
+		return []byte(strings.Join(context, "\n"))
+	}
+	return []byte(f.Raw + "\n" + f.RawV2)
+}
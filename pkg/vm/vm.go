@@ -0,0 +1,269 @@
+// Package vm finds the disk images of virtual machines, like libvirt domains
+// and their snapshots, VMware VMs and OVF exports, so they can be scanned
+// without booting or modifying them.
+package vm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// virsh runs virsh with the arguments and returns its output. It is a
+// variable so tests don't need libvirt.
+var virsh = func(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "virsh", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("virsh %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+type domainDisks struct {
+	Disks []struct {
+		Device string `xml:"device,attr"`
+		Source struct {
+			File string `xml:"file,attr"`
+			Dev  string `xml:"dev,attr"`
+		} `xml:"source"`
+	} `xml:"devices>disk"`
+}
+
+type snapshotDisks struct {
+	Disks []struct {
+		Snapshot string `xml:"snapshot,attr"`
+		Source   struct {
+			File string `xml:"file,attr"`
+		} `xml:"source"`
+	} `xml:"disks>disk"`
+	Domain domainDisks `xml:"domain"`
+}
+
+// LibvirtDisks returns the disk images of a libvirt domain, or of one of its
+// snapshots if snapshot is not empty, through a read-only connection to the
+// hypervisor at uri. An empty uri uses virsh's default. Disks of external
+// snapshots are their overlay files, and those of internal snapshots are the
+// domain's disks, which contain the snapshot.
+func LibvirtDisks(ctx context.Context, uri, domain, snapshot string) ([]string, error) {
+	args := []string{"--readonly"}
+	if uri != "" {
+		args = append(args, "--connect", uri)
+	}
+	if snapshot == "" {
+		out, err := virsh(ctx, append(args, "dumpxml", domain)...)
+		if err != nil {
+			return nil, err
+		}
+		return parseDomainXML(out)
+	}
+	out, err := virsh(ctx, append(args, "snapshot-dumpxml", domain, snapshot)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseSnapshotXML(out)
+}
+
+// IsLocalURI reports whether a libvirt URI connects to the hypervisor of this
+// machine, like qemu:///system, rather than over the network, like
+// qemu+ssh://host/system. The empty URI of virsh's default is local unless
+// LIBVIRT_DEFAULT_URI names a remote one.
+func IsLocalURI(uri string) bool {
+	if uri == "" {
+		uri = os.Getenv("LIBVIRT_DEFAULT_URI")
+		if uri == "" {
+			return true
+		}
+	}
+	u, err := url.Parse(uri)
+	return err == nil && u.Host == ""
+}
+
+func parseDomainXML(data []byte) ([]string, error) {
+	var domain domainDisks
+	if err := xml.Unmarshal(data, &domain); err != nil {
+		return nil, fmt.Errorf("could not parse domain XML: %w", err)
+	}
+	return domain.paths(), nil
+}
+
+func (d domainDisks) paths() []string {
+	var paths []string
+	for _, disk := range d.Disks {
+		if disk.Device != "" && disk.Device != "disk" {
+			// Skip CD-ROMs and floppies, which are usually installers.
+			continue
+		}
+		switch {
+		case disk.Source.File != "":
+			paths = append(paths, disk.Source.File)
+		case disk.Source.Dev != "":
+			paths = append(paths, disk.Source.Dev)
+		}
+	}
+	return paths
+}
+
+func parseSnapshotXML(data []byte) ([]string, error) {
+	var snapshot snapshotDisks
+	if err := xml.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("could not parse snapshot XML: %w", err)
+	}
+	var paths []string
+	for _, disk := range snapshot.Disks {
+		if disk.Snapshot == "external" && disk.Source.File != "" {
+			paths = append(paths, disk.Source.File)
+		}
+	}
+	if len(paths) > 0 {
+		return paths, nil
+	}
+	return snapshot.Domain.paths(), nil
+}
+
+var (
+	vmxDisk    = regexp.MustCompile(`(?i)^\s*((?:scsi|sata|ide|nvme)\d+:\d+)\.fileName\s*=\s*"([^"]+)"`)
+	vmxDevice  = regexp.MustCompile(`(?i)^\s*((?:scsi|sata|ide|nvme)\d+:\d+)\.deviceType\s*=\s*"([^"]+)"`)
+	vmdkExtent = regexp.MustCompile(`^\s*(?:RW|RDONLY)\s+\d+\s+\w+\s+"([^"]+)"`)
+)
+
+// VMXDisks returns the files of the disks of a VMware VM, given its .vmx
+// file. Disks are resolved to their extents, so for a VM with snapshots the
+// delta files of the current snapshot and those of its parents are returned.
+func VMXDisks(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dir := filepath.Dir(path)
+	files := make(map[string]string)
+	cdroms := make(map[string]bool)
+	var order []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := vmxDisk.FindStringSubmatch(line); m != nil {
+			id := strings.ToLower(m[1])
+			if _, ok := files[id]; !ok {
+				order = append(order, id)
+			}
+			files[id] = m[2]
+		}
+		if m := vmxDevice.FindStringSubmatch(line); m != nil && strings.Contains(strings.ToLower(m[2]), "cdrom") {
+			cdroms[strings.ToLower(m[1])] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var disks []string
+	for _, id := range order {
+		file := files[id]
+		if cdroms[id] || !strings.EqualFold(filepath.Ext(file), ".vmdk") {
+			continue
+		}
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		extents, err := VMDKExtents(file)
+		if err != nil {
+			return nil, err
+		}
+		disks = append(disks, extents...)
+	}
+	return disks, nil
+}
+
+// VMDKExtents returns the files holding the data of a VMDK disk: the extents
+// of its descriptor and those of its parents, or the disk itself if it has
+// no separate descriptor.
+func VMDKExtents(path string) ([]string, error) {
+	return vmdkExtents(path, make(map[string]bool))
+}
+
+// maxDescriptorSize is the size above which a VMDK is not read as a text
+// descriptor, since it holds disk data.
+const maxDescriptorSize = 64 * 1024
+
+func vmdkExtents(path string, seen map[string]bool) ([]string, error) {
+	if seen[path] {
+		return nil, nil
+	}
+	seen[path] = true
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxDescriptorSize {
+		return []string{path}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Contains(data, []byte("# Disk DescriptorFile")) {
+		return []string{path}, nil
+	}
+
+	dir := filepath.Dir(path)
+	var extents []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := vmdkExtent.FindStringSubmatch(line); m != nil {
+			extents = append(extents, filepath.Join(dir, m[1]))
+		}
+		if parent, ok := strings.CutPrefix(strings.TrimSpace(line), "parentFileNameHint="); ok {
+			parent = strings.Trim(parent, `"`)
+			if !filepath.IsAbs(parent) {
+				parent = filepath.Join(dir, parent)
+			}
+			parents, err := vmdkExtents(parent, seen)
+			if err != nil {
+				return nil, fmt.Errorf("parent of %s: %w", path, err)
+			}
+			extents = append(extents, parents...)
+		}
+	}
+	return extents, nil
+}
+
+type ovfEnvelope struct {
+	Files []struct {
+		Href string `xml:"href,attr"`
+	} `xml:"References>File"`
+}
+
+// OVFDisks returns the files referenced by an OVF descriptor, like the disks
+// of an exported VM.
+func OVFDisks(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var envelope ovfEnvelope
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("could not parse OVF descriptor: %w", err)
+	}
+	dir := filepath.Dir(path)
+	var disks []string
+	for _, file := range envelope.Files {
+		if file.Href == "" || strings.Contains(file.Href, "://") {
+			continue
+		}
+		disks = append(disks, filepath.Join(dir, file.Href))
+	}
+	return disks, nil
+}
@@ -0,0 +1,130 @@
+package vm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const domainXML = `<domain type='kvm'>
+  <name>web</name>
+  <devices>
+    <disk type='file' device='disk'>
+      <source file='/var/lib/libvirt/images/web.qcow2'/>
+    </disk>
+    <disk type='block' device='disk'>
+      <source dev='/dev/vg0/web-data'/>
+    </disk>
+    <disk type='file' device='cdrom'>
+      <source file='/isos/install.iso'/>
+    </disk>
+  </devices>
+</domain>`
+
+const snapshotXML = `<domainsnapshot>
+  <name>golden</name>
+  <disks>
+    <disk name='vda' snapshot='external'>
+      <source file='/var/lib/libvirt/images/web.golden'/>
+    </disk>
+    <disk name='vdb' snapshot='no'/>
+  </disks>
+  ` + domainXML + `
+</domainsnapshot>`
+
+func TestLibvirtDisks(t *testing.T) {
+	var calls [][]string
+	virsh = func(_ context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		if strings.Contains(strings.Join(args, " "), "snapshot-dumpxml") {
+			return []byte(snapshotXML), nil
+		}
+		return []byte(domainXML), nil
+	}
+	ctx := context.Background()
+
+	disks, err := LibvirtDisks(ctx, "qemu:///system", "web", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/var/lib/libvirt/images/web.qcow2", "/dev/vg0/web-data"}, disks)
+
+	disks, err = LibvirtDisks(ctx, "", "web", "golden")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/var/lib/libvirt/images/web.golden"}, disks)
+
+	assert.Equal(t, [][]string{
+		{"--readonly", "--connect", "qemu:///system", "dumpxml", "web"},
+		{"--readonly", "snapshot-dumpxml", "web", "golden"},
+	}, calls)
+}
+
+func TestIsLocalURI(t *testing.T) {
+	t.Setenv("LIBVIRT_DEFAULT_URI", "")
+	assert.True(t, IsLocalURI(""))
+	assert.True(t, IsLocalURI("qemu:///system"))
+	assert.True(t, IsLocalURI("qemu+unix:///session"))
+	assert.False(t, IsLocalURI("qemu+ssh://root@kvm01/system"))
+	assert.False(t, IsLocalURI("qemu+tls://kvm01/system"))
+
+	t.Setenv("LIBVIRT_DEFAULT_URI", "qemu+ssh://kvm01/system")
+	assert.False(t, IsLocalURI(""))
+}
+
+func TestParseSnapshotXML_Internal(t *testing.T) {
+	internal := strings.ReplaceAll(snapshotXML, "'external'", "'internal'")
+	disks, err := parseSnapshotXML([]byte(internal))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/var/lib/libvirt/images/web.qcow2", "/dev/vg0/web-data"}, disks)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestVMXDisks(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "web.vmx"), `.encoding = "UTF-8"
+scsi0:0.fileName = "web-000001.vmdk"
+scsi0:0.present = "TRUE"
+sata0:1.deviceType = "cdrom-image"
+sata0:1.fileName = "/isos/install.iso"
+`)
+	writeFile(t, filepath.Join(dir, "web-000001.vmdk"), `# Disk DescriptorFile
+parentFileNameHint="web.vmdk"
+RW 41943040 SPARSE "web-000001-sesparse.vmdk"
+`)
+	writeFile(t, filepath.Join(dir, "web.vmdk"), `# Disk DescriptorFile
+RW 41943040 FLAT "web-flat.vmdk" 0
+`)
+
+	disks, err := VMXDisks(filepath.Join(dir, "web.vmx"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "web-flat.vmdk"), filepath.Join(dir, "web-000001-sesparse.vmdk")}, disks)
+}
+
+func TestVMDKExtents_Monolithic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disk.vmdk")
+	writeFile(t, path, "KDMV\x01\x00\x00\x00 binary sparse extent")
+	disks, err := VMDKExtents(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, disks)
+}
+
+func TestOVFDisks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "web.ovf")
+	writeFile(t, path, `<Envelope xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1">
+  <References>
+    <File ovf:href="web-disk1.vmdk" ovf:id="file1"/>
+    <File ovf:href="https://example.com/disk2.vmdk" ovf:id="file2"/>
+  </References>
+</Envelope>`)
+	disks, err := OVFDisks(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(filepath.Dir(path), "web-disk1.vmdk")}, disks)
+}
@@ -0,0 +1,197 @@
+// Package workstation finds the files of a developer workstation that commonly
+// hold credentials, like shell history, dotfiles, package registry and cloud
+// CLI credentials, and kubeconfigs, and rates how exposed they are.
+package workstation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Risk rates how likely secrets in a location are to be live and privileged.
+type Risk int
+
+const (
+	RiskLow Risk = iota
+	RiskMedium
+	RiskHigh
+	RiskCritical
+)
+
+func (r Risk) String() string {
+	switch r {
+	case RiskCritical:
+		return "critical"
+	case RiskHigh:
+		return "high"
+	case RiskMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// Location is a file or directory of a workstation that may hold
+// credentials.
+type Location struct {
+	Path string
+	// Kind describes what the location holds, e.g. "shell history".
+	Kind string
+	Risk Risk
+	// Advice is how to stop keeping credentials in the location.
+	Advice string
+}
+
+// Contains reports whether file is the location or is below it.
+func (l Location) Contains(file string) bool {
+	rel, err := filepath.Rel(l.Path, file)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// kind is a group of locations, given as globs relative to the home
+// directory.
+type kind struct {
+	name   string
+	risk   Risk
+	advice string
+	globs  []string
+}
+
+var kinds = []kind{
+	{
+		name:   "cloud CLI credentials",
+		risk:   RiskCritical,
+		advice: "use short-lived credentials, like SSO or workload identity, instead of long-lived keys",
+		globs: []string{
+			".aws",
+			".config/gcloud",
+			".azure",
+			".oci",
+			".config/doctl",
+			".config/hcloud",
+			".terraform.d/credentials.tfrc.json",
+			".config/scw",
+			"AppData/Roaming/gcloud",
+		},
+	},
+	{
+		name:   "kubeconfig",
+		risk:   RiskCritical,
+		advice: "use an exec credential plugin instead of embedded tokens and client keys",
+		globs:  []string{".kube/config", ".kube/*.yaml", ".kube/*.yml"},
+	},
+	{
+		name:   "package registry and git credentials",
+		risk:   RiskHigh,
+		advice: "use a credential helper or the keychain instead of tokens in plain text",
+		globs: []string{
+			".netrc",
+			"_netrc",
+			".git-credentials",
+			".npmrc",
+			".yarnrc.yml",
+			".pypirc",
+			".gem/credentials",
+			".cargo/credentials",
+			".cargo/credentials.toml",
+			".m2/settings.xml",
+			".gradle/gradle.properties",
+			".nuget/NuGet/NuGet.Config",
+			".composer/auth.json",
+			".docker/config.json",
+			".config/gh/hosts.yml",
+			".config/hub",
+		},
+	},
+	{
+		name:   "shell history",
+		risk:   RiskMedium,
+		advice: "rotate secrets typed on the command line, and read them from files or prompts instead",
+		globs: []string{
+			".bash_history",
+			".zsh_history",
+			".zhistory",
+			".sh_history",
+			".local/share/fish/fish_history",
+			".python_history",
+			".node_repl_history",
+			".irb_history",
+			".psql_history",
+			".mysql_history",
+			".sqlite_history",
+			".rediscli_history",
+			"AppData/Roaming/Microsoft/Windows/PowerShell/PSReadLine/ConsoleHost_history.txt",
+		},
+	},
+	{
+		name:   "shell dotfiles",
+		risk:   RiskLow,
+		advice: "load secrets from a secrets manager instead of exporting them in shell startup files",
+		globs: []string{
+			".bashrc",
+			".bash_profile",
+			".bash_aliases",
+			".zshrc",
+			".zshenv",
+			".zprofile",
+			".profile",
+			".config/fish/config.fish",
+			".env",
+			"Documents/WindowsPowerShell/Microsoft.PowerShell_profile.ps1",
+			"Documents/PowerShell/Microsoft.PowerShell_profile.ps1",
+		},
+	},
+}
+
+// Find returns the locations found in the home directory, and the kubeconfigs
+// listed in $KUBECONFIG, riskiest first.
+func Find(home string) []Location {
+	var locations []Location
+	seen := make(map[string]bool)
+	add := func(path string, k kind) {
+		if seen[path] {
+			return
+		}
+		if _, err := os.Stat(path); err != nil {
+			return
+		}
+		seen[path] = true
+		locations = append(locations, Location{Path: path, Kind: k.name, Risk: k.risk, Advice: k.advice})
+	}
+	for _, k := range kinds {
+		for _, glob := range k.globs {
+			matches, _ := filepath.Glob(filepath.Join(home, filepath.FromSlash(glob)))
+			for _, match := range matches {
+				add(match, k)
+			}
+		}
+		if k.name == "kubeconfig" {
+			for _, path := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+				if path != "" {
+					add(path, k)
+				}
+			}
+		}
+	}
+	return locations
+}
+
+// Locate returns the location holding file.
+func Locate(locations []Location, file string) (Location, bool) {
+	for _, location := range locations {
+		if location.Contains(file) {
+			return location, true
+		}
+	}
+	return Location{}, false
+}
+
+// Paths returns the paths of the locations.
+func Paths(locations []Location) []string {
+	paths := make([]string, len(locations))
+	for i, location := range locations {
+		paths[i] = location.Path
+	}
+	return paths
+}
@@ -0,0 +1,40 @@
+package workstation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind(t *testing.T) {
+	home := t.TempDir()
+	for _, file := range []string{".aws/credentials", ".kube/config", ".npmrc", ".zsh_history", ".bashrc"} {
+		path := filepath.Join(home, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0600))
+	}
+	extra := filepath.Join(t.TempDir(), "staging.yaml")
+	require.NoError(t, os.WriteFile(extra, nil, 0600))
+	t.Setenv("KUBECONFIG", extra+string(filepath.ListSeparator)+filepath.Join(home, ".kube/config"))
+
+	locations := Find(home)
+	assert.Equal(t, []string{
+		filepath.Join(home, ".aws"),
+		filepath.Join(home, ".kube/config"),
+		extra,
+		filepath.Join(home, ".npmrc"),
+		filepath.Join(home, ".zsh_history"),
+		filepath.Join(home, ".bashrc"),
+	}, Paths(locations))
+	assert.Equal(t, RiskCritical, locations[0].Risk)
+	assert.Equal(t, RiskLow, locations[5].Risk)
+
+	location, ok := Locate(locations, filepath.Join(home, ".aws", "credentials"))
+	assert.True(t, ok)
+	assert.Equal(t, "cloud CLI credentials", location.Kind)
+	_, ok = Locate(locations, filepath.Join(home, ".awsome"))
+	assert.False(t, ok)
+}